@@ -11,6 +11,55 @@
       name: "{{ .Values.spec.config.awsCredentialsSecretName }}"
       key: aws-secret-access-key
       optional: true
+{{- if .Values.spec.config.s3ServerSideEncryption.enabled }}
+- name: HIVE_CATALOG_hive_s3_sse_enabled
+  value: "true"
+{{- if .Values.spec.config.s3ServerSideEncryption.kmsKeyID }}
+- name: HIVE_CATALOG_hive_s3_sse_type
+  value: "KMS"
+- name: HIVE_CATALOG_hive_s3_sse_kms___key___id
+  value: {{ .Values.spec.config.s3ServerSideEncryption.kmsKeyID | quote }}
+{{- else }}
+- name: HIVE_CATALOG_hive_s3_sse_type
+  value: "S3"
+{{- end }}
+{{- end }}
+{{- if .Values.spec.config.awsWebIdentity.roleARN }}
+# the default AWS credential chain is used, which assumes AWS_ROLE_ARN with
+# the web identity token.
+- name: HIVE_CATALOG_hive_s3_use___instance___credentials
+  value: "false"
+{{- include "aws-web-identity-env" . }}
+{{- end }}
+{{- if .Values.spec.config.s3.endpoint }}
+- name: HIVE_CATALOG_hive_s3_endpoint
+  value: {{ .Values.spec.config.s3.endpoint | quote }}
+- name: HIVE_CATALOG_hive_s3_path___style___access
+  value: {{ .Values.spec.config.s3.pathStyleAccess | quote }}
+- name: HIVE_CATALOG_hive_s3_ssl_enabled
+  value: {{ .Values.spec.config.s3.sslEnabled | quote }}
+{{- end }}
+{{- include "object-storage-core-env" . }}
+{{- if .Values.spec.presto.config.connectors }}
+- name: PRESTO_CATALOGS
+  value: "{{ range $i, $connector := .Values.spec.presto.config.connectors }}{{ if $i }} {{ end }}{{ $connector.name }}{{ end }}"
+{{- range .Values.spec.presto.config.connectors }}
+- name: PRESTO_CATALOG_{{ .name }}_connector_name
+  value: {{ .type | quote }}
+- name: PRESTO_CATALOG_{{ .name }}_connection___url
+  value: {{ .connectionURL | quote }}
+- name: PRESTO_CATALOG_{{ .name }}_connection___user
+  valueFrom:
+    secretKeyRef:
+      name: {{ .credentialsSecretName | quote }}
+      key: username
+- name: PRESTO_CATALOG_{{ .name }}_connection___password
+  valueFrom:
+    secretKeyRef:
+      name: {{ .credentialsSecretName | quote }}
+      key: password
+{{- end }}
+{{- end }}
 - name: HIVE_CATALOG_hive_metastore_uri
   valueFrom:
     configMapKeyRef:
@@ -100,6 +149,31 @@
       name: "{{ .Values.spec.config.awsCredentialsSecretName }}"
       key: aws-secret-access-key
       optional: true
+{{- if .Values.spec.config.s3ServerSideEncryption.enabled }}
+{{- if .Values.spec.config.s3ServerSideEncryption.kmsKeyID }}
+- name: CORE_CONF_fs_s3a_server___side___encryption___algorithm
+  value: "SSE-KMS"
+- name: CORE_CONF_fs_s3a_server___side___encryption_key
+  value: {{ .Values.spec.config.s3ServerSideEncryption.kmsKeyID | quote }}
+{{- else }}
+- name: CORE_CONF_fs_s3a_server___side___encryption___algorithm
+  value: "AES256"
+{{- end }}
+{{- end }}
+{{- if .Values.spec.config.awsWebIdentity.roleARN }}
+- name: CORE_CONF_fs_s3a_aws_credentials_provider
+  value: "com.amazonaws.auth.WebIdentityTokenCredentialsProvider"
+{{- include "aws-web-identity-env" . }}
+{{- end }}
+{{- if .Values.spec.config.s3.endpoint }}
+- name: CORE_CONF_fs_s3a_endpoint
+  value: {{ .Values.spec.config.s3.endpoint | quote }}
+- name: CORE_CONF_fs_s3a_path_style_access
+  value: {{ .Values.spec.config.s3.pathStyleAccess | quote }}
+- name: CORE_CONF_fs_s3a_connection_ssl_enabled
+  value: {{ .Values.spec.config.s3.sslEnabled | quote }}
+{{- end }}
+{{- include "object-storage-core-env" . }}
 - name: HIVE_SITE_CONF_hive_metastore_uris
   valueFrom:
     configMapKeyRef:
@@ -157,3 +231,97 @@
 - name: JAVA_MAX_MEM_RATIO
   value: "50"
 {{- end }}
+
+{{- define "aws-web-identity-env" }}
+- name: AWS_ROLE_ARN
+  value: {{ .Values.spec.config.awsWebIdentity.roleARN | quote }}
+- name: AWS_WEB_IDENTITY_TOKEN_FILE
+  value: "/var/run/secrets/metering/aws-web-identity/token"
+{{- end }}
+
+{{- define "aws-web-identity-volume-mount" }}
+{{- if .Values.spec.config.awsWebIdentity.roleARN }}
+- name: aws-web-identity-token
+  mountPath: /var/run/secrets/metering/aws-web-identity
+  readOnly: true
+{{- end }}
+{{- end }}
+
+{{- define "aws-web-identity-volume" }}
+{{- if .Values.spec.config.awsWebIdentity.roleARN }}
+- name: aws-web-identity-token
+  projected:
+    sources:
+    - serviceAccountToken:
+        path: token
+        audience: {{ .Values.spec.config.awsWebIdentity.audience | quote }}
+        expirationSeconds: {{ .Values.spec.config.awsWebIdentity.tokenExpirationSeconds }}
+{{- end }}
+{{- end }}
+
+{{- define "object-storage-core-env" }}
+{{- if .Values.spec.config.gcs.enabled }}
+- name: CORE_CONF_fs_gs_impl
+  value: "com.google.cloud.hadoop.fs.gcs.GoogleHadoopFileSystem"
+- name: CORE_CONF_fs_AbstractFileSystem_gs_impl
+  value: "com.google.cloud.hadoop.fs.gcs.GoogleHadoopFS"
+- name: CORE_CONF_google_cloud_auth_service_account_enable
+  value: "true"
+{{- if .Values.spec.config.gcs.credentialsSecretName }}
+- name: CORE_CONF_google_cloud_auth_service_account_json_keyfile
+  value: "/var/run/secrets/metering/gcs/key.json"
+{{- end }}
+{{- end }}
+{{- if .Values.spec.config.azure.storageAccountName }}
+{{- $account := printf "%s_dfs_core_windows_net" .Values.spec.config.azure.storageAccountName }}
+- name: CORE_CONF_fs_abfs_impl
+  value: "org.apache.hadoop.fs.azurebfs.AzureBlobFileSystem"
+- name: CORE_CONF_fs_abfss_impl
+  value: "org.apache.hadoop.fs.azurebfs.SecureAzureBlobFileSystem"
+{{- if .Values.spec.config.azure.useServicePrincipal }}
+- name: AZURE_TENANT_ID
+  valueFrom:
+    secretKeyRef:
+      name: "{{ .Values.spec.config.azure.credentialsSecretName }}"
+      key: azure-tenant-id
+- name: CORE_CONF_fs_azure_account_auth_type_{{ $account }}
+  value: "OAuth"
+- name: CORE_CONF_fs_azure_account_oauth_provider_type_{{ $account }}
+  value: "org.apache.hadoop.fs.azurebfs.oauth2.ClientCredsTokenProvider"
+- name: CORE_CONF_fs_azure_account_oauth2_client_endpoint_{{ $account }}
+  value: "https://login.microsoftonline.com/$(AZURE_TENANT_ID)/oauth2/token"
+- name: CORE_CONF_fs_azure_account_oauth2_client_id_{{ $account }}
+  valueFrom:
+    secretKeyRef:
+      name: "{{ .Values.spec.config.azure.credentialsSecretName }}"
+      key: azure-client-id
+- name: CORE_CONF_fs_azure_account_oauth2_client_secret_{{ $account }}
+  valueFrom:
+    secretKeyRef:
+      name: "{{ .Values.spec.config.azure.credentialsSecretName }}"
+      key: azure-client-secret
+{{- else }}
+- name: CORE_CONF_fs_azure_account_key_{{ $account }}
+  valueFrom:
+    secretKeyRef:
+      name: "{{ .Values.spec.config.azure.credentialsSecretName }}"
+      key: azure-storage-account-key
+{{- end }}
+{{- end }}
+{{- end }}
+
+{{- define "gcs-credentials-volume-mount" }}
+{{- if and .Values.spec.config.gcs.enabled .Values.spec.config.gcs.credentialsSecretName }}
+- name: gcs-credentials
+  mountPath: /var/run/secrets/metering/gcs
+  readOnly: true
+{{- end }}
+{{- end }}
+
+{{- define "gcs-credentials-volume" }}
+{{- if and .Values.spec.config.gcs.enabled .Values.spec.config.gcs.credentialsSecretName }}
+- name: gcs-credentials
+  secret:
+    secretName: {{ .Values.spec.config.gcs.credentialsSecretName | quote }}
+{{- end }}
+{{- end }}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbClientset "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned"
+	"github.com/operator-framework/operator-metering/pkg/meteringctl"
+)
+
+var (
+	kubeconfig        string
+	kubeContext       string
+	namespace         string
+	meteringNamespace string
+	useHTTPS          bool
+
+	reportOpts     meteringctl.ReportOptions
+	reportStartStr string
+	reportEndStr   string
+	reportWait     bool
+
+	waitInterval time.Duration
+	waitTimeout  time.Duration
+
+	resultsFormat    string
+	resultsScheduled bool
+	resultsWait      bool
+
+	dataStoreLocation string
+)
+
+var rootCmd = &cobra.Command{
+	Use:          "kubectl-metering",
+	Short:        "creates Metering reports, watches their progress, fetches their results, shows the import status of ReportDataSources and exports and imports their data",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return cmd.Help()
+	},
+}
+
+var createReportCmd = &cobra.Command{
+	Use:   "create-report NAME",
+	Short: "creates a Report running a ReportGenerationQuery for a reporting period",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runCreateReport,
+}
+
+var watchReportCmd = &cobra.Command{
+	Use:   "watch-report NAME",
+	Short: "watches the progress of a Report until it finishes or fails",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runWatchReport,
+}
+
+var resultsCmd = &cobra.Command{
+	Use:   "results NAME",
+	Short: "fetches the results of a Report or ScheduledReport",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runResults,
+}
+
+var dataSourcesCmd = &cobra.Command{
+	Use:   "datasources [NAME...]",
+	Short: "shows the import status of ReportDataSources",
+	RunE:  runDataSources,
+}
+
+var exportDataStoreCmd = &cobra.Command{
+	Use:   "export-datastore [NAME...]",
+	Short: "exports the tables of Prometheus metrics and Kubernetes objects ReportDataSources, with their schema and last import timestamp, to HDFS or an object store",
+	Long:  "exports the table of each named ReportDataSource, or of every Prometheus metrics and Kubernetes objects ReportDataSource if none are named, to its own directory named after the ReportDataSource within --location. The directories must not exist yet.",
+	RunE:  runExportDataStore,
+}
+
+var importDataStoreCmd = &cobra.Command{
+	Use:   "import-datastore [NAME...]",
+	Short: "imports the tables exported by export-datastore into the tables of ReportDataSources, such as after rebuilding a cluster",
+	Long:  "imports the export of each named ReportDataSource, or of every Prometheus metrics and Kubernetes objects ReportDataSource if none are named, from the directory named after the ReportDataSource within --location. The ReportDataSources must exist, and have had their tables created. Only rows older than the earliest row already in each table are imported, so imports can be repeated.",
+	RunE:  runImportDataStore,
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", "", "use kubeconfig provided instead of detecting defaults")
+	rootCmd.PersistentFlags().StringVar(&kubeContext, "context", "", "the kubeconfig context to use")
+	rootCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", "", "the namespace of the reports and ReportDataSources. Defaults to the namespace of the kubeconfig context")
+	rootCmd.PersistentFlags().StringVar(&meteringNamespace, "metering-namespace", "", "the namespace Metering is installed in, if it's not the namespace of the reports")
+	rootCmd.PersistentFlags().BoolVar(&useHTTPS, "https", false, "if true, the reporting-operator's API is served with TLS")
+
+	createReportCmd.Flags().StringVar(&reportOpts.GenerationQuery, "query", "", "the name of the ReportGenerationQuery the report runs")
+	createReportCmd.Flags().StringVar(&reportStartStr, "start", "", "the start of the reporting period, as an RFC3339 timestamp")
+	createReportCmd.Flags().StringVar(&reportEndStr, "end", "", "the end of the reporting period, as an RFC3339 timestamp")
+	createReportCmd.Flags().StringVar(&reportOpts.Timezone, "timezone", "", "the IANA timezone the query converts timestamps to. Defaults to UTC")
+	createReportCmd.Flags().BoolVar(&reportOpts.RunImmediately, "run-immediately", false, "run the report without waiting for its ReportDataSources to have data for the reporting period")
+	createReportCmd.Flags().StringArrayVar(&reportOpts.Inputs, "input", nil, "a value of the query's inputs, in the form name=value. May be repeated")
+	createReportCmd.Flags().StringSliceVar(&reportOpts.GroupByLabels, "group-by-label", nil, "a pod label key to group the results by. May be repeated")
+	createReportCmd.Flags().BoolVar(&reportWait, "wait", false, "watch the report's progress until it finishes or fails")
+	createReportCmd.MarkFlagRequired("query")
+	createReportCmd.MarkFlagRequired("start")
+	createReportCmd.MarkFlagRequired("end")
+
+	for _, cmd := range []*cobra.Command{createReportCmd, watchReportCmd, resultsCmd} {
+		cmd.Flags().DurationVar(&waitInterval, "interval", 5*time.Second, "how often the report's progress is checked when waiting for it")
+		cmd.Flags().DurationVar(&waitTimeout, "timeout", 0, "how long to wait for the report to finish. Zero waits forever")
+	}
+
+	resultsCmd.Flags().StringVarP(&resultsFormat, "format", "o", "tab", fmt.Sprintf("the format of the results, one of %s", strings.Join(meteringctl.ResultsFormats, ", ")))
+	resultsCmd.Flags().BoolVar(&resultsScheduled, "scheduled", false, "fetch the results of a ScheduledReport instead of a Report")
+	resultsCmd.Flags().BoolVar(&resultsWait, "wait", false, "wait for the Report to finish before fetching its results")
+
+	for _, cmd := range []*cobra.Command{exportDataStoreCmd, importDataStoreCmd} {
+		cmd.Flags().StringVar(&dataStoreLocation, "location", "", "the directory in HDFS or an object store, such as s3a://bucket/metering-export, containing a directory for each ReportDataSource")
+		cmd.MarkFlagRequired("location")
+	}
+
+	rootCmd.AddCommand(createReportCmd, watchReportCmd, resultsCmd, dataSourcesCmd, exportDataStoreCmd, importDataStoreCmd)
+}
+
+// restConfig returns the Kubernetes client config, and the namespace the
+// commands use.
+func restConfig() (*rest.Config, string, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+
+	kubeConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to get Kubernetes client config: %v", err)
+	}
+	ns := namespace
+	if ns == "" {
+		ns, _, err = clientConfig.Namespace()
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	return kubeConfig, ns, nil
+}
+
+// clients returns the Kubernetes and Metering clients, and the namespace
+// the commands use.
+func clients() (corev1.CoreV1Interface, cbClientset.Interface, string, error) {
+	kubeConfig, ns, err := restConfig()
+	if err != nil {
+		return nil, nil, "", err
+	}
+	kubeClient, err := corev1.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("unable to create Kubernetes client: %v", err)
+	}
+	meteringClient, err := cbClientset.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("unable to create Metering client: %v", err)
+	}
+	return kubeClient, meteringClient, ns, nil
+}
+
+func runCreateReport(cmd *cobra.Command, args []string) error {
+	_, meteringClient, ns, err := clients()
+	if err != nil {
+		return err
+	}
+	reportOpts.Name = args[0]
+	reportOpts.Namespace = ns
+	reportOpts.ReportingStart, err = time.Parse(time.RFC3339, reportStartStr)
+	if err != nil {
+		return fmt.Errorf("invalid --start: %v", err)
+	}
+	reportOpts.ReportingEnd, err = time.Parse(time.RFC3339, reportEndStr)
+	if err != nil {
+		return fmt.Errorf("invalid --end: %v", err)
+	}
+	report, err := meteringctl.NewReport(reportOpts)
+	if err != nil {
+		return err
+	}
+	report, err = meteringClient.MeteringV1alpha1().Reports(ns).Create(report)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "report %s created\n", report.Name)
+	if !reportWait {
+		return nil
+	}
+	_, err = meteringctl.WaitForReport(meteringClient.MeteringV1alpha1(), ns, report.Name, waitInterval, waitTimeout, os.Stderr)
+	return err
+}
+
+func runWatchReport(cmd *cobra.Command, args []string) error {
+	_, meteringClient, ns, err := clients()
+	if err != nil {
+		return err
+	}
+	_, err = meteringctl.WaitForReport(meteringClient.MeteringV1alpha1(), ns, args[0], waitInterval, waitTimeout, os.Stdout)
+	return err
+}
+
+func runResults(cmd *cobra.Command, args []string) error {
+	kubeClient, meteringClient, ns, err := clients()
+	if err != nil {
+		return err
+	}
+	if resultsWait && !resultsScheduled {
+		_, err = meteringctl.WaitForReport(meteringClient.MeteringV1alpha1(), ns, args[0], waitInterval, waitTimeout, os.Stderr)
+		if err != nil {
+			return err
+		}
+	}
+	opts := meteringctl.ResultsOptions{
+		MeteringNamespace: meteringNamespace,
+		Namespace:         ns,
+		Name:              args[0],
+		Scheduled:         resultsScheduled,
+		Format:            resultsFormat,
+		HTTPS:             useHTTPS,
+	}
+	if opts.MeteringNamespace == "" {
+		opts.MeteringNamespace = ns
+	}
+	return meteringctl.FetchResults(kubeClient, opts, os.Stdout)
+}
+
+func runDataSources(cmd *cobra.Command, args []string) error {
+	_, meteringClient, ns, err := clients()
+	if err != nil {
+		return err
+	}
+	dataSources, err := listDataSources(meteringClient, ns, args)
+	if err != nil {
+		return err
+	}
+	return meteringctl.PrintDataSources(os.Stdout, dataSources, time.Now())
+}
+
+// listDataSources returns the ReportDataSources in the namespace with the
+// names, or every ReportDataSource if there are no names.
+func listDataSources(meteringClient cbClientset.Interface, ns string, names []string) ([]*cbTypes.ReportDataSource, error) {
+	client := meteringClient.MeteringV1alpha1().ReportDataSources(ns)
+	list, err := client.List(meta.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	dataSources := list.Items
+	if len(names) != 0 {
+		byName := make(map[string]bool)
+		for _, name := range names {
+			byName[name] = true
+		}
+		dataSources = nil
+		for _, dataSource := range list.Items {
+			if byName[dataSource.Name] {
+				dataSources = append(dataSources, dataSource)
+				delete(byName, dataSource.Name)
+			}
+		}
+		for name := range byName {
+			return nil, fmt.Errorf("ReportDataSource %s not found in namespace %s", name, ns)
+		}
+	}
+	return dataSources, nil
+}
+
+func runExportDataStore(cmd *cobra.Command, args []string) error {
+	return runDataStore(meteringctl.ExportDataStore, args)
+}
+
+func runImportDataStore(cmd *cobra.Command, args []string) error {
+	return runDataStore(meteringctl.ImportDataStore, args)
+}
+
+func runDataStore(op meteringctl.DataStoreOperation, args []string) error {
+	kubeConfig, ns, err := restConfig()
+	if err != nil {
+		return err
+	}
+	meteringClient, err := cbClientset.NewForConfig(kubeConfig)
+	if err != nil {
+		return fmt.Errorf("unable to create Metering client: %v", err)
+	}
+	dataSources, err := listDataSources(meteringClient, ns, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		var transferable []*cbTypes.ReportDataSource
+		for _, dataSource := range dataSources {
+			if meteringctl.IsDataStoreDataSource(dataSource) {
+				transferable = append(transferable, dataSource)
+			}
+		}
+		dataSources = transferable
+	}
+	meteringNS := meteringNamespace
+	if meteringNS == "" {
+		meteringNS = ns
+	}
+	apiClient, err := meteringctl.NewReportingAPIClient(kubeConfig, meteringNS, useHTTPS)
+	if err != nil {
+		return err
+	}
+	return meteringctl.TransferDataStores(context.Background(), apiClient, op, dataSources, dataStoreLocation, os.Stdout)
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/operator-framework/operator-metering/pkg/backup"
+	"github.com/operator-framework/operator-metering/pkg/db"
+	cbClientset "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/operator"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+var (
+	restoreCfg        backup.Config
+	restoreKubeconfig string
+	restoreHiveHost   string
+	restorePrestoHost string
+	restorePrestoUser string
+	restoreBackupID   string
+	restoreList       bool
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "restores the Metering resources and the tables in the Hive metastore from a backup taken by the reporting-operator",
+	RunE:  runRestore,
+}
+
+func init() {
+	restoreCmd.Flags().StringVar(&restoreKubeconfig, "kubeconfig", "", "use kubeconfig provided instead of detecting defaults")
+	restoreCmd.Flags().StringVar(&restoreHiveHost, "hive-host", defaultHiveHost, "the hostname:port for connecting to Hive")
+	restoreCmd.Flags().StringVar(&restorePrestoHost, "presto-host", defaultPrestoHost, "the hostname:port for connecting to Presto")
+	restoreCmd.Flags().StringVar(&restorePrestoUser, "presto-user", operator.DefaultPrestoUser, "the user to query Presto as")
+	restoreCmd.Flags().StringVar(&restoreCfg.Location, "backup-location", "", "the directory in HDFS or an object store the backups were written to, such as s3a://bucket/metering-backups")
+	restoreCmd.Flags().StringVar(&restoreBackupID, "backup", "", "the ID of the backup to restore, such as 20190102T030405Z. Defaults to the latest backup")
+	restoreCmd.Flags().BoolVar(&restoreList, "list", false, "list the IDs of the backups in --backup-location instead of restoring one")
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	logger := newLogger()
+	if restoreCfg.Location == "" {
+		return fmt.Errorf("--backup-location must be set")
+	}
+	if err := backup.ValidateLocation(restoreCfg.Location); err != nil {
+		return fmt.Errorf("invalid --backup-location: %v", err)
+	}
+	if restoreBackupID != "" {
+		if _, err := backup.ParseID(restoreBackupID); err != nil {
+			return err
+		}
+	}
+
+	configOverrides := &clientcmd.ConfigOverrides{}
+	var clientConfig clientcmd.ClientConfig
+	if restoreKubeconfig == "" {
+		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+		clientConfig = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
+	} else {
+		apiCfg, err := clientcmd.LoadFromFile(restoreKubeconfig)
+		if err != nil {
+			return err
+		}
+		clientConfig = clientcmd.NewDefaultClientConfig(*apiCfg, configOverrides)
+	}
+	kubeConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return fmt.Errorf("unable to get Kubernetes client config: %v", err)
+	}
+	meteringClient, err := cbClientset.NewForConfig(kubeConfig)
+	if err != nil {
+		return fmt.Errorf("unable to create Metering client: %v", err)
+	}
+
+	hiveConn, err := hive.Connect(restoreHiveHost)
+	if err != nil {
+		return fmt.Errorf("unable to connect to hive: %v", err)
+	}
+	defer hiveConn.Close()
+
+	connStr := fmt.Sprintf("http://%s@%s?catalog=hive&schema=default", url.User(restorePrestoUser).String(), restorePrestoHost)
+	prestoConn, err := sql.Open("presto", connStr)
+	if err != nil {
+		return fmt.Errorf("unable to connect to presto: %v", err)
+	}
+	defer prestoConn.Close()
+	queryer := presto.NewDB(db.New(prestoConn, logger, false))
+
+	restorer := backup.NewRestorer(logger, restoreCfg, hiveConn, queryer, meteringClient.MeteringV1alpha1())
+	if restoreList {
+		ids, err := restorer.ListBackups()
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			fmt.Fprintln(os.Stdout, id)
+		}
+		return nil
+	}
+
+	result, err := restorer.Restore(restoreBackupID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "restored backup %s: %d tables, %d views, %d resources created, %d already existed\n", result.BackupID, len(result.Tables), len(result.Views), len(result.Created), len(result.Existing))
+	if len(result.MissingFiles) != 0 {
+		fmt.Fprintf(os.Stdout, "%d files listed in the backup's data manifests are missing:\n", len(result.MissingFiles))
+		for _, file := range result.MissingFiles {
+			fmt.Fprintf(os.Stdout, "  %s\n", file)
+		}
+	}
+	return nil
+}
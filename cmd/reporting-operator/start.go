@@ -16,7 +16,10 @@ import (
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/clock"
 
+	"github.com/operator-framework/operator-metering/pkg/aws"
+	"github.com/operator-framework/operator-metering/pkg/backup"
 	"github.com/operator-framework/operator-metering/pkg/operator"
+	"github.com/operator-framework/operator-metering/pkg/secrets"
 )
 
 var (
@@ -24,12 +27,28 @@ var (
 	defaultPrestoHost    = "presto:8080"
 	defaultPromHost      = "http://prometheus.tectonic-system.svc.cluster.local:9090"
 	defaultLeaseDuration = time.Second * 60
+
+	defaultScheduledReportStaleTolerance = time.Hour
+
+	defaultReportMetricsMaxSeries = 1000
+
+	defaultReportRetryMaxAttempts = 3
+	defaultReportRetryBackoff     = time.Minute
+	defaultReportRetryMaxBackoff  = time.Minute * 30
 	// cfg is the config for our operator
 	cfg operator.Config
 
 	logLevelStr         string
 	logFullTimestamp    bool
 	logDisableTimestamp bool
+
+	remoteClustersStr string
+	queryRolesStr     string
+	prestoSessionsStr string
+
+	monetaryRoundingModeStr string
+
+	rowLevelSecurityMappingsStr string
 )
 
 var rootCmd = &cobra.Command{
@@ -48,6 +67,8 @@ var startCmd = &cobra.Command{
 
 func AddCommands() {
 	rootCmd.AddCommand(startCmd)
+	rootCmd.AddCommand(supportBundleCmd)
+	rootCmd.AddCommand(restoreCmd)
 }
 
 func init() {
@@ -69,20 +90,105 @@ func init() {
 	startCmd.Flags().StringVar(&cfg.PrestoHost, "presto-host", defaultPrestoHost, "the hostname:port for connecting to Presto")
 	startCmd.Flags().StringVar(&cfg.PromHost, "prometheus-host", defaultPromHost, "the URL string for connecting to Prometheus")
 	startCmd.Flags().BoolVar(&cfg.DisablePromsum, "disable-promsum", false, "disables collecting Prometheus metrics periodically")
+	startCmd.Flags().BoolVar(&cfg.PrometheusQueryDryRun, "prometheus-query-dry-run", true, "If true, the query of each Prometheus ReportDataSource is run once when it's created or changed, to check that Prometheus accepts it and that it returns data")
+	startCmd.Flags().BoolVar(&cfg.ReadOnly, "read-only", false, "runs the reporting-operator in read-only mode, serving the results of existing reports from Presto without importing data, running reports or participating in leader election")
 	startCmd.Flags().BoolVar(&cfg.LogDMLQueries, "log-dml-queries", false, "logDMLQueries controls if we log data manipulation queries made via Presto (SELECT, INSERT, etc)")
 	startCmd.Flags().BoolVar(&cfg.LogDDLQueries, "log-ddl-queries", false, "logDDLQueries controls if we log data definition language queries made via Hive (CREATE TABLE, DROP TABLE, etc)")
 	startCmd.Flags().DurationVar(&cfg.PrometheusQueryConfig.QueryInterval.Duration, "promsum-interval", operator.DefaultPrometheusQueryInterval, "controls how often the operator polls Prometheus for metrics")
 	startCmd.Flags().DurationVar(&cfg.PrometheusQueryConfig.StepSize.Duration, "promsum-step-size", operator.DefaultPrometheusQueryStepSize, "the query step size for Promethus query. This controls resolution of results")
 	startCmd.Flags().DurationVar(&cfg.PrometheusQueryConfig.ChunkSize.Duration, "promsum-chunk-size", operator.DefaultPrometheusQueryChunkSize, "controls how much the range query window sizeby limiting the range query to a range of time no longer than this duration")
+	startCmd.Flags().Float64Var(&cfg.PrometheusImportJitterFactor, "promsum-jitter-factor", operator.DefaultPrometheusImportJitterFactor, "each periodic Prometheus import is delayed by a random duration of up to this fraction of its interval, in addition to its fixed offset within the interval, so that imports don't all query Prometheus at the same time. Must be between 0 and 1")
 	startCmd.Flags().DurationVar(&cfg.LeaderLeaseDuration, "lease-duration", defaultLeaseDuration, "controls how much time elapses before declaring leader")
+	startCmd.Flags().IntVar(&cfg.MaxConcurrentReports, "max-concurrent-reports", operator.DefaultMaxConcurrentReports, "the number of Report and ScheduledReport runs which query Presto at the same time. Other runs are queued, and started in order of their deadline and reporting period size, taking turns between namespaces")
+	startCmd.Flags().DurationVar(&cfg.ResyncPeriod, "resync-period", operator.DefaultResyncPeriod, "how often every resource is reconciled again even if it hasn't changed. Resources are reconciled when they or their dependencies change, so this is only a safety net. If 0, resources are never resynced")
+	startCmd.Flags().DurationVar(&cfg.AnalyzeConfig.Interval, "analyze-interval", operator.DefaultAnalyzeInterval, "how often the ReportDataSource, Report and ScheduledReport tables which have had significant writes since they were last analyzed are analyzed, to update the statistics Presto plans queries with. If 0, tables aren't analyzed")
+	startCmd.Flags().IntVar(&cfg.AnalyzeConfig.MinRows, "analyze-min-rows", operator.DefaultAnalyzeMinRows, "how many rows must be imported into a ReportDataSource table before it's analyzed again. Report and ScheduledReport tables are analyzed after every run")
+	startCmd.Flags().DurationVar(&cfg.PartitionArchiveInterval, "partition-archive-interval", operator.DefaultPartitionArchiveInterval, "how often the partitions of ReportDataSources with an archive are checked, and moved to their archive StorageLocation once they're older than the archive's after. If 0, partitions aren't archived")
+	startCmd.Flags().DurationVar(&cfg.DataCompletenessWindow, "data-completeness-window", operator.DefaultDataCompletenessWindow, "how far back the data of each Prometheus and kubernetesObjects ReportDataSource is checked for missing steps, exported as the metering_reportdatasource_data_completeness_ratio metric and in its status. If 0, data completeness isn't checked")
+	startCmd.Flags().DurationVar(&cfg.AnomalyDetectionConfig.Interval, "anomaly-detection-interval", 0, "the size of the buckets the usage of each namespace in each Prometheus ReportDataSource is summed into, and how often the latest bucket is checked for spikes and drops. If 0, anomalies aren't detected")
+	startCmd.Flags().DurationVar(&cfg.AnomalyDetectionConfig.Window, "anomaly-detection-window", operator.DefaultAnomalyDetectionWindow, "how far back the buckets the latest bucket of usage is compared against go")
+	startCmd.Flags().Float64Var(&cfg.AnomalyDetectionConfig.Threshold, "anomaly-detection-threshold", operator.DefaultAnomalyDetectionThreshold, "how many standard deviations from its mean the usage of a namespace must be to be flagged as an anomaly")
+	startCmd.Flags().Float64Var(&cfg.AnomalyDetectionConfig.MinChangePercent, "anomaly-detection-min-change-percent", operator.DefaultAnomalyDetectionMinChangePercent, "how far from its mean, as a percentage of the mean, the usage of a namespace must be to be flagged as an anomaly")
+	startCmd.Flags().StringVar(&monetaryRoundingModeStr, "monetary-rounding-mode", "", "how the values of report columns with the currency unit are rounded, one of halfUp, halfDown, halfEven (banker's rounding), up or down. If empty, monetary values aren't rounded")
+	startCmd.Flags().IntVar(&cfg.MonetaryRoundingConfig.DecimalPlaces, "monetary-decimal-places", operator.DefaultMonetaryDecimalPlaces, "how many decimal places the values of report columns with the currency unit are rounded to")
+	startCmd.Flags().StringVar(&cfg.BackupConfig.Location, "backup-location", "", "the directory in HDFS or an object store the Metering resources and the tables in the Hive metastore are backed up to, such as s3a://bucket/metering-backups. If empty, backups are disabled")
+	startCmd.Flags().DurationVar(&cfg.BackupConfig.Interval, "backup-interval", backup.DefaultInterval, "how often a backup is taken")
+	startCmd.Flags().IntVar(&cfg.BackupConfig.Retention, "backup-retention", backup.DefaultRetention, "the number of backups kept, older backups are deleted after each backup")
+	startCmd.Flags().BoolVar(&cfg.BackupConfig.IncludeDataManifests, "backup-include-data-manifests", false, "If true, backups include a manifest of the files holding each table's data, which restores check are still present. Listing the files reads every table")
+	startCmd.Flags().BoolVar(&cfg.RecoverTables, "recover-tables", false, "If true, the tables of the Metering resources are registered in the Hive metastore again from the files in their StorageLocations on startup, for recovering from the loss of the metastore")
+	startCmd.Flags().IntVar(&cfg.ShardingConfig.Shards, "shards", 1, "the number of shards ReportDataSources are split between, each run as a separate set of reporting-operator replicas which imports only the ReportDataSources it owns")
+	startCmd.Flags().IntVar(&cfg.ShardingConfig.Index, "shard-index", 0, "the shard this reporting-operator belongs to, between 0 and shards-1. Only shard 0 runs reports and handles resources other than ReportDataSources")
+	startCmd.Flags().Int64Var(&cfg.MemoryLimitBytes, "memory-limit", 0, "the reporting-operator's memory limit in bytes, used to recommend memory limit, chunk size and step size changes based on the memory used by the Prometheus importer. If 0, the memory limit is unknown")
+	startCmd.Flags().BoolVar(&cfg.APIAuthConfig.Enabled, "api-auth", false, "If true, authenticates HTTP API requests by validating their bearer token with the TokenReview API, and authorizes them with SubjectAccessReviews against the Metering resources they access")
+	startCmd.Flags().DurationVar(&cfg.APIAuthConfig.CacheTTL, "api-auth-cache-ttl", operator.DefaultAPIAuthCacheTTL, "how long the results of the TokenReviews and SubjectAccessReviews used to authenticate and authorize HTTP API requests are cached")
+	startCmd.Flags().BoolVar(&cfg.APIAuthConfig.RowLevelSecurity.Enabled, "api-row-level-security", false, "If true, only returns the rows of report results in the namespaces the authenticated user can access. Requires --api-auth")
+	startCmd.Flags().StringVar(&cfg.APIAuthConfig.RowLevelSecurity.NamespaceColumn, "api-row-level-security-namespace-column", operator.DefaultRowLevelSecurityNamespaceColumn, "the column of report results containing the namespace of each row. Results without it aren't filtered")
+	startCmd.Flags().StringVar(&cfg.APIAuthConfig.RowLevelSecurity.Verb, "api-row-level-security-verb", operator.DefaultRowLevelSecurityVerb, "the verb a user must be allowed on --api-row-level-security-resource in a namespace to see its rows. If empty, only --api-row-level-security-mappings are used")
+	startCmd.Flags().StringVar(&cfg.APIAuthConfig.RowLevelSecurity.Resource, "api-row-level-security-resource", operator.DefaultRowLevelSecurityResource, "the core API group resource a user must be allowed --api-row-level-security-verb on in a namespace to see its rows")
+	startCmd.Flags().StringVar(&rowLevelSecurityMappingsStr, "api-row-level-security-mappings", "", "a JSON list of mappings granting users and groups access to the rows of namespaces, each with users, groups and namespaces")
+	startCmd.Flags().BoolVar(&cfg.AuditConfig.Enabled, "audit", false, "If true, logs an audit event for each HTTP API request, and each creation, update and deletion of Reports, ScheduledReports and ReportDataSources")
+	startCmd.Flags().BoolVar(&cfg.AuditConfig.StoreInPresto, "audit-store-in-presto", false, "If true, stores audit events in the metering_audit_log Presto table in addition to logging them. Requires --audit")
+	startCmd.Flags().DurationVar(&cfg.AuditConfig.FlushInterval, "audit-flush-interval", operator.DefaultAuditFlushInterval, "how often audit events are stored in Presto")
+	startCmd.Flags().BoolVar(&cfg.EnableTenantNamespaces, "enable-tenant-namespaces", false, "If true, watches Reports, ScheduledReports and ReportGenerationQueries in every namespace, storing the results of those outside the operator's namespace in a schema per namespace, and rendering dataSourceTableName as the data of their own namespace. This is not a security boundary, as queries can still read any table by name")
+	startCmd.Flags().BoolVar(&cfg.EnableBudgetEnforcement, "enable-budget-enforcement", false, "If true, annotates namespaces, scales ResourceQuotas and calls webhooks as configured by the budgets of ScheduledReports when namespaces exceed them. Otherwise, budgets only record the namespaces which exceed them in the ScheduledReport's status")
+	startCmd.Flags().BoolVar(&cfg.EnableFaultInjection, "enable-fault-injection", false, "enables the /api/v1/debug/faults endpoint, which injects faults into the Prometheus importer to test how it recovers from failures. Do not enable in production")
+	startCmd.Flags().DurationVar(&cfg.ScheduledReportStaleTolerance, "scheduled-report-stale-tolerance", defaultScheduledReportStaleTolerance, "controls how long after a ScheduledReport's next period and grace period have elapsed without a successful run before the ScheduledReport is considered stale")
+	startCmd.Flags().IntVar(&cfg.ReportMetricsMaxSeries, "report-metrics-max-series", defaultReportMetricsMaxSeries, "the most series exported for each gauge of a Report or ScheduledReport with metrics set. Reports can set a lower limit")
+	startCmd.Flags().IntVar(&cfg.DefaultReportRetryPolicy.MaxAttempts, "report-retry-max-attempts", defaultReportRetryMaxAttempts, "the default maximum number of times a Report or ScheduledReport run is attempted before it's considered failed")
+	startCmd.Flags().DurationVar(&cfg.DefaultReportRetryPolicy.Backoff, "report-retry-backoff", defaultReportRetryBackoff, "the default time to wait before retrying a failed Report or ScheduledReport run, which doubles after each failed attempt")
+	startCmd.Flags().DurationVar(&cfg.DefaultReportRetryPolicy.MaxBackoff, "report-retry-max-backoff", defaultReportRetryMaxBackoff, "the default maximum time to wait between attempts of a Report or ScheduledReport run")
+	startCmd.Flags().DurationVar(&cfg.DefaultReportRetryPolicy.Deadline, "report-retry-deadline", 0, "the default time after the first attempt of a Report or ScheduledReport run after which it's no longer retried. If 0, there is no deadline")
 
 	startCmd.Flags().BoolVar(&cfg.APITLSConfig.UseTLS, "use-tls", false, "If true, uses TLS to secure HTTP API traffix")
 	startCmd.Flags().StringVar(&cfg.APITLSConfig.TLSCert, "tls-cert", "", "If use-tls is true, specifies the path to the TLS certificate.")
 	startCmd.Flags().StringVar(&cfg.APITLSConfig.TLSKey, "tls-key", "", "If use-tls is true, specifies the path to the TLS private key.")
+	startCmd.Flags().StringVar(&cfg.APIClientCAFile, "api-client-ca", "", "If set, specifies the path to the CA certificates HTTP API client certificates are verified with, rejecting requests without a valid certificate, except for health checks and webhooks. Requires use-tls")
+	startCmd.Flags().DurationVar(&cfg.CertificateReloadInterval, "tls-reload-interval", operator.DefaultCertificateReloadInterval, "how often the HTTP API's TLS certificate and key are reloaded, so rotated certificates are used without restarting")
+
+	startCmd.Flags().BoolVar(&cfg.GRPCConfig.Enabled, "enable-grpc-api", false, "If true, serves the gRPC API on port 8083")
+	startCmd.Flags().BoolVar(&cfg.GRPCConfig.TLSConfig.UseTLS, "grpc-use-tls", false, "If true, uses TLS to secure gRPC API traffic")
+	startCmd.Flags().StringVar(&cfg.GRPCConfig.TLSConfig.TLSCert, "grpc-tls-cert", "", "If grpc-use-tls is true, specifies the path to the TLS certificate to use for the gRPC API.")
+	startCmd.Flags().StringVar(&cfg.GRPCConfig.TLSConfig.TLSKey, "grpc-tls-key", "", "If grpc-use-tls is true, specifies the path to the TLS private key to use for the gRPC API.")
+	startCmd.Flags().StringVar(&cfg.GRPCConfig.ClientCAFile, "grpc-client-ca", "", "If set, specifies the path to the CA certificates gRPC API client certificates are verified with, rejecting clients without a valid certificate. Requires grpc-use-tls")
 
 	startCmd.Flags().BoolVar(&cfg.MetricsTLSConfig.UseTLS, "metrics-use-tls", false, "If true, uses TLS to secure Prometheus Metrics endpoint traffix")
 	startCmd.Flags().StringVar(&cfg.MetricsTLSConfig.TLSCert, "metrics-tls-cert", "", "If metrics-use-tls is true, specifies the path to the TLS certificate to use for the Metrics endpoint.")
 	startCmd.Flags().StringVar(&cfg.MetricsTLSConfig.TLSKey, "metrics-tls-key", "", "If metrics-use-tls is true, specifies the path to the TLS private key to use for the Metrics endpoint.")
+
+	startCmd.Flags().DurationVar(&cfg.SecretsConfig.CacheTTL, "secret-cache-ttl", secrets.DefaultCacheTTL, "controls how long secrets retrieved from secret providers are cached before being retrieved again, allowing rotated credentials to be picked up")
+	startCmd.Flags().BoolVar(&cfg.SecretsConfig.WatchKubernetesSecrets, "watch-kubernetes-secrets", true, "If true, watches the Kubernetes Secrets in the reporting-operator's namespace so that rotated credentials in kubernetes:// secrets are used as soon as they change, rather than once they expire from the cache")
+	startCmd.Flags().StringVar(&cfg.SecretsConfig.VaultConfig.Address, "vault-address", "", "the URL of the Vault server to use for vault:// secret references")
+	startCmd.Flags().StringVar(&cfg.SecretsConfig.VaultConfig.TokenFile, "vault-token-file", "", "the path to a file containing the token used to authenticate with Vault")
+	startCmd.Flags().StringVar(&cfg.SecretsConfig.PrestoCredentials, "presto-credentials-secret", "", "a secret reference (<provider>://<path>) containing the username and password keys used to authenticate with Presto")
+	startCmd.Flags().StringVar(&cfg.SecretsConfig.PrometheusBearerToken, "prometheus-bearer-token-secret", "", "a secret reference (<provider>://<path>) containing the token key used to authenticate with Prometheus")
+	startCmd.Flags().StringVar(&cfg.SecretsConfig.AWSCredentials, "aws-credentials-secret", "", "a secret reference (<provider>://<path>) containing the aws-access-key-id, aws-secret-access-key and optional aws-session-token keys used to access S3")
+	// the defaults are the environment variables set by the EKS pod identity
+	// webhook for IAM roles for service accounts.
+	startCmd.Flags().StringVar(&cfg.AWSWebIdentityConfig.RoleARN, "aws-role-arn", os.Getenv("AWS_ROLE_ARN"), "the ARN of the IAM role to assume with the web identity token to access S3, instead of using static AWS credentials. Can't be used with --aws-credentials-secret")
+	startCmd.Flags().StringVar(&cfg.AWSWebIdentityConfig.TokenFile, "aws-web-identity-token-file", os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"), "the path to the web identity token, such as a projected service account token, used to assume --aws-role-arn")
+	startCmd.Flags().StringVar(&cfg.AWSWebIdentityConfig.SessionName, "aws-role-session-name", aws.DefaultWebIdentitySessionName, "the role session name used when assuming --aws-role-arn")
+	startCmd.Flags().StringVar(&cfg.SecretsConfig.SMTPCredentials, "smtp-credentials-secret", "", "a secret reference (<provider>://<path>) containing the username and password keys used to authenticate with the SMTP server")
+	startCmd.Flags().StringVar(&cfg.NotificationConfig.SMTPAddress, "smtp-address", "", "the host:port of the SMTP server report notification emails are sent through")
+	startCmd.Flags().StringVar(&cfg.NotificationConfig.SMTPFrom, "smtp-from", "", "the address report notification emails are sent from")
+	startCmd.Flags().StringVar(&cfg.NotificationConfig.ResultsURL, "report-results-url", "", "the URL of the reporting-operator's HTTP API as reachable by the recipients of report notifications, used to link to report results")
+	startCmd.Flags().StringVar(&cfg.SecretsConfig.KafkaCredentials, "kafka-credentials-secret", "", "a secret reference (<provider>://<path>) containing the username and password keys used to authenticate with the Kafka brokers using SASL/PLAIN")
+	startCmd.Flags().StringSliceVar(&cfg.KafkaConfig.Brokers, "kafka-brokers", nil, "a comma separated list of host:port Kafka brokers imported metrics and report results are published to. If empty, nothing is published to Kafka")
+	startCmd.Flags().StringVar(&cfg.KafkaConfig.MetricsTopic, "kafka-metrics-topic", "", "the Kafka topic metrics imported from Prometheus are published to. If empty, metrics aren't published")
+	startCmd.Flags().StringVar(&cfg.KafkaConfig.ReportsTopic, "kafka-reports-topic", "", "the Kafka topic each row of the results of Reports and ScheduledReports is published to. If empty, report results aren't published")
+	startCmd.Flags().BoolVar(&cfg.KafkaConfig.UseTLS, "kafka-use-tls", false, "If true, uses TLS to connect to the Kafka brokers")
+	startCmd.Flags().StringVar(&cfg.ClusterID, "cluster-id", "", "identifies this cluster in the cluster_id label of the metrics it imports, required when importing from remote clusters")
+	startCmd.Flags().StringVar(&remoteClustersStr, "remote-clusters", "", "a JSON list of other clusters to import Prometheus metrics from, each with an id, prometheusURL and optional prometheusBearerTokenSecret")
+	startCmd.Flags().StringVar(&queryRolesStr, "query-roles", "", "a JSON list of roles limiting the ad-hoc query API, each with a name, users, maxRows and timeout. Callers in no role use the role named default")
+	startCmd.Flags().DurationVar(&cfg.QueryConfig.Cache.TTL, "query-cache-ttl", operator.DefaultQueryCacheTTL, "how long the results of report results and ad-hoc query API requests are cached for. Report results are also invalidated when the report runs again. If 0, results aren't cached")
+	startCmd.Flags().IntVar(&cfg.QueryConfig.Cache.MaxRows, "query-cache-max-rows", operator.DefaultQueryCacheMaxRows, "the most rows cached across all cached API results. The least recently used results are evicted first")
+	startCmd.Flags().BoolVar(&cfg.QueryConfig.TrustForwardedUser, "query-trust-forwarded-user", false, "If true, matches ad-hoc query API callers to query roles using the X-Forwarded-User header. Only set this when the API is only reachable through the auth proxy")
+	startCmd.Flags().StringVar(&cfg.ComponentIdentities.Importer.PrestoUser, "presto-importer-user", operator.DefaultPrestoUser, "the user the Prometheus importer queries Presto as")
+	startCmd.Flags().StringVar(&cfg.ComponentIdentities.Importer.PrestoCredentials, "presto-importer-credentials-secret", "", "a secret reference (<provider>://<path>) containing the username and password keys the Prometheus importer uses to authenticate with Presto, overriding --presto-credentials-secret")
+	startCmd.Flags().StringVar(&cfg.ComponentIdentities.Reporting.PrestoUser, "presto-reporting-user", operator.DefaultPrestoUser, "the user the report runner queries Presto as")
+	startCmd.Flags().StringVar(&cfg.ComponentIdentities.Reporting.PrestoCredentials, "presto-reporting-credentials-secret", "", "a secret reference (<provider>://<path>) containing the username and password keys the report runner uses to authenticate with Presto, overriding --presto-credentials-secret")
+	startCmd.Flags().StringVar(&cfg.ComponentIdentities.API.PrestoUser, "presto-api-user", operator.DefaultPrestoUser, "the user the HTTP API queries Presto as")
+	startCmd.Flags().StringVar(&cfg.ComponentIdentities.API.PrestoCredentials, "presto-api-credentials-secret", "", "a secret reference (<provider>://<path>) containing the username and password keys the HTTP API uses to authenticate with Presto, overriding --presto-credentials-secret")
+	startCmd.Flags().StringVar(&prestoSessionsStr, "presto-sessions", "", "a JSON object configuring the Presto session of the queries of the importer, reporting and api components, each with a source, which Presto resource group selectors can match, defaulting to metering-<component>, and session properties, such as query_max_memory and query_priority")
 }
 
 func main() {
@@ -96,8 +202,10 @@ func main() {
 
 	AddCommands()
 
-	if err := SetFlagsFromEnv(startCmd.Flags(), "CHARGEBACK"); err != nil {
-		log.WithError(err).Fatalf("error setting flags from environment variables: %v", err)
+	for _, cmd := range []*cobra.Command{startCmd, supportBundleCmd, restoreCmd} {
+		if err := SetFlagsFromEnv(cmd.Flags(), "CHARGEBACK"); err != nil {
+			log.WithError(err).Fatalf("error setting flags from environment variables: %v", err)
+		}
 	}
 	if err := rootCmd.Execute(); err != nil {
 		log.WithError(err).Fatalf("error executing command: %v", err)
@@ -122,6 +230,24 @@ func startChargeback(cmd *cobra.Command, args []string) {
 		logger.Fatalf("unable to get hostname, err: %s", err)
 	}
 
+	cfg.MonetaryRoundingConfig.Mode = operator.MonetaryRoundingMode(monetaryRoundingModeStr)
+	cfg.RemoteClusters, err = operator.ParseRemoteClusters(remoteClustersStr)
+	if err != nil {
+		logger.WithError(err).Fatal("invalid --remote-clusters")
+	}
+	cfg.QueryConfig.Roles, err = operator.ParseQueryRoles(queryRolesStr)
+	if err != nil {
+		logger.WithError(err).Fatal("invalid --query-roles")
+	}
+	cfg.PrestoSessions, err = operator.ParsePrestoSessions(prestoSessionsStr)
+	if err != nil {
+		logger.WithError(err).Fatal("invalid --presto-sessions")
+	}
+	cfg.APIAuthConfig.RowLevelSecurity.Mappings, err = operator.ParseNamespaceMappings(rowLevelSecurityMappingsStr)
+	if err != nil {
+		logger.WithError(err).Fatal("invalid --api-row-level-security-mappings")
+	}
+
 	signalStopCh := setupSignals()
 	runChargeback(logger, cfg, signalStopCh)
 }
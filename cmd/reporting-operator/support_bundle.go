@@ -0,0 +1,115 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/clock"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/operator-framework/operator-metering/pkg/db"
+	cbClientset "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned"
+	"github.com/operator-framework/operator-metering/pkg/operator"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/supportbundle"
+)
+
+var (
+	supportBundleCfg        supportbundle.Config
+	supportBundleKubeconfig string
+	supportBundlePrestoHost string
+	supportBundlePrestoUser string
+	supportBundleOutput     string
+)
+
+var supportBundleCmd = &cobra.Command{
+	Use:   "support-bundle",
+	Short: "exports the configuration, ReportDataSource statuses, import ledgers and table schemas of a Metering installation into an archive for debugging",
+	RunE:  runSupportBundle,
+}
+
+func init() {
+	supportBundleCmd.Flags().StringVar(&supportBundleKubeconfig, "kubeconfig", "", "use kubeconfig provided instead of detecting defaults")
+	supportBundleCmd.Flags().StringVar(&supportBundleCfg.Namespace, "namespace", "", "namespace Metering is installed in. Defaults to the namespace of the pod when run inside the cluster")
+	supportBundleCmd.Flags().StringVar(&supportBundlePrestoHost, "presto-host", defaultPrestoHost, "the hostname:port for connecting to Presto")
+	supportBundleCmd.Flags().StringVar(&supportBundlePrestoUser, "presto-user", operator.DefaultPrestoUser, "the user to query Presto as")
+	supportBundleCmd.Flags().StringVar(&supportBundleOutput, "output", "", "the path to write the support bundle to. If -, the support bundle is written to stdout. Defaults to metering-support-bundle-<timestamp>.tar.gz in the current directory")
+	supportBundleCmd.Flags().DurationVar(&supportBundleCfg.ImportHistory, "import-history", supportbundle.DefaultImportHistory, "how far back to include the metrics imported by each Prometheus ReportDataSource")
+	supportBundleCmd.Flags().BoolVar(&supportBundleCfg.Anonymize, "anonymize", true, "replace the names of ReportDataSources and tables not installed by Metering with pseudonyms, and redact hosts, URLs and S3 and GCS locations")
+}
+
+func runSupportBundle(cmd *cobra.Command, args []string) error {
+	logger := newLogger()
+	if supportBundleCfg.Namespace == "" {
+		namespace, err := ioutil.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace")
+		if err != nil {
+			return fmt.Errorf("--namespace must be set when not running inside the cluster")
+		}
+		supportBundleCfg.Namespace = string(namespace)
+	}
+
+	configOverrides := &clientcmd.ConfigOverrides{}
+	var clientConfig clientcmd.ClientConfig
+	if supportBundleKubeconfig == "" {
+		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+		clientConfig = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
+	} else {
+		apiCfg, err := clientcmd.LoadFromFile(supportBundleKubeconfig)
+		if err != nil {
+			return err
+		}
+		clientConfig = clientcmd.NewDefaultClientConfig(*apiCfg, configOverrides)
+	}
+	kubeConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return fmt.Errorf("unable to get Kubernetes client config: %v", err)
+	}
+	kubeClient, err := corev1.NewForConfig(kubeConfig)
+	if err != nil {
+		return fmt.Errorf("unable to create Kubernetes client: %v", err)
+	}
+	meteringClient, err := cbClientset.NewForConfig(kubeConfig)
+	if err != nil {
+		return fmt.Errorf("unable to create Metering client: %v", err)
+	}
+
+	connStr := fmt.Sprintf("http://%s@%s?catalog=hive&schema=default", url.User(supportBundlePrestoUser).String(), supportBundlePrestoHost)
+	prestoConn, err := sql.Open("presto", connStr)
+	if err != nil {
+		return fmt.Errorf("unable to connect to presto: %v", err)
+	}
+	defer prestoConn.Close()
+	queryer := presto.NewDB(db.New(prestoConn, logger, false))
+
+	generator, err := supportbundle.NewGenerator(logger, supportBundleCfg, clock.RealClock{}, kubeClient, meteringClient.MeteringV1alpha1(), queryer)
+	if err != nil {
+		return err
+	}
+
+	output := supportBundleOutput
+	if output == "-" {
+		return generator.Write(os.Stdout)
+	}
+	if output == "" {
+		output = fmt.Sprintf("metering-support-bundle-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z"))
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := generator.Write(f); err != nil {
+		return fmt.Errorf("unable to write support bundle: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	logger.Infof("wrote support bundle to %s", output)
+	return nil
+}
@@ -0,0 +1,34 @@
+// reportingapi-gen writes the OpenAPI specification of the
+// reporting-operator's HTTP API, and the Go client generated from it.
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+
+	"github.com/operator-framework/operator-metering/pkg/openapi"
+	"github.com/operator-framework/operator-metering/pkg/operator"
+)
+
+func main() {
+	specPath := flag.String("spec", "Documentation/openapi.json", "path to write the OpenAPI specification to")
+	clientPath := flag.String("client", "pkg/client/reportingapi/zz_generated.client.go", "path to write the generated Go client to")
+	flag.Parse()
+
+	spec, err := operator.OpenAPISpec()
+	if err != nil {
+		log.Fatalf("unable to encode OpenAPI specification: %v", err)
+	}
+	if err := ioutil.WriteFile(*specPath, spec, 0644); err != nil {
+		log.Fatal(err)
+	}
+
+	client, err := openapi.GenerateGoClient(operator.NewOpenAPIDocument(), "reportingapi")
+	if err != nil {
+		log.Fatalf("unable to generate client: %v", err)
+	}
+	if err := ioutil.WriteFile(*clientPath, client, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -27,7 +27,7 @@ set -x
 # generate kubernetes client
 ${CODEGEN_PKG}/generate-groups.sh "deepcopy,client,informer,lister" \
     "${SCRIPT_PACKAGE}/pkg/generated" "${SCRIPT_PACKAGE}/pkg/apis" \
-    metering:v1alpha1 \
+    metering:v1alpha1,v1 \
     --go-header-file ${SCRIPT_ROOT}/hack/boilerplate.go.txt
 
 # generate-groups doesn't do defaulters
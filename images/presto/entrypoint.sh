@@ -69,9 +69,15 @@ echo "-Xmx${MAX_HEAPSIZE}M" >> "${PRESTO_HOME}/etc/jvm.config"
 
 # Presto
 configure "${PRESTO_HOME}/etc/catalog/hive.properties" hive-catalog HIVE_CATALOG
+# The catalogs of external databases are configured from the
+# PRESTO_CATALOG_<name>_ variables of each catalog in PRESTO_CATALOGS.
+for catalog in ${PRESTO_CATALOGS}; do
+  configure "${PRESTO_HOME}/etc/catalog/${catalog}.properties" "${catalog}-catalog" "PRESTO_CATALOG_${catalog}"
+done
 configure "${PRESTO_HOME}/etc/config.properties" presto-conf PRESTO_CONF
 configure "${PRESTO_HOME}/etc/log.properties" presto-log PRESTO_LOG
 configure "${PRESTO_HOME}/etc/node.properties" presto-node PRESTO_NODE
+configure "${PRESTO_HOME}/etc/core-site.xml" core CORE_CONF
 
 # add UID to /etc/passwd if missing
 if ! whoami &> /dev/null; then
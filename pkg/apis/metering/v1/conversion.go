@@ -0,0 +1,311 @@
+package v1
+
+import (
+	"fmt"
+	"time"
+
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+// RetentionAnnotation stores the v1 retention field on v1alpha1 objects,
+// which have no retention field, so that it isn't lost when objects are
+// converted to v1alpha1, the version they're stored as.
+const RetentionAnnotation = "metering.openshift.io/retention"
+
+// ReportDataSourceFromV1alpha1 converts a v1alpha1 ReportDataSource to v1.
+func ReportDataSourceFromV1alpha1(in *v1alpha1.ReportDataSource) (*ReportDataSource, error) {
+	out := &ReportDataSource{
+		TypeMeta: meta.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "ReportDataSource"},
+		Spec: ReportDataSourceSpec{
+			Promsum:           in.Spec.Promsum.DeepCopy(),
+			AWSBilling:        in.Spec.AWSBilling.DeepCopy(),
+			GCPBilling:        in.Spec.GCPBilling.DeepCopy(),
+			KubernetesObjects: in.Spec.KubernetesObjects.DeepCopy(),
+			RemoteReport:      in.Spec.RemoteReport.DeepCopy(),
+			ObjectStorage:     in.Spec.ObjectStorage.DeepCopy(),
+			Database:          in.Spec.Database.DeepCopy(),
+			KubernetesEvents:  in.Spec.KubernetesEvents.DeepCopy(),
+		},
+		Status: ReportDataSourceStatus{
+			TableName:        in.TableName,
+			Conditions:       copyDataSourceConditions(in.Conditions),
+			DataCompleteness: in.DataCompleteness.DeepCopy(),
+		},
+	}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+
+	var err error
+	out.Spec.Retention, err = retentionFromAnnotations(&out.ObjectMeta)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ReportDataSource %s: %v", in.Name, err)
+	}
+	return out, nil
+}
+
+// ReportDataSourceToV1alpha1 converts a v1 ReportDataSource to v1alpha1.
+func ReportDataSourceToV1alpha1(in *ReportDataSource) *v1alpha1.ReportDataSource {
+	out := &v1alpha1.ReportDataSource{
+		TypeMeta: meta.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "ReportDataSource"},
+		Spec: v1alpha1.ReportDataSourceSpec{
+			Promsum:           in.Spec.Promsum.DeepCopy(),
+			AWSBilling:        in.Spec.AWSBilling.DeepCopy(),
+			GCPBilling:        in.Spec.GCPBilling.DeepCopy(),
+			KubernetesObjects: in.Spec.KubernetesObjects.DeepCopy(),
+			RemoteReport:      in.Spec.RemoteReport.DeepCopy(),
+			ObjectStorage:     in.Spec.ObjectStorage.DeepCopy(),
+			Database:          in.Spec.Database.DeepCopy(),
+			KubernetesEvents:  in.Spec.KubernetesEvents.DeepCopy(),
+		},
+		TableName:        in.Status.TableName,
+		Conditions:       copyDataSourceConditions(in.Status.Conditions),
+		DataCompleteness: in.Status.DataCompleteness.DeepCopy(),
+	}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	retentionToAnnotations(&out.ObjectMeta, in.Spec.Retention)
+	return out
+}
+
+// ReportFromV1alpha1 converts a v1alpha1 Report to v1.
+func ReportFromV1alpha1(in *v1alpha1.Report) (*Report, error) {
+	out := &Report{
+		TypeMeta: meta.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "Report"},
+		Spec: ReportSpec{
+			ReportingStart:        in.Spec.ReportingStart,
+			ReportingEnd:          in.Spec.ReportingEnd,
+			QueryName:             in.Spec.GenerationQueryName,
+			Timezone:              in.Spec.Timezone,
+			RunImmediately:        in.Spec.RunImmediately,
+			GracePeriod:           in.Spec.GracePeriod.DeepCopy(),
+			Output:                in.Spec.Output.DeepCopy(),
+			CheckpointInterval:    in.Spec.CheckpointInterval.DeepCopy(),
+			RetryPolicy:           in.Spec.RetryPolicy.DeepCopy(),
+			ActiveDeadlineSeconds: copyInt64Ptr(in.Spec.ActiveDeadlineSeconds),
+			Inputs:                copyInputValues(in.Spec.Inputs),
+			GroupByLabels:         copyStrings(in.Spec.GroupByLabels),
+			CostAllocation:        in.Spec.CostAllocation.DeepCopy(),
+			FanOut:                in.Spec.FanOut.DeepCopy(),
+			Deliveries:            copyDeliveries(in.Spec.Deliveries),
+			Notifications:         copyNotifications(in.Spec.Notifications),
+			Metrics:               in.Spec.Metrics.DeepCopy(),
+			Assertions:            copyAssertions(in.Spec.Assertions),
+			Versioned:             in.Spec.Versioned,
+			Amendments:            in.Spec.Amendments.DeepCopy(),
+			Lineage:               in.Spec.Lineage,
+		},
+	}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+
+	var err error
+	out.Spec.Retention, err = retentionFromAnnotations(&out.ObjectMeta)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Report %s: %v", in.Name, err)
+	}
+	return out, nil
+}
+
+// ReportToV1alpha1 converts a v1 Report to v1alpha1.
+func ReportToV1alpha1(in *Report) *v1alpha1.Report {
+	out := &v1alpha1.Report{
+		TypeMeta: meta.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "Report"},
+		Spec: v1alpha1.ReportSpec{
+			ReportingStart:        in.Spec.ReportingStart,
+			ReportingEnd:          in.Spec.ReportingEnd,
+			GenerationQueryName:   in.Spec.QueryName,
+			Timezone:              in.Spec.Timezone,
+			RunImmediately:        in.Spec.RunImmediately,
+			GracePeriod:           in.Spec.GracePeriod.DeepCopy(),
+			Output:                in.Spec.Output.DeepCopy(),
+			CheckpointInterval:    in.Spec.CheckpointInterval.DeepCopy(),
+			RetryPolicy:           in.Spec.RetryPolicy.DeepCopy(),
+			ActiveDeadlineSeconds: copyInt64Ptr(in.Spec.ActiveDeadlineSeconds),
+			Inputs:                copyInputValues(in.Spec.Inputs),
+			GroupByLabels:         copyStrings(in.Spec.GroupByLabels),
+			CostAllocation:        in.Spec.CostAllocation.DeepCopy(),
+			FanOut:                in.Spec.FanOut.DeepCopy(),
+			Deliveries:            copyDeliveries(in.Spec.Deliveries),
+			Notifications:         copyNotifications(in.Spec.Notifications),
+			Metrics:               in.Spec.Metrics.DeepCopy(),
+			Assertions:            copyAssertions(in.Spec.Assertions),
+			Versioned:             in.Spec.Versioned,
+			Amendments:            in.Spec.Amendments.DeepCopy(),
+			Lineage:               in.Spec.Lineage,
+		},
+	}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+	retentionToAnnotations(&out.ObjectMeta, in.Spec.Retention)
+	return out
+}
+
+// ScheduledReportFromV1alpha1 converts a v1alpha1 ScheduledReport to v1.
+func ScheduledReportFromV1alpha1(in *v1alpha1.ScheduledReport) (*ScheduledReport, error) {
+	out := &ScheduledReport{
+		TypeMeta: meta.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "ScheduledReport"},
+		Spec: ScheduledReportSpec{
+			QueryName:             in.Spec.GenerationQueryName,
+			Timezone:              in.Spec.Timezone,
+			GracePeriod:           in.Spec.GracePeriod.DeepCopy(),
+			OverwriteExistingData: in.Spec.OverwriteExistingData,
+			Window:                in.Spec.Window.DeepCopy(),
+			CheckpointInterval:    in.Spec.CheckpointInterval.DeepCopy(),
+			Output:                in.Spec.Output.DeepCopy(),
+			RetryPolicy:           in.Spec.RetryPolicy.DeepCopy(),
+			ActiveDeadlineSeconds: copyInt64Ptr(in.Spec.ActiveDeadlineSeconds),
+			Inputs:                copyInputValues(in.Spec.Inputs),
+			GroupByLabels:         copyStrings(in.Spec.GroupByLabels),
+			CostAllocation:        in.Spec.CostAllocation.DeepCopy(),
+			Deliveries:            copyDeliveries(in.Spec.Deliveries),
+			Notifications:         copyNotifications(in.Spec.Notifications),
+			Metrics:               in.Spec.Metrics.DeepCopy(),
+			Assertions:            copyAssertions(in.Spec.Assertions),
+			Budget:                in.Spec.Budget.DeepCopy(),
+			Versioned:             in.Spec.Versioned,
+			Amendments:            in.Spec.Amendments.DeepCopy(),
+			Lineage:               in.Spec.Lineage,
+		},
+	}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.Schedule.DeepCopyInto(&out.Spec.Schedule)
+	in.Status.DeepCopyInto(&out.Status)
+
+	var err error
+	out.Spec.Retention, err = retentionFromAnnotations(&out.ObjectMeta)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ScheduledReport %s: %v", in.Name, err)
+	}
+	return out, nil
+}
+
+// ScheduledReportToV1alpha1 converts a v1 ScheduledReport to v1alpha1.
+func ScheduledReportToV1alpha1(in *ScheduledReport) *v1alpha1.ScheduledReport {
+	out := &v1alpha1.ScheduledReport{
+		TypeMeta: meta.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "ScheduledReport"},
+		Spec: v1alpha1.ScheduledReportSpec{
+			GenerationQueryName:   in.Spec.QueryName,
+			Timezone:              in.Spec.Timezone,
+			GracePeriod:           in.Spec.GracePeriod.DeepCopy(),
+			OverwriteExistingData: in.Spec.OverwriteExistingData,
+			Window:                in.Spec.Window.DeepCopy(),
+			CheckpointInterval:    in.Spec.CheckpointInterval.DeepCopy(),
+			Output:                in.Spec.Output.DeepCopy(),
+			RetryPolicy:           in.Spec.RetryPolicy.DeepCopy(),
+			ActiveDeadlineSeconds: copyInt64Ptr(in.Spec.ActiveDeadlineSeconds),
+			Inputs:                copyInputValues(in.Spec.Inputs),
+			GroupByLabels:         copyStrings(in.Spec.GroupByLabels),
+			CostAllocation:        in.Spec.CostAllocation.DeepCopy(),
+			Deliveries:            copyDeliveries(in.Spec.Deliveries),
+			Notifications:         copyNotifications(in.Spec.Notifications),
+			Metrics:               in.Spec.Metrics.DeepCopy(),
+			Assertions:            copyAssertions(in.Spec.Assertions),
+			Budget:                in.Spec.Budget.DeepCopy(),
+			Versioned:             in.Spec.Versioned,
+			Amendments:            in.Spec.Amendments.DeepCopy(),
+			Lineage:               in.Spec.Lineage,
+		},
+	}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.Schedule.DeepCopyInto(&out.Spec.Schedule)
+	in.Status.DeepCopyInto(&out.Status)
+	retentionToAnnotations(&out.ObjectMeta, in.Spec.Retention)
+	return out
+}
+
+// retentionFromAnnotations removes the RetentionAnnotation from obj,
+// returning its value.
+func retentionFromAnnotations(obj *meta.ObjectMeta) (*meta.Duration, error) {
+	value, ok := obj.Annotations[RetentionAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	delete(obj.Annotations, RetentionAnnotation)
+	if len(obj.Annotations) == 0 {
+		obj.Annotations = nil
+	}
+	retention, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", RetentionAnnotation, err)
+	}
+	return &meta.Duration{Duration: retention}, nil
+}
+
+// retentionToAnnotations sets the RetentionAnnotation on obj if retention is
+// set.
+func retentionToAnnotations(obj *meta.ObjectMeta, retention *meta.Duration) {
+	if retention == nil {
+		delete(obj.Annotations, RetentionAnnotation)
+		return
+	}
+	if obj.Annotations == nil {
+		obj.Annotations = make(map[string]string)
+	}
+	obj.Annotations[RetentionAnnotation] = retention.Duration.String()
+}
+
+func copyInt64Ptr(in *int64) *int64 {
+	if in == nil {
+		return nil
+	}
+	out := *in
+	return &out
+}
+
+func copyInputValues(in []v1alpha1.ReportGenerationQueryInputValue) []v1alpha1.ReportGenerationQueryInputValue {
+	if in == nil {
+		return nil
+	}
+	return append([]v1alpha1.ReportGenerationQueryInputValue(nil), in...)
+}
+
+func copyStrings(in []string) []string {
+	if in == nil {
+		return nil
+	}
+	return append([]string(nil), in...)
+}
+
+func copyDeliveries(in []v1alpha1.ReportDelivery) []v1alpha1.ReportDelivery {
+	if in == nil {
+		return nil
+	}
+	out := make([]v1alpha1.ReportDelivery, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
+
+func copyNotifications(in []v1alpha1.ReportNotification) []v1alpha1.ReportNotification {
+	if in == nil {
+		return nil
+	}
+	out := make([]v1alpha1.ReportNotification, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
+
+func copyAssertions(in []v1alpha1.ReportAssertion) []v1alpha1.ReportAssertion {
+	if in == nil {
+		return nil
+	}
+	out := make([]v1alpha1.ReportAssertion, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
+
+func copyDataSourceConditions(in []v1alpha1.ReportDataSourceCondition) []v1alpha1.ReportDataSourceCondition {
+	if in == nil {
+		return nil
+	}
+	out := make([]v1alpha1.ReportDataSourceCondition, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
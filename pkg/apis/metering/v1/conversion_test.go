@@ -0,0 +1,212 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestReportDataSourceConversionRoundTrip(t *testing.T) {
+	in := &ReportDataSource{
+		TypeMeta: meta.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "ReportDataSource"},
+		ObjectMeta: meta.ObjectMeta{
+			Name:        "pod-request-cpu-cores",
+			Annotations: map[string]string{"example": "value"},
+		},
+		Spec: ReportDataSourceSpec{
+			Promsum: &v1alpha1.PrometheusMetricsDataSource{
+				Query: "pod-request-cpu-cores",
+			},
+			Retention: &meta.Duration{Duration: 90 * 24 * time.Hour},
+		},
+		Status: ReportDataSourceStatus{
+			TableName: "datasource_pod_request_cpu_cores",
+			Conditions: []v1alpha1.ReportDataSourceCondition{
+				{Type: v1alpha1.ReportDataSourceDegraded, Status: corev1.ConditionTrue, Reason: "ValidationFailed"},
+			},
+			DataCompleteness: &v1alpha1.ReportDataSourceDataCompleteness{Ratio: 0.5, ExpectedSteps: 60, PresentSteps: 30},
+		},
+	}
+
+	v1alpha1DataSource := ReportDataSourceToV1alpha1(in)
+	assert.Equal(t, "datasource_pod_request_cpu_cores", v1alpha1DataSource.TableName)
+	assert.Equal(t, "2160h0m0s", v1alpha1DataSource.Annotations[RetentionAnnotation])
+
+	out, err := ReportDataSourceFromV1alpha1(v1alpha1DataSource)
+	require.NoError(t, err)
+	assert.Equal(t, in, out)
+}
+
+func TestReportConversionRoundTrip(t *testing.T) {
+	tests := map[string]struct {
+		report *Report
+	}{
+		"with retention": {
+			report: &Report{
+				TypeMeta:   meta.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "Report"},
+				ObjectMeta: meta.ObjectMeta{Name: "namespace-cpu-request"},
+				Spec: ReportSpec{
+					QueryName: "namespace-cpu-request",
+					Retention: &meta.Duration{Duration: 24 * time.Hour},
+				},
+				Status: v1alpha1.ReportStatus{Phase: v1alpha1.ReportPhaseFinished},
+			},
+		},
+		"without retention": {
+			report: &Report{
+				TypeMeta:   meta.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "Report"},
+				ObjectMeta: meta.ObjectMeta{Name: "namespace-cpu-request"},
+				Spec: ReportSpec{
+					QueryName:      "namespace-cpu-request",
+					RunImmediately: true,
+				},
+			},
+		},
+		"with retry policy": {
+			report: &Report{
+				TypeMeta:   meta.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "Report"},
+				ObjectMeta: meta.ObjectMeta{Name: "namespace-cpu-request"},
+				Spec: ReportSpec{
+					QueryName: "namespace-cpu-request",
+					RetryPolicy: &v1alpha1.ReportRetryPolicy{
+						MaxAttempts: 5,
+						Backoff:     &meta.Duration{Duration: time.Minute},
+					},
+				},
+				Status: v1alpha1.ReportStatus{
+					Phase: v1alpha1.ReportPhaseError,
+					Attempts: []v1alpha1.ReportAttempt{
+						{Error: "presto is unavailable"},
+					},
+				},
+			},
+		},
+		"with inputs": {
+			report: &Report{
+				TypeMeta:   meta.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "Report"},
+				ObjectMeta: meta.ObjectMeta{Name: "namespace-cpu-request"},
+				Spec: ReportSpec{
+					QueryName: "namespace-cpu-request",
+					Inputs: []v1alpha1.ReportGenerationQueryInputValue{
+						{Name: "namespace", Value: "kube-system"},
+					},
+				},
+			},
+		},
+		"with deliveries": {
+			report: &Report{
+				TypeMeta:   meta.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "Report"},
+				ObjectMeta: meta.ObjectMeta{Name: "namespace-cpu-request"},
+				Spec: ReportSpec{
+					QueryName: "namespace-cpu-request",
+					Deliveries: []v1alpha1.ReportDelivery{
+						{
+							Name:   "finance",
+							Format: "parquet",
+							S3:     &v1alpha1.S3DeliveryDestination{S3Bucket: v1alpha1.S3Bucket{Bucket: "finance-reports"}},
+						},
+					},
+					Notifications: []v1alpha1.ReportNotification{
+						{
+							Name:          "finance",
+							CostThreshold: &v1alpha1.ReportCostThreshold{Column: "total_cost", Amount: 1000},
+							Email:         &v1alpha1.EmailNotification{To: []string{"finance@example.com"}},
+						},
+					},
+				},
+				Status: v1alpha1.ReportStatus{
+					Phase: v1alpha1.ReportPhaseFinished,
+					Deliveries: []v1alpha1.ReportDeliveryStatus{
+						{Name: "finance", URL: "s3://finance-reports/namespace-cpu-request.parquet"},
+					},
+				},
+			},
+		},
+		"with groupByLabels": {
+			report: &Report{
+				TypeMeta:   meta.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "Report"},
+				ObjectMeta: meta.ObjectMeta{Name: "label-cpu-request"},
+				Spec: ReportSpec{
+					QueryName:     "label-cpu-request",
+					GroupByLabels: []string{"team", "cost-center"},
+				},
+			},
+		},
+		"with metrics": {
+			report: &Report{
+				TypeMeta:   meta.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "Report"},
+				ObjectMeta: meta.ObjectMeta{Name: "namespace-cpu-request"},
+				Spec: ReportSpec{
+					QueryName: "namespace-cpu-request",
+					Metrics: &v1alpha1.ReportMetrics{
+						Gauges: []v1alpha1.ReportMetricGauge{{Column: "pod_request_cpu_core_seconds"}},
+						Labels: []v1alpha1.ReportMetricLabel{{Column: "namespace"}},
+					},
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			v1alpha1Report := ReportToV1alpha1(tt.report)
+			assert.Equal(t, tt.report.Spec.QueryName, v1alpha1Report.Spec.GenerationQueryName)
+
+			out, err := ReportFromV1alpha1(v1alpha1Report)
+			require.NoError(t, err)
+			assert.Equal(t, tt.report, out)
+		})
+	}
+}
+
+func TestScheduledReportConversionRoundTrip(t *testing.T) {
+	dayOfMonth := int64(1)
+	in := &ScheduledReport{
+		TypeMeta:   meta.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "ScheduledReport"},
+		ObjectMeta: meta.ObjectMeta{Name: "namespace-cpu-request-monthly"},
+		Spec: ScheduledReportSpec{
+			QueryName: "namespace-cpu-request",
+			Schedule: v1alpha1.ScheduledReportSchedule{
+				Period:  v1alpha1.ScheduledReportPeriodMonthly,
+				Monthly: &v1alpha1.ScheduledReportScheduleMonthly{DayOfMonth: &dayOfMonth},
+			},
+			Timezone:    "America/New_York",
+			GracePeriod: &meta.Duration{Duration: 5 * time.Minute},
+			Retention:   &meta.Duration{Duration: 365 * 24 * time.Hour},
+			Metrics: &v1alpha1.ReportMetrics{
+				Gauges: []v1alpha1.ReportMetricGauge{{Column: "pod_request_cpu_core_seconds"}},
+			},
+		},
+		Status: v1alpha1.ScheduledReportStatus{
+			Conditions: []v1alpha1.ScheduledReportCondition{
+				{Type: v1alpha1.ScheduledReportRunning, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+
+	v1alpha1Report := ScheduledReportToV1alpha1(in)
+	assert.Equal(t, "namespace-cpu-request", v1alpha1Report.Spec.GenerationQueryName)
+	assert.Equal(t, "8760h0m0s", v1alpha1Report.Annotations[RetentionAnnotation])
+
+	out, err := ScheduledReportFromV1alpha1(v1alpha1Report)
+	require.NoError(t, err)
+	assert.Equal(t, in, out)
+}
+
+func TestReportFromV1alpha1InvalidRetention(t *testing.T) {
+	_, err := ReportFromV1alpha1(&v1alpha1.Report{
+		ObjectMeta: meta.ObjectMeta{
+			Name:        "invalid",
+			Annotations: map[string]string{RetentionAnnotation: "forever"},
+		},
+	})
+	assert.Error(t, err)
+}
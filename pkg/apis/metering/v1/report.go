@@ -0,0 +1,128 @@
+package v1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ReportList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+	Items         []*Report `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Report struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ReportSpec            `json:"spec"`
+	Status v1alpha1.ReportStatus `json:"status"`
+}
+
+type ReportSpec struct {
+	// ReportingStart is the beginning period of time that the report will be based on.
+	ReportingStart meta.Time `json:"reportingStart"`
+
+	// ReportingEnd is the end period of time that the report will be based on.
+	ReportingEnd meta.Time `json:"reportingEnd"`
+
+	// QueryName is the name of the ReportGenerationQuery the report runs.
+	// In v1alpha1 this is the generationQuery field.
+	QueryName string `json:"query"`
+
+	// Timezone is the IANA timezone name, such as America/New_York, which
+	// is made available to the ReportGenerationQuery so that timestamps
+	// can be converted to local time, for example to group usage by day.
+	// Defaults to UTC.
+	Timezone string `json:"timezone,omitempty"`
+
+	// RunImmediately will run the report immediately, ignoring ReportingEnd and
+	// GracePeriod.
+	RunImmediately bool `json:"runImmediately,omitempty"`
+
+	// GracePeriod controls how long after `ReportingEnd` to wait until running
+	// the report
+	GracePeriod *meta.Duration `json:"gracePeriod,omitempty"`
+
+	// Output is the storage location where results are sent.
+	Output *v1alpha1.StorageLocationRef `json:"output,omitempty"`
+
+	// CheckpointInterval, if set, splits the reporting period into
+	// sub-ranges of this duration, which are generated and stored
+	// independently, allowing a report which failed or was interrupted to
+	// resume from the last sub-range stored.
+	CheckpointInterval *meta.Duration `json:"checkpointInterval,omitempty"`
+
+	// Retention is how long the report's results are kept after the report
+	// finishes before they may be removed.
+	Retention *meta.Duration `json:"retention,omitempty"`
+
+	// RetryPolicy controls how the report is retried if it fails to run.
+	// Defaults to the reporting-operator's default retry policy.
+	RetryPolicy *v1alpha1.ReportRetryPolicy `json:"retryPolicy,omitempty"`
+
+	// ActiveDeadlineSeconds, if set, is how long a run of the report may
+	// take before its queries are cancelled and the run is marked as timed
+	// out.
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// Inputs provides values for the inputs declared by the
+	// ReportGenerationQuery.
+	Inputs []v1alpha1.ReportGenerationQueryInputValue `json:"inputs,omitempty"`
+
+	// GroupByLabels is a list of pod label keys to group the report's
+	// results by, falling back to the pod's namespace's labels. A column is
+	// added to the report for each label, and the query must set
+	// supportsGroupByLabels.
+	GroupByLabels []string `json:"groupByLabels,omitempty"`
+
+	// CostAllocation controls whether the cost of idle and unallocated
+	// cluster capacity is allocated to the namespaces in the report's
+	// results. The query must set supportsCostAllocation.
+	CostAllocation *v1alpha1.ReportCostAllocation `json:"costAllocation,omitempty"`
+
+	// FanOut, if set, makes the Report generate a child Report for each
+	// namespace matching its namespaceSelector instead of running itself,
+	// so the results for each namespace are stored separately.
+	FanOut *v1alpha1.ReportFanOut `json:"fanOut,omitempty"`
+
+	// Deliveries are destinations in object stores the report's results
+	// are uploaded to after it finishes.
+	Deliveries []v1alpha1.ReportDelivery `json:"deliveries,omitempty"`
+
+	// Notifications are sent when the report finishes, fails or exceeds a
+	// cost threshold.
+	Notifications []v1alpha1.ReportNotification `json:"notifications,omitempty"`
+
+	// Metrics, if set, exports the results of the most recent run of the
+	// report as Prometheus gauges.
+	Metrics *v1alpha1.ReportMetrics `json:"metrics,omitempty"`
+
+	// Assertions are checks of the results evaluated after each successful
+	// run. If any fail, the ResultsValid condition is set to False and the
+	// AssertionFailed notification event is sent.
+	Assertions []v1alpha1.ReportAssertion `json:"assertions,omitempty"`
+
+	// Versioned, if true, causes each successful run to write an
+	// immutable snapshot of its results, which can be listed and fetched
+	// through the API after later runs or backfills change the results.
+	Versioned bool `json:"versioned,omitempty"`
+
+	// Amendments, if set, causes an amended run whenever a ReportDataSource
+	// the report depends on imports data for a period it has already
+	// reported on, which records how the late data changed its results
+	// without modifying them.
+	Amendments *v1alpha1.ReportAmendments `json:"amendments,omitempty"`
+
+	// Lineage, if true, causes each successful run to record which
+	// ReportDataSources, Prometheus queries, partitions and imports, and
+	// which Reports and ScheduledReports, fed it, which can be fetched
+	// through the API.
+	Lineage bool `json:"lineage,omitempty"`
+}
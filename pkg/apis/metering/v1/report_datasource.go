@@ -0,0 +1,72 @@
+package v1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ReportDataSourceList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+	Items         []*ReportDataSource `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ReportDataSource struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ReportDataSourceSpec   `json:"spec"`
+	Status ReportDataSourceStatus `json:"status,omitempty"`
+}
+
+type ReportDataSourceSpec struct {
+	// Prommsum represents a datasource which holds Prometheus metrics
+	Promsum *v1alpha1.PrometheusMetricsDataSource `json:"promsum,omitempty"`
+	// AWSBilling represents a datasource which points to a pre-existing S3
+	// bucket.
+	AWSBilling *v1alpha1.AWSBillingDataSource `json:"awsBilling,omitempty"`
+	// GCPBilling represents a datasource which points to Google Cloud
+	// billing export data in a pre-existing GCS bucket.
+	GCPBilling *v1alpha1.GCPBillingDataSource `json:"gcpBilling,omitempty"`
+	// KubernetesObjects represents a datasource which periodically
+	// snapshots the metadata of Kubernetes objects.
+	KubernetesObjects *v1alpha1.KubernetesObjectsDataSource `json:"kubernetesObjects,omitempty"`
+	// RemoteReport represents a datasource which periodically loads the
+	// results of a Report or ScheduledReport from another Metering
+	// installation.
+	RemoteReport *v1alpha1.RemoteReportDataSource `json:"remoteReport,omitempty"`
+	// ObjectStorage represents a datasource which periodically loads CSV
+	// or Parquet files from an S3 or GCS bucket.
+	ObjectStorage *v1alpha1.ObjectStorageDataSource `json:"objectStorage,omitempty"`
+	// Database represents a datasource which periodically copies a table
+	// from an external database, such as PostgreSQL or MySQL.
+	Database *v1alpha1.DatabaseDataSource `json:"database,omitempty"`
+	// KubernetesEvents represents a datasource which periodically records
+	// the Kubernetes events of workloads, such as pods being created or
+	// deployments being scaled.
+	KubernetesEvents *v1alpha1.KubernetesEventsDataSource `json:"kubernetesEvents,omitempty"`
+	// Retention is how long data is kept in the datasource's table before
+	// it may be removed.
+	Retention *meta.Duration `json:"retention,omitempty"`
+}
+
+type ReportDataSourceStatus struct {
+	// TableName is the name of the table the datasource's data is stored
+	// in. In v1alpha1 this is the top level tableName field.
+	TableName string `json:"tableName,omitempty"`
+	// Conditions contains the Ready condition, which is set once the
+	// table is created, the LastImportSucceeded condition, the Degraded
+	// condition, which is set when the imported data violates the
+	// ReportDataSource's validation rules, and the QueryValid condition of
+	// Prometheus ReportDataSources.
+	Conditions []v1alpha1.ReportDataSourceCondition `json:"conditions,omitempty"`
+	// DataCompleteness is how much of the data expected to be imported
+	// recently is present in the table.
+	DataCompleteness *v1alpha1.ReportDataSourceDataCompleteness `json:"dataCompleteness,omitempty"`
+}
@@ -0,0 +1,127 @@
+package v1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ScheduledReportList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+	Items         []*ScheduledReport `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ScheduledReport struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScheduledReportSpec            `json:"spec"`
+	Status v1alpha1.ScheduledReportStatus `json:"status"`
+}
+
+type ScheduledReportSpec struct {
+	// QueryName is the name of the ReportGenerationQuery the report runs.
+	// In v1alpha1 this is the generationQuery field.
+	QueryName string `json:"query"`
+
+	Schedule v1alpha1.ScheduledReportSchedule `json:"schedule"`
+
+	// Timezone is the IANA timezone name, such as America/New_York, the
+	// schedule and window are evaluated in, so that daily, weekly and
+	// monthly periods begin at midnight in that timezone. It is also
+	// available to the ReportGenerationQuery. Defaults to UTC.
+	Timezone string `json:"timezone,omitempty"`
+
+	// GracePeriod controls how long after each period to wait until running
+	// the report
+	GracePeriod *meta.Duration `json:"gracePeriod,omitempty"`
+
+	// OverwriteExistingData controls whether or not to delete any existing
+	// data in the report table before the scheduled report runs.
+	OverwriteExistingData bool `json:"overwriteExistingData,omitempty"`
+
+	// Window, if set, causes each run of the ScheduledReport to cover a
+	// rolling window ending at the end of the current period, such as month
+	// to date, instead of only the current period.
+	Window *v1alpha1.ScheduledReportWindow `json:"window,omitempty"`
+
+	// CheckpointInterval, if set, splits each run into sub-ranges of this
+	// duration, which are generated and stored independently, allowing a
+	// failed run to resume from the last sub-range stored.
+	CheckpointInterval *meta.Duration `json:"checkpointInterval,omitempty"`
+
+	// Output is the storage location where results are sent.
+	Output *v1alpha1.StorageLocationRef `json:"output,omitempty"`
+
+	// Retention is how long the results of each run are kept before they
+	// may be removed.
+	Retention *meta.Duration `json:"retention,omitempty"`
+
+	// RetryPolicy controls how a run which fails is retried before the
+	// ScheduledReport stops running. Defaults to the reporting-operator's
+	// default retry policy.
+	RetryPolicy *v1alpha1.ReportRetryPolicy `json:"retryPolicy,omitempty"`
+
+	// ActiveDeadlineSeconds, if set, is how long each run may take before
+	// its queries are cancelled and the run is marked as timed out.
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// Inputs provides values for the inputs declared by the
+	// ReportGenerationQuery.
+	Inputs []v1alpha1.ReportGenerationQueryInputValue `json:"inputs,omitempty"`
+
+	// GroupByLabels is a list of pod label keys to group the report's
+	// results by, falling back to the pod's namespace's labels. A column is
+	// added to the report for each label, and the query must set
+	// supportsGroupByLabels.
+	GroupByLabels []string `json:"groupByLabels,omitempty"`
+
+	// CostAllocation controls whether the cost of idle and unallocated
+	// cluster capacity is allocated to the namespaces in the report's
+	// results. The query must set supportsCostAllocation.
+	CostAllocation *v1alpha1.ReportCostAllocation `json:"costAllocation,omitempty"`
+
+	// Deliveries are destinations in object stores the report's results
+	// are uploaded to after each successful run.
+	Deliveries []v1alpha1.ReportDelivery `json:"deliveries,omitempty"`
+
+	// Notifications are sent when a run of the report succeeds, fails or
+	// exceeds a cost threshold.
+	Notifications []v1alpha1.ReportNotification `json:"notifications,omitempty"`
+
+	// Metrics, if set, exports the results of the most recent run of the
+	// report as Prometheus gauges.
+	Metrics *v1alpha1.ReportMetrics `json:"metrics,omitempty"`
+
+	// Assertions are checks of the results evaluated after each successful
+	// run. If any fail, the ResultsValid condition is set to False and the
+	// AssertionFailed notification event is sent.
+	Assertions []v1alpha1.ReportAssertion `json:"assertions,omitempty"`
+
+	// Budget, if set, is the amount each namespace in the results may cost
+	// in a period, and the actions taken on namespaces which exceed it.
+	Budget *v1alpha1.ScheduledReportBudget `json:"budget,omitempty"`
+
+	// Versioned, if true, causes each successful run to write an
+	// immutable snapshot of its results, which can be listed and fetched
+	// through the API after later runs or backfills change the results.
+	Versioned bool `json:"versioned,omitempty"`
+
+	// Amendments, if set, causes an amended run whenever a ReportDataSource
+	// the report depends on imports data for a period it has already
+	// reported on, which records how the late data changed its results
+	// without modifying them.
+	Amendments *v1alpha1.ReportAmendments `json:"amendments,omitempty"`
+
+	// Lineage, if true, causes each successful run to record which
+	// ReportDataSources, Prometheus queries, partitions and imports, and
+	// which Reports and ScheduledReports, fed it, which can be fetched
+	// through the API.
+	Lineage bool `json:"lineage,omitempty"`
+}
@@ -0,0 +1,82 @@
+package v1alpha1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type PricingModelList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+	Items         []*PricingModel `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PricingModel contains the unit rates ReportGenerationQueries use to
+// calculate costs, so they can be changed without editing queries.
+type PricingModel struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PricingModelSpec `json:"spec"`
+}
+
+type PricingModelSpec struct {
+	// Currency is the currency of the rates, eg: USD.
+	Currency string `json:"currency,omitempty"`
+	// Rates are the unit rates, each of which applies to usage from its
+	// effectiveFrom until the effectiveFrom of the next rates, so changing
+	// prices by adding rates doesn't change the cost of past usage.
+	Rates []PricingModelRates `json:"rates"`
+}
+
+// PricingModelRates are the price of a unit of each resource. Unset rates
+// are free.
+type PricingModelRates struct {
+	// EffectiveFrom is when the rates start applying. Usage before the
+	// effectiveFrom of the earliest rates has no cost.
+	EffectiveFrom meta.Time `json:"effectiveFrom"`
+	// CPUCoreHour is the price of a CPU core for an hour.
+	CPUCoreHour float64 `json:"cpuCoreHour,omitempty"`
+	// MemoryGiBHour is the price of a GiB of memory for an hour.
+	MemoryGiBHour float64 `json:"memoryGiBHour,omitempty"`
+	// StorageGiBMonth is the price of a GiB of persistent storage for a
+	// month, which is 730 hours.
+	StorageGiBMonth float64 `json:"storageGiBMonth,omitempty"`
+	// StorageClassGiBMonth overrides StorageGiBMonth for the storage of
+	// the StorageClasses it contains, keyed by StorageClass name.
+	StorageClassGiBMonth map[string]float64 `json:"storageClassGiBMonth,omitempty"`
+	// GPUHour is the price of a GPU for an hour.
+	GPUHour float64 `json:"gpuHour,omitempty"`
+	// EgressGB is the price of a GB (10^9 bytes) of network traffic sent.
+	EgressGB float64 `json:"egressGB,omitempty"`
+	// LoadBalancerHour is the price of a Service of type LoadBalancer for
+	// an hour.
+	LoadBalancerHour float64 `json:"loadBalancerHour,omitempty"`
+	// Nodes are the prices of nodes which aren't billed by a cloud
+	// provider, such as bare-metal nodes. Each node is priced by the first
+	// entry it matches, and nodes matching none have no cost.
+	Nodes []PricingModelNodeRate `json:"nodes,omitempty"`
+}
+
+// PricingModelNodeRate is the price of the nodes matching all of Node,
+// Labels and Annotations which are set. An entry with none set matches
+// every node.
+type PricingModelNodeRate struct {
+	// Name identifies the rate in reports, eg: the name of a node pool.
+	Name string `json:"name,omitempty"`
+	// Node is the name of the node the rate applies to.
+	Node string `json:"node,omitempty"`
+	// Labels are the labels a node must have for the rate to apply, eg: its
+	// node pool or instance type label.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are the annotations a node must have for the rate to
+	// apply. Annotations are only available if kube-state-metrics is
+	// configured to export them.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Hour is the price of the node for an hour.
+	Hour float64 `json:"hour"`
+}
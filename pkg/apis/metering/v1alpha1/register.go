@@ -41,6 +41,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&PrestoTableList{},
 		&ScheduledReport{},
 		&ScheduledReportList{},
+		&PricingModel{},
+		&PricingModelList{},
+		&ReportTemplate{},
+		&ReportTemplateList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
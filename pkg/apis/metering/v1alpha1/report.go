@@ -3,6 +3,7 @@ package v1alpha1
 import (
 	"fmt"
 
+	"k8s.io/api/core/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -34,6 +35,12 @@ type ReportSpec struct {
 
 	GenerationQueryName string `json:"generationQuery"`
 
+	// Timezone is the IANA timezone name, such as America/New_York, which
+	// is made available to the ReportGenerationQuery so that timestamps
+	// can be converted to local time, for example to group usage by day.
+	// Defaults to UTC.
+	Timezone string `json:"timezone,omitempty"`
+
 	// RunImmediately will run the report immediately, ignoring ReportingEnd and
 	// GracePeriod.
 	RunImmediately bool `json:"runImmediately,omitempty"`
@@ -44,11 +51,271 @@ type ReportSpec struct {
 
 	// Output is the storage location where results are sent.
 	Output *StorageLocationRef `json:"output,omitempty"`
+
+	// CheckpointInterval, if set, splits the reporting period into
+	// sub-ranges of this duration, which are generated and stored
+	// independently, allowing a report which failed or was interrupted to
+	// resume from the last sub-range stored.
+	CheckpointInterval *meta.Duration `json:"checkpointInterval,omitempty"`
+
+	// RetryPolicy controls how the report is retried if it fails to run.
+	// Defaults to the reporting-operator's default retry policy.
+	RetryPolicy *ReportRetryPolicy `json:"retryPolicy,omitempty"`
+
+	// ActiveDeadlineSeconds, if set, is how long a run of the report may
+	// take before its queries are cancelled and the run is marked as timed
+	// out.
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// Inputs provides values for the inputs declared by the
+	// ReportGenerationQuery.
+	Inputs []ReportGenerationQueryInputValue `json:"inputs,omitempty"`
+
+	// GroupByLabels is a list of pod label keys to group the report's
+	// results by, falling back to the pod's namespace's labels. A column is
+	// added to the report for each label, and the ReportGenerationQuery must
+	// set supportsGroupByLabels.
+	GroupByLabels []string `json:"groupByLabels,omitempty"`
+
+	// CostAllocation controls whether the cost of idle and unallocated
+	// cluster capacity is allocated to the namespaces in the report's
+	// results. The ReportGenerationQuery must set supportsCostAllocation.
+	CostAllocation *ReportCostAllocation `json:"costAllocation,omitempty"`
+
+	// FanOut, if set, makes the Report generate a child Report for each
+	// namespace matching its namespaceSelector instead of running itself,
+	// so the results for each namespace are stored separately.
+	FanOut *ReportFanOut `json:"fanOut,omitempty"`
+
+	// Deliveries are destinations in object stores the report's results
+	// are uploaded to after it finishes.
+	Deliveries []ReportDelivery `json:"deliveries,omitempty"`
+
+	// Notifications are sent when the report finishes, fails or exceeds a
+	// cost threshold.
+	Notifications []ReportNotification `json:"notifications,omitempty"`
+
+	// Metrics, if set, exports the results of the most recent run of the
+	// report as Prometheus gauges.
+	Metrics *ReportMetrics `json:"metrics,omitempty"`
+
+	// Assertions are checks of the results evaluated after each successful
+	// run. If any fail, the ResultsValid condition is set to False and the
+	// AssertionFailed notification event is sent.
+	Assertions []ReportAssertion `json:"assertions,omitempty"`
+
+	// Versioned, if true, causes each successful run to write an
+	// immutable snapshot of its results, which can be listed and fetched
+	// through the API after later runs or backfills change the results.
+	Versioned bool `json:"versioned,omitempty"`
+
+	// Amendments, if set, causes an amended run whenever a ReportDataSource
+	// the report depends on imports data for a period it has already
+	// reported on, which records how the late data changed its results
+	// without modifying them.
+	Amendments *ReportAmendments `json:"amendments,omitempty"`
+
+	// Lineage, if true, causes each successful run to record which
+	// ReportDataSources, Prometheus queries, partitions and imports, and
+	// which Reports and ScheduledReports, fed it, which can be fetched
+	// through the API.
+	Lineage bool `json:"lineage,omitempty"`
+}
+
+// ReportFanOut controls how a Report generates a child Report per
+// namespace.
+type ReportFanOut struct {
+	// NamespaceSelector selects the namespaces to generate a child Report
+	// for. An empty selector selects every namespace.
+	NamespaceSelector *meta.LabelSelector `json:"namespaceSelector"`
+	// NamespaceInput is the ReportGenerationQuery input each child Report
+	// sets to its namespace. Defaults to "namespace".
+	NamespaceInput string `json:"namespaceInput,omitempty"`
+}
+
+// ReportCostAllocationMode is how a report accounts for cluster cost which
+// isn't attributed to any namespace.
+type ReportCostAllocationMode string
+
+const (
+	// ReportCostAllocationModeShowback reports only the cost attributed to
+	// each namespace, so the results don't add up to the cluster's cost.
+	ReportCostAllocationModeShowback ReportCostAllocationMode = "showback"
+	// ReportCostAllocationModeChargeback allocates the idle cost across
+	// the namespaces, so the results add up to the cluster's cost.
+	ReportCostAllocationModeChargeback ReportCostAllocationMode = "chargeback"
+)
+
+// ReportIdleCostStrategy is how idle cost is split between namespaces in
+// chargeback mode.
+type ReportIdleCostStrategy string
+
+const (
+	// ReportIdleCostStrategyRequest splits idle cost in proportion to each
+	// namespace's resource requests.
+	ReportIdleCostStrategyRequest ReportIdleCostStrategy = "request"
+	// ReportIdleCostStrategyUsage splits idle cost in proportion to each
+	// namespace's resource usage.
+	ReportIdleCostStrategyUsage ReportIdleCostStrategy = "usage"
+	// ReportIdleCostStrategyEven splits idle cost evenly between
+	// namespaces.
+	ReportIdleCostStrategyEven ReportIdleCostStrategy = "even"
+)
+
+// ReportCostAllocation controls how a report allocates idle cost.
+type ReportCostAllocation struct {
+	// Mode is showback or chargeback. Defaults to showback.
+	Mode ReportCostAllocationMode `json:"mode,omitempty"`
+	// IdleCostStrategy is request, usage or even, and is only used in
+	// chargeback mode. Defaults to request.
+	IdleCostStrategy ReportIdleCostStrategy `json:"idleCostStrategy,omitempty"`
 }
 
+// ReportRetryPolicy controls how report runs which fail are retried.
+type ReportRetryPolicy struct {
+	// MaxAttempts is the maximum number of times a run is attempted,
+	// including the first attempt. Set to 1 to disable retries.
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+	// Backoff is how long to wait before the first retry. It doubles after
+	// each subsequent failed attempt, up to MaxBackoff.
+	Backoff *meta.Duration `json:"backoff,omitempty"`
+	// MaxBackoff is the longest to wait between attempts.
+	MaxBackoff *meta.Duration `json:"maxBackoff,omitempty"`
+	// Deadline, if set, is how long after the first attempt started a run
+	// may be retried, regardless of MaxAttempts.
+	Deadline *meta.Duration `json:"deadline,omitempty"`
+}
+
+// ReportAttempt records a failed attempt to run a report.
+type ReportAttempt struct {
+	// StartTime is when the attempt started.
+	StartTime meta.Time `json:"startTime"`
+	// EndTime is when the attempt failed.
+	EndTime meta.Time `json:"endTime"`
+	// Error is the error the attempt failed with.
+	Error string `json:"error"`
+	// Reason is a brief reason for the failure, if known, such as
+	// DeadlineExceeded if the attempt timed out.
+	Reason string `json:"reason,omitempty"`
+}
+
+const (
+	// ReportDeadlineExceededReason is the reason of an attempt which was
+	// cancelled because it exceeded the report's activeDeadlineSeconds.
+	ReportDeadlineExceededReason = "DeadlineExceeded"
+)
+
 type ReportStatus struct {
 	Phase  ReportPhase `json:"phase,omitempty"`
 	Output string      `json:"output,omitempty"`
+
+	// Dependencies contains the readiness of each ReportDataSource, Report
+	// and ScheduledReport the report's ReportGenerationQuery depends on for
+	// the reporting period.
+	Dependencies []ReportDependencyStatus `json:"dependencies,omitempty"`
+
+	// Checkpoint is the end of the most recent sub-range stored for reports
+	// with a checkpointInterval.
+	Checkpoint *meta.Time `json:"checkpoint,omitempty"`
+
+	// Attempts contains the failed attempts to run the report.
+	Attempts []ReportAttempt `json:"attempts,omitempty"`
+	// NextRetryTime is when the report will be retried after a failed
+	// attempt.
+	NextRetryTime *meta.Time `json:"nextRetryTime,omitempty"`
+
+	// FanOutReports contains the child Reports of a Report with fanOut
+	// set, sorted by namespace.
+	FanOutReports []ReportFanOutStatus `json:"fanOutReports,omitempty"`
+
+	// Deliveries contains the status of the upload of the results to each
+	// of the report's delivery destinations.
+	Deliveries []ReportDeliveryStatus `json:"deliveries,omitempty"`
+
+	// Notifications contains the most recent notification sent by each of
+	// the report's notifications.
+	Notifications []ReportNotificationStatus `json:"notifications,omitempty"`
+
+	// Assertions contains the outcome of each of the report's assertions
+	// in its most recent successful run.
+	Assertions []ReportAssertionStatus `json:"assertions,omitempty"`
+
+	// LatestVersion is the snapshot of the results written by the most
+	// recent successful run of a versioned report.
+	LatestVersion *ReportVersionStatus `json:"latestVersion,omitempty"`
+
+	// LastAmendment is the outcome of the most recent amended run.
+	LastAmendment *ReportAmendmentStatus `json:"lastAmendment,omitempty"`
+
+	// LatestLineage is the lineage recorded by the most recent successful
+	// run of a report with lineage enabled.
+	LatestLineage *ReportLineageStatus `json:"latestLineage,omitempty"`
+
+	// Conditions contains the Ready, Running and DataComplete conditions,
+	// which are set from the report's phase and dependencies, and the
+	// ResultsValid condition of reports with assertions.
+	Conditions []ReportCondition `json:"conditions,omitempty"`
+}
+
+type ReportCondition struct {
+	// Type of Report condition, Ready, Running, DataComplete or
+	// ResultsValid.
+	Type ReportConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status v1.ConditionStatus `json:"status"`
+	// Last time the condition was checked.
+	// +optional
+	LastUpdateTime meta.Time `json:"lastUpdateTime,omitempty"`
+	// Last time the condition transit from one status to another.
+	// +optional
+	LastTransitionTime meta.Time `json:"lastTransitionTime,omitempty"`
+	// (brief) reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Human readable message indicating details about last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+type ReportConditionType string
+
+const (
+	// ReportReady is True once the report has finished and its results
+	// are available.
+	ReportReady ReportConditionType = "Ready"
+	// ReportRunning is True while the report is running.
+	ReportRunning ReportConditionType = "Running"
+	// ReportDataComplete is True when every dependency of the report has
+	// data for the reporting period. It's not set for reports with
+	// runImmediately, which don't wait for their dependencies.
+	ReportDataComplete ReportConditionType = "DataComplete"
+	// ReportResultsValid is True when the results of the report's most
+	// recent run met all of its assertions.
+	ReportResultsValid ReportConditionType = "ResultsValid"
+)
+
+type ReportFanOutStatus struct {
+	// Namespace is the namespace the child Report reports on.
+	Namespace string `json:"namespace"`
+	// Name is the name of the child Report.
+	Name string `json:"name"`
+	// Phase is the phase of the child Report.
+	Phase ReportPhase `json:"phase,omitempty"`
+}
+
+type ReportDependencyStatus struct {
+	// Kind is the kind of the dependency, ReportDataSource, Report or
+	// ScheduledReport.
+	Kind string `json:"kind,omitempty"`
+	// Name is the name of the dependency.
+	Name string `json:"name"`
+	// Ready is true if the dependency has data covering the end of the
+	// reporting period.
+	Ready bool `json:"ready"`
+	// LastDataTime is the most recent time the dependency has data for.
+	LastDataTime *meta.Time `json:"lastDataTime,omitempty"`
+	// Message contains details about why the dependency isn't ready.
+	Message string `json:"message,omitempty"`
 }
 
 type ReportPhase string
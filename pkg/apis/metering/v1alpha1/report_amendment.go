@@ -0,0 +1,35 @@
+package v1alpha1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReportAmendments configures the amended runs of a report, which compare
+// its results with the results it would have now for periods a
+// ReportDataSource it depends on has imported data for since it ran.
+type ReportAmendments struct {
+	// KeyColumns are the columns identifying each row of the results, by
+	// which the original and amended results are compared. The other
+	// columns must be numeric. Defaults to every column which isn't
+	// numeric.
+	KeyColumns []string `json:"keyColumns,omitempty"`
+}
+
+// ReportAmendmentStatus is the outcome of an amended run of a report.
+type ReportAmendmentStatus struct {
+	// ID identifies the amendment, and is used to fetch its changes.
+	ID string `json:"id"`
+	// Time is when the amended run finished.
+	Time meta.Time `json:"time"`
+	// DataSources are the ReportDataSources whose late data caused the
+	// amended run.
+	DataSources []string `json:"dataSources,omitempty"`
+	// PeriodStart and PeriodEnd are the time range of the late data.
+	PeriodStart meta.Time `json:"periodStart"`
+	PeriodEnd   meta.Time `json:"periodEnd"`
+	// ChangedRows is the number of rows of the results whose values the
+	// late data changed, including rows which were added or removed.
+	ChangedRows int64 `json:"changedRows"`
+	// Error is the error the amended run failed with, if it failed.
+	Error string `json:"error,omitempty"`
+}
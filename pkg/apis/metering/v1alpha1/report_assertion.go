@@ -0,0 +1,65 @@
+package v1alpha1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReportAssertion is a check of a report's results which is evaluated after
+// each successful run. Exactly one of RowCount, NotNull or Sum must be set.
+type ReportAssertion struct {
+	// Name identifies the assertion in the report's status, and must be
+	// unique within the report.
+	Name string `json:"name"`
+	// RowCount checks the number of rows in the results.
+	RowCount *ReportRowCountAssertion `json:"rowCount,omitempty"`
+	// NotNull checks that a column of the results has no NULL values.
+	NotNull *ReportNotNullAssertion `json:"notNull,omitempty"`
+	// Sum checks that the sum of a column of the results is within a
+	// tolerance of an expected amount.
+	Sum *ReportSumAssertion `json:"sum,omitempty"`
+}
+
+type ReportRowCountAssertion struct {
+	// Min is the fewest rows the results may have.
+	Min *int64 `json:"min,omitempty"`
+	// Max is the most rows the results may have.
+	Max *int64 `json:"max,omitempty"`
+}
+
+type ReportNotNullAssertion struct {
+	// Column is the name of a column of the results.
+	Column string `json:"column"`
+}
+
+// ReportSumAssertion checks the sum of a column of the results against the
+// expected Value, or the value returned by Query. Exactly one of Value or
+// Query must be set.
+type ReportSumAssertion struct {
+	// Column is the name of a numeric column of the results, such as
+	// total_cost.
+	Column string `json:"column"`
+	// Value is the amount the sum is expected to be.
+	Value *float64 `json:"value,omitempty"`
+	// Query is a Presto query returning a single numeric value the sum is
+	// expected to be, such as the cost of the whole cluster. It's rendered
+	// like the query of a ReportGenerationQuery, with the period of the
+	// run in .Report.StartPeriod and .Report.EndPeriod.
+	Query string `json:"query,omitempty"`
+	// TolerancePercent is how far the sum may be from the expected amount,
+	// as a percentage of the expected amount. Defaults to 0.
+	TolerancePercent float64 `json:"tolerancePercent,omitempty"`
+}
+
+// ReportAssertionStatus is the outcome of an assertion in the most recent
+// successful run of a report.
+type ReportAssertionStatus struct {
+	// Name is the name of the assertion.
+	Name string `json:"name"`
+	// Passed is true if the results met the assertion.
+	Passed bool `json:"passed"`
+	// Message describes what was checked, and why the assertion failed if
+	// it did.
+	Message string `json:"message,omitempty"`
+	// Time is when the assertion was evaluated.
+	Time meta.Time `json:"time"`
+}
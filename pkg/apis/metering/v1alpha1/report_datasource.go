@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	"k8s.io/api/core/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -21,14 +22,99 @@ type ReportDataSource struct {
 
 	Spec      ReportDataSourceSpec `json:"spec"`
 	TableName string               `json:"tableName"`
+	// Conditions contains the Ready condition, which is set once the
+	// ReportDataSource's table is created, the LastImportSucceeded
+	// condition of ReportDataSources which import data periodically, the
+	// Degraded condition, which is set when the data imported by a
+	// ReportDataSource with validation rules violates them, and the
+	// QueryValid condition of Prometheus ReportDataSources.
+	Conditions []ReportDataSourceCondition `json:"conditions,omitempty"`
+	// DataCompleteness is how much of the data expected to be imported
+	// recently by a Prometheus or kubernetesObjects ReportDataSource is
+	// present in its table.
+	DataCompleteness *ReportDataSourceDataCompleteness `json:"dataCompleteness,omitempty"`
 }
 
+// ReportDataSourceDataCompleteness is the number of steps a ReportDataSource
+// imported data for between Start and End, out of the number expected.
+type ReportDataSourceDataCompleteness struct {
+	// Ratio is PresentSteps divided by ExpectedSteps.
+	Ratio float64 `json:"ratio"`
+	// ExpectedSteps is the number of stepSizes of Prometheus
+	// ReportDataSources, or collectionIntervals of kubernetesObjects
+	// ReportDataSources, between Start and End.
+	ExpectedSteps int64 `json:"expectedSteps"`
+	// PresentSteps is the number of those steps the table contains any
+	// data for.
+	PresentSteps int64     `json:"presentSteps"`
+	Start        meta.Time `json:"start"`
+	End          meta.Time `json:"end"`
+}
+
+type ReportDataSourceCondition struct {
+	// Type of ReportDataSource condition, Ready, LastImportSucceeded,
+	// Degraded or QueryValid.
+	Type ReportDataSourceConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status v1.ConditionStatus `json:"status"`
+	// Last time the condition was checked.
+	// +optional
+	LastUpdateTime meta.Time `json:"lastUpdateTime,omitempty"`
+	// Last time the condition transit from one status to another.
+	// +optional
+	LastTransitionTime meta.Time `json:"lastTransitionTime,omitempty"`
+	// (brief) reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Human readable message indicating details about last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+type ReportDataSourceConditionType string
+
+const (
+	ReportDataSourceDegraded ReportDataSourceConditionType = "Degraded"
+	// ReportDataSourceQueryValid is set on Prometheus ReportDataSources
+	// when their query is validated. Metrics aren't imported while it's
+	// False.
+	ReportDataSourceQueryValid ReportDataSourceConditionType = "QueryValid"
+	// ReportDataSourceReady is True once the ReportDataSource's table has
+	// been created.
+	ReportDataSourceReady ReportDataSourceConditionType = "Ready"
+	// ReportDataSourceLastImportSucceeded is set after each import of
+	// Prometheus, kubernetesObjects, kubernetesEvents, remoteReport,
+	// objectStorage and database ReportDataSources to whether or not it
+	// succeeded.
+	ReportDataSourceLastImportSucceeded ReportDataSourceConditionType = "LastImportSucceeded"
+)
+
 type ReportDataSourceSpec struct {
 	// Prommsum represents a datasource which holds Prometheus metrics
 	Promsum *PrometheusMetricsDataSource `json:"promsum"`
 	// AWSBilling represents a datasource which points to a pre-existing S3
 	// bucket.
 	AWSBilling *AWSBillingDataSource `json:"awsBilling"`
+	// GCPBilling represents a datasource which points to Google Cloud
+	// billing export data in a pre-existing GCS bucket.
+	GCPBilling *GCPBillingDataSource `json:"gcpBilling"`
+	// KubernetesObjects represents a datasource which periodically
+	// snapshots the metadata of Kubernetes objects.
+	KubernetesObjects *KubernetesObjectsDataSource `json:"kubernetesObjects"`
+	// RemoteReport represents a datasource which periodically loads the
+	// results of a Report or ScheduledReport from another Metering
+	// installation.
+	RemoteReport *RemoteReportDataSource `json:"remoteReport"`
+	// ObjectStorage represents a datasource which periodically loads CSV
+	// or Parquet files from an S3 or GCS bucket.
+	ObjectStorage *ObjectStorageDataSource `json:"objectStorage"`
+	// Database represents a datasource which periodically copies a table
+	// from an external database, such as PostgreSQL or MySQL.
+	Database *DatabaseDataSource `json:"database"`
+	// KubernetesEvents represents a datasource which periodically records
+	// the Kubernetes events of workloads, such as pods being created or
+	// deployments being scaled.
+	KubernetesEvents *KubernetesEventsDataSource `json:"kubernetesEvents"`
 }
 
 type AWSBillingDataSource struct {
@@ -41,6 +127,147 @@ type S3Bucket struct {
 	Prefix string `json:"prefix"`
 }
 
+// GCPBillingDataSource is the location of a Google Cloud detailed billing
+// export, exported from BigQuery as Parquet files.
+type GCPBillingDataSource struct {
+	Source *GCSBucket `json:"source"`
+}
+
+type GCSBucket struct {
+	Bucket string `json:"bucket"`
+	Prefix string `json:"prefix"`
+}
+
+// KubernetesObjectsDataSource snapshots the labels, annotations and
+// controlling owner of every object of a kind, so reports can join usage to
+// the teams and applications which own it.
+type KubernetesObjectsDataSource struct {
+	// Kind is the kind of object snapshotted, one of Namespace, Pod,
+	// ReplicaSet, Deployment, StatefulSet, DaemonSet, Job or CronJob.
+	Kind string `json:"kind"`
+	// CollectionInterval is how often the objects are snapshotted,
+	// defaulting to the Prometheus query interval.
+	CollectionInterval *meta.Duration      `json:"collectionInterval,omitempty"`
+	Storage            *StorageLocationRef `json:"storage,omitempty"`
+}
+
+// KubernetesEventsDataSource records the events of workloads, so reports can
+// explain changes in usage, such as a namespace's cost doubling because a
+// deployment was scaled up.
+type KubernetesEventsDataSource struct {
+	// Reasons are the reasons of the events recorded, defaulting to
+	// ScalingReplicaSet, SuccessfulCreate, SuccessfulDelete and
+	// SuccessfulRescale, which are recorded when deployments, replica sets
+	// and horizontal pod autoscalers scale workloads, and when pods are
+	// created and deleted by their controllers.
+	Reasons []string `json:"reasons,omitempty"`
+	// CollectionInterval is how often events are recorded, defaulting to
+	// the Prometheus query interval. It must be shorter than the time
+	// events are kept for by the API server, which defaults to an hour.
+	CollectionInterval *meta.Duration      `json:"collectionInterval,omitempty"`
+	Storage            *StorageLocationRef `json:"storage,omitempty"`
+}
+
+// RemoteReportDataSource loads the results of a finished Report or
+// ScheduledReport from another Metering installation, either from its
+// reporting-operator's API or from the S3 bucket it stores them in, so
+// reports across a fleet can be produced without importing every cluster's
+// metrics.
+type RemoteReportDataSource struct {
+	// ClusterID identifies the installation the results are loaded from,
+	// and is stored in the cluster_id column.
+	ClusterID string `json:"clusterID"`
+	// ReportName is the name of the Report whose results are loaded.
+	ReportName string `json:"reportName,omitempty"`
+	// ScheduledReportName is the name of the ScheduledReport whose results
+	// are loaded.
+	ScheduledReportName string `json:"scheduledReportName,omitempty"`
+	// Columns are the columns of the results, which must match the
+	// columns of the remote report's ReportGenerationQuery.
+	Columns []ReportGenerationQueryColumn `json:"columns"`
+	// TimestampColumn, if set, is a timestamp column containing the end of
+	// the period each row covers, such as period_end. Reports using this
+	// ReportDataSource wait until results up to their end have been loaded.
+	TimestampColumn string `json:"timestampColumn,omitempty"`
+	// API, if set, fetches the results from the remote reporting-operator's
+	// HTTP API.
+	API *RemoteReportAPI `json:"api,omitempty"`
+	// S3, if set, reads the results from the remote report's table
+	// directory in S3.
+	S3 *RemoteReportS3 `json:"s3,omitempty"`
+	// PollInterval is how often the results are loaded, defaulting to the
+	// Prometheus query interval.
+	PollInterval *meta.Duration      `json:"pollInterval,omitempty"`
+	Storage      *StorageLocationRef `json:"storage,omitempty"`
+}
+
+type RemoteReportAPI struct {
+	// URL is the URL of the remote reporting-operator's HTTP API.
+	URL string `json:"url"`
+	// BearerTokenSecret is a secret reference in the form
+	// <provider>://<path> containing the token key used to authenticate
+	// with the API.
+	BearerTokenSecret string `json:"bearerTokenSecret,omitempty"`
+}
+
+type RemoteReportS3 struct {
+	S3Bucket `json:",inline"`
+	// FileFormat is the Hive file format the remote installation stores
+	// report results in, defaulting to orc.
+	FileFormat string `json:"fileFormat,omitempty"`
+}
+
+// ObjectStorageDataSource loads the CSV or Parquet files under a prefix of an
+// S3 or GCS bucket, such as rate cards, discounts or amortization tables
+// maintained outside of the cluster, so reports can join them to usage.
+type ObjectStorageDataSource struct {
+	// S3, if set, reads the files under the prefix of an S3 bucket.
+	S3 *S3Bucket `json:"s3,omitempty"`
+	// GCS, if set, reads the files under the prefix of a GCS bucket.
+	GCS *GCSBucket `json:"gcs,omitempty"`
+	// Format is the format of the files, csv or parquet.
+	Format string `json:"format"`
+	// CSV configures how csv files are parsed.
+	CSV *ObjectStorageCSV `json:"csv,omitempty"`
+	// Columns are the columns of the files. Columns of csv files are
+	// matched by position, and columns of Parquet files by name.
+	Columns []ReportGenerationQueryColumn `json:"columns"`
+	// RefreshInterval is how often the files are loaded, defaulting to the
+	// Prometheus query interval.
+	RefreshInterval *meta.Duration      `json:"refreshInterval,omitempty"`
+	Storage         *StorageLocationRef `json:"storage,omitempty"`
+}
+
+type ObjectStorageCSV struct {
+	// Delimiter is the character separating the fields of each line,
+	// defaulting to a comma.
+	Delimiter string `json:"delimiter,omitempty"`
+	// SkipHeaderLines is the number of lines at the start of each file
+	// which aren't loaded, such as a header row.
+	SkipHeaderLines int `json:"skipHeaderLines,omitempty"`
+}
+
+// DatabaseDataSource copies a table from an external database, such as an
+// organization's cost center mappings, using a Presto catalog configured with
+// the database's connector, so reports can join it to usage without querying
+// the database while they run.
+type DatabaseDataSource struct {
+	// Catalog is the name of the Presto catalog of the database.
+	Catalog string `json:"catalog"`
+	// Schema is the schema of the table in the database, such as the
+	// PostgreSQL schema or the MySQL database.
+	Schema string `json:"schema"`
+	// Table is the name of the table copied.
+	Table string `json:"table"`
+	// Columns are the columns copied from the table. The table may have
+	// other columns, which aren't copied.
+	Columns []ReportGenerationQueryColumn `json:"columns"`
+	// RefreshInterval is how often the table is copied, defaulting to the
+	// Prometheus query interval.
+	RefreshInterval *meta.Duration      `json:"refreshInterval,omitempty"`
+	Storage         *StorageLocationRef `json:"storage,omitempty"`
+}
+
 type PrometheusQueryConfig struct {
 	QueryInterval *meta.Duration `json:"queryInterval,omitempty"`
 	StepSize      *meta.Duration `json:"stepSize,omitempty"`
@@ -51,4 +278,58 @@ type PrometheusMetricsDataSource struct {
 	Query       string                 `json:"query"`
 	QueryConfig *PrometheusQueryConfig `json:"queryConfig"`
 	Storage     *StorageLocationRef    `json:"storage"`
+	// TargetMetadata, if set, enriches each imported metric with metadata
+	// from Prometheus target discovery.
+	TargetMetadata *PrometheusTargetMetadata `json:"targetMetadata,omitempty"`
+	// Exemplars, if set, causes the exemplars for the metrics imported to
+	// be stored in a separate table.
+	Exemplars *PrometheusExemplars `json:"exemplars,omitempty"`
+	// Validation, if set, declares what the imported data is expected to
+	// look like. Each hour of imported data is checked against it, and
+	// the ReportDataSource is marked Degraded if it isn't met.
+	Validation *PrometheusMetricsValidation `json:"validation,omitempty"`
+	// Archive, if set, moves the metrics older than a day to a second
+	// StorageLocation, such as S3, so recent metrics can be kept in faster
+	// storage, such as HDFS. The table of a ReportDataSource with an
+	// archive is partitioned by the date of its metrics, so it must be set
+	// when the ReportDataSource is created.
+	Archive *PrometheusMetricsArchive `json:"archive,omitempty"`
+}
+
+// PrometheusMetricsArchive configures moving the partitions of a Prometheus
+// metrics table containing older metrics to another StorageLocation.
+type PrometheusMetricsArchive struct {
+	// After is how old the metrics of a day must be before the day's
+	// partition is moved. It must be at least 24h, and longer than the
+	// metrics of the day could still be imported, such as when backfilling.
+	After meta.Duration `json:"after"`
+	// Storage is the StorageLocation the partitions are moved to.
+	Storage *StorageLocationRef `json:"storage"`
+}
+
+// PrometheusMetricsValidation are the expectations of the metrics imported
+// by a ReportDataSource. Unset fields aren't checked.
+type PrometheusMetricsValidation struct {
+	// MinSamplesPerHour is the fewest metrics expected to be imported for
+	// each hour.
+	MinSamplesPerHour *int64 `json:"minSamplesPerHour,omitempty"`
+	// AllowedLabelKeys is the list of label names metrics may have.
+	AllowedLabelKeys []string `json:"allowedLabelKeys,omitempty"`
+	// MinValue is the smallest amount a metric may have.
+	MinValue *float64 `json:"minValue,omitempty"`
+	// MaxValue is the largest amount a metric may have.
+	MaxValue *float64 `json:"maxValue,omitempty"`
+}
+
+type PrometheusExemplars struct {
+	// TraceIDLabel is the exemplar label containing the trace ID. Defaults
+	// to trace_id.
+	TraceIDLabel string `json:"traceIDLabel,omitempty"`
+}
+
+type PrometheusTargetMetadata struct {
+	// Labels is a list of additional discovered labels, such as
+	// __meta_kubernetes_namespace, to copy from a metric's target onto the
+	// metric. The __meta_ prefix is removed from the label name.
+	Labels []string `json:"labels,omitempty"`
 }
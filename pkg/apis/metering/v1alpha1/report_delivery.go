@@ -0,0 +1,96 @@
+package v1alpha1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReportDelivery is a destination in an object store or a webhook a report's
+// results are delivered to after each successful run. Exactly one of S3, GCS,
+// Azure or Webhook must be set.
+type ReportDelivery struct {
+	// Name identifies the destination in the report's status, and must be
+	// unique within the report.
+	Name string `json:"name"`
+	// Format is the format the results are uploaded in, one of csv, json,
+	// parquet, xlsx, html or pdf. Defaults to csv, and must be json for
+	// webhooks.
+	Format string `json:"format,omitempty"`
+	// Template is the name of the ReportTemplate rendering the results,
+	// which is required for the html and pdf formats.
+	Template string `json:"template,omitempty"`
+	// S3 uploads the results to an S3 bucket, or a bucket in an S3
+	// compatible object store.
+	S3 *S3DeliveryDestination `json:"s3,omitempty"`
+	// GCS uploads the results to a Google Cloud Storage bucket.
+	GCS *GCSDeliveryDestination `json:"gcs,omitempty"`
+	// Azure uploads the results to an Azure Blob Storage container.
+	Azure *AzureBlobDeliveryDestination `json:"azure,omitempty"`
+	// Webhook posts the results as JSON to a URL.
+	Webhook *WebhookDeliveryDestination `json:"webhook,omitempty"`
+}
+
+type S3DeliveryDestination struct {
+	S3Bucket `json:",inline"`
+	// Endpoint, if set, is the URL of an S3 compatible object store to
+	// upload to instead of AWS.
+	Endpoint string `json:"endpoint,omitempty"`
+	// CredentialsSecret is a secret reference in the form
+	// <provider>://<path> containing the aws-access-key-id and
+	// aws-secret-access-key keys. Defaults to the reporting-operator's AWS
+	// credentials.
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+}
+
+type GCSDeliveryDestination struct {
+	GCSBucket `json:",inline"`
+	// CredentialsSecret is a secret reference in the form
+	// <provider>://<path> containing a GCS HMAC key, with the access ID in
+	// the aws-access-key-id key and the secret in the
+	// aws-secret-access-key key, since results are uploaded using GCS's
+	// S3 compatible XML API.
+	CredentialsSecret string `json:"credentialsSecret"`
+}
+
+type AzureBlobDeliveryDestination struct {
+	// Account is the name of the storage account.
+	Account string `json:"account"`
+	// Container is the name of the blob container.
+	Container string `json:"container"`
+	Prefix    string `json:"prefix"`
+	// Endpoint, if set, is the URL of the Blob service to upload to
+	// instead of https://<account>.blob.core.windows.net.
+	Endpoint string `json:"endpoint,omitempty"`
+	// SASTokenSecret is a secret reference in the form
+	// <provider>://<path> containing a shared access signature token
+	// allowing blobs to be written to the container in the sas-token key.
+	SASTokenSecret string `json:"sasTokenSecret"`
+}
+
+type WebhookDeliveryDestination struct {
+	// URL is the URL the results are posted to.
+	URL string `json:"url"`
+	// SigningSecret is a secret reference in the form <provider>://<path>
+	// containing the key the body of each request is signed with using
+	// HMAC-SHA256 in the hmac-key key. If set, the signature is sent in the
+	// X-Metering-Signature header.
+	SigningSecret string `json:"signingSecret,omitempty"`
+	// BatchSize is the maximum number of rows posted in each request. If
+	// unset, all the results are posted in a single request.
+	BatchSize int `json:"batchSize,omitempty"`
+	// MaxAttempts is the maximum number of times each request is attempted
+	// before the delivery fails. Defaults to 5.
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+}
+
+// ReportDeliveryStatus records the most recent upload of a report's results
+// to one of its delivery destinations.
+type ReportDeliveryStatus struct {
+	// Name is the name of the destination.
+	Name string `json:"name"`
+	// Time is when the results were uploaded, or the upload failed.
+	Time meta.Time `json:"time"`
+	// URL is the location the results were uploaded to.
+	URL string `json:"url,omitempty"`
+	// Error is the error the upload failed with, if it failed.
+	Error string `json:"error,omitempty"`
+}
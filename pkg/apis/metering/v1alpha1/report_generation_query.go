@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	"k8s.io/api/core/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -23,6 +24,64 @@ type ReportGenerationQuery struct {
 	// ViewName is the name of the view in Presto for this query, if the view
 	// has been created. If it is empty, the view does not exist.
 	ViewName string `json:"viewName,omitempty"`
+	// Revisions records the query and columns of the ReportGenerationQuery
+	// each time they change, so that Reports for past periods can be re-run
+	// using the query that was in effect for that period. The last revision
+	// is the current one.
+	Revisions []ReportGenerationQueryRevision `json:"revisions,omitempty"`
+	// Conditions contains the Ready condition, which is set once the
+	// query's dependencies are initialized and its view is created, and the
+	// QueryValid condition, which is set when the query is validated by
+	// running EXPLAIN against Presto.
+	Conditions []ReportGenerationQueryCondition `json:"conditions,omitempty"`
+}
+
+type ReportGenerationQueryCondition struct {
+	// Type of ReportGenerationQuery condition, Ready or QueryValid.
+	Type ReportGenerationQueryConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status v1.ConditionStatus `json:"status"`
+	// Last time the condition was checked.
+	// +optional
+	LastUpdateTime meta.Time `json:"lastUpdateTime,omitempty"`
+	// Last time the condition transit from one status to another.
+	// +optional
+	LastTransitionTime meta.Time `json:"lastTransitionTime,omitempty"`
+	// (brief) reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Human readable message indicating details about last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+type ReportGenerationQueryConditionType string
+
+const (
+	// ReportGenerationQueryQueryValid is set when the query is rendered
+	// with placeholder report values and explained by Presto.
+	ReportGenerationQueryQueryValid ReportGenerationQueryConditionType = "QueryValid"
+	// ReportGenerationQueryReady is True once the query's dependencies are
+	// initialized and its view is created, unless the view is disabled,
+	// and Reports using it can run.
+	ReportGenerationQueryReady ReportGenerationQueryConditionType = "Ready"
+)
+
+// ReportGenerationQueryRevision is the query and columns of a
+// ReportGenerationQuery during a period of time.
+type ReportGenerationQueryRevision struct {
+	// ValidFrom is when the revision became the current revision.
+	ValidFrom meta.Time `json:"validFrom"`
+	// ValidUntil is when the revision was replaced by a newer revision. It's
+	// unset for the current revision.
+	ValidUntil *meta.Time `json:"validUntil,omitempty"`
+
+	Query   string                        `json:"query"`
+	Columns []ReportGenerationQueryColumn `json:"columns"`
+
+	// ViewName is the name of the view in Presto for this revision's query,
+	// if it's a past revision whose view was kept when it was replaced.
+	ViewName string `json:"viewName,omitempty"`
 }
 
 type ReportGenerationQuerySpec struct {
@@ -32,8 +91,97 @@ type ReportGenerationQuerySpec struct {
 	Query                string                        `json:"query"`
 	Columns              []ReportGenerationQueryColumn `json:"columns"`
 	View                 GenQueryView                  `json:"view"`
+
+	// Materialization controls how the results of Reports and
+	// ScheduledReports using this query are stored. Defaults to "table".
+	Materialization ReportMaterializationPolicy `json:"materialization,omitempty"`
+
+	// Reports is a list of Reports whose results are read by this query.
+	// Reports using this query wait until each of these Reports has
+	// finished for a period covering the end of their reporting period.
+	Reports []string `json:"reports,omitempty"`
+	// ScheduledReports is a list of ScheduledReports whose results are read
+	// by this query. Reports and ScheduledReports using this query wait
+	// until each of these ScheduledReports has run for the same period.
+	ScheduledReports []string `json:"scheduledReports,omitempty"`
+
+	// Inputs declares the parameters of the query, which Reports and
+	// ScheduledReports using the query provide values for, and which are
+	// available to the query template as .Report.Inputs.
+	Inputs []ReportGenerationQueryInputDefinition `json:"inputs,omitempty"`
+
+	// SupportsGroupByLabels marks the query as supporting Reports and
+	// ScheduledReports which set groupByLabels. The query must select the
+	// label columns last using .Report.GroupByLabelColumns, after the
+	// columns listed in columns.
+	SupportsGroupByLabels bool `json:"supportsGroupByLabels,omitempty"`
+
+	// SupportsCostAllocation marks the query as supporting Reports and
+	// ScheduledReports which set costAllocation. The query must select
+	// each namespace's idle cost using .Report.AllocatedIdleCost.
+	SupportsCostAllocation bool `json:"supportsCostAllocation,omitempty"`
+}
+
+// ReportGenerationQueryInputType is the type of a ReportGenerationQuery
+// input, which controls how the values provided for it are validated and
+// the type of the value available to the query template.
+type ReportGenerationQueryInputType string
+
+const (
+	// ReportGenerationQueryInputTypeString accepts any value.
+	ReportGenerationQueryInputTypeString ReportGenerationQueryInputType = "string"
+	// ReportGenerationQueryInputTypeInteger accepts base 10 integers.
+	ReportGenerationQueryInputTypeInteger ReportGenerationQueryInputType = "integer"
+	// ReportGenerationQueryInputTypeNumber accepts finite decimal numbers,
+	// such as prices, and is available to the query template as a float64.
+	ReportGenerationQueryInputTypeNumber ReportGenerationQueryInputType = "number"
+	// ReportGenerationQueryInputTypeTime accepts RFC3339 timestamps, and
+	// is available to the query template as a time.Time.
+	ReportGenerationQueryInputTypeTime ReportGenerationQueryInputType = "time"
+	// ReportGenerationQueryInputTypeNamespace accepts valid namespace names.
+	ReportGenerationQueryInputTypeNamespace ReportGenerationQueryInputType = "namespace"
+	// ReportGenerationQueryInputTypeLabelSelector accepts Kubernetes label
+	// selectors, such as "app=web,tier!=cache".
+	ReportGenerationQueryInputTypeLabelSelector ReportGenerationQueryInputType = "labelSelector"
+)
+
+type ReportGenerationQueryInputDefinition struct {
+	Name string `json:"name"`
+	// Type defaults to "string".
+	Type ReportGenerationQueryInputType `json:"type,omitempty"`
+	// Required inputs must be provided by each Report using the query,
+	// unless they have a Default.
+	Required bool `json:"required,omitempty"`
+	// Default is the value used when a Report doesn't provide one.
+	Default *string `json:"default,omitempty"`
+	// AllowedValues, if set, restricts the values of the input, for
+	// example to the aggregation levels the query supports.
+	AllowedValues []string `json:"allowedValues,omitempty"`
+}
+
+// ReportGenerationQueryInputValue is a value for an input of the
+// ReportGenerationQuery used by a Report.
+type ReportGenerationQueryInputValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
 }
 
+type ReportMaterializationPolicy string
+
+const (
+	// ReportMaterializationTable stores report results in a physical table
+	// that results are inserted into each time the report runs.
+	ReportMaterializationTable ReportMaterializationPolicy = "table"
+	// ReportMaterializationView stores report results as a Presto view over
+	// the rendered query, so results are computed when they are read rather
+	// than being duplicated into storage.
+	ReportMaterializationView ReportMaterializationPolicy = "view"
+	// ReportMaterializationMaterialized drops and recreates the report table
+	// each time the report runs, so the table only ever contains the results
+	// of the most recent run.
+	ReportMaterializationMaterialized ReportMaterializationPolicy = "materialized"
+)
+
 type ReportGenerationQueryColumn struct {
 	Name        string `json:"name"`
 	Type        string `json:"type"`
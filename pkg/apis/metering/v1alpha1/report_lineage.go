@@ -0,0 +1,19 @@
+package v1alpha1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReportLineageStatus records the lineage written by a run of a report with
+// lineage enabled.
+type ReportLineageStatus struct {
+	// ID identifies the run the lineage was recorded for.
+	ID string `json:"id"`
+	// RecordedAt is when the lineage was written, or writing it failed.
+	RecordedAt meta.Time `json:"recordedAt"`
+	// Sources is the number of ReportDataSources, Reports and
+	// ScheduledReports recorded as having fed the run.
+	Sources int `json:"sources"`
+	// Error is the error writing the lineage failed with, if it failed.
+	Error string `json:"error,omitempty"`
+}
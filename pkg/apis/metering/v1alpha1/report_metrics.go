@@ -0,0 +1,31 @@
+package v1alpha1
+
+// ReportMetrics exports the results of the most recent run of a report as
+// Prometheus gauges on the reporting-operator's metrics endpoint, so alerts
+// can be written on them.
+type ReportMetrics struct {
+	// Gauges are the numeric columns of the results exported as gauges.
+	Gauges []ReportMetricGauge `json:"gauges"`
+	// Labels map columns of the results to the labels of the gauges. The
+	// values of rows with the same value for every label are summed.
+	Labels []ReportMetricLabel `json:"labels,omitempty"`
+	// MaxSeries is the most series exported for each gauge. If there are
+	// more, the series with the smallest values are dropped. Defaults to,
+	// and can't exceed, the reporting-operator's maximum.
+	MaxSeries *int `json:"maxSeries,omitempty"`
+}
+
+type ReportMetricGauge struct {
+	// Column is the name of a numeric column of the results.
+	Column string `json:"column"`
+	// Name is the name of the gauge, defaulting to
+	// metering_report_<column>.
+	Name string `json:"name,omitempty"`
+}
+
+type ReportMetricLabel struct {
+	// Column is the name of a column of the results.
+	Column string `json:"column"`
+	// Label is the name of the label, defaulting to the column's name.
+	Label string `json:"label,omitempty"`
+}
@@ -0,0 +1,94 @@
+package v1alpha1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReportNotificationEvent is an event which a report's notifications can be
+// sent for.
+type ReportNotificationEvent string
+
+const (
+	// ReportNotificationEventSucceeded is sent when a run of the report
+	// succeeds.
+	ReportNotificationEventSucceeded ReportNotificationEvent = "Succeeded"
+	// ReportNotificationEventFailed is sent when a run of the report fails,
+	// and won't be retried.
+	ReportNotificationEventFailed ReportNotificationEvent = "Failed"
+	// ReportNotificationEventCostThresholdExceeded is sent when a run of
+	// the report succeeds, and its results exceed the notification's
+	// costThreshold.
+	ReportNotificationEventCostThresholdExceeded ReportNotificationEvent = "CostThresholdExceeded"
+	// ReportNotificationEventAssertionFailed is sent when a run of the
+	// report succeeds, and its results fail any of the report's
+	// assertions.
+	ReportNotificationEventAssertionFailed ReportNotificationEvent = "AssertionFailed"
+)
+
+// ReportNotification sends a notification when a run of a report completes,
+// fails or exceeds a cost threshold. Exactly one of Email, Slack or Webhook
+// must be set.
+type ReportNotification struct {
+	// Name identifies the notification in the report's status, and must be
+	// unique within the report.
+	Name string `json:"name"`
+	// Events are the events the notification is sent for. Defaults to
+	// Failed, CostThresholdExceeded and AssertionFailed.
+	Events []ReportNotificationEvent `json:"events,omitempty"`
+	// CostThreshold, if set, causes the CostThresholdExceeded event to be
+	// sent when the sum of a column of the results exceeds an amount.
+	CostThreshold *ReportCostThreshold `json:"costThreshold,omitempty"`
+	// AttachResults controls whether the results are attached to emails as
+	// a CSV file.
+	AttachResults bool `json:"attachResults,omitempty"`
+	// Email sends the notification by email, using the
+	// reporting-operator's SMTP server.
+	Email *EmailNotification `json:"email,omitempty"`
+	// Slack posts the notification to a Slack incoming webhook.
+	Slack *SlackNotification `json:"slack,omitempty"`
+	// Webhook posts the notification as JSON to a URL.
+	Webhook *WebhookNotification `json:"webhook,omitempty"`
+}
+
+type ReportCostThreshold struct {
+	// Column is the name of a numeric column of the results, such as
+	// total_cost.
+	Column string `json:"column"`
+	// Amount is the amount the sum of the column must exceed.
+	Amount float64 `json:"amount"`
+}
+
+type EmailNotification struct {
+	// To are the addresses the email is sent to.
+	To []string `json:"to"`
+}
+
+type SlackNotification struct {
+	// WebhookURLSecret is a secret reference in the form
+	// <provider>://<path> containing the URL of the incoming webhook in
+	// the webhook-url key.
+	WebhookURLSecret string `json:"webhookURLSecret"`
+}
+
+type WebhookNotification struct {
+	// URL is the URL the notification is posted to.
+	URL string `json:"url"`
+	// BearerTokenSecret is a secret reference in the form
+	// <provider>://<path> containing the token key sent as a bearer token
+	// with the notification.
+	BearerTokenSecret string `json:"bearerTokenSecret,omitempty"`
+}
+
+// ReportNotificationStatus records the most recent notification sent by one
+// of a report's notifications.
+type ReportNotificationStatus struct {
+	// Name is the name of the notification.
+	Name string `json:"name"`
+	// Event is the event the notification was sent for.
+	Event ReportNotificationEvent `json:"event"`
+	// Time is when the notification was sent, or failed to send.
+	Time meta.Time `json:"time"`
+	// Error is the error sending the notification failed with, if it
+	// failed.
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,55 @@
+package v1alpha1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ReportTemplateList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+	Items         []*ReportTemplate `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ReportTemplate renders the results of a Report or ScheduledReport as a
+// human readable HTML or PDF document, such as an invoice.
+type ReportTemplate struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ReportTemplateSpec `json:"spec"`
+}
+
+// ReportTemplateSpec contains Go templates rendering the document. Title,
+// Header and Footer are text/templates, and HTML is an html/template.
+type ReportTemplateSpec struct {
+	// Title renders the title of the document.
+	Title string `json:"title,omitempty"`
+	// Header renders the text before the table of results.
+	Header string `json:"header,omitempty"`
+	// Footer renders the text after the table of results.
+	Footer string `json:"footer,omitempty"`
+	// Columns are the columns shown in the table of results, defaulting to
+	// every column.
+	Columns []ReportTemplateColumn `json:"columns,omitempty"`
+	// HTML, if set, renders the entire HTML document, replacing the
+	// default document containing the title, header, table of results and
+	// footer. It isn't used for PDF documents.
+	HTML string `json:"html,omitempty"`
+}
+
+type ReportTemplateColumn struct {
+	// Name is the name of the column in the results.
+	Name string `json:"name"`
+	// Title is the heading of the column, defaulting to Name.
+	Title string `json:"title,omitempty"`
+	// Format is the fmt format the column's values are formatted with, such
+	// as %.2f. Defaults to %v.
+	Format string `json:"format,omitempty"`
+	// Total adds the sum of the column to the last row of the table.
+	Total bool `json:"total,omitempty"`
+}
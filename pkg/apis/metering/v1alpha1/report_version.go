@@ -0,0 +1,16 @@
+package v1alpha1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReportVersionStatus records the snapshot of the results written by a run
+// of a versioned report.
+type ReportVersionStatus struct {
+	// ID identifies the version, and is used to fetch its results.
+	ID string `json:"id"`
+	// GeneratedAt is when the version was written, or writing it failed.
+	GeneratedAt meta.Time `json:"generatedAt"`
+	// Error is the error writing the version failed with, if it failed.
+	Error string `json:"error,omitempty"`
+}
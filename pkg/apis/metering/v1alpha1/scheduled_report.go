@@ -29,6 +29,12 @@ type ScheduledReportSpec struct {
 
 	Schedule ScheduledReportSchedule `json:"schedule"`
 
+	// Timezone is the IANA timezone name, such as America/New_York, the
+	// schedule and window are evaluated in, so that daily, weekly and
+	// monthly periods begin at midnight in that timezone. It is also
+	// available to the ReportGenerationQuery. Defaults to UTC.
+	Timezone string `json:"timezone,omitempty"`
+
 	// GracePeriod controls how long after each period to wait until running
 	// the report
 	GracePeriod *meta.Duration `json:"gracePeriod,omitempty"`
@@ -39,8 +45,81 @@ type ScheduledReportSpec struct {
 	// than a log of all runs before it.
 	OverwriteExistingData bool `json:"overwriteExistingData,omitempty"`
 
+	// Window, if set, causes each run of the ScheduledReport to cover a
+	// rolling window ending at the end of the current period, such as month
+	// to date, instead of only the current period. OverwriteExistingData
+	// cannot be used with Window.
+	Window *ScheduledReportWindow `json:"window,omitempty"`
+
+	// CheckpointInterval, if set, splits each run into sub-ranges of this
+	// duration, which are generated and stored independently, allowing a
+	// failed run to resume from the last sub-range stored.
+	CheckpointInterval *meta.Duration `json:"checkpointInterval,omitempty"`
+
 	// Output is the storage location where results are sent.
 	Output *StorageLocationRef `json:"output,omitempty"`
+
+	// RetryPolicy controls how a run which fails is retried before the
+	// ScheduledReport stops running. Defaults to the reporting-operator's
+	// default retry policy.
+	RetryPolicy *ReportRetryPolicy `json:"retryPolicy,omitempty"`
+
+	// ActiveDeadlineSeconds, if set, is how long each run may take before
+	// its queries are cancelled and the run is marked as timed out.
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// Inputs provides values for the inputs declared by the
+	// ReportGenerationQuery.
+	Inputs []ReportGenerationQueryInputValue `json:"inputs,omitempty"`
+
+	// GroupByLabels is a list of pod label keys to group the report's
+	// results by, falling back to the pod's namespace's labels. A column is
+	// added to the report for each label, and the ReportGenerationQuery must
+	// set supportsGroupByLabels.
+	GroupByLabels []string `json:"groupByLabels,omitempty"`
+
+	// CostAllocation controls whether the cost of idle and unallocated
+	// cluster capacity is allocated to the namespaces in the report's
+	// results. The ReportGenerationQuery must set supportsCostAllocation.
+	CostAllocation *ReportCostAllocation `json:"costAllocation,omitempty"`
+
+	// Deliveries are destinations in object stores the report's results
+	// are uploaded to after each successful run.
+	Deliveries []ReportDelivery `json:"deliveries,omitempty"`
+
+	// Notifications are sent when a run of the report succeeds, fails or
+	// exceeds a cost threshold.
+	Notifications []ReportNotification `json:"notifications,omitempty"`
+
+	// Metrics, if set, exports the results of the most recent run of the
+	// report as Prometheus gauges.
+	Metrics *ReportMetrics `json:"metrics,omitempty"`
+
+	// Assertions are checks of the results evaluated after each successful
+	// run. If any fail, the ResultsValid condition is set to False and the
+	// AssertionFailed notification event is sent.
+	Assertions []ReportAssertion `json:"assertions,omitempty"`
+
+	// Budget, if set, is the amount each namespace in the results may cost
+	// in a period, and the actions taken on namespaces which exceed it.
+	Budget *ScheduledReportBudget `json:"budget,omitempty"`
+
+	// Versioned, if true, causes each successful run to write an
+	// immutable snapshot of its results, which can be listed and fetched
+	// through the API after later runs or backfills change the results.
+	Versioned bool `json:"versioned,omitempty"`
+
+	// Amendments, if set, causes an amended run whenever a ReportDataSource
+	// the report depends on imports data for a period it has already
+	// reported on, which records how the late data changed its results
+	// without modifying them.
+	Amendments *ReportAmendments `json:"amendments,omitempty"`
+
+	// Lineage, if true, causes each successful run to record which
+	// ReportDataSources, Prometheus queries, partitions and imports, and
+	// which Reports and ScheduledReports, fed it, which can be fetched
+	// through the API.
+	Lineage bool `json:"lineage,omitempty"`
 }
 
 type ScheduledReportPeriod string
@@ -53,6 +132,19 @@ const (
 	ScheduledReportPeriodMonthly ScheduledReportPeriod = "monthly"
 )
 
+type ScheduledReportWindow struct {
+	// Period is the length of the window, and must be daily, weekly or
+	// monthly. Windows begin at the start of the day, week (Sunday) or
+	// month in the ScheduledReport's timezone.
+	Period ScheduledReportPeriod `json:"period"`
+
+	// Incremental controls whether each run only computes the time slice
+	// that arrived since the last run and appends it to the report table,
+	// rather than recomputing the entire window. The report table is
+	// emptied when a new window begins.
+	Incremental bool `json:"incremental,omitempty"`
+}
+
 type ScheduledReportSchedule struct {
 	Period ScheduledReportPeriod `json:"period"`
 
@@ -64,7 +156,13 @@ type ScheduledReportSchedule struct {
 }
 
 type ScheduledReportScheduleCron struct {
+	// Expression is a standard 5 field cron expression, which also supports
+	// the L, LW, # and day of week L extensions.
 	Expression string `json:"expression,omitempty"`
+	// Timezone is the IANA timezone name, such as America/New_York, the
+	// Expression is evaluated in. Defaults to the ScheduledReport's
+	// timezone.
+	Timezone string `json:"timezone,omitempty"`
 }
 
 type ScheduledReportScheduleHourly struct {
@@ -95,10 +193,42 @@ type ScheduledReportScheduleMonthly struct {
 type ScheduledReportStatus struct {
 	Conditions     []ScheduledReportCondition `json:"conditions,omitempty"`
 	LastReportTime *meta.Time                 `json:"lastReportTime,omitempty"`
+	// Checkpoint is the end of the most recent sub-range stored for the
+	// current run of ScheduledReports with a checkpointInterval.
+	Checkpoint *meta.Time `json:"checkpoint,omitempty"`
+	// Attempts contains the failed attempts of the current run, and is
+	// cleared once the run succeeds.
+	Attempts []ReportAttempt `json:"attempts,omitempty"`
+	// Deliveries contains the status of the most recent upload of the
+	// results to each of the report's delivery destinations.
+	Deliveries []ReportDeliveryStatus `json:"deliveries,omitempty"`
+	// Notifications contains the most recent notification sent by each of
+	// the report's notifications.
+	Notifications []ReportNotificationStatus `json:"notifications,omitempty"`
+
+	// Assertions contains the outcome of each of the report's assertions
+	// in its most recent successful run.
+	Assertions []ReportAssertionStatus `json:"assertions,omitempty"`
+
+	// Budget contains the namespaces which exceeded the report's budget in
+	// its most recent successful run.
+	Budget *ScheduledReportBudgetStatus `json:"budget,omitempty"`
+
+	// LatestVersion is the snapshot of the results written by the most
+	// recent successful run of a versioned report.
+	LatestVersion *ReportVersionStatus `json:"latestVersion,omitempty"`
+
+	// LastAmendment is the outcome of the most recent amended run.
+	LastAmendment *ReportAmendmentStatus `json:"lastAmendment,omitempty"`
+
+	// LatestLineage is the lineage recorded by the most recent successful
+	// run of a report with lineage enabled.
+	LatestLineage *ReportLineageStatus `json:"latestLineage,omitempty"`
 }
 
 type ScheduledReportCondition struct {
-	// Type of ScheduledReport condition, Waiting, Active or Failed.
+	// Type of ScheduledReport condition, Running, Failure, Stale or
+	// ResultsValid.
 	Type ScheduledReportConditionType `json:"type"`
 	// Status of the condition, one of True, False, Unknown.
 	Status v1.ConditionStatus `json:"status"`
@@ -121,4 +251,8 @@ type ScheduledReportConditionType string
 const (
 	ScheduledReportRunning ScheduledReportConditionType = "Running"
 	ScheduledReportFailure ScheduledReportConditionType = "Failure"
+	ScheduledReportStale   ScheduledReportConditionType = "Stale"
+	// ScheduledReportResultsValid is True when the results of the report's
+	// most recent successful run met all of its assertions.
+	ScheduledReportResultsValid ScheduledReportConditionType = "ResultsValid"
 )
@@ -0,0 +1,86 @@
+package v1alpha1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// BudgetExceededAnnotation is set on namespaces which exceed the budget
+	// of a ScheduledReport with enforcement.annotate, to the
+	// <namespace>/<name> of the ScheduledReport.
+	BudgetExceededAnnotation = "metering.openshift.io/budget-exceeded"
+	// BudgetOriginalHardAnnotation is set on ResourceQuotas scaled by a
+	// ScheduledReport's budget enforcement to the JSON encoded hard limits
+	// they had before being scaled, which are restored once the namespace
+	// no longer exceeds its budget.
+	BudgetOriginalHardAnnotation = "metering.openshift.io/budget-original-hard"
+)
+
+// ScheduledReportBudget is the amount each namespace in the results of a
+// ScheduledReport may cost in a period. The namespaces which exceed it are
+// recorded in the ScheduledReport's status after each run, and the actions
+// in Enforcement are taken on them if the reporting-operator's budget
+// enforcement is enabled.
+type ScheduledReportBudget struct {
+	// CostColumn is the numeric column of the results summed for each
+	// namespace, such as total_cost.
+	CostColumn string `json:"costColumn"`
+	// NamespaceColumn is the column of the results containing the
+	// namespace. Defaults to namespace.
+	NamespaceColumn string `json:"namespaceColumn,omitempty"`
+	// Amount is the budget of each namespace for a period.
+	Amount float64 `json:"amount"`
+	// Namespaces overrides Amount for individual namespaces.
+	Namespaces map[string]float64 `json:"namespaces,omitempty"`
+	// Enforcement are the actions taken on namespaces which exceed their
+	// budget.
+	Enforcement BudgetEnforcement `json:"enforcement,omitempty"`
+}
+
+// BudgetEnforcement are the actions taken on namespaces which exceed their
+// budget. The annotation and ResourceQuota are reverted once a later run
+// finds that the namespace no longer exceeds its budget.
+type BudgetEnforcement struct {
+	// Annotate sets the metering.openshift.io/budget-exceeded annotation on
+	// namespaces which exceed their budget.
+	Annotate bool `json:"annotate,omitempty"`
+	// ResourceQuota scales the hard limits of a ResourceQuota in namespaces
+	// which exceed their budget.
+	ResourceQuota *BudgetResourceQuotaAction `json:"resourceQuota,omitempty"`
+	// Webhook posts the namespaces which exceed their budget as JSON to a
+	// URL after each run in which any namespace exceeds its budget.
+	Webhook *WebhookNotification `json:"webhook,omitempty"`
+}
+
+type BudgetResourceQuotaAction struct {
+	// Name is the name of the ResourceQuota in each namespace. Namespaces
+	// without it are left unchanged.
+	Name string `json:"name"`
+	// Factor multiplies each of the ResourceQuota's hard limits, such as
+	// 0.5 to halve them. It must be at least 0 and less than 1.
+	Factor float64 `json:"factor"`
+}
+
+type ScheduledReportBudgetStatus struct {
+	// ExceededNamespaces are the namespaces which exceeded their budget in
+	// the report's most recent successful run.
+	ExceededNamespaces []BudgetNamespaceStatus `json:"exceededNamespaces,omitempty"`
+	// LastCheckTime is when the budget was last checked.
+	LastCheckTime meta.Time `json:"lastCheckTime"`
+	// Error is the error the budget check or its actions failed with, if
+	// any failed.
+	Error string `json:"error,omitempty"`
+}
+
+type BudgetNamespaceStatus struct {
+	Namespace string `json:"namespace"`
+	// Cost is the sum of the cost column for the namespace.
+	Cost float64 `json:"cost"`
+	// Amount is the namespace's budget.
+	Amount float64 `json:"amount"`
+	// Enforced is true if the enforcement actions were taken on the
+	// namespace, which requires the reporting-operator's budget enforcement
+	// to be enabled. They're reverted by the first run in which the
+	// namespace no longer exceeds its budget.
+	Enforced bool `json:"enforced,omitempty"`
+}
@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	"k8s.io/api/core/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -21,15 +22,64 @@ type StorageLocation struct {
 	meta.TypeMeta   `json:",inline"`
 	meta.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec StorageLocationSpec `json:"spec"`
+	Spec   StorageLocationSpec   `json:"spec"`
+	Status StorageLocationStatus `json:"status,omitempty"`
 }
 
+type StorageLocationStatus struct {
+	// Conditions contains the Ready condition, which is set when the
+	// StorageLocation's spec is validated.
+	Conditions []StorageLocationCondition `json:"conditions,omitempty"`
+}
+
+type StorageLocationCondition struct {
+	// Type of StorageLocation condition, currently only Ready.
+	Type StorageLocationConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status v1.ConditionStatus `json:"status"`
+	// Last time the condition was checked.
+	// +optional
+	LastUpdateTime meta.Time `json:"lastUpdateTime,omitempty"`
+	// Last time the condition transit from one status to another.
+	// +optional
+	LastTransitionTime meta.Time `json:"lastTransitionTime,omitempty"`
+	// (brief) reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Human readable message indicating details about last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+type StorageLocationConditionType string
+
+const (
+	// StorageLocationReady is True when the StorageLocation is valid and
+	// tables can be created in it.
+	StorageLocationReady StorageLocationConditionType = "Ready"
+)
+
 type StorageLocationSpec struct {
 	Hive *HiveStorage `json:"hive,omitempty"`
 }
 
 type HiveStorage struct {
 	TableProperties TableProperties `json:"tableProperties"`
+	// Encryption, if set, configures the encryption at rest of the tables
+	// created in this storage.
+	Encryption *HiveStorageEncryption `json:"encryption,omitempty"`
+}
+
+// HiveStorageEncryption configures the encryption at rest of tables. Only
+// one of its fields may be set.
+type HiveStorageEncryption struct {
+	// S3KMSKeyID is the ID or ARN of the AWS KMS key the objects of tables
+	// in an S3 location are encrypted with, using SSE-KMS.
+	S3KMSKeyID string `json:"s3KMSKeyID,omitempty"`
+	// HDFSEncryptionZone is the path of the HDFS encryption zone tables are
+	// created in. If the location isn't set, the encryption zone is used
+	// as the location.
+	HDFSEncryptionZone string `json:"hdfsEncryptionZone,omitempty"`
 }
 
 type StorageLocationRef struct {
@@ -0,0 +1,93 @@
+package util
+
+import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+const (
+	// Degraded reportDataSource conditions:
+	//
+	// ValidationFailedReason is added to a ReportDataSource when the data it
+	// imported violates its validation rules.
+	ValidationFailedReason = "ValidationFailed"
+	// ValidationPassedReason is added to a ReportDataSource when the data it
+	// imported meets its validation rules.
+	ValidationPassedReason = "ValidationPassed"
+
+	// QueryValid reportDataSource and reportGenerationQuery conditions:
+	//
+	// InvalidQueryReason is added to a ReportDataSource when its query has
+	// a syntax error, is rejected by Prometheus, or doesn't exist, and to a
+	// ReportGenerationQuery when its template or SQL is invalid.
+	InvalidQueryReason = "InvalidQuery"
+	// EmptyQueryResultReason is added to a ReportDataSource when its query
+	// is valid, but returned no data when it was dry run.
+	EmptyQueryResultReason = "EmptyQueryResult"
+	// QueryValidatedReason is added to a ReportDataSource or
+	// ReportGenerationQuery when its query is valid.
+	QueryValidatedReason = "QueryValidated"
+
+	// Ready reportDataSource conditions:
+	//
+	// TableCreatedReason is added to a ReportDataSource when its table has
+	// been created.
+	TableCreatedReason = "TableCreated"
+	// TableCreationFailedReason is added to a ReportDataSource when its
+	// table couldn't be created.
+	TableCreationFailedReason = "TableCreationFailed"
+
+	// LastImportSucceeded reportDataSource conditions:
+	//
+	// ImportSucceededReason is added to a ReportDataSource when its most
+	// recent import succeeded.
+	ImportSucceededReason = "ImportSucceeded"
+	// ImportFailedReason is added to a ReportDataSource when its most
+	// recent import failed.
+	ImportFailedReason = "ImportFailed"
+)
+
+// NewReportDataSourceCondition creates a new reportDataSource condition.
+func NewReportDataSourceCondition(condType v1alpha1.ReportDataSourceConditionType, status v1.ConditionStatus, reason, message string) *v1alpha1.ReportDataSourceCondition {
+	return &v1alpha1.ReportDataSourceCondition{
+		Type:               condType,
+		Status:             status,
+		LastUpdateTime:     metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// GetReportDataSourceCondition returns the condition with the provided type.
+func GetReportDataSourceCondition(dataSource *v1alpha1.ReportDataSource, condType v1alpha1.ReportDataSourceConditionType) *v1alpha1.ReportDataSourceCondition {
+	for i := range dataSource.Conditions {
+		c := dataSource.Conditions[i]
+		if c.Type == condType {
+			return &c
+		}
+	}
+	return nil
+}
+
+// SetReportDataSourceCondition updates the reportDataSource to include the provided condition. If the condition that
+// we are about to add already exists and has the same status, reason and message then we are not going to update.
+func SetReportDataSourceCondition(dataSource *v1alpha1.ReportDataSource, condition v1alpha1.ReportDataSourceCondition) {
+	currentCond := GetReportDataSourceCondition(dataSource, condition.Type)
+	if currentCond != nil && currentCond.Status == condition.Status && currentCond.Reason == condition.Reason && currentCond.Message == condition.Message {
+		return
+	}
+	// Do not update lastTransitionTime if the status of the condition doesn't change.
+	if currentCond != nil && currentCond.Status == condition.Status {
+		condition.LastTransitionTime = currentCond.LastTransitionTime
+	}
+	var newConditions []v1alpha1.ReportDataSourceCondition
+	for _, c := range dataSource.Conditions {
+		if c.Type != condition.Type {
+			newConditions = append(newConditions, c)
+		}
+	}
+	dataSource.Conditions = append(newConditions, condition)
+}
@@ -0,0 +1,81 @@
+package util
+
+import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+const (
+	// QueryValid reportGenerationQuery conditions, in addition to
+	// InvalidQueryReason and QueryValidatedReason:
+	//
+	// UnableToValidateQueryReason is added to a ReportGenerationQuery when
+	// its query couldn't be rendered with placeholder report values, or
+	// Presto couldn't be reached to explain it.
+	UnableToValidateQueryReason = "UnableToValidateQuery"
+
+	// Ready reportGenerationQuery conditions:
+	//
+	// ViewCreatedReason is added to a ReportGenerationQuery when its view
+	// has been created.
+	ViewCreatedReason = "ViewCreated"
+	// ViewDisabledReason is added to a ReportGenerationQuery with
+	// view.disabled set when its dependencies are initialized.
+	ViewDisabledReason = "ViewDisabled"
+	// DependenciesNotInitializedReason is added to a ReportGenerationQuery
+	// when the ReportDataSources or ReportGenerationQueries it depends on
+	// don't have their tables or views created yet.
+	DependenciesNotInitializedReason = "DependenciesNotInitialized"
+	// InvalidDependenciesReason is added to a ReportGenerationQuery when
+	// its dependencies are invalid, such as when it depends on a
+	// ReportGenerationQuery with view.disabled set in reportQueries.
+	InvalidDependenciesReason = "InvalidDependencies"
+	// ViewCreationFailedReason is added to a ReportGenerationQuery when its
+	// view couldn't be created.
+	ViewCreationFailedReason = "ViewCreationFailed"
+)
+
+// NewReportGenerationQueryCondition creates a new reportGenerationQuery condition.
+func NewReportGenerationQueryCondition(condType v1alpha1.ReportGenerationQueryConditionType, status v1.ConditionStatus, reason, message string) *v1alpha1.ReportGenerationQueryCondition {
+	return &v1alpha1.ReportGenerationQueryCondition{
+		Type:               condType,
+		Status:             status,
+		LastUpdateTime:     metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// GetReportGenerationQueryCondition returns the condition with the provided type.
+func GetReportGenerationQueryCondition(generationQuery *v1alpha1.ReportGenerationQuery, condType v1alpha1.ReportGenerationQueryConditionType) *v1alpha1.ReportGenerationQueryCondition {
+	for i := range generationQuery.Conditions {
+		c := generationQuery.Conditions[i]
+		if c.Type == condType {
+			return &c
+		}
+	}
+	return nil
+}
+
+// SetReportGenerationQueryCondition updates the reportGenerationQuery to include the provided condition. If the condition that
+// we are about to add already exists and has the same status, reason and message then we are not going to update.
+func SetReportGenerationQueryCondition(generationQuery *v1alpha1.ReportGenerationQuery, condition v1alpha1.ReportGenerationQueryCondition) {
+	currentCond := GetReportGenerationQueryCondition(generationQuery, condition.Type)
+	if currentCond != nil && currentCond.Status == condition.Status && currentCond.Reason == condition.Reason && currentCond.Message == condition.Message {
+		return
+	}
+	// Do not update lastTransitionTime if the status of the condition doesn't change.
+	if currentCond != nil && currentCond.Status == condition.Status {
+		condition.LastTransitionTime = currentCond.LastTransitionTime
+	}
+	var newConditions []v1alpha1.ReportGenerationQueryCondition
+	for _, c := range generationQuery.Conditions {
+		if c.Type != condition.Type {
+			newConditions = append(newConditions, c)
+		}
+	}
+	generationQuery.Conditions = append(newConditions, condition)
+}
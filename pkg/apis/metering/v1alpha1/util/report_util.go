@@ -0,0 +1,99 @@
+package util
+
+import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+const (
+	// Ready and Running report conditions:
+	//
+	// ReportFinishedReason is added to a Report when it has finished and
+	// its results are available.
+	ReportFinishedReason = "ReportFinished"
+	// ReportRunningReason is added to a Report while it's running.
+	ReportRunningReason = "ReportRunning"
+	// ReportPendingReason is added to a Report which hasn't run yet, such
+	// as while it waits for its reporting period and grace period to end.
+	ReportPendingReason = "ReportPending"
+	// RetryScheduledReason is added to a Report when an attempt to run it
+	// failed and it will be retried.
+	RetryScheduledReason = "RetryScheduled"
+	// ReportFailedReason is added to a Report when it has failed and won't
+	// be retried.
+	ReportFailedReason = "ReportFailed"
+
+	// DataComplete report conditions, and Ready and Running report
+	// conditions while it waits for its dependencies:
+	//
+	// DependenciesReadyReason is added to a Report when every dependency
+	// has data for the reporting period.
+	DependenciesReadyReason = "DependenciesReady"
+	// DependenciesNotReadyReason is added to a Report when some of its
+	// dependencies don't have data for the reporting period yet.
+	DependenciesNotReadyReason = "DependenciesNotReady"
+
+	// ResultsValid report and scheduled report conditions:
+	//
+	// AssertionsPassedReason is added to a Report or ScheduledReport when
+	// the results of its most recent run met all of its assertions.
+	AssertionsPassedReason = "AssertionsPassed"
+	// AssertionsFailedReason is added to a Report or ScheduledReport when
+	// the results of its most recent run failed any of its assertions.
+	AssertionsFailedReason = "AssertionsFailed"
+
+	// Report and scheduled report amendment events:
+	//
+	// ReportAmendedReason is recorded for a Report or ScheduledReport when
+	// an amended run compared its results with the results it would have
+	// after a ReportDataSource imported late data.
+	ReportAmendedReason = "ReportAmended"
+	// ReportAmendmentFailedReason is recorded for a Report or
+	// ScheduledReport when an amended run failed.
+	ReportAmendmentFailedReason = "ReportAmendmentFailed"
+)
+
+// NewReportCondition creates a new report condition.
+func NewReportCondition(condType v1alpha1.ReportConditionType, status v1.ConditionStatus, reason, message string) *v1alpha1.ReportCondition {
+	return &v1alpha1.ReportCondition{
+		Type:               condType,
+		Status:             status,
+		LastUpdateTime:     metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// GetReportCondition returns the condition with the provided type.
+func GetReportCondition(status v1alpha1.ReportStatus, condType v1alpha1.ReportConditionType) *v1alpha1.ReportCondition {
+	for i := range status.Conditions {
+		c := status.Conditions[i]
+		if c.Type == condType {
+			return &c
+		}
+	}
+	return nil
+}
+
+// SetReportCondition updates the report to include the provided condition. If the condition that
+// we are about to add already exists and has the same status, reason and message then we are not going to update.
+func SetReportCondition(status *v1alpha1.ReportStatus, condition v1alpha1.ReportCondition) {
+	currentCond := GetReportCondition(*status, condition.Type)
+	if currentCond != nil && currentCond.Status == condition.Status && currentCond.Reason == condition.Reason && currentCond.Message == condition.Message {
+		return
+	}
+	// Do not update lastTransitionTime if the status of the condition doesn't change.
+	if currentCond != nil && currentCond.Status == condition.Status {
+		condition.LastTransitionTime = currentCond.LastTransitionTime
+	}
+	var newConditions []v1alpha1.ReportCondition
+	for _, c := range status.Conditions {
+		if c.Type != condition.Type {
+			newConditions = append(newConditions, c)
+		}
+	}
+	status.Conditions = append(newConditions, condition)
+}
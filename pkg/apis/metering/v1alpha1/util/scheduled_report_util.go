@@ -13,6 +13,16 @@ const (
 	// GenerateReportErrorReason is added to a ScheduledReport when an error
 	// occurs while generating the report data.
 	GenerateReportErrorReason = "GenerateReportError"
+	// DeadlineExceededReason is added to a ScheduledReport when a run is
+	// cancelled because it exceeded the ScheduledReport's
+	// activeDeadlineSeconds.
+	DeadlineExceededReason = "DeadlineExceeded"
+
+	// Stale scheduledReport conditions:
+	//
+	// LastReportTooOldReason is added to a ScheduledReport when its last
+	// successful run is older than its schedule period plus tolerance.
+	LastReportTooOldReason = "LastReportTooOld"
 
 	// Running scheduledReport conditions:
 
@@ -25,6 +35,10 @@ const (
 	// ReportPeriodWaitingReason is added to a ScheduledReport when the report
 	// has to wait until the next scheduled reporting time.
 	ReportPeriodWaitingReason = "ReportPeriodNotFinished"
+	// DataSourcesNotReadyReason is added to a ScheduledReport when the
+	// ReportDataSources it depends on do not have data for the reporting
+	// period yet.
+	DataSourcesNotReadyReason = "DataSourcesNotReady"
 )
 
 // NewScheduledReportCondition creates a new scheduledReport condition.
@@ -0,0 +1,62 @@
+package util
+
+import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+const (
+	// Ready storageLocation conditions:
+	//
+	// StorageLocationValidatedReason is added to a StorageLocation when its
+	// spec is valid.
+	StorageLocationValidatedReason = "StorageLocationValidated"
+	// InvalidStorageLocationReason is added to a StorageLocation when its
+	// spec is invalid.
+	InvalidStorageLocationReason = "InvalidStorageLocation"
+)
+
+// NewStorageLocationCondition creates a new storageLocation condition.
+func NewStorageLocationCondition(condType v1alpha1.StorageLocationConditionType, status v1.ConditionStatus, reason, message string) *v1alpha1.StorageLocationCondition {
+	return &v1alpha1.StorageLocationCondition{
+		Type:               condType,
+		Status:             status,
+		LastUpdateTime:     metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// GetStorageLocationCondition returns the condition with the provided type.
+func GetStorageLocationCondition(status v1alpha1.StorageLocationStatus, condType v1alpha1.StorageLocationConditionType) *v1alpha1.StorageLocationCondition {
+	for i := range status.Conditions {
+		c := status.Conditions[i]
+		if c.Type == condType {
+			return &c
+		}
+	}
+	return nil
+}
+
+// SetStorageLocationCondition updates the storageLocation to include the provided condition. If the condition that
+// we are about to add already exists and has the same status, reason and message then we are not going to update.
+func SetStorageLocationCondition(status *v1alpha1.StorageLocationStatus, condition v1alpha1.StorageLocationCondition) {
+	currentCond := GetStorageLocationCondition(*status, condition.Type)
+	if currentCond != nil && currentCond.Status == condition.Status && currentCond.Reason == condition.Reason && currentCond.Message == condition.Message {
+		return
+	}
+	// Do not update lastTransitionTime if the status of the condition doesn't change.
+	if currentCond != nil && currentCond.Status == condition.Status {
+		condition.LastTransitionTime = currentCond.LastTransitionTime
+	}
+	var newConditions []v1alpha1.StorageLocationCondition
+	for _, c := range status.Conditions {
+		if c.Type != condition.Type {
+			newConditions = append(newConditions, c)
+		}
+	}
+	status.Conditions = append(newConditions, condition)
+}
@@ -1,3 +1,4 @@
+//go:build !ignore_autogenerated
 // +build !ignore_autogenerated
 
 // Code generated by deepcopy-gen. DO NOT EDIT.
@@ -37,138 +38,102 @@ func (in *AWSBillingDataSource) DeepCopy() *AWSBillingDataSource {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GenQueryView) DeepCopyInto(out *GenQueryView) {
+func (in *AzureBlobDeliveryDestination) DeepCopyInto(out *AzureBlobDeliveryDestination) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenQueryView.
-func (in *GenQueryView) DeepCopy() *GenQueryView {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureBlobDeliveryDestination.
+func (in *AzureBlobDeliveryDestination) DeepCopy() *AzureBlobDeliveryDestination {
 	if in == nil {
 		return nil
 	}
-	out := new(GenQueryView)
+	out := new(AzureBlobDeliveryDestination)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HiveStorage) DeepCopyInto(out *HiveStorage) {
+func (in *BudgetEnforcement) DeepCopyInto(out *BudgetEnforcement) {
 	*out = *in
-	in.TableProperties.DeepCopyInto(&out.TableProperties)
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HiveStorage.
-func (in *HiveStorage) DeepCopy() *HiveStorage {
-	if in == nil {
-		return nil
+	if in.ResourceQuota != nil {
+		in, out := &in.ResourceQuota, &out.ResourceQuota
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(BudgetResourceQuotaAction)
+			**out = **in
+		}
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(WebhookNotification)
+			**out = **in
+		}
 	}
-	out := new(HiveStorage)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PrestoTable) DeepCopyInto(out *PrestoTable) {
-	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.State.DeepCopyInto(&out.State)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrestoTable.
-func (in *PrestoTable) DeepCopy() *PrestoTable {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BudgetEnforcement.
+func (in *BudgetEnforcement) DeepCopy() *BudgetEnforcement {
 	if in == nil {
 		return nil
 	}
-	out := new(PrestoTable)
+	out := new(BudgetEnforcement)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PrestoTable) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PrestoTableList) DeepCopyInto(out *PrestoTableList) {
+func (in *BudgetNamespaceStatus) DeepCopyInto(out *BudgetNamespaceStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	out.ListMeta = in.ListMeta
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]*PrestoTable, len(*in))
-		for i := range *in {
-			if (*in)[i] == nil {
-				(*out)[i] = nil
-			} else {
-				(*out)[i] = new(PrestoTable)
-				(*in)[i].DeepCopyInto((*out)[i])
-			}
-		}
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrestoTableList.
-func (in *PrestoTableList) DeepCopy() *PrestoTableList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BudgetNamespaceStatus.
+func (in *BudgetNamespaceStatus) DeepCopy() *BudgetNamespaceStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PrestoTableList)
+	out := new(BudgetNamespaceStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PrestoTableList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PrestoTableState) DeepCopyInto(out *PrestoTableState) {
+func (in *BudgetResourceQuotaAction) DeepCopyInto(out *BudgetResourceQuotaAction) {
 	*out = *in
-	in.Parameters.DeepCopyInto(&out.Parameters)
-	in.Properties.DeepCopyInto(&out.Properties)
-	if in.Partitions != nil {
-		in, out := &in.Partitions, &out.Partitions
-		*out = make([]TablePartition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrestoTableState.
-func (in *PrestoTableState) DeepCopy() *PrestoTableState {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BudgetResourceQuotaAction.
+func (in *BudgetResourceQuotaAction) DeepCopy() *BudgetResourceQuotaAction {
 	if in == nil {
 		return nil
 	}
-	out := new(PrestoTableState)
+	out := new(BudgetResourceQuotaAction)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PrometheusMetricsDataSource) DeepCopyInto(out *PrometheusMetricsDataSource) {
+func (in *DatabaseDataSource) DeepCopyInto(out *DatabaseDataSource) {
 	*out = *in
-	if in.QueryConfig != nil {
-		in, out := &in.QueryConfig, &out.QueryConfig
+	if in.Columns != nil {
+		in, out := &in.Columns, &out.Columns
+		*out = make([]ReportGenerationQueryColumn, len(*in))
+		copy(*out, *in)
+	}
+	if in.RefreshInterval != nil {
+		in, out := &in.RefreshInterval, &out.RefreshInterval
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = new(PrometheusQueryConfig)
-			(*in).DeepCopyInto(*out)
+			*out = new(v1.Duration)
+			**out = **in
 		}
 	}
 	if in.Storage != nil {
@@ -183,317 +148,348 @@ func (in *PrometheusMetricsDataSource) DeepCopyInto(out *PrometheusMetricsDataSo
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusMetricsDataSource.
-func (in *PrometheusMetricsDataSource) DeepCopy() *PrometheusMetricsDataSource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseDataSource.
+func (in *DatabaseDataSource) DeepCopy() *DatabaseDataSource {
 	if in == nil {
 		return nil
 	}
-	out := new(PrometheusMetricsDataSource)
+	out := new(DatabaseDataSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PrometheusQueryConfig) DeepCopyInto(out *PrometheusQueryConfig) {
+func (in *EmailNotification) DeepCopyInto(out *EmailNotification) {
 	*out = *in
-	if in.QueryInterval != nil {
-		in, out := &in.QueryInterval, &out.QueryInterval
-		if *in == nil {
-			*out = nil
-		} else {
-			*out = new(v1.Duration)
-			**out = **in
-		}
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.StepSize != nil {
-		in, out := &in.StepSize, &out.StepSize
-		if *in == nil {
-			*out = nil
-		} else {
-			*out = new(v1.Duration)
-			**out = **in
-		}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailNotification.
+func (in *EmailNotification) DeepCopy() *EmailNotification {
+	if in == nil {
+		return nil
 	}
-	if in.ChunkSize != nil {
-		in, out := &in.ChunkSize, &out.ChunkSize
+	out := new(EmailNotification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GCPBillingDataSource) DeepCopyInto(out *GCPBillingDataSource) {
+	*out = *in
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = new(v1.Duration)
+			*out = new(GCSBucket)
 			**out = **in
 		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusQueryConfig.
-func (in *PrometheusQueryConfig) DeepCopy() *PrometheusQueryConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GCPBillingDataSource.
+func (in *GCPBillingDataSource) DeepCopy() *GCPBillingDataSource {
 	if in == nil {
 		return nil
 	}
-	out := new(PrometheusQueryConfig)
+	out := new(GCPBillingDataSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Report) DeepCopyInto(out *Report) {
+func (in *GCSBucket) DeepCopyInto(out *GCSBucket) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Report.
-func (in *Report) DeepCopy() *Report {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GCSBucket.
+func (in *GCSBucket) DeepCopy() *GCSBucket {
 	if in == nil {
 		return nil
 	}
-	out := new(Report)
+	out := new(GCSBucket)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Report) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportDataSource) DeepCopyInto(out *ReportDataSource) {
+func (in *GCSDeliveryDestination) DeepCopyInto(out *GCSDeliveryDestination) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	out.GCSBucket = in.GCSBucket
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDataSource.
-func (in *ReportDataSource) DeepCopy() *ReportDataSource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GCSDeliveryDestination.
+func (in *GCSDeliveryDestination) DeepCopy() *GCSDeliveryDestination {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportDataSource)
+	out := new(GCSDeliveryDestination)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ReportDataSource) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GenQueryView) DeepCopyInto(out *GenQueryView) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenQueryView.
+func (in *GenQueryView) DeepCopy() *GenQueryView {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(GenQueryView)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportDataSourceList) DeepCopyInto(out *ReportDataSourceList) {
+func (in *HiveStorage) DeepCopyInto(out *HiveStorage) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	out.ListMeta = in.ListMeta
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]*ReportDataSource, len(*in))
-		for i := range *in {
-			if (*in)[i] == nil {
-				(*out)[i] = nil
-			} else {
-				(*out)[i] = new(ReportDataSource)
-				(*in)[i].DeepCopyInto((*out)[i])
-			}
+	in.TableProperties.DeepCopyInto(&out.TableProperties)
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(HiveStorageEncryption)
+			**out = **in
 		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDataSourceList.
-func (in *ReportDataSourceList) DeepCopy() *ReportDataSourceList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HiveStorage.
+func (in *HiveStorage) DeepCopy() *HiveStorage {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportDataSourceList)
+	out := new(HiveStorage)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ReportDataSourceList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HiveStorageEncryption) DeepCopyInto(out *HiveStorageEncryption) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HiveStorageEncryption.
+func (in *HiveStorageEncryption) DeepCopy() *HiveStorageEncryption {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(HiveStorageEncryption)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportDataSourceSpec) DeepCopyInto(out *ReportDataSourceSpec) {
+func (in *KubernetesEventsDataSource) DeepCopyInto(out *KubernetesEventsDataSource) {
 	*out = *in
-	if in.Promsum != nil {
-		in, out := &in.Promsum, &out.Promsum
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CollectionInterval != nil {
+		in, out := &in.CollectionInterval, &out.CollectionInterval
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = new(PrometheusMetricsDataSource)
-			(*in).DeepCopyInto(*out)
+			*out = new(v1.Duration)
+			**out = **in
 		}
 	}
-	if in.AWSBilling != nil {
-		in, out := &in.AWSBilling, &out.AWSBilling
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = new(AWSBillingDataSource)
+			*out = new(StorageLocationRef)
 			(*in).DeepCopyInto(*out)
 		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDataSourceSpec.
-func (in *ReportDataSourceSpec) DeepCopy() *ReportDataSourceSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernetesEventsDataSource.
+func (in *KubernetesEventsDataSource) DeepCopy() *KubernetesEventsDataSource {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportDataSourceSpec)
+	out := new(KubernetesEventsDataSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportGenerationQuery) DeepCopyInto(out *ReportGenerationQuery) {
+func (in *KubernetesObjectsDataSource) DeepCopyInto(out *KubernetesObjectsDataSource) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	if in.CollectionInterval != nil {
+		in, out := &in.CollectionInterval, &out.CollectionInterval
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(StorageLocationRef)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQuery.
-func (in *ReportGenerationQuery) DeepCopy() *ReportGenerationQuery {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernetesObjectsDataSource.
+func (in *KubernetesObjectsDataSource) DeepCopy() *KubernetesObjectsDataSource {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportGenerationQuery)
+	out := new(KubernetesObjectsDataSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ReportGenerationQuery) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportGenerationQueryColumn) DeepCopyInto(out *ReportGenerationQueryColumn) {
+func (in *ObjectStorageCSV) DeepCopyInto(out *ObjectStorageCSV) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryColumn.
-func (in *ReportGenerationQueryColumn) DeepCopy() *ReportGenerationQueryColumn {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStorageCSV.
+func (in *ObjectStorageCSV) DeepCopy() *ObjectStorageCSV {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportGenerationQueryColumn)
+	out := new(ObjectStorageCSV)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportGenerationQueryList) DeepCopyInto(out *ReportGenerationQueryList) {
+func (in *ObjectStorageDataSource) DeepCopyInto(out *ObjectStorageDataSource) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	out.ListMeta = in.ListMeta
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]*ReportGenerationQuery, len(*in))
-		for i := range *in {
-			if (*in)[i] == nil {
-				(*out)[i] = nil
-			} else {
-				(*out)[i] = new(ReportGenerationQuery)
-				(*in)[i].DeepCopyInto((*out)[i])
-			}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(S3Bucket)
+			**out = **in
+		}
+	}
+	if in.GCS != nil {
+		in, out := &in.GCS, &out.GCS
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(GCSBucket)
+			**out = **in
+		}
+	}
+	if in.CSV != nil {
+		in, out := &in.CSV, &out.CSV
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ObjectStorageCSV)
+			**out = **in
+		}
+	}
+	if in.Columns != nil {
+		in, out := &in.Columns, &out.Columns
+		*out = make([]ReportGenerationQueryColumn, len(*in))
+		copy(*out, *in)
+	}
+	if in.RefreshInterval != nil {
+		in, out := &in.RefreshInterval, &out.RefreshInterval
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(StorageLocationRef)
+			(*in).DeepCopyInto(*out)
 		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryList.
-func (in *ReportGenerationQueryList) DeepCopy() *ReportGenerationQueryList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStorageDataSource.
+func (in *ObjectStorageDataSource) DeepCopy() *ObjectStorageDataSource {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportGenerationQueryList)
+	out := new(ObjectStorageDataSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ReportGenerationQueryList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportGenerationQuerySpec) DeepCopyInto(out *ReportGenerationQuerySpec) {
+func (in *PrestoTable) DeepCopyInto(out *PrestoTable) {
 	*out = *in
-	if in.ReportQueries != nil {
-		in, out := &in.ReportQueries, &out.ReportQueries
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.DynamicReportQueries != nil {
-		in, out := &in.DynamicReportQueries, &out.DynamicReportQueries
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.DataSources != nil {
-		in, out := &in.DataSources, &out.DataSources
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.Columns != nil {
-		in, out := &in.Columns, &out.Columns
-		*out = make([]ReportGenerationQueryColumn, len(*in))
-		copy(*out, *in)
-	}
-	out.View = in.View
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.State.DeepCopyInto(&out.State)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQuerySpec.
-func (in *ReportGenerationQuerySpec) DeepCopy() *ReportGenerationQuerySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrestoTable.
+func (in *PrestoTable) DeepCopy() *PrestoTable {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportGenerationQuerySpec)
+	out := new(PrestoTable)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PrestoTable) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportList) DeepCopyInto(out *ReportList) {
+func (in *PrestoTableList) DeepCopyInto(out *PrestoTableList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	out.ListMeta = in.ListMeta
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]*Report, len(*in))
+		*out = make([]*PrestoTable, len(*in))
 		for i := range *in {
 			if (*in)[i] == nil {
 				(*out)[i] = nil
 			} else {
-				(*out)[i] = new(Report)
+				(*out)[i] = new(PrestoTable)
 				(*in)[i].DeepCopyInto((*out)[i])
 			}
 		}
@@ -501,18 +497,18 @@ func (in *ReportList) DeepCopyInto(out *ReportList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportList.
-func (in *ReportList) DeepCopy() *ReportList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrestoTableList.
+func (in *PrestoTableList) DeepCopy() *PrestoTableList {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportList)
+	out := new(PrestoTableList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ReportList) DeepCopyObject() runtime.Object {
+func (in *PrestoTableList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -520,26 +516,51 @@ func (in *ReportList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportPrometheusQuery) DeepCopyInto(out *ReportPrometheusQuery) {
+func (in *PrestoTableState) DeepCopyInto(out *PrestoTableState) {
+	*out = *in
+	in.Parameters.DeepCopyInto(&out.Parameters)
+	in.Properties.DeepCopyInto(&out.Properties)
+	if in.Partitions != nil {
+		in, out := &in.Partitions, &out.Partitions
+		*out = make([]TablePartition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrestoTableState.
+func (in *PrestoTableState) DeepCopy() *PrestoTableState {
+	if in == nil {
+		return nil
+	}
+	out := new(PrestoTableState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PricingModel) DeepCopyInto(out *PricingModel) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportPrometheusQuery.
-func (in *ReportPrometheusQuery) DeepCopy() *ReportPrometheusQuery {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PricingModel.
+func (in *PricingModel) DeepCopy() *PricingModel {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportPrometheusQuery)
+	out := new(PricingModel)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ReportPrometheusQuery) DeepCopyObject() runtime.Object {
+func (in *PricingModel) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -547,18 +568,18 @@ func (in *ReportPrometheusQuery) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportPrometheusQueryList) DeepCopyInto(out *ReportPrometheusQueryList) {
+func (in *PricingModelList) DeepCopyInto(out *PricingModelList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	out.ListMeta = in.ListMeta
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]*ReportPrometheusQuery, len(*in))
+		*out = make([]*PricingModel, len(*in))
 		for i := range *in {
 			if (*in)[i] == nil {
 				(*out)[i] = nil
 			} else {
-				(*out)[i] = new(ReportPrometheusQuery)
+				(*out)[i] = new(PricingModel)
 				(*in)[i].DeepCopyInto((*out)[i])
 			}
 		}
@@ -566,18 +587,18 @@ func (in *ReportPrometheusQueryList) DeepCopyInto(out *ReportPrometheusQueryList
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportPrometheusQueryList.
-func (in *ReportPrometheusQueryList) DeepCopy() *ReportPrometheusQueryList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PricingModelList.
+func (in *PricingModelList) DeepCopy() *PricingModelList {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportPrometheusQueryList)
+	out := new(PricingModelList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ReportPrometheusQueryList) DeepCopyObject() runtime.Object {
+func (in *PricingModelList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -585,37 +606,111 @@ func (in *ReportPrometheusQueryList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportPrometheusQuerySpec) DeepCopyInto(out *ReportPrometheusQuerySpec) {
+func (in *PricingModelNodeRate) DeepCopyInto(out *PricingModelNodeRate) {
 	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportPrometheusQuerySpec.
-func (in *ReportPrometheusQuerySpec) DeepCopy() *ReportPrometheusQuerySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PricingModelNodeRate.
+func (in *PricingModelNodeRate) DeepCopy() *PricingModelNodeRate {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportPrometheusQuerySpec)
+	out := new(PricingModelNodeRate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportSpec) DeepCopyInto(out *ReportSpec) {
+func (in *PricingModelRates) DeepCopyInto(out *PricingModelRates) {
 	*out = *in
-	in.ReportingStart.DeepCopyInto(&out.ReportingStart)
-	in.ReportingEnd.DeepCopyInto(&out.ReportingEnd)
-	if in.GracePeriod != nil {
-		in, out := &in.GracePeriod, &out.GracePeriod
-		if *in == nil {
-			*out = nil
-		} else {
-			*out = new(v1.Duration)
-			**out = **in
+	in.EffectiveFrom.DeepCopyInto(&out.EffectiveFrom)
+	if in.StorageClassGiBMonth != nil {
+		in, out := &in.StorageClassGiBMonth, &out.StorageClassGiBMonth
+		*out = make(map[string]float64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
-	if in.Output != nil {
-		in, out := &in.Output, &out.Output
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]PricingModelNodeRate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PricingModelRates.
+func (in *PricingModelRates) DeepCopy() *PricingModelRates {
+	if in == nil {
+		return nil
+	}
+	out := new(PricingModelRates)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PricingModelSpec) DeepCopyInto(out *PricingModelSpec) {
+	*out = *in
+	if in.Rates != nil {
+		in, out := &in.Rates, &out.Rates
+		*out = make([]PricingModelRates, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PricingModelSpec.
+func (in *PricingModelSpec) DeepCopy() *PricingModelSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PricingModelSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusExemplars) DeepCopyInto(out *PrometheusExemplars) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusExemplars.
+func (in *PrometheusExemplars) DeepCopy() *PrometheusExemplars {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusExemplars)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusMetricsArchive) DeepCopyInto(out *PrometheusMetricsArchive) {
+	*out = *in
+	out.After = in.After
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
 		if *in == nil {
 			*out = nil
 		} else {
@@ -626,28 +721,1817 @@ func (in *ReportSpec) DeepCopyInto(out *ReportSpec) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportSpec.
-func (in *ReportSpec) DeepCopy() *ReportSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusMetricsArchive.
+func (in *PrometheusMetricsArchive) DeepCopy() *PrometheusMetricsArchive {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusMetricsArchive)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusMetricsDataSource) DeepCopyInto(out *PrometheusMetricsDataSource) {
+	*out = *in
+	if in.QueryConfig != nil {
+		in, out := &in.QueryConfig, &out.QueryConfig
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PrometheusQueryConfig)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(StorageLocationRef)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.TargetMetadata != nil {
+		in, out := &in.TargetMetadata, &out.TargetMetadata
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PrometheusTargetMetadata)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Exemplars != nil {
+		in, out := &in.Exemplars, &out.Exemplars
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PrometheusExemplars)
+			**out = **in
+		}
+	}
+	if in.Validation != nil {
+		in, out := &in.Validation, &out.Validation
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PrometheusMetricsValidation)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Archive != nil {
+		in, out := &in.Archive, &out.Archive
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PrometheusMetricsArchive)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusMetricsDataSource.
+func (in *PrometheusMetricsDataSource) DeepCopy() *PrometheusMetricsDataSource {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusMetricsDataSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusMetricsValidation) DeepCopyInto(out *PrometheusMetricsValidation) {
+	*out = *in
+	if in.MinSamplesPerHour != nil {
+		in, out := &in.MinSamplesPerHour, &out.MinSamplesPerHour
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	if in.AllowedLabelKeys != nil {
+		in, out := &in.AllowedLabelKeys, &out.AllowedLabelKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MinValue != nil {
+		in, out := &in.MinValue, &out.MinValue
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	if in.MaxValue != nil {
+		in, out := &in.MaxValue, &out.MaxValue
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusMetricsValidation.
+func (in *PrometheusMetricsValidation) DeepCopy() *PrometheusMetricsValidation {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusMetricsValidation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusQueryConfig) DeepCopyInto(out *PrometheusQueryConfig) {
+	*out = *in
+	if in.QueryInterval != nil {
+		in, out := &in.QueryInterval, &out.QueryInterval
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
+	if in.StepSize != nil {
+		in, out := &in.StepSize, &out.StepSize
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
+	if in.ChunkSize != nil {
+		in, out := &in.ChunkSize, &out.ChunkSize
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusQueryConfig.
+func (in *PrometheusQueryConfig) DeepCopy() *PrometheusQueryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusQueryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusTargetMetadata) DeepCopyInto(out *PrometheusTargetMetadata) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusTargetMetadata.
+func (in *PrometheusTargetMetadata) DeepCopy() *PrometheusTargetMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusTargetMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteReportAPI) DeepCopyInto(out *RemoteReportAPI) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteReportAPI.
+func (in *RemoteReportAPI) DeepCopy() *RemoteReportAPI {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteReportAPI)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteReportDataSource) DeepCopyInto(out *RemoteReportDataSource) {
+	*out = *in
+	if in.Columns != nil {
+		in, out := &in.Columns, &out.Columns
+		*out = make([]ReportGenerationQueryColumn, len(*in))
+		copy(*out, *in)
+	}
+	if in.API != nil {
+		in, out := &in.API, &out.API
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RemoteReportAPI)
+			**out = **in
+		}
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RemoteReportS3)
+			**out = **in
+		}
+	}
+	if in.PollInterval != nil {
+		in, out := &in.PollInterval, &out.PollInterval
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(StorageLocationRef)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteReportDataSource.
+func (in *RemoteReportDataSource) DeepCopy() *RemoteReportDataSource {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteReportDataSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteReportS3) DeepCopyInto(out *RemoteReportS3) {
+	*out = *in
+	out.S3Bucket = in.S3Bucket
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteReportS3.
+func (in *RemoteReportS3) DeepCopy() *RemoteReportS3 {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteReportS3)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Report) DeepCopyInto(out *Report) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Report.
+func (in *Report) DeepCopy() *Report {
+	if in == nil {
+		return nil
+	}
+	out := new(Report)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Report) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportAmendmentStatus) DeepCopyInto(out *ReportAmendmentStatus) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.DataSources != nil {
+		in, out := &in.DataSources, &out.DataSources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.PeriodStart.DeepCopyInto(&out.PeriodStart)
+	in.PeriodEnd.DeepCopyInto(&out.PeriodEnd)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportAmendmentStatus.
+func (in *ReportAmendmentStatus) DeepCopy() *ReportAmendmentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportAmendmentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportAmendments) DeepCopyInto(out *ReportAmendments) {
+	*out = *in
+	if in.KeyColumns != nil {
+		in, out := &in.KeyColumns, &out.KeyColumns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportAmendments.
+func (in *ReportAmendments) DeepCopy() *ReportAmendments {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportAmendments)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportAssertion) DeepCopyInto(out *ReportAssertion) {
+	*out = *in
+	if in.RowCount != nil {
+		in, out := &in.RowCount, &out.RowCount
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportRowCountAssertion)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.NotNull != nil {
+		in, out := &in.NotNull, &out.NotNull
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportNotNullAssertion)
+			**out = **in
+		}
+	}
+	if in.Sum != nil {
+		in, out := &in.Sum, &out.Sum
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportSumAssertion)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportAssertion.
+func (in *ReportAssertion) DeepCopy() *ReportAssertion {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportAssertion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportAssertionStatus) DeepCopyInto(out *ReportAssertionStatus) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportAssertionStatus.
+func (in *ReportAssertionStatus) DeepCopy() *ReportAssertionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportAssertionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportAttempt) DeepCopyInto(out *ReportAttempt) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.EndTime.DeepCopyInto(&out.EndTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportAttempt.
+func (in *ReportAttempt) DeepCopy() *ReportAttempt {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportAttempt)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportCondition) DeepCopyInto(out *ReportCondition) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportCondition.
+func (in *ReportCondition) DeepCopy() *ReportCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportCostAllocation) DeepCopyInto(out *ReportCostAllocation) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportCostAllocation.
+func (in *ReportCostAllocation) DeepCopy() *ReportCostAllocation {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportCostAllocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportCostThreshold) DeepCopyInto(out *ReportCostThreshold) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportCostThreshold.
+func (in *ReportCostThreshold) DeepCopy() *ReportCostThreshold {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportCostThreshold)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportDataSource) DeepCopyInto(out *ReportDataSource) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ReportDataSourceCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DataCompleteness != nil {
+		in, out := &in.DataCompleteness, &out.DataCompleteness
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportDataSourceDataCompleteness)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDataSource.
+func (in *ReportDataSource) DeepCopy() *ReportDataSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportDataSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReportDataSource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportDataSourceCondition) DeepCopyInto(out *ReportDataSourceCondition) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDataSourceCondition.
+func (in *ReportDataSourceCondition) DeepCopy() *ReportDataSourceCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportDataSourceCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportDataSourceDataCompleteness) DeepCopyInto(out *ReportDataSourceDataCompleteness) {
+	*out = *in
+	in.Start.DeepCopyInto(&out.Start)
+	in.End.DeepCopyInto(&out.End)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDataSourceDataCompleteness.
+func (in *ReportDataSourceDataCompleteness) DeepCopy() *ReportDataSourceDataCompleteness {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportDataSourceDataCompleteness)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportDataSourceList) DeepCopyInto(out *ReportDataSourceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]*ReportDataSource, len(*in))
+		for i := range *in {
+			if (*in)[i] == nil {
+				(*out)[i] = nil
+			} else {
+				(*out)[i] = new(ReportDataSource)
+				(*in)[i].DeepCopyInto((*out)[i])
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDataSourceList.
+func (in *ReportDataSourceList) DeepCopy() *ReportDataSourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportDataSourceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReportDataSourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportDataSourceSpec) DeepCopyInto(out *ReportDataSourceSpec) {
+	*out = *in
+	if in.Promsum != nil {
+		in, out := &in.Promsum, &out.Promsum
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PrometheusMetricsDataSource)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.AWSBilling != nil {
+		in, out := &in.AWSBilling, &out.AWSBilling
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(AWSBillingDataSource)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.GCPBilling != nil {
+		in, out := &in.GCPBilling, &out.GCPBilling
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(GCPBillingDataSource)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.KubernetesObjects != nil {
+		in, out := &in.KubernetesObjects, &out.KubernetesObjects
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(KubernetesObjectsDataSource)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.RemoteReport != nil {
+		in, out := &in.RemoteReport, &out.RemoteReport
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RemoteReportDataSource)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.ObjectStorage != nil {
+		in, out := &in.ObjectStorage, &out.ObjectStorage
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ObjectStorageDataSource)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(DatabaseDataSource)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.KubernetesEvents != nil {
+		in, out := &in.KubernetesEvents, &out.KubernetesEvents
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(KubernetesEventsDataSource)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDataSourceSpec.
+func (in *ReportDataSourceSpec) DeepCopy() *ReportDataSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportDataSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportDelivery) DeepCopyInto(out *ReportDelivery) {
+	*out = *in
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(S3DeliveryDestination)
+			**out = **in
+		}
+	}
+	if in.GCS != nil {
+		in, out := &in.GCS, &out.GCS
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(GCSDeliveryDestination)
+			**out = **in
+		}
+	}
+	if in.Azure != nil {
+		in, out := &in.Azure, &out.Azure
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(AzureBlobDeliveryDestination)
+			**out = **in
+		}
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(WebhookDeliveryDestination)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDelivery.
+func (in *ReportDelivery) DeepCopy() *ReportDelivery {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportDelivery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportDeliveryStatus) DeepCopyInto(out *ReportDeliveryStatus) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDeliveryStatus.
+func (in *ReportDeliveryStatus) DeepCopy() *ReportDeliveryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportDeliveryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportDependencyStatus) DeepCopyInto(out *ReportDependencyStatus) {
+	*out = *in
+	if in.LastDataTime != nil {
+		in, out := &in.LastDataTime, &out.LastDataTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportDependencyStatus.
+func (in *ReportDependencyStatus) DeepCopy() *ReportDependencyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportDependencyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportFanOut) DeepCopyInto(out *ReportFanOut) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.LabelSelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportFanOut.
+func (in *ReportFanOut) DeepCopy() *ReportFanOut {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportFanOut)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportFanOutStatus) DeepCopyInto(out *ReportFanOutStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportFanOutStatus.
+func (in *ReportFanOutStatus) DeepCopy() *ReportFanOutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportFanOutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportGenerationQuery) DeepCopyInto(out *ReportGenerationQuery) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Revisions != nil {
+		in, out := &in.Revisions, &out.Revisions
+		*out = make([]ReportGenerationQueryRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ReportGenerationQueryCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQuery.
+func (in *ReportGenerationQuery) DeepCopy() *ReportGenerationQuery {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportGenerationQuery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReportGenerationQuery) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportGenerationQueryColumn) DeepCopyInto(out *ReportGenerationQueryColumn) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryColumn.
+func (in *ReportGenerationQueryColumn) DeepCopy() *ReportGenerationQueryColumn {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportGenerationQueryColumn)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportGenerationQueryCondition) DeepCopyInto(out *ReportGenerationQueryCondition) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryCondition.
+func (in *ReportGenerationQueryCondition) DeepCopy() *ReportGenerationQueryCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportGenerationQueryCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportGenerationQueryInputDefinition) DeepCopyInto(out *ReportGenerationQueryInputDefinition) {
+	*out = *in
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(string)
+			**out = **in
+		}
+	}
+	if in.AllowedValues != nil {
+		in, out := &in.AllowedValues, &out.AllowedValues
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryInputDefinition.
+func (in *ReportGenerationQueryInputDefinition) DeepCopy() *ReportGenerationQueryInputDefinition {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportGenerationQueryInputDefinition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportGenerationQueryInputValue) DeepCopyInto(out *ReportGenerationQueryInputValue) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryInputValue.
+func (in *ReportGenerationQueryInputValue) DeepCopy() *ReportGenerationQueryInputValue {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportGenerationQueryInputValue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportGenerationQueryList) DeepCopyInto(out *ReportGenerationQueryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]*ReportGenerationQuery, len(*in))
+		for i := range *in {
+			if (*in)[i] == nil {
+				(*out)[i] = nil
+			} else {
+				(*out)[i] = new(ReportGenerationQuery)
+				(*in)[i].DeepCopyInto((*out)[i])
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryList.
+func (in *ReportGenerationQueryList) DeepCopy() *ReportGenerationQueryList {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportGenerationQueryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReportGenerationQueryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportGenerationQueryRevision) DeepCopyInto(out *ReportGenerationQueryRevision) {
+	*out = *in
+	in.ValidFrom.DeepCopyInto(&out.ValidFrom)
+	if in.ValidUntil != nil {
+		in, out := &in.ValidUntil, &out.ValidUntil
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.Columns != nil {
+		in, out := &in.Columns, &out.Columns
+		*out = make([]ReportGenerationQueryColumn, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQueryRevision.
+func (in *ReportGenerationQueryRevision) DeepCopy() *ReportGenerationQueryRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportGenerationQueryRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportGenerationQuerySpec) DeepCopyInto(out *ReportGenerationQuerySpec) {
+	*out = *in
+	if in.ReportQueries != nil {
+		in, out := &in.ReportQueries, &out.ReportQueries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DynamicReportQueries != nil {
+		in, out := &in.DynamicReportQueries, &out.DynamicReportQueries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DataSources != nil {
+		in, out := &in.DataSources, &out.DataSources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Columns != nil {
+		in, out := &in.Columns, &out.Columns
+		*out = make([]ReportGenerationQueryColumn, len(*in))
+		copy(*out, *in)
+	}
+	out.View = in.View
+	if in.Reports != nil {
+		in, out := &in.Reports, &out.Reports
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ScheduledReports != nil {
+		in, out := &in.ScheduledReports, &out.ScheduledReports
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make([]ReportGenerationQueryInputDefinition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportGenerationQuerySpec.
+func (in *ReportGenerationQuerySpec) DeepCopy() *ReportGenerationQuerySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportGenerationQuerySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportLineageStatus) DeepCopyInto(out *ReportLineageStatus) {
+	*out = *in
+	in.RecordedAt.DeepCopyInto(&out.RecordedAt)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportLineageStatus.
+func (in *ReportLineageStatus) DeepCopy() *ReportLineageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportLineageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportList) DeepCopyInto(out *ReportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]*Report, len(*in))
+		for i := range *in {
+			if (*in)[i] == nil {
+				(*out)[i] = nil
+			} else {
+				(*out)[i] = new(Report)
+				(*in)[i].DeepCopyInto((*out)[i])
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportList.
+func (in *ReportList) DeepCopy() *ReportList {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportMetricGauge) DeepCopyInto(out *ReportMetricGauge) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportMetricGauge.
+func (in *ReportMetricGauge) DeepCopy() *ReportMetricGauge {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportMetricGauge)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportMetricLabel) DeepCopyInto(out *ReportMetricLabel) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportMetricLabel.
+func (in *ReportMetricLabel) DeepCopy() *ReportMetricLabel {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportMetricLabel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportMetrics) DeepCopyInto(out *ReportMetrics) {
+	*out = *in
+	if in.Gauges != nil {
+		in, out := &in.Gauges, &out.Gauges
+		*out = make([]ReportMetricGauge, len(*in))
+		copy(*out, *in)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]ReportMetricLabel, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxSeries != nil {
+		in, out := &in.MaxSeries, &out.MaxSeries
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportMetrics.
+func (in *ReportMetrics) DeepCopy() *ReportMetrics {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportMetrics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportNotNullAssertion) DeepCopyInto(out *ReportNotNullAssertion) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportNotNullAssertion.
+func (in *ReportNotNullAssertion) DeepCopy() *ReportNotNullAssertion {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportNotNullAssertion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportNotification) DeepCopyInto(out *ReportNotification) {
+	*out = *in
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]ReportNotificationEvent, len(*in))
+		copy(*out, *in)
+	}
+	if in.CostThreshold != nil {
+		in, out := &in.CostThreshold, &out.CostThreshold
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportCostThreshold)
+			**out = **in
+		}
+	}
+	if in.Email != nil {
+		in, out := &in.Email, &out.Email
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(EmailNotification)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Slack != nil {
+		in, out := &in.Slack, &out.Slack
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(SlackNotification)
+			**out = **in
+		}
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(WebhookNotification)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportNotification.
+func (in *ReportNotification) DeepCopy() *ReportNotification {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportNotification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportNotificationStatus) DeepCopyInto(out *ReportNotificationStatus) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportNotificationStatus.
+func (in *ReportNotificationStatus) DeepCopy() *ReportNotificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportNotificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportPrometheusQuery) DeepCopyInto(out *ReportPrometheusQuery) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportPrometheusQuery.
+func (in *ReportPrometheusQuery) DeepCopy() *ReportPrometheusQuery {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportPrometheusQuery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReportPrometheusQuery) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportPrometheusQueryList) DeepCopyInto(out *ReportPrometheusQueryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]*ReportPrometheusQuery, len(*in))
+		for i := range *in {
+			if (*in)[i] == nil {
+				(*out)[i] = nil
+			} else {
+				(*out)[i] = new(ReportPrometheusQuery)
+				(*in)[i].DeepCopyInto((*out)[i])
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportPrometheusQueryList.
+func (in *ReportPrometheusQueryList) DeepCopy() *ReportPrometheusQueryList {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportPrometheusQueryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReportPrometheusQueryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportPrometheusQuerySpec) DeepCopyInto(out *ReportPrometheusQuerySpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportPrometheusQuerySpec.
+func (in *ReportPrometheusQuerySpec) DeepCopy() *ReportPrometheusQuerySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportPrometheusQuerySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportRetryPolicy) DeepCopyInto(out *ReportRetryPolicy) {
+	*out = *in
+	if in.Backoff != nil {
+		in, out := &in.Backoff, &out.Backoff
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
+	if in.MaxBackoff != nil {
+		in, out := &in.MaxBackoff, &out.MaxBackoff
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
+	if in.Deadline != nil {
+		in, out := &in.Deadline, &out.Deadline
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportRetryPolicy.
+func (in *ReportRetryPolicy) DeepCopy() *ReportRetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportRetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportRowCountAssertion) DeepCopyInto(out *ReportRowCountAssertion) {
+	*out = *in
+	if in.Min != nil {
+		in, out := &in.Min, &out.Min
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportRowCountAssertion.
+func (in *ReportRowCountAssertion) DeepCopy() *ReportRowCountAssertion {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportRowCountAssertion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportSpec) DeepCopyInto(out *ReportSpec) {
+	*out = *in
+	in.ReportingStart.DeepCopyInto(&out.ReportingStart)
+	in.ReportingEnd.DeepCopyInto(&out.ReportingEnd)
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
+	if in.Output != nil {
+		in, out := &in.Output, &out.Output
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(StorageLocationRef)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.CheckpointInterval != nil {
+		in, out := &in.CheckpointInterval, &out.CheckpointInterval
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportRetryPolicy)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make([]ReportGenerationQueryInputValue, len(*in))
+		copy(*out, *in)
+	}
+	if in.GroupByLabels != nil {
+		in, out := &in.GroupByLabels, &out.GroupByLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CostAllocation != nil {
+		in, out := &in.CostAllocation, &out.CostAllocation
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportCostAllocation)
+			**out = **in
+		}
+	}
+	if in.FanOut != nil {
+		in, out := &in.FanOut, &out.FanOut
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportFanOut)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Deliveries != nil {
+		in, out := &in.Deliveries, &out.Deliveries
+		*out = make([]ReportDelivery, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]ReportNotification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportMetrics)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Assertions != nil {
+		in, out := &in.Assertions, &out.Assertions
+		*out = make([]ReportAssertion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Amendments != nil {
+		in, out := &in.Amendments, &out.Amendments
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportAmendments)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportSpec.
+func (in *ReportSpec) DeepCopy() *ReportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportStatus) DeepCopyInto(out *ReportStatus) {
+	*out = *in
+	if in.Dependencies != nil {
+		in, out := &in.Dependencies, &out.Dependencies
+		*out = make([]ReportDependencyStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Checkpoint != nil {
+		in, out := &in.Checkpoint, &out.Checkpoint
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.Attempts != nil {
+		in, out := &in.Attempts, &out.Attempts
+		*out = make([]ReportAttempt, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.FanOutReports != nil {
+		in, out := &in.FanOutReports, &out.FanOutReports
+		*out = make([]ReportFanOutStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deliveries != nil {
+		in, out := &in.Deliveries, &out.Deliveries
+		*out = make([]ReportDeliveryStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]ReportNotificationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Assertions != nil {
+		in, out := &in.Assertions, &out.Assertions
+		*out = make([]ReportAssertionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LatestVersion != nil {
+		in, out := &in.LatestVersion, &out.LatestVersion
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportVersionStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.LastAmendment != nil {
+		in, out := &in.LastAmendment, &out.LastAmendment
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportAmendmentStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.LatestLineage != nil {
+		in, out := &in.LatestLineage, &out.LatestLineage
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportLineageStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ReportCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportStatus.
+func (in *ReportStatus) DeepCopy() *ReportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportSumAssertion) DeepCopyInto(out *ReportSumAssertion) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(float64)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportSumAssertion.
+func (in *ReportSumAssertion) DeepCopy() *ReportSumAssertion {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportSumAssertion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportTemplate) DeepCopyInto(out *ReportTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportTemplate.
+func (in *ReportTemplate) DeepCopy() *ReportTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReportTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportTemplateColumn) DeepCopyInto(out *ReportTemplateColumn) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportTemplateColumn.
+func (in *ReportTemplateColumn) DeepCopy() *ReportTemplateColumn {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportTemplateColumn)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportTemplateList) DeepCopyInto(out *ReportTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]*ReportTemplate, len(*in))
+		for i := range *in {
+			if (*in)[i] == nil {
+				(*out)[i] = nil
+			} else {
+				(*out)[i] = new(ReportTemplate)
+				(*in)[i].DeepCopyInto((*out)[i])
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportTemplateList.
+func (in *ReportTemplateList) DeepCopy() *ReportTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(ReportTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReportTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportTemplateSpec) DeepCopyInto(out *ReportTemplateSpec) {
+	*out = *in
+	if in.Columns != nil {
+		in, out := &in.Columns, &out.Columns
+		*out = make([]ReportTemplateColumn, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportTemplateSpec.
+func (in *ReportTemplateSpec) DeepCopy() *ReportTemplateSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportSpec)
+	out := new(ReportTemplateSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReportStatus) DeepCopyInto(out *ReportStatus) {
+func (in *ReportVersionStatus) DeepCopyInto(out *ReportVersionStatus) {
 	*out = *in
+	in.GeneratedAt.DeepCopyInto(&out.GeneratedAt)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportStatus.
-func (in *ReportStatus) DeepCopy() *ReportStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportVersionStatus.
+func (in *ReportVersionStatus) DeepCopy() *ReportVersionStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ReportStatus)
+	out := new(ReportVersionStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -668,6 +2552,23 @@ func (in *S3Bucket) DeepCopy() *S3Bucket {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3DeliveryDestination) DeepCopyInto(out *S3DeliveryDestination) {
+	*out = *in
+	out.S3Bucket = in.S3Bucket
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3DeliveryDestination.
+func (in *S3DeliveryDestination) DeepCopy() *S3DeliveryDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(S3DeliveryDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScheduledReport) DeepCopyInto(out *ScheduledReport) {
 	*out = *in
@@ -696,6 +2597,52 @@ func (in *ScheduledReport) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledReportBudget) DeepCopyInto(out *ScheduledReportBudget) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make(map[string]float64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Enforcement.DeepCopyInto(&out.Enforcement)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledReportBudget.
+func (in *ScheduledReportBudget) DeepCopy() *ScheduledReportBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledReportBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledReportBudgetStatus) DeepCopyInto(out *ScheduledReportBudgetStatus) {
+	*out = *in
+	if in.ExceededNamespaces != nil {
+		in, out := &in.ExceededNamespaces, &out.ExceededNamespaces
+		*out = make([]BudgetNamespaceStatus, len(*in))
+		copy(*out, *in)
+	}
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledReportBudgetStatus.
+func (in *ScheduledReportBudgetStatus) DeepCopy() *ScheduledReportBudgetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledReportBudgetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScheduledReportCondition) DeepCopyInto(out *ScheduledReportCondition) {
 	*out = *in
@@ -924,6 +2871,24 @@ func (in *ScheduledReportSpec) DeepCopyInto(out *ScheduledReportSpec) {
 			**out = **in
 		}
 	}
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ScheduledReportWindow)
+			**out = **in
+		}
+	}
+	if in.CheckpointInterval != nil {
+		in, out := &in.CheckpointInterval, &out.CheckpointInterval
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Duration)
+			**out = **in
+		}
+	}
 	if in.Output != nil {
 		in, out := &in.Output, &out.Output
 		if *in == nil {
@@ -933,6 +2898,91 @@ func (in *ScheduledReportSpec) DeepCopyInto(out *ScheduledReportSpec) {
 			(*in).DeepCopyInto(*out)
 		}
 	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportRetryPolicy)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make([]ReportGenerationQueryInputValue, len(*in))
+		copy(*out, *in)
+	}
+	if in.GroupByLabels != nil {
+		in, out := &in.GroupByLabels, &out.GroupByLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CostAllocation != nil {
+		in, out := &in.CostAllocation, &out.CostAllocation
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportCostAllocation)
+			**out = **in
+		}
+	}
+	if in.Deliveries != nil {
+		in, out := &in.Deliveries, &out.Deliveries
+		*out = make([]ReportDelivery, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]ReportNotification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportMetrics)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Assertions != nil {
+		in, out := &in.Assertions, &out.Assertions
+		*out = make([]ReportAssertion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Budget != nil {
+		in, out := &in.Budget, &out.Budget
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ScheduledReportBudget)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Amendments != nil {
+		in, out := &in.Amendments, &out.Amendments
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportAmendments)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -964,6 +3014,78 @@ func (in *ScheduledReportStatus) DeepCopyInto(out *ScheduledReportStatus) {
 			*out = (*in).DeepCopy()
 		}
 	}
+	if in.Checkpoint != nil {
+		in, out := &in.Checkpoint, &out.Checkpoint
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.Attempts != nil {
+		in, out := &in.Attempts, &out.Attempts
+		*out = make([]ReportAttempt, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Deliveries != nil {
+		in, out := &in.Deliveries, &out.Deliveries
+		*out = make([]ReportDeliveryStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]ReportNotificationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Assertions != nil {
+		in, out := &in.Assertions, &out.Assertions
+		*out = make([]ReportAssertionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Budget != nil {
+		in, out := &in.Budget, &out.Budget
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ScheduledReportBudgetStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.LatestVersion != nil {
+		in, out := &in.LatestVersion, &out.LatestVersion
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportVersionStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.LastAmendment != nil {
+		in, out := &in.LastAmendment, &out.LastAmendment
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportAmendmentStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.LatestLineage != nil {
+		in, out := &in.LatestLineage, &out.LatestLineage
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ReportLineageStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -977,12 +3099,45 @@ func (in *ScheduledReportStatus) DeepCopy() *ScheduledReportStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledReportWindow) DeepCopyInto(out *ScheduledReportWindow) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledReportWindow.
+func (in *ScheduledReportWindow) DeepCopy() *ScheduledReportWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledReportWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlackNotification) DeepCopyInto(out *SlackNotification) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackNotification.
+func (in *SlackNotification) DeepCopy() *SlackNotification {
+	if in == nil {
+		return nil
+	}
+	out := new(SlackNotification)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageLocation) DeepCopyInto(out *StorageLocation) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -1004,6 +3159,24 @@ func (in *StorageLocation) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageLocationCondition) DeepCopyInto(out *StorageLocationCondition) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageLocationCondition.
+func (in *StorageLocationCondition) DeepCopy() *StorageLocationCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageLocationCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageLocationList) DeepCopyInto(out *StorageLocationList) {
 	*out = *in
@@ -1092,6 +3265,29 @@ func (in *StorageLocationSpec) DeepCopy() *StorageLocationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageLocationStatus) DeepCopyInto(out *StorageLocationStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]StorageLocationCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageLocationStatus.
+func (in *StorageLocationStatus) DeepCopy() *StorageLocationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageLocationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TableParameters) DeepCopyInto(out *TableParameters) {
 	*out = *in
@@ -1151,6 +3347,13 @@ func (in *TableProperties) DeepCopyInto(out *TableProperties) {
 			(*out)[key] = val
 		}
 	}
+	if in.Properties != nil {
+		in, out := &in.Properties, &out.Properties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -1163,3 +3366,35 @@ func (in *TableProperties) DeepCopy() *TableProperties {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookDeliveryDestination) DeepCopyInto(out *WebhookDeliveryDestination) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookDeliveryDestination.
+func (in *WebhookDeliveryDestination) DeepCopy() *WebhookDeliveryDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookDeliveryDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookNotification) DeepCopyInto(out *WebhookNotification) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookNotification.
+func (in *WebhookNotification) DeepCopy() *WebhookNotification {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookNotification)
+	in.DeepCopyInto(out)
+	return out
+}
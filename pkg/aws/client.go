@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
@@ -36,9 +37,15 @@ type manifestRetriever struct {
 	bucket, prefix string
 }
 
-func NewManifestRetriever(region, bucket, prefix string) ManifestRetriever {
+// NewManifestRetriever returns a ManifestRetriever for the bucket and
+// prefix. If creds is nil, the default AWS credential chain is used.
+func NewManifestRetriever(region, bucket, prefix string, creds *credentials.Credentials) ManifestRetriever {
 	awsSession := session.Must(session.NewSession())
-	client := s3.New(awsSession, aws.NewConfig().WithRegion(region))
+	awsConfig := aws.NewConfig().WithRegion(region)
+	if creds != nil {
+		awsConfig = awsConfig.WithCredentials(creds)
+	}
+	client := s3.New(awsSession, awsConfig)
 	return &manifestRetriever{
 		s3API:  client,
 		bucket: bucket,
@@ -0,0 +1,50 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+
+	"github.com/operator-framework/operator-metering/pkg/secrets"
+)
+
+// Keys within a secret containing AWS credentials.
+const (
+	AccessKeyIDKey     = "aws-access-key-id"
+	SecretAccessKeyKey = "aws-secret-access-key"
+	SessionTokenKey    = "aws-session-token"
+
+	secretCredentialsProviderName = "MeteringSecretProvider"
+)
+
+type secretCredentialsProvider struct {
+	resolver *secrets.Resolver
+	ref      secrets.Ref
+	// secret is the secret the current credentials were retrieved from.
+	secret *secrets.Secret
+}
+
+// NewSecretCredentials returns AWS credentials retrieved from the secret ref
+// refers to, which are refreshed once the resolver's cached copy of the
+// secret expires or changes.
+func NewSecretCredentials(resolver *secrets.Resolver, ref secrets.Ref) *credentials.Credentials {
+	return credentials.NewCredentials(&secretCredentialsProvider{resolver: resolver, ref: ref})
+}
+
+func (p *secretCredentialsProvider) Retrieve() (credentials.Value, error) {
+	secret, err := p.resolver.GetSecret(context.Background(), p.ref)
+	if err != nil {
+		return credentials.Value{ProviderName: secretCredentialsProviderName}, err
+	}
+	p.secret = secret
+	return credentials.Value{
+		AccessKeyID:     secret.Data[AccessKeyIDKey],
+		SecretAccessKey: secret.Data[SecretAccessKeyKey],
+		SessionToken:    secret.Data[SessionTokenKey],
+		ProviderName:    secretCredentialsProviderName,
+	}, nil
+}
+
+func (p *secretCredentialsProvider) IsExpired() bool {
+	return p.resolver.Stale(p.ref, p.secret)
+}
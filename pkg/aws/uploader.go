@@ -0,0 +1,48 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// Uploader uploads objects to an S3 bucket.
+type Uploader struct {
+	s3API  s3iface.S3API
+	bucket string
+}
+
+// NewUploader returns an Uploader for the bucket. If endpoint is set, objects
+// are uploaded to the S3 compatible object store at that URL instead of AWS,
+// using path style addressing. If creds is nil, the default AWS credential
+// chain is used.
+func NewUploader(region, endpoint, bucket string, creds *credentials.Credentials) *Uploader {
+	awsSession := session.Must(session.NewSession())
+	awsConfig := aws.NewConfig().WithRegion(region)
+	if endpoint != "" {
+		awsConfig = awsConfig.WithEndpoint(endpoint).WithS3ForcePathStyle(true)
+	}
+	if creds != nil {
+		awsConfig = awsConfig.WithCredentials(creds)
+	}
+	return &Uploader{
+		s3API:  s3.New(awsSession, awsConfig),
+		bucket: bucket,
+	}
+}
+
+// Upload creates or replaces the object at key with body.
+func (u *Uploader) Upload(ctx context.Context, key, contentType string, body []byte) error {
+	_, err := u.s3API.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(u.bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+		Body:        bytes.NewReader(body),
+	})
+	return err
+}
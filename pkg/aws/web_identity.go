@@ -0,0 +1,112 @@
+package aws
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+const (
+	webIdentityCredentialsProviderName = "MeteringWebIdentityProvider"
+
+	// DefaultWebIdentitySessionName is the role session name used when
+	// assuming a role with a web identity token, which identifies the
+	// reporting-operator in CloudTrail.
+	DefaultWebIdentitySessionName = "metering-reporting-operator"
+
+	// defaultSTSRegion is the region of the global STS endpoint, used if a
+	// region isn't configured.
+	defaultSTSRegion = "us-east-1"
+
+	// webIdentityExpiryWindow is how long before the assumed role's
+	// credentials expire that they're refreshed.
+	webIdentityExpiryWindow = 5 * time.Minute
+)
+
+// WebIdentityConfig configures retrieving AWS credentials by assuming an IAM
+// role with a web identity token, such as a projected service account token
+// used by IAM roles for service accounts.
+type WebIdentityConfig struct {
+	RoleARN string
+	// TokenFile is the path of the web identity token. It's read each time
+	// the credentials are refreshed, since projected service account tokens
+	// are rotated by the kubelet.
+	TokenFile   string
+	SessionName string
+}
+
+// Enabled returns true if a role is configured.
+func (cfg WebIdentityConfig) Enabled() bool {
+	return cfg.RoleARN != ""
+}
+
+func (cfg WebIdentityConfig) Valid() error {
+	if cfg.RoleARN != "" && cfg.TokenFile == "" {
+		return fmt.Errorf("a web identity token file must be set to assume the AWS role %s", cfg.RoleARN)
+	}
+	if cfg.RoleARN == "" && cfg.TokenFile != "" {
+		return fmt.Errorf("an AWS role ARN must be set to use the web identity token file %s", cfg.TokenFile)
+	}
+	return nil
+}
+
+// assumeRoleWithWebIdentityAPI is the part of the STS API used to assume
+// roles with web identity tokens.
+type assumeRoleWithWebIdentityAPI interface {
+	AssumeRoleWithWebIdentity(*sts.AssumeRoleWithWebIdentityInput) (*sts.AssumeRoleWithWebIdentityOutput, error)
+}
+
+type webIdentityCredentialsProvider struct {
+	credentials.Expiry
+	client assumeRoleWithWebIdentityAPI
+	cfg    WebIdentityConfig
+}
+
+// NewWebIdentityCredentials returns AWS credentials retrieved by assuming
+// the configured role with the web identity token, which are refreshed
+// before they expire.
+func NewWebIdentityCredentials(cfg WebIdentityConfig) *credentials.Credentials {
+	awsSession := session.Must(session.NewSession())
+	// AssumeRoleWithWebIdentity requests are authenticated by the token,
+	// so they aren't signed.
+	awsConfig := aws.NewConfig().WithCredentials(credentials.AnonymousCredentials)
+	if aws.StringValue(awsSession.Config.Region) == "" {
+		awsConfig = awsConfig.WithRegion(defaultSTSRegion)
+	}
+	return newWebIdentityCredentials(sts.New(awsSession, awsConfig), cfg)
+}
+
+func newWebIdentityCredentials(client assumeRoleWithWebIdentityAPI, cfg WebIdentityConfig) *credentials.Credentials {
+	if cfg.SessionName == "" {
+		cfg.SessionName = DefaultWebIdentitySessionName
+	}
+	return credentials.NewCredentials(&webIdentityCredentialsProvider{client: client, cfg: cfg})
+}
+
+func (p *webIdentityCredentialsProvider) Retrieve() (credentials.Value, error) {
+	token, err := ioutil.ReadFile(p.cfg.TokenFile)
+	if err != nil {
+		return credentials.Value{ProviderName: webIdentityCredentialsProviderName}, fmt.Errorf("unable to read web identity token: %v", err)
+	}
+	out, err := p.client.AssumeRoleWithWebIdentity(&sts.AssumeRoleWithWebIdentityInput{
+		RoleArn:          aws.String(p.cfg.RoleARN),
+		RoleSessionName:  aws.String(p.cfg.SessionName),
+		WebIdentityToken: aws.String(strings.TrimSpace(string(token))),
+	})
+	if err != nil {
+		return credentials.Value{ProviderName: webIdentityCredentialsProviderName}, fmt.Errorf("unable to assume AWS role %s with web identity token: %v", p.cfg.RoleARN, err)
+	}
+	p.SetExpiration(aws.TimeValue(out.Credentials.Expiration), webIdentityExpiryWindow)
+	return credentials.Value{
+		AccessKeyID:     aws.StringValue(out.Credentials.AccessKeyId),
+		SecretAccessKey: aws.StringValue(out.Credentials.SecretAccessKey),
+		SessionToken:    aws.StringValue(out.Credentials.SessionToken),
+		ProviderName:    webIdentityCredentialsProviderName,
+	}, nil
+}
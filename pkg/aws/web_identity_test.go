@@ -0,0 +1,76 @@
+package aws
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSTS struct {
+	expiration time.Time
+	inputs     []*sts.AssumeRoleWithWebIdentityInput
+}
+
+func (f *fakeSTS) AssumeRoleWithWebIdentity(input *sts.AssumeRoleWithWebIdentityInput) (*sts.AssumeRoleWithWebIdentityOutput, error) {
+	f.inputs = append(f.inputs, input)
+	return &sts.AssumeRoleWithWebIdentityOutput{
+		Credentials: &sts.Credentials{
+			AccessKeyId:     aws.String("ASIA" + aws.StringValue(input.WebIdentityToken)),
+			SecretAccessKey: aws.String("secret"),
+			SessionToken:    aws.String("session"),
+			Expiration:      aws.Time(f.expiration),
+		},
+	}, nil
+}
+
+func TestWebIdentityCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metering-web-identity")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("first\n"), 0600))
+
+	now := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	client := &fakeSTS{expiration: now.Add(time.Hour)}
+	provider := &webIdentityCredentialsProvider{
+		client: client,
+		cfg: WebIdentityConfig{
+			RoleARN:     "arn:aws:iam::123456789012:role/metering",
+			TokenFile:   tokenFile,
+			SessionName: DefaultWebIdentitySessionName,
+		},
+	}
+	provider.CurrentTime = func() time.Time { return now }
+
+	value, err := provider.Retrieve()
+	require.NoError(t, err)
+	assert.Equal(t, "ASIAfirst", value.AccessKeyID)
+	assert.Equal(t, "session", value.SessionToken)
+	require.Len(t, client.inputs, 1)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/metering", aws.StringValue(client.inputs[0].RoleArn))
+	assert.Equal(t, DefaultWebIdentitySessionName, aws.StringValue(client.inputs[0].RoleSessionName))
+	assert.False(t, provider.IsExpired())
+
+	// the credentials are refreshed before they expire, using the rotated
+	// token.
+	now = now.Add(time.Hour - webIdentityExpiryWindow + time.Second)
+	assert.True(t, provider.IsExpired())
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("second"), 0600))
+	value, err = provider.Retrieve()
+	require.NoError(t, err)
+	assert.Equal(t, "ASIAsecond", value.AccessKeyID)
+}
+
+func TestWebIdentityConfigValid(t *testing.T) {
+	assert.NoError(t, WebIdentityConfig{}.Valid())
+	assert.NoError(t, WebIdentityConfig{RoleARN: "arn:aws:iam::123456789012:role/metering", TokenFile: "/var/run/secrets/token"}.Valid())
+	assert.Error(t, WebIdentityConfig{RoleARN: "arn:aws:iam::123456789012:role/metering"}.Valid())
+	assert.Error(t, WebIdentityConfig{TokenFile: "/var/run/secrets/token"}.Valid())
+}
@@ -0,0 +1,569 @@
+// Package backup periodically snapshots the state of a Metering
+// installation into a Hive table in HDFS or an object store, and restores a
+// snapshot into a new or rebuilt installation, so that losing the Hive
+// metastore or the Metering resources doesn't lose every historical report.
+//
+// A backup contains every Metering resource, including the PrestoTables
+// recording the schema, location and partitions of each table in the Hive
+// metastore, and optionally a manifest of the files holding each table's
+// data. The data itself is not copied, it's expected to live in storage
+// which outlives the metastore, such as S3.
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/operator-framework/operator-metering/pkg/db"
+	meteringv1alpha1 "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/typed/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	DefaultInterval  = 24 * time.Hour
+	DefaultRetention = 7
+
+	// TableName is the table backups are written to, each backup in its
+	// own partition.
+	TableName = "metering_backups"
+	// IDFormat is the format of backup IDs, which is the UTC time the
+	// backup was taken, so that IDs sort in the order backups were taken.
+	IDFormat = "20060102T150405Z"
+
+	// TableManifestKind is the kind of the records listing the files of a
+	// table's data.
+	TableManifestKind = "TableManifest"
+
+	// maxInsertSize is the approximate size of the INSERT statements
+	// records are written to Presto with, which keeps large backups from
+	// exceeding Presto's maximum query length.
+	maxInsertSize = 512 * 1024
+
+	backupResultSuccess = "success"
+	backupResultFailure = "failure"
+)
+
+var (
+	backupColumns = []hive.Column{
+		{Name: "kind", Type: "string"},
+		{Name: "namespace", Type: "string"},
+		{Name: "name", Type: "string"},
+		{Name: "object", Type: "string"},
+	}
+	backupPartitionColumns = []hive.Column{
+		{Name: "backup", Type: "string"},
+	}
+
+	backupCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "metering",
+			Name:      "backup_total",
+			Help:      "The number of backups taken, by whether taking them succeeded.",
+		},
+		[]string{"result"},
+	)
+	lastBackupTimestampGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "metering",
+			Name:      "backup_last_success_timestamp_seconds",
+			Help:      "The time of the last successful backup, as a Unix timestamp.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(backupCounter)
+	prometheus.MustRegister(lastBackupTimestampGauge)
+}
+
+type Config struct {
+	// Namespace is the namespace resources are backed up from. If empty,
+	// resources in every namespace are backed up.
+	Namespace string
+	// TenantNamespaces also backs up the ReportGenerationQueries,
+	// PricingModels, Reports, ScheduledReports and PrestoTables of tenant
+	// namespaces, which can be any namespace.
+	TenantNamespaces bool
+	// Location is the directory in HDFS or an object store backups are
+	// written to, such as s3a://bucket/metering-backups. If empty, backups
+	// are disabled.
+	Location string
+	// Interval is how often a backup is taken.
+	Interval time.Duration
+	// Retention is the number of backups kept, older backups are deleted
+	// after each backup.
+	Retention int
+	// IncludeDataManifests includes a manifest of the files holding each
+	// table's data, and the number of rows in each, which restores check
+	// against the tables they recreate.
+	IncludeDataManifests bool
+}
+
+func (cfg Config) Enabled() bool {
+	return cfg.Location != ""
+}
+
+func (cfg Config) Valid() error {
+	if !cfg.Enabled() {
+		return nil
+	}
+	if err := ValidateLocation(cfg.Location); err != nil {
+		return fmt.Errorf("invalid backup location: %v", err)
+	}
+	if cfg.Interval <= 0 {
+		return fmt.Errorf("the backup interval must be positive, got %s", cfg.Interval)
+	}
+	if cfg.Retention < 1 {
+		return fmt.Errorf("the backup retention must be at least 1, got %d", cfg.Retention)
+	}
+	return nil
+}
+
+// ValidateLocation returns an error unless location is a directory in HDFS
+// or an object store.
+func ValidateLocation(location string) error {
+	u, err := url.Parse(location)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("location must include the filesystem and bucket or host, such as s3a://bucket/metering-backups, got %q", location)
+	}
+	if strings.ContainsAny(location, "'\"\\") {
+		return fmt.Errorf("location must not contain quotes or backslashes")
+	}
+	return nil
+}
+
+// Record is a single object in a backup, such as a Metering resource.
+type Record struct {
+	Kind      string
+	Namespace string
+	Name      string
+	// Object is the JSON encoded object.
+	Object string
+}
+
+// TableManifest lists the files holding a table's data when it was backed
+// up.
+type TableManifest struct {
+	TableName string              `json:"tableName"`
+	Files     []TableManifestFile `json:"files"`
+	Error     string              `json:"error,omitempty"`
+}
+
+type TableManifestFile struct {
+	Path string `json:"path"`
+	Rows int64  `json:"rows"`
+}
+
+// Backuper takes backups.
+type Backuper struct {
+	logger         log.FieldLogger
+	cfg            Config
+	clock          clock.Clock
+	hiveQueryer    db.Queryer
+	prestoQueryer  presto.ExecQueryer
+	meteringClient meteringv1alpha1.MeteringV1alpha1Interface
+}
+
+func NewBackuper(logger log.FieldLogger, cfg Config, clock clock.Clock, hiveQueryer db.Queryer, prestoQueryer presto.ExecQueryer, meteringClient meteringv1alpha1.MeteringV1alpha1Interface) *Backuper {
+	return &Backuper{
+		logger:         logger,
+		cfg:            cfg,
+		clock:          clock,
+		hiveQueryer:    hiveQueryer,
+		prestoQueryer:  prestoQueryer,
+		meteringClient: meteringClient,
+	}
+}
+
+// Run takes a backup every interval until stopCh is closed.
+func (b *Backuper) Run(stopCh <-chan struct{}) {
+	tick := b.clock.Tick(b.cfg.Interval)
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-tick:
+		}
+		if _, err := b.Backup(); err != nil {
+			b.logger.WithError(err).Errorf("unable to take backup")
+		}
+	}
+}
+
+// Backup takes a backup and deletes the backups older than the retention,
+// returning the ID of the backup.
+func (b *Backuper) Backup() (string, error) {
+	id := b.clock.Now().UTC().Format(IDFormat)
+	logger := b.logger.WithField("backup", id)
+	if err := b.backup(logger, id); err != nil {
+		backupCounter.WithLabelValues(backupResultFailure).Inc()
+		return "", err
+	}
+	backupCounter.WithLabelValues(backupResultSuccess).Inc()
+	lastBackupTimestampGauge.Set(float64(b.clock.Now().Unix()))
+
+	if err := b.deleteExpiredBackups(logger); err != nil {
+		logger.WithError(err).Warnf("unable to delete expired backups")
+	}
+	return id, nil
+}
+
+func (b *Backuper) backup(logger log.FieldLogger, id string) error {
+	logger.Infof("taking backup %s", id)
+	if err := createBackupTable(b.hiveQueryer, TableName, b.cfg.Location, false); err != nil {
+		return err
+	}
+	records, err := b.collectRecords(logger)
+	if err != nil {
+		return err
+	}
+	for _, query := range insertRecordsQueries(records, id) {
+		if err := presto.InsertInto(b.prestoQueryer, TableName, query); err != nil {
+			// a partial backup would be restored as if it were complete.
+			if dropErr := dropBackupPartition(b.hiveQueryer, TableName, id); dropErr != nil {
+				logger.WithError(dropErr).Warnf("unable to delete partial backup %s", id)
+			}
+			return fmt.Errorf("unable to write backup %s: %v", id, err)
+		}
+	}
+	logger.Infof("took backup %s of %d objects", id, len(records))
+	return nil
+}
+
+// collectRecords returns a record of every Metering resource, and of the
+// data manifest of each table if IncludeDataManifests is set. Resources
+// which can be created in tenant namespaces are listed from every namespace
+// if TenantNamespaces is set.
+func (b *Backuper) collectRecords(logger log.FieldLogger) ([]Record, error) {
+	var records []Record
+	add := func(kind, namespace, name string, obj interface{}) error {
+		record, err := newRecord(kind, namespace, name, obj)
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+		return nil
+	}
+
+	opts := meta.ListOptions{}
+	ns := b.cfg.Namespace
+	tenantNS := ns
+	if b.cfg.TenantNamespaces {
+		tenantNS = meta.NamespaceAll
+	}
+	client := b.meteringClient
+
+	storageLocations, err := client.StorageLocations(ns).List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list StorageLocations: %v", err)
+	}
+	for _, obj := range storageLocations.Items {
+		if err := add("StorageLocation", obj.Namespace, obj.Name, obj); err != nil {
+			return nil, err
+		}
+	}
+	prometheusQueries, err := client.ReportPrometheusQueries(ns).List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list ReportPrometheusQueries: %v", err)
+	}
+	for _, obj := range prometheusQueries.Items {
+		if err := add("ReportPrometheusQuery", obj.Namespace, obj.Name, obj); err != nil {
+			return nil, err
+		}
+	}
+	dataSources, err := client.ReportDataSources(ns).List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list ReportDataSources: %v", err)
+	}
+	for _, obj := range dataSources.Items {
+		if err := add("ReportDataSource", obj.Namespace, obj.Name, obj); err != nil {
+			return nil, err
+		}
+	}
+	prestoTables, err := client.PrestoTables(tenantNS).List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list PrestoTables: %v", err)
+	}
+	for _, obj := range prestoTables.Items {
+		if err := add("PrestoTable", obj.Namespace, obj.Name, obj); err != nil {
+			return nil, err
+		}
+	}
+	generationQueries, err := client.ReportGenerationQueries(tenantNS).List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list ReportGenerationQueries: %v", err)
+	}
+	for _, obj := range generationQueries.Items {
+		if err := add("ReportGenerationQuery", obj.Namespace, obj.Name, obj); err != nil {
+			return nil, err
+		}
+	}
+	templates, err := client.ReportTemplates(ns).List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list ReportTemplates: %v", err)
+	}
+	for _, obj := range templates.Items {
+		if err := add("ReportTemplate", obj.Namespace, obj.Name, obj); err != nil {
+			return nil, err
+		}
+	}
+	pricingModels, err := client.PricingModels(tenantNS).List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list PricingModels: %v", err)
+	}
+	for _, obj := range pricingModels.Items {
+		if err := add("PricingModel", obj.Namespace, obj.Name, obj); err != nil {
+			return nil, err
+		}
+	}
+	reports, err := client.Reports(tenantNS).List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list Reports: %v", err)
+	}
+	for _, obj := range reports.Items {
+		if err := add("Report", obj.Namespace, obj.Name, obj); err != nil {
+			return nil, err
+		}
+	}
+	scheduledReports, err := client.ScheduledReports(tenantNS).List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list ScheduledReports: %v", err)
+	}
+	for _, obj := range scheduledReports.Items {
+		if err := add("ScheduledReport", obj.Namespace, obj.Name, obj); err != nil {
+			return nil, err
+		}
+	}
+
+	views, err := collectViews(b.prestoQueryer)
+	if err != nil {
+		return nil, err
+	}
+	for _, view := range views {
+		if err := add(PrestoViewKind, "", view.Name, view); err != nil {
+			return nil, err
+		}
+	}
+
+	if b.cfg.IncludeDataManifests {
+		for _, table := range prestoTables.Items {
+			manifest := collectTableManifest(b.prestoQueryer, table.State.Parameters.Name)
+			if manifest.Error != "" {
+				logger.Warnf("unable to collect the data manifest of table %s: %s", manifest.TableName, manifest.Error)
+			}
+			if err := add(TableManifestKind, table.Namespace, manifest.TableName, manifest); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return records, nil
+}
+
+func newRecord(kind, namespace, name string, obj interface{}) (Record, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return Record{}, fmt.Errorf("unable to encode %s %s: %v", kind, name, err)
+	}
+	return Record{Kind: kind, Namespace: namespace, Name: name, Object: string(data)}, nil
+}
+
+// collectViews returns the definitions of the Presto views in the Hive
+// metastore, which the views of Reports and ReportGenerationQueries are
+// recreated from.
+func collectViews(queryer presto.Queryer) ([]PrestoView, error) {
+	rows, err := queryer.Query("SELECT table_schema, table_name, view_definition FROM information_schema.views WHERE table_schema <> 'information_schema' ORDER BY 1, 2")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list views: %v", err)
+	}
+	views := make([]PrestoView, 0, len(rows))
+	for _, row := range rows {
+		schema, _ := row["table_schema"].(string)
+		name, _ := row["table_name"].(string)
+		definition, ok := row["view_definition"].(string)
+		if name == "" || !ok {
+			return nil, fmt.Errorf("invalid view %q in schema %q", name, schema)
+		}
+		views = append(views, PrestoView{Name: qualifiedName(schema, name), Definition: definition})
+	}
+	return views, nil
+}
+
+// qualifiedName returns the name tables and views in schema are referred
+// to by, which is unqualified in the default schema.
+func qualifiedName(schema, name string) string {
+	if schema == "" || schema == "default" {
+		return name
+	}
+	return schema + "." + name
+}
+
+// collectTableManifest lists the files holding the table's data, and the
+// number of rows in each. Failing to list them is recorded in the manifest
+// rather than failing the backup, as the tables are still restored without
+// it.
+func collectTableManifest(queryer presto.Queryer, tableName string) TableManifest {
+	manifest := TableManifest{TableName: tableName, Files: []TableManifestFile{}}
+	files, err := queryTableFiles(queryer, tableName)
+	if err != nil {
+		manifest.Error = err.Error()
+		return manifest
+	}
+	manifest.Files = files
+	return manifest
+}
+
+func queryTableFiles(queryer presto.Queryer, tableName string) ([]TableManifestFile, error) {
+	rows, err := queryer.Query(fmt.Sprintf(`SELECT "$path" AS path, count(*) AS row_count FROM %s GROUP BY 1 ORDER BY 1`, tableName))
+	if err != nil {
+		return nil, err
+	}
+	files := make([]TableManifestFile, 0, len(rows))
+	for _, row := range rows {
+		path, ok := row["path"].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid path, valueType: %T", row["path"])
+		}
+		count, ok := row["row_count"].(int64)
+		if !ok {
+			return nil, fmt.Errorf("invalid row_count, valueType: %T", row["row_count"])
+		}
+		files = append(files, TableManifestFile{Path: path, Rows: count})
+	}
+	return files, nil
+}
+
+// insertRecordsQueries returns the queries selecting the records as the
+// rows of backup id, split so that each is at most around maxInsertSize.
+func insertRecordsQueries(records []Record, id string) []string {
+	var queries []string
+	var values []string
+	size := 0
+	flush := func() {
+		if len(values) == 0 {
+			return
+		}
+		queries = append(queries, "VALUES "+strings.Join(values, ", "))
+		values = nil
+		size = 0
+	}
+	for _, record := range records {
+		value := fmt.Sprintf("('%s', '%s', '%s', '%s', '%s')", escapeSQLString(record.Kind), escapeSQLString(record.Namespace), escapeSQLString(record.Name), escapeSQLString(record.Object), id)
+		if size+len(value) > maxInsertSize {
+			flush()
+		}
+		values = append(values, value)
+		size += len(value)
+	}
+	flush()
+	return queries
+}
+
+func escapeSQLString(s string) string {
+	return strings.Replace(s, "'", "''", -1)
+}
+
+// deleteExpiredBackups deletes the oldest backups once there are more than
+// the retention.
+func (b *Backuper) deleteExpiredBackups(logger log.FieldLogger) error {
+	ids, err := listBackups(b.hiveQueryer, TableName)
+	if err != nil {
+		return err
+	}
+	for _, id := range expiredBackups(ids, b.cfg.Retention) {
+		logger.Infof("deleting expired backup %s", id)
+		if err := dropBackupPartition(b.hiveQueryer, TableName, id); err != nil {
+			return fmt.Errorf("unable to delete backup %s: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// expiredBackups returns the backups to delete to keep only the newest
+// retention backups.
+func expiredBackups(ids []string, retention int) []string {
+	if len(ids) <= retention {
+		return nil
+	}
+	sorted := append([]string(nil), ids...)
+	sort.Strings(sorted)
+	return sorted[:len(sorted)-retention]
+}
+
+// createBackupTable creates the table backups are stored in at location,
+// and adds the partitions of the backups already stored there, which
+// exist when the table is recreated after the metastore is lost.
+func createBackupTable(queryer db.Queryer, tableName, location string, external bool) error {
+	params := hive.TableParameters{
+		Name:         tableName,
+		Columns:      backupColumns,
+		Partitions:   backupPartitionColumns,
+		IgnoreExists: true,
+	}
+	properties := hive.TableProperties{
+		Location:   location,
+		FileFormat: "orc",
+		External:   external,
+	}
+	if err := hive.ExecuteCreateTable(queryer, params, properties); err != nil {
+		return fmt.Errorf("unable to create table %s: %v", tableName, err)
+	}
+	if err := repairTable(queryer, tableName); err != nil {
+		return err
+	}
+	return nil
+}
+
+// repairTable adds the partitions of the table which exist in its location
+// but not in the metastore.
+func repairTable(queryer db.Queryer, tableName string) error {
+	rows, err := queryer.Query(fmt.Sprintf("MSCK REPAIR TABLE %s", tableName))
+	if err != nil {
+		return fmt.Errorf("unable to add the existing partitions of table %s: %v", tableName, err)
+	}
+	return rows.Close()
+}
+
+// listBackups returns the IDs of the backups in the table.
+func listBackups(queryer db.Queryer, tableName string) ([]string, error) {
+	rows, err := queryer.Query(fmt.Sprintf("SHOW PARTITIONS %s", tableName))
+	if err != nil {
+		return nil, fmt.Errorf("unable to list backups: %v", err)
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var partition string
+		if err := rows.Scan(&partition); err != nil {
+			return nil, err
+		}
+		if id := strings.TrimPrefix(partition, backupPartitionColumns[0].Name+"="); id != partition {
+			ids = append(ids, id)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func dropBackupPartition(queryer db.Queryer, tableName, id string) error {
+	rows, err := queryer.Query(fmt.Sprintf("ALTER TABLE %s DROP IF EXISTS PARTITION (`%s`='%s') PURGE", tableName, backupPartitionColumns[0].Name, id))
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
@@ -0,0 +1,315 @@
+package backup
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	k8stesting "k8s.io/client-go/testing"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/fake"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	mockpresto "github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+var testLogger = log.New()
+
+func TestConfigValid(t *testing.T) {
+	tests := map[string]struct {
+		cfg         Config
+		expectedErr bool
+	}{
+		"disabled": {},
+		"valid": {
+			cfg: Config{Location: "s3a://bucket/metering-backups", Interval: time.Hour, Retention: 1},
+		},
+		"no filesystem": {
+			cfg:         Config{Location: "/metering-backups", Interval: time.Hour, Retention: 1},
+			expectedErr: true,
+		},
+		"quote in location": {
+			cfg:         Config{Location: "s3a://bucket/metering'backups", Interval: time.Hour, Retention: 1},
+			expectedErr: true,
+		},
+		"no interval": {
+			cfg:         Config{Location: "s3a://bucket/metering-backups", Retention: 1},
+			expectedErr: true,
+		},
+		"no retention": {
+			cfg:         Config{Location: "s3a://bucket/metering-backups", Interval: time.Hour},
+			expectedErr: true,
+		},
+	}
+	for name, test := range tests {
+		err := test.cfg.Valid()
+		if test.expectedErr {
+			assert.Error(t, err, name)
+		} else {
+			assert.NoError(t, err, name)
+		}
+	}
+}
+
+func TestExpiredBackups(t *testing.T) {
+	ids := []string{"20190103T000000Z", "20190101T000000Z", "20190104T000000Z", "20190102T000000Z"}
+	assert.Equal(t, []string{"20190101T000000Z", "20190102T000000Z"}, expiredBackups(ids, 2))
+	assert.Empty(t, expiredBackups(ids, 4))
+	assert.Empty(t, expiredBackups(nil, 1))
+}
+
+func TestInsertRecordsQueries(t *testing.T) {
+	records := []Record{
+		{Kind: "Report", Namespace: "metering", Name: "namespace-cpu", Object: `{"spec":{"query":"it's"}}`},
+		{Kind: "PrestoView", Name: "report_namespace_cpu", Object: `{}`},
+	}
+	queries := insertRecordsQueries(records, "20190101T000000Z")
+	require.Len(t, queries, 1)
+	assert.Equal(t, `VALUES ('Report', 'metering', 'namespace-cpu', '{"spec":{"query":"it''s"}}', '20190101T000000Z'), ('PrestoView', '', 'report_namespace_cpu', '{}', '20190101T000000Z')`, queries[0])
+
+	large := Record{Kind: "Report", Name: "large", Object: strings.Repeat("x", maxInsertSize/2)}
+	queries = insertRecordsQueries([]Record{large, large, large}, "20190101T000000Z")
+	assert.Len(t, queries, 3)
+
+	assert.Empty(t, insertRecordsQueries(nil, "20190101T000000Z"))
+}
+
+func TestRestorePartitionsQueries(t *testing.T) {
+	prestoTable := &cbTypes.PrestoTable{
+		State: cbTypes.PrestoTableState{
+			Parameters: cbTypes.TableParameters{
+				Name:       "datasource_pod_request_cpu_cores",
+				Columns:    []hive.Column{{Name: "amount", Type: "double"}},
+				Partitions: []hive.Column{{Name: "dt", Type: "string"}},
+			},
+			Partitions: []cbTypes.TablePartition{
+				{Location: "s3a://archive/datasource_pod_request_cpu_cores/dt=2019-01-01", PartitionSpec: presto.PartitionSpec{"dt": "2019-01-01"}},
+			},
+		},
+	}
+	assert.Equal(t, []string{
+		"MSCK REPAIR TABLE datasource_pod_request_cpu_cores",
+		"ALTER TABLE datasource_pod_request_cpu_cores ADD IF NOT EXISTS PARTITION (`dt`='2019-01-01') LOCATION 's3a://archive/datasource_pod_request_cpu_cores/dt=2019-01-01'",
+	}, restorePartitionsQueries(prestoTable))
+
+	prestoTable.State.Parameters.Partitions = nil
+	assert.Empty(t, restorePartitionsQueries(prestoTable))
+}
+
+func TestPartitionSpecSQL(t *testing.T) {
+	spec := presto.PartitionSpec{"billing_period_start": "20190101", "billing_period_end": "20190201"}
+	assert.Equal(t, "`billing_period_end`='20190201',`billing_period_start`='20190101'", partitionSpecSQL(spec))
+}
+
+func TestMissingFiles(t *testing.T) {
+	expected := []TableManifestFile{{Path: "s3a://bucket/a", Rows: 1}, {Path: "s3a://bucket/b", Rows: 2}}
+	actual := []TableManifestFile{{Path: "s3a://bucket/b", Rows: 2}, {Path: "s3a://bucket/c", Rows: 3}}
+	assert.Equal(t, []string{"s3a://bucket/a"}, missingFiles(expected, actual))
+	assert.Empty(t, missingFiles(expected, expected))
+}
+
+func TestCollectRecords(t *testing.T) {
+	const namespace = "metering"
+	dataSource := &cbTypes.ReportDataSource{
+		ObjectMeta: meta.ObjectMeta{Name: "pod-request-cpu-cores", Namespace: namespace},
+		TableName:  "datasource_pod_request_cpu_cores",
+	}
+	prestoTable := &cbTypes.PrestoTable{
+		ObjectMeta: meta.ObjectMeta{Name: "reportdatasource-pod-request-cpu-cores", Namespace: namespace},
+		State: cbTypes.PrestoTableState{
+			Parameters: cbTypes.TableParameters{Name: "datasource_pod_request_cpu_cores"},
+		},
+	}
+	report := &cbTypes.Report{
+		ObjectMeta: meta.ObjectMeta{Name: "namespace-cpu", Namespace: namespace},
+	}
+	client := fake.NewSimpleClientset(dataSource, prestoTable, report)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	queryer := mockpresto.NewMockExecQueryer(ctrl)
+	queryer.EXPECT().Query(gomock.Any()).DoAndReturn(func(query string) ([]presto.Row, error) {
+		if strings.Contains(query, "information_schema.views") {
+			return []presto.Row{
+				{"table_schema": "default", "table_name": "report_namespace_cpu", "view_definition": "SELECT 1"},
+				{"table_schema": "tenant_acme", "table_name": "report_acme", "view_definition": "SELECT 2"},
+			}, nil
+		}
+		assert.Equal(t, `SELECT "$path" AS path, count(*) AS row_count FROM datasource_pod_request_cpu_cores GROUP BY 1 ORDER BY 1`, query)
+		return []presto.Row{{"path": "s3a://bucket/datasource_pod_request_cpu_cores/000000_0", "row_count": int64(10)}}, nil
+	}).AnyTimes()
+
+	cfg := Config{Namespace: namespace, IncludeDataManifests: true}
+	b := NewBackuper(testLogger, cfg, clock.NewFakeClock(time.Now()), nil, queryer, client.MeteringV1alpha1())
+	records, err := b.collectRecords(testLogger)
+	require.NoError(t, err)
+
+	var kinds []string
+	for _, record := range records {
+		kinds = append(kinds, record.Kind+"/"+record.Name)
+	}
+	assert.Equal(t, []string{
+		"ReportDataSource/pod-request-cpu-cores",
+		"PrestoTable/reportdatasource-pod-request-cpu-cores",
+		"Report/namespace-cpu",
+		"PrestoView/report_namespace_cpu",
+		"PrestoView/tenant_acme.report_acme",
+		"TableManifest/datasource_pod_request_cpu_cores",
+	}, kinds)
+
+	var decoded cbTypes.ReportDataSource
+	require.NoError(t, json.Unmarshal([]byte(records[0].Object), &decoded))
+	assert.Equal(t, dataSource.TableName, decoded.TableName)
+
+	var manifest TableManifest
+	require.NoError(t, json.Unmarshal([]byte(records[5].Object), &manifest))
+	assert.Equal(t, []TableManifestFile{{Path: "s3a://bucket/datasource_pod_request_cpu_cores/000000_0", Rows: 10}}, manifest.Files)
+}
+
+func TestCollectRecordsTenantNamespaces(t *testing.T) {
+	meteringDataSource := &cbTypes.ReportDataSource{
+		ObjectMeta: meta.ObjectMeta{Name: "pod-request-cpu-cores", Namespace: "metering"},
+	}
+	meteringReport := &cbTypes.Report{
+		ObjectMeta: meta.ObjectMeta{Name: "namespace-cpu", Namespace: "metering"},
+	}
+	tenantReport := &cbTypes.Report{
+		ObjectMeta: meta.ObjectMeta{Name: "team-cpu", Namespace: "team-a"},
+	}
+	// ReportDataSources can't be created in tenant namespaces.
+	tenantDataSource := &cbTypes.ReportDataSource{
+		ObjectMeta: meta.ObjectMeta{Name: "other-install", Namespace: "team-a"},
+	}
+
+	tests := map[string]struct {
+		tenantNamespaces bool
+		expectedRecords  []string
+	}{
+		"metering namespace only": {
+			expectedRecords: []string{
+				"ReportDataSource/metering/pod-request-cpu-cores",
+				"Report/metering/namespace-cpu",
+			},
+		},
+		"tenant namespaces": {
+			tenantNamespaces: true,
+			expectedRecords: []string{
+				"ReportDataSource/metering/pod-request-cpu-cores",
+				"Report/metering/namespace-cpu",
+				"Report/team-a/team-cpu",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(meteringDataSource, meteringReport, tenantReport, tenantDataSource)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			queryer := mockpresto.NewMockExecQueryer(ctrl)
+			queryer.EXPECT().Query(gomock.Any()).Return(nil, nil)
+
+			cfg := Config{Namespace: "metering", TenantNamespaces: test.tenantNamespaces}
+			b := NewBackuper(testLogger, cfg, clock.NewFakeClock(time.Now()), nil, queryer, client.MeteringV1alpha1())
+			records, err := b.collectRecords(testLogger)
+			require.NoError(t, err)
+
+			var keys []string
+			for _, record := range records {
+				keys = append(keys, record.Kind+"/"+record.Namespace+"/"+record.Name)
+			}
+			assert.ElementsMatch(t, test.expectedRecords, keys)
+		})
+	}
+}
+
+func TestRestoreResources(t *testing.T) {
+	const namespace = "metering"
+	newRecordForTest := func(kind string, obj interface{ GetName() string }) Record {
+		record, err := newRecord(kind, namespace, obj.GetName(), obj)
+		require.NoError(t, err)
+		return record
+	}
+	dataSource := &cbTypes.ReportDataSource{
+		ObjectMeta: meta.ObjectMeta{Name: "pod-request-cpu-cores", Namespace: namespace, UID: "old-datasource-uid", ResourceVersion: "10"},
+		TableName:  "datasource_pod_request_cpu_cores",
+	}
+	report := &cbTypes.Report{
+		ObjectMeta: meta.ObjectMeta{Name: "namespace-cpu", Namespace: namespace, UID: "old-report-uid"},
+	}
+	dataSourceTable := &cbTypes.PrestoTable{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "reportdatasource-pod-request-cpu-cores",
+			Namespace: namespace,
+			OwnerReferences: []meta.OwnerReference{
+				{Kind: "ReportDataSource", Name: "pod-request-cpu-cores", UID: "old-datasource-uid"},
+			},
+		},
+	}
+	reportTable := &cbTypes.PrestoTable{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "report-namespace-cpu",
+			Namespace: namespace,
+			OwnerReferences: []meta.OwnerReference{
+				{Kind: "report", Name: "namespace-cpu", UID: "old-report-uid"},
+				{Kind: "Deployment", Name: "not-in-backup", UID: "deployment-uid"},
+			},
+		},
+	}
+	// the PrestoTables are first, to check they're restored after their
+	// owners.
+	records := []Record{
+		newRecordForTest("PrestoTable", dataSourceTable),
+		newRecordForTest("PrestoTable", reportTable),
+		newRecordForTest("ReportDataSource", dataSource),
+		newRecordForTest("Report", report),
+		newRecordForTest(PrestoViewKind, &meta.ObjectMeta{Name: "report_namespace_cpu"}),
+	}
+
+	existingReport := report.DeepCopy()
+	existingReport.UID = "existing-report-uid"
+	client := fake.NewSimpleClientset(existingReport)
+	client.PrependReactor("create", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj := action.(k8stesting.CreateAction).GetObject().(meta.Object)
+		obj.SetUID(types.UID("new-" + obj.GetName()))
+		return false, nil, nil
+	})
+
+	r := NewRestorer(testLogger, Config{}, nil, nil, client.MeteringV1alpha1())
+	result := &RestoreResult{}
+	require.NoError(t, r.restoreResources(testLogger, records, result))
+	assert.Equal(t, []string{
+		"reportdatasource/metering/pod-request-cpu-cores",
+		"prestotable/metering/reportdatasource-pod-request-cpu-cores",
+		"prestotable/metering/report-namespace-cpu",
+	}, result.Created)
+	assert.Equal(t, []string{"report/metering/namespace-cpu"}, result.Existing)
+
+	restoredDataSource, err := client.MeteringV1alpha1().ReportDataSources(namespace).Get(dataSource.Name, meta.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, dataSource.TableName, restoredDataSource.TableName)
+	assert.Empty(t, restoredDataSource.ResourceVersion)
+
+	restoredTable, err := client.MeteringV1alpha1().PrestoTables(namespace).Get(dataSourceTable.Name, meta.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []meta.OwnerReference{
+		{Kind: "ReportDataSource", Name: "pod-request-cpu-cores", UID: "new-pod-request-cpu-cores"},
+	}, restoredTable.OwnerReferences)
+
+	restoredTable, err = client.MeteringV1alpha1().PrestoTables(namespace).Get(reportTable.Name, meta.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []meta.OwnerReference{
+		{Kind: "report", Name: "namespace-cpu", UID: "existing-report-uid"},
+	}, restoredTable.OwnerReferences)
+}
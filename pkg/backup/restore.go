@@ -0,0 +1,556 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/db"
+	meteringv1alpha1 "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/typed/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+// PrestoViewKind is the kind of the records of Presto views, such as the
+// results of Reports using the view materialization, whose definitions are
+// stored in the Hive metastore.
+const PrestoViewKind = "PrestoView"
+
+// restoreOrder is the order resources are restored in, so that the
+// resources others depend on exist first. PrestoTables are restored last,
+// as they're owned by the ReportDataSources, Reports and ScheduledReports
+// whose tables they describe.
+var restoreOrder = []string{
+	"StorageLocation",
+	"ReportPrometheusQuery",
+	"ReportDataSource",
+	"ReportGenerationQuery",
+	"ReportTemplate",
+	"PricingModel",
+	"ScheduledReport",
+	"Report",
+	"PrestoTable",
+}
+
+// PrestoView is the definition of a Presto view.
+type PrestoView struct {
+	Name       string `json:"name"`
+	Definition string `json:"definition"`
+}
+
+// RestoreResult describes what a restore recreated.
+type RestoreResult struct {
+	BackupID string
+	// Tables and Views are the tables and views recreated in the Hive
+	// metastore. Tables which still existed are included.
+	Tables []string
+	Views  []string
+	// Created and Existing are the resources which were created, and which
+	// already existed and were left unchanged, as <kind>/<namespace>/<name>.
+	Created  []string
+	Existing []string
+	// MissingFiles are the files listed in the data manifests of the backup
+	// which are no longer part of their table, as <table>: <path>.
+	MissingFiles []string
+}
+
+// Restorer restores backups.
+type Restorer struct {
+	logger         log.FieldLogger
+	cfg            Config
+	hiveQueryer    db.Queryer
+	prestoQueryer  presto.ExecQueryer
+	meteringClient meteringv1alpha1.MeteringV1alpha1Interface
+}
+
+func NewRestorer(logger log.FieldLogger, cfg Config, hiveQueryer db.Queryer, prestoQueryer presto.ExecQueryer, meteringClient meteringv1alpha1.MeteringV1alpha1Interface) *Restorer {
+	return &Restorer{
+		logger:         logger,
+		cfg:            cfg,
+		hiveQueryer:    hiveQueryer,
+		prestoQueryer:  prestoQueryer,
+		meteringClient: meteringClient,
+	}
+}
+
+// ListBackups returns the IDs of the backups in the configured location,
+// oldest first.
+func (r *Restorer) ListBackups() ([]string, error) {
+	if err := createBackupTable(r.hiveQueryer, TableName, r.cfg.Location, false); err != nil {
+		return nil, err
+	}
+	return listBackups(r.hiveQueryer, TableName)
+}
+
+// Restore restores the backup id, or the latest backup if id is empty.
+//
+// The tables and views in the backup are recreated in the Hive metastore
+// over their existing data, and then the resources in the backup are
+// created, keeping the table names in their statuses so the
+// reporting-operator uses the recreated tables rather than creating new
+// ones. Tables, views and resources which still exist are left unchanged,
+// so a restore can be repeated after a failure.
+func (r *Restorer) Restore(id string) (*RestoreResult, error) {
+	ids, err := r.ListBackups()
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("there are no backups in %s", r.cfg.Location)
+		}
+		id = ids[len(ids)-1]
+	} else if !containsString(ids, id) {
+		return nil, fmt.Errorf("backup %s not found in %s", id, r.cfg.Location)
+	}
+	logger := r.logger.WithField("backup", id)
+
+	records, err := r.readRecords(id)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("restoring backup %s of %d objects", id, len(records))
+
+	result := &RestoreResult{BackupID: id}
+	views := make(map[string]bool)
+	for _, record := range records {
+		if record.Kind != PrestoViewKind {
+			continue
+		}
+		views[record.Name] = true
+	}
+	if err := r.restoreTables(logger, records, views, result); err != nil {
+		return nil, err
+	}
+	if err := r.restoreViews(logger, records, result); err != nil {
+		return nil, err
+	}
+	if err := r.restoreResources(logger, records, result); err != nil {
+		return nil, err
+	}
+	r.verifyManifests(logger, records, result)
+	logger.Infof("restored backup %s: %d tables, %d views, %d resources created, %d already existed", id, len(result.Tables), len(result.Views), len(result.Created), len(result.Existing))
+	return result, nil
+}
+
+func (r *Restorer) readRecords(id string) ([]Record, error) {
+	rows, err := r.prestoQueryer.Query(fmt.Sprintf("SELECT kind, namespace, name, object FROM %s WHERE backup = '%s'", TableName, escapeSQLString(id)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read backup %s: %v", id, err)
+	}
+	records := make([]Record, 0, len(rows))
+	for _, row := range rows {
+		var record Record
+		var ok bool
+		for column, value := range map[string]*string{"kind": &record.Kind, "namespace": &record.Namespace, "name": &record.Name, "object": &record.Object} {
+			if *value, ok = row[column].(string); !ok {
+				return nil, fmt.Errorf("invalid %s in backup %s, valueType: %T", column, id, row[column])
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// restoreTables recreates the tables of the PrestoTables in the backup,
+// other than those which are views.
+func (r *Restorer) restoreTables(logger log.FieldLogger, records []Record, views map[string]bool, result *RestoreResult) error {
+	for _, record := range records {
+		if record.Kind != "PrestoTable" {
+			continue
+		}
+		var prestoTable cbTypes.PrestoTable
+		if err := json.Unmarshal([]byte(record.Object), &prestoTable); err != nil {
+			return fmt.Errorf("unable to decode PrestoTable %s: %v", record.Name, err)
+		}
+		tableName := prestoTable.State.Parameters.Name
+		if tableName == "" || views[tableName] {
+			continue
+		}
+		logger.Infof("restoring table %s", tableName)
+		if err := RestoreTable(r.hiveQueryer, &prestoTable); err != nil {
+			return err
+		}
+		result.Tables = append(result.Tables, tableName)
+	}
+	return nil
+}
+
+// RestoreTable creates the table of the PrestoTable unless it exists, with
+// the schema, location and partitions recorded in the PrestoTable, over any
+// data already in its location.
+func RestoreTable(queryer db.Queryer, prestoTable *cbTypes.PrestoTable) error {
+	tableName := prestoTable.State.Parameters.Name
+	if err := ensureSchema(queryer, tableName); err != nil {
+		return err
+	}
+	params := hive.TableParameters(prestoTable.State.Parameters)
+	params.IgnoreExists = true
+	if err := hive.ExecuteCreateTable(queryer, params, hive.TableProperties(prestoTable.State.Properties)); err != nil {
+		return fmt.Errorf("unable to restore table %s: %v", tableName, err)
+	}
+	for _, stmt := range restorePartitionsQueries(prestoTable) {
+		rows, err := queryer.Query(stmt)
+		if err != nil {
+			return fmt.Errorf("unable to restore the partitions of table %s: %v", tableName, err)
+		}
+		rows.Close()
+	}
+	return nil
+}
+
+// ensureSchema creates the schema of a table or view qualified with one,
+// such as the schemas of tenant namespaces.
+func ensureSchema(queryer db.Queryer, name string) error {
+	i := strings.Index(name, ".")
+	if i <= 0 {
+		return nil
+	}
+	rows, err := queryer.Query(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", name[:i]))
+	if err != nil {
+		return fmt.Errorf("unable to create schema %s: %v", name[:i], err)
+	}
+	return rows.Close()
+}
+
+// restorePartitionsQueries returns the Hive statements recreating the
+// partitions of the PrestoTable's table. Partitions in the table's
+// location are added by MSCK REPAIR TABLE, and those elsewhere, such as
+// archived partitions and billing report partitions, from the PrestoTable.
+func restorePartitionsQueries(prestoTable *cbTypes.PrestoTable) []string {
+	tableName := prestoTable.State.Parameters.Name
+	if len(prestoTable.State.Parameters.Partitions) == 0 {
+		return nil
+	}
+	queries := []string{fmt.Sprintf("MSCK REPAIR TABLE %s", tableName)}
+	for _, partition := range prestoTable.State.Partitions {
+		if partition.Location == "" || len(partition.PartitionSpec) == 0 {
+			continue
+		}
+		queries = append(queries, fmt.Sprintf("ALTER TABLE %s ADD IF NOT EXISTS PARTITION (%s) LOCATION '%s'", tableName, partitionSpecSQL(presto.PartitionSpec(partition.PartitionSpec)), escapeSQLString(partition.Location)))
+	}
+	return queries
+}
+
+func partitionSpecSQL(spec presto.PartitionSpec) string {
+	keys := make([]string, 0, len(spec))
+	for key := range spec {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, fmt.Sprintf("`%s`='%s'", key, escapeSQLString(spec[key])))
+	}
+	return strings.Join(values, ",")
+}
+
+// restoreViews recreates the Presto views in the backup, after the tables
+// they select from.
+func (r *Restorer) restoreViews(logger log.FieldLogger, records []Record, result *RestoreResult) error {
+	for _, record := range records {
+		if record.Kind != PrestoViewKind {
+			continue
+		}
+		var view PrestoView
+		if err := json.Unmarshal([]byte(record.Object), &view); err != nil {
+			return fmt.Errorf("unable to decode view %s: %v", record.Name, err)
+		}
+		if err := ensureSchema(r.hiveQueryer, view.Name); err != nil {
+			return err
+		}
+		logger.Infof("restoring view %s", view.Name)
+		if err := presto.CreateOrReplaceView(r.prestoQueryer, view.Name, view.Definition); err != nil {
+			return fmt.Errorf("unable to restore view %s: %v", view.Name, err)
+		}
+		result.Views = append(result.Views, view.Name)
+	}
+	return nil
+}
+
+// restoreResources creates the resources in the backup which don't exist.
+// Resources are created after the resources which own them, so that their
+// owner references can be updated to the UIDs of the recreated owners.
+// Owner references to resources not in the backup are removed, as the
+// garbage collector would delete the resource since the owner's UID no
+// longer exists.
+func (r *Restorer) restoreResources(logger log.FieldLogger, records []Record, result *RestoreResult) error {
+	order := make(map[string]int, len(restoreOrder))
+	for i, kind := range restoreOrder {
+		order[kind] = i
+	}
+	var pending []*backedUpResource
+	inBackup := make(map[string]bool)
+	for _, record := range records {
+		if _, ok := order[record.Kind]; !ok {
+			continue
+		}
+		res, err := decodeResource(record)
+		if err != nil {
+			return err
+		}
+		pending = append(pending, res)
+		inBackup[res.key()] = true
+	}
+	sort.SliceStable(pending, func(i, j int) bool { return order[pending[i].kind] < order[pending[j].kind] })
+
+	uids := make(map[string]types.UID)
+	for len(pending) != 0 {
+		var deferred []*backedUpResource
+		for _, res := range pending {
+			if !ownersRestored(res.objMeta, inBackup, uids) {
+				deferred = append(deferred, res)
+				continue
+			}
+			prepareObjectMeta(res.objMeta, uids)
+			created, uid, err := r.restoreResource(res)
+			if err != nil {
+				return err
+			}
+			uids[res.key()] = uid
+			if created {
+				logger.Infof("restored %s %s/%s", res.kind, res.objMeta.Namespace, res.objMeta.Name)
+				result.Created = append(result.Created, res.key())
+			} else {
+				result.Existing = append(result.Existing, res.key())
+			}
+		}
+		if len(deferred) == len(pending) {
+			// the remaining resources own each other, which only happens if
+			// the backup was modified, so their owners are ignored.
+			for _, res := range deferred {
+				inBackup[res.key()] = false
+			}
+		}
+		pending = deferred
+	}
+	return nil
+}
+
+// backedUpResource is a resource decoded from a backup.
+type backedUpResource struct {
+	kind string
+	obj  interface{}
+	// objMeta is the metadata of obj.
+	objMeta *meta.ObjectMeta
+}
+
+func (res *backedUpResource) key() string {
+	return resourceKey(res.kind, res.objMeta.Namespace, res.objMeta.Name)
+}
+
+// resourceKey identifies a resource. Kinds are compared case
+// insensitively, as the owner references of PrestoTables use lowercase
+// kinds.
+func resourceKey(kind, namespace, name string) string {
+	return strings.ToLower(kind) + "/" + namespace + "/" + name
+}
+
+func decodeResource(record Record) (*backedUpResource, error) {
+	res := &backedUpResource{kind: record.Kind}
+	switch record.Kind {
+	case "StorageLocation":
+		obj := &cbTypes.StorageLocation{}
+		res.obj, res.objMeta = obj, &obj.ObjectMeta
+	case "ReportPrometheusQuery":
+		obj := &cbTypes.ReportPrometheusQuery{}
+		res.obj, res.objMeta = obj, &obj.ObjectMeta
+	case "ReportDataSource":
+		obj := &cbTypes.ReportDataSource{}
+		res.obj, res.objMeta = obj, &obj.ObjectMeta
+	case "ReportGenerationQuery":
+		obj := &cbTypes.ReportGenerationQuery{}
+		res.obj, res.objMeta = obj, &obj.ObjectMeta
+	case "ReportTemplate":
+		obj := &cbTypes.ReportTemplate{}
+		res.obj, res.objMeta = obj, &obj.ObjectMeta
+	case "PricingModel":
+		obj := &cbTypes.PricingModel{}
+		res.obj, res.objMeta = obj, &obj.ObjectMeta
+	case "ScheduledReport":
+		obj := &cbTypes.ScheduledReport{}
+		res.obj, res.objMeta = obj, &obj.ObjectMeta
+	case "Report":
+		obj := &cbTypes.Report{}
+		res.obj, res.objMeta = obj, &obj.ObjectMeta
+	case "PrestoTable":
+		obj := &cbTypes.PrestoTable{}
+		res.obj, res.objMeta = obj, &obj.ObjectMeta
+	default:
+		return nil, fmt.Errorf("unknown kind %s", record.Kind)
+	}
+	if err := json.Unmarshal([]byte(record.Object), res.obj); err != nil {
+		return nil, fmt.Errorf("unable to decode %s %s/%s: %v", record.Kind, record.Namespace, record.Name, err)
+	}
+	return res, nil
+}
+
+// ownersRestored returns true if the owners of the resource which are in
+// the backup have been restored.
+func ownersRestored(objMeta *meta.ObjectMeta, inBackup map[string]bool, uids map[string]types.UID) bool {
+	for _, ref := range objMeta.OwnerReferences {
+		key := resourceKey(ref.Kind, objMeta.Namespace, ref.Name)
+		if _, restored := uids[key]; inBackup[key] && !restored {
+			return false
+		}
+	}
+	return true
+}
+
+// prepareObjectMeta clears the fields of a backed up resource's metadata
+// which are set by the API server, and updates its owner references to the
+// UIDs of the restored owners.
+func prepareObjectMeta(objMeta *meta.ObjectMeta, uids map[string]types.UID) {
+	objMeta.UID = ""
+	objMeta.ResourceVersion = ""
+	objMeta.SelfLink = ""
+	objMeta.Generation = 0
+	objMeta.CreationTimestamp = meta.Time{}
+	objMeta.DeletionTimestamp = nil
+	objMeta.DeletionGracePeriodSeconds = nil
+	var refs []meta.OwnerReference
+	for _, ref := range objMeta.OwnerReferences {
+		uid, ok := uids[resourceKey(ref.Kind, objMeta.Namespace, ref.Name)]
+		if !ok {
+			continue
+		}
+		ref.UID = uid
+		refs = append(refs, ref)
+	}
+	objMeta.OwnerReferences = refs
+}
+
+// restoreResource creates the resource unless it exists, returning whether
+// it was created and its UID. Resources are created with their status,
+// including the names of their tables.
+func (r *Restorer) restoreResource(res *backedUpResource) (bool, types.UID, error) {
+	c := r.meteringClient
+	ns := res.objMeta.Namespace
+	var created meta.Object
+	var err error
+	switch obj := res.obj.(type) {
+	case *cbTypes.StorageLocation:
+		created, err = c.StorageLocations(ns).Create(obj)
+	case *cbTypes.ReportPrometheusQuery:
+		created, err = c.ReportPrometheusQueries(ns).Create(obj)
+	case *cbTypes.ReportDataSource:
+		created, err = c.ReportDataSources(ns).Create(obj)
+	case *cbTypes.ReportGenerationQuery:
+		created, err = c.ReportGenerationQueries(ns).Create(obj)
+	case *cbTypes.ReportTemplate:
+		created, err = c.ReportTemplates(ns).Create(obj)
+	case *cbTypes.PricingModel:
+		created, err = c.PricingModels(ns).Create(obj)
+	case *cbTypes.ScheduledReport:
+		created, err = c.ScheduledReports(ns).Create(obj)
+	case *cbTypes.Report:
+		created, err = c.Reports(ns).Create(obj)
+	case *cbTypes.PrestoTable:
+		created, err = c.PrestoTables(ns).Create(obj)
+	}
+	if err == nil {
+		return true, created.GetUID(), nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return false, "", fmt.Errorf("unable to restore %s %s/%s: %v", res.kind, ns, res.objMeta.Name, err)
+	}
+
+	name := res.objMeta.Name
+	opts := meta.GetOptions{}
+	var existing meta.Object
+	switch res.obj.(type) {
+	case *cbTypes.StorageLocation:
+		existing, err = c.StorageLocations(ns).Get(name, opts)
+	case *cbTypes.ReportPrometheusQuery:
+		existing, err = c.ReportPrometheusQueries(ns).Get(name, opts)
+	case *cbTypes.ReportDataSource:
+		existing, err = c.ReportDataSources(ns).Get(name, opts)
+	case *cbTypes.ReportGenerationQuery:
+		existing, err = c.ReportGenerationQueries(ns).Get(name, opts)
+	case *cbTypes.ReportTemplate:
+		existing, err = c.ReportTemplates(ns).Get(name, opts)
+	case *cbTypes.PricingModel:
+		existing, err = c.PricingModels(ns).Get(name, opts)
+	case *cbTypes.ScheduledReport:
+		existing, err = c.ScheduledReports(ns).Get(name, opts)
+	case *cbTypes.Report:
+		existing, err = c.Reports(ns).Get(name, opts)
+	case *cbTypes.PrestoTable:
+		existing, err = c.PrestoTables(ns).Get(name, opts)
+	}
+	if err != nil {
+		return false, "", fmt.Errorf("unable to get existing %s %s/%s: %v", res.kind, ns, name, err)
+	}
+	return false, existing.GetUID(), nil
+}
+
+// verifyManifests checks that the files listed in the data manifests of the
+// backup are still part of their tables. Missing files mean data was lost
+// or moved since the backup, and are reported rather than failing the
+// restore, as the rest of the data is still usable.
+func (r *Restorer) verifyManifests(logger log.FieldLogger, records []Record, result *RestoreResult) {
+	for _, record := range records {
+		if record.Kind != TableManifestKind {
+			continue
+		}
+		var manifest TableManifest
+		if err := json.Unmarshal([]byte(record.Object), &manifest); err != nil {
+			logger.WithError(err).Warnf("unable to decode the data manifest of table %s", record.Name)
+			continue
+		}
+		if manifest.Error != "" {
+			continue
+		}
+		files, err := queryTableFiles(r.prestoQueryer, manifest.TableName)
+		if err != nil {
+			logger.WithError(err).Warnf("unable to list the files of table %s", manifest.TableName)
+			continue
+		}
+		for _, path := range missingFiles(manifest.Files, files) {
+			logger.Warnf("file %s of table %s in the backup's data manifest is missing", path, manifest.TableName)
+			result.MissingFiles = append(result.MissingFiles, manifest.TableName+": "+path)
+		}
+	}
+}
+
+// missingFiles returns the paths of the files in expected which aren't in
+// actual.
+func missingFiles(expected, actual []TableManifestFile) []string {
+	present := make(map[string]bool, len(actual))
+	for _, file := range actual {
+		present[file.Path] = true
+	}
+	var missing []string
+	for _, file := range expected {
+		if !present[file.Path] {
+			missing = append(missing, file.Path)
+		}
+	}
+	return missing
+}
+
+// ParseID returns an error if id isn't a backup ID.
+func ParseID(id string) (time.Time, error) {
+	t, err := time.Parse(IDFormat, id)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid backup ID %q, must be in the format %s", id, IDFormat)
+	}
+	return t, nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
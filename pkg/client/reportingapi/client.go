@@ -0,0 +1,124 @@
+// Package reportingapi is a client for the reporting-operator's HTTP API.
+// The types and methods in zz_generated.client.go are generated from the
+// API's OpenAPI specification by hack/reportingapi-gen; run
+// `make reportingapi-gen` after changing the API.
+package reportingapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client calls the reporting-operator's HTTP API.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+}
+
+// NewClient returns a Client for the API at baseURL, such as
+// https://metering.example.com. If httpClient is nil, http.DefaultClient is
+// used; to authenticate with the API, use an http.Client whose transport
+// adds credentials to each request.
+func NewClient(baseURL string, httpClient *http.Client) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL %q: %v", baseURL, err)
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return &Client{baseURL: u, httpClient: httpClient}, nil
+}
+
+// APIError is returned when the API responds with a status other than the
+// operation's successful status, usually 200 OK.
+type APIError struct {
+	StatusCode int
+	// Message is the error returned by the API.
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("reporting API returned %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// doRequest sends a request, returning an *APIError if the response status
+// isn't status.
+func (c *Client) doRequest(ctx context.Context, method, path string, query url.Values, status int, contentType string, body io.Reader) (*http.Response, error) {
+	u := *c.baseURL
+	u.Path += path
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != status {
+		defer resp.Body.Close()
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		var errResp ErrorResponse
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		if json.Unmarshal(respBody, &errResp) == nil && errResp.Error != "" {
+			apiErr.Message = errResp.Error
+		} else {
+			apiErr.Message = string(respBody)
+		}
+		return nil, apiErr
+	}
+	return resp, nil
+}
+
+// doJSON sends reqBody encoded as JSON, if it's not nil, and decodes the
+// response into result, if it's not nil.
+func (c *Client) doJSON(ctx context.Context, method, path string, query url.Values, status int, reqBody, result interface{}) error {
+	var body io.Reader
+	contentType := ""
+	if reqBody != nil {
+		var err error
+		body, err = jsonBody(reqBody)
+		if err != nil {
+			return err
+		}
+		contentType = "application/json"
+	}
+	resp, err := c.doRequest(ctx, method, path, query, status, contentType, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if result == nil {
+		return nil
+	}
+	return decodeJSON(resp, result)
+}
+
+// jsonBody encodes v as the JSON body of a request.
+func jsonBody(v interface{}) (io.Reader, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
+
+func decodeJSON(resp *http.Response, result interface{}) error {
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("unable to decode response as JSON: %v", err)
+	}
+	return nil
+}
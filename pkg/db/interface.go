@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
@@ -12,6 +13,13 @@ type Queryer interface {
 	Query(query string, args ...interface{}) (*sql.Rows, error)
 }
 
+// ContextQueryer is a Queryer which can also perform queries that are
+// cancelled when a context is done.
+type ContextQueryer interface {
+	Queryer
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
 type db struct {
 	logger     log.FieldLogger
 	logQueries bool
@@ -35,6 +43,14 @@ func (db *db) Query(query string, args ...interface{}) (*sql.Rows, error) {
 	return db.db.Query(query, args...)
 }
 
+func (db *db) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if db.logQueries {
+		margs := argsString(args...)
+		db.logger.Debugf("QUERY: %s [%s]", query, margs)
+	}
+	return db.db.QueryContext(ctx, query, args...)
+}
+
 // argsString pretty prints arguments passed into it for logging query
 // arguments
 func argsString(args ...interface{}) string {
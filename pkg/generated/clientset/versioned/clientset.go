@@ -4,6 +4,7 @@ package versioned
 
 import (
 	glog "github.com/golang/glog"
+	meteringv1 "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/typed/metering/v1"
 	meteringv1alpha1 "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/typed/metering/v1alpha1"
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
@@ -13,8 +14,9 @@ import (
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	MeteringV1alpha1() meteringv1alpha1.MeteringV1alpha1Interface
+	MeteringV1() meteringv1.MeteringV1Interface
 	// Deprecated: please explicitly pick a version if possible.
-	Metering() meteringv1alpha1.MeteringV1alpha1Interface
+	Metering() meteringv1.MeteringV1Interface
 }
 
 // Clientset contains the clients for groups. Each group has exactly one
@@ -22,6 +24,7 @@ type Interface interface {
 type Clientset struct {
 	*discovery.DiscoveryClient
 	meteringV1alpha1 *meteringv1alpha1.MeteringV1alpha1Client
+	meteringV1       *meteringv1.MeteringV1Client
 }
 
 // MeteringV1alpha1 retrieves the MeteringV1alpha1Client
@@ -29,10 +32,15 @@ func (c *Clientset) MeteringV1alpha1() meteringv1alpha1.MeteringV1alpha1Interfac
 	return c.meteringV1alpha1
 }
 
+// MeteringV1 retrieves the MeteringV1Client
+func (c *Clientset) MeteringV1() meteringv1.MeteringV1Interface {
+	return c.meteringV1
+}
+
 // Deprecated: Metering retrieves the default version of MeteringClient.
 // Please explicitly pick a version.
-func (c *Clientset) Metering() meteringv1alpha1.MeteringV1alpha1Interface {
-	return c.meteringV1alpha1
+func (c *Clientset) Metering() meteringv1.MeteringV1Interface {
+	return c.meteringV1
 }
 
 // Discovery retrieves the DiscoveryClient
@@ -55,6 +63,10 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.meteringV1, err = meteringv1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfig(&configShallowCopy)
 	if err != nil {
@@ -69,6 +81,7 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 func NewForConfigOrDie(c *rest.Config) *Clientset {
 	var cs Clientset
 	cs.meteringV1alpha1 = meteringv1alpha1.NewForConfigOrDie(c)
+	cs.meteringV1 = meteringv1.NewForConfigOrDie(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClientForConfigOrDie(c)
 	return &cs
@@ -78,6 +91,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.meteringV1alpha1 = meteringv1alpha1.New(c)
+	cs.meteringV1 = meteringv1.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
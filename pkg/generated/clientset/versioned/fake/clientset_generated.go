@@ -4,6 +4,8 @@ package fake
 
 import (
 	clientset "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned"
+	meteringv1 "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/typed/metering/v1"
+	fakemeteringv1 "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/typed/metering/v1/fake"
 	meteringv1alpha1 "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/typed/metering/v1alpha1"
 	fakemeteringv1alpha1 "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/typed/metering/v1alpha1/fake"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -51,7 +53,12 @@ func (c *Clientset) MeteringV1alpha1() meteringv1alpha1.MeteringV1alpha1Interfac
 	return &fakemeteringv1alpha1.FakeMeteringV1alpha1{Fake: &c.Fake}
 }
 
-// Metering retrieves the MeteringV1alpha1Client
-func (c *Clientset) Metering() meteringv1alpha1.MeteringV1alpha1Interface {
-	return &fakemeteringv1alpha1.FakeMeteringV1alpha1{Fake: &c.Fake}
+// MeteringV1 retrieves the MeteringV1Client
+func (c *Clientset) MeteringV1() meteringv1.MeteringV1Interface {
+	return &fakemeteringv1.FakeMeteringV1{Fake: &c.Fake}
+}
+
+// Metering retrieves the MeteringV1Client
+func (c *Clientset) Metering() meteringv1.MeteringV1Interface {
+	return &fakemeteringv1.FakeMeteringV1{Fake: &c.Fake}
 }
@@ -3,6 +3,7 @@
 package scheme
 
 import (
+	meteringv1 "github.com/operator-framework/operator-metering/pkg/apis/metering/v1"
 	meteringv1alpha1 "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
@@ -35,5 +36,6 @@ func init() {
 // correctly.
 func AddToScheme(scheme *runtime.Scheme) {
 	meteringv1alpha1.AddToScheme(scheme)
+	meteringv1.AddToScheme(scheme)
 
 }
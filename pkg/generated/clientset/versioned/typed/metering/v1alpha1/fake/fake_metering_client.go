@@ -16,6 +16,10 @@ func (c *FakeMeteringV1alpha1) PrestoTables(namespace string) v1alpha1.PrestoTab
 	return &FakePrestoTables{c, namespace}
 }
 
+func (c *FakeMeteringV1alpha1) PricingModels(namespace string) v1alpha1.PricingModelInterface {
+	return &FakePricingModels{c, namespace}
+}
+
 func (c *FakeMeteringV1alpha1) Reports(namespace string) v1alpha1.ReportInterface {
 	return &FakeReports{c, namespace}
 }
@@ -32,6 +36,10 @@ func (c *FakeMeteringV1alpha1) ReportPrometheusQueries(namespace string) v1alpha
 	return &FakeReportPrometheusQueries{c, namespace}
 }
 
+func (c *FakeMeteringV1alpha1) ReportTemplates(namespace string) v1alpha1.ReportTemplateInterface {
+	return &FakeReportTemplates{c, namespace}
+}
+
 func (c *FakeMeteringV1alpha1) ScheduledReports(namespace string) v1alpha1.ScheduledReportInterface {
 	return &FakeScheduledReports{c, namespace}
 }
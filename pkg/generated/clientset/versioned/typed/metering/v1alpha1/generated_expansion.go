@@ -4,6 +4,8 @@ package v1alpha1
 
 type PrestoTableExpansion interface{}
 
+type PricingModelExpansion interface{}
+
 type ReportExpansion interface{}
 
 type ReportDataSourceExpansion interface{}
@@ -12,6 +14,8 @@ type ReportGenerationQueryExpansion interface{}
 
 type ReportPrometheusQueryExpansion interface{}
 
+type ReportTemplateExpansion interface{}
+
 type ScheduledReportExpansion interface{}
 
 type StorageLocationExpansion interface{}
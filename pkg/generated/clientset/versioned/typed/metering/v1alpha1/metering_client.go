@@ -12,10 +12,12 @@ import (
 type MeteringV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	PrestoTablesGetter
+	PricingModelsGetter
 	ReportsGetter
 	ReportDataSourcesGetter
 	ReportGenerationQueriesGetter
 	ReportPrometheusQueriesGetter
+	ReportTemplatesGetter
 	ScheduledReportsGetter
 	StorageLocationsGetter
 }
@@ -29,6 +31,10 @@ func (c *MeteringV1alpha1Client) PrestoTables(namespace string) PrestoTableInter
 	return newPrestoTables(c, namespace)
 }
 
+func (c *MeteringV1alpha1Client) PricingModels(namespace string) PricingModelInterface {
+	return newPricingModels(c, namespace)
+}
+
 func (c *MeteringV1alpha1Client) Reports(namespace string) ReportInterface {
 	return newReports(c, namespace)
 }
@@ -45,6 +51,10 @@ func (c *MeteringV1alpha1Client) ReportPrometheusQueries(namespace string) Repor
 	return newReportPrometheusQueries(c, namespace)
 }
 
+func (c *MeteringV1alpha1Client) ReportTemplates(namespace string) ReportTemplateInterface {
+	return newReportTemplates(c, namespace)
+}
+
 func (c *MeteringV1alpha1Client) ScheduledReports(namespace string) ScheduledReportInterface {
 	return newScheduledReports(c, namespace)
 }
@@ -21,6 +21,7 @@ type StorageLocationsGetter interface {
 type StorageLocationInterface interface {
 	Create(*v1alpha1.StorageLocation) (*v1alpha1.StorageLocation, error)
 	Update(*v1alpha1.StorageLocation) (*v1alpha1.StorageLocation, error)
+	UpdateStatus(*v1alpha1.StorageLocation) (*v1alpha1.StorageLocation, error)
 	Delete(name string, options *v1.DeleteOptions) error
 	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
 	Get(name string, options v1.GetOptions) (*v1alpha1.StorageLocation, error)
@@ -104,6 +105,22 @@ func (c *storageLocations) Update(storageLocation *v1alpha1.StorageLocation) (re
 	return
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *storageLocations) UpdateStatus(storageLocation *v1alpha1.StorageLocation) (result *v1alpha1.StorageLocation, err error) {
+	result = &v1alpha1.StorageLocation{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("storagelocations").
+		Name(storageLocation.Name).
+		SubResource("status").
+		Body(storageLocation).
+		Do().
+		Into(result)
+	return
+}
+
 // Delete takes name of the storageLocation and deletes it. Returns an error if one occurs.
 func (c *storageLocations) Delete(name string, options *v1.DeleteOptions) error {
 	return c.client.Delete().
@@ -7,6 +7,7 @@ package externalversions
 import (
 	"fmt"
 
+	v1 "github.com/operator-framework/operator-metering/pkg/apis/metering/v1"
 	v1alpha1 "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
@@ -38,9 +39,19 @@ func (f *genericInformer) Lister() cache.GenericLister {
 // TODO extend this to unknown resources with a client pool
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
-	// Group=metering.openshift.io, Version=v1alpha1
+	// Group=metering.openshift.io, Version=v1
+	case v1.SchemeGroupVersion.WithResource("reports"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Metering().V1().Reports().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("reportdatasources"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Metering().V1().ReportDataSources().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("scheduledreports"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Metering().V1().ScheduledReports().Informer()}, nil
+
+		// Group=metering.openshift.io, Version=v1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("prestotables"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Metering().V1alpha1().PrestoTables().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("pricingmodels"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Metering().V1alpha1().PricingModels().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("reports"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Metering().V1alpha1().Reports().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("reportdatasources"):
@@ -49,6 +60,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Metering().V1alpha1().ReportGenerationQueries().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("reportprometheusqueries"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Metering().V1alpha1().ReportPrometheusQueries().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("reporttemplates"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Metering().V1alpha1().ReportTemplates().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("scheduledreports"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Metering().V1alpha1().ScheduledReports().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("storagelocations"):
@@ -12,6 +12,8 @@ import (
 type Interface interface {
 	// PrestoTables returns a PrestoTableInformer.
 	PrestoTables() PrestoTableInformer
+	// PricingModels returns a PricingModelInformer.
+	PricingModels() PricingModelInformer
 	// Reports returns a ReportInformer.
 	Reports() ReportInformer
 	// ReportDataSources returns a ReportDataSourceInformer.
@@ -20,6 +22,8 @@ type Interface interface {
 	ReportGenerationQueries() ReportGenerationQueryInformer
 	// ReportPrometheusQueries returns a ReportPrometheusQueryInformer.
 	ReportPrometheusQueries() ReportPrometheusQueryInformer
+	// ReportTemplates returns a ReportTemplateInformer.
+	ReportTemplates() ReportTemplateInformer
 	// ScheduledReports returns a ScheduledReportInformer.
 	ScheduledReports() ScheduledReportInformer
 	// StorageLocations returns a StorageLocationInformer.
@@ -42,6 +46,11 @@ func (v *version) PrestoTables() PrestoTableInformer {
 	return &prestoTableInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// PricingModels returns a PricingModelInformer.
+func (v *version) PricingModels() PricingModelInformer {
+	return &pricingModelInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Reports returns a ReportInformer.
 func (v *version) Reports() ReportInformer {
 	return &reportInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -62,6 +71,11 @@ func (v *version) ReportPrometheusQueries() ReportPrometheusQueryInformer {
 	return &reportPrometheusQueryInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// ReportTemplates returns a ReportTemplateInformer.
+func (v *version) ReportTemplates() ReportTemplateInformer {
+	return &reportTemplateInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // ScheduledReports returns a ScheduledReportInformer.
 func (v *version) ScheduledReports() ScheduledReportInformer {
 	return &scheduledReportInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -12,6 +12,14 @@ type PrestoTableListerExpansion interface{}
 // PrestoTableNamespaceLister.
 type PrestoTableNamespaceListerExpansion interface{}
 
+// PricingModelListerExpansion allows custom methods to be added to
+// PricingModelLister.
+type PricingModelListerExpansion interface{}
+
+// PricingModelNamespaceListerExpansion allows custom methods to be added to
+// PricingModelNamespaceLister.
+type PricingModelNamespaceListerExpansion interface{}
+
 // ReportListerExpansion allows custom methods to be added to
 // ReportLister.
 type ReportListerExpansion interface{}
@@ -44,6 +52,14 @@ type ReportPrometheusQueryListerExpansion interface{}
 // ReportPrometheusQueryNamespaceLister.
 type ReportPrometheusQueryNamespaceListerExpansion interface{}
 
+// ReportTemplateListerExpansion allows custom methods to be added to
+// ReportTemplateLister.
+type ReportTemplateListerExpansion interface{}
+
+// ReportTemplateNamespaceListerExpansion allows custom methods to be added to
+// ReportTemplateNamespaceLister.
+type ReportTemplateNamespaceListerExpansion interface{}
+
 // ScheduledReportListerExpansion allows custom methods to be added to
 // ScheduledReportLister.
 type ScheduledReportListerExpansion interface{}
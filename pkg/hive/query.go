@@ -2,6 +2,7 @@ package hive
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -18,6 +19,12 @@ func generateDropTableSQL(name string, ignoreNotExists, purge bool) string {
 	return fmt.Sprintf("DROP TABLE %s %s %s", ifExists, name, purgeStr)
 }
 
+// generateAddColumnsSQL returns a query adding the columns to the end of
+// an existing table's columns.
+func generateAddColumnsSQL(name string, columns []Column) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMNS (%s)", name, generateColumnListSQL(columns))
+}
+
 // generateCreateTableSQL returns a query for a CREATE statement which instantiates a new external Hive table.
 // If is external is set, an external Hive table will be used.
 func generateCreateTableSQL(params TableParameters, properties TableProperties) string {
@@ -50,13 +57,17 @@ func generateCreateTableSQL(params TableParameters, properties TableProperties)
 	if properties.FileFormat != "" {
 		format = fmt.Sprintf("STORED AS %s", properties.FileFormat)
 	}
+	tblProperties := ""
+	if len(properties.Properties) != 0 {
+		tblProperties = fmt.Sprintf("TBLPROPERTIES (%s)", generateTablePropertiesSQL(properties.Properties))
+	}
 	return fmt.Sprintf(
 		`CREATE %s TABLE %s
 %s (%s) %s
-%s %s %s`,
+%s %s %s %s`,
 		tableType, ifNotExists,
 		params.Name, columnsStr, partitionedBy,
-		serdeFormatStr, format, location,
+		serdeFormatStr, format, location, tblProperties,
 	)
 }
 
@@ -74,6 +85,21 @@ func escapeColumn(columnName, columnType string) string {
 	return fmt.Sprintf("`%s` %s", columnName, columnType)
 }
 
+// generateTablePropertiesSQL returns the table properties for a Hive
+// query, sorted by name.
+func generateTablePropertiesSQL(props map[string]string) string {
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf("%q = %q", name, props[name])
+	}
+	return strings.Join(pairs, ", ")
+}
+
 // generateSerdeRowPropertiesSQL returns a formatted a set of SerDe properties for a Hive query.
 func generateSerdeRowPropertiesSQL(props map[string]string) (propsTxt string) {
 	first := true
@@ -25,6 +25,8 @@ type TableProperties struct {
 	FileFormat         string            `json:"fileFormat,omitempty"`
 	SerdeRowProperties map[string]string `json:"serdeRowProperties,omitempty"`
 	External           bool              `json:"external,omitempty"`
+	// Properties are set as the table's TBLPROPERTIES.
+	Properties map[string]string `json:"properties,omitempty"`
 }
 
 func ExecuteCreateTable(queryer db.Queryer, params TableParameters, properties TableProperties) error {
@@ -33,20 +35,53 @@ func ExecuteCreateTable(queryer db.Queryer, params TableParameters, properties T
 	return err
 }
 
+// ExecuteAddColumns adds the columns to the end of the existing table's
+// columns. Rows written before they were added have NULL values for them.
+func ExecuteAddColumns(queryer db.Queryer, tableName string, columns []Column) error {
+	query := generateAddColumnsSQL(tableName, columns)
+	_, err := queryer.Query(query)
+	return err
+}
+
 func ExecuteDropTable(queryer db.Queryer, tableName string, ignoreNotExists bool) error {
 	query := generateDropTableSQL(tableName, ignoreNotExists, true)
 	_, err := queryer.Query(query)
 	return err
 }
 
+// IsObjectStoreLocation returns true if location is in an object store, such
+// as S3 or GCS, rather than in a filesystem, such as HDFS. Objects can't be
+// renamed atomically, and may still be listed for a while after they're
+// deleted.
+func IsObjectStoreLocation(location string) bool {
+	u, err := url.Parse(location)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "s3", "s3a", "s3n", "gs":
+		return true
+	}
+	return false
+}
+
 // s3Location returns the HDFS path based on an S3 bucket and prefix.
 func S3Location(bucket, prefix string) (string, error) {
+	return bucketLocation("s3a", bucket, prefix)
+}
+
+// GCSLocation returns the HDFS path based on a GCS bucket and prefix.
+func GCSLocation(bucket, prefix string) (string, error) {
+	return bucketLocation("gs", bucket, prefix)
+}
+
+func bucketLocation(scheme, bucket, prefix string) (string, error) {
 	bucket = path.Join(bucket, prefix)
 	// Ensure the bucket URL has a trailing slash
 	if bucket[len(bucket)-1] != '/' {
 		bucket = bucket + "/"
 	}
-	location := "s3a://" + bucket
+	location := scheme + "://" + bucket
 
 	locationURL, err := url.Parse(location)
 	if err != nil {
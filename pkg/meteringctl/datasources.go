@@ -0,0 +1,122 @@
+package meteringctl
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/api/core/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+)
+
+// PrintDataSources writes a table of the dataSources and the status of
+// their imports to w. The message is the message of the first condition
+// which indicates a problem.
+func PrintDataSources(w io.Writer, dataSources []*cbTypes.ReportDataSource, now time.Time) error {
+	sorted := make([]*cbTypes.ReportDataSource, len(dataSources))
+	copy(sorted, dataSources)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTYPE\tTABLE\tREADY\tLAST IMPORT\tSINCE\tMESSAGE")
+	for _, dataSource := range sorted {
+		lastImport, since := "-", "-"
+		if cond := cbutil.GetReportDataSourceCondition(dataSource, cbTypes.ReportDataSourceLastImportSucceeded); cond != nil {
+			lastImport = "Succeeded"
+			if cond.Status != v1.ConditionTrue {
+				lastImport = "Failed"
+			}
+			since = humanDuration(now.Sub(cond.LastTransitionTime.Time))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			dataSource.Name,
+			dataSourceType(dataSource),
+			valueOrDash(dataSource.TableName),
+			conditionStatus(dataSource, cbTypes.ReportDataSourceReady),
+			lastImport,
+			since,
+			problemMessage(dataSource),
+		)
+	}
+	return tw.Flush()
+}
+
+func dataSourceType(dataSource *cbTypes.ReportDataSource) string {
+	switch {
+	case dataSource.Spec.Promsum != nil:
+		return "Prometheus"
+	case dataSource.Spec.AWSBilling != nil:
+		return "AWSBilling"
+	case dataSource.Spec.GCPBilling != nil:
+		return "GCPBilling"
+	case dataSource.Spec.KubernetesObjects != nil:
+		return "KubernetesObjects"
+	case dataSource.Spec.KubernetesEvents != nil:
+		return "KubernetesEvents"
+	case dataSource.Spec.RemoteReport != nil:
+		return "RemoteReport"
+	case dataSource.Spec.ObjectStorage != nil:
+		return "ObjectStorage"
+	case dataSource.Spec.Database != nil:
+		return "Database"
+	default:
+		return "Unknown"
+	}
+}
+
+func conditionStatus(dataSource *cbTypes.ReportDataSource, condType cbTypes.ReportDataSourceConditionType) string {
+	cond := cbutil.GetReportDataSourceCondition(dataSource, condType)
+	if cond == nil {
+		return string(v1.ConditionUnknown)
+	}
+	return string(cond.Status)
+}
+
+// problemMessage returns the message of the first condition indicating a
+// problem with the dataSource, which is a Degraded condition which is True,
+// or any other condition which is False.
+func problemMessage(dataSource *cbTypes.ReportDataSource) string {
+	for _, condType := range []cbTypes.ReportDataSourceConditionType{
+		cbTypes.ReportDataSourceReady,
+		cbTypes.ReportDataSourceQueryValid,
+		cbTypes.ReportDataSourceLastImportSucceeded,
+	} {
+		if cond := cbutil.GetReportDataSourceCondition(dataSource, condType); cond != nil && cond.Status == v1.ConditionFalse {
+			return cond.Message
+		}
+	}
+	if cond := cbutil.GetReportDataSourceCondition(dataSource, cbTypes.ReportDataSourceDegraded); cond != nil && cond.Status == v1.ConditionTrue {
+		return cond.Message
+	}
+	return ""
+}
+
+// humanDuration formats d like kubectl formats ages, such as 45s, 12m or
+// 3d.
+func humanDuration(d time.Duration) string {
+	switch {
+	case d < 0:
+		return "0s"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,42 @@
+package meteringctl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestPrintDataSources(t *testing.T) {
+	now := time.Date(2018, time.August, 1, 12, 0, 0, 0, time.UTC)
+	dataSources := []*cbTypes.ReportDataSource{
+		{
+			ObjectMeta: meta.ObjectMeta{Name: "pod-request-cpu-cores"},
+			Spec:       cbTypes.ReportDataSourceSpec{Promsum: &cbTypes.PrometheusMetricsDataSource{}},
+			TableName:  "datasource_pod_request_cpu_cores",
+			Conditions: []cbTypes.ReportDataSourceCondition{
+				{Type: cbTypes.ReportDataSourceReady, Status: v1.ConditionTrue},
+				{Type: cbTypes.ReportDataSourceLastImportSucceeded, Status: v1.ConditionFalse, Message: "connection refused", LastTransitionTime: meta.NewTime(now.Add(-5 * time.Minute))},
+			},
+		},
+		{
+			ObjectMeta: meta.ObjectMeta{Name: "aws-billing"},
+			Spec:       cbTypes.ReportDataSourceSpec{AWSBilling: &cbTypes.AWSBillingDataSource{}},
+		},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, PrintDataSources(&out, dataSources, now))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, []string{"NAME", "TYPE", "TABLE", "READY", "LAST", "IMPORT", "SINCE", "MESSAGE"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"aws-billing", "AWSBilling", "-", "Unknown", "-", "-"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"pod-request-cpu-cores", "Prometheus", "datasource_pod_request_cpu_cores", "True", "Failed", "5m", "connection", "refused"}, strings.Fields(lines[2]))
+}
@@ -0,0 +1,101 @@
+package meteringctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/rest"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/client/reportingapi"
+)
+
+// DataStoreOperation is exporting or importing the tables of
+// ReportDataSources.
+type DataStoreOperation string
+
+const (
+	ExportDataStore DataStoreOperation = "export"
+	ImportDataStore DataStoreOperation = "import"
+)
+
+// NewReportingAPIClient returns a client for the reporting-operator's HTTP
+// API in meteringNamespace, which calls it through the Kubernetes API
+// server's service proxy.
+func NewReportingAPIClient(config *rest.Config, meteringNamespace string, https bool) (*reportingapi.Client, error) {
+	transport, err := rest.TransportFor(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create Kubernetes transport: %v", err)
+	}
+	host := config.Host
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	return reportingapi.NewClient(reportingAPIProxyURL(host, meteringNamespace, https), &http.Client{Transport: transport})
+}
+
+// reportingAPIProxyURL returns the URL of the Kubernetes API server's
+// service proxy for the reporting-operator's HTTP API.
+func reportingAPIProxyURL(host, meteringNamespace string, https bool) string {
+	scheme := "http"
+	if https {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s/api/v1/namespaces/%s/services/%s:%s:%s/proxy", strings.TrimSuffix(host, "/"), meteringNamespace, scheme, ReportingOperatorServiceName, reportingOperatorServicePortName)
+}
+
+// DataStoreLocation returns the directory within location the table of the
+// ReportDataSource named name is exported to and imported from.
+func DataStoreLocation(location, name string) string {
+	return strings.TrimSuffix(location, "/") + "/" + name
+}
+
+// IsDataStoreDataSource returns true if the table of the ReportDataSource
+// can be exported and imported, which are the tables of the data the
+// reporting-operator collects.
+func IsDataStoreDataSource(dataSource *cbTypes.ReportDataSource) bool {
+	return dataSource.Spec.Promsum != nil || dataSource.Spec.KubernetesObjects != nil
+}
+
+// TransferDataStores exports or imports the tables of the dataSources, each
+// to or from its own directory within location, one at a time, and writes
+// the outcome of each to w. It stops at the first failure, as the
+// remaining tables would likely fail the same way.
+func TransferDataStores(ctx context.Context, client *reportingapi.Client, op DataStoreOperation, dataSources []*cbTypes.ReportDataSource, location string, w io.Writer) error {
+	for _, dataSource := range dataSources {
+		req := reportingapi.DataStoreTransferRequest{Location: DataStoreLocation(location, dataSource.Name)}
+		var transfer reportingapi.DataStoreTransfer
+		var err error
+		switch op {
+		case ExportDataStore:
+			transfer, err = client.ExportDataStore(ctx, reportingapi.ExportDataStoreParams{DatasourceName: dataSource.Name}, req)
+		case ImportDataStore:
+			transfer, err = client.ImportDataStore(ctx, reportingapi.ImportDataStoreParams{DatasourceName: dataSource.Name}, req)
+		default:
+			return fmt.Errorf("unknown data store operation %q", op)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to %s ReportDataSource %s: %v", op, dataSource.Name, err)
+		}
+		fmt.Fprintf(w, "%sed ReportDataSource %s table %s %s %s, %s\n", op, transfer.DataSource, transfer.TableName, dataStoreDirection(op), transfer.Location, lastTimestampDescription(transfer.LastTimestamp))
+	}
+	return nil
+}
+
+func dataStoreDirection(op DataStoreOperation) string {
+	if op == ImportDataStore {
+		return "from"
+	}
+	return "to"
+}
+
+func lastTimestampDescription(lastTimestamp time.Time) string {
+	if lastTimestamp.IsZero() {
+		return "the exported table was empty"
+	}
+	return "last timestamp " + lastTimestamp.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,59 @@
+package meteringctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/client/reportingapi"
+)
+
+func TestReportingAPIProxyURL(t *testing.T) {
+	assert.Equal(t, "https://api.example.com:6443/api/v1/namespaces/metering/services/http:reporting-operator:http/proxy", reportingAPIProxyURL("https://api.example.com:6443/", "metering", false))
+	assert.Equal(t, "https://api.example.com:6443/api/v1/namespaces/metering/services/https:reporting-operator:http/proxy", reportingAPIProxyURL("https://api.example.com:6443", "metering", true))
+}
+
+func TestTransferDataStores(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req reportingapi.DataStoreTransferRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, r.URL.Path+" "+req.Location)
+		if r.URL.Path == "/api/v1/datasources/import/node-capacity-cpu-cores" {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(reportingapi.ErrorResponse{Error: "the table for ReportDataSource node-capacity-cpu-cores has not been created yet"})
+			return
+		}
+		json.NewEncoder(w).Encode(reportingapi.DataStoreTransfer{
+			DataSource: "pod-request-cpu-cores",
+			TableName:  "datasource_pod_request_cpu_cores",
+			Location:   req.Location,
+		})
+	}))
+	defer server.Close()
+	client, err := reportingapi.NewClient(server.URL, nil)
+	require.NoError(t, err)
+
+	dataSources := []*cbTypes.ReportDataSource{
+		{ObjectMeta: meta.ObjectMeta{Name: "pod-request-cpu-cores"}},
+		{ObjectMeta: meta.ObjectMeta{Name: "node-capacity-cpu-cores"}},
+		{ObjectMeta: meta.ObjectMeta{Name: "node-allocatable-cpu-cores"}},
+	}
+	var out bytes.Buffer
+	err = TransferDataStores(context.Background(), client, ImportDataStore, dataSources, "s3a://metering/export/", &out)
+	assert.Error(t, err)
+	// the remaining ReportDataSources aren't imported after a failure.
+	assert.Equal(t, []string{
+		"/api/v1/datasources/import/pod-request-cpu-cores s3a://metering/export/pod-request-cpu-cores",
+		"/api/v1/datasources/import/node-capacity-cpu-cores s3a://metering/export/node-capacity-cpu-cores",
+	}, requests)
+	assert.Equal(t, "imported ReportDataSource pod-request-cpu-cores table datasource_pod_request_cpu_cores from s3a://metering/export/pod-request-cpu-cores, the exported table was empty\n", out.String())
+}
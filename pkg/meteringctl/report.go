@@ -0,0 +1,147 @@
+// Package meteringctl implements the commands of the kubectl-metering
+// plugin, which creates Reports, waits for them to finish, fetches their
+// results and shows the import status of ReportDataSources.
+package meteringctl
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+	meteringv1alpha1 "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/typed/metering/v1alpha1"
+)
+
+// ReportOptions are the options of an ad-hoc Report.
+type ReportOptions struct {
+	Name            string
+	Namespace       string
+	GenerationQuery string
+	ReportingStart  time.Time
+	ReportingEnd    time.Time
+	Timezone        string
+	RunImmediately  bool
+	// Inputs are the values of the query's inputs, as name=value pairs.
+	Inputs        []string
+	GroupByLabels []string
+}
+
+// NewReport returns the Report described by opts.
+func NewReport(opts ReportOptions) (*cbTypes.Report, error) {
+	if opts.Name == "" {
+		return nil, fmt.Errorf("the report's name must be set")
+	}
+	if opts.GenerationQuery == "" {
+		return nil, fmt.Errorf("the report's query must be set")
+	}
+	if !opts.ReportingEnd.After(opts.ReportingStart) {
+		return nil, fmt.Errorf("the end of the reporting period must be after its start")
+	}
+	inputs, err := ParseInputs(opts.Inputs)
+	if err != nil {
+		return nil, err
+	}
+	return &cbTypes.Report{
+		TypeMeta: meta.TypeMeta{
+			APIVersion: cbTypes.SchemeGroupVersion.String(),
+			Kind:       "Report",
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name:      opts.Name,
+			Namespace: opts.Namespace,
+		},
+		Spec: cbTypes.ReportSpec{
+			GenerationQueryName: opts.GenerationQuery,
+			ReportingStart:      meta.NewTime(opts.ReportingStart),
+			ReportingEnd:        meta.NewTime(opts.ReportingEnd),
+			Timezone:            opts.Timezone,
+			RunImmediately:      opts.RunImmediately,
+			Inputs:              inputs,
+			GroupByLabels:       opts.GroupByLabels,
+		},
+	}, nil
+}
+
+// ParseInputs parses the name=value pairs into ReportGenerationQuery input
+// values.
+func ParseInputs(pairs []string) ([]cbTypes.ReportGenerationQueryInputValue, error) {
+	var inputs []cbTypes.ReportGenerationQueryInputValue
+	seen := make(map[string]bool)
+	for _, pair := range pairs {
+		idx := strings.Index(pair, "=")
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid input %q, must be in the form name=value", pair)
+		}
+		name := pair[:idx]
+		if seen[name] {
+			return nil, fmt.Errorf("input %s is set more than once", name)
+		}
+		seen[name] = true
+		inputs = append(inputs, cbTypes.ReportGenerationQueryInputValue{
+			Name:  name,
+			Value: pair[idx+1:],
+		})
+	}
+	return inputs, nil
+}
+
+// ErrReportFailed is returned by WaitForReport when the report fails.
+type ErrReportFailed struct {
+	Name   string
+	Output string
+}
+
+func (e *ErrReportFailed) Error() string {
+	return fmt.Sprintf("report %s failed: %s", e.Name, e.Output)
+}
+
+// WaitForReport polls the report every interval until it finishes or
+// fails, or the timeout elapses, writing a line to w each time its progress
+// changes. It returns the finished report.
+func WaitForReport(client meteringv1alpha1.ReportsGetter, namespace, name string, interval, timeout time.Duration, w io.Writer) (*cbTypes.Report, error) {
+	deadline := time.Now().Add(timeout)
+	var lastProgress string
+	for {
+		report, err := client.Reports(namespace).Get(name, meta.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		progress := ReportProgress(report)
+		if progress != lastProgress {
+			fmt.Fprintf(w, "%s\t%s\n", time.Now().UTC().Format(time.RFC3339), progress)
+			lastProgress = progress
+		}
+		switch report.Status.Phase {
+		case cbTypes.ReportPhaseFinished:
+			return report, nil
+		case cbTypes.ReportPhaseError:
+			return report, &ErrReportFailed{Name: name, Output: report.Status.Output}
+		}
+		if timeout > 0 && time.Now().After(deadline) {
+			return report, fmt.Errorf("timed out after %s waiting for report %s to finish", timeout, name)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// ReportProgress returns a line describing the progress of the report: its
+// phase and the reason and message of its Ready condition.
+func ReportProgress(report *cbTypes.Report) string {
+	phase := string(report.Status.Phase)
+	if phase == "" {
+		phase = "Pending"
+	}
+	cond := cbutil.GetReportCondition(report.Status, cbTypes.ReportReady)
+	if cond == nil || cond.Status == v1.ConditionTrue {
+		return phase
+	}
+	if cond.Message == "" {
+		return fmt.Sprintf("%s (%s)", phase, cond.Reason)
+	}
+	return fmt.Sprintf("%s (%s): %s", phase, cond.Reason, cond.Message)
+}
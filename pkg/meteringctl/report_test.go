@@ -0,0 +1,100 @@
+package meteringctl
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+	"github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/fake"
+)
+
+func TestNewReport(t *testing.T) {
+	start := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	validOpts := ReportOptions{
+		Name:            "namespace-cpu-request-july",
+		Namespace:       "metering",
+		GenerationQuery: "namespace-cpu-request",
+		ReportingStart:  start,
+		ReportingEnd:    end,
+		Inputs:          []string{"namespace=web", "labelSelector=app=api"},
+	}
+
+	report, err := NewReport(validOpts)
+	require.NoError(t, err)
+	assert.Equal(t, "namespace-cpu-request-july", report.Name)
+	assert.Equal(t, "metering", report.Namespace)
+	assert.Equal(t, "namespace-cpu-request", report.Spec.GenerationQueryName)
+	assert.Equal(t, start, report.Spec.ReportingStart.Time)
+	assert.Equal(t, end, report.Spec.ReportingEnd.Time)
+	assert.Equal(t, []cbTypes.ReportGenerationQueryInputValue{
+		{Name: "namespace", Value: "web"},
+		{Name: "labelSelector", Value: "app=api"},
+	}, report.Spec.Inputs)
+
+	invalid := map[string]func(opts *ReportOptions){
+		"missing name":       func(opts *ReportOptions) { opts.Name = "" },
+		"missing query":      func(opts *ReportOptions) { opts.GenerationQuery = "" },
+		"end before start":   func(opts *ReportOptions) { opts.ReportingEnd = start.Add(-time.Hour) },
+		"input without name": func(opts *ReportOptions) { opts.Inputs = []string{"=web"} },
+		"input without =":    func(opts *ReportOptions) { opts.Inputs = []string{"namespace"} },
+		"duplicate input":    func(opts *ReportOptions) { opts.Inputs = []string{"namespace=a", "namespace=b"} },
+	}
+	for name, modify := range invalid {
+		opts := validOpts
+		modify(&opts)
+		_, err := NewReport(opts)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestWaitForReport(t *testing.T) {
+	newReport := func(name string, phase cbTypes.ReportPhase, output string) *cbTypes.Report {
+		return &cbTypes.Report{
+			ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "metering"},
+			Status:     cbTypes.ReportStatus{Phase: phase, Output: output},
+		}
+	}
+	client := fake.NewSimpleClientset(
+		newReport("finished", cbTypes.ReportPhaseFinished, ""),
+		newReport("failed", cbTypes.ReportPhaseError, "query failed"),
+		newReport("running", cbTypes.ReportPhaseStarted, ""),
+	).MeteringV1alpha1()
+
+	var out bytes.Buffer
+	report, err := WaitForReport(client, "metering", "finished", time.Millisecond, time.Second, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "finished", report.Name)
+	assert.Contains(t, out.String(), "Finished")
+
+	_, err = WaitForReport(client, "metering", "failed", time.Millisecond, time.Second, &out)
+	require.Error(t, err)
+	assert.IsType(t, &ErrReportFailed{}, err)
+	assert.Contains(t, err.Error(), "query failed")
+
+	_, err = WaitForReport(client, "metering", "running", time.Millisecond, 10*time.Millisecond, &out)
+	assert.Error(t, err)
+
+	_, err = WaitForReport(client, "metering", "missing", time.Millisecond, time.Second, &out)
+	assert.Error(t, err)
+}
+
+func TestReportProgress(t *testing.T) {
+	report := &cbTypes.Report{}
+	assert.Equal(t, "Pending", ReportProgress(report))
+
+	report.Status.Phase = cbTypes.ReportPhaseWaiting
+	cbutil.SetReportCondition(&report.Status, *cbutil.NewReportCondition(cbTypes.ReportReady, v1.ConditionFalse, cbutil.DependenciesNotReadyReason, "waiting for data"))
+	assert.Equal(t, "Waiting (DependenciesNotReady): waiting for data", ReportProgress(report))
+
+	report.Status.Phase = cbTypes.ReportPhaseFinished
+	cbutil.SetReportCondition(&report.Status, *cbutil.NewReportCondition(cbTypes.ReportReady, v1.ConditionTrue, cbutil.ReportFinishedReason, "done"))
+	assert.Equal(t, "Finished", ReportProgress(report))
+}
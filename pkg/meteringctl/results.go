@@ -0,0 +1,81 @@
+package meteringctl
+
+import (
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+const (
+	// ReportingOperatorServiceName is the name of the reporting-operator's
+	// Service, which results are fetched through.
+	ReportingOperatorServiceName = "reporting-operator"
+	// reportingOperatorServicePortName is the name of the port of the
+	// reporting-operator's Service the HTTP API is served on.
+	reportingOperatorServicePortName = "http"
+
+	reportsGetEndpoint          = "/api/v1/reports/get"
+	scheduledReportsGetEndpoint = "/api/v1/scheduledreports/get"
+)
+
+// ResultsFormats are the formats results can be fetched in. parquet and
+// xlsx are binary, and should be written to a file.
+var ResultsFormats = []string{"json", "csv", "tab", "tabular", "parquet", "xlsx"}
+
+// ResultsOptions configure how the results of a report are fetched.
+type ResultsOptions struct {
+	// MeteringNamespace is the namespace the reporting-operator is
+	// installed in.
+	MeteringNamespace string
+	// Namespace is the namespace of the report, if it's not the metering
+	// namespace.
+	Namespace string
+	Name      string
+	// Scheduled is true if the report is a ScheduledReport.
+	Scheduled bool
+	Format    string
+	// HTTPS is true if the reporting-operator serves its API with TLS.
+	HTTPS bool
+}
+
+// FetchResults fetches the results of the report from the
+// reporting-operator's HTTP API through the Kubernetes API server's service
+// proxy, and copies them to w.
+func FetchResults(services corev1.ServicesGetter, opts ResultsOptions, w io.Writer) error {
+	if !isResultsFormat(opts.Format) {
+		return fmt.Errorf("invalid format %q, must be one of %v", opts.Format, ResultsFormats)
+	}
+	endpoint := reportsGetEndpoint
+	if opts.Scheduled {
+		endpoint = scheduledReportsGetEndpoint
+	}
+	params := map[string]string{
+		"name":   opts.Name,
+		"format": opts.Format,
+	}
+	if opts.Namespace != "" && opts.Namespace != opts.MeteringNamespace {
+		params["namespace"] = opts.Namespace
+	}
+	scheme := "http"
+	if opts.HTTPS {
+		scheme = "https"
+	}
+
+	body, err := services.Services(opts.MeteringNamespace).ProxyGet(scheme, ReportingOperatorServiceName, reportingOperatorServicePortName, endpoint, params).Stream()
+	if err != nil {
+		return fmt.Errorf("unable to fetch the results of %s: %v", opts.Name, err)
+	}
+	defer body.Close()
+	_, err = io.Copy(w, body)
+	return err
+}
+
+func isResultsFormat(format string) bool {
+	for _, f := range ResultsFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
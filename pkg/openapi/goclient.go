@@ -0,0 +1,422 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+)
+
+// GenerateGoClient generates the types and methods of a Go client for the API
+// described by doc. The generated methods are on a Client type, and use its
+// doRequest and doJSON methods and the jsonBody and decodeJSON functions,
+// which must be written by hand in the same package.
+func GenerateGoClient(doc *Document, packageName string) ([]byte, error) {
+	g := &goClientGenerator{doc: doc}
+	g.printf("// Code generated by reportingapi-gen. DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", packageName)
+	g.printf("import (\n\"context\"\n\"fmt\"\n\"io\"\n\"net/http\"\n\"net/url\"\n\"strconv\"\n\"strings\"\n\"time\"\n)\n\n")
+	// ensure every import is used, regardless of which operations exist.
+	g.printf("var (\n_ = fmt.Sprintf\n_ = io.EOF\n_ = strconv.Itoa\n_ = strings.Join\n_ time.Time\n)\n\n")
+
+	var schemaNames []string
+	for name := range doc.Components.Schemas {
+		schemaNames = append(schemaNames, name)
+	}
+	sort.Strings(schemaNames)
+	for _, name := range schemaNames {
+		if err := g.generateType(name, doc.Components.Schemas[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	var operations []operationInfo
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			operations = append(operations, operationInfo{method: strings.ToUpper(method), path: path, op: op})
+		}
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		return operations[i].op.OperationID < operations[j].op.OperationID
+	})
+	for _, info := range operations {
+		if err := g.generateOperation(info); err != nil {
+			return nil, fmt.Errorf("operation %s: %v", info.op.OperationID, err)
+		}
+	}
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("unable to format generated client: %v", err)
+	}
+	return src, nil
+}
+
+type operationInfo struct {
+	method string
+	path   string
+	op     *Operation
+}
+
+type goClientGenerator struct {
+	doc *Document
+	buf bytes.Buffer
+}
+
+func (g *goClientGenerator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// goName converts a JSON or parameter name into an exported Go identifier.
+func goName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '_' || r == '-' {
+			upper = true
+			continue
+		}
+		if upper {
+			b.WriteString(strings.ToUpper(string(r)))
+			upper = false
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func (g *goClientGenerator) goType(schema *Schema) (string, error) {
+	if schema == nil {
+		return "interface{}", nil
+	}
+	if schema.Ref != "" {
+		name := RefName(schema.Ref)
+		if _, exists := g.doc.Components.Schemas[name]; !exists {
+			return "", fmt.Errorf("reference to unknown schema %q", name)
+		}
+		return name, nil
+	}
+	switch schema.Type {
+	case "":
+		return "interface{}", nil
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			return "time.Time", nil
+		case "binary":
+			return "[]byte", nil
+		}
+		return "string", nil
+	case "boolean":
+		return "bool", nil
+	case "integer":
+		if schema.Format == "int32" {
+			return "int32", nil
+		}
+		return "int64", nil
+	case "number":
+		return "float64", nil
+	case "array":
+		itemType, err := g.goType(schema.Items)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + itemType, nil
+	case "object":
+		if schema.AdditionalProperties != nil {
+			valueType, err := g.goType(schema.AdditionalProperties)
+			if err != nil {
+				return "", err
+			}
+			return "map[string]" + valueType, nil
+		}
+		var b bytes.Buffer
+		if err := g.writeStructFields(&b, schema); err != nil {
+			return "", err
+		}
+		return "struct {\n" + b.String() + "}", nil
+	}
+	return "", fmt.Errorf("unsupported schema type %q", schema.Type)
+}
+
+func (g *goClientGenerator) writeStructFields(b *bytes.Buffer, schema *Schema) error {
+	required := make(map[string]bool)
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	var names []string
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fieldType, err := g.goType(schema.Properties[name])
+		if err != nil {
+			return fmt.Errorf("property %s: %v", name, err)
+		}
+		tag := name
+		if !required[name] {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(b, "%s %s `json:\"%s\"`\n", goName(name), fieldType, tag)
+	}
+	return nil
+}
+
+func (g *goClientGenerator) generateType(name string, schema *Schema) error {
+	goType, err := g.goType(&Schema{Type: schema.Type, Properties: schema.Properties, Required: schema.Required, AdditionalProperties: schema.AdditionalProperties, Items: schema.Items, Format: schema.Format})
+	if err != nil {
+		return fmt.Errorf("schema %s: %v", name, err)
+	}
+	g.printf("type %s %s\n\n", name, goType)
+	return nil
+}
+
+// statusConstants are the net/http constants of the successful statuses
+// operations can return.
+var statusConstants = map[string]string{
+	"200": "http.StatusOK",
+	"201": "http.StatusCreated",
+	"202": "http.StatusAccepted",
+	"204": "http.StatusNoContent",
+}
+
+// successStatus returns the status code of the successful response of op,
+// which is 200 unless op only succeeds with another 2xx status.
+func successStatus(op *Operation) string {
+	if _, ok := op.Responses["200"]; ok {
+		return "200"
+	}
+	var codes []string
+	for code := range op.Responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 0 {
+		return "200"
+	}
+	sort.Strings(codes)
+	return codes[0]
+}
+
+// resultType returns the Go type the successful response of op is decoded
+// into. If the response isn't only JSON, the *http.Response is returned to
+// the caller instead, and if it has no body, only an error is returned.
+func (g *goClientGenerator) resultType(op *Operation) (string, bool, error) {
+	resp := op.Responses[successStatus(op)]
+	if resp == nil || len(resp.Content) == 0 {
+		return "", false, nil
+	}
+	media, isJSON := resp.Content["application/json"]
+	if !isJSON || len(resp.Content) != 1 || media.Schema == nil || media.Schema.Type == "object" {
+		return "*http.Response", true, nil
+	}
+	if RefName(media.Schema.Ref) == "EmptyResponse" {
+		return "", false, nil
+	}
+	goType, err := g.goType(media.Schema)
+	if err != nil {
+		return "", false, err
+	}
+	return goType, false, nil
+}
+
+func (g *goClientGenerator) generateOperation(info operationInfo) error {
+	op := info.op
+	name := goName(op.OperationID)
+	paramsType := name + "Params"
+	if len(op.Parameters) != 0 {
+		g.printf("// %s are the parameters of %s.\n", paramsType, name)
+		g.printf("type %s struct {\n", paramsType)
+		for _, param := range op.Parameters {
+			paramType, err := g.goType(param.Schema)
+			if err != nil {
+				return fmt.Errorf("parameter %s: %v", param.Name, err)
+			}
+			if param.Description != "" {
+				g.printf("// %s\n", param.Description)
+			}
+			g.printf("%s %s\n", goName(param.Name), paramType)
+		}
+		g.printf("}\n\n")
+	}
+
+	args := []string{"ctx context.Context"}
+	if len(op.Parameters) != 0 {
+		args = append(args, "params "+paramsType)
+	}
+	bodyContentType := ""
+	if op.RequestBody != nil {
+		if media, ok := op.RequestBody.Content["application/json"]; ok {
+			bodyType, err := g.goType(media.Schema)
+			if err != nil {
+				return fmt.Errorf("request body: %v", err)
+			}
+			args = append(args, "body "+bodyType)
+			bodyContentType = "application/json"
+		} else {
+			for contentType := range op.RequestBody.Content {
+				bodyContentType = contentType
+			}
+			args = append(args, "body io.Reader")
+		}
+	}
+
+	result, rawResponse, err := g.resultType(op)
+	if err != nil {
+		return fmt.Errorf("response: %v", err)
+	}
+	returns := "error"
+	if result != "" {
+		returns = fmt.Sprintf("(%s, error)", result)
+	}
+
+	g.printf("// %s calls %s %s.", name, info.method, info.path)
+	if op.Summary != "" {
+		g.printf(" %s", op.Summary)
+	}
+	g.printf("\n")
+	if rawResponse {
+		g.printf("// The caller must close the body of the returned response.\n")
+	}
+	g.printf("func (c *Client) %s(%s) %s {\n", name, strings.Join(args, ", "), returns)
+
+	path := info.path
+	var pathArgs []string
+	query := false
+	for _, param := range op.Parameters {
+		switch param.In {
+		case InPath:
+			placeholder := "{" + param.Name + "}"
+			path = strings.Replace(path, placeholder, "%s", 1)
+			pathArgs = append(pathArgs, fmt.Sprintf("url.PathEscape(params.%s)", goName(param.Name)))
+		case InQuery:
+			query = true
+		}
+	}
+	if len(pathArgs) != 0 {
+		g.printf("path := fmt.Sprintf(%q, %s)\n", path, strings.Join(pathArgs, ", "))
+	} else {
+		g.printf("path := %q\n", path)
+	}
+	if query {
+		g.printf("query := make(url.Values)\n")
+		for _, param := range op.Parameters {
+			if param.In != InQuery {
+				continue
+			}
+			if err := g.generateQueryParam(param); err != nil {
+				return fmt.Errorf("parameter %s: %v", param.Name, err)
+			}
+		}
+	} else {
+		g.printf("var query url.Values\n")
+	}
+
+	status, ok := statusConstants[successStatus(op)]
+	if !ok {
+		return fmt.Errorf("unsupported successful status %s", successStatus(op))
+	}
+	errReturn := "return err"
+	if result != "" {
+		errReturn = fmt.Sprintf("return %s, err", zeroValue(result))
+	}
+	switch {
+	case rawResponse && bodyContentType == "application/json":
+		g.printf("reqBody, err := jsonBody(body)\n")
+		g.printf("if err != nil {\n%s\n}\n", errReturn)
+		g.printf("return c.doRequest(ctx, %q, path, query, %s, %q, reqBody)\n", info.method, status, bodyContentType)
+	case rawResponse:
+		g.printf("return c.doRequest(ctx, %q, path, query, %s, %q, %s)\n", info.method, status, bodyContentType, bodyArg(bodyContentType))
+	case bodyContentType != "" && bodyContentType != "application/json":
+		g.printf("resp, err := c.doRequest(ctx, %q, path, query, %s, %q, body)\n", info.method, status, bodyContentType)
+		g.printf("if err != nil {\n%s\n}\n", errReturn)
+		g.printf("defer resp.Body.Close()\n")
+		if result != "" {
+			g.printf("var result %s\n", result)
+			g.printf("err = decodeJSON(resp, &result)\n")
+			g.printf("return result, err\n")
+		} else {
+			g.printf("return nil\n")
+		}
+	default:
+		body := "nil"
+		if bodyContentType != "" {
+			body = "body"
+		}
+		if result != "" {
+			g.printf("var result %s\n", result)
+			g.printf("err := c.doJSON(ctx, %q, path, query, %s, %s, &result)\n", info.method, status, body)
+			g.printf("return result, err\n")
+		} else {
+			g.printf("return c.doJSON(ctx, %q, path, query, %s, %s, nil)\n", info.method, status, body)
+		}
+	}
+	g.printf("}\n\n")
+	return nil
+}
+
+func bodyArg(contentType string) string {
+	if contentType == "" {
+		return "nil"
+	}
+	return "body"
+}
+
+func (g *goClientGenerator) generateQueryParam(param Parameter) error {
+	field := "params." + goName(param.Name)
+	schema := param.Schema
+	set := func(value string) {
+		g.printf("query.Set(%q, %s)\n", param.Name, value)
+	}
+	switch schema.Type {
+	case "string":
+		value := field
+		if schema.Format == "date-time" {
+			g.printf("if !%s.IsZero() {\n", field)
+			set(field + ".Format(time.RFC3339)")
+			g.printf("}\n")
+			return nil
+		}
+		if param.Required {
+			set(value)
+			return nil
+		}
+		g.printf("if %s != \"\" {\n", field)
+		set(value)
+		g.printf("}\n")
+	case "integer":
+		g.printf("if %s != 0 {\n", field)
+		set(fmt.Sprintf("strconv.FormatInt(int64(%s), 10)", field))
+		g.printf("}\n")
+	case "boolean":
+		g.printf("if %s {\n", field)
+		set(fmt.Sprintf("strconv.FormatBool(%s)", field))
+		g.printf("}\n")
+	case "array":
+		if schema.Items == nil || schema.Items.Type != "string" {
+			return fmt.Errorf("only arrays of strings are supported")
+		}
+		g.printf("if len(%s) != 0 {\n", field)
+		if param.Explode != nil && !*param.Explode {
+			set(fmt.Sprintf("strings.Join(%s, \",\")", field))
+		} else {
+			g.printf("query[%q] = %s\n", param.Name, field)
+		}
+		g.printf("}\n")
+	default:
+		return fmt.Errorf("unsupported type %q", schema.Type)
+	}
+	return nil
+}
+
+func zeroValue(goType string) string {
+	switch {
+	case strings.HasPrefix(goType, "*"), strings.HasPrefix(goType, "[]"), strings.HasPrefix(goType, "map["):
+		return "nil"
+	}
+	return goType + "{}"
+}
@@ -0,0 +1,231 @@
+// Package openapi contains the subset of the OpenAPI 3.0 specification used
+// to describe the reporting-operator's HTTP API, and builds schemas from Go
+// types so the specification can't drift from the types the API encodes.
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const Version = "3.0.0"
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+	// Security lists the alternative SecuritySchemes requests can use. An
+	// empty requirement makes the schemes optional.
+	Security []SecurityRequirement `json:"security,omitempty"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem is the operations of a path, keyed by their lowercase HTTP
+// method.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter locations.
+const (
+	InPath  = "path"
+	InQuery = "query"
+)
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+	// Explode, if false, means array values are passed as a single comma
+	// separated parameter rather than by repeating the parameter.
+	Explode *bool `json:"explode,omitempty"`
+}
+
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	Scheme      string `json:"scheme,omitempty"`
+}
+
+// SecurityRequirement maps the names of SecuritySchemes to the scopes
+// required, which are empty for non-OAuth2 schemes.
+type SecurityRequirement map[string][]string
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// RefPrefix is the prefix of references to component schemas.
+const RefPrefix = "#/components/schemas/"
+
+// RefName returns the name of the component schema a $ref refers to.
+func RefName(ref string) string {
+	return strings.TrimPrefix(ref, RefPrefix)
+}
+
+// Ref returns a schema referencing the named component schema.
+func Ref(name string) *Schema {
+	return &Schema{Ref: RefPrefix + name}
+}
+
+func String(description string) *Schema {
+	return &Schema{Type: "string", Description: description}
+}
+
+func StringEnum(description string, values ...string) *Schema {
+	return &Schema{Type: "string", Description: description, Enum: values}
+}
+
+func ArrayOf(items *Schema) *Schema {
+	return &Schema{Type: "array", Items: items}
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// AddSchema adds a component schema with the given name for the struct v,
+// and a schema for each named struct type it contains, and returns a
+// reference to it. Struct fields are named by their json tags, and fields
+// without omitempty are required.
+func (c *Components) AddSchema(name string, v interface{}) *Schema {
+	if c.Schemas == nil {
+		c.Schemas = make(map[string]*Schema)
+	}
+	t := reflect.TypeOf(v)
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("openapi: %s must be a struct", t))
+	}
+	c.Schemas[name] = c.structSchema(t)
+	return Ref(name)
+}
+
+func (c *Components) schemaFor(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "A duration in nanoseconds."}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return c.schemaFor(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Interface:
+		// any value
+		return &Schema{}
+	case reflect.Slice, reflect.Array:
+		return ArrayOf(c.schemaFor(t.Elem()))
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			panic(fmt.Sprintf("openapi: map key of %s must be a string", t))
+		}
+		return &Schema{Type: "object", AdditionalProperties: c.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return c.structSchema(t)
+		}
+		if _, exists := c.Schemas[t.Name()]; !exists {
+			// reserve the name before building the schema, in case the type
+			// refers to itself.
+			c.Schemas[t.Name()] = nil
+			c.Schemas[t.Name()] = c.structSchema(t)
+		}
+		return Ref(t.Name())
+	}
+	panic(fmt.Sprintf("openapi: unsupported type %s", t))
+}
+
+func (c *Components) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Name
+		omitEmpty := false
+		if tag := field.Tag.Get("json"); tag != "" {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			for _, opt := range parts[1:] {
+				if opt == "omitempty" {
+					omitEmpty = true
+				}
+			}
+		}
+		schema.Properties[name] = c.schemaFor(field.Type)
+		if !omitEmpty {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
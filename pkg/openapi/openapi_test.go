@@ -0,0 +1,48 @@
+package openapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testItem struct {
+	Name string `json:"name"`
+}
+
+type testObject struct {
+	Name     string            `json:"name"`
+	Count    int               `json:"count,omitempty"`
+	Created  time.Time         `json:"created"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Items    []testItem        `json:"items"`
+	Value    interface{}       `json:"value"`
+	Ignored  string            `json:"-"`
+	internal string
+}
+
+func TestAddSchema(t *testing.T) {
+	var c Components
+	ref := c.AddSchema("TestObject", testObject{})
+	assert.Equal(t, RefPrefix+"TestObject", ref.Ref)
+	assert.Equal(t, "TestObject", RefName(ref.Ref))
+
+	assert.Equal(t, &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"name":    {Type: "string"},
+			"count":   {Type: "integer", Format: "int32"},
+			"created": {Type: "string", Format: "date-time"},
+			"labels":  {Type: "object", AdditionalProperties: &Schema{Type: "string"}},
+			"items":   ArrayOf(Ref("testItem")),
+			"value":   {},
+		},
+		Required: []string{"name", "created", "items", "value"},
+	}, c.Schemas["TestObject"])
+	assert.Equal(t, &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{"name": {Type: "string"}},
+		Required:   []string{"name"},
+	}, c.Schemas["testItem"], "expected nested structs to be added as schemas")
+}
@@ -0,0 +1,235 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	APIV1QueryEndpoint = "/api/v1/query"
+
+	// forwardedUserHeader is the header the auth proxy sets to the name of
+	// the authenticated user.
+	forwardedUserHeader = "X-Forwarded-User"
+	// truncatedHeader is set on ad-hoc query results which were truncated
+	// at the row limit.
+	truncatedHeader = "X-Results-Truncated"
+
+	// DefaultQueryRoleName is the name of the QueryRole used for callers
+	// who aren't in any other role.
+	DefaultQueryRoleName = "default"
+	// DefaultQueryMaxRows and DefaultQueryTimeout are the limits of the
+	// default QueryRole, unless it's configured.
+	DefaultQueryMaxRows = 1000
+	DefaultQueryTimeout = time.Minute
+)
+
+// QueryRole limits the ad-hoc queries of the users in the role.
+type QueryRole struct {
+	Name string `json:"name"`
+	// Users are the names of the users in the role, as authenticated by the
+	// auth proxy.
+	Users []string `json:"users,omitempty"`
+	// MaxRows is the most rows a query returns. Results with more rows are
+	// truncated.
+	MaxRows int `json:"maxRows"`
+	// Timeout is how long a query can take before it's cancelled.
+	Timeout meta.Duration `json:"timeout"`
+}
+
+// QueryConfig configures the ad-hoc query API.
+type QueryConfig struct {
+	// Roles limit the queries of their users. Callers who aren't in any
+	// role use the role named default, or DefaultQueryMaxRows and
+	// DefaultQueryTimeout if there isn't one.
+	Roles []QueryRole
+	// TrustForwardedUser matches callers to roles using the user set by the
+	// auth proxy in the X-Forwarded-User header. It must only be set when
+	// the API is only reachable through the auth proxy, otherwise callers
+	// can choose their role.
+	TrustForwardedUser bool
+	// Cache configures the cache of the results of ad-hoc queries and of
+	// the report results endpoints.
+	Cache QueryCacheConfig
+}
+
+// ParseQueryRoles parses a JSON list of QueryRoles.
+func ParseQueryRoles(s string) ([]QueryRole, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var roles []QueryRole
+	if err := json.Unmarshal([]byte(s), &roles); err != nil {
+		return nil, fmt.Errorf("invalid query roles: %v", err)
+	}
+	return roles, nil
+}
+
+func (cfg QueryConfig) Valid() error {
+	names := make(map[string]bool)
+	users := make(map[string]string)
+	for i, role := range cfg.Roles {
+		if role.Name == "" {
+			return fmt.Errorf("query role %d must have a name", i)
+		}
+		if names[role.Name] {
+			return fmt.Errorf("query role %s is defined more than once", role.Name)
+		}
+		names[role.Name] = true
+		if role.MaxRows <= 0 {
+			return fmt.Errorf("query role %s must have a positive maxRows", role.Name)
+		}
+		if role.Timeout.Duration <= 0 {
+			return fmt.Errorf("query role %s must have a positive timeout", role.Name)
+		}
+		for _, user := range role.Users {
+			if other, exists := users[user]; exists {
+				return fmt.Errorf("user %s is in query roles %s and %s, users can only be in one role", user, other, role.Name)
+			}
+			users[user] = role.Name
+		}
+	}
+	return cfg.Cache.Valid()
+}
+
+// roleFor returns the QueryRole of the user making the request, who is the
+// authenticated user if API authentication is enabled.
+func (cfg QueryConfig) roleFor(r *http.Request) QueryRole {
+	defaultRole := QueryRole{
+		Name:    DefaultQueryRoleName,
+		MaxRows: DefaultQueryMaxRows,
+		Timeout: meta.Duration{Duration: DefaultQueryTimeout},
+	}
+	user := ""
+	if authUser, ok := authenticatedUser(r.Context()); ok {
+		user = authUser.Username
+	} else if cfg.TrustForwardedUser {
+		user = r.Header.Get(forwardedUserHeader)
+	}
+	for _, role := range cfg.Roles {
+		if role.Name == DefaultQueryRoleName {
+			defaultRole = role
+		}
+		if user == "" {
+			continue
+		}
+		for _, roleUser := range role.Users {
+			if roleUser == user {
+				return role
+			}
+		}
+	}
+	return defaultRole
+}
+
+// QueryRequest runs a ReportGenerationQuery and returns its results
+// directly, without storing them in a table.
+type QueryRequest struct {
+	GenerationQuery string                                    `json:"generationQuery"`
+	ReportingStart  time.Time                                 `json:"reportingStart"`
+	ReportingEnd    time.Time                                 `json:"reportingEnd"`
+	Timezone        string                                    `json:"timezone,omitempty"`
+	Inputs          []cbTypes.ReportGenerationQueryInputValue `json:"inputs,omitempty"`
+	GroupByLabels   []string                                  `json:"groupByLabels,omitempty"`
+	// Columns are the columns returned, defaulting to every column.
+	Columns []string `json:"columns,omitempty"`
+	// Filters are filter expressions every row returned must match.
+	Filters []string `json:"filters,omitempty"`
+	// Limit is the most rows returned. It can't exceed the maxRows of the
+	// caller's QueryRole.
+	Limit int `json:"limit,omitempty"`
+}
+
+func (srv *server) queryHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	if err := checkForFields([]string{"format"}, r.URL.Query()); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "json", "csv", "tab", "tabular", "parquet", "xlsx":
+	default:
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "format must be one of: csv, json, tabular, parquet or xlsx")
+		return
+	}
+
+	var req QueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to decode request as JSON: %v", err)
+		return
+	}
+	role := srv.queryConfig.roleFor(r)
+	logger = logger.WithFields(log.Fields{"generationQuery": req.GenerationQuery, "queryRole": role.Name})
+	limit := role.MaxRows
+	if req.Limit < 0 {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "limit must not be negative")
+		return
+	} else if req.Limit > role.MaxRows {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "limit must not exceed %d, the most rows queries in role %s can return", role.MaxRows, role.Name)
+		return
+	} else if req.Limit > 0 {
+		limit = req.Limit
+	}
+
+	query, reportColumns, err := srv.reportRuns.renderQuery(logger, ReportRunRequest{
+		GenerationQuery: req.GenerationQuery,
+		ReportingStart:  req.ReportingStart,
+		ReportingEnd:    req.ReportingEnd,
+		Timezone:        req.Timezone,
+		Inputs:          req.Inputs,
+		GroupByLabels:   req.GroupByLabels,
+	})
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
+	prestoColumns, err := generatePrestoColumns(reportColumns)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "invalid columns for ReportGenerationQuery %s: %v", req.GenerationQuery, err)
+		return
+	}
+	reportColumns, prestoColumns, whereSQL, err := selectReportColumns(reportColumns, prestoColumns, req.Columns, req.Filters)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	// query one more row than the limit, to know if the results were
+	// truncated.
+	sql := fmt.Sprintf("%s LIMIT %d", presto.GenerateGetRowsWhereSQL("("+query+") AS adhoc_query", prestoColumns, whereSQL), limit+1)
+	ctx, cancel := context.WithTimeout(r.Context(), role.Timeout.Duration)
+	defer cancel()
+	results, err := srv.queryCache.get(sql, srv.adhocQueryDataVersion(r, req.GenerationQuery), func() ([]presto.Row, error) {
+		var results []presto.Row
+		err := presto.StreamQuery(ctx, srv.queryer, sql, func(row presto.Row) error {
+			results = append(results, row)
+			return nil
+		})
+		return results, err
+	})
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			writeErrorResponse(logger, w, r, http.StatusGatewayTimeout, "the query exceeded the timeout of %s for queries in role %s", role.Timeout.Duration, role.Name)
+			return
+		}
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "failed to run query: %v", err)
+		return
+	}
+	truncated := len(results) > limit
+	if truncated {
+		results = results[:limit]
+	}
+	w.Header().Set(truncatedHeader, strconv.FormatBool(truncated))
+	writeResultsResponse(logger, format, reportColumns, results, w, r)
+}
@@ -0,0 +1,186 @@
+package operator
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+func TestQueryHandler(t *testing.T) {
+	const query = "SELECT namespace, cost FROM datasource_costs"
+	start := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	queryFunc := func(logger log.FieldLogger, report *v1alpha1.Report) (string, []v1alpha1.ReportGenerationQueryColumn, error) {
+		if report.Spec.GenerationQueryName != "namespace-cost" {
+			return "", nil, k8serrors.NewNotFound(schema.GroupResource{Resource: "reportgenerationqueries"}, report.Spec.GenerationQueryName)
+		}
+		return query, []v1alpha1.ReportGenerationQueryColumn{
+			{Name: "namespace", Type: "string"},
+			{Name: "cost", Type: "double"},
+		}, nil
+	}
+	queryConfig := QueryConfig{
+		Roles: []QueryRole{
+			{Name: DefaultQueryRoleName, MaxRows: 2, Timeout: meta.Duration{Duration: time.Minute}},
+			{Name: "analysts", Users: []string{"alice"}, MaxRows: 10, Timeout: meta.Duration{Duration: time.Minute}},
+		},
+		TrustForwardedUser: true,
+	}
+	rows := []presto.Row{
+		{"namespace": "team-a", "cost": 75.0},
+		{"namespace": "team-b", "cost": 50.5},
+		{"namespace": "team-c", "cost": 25.0},
+	}
+
+	tests := map[string]struct {
+		req                QueryRequest
+		user               string
+		trustForwardedUser bool
+		expectedSQL        string
+		results            []presto.Row
+		expectedStatusCode int
+		expectedTruncated  string
+		expectedBody       string
+	}{
+		"truncated at the default role's limit": {
+			req:                QueryRequest{GenerationQuery: "namespace-cost", ReportingStart: start, ReportingEnd: end},
+			expectedSQL:        `SELECT "namespace","cost" FROM (` + query + `) AS adhoc_query ORDER BY "namespace", "cost" ASC LIMIT 3`,
+			results:            rows,
+			expectedStatusCode: http.StatusOK,
+			expectedTruncated:  "true",
+			expectedBody:       "namespace,cost\nteam-a,75.000000\nteam-b,50.500000\n",
+		},
+		"forwarded user's role": {
+			req:                QueryRequest{GenerationQuery: "namespace-cost", ReportingStart: start, ReportingEnd: end},
+			user:               "alice",
+			trustForwardedUser: true,
+			expectedSQL:        `SELECT "namespace","cost" FROM (` + query + `) AS adhoc_query ORDER BY "namespace", "cost" ASC LIMIT 11`,
+			results:            rows,
+			expectedStatusCode: http.StatusOK,
+			expectedTruncated:  "false",
+			expectedBody:       "namespace,cost\nteam-a,75.000000\nteam-b,50.500000\nteam-c,25.000000\n",
+		},
+		"untrusted forwarded user": {
+			req:                QueryRequest{GenerationQuery: "namespace-cost", ReportingStart: start, ReportingEnd: end, Limit: 5},
+			user:               "alice",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"columns and filters": {
+			req: QueryRequest{
+				GenerationQuery: "namespace-cost",
+				ReportingStart:  start,
+				ReportingEnd:    end,
+				Columns:         []string{"namespace"},
+				Filters:         []string{"cost>50"},
+				Limit:           1,
+			},
+			expectedSQL:        `SELECT "namespace" FROM (` + query + `) AS adhoc_query WHERE "cost" > DOUBLE '50' ORDER BY "namespace" ASC LIMIT 2`,
+			results:            []presto.Row{{"namespace": "team-a"}},
+			expectedStatusCode: http.StatusOK,
+			expectedTruncated:  "false",
+			expectedBody:       "namespace\nteam-a\n",
+		},
+		"missing query": {
+			req:                QueryRequest{GenerationQuery: "missing", ReportingStart: start, ReportingEnd: end},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"invalid filter": {
+			req:                QueryRequest{GenerationQuery: "namespace-cost", ReportingStart: start, ReportingEnd: end, Filters: []string{"pod=app-1"}},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			queryer := mockpresto.NewMockExecQueryer(ctrl)
+			if tt.expectedSQL != "" {
+				queryer.EXPECT().Query(tt.expectedSQL).Return(tt.results, nil)
+			}
+
+			cfg := queryConfig
+			cfg.TrustForwardedUser = tt.trustForwardedUser
+			router := newRouter(testLogger, routerConfig{
+				rand:               testRand,
+				queryer:            queryer,
+				importerQueryer:    queryer,
+				collectorFunc:      noopPrometheusImporterFunc,
+				reportRunQueryFunc: queryFunc,
+				queryConfig:        cfg,
+			})
+			body, err := json.Marshal(tt.req)
+			require.NoError(t, err)
+			req := httptest.NewRequest("POST", APIV1QueryEndpoint+"?format=csv", bytes.NewReader(body))
+			if tt.user != "" {
+				req.Header.Set(forwardedUserHeader, tt.user)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			resp := w.Result()
+			respBody, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedStatusCode, resp.StatusCode, "unexpected status code, body: %s", respBody)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tt.expectedTruncated, resp.Header.Get(truncatedHeader))
+			assert.Equal(t, tt.expectedBody, string(respBody))
+		})
+	}
+}
+
+func TestQueryConfigValid(t *testing.T) {
+	timeout := meta.Duration{Duration: time.Minute}
+	tests := map[string]struct {
+		roles       []QueryRole
+		expectedErr bool
+	}{
+		"valid": {
+			roles: []QueryRole{{Name: DefaultQueryRoleName, MaxRows: 10, Timeout: timeout}, {Name: "analysts", Users: []string{"alice"}, MaxRows: 100, Timeout: timeout}},
+		},
+		"duplicate role": {
+			roles:       []QueryRole{{Name: "analysts", MaxRows: 10, Timeout: timeout}, {Name: "analysts", MaxRows: 10, Timeout: timeout}},
+			expectedErr: true,
+		},
+		"user in two roles": {
+			roles:       []QueryRole{{Name: "a", Users: []string{"alice"}, MaxRows: 10, Timeout: timeout}, {Name: "b", Users: []string{"alice"}, MaxRows: 10, Timeout: timeout}},
+			expectedErr: true,
+		},
+		"no row limit": {
+			roles:       []QueryRole{{Name: "analysts", Timeout: timeout}},
+			expectedErr: true,
+		},
+		"no timeout": {
+			roles:       []QueryRole{{Name: "analysts", MaxRows: 10}},
+			expectedErr: true,
+		},
+	}
+	for name, tt := range tests {
+		err := QueryConfig{Roles: tt.roles}.Valid()
+		if tt.expectedErr {
+			assert.Error(t, err, name)
+		} else {
+			assert.NoError(t, err, name)
+		}
+	}
+}
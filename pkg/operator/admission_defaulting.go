@@ -0,0 +1,165 @@
+package operator
+
+import (
+	"encoding/json"
+	"net/http"
+
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+const (
+	DefaultingWebhookEndpoint = "/default"
+
+	admissionPatchTypeJSONPatch = "JSONPatch"
+)
+
+// jsonPatchOperation is an RFC 6902 JSON patch operation, which mutating
+// admission webhooks respond with to change the object being admitted.
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// admissionDefaults are the values the defaulting webhook sets on resources
+// which don't set them, which are otherwise the reporting-operator's
+// configuration and default StorageLocation at the time they're used.
+type admissionDefaults struct {
+	prometheusQueryConfig api.PrometheusQueryConfig
+	gracePeriod           meta.Duration
+	// storageLocationName is the default StorageLocation, and is empty if
+	// there isn't one.
+	storageLocationName string
+}
+
+// defaultingWebhookHandler is a mutating admission webhook which sets the
+// gracePeriod and output of Reports and ScheduledReports, and the
+// queryConfig and storage of Prometheus ReportDataSources, to the
+// reporting-operator's defaults when they're created, so that the values
+// in use are visible on the resource and don't change when the defaults
+// do.
+func (op *Reporting) defaultingWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(op.logger, r, op.rand)
+	if r.Method != http.MethodPost {
+		writeErrorResponse(logger, w, r, http.StatusMethodNotAllowed, "admission requests must be POST requests")
+		return
+	}
+
+	var review admissionReview
+	err := json.NewDecoder(r.Body).Decode(&review)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to decode AdmissionReview: %v", err)
+		return
+	}
+	if review.Request == nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "AdmissionReview request is missing")
+		return
+	}
+
+	req := review.Request
+	review.Request = nil
+	review.Response = &admissionResponse{UID: req.UID, Allowed: true}
+	if req.Operation != "CREATE" {
+		writeResponseAsJSON(logger, w, http.StatusOK, review)
+		return
+	}
+
+	defaults := admissionDefaults{
+		prometheusQueryConfig: op.cfg.PrometheusQueryConfig,
+		gracePeriod:           meta.Duration{Duration: op.getDefaultReportGracePeriod()},
+	}
+	storageLocation, err := op.getDefaultStorageLocation(op.informers.Metering().V1alpha1().StorageLocations().Lister())
+	if err != nil {
+		// without a default StorageLocation, the storage of resources
+		// is left unset, and is resolved when they're used.
+		logger.WithError(err).Warnf("unable to get the default StorageLocation")
+	} else if storageLocation != nil {
+		defaults.storageLocationName = storageLocation.Name
+	}
+
+	patch, err := admissionDefaultsPatch(req.Kind.Kind, req.Object, defaults)
+	if err != nil {
+		// fail open, the resource is still defaulted when it's used
+		logger.WithError(err).Errorf("unable to default %s %s", req.Kind.Kind, req.Name)
+	} else if len(patch) != 0 {
+		review.Response.Patch, err = json.Marshal(patch)
+		if err != nil {
+			writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to encode patch: %v", err)
+			return
+		}
+		patchType := admissionPatchTypeJSONPatch
+		review.Response.PatchType = &patchType
+	}
+	writeResponseAsJSON(logger, w, http.StatusOK, review)
+}
+
+// admissionDefaultsPatch returns the JSON patch setting the defaults of the
+// fields the object doesn't set. The fields defaulted are at the same paths
+// in every version of the resources, so the patch applies to the version
+// the object was sent as.
+func admissionDefaultsPatch(kind string, raw []byte, defaults admissionDefaults) ([]jsonPatchOperation, error) {
+	raw, err := convertObject(raw, api.SchemeGroupVersion.String())
+	if err != nil {
+		return nil, err
+	}
+
+	var patch []jsonPatchOperation
+	add := func(path string, value interface{}) {
+		patch = append(patch, jsonPatchOperation{Op: "add", Path: path, Value: value})
+	}
+	addStorage := func(path string, storage *api.StorageLocationRef) {
+		if defaults.storageLocationName != "" && (storage == nil || (storage.StorageSpec == nil && storage.StorageLocationName == "")) {
+			add(path, api.StorageLocationRef{StorageLocationName: defaults.storageLocationName})
+		}
+	}
+
+	switch kind {
+	case dependencyKindReport:
+		var report api.Report
+		if err := json.Unmarshal(raw, &report); err != nil {
+			return nil, err
+		}
+		if report.Spec.GracePeriod == nil {
+			add("/spec/gracePeriod", defaults.gracePeriod)
+		}
+		addStorage("/spec/output", report.Spec.Output)
+	case dependencyKindScheduledReport:
+		var report api.ScheduledReport
+		if err := json.Unmarshal(raw, &report); err != nil {
+			return nil, err
+		}
+		if report.Spec.GracePeriod == nil {
+			add("/spec/gracePeriod", defaults.gracePeriod)
+		}
+		addStorage("/spec/output", report.Spec.Output)
+	case dependencyKindReportDataSource:
+		var dataSource api.ReportDataSource
+		if err := json.Unmarshal(raw, &dataSource); err != nil {
+			return nil, err
+		}
+		promsum := dataSource.Spec.Promsum
+		if promsum == nil {
+			break
+		}
+		if promsum.QueryConfig == nil {
+			add("/spec/promsum/queryConfig", defaults.prometheusQueryConfig)
+		} else {
+			// the chunkSize and stepSize default to at most a queryInterval
+			// which is shorter than the defaults.
+			queryInterval, stepSize, chunkSize := resolvePrometheusQueryConfig(defaults.prometheusQueryConfig, promsum.QueryConfig)
+			if promsum.QueryConfig.QueryInterval == nil && defaults.prometheusQueryConfig.QueryInterval != nil {
+				add("/spec/promsum/queryConfig/queryInterval", meta.Duration{Duration: queryInterval})
+			}
+			if promsum.QueryConfig.StepSize == nil && defaults.prometheusQueryConfig.StepSize != nil {
+				add("/spec/promsum/queryConfig/stepSize", meta.Duration{Duration: stepSize})
+			}
+			if promsum.QueryConfig.ChunkSize == nil && defaults.prometheusQueryConfig.ChunkSize != nil {
+				add("/spec/promsum/queryConfig/chunkSize", meta.Duration{Duration: chunkSize})
+			}
+		}
+		addStorage("/spec/promsum/storage", promsum.Storage)
+	}
+	return patch, nil
+}
@@ -0,0 +1,127 @@
+package operator
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	meteringv1 "github.com/operator-framework/operator-metering/pkg/apis/metering/v1"
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestAdmissionDefaultsPatch(t *testing.T) {
+	defaults := admissionDefaults{
+		prometheusQueryConfig: v1alpha1.PrometheusQueryConfig{
+			QueryInterval: &meta.Duration{Duration: 5 * time.Minute},
+			StepSize:      &meta.Duration{Duration: time.Minute},
+			ChunkSize:     &meta.Duration{Duration: 5 * time.Minute},
+		},
+		gracePeriod:         meta.Duration{Duration: 5 * time.Minute},
+		storageLocationName: "hive",
+	}
+
+	tests := map[string]struct {
+		kind          string
+		object        interface{}
+		defaults      admissionDefaults
+		expectedPatch string
+	}{
+		"Report": {
+			kind: "Report",
+			object: &v1alpha1.Report{
+				TypeMeta: meta.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "Report"},
+				Spec:     v1alpha1.ReportSpec{GenerationQueryName: "namespace-cpu-request"},
+			},
+			defaults:      defaults,
+			expectedPatch: `[{"op":"add","path":"/spec/gracePeriod","value":"5m0s"},{"op":"add","path":"/spec/output","value":{"storageLocationName":"hive"}}]`,
+		},
+		"v1 Report": {
+			kind: "Report",
+			object: &meteringv1.Report{
+				TypeMeta: meta.TypeMeta{APIVersion: meteringv1.SchemeGroupVersion.String(), Kind: "Report"},
+				Spec: meteringv1.ReportSpec{
+					QueryName:   "namespace-cpu-request",
+					GracePeriod: &meta.Duration{Duration: time.Hour},
+				},
+			},
+			defaults:      defaults,
+			expectedPatch: `[{"op":"add","path":"/spec/output","value":{"storageLocationName":"hive"}}]`,
+		},
+		"ScheduledReport without a default StorageLocation": {
+			kind: "ScheduledReport",
+			object: &v1alpha1.ScheduledReport{
+				TypeMeta: meta.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "ScheduledReport"},
+				Spec:     v1alpha1.ScheduledReportSpec{GenerationQueryName: "namespace-cpu-request"},
+			},
+			defaults: admissionDefaults{
+				gracePeriod: meta.Duration{Duration: 5 * time.Minute},
+			},
+			expectedPatch: `[{"op":"add","path":"/spec/gracePeriod","value":"5m0s"}]`,
+		},
+		"ReportDataSource without queryConfig": {
+			kind: "ReportDataSource",
+			object: &v1alpha1.ReportDataSource{
+				TypeMeta: meta.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "ReportDataSource"},
+				Spec: v1alpha1.ReportDataSourceSpec{
+					Promsum: &v1alpha1.PrometheusMetricsDataSource{
+						Query:   "pod-request-cpu-cores",
+						Storage: &v1alpha1.StorageLocationRef{StorageLocationName: "s3"},
+					},
+				},
+			},
+			defaults:      defaults,
+			expectedPatch: `[{"op":"add","path":"/spec/promsum/queryConfig","value":{"queryInterval":"5m0s","stepSize":"1m0s","chunkSize":"5m0s"}}]`,
+		},
+		"ReportDataSource with partial queryConfig": {
+			kind: "ReportDataSource",
+			object: &v1alpha1.ReportDataSource{
+				TypeMeta: meta.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "ReportDataSource"},
+				Spec: v1alpha1.ReportDataSourceSpec{
+					Promsum: &v1alpha1.PrometheusMetricsDataSource{
+						Query:       "pod-request-cpu-cores",
+						QueryConfig: &v1alpha1.PrometheusQueryConfig{StepSize: &meta.Duration{Duration: 30 * time.Second}},
+					},
+				},
+			},
+			defaults:      defaults,
+			expectedPatch: `[{"op":"add","path":"/spec/promsum/queryConfig/queryInterval","value":"5m0s"},{"op":"add","path":"/spec/promsum/queryConfig/chunkSize","value":"5m0s"},{"op":"add","path":"/spec/promsum/storage","value":{"storageLocationName":"hive"}}]`,
+		},
+		"ReportDataSource with a short queryInterval": {
+			kind: "ReportDataSource",
+			object: &v1alpha1.ReportDataSource{
+				TypeMeta: meta.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "ReportDataSource"},
+				Spec: v1alpha1.ReportDataSourceSpec{
+					Promsum: &v1alpha1.PrometheusMetricsDataSource{
+						Query:       "pod-request-cpu-cores",
+						QueryConfig: &v1alpha1.PrometheusQueryConfig{QueryInterval: &meta.Duration{Duration: 30 * time.Second}},
+						Storage:     &v1alpha1.StorageLocationRef{StorageLocationName: "s3"},
+					},
+				},
+			},
+			defaults:      defaults,
+			expectedPatch: `[{"op":"add","path":"/spec/promsum/queryConfig/stepSize","value":"30s"},{"op":"add","path":"/spec/promsum/queryConfig/chunkSize","value":"30s"}]`,
+		},
+		"AWS billing ReportDataSource": {
+			kind: "ReportDataSource",
+			object: &v1alpha1.ReportDataSource{
+				TypeMeta: meta.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "ReportDataSource"},
+				Spec:     v1alpha1.ReportDataSourceSpec{AWSBilling: &v1alpha1.AWSBillingDataSource{}},
+			},
+			defaults:      defaults,
+			expectedPatch: `null`,
+		},
+	}
+	for name, tt := range tests {
+		raw, err := json.Marshal(tt.object)
+		require.NoError(t, err, name)
+		patch, err := admissionDefaultsPatch(tt.kind, raw, tt.defaults)
+		require.NoError(t, err, name)
+		encoded, err := json.Marshal(patch)
+		require.NoError(t, err, name)
+		assert.JSONEq(t, tt.expectedPatch, string(encoded), name)
+	}
+}
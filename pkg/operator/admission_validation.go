@@ -0,0 +1,233 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+const (
+	ValidationWebhookEndpoint = "/validate"
+
+	kindReportPrometheusQuery = "ReportPrometheusQuery"
+)
+
+// validationWebhookHandler is a validating admission webhook which rejects
+// creating or updating ReportGenerationQueries, Reports, ReportDataSources
+// and ReportPrometheusQueries which would otherwise only fail once the
+// reporting-operator uses them.
+func (srv *server) validationWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	if r.Method != http.MethodPost {
+		writeErrorResponse(logger, w, r, http.StatusMethodNotAllowed, "admission requests must be POST requests")
+		return
+	}
+
+	var review admissionReview
+	err := json.NewDecoder(r.Body).Decode(&review)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to decode AdmissionReview: %v", err)
+		return
+	}
+	if review.Request == nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "AdmissionReview request is missing")
+		return
+	}
+
+	review.Response = srv.validateAdmission(logger, review.Request)
+	review.Request = nil
+	writeResponseAsJSON(logger, w, http.StatusOK, review)
+}
+
+func (srv *server) validateAdmission(logger log.FieldLogger, req *admissionRequest) *admissionResponse {
+	resp := &admissionResponse{UID: req.UID, Allowed: true}
+	if req.Operation != "CREATE" && req.Operation != "UPDATE" {
+		return resp
+	}
+	kind := req.Kind.Kind
+
+	var validate func(logger log.FieldLogger, raw []byte) error
+	switch kind {
+	case dependencyKindReportGenerationQuery:
+		validate = srv.validateReportGenerationQueryAdmission
+	case dependencyKindReport:
+		validate = validateReportAdmission
+	case dependencyKindReportDataSource:
+		validate = srv.validateReportDataSourceAdmission
+	case kindReportPrometheusQuery:
+		validate = validateReportPrometheusQueryAdmission
+	default:
+		return resp
+	}
+
+	// Reports and ReportDataSources may be sent as any of the versions
+	// their CRDs serve, and are validated as v1alpha1.
+	raw, err := convertObject(req.Object, api.SchemeGroupVersion.String())
+	if err != nil {
+		// fail open, the webhook's failurePolicy determines what
+		// happens if the webhook itself is unavailable
+		logger.WithError(err).Errorf("unable to decode %s %s", kind, req.Name)
+		return resp
+	}
+	validationErr := validate(logger, raw)
+	if validationErr == nil {
+		return resp
+	}
+	// updates which don't change the spec are allowed, so that the status of
+	// resources whose dependencies have since changed can still be updated.
+	if req.Operation == "UPDATE" {
+		unchanged, err := admissionSpecUnchanged(raw, req.OldObject)
+		if err != nil {
+			logger.WithError(err).Errorf("unable to decode the previous %s %s", kind, req.Name)
+			return resp
+		}
+		if unchanged {
+			return resp
+		}
+	}
+
+	msg := fmt.Sprintf("%s %s is invalid: %v", kind, req.Name, validationErr)
+	logger.Infof("rejecting %s: %s", strings.ToLower(req.Operation), msg)
+	resp.Allowed = false
+	resp.Result = &meta.Status{
+		Status:  meta.StatusFailure,
+		Message: msg,
+		Reason:  meta.StatusReasonInvalid,
+		Code:    http.StatusUnprocessableEntity,
+	}
+	return resp
+}
+
+// admissionSpecUnchanged returns whether the v1alpha1 encoded object has the
+// same spec as the old object.
+func admissionSpecUnchanged(raw, oldRaw []byte) (bool, error) {
+	if len(oldRaw) == 0 {
+		return false, nil
+	}
+	oldRaw, err := convertObject(oldRaw, api.SchemeGroupVersion.String())
+	if err != nil {
+		return false, err
+	}
+	var obj, old struct {
+		Spec interface{} `json:"spec"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(oldRaw, &old); err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(obj.Spec, old.Spec), nil
+}
+
+// validateReportGenerationQueryAdmission checks that the query template
+// parses, and that the ReportDataSources the query uses exist.
+func (srv *server) validateReportGenerationQueryAdmission(logger log.FieldLogger, raw []byte) error {
+	var generationQuery api.ReportGenerationQuery
+	if err := json.Unmarshal(raw, &generationQuery); err != nil {
+		return err
+	}
+	if _, err := newQueryTemplate(generationQuery.Spec.Query); err != nil {
+		return err
+	}
+
+	dataSources, err := getTemplateDataSourceReferences(generationQuery.Spec.Query)
+	if err != nil {
+		return err
+	}
+	dataSources = append(append([]string(nil), generationQuery.Spec.DataSources...), dataSources...)
+	seen := make(map[string]struct{})
+	var missing []string
+	for _, name := range dataSources {
+		if _, exists := seen[name]; exists {
+			continue
+		}
+		seen[name] = struct{}{}
+		_, err := srv.listers.reportDataSources.Get(name)
+		if k8serrors.IsNotFound(err) {
+			missing = append(missing, name)
+		} else if err != nil {
+			logger.WithError(err).Errorf("unable to get ReportDataSource %s", name)
+		}
+	}
+	if len(missing) != 0 {
+		return fmt.Errorf("unknown ReportDataSources: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// validateReportAdmission checks that the reporting period doesn't end
+// before it starts, and validates the fields which don't depend on other
+// resources.
+func validateReportAdmission(logger log.FieldLogger, raw []byte) error {
+	var report api.Report
+	if err := json.Unmarshal(raw, &report); err != nil {
+		return err
+	}
+	start, end := report.Spec.ReportingStart.Time, report.Spec.ReportingEnd.Time
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		return fmt.Errorf("reportingEnd %s is before reportingStart %s", end, start)
+	}
+	if _, err := loadTimezone(report.Spec.Timezone); err != nil {
+		return err
+	}
+	if err := validateReportDeliveries(report.Spec.Deliveries); err != nil {
+		return err
+	}
+	if err := validateReportNotifications(report.Spec.Notifications); err != nil {
+		return err
+	}
+	if err := validateReportAssertions(report.Spec.Assertions); err != nil {
+		return err
+	}
+	if err := validateReportAmendments(report.Spec.Amendments); err != nil {
+		return err
+	}
+	return validateReportMetrics(report.Spec.Metrics)
+}
+
+// validateReportDataSourceAdmission checks that the ReportPrometheusQuery
+// of promsum ReportDataSources exists, and that their queryConfig and
+// archive are valid.
+func (srv *server) validateReportDataSourceAdmission(logger log.FieldLogger, raw []byte) error {
+	var dataSource api.ReportDataSource
+	if err := json.Unmarshal(raw, &dataSource); err != nil {
+		return err
+	}
+	if dataSource.Spec.Promsum == nil {
+		return nil
+	}
+	queryName := dataSource.Spec.Promsum.Query
+	if queryName == "" {
+		return fmt.Errorf("promsum query must be set")
+	}
+	if err := validatePrometheusQueryConfig(dataSource.Spec.Promsum.QueryConfig); err != nil {
+		return fmt.Errorf("promsum %v", err)
+	}
+	if err := validatePrometheusMetricsArchive(dataSource.Spec.Promsum.Archive); err != nil {
+		return fmt.Errorf("promsum %v", err)
+	}
+	_, err := srv.listers.reportPrometheusQueries.Get(queryName)
+	if k8serrors.IsNotFound(err) {
+		return fmt.Errorf("unknown ReportPrometheusQuery %s", queryName)
+	} else if err != nil {
+		logger.WithError(err).Errorf("unable to get ReportPrometheusQuery %s", queryName)
+	}
+	return nil
+}
+
+func validateReportPrometheusQueryAdmission(logger log.FieldLogger, raw []byte) error {
+	var query api.ReportPrometheusQuery
+	if err := json.Unmarshal(raw, &query); err != nil {
+		return err
+	}
+	return validatePromQL(query.Spec.Query)
+}
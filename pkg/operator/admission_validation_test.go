@@ -0,0 +1,160 @@
+package operator
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+)
+
+func TestValidateAdmission(t *testing.T) {
+	const namespace = "metering"
+	start := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+
+	dataSourceIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	promQueryIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	dataSourceIndexer.Add(&v1alpha1.ReportDataSource{ObjectMeta: meta.ObjectMeta{Name: "pod-cpu-usage", Namespace: namespace}})
+	promQueryIndexer.Add(&v1alpha1.ReportPrometheusQuery{ObjectMeta: meta.ObjectMeta{Name: "pod-cpu-usage", Namespace: namespace}})
+	srv := &server{
+		logger: testLogger,
+		listers: meteringListers{
+			reportDataSources:       listers.NewReportDataSourceLister(dataSourceIndexer).ReportDataSources(namespace),
+			reportPrometheusQueries: listers.NewReportPrometheusQueryLister(promQueryIndexer).ReportPrometheusQueries(namespace),
+		},
+	}
+
+	newGenerationQuery := func(query string, dataSources ...string) *v1alpha1.ReportGenerationQuery {
+		genQuery := newTestReportGenQuery("pod-cpu", namespace, nil)
+		genQuery.Spec.Query = query
+		genQuery.Spec.DataSources = dataSources
+		return genQuery
+	}
+	newDataSource := func(query string) *v1alpha1.ReportDataSource {
+		return &v1alpha1.ReportDataSource{
+			ObjectMeta: meta.ObjectMeta{Name: "pod-memory-usage", Namespace: namespace},
+			Spec:       v1alpha1.ReportDataSourceSpec{Promsum: &v1alpha1.PrometheusMetricsDataSource{Query: query}},
+		}
+	}
+	newPromQuery := func(query string) *v1alpha1.ReportPrometheusQuery {
+		return &v1alpha1.ReportPrometheusQuery{
+			ObjectMeta: meta.ObjectMeta{Name: "pod-memory-usage", Namespace: namespace},
+			Spec:       v1alpha1.ReportPrometheusQuerySpec{Query: query},
+		}
+	}
+	invalidReport := newTestReport("namespace-cpu", namespace, "pod-cpu", end, start, v1alpha1.ReportStatus{})
+	invalidReportStatusUpdate := invalidReport.DeepCopy()
+	invalidReportStatusUpdate.Status.Phase = v1alpha1.ReportPhaseError
+
+	tests := map[string]struct {
+		kind      string
+		operation string
+		object    interface{}
+		oldObject interface{}
+		allowed   bool
+	}{
+		"valid ReportGenerationQuery": {
+			kind:    "ReportGenerationQuery",
+			object:  newGenerationQuery(`SELECT * FROM {| dataSourceTableName "pod-cpu-usage" |}`, "pod-cpu-usage"),
+			allowed: true,
+		},
+		"ReportGenerationQuery with bad template syntax": {
+			kind:   "ReportGenerationQuery",
+			object: newGenerationQuery(`SELECT * FROM {| dataSourceTableName "pod-cpu-usage" `, "pod-cpu-usage"),
+		},
+		"ReportGenerationQuery with unknown datasource": {
+			kind:   "ReportGenerationQuery",
+			object: newGenerationQuery("SELECT 1", "pod-cpu-usage", "missing"),
+		},
+		"ReportGenerationQuery template referencing unknown datasource": {
+			kind:   "ReportGenerationQuery",
+			object: newGenerationQuery(`SELECT * FROM {| dataSourceTableName "missing" |}`),
+		},
+		"valid Report": {
+			kind:    "Report",
+			object:  newTestReport("namespace-cpu", namespace, "pod-cpu", start, end, v1alpha1.ReportStatus{}),
+			allowed: true,
+		},
+		"Report ending before it starts": {
+			kind:   "Report",
+			object: invalidReport,
+		},
+		"status update of invalid Report": {
+			kind:      "Report",
+			operation: "UPDATE",
+			object:    invalidReportStatusUpdate,
+			oldObject: invalidReport,
+			allowed:   true,
+		},
+		"ReportDataSource with unknown ReportPrometheusQuery": {
+			kind:   "ReportDataSource",
+			object: newDataSource("missing"),
+		},
+		"ReportDataSource with existing ReportPrometheusQuery": {
+			kind:    "ReportDataSource",
+			object:  newDataSource("pod-cpu-usage"),
+			allowed: true,
+		},
+		"valid ReportPrometheusQuery": {
+			kind:    "ReportPrometheusQuery",
+			object:  newPromQuery(`sum(rate(container_cpu_usage_seconds_total{container_name!="POD", pod_name=~"a[)]"}[1m])) by (pod_name)`),
+			allowed: true,
+		},
+		"ReportPrometheusQuery with unbalanced brackets": {
+			kind:   "ReportPrometheusQuery",
+			object: newPromQuery(`sum(container_memory_bytes{container_name!="POD"}`),
+		},
+		"ReportPrometheusQuery with unterminated string": {
+			kind:   "ReportPrometheusQuery",
+			object: newPromQuery(`container_memory_bytes{container_name!="POD}`),
+		},
+		"deleting is allowed": {
+			kind:      "Report",
+			operation: "DELETE",
+			allowed:   true,
+		},
+	}
+	for name, tt := range tests {
+		req := &admissionRequest{
+			UID:       "test-uid",
+			Kind:      meta.GroupVersionKind{Group: v1alpha1.GroupName, Version: "v1alpha1", Kind: tt.kind},
+			Operation: tt.operation,
+		}
+		if req.Operation == "" {
+			req.Operation = "CREATE"
+		}
+		if tt.object != nil {
+			req.Object = marshalAdmissionObject(t, tt.kind, tt.object)
+		}
+		if tt.oldObject != nil {
+			req.OldObject = marshalAdmissionObject(t, tt.kind, tt.oldObject)
+		}
+		resp := srv.validateAdmission(testLogger, req)
+		assert.Equal(t, req.UID, resp.UID, name)
+		assert.Equal(t, tt.allowed, resp.Allowed, name)
+		if !tt.allowed {
+			require.NotNil(t, resp.Result, name)
+			assert.Equal(t, meta.StatusReasonInvalid, resp.Result.Reason, name)
+		}
+	}
+}
+
+func marshalAdmissionObject(t *testing.T, kind string, obj interface{}) []byte {
+	raw, err := json.Marshal(obj)
+	require.NoError(t, err)
+	// the API server always sends the apiVersion and kind
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &fields))
+	fields["apiVersion"] = v1alpha1.SchemeGroupVersion.String()
+	fields["kind"] = kind
+	raw, err = json.Marshal(fields)
+	require.NoError(t, err)
+	return raw
+}
@@ -0,0 +1,330 @@
+package operator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi"
+	log "github.com/sirupsen/logrus"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	authenticationv1client "k8s.io/client-go/kubernetes/typed/authentication/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+const DefaultAPIAuthCacheTTL = time.Minute
+
+// APIAuthConfig configures the authentication and authorization of HTTP
+// API requests.
+type APIAuthConfig struct {
+	// Enabled authenticates requests by validating their bearer token using
+	// the TokenReview API, and authorizes them using SubjectAccessReviews
+	// against the Metering resources they access.
+	Enabled bool
+	// CacheTTL is how long the results of TokenReviews and
+	// SubjectAccessReviews are cached for.
+	CacheTTL time.Duration
+	// RowLevelSecurity restricts the rows of report results returned to
+	// the namespaces the authenticated user can access.
+	RowLevelSecurity RowLevelSecurityConfig
+}
+
+func (cfg APIAuthConfig) Valid() error {
+	if cfg.Enabled && cfg.CacheTTL < 0 {
+		return fmt.Errorf("the API auth cache TTL must not be negative")
+	}
+	if cfg.RowLevelSecurity.Enabled && !cfg.Enabled {
+		return fmt.Errorf("row-level security requires API auth to be enabled")
+	}
+	return cfg.RowLevelSecurity.Valid()
+}
+
+// routeAccess is the access to a Metering resource a caller must have to
+// use a route. Routes without a verb are authorized as non-resource URLs
+// instead.
+type routeAccess struct {
+	verb string
+	// resource is the plural name of the resource accessed. If
+	// resourceParam is set, the resource is the value of that URL
+	// parameter instead.
+	resource      string
+	resourceParam string
+	// nameParam, if set, is the URL or query parameter naming the resource
+	// accessed.
+	nameParam string
+	// namespaceParam, if set, is the query parameter naming the namespace
+	// of the resource accessed, which defaults to the metering namespace.
+	namespaceParam string
+}
+
+type authenticatedUserKey struct{}
+
+// authenticatedUser returns the user which made the request, if the request
+// was authenticated.
+func authenticatedUser(ctx context.Context) (authenticationv1.UserInfo, bool) {
+	user, ok := ctx.Value(authenticatedUserKey{}).(authenticationv1.UserInfo)
+	return user, ok
+}
+
+// apiAuth authenticates and authorizes HTTP API requests, caching the
+// results of the reviews it creates.
+type apiAuth struct {
+	tokenReviews  authenticationv1client.TokenReviewInterface
+	accessReviews authorizationv1client.SubjectAccessReviewInterface
+	namespace     string
+	cacheTTL      time.Duration
+	clock         clock.Clock
+	// rowLevelSecurity restricts the rows of report results returned to
+	// the namespaces the user can access.
+	rowLevelSecurity RowLevelSecurityConfig
+
+	mu sync.Mutex
+	// users and decisions are keyed by the hash of the token, so tokens
+	// aren't held in memory.
+	users     map[string]cachedUser
+	decisions map[string]cachedDecision
+}
+
+type cachedUser struct {
+	user    authenticationv1.UserInfo
+	expires time.Time
+}
+
+type cachedDecision struct {
+	allowed bool
+	reason  string
+	expires time.Time
+}
+
+func newAPIAuth(tokenReviews authenticationv1client.TokenReviewInterface, accessReviews authorizationv1client.SubjectAccessReviewInterface, namespace string, cacheTTL time.Duration, clock clock.Clock) *apiAuth {
+	return &apiAuth{
+		tokenReviews:  tokenReviews,
+		accessReviews: accessReviews,
+		namespace:     namespace,
+		cacheTTL:      cacheTTL,
+		clock:         clock,
+		users:         make(map[string]cachedUser),
+		decisions:     make(map[string]cachedDecision),
+	}
+}
+
+// handler wraps the handler of a route, rejecting requests without a valid
+// bearer token, or whose user doesn't have the access the route requires.
+func (auth *apiAuth) handler(srv *server, access routeAccess, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := newRequestLogger(srv.logger, r, srv.rand)
+		token := bearerToken(r)
+		if token == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeErrorResponse(logger, w, r, http.StatusUnauthorized, "a bearer token is required")
+			return
+		}
+		tokenHash := hashToken(token)
+		user, authenticated, err := auth.authenticate(logger, token, tokenHash)
+		if err != nil {
+			writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to authenticate the request: %v", err)
+			return
+		}
+		if !authenticated {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeErrorResponse(logger, w, r, http.StatusUnauthorized, "the bearer token is invalid")
+			return
+		}
+		logger = logger.WithField("user", user.Username)
+		setAuditUser(r, user.Username)
+
+		spec := auth.accessReviewSpec(access, user, r)
+		allowed, reason, err := auth.authorize(spec, tokenHash)
+		if err != nil {
+			writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to authorize the request: %v", err)
+			return
+		}
+		if !allowed {
+			logger.WithField("reason", reason).Debugf("request denied")
+			writeErrorResponse(logger, w, r, http.StatusForbidden, "user %s cannot %s", user.Username, describeAccess(spec))
+			return
+		}
+		handler(w, r.WithContext(context.WithValue(r.Context(), authenticatedUserKey{}, user)))
+	}
+}
+
+func bearerToken(r *http.Request) string {
+	return parseBearerToken(r.Header.Get("Authorization"))
+}
+
+// parseBearerToken returns the token of an Authorization header, or an
+// empty string if it isn't a bearer token.
+func parseBearerToken(header string) string {
+	const prefix = "bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return ""
+	}
+	return strings.TrimSpace(header[len(prefix):])
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// authenticate returns the user the token belongs to, using the TokenReview
+// API. An error is only returned if the token couldn't be reviewed.
+func (auth *apiAuth) authenticate(logger log.FieldLogger, token, tokenHash string) (authenticationv1.UserInfo, bool, error) {
+	now := auth.clock.Now()
+	auth.mu.Lock()
+	cached, ok := auth.users[tokenHash]
+	auth.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.user, true, nil
+	}
+
+	review, err := auth.tokenReviews.Create(&authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	})
+	if err != nil {
+		return authenticationv1.UserInfo{}, false, err
+	}
+	// the authenticators set Status.Error for tokens they reject, such as
+	// expired or malformed tokens, so it's treated as an invalid token.
+	if review.Status.Error != "" {
+		logger.Debugf("token review failed: %s", review.Status.Error)
+		return authenticationv1.UserInfo{}, false, nil
+	}
+	if !review.Status.Authenticated {
+		return authenticationv1.UserInfo{}, false, nil
+	}
+
+	auth.mu.Lock()
+	defer auth.mu.Unlock()
+	auth.pruneLocked(now)
+	auth.users[tokenHash] = cachedUser{user: review.Status.User, expires: now.Add(auth.cacheTTL)}
+	return review.Status.User, true, nil
+}
+
+// userAccessReviewSpec returns a SubjectAccessReviewSpec for the user,
+// without the attributes of the access reviewed.
+func userAccessReviewSpec(user authenticationv1.UserInfo) authorizationv1.SubjectAccessReviewSpec {
+	spec := authorizationv1.SubjectAccessReviewSpec{
+		User:   user.Username,
+		UID:    user.UID,
+		Groups: user.Groups,
+	}
+	if len(user.Extra) != 0 {
+		spec.Extra = make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+		for k, v := range user.Extra {
+			spec.Extra[k] = authorizationv1.ExtraValue(v)
+		}
+	}
+	return spec
+}
+
+func (auth *apiAuth) accessReviewSpec(access routeAccess, user authenticationv1.UserInfo, r *http.Request) authorizationv1.SubjectAccessReviewSpec {
+	spec := userAccessReviewSpec(user)
+	if access.verb == "" {
+		spec.NonResourceAttributes = &authorizationv1.NonResourceAttributes{
+			Path: r.URL.Path,
+			Verb: strings.ToLower(r.Method),
+		}
+		return spec
+	}
+	resource, namespace, name := access.target(r, auth.namespace)
+	spec.ResourceAttributes = &authorizationv1.ResourceAttributes{
+		Namespace: namespace,
+		Verb:      access.verb,
+		Group:     cbTypes.GroupName,
+		Resource:  resource,
+		Name:      name,
+	}
+	return spec
+}
+
+// target returns the resource, namespace and name of the resource the
+// request accesses. The namespace is defaultNamespace unless the request
+// names another.
+func (access routeAccess) target(r *http.Request, defaultNamespace string) (resource, namespace, name string) {
+	resource = access.resource
+	if access.resourceParam != "" {
+		resource = chi.URLParam(r, access.resourceParam)
+	}
+	if access.nameParam != "" {
+		name = chi.URLParam(r, access.nameParam)
+		if name == "" {
+			name = r.URL.Query().Get(access.nameParam)
+		}
+	}
+	namespace = defaultNamespace
+	if access.namespaceParam != "" {
+		if ns := r.URL.Query().Get(access.namespaceParam); ns != "" {
+			namespace = ns
+		}
+	}
+	return resource, namespace, name
+}
+
+// authorize returns whether the SubjectAccessReview described by spec is
+// allowed.
+func (auth *apiAuth) authorize(spec authorizationv1.SubjectAccessReviewSpec, tokenHash string) (bool, string, error) {
+	key := tokenHash + "/" + describeAccess(spec)
+	now := auth.clock.Now()
+	auth.mu.Lock()
+	cached, ok := auth.decisions[key]
+	auth.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.allowed, cached.reason, nil
+	}
+
+	review, err := auth.accessReviews.Create(&authorizationv1.SubjectAccessReview{Spec: spec})
+	if err != nil {
+		return false, "", err
+	}
+	if review.Status.EvaluationError != "" && !review.Status.Allowed {
+		return false, "", fmt.Errorf("%s", review.Status.EvaluationError)
+	}
+
+	auth.mu.Lock()
+	defer auth.mu.Unlock()
+	auth.pruneLocked(now)
+	auth.decisions[key] = cachedDecision{allowed: review.Status.Allowed, reason: review.Status.Reason, expires: now.Add(auth.cacheTTL)}
+	return review.Status.Allowed, review.Status.Reason, nil
+}
+
+// pruneLocked removes expired cache entries. It must be called with mu
+// held.
+func (auth *apiAuth) pruneLocked(now time.Time) {
+	for k, cached := range auth.users {
+		if !now.Before(cached.expires) {
+			delete(auth.users, k)
+		}
+	}
+	for k, cached := range auth.decisions {
+		if !now.Before(cached.expires) {
+			delete(auth.decisions, k)
+		}
+	}
+}
+
+// describeAccess describes the access reviewed by spec, eg: "get
+// reports.metering.openshift.io/my-report in namespace metering".
+func describeAccess(spec authorizationv1.SubjectAccessReviewSpec) string {
+	if attrs := spec.NonResourceAttributes; attrs != nil {
+		return fmt.Sprintf("%s %s", attrs.Verb, attrs.Path)
+	}
+	attrs := spec.ResourceAttributes
+	resource := attrs.Resource
+	if attrs.Group != "" {
+		resource += "." + attrs.Group
+	}
+	if attrs.Name != "" {
+		resource += "/" + attrs.Name
+	}
+	return fmt.Sprintf("%s %s in namespace %s", attrs.Verb, resource, attrs.Namespace)
+}
@@ -0,0 +1,182 @@
+package operator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+type fakeTokenReviews struct {
+	users map[string]authenticationv1.UserInfo
+	// errors maps tokens to the error their review's status is set to.
+	errors  map[string]string
+	reviews int
+}
+
+func (f *fakeTokenReviews) Create(review *authenticationv1.TokenReview) (*authenticationv1.TokenReview, error) {
+	f.reviews++
+	if reviewErr, ok := f.errors[review.Spec.Token]; ok {
+		review.Status = authenticationv1.TokenReviewStatus{Error: reviewErr}
+		return review, nil
+	}
+	user, ok := f.users[review.Spec.Token]
+	review.Status = authenticationv1.TokenReviewStatus{Authenticated: ok, User: user}
+	return review, nil
+}
+
+type fakeAccessReviews struct {
+	// allowed maps users to the access they're allowed, as described by
+	// describeAccess.
+	allowed map[string][]string
+	specs   []authorizationv1.SubjectAccessReviewSpec
+}
+
+func (f *fakeAccessReviews) Create(review *authorizationv1.SubjectAccessReview) (*authorizationv1.SubjectAccessReview, error) {
+	f.specs = append(f.specs, review.Spec)
+	for _, access := range f.allowed[review.Spec.User] {
+		if access == describeAccess(review.Spec) {
+			review.Status.Allowed = true
+		}
+	}
+	return review, nil
+}
+
+func TestAPIAuth(t *testing.T) {
+	tests := map[string]struct {
+		path               string
+		token              string
+		expectedStatusCode int
+		expectedAccess     string
+	}{
+		"no token": {
+			path:               APIV1ReportRunsEndpoint,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		"invalid token": {
+			path:               APIV1ReportRunsEndpoint,
+			token:              "invalid",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		"token review error": {
+			path:               APIV1ReportRunsEndpoint,
+			token:              "expired-token",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		"allowed": {
+			path:               APIV1ReportRunsEndpoint,
+			token:              "alice-token",
+			expectedStatusCode: http.StatusOK,
+			expectedAccess:     "list reports.metering.openshift.io in namespace metering",
+		},
+		"denied": {
+			path:               APIV1ReportRunsEndpoint,
+			token:              "bob-token",
+			expectedStatusCode: http.StatusForbidden,
+			expectedAccess:     "list reports.metering.openshift.io in namespace metering",
+		},
+		"named report denied": {
+			path:               APIV2Reports + "/payroll/full?format=json",
+			token:              "alice-token",
+			expectedStatusCode: http.StatusForbidden,
+			expectedAccess:     "get reports.metering.openshift.io/payroll in namespace metering",
+		},
+		"tenant report": {
+			path:               APIV1ReportsGetEndpoint + "?format=json&name=payroll&namespace=team-a",
+			token:              "alice-token",
+			expectedStatusCode: http.StatusForbidden,
+			expectedAccess:     "get reports.metering.openshift.io/payroll in namespace team-a",
+		},
+		"non-resource url": {
+			path:               OpenAPISpecEndpoint,
+			token:              "bob-token",
+			expectedStatusCode: http.StatusOK,
+			expectedAccess:     "get /openapi.json",
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			tokenReviews := &fakeTokenReviews{users: map[string]authenticationv1.UserInfo{
+				"alice-token": {Username: "alice"},
+				"bob-token":   {Username: "bob"},
+			}, errors: map[string]string{
+				"expired-token": "[invalid bearer token, Token has expired.]",
+			}}
+			accessReviews := &fakeAccessReviews{allowed: map[string][]string{
+				"alice": {"list reports.metering.openshift.io in namespace metering"},
+				"bob":   {"get /openapi.json"},
+			}}
+			auth := newAPIAuth(tokenReviews, accessReviews, "metering", time.Minute, clock.NewFakeClock(time.Now()))
+			router := newRouter(testLogger, routerConfig{
+				rand:          testRand,
+				collectorFunc: noopPrometheusImporterFunc,
+				auth:          auth,
+			})
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code, "unexpected status code, body: %s", w.Body.String())
+			if tt.expectedAccess == "" {
+				assert.Empty(t, accessReviews.specs, "expected unauthenticated requests not to be authorized")
+				return
+			}
+			require.Len(t, accessReviews.specs, 1)
+			assert.Equal(t, tt.expectedAccess, describeAccess(accessReviews.specs[0]))
+		})
+	}
+}
+
+func TestAPIAuthCache(t *testing.T) {
+	tokenReviews := &fakeTokenReviews{users: map[string]authenticationv1.UserInfo{"alice-token": {Username: "alice"}}}
+	accessReviews := &fakeAccessReviews{allowed: map[string][]string{"alice": {"list reports.metering.openshift.io in namespace metering"}}}
+	fakeClock := clock.NewFakeClock(time.Now())
+	auth := newAPIAuth(tokenReviews, accessReviews, "metering", time.Minute, fakeClock)
+	router := newRouter(testLogger, routerConfig{
+		rand:          testRand,
+		collectorFunc: noopPrometheusImporterFunc,
+		auth:          auth,
+	})
+
+	get := func() int {
+		req := httptest.NewRequest("GET", APIV1ReportRunsEndpoint, nil)
+		req.Header.Set("Authorization", "Bearer alice-token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusOK, get())
+	assert.Equal(t, http.StatusOK, get())
+	assert.Equal(t, 1, tokenReviews.reviews, "expected the token review to be cached")
+	assert.Len(t, accessReviews.specs, 1, "expected the access review to be cached")
+
+	fakeClock.Step(time.Minute)
+	assert.Equal(t, http.StatusOK, get())
+	assert.Equal(t, 2, tokenReviews.reviews, "expected the token to be reviewed again once the cache expires")
+	assert.Len(t, accessReviews.specs, 2, "expected the access to be reviewed again once the cache expires")
+}
+
+func TestQueryRoleForAuthenticatedUser(t *testing.T) {
+	cfg := QueryConfig{Roles: []QueryRole{
+		{Name: "analysts", Users: []string{"alice"}, MaxRows: 10, Timeout: meta.Duration{Duration: time.Minute}},
+	}}
+	req := httptest.NewRequest("POST", APIV1QueryEndpoint, nil)
+	req.Header.Set(forwardedUserHeader, "alice")
+	assert.Equal(t, DefaultQueryRoleName, cfg.roleFor(req).Name, "expected the forwarded user not to be trusted")
+	req = req.WithContext(context.WithValue(req.Context(), authenticatedUserKey{}, authenticationv1.UserInfo{Username: "alice"}))
+	assert.Equal(t, "analysts", cfg.roleFor(req).Name, "expected the authenticated user's role to be used")
+}
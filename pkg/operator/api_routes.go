@@ -0,0 +1,877 @@
+package operator
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/operator-framework/operator-metering/pkg/openapi"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+const OpenAPISpecEndpoint = "/openapi.json"
+
+// apiRoute is an endpoint of the HTTP API and its OpenAPI operation. The
+// router only serves the routes in apiRoutes, so every endpoint is
+// described by the specification served at OpenAPISpecEndpoint.
+type apiRoute struct {
+	method    string
+	path      string
+	operation openapi.Operation
+	handler   func(*server, http.ResponseWriter, *http.Request)
+	// write routes write to Presto or run reports, and are rejected when
+	// the server is read-only.
+	write bool
+	// enabled, if set, returns whether the route is served.
+	enabled func(*server) bool
+	// access is the access callers must have to use the route, when API
+	// authentication is enabled.
+	access routeAccess
+	// unfilteredResults routes return rows which row-level security can't
+	// restrict to namespaces, and are rejected for the users it restricts.
+	unfilteredResults bool
+}
+
+// reportResultsFormats are the formats the get endpoints return results in.
+var reportResultsFormats = []string{"json", "csv", "tab", "tabular", "parquet", "xlsx"}
+
+var (
+	apiComponents openapi.Components
+
+	errorResponseSchema    = apiComponents.AddSchema("ErrorResponse", errorResponse{})
+	getReportResultsSchema = apiComponents.AddSchema("GetReportResults", GetReportResults{})
+	prometheusMetricSchema = apiComponents.AddSchema("PrometheusMetric", prestostore.PrometheusMetric{})
+	collectRequestSchema   = apiComponents.AddSchema("CollectPromsumDataRequest", CollectPromsumDataRequest{})
+	ingestResponseSchema   = apiComponents.AddSchema("IngestPromsumDataResponse", IngestPromsumDataResponse{})
+	dataStoreRequestSchema = apiComponents.AddSchema("DataStoreTransferRequest", DataStoreTransferRequest{})
+	dataStoreSchema        = apiComponents.AddSchema("DataStoreTransfer", DataStoreTransfer{})
+	recommendationsSchema  = apiComponents.AddSchema("ImporterRecommendationsResponse", ImporterRecommendationsResponse{})
+	usageAnomaliesSchema   = apiComponents.AddSchema("UsageAnomaliesResponse", UsageAnomaliesResponse{})
+	costForecastSchema     = apiComponents.AddSchema("CostForecastResponse", CostForecastResponse{})
+	reportVersionsSchema   = apiComponents.AddSchema("ReportVersionList", ReportVersionList{})
+	reportLineageSchema    = apiComponents.AddSchema("ReportLineageList", ReportLineageList{})
+	deletionImpactSchema   = apiComponents.AddSchema("DeletionImpact", DeletionImpact{})
+	faultsRequestSchema    = apiComponents.AddSchema("FaultsRequest", FaultsRequest{})
+	faultsResponseSchema   = apiComponents.AddSchema("FaultsResponse", FaultsResponse{})
+	emptyResponseSchema    = apiComponents.AddSchema("EmptyResponse", struct{}{})
+	reportRunRequestSchema = apiComponents.AddSchema("ReportRunRequest", ReportRunRequest{})
+	reportRunStatusSchema  = apiComponents.AddSchema("ReportRunStatus", ReportRunStatus{})
+	reportRunListSchema    = apiComponents.AddSchema("ReportRunList", ReportRunList{})
+	queryRequestSchema     = apiComponents.AddSchema("QueryRequest", QueryRequest{})
+	queryEstimateSchema    = apiComponents.AddSchema("QueryEstimate", QueryEstimate{})
+)
+
+var (
+	nameParam = openapi.Parameter{
+		Name:        "name",
+		In:          openapi.InQuery,
+		Description: "The name of the report.",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	reportNamePathParam = openapi.Parameter{
+		Name:        "name",
+		In:          openapi.InPath,
+		Description: "The name of the report.",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	namespaceParam = openapi.Parameter{
+		Name:        "namespace",
+		In:          openapi.InQuery,
+		Description: "The namespace of the report, defaulting to the metering namespace. Requires tenant namespaces to be enabled.",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	dataSourceNamePathParam = openapi.Parameter{
+		Name:        "datasourceName",
+		In:          openapi.InPath,
+		Description: "The name of the ReportDataSource.",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	reportRunIDPathParam = openapi.Parameter{
+		Name:        "id",
+		In:          openapi.InPath,
+		Description: "The ID of the report run.",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	resultsFormatParam = openapi.Parameter{
+		Name:     "format",
+		In:       openapi.InQuery,
+		Required: true,
+		Schema:   openapi.StringEnum("", reportResultsFormats...),
+	}
+	streamFormatParam = openapi.Parameter{
+		Name:     "format",
+		In:       openapi.InQuery,
+		Required: true,
+		Schema:   openapi.StringEnum("", "json", "csv"),
+	}
+	renderFormatParam = openapi.Parameter{
+		Name:     "format",
+		In:       openapi.InQuery,
+		Required: true,
+		Schema:   openapi.StringEnum("", "html", "pdf"),
+	}
+	unitsParam = openapi.Parameter{
+		Name:        "units",
+		In:          openapi.InQuery,
+		Description: "Add the unit of each column to the header row of csv, tabular and xlsx results, eg: pod_request_cpu_core_seconds (cpu_core_seconds).",
+		Schema:      &openapi.Schema{Type: "boolean"},
+	}
+	localeParam = openapi.Parameter{
+		Name:        "locale",
+		In:          openapi.InQuery,
+		Description: "The locale to format numbers and timestamps in csv and tabular results for. Locales using decimal commas delimit csv results with semicolons.",
+		Schema:      openapi.StringEnum("", "en-US", "en-GB", "de-DE", "fr-FR", "es-ES", "it-IT", "nl-NL"),
+	}
+	decimalSeparatorParam = openapi.Parameter{
+		Name:        "decimal_separator",
+		In:          openapi.InQuery,
+		Description: "The decimal separator of numbers in csv and tabular results, overriding the locale's. A comma delimits csv results with semicolons.",
+		Schema:      openapi.StringEnum("", ".", ","),
+	}
+	dateFormatParam = openapi.Parameter{
+		Name:        "date_format",
+		In:          openapi.InQuery,
+		Description: "The format of timestamps in csv and tabular results, overriding the locale's, made of YYYY, MM, DD, hh, mm and ss separated by spaces or one of . - / : T, eg: DD.MM.YYYY hh:mm:ss.",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	templateParam = openapi.Parameter{
+		Name:        "template",
+		In:          openapi.InQuery,
+		Description: "The name of the ReportTemplate to render the results with.",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	columnsParam = openapi.Parameter{
+		Name:        "columns",
+		In:          openapi.InQuery,
+		Description: "The columns to return, defaulting to every column.",
+		Schema:      openapi.ArrayOf(&openapi.Schema{Type: "string"}),
+		Explode:     newFalse(),
+	}
+	filterParam = openapi.Parameter{
+		Name:        "filter",
+		In:          openapi.InQuery,
+		Description: "A filter expression in the form <column><operator><value>. Only rows matching every filter are returned.",
+		Schema:      openapi.ArrayOf(&openapi.Schema{Type: "string"}),
+	}
+	limitParam = openapi.Parameter{
+		Name:        "limit",
+		In:          openapi.InQuery,
+		Description: "The most rows to return. If there are more rows, the cursor of the next page is returned in the X-Next-Cursor header.",
+		Schema:      &openapi.Schema{Type: "integer", Format: "int32"},
+	}
+	cursorParam = openapi.Parameter{
+		Name:        "cursor",
+		In:          openapi.InQuery,
+		Description: "The cursor of the page to return, from the X-Next-Cursor header of the previous page. Requires limit.",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	versionParam = openapi.Parameter{
+		Name:        "version",
+		In:          openapi.InQuery,
+		Description: "The ID of a version of a versioned report to return the results of, instead of its current results.",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	lineageRunParam = openapi.Parameter{
+		Name:        "run",
+		In:          openapi.InQuery,
+		Description: "The ID of a run to return the lineage of, instead of the lineage of every run.",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	amendmentParam = openapi.Parameter{
+		Name:        "amendment",
+		In:          openapi.InQuery,
+		Description: "The ID of an amendment to return the changed rows of, instead of the changed rows of every amendment.",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	ignoreFailedParam = openapi.Parameter{
+		Name:        "ignore_failed",
+		In:          openapi.InQuery,
+		Description: "Return the results even if the most recent run of the ScheduledReport failed.",
+		Schema:      &openapi.Schema{Type: "boolean"},
+	}
+	forecastColumnParam = openapi.Parameter{
+		Name:        "column",
+		In:          openapi.InQuery,
+		Description: "The numeric column of the results containing the cost, such as total_cost.",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	forecastGroupByParam = openapi.Parameter{
+		Name:        "groupBy",
+		In:          openapi.InQuery,
+		Description: "The column of the results to forecast the cost of each value of, such as namespace. If unset, only the total is forecast.",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	forecastMethodParam = openapi.Parameter{
+		Name:        "method",
+		In:          openapi.InQuery,
+		Description: "How the cost of the remaining days is projected: linear extrapolates the trend of the daily cost, and seasonal uses the mean cost of the same day of the week. Defaults to linear.",
+		Schema:      openapi.StringEnum("", ForecastMethodLinear, ForecastMethodSeasonal),
+	}
+	startParam = openapi.Parameter{
+		Name:   "start",
+		In:     openapi.InQuery,
+		Schema: &openapi.Schema{Type: "string", Format: "date-time"},
+	}
+	endParam = openapi.Parameter{
+		Name:   "end",
+		In:     openapi.InQuery,
+		Schema: &openapi.Schema{Type: "string", Format: "date-time"},
+	}
+)
+
+func newFalse() *bool {
+	b := false
+	return &b
+}
+
+func jsonContent(schema *openapi.Schema) map[string]openapi.MediaType {
+	return map[string]openapi.MediaType{"application/json": {Schema: schema}}
+}
+
+func jsonResponse(description string, schema *openapi.Schema) *openapi.Response {
+	return &openapi.Response{Description: description, Content: jsonContent(schema)}
+}
+
+// withErrorResponses adds an error response for each of the status codes to
+// the responses.
+func withErrorResponses(responses map[string]*openapi.Response, codes ...string) map[string]*openapi.Response {
+	for _, code := range codes {
+		var description string
+		switch code {
+		case "202":
+			description = "The report is still running."
+		case "400":
+			description = "The request is invalid."
+		case "403":
+			description = "The reporting-operator is read-only."
+		case "404":
+			description = "The resource doesn't exist."
+		default:
+			description = "An error occurred."
+		}
+		responses[code] = jsonResponse(description, errorResponseSchema)
+	}
+	return responses
+}
+
+var binaryResultsSchema = &openapi.Schema{Type: "string", Format: "binary"}
+
+// reportResultsResponse is the response of the get endpoints, in each of
+// the results formats.
+func reportResultsResponse(jsonSchema *openapi.Schema) *openapi.Response {
+	return &openapi.Response{
+		Description: "The results of the report.",
+		Headers: map[string]openapi.Header{
+			nextCursorHeader: {Description: "The cursor of the next page, if the results are paginated and there are more rows.", Schema: &openapi.Schema{Type: "string"}},
+		},
+		Content: map[string]openapi.MediaType{
+			"application/json":         {Schema: jsonSchema},
+			"text/csv":                 {Schema: &openapi.Schema{Type: "string"}},
+			"text/plain":               {Schema: &openapi.Schema{Type: "string"}},
+			"application/octet-stream": {Schema: binaryResultsSchema},
+			"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": {Schema: binaryResultsSchema},
+		},
+	}
+}
+
+var (
+	resultRowsSchema = openapi.ArrayOf(&openapi.Schema{Type: "object", AdditionalProperties: &openapi.Schema{}})
+
+	streamedResultsResponse = &openapi.Response{
+		Description: "The results of the report, streamed as they're read from Presto.",
+		Content: map[string]openapi.MediaType{
+			"application/json": {Schema: resultRowsSchema},
+			"text/csv":         {Schema: &openapi.Schema{Type: "string"}},
+		},
+	}
+	renderedResultsResponse = &openapi.Response{
+		Description: "The results of the report rendered with the ReportTemplate.",
+		Content: map[string]openapi.MediaType{
+			"text/html":       {Schema: &openapi.Schema{Type: "string"}},
+			"application/pdf": {Schema: binaryResultsSchema},
+		},
+	}
+)
+
+var apiRoutes = []apiRoute{
+	{
+		method: "GET",
+		path:   APIV1ReportsGetEndpoint,
+		operation: openapi.Operation{
+			OperationID: "getReport",
+			Summary:     "Get the results of a finished Report.",
+			Tags:        []string{"reports"},
+			Parameters:  []openapi.Parameter{nameParam, namespaceParam, resultsFormatParam, unitsParam, localeParam, decimalSeparatorParam, dateFormatParam, columnsParam, filterParam, limitParam, cursorParam, versionParam},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": reportResultsResponse(resultRowsSchema)}, "202", "400", "404", "500"),
+		},
+		handler: (*server).getReportHandler,
+		access:  routeAccess{verb: "get", resource: "reports", nameParam: "name", namespaceParam: "namespace"},
+	},
+	{
+		method: "GET",
+		path:   APIV1ScheduledReportsGetEndpoint,
+		operation: openapi.Operation{
+			OperationID: "getScheduledReport",
+			Summary:     "Get the results of every run of a ScheduledReport.",
+			Tags:        []string{"scheduledreports"},
+			Parameters:  []openapi.Parameter{nameParam, namespaceParam, resultsFormatParam, unitsParam, localeParam, decimalSeparatorParam, dateFormatParam, ignoreFailedParam, columnsParam, filterParam, limitParam, cursorParam, versionParam},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": reportResultsResponse(resultRowsSchema)}, "400", "404", "500"),
+		},
+		handler: (*server).getScheduledReportHandler,
+		access:  routeAccess{verb: "get", resource: "scheduledreports", nameParam: "name", namespaceParam: "namespace"},
+	},
+	{
+		method: "GET",
+		path:   APIV1ReportsStreamEndpoint,
+		operation: openapi.Operation{
+			OperationID: "streamReport",
+			Summary:     "Stream the results of a finished Report.",
+			Tags:        []string{"reports"},
+			Parameters:  []openapi.Parameter{nameParam, namespaceParam, streamFormatParam, unitsParam, localeParam, decimalSeparatorParam, dateFormatParam, columnsParam, filterParam},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": streamedResultsResponse}, "202", "400", "404", "500"),
+		},
+		handler: (*server).streamReportHandler,
+		access:  routeAccess{verb: "get", resource: "reports", nameParam: "name", namespaceParam: "namespace"},
+	},
+	{
+		method: "GET",
+		path:   APIV1ScheduledReportsStreamEndpoint,
+		operation: openapi.Operation{
+			OperationID: "streamScheduledReport",
+			Summary:     "Stream the results of every run of a ScheduledReport.",
+			Tags:        []string{"scheduledreports"},
+			Parameters:  []openapi.Parameter{nameParam, namespaceParam, streamFormatParam, unitsParam, localeParam, decimalSeparatorParam, dateFormatParam, ignoreFailedParam, columnsParam, filterParam},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": streamedResultsResponse}, "400", "404", "500"),
+		},
+		handler: (*server).streamScheduledReportHandler,
+		access:  routeAccess{verb: "get", resource: "scheduledreports", nameParam: "name", namespaceParam: "namespace"},
+	},
+	{
+		method: "GET",
+		path:   APIV1ReportsRenderEndpoint,
+		operation: openapi.Operation{
+			OperationID: "renderReport",
+			Summary:     "Render the results of a finished Report with a ReportTemplate.",
+			Tags:        []string{"reports"},
+			Parameters:  []openapi.Parameter{nameParam, namespaceParam, templateParam, renderFormatParam, columnsParam, filterParam},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": renderedResultsResponse}, "202", "400", "404", "500"),
+		},
+		handler: (*server).renderReportHandler,
+		access:  routeAccess{verb: "get", resource: "reports", nameParam: "name", namespaceParam: "namespace"},
+	},
+	{
+		method: "GET",
+		path:   APIV1ScheduledReportsRenderEndpoint,
+		operation: openapi.Operation{
+			OperationID: "renderScheduledReport",
+			Summary:     "Render the results of a ScheduledReport with a ReportTemplate.",
+			Tags:        []string{"scheduledreports"},
+			Parameters:  []openapi.Parameter{nameParam, namespaceParam, templateParam, renderFormatParam, ignoreFailedParam, columnsParam, filterParam},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": renderedResultsResponse}, "400", "404", "500"),
+		},
+		handler: (*server).renderScheduledReportHandler,
+		access:  routeAccess{verb: "get", resource: "scheduledreports", nameParam: "name", namespaceParam: "namespace"},
+	},
+	{
+		method: "GET",
+		path:   APIV1ScheduledReportsForecastEndpoint,
+		operation: openapi.Operation{
+			OperationID: "forecastScheduledReport",
+			Summary:     "Project the month-end cost of a ScheduledReport's results from the results of the month so far.",
+			Description: "The ScheduledReport must have hourly or daily periods. The month is the month of the end of its latest period, in its timezone.",
+			Tags:        []string{"scheduledreports"},
+			Parameters:  []openapi.Parameter{nameParam, namespaceParam, forecastColumnParam, forecastGroupByParam, forecastMethodParam, ignoreFailedParam, filterParam},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": jsonResponse("The forecast cost.", costForecastSchema)}, "400", "404", "500"),
+		},
+		handler: (*server).scheduledReportForecastHandler,
+		access:  routeAccess{verb: "get", resource: "scheduledreports", nameParam: "name", namespaceParam: "namespace"},
+	},
+	{
+		method: "GET",
+		path:   APIV1ReportsVersionsEndpoint,
+		operation: openapi.Operation{
+			OperationID: "listReportVersions",
+			Summary:     "List the versions of a versioned Report's results written by each of its runs.",
+			Tags:        []string{"reports"},
+			Parameters:  []openapi.Parameter{nameParam, namespaceParam},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": jsonResponse("The versions, oldest first.", reportVersionsSchema)}, "400", "404", "500"),
+		},
+		handler: (*server).listReportVersionsHandler,
+		access:  routeAccess{verb: "get", resource: "reports", nameParam: "name", namespaceParam: "namespace"},
+	},
+	{
+		method: "GET",
+		path:   APIV1ScheduledReportsVersionsEndpoint,
+		operation: openapi.Operation{
+			OperationID: "listScheduledReportVersions",
+			Summary:     "List the versions of a versioned ScheduledReport's results written by each of its runs.",
+			Tags:        []string{"scheduledreports"},
+			Parameters:  []openapi.Parameter{nameParam, namespaceParam},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": jsonResponse("The versions, oldest first.", reportVersionsSchema)}, "400", "404", "500"),
+		},
+		handler: (*server).listScheduledReportVersionsHandler,
+		access:  routeAccess{verb: "get", resource: "scheduledreports", nameParam: "name", namespaceParam: "namespace"},
+	},
+	{
+		method: "GET",
+		path:   APIV1ReportsAmendmentsEndpoint,
+		operation: openapi.Operation{
+			OperationID: "getReportAmendments",
+			Summary:     "Get the rows of a Report's results changed by late data, with their original and amended values.",
+			Tags:        []string{"reports"},
+			Parameters:  []openapi.Parameter{nameParam, namespaceParam, resultsFormatParam, unitsParam, localeParam, decimalSeparatorParam, dateFormatParam, amendmentParam, columnsParam, filterParam, limitParam, cursorParam},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": reportResultsResponse(resultRowsSchema)}, "400", "404", "500"),
+		},
+		handler: (*server).getReportAmendmentsHandler,
+		access:  routeAccess{verb: "get", resource: "reports", nameParam: "name", namespaceParam: "namespace"},
+	},
+	{
+		method: "GET",
+		path:   APIV1ScheduledReportsAmendmentsEndpoint,
+		operation: openapi.Operation{
+			OperationID: "getScheduledReportAmendments",
+			Summary:     "Get the rows of a ScheduledReport's results changed by late data, with their original and amended values.",
+			Tags:        []string{"scheduledreports"},
+			Parameters:  []openapi.Parameter{nameParam, namespaceParam, resultsFormatParam, unitsParam, localeParam, decimalSeparatorParam, dateFormatParam, amendmentParam, columnsParam, filterParam, limitParam, cursorParam},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": reportResultsResponse(resultRowsSchema)}, "400", "404", "500"),
+		},
+		handler: (*server).getScheduledReportAmendmentsHandler,
+		access:  routeAccess{verb: "get", resource: "scheduledreports", nameParam: "name", namespaceParam: "namespace"},
+	},
+	{
+		method: "GET",
+		path:   APIV1ReportsLineageEndpoint,
+		operation: openapi.Operation{
+			OperationID: "listReportLineage",
+			Summary:     "List the ReportDataSources, Prometheus queries, partitions, imports and reports which fed each run of a Report with lineage enabled.",
+			Tags:        []string{"reports"},
+			Parameters:  []openapi.Parameter{nameParam, namespaceParam, lineageRunParam},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": jsonResponse("The lineage of each run, oldest first.", reportLineageSchema)}, "400", "404", "500"),
+		},
+		handler: (*server).listReportLineageHandler,
+		access:  routeAccess{verb: "get", resource: "reports", nameParam: "name", namespaceParam: "namespace"},
+	},
+	{
+		method: "GET",
+		path:   APIV1ScheduledReportsLineageEndpoint,
+		operation: openapi.Operation{
+			OperationID: "listScheduledReportLineage",
+			Summary:     "List the ReportDataSources, Prometheus queries, partitions, imports and reports which fed each run of a ScheduledReport with lineage enabled.",
+			Tags:        []string{"scheduledreports"},
+			Parameters:  []openapi.Parameter{nameParam, namespaceParam, lineageRunParam},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": jsonResponse("The lineage of each run, oldest first.", reportLineageSchema)}, "400", "404", "500"),
+		},
+		handler: (*server).listScheduledReportLineageHandler,
+		access:  routeAccess{verb: "get", resource: "scheduledreports", nameParam: "name", namespaceParam: "namespace"},
+	},
+	{
+		method: "GET",
+		path:   APIV2Reports + "/{name}/full",
+		operation: openapi.Operation{
+			OperationID: "getReportV2Full",
+			Summary:     "Get the results of a finished Report, including hidden columns.",
+			Tags:        []string{"reports"},
+			Parameters:  []openapi.Parameter{reportNamePathParam, namespaceParam, resultsFormatParam, unitsParam, localeParam, decimalSeparatorParam, dateFormatParam, columnsParam, filterParam, limitParam, cursorParam, versionParam},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": reportResultsResponse(getReportResultsSchema)}, "202", "400", "404", "500"),
+		},
+		handler: (*server).getReportV2FullHandler,
+		access:  routeAccess{verb: "get", resource: "reports", nameParam: "name", namespaceParam: "namespace"},
+	},
+	{
+		method: "GET",
+		path:   APIV2Reports + "/{name}/table",
+		operation: openapi.Operation{
+			OperationID: "getReportV2Table",
+			Summary:     "Get the results of a finished Report, excluding columns hidden from tables.",
+			Tags:        []string{"reports"},
+			Parameters:  []openapi.Parameter{reportNamePathParam, namespaceParam, resultsFormatParam, unitsParam, localeParam, decimalSeparatorParam, dateFormatParam, columnsParam, filterParam, limitParam, cursorParam, versionParam},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": reportResultsResponse(getReportResultsSchema)}, "202", "400", "404", "500"),
+		},
+		handler: (*server).getReportV2TableHandler,
+		access:  routeAccess{verb: "get", resource: "reports", nameParam: "name", namespaceParam: "namespace"},
+	},
+	{
+		method: "POST",
+		path:   APIV1ReportRunsEndpoint,
+		operation: openapi.Operation{
+			OperationID: "createReportRun",
+			Summary:     "Start an ad-hoc run of a ReportGenerationQuery, without creating a Report.",
+			Description: "The run is started in the background, and its status is returned immediately. Poll the run's status until it's finished, then get its results.",
+			Tags:        []string{"reportruns"},
+			RequestBody: &openapi.RequestBody{Required: true, Content: jsonContent(reportRunRequestSchema)},
+			Responses: withErrorResponses(map[string]*openapi.Response{
+				"202": {
+					Description: "The run was started.",
+					Headers: map[string]openapi.Header{
+						"Location": {Description: "The URL of the run's status.", Schema: &openapi.Schema{Type: "string"}},
+					},
+					Content: jsonContent(reportRunStatusSchema),
+				},
+				"429": jsonResponse("Too many runs are already running.", errorResponseSchema),
+			}, "400", "403", "500"),
+		},
+		handler:           (*server).createReportRunHandler,
+		access:            routeAccess{verb: "create", resource: "reports"},
+		write:             true,
+		unfilteredResults: true,
+	},
+	{
+		method: "GET",
+		path:   APIV1ReportRunsEndpoint,
+		operation: openapi.Operation{
+			OperationID: "listReportRuns",
+			Summary:     "List the report runs started through this reporting-operator.",
+			Tags:        []string{"reportruns"},
+			Responses:   map[string]*openapi.Response{"200": jsonResponse("The report runs.", reportRunListSchema)},
+		},
+		handler: (*server).listReportRunsHandler,
+		access:  routeAccess{verb: "list", resource: "reports"},
+	},
+	{
+		method: "GET",
+		path:   APIV1ReportRunsEndpoint + "/{id}",
+		operation: openapi.Operation{
+			OperationID: "getReportRun",
+			Summary:     "Get the status of a report run.",
+			Tags:        []string{"reportruns"},
+			Parameters:  []openapi.Parameter{reportRunIDPathParam},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": jsonResponse("The status of the run.", reportRunStatusSchema)}, "404"),
+		},
+		handler: (*server).getReportRunHandler,
+		access:  routeAccess{verb: "get", resource: "reports"},
+	},
+	{
+		method: "DELETE",
+		path:   APIV1ReportRunsEndpoint + "/{id}",
+		operation: openapi.Operation{
+			OperationID: "deleteReportRun",
+			Summary:     "Cancel a report run if it's running, and delete it and its results.",
+			Tags:        []string{"reportruns"},
+			Parameters:  []openapi.Parameter{reportRunIDPathParam},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": jsonResponse("The status of the deleted run.", reportRunStatusSchema)}, "403", "404"),
+		},
+		handler: (*server).deleteReportRunHandler,
+		access:  routeAccess{verb: "delete", resource: "reports"},
+		write:   true,
+	},
+	{
+		method: "GET",
+		path:   APIV1ReportRunsEndpoint + "/{id}/results",
+		operation: openapi.Operation{
+			OperationID: "getReportRunResults",
+			Summary:     "Get the results of a finished report run.",
+			Tags:        []string{"reportruns"},
+			Parameters:  []openapi.Parameter{reportRunIDPathParam, resultsFormatParam, unitsParam, localeParam, decimalSeparatorParam, dateFormatParam},
+			Responses: withErrorResponses(map[string]*openapi.Response{
+				"200": reportResultsResponse(resultRowsSchema),
+				"409": jsonResponse("The run failed.", errorResponseSchema),
+			}, "202", "400", "403", "404"),
+		},
+		handler:           (*server).getReportRunResultsHandler,
+		access:            routeAccess{verb: "get", resource: "reports"},
+		unfilteredResults: true,
+	},
+	{
+		method: "POST",
+		path:   APIV1QueryEndpoint,
+		operation: openapi.Operation{
+			OperationID: "runQuery",
+			Summary:     "Run a ReportGenerationQuery and return its results, without creating a Report or storing the results.",
+			Description: "The rows returned and the time the query can take are limited by the caller's query role.",
+			Tags:        []string{"query"},
+			Parameters:  []openapi.Parameter{resultsFormatParam},
+			RequestBody: &openapi.RequestBody{Required: true, Content: jsonContent(queryRequestSchema)},
+			Responses: withErrorResponses(map[string]*openapi.Response{
+				"200": {
+					Description: "The results of the query.",
+					Headers: map[string]openapi.Header{
+						truncatedHeader: {Description: "Whether the results were truncated at the row limit.", Schema: &openapi.Schema{Type: "boolean"}},
+					},
+					Content: reportResultsResponse(resultRowsSchema).Content,
+				},
+				"504": jsonResponse("The query exceeded the timeout of the caller's query role.", errorResponseSchema),
+			}, "400", "403", "500"),
+		},
+		handler:           (*server).queryHandler,
+		access:            routeAccess{verb: "create", resource: "reports"},
+		write:             true,
+		unfilteredResults: true,
+	},
+	{
+		method: "POST",
+		path:   APIV1QueryEstimateEndpoint,
+		operation: openapi.Operation{
+			OperationID: "estimateQuery",
+			Summary:     "Estimate the cost of running a ReportGenerationQuery for a reporting period, without running it.",
+			Description: "The estimate is based on Presto's distributed query plan and the statistics of the tables the query reads. Use it to check whether a reporting period is too expensive before creating a Report or running the query.",
+			Tags:        []string{"query"},
+			RequestBody: &openapi.RequestBody{Required: true, Content: jsonContent(reportRunRequestSchema)},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": jsonResponse("The estimated cost of the query.", queryEstimateSchema)}, "400", "403", "500"),
+		},
+		handler: (*server).queryEstimateHandler,
+		access:  routeAccess{verb: "create", resource: "reports"},
+	},
+	{
+		method: "POST",
+		path:   "/api/v1/datasources/prometheus/collect",
+		operation: openapi.Operation{
+			OperationID: "collectPrometheusData",
+			Summary:     "Import metrics from Prometheus into every Prometheus metrics ReportDataSource for a time range.",
+			Tags:        []string{"datasources"},
+			RequestBody: &openapi.RequestBody{Required: true, Content: jsonContent(collectRequestSchema)},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": jsonResponse("The metrics were imported.", emptyResponseSchema)}, "403", "500"),
+		},
+		handler: (*server).collectPromsumDataHandler,
+		access:  routeAccess{verb: "update", resource: "reportdatasources"},
+		write:   true,
+	},
+	{
+		method: "POST",
+		path:   "/api/v1/datasources/prometheus/store/{datasourceName}",
+		operation: openapi.Operation{
+			OperationID: "storePrometheusData",
+			Summary:     "Store metrics in the table of a Prometheus metrics ReportDataSource.",
+			Tags:        []string{"datasources"},
+			Parameters:  []openapi.Parameter{dataSourceNamePathParam},
+			RequestBody: &openapi.RequestBody{Required: true, Content: jsonContent(openapi.ArrayOf(prometheusMetricSchema))},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": jsonResponse("The metrics were stored.", emptyResponseSchema)}, "403", "500"),
+		},
+		handler: (*server).storePromsumDataHandler,
+		access:  routeAccess{verb: "update", resource: "reportdatasources", nameParam: "datasourceName"},
+		write:   true,
+	},
+	{
+		method: "GET",
+		path:   "/api/v1/datasources/prometheus/fetch/{datasourceName}",
+		operation: openapi.Operation{
+			OperationID: "fetchPrometheusData",
+			Summary:     "Get the metrics stored in the table of a Prometheus metrics ReportDataSource.",
+			Tags:        []string{"datasources"},
+			Parameters:  []openapi.Parameter{dataSourceNamePathParam, startParam, endParam},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": jsonResponse("The metrics.", openapi.ArrayOf(prometheusMetricSchema))}, "500"),
+		},
+		handler: (*server).fetchPromsumDataHandler,
+		access:  routeAccess{verb: "get", resource: "reportdatasources", nameParam: "datasourceName"},
+	},
+	{
+		method: "POST",
+		path:   APIV1PrometheusIngestEndpoint + "/{datasourceName}",
+		operation: openapi.Operation{
+			OperationID: "ingestPrometheusData",
+			Summary:     "Ingest a batch of metrics into a Prometheus metrics ReportDataSource.",
+			Description: "The body contains a PrometheusMetric encoded as JSON on each line, and may be gzip compressed.",
+			Tags:        []string{"datasources"},
+			Parameters:  []openapi.Parameter{dataSourceNamePathParam},
+			RequestBody: &openapi.RequestBody{
+				Required: true,
+				Content:  map[string]openapi.MediaType{"application/x-ndjson": {Schema: &openapi.Schema{Type: "string"}}},
+			},
+			Responses: withErrorResponses(map[string]*openapi.Response{"200": jsonResponse("The metrics were stored.", ingestResponseSchema)}, "400", "403", "404", "409", "415", "500"),
+		},
+		handler: (*server).ingestPromsumDataHandler,
+		access:  routeAccess{verb: "update", resource: "reportdatasources", nameParam: "datasourceName"},
+		write:   true,
+	},
+	{
+		method: "POST",
+		path:   APIV1DataStoreExportEndpoint + "/{datasourceName}",
+		operation: openapi.Operation{
+			OperationID: "exportDataStore",
+			Summary:     "Export the table of a Prometheus metrics or Kubernetes objects ReportDataSource to a location in HDFS or an object store.",
+			Description: "The table's data, schema and the timestamp of its most recent row are exported using Hive's EXPORT TABLE, to a location which must not exist yet.",
+			Tags:        []string{"datasources"},
+			Parameters:  []openapi.Parameter{dataSourceNamePathParam},
+			RequestBody: &openapi.RequestBody{Required: true, Content: jsonContent(dataStoreRequestSchema)},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": jsonResponse("The table was exported.", dataStoreSchema)}, "400", "403", "404", "409", "500"),
+		},
+		handler: (*server).exportDataStoreHandler,
+		access:  routeAccess{verb: "get", resource: "reportdatasources", nameParam: "datasourceName"},
+		write:   true,
+	},
+	{
+		method: "POST",
+		path:   APIV1DataStoreImportEndpoint + "/{datasourceName}",
+		operation: openapi.Operation{
+			OperationID: "importDataStore",
+			Summary:     "Import a table exported by exportDataStore into the table of a ReportDataSource.",
+			Description: "Only the exported rows older than the earliest row already in the table are imported, so importing into a ReportDataSource which has collected data since it was created doesn't duplicate rows, and imports can be repeated.",
+			Tags:        []string{"datasources"},
+			Parameters:  []openapi.Parameter{dataSourceNamePathParam},
+			RequestBody: &openapi.RequestBody{Required: true, Content: jsonContent(dataStoreRequestSchema)},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": jsonResponse("The table was imported.", dataStoreSchema)}, "400", "403", "404", "409", "500"),
+		},
+		handler: (*server).importDataStoreHandler,
+		access:  routeAccess{verb: "update", resource: "reportdatasources", nameParam: "datasourceName"},
+		write:   true,
+	},
+	{
+		method: "GET",
+		path:   APIV1PrometheusImporterRecommendationsEndpoint,
+		operation: openapi.Operation{
+			OperationID: "getImporterRecommendations",
+			Summary:     "Get recommended changes to the Prometheus importer's configuration.",
+			Tags:        []string{"datasources"},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": jsonResponse("The recommendations.", recommendationsSchema)}, "404"),
+		},
+		handler: (*server).getImporterRecommendationsHandler,
+		access:  routeAccess{verb: "list", resource: "reportdatasources"},
+	},
+	{
+		method: "GET",
+		path:   APIV1UsageAnomaliesEndpoint,
+		operation: openapi.Operation{
+			OperationID: "getUsageAnomalies",
+			Summary:     "Get the namespaces whose usage spiked or dropped in the latest bucket checked for each Prometheus ReportDataSource.",
+			Description: "Only served when anomaly detection is enabled. With sharding, only the ReportDataSources owned by this reporting-operator's shard are included.",
+			Tags:        []string{"datasources"},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": jsonResponse("The usage anomalies.", usageAnomaliesSchema)}, "404"),
+		},
+		handler: (*server).getUsageAnomaliesHandler,
+		access:  routeAccess{verb: "list", resource: "reportdatasources"},
+	},
+	{
+		method: "GET",
+		path:   APIV1DeletionImpactEndpoint + "/{resource}/{name}",
+		operation: openapi.Operation{
+			OperationID: "getDeletionImpact",
+			Summary:     "Get the resources which would break or be removed by deleting a resource.",
+			Tags:        []string{"deletionimpact"},
+			Parameters: []openapi.Parameter{
+				{Name: "resource", In: openapi.InPath, Required: true, Schema: openapi.StringEnum("", "reportgenerationqueries", "reportdatasources")},
+				{Name: "name", In: openapi.InPath, Required: true, Schema: &openapi.Schema{Type: "string"}},
+			},
+			Responses: withErrorResponses(map[string]*openapi.Response{"200": jsonResponse("The impact of deleting the resource.", deletionImpactSchema)}, "400", "404", "500"),
+		},
+		handler: (*server).getDeletionImpactHandler,
+		access:  routeAccess{verb: "get", resourceParam: "resource", nameParam: "name"},
+	},
+	{
+		method: "GET",
+		path:   APIV1DebugFaultsEndpoint,
+		operation: openapi.Operation{
+			OperationID: "getFaults",
+			Summary:     "Get the faults injected into the Prometheus importer. Only served when fault injection is enabled.",
+			Tags:        []string{"debug"},
+			Responses:   map[string]*openapi.Response{"200": jsonResponse("The injected faults.", faultsResponseSchema)},
+		},
+		handler: (*server).faultsHandler,
+		enabled: faultInjectionEnabled,
+	},
+	{
+		method: "PUT",
+		path:   APIV1DebugFaultsEndpoint,
+		operation: openapi.Operation{
+			OperationID: "setFaults",
+			Summary:     "Set the faults injected into the Prometheus importer. Only served when fault injection is enabled.",
+			Tags:        []string{"debug"},
+			RequestBody: &openapi.RequestBody{Required: true, Content: jsonContent(faultsRequestSchema)},
+			Responses:   withErrorResponses(map[string]*openapi.Response{"200": jsonResponse("The injected faults.", faultsResponseSchema)}, "400"),
+		},
+		handler: (*server).faultsHandler,
+		enabled: faultInjectionEnabled,
+	},
+	{
+		method: "DELETE",
+		path:   APIV1DebugFaultsEndpoint,
+		operation: openapi.Operation{
+			OperationID: "deleteFaults",
+			Summary:     "Stop injecting faults into the Prometheus importer. Only served when fault injection is enabled.",
+			Tags:        []string{"debug"},
+			Responses:   map[string]*openapi.Response{"200": jsonResponse("The injected faults.", faultsResponseSchema)},
+		},
+		handler: (*server).faultsHandler,
+		enabled: faultInjectionEnabled,
+	},
+}
+
+func init() {
+	// The specification's own route is added here, as it refers to
+	// apiRoutes.
+	apiRoutes = append(apiRoutes, apiRoute{
+		method: "GET",
+		path:   OpenAPISpecEndpoint,
+		operation: openapi.Operation{
+			OperationID: "getOpenAPISpec",
+			Summary:     "Get the OpenAPI specification of the API.",
+			Tags:        []string{"openapi"},
+			Responses:   map[string]*openapi.Response{"200": jsonResponse("The OpenAPI specification.", &openapi.Schema{Type: "object"})},
+		},
+		handler: (*server).openAPISpecHandler,
+	})
+}
+
+func faultInjectionEnabled(srv *server) bool {
+	return srv.faultInjector != nil
+}
+
+// handlerFunc returns the handler serving the route.
+func (route apiRoute) handlerFunc(srv *server) http.HandlerFunc {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		route.handler(srv, w, r)
+	}
+	if route.write {
+		handler = srv.writeHandler(handler)
+	}
+	if srv.auth != nil {
+		if route.unfilteredResults {
+			handler = srv.auth.unrestrictedUserHandler(srv, handler)
+		}
+		handler = srv.auth.handler(srv, route.access, handler)
+	}
+	if srv.audit != nil {
+		handler = srv.audit.handler(srv, route, handler)
+	}
+	return handler
+}
+
+// NewOpenAPIDocument returns the OpenAPI specification of the reporting
+// API, describing every route in apiRoutes.
+func NewOpenAPIDocument() *openapi.Document {
+	components := apiComponents
+	components.SecuritySchemes = map[string]*openapi.SecurityScheme{
+		"bearerAuth": {
+			Type:        "http",
+			Scheme:      "bearer",
+			Description: "A Kubernetes bearer token, required when API authentication is enabled.",
+		},
+	}
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:       "Metering Reporting API",
+			Description: "The reporting-operator's API for retrieving the results of reports and importing metrics.",
+			Version:     "v1",
+		},
+		Paths:      make(map[string]openapi.PathItem),
+		Components: components,
+		Security:   []openapi.SecurityRequirement{{"bearerAuth": {}}, {}},
+	}
+	for i := range apiRoutes {
+		route := apiRoutes[i]
+		item, exists := doc.Paths[route.path]
+		if !exists {
+			item = make(openapi.PathItem)
+			doc.Paths[route.path] = item
+		}
+		operation := route.operation
+		item[strings.ToLower(route.method)] = &operation
+	}
+	return doc
+}
+
+func (srv *server) openAPISpecHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	writeResponseAsJSON(logger, w, http.StatusOK, NewOpenAPIDocument())
+}
+
+// OpenAPISpec returns the OpenAPI specification of the reporting API as
+// indented JSON.
+func OpenAPISpec() ([]byte, error) {
+	return json.MarshalIndent(NewOpenAPIDocument(), "", "  ")
+}
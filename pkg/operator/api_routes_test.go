@@ -0,0 +1,110 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-metering/pkg/client/reportingapi"
+	"github.com/operator-framework/operator-metering/pkg/openapi"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+func TestOpenAPISpecRoutes(t *testing.T) {
+	router := newRouter(testLogger, routerConfig{
+		rand:          testRand,
+		collectorFunc: noopPrometheusImporterFunc,
+		faultInjector: &prestostore.FaultInjector{},
+	})
+	routes := make(map[string]bool)
+	err := chi.Walk(router, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		routes[route] = true
+		return nil
+	})
+	require.NoError(t, err)
+
+	doc := NewOpenAPIDocument()
+	operationIDs := make(map[string]bool)
+	for path, item := range doc.Paths {
+		assert.True(t, routes[path], "expected %s to be routed", path)
+		for method, op := range item {
+			assert.Equal(t, strings.ToLower(method), method, "expected methods to be lowercase")
+			assert.False(t, operationIDs[op.OperationID], "duplicate operationId %s", op.OperationID)
+			operationIDs[op.OperationID] = true
+			for _, param := range op.Parameters {
+				if param.In == openapi.InPath {
+					assert.Contains(t, path, "{"+param.Name+"}", "expected path parameter %s of %s to be in the path", param.Name, op.OperationID)
+				}
+			}
+		}
+	}
+	for _, schema := range doc.Components.Schemas {
+		assert.NotNil(t, schema)
+	}
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+	resp, err := server.Client().Get(server.URL + OpenAPISpecEndpoint)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var served openapi.Document
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&served))
+	assert.Equal(t, openapi.Version, served.OpenAPI)
+	assert.Len(t, served.Paths, len(doc.Paths))
+}
+
+func TestOpenAPIGeneratedFilesUpToDate(t *testing.T) {
+	spec, err := OpenAPISpec()
+	require.NoError(t, err)
+	committedSpec, err := ioutil.ReadFile(filepath.Join("..", "..", "Documentation", "openapi.json"))
+	require.NoError(t, err)
+	assert.Equal(t, string(spec), string(committedSpec), "Documentation/openapi.json is out of date, run make reportingapi-gen")
+
+	client, err := openapi.GenerateGoClient(NewOpenAPIDocument(), "reportingapi")
+	require.NoError(t, err)
+	committedClient, err := ioutil.ReadFile(filepath.Join("..", "client", "reportingapi", "zz_generated.client.go"))
+	require.NoError(t, err)
+	assert.Equal(t, string(client), string(committedClient), "pkg/client/reportingapi is out of date, run make reportingapi-gen")
+}
+
+func TestReportingAPIClient(t *testing.T) {
+	router := newRouter(testLogger, routerConfig{
+		rand:          testRand,
+		collectorFunc: noopPrometheusImporterFunc,
+		faultInjector: &prestostore.FaultInjector{},
+		readOnly:      true,
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+	client, err := reportingapi.NewClient(server.URL+"/", server.Client())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	faults, err := client.SetFaults(ctx, reportingapi.FaultsRequest{DropChunkProbability: 0.5, InsertDelay: "1s"})
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, faults.DropChunkProbability)
+	assert.Equal(t, "1s", faults.InsertDelay)
+	faults, err = client.DeleteFaults(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, faults.DropChunkProbability)
+
+	_, err = client.CreateReportRun(ctx, reportingapi.ReportRunRequest{GenerationQuery: "pod-cpu-request"})
+	require.IsType(t, &reportingapi.APIError{}, err)
+	assert.Equal(t, http.StatusForbidden, err.(*reportingapi.APIError).StatusCode)
+	assert.Contains(t, err.(*reportingapi.APIError).Message, "read-only mode")
+
+	resp, err := client.GetOpenAPISpec(ctx)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+}
@@ -0,0 +1,296 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	// auditTableName is the table audit events are stored in when
+	// AuditConfig.StoreInPresto is set.
+	auditTableName = "metering_audit_log"
+
+	DefaultAuditFlushInterval = time.Minute
+
+	// auditMaxPendingEvents is the most audit events held while waiting to
+	// be stored in Presto. Events are dropped, though still logged, while
+	// this many are pending.
+	auditMaxPendingEvents = 10000
+)
+
+var auditHiveColumns = []hive.Column{
+	{Name: "timestamp", Type: "timestamp"},
+	{Name: "source", Type: "string"},
+	{Name: "user", Type: "string"},
+	{Name: "action", Type: "string"},
+	{Name: "endpoint", Type: "string"},
+	{Name: "resource", Type: "string"},
+	{Name: "namespace", Type: "string"},
+	{Name: "name", Type: "string"},
+	{Name: "status_code", Type: "int"},
+	{Name: "rows", Type: "bigint"},
+}
+
+// AuditConfig configures audit logging of HTTP API requests, and of the
+// creation, update and deletion of Reports, ScheduledReports and
+// ReportDataSources.
+type AuditConfig struct {
+	Enabled bool
+	// StoreInPresto stores audit events in the metering_audit_log table,
+	// in addition to logging them.
+	StoreInPresto bool
+	// FlushInterval is how often audit events are stored in Presto.
+	FlushInterval time.Duration
+}
+
+func (cfg AuditConfig) Valid() error {
+	if cfg.StoreInPresto && !cfg.Enabled {
+		return fmt.Errorf("audit logging must be enabled to store audit events in Presto")
+	}
+	if cfg.StoreInPresto && cfg.FlushInterval <= 0 {
+		return fmt.Errorf("the audit flush interval must be positive")
+	}
+	return nil
+}
+
+// auditLogger logs audit events, and holds them to be stored in Presto if
+// storing them is enabled.
+type auditLogger struct {
+	logger log.FieldLogger
+	clock  clock.Clock
+	// namespace is the namespace of resources accessed by API requests
+	// which don't name one.
+	namespace string
+	// started is when the auditLogger was created. Resources created
+	// before then are listed when the reporting-operator starts, and
+	// aren't audited as being created.
+	started time.Time
+	store   bool
+
+	mu      sync.Mutex
+	pending []*prestostore.AuditEvent
+}
+
+func newAuditLogger(logger log.FieldLogger, clock clock.Clock, namespace string, store bool) *auditLogger {
+	return &auditLogger{
+		logger:    logger.WithField("component", "audit"),
+		clock:     clock,
+		namespace: namespace,
+		started:   clock.Now(),
+		store:     store,
+	}
+}
+
+func (a *auditLogger) log(event *prestostore.AuditEvent) {
+	fields := log.Fields{
+		"audit":     true,
+		"source":    event.Source,
+		"action":    event.Action,
+		"resource":  event.Resource,
+		"namespace": event.Namespace,
+		"name":      event.Name,
+	}
+	if event.User != "" {
+		fields["user"] = event.User
+	}
+	if event.Endpoint != "" {
+		fields["endpoint"] = event.Endpoint
+		fields["status"] = event.StatusCode
+	}
+	if event.Rows >= 0 {
+		fields["rows"] = event.Rows
+	}
+	a.logger.WithFields(fields).Info("audit")
+
+	if !a.store {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.pending) >= auditMaxPendingEvents {
+		a.logger.Warnf("dropping audit event, %d audit events are waiting to be stored in Presto", len(a.pending))
+		return
+	}
+	a.pending = append(a.pending, event)
+}
+
+// run stores the pending audit events in Presto every flushInterval, until
+// stopCh is closed.
+func (a *auditLogger) run(stopCh <-chan struct{}, execer presto.Execer, flushInterval time.Duration) {
+	tick := a.clock.Tick(flushInterval)
+	for {
+		select {
+		case <-stopCh:
+			a.flush(execer)
+			return
+		case <-tick:
+			a.flush(execer)
+		}
+	}
+}
+
+// flush stores the pending audit events in Presto. If they can't be
+// stored, they remain pending and are retried by the next flush.
+func (a *auditLogger) flush(execer presto.Execer) {
+	a.mu.Lock()
+	events := a.pending
+	a.pending = nil
+	a.mu.Unlock()
+	if len(events) == 0 {
+		return
+	}
+	err := prestostore.StoreAuditEvents(context.Background(), execer, auditTableName, events)
+	if err != nil {
+		a.logger.WithError(err).Errorf("unable to store %d audit events", len(events))
+		a.mu.Lock()
+		a.pending = append(events, a.pending...)
+		if len(a.pending) > auditMaxPendingEvents {
+			a.pending = a.pending[:auditMaxPendingEvents]
+		}
+		a.mu.Unlock()
+	}
+}
+
+// auditRecord collects the details of an API request which are only known
+// once it's been handled.
+type auditRecord struct {
+	user string
+	rows int64
+}
+
+type auditRecordKey struct{}
+
+// setAuditUser records the authenticated user making the request, if the
+// request is audited.
+func setAuditUser(r *http.Request, user string) {
+	if record, ok := r.Context().Value(auditRecordKey{}).(*auditRecord); ok {
+		record.user = user
+	}
+}
+
+// setAuditRows records the number of result rows returned by the request,
+// if the request is audited.
+func setAuditRows(r *http.Request, rows int) {
+	if record, ok := r.Context().Value(auditRecordKey{}).(*auditRecord); ok {
+		record.rows = int64(rows)
+	}
+}
+
+// handler wraps a route's handler, logging an audit event for each request
+// once it's been handled. The user is the authenticated user if API
+// authentication is enabled, the common name of the client certificate if
+// there is one, or the user set by the auth proxy if it's trusted.
+func (a *auditLogger) handler(srv *server, route apiRoute, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		record := &auditRecord{rows: -1}
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		// the event is logged even if the handler panics to abort a
+		// streamed response.
+		defer func() {
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			user := record.user
+			if user == "" {
+				user = clientCertificateUser(r)
+			}
+			if user == "" && srv.queryConfig.TrustForwardedUser {
+				user = r.Header.Get(forwardedUserHeader)
+			}
+			resource, namespace, name := route.access.target(r, a.namespace)
+			if name == "" {
+				namespace = ""
+			}
+			a.log(&prestostore.AuditEvent{
+				Timestamp:  a.clock.Now().UTC(),
+				Source:     "api",
+				User:       user,
+				Action:     route.operation.OperationID,
+				Endpoint:   route.path,
+				Resource:   resource,
+				Namespace:  namespace,
+				Name:       name,
+				StatusCode: status,
+				Rows:       record.rows,
+			})
+		}()
+		handler(ww, r.WithContext(context.WithValue(r.Context(), auditRecordKey{}, record)))
+	}
+}
+
+// resourceEventHandler returns an event handler logging an audit event
+// when resources are created, have their spec updated, or are deleted.
+func (a *auditLogger) resourceEventHandler(resource string) cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			accessor, err := meta.Accessor(obj)
+			if err != nil || accessor.GetCreationTimestamp().Time.Before(a.started) {
+				return
+			}
+			a.logResourceEvent("create", resource, accessor)
+		},
+		UpdateFunc: func(old, current interface{}) {
+			if !specChanged(old, current) {
+				return
+			}
+			accessor, err := meta.Accessor(current)
+			if err != nil {
+				return
+			}
+			a.logResourceEvent("update", resource, accessor)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			accessor, err := meta.Accessor(obj)
+			if err != nil {
+				return
+			}
+			a.logResourceEvent("delete", resource, accessor)
+		},
+	}
+}
+
+func (a *auditLogger) logResourceEvent(action, resource string, obj metav1.Object) {
+	a.log(&prestostore.AuditEvent{
+		Timestamp: a.clock.Now().UTC(),
+		Source:    "controller",
+		Action:    action,
+		Resource:  resource,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Rows:      -1,
+	})
+}
+
+// specChanged returns whether the spec of the resource changed. Updates to
+// only the status or metadata of resources, and resyncs, aren't audited.
+func specChanged(old, current interface{}) bool {
+	oldObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(old)
+	if err != nil {
+		return false
+	}
+	currentObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(current)
+	if err != nil {
+		return false
+	}
+	return !reflect.DeepEqual(oldObj["spec"], currentObj["spec"])
+}
@@ -0,0 +1,151 @@
+package operator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+func TestAuditAPIRequests(t *testing.T) {
+	const (
+		namespace  = "metering"
+		reportName = "namespace-cost"
+	)
+	now := time.Date(2018, time.August, 2, 0, 0, 0, 0, time.UTC)
+	start := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	columns := []v1alpha1.ReportGenerationQueryColumn{{Name: "namespace", Type: "string"}}
+	tableColumns := []hive.Column{{Name: "namespace", Type: "string"}}
+
+	reportIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	reportGenerationQueryIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	prestoTableIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	reportIndexer.Add(newTestReport(reportName, namespace, "test-query", start, end, v1alpha1.ReportStatus{Phase: v1alpha1.ReportPhaseFinished}))
+	reportGenerationQueryIndexer.Add(newTestReportGenQuery("test-query", namespace, columns))
+	prestoTableIndexer.Add(newTestPrestoTable(reportName, namespace, tableColumns))
+	meteringListers := meteringListers{
+		reports:                 listers.NewReportLister(reportIndexer).Reports(namespace),
+		reportGenerationQueries: listers.NewReportGenerationQueryLister(reportGenerationQueryIndexer).ReportGenerationQueries(namespace),
+		prestoTables:            listers.NewPrestoTableLister(prestoTableIndexer).PrestoTables(namespace),
+	}
+	prestoColumns := []presto.Column{{Name: "namespace", Type: "VARCHAR"}}
+
+	tests := map[string]struct {
+		path          string
+		expectQuery   bool
+		expectedEvent prestostore.AuditEvent
+	}{
+		"report results": {
+			path:        APIV1ReportsGetEndpoint + "?format=json&name=" + reportName,
+			expectQuery: true,
+			expectedEvent: prestostore.AuditEvent{
+				Timestamp:  now,
+				Source:     "api",
+				User:       "alice",
+				Action:     "getReport",
+				Endpoint:   APIV1ReportsGetEndpoint,
+				Resource:   "reports",
+				Namespace:  namespace,
+				Name:       reportName,
+				StatusCode: http.StatusOK,
+				Rows:       2,
+			},
+		},
+		"missing report": {
+			path: APIV1ReportsGetEndpoint + "?format=json&name=missing",
+			expectedEvent: prestostore.AuditEvent{
+				Timestamp:  now,
+				Source:     "api",
+				User:       "alice",
+				Action:     "getReport",
+				Endpoint:   APIV1ReportsGetEndpoint,
+				Resource:   "reports",
+				Namespace:  namespace,
+				Name:       "missing",
+				StatusCode: http.StatusNotFound,
+				Rows:       -1,
+			},
+		},
+	}
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			queryer := mockpresto.NewMockExecQueryer(ctrl)
+			if tt.expectQuery {
+				queryer.EXPECT().Query(presto.GenerateGetRowsWhereSQL(reportTableName(reportName), prestoColumns, "")).Return([]presto.Row{{"namespace": "team-a"}, {"namespace": "team-b"}}, nil)
+			}
+			audit := newAuditLogger(testLogger, clock.NewFakeClock(now), namespace, true)
+			router := newRouter(testLogger, routerConfig{
+				rand:            testRand,
+				queryer:         queryer,
+				importerQueryer: queryer,
+				collectorFunc:   noopPrometheusImporterFunc,
+				listers:         meteringListers,
+				queryConfig:     QueryConfig{TrustForwardedUser: true},
+				audit:           audit,
+			})
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set(forwardedUserHeader, "alice")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Len(t, audit.pending, 1)
+			assert.Equal(t, tt.expectedEvent, *audit.pending[0])
+		})
+	}
+}
+
+func TestAuditResourceEvents(t *testing.T) {
+	started := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	audit := newAuditLogger(testLogger, clock.NewFakeClock(started), "metering", true)
+	handler := audit.resourceEventHandler("reports")
+
+	newReport := func(created time.Time, query string) *v1alpha1.Report {
+		return &v1alpha1.Report{
+			ObjectMeta: metav1.ObjectMeta{Name: "namespace-cost", Namespace: "metering", CreationTimestamp: metav1.NewTime(created)},
+			Spec:       v1alpha1.ReportSpec{GenerationQueryName: query},
+		}
+	}
+	existing := newReport(started.Add(-time.Hour), "pod-cpu")
+	created := newReport(started.Add(time.Second), "pod-cpu")
+	finished := created.DeepCopy()
+	finished.Status.Phase = v1alpha1.ReportPhaseFinished
+	updated := finished.DeepCopy()
+	updated.Spec.GenerationQueryName = "pod-memory"
+
+	// reports which existed when the reporting-operator started aren't
+	// audited, nor are status updates.
+	handler.OnAdd(existing)
+	handler.OnAdd(created)
+	handler.OnUpdate(created, finished)
+	handler.OnUpdate(finished, updated)
+	handler.OnDelete(cache.DeletedFinalStateUnknown{Key: "metering/namespace-cost", Obj: updated})
+
+	var actions []string
+	for _, event := range audit.pending {
+		assert.Equal(t, "controller", event.Source)
+		assert.Equal(t, "reports", event.Resource)
+		assert.Equal(t, "namespace-cost", event.Name)
+		actions = append(actions, event.Action)
+	}
+	assert.Equal(t, []string{"create", "update", "delete"}, actions)
+}
@@ -28,6 +28,22 @@ var (
 		{Name: "billing_period_start", Type: "string"},
 		{Name: "billing_period_end", Type: "string"},
 	}
+
+	// awsUsageRequiredColumns are the columns used by the aws-billing
+	// ReportGenerationQueries to determine the amortized cost of Spot,
+	// Reserved Instance and Savings Plans usage. Reports only contain the
+	// reservation and savingsPlan columns once the account has purchased
+	// them, so they're added to the table if none of the manifests contain
+	// them. They're added after the manifest columns, so in reports without
+	// them they're NULL.
+	awsUsageRequiredColumns = []aws.Column{
+		{Category: "lineItem", Name: "LineItemType"},
+		{Category: "lineItem", Name: "UsageType"},
+		{Category: "lineItem", Name: "UnblendedCost"},
+		{Category: "pricing", Name: "publicOnDemandCost"},
+		{Category: "reservation", Name: "EffectiveCost"},
+		{Category: "savingsPlan", Name: "SavingsPlanEffectiveCost"},
+	}
 )
 
 const awsUsagePartitionDateStringLayout = "20060102"
@@ -39,31 +55,9 @@ func (op *Reporting) createAWSUsageTable(logger logrus.FieldLogger, dataSource *
 		return err
 	}
 
-	// Since the billing data likely exists already, we need to enumerate all
-	// columns for all manifests to get the entire set of columns used
-	// historically.
-	// TODO(chance): We will likely want to do this when we add partitions
-	// to avoid having to do it all up front.
-	columns := make([]hive.Column, 0)
-	seen := make(map[string]struct{})
-	for _, manifest := range manifests {
-		for _, c := range manifest.Columns {
-			name := sanetizeAWSColumnForHive(c)
-			colType := awsColumnToHiveColumnType(c)
-
-			if _, exists := seen[name]; !exists {
-				seen[name] = struct{}{}
-				columns = append(columns, hive.Column{
-					Name: name,
-					Type: colType,
-				})
-			}
-		}
-	}
-
 	params := hive.TableParameters{
 		Name:         tableName,
-		Columns:      columns,
+		Columns:      awsUsageHiveColumns(manifests),
 		Partitions:   awsUsageHivePartitions,
 		IgnoreExists: true,
 	}
@@ -77,6 +71,37 @@ func (op *Reporting) createAWSUsageTable(logger logrus.FieldLogger, dataSource *
 	return op.createTableWith(logger, dataSource, "ReportDataSource", dataSource.Name, params, properties)
 }
 
+// awsUsageHiveColumns returns the columns of the table for the AWS billing
+// reports described by manifests.
+func awsUsageHiveColumns(manifests []*aws.Manifest) []hive.Column {
+	// Since the billing data likely exists already, we need to enumerate all
+	// columns for all manifests to get the entire set of columns used
+	// historically.
+	// TODO(chance): We will likely want to do this when we add partitions
+	// to avoid having to do it all up front.
+	columns := make([]hive.Column, 0)
+	seen := make(map[string]struct{})
+	addColumn := func(c aws.Column) {
+		name := sanetizeAWSColumnForHive(c)
+		if _, exists := seen[name]; !exists {
+			seen[name] = struct{}{}
+			columns = append(columns, hive.Column{
+				Name: name,
+				Type: awsColumnToHiveColumnType(c),
+			})
+		}
+	}
+	for _, manifest := range manifests {
+		for _, c := range manifest.Columns {
+			addColumn(c)
+		}
+	}
+	for _, c := range awsUsageRequiredColumns {
+		addColumn(c)
+	}
+	return columns
+}
+
 // addAWSHivePartition will add a new partition to the given tableName for the time
 // range, pointing at the location
 func addAWSHivePartition(queryer db.Queryer, tableName, start, end, location string) error {
@@ -110,7 +135,7 @@ func awsColumnToHiveColumnType(c aws.Column) string {
 	switch sanetizeAWSColumnForHive(c) {
 	case "lineitem_usagestartdate", "lineitem_usageenddate":
 		return "timestamp"
-	case "lineitem_blendedcost":
+	case "lineitem_blendedcost", "lineitem_unblendedcost", "pricing_publicondemandcost", "reservation_effectivecost", "savingsplan_savingsplaneffectivecost":
 		return "double"
 	default:
 		return "string"
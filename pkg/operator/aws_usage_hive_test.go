@@ -0,0 +1,73 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/operator-framework/operator-metering/pkg/aws"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+)
+
+func TestAWSUsageHiveColumns(t *testing.T) {
+	requiredColumns := []hive.Column{
+		{Name: "lineitem_lineitemtype", Type: "string"},
+		{Name: "lineitem_usagetype", Type: "string"},
+		{Name: "lineitem_unblendedcost", Type: "double"},
+		{Name: "pricing_publicondemandcost", Type: "double"},
+		{Name: "reservation_effectivecost", Type: "double"},
+		{Name: "savingsplan_savingsplaneffectivecost", Type: "double"},
+	}
+
+	tests := map[string]struct {
+		manifests []*aws.Manifest
+		expected  []hive.Column
+	}{
+		"no manifests": {
+			expected: requiredColumns,
+		},
+		"required columns are added after manifest columns": {
+			manifests: []*aws.Manifest{
+				{Columns: []aws.Column{
+					{Category: "lineItem", Name: "UsageStartDate"},
+					{Category: "lineItem", Name: "BlendedCost"},
+				}},
+			},
+			expected: append([]hive.Column{
+				{Name: "lineitem_usagestartdate", Type: "timestamp"},
+				{Name: "lineitem_blendedcost", Type: "double"},
+			}, requiredColumns...),
+		},
+		"columns in manifests keep their position": {
+			manifests: []*aws.Manifest{
+				{Columns: []aws.Column{
+					{Category: "lineItem", Name: "LineItemType"},
+					{Category: "lineItem", Name: "UsageType"},
+					{Category: "lineItem", Name: "UnblendedCost"},
+				}},
+				{Columns: []aws.Column{
+					{Category: "lineItem", Name: "LineItemType"},
+					{Category: "lineItem", Name: "UsageType"},
+					{Category: "lineItem", Name: "UnblendedCost"},
+					{Category: "savingsPlan", Name: "SavingsPlanEffectiveCost"},
+				}},
+			},
+			expected: []hive.Column{
+				{Name: "lineitem_lineitemtype", Type: "string"},
+				{Name: "lineitem_usagetype", Type: "string"},
+				{Name: "lineitem_unblendedcost", Type: "double"},
+				{Name: "savingsplan_savingsplaneffectivecost", Type: "double"},
+				{Name: "pricing_publicondemandcost", Type: "double"},
+				{Name: "reservation_effectivecost", Type: "double"},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, awsUsageHiveColumns(tt.manifests))
+		})
+	}
+}
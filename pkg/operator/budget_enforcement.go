@@ -0,0 +1,355 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/secrets"
+)
+
+const defaultBudgetNamespaceColumn = "namespace"
+
+func validateScheduledReportBudget(budget *cbTypes.ScheduledReportBudget) error {
+	if budget == nil {
+		return nil
+	}
+	if budget.CostColumn == "" {
+		return fmt.Errorf("budget.costColumn must be set")
+	}
+	if budget.Amount < 0 {
+		return fmt.Errorf("budget.amount must not be negative")
+	}
+	for namespace, amount := range budget.Namespaces {
+		if amount < 0 {
+			return fmt.Errorf("budget.namespaces: the amount of namespace %s must not be negative", namespace)
+		}
+	}
+	if quota := budget.Enforcement.ResourceQuota; quota != nil {
+		if quota.Name == "" {
+			return fmt.Errorf("budget.enforcement.resourceQuota.name must be set")
+		}
+		if quota.Factor < 0 || quota.Factor >= 1 {
+			return fmt.Errorf("budget.enforcement.resourceQuota.factor must be at least 0 and less than 1, got %v", quota.Factor)
+		}
+	}
+	if webhook := budget.Enforcement.Webhook; webhook != nil {
+		if _, err := url.Parse(webhook.URL); err != nil || webhook.URL == "" {
+			return fmt.Errorf("budget.enforcement.webhook.url must be a valid URL")
+		}
+		if webhook.BearerTokenSecret != "" {
+			if _, err := secrets.ParseRef(webhook.BearerTokenSecret); err != nil {
+				return fmt.Errorf("invalid budget.enforcement.webhook.bearerTokenSecret: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// exceededNamespaceBudgets sums the budget's cost column of the results for
+// each namespace, returning the namespaces whose sum exceeds their budget,
+// sorted by namespace. Like report metrics, only the rows of the most recent
+// period are used if the results have a period_end column.
+func exceededNamespaceBudgets(budget *cbTypes.ScheduledReportBudget, columns []cbTypes.ReportGenerationQueryColumn, results []presto.Row) ([]cbTypes.BudgetNamespaceStatus, error) {
+	namespaceColumn := budget.NamespaceColumn
+	if namespaceColumn == "" {
+		namespaceColumn = defaultBudgetNamespaceColumn
+	}
+	for _, column := range columns {
+		if column.Name == reportMetricsPeriodEndColumn {
+			results = latestPeriodResults(results)
+			break
+		}
+	}
+	rowsByNamespace := make(map[string][]presto.Row)
+	for _, row := range results {
+		val, ok := row[namespaceColumn]
+		if !ok {
+			return nil, fmt.Errorf("column %s isn't in the results", namespaceColumn)
+		}
+		// rows without a namespace, such as unallocated cost, aren't
+		// charged to any namespace's budget
+		namespace, _ := val.(string)
+		if namespace == "" {
+			continue
+		}
+		rowsByNamespace[namespace] = append(rowsByNamespace[namespace], row)
+	}
+
+	var exceeded []cbTypes.BudgetNamespaceStatus
+	for namespace, rows := range rowsByNamespace {
+		cost, err := sumResultsColumn(rows, budget.CostColumn)
+		if err != nil {
+			return nil, err
+		}
+		amount := budget.Amount
+		if override, ok := budget.Namespaces[namespace]; ok {
+			amount = override
+		}
+		if cost > amount {
+			exceeded = append(exceeded, cbTypes.BudgetNamespaceStatus{
+				Namespace: namespace,
+				Cost:      cost,
+				Amount:    amount,
+			})
+		}
+	}
+	sort.Slice(exceeded, func(i, j int) bool {
+		return exceeded[i].Namespace < exceeded[j].Namespace
+	})
+	return exceeded, nil
+}
+
+// checkScheduledReportBudget finds the namespaces which exceeded the
+// ScheduledReport's budget in the run, and if budget enforcement is
+// enabled, takes the budget's enforcement actions on them, and reverts them
+// on the namespaces which were enforced by the previous run but no longer
+// exceed their budget. Failing to check the budget doesn't fail the run.
+func (op *Reporting) checkScheduledReportBudget(ctx context.Context, logger logrus.FieldLogger, run reportRun, budget *cbTypes.ScheduledReportBudget, previous *cbTypes.ScheduledReportBudgetStatus) *cbTypes.ScheduledReportBudgetStatus {
+	if budget == nil {
+		return nil
+	}
+	status := &cbTypes.ScheduledReportBudgetStatus{
+		LastCheckTime: metav1.Time{Time: op.clock.Now().UTC()},
+	}
+	columns, results, err := op.getDeliveryResults(run.tableName, run.generationQuery, run.groupByLabels)
+	if err != nil {
+		err = fmt.Errorf("unable to get report results: %v", err)
+	} else {
+		status.ExceededNamespaces, err = exceededNamespaceBudgets(budget, columns, results)
+	}
+	if err != nil {
+		logger.WithError(err).Errorf("unable to check budget")
+		status.Error = err.Error()
+		// keep the previous namespaces, so their enforcement is reverted
+		// by the next run which succeeds in checking the budget
+		if previous != nil {
+			status.ExceededNamespaces = previous.ExceededNamespaces
+		}
+		return status
+	}
+	if len(status.ExceededNamespaces) != 0 {
+		logger.Infof("%d namespaces exceeded their budget", len(status.ExceededNamespaces))
+	}
+	if !op.cfg.EnableBudgetEnforcement {
+		return status
+	}
+
+	var errs []string
+	exceeded := make(map[string]bool)
+	for i, ns := range status.ExceededNamespaces {
+		exceeded[ns.Namespace] = true
+		// actions which succeeded before one failed are reverted in the
+		// same way as when all succeeded
+		status.ExceededNamespaces[i].Enforced = true
+		if err := op.enforceNamespaceBudget(run, budget.Enforcement, ns.Namespace); err != nil {
+			logger.WithError(err).Errorf("unable to enforce the budget of namespace %s", ns.Namespace)
+			errs = append(errs, fmt.Sprintf("namespace %s: %v", ns.Namespace, err))
+		}
+	}
+	if webhook := budget.Enforcement.Webhook; webhook != nil && len(exceeded) != 0 {
+		if err := op.sendBudgetWebhook(ctx, webhook, run, status.ExceededNamespaces); err != nil {
+			logger.WithError(err).Errorf("unable to send budget webhook")
+			errs = append(errs, err.Error())
+		}
+	}
+	if previous != nil {
+		for _, ns := range previous.ExceededNamespaces {
+			if !ns.Enforced || exceeded[ns.Namespace] {
+				continue
+			}
+			if err := op.revertNamespaceBudget(run, budget.Enforcement, ns.Namespace); err != nil {
+				logger.WithError(err).Errorf("unable to revert the budget enforcement of namespace %s", ns.Namespace)
+				errs = append(errs, fmt.Sprintf("namespace %s: %v", ns.Namespace, err))
+				// keep the namespace, so reverting it is retried
+				ns.Enforced = true
+				status.ExceededNamespaces = append(status.ExceededNamespaces, ns)
+				continue
+			}
+			logger.Infof("reverted the budget enforcement of namespace %s", ns.Namespace)
+		}
+	}
+	status.Error = strings.Join(errs, "; ")
+	return status
+}
+
+// budgetAnnotationValue identifies the ScheduledReport which enforced the
+// budget of a namespace.
+func budgetAnnotationValue(run reportRun) string {
+	return run.namespace + "/" + run.name
+}
+
+func (op *Reporting) enforceNamespaceBudget(run reportRun, enforcement cbTypes.BudgetEnforcement, namespace string) error {
+	if enforcement.Annotate {
+		if err := op.patchNamespaceAnnotation(namespace, budgetAnnotationValue(run)); err != nil {
+			return err
+		}
+	}
+	if quota := enforcement.ResourceQuota; quota != nil {
+		if err := op.scaleResourceQuota(namespace, quota); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (op *Reporting) revertNamespaceBudget(run reportRun, enforcement cbTypes.BudgetEnforcement, namespace string) error {
+	if enforcement.Annotate {
+		ns, err := op.kubeClient.Namespaces().Get(namespace, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			return nil
+		case err != nil:
+			return err
+		}
+		// the annotation may have been set by another ScheduledReport's
+		// budget, which the namespace still exceeds
+		if ns.Annotations[cbTypes.BudgetExceededAnnotation] == budgetAnnotationValue(run) {
+			if err := op.patchNamespaceAnnotation(namespace, nil); err != nil {
+				return err
+			}
+		}
+	}
+	if quota := enforcement.ResourceQuota; quota != nil {
+		if err := op.restoreResourceQuota(namespace, quota.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// patchNamespaceAnnotation sets the BudgetExceededAnnotation of the
+// namespace to value, or removes it if value is nil.
+func (op *Reporting) patchNamespaceAnnotation(namespace string, value interface{}) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				cbTypes.BudgetExceededAnnotation: value,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = op.kubeClient.Namespaces().Patch(namespace, types.MergePatchType, patch)
+	if err != nil {
+		return fmt.Errorf("unable to annotate namespace: %v", err)
+	}
+	return nil
+}
+
+// scaleResourceQuota multiplies the hard limits of the ResourceQuota by the
+// action's factor, recording the original limits in an annotation. A
+// ResourceQuota which is already scaled, or doesn't exist, is unchanged.
+func (op *Reporting) scaleResourceQuota(namespace string, action *cbTypes.BudgetResourceQuotaAction) error {
+	quota, err := op.kubeClient.ResourceQuotas(namespace).Get(action.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return nil
+	case err != nil:
+		return fmt.Errorf("unable to get ResourceQuota %s: %v", action.Name, err)
+	}
+	if _, scaled := quota.Annotations[cbTypes.BudgetOriginalHardAnnotation]; scaled {
+		return nil
+	}
+	original, err := json.Marshal(quota.Spec.Hard)
+	if err != nil {
+		return err
+	}
+	if quota.Annotations == nil {
+		quota.Annotations = make(map[string]string)
+	}
+	quota.Annotations[cbTypes.BudgetOriginalHardAnnotation] = string(original)
+	quota.Spec.Hard = scaleResourceList(quota.Spec.Hard, action.Factor)
+	_, err = op.kubeClient.ResourceQuotas(namespace).Update(quota)
+	if err != nil {
+		return fmt.Errorf("unable to scale ResourceQuota %s: %v", action.Name, err)
+	}
+	return nil
+}
+
+// restoreResourceQuota restores the hard limits of a ResourceQuota scaled
+// by scaleResourceQuota.
+func (op *Reporting) restoreResourceQuota(namespace, name string) error {
+	quota, err := op.kubeClient.ResourceQuotas(namespace).Get(name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return nil
+	case err != nil:
+		return fmt.Errorf("unable to get ResourceQuota %s: %v", name, err)
+	}
+	original, scaled := quota.Annotations[cbTypes.BudgetOriginalHardAnnotation]
+	if !scaled {
+		return nil
+	}
+	var hard v1.ResourceList
+	if err := json.Unmarshal([]byte(original), &hard); err != nil {
+		return fmt.Errorf("invalid %s annotation on ResourceQuota %s: %v", cbTypes.BudgetOriginalHardAnnotation, name, err)
+	}
+	delete(quota.Annotations, cbTypes.BudgetOriginalHardAnnotation)
+	quota.Spec.Hard = hard
+	_, err = op.kubeClient.ResourceQuotas(namespace).Update(quota)
+	if err != nil {
+		return fmt.Errorf("unable to restore ResourceQuota %s: %v", name, err)
+	}
+	return nil
+}
+
+// scaleResourceList returns a copy of list with each quantity multiplied by
+// factor, rounded down.
+func scaleResourceList(list v1.ResourceList, factor float64) v1.ResourceList {
+	scaled := make(v1.ResourceList, len(list))
+	for name, quantity := range list {
+		milli := math.Floor(float64(quantity.MilliValue()) * factor)
+		// quantities of whole units, such as pods, stay whole
+		if quantity.MilliValue()%1000 == 0 {
+			milli = math.Floor(milli/1000) * 1000
+		}
+		scaled[name] = *resource.NewMilliQuantity(int64(milli), quantity.Format)
+	}
+	return scaled
+}
+
+// budgetWebhookPayload is the body of the requests sent to a budget's
+// enforcement webhook.
+type budgetWebhookPayload struct {
+	Kind               string                          `json:"kind"`
+	Namespace          string                          `json:"namespace"`
+	Name               string                          `json:"name"`
+	PeriodStart        time.Time                       `json:"periodStart"`
+	PeriodEnd          time.Time                       `json:"periodEnd"`
+	ExceededNamespaces []cbTypes.BudgetNamespaceStatus `json:"exceededNamespaces"`
+}
+
+func (op *Reporting) sendBudgetWebhook(ctx context.Context, webhook *cbTypes.WebhookNotification, run reportRun, exceeded []cbTypes.BudgetNamespaceStatus) error {
+	client, err := op.webhookNotificationClient(webhook)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(budgetWebhookPayload{
+		Kind:               run.kind,
+		Namespace:          run.namespace,
+		Name:               run.name,
+		PeriodStart:        run.periodStart,
+		PeriodEnd:          run.periodEnd,
+		ExceededNamespaces: exceeded,
+	})
+	if err != nil {
+		return err
+	}
+	return postNotification(ctx, client, webhook.URL, webhook.URL, body)
+}
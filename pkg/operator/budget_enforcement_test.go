@@ -0,0 +1,145 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+func TestValidateScheduledReportBudget(t *testing.T) {
+	tests := map[string]struct {
+		budget      *cbTypes.ScheduledReportBudget
+		expectedErr bool
+	}{
+		"no budget": {},
+		"valid": {
+			budget: &cbTypes.ScheduledReportBudget{
+				CostColumn: "total_cost",
+				Amount:     100,
+				Namespaces: map[string]float64{"team-a": 500},
+				Enforcement: cbTypes.BudgetEnforcement{
+					Annotate:      true,
+					ResourceQuota: &cbTypes.BudgetResourceQuotaAction{Name: "compute", Factor: 0.5},
+					Webhook:       &cbTypes.WebhookNotification{URL: "https://billing.example.com/budgets"},
+				},
+			},
+		},
+		"no cost column": {
+			budget:      &cbTypes.ScheduledReportBudget{Amount: 100},
+			expectedErr: true,
+		},
+		"negative namespace amount": {
+			budget:      &cbTypes.ScheduledReportBudget{CostColumn: "total_cost", Namespaces: map[string]float64{"team-a": -1}},
+			expectedErr: true,
+		},
+		"resource quota without a name": {
+			budget: &cbTypes.ScheduledReportBudget{
+				CostColumn:  "total_cost",
+				Enforcement: cbTypes.BudgetEnforcement{ResourceQuota: &cbTypes.BudgetResourceQuotaAction{Factor: 0.5}},
+			},
+			expectedErr: true,
+		},
+		"resource quota factor of 1": {
+			budget: &cbTypes.ScheduledReportBudget{
+				CostColumn:  "total_cost",
+				Enforcement: cbTypes.BudgetEnforcement{ResourceQuota: &cbTypes.BudgetResourceQuotaAction{Name: "compute", Factor: 1}},
+			},
+			expectedErr: true,
+		},
+		"webhook without a URL": {
+			budget: &cbTypes.ScheduledReportBudget{
+				CostColumn:  "total_cost",
+				Enforcement: cbTypes.BudgetEnforcement{Webhook: &cbTypes.WebhookNotification{}},
+			},
+			expectedErr: true,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			err := validateScheduledReportBudget(test.budget)
+			if test.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestExceededNamespaceBudgets(t *testing.T) {
+	budget := &cbTypes.ScheduledReportBudget{
+		CostColumn: "total_cost",
+		Amount:     100,
+		Namespaces: map[string]float64{"team-b": 500},
+	}
+	results := []presto.Row{
+		{"namespace": "team-c", "pod": "web", "total_cost": 80.0},
+		{"namespace": "team-c", "pod": "db", "total_cost": 40.0},
+		{"namespace": "team-a", "pod": "web", "total_cost": 101.0},
+		{"namespace": "team-b", "pod": "web", "total_cost": 400.0},
+		{"namespace": "team-d", "pod": "web", "total_cost": 100.0},
+		{"namespace": "", "pod": "", "total_cost": 1000.0},
+	}
+
+	exceeded, err := exceededNamespaceBudgets(budget, nil, results)
+	require.NoError(t, err)
+	assert.Equal(t, []cbTypes.BudgetNamespaceStatus{
+		{Namespace: "team-a", Cost: 101, Amount: 100},
+		{Namespace: "team-c", Cost: 120, Amount: 100},
+	}, exceeded)
+
+	// only the latest period of results with a period_end is used
+	columns := []cbTypes.ReportGenerationQueryColumn{{Name: "period_end"}, {Name: "namespace"}, {Name: "total_cost"}}
+	periodResults := []presto.Row{
+		{"period_end": time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC), "namespace": "team-a", "total_cost": 150.0},
+		{"period_end": time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC), "namespace": "team-a", "total_cost": 90.0},
+		{"period_end": time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC), "namespace": "team-c", "total_cost": 110.0},
+	}
+	exceeded, err = exceededNamespaceBudgets(budget, columns, periodResults)
+	require.NoError(t, err)
+	assert.Equal(t, []cbTypes.BudgetNamespaceStatus{
+		{Namespace: "team-c", Cost: 110, Amount: 100},
+	}, exceeded)
+
+	budget.NamespaceColumn = "project"
+	_, err = exceededNamespaceBudgets(budget, nil, results)
+	assert.Error(t, err, "namespace column must be in the results")
+
+	budget.NamespaceColumn = ""
+	budget.CostColumn = "pod"
+	_, err = exceededNamespaceBudgets(budget, nil, results)
+	assert.Error(t, err, "cost column must be numeric")
+}
+
+func TestScaleResourceList(t *testing.T) {
+	hard := v1.ResourceList{
+		v1.ResourceRequestsCPU:    resource.MustParse("10"),
+		v1.ResourceRequestsMemory: resource.MustParse("64Gi"),
+		v1.ResourcePods:           resource.MustParse("5"),
+		v1.ResourceLimitsCPU:      resource.MustParse("500m"),
+	}
+	scaled := scaleResourceList(hard, 0.5)
+
+	expected := map[v1.ResourceName]string{
+		v1.ResourceRequestsCPU:    "5",
+		v1.ResourceRequestsMemory: "32Gi",
+		v1.ResourcePods:           "2",
+		v1.ResourceLimitsCPU:      "250m",
+	}
+	require.Len(t, scaled, len(expected))
+	for name, quantity := range expected {
+		actual := scaled[name]
+		assert.Equal(t, 0, actual.Cmp(resource.MustParse(quantity)), "expected %s to be %s, got %s", name, quantity, actual.String())
+	}
+	// the original limits are unchanged
+	original := hard[v1.ResourcePods]
+	assert.Equal(t, int64(5), original.Value())
+}
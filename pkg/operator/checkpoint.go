@@ -0,0 +1,110 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+const (
+	// maxCheckpointAttempts is the number of times generating a single
+	// sub-range of a checkpointed report is attempted before failing.
+	maxCheckpointAttempts = 3
+	// checkpointRetryBackoff is multiplied by the attempt number to determine
+	// how long to wait before retrying a failed sub-range.
+	checkpointRetryBackoff = 30 * time.Second
+)
+
+// checkpointFunc is called with the end of each sub-range after it has been
+// stored.
+type checkpointFunc func(checkpoint time.Time) error
+
+// generateReportCheckpointed generates a report by splitting the period
+// between reportStart and reportEnd into sub-ranges of checkpointInterval,
+// generating and storing each independently. After each sub-range is stored
+// onCheckpoint is called, allowing the caller to persist how far the report
+// has progressed so that it can be resumed from that point. dropTable and
+// deleteExistingData only apply to the first sub-range, since later
+// sub-ranges are appended to the results of earlier ones.
+//
+// Views and materialized views are recreated in full on every run, so they
+// are generated without checkpoints.
+func (op *Reporting) generateReportCheckpointed(ctx context.Context, logger log.FieldLogger, report runtime.Object, reportKind, reportName, tableName string, reportStart, reportEnd time.Time, checkpointInterval time.Duration, storage *cbTypes.StorageLocationRef, generationQuery *cbTypes.ReportGenerationQuery, dropTable, deleteExistingData bool, onCheckpoint checkpointFunc) error {
+	materialization, err := getReportMaterializationPolicy(generationQuery)
+	if err != nil {
+		return err
+	}
+	if materialization != cbTypes.ReportMaterializationTable {
+		logger.Warnf("checkpointing is not supported with %s materialization, generating the entire report at once", materialization)
+		return op.generateReport(ctx, logger, report, reportKind, reportName, tableName, reportStart, reportEnd, storage, generationQuery, dropTable, deleteExistingData)
+	}
+
+	subRanges := getCheckpointRanges(reportStart, reportEnd, checkpointInterval)
+	for i, subRange := range subRanges {
+		subRangeLogger := logger.WithFields(log.Fields{
+			"subRangeStart": subRange.periodStart,
+			"subRangeEnd":   subRange.periodEnd,
+		})
+		subRangeLogger.Infof("generating sub-range %d of %d", i+1, len(subRanges))
+
+		for attempt := 1; ; attempt++ {
+			err = op.generateReport(ctx, subRangeLogger, report, reportKind, reportName, tableName, subRange.periodStart, subRange.periodEnd, storage, generationQuery, dropTable, deleteExistingData)
+			if err == nil {
+				break
+			}
+			if attempt >= maxCheckpointAttempts || ctx.Err() != nil {
+				return fmt.Errorf("failed to generate sub-range [%s to %s] after %d attempts: %v", subRange.periodStart, subRange.periodEnd, attempt, err)
+			}
+			backoff := checkpointRetryBackoff * time.Duration(attempt)
+			subRangeLogger.WithError(err).Warnf("failed to generate sub-range, retrying in %s", backoff)
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("failed to generate sub-range [%s to %s] after %d attempts: %v", subRange.periodStart, subRange.periodEnd, attempt, ctx.Err())
+			case <-op.clock.After(backoff):
+			}
+		}
+
+		// only the first sub-range should replace existing results
+		dropTable = false
+		deleteExistingData = false
+
+		err = onCheckpoint(subRange.periodEnd)
+		if err != nil {
+			return fmt.Errorf("unable to record checkpoint %s: %v", subRange.periodEnd, err)
+		}
+	}
+	return nil
+}
+
+// getCheckpointRanges splits the period between start and end into
+// consecutive sub-ranges of interval. The final sub-range ends at end, and
+// may be shorter than interval.
+func getCheckpointRanges(start, end time.Time, interval time.Duration) []reportPeriod {
+	if interval <= 0 {
+		return []reportPeriod{{periodStart: start, periodEnd: end}}
+	}
+	var ranges []reportPeriod
+	for subRangeStart := start; subRangeStart.Before(end); subRangeStart = subRangeStart.Add(interval) {
+		subRangeEnd := subRangeStart.Add(interval)
+		if subRangeEnd.After(end) {
+			subRangeEnd = end
+		}
+		ranges = append(ranges, reportPeriod{periodStart: subRangeStart, periodEnd: subRangeEnd})
+	}
+	return ranges
+}
+
+// getCheckpointResumeTime returns the time to resume generating a report
+// from if checkpoint is between start and end, and true, or start and false
+// if there is nothing to resume.
+func getCheckpointResumeTime(checkpoint *time.Time, start, end time.Time) (time.Time, bool) {
+	if checkpoint == nil || !checkpoint.After(start) || !checkpoint.Before(end) {
+		return start, false
+	}
+	return *checkpoint, true
+}
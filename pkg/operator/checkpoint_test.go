@@ -0,0 +1,81 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCheckpointRanges(t *testing.T) {
+	start := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		end            time.Time
+		interval       time.Duration
+		expectedRanges []reportPeriod
+	}{
+		"evenly divisible": {
+			end:      start.Add(3 * time.Hour),
+			interval: time.Hour,
+			expectedRanges: []reportPeriod{
+				{periodStart: start, periodEnd: start.Add(time.Hour)},
+				{periodStart: start.Add(time.Hour), periodEnd: start.Add(2 * time.Hour)},
+				{periodStart: start.Add(2 * time.Hour), periodEnd: start.Add(3 * time.Hour)},
+			},
+		},
+		"final sub-range is shorter": {
+			end:      start.Add(90 * time.Minute),
+			interval: time.Hour,
+			expectedRanges: []reportPeriod{
+				{periodStart: start, periodEnd: start.Add(time.Hour)},
+				{periodStart: start.Add(time.Hour), periodEnd: start.Add(90 * time.Minute)},
+			},
+		},
+		"interval longer than period": {
+			end:      start.Add(30 * time.Minute),
+			interval: time.Hour,
+			expectedRanges: []reportPeriod{
+				{periodStart: start, periodEnd: start.Add(30 * time.Minute)},
+			},
+		},
+		"zero interval": {
+			end:      start.Add(3 * time.Hour),
+			interval: 0,
+			expectedRanges: []reportPeriod{
+				{periodStart: start, periodEnd: start.Add(3 * time.Hour)},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedRanges, getCheckpointRanges(start, tt.end, tt.interval))
+		})
+	}
+}
+
+func TestGetCheckpointResumeTime(t *testing.T) {
+	start := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	within := start.Add(6 * time.Hour)
+	before := start.Add(-time.Hour)
+
+	resumeTime, resuming := getCheckpointResumeTime(nil, start, end)
+	assert.False(t, resuming)
+	assert.Equal(t, start, resumeTime)
+
+	resumeTime, resuming = getCheckpointResumeTime(&within, start, end)
+	assert.True(t, resuming)
+	assert.Equal(t, within, resumeTime)
+
+	// checkpoints from a previous period are ignored
+	resumeTime, resuming = getCheckpointResumeTime(&before, start, end)
+	assert.False(t, resuming)
+	assert.Equal(t, start, resumeTime)
+
+	resumeTime, resuming = getCheckpointResumeTime(&end, start, end)
+	assert.False(t, resuming)
+	assert.Equal(t, start, resumeTime)
+}
@@ -0,0 +1,116 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+
+	promapi "github.com/prometheus/client_golang/api"
+	prom "github.com/prometheus/client_golang/api/prometheus/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/promquery"
+	"github.com/operator-framework/operator-metering/pkg/secrets"
+)
+
+// RemoteCluster is another cluster whose Prometheus metrics are imported
+// into the reporting-operator's ReportDataSource tables.
+type RemoteCluster struct {
+	// ID identifies the cluster in the cluster_id label of its metrics.
+	ID string `json:"id"`
+	// PrometheusURL is the URL of the cluster's Prometheus.
+	PrometheusURL string `json:"prometheusURL"`
+	// PrometheusBearerToken is a secret reference in the form
+	// <provider>://<path> containing the token key used to authenticate
+	// with the cluster's Prometheus.
+	PrometheusBearerToken string `json:"prometheusBearerTokenSecret,omitempty"`
+}
+
+// ParseRemoteClusters parses a JSON list of RemoteClusters.
+func ParseRemoteClusters(s string) ([]RemoteCluster, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var clusters []RemoteCluster
+	if err := json.Unmarshal([]byte(s), &clusters); err != nil {
+		return nil, fmt.Errorf("invalid remote clusters: %v", err)
+	}
+	return clusters, nil
+}
+
+// validateClusters checks every remote cluster has a unique ID, and that the
+// local cluster has an ID if there are remote clusters, so that the metrics
+// of each cluster can be told apart.
+func (cfg *Config) validateClusters() error {
+	if len(cfg.RemoteClusters) == 0 {
+		return nil
+	}
+	if cfg.ClusterID == "" {
+		return fmt.Errorf("a cluster ID must be set to import metrics from remote clusters")
+	}
+	ids := map[string]bool{cfg.ClusterID: true}
+	for i, cluster := range cfg.RemoteClusters {
+		if cluster.ID == "" {
+			return fmt.Errorf("remote cluster %d must have an id", i)
+		}
+		if ids[cluster.ID] {
+			return fmt.Errorf("remote cluster id %s is used by more than one cluster", cluster.ID)
+		}
+		ids[cluster.ID] = true
+		if cluster.PrometheusURL == "" {
+			return fmt.Errorf("remote cluster %s must have a prometheusURL", cluster.ID)
+		}
+		if cluster.PrometheusBearerToken != "" {
+			if _, err := secrets.ParseRef(cluster.PrometheusBearerToken); err != nil {
+				return fmt.Errorf("invalid Prometheus bearer token for remote cluster %s: %v", cluster.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// prometheusCluster is a cluster whose Prometheus the PrometheusImporter
+// imports metrics from.
+type prometheusCluster struct {
+	// id is empty if the local cluster doesn't have an ID.
+	id       string
+	remote   bool
+	promConn prom.API
+	promAPI  promquery.API
+}
+
+// newRemotePrometheusClusters creates the clients for the Prometheus of each
+// remote cluster.
+func (op *Reporting) newRemotePrometheusClusters() ([]prometheusCluster, error) {
+	var clusters []prometheusCluster
+	for _, cluster := range op.cfg.RemoteClusters {
+		promConfig := promapi.Config{Address: cluster.PrometheusURL}
+		if cluster.PrometheusBearerToken != "" {
+			ref, err := secrets.ParseRef(cluster.PrometheusBearerToken)
+			if err != nil {
+				return nil, err
+			}
+			promConfig.RoundTripper = secrets.NewBearerTokenRoundTripper(op.secretResolver, ref, nil)
+		}
+		promClient, err := op.newPrometheusClient(promConfig)
+		if err != nil {
+			return nil, fmt.Errorf("remote cluster %s: %v", cluster.ID, err)
+		}
+		clusters = append(clusters, prometheusCluster{
+			id:       cluster.ID,
+			remote:   true,
+			promConn: prom.NewAPI(promClient),
+			promAPI:  promquery.NewAPI(promClient),
+		})
+		op.logger.Infof("importing Prometheus metrics from remote cluster %s at %s", cluster.ID, cluster.PrometheusURL)
+	}
+	return clusters, nil
+}
+
+// prometheusImporterKey identifies the PrometheusImporter of a
+// ReportDataSource for a cluster. The local cluster's importer is keyed by
+// the ReportDataSource's name.
+func prometheusImporterKey(dataSourceName string, cluster prometheusCluster) string {
+	if !cluster.remote {
+		return dataSourceName
+	}
+	return dataSourceName + "@" + cluster.id
+}
@@ -0,0 +1,94 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRemoteClusters(t *testing.T) {
+	clusters, err := ParseRemoteClusters("")
+	require.NoError(t, err)
+	assert.Empty(t, clusters)
+
+	clusters, err = ParseRemoteClusters(`[{"id": "eu-west", "prometheusURL": "https://prometheus.eu-west.example.com", "prometheusBearerTokenSecret": "kubernetes://metering/eu-west-prometheus"}]`)
+	require.NoError(t, err)
+	assert.Equal(t, []RemoteCluster{
+		{
+			ID:                    "eu-west",
+			PrometheusURL:         "https://prometheus.eu-west.example.com",
+			PrometheusBearerToken: "kubernetes://metering/eu-west-prometheus",
+		},
+	}, clusters)
+
+	_, err = ParseRemoteClusters(`{"id": "eu-west"}`)
+	assert.Error(t, err)
+}
+
+func TestValidateClusters(t *testing.T) {
+	tests := map[string]struct {
+		cfg         Config
+		expectedErr bool
+	}{
+		"no remote clusters": {
+			cfg: Config{},
+		},
+		"remote clusters": {
+			cfg: Config{
+				ClusterID: "us-east",
+				RemoteClusters: []RemoteCluster{
+					{ID: "eu-west", PrometheusURL: "https://prometheus.eu-west.example.com"},
+					{ID: "ap-south", PrometheusURL: "https://prometheus.ap-south.example.com", PrometheusBearerToken: "kubernetes://metering/ap-south-prometheus"},
+				},
+			},
+		},
+		"no local cluster id": {
+			cfg: Config{
+				RemoteClusters: []RemoteCluster{{ID: "eu-west", PrometheusURL: "https://prometheus.eu-west.example.com"}},
+			},
+			expectedErr: true,
+		},
+		"remote cluster without id": {
+			cfg: Config{
+				ClusterID:      "us-east",
+				RemoteClusters: []RemoteCluster{{PrometheusURL: "https://prometheus.eu-west.example.com"}},
+			},
+			expectedErr: true,
+		},
+		"remote cluster using local cluster id": {
+			cfg: Config{
+				ClusterID:      "us-east",
+				RemoteClusters: []RemoteCluster{{ID: "us-east", PrometheusURL: "https://prometheus.eu-west.example.com"}},
+			},
+			expectedErr: true,
+		},
+		"remote cluster without prometheusURL": {
+			cfg: Config{
+				ClusterID:      "us-east",
+				RemoteClusters: []RemoteCluster{{ID: "eu-west"}},
+			},
+			expectedErr: true,
+		},
+		"invalid bearer token secret": {
+			cfg: Config{
+				ClusterID:      "us-east",
+				RemoteClusters: []RemoteCluster{{ID: "eu-west", PrometheusURL: "https://prometheus.eu-west.example.com", PrometheusBearerToken: "eu-west-prometheus"}},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			err := tt.cfg.validateClusters()
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
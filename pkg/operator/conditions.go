@@ -0,0 +1,178 @@
+package operator
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+)
+
+// conditionWarningReasons are the reasons of conditions which indicate a
+// failure, which are recorded as Warning events.
+var conditionWarningReasons = map[string]bool{
+	cbutil.ReportFailedReason:           true,
+	cbutil.RetryScheduledReason:         true,
+	cbutil.TableCreationFailedReason:    true,
+	cbutil.ImportFailedReason:           true,
+	cbutil.InvalidDependenciesReason:    true,
+	cbutil.ViewCreationFailedReason:     true,
+	cbutil.InvalidStorageLocationReason: true,
+	cbutil.AssertionsFailedReason:       true,
+}
+
+// recordConditionTransition records an event on obj when the status of its
+// condType condition changes from previous, which is empty if the condition
+// wasn't set. Conditions which are initially False for reasons other than a
+// failure, such as a Report which hasn't run yet, aren't recorded.
+func (op *Reporting) recordConditionTransition(obj runtime.Object, condType string, previous, status v1.ConditionStatus, reason, msg string) {
+	if previous == status {
+		return
+	}
+	warning := conditionWarningReasons[reason]
+	if previous == "" && status != v1.ConditionTrue && !warning {
+		return
+	}
+	eventType := v1.EventTypeNormal
+	if warning {
+		eventType = v1.EventTypeWarning
+	}
+	op.eventRecorder.Eventf(obj, eventType, reason, "%s is %s: %s", condType, status, msg)
+}
+
+// setReportConditions sets the Ready, Running and DataComplete conditions
+// of the report from its phase and dependencies, and the ResultsValid
+// condition from its assertions.
+func setReportConditions(report *cbTypes.Report) {
+	status := &report.Status
+	var notReady []string
+	for _, dependency := range status.Dependencies {
+		if !dependency.Ready {
+			notReady = append(notReady, fmt.Sprintf("%s %s", dependency.Kind, dependency.Name))
+		}
+	}
+	if len(status.Dependencies) != 0 {
+		if len(notReady) == 0 {
+			cbutil.SetReportCondition(status, *cbutil.NewReportCondition(cbTypes.ReportDataComplete, v1.ConditionTrue, cbutil.DependenciesReadyReason, "all dependencies have data for the reporting period"))
+		} else {
+			cbutil.SetReportCondition(status, *cbutil.NewReportCondition(cbTypes.ReportDataComplete, v1.ConditionFalse, cbutil.DependenciesNotReadyReason, fmt.Sprintf("the following dependencies do not have data for the reporting period: %s", strings.Join(notReady, ", "))))
+		}
+	}
+
+	readyStatus := v1.ConditionFalse
+	var reason, msg string
+	switch {
+	case status.Phase == cbTypes.ReportPhaseFinished:
+		readyStatus, reason, msg = v1.ConditionTrue, cbutil.ReportFinishedReason, "report has finished and its results are available"
+	case status.Phase == cbTypes.ReportPhaseError:
+		reason, msg = cbutil.ReportFailedReason, status.Output
+	case status.Phase == cbTypes.ReportPhaseStarted:
+		reason, msg = cbutil.ReportRunningReason, "report is running"
+	case status.NextRetryTime != nil:
+		reason, msg = cbutil.RetryScheduledReason, status.Output
+	case len(notReady) != 0:
+		reason, msg = cbutil.DependenciesNotReadyReason, "waiting for the report's dependencies to have data for the reporting period"
+	default:
+		reason, msg = cbutil.ReportPendingReason, "report has not run yet"
+	}
+	cbutil.SetReportCondition(status, *cbutil.NewReportCondition(cbTypes.ReportReady, readyStatus, reason, msg))
+
+	runningStatus := v1.ConditionFalse
+	if status.Phase == cbTypes.ReportPhaseStarted {
+		runningStatus = v1.ConditionTrue
+	}
+	cbutil.SetReportCondition(status, *cbutil.NewReportCondition(cbTypes.ReportRunning, runningStatus, reason, msg))
+
+	if len(status.Assertions) != 0 {
+		resultsStatus, reason, msg := reportAssertionsCondition(status.Assertions)
+		cbutil.SetReportCondition(status, *cbutil.NewReportCondition(cbTypes.ReportResultsValid, resultsStatus, reason, msg))
+	}
+}
+
+// updateReport sets the conditions of the report from its status and
+// updates it, recording events when its Ready, DataComplete or
+// ResultsValid conditions change.
+func (op *Reporting) updateReport(report *cbTypes.Report) (*cbTypes.Report, error) {
+	previous := make(map[cbTypes.ReportConditionType]v1.ConditionStatus)
+	for _, cond := range report.Status.Conditions {
+		previous[cond.Type] = cond.Status
+	}
+	setReportConditions(report)
+	newReport, err := op.meteringClient.MeteringV1alpha1().Reports(report.Namespace).Update(report)
+	if err != nil {
+		return nil, err
+	}
+	for _, condType := range []cbTypes.ReportConditionType{cbTypes.ReportReady, cbTypes.ReportDataComplete, cbTypes.ReportResultsValid} {
+		if cond := cbutil.GetReportCondition(newReport.Status, condType); cond != nil {
+			op.recordConditionTransition(newReport, string(condType), previous[condType], cond.Status, cond.Reason, cond.Message)
+		}
+	}
+	return newReport, nil
+}
+
+// updateDataSourceCondition sets the condition on the dataSource and
+// updates it if the condition changed, recording an event if its status
+// changed.
+func (op *Reporting) updateDataSourceCondition(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource, condition cbTypes.ReportDataSourceCondition) error {
+	var previous v1.ConditionStatus
+	current := cbutil.GetReportDataSourceCondition(dataSource, condition.Type)
+	if current != nil {
+		if current.Status == condition.Status && current.Reason == condition.Reason && current.Message == condition.Message {
+			return nil
+		}
+		previous = current.Status
+	}
+	cbutil.SetReportDataSourceCondition(dataSource, condition)
+	_, err := op.meteringClient.MeteringV1alpha1().ReportDataSources(dataSource.Namespace).Update(dataSource)
+	if err != nil {
+		logger.WithError(err).Errorf("failed to update ReportDataSource %s condition for %q", condition.Type, dataSource.Name)
+		return err
+	}
+	op.recordConditionTransition(dataSource, string(condition.Type), previous, condition.Status, condition.Reason, condition.Message)
+	return nil
+}
+
+// updateDataSourceImportCondition sets the LastImportSucceeded condition of
+// the ReportDataSource to the result of its most recent import, and notifies
+// the reports waiting on it if the import succeeded.
+func (op *Reporting) updateDataSourceImportCondition(logger log.FieldLogger, namespace, name string, importErr error) {
+	dataSource, err := op.informers.Metering().V1alpha1().ReportDataSources().Lister().ReportDataSources(namespace).Get(name)
+	if err != nil {
+		logger.WithError(err).Warnf("unable to get ReportDataSource %s to record the result of its import", name)
+		return
+	}
+	condition := cbutil.NewReportDataSourceCondition(cbTypes.ReportDataSourceLastImportSucceeded, v1.ConditionTrue, cbutil.ImportSucceededReason, "the most recent import succeeded")
+	if importErr != nil {
+		condition = cbutil.NewReportDataSourceCondition(cbTypes.ReportDataSourceLastImportSucceeded, v1.ConditionFalse, cbutil.ImportFailedReason, importErr.Error())
+	}
+	op.updateDataSourceCondition(logger, dataSource.DeepCopy(), *condition)
+	if importErr == nil {
+		op.notifyReportDependents(namespace, dependencyKindReportDataSource, name)
+	}
+}
+
+// updateGenerationQueryCondition sets the condition on the generationQuery
+// and updates it if the condition changed, recording an event if its
+// status changed.
+func (op *Reporting) updateGenerationQueryCondition(logger log.FieldLogger, generationQuery *cbTypes.ReportGenerationQuery, condition cbTypes.ReportGenerationQueryCondition) (*cbTypes.ReportGenerationQuery, error) {
+	var previous v1.ConditionStatus
+	current := cbutil.GetReportGenerationQueryCondition(generationQuery, condition.Type)
+	if current != nil {
+		if current.Status == condition.Status && current.Reason == condition.Reason && current.Message == condition.Message {
+			return generationQuery, nil
+		}
+		previous = current.Status
+	}
+	cbutil.SetReportGenerationQueryCondition(generationQuery, condition)
+	newGenerationQuery, err := op.meteringClient.MeteringV1alpha1().ReportGenerationQueries(generationQuery.Namespace).Update(generationQuery)
+	if err != nil {
+		logger.WithError(err).Errorf("failed to update ReportGenerationQuery %s condition for %q", condition.Type, generationQuery.Name)
+		return nil, err
+	}
+	op.recordConditionTransition(newGenerationQuery, string(condition.Type), previous, condition.Status, condition.Reason, condition.Message)
+	return newGenerationQuery, nil
+}
@@ -0,0 +1,178 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+)
+
+func TestSetReportConditions(t *testing.T) {
+	readyDependency := cbTypes.ReportDependencyStatus{Kind: "ReportDataSource", Name: "pod-request-cpu-cores", Ready: true}
+	notReadyDependency := cbTypes.ReportDependencyStatus{Kind: "ReportDataSource", Name: "pod-usage-cpu-cores", Message: "no data"}
+
+	tests := map[string]struct {
+		status               cbTypes.ReportStatus
+		expectedReady        v1.ConditionStatus
+		expectedReason       string
+		expectedRunning      v1.ConditionStatus
+		expectedDataComplete v1.ConditionStatus
+	}{
+		"pending": {
+			status:               cbTypes.ReportStatus{},
+			expectedReady:        v1.ConditionFalse,
+			expectedReason:       cbutil.ReportPendingReason,
+			expectedRunning:      v1.ConditionFalse,
+			expectedDataComplete: "",
+		},
+		"waiting for dependencies": {
+			status: cbTypes.ReportStatus{
+				Phase:        cbTypes.ReportPhaseWaiting,
+				Dependencies: []cbTypes.ReportDependencyStatus{readyDependency, notReadyDependency},
+			},
+			expectedReady:        v1.ConditionFalse,
+			expectedReason:       cbutil.DependenciesNotReadyReason,
+			expectedRunning:      v1.ConditionFalse,
+			expectedDataComplete: v1.ConditionFalse,
+		},
+		"running": {
+			status: cbTypes.ReportStatus{
+				Phase:        cbTypes.ReportPhaseStarted,
+				Dependencies: []cbTypes.ReportDependencyStatus{readyDependency},
+			},
+			expectedReady:        v1.ConditionFalse,
+			expectedReason:       cbutil.ReportRunningReason,
+			expectedRunning:      v1.ConditionTrue,
+			expectedDataComplete: v1.ConditionTrue,
+		},
+		"retry scheduled": {
+			status: cbTypes.ReportStatus{
+				Phase:         cbTypes.ReportPhaseWaiting,
+				Output:        "query failed, retrying",
+				NextRetryTime: &meta.Time{},
+			},
+			expectedReady:   v1.ConditionFalse,
+			expectedReason:  cbutil.RetryScheduledReason,
+			expectedRunning: v1.ConditionFalse,
+		},
+		"failed": {
+			status: cbTypes.ReportStatus{
+				Phase:  cbTypes.ReportPhaseError,
+				Output: "query failed",
+			},
+			expectedReady:   v1.ConditionFalse,
+			expectedReason:  cbutil.ReportFailedReason,
+			expectedRunning: v1.ConditionFalse,
+		},
+		"finished": {
+			status: cbTypes.ReportStatus{
+				Phase:        cbTypes.ReportPhaseFinished,
+				Dependencies: []cbTypes.ReportDependencyStatus{readyDependency},
+			},
+			expectedReady:        v1.ConditionTrue,
+			expectedReason:       cbutil.ReportFinishedReason,
+			expectedRunning:      v1.ConditionFalse,
+			expectedDataComplete: v1.ConditionTrue,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			report := &cbTypes.Report{Status: tt.status}
+			setReportConditions(report)
+
+			ready := cbutil.GetReportCondition(report.Status, cbTypes.ReportReady)
+			require.NotNil(t, ready)
+			assert.Equal(t, tt.expectedReady, ready.Status)
+			assert.Equal(t, tt.expectedReason, ready.Reason)
+
+			running := cbutil.GetReportCondition(report.Status, cbTypes.ReportRunning)
+			require.NotNil(t, running)
+			assert.Equal(t, tt.expectedRunning, running.Status)
+
+			dataComplete := cbutil.GetReportCondition(report.Status, cbTypes.ReportDataComplete)
+			if tt.expectedDataComplete == "" {
+				assert.Nil(t, dataComplete)
+			} else {
+				require.NotNil(t, dataComplete)
+				assert.Equal(t, tt.expectedDataComplete, dataComplete.Status)
+			}
+		})
+	}
+}
+
+func TestValidateStorageLocationSpec(t *testing.T) {
+	tests := map[string]struct {
+		spec      cbTypes.StorageLocationSpec
+		expectErr bool
+	}{
+		"missing hive": {
+			spec:      cbTypes.StorageLocationSpec{},
+			expectErr: true,
+		},
+		"default location": {
+			spec: cbTypes.StorageLocationSpec{Hive: &cbTypes.HiveStorage{}},
+		},
+		"s3 location": {
+			spec: cbTypes.StorageLocationSpec{Hive: &cbTypes.HiveStorage{
+				TableProperties: cbTypes.TableProperties{Location: "s3a://bucket/metering"},
+			}},
+		},
+		"gcs location": {
+			spec: cbTypes.StorageLocationSpec{Hive: &cbTypes.HiveStorage{
+				TableProperties: cbTypes.TableProperties{Location: "gs://bucket/metering"},
+			}},
+		},
+		"abfss location": {
+			spec: cbTypes.StorageLocationSpec{Hive: &cbTypes.HiveStorage{
+				TableProperties: cbTypes.TableProperties{Location: "abfss://metering@account.dfs.core.windows.net/storage"},
+			}},
+		},
+		"abfs location without a container": {
+			spec: cbTypes.StorageLocationSpec{Hive: &cbTypes.HiveStorage{
+				TableProperties: cbTypes.TableProperties{Location: "abfs://account.dfs.core.windows.net/storage"},
+			}},
+			expectErr: true,
+		},
+		"gcs location without a bucket": {
+			spec: cbTypes.StorageLocationSpec{Hive: &cbTypes.HiveStorage{
+				TableProperties: cbTypes.TableProperties{Location: "gs:///metering"},
+			}},
+			expectErr: true,
+		},
+		"unsupported filesystem": {
+			spec: cbTypes.StorageLocationSpec{Hive: &cbTypes.HiveStorage{
+				TableProperties: cbTypes.TableProperties{Location: "ftp://example.com/metering"},
+			}},
+			expectErr: true,
+		},
+		"invalid location": {
+			spec: cbTypes.StorageLocationSpec{Hive: &cbTypes.HiveStorage{
+				TableProperties: cbTypes.TableProperties{Location: "s3a://bucket/%zz"},
+			}},
+			expectErr: true,
+		},
+		"invalid encryption": {
+			spec: cbTypes.StorageLocationSpec{Hive: &cbTypes.HiveStorage{
+				TableProperties: cbTypes.TableProperties{Location: "hdfs://namenode:9820/metering"},
+				Encryption:      &cbTypes.HiveStorageEncryption{S3KMSKeyID: "alias/metering"},
+			}},
+			expectErr: true,
+		},
+	}
+	for name, tt := range tests {
+		err := validateStorageLocationSpec(tt.spec)
+		if tt.expectErr {
+			assert.Error(t, err, name)
+		} else {
+			assert.NoError(t, err, name)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package operator
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricResultSuccess = "success"
+	metricResultError   = "error"
+	metricResultTimeout = "timeout"
+)
+
+var (
+	reconcileDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "metering",
+			Name:      "reconcile_duration_seconds",
+			Help:      "How long syncing a resource taken from a workqueue takes, by the kind of resource and whether the sync succeeded.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 9),
+		},
+		[]string{"kind", "result"},
+	)
+	reportRunDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "metering",
+			Name:      "report_run_duration_seconds",
+			Help:      "How long generating the results of a Report or a ScheduledReport's period takes, by the kind of report and whether it succeeded, failed or timed out.",
+			Buckets:   prometheus.ExponentialBuckets(1, 3, 10),
+		},
+		[]string{"kind", "result"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(reconcileDurationHistogram)
+	prometheus.MustRegister(reportRunDurationHistogram)
+}
+
+// observeReconcile records the duration and result of syncing a resource
+// of kind which started at start.
+func (op *Reporting) observeReconcile(kind string, start time.Time, err error) {
+	result := metricResultSuccess
+	if err != nil {
+		result = metricResultError
+	}
+	reconcileDurationHistogram.WithLabelValues(kind, result).Observe(op.clock.Since(start).Seconds())
+}
+
+// observeReportRun records the duration and result of a run of a report of
+// kind which started at start.
+func (op *Reporting) observeReportRun(kind string, start time.Time, err error, timedOut bool) {
+	result := metricResultSuccess
+	switch {
+	case timedOut:
+		result = metricResultTimeout
+	case err != nil:
+		result = metricResultError
+	}
+	reportRunDurationHistogram.WithLabelValues(kind, result).Observe(op.clock.Since(start).Seconds())
+}
@@ -0,0 +1,62 @@
+package operator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// histogramSample returns the sample count and sum of the histogram's
+// series with the labels.
+func histogramSample(t *testing.T, vec *prometheus.HistogramVec, labels ...string) (uint64, float64) {
+	var m dto.Metric
+	require.NoError(t, vec.WithLabelValues(labels...).(prometheus.Metric).Write(&m))
+	return m.Histogram.GetSampleCount(), m.Histogram.GetSampleSum()
+}
+
+func TestObserveReportRun(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC))
+	op := &Reporting{clock: fakeClock}
+
+	tests := []struct {
+		err            error
+		timedOut       bool
+		expectedResult string
+	}{
+		{expectedResult: metricResultSuccess},
+		{err: errors.New("query failed"), expectedResult: metricResultError},
+		{err: errors.New("query cancelled"), timedOut: true, expectedResult: metricResultTimeout},
+	}
+	for _, tt := range tests {
+		countBefore, sumBefore := histogramSample(t, reportRunDurationHistogram, "Report", tt.expectedResult)
+		start := fakeClock.Now()
+		fakeClock.Step(90 * time.Second)
+		op.observeReportRun("Report", start, tt.err, tt.timedOut)
+
+		count, sum := histogramSample(t, reportRunDurationHistogram, "Report", tt.expectedResult)
+		assert.Equal(t, countBefore+1, count, tt.expectedResult)
+		assert.Equal(t, sumBefore+90, sum, tt.expectedResult)
+	}
+}
+
+func TestObserveReconcile(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC))
+	op := &Reporting{clock: fakeClock}
+
+	successBefore, _ := histogramSample(t, reconcileDurationHistogram, "StorageLocation", metricResultSuccess)
+	errorBefore, _ := histogramSample(t, reconcileDurationHistogram, "StorageLocation", metricResultError)
+	op.observeReconcile("StorageLocation", fakeClock.Now(), nil)
+	op.observeReconcile("StorageLocation", fakeClock.Now(), errors.New("update failed"))
+	op.observeReconcile("StorageLocation", fakeClock.Now(), errors.New("update failed"))
+
+	success, _ := histogramSample(t, reconcileDurationHistogram, "StorageLocation", metricResultSuccess)
+	errored, _ := histogramSample(t, reconcileDurationHistogram, "StorageLocation", metricResultError)
+	assert.Equal(t, successBefore+1, success)
+	assert.Equal(t, errorBefore+2, errored)
+}
@@ -0,0 +1,146 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	meteringv1 "github.com/operator-framework/operator-metering/pkg/apis/metering/v1"
+	api "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+const ConversionWebhookEndpoint = "/convert"
+
+// conversionReview mirrors the apiextensions.k8s.io/v1beta1 ConversionReview
+// sent by the Kubernetes API server to CRD conversion webhooks.
+type conversionReview struct {
+	meta.TypeMeta `json:",inline"`
+	Request       *conversionRequest  `json:"request,omitempty"`
+	Response      *conversionResponse `json:"response,omitempty"`
+}
+
+type conversionRequest struct {
+	UID               types.UID              `json:"uid"`
+	DesiredAPIVersion string                 `json:"desiredAPIVersion"`
+	Objects           []runtime.RawExtension `json:"objects"`
+}
+
+type conversionResponse struct {
+	UID              types.UID              `json:"uid"`
+	ConvertedObjects []runtime.RawExtension `json:"convertedObjects"`
+	Result           meta.Status            `json:"result"`
+}
+
+// conversionWebhookHandler converts ReportDataSources, Reports and
+// ScheduledReports between the API versions served by their CRDs.
+func (srv *server) conversionWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	if r.Method != http.MethodPost {
+		writeErrorResponse(logger, w, r, http.StatusMethodNotAllowed, "conversion requests must be POST requests")
+		return
+	}
+
+	var review conversionReview
+	err := json.NewDecoder(r.Body).Decode(&review)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to decode ConversionReview: %v", err)
+		return
+	}
+	if review.Request == nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "ConversionReview request is missing")
+		return
+	}
+
+	desiredAPIVersion := review.Request.DesiredAPIVersion
+	review.Response = convertObjects(review.Request)
+	review.Request = nil
+	if review.Response.Result.Status == meta.StatusFailure {
+		logger.Warnf("unable to convert objects to %s: %s", desiredAPIVersion, review.Response.Result.Message)
+	}
+	writeResponseAsJSON(logger, w, http.StatusOK, review)
+}
+
+func convertObjects(req *conversionRequest) *conversionResponse {
+	resp := &conversionResponse{
+		UID:              req.UID,
+		ConvertedObjects: make([]runtime.RawExtension, len(req.Objects)),
+	}
+	for i, obj := range req.Objects {
+		converted, err := convertObject(obj.Raw, req.DesiredAPIVersion)
+		if err != nil {
+			resp.ConvertedObjects = nil
+			resp.Result = meta.Status{
+				Status:  meta.StatusFailure,
+				Message: err.Error(),
+			}
+			return resp
+		}
+		resp.ConvertedObjects[i] = runtime.RawExtension{Raw: converted}
+	}
+	resp.Result = meta.Status{Status: meta.StatusSuccess}
+	return resp
+}
+
+// convertObject converts the JSON encoded object to the desiredAPIVersion.
+func convertObject(raw []byte, desiredAPIVersion string) ([]byte, error) {
+	var typeMeta meta.TypeMeta
+	err := json.Unmarshal(raw, &typeMeta)
+	if err != nil {
+		return nil, err
+	}
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+
+	v1alpha1Version := api.SchemeGroupVersion.String()
+	v1Version := meteringv1.SchemeGroupVersion.String()
+	var converted interface{}
+	switch {
+	case typeMeta.Kind == "ReportDataSource" && typeMeta.APIVersion == v1alpha1Version && desiredAPIVersion == v1Version:
+		var in api.ReportDataSource
+		if err := json.Unmarshal(raw, &in); err != nil {
+			return nil, err
+		}
+		converted, err = meteringv1.ReportDataSourceFromV1alpha1(&in)
+	case typeMeta.Kind == "ReportDataSource" && typeMeta.APIVersion == v1Version && desiredAPIVersion == v1alpha1Version:
+		var in meteringv1.ReportDataSource
+		if err := json.Unmarshal(raw, &in); err != nil {
+			return nil, err
+		}
+		converted = meteringv1.ReportDataSourceToV1alpha1(&in)
+	case typeMeta.Kind == "Report" && typeMeta.APIVersion == v1alpha1Version && desiredAPIVersion == v1Version:
+		var in api.Report
+		if err := json.Unmarshal(raw, &in); err != nil {
+			return nil, err
+		}
+		converted, err = meteringv1.ReportFromV1alpha1(&in)
+	case typeMeta.Kind == "Report" && typeMeta.APIVersion == v1Version && desiredAPIVersion == v1alpha1Version:
+		var in meteringv1.Report
+		if err := json.Unmarshal(raw, &in); err != nil {
+			return nil, err
+		}
+		converted = meteringv1.ReportToV1alpha1(&in)
+	case typeMeta.Kind == "ScheduledReport" && typeMeta.APIVersion == v1alpha1Version && desiredAPIVersion == v1Version:
+		var in api.ScheduledReport
+		if err := json.Unmarshal(raw, &in); err != nil {
+			return nil, err
+		}
+		converted, err = meteringv1.ScheduledReportFromV1alpha1(&in)
+	case typeMeta.Kind == "ScheduledReport" && typeMeta.APIVersion == v1Version && desiredAPIVersion == v1alpha1Version:
+		var in meteringv1.ScheduledReport
+		if err := json.Unmarshal(raw, &in); err != nil {
+			return nil, err
+		}
+		converted = meteringv1.ScheduledReportToV1alpha1(&in)
+	default:
+		return nil, fmt.Errorf("unable to convert %s %s to %s", typeMeta.APIVersion, typeMeta.Kind, desiredAPIVersion)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(converted)
+}
@@ -0,0 +1,71 @@
+package operator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestConvertObjects(t *testing.T) {
+	tests := map[string]struct {
+		object            string
+		desiredAPIVersion string
+		expectObject      string
+		expectFailure     bool
+	}{
+		"v1alpha1 ReportDataSource to v1": {
+			object:            `{"apiVersion":"metering.openshift.io/v1alpha1","kind":"ReportDataSource","metadata":{"name":"example"},"spec":{"promsum":{"query":"example"}},"tableName":"datasource_example"}`,
+			desiredAPIVersion: "metering.openshift.io/v1",
+			expectObject:      `{"apiVersion":"metering.openshift.io/v1","kind":"ReportDataSource","metadata":{"name":"example","creationTimestamp":null},"spec":{"promsum":{"query":"example","queryConfig":null,"storage":null}},"status":{"tableName":"datasource_example"}}`,
+		},
+		"v1 Report to v1alpha1": {
+			object:            `{"apiVersion":"metering.openshift.io/v1","kind":"Report","metadata":{"name":"example"},"spec":{"reportingStart":null,"reportingEnd":null,"query":"example-query","retention":"1h"},"status":{}}`,
+			desiredAPIVersion: "metering.openshift.io/v1alpha1",
+			expectObject:      `{"apiVersion":"metering.openshift.io/v1alpha1","kind":"Report","metadata":{"name":"example","creationTimestamp":null,"annotations":{"metering.openshift.io/retention":"1h0m0s"}},"spec":{"reportingStart":null,"reportingEnd":null,"generationQuery":"example-query"},"status":{}}`,
+		},
+		"same version": {
+			object:            `{"apiVersion":"metering.openshift.io/v1","kind":"Report"}`,
+			desiredAPIVersion: "metering.openshift.io/v1",
+			expectObject:      `{"apiVersion":"metering.openshift.io/v1","kind":"Report"}`,
+		},
+		"v1alpha1 ScheduledReport to v1": {
+			object:            `{"apiVersion":"metering.openshift.io/v1alpha1","kind":"ScheduledReport","metadata":{"name":"example"},"spec":{"generationQuery":"example-query","schedule":{"period":"daily"}},"status":{}}`,
+			desiredAPIVersion: "metering.openshift.io/v1",
+			expectObject:      `{"apiVersion":"metering.openshift.io/v1","kind":"ScheduledReport","metadata":{"name":"example","creationTimestamp":null},"spec":{"query":"example-query","schedule":{"period":"daily"}},"status":{}}`,
+		},
+		"unsupported kind": {
+			object:            `{"apiVersion":"metering.openshift.io/v1alpha1","kind":"ReportGenerationQuery"}`,
+			desiredAPIVersion: "metering.openshift.io/v1",
+			expectFailure:     true,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			resp := convertObjects(&conversionRequest{
+				UID:               "test-uid",
+				DesiredAPIVersion: tt.desiredAPIVersion,
+				Objects:           []runtime.RawExtension{{Raw: []byte(tt.object)}},
+			})
+			assert.Equal(t, "test-uid", string(resp.UID))
+			if tt.expectFailure {
+				assert.Equal(t, meta.StatusFailure, resp.Result.Status)
+				assert.Empty(t, resp.ConvertedObjects)
+				return
+			}
+			require.Equal(t, meta.StatusSuccess, resp.Result.Status, resp.Result.Message)
+			require.Len(t, resp.ConvertedObjects, 1)
+			assert.JSONEq(t, tt.expectObject, string(resp.ConvertedObjects[0].Raw))
+
+			var typeMeta meta.TypeMeta
+			require.NoError(t, json.Unmarshal(resp.ConvertedObjects[0].Raw, &typeMeta))
+			assert.Equal(t, tt.desiredAPIVersion, typeMeta.APIVersion)
+		})
+	}
+}
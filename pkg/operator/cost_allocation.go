@@ -0,0 +1,82 @@
+package operator
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func getReportCostAllocation(report runtime.Object) *cbTypes.ReportCostAllocation {
+	switch r := report.(type) {
+	case *cbTypes.Report:
+		return r.Spec.CostAllocation
+	case *cbTypes.ScheduledReport:
+		return r.Spec.CostAllocation
+	}
+	return nil
+}
+
+// getCostAllocation validates the cost allocation of a report, and returns
+// it with the defaults applied. Queries supporting cost allocation always
+// get one, so reports which don't set costAllocation use showback.
+func getCostAllocation(generationQuery *cbTypes.ReportGenerationQuery, costAllocation *cbTypes.ReportCostAllocation) (*cbTypes.ReportCostAllocation, error) {
+	if !generationQuery.Spec.SupportsCostAllocation {
+		if costAllocation != nil {
+			return nil, fmt.Errorf("ReportGenerationQuery %s does not support costAllocation", generationQuery.Name)
+		}
+		return nil, nil
+	}
+	allocation := cbTypes.ReportCostAllocation{
+		Mode:             cbTypes.ReportCostAllocationModeShowback,
+		IdleCostStrategy: cbTypes.ReportIdleCostStrategyRequest,
+	}
+	if costAllocation != nil {
+		if costAllocation.Mode != "" {
+			allocation.Mode = costAllocation.Mode
+		}
+		if costAllocation.IdleCostStrategy != "" {
+			allocation.IdleCostStrategy = costAllocation.IdleCostStrategy
+		}
+	}
+	switch allocation.Mode {
+	case cbTypes.ReportCostAllocationModeShowback, cbTypes.ReportCostAllocationModeChargeback:
+	default:
+		return nil, fmt.Errorf("invalid costAllocation mode %q, must be one of: showback, chargeback", allocation.Mode)
+	}
+	switch allocation.IdleCostStrategy {
+	case cbTypes.ReportIdleCostStrategyRequest, cbTypes.ReportIdleCostStrategyUsage, cbTypes.ReportIdleCostStrategyEven:
+	default:
+		return nil, fmt.Errorf("invalid costAllocation idleCostStrategy %q, must be one of: request, usage, even", allocation.IdleCostStrategy)
+	}
+	return &allocation, nil
+}
+
+// AllocatedIdleCost renders an expression for the share of the idle cost in
+// idleCostColumn allocated to each row, using the requests in
+// requestColumn, the usage in usageColumn, or splitting it evenly,
+// depending on the report's idleCostStrategy. The expression uses window
+// functions over every row, so it must be selected from a relation with one
+// row per namespace. In showback mode no idle cost is allocated, eg:
+// SELECT namespace_cost + {| .Report.AllocatedIdleCost "idle_cost" "request" "usage" |} AS total_cost
+func (info *reportTemplateInfo) AllocatedIdleCost(idleCostColumn, requestColumn, usageColumn string) (string, error) {
+	if info.CostAllocation == nil {
+		return "", fmt.Errorf("the report has no costAllocation, the ReportGenerationQuery must set supportsCostAllocation")
+	}
+	if info.CostAllocation.Mode != cbTypes.ReportCostAllocationModeChargeback {
+		return "CAST(0 AS double)", nil
+	}
+	var share string
+	switch info.CostAllocation.IdleCostStrategy {
+	case cbTypes.ReportIdleCostStrategyRequest:
+		share = fmt.Sprintf("coalesce(%[1]s / nullif(sum(%[1]s) OVER (), 0), 0)", requestColumn)
+	case cbTypes.ReportIdleCostStrategyUsage:
+		share = fmt.Sprintf("coalesce(%[1]s / nullif(sum(%[1]s) OVER (), 0), 0)", usageColumn)
+	case cbTypes.ReportIdleCostStrategyEven:
+		share = "1.0 / count(*) OVER ()"
+	default:
+		return "", fmt.Errorf("invalid costAllocation idleCostStrategy %q", info.CostAllocation.IdleCostStrategy)
+	}
+	return fmt.Sprintf("(%s * %s)", idleCostColumn, share), nil
+}
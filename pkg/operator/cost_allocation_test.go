@@ -0,0 +1,128 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestGetCostAllocation(t *testing.T) {
+	newQuery := func(supportsCostAllocation bool) *cbTypes.ReportGenerationQuery {
+		return &cbTypes.ReportGenerationQuery{
+			ObjectMeta: meta.ObjectMeta{Name: "namespace-cpu-cost-aws"},
+			Spec: cbTypes.ReportGenerationQuerySpec{
+				SupportsCostAllocation: supportsCostAllocation,
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		query          *cbTypes.ReportGenerationQuery
+		costAllocation *cbTypes.ReportCostAllocation
+		expected       *cbTypes.ReportCostAllocation
+		expectedErr    string
+	}{
+		"unsupported query without costAllocation": {
+			query: newQuery(false),
+		},
+		"unsupported query": {
+			query:          newQuery(false),
+			costAllocation: &cbTypes.ReportCostAllocation{Mode: cbTypes.ReportCostAllocationModeChargeback},
+			expectedErr:    "ReportGenerationQuery namespace-cpu-cost-aws does not support costAllocation",
+		},
+		"defaults to showback": {
+			query: newQuery(true),
+			expected: &cbTypes.ReportCostAllocation{
+				Mode:             cbTypes.ReportCostAllocationModeShowback,
+				IdleCostStrategy: cbTypes.ReportIdleCostStrategyRequest,
+			},
+		},
+		"chargeback by usage": {
+			query: newQuery(true),
+			costAllocation: &cbTypes.ReportCostAllocation{
+				Mode:             cbTypes.ReportCostAllocationModeChargeback,
+				IdleCostStrategy: cbTypes.ReportIdleCostStrategyUsage,
+			},
+			expected: &cbTypes.ReportCostAllocation{
+				Mode:             cbTypes.ReportCostAllocationModeChargeback,
+				IdleCostStrategy: cbTypes.ReportIdleCostStrategyUsage,
+			},
+		},
+		"invalid mode": {
+			query:          newQuery(true),
+			costAllocation: &cbTypes.ReportCostAllocation{Mode: "invoice"},
+			expectedErr:    `invalid costAllocation mode "invoice"`,
+		},
+		"invalid idleCostStrategy": {
+			query:          newQuery(true),
+			costAllocation: &cbTypes.ReportCostAllocation{IdleCostStrategy: "largest"},
+			expectedErr:    `invalid costAllocation idleCostStrategy "largest"`,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			costAllocation, err := getCostAllocation(tt.query, tt.costAllocation)
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, costAllocation)
+		})
+	}
+}
+
+func TestRenderAllocatedIdleCost(t *testing.T) {
+	const query = `SELECT namespace_cost + {| .Report.AllocatedIdleCost "idle" "request" "usage" |} AS total_cost FROM costs`
+
+	tests := map[string]struct {
+		costAllocation *cbTypes.ReportCostAllocation
+		expected       string
+		expectedErr    bool
+	}{
+		"no costAllocation": {
+			expectedErr: true,
+		},
+		"showback": {
+			costAllocation: &cbTypes.ReportCostAllocation{Mode: cbTypes.ReportCostAllocationModeShowback, IdleCostStrategy: cbTypes.ReportIdleCostStrategyRequest},
+			expected:       `SELECT namespace_cost + CAST(0 AS double) AS total_cost FROM costs`,
+		},
+		"chargeback by request": {
+			costAllocation: &cbTypes.ReportCostAllocation{Mode: cbTypes.ReportCostAllocationModeChargeback, IdleCostStrategy: cbTypes.ReportIdleCostStrategyRequest},
+			expected:       `SELECT namespace_cost + (idle * coalesce(request / nullif(sum(request) OVER (), 0), 0)) AS total_cost FROM costs`,
+		},
+		"chargeback by usage": {
+			costAllocation: &cbTypes.ReportCostAllocation{Mode: cbTypes.ReportCostAllocationModeChargeback, IdleCostStrategy: cbTypes.ReportIdleCostStrategyUsage},
+			expected:       `SELECT namespace_cost + (idle * coalesce(usage / nullif(sum(usage) OVER (), 0), 0)) AS total_cost FROM costs`,
+		},
+		"chargeback evenly": {
+			costAllocation: &cbTypes.ReportCostAllocation{Mode: cbTypes.ReportCostAllocationModeChargeback, IdleCostStrategy: cbTypes.ReportIdleCostStrategyEven},
+			expected:       `SELECT namespace_cost + (idle * 1.0 / count(*) OVER ()) AS total_cost FROM costs`,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			qr := queryRenderer{templateInfo: &templateInfo{
+				Report: &reportTemplateInfo{CostAllocation: tt.costAllocation},
+			}}
+			rendered, err := qr.Render(query)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rendered)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package operator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+const (
+	cronDayOfMonthField = 2
+	cronDayOfWeekField  = 4
+
+	// maxExtendedCronIterations limits how many times an extendedCronSchedule
+	// searches for a matching day before giving up, which is roughly 5 years
+	// of days, matching the limit used by the cron package.
+	maxExtendedCronIterations = 5 * 366
+)
+
+// parseCronSchedule parses a standard 5 field cron expression, evaluating it
+// in the timezone specified, or UTC if timezone is empty.
+//
+// In addition to the standard cron syntax, the following Quartz style
+// extensions are supported:
+//   - "L" in the day of month field, which matches the last day of the month.
+//   - "LW" in the day of month field, which matches the last business day
+//     (Monday to Friday) of the month.
+//   - "<day>#<n>" in the day of week field, which matches the nth occurrence
+//     of the day of the week in the month, eg: "mon#1" is the first Monday.
+//   - "<day>L" in the day of week field, which matches the last occurrence of
+//     the day of the week in the month, eg: "5L" is the last Friday.
+//
+// If an extension is used in both day fields, both must match.
+func parseCronSchedule(expression, timezone string) (reportSchedule, error) {
+	loc, err := loadTimezone(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron timezone: %v", err)
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 || strings.HasPrefix(expression, "@") {
+		// not something we can extend, let the cron package validate it
+		schedule, err := cron.ParseStandard(expression)
+		if err != nil {
+			return nil, err
+		}
+		return &cronSchedule{schedule: schedule, loc: loc}, nil
+	}
+
+	var dayFilters []func(time.Time) bool
+	if filter, err := parseDayOfMonthExtension(fields[cronDayOfMonthField]); err != nil {
+		return nil, err
+	} else if filter != nil {
+		dayFilters = append(dayFilters, filter)
+		fields[cronDayOfMonthField] = "*"
+	}
+	if filter, err := parseDayOfWeekExtension(fields[cronDayOfWeekField]); err != nil {
+		return nil, err
+	} else if filter != nil {
+		dayFilters = append(dayFilters, filter)
+		fields[cronDayOfWeekField] = "*"
+	}
+
+	schedule, err := cron.ParseStandard(strings.Join(fields, " "))
+	if err != nil {
+		return nil, err
+	}
+	return &cronSchedule{schedule: schedule, loc: loc, dayFilters: dayFilters}, nil
+}
+
+// cronSchedule evaluates a cron schedule in a specific location, and
+// optionally only activates on days matching every dayFilter.
+type cronSchedule struct {
+	schedule   cron.Schedule
+	loc        *time.Location
+	dayFilters []func(time.Time) bool
+}
+
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	t = t.In(s.loc)
+	for i := 0; i < maxExtendedCronIterations; i++ {
+		t = s.schedule.Next(t)
+		if t.IsZero() {
+			return t
+		}
+		if s.dayMatches(t) {
+			return t.UTC()
+		}
+		// skip to the last second of the day, so the next activation is on
+		// the following day
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.loc).Add(-time.Second)
+	}
+	return time.Time{}
+}
+
+func (s *cronSchedule) Location() *time.Location {
+	return s.loc
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	for _, filter := range s.dayFilters {
+		if !filter(t) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseDayOfMonthExtension(field string) (func(time.Time) bool, error) {
+	switch strings.ToUpper(field) {
+	case "L":
+		return func(t time.Time) bool {
+			return t.Day() == lastDayOfMonth(t)
+		}, nil
+	case "LW":
+		return func(t time.Time) bool {
+			return t.Day() == lastBusinessDayOfMonth(t)
+		}, nil
+	}
+	if strings.ContainsAny(field, "LlWw") && !strings.ContainsAny(field, ",-/") {
+		return nil, fmt.Errorf("invalid day of month %q, only L and LW are supported", field)
+	}
+	return nil, nil
+}
+
+func parseDayOfWeekExtension(field string) (func(time.Time) bool, error) {
+	if i := strings.Index(field, "#"); i != -1 {
+		dow, err := parseCronDayOfWeek(field[:i])
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(field[i+1:])
+		if err != nil || n < 1 || n > 5 {
+			return nil, fmt.Errorf("invalid day of week %q, occurrence must be between 1 and 5", field)
+		}
+		return func(t time.Time) bool {
+			return t.Weekday() == dow && (t.Day()-1)/7+1 == n
+		}, nil
+	}
+	if len(field) > 1 && strings.HasSuffix(strings.ToUpper(field), "L") {
+		dow, err := parseCronDayOfWeek(field[:len(field)-1])
+		if err != nil {
+			return nil, err
+		}
+		return func(t time.Time) bool {
+			return t.Weekday() == dow && t.Day()+7 > lastDayOfMonth(t)
+		}, nil
+	}
+	return nil, nil
+}
+
+func parseCronDayOfWeek(dow string) (time.Weekday, error) {
+	if n, err := strconv.Atoi(dow); err == nil {
+		if n < 0 || n > 7 {
+			return 0, fmt.Errorf("invalid day of week: %s", dow)
+		}
+		// both 0 and 7 are Sunday
+		return time.Weekday(n % 7), nil
+	}
+	n, err := convertDayOfWeek(dow)
+	if err != nil {
+		return 0, err
+	}
+	return time.Weekday(n), nil
+}
+
+func lastDayOfMonth(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+}
+
+func lastBusinessDayOfMonth(t time.Time) int {
+	last := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location())
+	for last.Weekday() == time.Saturday || last.Weekday() == time.Sunday {
+		last = last.AddDate(0, 0, -1)
+	}
+	return last.Day()
+}
@@ -0,0 +1,101 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	start := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		expression    string
+		timezone      string
+		expectError   bool
+		expectedTimes []time.Time
+	}{
+		"standard": {
+			expression: "30 2 * * *",
+			expectedTimes: []time.Time{
+				time.Date(2018, time.August, 1, 2, 30, 0, 0, time.UTC),
+				time.Date(2018, time.August, 2, 2, 30, 0, 0, time.UTC),
+			},
+		},
+		"timezone": {
+			expression: "0 0 1 * *",
+			timezone:   "America/New_York",
+			expectedTimes: []time.Time{
+				time.Date(2018, time.August, 1, 4, 0, 0, 0, time.UTC),
+				time.Date(2018, time.September, 1, 4, 0, 0, 0, time.UTC),
+				time.Date(2018, time.October, 1, 4, 0, 0, 0, time.UTC),
+				// EST starts in November
+				time.Date(2018, time.November, 1, 4, 0, 0, 0, time.UTC),
+				time.Date(2018, time.December, 1, 5, 0, 0, 0, time.UTC),
+			},
+		},
+		"last day of month": {
+			expression: "0 0 L * *",
+			expectedTimes: []time.Time{
+				time.Date(2018, time.August, 31, 0, 0, 0, 0, time.UTC),
+				time.Date(2018, time.September, 30, 0, 0, 0, 0, time.UTC),
+				time.Date(2018, time.October, 31, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		"last business day of month": {
+			expression: "0 17 LW * *",
+			expectedTimes: []time.Time{
+				time.Date(2018, time.August, 31, 17, 0, 0, 0, time.UTC),
+				// September 30th 2018 is a Sunday
+				time.Date(2018, time.September, 28, 17, 0, 0, 0, time.UTC),
+				time.Date(2018, time.October, 31, 17, 0, 0, 0, time.UTC),
+			},
+		},
+		"first monday of month": {
+			expression: "0 9 * * mon#1",
+			expectedTimes: []time.Time{
+				time.Date(2018, time.August, 6, 9, 0, 0, 0, time.UTC),
+				time.Date(2018, time.September, 3, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		"last friday of month": {
+			expression: "0 0 * * 5L",
+			expectedTimes: []time.Time{
+				time.Date(2018, time.August, 31, 0, 0, 0, 0, time.UTC),
+				time.Date(2018, time.September, 28, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		"invalid timezone": {
+			expression:  "0 0 * * *",
+			timezone:    "Not/AZone",
+			expectError: true,
+		},
+		"invalid day of month extension": {
+			expression:  "0 0 15W * *",
+			expectError: true,
+		},
+		"invalid occurrence": {
+			expression:  "0 0 * * mon#6",
+			expectError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			schedule, err := parseCronSchedule(tt.expression, tt.timezone)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			next := start
+			for _, expected := range tt.expectedTimes {
+				next = schedule.Next(next)
+				assert.Equal(t, expected, next)
+			}
+		})
+	}
+}
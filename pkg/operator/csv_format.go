@@ -0,0 +1,136 @@
+package operator
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+// csvFormat is how results are written in the csv and tabular formats.
+type csvFormat struct {
+	delimiter rune
+	// units adds the units of the columns to the header row.
+	units bool
+	// decimalSeparator separates the integer and fractional digits of
+	// numbers.
+	decimalSeparator string
+	// timeLayout is the layout timestamps are written with. If empty,
+	// timestamps are written using time.Time's String method.
+	timeLayout string
+}
+
+// defaultCSVFormat is the format of CSV results without locale options,
+// which is also used for delivered results.
+var defaultCSVFormat = csvFormat{delimiter: ',', decimalSeparator: "."}
+
+// csvLocale is the decimal separator and date format of numbers and
+// timestamps which spreadsheets using a locale parse.
+type csvLocale struct {
+	decimalSeparator string
+	dateFormat       string
+}
+
+// csvLocales are the locales results can be formatted for, keyed by their
+// lower case language tag.
+var csvLocales = map[string]csvLocale{
+	"en-us": {decimalSeparator: ".", dateFormat: "MM/DD/YYYY hh:mm:ss"},
+	"en-gb": {decimalSeparator: ".", dateFormat: "DD/MM/YYYY hh:mm:ss"},
+	"de-de": {decimalSeparator: ",", dateFormat: "DD.MM.YYYY hh:mm:ss"},
+	"fr-fr": {decimalSeparator: ",", dateFormat: "DD/MM/YYYY hh:mm:ss"},
+	"es-es": {decimalSeparator: ",", dateFormat: "DD/MM/YYYY hh:mm:ss"},
+	"it-it": {decimalSeparator: ",", dateFormat: "DD/MM/YYYY hh:mm:ss"},
+	"nl-nl": {decimalSeparator: ",", dateFormat: "DD-MM-YYYY hh:mm:ss"},
+}
+
+// dateFormatRegexp matches the date formats the date_format query parameter
+// accepts, which are made of the YYYY, MM, DD, hh, mm and ss fields
+// separated by spaces or one of . - / : T.
+var dateFormatRegexp = regexp.MustCompile(`^(?:YYYY|MM|DD|hh|mm|ss|[ .\-/:T])+$`)
+
+var dateFormatReplacer = strings.NewReplacer(
+	"YYYY", "2006",
+	"MM", "01",
+	"DD", "02",
+	"hh", "15",
+	"mm", "04",
+	"ss", "05",
+)
+
+// csvFormatFromRequest returns the csvFormat of the request, using the
+// delimiter of the results' format. The locale query parameter sets the
+// decimal separator and date format, which the decimal_separator and
+// date_format query parameters override. When the decimal separator is a
+// comma, CSV results are delimited by semicolons, as spreadsheets expect in
+// locales using decimal commas.
+func csvFormatFromRequest(r *http.Request, delimiter rune) (csvFormat, error) {
+	format := defaultCSVFormat
+	format.delimiter = delimiter
+	format.units = r.FormValue("units") == "true"
+
+	var dateFormat string
+	if localeStr := r.FormValue("locale"); localeStr != "" {
+		locale, ok := csvLocales[strings.ToLower(localeStr)]
+		if !ok {
+			return csvFormat{}, fmt.Errorf("unsupported locale %q, must be one of: en-US, en-GB, de-DE, fr-FR, es-ES, it-IT or nl-NL", localeStr)
+		}
+		format.decimalSeparator = locale.decimalSeparator
+		dateFormat = locale.dateFormat
+	}
+	if sep := r.FormValue("decimal_separator"); sep != "" {
+		if sep != "." && sep != "," {
+			return csvFormat{}, fmt.Errorf("decimal_separator must be . or , got %q", sep)
+		}
+		format.decimalSeparator = sep
+	}
+	if df := r.FormValue("date_format"); df != "" {
+		dateFormat = df
+	}
+	if dateFormat != "" {
+		if !dateFormatRegexp.MatchString(dateFormat) {
+			return csvFormat{}, fmt.Errorf("invalid date_format %q, must be made of YYYY, MM, DD, hh, mm and ss separated by spaces or one of . - / : T", dateFormat)
+		}
+		format.timeLayout = dateFormatReplacer.Replace(dateFormat)
+	}
+	if format.decimalSeparator == "," && format.delimiter == ',' {
+		format.delimiter = ';'
+	}
+	return format, nil
+}
+
+// value converts a value returned by Presto into its CSV representation.
+func (f csvFormat) value(val interface{}) (string, error) {
+	switch v := val.(type) {
+	case string:
+		return v, nil
+	case presto.Decimal:
+		return f.number(v.String()), nil
+	case []byte:
+		return string(v), nil
+	case uint, uint8, uint16, uint32, uint64, int, int8, int16, int32, int64:
+		return fmt.Sprintf("%d", v), nil
+	case float32, float64, complex64, complex128:
+		return f.number(fmt.Sprintf("%f", v)), nil
+	case bool:
+		return fmt.Sprintf("%t", v), nil
+	case time.Time:
+		if f.timeLayout != "" {
+			return v.Format(f.timeLayout), nil
+		}
+		return v.String(), nil
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("error marshalling csv: unknown type %t for value %v", val, val)
+	}
+}
+
+func (f csvFormat) number(s string) string {
+	if f.decimalSeparator == "." {
+		return s
+	}
+	return strings.Replace(s, ".", f.decimalSeparator, -1)
+}
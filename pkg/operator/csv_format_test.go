@@ -0,0 +1,95 @@
+package operator
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+func TestCSVFormatFromRequest(t *testing.T) {
+	tests := map[string]struct {
+		query       string
+		delimiter   rune
+		expected    csvFormat
+		expectedErr bool
+	}{
+		"default": {
+			delimiter: ',',
+			expected:  defaultCSVFormat,
+		},
+		"german locale": {
+			query:     "locale=de-DE",
+			delimiter: ',',
+			expected:  csvFormat{delimiter: ';', decimalSeparator: ",", timeLayout: "02.01.2006 15:04:05"},
+		},
+		"tabular keeps its delimiter": {
+			query:     "locale=fr-fr",
+			delimiter: '\t',
+			expected:  csvFormat{delimiter: '\t', decimalSeparator: ",", timeLayout: "02/01/2006 15:04:05"},
+		},
+		"overrides": {
+			query:     "locale=de-DE&decimal_separator=.&date_format=YYYY-MM-DDThh:mm:ss&units=true",
+			delimiter: ',',
+			expected:  csvFormat{delimiter: ',', units: true, decimalSeparator: ".", timeLayout: "2006-01-02T15:04:05"},
+		},
+		"decimal comma": {
+			query:     "decimal_separator=,",
+			delimiter: ',',
+			expected:  csvFormat{delimiter: ';', decimalSeparator: ","},
+		},
+		"unknown locale": {
+			query:       "locale=xx-XX",
+			expectedErr: true,
+		},
+		"invalid decimal separator": {
+			query:       "decimal_separator=x",
+			expectedErr: true,
+		},
+		"invalid date format": {
+			query:       "date_format=2006-01-02",
+			expectedErr: true,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/v1/reports/get?"+test.query, nil)
+			format, err := csvFormatFromRequest(r, test.delimiter)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, format)
+		})
+	}
+}
+
+func TestWriteResultsAsCSVWithLocale(t *testing.T) {
+	columns := []cbTypes.ReportGenerationQueryColumn{
+		{Name: "period_start", Type: "timestamp"},
+		{Name: "namespace", Type: "string"},
+		{Name: "pod_request_cpu_core_seconds", Type: "double"},
+		{Name: "pod_cost", Type: "decimal(38,12)"},
+	}
+	results := []presto.Row{{
+		"period_start":                 time.Date(2019, time.January, 31, 13, 0, 0, 0, time.UTC),
+		"namespace":                    "metering",
+		"pod_request_cpu_core_seconds": 7200.5,
+		"pod_cost":                     presto.Decimal("1234.560000000000"),
+	}}
+
+	r := httptest.NewRequest("GET", "/api/v1/reports/get?locale=de-DE", nil)
+	format, err := csvFormatFromRequest(r, ',')
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, writeResultsAsCSV(columns, results, &buf, format))
+	assert.Equal(t, "period_start;namespace;pod_request_cpu_core_seconds;pod_cost\n31.01.2019 13:00:00;metering;7200,500000;1234,560000000000\n", buf.String())
+}
@@ -0,0 +1,113 @@
+package operator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func (op *Reporting) handleDatabaseDataSource(logger logrus.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
+	database := dataSource.Spec.Database
+	if err := validateDatabaseDataSource(database); err != nil {
+		return fmt.Errorf("datasource %q: improperly configured datasource, %v", dataSource.Name, err)
+	}
+	if interval := op.databaseRefreshInterval(dataSource); interval <= 0 {
+		return fmt.Errorf("datasource %q: improperly configured datasource, refreshInterval must be positive, got %s", dataSource.Name, interval)
+	}
+
+	if dataSource.TableName == "" {
+		tableName := dataSourceTableName(dataSource.Name)
+		err := op.createTableForStorage(logger, dataSource, "ReportDataSource", dataSource.Name, database.Storage, tableName, generateHiveColumns(database.Columns))
+		if err != nil {
+			return err
+		}
+
+		err = op.updateDataSourceTableName(logger, dataSource, tableName)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to update ReportDataSource TableName field %q", tableName)
+			return err
+		}
+	}
+
+	op.tableLoaderNewDataSourceQueue <- tableLoad{
+		namespace:      dataSource.Namespace,
+		dataSourceName: dataSource.Name,
+		tableName:      dataSource.TableName,
+		description:    fmt.Sprintf("table %s.%s.%s", database.Catalog, database.Schema, database.Table),
+		query:          databaseTableQuery(database),
+		interval:       op.databaseRefreshInterval(dataSource),
+	}
+	return nil
+}
+
+func validateDatabaseDataSource(database *cbTypes.DatabaseDataSource) error {
+	if database.Catalog == "" {
+		return fmt.Errorf("catalog must be set")
+	}
+	// the hive catalog contains metering's own tables, which are read
+	// directly rather than copied.
+	if database.Catalog == "hive" {
+		return fmt.Errorf("catalog must be the catalog of an external database, not hive")
+	}
+	if database.Schema == "" {
+		return fmt.Errorf("schema must be set")
+	}
+	if database.Table == "" {
+		return fmt.Errorf("table must be set")
+	}
+	if len(database.Columns) == 0 {
+		return fmt.Errorf("columns must be set")
+	}
+	seen := make(map[string]struct{}, len(database.Columns))
+	for _, column := range database.Columns {
+		if column.Name == "" {
+			return fmt.Errorf("columns must have a name")
+		}
+		if _, exists := seen[column.Name]; exists {
+			return fmt.Errorf("column %s is defined more than once", column.Name)
+		}
+		seen[column.Name] = struct{}{}
+		// the JDBC connectors only support primitive types.
+		if simpleHiveColumnTypeToPrestoColumnType(column.Type) == "" {
+			return fmt.Errorf("column %s: type %s is not supported", column.Name, column.Type)
+		}
+	}
+	return nil
+}
+
+// databaseTableQuery returns the query selecting the columns of a database
+// ReportDataSource from the external table. Each column is cast to the type
+// of the ReportDataSource's column, since the types of the database's
+// columns, such as integer or varchar(64), often differ from them.
+func databaseTableQuery(database *cbTypes.DatabaseDataSource) string {
+	columns := make([]string, len(database.Columns))
+	for i, column := range database.Columns {
+		name := quotePrestoIdentifier(column.Name)
+		columns[i] = fmt.Sprintf("CAST(%s AS %s) AS %s", name, simpleHiveColumnTypeToPrestoColumnType(column.Type), name)
+	}
+	return fmt.Sprintf("SELECT %s FROM %s.%s.%s",
+		strings.Join(columns, ", "),
+		quotePrestoIdentifier(database.Catalog),
+		quotePrestoIdentifier(database.Schema),
+		quotePrestoIdentifier(database.Table),
+	)
+}
+
+// quotePrestoIdentifier quotes an identifier, such as the name of a table in
+// an external database, which may not be a valid unquoted identifier.
+func quotePrestoIdentifier(identifier string) string {
+	return `"` + strings.Replace(identifier, `"`, `""`, -1) + `"`
+}
+
+// databaseRefreshInterval returns how often the table of a database
+// ReportDataSource is copied.
+func (op *Reporting) databaseRefreshInterval(dataSource *cbTypes.ReportDataSource) time.Duration {
+	if interval := dataSource.Spec.Database.RefreshInterval; interval != nil {
+		return interval.Duration
+	}
+	return op.cfg.PrometheusQueryConfig.QueryInterval.Duration
+}
@@ -0,0 +1,145 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestValidateDatabaseDataSource(t *testing.T) {
+	columns := []cbTypes.ReportGenerationQueryColumn{
+		{Name: "namespace", Type: "string"},
+		{Name: "cost_center", Type: "string"},
+		{Name: "budget", Type: "decimal(38,12)"},
+	}
+
+	tests := map[string]struct {
+		database    *cbTypes.DatabaseDataSource
+		expectedErr bool
+	}{
+		"valid": {
+			database: &cbTypes.DatabaseDataSource{
+				Catalog: "finance",
+				Schema:  "public",
+				Table:   "cost_centers",
+				Columns: columns,
+			},
+		},
+		"no catalog": {
+			database: &cbTypes.DatabaseDataSource{
+				Schema:  "public",
+				Table:   "cost_centers",
+				Columns: columns,
+			},
+			expectedErr: true,
+		},
+		"hive catalog": {
+			database: &cbTypes.DatabaseDataSource{
+				Catalog: "hive",
+				Schema:  "public",
+				Table:   "cost_centers",
+				Columns: columns,
+			},
+			expectedErr: true,
+		},
+		"no schema": {
+			database: &cbTypes.DatabaseDataSource{
+				Catalog: "finance",
+				Table:   "cost_centers",
+				Columns: columns,
+			},
+			expectedErr: true,
+		},
+		"no table": {
+			database: &cbTypes.DatabaseDataSource{
+				Catalog: "finance",
+				Schema:  "public",
+				Columns: columns,
+			},
+			expectedErr: true,
+		},
+		"no columns": {
+			database: &cbTypes.DatabaseDataSource{
+				Catalog: "finance",
+				Schema:  "public",
+				Table:   "cost_centers",
+			},
+			expectedErr: true,
+		},
+		"duplicate column": {
+			database: &cbTypes.DatabaseDataSource{
+				Catalog: "finance",
+				Schema:  "public",
+				Table:   "cost_centers",
+				Columns: []cbTypes.ReportGenerationQueryColumn{
+					{Name: "namespace", Type: "string"},
+					{Name: "namespace", Type: "string"},
+				},
+			},
+			expectedErr: true,
+		},
+		"complex column": {
+			database: &cbTypes.DatabaseDataSource{
+				Catalog: "finance",
+				Schema:  "public",
+				Table:   "cost_centers",
+				Columns: []cbTypes.ReportGenerationQueryColumn{
+					{Name: "namespace", Type: "string"},
+					{Name: "owners", Type: "array<string>"},
+				},
+			},
+			expectedErr: true,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			err := validateDatabaseDataSource(test.database)
+			if test.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDatabaseTableQuery(t *testing.T) {
+	tests := map[string]struct {
+		database      *cbTypes.DatabaseDataSource
+		expectedQuery string
+	}{
+		"casts columns": {
+			database: &cbTypes.DatabaseDataSource{
+				Catalog: "finance",
+				Schema:  "public",
+				Table:   "cost_centers",
+				Columns: []cbTypes.ReportGenerationQueryColumn{
+					{Name: "namespace", Type: "string"},
+					{Name: "cost_center", Type: "string"},
+					{Name: "budget", Type: "decimal(38,12)"},
+				},
+			},
+			expectedQuery: `SELECT CAST("namespace" AS VARCHAR) AS "namespace", CAST("cost_center" AS VARCHAR) AS "cost_center", CAST("budget" AS DECIMAL(38,12)) AS "budget" FROM "finance"."public"."cost_centers"`,
+		},
+		"quoted table": {
+			database: &cbTypes.DatabaseDataSource{
+				Catalog: "finance",
+				Schema:  "public",
+				Table:   `cost "centers"`,
+				Columns: []cbTypes.ReportGenerationQueryColumn{
+					{Name: "namespace", Type: "string"},
+				},
+			},
+			expectedQuery: `SELECT CAST("namespace" AS VARCHAR) AS "namespace" FROM "finance"."public"."cost ""centers"""`,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expectedQuery, databaseTableQuery(test.database))
+		})
+	}
+}
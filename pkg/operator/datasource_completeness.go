@@ -0,0 +1,158 @@
+package operator
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+// DefaultDataCompletenessWindow is how far back the data of each
+// ReportDataSource is checked for missing steps.
+const DefaultDataCompletenessWindow = 24 * time.Hour
+
+var reportDataSourceDataCompletenessGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "metering",
+		Name:      "reportdatasource_data_completeness_ratio",
+		Help:      "The fraction of the steps a ReportDataSource was expected to import data for during the data completeness window which its table has data for.",
+	},
+	[]string{"reportdatasource"},
+)
+
+func init() {
+	prometheus.MustRegister(reportDataSourceDataCompletenessGauge)
+}
+
+// runReportDataSourceCompletenessChecker periodically counts the steps
+// within the last window each Prometheus and kubernetesObjects
+// ReportDataSource has data for, exporting the fraction of the expected
+// steps present as a metric and in the ReportDataSource's status. The
+// window ends at the start of the hour, once the data for it should have
+// been imported, so each ReportDataSource is checked once an hour.
+func (op *Reporting) runReportDataSourceCompletenessChecker(stopCh <-chan struct{}, window time.Duration) {
+	logger := op.logger.WithField("component", "reportDataSourceCompletenessChecker")
+	logger.Infof("ReportDataSource completeness checker started")
+
+	// checkedUntil tracks the end of the window last checked for each
+	// ReportDataSource so each window is only checked once.
+	checkedUntil := make(map[string]time.Time)
+	for {
+		select {
+		case <-stopCh:
+			logger.Infof("ReportDataSource completeness checker exiting")
+			return
+		case <-op.clock.Tick(dataSourceValidationInterval):
+			dataSources, err := op.informers.Metering().V1alpha1().ReportDataSources().Lister().ReportDataSources(op.cfg.Namespace).List(labels.Everything())
+			if err != nil {
+				logger.WithError(err).Errorf("unable to list reportDataSources")
+				continue
+			}
+
+			now := op.clock.Now().UTC()
+			seen := make(map[string]struct{})
+			for _, dataSource := range dataSources {
+				if dataSource.TableName == "" || !op.cfg.ShardingConfig.owns(dataSource.Name) {
+					continue
+				}
+				step, lag := op.dataCompletenessStep(dataSource)
+				if step < time.Second {
+					continue
+				}
+				seen[dataSource.Name] = struct{}{}
+				end := now.Add(-lag - dataSourceValidationDelay).Truncate(time.Hour)
+				if checkedUntil[dataSource.Name].Equal(end) {
+					continue
+				}
+				start := dataCompletenessWindowStart(end, window, step, dataSource.CreationTimestamp.Time)
+				if !start.Before(end) {
+					continue
+				}
+				err := op.checkReportDataSourceCompleteness(logger.WithField("reportDataSource", dataSource.Name), dataSource, start, end, step)
+				if err != nil {
+					logger.WithError(err).Errorf("unable to check the data completeness of reportDataSource %s", dataSource.Name)
+					continue
+				}
+				checkedUntil[dataSource.Name] = end
+			}
+			for name := range checkedUntil {
+				if _, exists := seen[name]; !exists {
+					delete(checkedUntil, name)
+					reportDataSourceDataCompletenessGauge.DeleteLabelValues(name)
+				}
+			}
+		}
+	}
+}
+
+// dataCompletenessStep returns how often a ReportDataSource is expected to
+// have data, and how long after a step its data is imported. The step is 0
+// for ReportDataSources which don't import data periodically.
+func (op *Reporting) dataCompletenessStep(dataSource *cbTypes.ReportDataSource) (step, lag time.Duration) {
+	switch {
+	case dataSource.Spec.Promsum != nil:
+		queryInterval, stepSize, _ := op.prometheusQueryConfig(dataSource)
+		return stepSize, queryInterval
+	case dataSource.Spec.KubernetesObjects != nil:
+		interval := op.kubernetesObjectsCollectionInterval(dataSource)
+		return interval, interval
+	default:
+		return 0, 0
+	}
+}
+
+// dataCompletenessWindowStart returns the start of the window ending at
+// end, which is after created for ReportDataSources created during the
+// window, as they aren't expected to have data from before they existed.
+func dataCompletenessWindowStart(end time.Time, window, step time.Duration, created time.Time) time.Time {
+	start := end.Add(-window)
+	if created.After(start) {
+		start = created.Truncate(step)
+		if start.Before(created) {
+			start = start.Add(step)
+		}
+	}
+	return start
+}
+
+func (op *Reporting) checkReportDataSourceCompleteness(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource, start, end time.Time, step time.Duration) error {
+	present, err := prestostore.GetTimestampStepCount(op.prestoQueryer, dataSource.TableName, start, end, step)
+	if err != nil {
+		return err
+	}
+	completeness := newDataCompleteness(start, end, step, present)
+	reportDataSourceDataCompletenessGauge.WithLabelValues(dataSource.Name).Set(completeness.Ratio)
+	if completeness.PresentSteps < completeness.ExpectedSteps {
+		logger.Warnf("reportDataSource has data for %d of %d steps between %s and %s", completeness.PresentSteps, completeness.ExpectedSteps, start, end)
+	}
+
+	dataSource = dataSource.DeepCopy()
+	dataSource.DataCompleteness = completeness
+	_, err = op.meteringClient.MeteringV1alpha1().ReportDataSources(dataSource.Namespace).Update(dataSource)
+	return err
+}
+
+// newDataCompleteness returns the completeness of a table with data for
+// present of the steps between start and end.
+func newDataCompleteness(start, end time.Time, step time.Duration, present int64) *cbTypes.ReportDataSourceDataCompleteness {
+	expected := int64((end.Sub(start) + step - 1) / step)
+	if present > expected {
+		present = expected
+	}
+	ratio := 1.0
+	if expected != 0 {
+		ratio = float64(present) / float64(expected)
+	}
+	return &cbTypes.ReportDataSourceDataCompleteness{
+		Ratio:         ratio,
+		ExpectedSteps: expected,
+		PresentSteps:  present,
+		Start:         metav1.NewTime(start),
+		End:           metav1.NewTime(end),
+	}
+}
@@ -0,0 +1,56 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataCompletenessWindowStart(t *testing.T) {
+	end := time.Date(2019, time.January, 2, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		created  time.Time
+		expected time.Time
+	}{
+		"created before the window": {
+			created:  time.Date(2018, time.December, 1, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2019, time.January, 1, 12, 0, 0, 0, time.UTC),
+		},
+		"created during the window": {
+			created:  time.Date(2019, time.January, 2, 6, 30, 20, 0, time.UTC),
+			expected: time.Date(2019, time.January, 2, 6, 31, 0, 0, time.UTC),
+		},
+		"created on a step": {
+			created:  time.Date(2019, time.January, 2, 6, 30, 0, 0, time.UTC),
+			expected: time.Date(2019, time.January, 2, 6, 30, 0, 0, time.UTC),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, dataCompletenessWindowStart(end, 24*time.Hour, time.Minute, test.created))
+		})
+	}
+}
+
+func TestNewDataCompleteness(t *testing.T) {
+	start := time.Date(2019, time.January, 1, 12, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	completeness := newDataCompleteness(start, end, time.Minute, 1080)
+	assert.Equal(t, int64(1440), completeness.ExpectedSteps)
+	assert.Equal(t, int64(1080), completeness.PresentSteps)
+	assert.Equal(t, 0.75, completeness.Ratio)
+	assert.Equal(t, start, completeness.Start.Time)
+	assert.Equal(t, end, completeness.End.Time)
+
+	// partial steps at the end of the window are expected.
+	completeness = newDataCompleteness(start, start.Add(90*time.Second), time.Minute, 2)
+	assert.Equal(t, int64(2), completeness.ExpectedSteps)
+	assert.Equal(t, 1.0, completeness.Ratio)
+
+	// steps outside the window can't make the data more than complete.
+	completeness = newDataCompleteness(start, end, time.Hour, 25)
+	assert.Equal(t, int64(24), completeness.PresentSteps)
+	assert.Equal(t, 1.0, completeness.Ratio)
+}
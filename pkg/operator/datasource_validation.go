@@ -0,0 +1,135 @@
+package operator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+const (
+	dataSourceValidationInterval = 5 * time.Minute
+	// dataSourceValidationDelay is how long after an hour ends before the
+	// data imported for it is validated, giving the Prometheus importer
+	// time to finish importing it.
+	dataSourceValidationDelay = 15 * time.Minute
+)
+
+// runReportDataSourceValidator periodically checks the data imported by
+// each ReportDataSource with validation rules during the most recent hour
+// against those rules, setting the Degraded condition and recording an
+// event when they're violated.
+func (op *Reporting) runReportDataSourceValidator(stopCh <-chan struct{}) {
+	logger := op.logger.WithField("component", "reportDataSourceValidator")
+	logger.Infof("ReportDataSource validator started")
+
+	// validatedUntil tracks the end of the last hour validated for each
+	// ReportDataSource so each hour is only validated once.
+	validatedUntil := make(map[string]time.Time)
+	for {
+		select {
+		case <-stopCh:
+			logger.Infof("ReportDataSource validator exiting")
+			return
+		case <-op.clock.Tick(dataSourceValidationInterval):
+			dataSources, err := op.informers.Metering().V1alpha1().ReportDataSources().Lister().ReportDataSources(op.cfg.Namespace).List(labels.Everything())
+			if err != nil {
+				logger.WithError(err).Errorf("unable to list reportDataSources")
+				continue
+			}
+
+			end := op.clock.Now().UTC().Add(-dataSourceValidationDelay).Truncate(time.Hour)
+			start := end.Add(-time.Hour)
+			seen := make(map[string]struct{})
+			for _, dataSource := range dataSources {
+				seen[dataSource.Name] = struct{}{}
+				if dataSource.Spec.Promsum == nil || dataSource.Spec.Promsum.Validation == nil || dataSource.TableName == "" || !op.cfg.ShardingConfig.owns(dataSource.Name) {
+					continue
+				}
+				// the first hour is likely incomplete.
+				if dataSource.CreationTimestamp.Time.After(start) || validatedUntil[dataSource.Name].Equal(end) {
+					continue
+				}
+				err := op.validateReportDataSource(logger.WithField("reportDataSource", dataSource.Name), dataSource, start, end)
+				if err != nil {
+					logger.WithError(err).Errorf("unable to validate reportDataSource %s", dataSource.Name)
+					continue
+				}
+				validatedUntil[dataSource.Name] = end
+			}
+			for name := range validatedUntil {
+				if _, exists := seen[name]; !exists {
+					delete(validatedUntil, name)
+				}
+			}
+		}
+	}
+}
+
+func (op *Reporting) validateReportDataSource(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource, start, end time.Time) error {
+	rules := dataSource.Spec.Promsum.Validation
+	stats, err := prestostore.GetPrometheusMetricsStats(op.prestoQueryer, dataSource.TableName, start, end, rules.MinValue, rules.MaxValue)
+	if err != nil {
+		return err
+	}
+
+	dataSource = dataSource.DeepCopy()
+	degradedCond := cbutil.GetReportDataSourceCondition(dataSource, cbTypes.ReportDataSourceDegraded)
+	violations := getDataSourceValidationViolations(rules, stats)
+	var cond *cbTypes.ReportDataSourceCondition
+	if len(violations) != 0 {
+		msg := fmt.Sprintf("data imported between %s and %s violates validation rules: %s", start, end, strings.Join(violations, "; "))
+		logger.Warnf("reportDataSource is degraded: %s", msg)
+		op.eventRecorder.Event(dataSource, v1.EventTypeWarning, cbutil.ValidationFailedReason, msg)
+		cond = cbutil.NewReportDataSourceCondition(cbTypes.ReportDataSourceDegraded, v1.ConditionTrue, cbutil.ValidationFailedReason, msg)
+	} else {
+		msg := fmt.Sprintf("data imported between %s and %s meets validation rules", start, end)
+		if degradedCond != nil && degradedCond.Status == v1.ConditionTrue {
+			logger.Infof("reportDataSource is no longer degraded")
+			op.eventRecorder.Event(dataSource, v1.EventTypeNormal, cbutil.ValidationPassedReason, msg)
+		}
+		cond = cbutil.NewReportDataSourceCondition(cbTypes.ReportDataSourceDegraded, v1.ConditionFalse, cbutil.ValidationPassedReason, msg)
+	}
+	cbutil.SetReportDataSourceCondition(dataSource, *cond)
+
+	_, err = op.meteringClient.MeteringV1alpha1().ReportDataSources(dataSource.Namespace).Update(dataSource)
+	return err
+}
+
+// getDataSourceValidationViolations returns a description of each of the
+// validation rules the stats of an hour of imported data violate.
+func getDataSourceValidationViolations(rules *cbTypes.PrometheusMetricsValidation, stats prestostore.PrometheusMetricsStats) []string {
+	var violations []string
+	if rules.MinSamplesPerHour != nil && stats.Samples < *rules.MinSamplesPerHour {
+		violations = append(violations, fmt.Sprintf("got %d samples, expected at least %d", stats.Samples, *rules.MinSamplesPerHour))
+	}
+	if len(rules.AllowedLabelKeys) != 0 {
+		allowed := make(map[string]struct{}, len(rules.AllowedLabelKeys))
+		for _, key := range rules.AllowedLabelKeys {
+			allowed[key] = struct{}{}
+		}
+		var unexpected []string
+		for _, key := range stats.LabelKeys {
+			if _, ok := allowed[key]; !ok {
+				unexpected = append(unexpected, key)
+			}
+		}
+		if len(unexpected) != 0 {
+			violations = append(violations, fmt.Sprintf("unexpected label keys: %s", strings.Join(unexpected, ", ")))
+		}
+	}
+	if rules.MinValue != nil && stats.BelowMin != 0 {
+		violations = append(violations, fmt.Sprintf("%d samples below the minimum value %g", stats.BelowMin, *rules.MinValue))
+	}
+	if rules.MaxValue != nil && stats.AboveMax != 0 {
+		violations = append(violations, fmt.Sprintf("%d samples above the maximum value %g", stats.AboveMax, *rules.MaxValue))
+	}
+	return violations
+}
@@ -0,0 +1,64 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+func TestGetDataSourceValidationViolations(t *testing.T) {
+	minSamples := int64(60)
+	minValue := 0.0
+	maxValue := 64.0
+	rules := &v1alpha1.PrometheusMetricsValidation{
+		MinSamplesPerHour: &minSamples,
+		AllowedLabelKeys:  []string{"namespace", "pod"},
+		MinValue:          &minValue,
+		MaxValue:          &maxValue,
+	}
+
+	tests := map[string]struct {
+		rules              *v1alpha1.PrometheusMetricsValidation
+		stats              prestostore.PrometheusMetricsStats
+		expectedViolations []string
+	}{
+		"valid": {
+			rules: rules,
+			stats: prestostore.PrometheusMetricsStats{Samples: 120, LabelKeys: []string{"namespace", "pod"}},
+		},
+		"too few samples": {
+			rules:              rules,
+			stats:              prestostore.PrometheusMetricsStats{Samples: 12, LabelKeys: []string{"namespace"}},
+			expectedViolations: []string{"got 12 samples, expected at least 60"},
+		},
+		"unexpected labels and values out of range": {
+			rules: rules,
+			stats: prestostore.PrometheusMetricsStats{
+				Samples:   120,
+				BelowMin:  1,
+				AboveMax:  2,
+				LabelKeys: []string{"container", "namespace", "pod", "pod_name"},
+			},
+			expectedViolations: []string{
+				"unexpected label keys: container, pod_name",
+				"1 samples below the minimum value 0",
+				"2 samples above the maximum value 64",
+			},
+		},
+		"no rules": {
+			rules: &v1alpha1.PrometheusMetricsValidation{},
+			stats: prestostore.PrometheusMetricsStats{LabelKeys: []string{"container"}},
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedViolations, getDataSourceValidationViolations(tt.rules, tt.stats))
+		})
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"fmt"
 
 	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/tools/cache"
 
 	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
 	"github.com/operator-framework/operator-metering/pkg/aws"
 	"github.com/operator-framework/operator-metering/pkg/hive"
 )
@@ -19,6 +21,13 @@ var (
 		{Name: "timePrecision", Type: "double"},
 		{Name: "labels", Type: "map<string, string>"},
 	}
+	promsumExemplarsHiveColumns = []hive.Column{
+		{Name: "timestamp", Type: "timestamp"},
+		{Name: "value", Type: "double"},
+		{Name: "trace_id", Type: "string"},
+		{Name: "series_labels", Type: "map<string, string>"},
+		{Name: "labels", Type: "map<string, string>"},
+	}
 )
 
 func (op *Reporting) runReportDataSourceWorker() {
@@ -39,7 +48,9 @@ func (op *Reporting) processReportDataSource(logger log.FieldLogger) bool {
 
 	logger = logger.WithFields(newLogIdentifier(op.rand))
 	if key, ok := op.getKeyFromQueueObj(logger, "ReportDataSource", obj, op.queues.reportDataSourceQueue); ok {
+		start := op.clock.Now()
 		err := op.syncReportDataSource(logger, key)
+		op.observeReconcile("ReportDataSource", start, err)
 		op.handleErr(logger, err, "ReportDataSource", key, op.queues.reportDataSourceQueue)
 	}
 	return true
@@ -53,11 +64,25 @@ func (op *Reporting) syncReportDataSource(logger log.FieldLogger, key string) er
 	}
 
 	logger = logger.WithField("datasource", name)
+	if tenantNamespace := op.tenantNamespace(namespace); tenantNamespace != "" {
+		// ReportDataSources share table names across namespaces, so only
+		// those in the metering namespace are imported.
+		logger.Warnf("ignoring ReportDataSource %s, ReportDataSources in tenant namespaces are not supported, the ReportDataSources in namespace %s are shared with tenants instead", key, op.cfg.Namespace)
+		return nil
+	}
+	if !op.cfg.ShardingConfig.owns(name) {
+		logger.Debugf("ignoring ReportDataSource %s, it's owned by shard %d", key, dataSourceShard(name, op.cfg.ShardingConfig.Shards))
+		return nil
+	}
 	reportDataSource, err := op.informers.Metering().V1alpha1().ReportDataSources().Lister().ReportDataSources(namespace).Get(name)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			logger.Infof("ReportDataSource %s does not exist anymore, deleting data associated with it", key)
 			op.prometheusImporterDeletedDataSourceQueue <- name
+			op.kubernetesObjectsDeletedDataSourceQueue <- name
+			op.kubernetesEventsDeletedDataSourceQueue <- name
+			op.remoteReportDeletedDataSourceQueue <- name
+			op.tableLoaderDeletedDataSourceQueue <- name
 			op.deleteReportDataSourceTable(name)
 			return nil
 		}
@@ -104,21 +129,71 @@ func (op *Reporting) handleReportDataSource(logger log.FieldLogger, dataSource *
 		logger.Infof("existing dataSource discovered, tableName: %s", dataSource.TableName)
 	}
 
+	var err error
 	switch {
 	case dataSource.Spec.Promsum != nil:
-		return op.handlePrometheusMetricsDataSource(logger, dataSource)
+		err = op.handlePrometheusMetricsDataSource(logger, dataSource)
 	case dataSource.Spec.AWSBilling != nil:
-		return op.handleAWSBillingDataSource(logger, dataSource)
+		err = op.handleAWSBillingDataSource(logger, dataSource)
+	case dataSource.Spec.GCPBilling != nil:
+		err = op.handleGCPBillingDataSource(logger, dataSource)
+	case dataSource.Spec.KubernetesObjects != nil:
+		err = op.handleKubernetesObjectsDataSource(logger, dataSource)
+	case dataSource.Spec.KubernetesEvents != nil:
+		err = op.handleKubernetesEventsDataSource(logger, dataSource)
+	case dataSource.Spec.RemoteReport != nil:
+		err = op.handleRemoteReportDataSource(logger, dataSource)
+	case dataSource.Spec.ObjectStorage != nil:
+		err = op.handleObjectStorageDataSource(logger, dataSource)
+	case dataSource.Spec.Database != nil:
+		err = op.handleDatabaseDataSource(logger, dataSource)
 	default:
-		return fmt.Errorf("datasource %s: improperly configured missing promsum or awsBilling configuration", dataSource.Name)
+		err = fmt.Errorf("datasource %s: improperly configured missing promsum, awsBilling, gcpBilling, kubernetesObjects, kubernetesEvents, remoteReport, objectStorage or database configuration", dataSource.Name)
+	}
+	if err != nil && dataSource.TableName == "" {
+		// the dataSource may have been updated while it was handled, so
+		// the latest version is updated.
+		if latest, getErr := op.informers.Metering().V1alpha1().ReportDataSources().Lister().ReportDataSources(dataSource.Namespace).Get(dataSource.Name); getErr == nil {
+			op.updateDataSourceCondition(logger, latest.DeepCopy(), *cbutil.NewReportDataSourceCondition(cbTypes.ReportDataSourceReady, v1.ConditionFalse, cbutil.TableCreationFailedReason, err.Error()))
+		}
 	}
+	return err
 }
 
 func (op *Reporting) handlePrometheusMetricsDataSource(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
+	if err := validatePrometheusQueryConfig(dataSource.Spec.Promsum.QueryConfig); err != nil {
+		op.prometheusImporterDeletedDataSourceQueue <- dataSource.Name
+		return fmt.Errorf("datasource %q: improperly configured datasource, %v", dataSource.Name, err)
+	}
+	if err := validatePrometheusMetricsArchive(dataSource.Spec.Promsum.Archive); err != nil {
+		op.prometheusImporterDeletedDataSourceQueue <- dataSource.Name
+		return fmt.Errorf("datasource %q: improperly configured datasource, %v", dataSource.Name, err)
+	}
+	if err := op.checkDataSourceTablePartitioning(dataSource); err != nil {
+		op.prometheusImporterDeletedDataSourceQueue <- dataSource.Name
+		return fmt.Errorf("datasource %q: %v", dataSource.Name, err)
+	}
+	valid, err := op.checkPrometheusDataSourceQuery(logger, dataSource)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		// stop importing until the query is fixed, rather than failing
+		// every import.
+		op.prometheusImporterDeletedDataSourceQueue <- dataSource.Name
+		return nil
+	}
+
 	if dataSource.TableName == "" {
 		storage := dataSource.Spec.Promsum.Storage
 		tableName := dataSourceTableName(dataSource.Name)
-		err := op.createTableForStorage(logger, dataSource, "ReportDataSource", dataSource.Name, storage, tableName, promsumHiveColumns)
+		var partitions []hive.Column
+		if isDatePartitionedDataSource(dataSource) {
+			// the metrics are partitioned by date so that older dates can be
+			// moved to the archive storage.
+			partitions = promsumDatePartitionColumns
+		}
+		err := op.createPartitionedTableForStorage(logger, dataSource, "ReportDataSource", dataSource.Name, storage, tableName, promsumHiveColumns, partitions)
 		if err != nil {
 			return err
 		}
@@ -130,6 +205,17 @@ func (op *Reporting) handlePrometheusMetricsDataSource(logger log.FieldLogger, d
 		}
 	}
 
+	if dataSource.Spec.Promsum.Exemplars != nil {
+		// the exemplars table is created every sync, since exemplars can be
+		// enabled after the ReportDataSource table was created
+		tableName := dataSourceExemplarsTableName(dataSource.Name)
+		err := op.createTableForStorageNoCR(logger, dataSource.Spec.Promsum.Storage, tableName, promsumExemplarsHiveColumns)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to create exemplars table %s", tableName)
+			return err
+		}
+	}
+
 	op.prometheusImporterNewDataSourceQueue <- dataSource
 
 	return nil
@@ -141,7 +227,7 @@ func (op *Reporting) handleAWSBillingDataSource(logger log.FieldLogger, dataSour
 		return fmt.Errorf("datasource %q: improperly configured datasource, source is empty", dataSource.Name)
 	}
 
-	manifestRetriever := aws.NewManifestRetriever(source.Region, source.Bucket, source.Prefix)
+	manifestRetriever := aws.NewManifestRetriever(source.Region, source.Bucket, source.Prefix, op.awsCredentials)
 
 	manifests, err := manifestRetriever.RetrieveManifests()
 	if err != nil {
@@ -172,13 +258,40 @@ func (op *Reporting) handleAWSBillingDataSource(logger log.FieldLogger, dataSour
 	return nil
 }
 
+func (op *Reporting) handleGCPBillingDataSource(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
+	source := dataSource.Spec.GCPBilling.Source
+	if source == nil || source.Bucket == "" {
+		return fmt.Errorf("datasource %q: improperly configured datasource, source bucket is empty", dataSource.Name)
+	}
+	if dataSource.TableName != "" {
+		return nil
+	}
+
+	tableName := dataSourceTableName(dataSource.Name)
+	logger.Debugf("creating GCP Billing DataSource table %s pointing to gcs bucket %s at prefix %s", tableName, source.Bucket, source.Prefix)
+	err := op.createGCPBillingTable(logger, dataSource, tableName, source.Bucket, source.Prefix)
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("successfully created GCP Billing DataSource table %s pointing to gcs bucket %s at prefix %s", tableName, source.Bucket, source.Prefix)
+	return op.updateDataSourceTableName(logger, dataSource, tableName)
+}
+
 func (op *Reporting) updateDataSourceTableName(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource, tableName string) error {
 	dataSource.TableName = tableName
+	var previous v1.ConditionStatus
+	if cond := cbutil.GetReportDataSourceCondition(dataSource, cbTypes.ReportDataSourceReady); cond != nil {
+		previous = cond.Status
+	}
+	msg := fmt.Sprintf("table %s has been created", tableName)
+	cbutil.SetReportDataSourceCondition(dataSource, *cbutil.NewReportDataSourceCondition(cbTypes.ReportDataSourceReady, v1.ConditionTrue, cbutil.TableCreatedReason, msg))
 	_, err := op.meteringClient.MeteringV1alpha1().ReportDataSources(dataSource.Namespace).Update(dataSource)
 	if err != nil {
 		logger.WithError(err).Errorf("failed to update ReportDataSource table name for %q", dataSource.Name)
 		return err
 	}
+	op.recordConditionTransition(dataSource, string(cbTypes.ReportDataSourceReady), previous, v1.ConditionTrue, cbutil.TableCreatedReason, msg)
 	return nil
 }
 
@@ -189,4 +302,22 @@ func (op *Reporting) deleteReportDataSourceTable(name string) {
 		op.logger.WithError(err).Error("unable to drop ReportDataSource table")
 	}
 	op.logger.Infof("successfully deleted table %s", tableName)
+
+	exemplarsTableName := dataSourceExemplarsTableName(name)
+	err = hive.ExecuteDropTable(op.hiveQueryer, exemplarsTableName, true)
+	if err != nil {
+		op.logger.WithError(err).Error("unable to drop ReportDataSource exemplars table")
+	}
+
+	remoteTableName := dataSourceRemoteTableName(name)
+	err = hive.ExecuteDropTable(op.hiveQueryer, remoteTableName, true)
+	if err != nil {
+		op.logger.WithError(err).Error("unable to drop ReportDataSource remote report table")
+	}
+
+	filesTableName := dataSourceFilesTableName(name)
+	err = hive.ExecuteDropTable(op.hiveQueryer, filesTableName, true)
+	if err != nil {
+		op.logger.WithError(err).Error("unable to drop ReportDataSource files table")
+	}
 }
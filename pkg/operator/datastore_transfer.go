@@ -0,0 +1,279 @@
+package operator
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	// APIV1DataStoreExportEndpoint and APIV1DataStoreImportEndpoint copy
+	// the table of a ReportDataSource to and from a location in HDFS or an
+	// object store, so that the data collected by one Metering installation
+	// can be restored in another.
+	APIV1DataStoreExportEndpoint = "/api/v1/datasources/export"
+	APIV1DataStoreImportEndpoint = "/api/v1/datasources/import"
+
+	// lastTimestampTableProperty is the table property exported tables
+	// record the timestamp of their most recent row in.
+	lastTimestampTableProperty = "metering.lastTimestamp"
+)
+
+// DataStoreTransferRequest is the request of the export and import
+// endpoints.
+type DataStoreTransferRequest struct {
+	// Location is the directory the table is exported to, or imported
+	// from, such as s3a://bucket/metering-export/pod-request-cpu-cores. It
+	// must not exist when exporting.
+	Location string `json:"location"`
+}
+
+// DataStoreTransfer is the response of the export and import endpoints.
+type DataStoreTransfer struct {
+	DataSource string `json:"dataSource"`
+	TableName  string `json:"tableName"`
+	Location   string `json:"location"`
+	// LastTimestamp is the timestamp of the most recent row when the table
+	// was exported, which imports resume from.
+	LastTimestamp *time.Time `json:"lastTimestamp,omitempty"`
+}
+
+// dataStoreTransferer exports and imports the tables of ReportDataSources.
+type dataStoreTransferer interface {
+	exportDataSourceTable(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource, location string) (*DataStoreTransfer, error)
+	importDataSourceTable(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource, location string) (*DataStoreTransfer, error)
+}
+
+func (srv *server) exportDataStoreHandler(w http.ResponseWriter, r *http.Request) {
+	srv.dataStoreTransferHandler(w, r, srv.dataStore.exportDataSourceTable)
+}
+
+func (srv *server) importDataStoreHandler(w http.ResponseWriter, r *http.Request) {
+	srv.dataStoreTransferHandler(w, r, srv.dataStore.importDataSourceTable)
+}
+
+func (srv *server) dataStoreTransferHandler(w http.ResponseWriter, r *http.Request, transfer func(log.FieldLogger, *cbTypes.ReportDataSource, string) (*DataStoreTransfer, error)) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+
+	name := chi.URLParam(r, "datasourceName")
+	var req DataStoreTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to decode request as JSON: %v", err)
+		return
+	}
+	if err := validateDataStoreLocation(req.Location); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "invalid location: %v", err)
+		return
+	}
+	dataSource, err := srv.listers.reportDataSources.Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			writeErrorResponse(logger, w, r, http.StatusNotFound, "ReportDataSource %s not found", name)
+			return
+		}
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to get ReportDataSource %s: %v", name, err)
+		return
+	}
+	if dataSource.Spec.Promsum == nil && dataSource.Spec.KubernetesObjects == nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "only Prometheus metrics and Kubernetes objects ReportDataSources can be exported and imported")
+		return
+	}
+	if dataSource.TableName == "" {
+		writeErrorResponse(logger, w, r, http.StatusConflict, "the table for ReportDataSource %s has not been created yet", name)
+		return
+	}
+
+	resp, err := transfer(logger.WithField("reportDataSource", name), dataSource, req.Location)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	writeResponseAsJSON(logger, w, http.StatusOK, resp)
+}
+
+// validateDataStoreLocation returns an error unless location is a
+// directory in HDFS or an object store.
+func validateDataStoreLocation(location string) error {
+	if location == "" {
+		return fmt.Errorf("location must be set")
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" {
+		return fmt.Errorf("location must include the filesystem, such as s3a://")
+	}
+	if strings.ContainsAny(location, "'\\") {
+		return fmt.Errorf("location must not contain quotes or backslashes")
+	}
+	return validateStorageLocationURL(u)
+}
+
+// dataSourceStorage returns the StorageLocation of the ReportDataSources
+// whose tables can be exported and imported, which are the tables the
+// reporting-operator writes the data it collects to.
+func dataSourceStorage(dataSource *cbTypes.ReportDataSource) *cbTypes.StorageLocationRef {
+	switch {
+	case dataSource.Spec.Promsum != nil:
+		return dataSource.Spec.Promsum.Storage
+	case dataSource.Spec.KubernetesObjects != nil:
+		return dataSource.Spec.KubernetesObjects.Storage
+	}
+	return nil
+}
+
+// exportDataSourceTable exports the ReportDataSource's table, including its
+// schema and the timestamp of its most recent row, to location using
+// Hive's EXPORT TABLE.
+func (op *Reporting) exportDataSourceTable(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource, location string) (*DataStoreTransfer, error) {
+	tableName := dataSource.TableName
+	lastTimestamp, err := prestostore.GetLastTimestampForTable(op.importerPrestoQueryer, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if lastTimestamp != nil {
+		// the table's properties are exported with it, so the checkpoint
+		// is kept with the data.
+		stmt := fmt.Sprintf("ALTER TABLE %s SET TBLPROPERTIES ('%s'='%s')", tableName, lastTimestampTableProperty, lastTimestamp.UTC().Format(time.RFC3339))
+		if _, err := op.hiveQueryer.Query(stmt); err != nil {
+			return nil, fmt.Errorf("unable to record the last timestamp of table %s: %v", tableName, err)
+		}
+	}
+
+	logger.Infof("exporting table %s to %s", tableName, location)
+	if _, err := op.hiveQueryer.Query(fmt.Sprintf("EXPORT TABLE %s TO '%s'", tableName, location)); err != nil {
+		return nil, fmt.Errorf("unable to export table %s to %s: %v", tableName, location, err)
+	}
+	logger.Infof("exported table %s to %s", tableName, location)
+	return &DataStoreTransfer{
+		DataSource:    dataSource.Name,
+		TableName:     tableName,
+		Location:      location,
+		LastTimestamp: lastTimestamp,
+	}, nil
+}
+
+// importDataSourceTable imports a table exported by exportDataSourceTable
+// from location into the ReportDataSource's table.
+//
+// The export is imported into a staging table using Hive's IMPORT TABLE,
+// and copied into the ReportDataSource's table with Presto. Only the rows
+// older than the earliest row already in the table are copied, so data
+// collected since the ReportDataSource was created isn't duplicated, and an
+// import can be repeated.
+func (op *Reporting) importDataSourceTable(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource, location string) (*DataStoreTransfer, error) {
+	tableName := dataSource.TableName
+	prestoTable, err := op.informers.Metering().V1alpha1().PrestoTables().Lister().PrestoTables(dataSource.Namespace).Get(prestoTableResourceNameFromKind("ReportDataSource", dataSource.Name))
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the PrestoTable of table %s: %v", tableName, err)
+	}
+	tableProperties, err := op.getHiveTableProperties(logger, dataSourceStorage(dataSource), "ReportDataSource")
+	if err != nil {
+		return nil, fmt.Errorf("storage incorrectly configured for ReportDataSource %s: %v", dataSource.Name, err)
+	}
+	stagingTableName := fmt.Sprintf("%s_import_%d", tableName, op.clock.Now().UnixNano())
+	stagingProperties, err := addTableNameToLocation(*tableProperties, stagingTableName)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("importing %s into staging table %s", location, stagingTableName)
+	stmt := fmt.Sprintf("IMPORT TABLE %s FROM '%s'", stagingTableName, location)
+	if stagingProperties.Location != "" {
+		stmt += fmt.Sprintf(" LOCATION '%s'", stagingProperties.Location)
+	}
+	if _, err := op.hiveQueryer.Query(stmt); err != nil {
+		return nil, fmt.Errorf("unable to import %s: %v", location, err)
+	}
+	defer func() {
+		if err := hive.ExecuteDropTable(op.hiveQueryer, stagingTableName, true); err != nil {
+			logger.WithError(err).Warnf("unable to drop staging table %s", stagingTableName)
+		}
+	}()
+
+	lastTimestamp, err := op.exportedLastTimestamp(stagingTableName)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := op.importerPrestoQueryer.Query(fmt.Sprintf(`SELECT min("timestamp") AS "timestamp" FROM %s`, tableName))
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the earliest timestamp of table %s: %v", tableName, err)
+	}
+	var earliest *time.Time
+	if len(rows) != 0 {
+		if ts, ok := rows[0]["timestamp"].(time.Time); ok {
+			earliest = &ts
+		}
+	}
+
+	logger.Infof("copying staging table %s into table %s", stagingTableName, tableName)
+	if err := presto.InsertInto(op.importerPrestoQueryer, tableName, importDataStoreQuery(stagingTableName, prestoTable, earliest)); err != nil {
+		return nil, fmt.Errorf("unable to copy the imported rows into table %s: %v", tableName, err)
+	}
+	logger.Infof("imported %s into table %s", location, tableName)
+	return &DataStoreTransfer{
+		DataSource:    dataSource.Name,
+		TableName:     tableName,
+		Location:      location,
+		LastTimestamp: lastTimestamp,
+	}, nil
+}
+
+// exportedLastTimestamp returns the last timestamp recorded in the
+// properties of an imported table, or nil if the exported table was empty.
+func (op *Reporting) exportedLastTimestamp(tableName string) (*time.Time, error) {
+	rows, err := op.hiveQueryer.Query(fmt.Sprintf("SHOW TBLPROPERTIES %s('%s')", tableName, lastTimestampTableProperty))
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the properties of table %s: %v", tableName, err)
+	}
+	defer rows.Close()
+	var value sql.NullString
+	if rows.Next() {
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// Hive returns a message rather than an error if the property isn't
+	// set.
+	ts, err := time.Parse(time.RFC3339, value.String)
+	if err != nil {
+		return nil, nil
+	}
+	return &ts, nil
+}
+
+// importDataStoreQuery returns the query selecting the rows of the staging
+// table to copy into the table of the PrestoTable, which are the rows older
+// than earliest, if it's set.
+func importDataStoreQuery(stagingTableName string, prestoTable *cbTypes.PrestoTable, earliest *time.Time) string {
+	var columns []string
+	for _, col := range prestoTable.State.Parameters.Columns {
+		columns = append(columns, fmt.Sprintf(`"%s"`, col.Name))
+	}
+	for _, col := range prestoTable.State.Parameters.Partitions {
+		columns = append(columns, fmt.Sprintf(`"%s"`, col.Name))
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(columns, ", "), stagingTableName)
+	if earliest != nil {
+		query += fmt.Sprintf(` WHERE "timestamp" < timestamp '%s'`, presto.Timestamp(*earliest))
+	}
+	return query
+}
@@ -0,0 +1,54 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+)
+
+func TestImportDataStoreQuery(t *testing.T) {
+	prestoTable := &cbTypes.PrestoTable{
+		State: cbTypes.PrestoTableState{
+			Parameters: cbTypes.TableParameters{
+				Columns:    promsumHiveColumns,
+				Partitions: []hive.Column{{Name: "dt", Type: "string"}},
+			},
+		},
+	}
+	assert.Equal(t,
+		`SELECT "amount", "timestamp", "timePrecision", "labels", "dt" FROM datasource_pod_request_cpu_cores_import_1`,
+		importDataStoreQuery("datasource_pod_request_cpu_cores_import_1", prestoTable, nil))
+
+	earliest := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t,
+		`SELECT "amount", "timestamp", "timePrecision", "labels", "dt" FROM datasource_pod_request_cpu_cores_import_1 WHERE "timestamp" < timestamp '2018-07-01 00:00:00.000'`,
+		importDataStoreQuery("datasource_pod_request_cpu_cores_import_1", prestoTable, &earliest))
+}
+
+func TestValidateDataStoreLocation(t *testing.T) {
+	tests := map[string]struct {
+		location    string
+		expectedErr bool
+	}{
+		"s3":                    {location: "s3a://metering/export/pod-request-cpu-cores"},
+		"hdfs":                  {location: "hdfs://hdfs-namenode-0.hdfs-namenode:9820/export"},
+		"empty":                 {location: "", expectedErr: true},
+		"no filesystem":         {location: "/export/pod-request-cpu-cores", expectedErr: true},
+		"s3 without bucket":     {location: "s3a:///export", expectedErr: true},
+		"unsupported":           {location: "ftp://example.com/export", expectedErr: true},
+		"quote in location":     {location: "s3a://metering/export'", expectedErr: true},
+		"backslash in location": {location: `s3a://metering/export\`, expectedErr: true},
+	}
+	for name, test := range tests {
+		err := validateDataStoreLocation(test.location)
+		if test.expectedErr {
+			assert.Error(t, err, name)
+		} else {
+			assert.NoError(t, err, name)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi"
+	log "github.com/sirupsen/logrus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+const (
+	APIV1DeletionImpactEndpoint       = "/api/v1/deletionimpact"
+	DeletionValidationWebhookEndpoint = "/validate-deletion"
+
+	dependencyKindReportGenerationQuery = "ReportGenerationQuery"
+)
+
+// deletionImpactResourceKinds maps the resource names accepted by the
+// deletion impact endpoint to the kind of the resource.
+var deletionImpactResourceKinds = map[string]string{
+	"reportgenerationqueries": dependencyKindReportGenerationQuery,
+	"reportdatasources":       dependencyKindReportDataSource,
+}
+
+// DeletionImpact describes what would break, or be removed, if a
+// ReportGenerationQuery or ReportDataSource was deleted.
+type DeletionImpact struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Dependents are the resources which directly or indirectly depend on
+	// the resource, and would fail once it is deleted.
+	Dependents []DeletionImpactDependent `json:"dependents"`
+	// RemovedTables are the tables which are dropped when the resource is
+	// deleted.
+	RemovedTables []string `json:"removedTables"`
+}
+
+// DeletionImpactDependent is a resource which depends on a resource being
+// deleted.
+type DeletionImpactDependent struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// DependsOn is the resource this resource directly depends on which
+	// would be deleted, or would break because of the deletion.
+	DependsOn string `json:"dependsOn"`
+	// TableName is the table or view populated by the resource, which would
+	// no longer be updated, if it has one.
+	TableName string `json:"tableName,omitempty"`
+}
+
+// deletionImpactResources are the resources considered when determining the
+// impact of deleting a resource.
+type deletionImpactResources struct {
+	generationQueries []*api.ReportGenerationQuery
+	dataSources       []*api.ReportDataSource
+	reports           []*api.Report
+	scheduledReports  []*api.ScheduledReport
+}
+
+func (srv *server) listDeletionImpactResources() (deletionImpactResources, error) {
+	var resources deletionImpactResources
+	var err error
+	resources.generationQueries, err = srv.listers.reportGenerationQueries.List(labels.Everything())
+	if err != nil {
+		return resources, err
+	}
+	resources.dataSources, err = srv.listers.reportDataSources.List(labels.Everything())
+	if err != nil {
+		return resources, err
+	}
+	resources.reports, err = srv.listers.reports.List(labels.Everything())
+	if err != nil {
+		return resources, err
+	}
+	resources.scheduledReports, err = srv.listers.scheduledReports.List(labels.Everything())
+	if err != nil {
+		return resources, err
+	}
+	return resources, nil
+}
+
+// getDeletionImpact returns every resource which directly or indirectly
+// depends on the resource, and the tables removed when it's deleted.
+func getDeletionImpact(kind, name string, resources deletionImpactResources) (DeletionImpact, error) {
+	target := reportNode{kind: kind, name: name}
+	impact := DeletionImpact{
+		Kind:          kind,
+		Name:          name,
+		Dependents:    []DeletionImpactDependent{},
+		RemovedTables: []string{},
+	}
+
+	if kind == dependencyKindReportDataSource {
+		for _, dataSource := range resources.dataSources {
+			if dataSource.Name != name {
+				continue
+			}
+			impact.RemovedTables = append(impact.RemovedTables, dataSourceTableName(name))
+			if dataSource.Spec.Promsum != nil && dataSource.Spec.Promsum.Exemplars != nil {
+				impact.RemovedTables = append(impact.RemovedTables, dataSourceExemplarsTableName(name))
+			}
+			if dataSource.Spec.RemoteReport != nil && dataSource.Spec.RemoteReport.S3 != nil {
+				impact.RemovedTables = append(impact.RemovedTables, dataSourceRemoteTableName(name))
+			}
+			if dataSource.Spec.ObjectStorage != nil {
+				impact.RemovedTables = append(impact.RemovedTables, dataSourceFilesTableName(name))
+			}
+		}
+	}
+
+	// build a reverse index of each resource to the resources which
+	// directly depend on it
+	dependents := make(map[reportNode][]reportNode)
+	tableNames := make(map[reportNode]string)
+	for _, query := range resources.generationQueries {
+		node := reportNode{kind: dependencyKindReportGenerationQuery, name: query.Name}
+		tableNames[node] = query.ViewName
+		dependencies, err := getGenerationQueryDirectDependencies(query)
+		if err != nil {
+			return impact, err
+		}
+		for _, dependency := range dependencies {
+			dependents[dependency] = append(dependents[dependency], node)
+		}
+	}
+	for _, report := range resources.reports {
+		node := reportNode{kind: dependencyKindReport, name: report.Name}
+		tableNames[node] = reportTableName(report.Name)
+		query := reportNode{kind: dependencyKindReportGenerationQuery, name: report.Spec.GenerationQueryName}
+		dependents[query] = append(dependents[query], node)
+	}
+	for _, report := range resources.scheduledReports {
+		node := reportNode{kind: dependencyKindScheduledReport, name: report.Name}
+		tableNames[node] = scheduledReportTableName(report.Name)
+		query := reportNode{kind: dependencyKindReportGenerationQuery, name: report.Spec.GenerationQueryName}
+		dependents[query] = append(dependents[query], node)
+	}
+
+	// breadth first search from the resource being deleted, so each
+	// dependent is reported with the shortest path to it
+	visited := map[reportNode]struct{}{target: {}}
+	queue := []reportNode{target}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, dependent := range dependents[node] {
+			if _, seen := visited[dependent]; seen {
+				continue
+			}
+			visited[dependent] = struct{}{}
+			queue = append(queue, dependent)
+			impact.Dependents = append(impact.Dependents, DeletionImpactDependent{
+				Kind:      dependent.kind,
+				Name:      dependent.name,
+				DependsOn: node.String(),
+				TableName: tableNames[dependent],
+			})
+		}
+	}
+
+	sort.Slice(impact.Dependents, func(i, j int) bool {
+		if impact.Dependents[i].Kind != impact.Dependents[j].Kind {
+			return impact.Dependents[i].Kind < impact.Dependents[j].Kind
+		}
+		return impact.Dependents[i].Name < impact.Dependents[j].Name
+	})
+	return impact, nil
+}
+
+// getGenerationQueryDirectDependencies returns the resources the
+// generationQuery references directly, either in its spec, or by using
+// template functions in its query.
+func getGenerationQueryDirectDependencies(generationQuery *api.ReportGenerationQuery) ([]reportNode, error) {
+	references := []struct {
+		kind          string
+		names         []string
+		templateFuncs []string
+	}{
+		{
+			kind:          dependencyKindReportDataSource,
+			names:         generationQuery.Spec.DataSources,
+			templateFuncs: []string{"dataSourceTableName"},
+		},
+		{
+			kind:          dependencyKindReportGenerationQuery,
+			names:         append(append([]string(nil), generationQuery.Spec.ReportQueries...), generationQuery.Spec.DynamicReportQueries...),
+			templateFuncs: []string{"generationQueryViewName", "renderReportGenerationQuery"},
+		},
+		{
+			kind:          dependencyKindReport,
+			names:         generationQuery.Spec.Reports,
+			templateFuncs: []string{"reportTableName"},
+		},
+		{
+			kind:          dependencyKindScheduledReport,
+			names:         generationQuery.Spec.ScheduledReports,
+			templateFuncs: []string{"scheduledReportTableName"},
+		},
+	}
+
+	seen := make(map[reportNode]struct{})
+	var dependencies []reportNode
+	for _, ref := range references {
+		names := ref.names
+		for _, templateFunc := range ref.templateFuncs {
+			referenced, err := getTemplateReferences(generationQuery.Spec.Query, templateFunc)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse query for ReportGenerationQuery %s: %v", generationQuery.Name, err)
+			}
+			names = append(names, referenced...)
+		}
+		for _, name := range names {
+			node := reportNode{kind: ref.kind, name: name}
+			if _, exists := seen[node]; !exists {
+				seen[node] = struct{}{}
+				dependencies = append(dependencies, node)
+			}
+		}
+	}
+	return dependencies, nil
+}
+
+// getDeletionImpactHandler returns the DeletionImpact of deleting the
+// ReportGenerationQuery or ReportDataSource in the URL.
+func (srv *server) getDeletionImpactHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	resource := chi.URLParam(r, "resource")
+	name := chi.URLParam(r, "name")
+	kind, ok := deletionImpactResourceKinds[strings.ToLower(resource)]
+	if !ok {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "unsupported resource %q, must be one of reportgenerationqueries or reportdatasources", resource)
+		return
+	}
+
+	_, err := srv.getDeletionTarget(kind, name)
+	if k8serrors.IsNotFound(err) {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "%s %s does not exist", kind, name)
+		return
+	} else if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to get %s %s: %v", kind, name, err)
+		return
+	}
+
+	resources, err := srv.listDeletionImpactResources()
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to list resources: %v", err)
+		return
+	}
+	impact, err := getDeletionImpact(kind, name, resources)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to determine deletion impact of %s %s: %v", kind, name, err)
+		return
+	}
+	writeResponseAsJSON(logger, w, http.StatusOK, impact)
+}
+
+// getDeletionTarget returns the ReportGenerationQuery or ReportDataSource
+// identified by kind and name.
+func (srv *server) getDeletionTarget(kind, name string) (meta.Object, error) {
+	if kind == dependencyKindReportGenerationQuery {
+		return srv.listers.reportGenerationQueries.Get(name)
+	}
+	return srv.listers.reportDataSources.Get(name)
+}
+
+// admissionReview mirrors the admission.k8s.io/v1beta1 AdmissionReview sent
+// by the Kubernetes API server to validating admission webhooks.
+type admissionReview struct {
+	meta.TypeMeta `json:",inline"`
+	Request       *admissionRequest  `json:"request,omitempty"`
+	Response      *admissionResponse `json:"response,omitempty"`
+}
+
+type admissionRequest struct {
+	UID       types.UID             `json:"uid"`
+	Kind      meta.GroupVersionKind `json:"kind"`
+	Name      string                `json:"name,omitempty"`
+	Namespace string                `json:"namespace,omitempty"`
+	Operation string                `json:"operation"`
+	// Object and OldObject are the object being created or updated, and
+	// the object before an update.
+	Object    json.RawMessage `json:"object,omitempty"`
+	OldObject json.RawMessage `json:"oldObject,omitempty"`
+}
+
+type admissionResponse struct {
+	UID     types.UID    `json:"uid"`
+	Allowed bool         `json:"allowed"`
+	Result  *meta.Status `json:"result,omitempty"`
+	// Patch is the JSON patch mutating admission webhooks apply to the
+	// object, and PatchType is always JSONPatch when it's set.
+	Patch     []byte  `json:"patch,omitempty"`
+	PatchType *string `json:"patchType,omitempty"`
+}
+
+// deletionValidationWebhookHandler is a validating admission webhook which
+// rejects deleting a ReportGenerationQuery or ReportDataSource while other
+// resources depend on it.
+func (srv *server) deletionValidationWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	if r.Method != http.MethodPost {
+		writeErrorResponse(logger, w, r, http.StatusMethodNotAllowed, "admission requests must be POST requests")
+		return
+	}
+
+	var review admissionReview
+	err := json.NewDecoder(r.Body).Decode(&review)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to decode AdmissionReview: %v", err)
+		return
+	}
+	if review.Request == nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "AdmissionReview request is missing")
+		return
+	}
+
+	review.Response = srv.validateDeletion(logger, review.Request)
+	review.Request = nil
+	writeResponseAsJSON(logger, w, http.StatusOK, review)
+}
+
+func (srv *server) validateDeletion(logger log.FieldLogger, req *admissionRequest) *admissionResponse {
+	resp := &admissionResponse{UID: req.UID, Allowed: true}
+	kind := req.Kind.Kind
+	if req.Operation != "DELETE" || (kind != dependencyKindReportGenerationQuery && kind != dependencyKindReportDataSource) {
+		return resp
+	}
+	// only resources in the namespace the operator manages are known
+	target, err := srv.getDeletionTarget(kind, req.Name)
+	if err != nil || target.GetNamespace() != req.Namespace {
+		return resp
+	}
+
+	resources, err := srv.listDeletionImpactResources()
+	if err != nil {
+		// fail open, the webhook's failurePolicy determines what
+		// happens if the webhook itself is unavailable
+		logger.WithError(err).Errorf("unable to list resources to validate deletion of %s %s", kind, req.Name)
+		return resp
+	}
+	impact, err := getDeletionImpact(kind, req.Name, resources)
+	if err != nil {
+		logger.WithError(err).Errorf("unable to determine deletion impact of %s %s", kind, req.Name)
+		return resp
+	}
+	if len(impact.Dependents) == 0 {
+		return resp
+	}
+
+	dependents := make([]string, len(impact.Dependents))
+	for i, dependent := range impact.Dependents {
+		dependents[i] = reportNode{kind: dependent.Kind, name: dependent.Name}.String()
+	}
+	msg := fmt.Sprintf("cannot delete %s %s, the following resources depend on it: %s", kind, req.Name, strings.Join(dependents, ", "))
+	logger.Infof("rejecting deletion: %s", msg)
+	resp.Allowed = false
+	resp.Result = &meta.Status{
+		Status:  meta.StatusFailure,
+		Message: msg,
+		Reason:  meta.StatusReasonForbidden,
+		Code:    http.StatusForbidden,
+	}
+	return resp
+}
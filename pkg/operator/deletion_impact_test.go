@@ -0,0 +1,112 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestGetDeletionImpact(t *testing.T) {
+	dataSource := &v1alpha1.ReportDataSource{
+		ObjectMeta: meta.ObjectMeta{Name: "pod-cpu"},
+		Spec: v1alpha1.ReportDataSourceSpec{
+			Promsum: &v1alpha1.PrometheusMetricsDataSource{
+				Exemplars: &v1alpha1.PrometheusExemplars{},
+			},
+		},
+	}
+	unusedDataSource := &v1alpha1.ReportDataSource{
+		ObjectMeta: meta.ObjectMeta{Name: "unused"},
+	}
+	rawQuery := &v1alpha1.ReportGenerationQuery{
+		ObjectMeta: meta.ObjectMeta{Name: "pod-cpu-raw"},
+		Spec: v1alpha1.ReportGenerationQuerySpec{
+			DataSources: []string{"pod-cpu"},
+		},
+		ViewName: generationQueryViewName("pod-cpu-raw"),
+	}
+	namespaceQuery := &v1alpha1.ReportGenerationQuery{
+		ObjectMeta: meta.ObjectMeta{Name: "namespace-cpu"},
+		Spec: v1alpha1.ReportGenerationQuerySpec{
+			Query: `SELECT * FROM {| generationQueryViewName "pod-cpu-raw" |}`,
+		},
+	}
+	rollupQuery := &v1alpha1.ReportGenerationQuery{
+		ObjectMeta: meta.ObjectMeta{Name: "namespace-cpu-rollup"},
+		Spec: v1alpha1.ReportGenerationQuerySpec{
+			Query: `SELECT * FROM {| scheduledReportTableName "namespace-cpu-daily" |}`,
+		},
+	}
+	resources := deletionImpactResources{
+		generationQueries: []*v1alpha1.ReportGenerationQuery{rawQuery, namespaceQuery, rollupQuery},
+		dataSources:       []*v1alpha1.ReportDataSource{dataSource, unusedDataSource},
+		reports: []*v1alpha1.Report{
+			{
+				ObjectMeta: meta.ObjectMeta{Name: "namespace-cpu-once"},
+				Spec:       v1alpha1.ReportSpec{GenerationQueryName: "namespace-cpu"},
+			},
+		},
+		scheduledReports: []*v1alpha1.ScheduledReport{
+			{
+				ObjectMeta: meta.ObjectMeta{Name: "namespace-cpu-daily"},
+				Spec:       v1alpha1.ScheduledReportSpec{GenerationQueryName: "namespace-cpu"},
+			},
+			{
+				ObjectMeta: meta.ObjectMeta{Name: "namespace-cpu-monthly"},
+				Spec:       v1alpha1.ScheduledReportSpec{GenerationQueryName: "namespace-cpu-rollup"},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		kind                  string
+		name                  string
+		expectedDependents    []DeletionImpactDependent
+		expectedRemovedTables []string
+	}{
+		"dataSource used by a chain of queries and reports": {
+			kind: dependencyKindReportDataSource,
+			name: "pod-cpu",
+			expectedDependents: []DeletionImpactDependent{
+				{Kind: dependencyKindReport, Name: "namespace-cpu-once", DependsOn: "ReportGenerationQuery/namespace-cpu", TableName: reportTableName("namespace-cpu-once")},
+				{Kind: dependencyKindReportGenerationQuery, Name: "namespace-cpu", DependsOn: "ReportGenerationQuery/pod-cpu-raw"},
+				{Kind: dependencyKindReportGenerationQuery, Name: "namespace-cpu-rollup", DependsOn: "ScheduledReport/namespace-cpu-daily"},
+				{Kind: dependencyKindReportGenerationQuery, Name: "pod-cpu-raw", DependsOn: "ReportDataSource/pod-cpu", TableName: generationQueryViewName("pod-cpu-raw")},
+				{Kind: dependencyKindScheduledReport, Name: "namespace-cpu-daily", DependsOn: "ReportGenerationQuery/namespace-cpu", TableName: scheduledReportTableName("namespace-cpu-daily")},
+				{Kind: dependencyKindScheduledReport, Name: "namespace-cpu-monthly", DependsOn: "ReportGenerationQuery/namespace-cpu-rollup", TableName: scheduledReportTableName("namespace-cpu-monthly")},
+			},
+			expectedRemovedTables: []string{dataSourceTableName("pod-cpu"), dataSourceExemplarsTableName("pod-cpu")},
+		},
+		"query used by a rollup": {
+			kind: dependencyKindReportGenerationQuery,
+			name: "namespace-cpu-rollup",
+			expectedDependents: []DeletionImpactDependent{
+				{Kind: dependencyKindScheduledReport, Name: "namespace-cpu-monthly", DependsOn: "ReportGenerationQuery/namespace-cpu-rollup", TableName: scheduledReportTableName("namespace-cpu-monthly")},
+			},
+			expectedRemovedTables: []string{},
+		},
+		"unused dataSource": {
+			kind:                  dependencyKindReportDataSource,
+			name:                  "unused",
+			expectedDependents:    []DeletionImpactDependent{},
+			expectedRemovedTables: []string{dataSourceTableName("unused")},
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			impact, err := getDeletionImpact(tt.kind, tt.name, resources)
+			require.NoError(t, err)
+			assert.Equal(t, tt.kind, impact.Kind)
+			assert.Equal(t, tt.name, impact.Name)
+			assert.Equal(t, tt.expectedDependents, impact.Dependents)
+			assert.Equal(t, tt.expectedRemovedTables, impact.RemovedTables)
+		})
+	}
+}
@@ -0,0 +1,504 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/aws"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/secrets"
+	"github.com/operator-framework/operator-metering/pkg/util/orderedmap"
+)
+
+const (
+	defaultDeliveryFormat = "csv"
+
+	// deliveryTimeLayout is the layout of the period start and end in the
+	// names of delivered objects.
+	deliveryTimeLayout = "20060102T150405Z"
+
+	// gcsEndpoint is the endpoint of GCS's S3 compatible XML API.
+	gcsEndpoint = "https://storage.googleapis.com"
+	gcsRegion   = "auto"
+
+	// azureSASTokenKey is the key within a secret containing an Azure
+	// shared access signature token.
+	azureSASTokenKey = "sas-token"
+
+	// webhookSigningKey is the key within a secret containing the key
+	// requests to webhooks are signed with.
+	webhookSigningKey = "hmac-key"
+	// webhookSignatureHeader contains the hex encoded HMAC-SHA256 of the
+	// request body, prefixed with sha256=.
+	webhookSignatureHeader = "X-Metering-Signature"
+
+	defaultWebhookMaxAttempts = 5
+	// webhookRetryBackoff is multiplied by the attempt number to determine
+	// how long to wait before retrying a request to a webhook.
+	webhookRetryBackoff = 5 * time.Second
+)
+
+type deliveryFormat struct {
+	extension   string
+	contentType string
+}
+
+var deliveryFormats = map[string]deliveryFormat{
+	"csv":     {extension: "csv", contentType: "text/csv"},
+	"json":    {extension: "json", contentType: "application/json"},
+	"parquet": {extension: "parquet", contentType: "application/octet-stream"},
+	"xlsx":    {extension: "xlsx", contentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+	"html":    {extension: "html", contentType: "text/html; charset=utf-8"},
+	"pdf":     {extension: "pdf", contentType: "application/pdf"},
+}
+
+func validateReportDeliveries(deliveries []cbTypes.ReportDelivery) error {
+	names := make(map[string]bool)
+	for i, delivery := range deliveries {
+		if delivery.Name == "" {
+			return fmt.Errorf("deliveries[%d]: name must be set", i)
+		}
+		if names[delivery.Name] {
+			return fmt.Errorf("delivery %s: name must be unique", delivery.Name)
+		}
+		names[delivery.Name] = true
+		if err := validateReportDelivery(delivery); err != nil {
+			return fmt.Errorf("delivery %s: %v", delivery.Name, err)
+		}
+	}
+	return nil
+}
+
+func validateReportDelivery(delivery cbTypes.ReportDelivery) error {
+	if delivery.Format != "" {
+		if _, ok := deliveryFormats[delivery.Format]; !ok {
+			return fmt.Errorf("format must be one of: csv, json, parquet, xlsx, html or pdf, got %q", delivery.Format)
+		}
+	}
+	if (delivery.Format == "html" || delivery.Format == "pdf") && delivery.Template == "" {
+		return fmt.Errorf("template must be set for the %s format", delivery.Format)
+	}
+	destinations := 0
+	if delivery.S3 != nil {
+		destinations++
+		if delivery.S3.Bucket == "" || delivery.S3.Region == "" {
+			return fmt.Errorf("s3.bucket and s3.region must be set")
+		}
+		if delivery.S3.CredentialsSecret != "" {
+			if _, err := secrets.ParseRef(delivery.S3.CredentialsSecret); err != nil {
+				return fmt.Errorf("invalid s3.credentialsSecret: %v", err)
+			}
+		}
+	}
+	if delivery.GCS != nil {
+		destinations++
+		if delivery.GCS.Bucket == "" {
+			return fmt.Errorf("gcs.bucket must be set")
+		}
+		if _, err := secrets.ParseRef(delivery.GCS.CredentialsSecret); err != nil {
+			return fmt.Errorf("invalid gcs.credentialsSecret: %v", err)
+		}
+	}
+	if delivery.Azure != nil {
+		destinations++
+		if delivery.Azure.Account == "" || delivery.Azure.Container == "" {
+			return fmt.Errorf("azure.account and azure.container must be set")
+		}
+		if _, err := secrets.ParseRef(delivery.Azure.SASTokenSecret); err != nil {
+			return fmt.Errorf("invalid azure.sasTokenSecret: %v", err)
+		}
+		if delivery.Azure.Endpoint != "" {
+			if _, err := url.Parse(delivery.Azure.Endpoint); err != nil {
+				return fmt.Errorf("invalid azure.endpoint: %v", err)
+			}
+		}
+	}
+	if delivery.Webhook != nil {
+		destinations++
+		if delivery.Format != "" && delivery.Format != "json" {
+			return fmt.Errorf("format must be json for webhooks, got %q", delivery.Format)
+		}
+		u, err := url.Parse(delivery.Webhook.URL)
+		if err != nil {
+			return fmt.Errorf("invalid webhook.url: %v", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("webhook.url must be an http or https URL")
+		}
+		if delivery.Webhook.SigningSecret != "" {
+			if _, err := secrets.ParseRef(delivery.Webhook.SigningSecret); err != nil {
+				return fmt.Errorf("invalid webhook.signingSecret: %v", err)
+			}
+		}
+		if delivery.Webhook.BatchSize < 0 || delivery.Webhook.MaxAttempts < 0 {
+			return fmt.Errorf("webhook.batchSize and webhook.maxAttempts must not be negative")
+		}
+	}
+	if destinations != 1 {
+		return fmt.Errorf("exactly one of s3, gcs, azure or webhook must be set")
+	}
+	return nil
+}
+
+// deliverReportResults uploads the results in the report's table to each of
+// its delivery destinations, returning the status of each delivery. Failed
+// deliveries are recorded in the status rather than failing the report, since
+// the results were generated successfully.
+func (op *Reporting) deliverReportResults(ctx context.Context, logger logrus.FieldLogger, kind, namespace, name, tableName string, generationQuery *cbTypes.ReportGenerationQuery, groupByLabelKeys []string, deliveries []cbTypes.ReportDelivery, periodStart, periodEnd time.Time) []cbTypes.ReportDeliveryStatus {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	columns, results, err := op.getDeliveryResults(tableName, generationQuery, groupByLabelKeys)
+	if err != nil {
+		err = fmt.Errorf("unable to get report results: %v", err)
+	}
+
+	now := op.clock.Now().UTC()
+	// results are only encoded once for each format and template
+	encoded := make(map[string][]byte)
+	statuses := make([]cbTypes.ReportDeliveryStatus, len(deliveries))
+	for i, delivery := range deliveries {
+		status := cbTypes.ReportDeliveryStatus{
+			Name: delivery.Name,
+			Time: metav1.Time{Time: now},
+		}
+		deliveryErr := err
+		if deliveryErr == nil && delivery.Webhook != nil {
+			status.URL = delivery.Webhook.URL
+			deliveryErr = op.postResultsToWebhook(ctx, logger, delivery.Webhook, namespace, name, periodStart, periodEnd, results)
+		} else if deliveryErr == nil {
+			format := delivery.Format
+			if format == "" {
+				format = defaultDeliveryFormat
+			}
+			encodedKey := format + "/" + delivery.Template
+			body, ok := encoded[encodedKey]
+			if !ok {
+				if format == "html" || format == "pdf" {
+					data := newReportTemplateData(kind, namespace, name, periodStart, periodEnd, columns, results)
+					body, deliveryErr = op.renderReportTemplate(namespace, delivery.Template, format, data)
+				} else {
+					body, deliveryErr = encodeDeliveryResults(format, columns, results)
+				}
+				if deliveryErr == nil {
+					encoded[encodedKey] = body
+				}
+			}
+			if deliveryErr == nil {
+				key := deliveryObjectKey(deliveryPrefix(delivery), namespace, name, periodStart, periodEnd, deliveryFormats[format].extension)
+				status.URL, deliveryErr = op.uploadReportResults(ctx, delivery, key, deliveryFormats[format].contentType, body)
+			}
+		}
+		if deliveryErr != nil {
+			status.Error = deliveryErr.Error()
+			logger.WithError(deliveryErr).Errorf("failed to deliver report results to %s", delivery.Name)
+		} else {
+			logger.Infof("delivered report results to %s", status.URL)
+		}
+		statuses[i] = status
+	}
+	return statuses
+}
+
+func (op *Reporting) getDeliveryResults(tableName string, generationQuery *cbTypes.ReportGenerationQuery, groupByLabelKeys []string) ([]cbTypes.ReportGenerationQueryColumn, []presto.Row, error) {
+	groupByLabels, err := getGroupByLabels(generationQuery, groupByLabelKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+	columns := getReportColumns(generationQuery, groupByLabels)
+	prestoColumns, err := generatePrestoColumns(columns)
+	if err != nil {
+		return nil, nil, err
+	}
+	results, err := presto.GetRows(op.prestoQueryer, tableName, prestoColumns)
+	if err != nil {
+		return nil, nil, err
+	}
+	return columns, results, nil
+}
+
+// encodeDeliveryResults encodes the results in one of the deliveryFormats.
+func encodeDeliveryResults(format string, columns []cbTypes.ReportGenerationQueryColumn, results []presto.Row) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "csv":
+		err = writeResultsAsCSV(columns, results, &buf, defaultCSVFormat)
+	case "json":
+		var rows []*orderedmap.OrderedMap
+		rows, err = resultsToOrderedMaps(results)
+		if err != nil {
+			return nil, err
+		}
+		err = json.NewEncoder(&buf).Encode(rows)
+	case "parquet":
+		err = writeResultsAsParquet(columns, results, &buf)
+	case "xlsx":
+		err = writeResultsAsXLSX(columns, results, &buf, false)
+	default:
+		err = fmt.Errorf("unsupported format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func resultsToOrderedMaps(results []presto.Row) ([]*orderedmap.OrderedMap, error) {
+	rows := make([]*orderedmap.OrderedMap, len(results))
+	for i, row := range results {
+		var err error
+		rows[i], err = orderedmap.NewFromMap(row)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+func deliveryPrefix(delivery cbTypes.ReportDelivery) string {
+	switch {
+	case delivery.S3 != nil:
+		return delivery.S3.Prefix
+	case delivery.GCS != nil:
+		return delivery.GCS.Prefix
+	case delivery.Azure != nil:
+		return delivery.Azure.Prefix
+	}
+	return ""
+}
+
+// deliveryObjectKey returns the name of the object the results of a run
+// covering periodStart to periodEnd are uploaded to, which is
+// <prefix>/<namespace>/<name>/<periodStart>-<periodEnd>.<extension>.
+func deliveryObjectKey(prefix, namespace, name string, periodStart, periodEnd time.Time, extension string) string {
+	fileName := fmt.Sprintf("%s-%s.%s", periodStart.UTC().Format(deliveryTimeLayout), periodEnd.UTC().Format(deliveryTimeLayout), extension)
+	return strings.TrimPrefix(path.Join(prefix, namespace, name, fileName), "/")
+}
+
+// uploadReportResults uploads body to key in the delivery's destination,
+// returning the URL of the uploaded object.
+func (op *Reporting) uploadReportResults(ctx context.Context, delivery cbTypes.ReportDelivery, key, contentType string, body []byte) (string, error) {
+	switch {
+	case delivery.S3 != nil:
+		creds := op.awsCredentials
+		if delivery.S3.CredentialsSecret != "" {
+			ref, err := secrets.ParseRef(delivery.S3.CredentialsSecret)
+			if err != nil {
+				return "", err
+			}
+			creds = aws.NewSecretCredentials(op.secretResolver, ref)
+		}
+		uploader := aws.NewUploader(delivery.S3.Region, delivery.S3.Endpoint, delivery.S3.Bucket, creds)
+		if err := uploader.Upload(ctx, key, contentType, body); err != nil {
+			return "", fmt.Errorf("unable to upload results to S3: %v", err)
+		}
+		return fmt.Sprintf("s3://%s/%s", delivery.S3.Bucket, key), nil
+	case delivery.GCS != nil:
+		ref, err := secrets.ParseRef(delivery.GCS.CredentialsSecret)
+		if err != nil {
+			return "", err
+		}
+		uploader := aws.NewUploader(gcsRegion, gcsEndpoint, delivery.GCS.Bucket, aws.NewSecretCredentials(op.secretResolver, ref))
+		if err := uploader.Upload(ctx, key, contentType, body); err != nil {
+			return "", fmt.Errorf("unable to upload results to GCS: %v", err)
+		}
+		return fmt.Sprintf("gs://%s/%s", delivery.GCS.Bucket, key), nil
+	case delivery.Azure != nil:
+		return op.uploadToAzureBlob(ctx, delivery.Azure, key, contentType, body)
+	}
+	return "", fmt.Errorf("no destination set")
+}
+
+// uploadToAzureBlob uploads body as a block blob using the Blob service's
+// Put Blob operation, authenticated with a shared access signature,
+// returning the URL of the blob without the signature.
+func (op *Reporting) uploadToAzureBlob(ctx context.Context, dest *cbTypes.AzureBlobDeliveryDestination, key, contentType string, body []byte) (string, error) {
+	ref, err := secrets.ParseRef(dest.SASTokenSecret)
+	if err != nil {
+		return "", err
+	}
+	sasToken, err := op.secretResolver.GetValue(ctx, ref, azureSASTokenKey)
+	if err != nil {
+		return "", err
+	}
+	blobURL, err := azureBlobURL(dest, key)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, blobURL+"?"+strings.TrimPrefix(sasToken, "?"), bytes.NewReader(body))
+	if err != nil {
+		// the error contains the URL, which includes the signature
+		return "", fmt.Errorf("unable to create request to upload to %s", blobURL)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return "", fmt.Errorf("unable to upload results to %s: %v", blobURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return "", fmt.Errorf("unable to upload results to %s, got status %d: %s", blobURL, resp.StatusCode, respBody)
+	}
+	return blobURL, nil
+}
+
+// azureBlobURL returns the URL of the blob named key in the destination's
+// container.
+func azureBlobURL(dest *cbTypes.AzureBlobDeliveryDestination, key string) (string, error) {
+	endpoint := dest.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", dest.Account)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid azure.endpoint: %v", err)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + dest.Container + "/" + key
+	return u.String(), nil
+}
+
+// webhookDeliveryPayload is the body of each request posting a report's
+// results to a webhook.
+type webhookDeliveryPayload struct {
+	Namespace   string    `json:"namespace"`
+	Name        string    `json:"name"`
+	PeriodStart time.Time `json:"periodStart"`
+	PeriodEnd   time.Time `json:"periodEnd"`
+	// Batch is the 1-indexed number of this request's batch of results, out
+	// of Batches.
+	Batch   int                      `json:"batch"`
+	Batches int                      `json:"batches"`
+	Results []*orderedmap.OrderedMap `json:"results"`
+}
+
+// postResultsToWebhook posts the results to the webhook in batches of at
+// most BatchSize rows, retrying each batch until it's accepted or it has
+// been attempted MaxAttempts times.
+func (op *Reporting) postResultsToWebhook(ctx context.Context, logger logrus.FieldLogger, dest *cbTypes.WebhookDeliveryDestination, namespace, name string, periodStart, periodEnd time.Time, results []presto.Row) error {
+	var signingKey []byte
+	if dest.SigningSecret != "" {
+		ref, err := secrets.ParseRef(dest.SigningSecret)
+		if err != nil {
+			return err
+		}
+		key, err := op.secretResolver.GetValue(ctx, ref, webhookSigningKey)
+		if err != nil {
+			return err
+		}
+		signingKey = []byte(key)
+	}
+	maxAttempts := dest.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = defaultWebhookMaxAttempts
+	}
+	rows, err := resultsToOrderedMaps(results)
+	if err != nil {
+		return err
+	}
+
+	batches := webhookBatches(rows, dest.BatchSize)
+	for i, batch := range batches {
+		body, err := json.Marshal(webhookDeliveryPayload{
+			Namespace:   namespace,
+			Name:        name,
+			PeriodStart: periodStart.UTC(),
+			PeriodEnd:   periodEnd.UTC(),
+			Batch:       i + 1,
+			Batches:     len(batches),
+			Results:     batch,
+		})
+		if err != nil {
+			return err
+		}
+		for attempt := 1; ; attempt++ {
+			var retry bool
+			retry, err = postToWebhook(ctx, dest.URL, body, signingKey)
+			if err == nil {
+				break
+			}
+			if !retry || attempt >= maxAttempts {
+				return fmt.Errorf("failed to post batch %d of %d after %d attempts: %v", i+1, len(batches), attempt, err)
+			}
+			backoff := webhookRetryBackoff * time.Duration(attempt)
+			logger.WithError(err).Warnf("failed to post batch %d of %d to webhook, retrying in %s", i+1, len(batches), backoff)
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("failed to post batch %d of %d after %d attempts: %v", i+1, len(batches), attempt, ctx.Err())
+			case <-op.clock.After(backoff):
+			}
+		}
+	}
+	return nil
+}
+
+// webhookBatches splits rows into batches of at most batchSize rows. There's
+// always at least one batch, so reports without any results are still
+// delivered.
+func webhookBatches(rows []*orderedmap.OrderedMap, batchSize int) [][]*orderedmap.OrderedMap {
+	if batchSize <= 0 || len(rows) <= batchSize {
+		return [][]*orderedmap.OrderedMap{rows}
+	}
+	var batches [][]*orderedmap.OrderedMap
+	for start := 0; start < len(rows); start += batchSize {
+		end := start + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		batches = append(batches, rows[start:end])
+	}
+	return batches
+}
+
+// postToWebhook posts body to target, signing it with signingKey if it's
+// set. It returns whether the request should be retried if it fails, which
+// is the case for connection errors, 429 and 5xx responses.
+func postToWebhook(ctx context.Context, target string, body, signingKey []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if signingKey != nil {
+		req.Header.Set(webhookSignatureHeader, "sha256="+signWebhookBody(signingKey, body))
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("got status %d: %s", resp.StatusCode, respBody)
+	}
+	return false, nil
+}
+
+// signWebhookBody returns the hex encoded HMAC-SHA256 of body.
+func signWebhookBody(key, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,261 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/secrets"
+)
+
+func TestValidateReportDeliveries(t *testing.T) {
+	s3 := &cbTypes.S3DeliveryDestination{S3Bucket: cbTypes.S3Bucket{Region: "us-east-1", Bucket: "reports"}}
+	tests := map[string]struct {
+		deliveries []cbTypes.ReportDelivery
+		wantErr    bool
+	}{
+		"none": {},
+		"s3": {
+			deliveries: []cbTypes.ReportDelivery{{Name: "finance", Format: "parquet", S3: s3}},
+		},
+		"gcs and azure": {
+			deliveries: []cbTypes.ReportDelivery{
+				{Name: "gcs", GCS: &cbTypes.GCSDeliveryDestination{GCSBucket: cbTypes.GCSBucket{Bucket: "reports"}, CredentialsSecret: "kubernetes://gcs-hmac"}},
+				{Name: "azure", Azure: &cbTypes.AzureBlobDeliveryDestination{Account: "metering", Container: "reports", SASTokenSecret: "kubernetes://azure-sas"}},
+			},
+		},
+		"missing name": {
+			deliveries: []cbTypes.ReportDelivery{{S3: s3}},
+			wantErr:    true,
+		},
+		"duplicate name": {
+			deliveries: []cbTypes.ReportDelivery{{Name: "finance", S3: s3}, {Name: "finance", S3: s3}},
+			wantErr:    true,
+		},
+		"invalid format": {
+			deliveries: []cbTypes.ReportDelivery{{Name: "finance", Format: "tabular", S3: s3}},
+			wantErr:    true,
+		},
+		"no destination": {
+			deliveries: []cbTypes.ReportDelivery{{Name: "finance"}},
+			wantErr:    true,
+		},
+		"multiple destinations": {
+			deliveries: []cbTypes.ReportDelivery{{Name: "finance", S3: s3, GCS: &cbTypes.GCSDeliveryDestination{GCSBucket: cbTypes.GCSBucket{Bucket: "reports"}, CredentialsSecret: "kubernetes://gcs-hmac"}}},
+			wantErr:    true,
+		},
+		"webhook": {
+			deliveries: []cbTypes.ReportDelivery{{Name: "billing", Webhook: &cbTypes.WebhookDeliveryDestination{URL: "https://billing.example.com/ingest", SigningSecret: "kubernetes://billing-hmac", BatchSize: 1000}}},
+		},
+		"webhook with csv format": {
+			deliveries: []cbTypes.ReportDelivery{{Name: "billing", Format: "csv", Webhook: &cbTypes.WebhookDeliveryDestination{URL: "https://billing.example.com/ingest"}}},
+			wantErr:    true,
+		},
+		"webhook without scheme": {
+			deliveries: []cbTypes.ReportDelivery{{Name: "billing", Webhook: &cbTypes.WebhookDeliveryDestination{URL: "billing.example.com/ingest"}}},
+			wantErr:    true,
+		},
+		"gcs without credentials": {
+			deliveries: []cbTypes.ReportDelivery{{Name: "gcs", GCS: &cbTypes.GCSDeliveryDestination{GCSBucket: cbTypes.GCSBucket{Bucket: "reports"}}}},
+			wantErr:    true,
+		},
+		"s3 without region": {
+			deliveries: []cbTypes.ReportDelivery{{Name: "finance", S3: &cbTypes.S3DeliveryDestination{S3Bucket: cbTypes.S3Bucket{Bucket: "reports"}}}},
+			wantErr:    true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateReportDeliveries(tt.deliveries)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDeliveryObjectKey(t *testing.T) {
+	start := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "exports/metering/namespace-cpu-request/20180701T000000Z-20180801T000000Z.csv", deliveryObjectKey("/exports/", "metering", "namespace-cpu-request", start, end, "csv"))
+	assert.Equal(t, "metering/namespace-cpu-request/20180701T000000Z-20180801T000000Z.parquet", deliveryObjectKey("", "metering", "namespace-cpu-request", start, end, "parquet"))
+}
+
+func TestEncodeDeliveryResults(t *testing.T) {
+	columns := []cbTypes.ReportGenerationQueryColumn{
+		{Name: "namespace", Type: "string"},
+		{Name: "pods", Type: "bigint"},
+	}
+	results := []presto.Row{{"namespace": "team-a", "pods": int64(3)}}
+
+	body, err := encodeDeliveryResults("csv", columns, results)
+	require.NoError(t, err)
+	assert.Equal(t, "namespace,pods\nteam-a,3\n", string(body))
+
+	body, err = encodeDeliveryResults("json", columns, results)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"namespace":"team-a","pods":3}]`, string(body))
+
+	for _, format := range []string{"parquet", "xlsx"} {
+		body, err = encodeDeliveryResults(format, columns, results)
+		require.NoError(t, err)
+		assert.NotEmpty(t, body)
+	}
+}
+
+// newTestSecretResolver returns a secrets.Resolver reading the secret
+// containing data from a file:// ref, which is returned.
+func newTestSecretResolver(t *testing.T, data map[string]string) (*secrets.Resolver, string, func()) {
+	dir, err := ioutil.TempDir("", "delivery-test")
+	require.NoError(t, err)
+	for key, value := range data {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, key), []byte(value), 0600))
+	}
+	resolver := secrets.NewResolver(logrus.New(), clock.RealClock{}, time.Minute, secrets.NewFileProvider())
+	return resolver, "file://" + dir, func() { os.RemoveAll(dir) }
+}
+
+func TestUploadReportResultsAzure(t *testing.T) {
+	var gotReq *http.Request
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotReq = r
+		gotBody, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	resolver, ref, cleanup := newTestSecretResolver(t, map[string]string{azureSASTokenKey: "?sv=2018-03-28&sig=secret"})
+	defer cleanup()
+	op := &Reporting{secretResolver: resolver}
+	delivery := cbTypes.ReportDelivery{
+		Name:  "azure",
+		Azure: &cbTypes.AzureBlobDeliveryDestination{Account: "metering", Container: "reports", Endpoint: srv.URL, SASTokenSecret: ref},
+	}
+
+	blobURL, err := op.uploadReportResults(context.Background(), delivery, "metering/report.csv", "text/csv", []byte("namespace\n"))
+	require.NoError(t, err)
+	assert.Equal(t, srv.URL+"/reports/metering/report.csv", blobURL, "the URL shouldn't contain the signature")
+	require.NotNil(t, gotReq)
+	assert.Equal(t, http.MethodPut, gotReq.Method)
+	assert.Equal(t, "/reports/metering/report.csv", gotReq.URL.Path)
+	assert.Equal(t, "secret", gotReq.URL.Query().Get("sig"))
+	assert.Equal(t, "BlockBlob", gotReq.Header.Get("x-ms-blob-type"))
+	assert.Equal(t, "text/csv", gotReq.Header.Get("Content-Type"))
+	assert.Equal(t, "namespace\n", string(gotBody))
+}
+
+func TestUploadReportResultsS3(t *testing.T) {
+	var gotReq *http.Request
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotReq = r
+		gotBody, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	resolver, ref, cleanup := newTestSecretResolver(t, map[string]string{
+		"aws-access-key-id":     "AKIAEXAMPLE",
+		"aws-secret-access-key": "secret",
+	})
+	defer cleanup()
+	op := &Reporting{secretResolver: resolver}
+	delivery := cbTypes.ReportDelivery{
+		Name: "minio",
+		S3: &cbTypes.S3DeliveryDestination{
+			S3Bucket:          cbTypes.S3Bucket{Region: "us-east-1", Bucket: "reports"},
+			Endpoint:          srv.URL,
+			CredentialsSecret: ref,
+		},
+	}
+
+	objectURL, err := op.uploadReportResults(context.Background(), delivery, "metering/report.csv", "text/csv", []byte("namespace\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "s3://reports/metering/report.csv", objectURL)
+	require.NotNil(t, gotReq)
+	assert.Equal(t, http.MethodPut, gotReq.Method)
+	assert.Equal(t, "/reports/metering/report.csv", gotReq.URL.Path)
+	assert.Contains(t, gotReq.Header.Get("Authorization"), "AKIAEXAMPLE")
+	assert.Equal(t, "namespace\n", string(gotBody))
+}
+
+func TestPostResultsToWebhook(t *testing.T) {
+	var requests int
+	var payloads []webhookDeliveryPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "sha256="+signWebhookBody([]byte("signing-key"), body), r.Header.Get(webhookSignatureHeader))
+		var payload webhookDeliveryPayload
+		require.NoError(t, json.Unmarshal(body, &payload))
+		payloads = append(payloads, payload)
+	}))
+	defer srv.Close()
+
+	resolver, ref, cleanup := newTestSecretResolver(t, map[string]string{webhookSigningKey: "signing-key"})
+	defer cleanup()
+	fakeClock := clock.NewFakeClock(time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC))
+	op := &Reporting{clock: fakeClock, secretResolver: resolver}
+	dest := &cbTypes.WebhookDeliveryDestination{URL: srv.URL, SigningSecret: ref, BatchSize: 2}
+	results := []presto.Row{{"namespace": "team-a"}, {"namespace": "team-b"}, {"namespace": "team-c"}}
+	start := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- op.postResultsToWebhook(context.Background(), logrus.New(), dest, "metering", "namespace-cost", start, end, results)
+	}()
+	// the first request fails, and is retried after the backoff
+	for !fakeClock.HasWaiters() {
+		time.Sleep(time.Millisecond)
+	}
+	fakeClock.Step(webhookRetryBackoff)
+	require.NoError(t, <-errCh)
+
+	assert.Equal(t, 3, requests)
+	require.Len(t, payloads, 2)
+	for i, payload := range payloads {
+		assert.Equal(t, "metering", payload.Namespace)
+		assert.Equal(t, "namespace-cost", payload.Name)
+		assert.True(t, start.Equal(payload.PeriodStart))
+		assert.Equal(t, i+1, payload.Batch)
+		assert.Equal(t, 2, payload.Batches)
+	}
+	assert.Len(t, payloads[0].Results, 2)
+	assert.Len(t, payloads[1].Results, 1)
+}
+
+func TestPostResultsToWebhookClientError(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	op := &Reporting{clock: clock.NewFakeClock(time.Now())}
+	dest := &cbTypes.WebhookDeliveryDestination{URL: srv.URL}
+	err := op.postResultsToWebhook(context.Background(), logrus.New(), dest, "metering", "namespace-cost", time.Now(), time.Now(), nil)
+	assert.Error(t, err)
+	assert.Equal(t, 1, requests, "client errors shouldn't be retried")
+}
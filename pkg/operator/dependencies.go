@@ -0,0 +1,269 @@
+package operator
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+// dataSourceNotReadyRetryInterval is how long to wait before re-checking if
+// the dependencies of a report have data for the reporting period.
+const dataSourceNotReadyRetryInterval = time.Minute * 5
+
+const (
+	dependencyKindReportDataSource = "ReportDataSource"
+	dependencyKindReport           = "Report"
+	dependencyKindScheduledReport  = "ScheduledReport"
+)
+
+// getDependencyNames returns the names of the dependencies of the
+// generationQuery and of the ReportGenerationQueries it depends on, which
+// are discovered using specNames and by inspecting the query templates for
+// uses of the templateFunc template function.
+func (op *Reporting) getDependencyNames(generationQuery *cbTypes.ReportGenerationQuery, specNames func(*cbTypes.ReportGenerationQuery) []string, templateFunc string) ([]string, error) {
+	viewQueries, err := op.getDependentGenerationQueries(generationQuery, false)
+	if err != nil {
+		return nil, err
+	}
+	dynamicQueries, err := op.getDependentGenerationQueries(generationQuery, true)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := append([]*cbTypes.ReportGenerationQuery{generationQuery}, viewQueries...)
+	queries = append(queries, dynamicQueries...)
+
+	seen := make(map[string]struct{})
+	var names []string
+	for _, query := range queries {
+		referenced, err := getTemplateReferences(query.Spec.Query, templateFunc)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse query for ReportGenerationQuery %s: %v", query.Name, err)
+		}
+		for _, name := range append(specNames(query), referenced...) {
+			if _, exists := seen[name]; !exists {
+				seen[name] = struct{}{}
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// getReportDataSourceDependencies returns every ReportDataSource the
+// generationQuery depends on, including the ReportDataSources used by the
+// ReportGenerationQueries it depends on. ReportDataSources are discovered
+// from spec.reportDataSources and by inspecting the query templates for uses
+// of the dataSourceTableName template function.
+func (op *Reporting) getReportDataSourceDependencies(generationQuery *cbTypes.ReportGenerationQuery) ([]*cbTypes.ReportDataSource, error) {
+	names, err := op.getDependencyNames(generationQuery, func(query *cbTypes.ReportGenerationQuery) []string {
+		return query.Spec.DataSources
+	}, "dataSourceTableName")
+	if err != nil {
+		return nil, err
+	}
+
+	dataSources := make([]*cbTypes.ReportDataSource, len(names))
+	for i, name := range names {
+		dataSource, err := op.getDependentDataSource(generationQuery.Namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		dataSources[i] = dataSource
+	}
+	return dataSources, nil
+}
+
+// getReportDependencyNames returns the names of the Reports and
+// ScheduledReports the generationQuery reads the results of, including those
+// read by the ReportGenerationQueries it depends on. They are discovered from
+// spec.reports and spec.scheduledReports and by inspecting the query
+// templates for uses of the reportTableName and scheduledReportTableName
+// template functions.
+func (op *Reporting) getReportDependencyNames(generationQuery *cbTypes.ReportGenerationQuery) (reports, scheduledReports []string, err error) {
+	reports, err = op.getDependencyNames(generationQuery, func(query *cbTypes.ReportGenerationQuery) []string {
+		return query.Spec.Reports
+	}, "reportTableName")
+	if err != nil {
+		return nil, nil, err
+	}
+	scheduledReports, err = op.getDependencyNames(generationQuery, func(query *cbTypes.ReportGenerationQuery) []string {
+		return query.Spec.ScheduledReports
+	}, "scheduledReportTableName")
+	if err != nil {
+		return nil, nil, err
+	}
+	return reports, scheduledReports, nil
+}
+
+// getDependenciesStatus checks if each ReportDataSource, Report and
+// ScheduledReport the generationQuery depends on has data up until
+// reportEnd, and returns the status of each dependency, and whether or not
+// all dependencies are ready.
+func (op *Reporting) getDependenciesStatus(logger log.FieldLogger, generationQuery *cbTypes.ReportGenerationQuery, reportEnd time.Time) ([]cbTypes.ReportDependencyStatus, bool, error) {
+	dataSources, err := op.getReportDataSourceDependencies(generationQuery)
+	if err != nil {
+		return nil, false, err
+	}
+	reports, scheduledReports, err := op.getReportDependencyNames(generationQuery)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var statuses []cbTypes.ReportDependencyStatus
+	for _, dataSource := range dataSources {
+		status, err := op.getDataSourceDependencyStatus(dataSource, reportEnd)
+		if err != nil {
+			return nil, false, err
+		}
+		statuses = append(statuses, status)
+	}
+	reportLister := op.informers.Metering().V1alpha1().Reports().Lister().Reports(generationQuery.Namespace)
+	for _, name := range reports {
+		report, err := reportLister.Get(name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, false, err
+		}
+		statuses = append(statuses, getReportDependencyStatus(name, report, reportEnd))
+	}
+	scheduledReportLister := op.informers.Metering().V1alpha1().ScheduledReports().Lister().ScheduledReports(generationQuery.Namespace)
+	for _, name := range scheduledReports {
+		scheduledReport, err := scheduledReportLister.Get(name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, false, err
+		}
+		statuses = append(statuses, getScheduledReportDependencyStatus(name, scheduledReport, reportEnd))
+	}
+
+	allReady := true
+	for _, status := range statuses {
+		if !status.Ready {
+			logger.Infof("%s %s is not ready: %s", status.Kind, status.Name, status.Message)
+			allReady = false
+		}
+	}
+	return statuses, allReady, nil
+}
+
+// getReportDependencyStatus returns the status of a Report dependency, which
+// is ready once it has finished, and its reportingEnd is at or after
+// reportEnd. report is nil if it doesn't exist.
+func getReportDependencyStatus(name string, report *cbTypes.Report, reportEnd time.Time) cbTypes.ReportDependencyStatus {
+	status := cbTypes.ReportDependencyStatus{Kind: dependencyKindReport, Name: name}
+	switch {
+	case report == nil:
+		status.Message = "report does not exist"
+	case report.Status.Phase != cbTypes.ReportPhaseFinished:
+		status.Message = fmt.Sprintf("report has not finished, phase is %s", report.Status.Phase)
+	case report.Spec.ReportingEnd.Time.Before(reportEnd):
+		status.LastDataTime = &metav1.Time{Time: report.Spec.ReportingEnd.Time}
+		status.Message = fmt.Sprintf("report ends at %s, before %s", report.Spec.ReportingEnd.UTC(), reportEnd.UTC())
+	default:
+		status.LastDataTime = &metav1.Time{Time: report.Spec.ReportingEnd.Time}
+		status.Ready = true
+	}
+	return status
+}
+
+// getScheduledReportDependencyStatus returns the status of a ScheduledReport
+// dependency, which is ready once it has run for the period ending at
+// reportEnd. scheduledReport is nil if it doesn't exist.
+func getScheduledReportDependencyStatus(name string, scheduledReport *cbTypes.ScheduledReport, reportEnd time.Time) cbTypes.ReportDependencyStatus {
+	status := cbTypes.ReportDependencyStatus{Kind: dependencyKindScheduledReport, Name: name}
+	switch {
+	case scheduledReport == nil:
+		status.Message = "scheduledReport does not exist"
+	case scheduledReport.Status.LastReportTime == nil:
+		status.Message = "scheduledReport has not run yet"
+	case scheduledReport.Status.LastReportTime.Time.Before(reportEnd):
+		status.LastDataTime = &metav1.Time{Time: scheduledReport.Status.LastReportTime.Time}
+		status.Message = fmt.Sprintf("scheduledReport has run until %s, waiting for it to run until %s", scheduledReport.Status.LastReportTime.UTC(), reportEnd.UTC())
+	default:
+		status.LastDataTime = &metav1.Time{Time: scheduledReport.Status.LastReportTime.Time}
+		status.Ready = true
+	}
+	return status
+}
+
+func (op *Reporting) getDataSourceDependencyStatus(dataSource *cbTypes.ReportDataSource, reportEnd time.Time) (cbTypes.ReportDependencyStatus, error) {
+	status := cbTypes.ReportDependencyStatus{Kind: dependencyKindReportDataSource, Name: dataSource.Name}
+	if dataSource.TableName == "" {
+		status.Message = "table has not been created yet"
+		return status, nil
+	}
+
+	var lastDataTime *time.Time
+	// tolerance is how far before the reportEnd the last data point may be
+	// while still considering the data complete
+	var tolerance time.Duration
+	switch {
+	case dataSource.Spec.Promsum != nil:
+		var err error
+		lastDataTime, err = prestostore.GetLastTimestampForTable(op.prestoQueryer, dataSource.TableName)
+		if err != nil {
+			return status, err
+		}
+		tolerance = op.cfg.PrometheusQueryConfig.StepSize.Duration
+		if queryConf := dataSource.Spec.Promsum.QueryConfig; queryConf != nil && queryConf.StepSize != nil {
+			tolerance = queryConf.StepSize.Duration
+		}
+	case dataSource.Spec.KubernetesObjects != nil:
+		var err error
+		lastDataTime, err = prestostore.GetLastTimestampForTable(op.prestoQueryer, dataSource.TableName)
+		if err != nil {
+			return status, err
+		}
+		tolerance = op.kubernetesObjectsCollectionInterval(dataSource)
+	case dataSource.Spec.RemoteReport != nil:
+		timestampColumn := dataSource.Spec.RemoteReport.TimestampColumn
+		if timestampColumn == "" {
+			// without a timestamp column, there's no way to know how
+			// recent the results are
+			status.Ready = true
+			return status, nil
+		}
+		var err error
+		lastDataTime, err = getLastRemoteReportTimestamp(op.prestoQueryer, dataSource.TableName, timestampColumn)
+		if err != nil {
+			return status, err
+		}
+	case dataSource.Spec.AWSBilling != nil:
+		prestoTable, err := op.informers.Metering().V1alpha1().PrestoTables().Lister().PrestoTables(dataSource.Namespace).Get(prestoTableResourceNameFromKind("reportdatasource", dataSource.Name))
+		if err != nil {
+			return status, err
+		}
+		for _, partition := range prestoTable.State.Partitions {
+			end, err := time.Parse(awsUsagePartitionDateStringLayout, partition.PartitionSpec["end"])
+			if err != nil {
+				return status, fmt.Errorf("invalid partition end %q for ReportDataSource %s: %v", partition.PartitionSpec["end"], dataSource.Name, err)
+			}
+			if lastDataTime == nil || end.After(*lastDataTime) {
+				lastDataTime = &end
+			}
+		}
+	default:
+		// unknown datasource types are assumed to always be ready
+		status.Ready = true
+		return status, nil
+	}
+
+	if lastDataTime == nil {
+		status.Message = "table has no data yet"
+		return status, nil
+	}
+	status.LastDataTime = &metav1.Time{Time: *lastDataTime}
+	if lastDataTime.Add(tolerance).Before(reportEnd) {
+		status.Message = fmt.Sprintf("most recent data is from %s, waiting for data until %s", lastDataTime.UTC(), reportEnd.UTC())
+		return status, nil
+	}
+	status.Ready = true
+	return status, nil
+}
@@ -0,0 +1,100 @@
+package operator
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+const APIV1DebugFaultsEndpoint = "/api/v1/debug/faults"
+
+// FaultsRequest configures the faults injected into the Prometheus
+// importer.
+type FaultsRequest struct {
+	// DataSources limits the faults to imports of these ReportDataSources.
+	// If empty, faults are injected into imports of every ReportDataSource.
+	DataSources               []string `json:"dataSources,omitempty"`
+	DropChunkProbability      float64  `json:"dropChunkProbability,omitempty"`
+	DelayInsertProbability    float64  `json:"delayInsertProbability,omitempty"`
+	InsertDelay               string   `json:"insertDelay,omitempty"`
+	AmbiguousErrorProbability float64  `json:"ambiguousErrorProbability,omitempty"`
+}
+
+func (req FaultsRequest) toFaults() (prestostore.Faults, error) {
+	faults := prestostore.Faults{
+		DropChunkProbability:      req.DropChunkProbability,
+		DelayInsertProbability:    req.DelayInsertProbability,
+		AmbiguousErrorProbability: req.AmbiguousErrorProbability,
+	}
+	for _, name := range req.DataSources {
+		faults.Tables = append(faults.Tables, dataSourceTableName(name))
+	}
+	if req.InsertDelay != "" {
+		var err error
+		faults.InsertDelay, err = time.ParseDuration(req.InsertDelay)
+		if err != nil {
+			return faults, err
+		}
+	}
+	return faults, faults.Validate()
+}
+
+// FaultsResponse is the faults being injected into the Prometheus importer.
+type FaultsResponse struct {
+	// Tables are the Presto tables of the ReportDataSources faults are
+	// injected into, or empty if faults are injected into every table.
+	Tables                    []string `json:"tables"`
+	DropChunkProbability      float64  `json:"dropChunkProbability"`
+	DelayInsertProbability    float64  `json:"delayInsertProbability"`
+	InsertDelay               string   `json:"insertDelay"`
+	AmbiguousErrorProbability float64  `json:"ambiguousErrorProbability"`
+}
+
+func newFaultsResponse(faults prestostore.Faults) FaultsResponse {
+	resp := FaultsResponse{
+		Tables:                    faults.Tables,
+		DropChunkProbability:      faults.DropChunkProbability,
+		DelayInsertProbability:    faults.DelayInsertProbability,
+		InsertDelay:               faults.InsertDelay.String(),
+		AmbiguousErrorProbability: faults.AmbiguousErrorProbability,
+	}
+	if resp.Tables == nil {
+		resp.Tables = []string{}
+	}
+	return resp
+}
+
+// faultsHandler returns the faults being injected into the Prometheus
+// importer on GET, replaces them on PUT, and stops injecting faults on
+// DELETE.
+func (srv *server) faultsHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+
+	switch r.Method {
+	case "GET":
+		writeResponseAsJSON(logger, w, http.StatusOK, newFaultsResponse(srv.faultInjector.Faults()))
+	case "PUT":
+		var req FaultsRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to decode request as JSON: %v", err)
+			return
+		}
+		faults, err := req.toFaults()
+		if err != nil {
+			writeErrorResponse(logger, w, r, http.StatusBadRequest, "invalid faults: %v", err)
+			return
+		}
+		logger.Warnf("injecting faults into the Prometheus importer: %+v", req)
+		srv.faultInjector.SetFaults(faults)
+		writeResponseAsJSON(logger, w, http.StatusOK, newFaultsResponse(faults))
+	case "DELETE":
+		logger.Infof("no longer injecting faults into the Prometheus importer")
+		srv.faultInjector.SetFaults(prestostore.Faults{})
+		writeResponseAsJSON(logger, w, http.StatusOK, newFaultsResponse(prestostore.Faults{}))
+	default:
+		writeErrorResponse(logger, w, r, http.StatusMethodNotAllowed, "method must be one of: GET, PUT, DELETE")
+	}
+}
@@ -0,0 +1,367 @@
+package operator
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	APIV1ScheduledReportsForecastEndpoint = "/api/v1/scheduledreports/forecast"
+
+	ForecastMethodLinear   = "linear"
+	ForecastMethodSeasonal = "seasonal"
+)
+
+// CostForecast is the cost so far this month of a group of a ScheduledReport's
+// results, and the cost it's projected to reach by the end of the month.
+type CostForecast struct {
+	// Group is the value of the groupBy column, and is omitted for the
+	// total.
+	Group       string  `json:"group,omitempty"`
+	MonthToDate float64 `json:"monthToDate"`
+	Forecast    float64 `json:"forecast"`
+}
+
+type CostForecastResponse struct {
+	ScheduledReport string `json:"scheduledReport"`
+	Column          string `json:"column"`
+	GroupBy         string `json:"groupBy,omitempty"`
+	Method          string `json:"method"`
+	// MonthStart and MonthEnd are the bounds of the month in the
+	// ScheduledReport's timezone, and AsOf is the end of its latest period.
+	MonthStart time.Time      `json:"monthStart"`
+	MonthEnd   time.Time      `json:"monthEnd"`
+	AsOf       time.Time      `json:"asOf"`
+	Total      CostForecast   `json:"total"`
+	Forecasts  []CostForecast `json:"forecasts"`
+}
+
+// scheduledReportForecastHandler projects the month-end cost of each group
+// of a ScheduledReport's results from the results of the month so far. The
+// ScheduledReport must have hourly or daily periods, so the cost of each day
+// is known.
+func (srv *server) scheduledReportForecastHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	if err := r.ParseForm(); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "couldn't parse URL query params: %v", err)
+		return
+	}
+	if err := checkForFields([]string{"name", "column"}, r.Form); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
+	name, column, groupBy := r.FormValue("name"), r.FormValue("column"), r.FormValue("groupBy")
+	method := r.FormValue("method")
+	switch method {
+	case "":
+		method = ForecastMethodLinear
+	case ForecastMethodLinear, ForecastMethodSeasonal:
+	default:
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "method must be one of: %s or %s", ForecastMethodLinear, ForecastMethodSeasonal)
+		return
+	}
+
+	tableName, reportColumns, prestoColumns, ok := srv.getScheduledReportTable(logger, name, w, r)
+	if !ok {
+		return
+	}
+	// the ScheduledReport was just found by getScheduledReportTable.
+	listers, _ := srv.listersFor(r)
+	report, err := listers.scheduledReports.Get(name)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error getting scheduledReport: %v", err)
+		return
+	}
+	if err := validateForecastScheduledReport(report); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
+	loc, err := loadTimezone(report.Spec.Timezone)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	_, _, whereSQL, ok := srv.selectReportResults(logger, tableName, reportColumns, prestoColumns, w, r)
+	if !ok {
+		return
+	}
+	query, err := newForecastQuery(tableName, prestoColumns, column, groupBy, whereSQL)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	resp, err := query.forecast(srv.queryer, loc, method)
+	if err != nil {
+		logger.WithError(err).Errorf("unable to forecast the cost of scheduledReport %s", name)
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to forecast the cost of scheduledReport %s: %v", name, err)
+		return
+	}
+	if resp == nil {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "scheduledReport %s has no results to forecast from", name)
+		return
+	}
+	resp.ScheduledReport = name
+	resp.Column = column
+	resp.GroupBy = groupBy
+	writeResponseAsJSON(logger, w, http.StatusOK, resp)
+}
+
+func validateForecastScheduledReport(report *cbTypes.ScheduledReport) error {
+	switch report.Spec.Schedule.Period {
+	case cbTypes.ScheduledReportPeriodHourly, cbTypes.ScheduledReportPeriodDaily:
+	default:
+		return fmt.Errorf("scheduledReport %s must have an hourly or daily period to forecast its cost, got %s", report.Name, report.Spec.Schedule.Period)
+	}
+	if report.Spec.Window != nil || report.Spec.OverwriteExistingData {
+		return fmt.Errorf("scheduledReport %s must keep the results of each period to forecast its cost, so it can't set window or overwriteExistingData", report.Name)
+	}
+	return nil
+}
+
+// forecastQuery reads the daily cost of each group of a ScheduledReport's
+// results.
+type forecastQuery struct {
+	tableName string
+	// columnSQL and groupSQL are the quoted cost and groupBy columns.
+	// groupSQL is an empty string literal when the results aren't grouped.
+	columnSQL string
+	groupSQL  string
+	whereSQL  string
+}
+
+func newForecastQuery(tableName string, columns []presto.Column, column, groupBy, whereSQL string) (*forecastQuery, error) {
+	byName := make(map[string]presto.Column, len(columns))
+	for _, col := range columns {
+		byName[col.Name] = col
+	}
+	for _, required := range []string{"period_start", "period_end"} {
+		if col, exists := byName[required]; !exists || !strings.EqualFold(col.Type, "timestamp") {
+			return nil, fmt.Errorf("the results must have a %s timestamp column to forecast their cost", required)
+		}
+	}
+	col, exists := byName[column]
+	if !exists {
+		return nil, fmt.Errorf("column %s isn't in the results", column)
+	}
+	if !isNumericColumnType(col.Type) {
+		return nil, fmt.Errorf("column %s must be numeric, got %s", column, col.Type)
+	}
+	query := &forecastQuery{
+		tableName: tableName,
+		columnSQL: presto.GenerateQuotedColumnsListSQL([]presto.Column{col}),
+		groupSQL:  "''",
+		whereSQL:  whereSQL,
+	}
+	if groupBy != "" {
+		groupCol, exists := byName[groupBy]
+		if !exists {
+			return nil, fmt.Errorf("groupBy column %s isn't in the results", groupBy)
+		}
+		query.groupSQL = presto.GenerateQuotedColumnsListSQL([]presto.Column{groupCol})
+	}
+	return query, nil
+}
+
+func isNumericColumnType(colType string) bool {
+	switch strings.ToLower(colType) {
+	case "double", "real", "float", "bigint", "integer", "int", "smallint", "tinyint":
+		return true
+	}
+	return strings.HasPrefix(strings.ToLower(colType), "decimal")
+}
+
+func (q *forecastQuery) where(predicates ...string) string {
+	if q.whereSQL != "" {
+		predicates = append(predicates, "("+q.whereSQL+")")
+	}
+	if len(predicates) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(predicates, " AND ")
+}
+
+// forecast returns the forecast of the month containing the end of the
+// latest period, or nil if there are no results.
+func (q *forecastQuery) forecast(queryer presto.Queryer, loc *time.Location, method string) (*CostForecastResponse, error) {
+	rows, err := queryer.Query(fmt.Sprintf(`SELECT CAST(to_unixtime(max(period_end)) AS bigint) AS as_of FROM %s%s`, q.tableName, q.where()))
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) != 1 || rows[0]["as_of"] == nil {
+		return nil, nil
+	}
+	asOfSecs, ok := rows[0]["as_of"].(int64)
+	if !ok {
+		return nil, fmt.Errorf("invalid as_of, valueType: %T, value: %+v", rows[0]["as_of"], rows[0]["as_of"])
+	}
+	asOf := time.Unix(asOfSecs, 0).In(loc)
+	// a period ending at midnight on the 1st is the end of the month
+	// before.
+	lastInstant := asOf.Add(-time.Nanosecond)
+	monthStart := time.Date(lastInstant.Year(), lastInstant.Month(), 1, 0, 0, 0, 0, loc)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+	monthDays := monthEnd.AddDate(0, 0, -1).Day()
+
+	rows, err = queryer.Query(fmt.Sprintf(`SELECT CAST(%s AS varchar) AS grp, CAST(to_unixtime(period_start) AS bigint) AS period_start, CAST(sum(%s) AS double) AS cost FROM %s%s GROUP BY 1, 2`,
+		q.groupSQL, q.columnSQL, q.tableName,
+		q.where(
+			fmt.Sprintf("period_start >= timestamp '%s'", presto.Timestamp(monthStart.UTC())),
+			fmt.Sprintf("period_start < timestamp '%s'", presto.Timestamp(asOf.UTC())),
+		),
+	))
+	if err != nil {
+		return nil, err
+	}
+	daily := make(map[string][]float64)
+	total := make([]float64, monthDays)
+	for _, row := range rows {
+		group, _ := row["grp"].(string)
+		periodStart, ok := row["period_start"].(int64)
+		if !ok {
+			return nil, fmt.Errorf("invalid period_start, valueType: %T, value: %+v", row["period_start"], row["period_start"])
+		}
+		var cost float64
+		if row["cost"] != nil {
+			if cost, ok = row["cost"].(float64); !ok {
+				return nil, fmt.Errorf("invalid cost, valueType: %T, value: %+v", row["cost"], row["cost"])
+			}
+		}
+		day := time.Unix(periodStart, 0).In(loc).Day() - 1
+		if daily[group] == nil {
+			daily[group] = make([]float64, monthDays)
+		}
+		daily[group][day] += cost
+		total[day] += cost
+	}
+
+	elapsed := elapsedDays(monthStart, asOf)
+	resp := &CostForecastResponse{
+		Method:     method,
+		MonthStart: monthStart,
+		MonthEnd:   monthEnd,
+		AsOf:       asOf,
+		Total:      newCostForecast("", total, monthStart, elapsed, method),
+		Forecasts:  []CostForecast{},
+	}
+	if q.groupSQL != "''" {
+		for group, days := range daily {
+			resp.Forecasts = append(resp.Forecasts, newCostForecast(group, days, monthStart, elapsed, method))
+		}
+		sort.Slice(resp.Forecasts, func(i, j int) bool {
+			if resp.Forecasts[i].Forecast != resp.Forecasts[j].Forecast {
+				return resp.Forecasts[i].Forecast > resp.Forecasts[j].Forecast
+			}
+			return resp.Forecasts[i].Group < resp.Forecasts[j].Group
+		})
+	}
+	return resp, nil
+}
+
+// elapsedDays returns the number of days of the month before asOf,
+// including the fraction of the current day, which isn't always 24 hours
+// long.
+func elapsedDays(monthStart, asOf time.Time) float64 {
+	if !asOf.Before(monthStart.AddDate(0, 1, 0)) {
+		return float64(monthStart.AddDate(0, 1, -1).Day())
+	}
+	dayStart := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, asOf.Location())
+	dayLength := dayStart.AddDate(0, 0, 1).Sub(dayStart)
+	return float64(asOf.Day()-1) + float64(asOf.Sub(dayStart))/float64(dayLength)
+}
+
+func newCostForecast(group string, daily []float64, monthStart time.Time, elapsed float64, method string) CostForecast {
+	var monthToDate float64
+	for _, cost := range daily {
+		monthToDate += cost
+	}
+	return CostForecast{
+		Group:       group,
+		MonthToDate: monthToDate,
+		Forecast:    forecastMonthEnd(daily, monthStart, elapsed, method),
+	}
+}
+
+// forecastMonthEnd projects the cost of the month from the cost of each
+// day so far, predicting the cost of each remaining day from the complete
+// days before it. The linear method extrapolates the least squares trend of
+// the daily cost, and the seasonal method uses the mean cost of the same
+// day of the week, so weekends and weekdays are projected separately.
+// Without enough complete days, the cost so far is extrapolated at the
+// same rate.
+func forecastMonthEnd(daily []float64, monthStart time.Time, elapsed float64, method string) float64 {
+	var monthToDate float64
+	for _, cost := range daily {
+		monthToDate += cost
+	}
+	if elapsed <= 0 {
+		return 0
+	}
+	complete := int(elapsed)
+	runRate := monthToDate / elapsed
+
+	var predict func(day int) float64
+	switch {
+	case method == ForecastMethodSeasonal && complete >= 1:
+		var weekdaySums, weekdayCounts [7]float64
+		var sum float64
+		for day := 0; day < complete; day++ {
+			weekday := monthStart.AddDate(0, 0, day).Weekday()
+			weekdaySums[weekday] += daily[day]
+			weekdayCounts[weekday]++
+			sum += daily[day]
+		}
+		mean := sum / float64(complete)
+		predict = func(day int) float64 {
+			weekday := monthStart.AddDate(0, 0, day).Weekday()
+			if weekdayCounts[weekday] == 0 {
+				return mean
+			}
+			return weekdaySums[weekday] / weekdayCounts[weekday]
+		}
+	case method == ForecastMethodLinear && complete >= 2:
+		intercept, slope := linearTrend(daily[:complete])
+		predict = func(day int) float64 {
+			if cost := intercept + slope*float64(day); cost > 0 {
+				return cost
+			}
+			return 0
+		}
+	default:
+		predict = func(int) float64 { return runRate }
+	}
+
+	forecast := monthToDate
+	for day := complete; day < len(daily); day++ {
+		remaining := 1.0
+		if day == complete {
+			// the current day is partly over, and its cost so far is
+			// already included.
+			remaining = 1 - (elapsed - float64(complete))
+		}
+		forecast += remaining * predict(day)
+	}
+	return forecast
+}
+
+// linearTrend returns the intercept and slope of the least squares line
+// through the cost of each day.
+func linearTrend(daily []float64) (float64, float64) {
+	n := float64(len(daily))
+	var sumX, sumY, sumXY, sumXX float64
+	for day, cost := range daily {
+		x := float64(day)
+		sumX += x
+		sumY += cost
+		sumXY += x * cost
+		sumXX += x * x
+	}
+	slope := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	return (sumY - slope*sumX) / n, slope
+}
@@ -0,0 +1,191 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	mockpresto "github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+func TestForecastMonthEnd(t *testing.T) {
+	// January 2019 starts on a Tuesday.
+	monthStart := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	days := func(costs ...float64) []float64 {
+		daily := make([]float64, 31)
+		copy(daily, costs)
+		return daily
+	}
+
+	tests := map[string]struct {
+		daily    []float64
+		elapsed  float64
+		method   string
+		expected float64
+	}{
+		"linear extrapolates the trend": {
+			daily:    days(1, 2, 3, 4, 5, 6, 7, 8, 9, 10),
+			elapsed:  10,
+			method:   ForecastMethodLinear,
+			expected: 496,
+		},
+		"linear doesn't project a negative cost": {
+			daily:    days(10, 8, 6, 4),
+			elapsed:  4,
+			method:   ForecastMethodLinear,
+			expected: 30,
+		},
+		"linear uses the run rate without two complete days": {
+			daily:    days(10, 5),
+			elapsed:  1.5,
+			method:   ForecastMethodLinear,
+			expected: 310,
+		},
+		"seasonal uses the mean of each day of the week": {
+			// weekends cost nothing.
+			daily:    days(10, 10, 10, 10, 0, 0, 10, 10, 10, 10, 10, 0, 0, 10),
+			elapsed:  14,
+			method:   ForecastMethodSeasonal,
+			expected: 230,
+		},
+		"seasonal uses the mean for days of the week without a complete day": {
+			// 4 more Tuesdays and Wednesdays, and 21 other days at the
+			// mean.
+			daily:    days(10, 20),
+			elapsed:  2,
+			method:   ForecastMethodSeasonal,
+			expected: 30 + 4*10 + 4*20 + 21*15,
+		},
+		"no elapsed days": {
+			daily:    days(),
+			method:   ForecastMethodLinear,
+			expected: 0,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assert.InDelta(t, test.expected, forecastMonthEnd(test.daily, monthStart, test.elapsed, test.method), 0.0001)
+		})
+	}
+}
+
+func TestElapsedDays(t *testing.T) {
+	monthStart := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 10.5, elapsedDays(monthStart, time.Date(2019, time.January, 11, 12, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 31.0, elapsedDays(monthStart, time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC)))
+
+	// the day daylight saving time starts is 23 hours long.
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	monthStart = time.Date(2019, time.March, 1, 0, 0, 0, 0, loc)
+	assert.InDelta(t, 9+11.0/23, elapsedDays(monthStart, time.Date(2019, time.March, 10, 12, 0, 0, 0, loc)), 0.0001)
+}
+
+func TestNewForecastQuery(t *testing.T) {
+	columns := []presto.Column{
+		{Name: "period_start", Type: "timestamp"},
+		{Name: "period_end", Type: "timestamp"},
+		{Name: "namespace", Type: "varchar"},
+		{Name: "total_cost", Type: "double"},
+	}
+
+	query, err := newForecastQuery("report_table", columns, "total_cost", "namespace", "namespace = 'a'")
+	require.NoError(t, err)
+	assert.Equal(t, `"total_cost"`, query.columnSQL)
+	assert.Equal(t, `"namespace"`, query.groupSQL)
+	assert.Equal(t, ` WHERE x > 1 AND (namespace = 'a')`, query.where("x > 1"))
+
+	query, err = newForecastQuery("report_table", columns, "total_cost", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "''", query.groupSQL)
+	assert.Equal(t, "", query.where())
+
+	_, err = newForecastQuery("report_table", columns, "namespace", "", "")
+	assert.Error(t, err, "column must be numeric")
+	_, err = newForecastQuery("report_table", columns, "cost", "", "")
+	assert.Error(t, err, "column must exist")
+	_, err = newForecastQuery("report_table", columns, "total_cost", "pod", "")
+	assert.Error(t, err, "groupBy column must exist")
+	_, err = newForecastQuery("report_table", columns[1:], "total_cost", "", "")
+	assert.Error(t, err, "period_start is required")
+
+	// Presto returns the types of some tables' columns in upper case
+	upperColumns := []presto.Column{
+		{Name: "period_start", Type: "TIMESTAMP"},
+		{Name: "period_end", Type: "TIMESTAMP"},
+		{Name: "namespace", Type: "VARCHAR"},
+		{Name: "total_cost", Type: "DOUBLE"},
+	}
+	query, err = newForecastQuery("report_table", upperColumns, "total_cost", "namespace", "")
+	require.NoError(t, err)
+	assert.Equal(t, `"total_cost"`, query.columnSQL)
+	assert.Equal(t, `"namespace"`, query.groupSQL)
+}
+
+func TestValidateForecastScheduledReport(t *testing.T) {
+	report := &cbTypes.ScheduledReport{}
+	report.Spec.Schedule.Period = cbTypes.ScheduledReportPeriodDaily
+	assert.NoError(t, validateForecastScheduledReport(report))
+
+	report.Spec.Schedule.Period = cbTypes.ScheduledReportPeriodMonthly
+	assert.Error(t, validateForecastScheduledReport(report))
+
+	report.Spec.Schedule.Period = cbTypes.ScheduledReportPeriodHourly
+	report.Spec.Window = &cbTypes.ScheduledReportWindow{Period: cbTypes.ScheduledReportPeriodMonthly}
+	assert.Error(t, validateForecastScheduledReport(report))
+}
+
+func TestForecast(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	queryer := mockpresto.NewMockExecQueryer(ctrl)
+
+	query := &forecastQuery{
+		tableName: "report_table",
+		columnSQL: `"total_cost"`,
+		groupSQL:  `"namespace"`,
+	}
+	monthStart := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	asOf := time.Date(2019, time.January, 11, 0, 0, 0, 0, time.UTC)
+
+	// team-a costs 10 each day, and team-b's cost grows by 1 each day.
+	var rows []presto.Row
+	for day := 0; day < 10; day++ {
+		periodStart := monthStart.AddDate(0, 0, day).Unix()
+		rows = append(rows,
+			presto.Row{"grp": "team-a", "period_start": periodStart, "cost": 10.0},
+			presto.Row{"grp": "team-b", "period_start": periodStart, "cost": float64(day + 1)},
+		)
+	}
+	gomock.InOrder(
+		queryer.EXPECT().Query(`SELECT CAST(to_unixtime(max(period_end)) AS bigint) AS as_of FROM report_table`).Return([]presto.Row{{"as_of": asOf.Unix()}}, nil),
+		queryer.EXPECT().Query(`SELECT CAST("namespace" AS varchar) AS grp, CAST(to_unixtime(period_start) AS bigint) AS period_start, CAST(sum("total_cost") AS double) AS cost FROM report_table WHERE period_start >= timestamp '2019-01-01 00:00:00.000' AND period_start < timestamp '2019-01-11 00:00:00.000' GROUP BY 1, 2`).Return(rows, nil),
+	)
+
+	resp, err := query.forecast(queryer, time.UTC, ForecastMethodLinear)
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, monthStart, resp.MonthStart)
+	assert.Equal(t, time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC), resp.MonthEnd)
+	assert.Equal(t, asOf, resp.AsOf)
+	assert.InDelta(t, 155, resp.Total.MonthToDate, 0.0001)
+	assert.InDelta(t, 806, resp.Total.Forecast, 0.0001)
+	require.Len(t, resp.Forecasts, 2)
+	assert.Equal(t, "team-b", resp.Forecasts[0].Group)
+	assert.InDelta(t, 55, resp.Forecasts[0].MonthToDate, 0.0001)
+	assert.InDelta(t, 496, resp.Forecasts[0].Forecast, 0.0001)
+	assert.Equal(t, "team-a", resp.Forecasts[1].Group)
+	assert.InDelta(t, 310, resp.Forecasts[1].Forecast, 0.0001)
+
+	// without any results, there's nothing to forecast.
+	queryer.EXPECT().Query(gomock.Any()).Return([]presto.Row{{"as_of": nil}}, nil)
+	resp, err = query.forecast(queryer, time.UTC, ForecastMethodLinear)
+	require.NoError(t, err)
+	assert.Nil(t, resp)
+}
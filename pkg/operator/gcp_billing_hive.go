@@ -0,0 +1,56 @@
+package operator
+
+import (
+	"github.com/sirupsen/logrus"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+)
+
+// gcpBillingHiveColumns are the columns of the Google Cloud detailed billing
+// export BigQuery table. The export is read from Parquet files, which are
+// matched to columns by name, so columns the export doesn't contain are NULL
+// and columns added to the export later are ignored.
+var gcpBillingHiveColumns = []hive.Column{
+	{Name: "billing_account_id", Type: "string"},
+	{Name: "service", Type: "struct<id:string,description:string>"},
+	{Name: "sku", Type: "struct<id:string,description:string>"},
+	{Name: "usage_start_time", Type: "timestamp"},
+	{Name: "usage_end_time", Type: "timestamp"},
+	{Name: "project", Type: "struct<id:string,number:string,name:string>"},
+	{Name: "labels", Type: "array<struct<key:string,value:string>>"},
+	{Name: "system_labels", Type: "array<struct<key:string,value:string>>"},
+	{Name: "location", Type: "struct<location:string,country:string,region:string,zone:string>"},
+	{Name: "resource", Type: "struct<name:string,global_name:string>"},
+	{Name: "export_time", Type: "timestamp"},
+	{Name: "cost", Type: "double"},
+	{Name: "currency", Type: "string"},
+	{Name: "currency_conversion_rate", Type: "double"},
+	{Name: "usage", Type: "struct<amount:double,unit:string,amount_in_pricing_units:double,pricing_unit:string>"},
+	{Name: "credits", Type: "array<struct<name:string,amount:double,full_name:string,id:string,type:string>>"},
+	{Name: "invoice", Type: "struct<month:string>"},
+	{Name: "cost_type", Type: "string"},
+}
+
+// createGCPBillingTable creates an external Hive table reading the Google
+// Cloud billing export Parquet files in the bucket.
+func (op *Reporting) createGCPBillingTable(logger logrus.FieldLogger, dataSource *cbTypes.ReportDataSource, tableName, bucket, prefix string) error {
+	location, err := hive.GCSLocation(bucket, prefix)
+	if err != nil {
+		return err
+	}
+
+	params := hive.TableParameters{
+		Name:         tableName,
+		Columns:      gcpBillingHiveColumns,
+		IgnoreExists: true,
+	}
+	properties := hive.TableProperties{
+		Location:   location,
+		FileFormat: "parquet",
+		External:   true,
+	}
+	// unlike createTableWith, the table's name isn't added to the location,
+	// since the export is read from where it's written.
+	return op.createTableAndCR(logger, dataSource, "ReportDataSource", dataSource.Name, params, properties)
+}
@@ -1,6 +1,7 @@
 package operator
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -13,7 +14,10 @@ import (
 	"github.com/operator-framework/operator-metering/pkg/presto"
 )
 
-func (op *Reporting) generateReport(logger log.FieldLogger, report runtime.Object, reportKind, reportName, tableName string, reportStart, reportEnd time.Time, storage *cbTypes.StorageLocationRef, generationQuery *cbTypes.ReportGenerationQuery, dropTable, deleteExistingData bool) error {
+// generateReport runs the generationQuery for the reporting period, storing
+// the results in tableName. The queries which populate the table are
+// cancelled if ctx is done before they finish.
+func (op *Reporting) generateReport(ctx context.Context, logger log.FieldLogger, report runtime.Object, reportKind, reportName, tableName string, reportStart, reportEnd time.Time, storage *cbTypes.StorageLocationRef, generationQuery *cbTypes.ReportGenerationQuery, dropTable, deleteExistingData bool) error {
 	logger = logger.WithFields(log.Fields{
 		"reportKind":         reportKind,
 		"deleteExistingData": deleteExistingData,
@@ -21,25 +25,11 @@ func (op *Reporting) generateReport(logger log.FieldLogger, report runtime.Objec
 	})
 	logger.Infof("generating usage report")
 
-	dependentQueries, err := op.getDependentGenerationQueries(generationQuery, true)
-	if err != nil {
-		return fmt.Errorf("unable to get dependent generationQueries for %s, err: %v", generationQuery.Name, err)
-	}
-
-	columns := generateHiveColumns(generationQuery)
-
-	templateInfo := &templateInfo{
-		DynamicDependentQueries: dependentQueries,
-		Report: &reportTemplateInfo{
-			StartPeriod: reportStart,
-			EndPeriod:   reportEnd,
-		},
-	}
-	qr := queryRenderer{templateInfo: templateInfo}
-	query, err := qr.Render(generationQuery.Spec.Query)
+	query, generationQuery, reportColumns, err := op.renderReportQuery(logger, report, reportKind, reportName, reportStart, reportEnd, generationQuery)
 	if err != nil {
 		return err
 	}
+	columns := generateHiveColumns(reportColumns)
 
 	switch strings.ToLower(reportKind) {
 	case "report", "scheduledreport":
@@ -48,34 +38,266 @@ func (op *Reporting) generateReport(logger log.FieldLogger, report runtime.Objec
 		return fmt.Errorf("invalid report kind: %s", reportKind)
 	}
 
-	if dropTable {
-		logger.Debugf("dropping table %s", tableName)
-		err := hive.ExecuteDropTable(op.hiveQueryer, tableName, true)
+	materialization, err := getReportMaterializationPolicy(generationQuery)
+	if err != nil {
+		return err
+	}
+	logger = logger.WithField("materialization", materialization)
+
+	err = op.ensureTenantSchema(logger, generationQuery.Namespace)
+	if err != nil {
+		return err
+	}
+
+	err = op.dropReplacedReportTable(logger, tableName, materialization)
+	if err != nil {
+		return err
+	}
+
+	if materialization == cbTypes.ReportMaterializationView {
+		logger.Debugf("creating view %s for report", tableName)
+		err = presto.CreateOrReplaceView(op.prestoQueryer, tableName, query)
+		if err != nil {
+			return fmt.Errorf("Failed to create view for %s usage report: %v", reportName, err)
+		}
+		params := hive.TableParameters{
+			Name:    tableName,
+			Columns: columns,
+		}
+		err = op.createPrestoTableCR(report, cbTypes.GroupName, reportKind, params, hive.TableProperties{}, nil)
 		if err != nil {
-			return err
+			return fmt.Errorf("couldn't create PrestoTable resource for %s: %v", reportKind, err)
 		}
+		return nil
 	}
 
-	err = op.createTableForStorage(logger, report, reportKind, reportName, storage, tableName, columns)
+	tableProperties, err := op.getHiveTableProperties(logger, storage, reportKind)
+	if err != nil {
+		return fmt.Errorf("storage incorrectly configured for %s: %s", reportKind, reportName)
+	}
+	// Objects deleted from object stores may still be listed for a while,
+	// so rather than deleting the existing rows of a table in an object
+	// store, which the insert could then read, it's recreated empty at a new
+	// location.
+	if deleteExistingData && hive.IsObjectStoreLocation(tableProperties.Location) {
+		logger.Debugf("recreating table %s in object storage instead of deleting its rows", tableName)
+		dropTable, deleteExistingData = true, false
+	}
+
+	tableParams := hive.TableParameters{
+		Name:         tableName,
+		Columns:      columns,
+		IgnoreExists: true,
+	}
+	if dropTable || materialization == cbTypes.ReportMaterializationMaterialized {
+		err = op.recreateTableWith(logger, report, reportKind, reportName, tableParams, *tableProperties)
+	} else {
+		err = op.createTableWith(logger, report, reportKind, reportName, tableParams, *tableProperties)
+		if err == nil {
+			err = addMissingTableColumns(logger, op.prestoQueryer, op.hiveQueryer, tableName, columns)
+		}
+	}
 	if err != nil {
 		return err
 	}
 
+	if op.reportScheduler != nil {
+		logger.Debugf("waiting for a free report query slot")
+		release, err := op.reportScheduler.acquire(ctx, generationQuery.Namespace, reportEnd.Sub(reportStart))
+		if err != nil {
+			return fmt.Errorf("report %s was cancelled while waiting to run: %v", reportName, err)
+		}
+		defer release()
+	}
+
 	if deleteExistingData {
 		logger.Debugf("deleting any preexisting rows in %s", tableName)
-		err = presto.DeleteFrom(op.prestoQueryer, tableName)
+		err = presto.DeleteFromContext(ctx, op.prestoQueryer, tableName)
 		if err != nil {
 			return fmt.Errorf("couldn't empty table %s of preexisting rows: %v", tableName, err)
 		}
 	}
 
-	// Run the report
+	// Run the report. The results are inserted by column name, as the
+	// columns of an existing table may be in a different order after
+	// addMissingTableColumns adds to them.
 	logger.Debugf("running report generation query")
-	err = presto.InsertInto(op.prestoQueryer, tableName, query)
+	columnNames := make([]string, len(columns))
+	for i, column := range columns {
+		columnNames[i] = column.Name
+	}
+	err = presto.InsertIntoColumnsContext(ctx, op.prestoQueryer, tableName, columnNames, query)
 	if err != nil {
 		logger.WithError(err).Errorf("creating usage report FAILED!")
 		return fmt.Errorf("Failed to execute %s usage report: %v", reportName, err)
 	}
+	op.tableAnalyzer.recordReportInsert(tableName)
+
+	return nil
+}
 
+// dropReplacedReportTable drops the report's table if it's now stored in a
+// view, or its view if it's now stored in a table, so that changing the
+// materialization of a ReportGenerationQuery replaces the tables of the
+// reports using it rather than failing to create them. Their PrestoTable
+// resources are updated when the new table or view is created.
+func (op *Reporting) dropReplacedReportTable(logger log.FieldLogger, tableName string, materialization cbTypes.ReportMaterializationPolicy) error {
+	tableType, err := presto.GetTableType(op.prestoQueryer, tableName)
+	if err != nil {
+		return fmt.Errorf("couldn't get the table type of %s: %v", tableName, err)
+	}
+	switch {
+	case tableType == presto.TableTypeTable && materialization == cbTypes.ReportMaterializationView:
+		logger.Infof("dropping table %s to replace it with a view", tableName)
+		err = hive.ExecuteDropTable(op.hiveQueryer, tableName, true)
+	case tableType == presto.TableTypeView && materialization != cbTypes.ReportMaterializationView:
+		logger.Infof("dropping view %s to replace it with a table", tableName)
+		err = presto.DropView(op.prestoQueryer, tableName, true)
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't drop %s to change its materialization to %s: %v", tableName, materialization, err)
+	}
 	return nil
 }
+
+// renderReportQuery renders the query of a run of the report, using the
+// revision of the generationQuery in effect at reportEnd, and returns it
+// along with the revision used and the columns of the query's results. The
+// values of monetary columns are rounded by the query if rounding is
+// configured, and the values of decimal columns are cast to their type.
+func (op *Reporting) renderReportQuery(logger log.FieldLogger, report runtime.Object, reportKind, reportName string, reportStart, reportEnd time.Time, generationQuery *cbTypes.ReportGenerationQuery) (string, *cbTypes.ReportGenerationQuery, []cbTypes.ReportGenerationQueryColumn, error) {
+	dependentQueries, err := op.getDependentGenerationQueries(generationQuery, true)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("unable to get dependent generationQueries for %s, err: %v", generationQuery.Name, err)
+	}
+
+	generationQuery, dependentQueries, viewNames, err := op.getGenerationQueriesForPeriod(logger, strings.ToLower(reportKind), generationQuery, dependentQueries, reportEnd)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("unable to get revisions of generationQueries for %s %s, err: %v", reportKind, reportName, err)
+	}
+
+	groupByLabels, err := getGroupByLabels(generationQuery, getReportGroupByLabels(report))
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("invalid groupByLabels for %s %s: %v", reportKind, reportName, err)
+	}
+	columns := getReportColumns(generationQuery, groupByLabels)
+
+	costAllocation, err := getCostAllocation(generationQuery, getReportCostAllocation(report))
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("invalid costAllocation for %s %s: %v", reportKind, reportName, err)
+	}
+
+	timezone := getReportTimezone(report)
+	if _, err := loadTimezone(timezone); err != nil {
+		return "", nil, nil, fmt.Errorf("invalid timezone for %s %s: %v", reportKind, reportName, err)
+	}
+
+	inputDefinitions, err := getQueryInputDefinitions(generationQuery, dependentQueries)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("invalid inputs for generationQuery %s: %v", generationQuery.Name, err)
+	}
+	inputs, err := resolveQueryInputs(inputDefinitions, getReportInputs(report))
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("invalid inputs for %s %s: %v", reportKind, reportName, err)
+	}
+
+	pricingModels, err := op.getPricingModels(generationQuery.Namespace)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("unable to list PricingModels: %v", err)
+	}
+
+	tenantNamespace := op.tenantNamespace(generationQuery.Namespace)
+	templateInfo := &templateInfo{
+		DynamicDependentQueries: dependentQueries,
+		viewNames:               viewNames,
+		pricingModels:           pricingModels,
+		tenantNamespace:         tenantNamespace,
+		Report: &reportTemplateInfo{
+			StartPeriod:     reportStart,
+			EndPeriod:       reportEnd,
+			Timezone:        timezone,
+			Inputs:          inputs,
+			GroupByLabels:   groupByLabels,
+			CostAllocation:  costAllocation,
+			tenantNamespace: tenantNamespace,
+		},
+	}
+	qr := queryRenderer{templateInfo: templateInfo}
+	query, err := qr.Render(generationQuery.Spec.Query)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	query = reportColumnsQuery(op.cfg.MonetaryRoundingConfig, query, columns)
+	return query, generationQuery, columns, nil
+}
+
+// newReportRunContext returns a context for a single run of a Report or
+// ScheduledReport, which is cancelled once the run exceeds
+// activeDeadlineSeconds, if set, or once stopCh is closed, so that runs and
+// the backoff between their checkpoint retries end when the worker stops.
+func newReportRunContext(stopCh <-chan struct{}, activeDeadlineSeconds *int64) (context.Context, context.CancelFunc) {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if activeDeadlineSeconds == nil {
+		ctx, cancel = context.WithCancel(context.Background())
+	} else {
+		ctx, cancel = context.WithTimeout(context.Background(), time.Duration(*activeDeadlineSeconds)*time.Second)
+	}
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// stopRequested returns true if stopCh has been closed.
+func stopRequested(stopCh <-chan struct{}) bool {
+	select {
+	case <-stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// reportRunTimedOut returns true if the run using ctx was cancelled because
+// it exceeded its activeDeadlineSeconds.
+func reportRunTimedOut(ctx context.Context) bool {
+	return ctx.Err() == context.DeadlineExceeded
+}
+
+// getReportMaterializationPolicy returns the materialization policy for the
+// generationQuery, defaulting to ReportMaterializationTable if it's unset.
+func getReportMaterializationPolicy(generationQuery *cbTypes.ReportGenerationQuery) (cbTypes.ReportMaterializationPolicy, error) {
+	switch generationQuery.Spec.Materialization {
+	case "", cbTypes.ReportMaterializationTable:
+		return cbTypes.ReportMaterializationTable, nil
+	case cbTypes.ReportMaterializationView, cbTypes.ReportMaterializationMaterialized:
+		return generationQuery.Spec.Materialization, nil
+	default:
+		return "", fmt.Errorf("invalid ReportGenerationQuery.spec.materialization: %s, must be one of: %s, %s or %s", generationQuery.Spec.Materialization, cbTypes.ReportMaterializationTable, cbTypes.ReportMaterializationView, cbTypes.ReportMaterializationMaterialized)
+	}
+}
+
+// getReportTimezone returns the timezone of a Report or ScheduledReport,
+// defaulting to UTC.
+func getReportTimezone(report runtime.Object) string {
+	var timezone string
+	switch r := report.(type) {
+	case *cbTypes.Report:
+		timezone = r.Spec.Timezone
+	case *cbTypes.ScheduledReport:
+		timezone = r.Spec.Timezone
+	}
+	if timezone == "" {
+		return "UTC"
+	}
+	return timezone
+}
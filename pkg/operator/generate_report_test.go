@@ -0,0 +1,251 @@
+package operator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/fake"
+	cbInformers "github.com/operator-framework/operator-metering/pkg/generated/informers/externalversions"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+// recordingPrestoQueryer records the Presto queries it's asked to run, and
+// returns the rows of queries set in results.
+type recordingPrestoQueryer struct {
+	results map[string][]presto.Row
+	queries []string
+}
+
+func (q *recordingPrestoQueryer) Query(query string) ([]presto.Row, error) {
+	q.queries = append(q.queries, query)
+	return q.results[query], nil
+}
+
+func (q *recordingPrestoQueryer) Exec(query string) error {
+	q.queries = append(q.queries, query)
+	return nil
+}
+
+func TestGetReportMaterializationPolicy(t *testing.T) {
+	tests := map[string]struct {
+		materialization cbTypes.ReportMaterializationPolicy
+		expected        cbTypes.ReportMaterializationPolicy
+		expectErr       bool
+	}{
+		"unset": {
+			materialization: "",
+			expected:        cbTypes.ReportMaterializationTable,
+		},
+		"table": {
+			materialization: cbTypes.ReportMaterializationTable,
+			expected:        cbTypes.ReportMaterializationTable,
+		},
+		"view": {
+			materialization: cbTypes.ReportMaterializationView,
+			expected:        cbTypes.ReportMaterializationView,
+		},
+		"materialized": {
+			materialization: cbTypes.ReportMaterializationMaterialized,
+			expected:        cbTypes.ReportMaterializationMaterialized,
+		},
+		"invalid": {
+			materialization: "cached",
+			expectErr:       true,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			generationQuery := &cbTypes.ReportGenerationQuery{
+				Spec: cbTypes.ReportGenerationQuerySpec{Materialization: test.materialization},
+			}
+			materialization, err := getReportMaterializationPolicy(generationQuery)
+			if test.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, materialization)
+		})
+	}
+}
+
+func TestGenerateReportMaterialization(t *testing.T) {
+	const (
+		tableTypeQuery  = "SELECT table_type FROM information_schema.tables WHERE table_schema = 'default' AND table_name = 'report_cpu'"
+		createView      = "CREATE OR REPLACE VIEW report_cpu AS SELECT namespace, cpu FROM usage"
+		dropView        = "DROP VIEW IF EXISTS report_cpu"
+		showColumns     = "SHOW COLUMNS FROM report_cpu"
+		insert          = `INSERT INTO report_cpu ("namespace", "cpu") SELECT namespace, cpu FROM usage`
+		createTable     = "CREATE  TABLE IF NOT EXISTS\nreport_cpu (`namespace` varchar,`cpu` double) \n  LOCATION \"hdfs://hdfs-namenode-proxy:8020/operator_metering/storage/report_cpu\" "
+		dropTable       = "DROP TABLE IF EXISTS report_cpu PURGE"
+		tableLocation   = "hdfs://hdfs-namenode-proxy:8020/operator_metering/storage/report_cpu"
+		existingTable   = presto.TableTypeTable
+		existingView    = presto.TableTypeView
+		noTableLocation = ""
+	)
+	tests := map[string]struct {
+		materialization       cbTypes.ReportMaterializationPolicy
+		existingTableType     string
+		expectedPrestoQueries []string
+		expectedHiveQueries   []string
+		expectedLocation      string
+	}{
+		"table": {
+			materialization:       cbTypes.ReportMaterializationTable,
+			expectedPrestoQueries: []string{tableTypeQuery, showColumns, insert},
+			expectedHiveQueries:   []string{createTable},
+			expectedLocation:      tableLocation,
+		},
+		"table with an existing table": {
+			materialization:       cbTypes.ReportMaterializationTable,
+			existingTableType:     existingTable,
+			expectedPrestoQueries: []string{tableTypeQuery, showColumns, insert},
+			expectedHiveQueries:   []string{createTable},
+			expectedLocation:      tableLocation,
+		},
+		"view": {
+			materialization:       cbTypes.ReportMaterializationView,
+			expectedPrestoQueries: []string{tableTypeQuery, createView},
+			expectedLocation:      noTableLocation,
+		},
+		"view with an existing view": {
+			materialization:       cbTypes.ReportMaterializationView,
+			existingTableType:     existingView,
+			expectedPrestoQueries: []string{tableTypeQuery, createView},
+			expectedLocation:      noTableLocation,
+		},
+		"materialized": {
+			materialization:       cbTypes.ReportMaterializationMaterialized,
+			expectedPrestoQueries: []string{tableTypeQuery, insert},
+			expectedHiveQueries:   []string{dropTable, createTable},
+			expectedLocation:      tableLocation,
+		},
+		"table switched to view": {
+			materialization:       cbTypes.ReportMaterializationView,
+			existingTableType:     existingTable,
+			expectedPrestoQueries: []string{tableTypeQuery, createView},
+			expectedHiveQueries:   []string{dropTable},
+			expectedLocation:      noTableLocation,
+		},
+		"view switched to table": {
+			materialization:       cbTypes.ReportMaterializationTable,
+			existingTableType:     existingView,
+			expectedPrestoQueries: []string{tableTypeQuery, dropView, showColumns, insert},
+			expectedHiveQueries:   []string{createTable},
+			expectedLocation:      tableLocation,
+		},
+		"view switched to materialized": {
+			materialization:       cbTypes.ReportMaterializationMaterialized,
+			existingTableType:     existingView,
+			expectedPrestoQueries: []string{tableTypeQuery, dropView, insert},
+			expectedHiveQueries:   []string{dropTable, createTable},
+			expectedLocation:      tableLocation,
+		},
+	}
+
+	reportStart := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	reportEnd := reportStart.Add(24 * time.Hour)
+	storage := &cbTypes.StorageLocationRef{
+		StorageSpec: &cbTypes.StorageLocationSpec{
+			Hive: &cbTypes.HiveStorage{
+				TableProperties: cbTypes.TableProperties{Location: "hdfs://hdfs-namenode-proxy:8020/operator_metering/storage/"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			generationQuery := &cbTypes.ReportGenerationQuery{
+				ObjectMeta: meta.ObjectMeta{Namespace: "metering", Name: "namespace-cpu"},
+				Spec: cbTypes.ReportGenerationQuerySpec{
+					Query:           "SELECT namespace, cpu FROM usage",
+					Columns:         []cbTypes.ReportGenerationQueryColumn{{Name: "namespace", Type: "varchar"}, {Name: "cpu", Type: "double"}},
+					Materialization: test.materialization,
+				},
+			}
+			report := &cbTypes.Report{
+				ObjectMeta: meta.ObjectMeta{Namespace: "metering", Name: "cpu"},
+				Spec: cbTypes.ReportSpec{
+					GenerationQueryName: generationQuery.Name,
+					ReportingStart:      meta.NewTime(reportStart),
+					ReportingEnd:        meta.NewTime(reportEnd),
+				},
+			}
+			prestoQueryer := &recordingPrestoQueryer{
+				results: map[string][]presto.Row{
+					"SHOW COLUMNS FROM report_cpu": {{"Column": "namespace"}, {"Column": "cpu"}},
+				},
+			}
+			meteringClient := fake.NewSimpleClientset()
+			if test.existingTableType != "" {
+				prestoQueryer.results[tableTypeQuery] = []presto.Row{{"table_type": test.existingTableType}}
+				_, err := meteringClient.MeteringV1alpha1().PrestoTables("metering").Create(&cbTypes.PrestoTable{
+					ObjectMeta: meta.ObjectMeta{Namespace: "metering", Name: "report-cpu"},
+				})
+				require.NoError(t, err)
+			}
+			hiveQueryer := &recordingHiveQueryer{}
+			op := &Reporting{
+				logger:         testLogger,
+				clock:          clock.NewFakeClock(reportEnd),
+				cfg:            Config{Namespace: "metering"},
+				informers:      cbInformers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0),
+				meteringClient: meteringClient,
+				prestoQueryer:  prestoQueryer,
+				hiveQueryer:    hiveQueryer,
+			}
+
+			err := op.generateReport(context.Background(), testLogger, report, "report", report.Name, "report_cpu", reportStart, reportEnd, storage, generationQuery, false, false)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedPrestoQueries, prestoQueryer.queries)
+			assert.Equal(t, test.expectedHiveQueries, hiveQueryer.queries)
+
+			prestoTable, err := meteringClient.MeteringV1alpha1().PrestoTables("metering").Get("report-cpu", meta.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedLocation, prestoTable.State.Properties.Location)
+		})
+	}
+}
+
+func TestNewReportRunContext(t *testing.T) {
+	hour := int64(3600)
+	tests := map[string]struct {
+		activeDeadlineSeconds *int64
+	}{
+		"no deadline": {
+			activeDeadlineSeconds: nil,
+		},
+		"deadline": {
+			activeDeadlineSeconds: &hour,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			stopCh := make(chan struct{})
+			ctx, cancel := newReportRunContext(stopCh, test.activeDeadlineSeconds)
+			defer cancel()
+
+			assert.NoError(t, ctx.Err(), "expected the context to be active before stopCh is closed")
+			close(stopCh)
+			select {
+			case <-ctx.Done():
+			case <-time.After(5 * time.Second):
+				t.Fatal("expected the context to be cancelled once stopCh is closed")
+			}
+			assert.Equal(t, context.Canceled, ctx.Err())
+			assert.False(t, reportRunTimedOut(ctx), "expected a stopped run not to count as timed out")
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package operator
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	// podLabelsDataSourceName and namespaceLabelsDataSourceName are the
+	// default ReportDataSources importing the kube_pod_labels and
+	// kube_namespace_labels metrics, which report label values are joined
+	// in from.
+	podLabelsDataSourceName       = "pod-labels"
+	namespaceLabelsDataSourceName = "namespace-labels"
+
+	groupByLabelColumnUnit = "kubernetes_label"
+)
+
+// invalidPrometheusLabelCharRE matches the characters kube-state-metrics
+// replaces with underscores when exporting Kubernetes labels as Prometheus
+// labels.
+var invalidPrometheusLabelCharRE = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// groupByLabel is a label a report's results are grouped by.
+type groupByLabel struct {
+	// Key is the Kubernetes label key.
+	Key string
+	// PrometheusLabel is the name of the label in the kube_pod_labels and
+	// kube_namespace_labels metrics, eg: label_cost_center.
+	PrometheusLabel string
+	// Column is the name of the report column holding the label's value.
+	Column string
+}
+
+func newGroupByLabel(key string) groupByLabel {
+	promLabel := "label_" + invalidPrometheusLabelCharRE.ReplaceAllString(key, "_")
+	return groupByLabel{
+		Key:             key,
+		PrometheusLabel: promLabel,
+		// Hive column names are case insensitive.
+		Column: strings.ToLower(promLabel),
+	}
+}
+
+func getReportGroupByLabels(report runtime.Object) []string {
+	switch r := report.(type) {
+	case *cbTypes.Report:
+		return r.Spec.GroupByLabels
+	case *cbTypes.ScheduledReport:
+		return r.Spec.GroupByLabels
+	}
+	return nil
+}
+
+// getGroupByLabels validates the label keys a report groups by, and returns
+// the groupByLabel for each of them.
+func getGroupByLabels(generationQuery *cbTypes.ReportGenerationQuery, keys []string) ([]groupByLabel, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	if !generationQuery.Spec.SupportsGroupByLabels {
+		return nil, fmt.Errorf("ReportGenerationQuery %s does not support groupByLabels", generationQuery.Name)
+	}
+	columns := make(map[string]string)
+	for _, col := range generationQuery.Spec.Columns {
+		columns[strings.ToLower(col.Name)] = fmt.Sprintf("column %s of ReportGenerationQuery %s", col.Name, generationQuery.Name)
+	}
+	labels := make([]groupByLabel, len(keys))
+	for i, key := range keys {
+		if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+			return nil, fmt.Errorf("invalid groupByLabels key %q: %s", key, strings.Join(errs, ", "))
+		}
+		labels[i] = newGroupByLabel(key)
+		if conflict, exists := columns[labels[i].Column]; exists {
+			return nil, fmt.Errorf("groupByLabels key %q uses the column name %s, which conflicts with %s", key, labels[i].Column, conflict)
+		}
+		columns[labels[i].Column] = fmt.Sprintf("groupByLabels key %q", key)
+	}
+	return labels, nil
+}
+
+// getReportColumns returns the columns of a report using the
+// generationQuery, which are the query's columns followed by a column for
+// each label the report groups by.
+func getReportColumns(generationQuery *cbTypes.ReportGenerationQuery, labels []groupByLabel) []cbTypes.ReportGenerationQueryColumn {
+	columns := append([]cbTypes.ReportGenerationQueryColumn(nil), generationQuery.Spec.Columns...)
+	for _, label := range labels {
+		columns = append(columns, cbTypes.ReportGenerationQueryColumn{
+			Name: label.Column,
+			Type: "string",
+			Unit: groupByLabelColumnUnit,
+		})
+	}
+	return columns
+}
+
+// GroupByLabelColumns renders the label columns the report groups by from
+// the table alias, each preceded by a comma so it can be appended to the
+// columns of a SELECT, eg:
+// SELECT sum(amount) AS amount{| .Report.GroupByLabelColumns "labels" |}
+func (info *reportTemplateInfo) GroupByLabelColumns(alias string) string {
+	var buf bytes.Buffer
+	for _, label := range info.GroupByLabels {
+		fmt.Fprintf(&buf, `, %s."%s"`, alias, label.Column)
+	}
+	return buf.String()
+}
+
+// GroupByLabelsClause renders a GROUP BY clause for the label columns the
+// report groups by from the table alias, or nothing if the report doesn't
+// group by labels.
+func (info *reportTemplateInfo) GroupByLabelsClause(alias string) string {
+	if len(info.GroupByLabels) == 0 {
+		return ""
+	}
+	columns := make([]string, len(info.GroupByLabels))
+	for i, label := range info.GroupByLabels {
+		columns[i] = fmt.Sprintf(`%s."%s"`, alias, label.Column)
+	}
+	return "GROUP BY " + strings.Join(columns, ", ")
+}
+
+// GroupByLabelsTable renders a subquery with the namespace, pod and the
+// value of each label the report groups by for every pod with labels during
+// the reporting period. The most recent labels of the pod during the period
+// are used, and labels the pod doesn't have are taken from its namespace.
+// Queries using it must depend on the pod-labels and namespace-labels
+// ReportDataSources.
+func (info *reportTemplateInfo) GroupByLabelsTable() string {
+	var columns bytes.Buffer
+	for _, label := range info.GroupByLabels {
+		fmt.Fprintf(&columns, ",\n    coalesce(element_at(pod_labels.labels, '%[1]s'), element_at(namespace_labels.labels, '%[1]s')) AS \"%[2]s\"", label.PrometheusLabel, label.Column)
+	}
+	periodFilter := fmt.Sprintf(`"timestamp" >= timestamp '%s' AND "timestamp" <= timestamp '%s'`, presto.Timestamp(info.StartPeriod), presto.Timestamp(info.EndPeriod))
+	return fmt.Sprintf(`(
+  SELECT pod_labels.namespace, pod_labels.pod%s
+  FROM (
+    SELECT labels['namespace'] AS namespace, labels['pod'] AS pod, max_by(labels, "timestamp") AS labels
+    FROM %s
+    WHERE %s
+    GROUP BY labels['namespace'], labels['pod']
+  ) pod_labels
+  LEFT JOIN (
+    SELECT labels['namespace'] AS namespace, max_by(labels, "timestamp") AS labels
+    FROM %s
+    WHERE %s
+    GROUP BY labels['namespace']
+  ) namespace_labels
+  ON pod_labels.namespace = namespace_labels.namespace
+)`, columns.String(), tenantDataSourceTableName(info.tenantNamespace, podLabelsDataSourceName), periodFilter, tenantDataSourceTableName(info.tenantNamespace, namespaceLabelsDataSourceName), periodFilter)
+}
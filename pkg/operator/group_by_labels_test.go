@@ -0,0 +1,135 @@
+package operator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestGetGroupByLabels(t *testing.T) {
+	newQuery := func(supportsGroupByLabels bool) *cbTypes.ReportGenerationQuery {
+		return &cbTypes.ReportGenerationQuery{
+			ObjectMeta: meta.ObjectMeta{Name: "label-cpu-request"},
+			Spec: cbTypes.ReportGenerationQuerySpec{
+				SupportsGroupByLabels: supportsGroupByLabels,
+				Columns: []cbTypes.ReportGenerationQueryColumn{
+					{Name: "period_start", Type: "timestamp"},
+					{Name: "label_app", Type: "string"},
+				},
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		query       *cbTypes.ReportGenerationQuery
+		keys        []string
+		expected    []groupByLabel
+		expectedErr string
+	}{
+		"no labels": {
+			query: newQuery(false),
+		},
+		"labels": {
+			query: newQuery(true),
+			keys:  []string{"team", "Cost-Center", "example.com/owner"},
+			expected: []groupByLabel{
+				{Key: "team", PrometheusLabel: "label_team", Column: "label_team"},
+				{Key: "Cost-Center", PrometheusLabel: "label_Cost_Center", Column: "label_cost_center"},
+				{Key: "example.com/owner", PrometheusLabel: "label_example_com_owner", Column: "label_example_com_owner"},
+			},
+		},
+		"unsupported query": {
+			query:       newQuery(false),
+			keys:        []string{"team"},
+			expectedErr: "ReportGenerationQuery label-cpu-request does not support groupByLabels",
+		},
+		"invalid key": {
+			query:       newQuery(true),
+			keys:        []string{"team name"},
+			expectedErr: `invalid groupByLabels key "team name"`,
+		},
+		"conflicts with query column": {
+			query:       newQuery(true),
+			keys:        []string{"app"},
+			expectedErr: `groupByLabels key "app" uses the column name label_app, which conflicts with column label_app of ReportGenerationQuery label-cpu-request`,
+		},
+		"keys with the same column": {
+			query:       newQuery(true),
+			keys:        []string{"cost-center", "cost_center"},
+			expectedErr: `groupByLabels key "cost_center" uses the column name label_cost_center, which conflicts with groupByLabels key "cost-center"`,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			labels, err := getGroupByLabels(tt.query, tt.keys)
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, labels)
+		})
+	}
+}
+
+func TestRenderGroupByLabels(t *testing.T) {
+	const query = `SELECT sum(amount) AS amount{| .Report.GroupByLabelColumns "l" |} FROM usage LEFT JOIN {| .Report.GroupByLabelsTable |} l ON usage.pod = l.pod {| .Report.GroupByLabelsClause "l" |}`
+
+	tests := map[string]struct {
+		keys             []string
+		expectedContains []string
+		expectedSuffix   string
+	}{
+		"no labels": {
+			expectedContains: []string{
+				"SELECT sum(amount) AS amount FROM usage",
+				"datasource_pod_labels",
+				"datasource_namespace_labels",
+			},
+			expectedSuffix: "ON usage.pod = l.pod ",
+		},
+		"labels": {
+			keys: []string{"team", "cost-center"},
+			expectedContains: []string{
+				`SELECT sum(amount) AS amount, l."label_team", l."label_cost_center" FROM usage`,
+				`coalesce(element_at(pod_labels.labels, 'label_cost_center'), element_at(namespace_labels.labels, 'label_cost_center')) AS "label_cost_center"`,
+				`"timestamp" >= timestamp '2018-07-01 00:00:00.000' AND "timestamp" <= timestamp '2018-08-01 00:00:00.000'`,
+			},
+			expectedSuffix: `GROUP BY l."label_team", l."label_cost_center"`,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			var labels []groupByLabel
+			for _, key := range tt.keys {
+				labels = append(labels, newGroupByLabel(key))
+			}
+			qr := queryRenderer{templateInfo: &templateInfo{
+				Report: &reportTemplateInfo{
+					StartPeriod:   time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC),
+					EndPeriod:     time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC),
+					GroupByLabels: labels,
+				},
+			}}
+			rendered, err := qr.Render(query)
+			require.NoError(t, err)
+			for _, s := range tt.expectedContains {
+				assert.Contains(t, rendered, s)
+			}
+			assert.True(t, strings.HasSuffix(rendered, tt.expectedSuffix), "expected %q to end with %q", rendered, tt.expectedSuffix)
+		})
+	}
+}
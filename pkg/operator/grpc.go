@@ -0,0 +1,653 @@
+package operator
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	api "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbClientset "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/reportingpb"
+)
+
+// defaultGRPCResultsBatchSize is the number of rows sent in each batch of
+// streamed results if the request doesn't set a batch size.
+const defaultGRPCResultsBatchSize = 1000
+
+// GRPCConfig configures the gRPC API, which is served alongside the HTTP API.
+// Unlike the HTTP API it isn't behind the auth proxy, so it has its own TLS
+// config, and can require client certificates. When API auth is enabled,
+// calls are authenticated and authorized the same way as HTTP requests.
+type GRPCConfig struct {
+	Enabled   bool
+	TLSConfig TLSConfig
+	// ClientCAFile, if set, is the path to the CA certificates client
+	// certificates are verified with, and clients without a valid
+	// certificate are rejected. Requires TLS to be enabled.
+	ClientCAFile string
+}
+
+func (cfg *GRPCConfig) Valid() error {
+	if err := cfg.TLSConfig.Valid(); err != nil {
+		return err
+	}
+	if cfg.ClientCAFile != "" && !cfg.TLSConfig.UseTLS {
+		return fmt.Errorf("Must enable TLS to verify gRPC client certificates")
+	}
+	return nil
+}
+
+// grpcServer implements the reportingpb.ReportingServer gRPC service.
+type grpcServer struct {
+	logger         log.FieldLogger
+	queryer        presto.ExecQueryer
+	meteringClient cbClientset.Interface
+	namespace      string
+	listers        meteringListers
+	// auth, if set, authenticates calls by their bearer token, and
+	// authorizes them against the resources they access.
+	auth     *apiAuth
+	readOnly bool
+	// rowLevelSecurity is set if the HTTP API restricts report results to
+	// the namespaces the user can access. The gRPC API doesn't filter
+	// results by namespace, so it can't stream them.
+	rowLevelSecurity bool
+}
+
+func newGRPCServer(logger log.FieldLogger, queryer presto.ExecQueryer, meteringClient cbClientset.Interface, namespace string, listers meteringListers, auth *apiAuth, readOnly, rowLevelSecurity bool, opts ...grpc.ServerOption) *grpc.Server {
+	srv := &grpcServer{
+		logger:           logger,
+		queryer:          queryer,
+		meteringClient:   meteringClient,
+		namespace:        namespace,
+		listers:          listers,
+		auth:             auth,
+		readOnly:         readOnly,
+		rowLevelSecurity: rowLevelSecurity,
+	}
+	opts = append(opts,
+		grpc.UnaryInterceptor(srv.handleUnaryCall),
+		grpc.StreamInterceptor(srv.handleStreamCall),
+	)
+	grpcSrv := grpc.NewServer(opts...)
+	reportingpb.RegisterReportingServer(grpcSrv, srv)
+	return grpcSrv
+}
+
+// newGRPCServerCredentials returns the TLS credentials of the gRPC API,
+// requiring client certificates signed by the CA in clientCAFile if it's
+// set.
+func newGRPCServerCredentials(tlsConfig TLSConfig, clientCAFile string) (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(tlsConfig.TLSCert, tlsConfig.TLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load TLS certificate: %v", err)
+	}
+	serverTLSConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if clientCAFile != "" {
+		clientCAs, err := loadCertPool(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		serverTLSConfig.ClientCAs = clientCAs
+		serverTLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(serverTLSConfig), nil
+}
+
+// handleUnaryCall authenticates and authorizes unary calls before handling
+// them, and logs them.
+func (srv *grpcServer) handleUnaryCall(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	ctx, tokenHash, err := srv.authenticateCall(ctx)
+	if err == nil {
+		err = srv.authorizeCall(ctx, tokenHash, req)
+	}
+	var resp interface{}
+	if err == nil {
+		resp, err = handler(ctx, req)
+	}
+	srv.logCall(info.FullMethod, start, err)
+	return resp, err
+}
+
+// handleStreamCall authenticates streaming calls before handling them,
+// authorizing the request once it's received, and logs them.
+func (srv *grpcServer) handleStreamCall(s interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx, tokenHash, err := srv.authenticateCall(stream.Context())
+	if err == nil {
+		err = handler(s, &authorizedServerStream{ServerStream: stream, srv: srv, ctx: ctx, tokenHash: tokenHash})
+	}
+	srv.logCall(info.FullMethod, start, err)
+	return err
+}
+
+// authorizedServerStream authorizes each request received on a stream
+// before it's handled.
+type authorizedServerStream struct {
+	grpc.ServerStream
+	srv       *grpcServer
+	ctx       context.Context
+	tokenHash string
+}
+
+func (s *authorizedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *authorizedServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.srv.authorizeCall(s.ctx, s.tokenHash, m)
+}
+
+// authenticateCall validates the bearer token in the call's metadata if API
+// auth is enabled, returning a context containing the authenticated user
+// and the hash of the token.
+func (srv *grpcServer) authenticateCall(ctx context.Context) (context.Context, string, error) {
+	if srv.auth == nil {
+		return ctx, "", nil
+	}
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, header := range md.Get("authorization") {
+			if token = parseBearerToken(header); token != "" {
+				break
+			}
+		}
+	}
+	if token == "" {
+		return ctx, "", status.Error(codes.Unauthenticated, "a bearer token is required")
+	}
+	tokenHash := hashToken(token)
+	user, authenticated, err := srv.auth.authenticate(srv.logger, token, tokenHash)
+	if err != nil {
+		return ctx, "", status.Errorf(codes.Internal, "unable to authenticate the call: %v", err)
+	}
+	if !authenticated {
+		return ctx, "", status.Error(codes.Unauthenticated, "the bearer token is invalid")
+	}
+	return context.WithValue(ctx, authenticatedUserKey{}, user), tokenHash, nil
+}
+
+// authorizeCall checks the authenticated user in ctx has the access to the
+// Metering resource req requires, if API auth is enabled.
+func (srv *grpcServer) authorizeCall(ctx context.Context, tokenHash string, req interface{}) error {
+	if srv.auth == nil {
+		return nil
+	}
+	user, ok := authenticatedUser(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "the call isn't authenticated")
+	}
+	verb, resource, name, ok := grpcRequestAccess(req)
+	if !ok {
+		return status.Errorf(codes.PermissionDenied, "unknown request %T", req)
+	}
+	spec := userAccessReviewSpec(user)
+	spec.ResourceAttributes = &authorizationv1.ResourceAttributes{
+		Namespace: srv.namespace,
+		Verb:      verb,
+		Group:     api.GroupName,
+		Resource:  resource,
+		Name:      name,
+	}
+	allowed, reason, err := srv.auth.authorize(spec, tokenHash)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to authorize the call: %v", err)
+	}
+	if !allowed {
+		srv.logger.WithFields(log.Fields{"user": user.Username, "reason": reason}).Debugf("gRPC call denied")
+		return status.Errorf(codes.PermissionDenied, "user %s cannot %s", user.Username, describeAccess(spec))
+	}
+	return nil
+}
+
+// grpcRequestAccess returns the verb, resource and name of the Metering
+// resource a request accesses, mirroring the access required by the
+// equivalent HTTP endpoints.
+func grpcRequestAccess(req interface{}) (verb, resource, name string, ok bool) {
+	switch req := req.(type) {
+	case *reportingpb.ListReportsRequest:
+		return "list", "reports", "", true
+	case *reportingpb.GetReportRequest:
+		return "get", "reports", req.Name, true
+	case *reportingpb.CreateReportRequest:
+		return "create", "reports", "", true
+	case *reportingpb.DeleteReportRequest:
+		return "delete", "reports", req.Name, true
+	case *reportingpb.ListScheduledReportsRequest:
+		return "list", "scheduledreports", "", true
+	case *reportingpb.GetScheduledReportRequest:
+		return "get", "scheduledreports", req.Name, true
+	case *reportingpb.StreamReportResultsRequest:
+		switch req.Kind {
+		case reportingpb.ReportKind_REPORT:
+			return "get", "reports", req.Name, true
+		case reportingpb.ReportKind_SCHEDULED_REPORT:
+			return "get", "scheduledreports", req.Name, true
+		}
+	case *reportingpb.ListReportDataSourcesRequest:
+		return "list", "reportdatasources", "", true
+	case *reportingpb.GetReportDataSourceRequest:
+		return "get", "reportdatasources", req.Name, true
+	}
+	return "", "", "", false
+}
+
+func (srv *grpcServer) logCall(method string, start time.Time, err error) {
+	logger := srv.logger.WithFields(log.Fields{
+		"method":   method,
+		"code":     status.Code(err),
+		"duration": time.Since(start),
+	})
+	if err != nil && status.Code(err) == codes.Internal {
+		logger.WithError(err).Errorf("gRPC call failed")
+		return
+	}
+	logger.Debugf("gRPC call finished")
+}
+
+func (srv *grpcServer) ListReports(ctx context.Context, req *reportingpb.ListReportsRequest) (*reportingpb.ListReportsResponse, error) {
+	reports, err := srv.listers.reports.List(labels.Everything())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error listing reports: %v", err)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Name < reports[j].Name })
+	resp := &reportingpb.ListReportsResponse{}
+	for _, report := range reports {
+		resp.Reports = append(resp.Reports, reportToProto(report))
+	}
+	return resp, nil
+}
+
+func (srv *grpcServer) GetReport(ctx context.Context, req *reportingpb.GetReportRequest) (*reportingpb.Report, error) {
+	report, err := srv.listers.reports.Get(req.Name)
+	if err != nil {
+		return nil, kubeErrorToGRPC(err, "error getting report")
+	}
+	return reportToProto(report), nil
+}
+
+func (srv *grpcServer) CreateReport(ctx context.Context, req *reportingpb.CreateReportRequest) (*reportingpb.Report, error) {
+	if srv.readOnly {
+		return nil, status.Error(codes.FailedPrecondition, "reports can't be created in read-only mode")
+	}
+	report, err := reportFromProto(req.Report)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	report, err = srv.meteringClient.MeteringV1alpha1().Reports(srv.namespace).Create(report)
+	if err != nil {
+		return nil, kubeErrorToGRPC(err, "error creating report")
+	}
+	return reportToProto(report), nil
+}
+
+func (srv *grpcServer) DeleteReport(ctx context.Context, req *reportingpb.DeleteReportRequest) (*reportingpb.DeleteReportResponse, error) {
+	if srv.readOnly {
+		return nil, status.Error(codes.FailedPrecondition, "reports can't be deleted in read-only mode")
+	}
+	err := srv.meteringClient.MeteringV1alpha1().Reports(srv.namespace).Delete(req.Name, &metav1.DeleteOptions{})
+	if err != nil {
+		return nil, kubeErrorToGRPC(err, "error deleting report")
+	}
+	return &reportingpb.DeleteReportResponse{}, nil
+}
+
+func (srv *grpcServer) ListScheduledReports(ctx context.Context, req *reportingpb.ListScheduledReportsRequest) (*reportingpb.ListScheduledReportsResponse, error) {
+	reports, err := srv.listers.scheduledReports.List(labels.Everything())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error listing scheduledReports: %v", err)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Name < reports[j].Name })
+	resp := &reportingpb.ListScheduledReportsResponse{}
+	for _, report := range reports {
+		resp.ScheduledReports = append(resp.ScheduledReports, scheduledReportToProto(report))
+	}
+	return resp, nil
+}
+
+func (srv *grpcServer) GetScheduledReport(ctx context.Context, req *reportingpb.GetScheduledReportRequest) (*reportingpb.ScheduledReport, error) {
+	report, err := srv.listers.scheduledReports.Get(req.Name)
+	if err != nil {
+		return nil, kubeErrorToGRPC(err, "error getting scheduledReport")
+	}
+	return scheduledReportToProto(report), nil
+}
+
+// StreamReportResults sends the results of a report in batches as they're
+// read from Presto, so the results are never all held in memory.
+func (srv *grpcServer) StreamReportResults(req *reportingpb.StreamReportResultsRequest, stream reportingpb.Reporting_StreamReportResultsServer) error {
+	if srv.rowLevelSecurity {
+		return status.Error(codes.PermissionDenied, "report results can't be streamed over gRPC when row-level security is enabled, use the HTTP API")
+	}
+	if req.BatchSize < 0 {
+		return status.Errorf(codes.InvalidArgument, "batch_size must not be negative, got %d", req.BatchSize)
+	}
+	batchSize := int(req.BatchSize)
+	if batchSize == 0 {
+		batchSize = defaultGRPCResultsBatchSize
+	}
+
+	logger := srv.logger.WithFields(log.Fields{"kind": req.Kind.String(), "name": req.Name})
+	var (
+		tableName     string
+		reportColumns []api.ReportGenerationQueryColumn
+		prestoColumns []presto.Column
+		err           error
+	)
+	switch req.Kind {
+	case reportingpb.ReportKind_REPORT:
+		tableName, reportColumns, prestoColumns, err = getReportTable(logger, srv.listers, req.Name)
+	case reportingpb.ReportKind_SCHEDULED_REPORT:
+		tableName, reportColumns, prestoColumns, err = getScheduledReportTable(logger, srv.listers, req.Name, req.IgnoreFailed)
+	default:
+		return status.Errorf(codes.InvalidArgument, "unknown report kind %v", req.Kind)
+	}
+	if err != nil {
+		return reportTableErrorToGRPC(err)
+	}
+	reportColumns, prestoColumns, whereSQL, err := selectReportColumns(reportColumns, prestoColumns, req.Columns, req.Filters)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	batch := &reportingpb.ReportResultsBatch{}
+	for _, col := range reportColumns {
+		batch.Columns = append(batch.Columns, &reportingpb.Column{Name: col.Name, Type: col.Type})
+	}
+	// the columns are always sent, even if there are no results.
+	sent := false
+	err = presto.StreamRows(stream.Context(), srv.queryer, tableName, prestoColumns, whereSQL, func(row presto.Row) error {
+		if len(row) != len(prestoColumns) {
+			return fmt.Errorf("report results schema doesn't match expected schema, got %d columns, expected %d", len(row), len(prestoColumns))
+		}
+		pbRow, err := rowToProto(prestoColumns, row)
+		if err != nil {
+			return err
+		}
+		batch.Rows = append(batch.Rows, pbRow)
+		if len(batch.Rows) < batchSize {
+			return nil
+		}
+		if err := stream.Send(batch); err != nil {
+			return err
+		}
+		sent = true
+		batch = &reportingpb.ReportResultsBatch{}
+		return nil
+	})
+	if err != nil {
+		switch stream.Context().Err() {
+		case context.Canceled:
+			return status.Error(codes.Canceled, err.Error())
+		case context.DeadlineExceeded:
+			return status.Error(codes.DeadlineExceeded, err.Error())
+		}
+		return status.Errorf(codes.Internal, "failed to stream report results: %v", err)
+	}
+	if len(batch.Rows) != 0 || !sent {
+		return stream.Send(batch)
+	}
+	return nil
+}
+
+func (srv *grpcServer) ListReportDataSources(ctx context.Context, req *reportingpb.ListReportDataSourcesRequest) (*reportingpb.ListReportDataSourcesResponse, error) {
+	dataSources, err := srv.listers.reportDataSources.List(labels.Everything())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error listing reportDataSources: %v", err)
+	}
+	sort.Slice(dataSources, func(i, j int) bool { return dataSources[i].Name < dataSources[j].Name })
+	resp := &reportingpb.ListReportDataSourcesResponse{}
+	for _, dataSource := range dataSources {
+		resp.ReportDataSources = append(resp.ReportDataSources, reportDataSourceToProto(dataSource))
+	}
+	return resp, nil
+}
+
+func (srv *grpcServer) GetReportDataSource(ctx context.Context, req *reportingpb.GetReportDataSourceRequest) (*reportingpb.ReportDataSource, error) {
+	dataSource, err := srv.listers.reportDataSources.Get(req.Name)
+	if err != nil {
+		return nil, kubeErrorToGRPC(err, "error getting reportDataSource")
+	}
+	return reportDataSourceToProto(dataSource), nil
+}
+
+func kubeErrorToGRPC(err error, msg string) error {
+	code := codes.Internal
+	switch {
+	case k8serrors.IsNotFound(err):
+		code = codes.NotFound
+	case k8serrors.IsAlreadyExists(err):
+		code = codes.AlreadyExists
+	case k8serrors.IsInvalid(err), k8serrors.IsBadRequest(err):
+		code = codes.InvalidArgument
+	case k8serrors.IsForbidden(err):
+		code = codes.PermissionDenied
+	}
+	return status.Errorf(code, "%s: %v", msg, err)
+}
+
+// reportTableErrorToGRPC converts the HTTP status code of a reportTableError
+// to the equivalent gRPC code.
+func reportTableErrorToGRPC(err error) error {
+	code := codes.Internal
+	if tableErr, ok := err.(*reportTableError); ok {
+		switch tableErr.code {
+		case http.StatusNotFound:
+			code = codes.NotFound
+		case http.StatusAccepted:
+			code = codes.FailedPrecondition
+		}
+	}
+	return status.Error(code, err.Error())
+}
+
+func timeToProto(t time.Time) *timestamp.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return &timestamp.Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}
+}
+
+func metaTimeToProto(t *metav1.Time) *timestamp.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timeToProto(t.Time)
+}
+
+func timeFromProto(ts *timestamp.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return time.Unix(ts.Seconds, int64(ts.Nanos)).UTC()
+}
+
+func inputsToProto(inputs []api.ReportGenerationQueryInputValue) []*reportingpb.InputValue {
+	var pbInputs []*reportingpb.InputValue
+	for _, input := range inputs {
+		pbInputs = append(pbInputs, &reportingpb.InputValue{Name: input.Name, Value: input.Value})
+	}
+	return pbInputs
+}
+
+func reportToProto(report *api.Report) *reportingpb.Report {
+	return &reportingpb.Report{
+		Name:            report.Name,
+		CreationTime:    timeToProto(report.CreationTimestamp.Time),
+		GenerationQuery: report.Spec.GenerationQueryName,
+		ReportingStart:  timeToProto(report.Spec.ReportingStart.Time),
+		ReportingEnd:    timeToProto(report.Spec.ReportingEnd.Time),
+		RunImmediately:  report.Spec.RunImmediately,
+		Inputs:          inputsToProto(report.Spec.Inputs),
+		GroupByLabels:   report.Spec.GroupByLabels,
+		Status: &reportingpb.ReportStatus{
+			Phase:         string(report.Status.Phase),
+			Output:        report.Status.Output,
+			NextRetryTime: metaTimeToProto(report.Status.NextRetryTime),
+		},
+	}
+}
+
+func reportFromProto(pbReport *reportingpb.Report) (*api.Report, error) {
+	if pbReport == nil {
+		return nil, fmt.Errorf("report must be set")
+	}
+	if pbReport.Name == "" {
+		return nil, fmt.Errorf("report name must be set")
+	}
+	if pbReport.GenerationQuery == "" {
+		return nil, fmt.Errorf("report generation_query must be set")
+	}
+	if pbReport.ReportingStart == nil || pbReport.ReportingEnd == nil {
+		return nil, fmt.Errorf("report reporting_start and reporting_end must be set")
+	}
+	report := &api.Report{
+		ObjectMeta: metav1.ObjectMeta{Name: pbReport.Name},
+		Spec: api.ReportSpec{
+			GenerationQueryName: pbReport.GenerationQuery,
+			ReportingStart:      metav1.NewTime(timeFromProto(pbReport.ReportingStart)),
+			ReportingEnd:        metav1.NewTime(timeFromProto(pbReport.ReportingEnd)),
+			RunImmediately:      pbReport.RunImmediately,
+			GroupByLabels:       pbReport.GroupByLabels,
+		},
+	}
+	if !report.Spec.ReportingStart.Before(&report.Spec.ReportingEnd) {
+		return nil, fmt.Errorf("report reporting_start must be before reporting_end")
+	}
+	for _, input := range pbReport.Inputs {
+		report.Spec.Inputs = append(report.Spec.Inputs, api.ReportGenerationQueryInputValue{Name: input.Name, Value: input.Value})
+	}
+	return report, nil
+}
+
+func scheduledReportToProto(report *api.ScheduledReport) *reportingpb.ScheduledReport {
+	pbReport := &reportingpb.ScheduledReport{
+		Name:            report.Name,
+		CreationTime:    timeToProto(report.CreationTimestamp.Time),
+		GenerationQuery: report.Spec.GenerationQueryName,
+		Period:          string(report.Spec.Schedule.Period),
+		Inputs:          inputsToProto(report.Spec.Inputs),
+		GroupByLabels:   report.Spec.GroupByLabels,
+		Status: &reportingpb.ScheduledReportStatus{
+			LastReportTime: metaTimeToProto(report.Status.LastReportTime),
+		},
+	}
+	for _, cond := range report.Status.Conditions {
+		pbReport.Status.Conditions = append(pbReport.Status.Conditions, &reportingpb.Condition{
+			Type:               string(cond.Type),
+			Status:             string(cond.Status),
+			Reason:             cond.Reason,
+			Message:            cond.Message,
+			LastTransitionTime: timeToProto(cond.LastTransitionTime.Time),
+		})
+	}
+	return pbReport
+}
+
+// reportDataSourceType returns the name of the field of the spec which is
+// set.
+func reportDataSourceType(spec api.ReportDataSourceSpec) string {
+	switch {
+	case spec.Promsum != nil:
+		return "promsum"
+	case spec.AWSBilling != nil:
+		return "awsBilling"
+	case spec.GCPBilling != nil:
+		return "gcpBilling"
+	case spec.KubernetesObjects != nil:
+		return "kubernetesObjects"
+	case spec.KubernetesEvents != nil:
+		return "kubernetesEvents"
+	case spec.RemoteReport != nil:
+		return "remoteReport"
+	case spec.ObjectStorage != nil:
+		return "objectStorage"
+	case spec.Database != nil:
+		return "database"
+	}
+	return ""
+}
+
+func reportDataSourceToProto(dataSource *api.ReportDataSource) *reportingpb.ReportDataSource {
+	pbDataSource := &reportingpb.ReportDataSource{
+		Name:         dataSource.Name,
+		CreationTime: timeToProto(dataSource.CreationTimestamp.Time),
+		Type:         reportDataSourceType(dataSource.Spec),
+		TableName:    dataSource.TableName,
+	}
+	for _, cond := range dataSource.Conditions {
+		pbDataSource.Conditions = append(pbDataSource.Conditions, &reportingpb.Condition{
+			Type:               string(cond.Type),
+			Status:             string(cond.Status),
+			Reason:             cond.Reason,
+			Message:            cond.Message,
+			LastTransitionTime: timeToProto(cond.LastTransitionTime.Time),
+		})
+	}
+	return pbDataSource
+}
+
+func rowToProto(columns []presto.Column, row presto.Row) (*reportingpb.Row, error) {
+	pbRow := &reportingpb.Row{Values: make([]*reportingpb.Value, len(columns))}
+	for i, col := range columns {
+		value, err := valueToProto(row[col.Name])
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert column %s: %v", col.Name, err)
+		}
+		pbRow.Values[i] = value
+	}
+	return pbRow, nil
+}
+
+// valueToProto converts a value returned by Presto to a Value, encoding
+// values of complex types as JSON.
+func valueToProto(val interface{}) (*reportingpb.Value, error) {
+	switch v := val.(type) {
+	case nil:
+		return &reportingpb.Value{}, nil
+	case string:
+		return &reportingpb.Value{Value: &reportingpb.Value_StringValue{StringValue: v}}, nil
+	case []byte:
+		return &reportingpb.Value{Value: &reportingpb.Value_StringValue{StringValue: string(v)}}, nil
+	case int64:
+		return &reportingpb.Value{Value: &reportingpb.Value_IntValue{IntValue: v}}, nil
+	case int32:
+		return &reportingpb.Value{Value: &reportingpb.Value_IntValue{IntValue: int64(v)}}, nil
+	case int:
+		return &reportingpb.Value{Value: &reportingpb.Value_IntValue{IntValue: int64(v)}}, nil
+	case float64:
+		return &reportingpb.Value{Value: &reportingpb.Value_DoubleValue{DoubleValue: v}}, nil
+	case float32:
+		return &reportingpb.Value{Value: &reportingpb.Value_DoubleValue{DoubleValue: float64(v)}}, nil
+	case bool:
+		return &reportingpb.Value{Value: &reportingpb.Value_BoolValue{BoolValue: v}}, nil
+	case time.Time:
+		return &reportingpb.Value{Value: &reportingpb.Value_TimestampValue{TimestampValue: timeToProto(v)}}, nil
+	}
+	b, err := json.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+	return &reportingpb.Value{Value: &reportingpb.Value_JsonValue{JsonValue: string(b)}}, nil
+}
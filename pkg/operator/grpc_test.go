@@ -0,0 +1,314 @@
+package operator
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/fake"
+	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/presto/mock"
+	"github.com/operator-framework/operator-metering/pkg/reportingpb"
+)
+
+// startTestGRPCServer serves the gRPC API on a random local port, returning
+// a client connected to it.
+func startTestGRPCServer(t *testing.T, queryer presto.ExecQueryer, listers meteringListers, auth *apiAuth, readOnly bool) (reportingpb.ReportingClient, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcSrv := newGRPCServer(testLogger, queryer, fake.NewSimpleClientset(), "default", listers, auth, readOnly, false)
+	go grpcSrv.Serve(listener)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	return reportingpb.NewReportingClient(conn), func() {
+		conn.Close()
+		grpcSrv.Stop()
+	}
+}
+
+func TestGRPCReports(t *testing.T) {
+	const namespace = "default"
+	start := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+
+	reportIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	reportIndexer.Add(newTestReport("finished", namespace, "test-query", start, end, v1alpha1.ReportStatus{Phase: v1alpha1.ReportPhaseFinished}))
+	reportIndexer.Add(newTestReport("failed", namespace, "test-query", start, end, v1alpha1.ReportStatus{Phase: v1alpha1.ReportPhaseError, Output: "query failed"}))
+	dataSourceIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	dataSourceIndexer.Add(&v1alpha1.ReportDataSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-request-cpu-cores", Namespace: namespace},
+		Spec:       v1alpha1.ReportDataSourceSpec{Promsum: &v1alpha1.PrometheusMetricsDataSource{}},
+		TableName:  "datasource_pod_request_cpu_cores",
+	})
+	listers := meteringListers{
+		reports:           listers.NewReportLister(reportIndexer).Reports(namespace),
+		reportDataSources: listers.NewReportDataSourceLister(dataSourceIndexer).ReportDataSources(namespace),
+	}
+
+	client, stop := startTestGRPCServer(t, nil, listers, nil, true)
+	defer stop()
+	ctx := context.Background()
+
+	resp, err := client.ListReports(ctx, &reportingpb.ListReportsRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Reports, 2)
+	assert.Equal(t, "failed", resp.Reports[0].Name, "expected reports to be sorted by name")
+	assert.Equal(t, "query failed", resp.Reports[0].Status.Output)
+
+	report, err := client.GetReport(ctx, &reportingpb.GetReportRequest{Name: "finished"})
+	require.NoError(t, err)
+	assert.Equal(t, "test-query", report.GenerationQuery)
+	assert.Equal(t, string(v1alpha1.ReportPhaseFinished), report.Status.Phase)
+	assert.Equal(t, start, timeFromProto(report.ReportingStart))
+
+	_, err = client.GetReport(ctx, &reportingpb.GetReportRequest{Name: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.CreateReport(ctx, &reportingpb.CreateReportRequest{Report: report})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "expected reports not to be created in read-only mode")
+
+	dataSource, err := client.GetReportDataSource(ctx, &reportingpb.GetReportDataSourceRequest{Name: "pod-request-cpu-cores"})
+	require.NoError(t, err)
+	assert.Equal(t, "promsum", dataSource.Type)
+	assert.Equal(t, "datasource_pod_request_cpu_cores", dataSource.TableName)
+}
+
+func TestGRPCCreateReport(t *testing.T) {
+	client, stop := startTestGRPCServer(t, nil, meteringListers{}, nil, false)
+	defer stop()
+	ctx := context.Background()
+
+	start := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	report, err := client.CreateReport(ctx, &reportingpb.CreateReportRequest{Report: &reportingpb.Report{
+		Name:            "namespace-cpu",
+		GenerationQuery: "namespace-cpu-request",
+		ReportingStart:  timeToProto(start),
+		ReportingEnd:    timeToProto(end),
+		Inputs:          []*reportingpb.InputValue{{Name: "namespace", Value: "team-a"}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "namespace-cpu", report.Name)
+	assert.Equal(t, end, timeFromProto(report.ReportingEnd))
+
+	_, err = client.CreateReport(ctx, &reportingpb.CreateReportRequest{Report: &reportingpb.Report{
+		Name:            "backwards",
+		GenerationQuery: "namespace-cpu-request",
+		ReportingStart:  timeToProto(end),
+		ReportingEnd:    timeToProto(start),
+	}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.DeleteReport(ctx, &reportingpb.DeleteReportRequest{Name: "namespace-cpu"})
+	require.NoError(t, err)
+	_, err = client.DeleteReport(ctx, &reportingpb.DeleteReportRequest{Name: "namespace-cpu"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGRPCAuth(t *testing.T) {
+	newReportRequest := func() *reportingpb.CreateReportRequest {
+		return &reportingpb.CreateReportRequest{Report: &reportingpb.Report{
+			Name:            "namespace-cpu",
+			GenerationQuery: "namespace-cpu-request",
+			ReportingStart:  timeToProto(time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)),
+			ReportingEnd:    timeToProto(time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)),
+		}}
+	}
+	tests := map[string]struct {
+		token        string
+		call         func(ctx context.Context, client reportingpb.ReportingClient) error
+		expectedCode codes.Code
+	}{
+		"create report without a token": {
+			call: func(ctx context.Context, client reportingpb.ReportingClient) error {
+				_, err := client.CreateReport(ctx, newReportRequest())
+				return err
+			},
+			expectedCode: codes.Unauthenticated,
+		},
+		"create report with an invalid token": {
+			token: "invalid",
+			call: func(ctx context.Context, client reportingpb.ReportingClient) error {
+				_, err := client.CreateReport(ctx, newReportRequest())
+				return err
+			},
+			expectedCode: codes.Unauthenticated,
+		},
+		"create report denied": {
+			token: "bob-token",
+			call: func(ctx context.Context, client reportingpb.ReportingClient) error {
+				_, err := client.CreateReport(ctx, newReportRequest())
+				return err
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+		"create report allowed": {
+			token: "alice-token",
+			call: func(ctx context.Context, client reportingpb.ReportingClient) error {
+				_, err := client.CreateReport(ctx, newReportRequest())
+				return err
+			},
+			expectedCode: codes.OK,
+		},
+		"delete report denied": {
+			token: "alice-token",
+			call: func(ctx context.Context, client reportingpb.ReportingClient) error {
+				_, err := client.DeleteReport(ctx, &reportingpb.DeleteReportRequest{Name: "namespace-cpu"})
+				return err
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+		"stream report results without a token": {
+			call: func(ctx context.Context, client reportingpb.ReportingClient) error {
+				stream, err := client.StreamReportResults(ctx, &reportingpb.StreamReportResultsRequest{Name: "payroll"})
+				if err != nil {
+					return err
+				}
+				_, err = stream.Recv()
+				return err
+			},
+			expectedCode: codes.Unauthenticated,
+		},
+		"stream report results denied": {
+			token: "alice-token",
+			call: func(ctx context.Context, client reportingpb.ReportingClient) error {
+				stream, err := client.StreamReportResults(ctx, &reportingpb.StreamReportResultsRequest{Name: "payroll"})
+				if err != nil {
+					return err
+				}
+				_, err = stream.Recv()
+				return err
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			tokenReviews := &fakeTokenReviews{users: map[string]authenticationv1.UserInfo{
+				"alice-token": {Username: "alice"},
+				"bob-token":   {Username: "bob"},
+			}}
+			accessReviews := &fakeAccessReviews{allowed: map[string][]string{
+				"alice": {"create reports.metering.openshift.io in namespace default"},
+			}}
+			auth := newAPIAuth(tokenReviews, accessReviews, "default", time.Minute, clock.NewFakeClock(time.Now()))
+			client, stop := startTestGRPCServer(t, nil, meteringListers{}, auth, false)
+			defer stop()
+
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+tt.token)
+			}
+			err := tt.call(ctx, client)
+			assert.Equal(t, tt.expectedCode, status.Code(err), "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestGRPCStreamReportResults(t *testing.T) {
+	const namespace = "default"
+	const reportName = "test-report"
+	start := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	columns := []v1alpha1.ReportGenerationQueryColumn{
+		{Name: "namespace", Type: "string"},
+		{Name: "period_end", Type: "timestamp"},
+		{Name: "cost", Type: "double"},
+	}
+	tableColumns := []hive.Column{
+		{Name: "namespace", Type: "string"},
+		{Name: "period_end", Type: "timestamp"},
+		{Name: "cost", Type: "double"},
+	}
+	results := []presto.Row{
+		{"namespace": "team-a", "period_end": end, "cost": 75.0},
+		{"namespace": "team-b", "period_end": end, "cost": 50.5},
+		{"namespace": "team-c", "period_end": end, "cost": nil},
+	}
+
+	reportIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	reportGenerationQueryIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	prestoTableIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	reportIndexer.Add(newTestReport(reportName, namespace, "test-query", start, end, v1alpha1.ReportStatus{Phase: v1alpha1.ReportPhaseFinished}))
+	reportIndexer.Add(newTestReport("running", namespace, "test-query", start, end, v1alpha1.ReportStatus{Phase: v1alpha1.ReportPhaseStarted}))
+	reportGenerationQueryIndexer.Add(newTestReportGenQuery("test-query", namespace, columns))
+	prestoTableIndexer.Add(newTestPrestoTable(reportName, namespace, tableColumns))
+	listers := meteringListers{
+		reports:                 listers.NewReportLister(reportIndexer).Reports(namespace),
+		reportGenerationQueries: listers.NewReportGenerationQueryLister(reportGenerationQueryIndexer).ReportGenerationQueries(namespace),
+		prestoTables:            listers.NewPrestoTableLister(prestoTableIndexer).PrestoTables(namespace),
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	queryer := mockpresto.NewMockExecQueryer(ctrl)
+	prestoColumns, err := hiveColumnsToPrestoColumns(tableColumns)
+	require.NoError(t, err)
+	queryer.EXPECT().Query(presto.GenerateGetRowsWhereSQL(reportTableName(reportName), prestoColumns, "")).Return(results, nil)
+
+	client, stop := startTestGRPCServer(t, queryer, listers, nil, true)
+	defer stop()
+	ctx := context.Background()
+
+	stream, err := client.StreamReportResults(ctx, &reportingpb.StreamReportResultsRequest{Name: reportName, BatchSize: 2})
+	require.NoError(t, err)
+	var batches []*reportingpb.ReportResultsBatch
+	for {
+		batch, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		batches = append(batches, batch)
+	}
+	require.Len(t, batches, 2)
+	assert.Equal(t, []*reportingpb.Column{
+		{Name: "namespace", Type: "string"},
+		{Name: "period_end", Type: "timestamp"},
+		{Name: "cost", Type: "double"},
+	}, batches[0].Columns)
+	assert.Empty(t, batches[1].Columns, "expected columns only in the first batch")
+	require.Len(t, batches[0].Rows, 2)
+	require.Len(t, batches[1].Rows, 1)
+	assert.Equal(t, "team-a", batches[0].Rows[0].Values[0].GetStringValue())
+	assert.Equal(t, end, timeFromProto(batches[0].Rows[0].Values[1].GetTimestampValue()))
+	assert.Equal(t, 50.5, batches[0].Rows[1].Values[2].GetDoubleValue())
+	assert.Nil(t, batches[1].Rows[0].Values[2].GetValue(), "expected no value for nulls")
+
+	stream, err = client.StreamReportResults(ctx, &reportingpb.StreamReportResultsRequest{Name: "running"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	stream, err = client.StreamReportResults(ctx, &reportingpb.StreamReportResultsRequest{Name: reportName, Columns: []string{"unknown"}})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCStreamReportResultsRowLevelSecurity(t *testing.T) {
+	srv := &grpcServer{logger: testLogger, rowLevelSecurity: true}
+	err := srv.StreamReportResults(&reportingpb.StreamReportResultsRequest{Name: "test-report"}, nil)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -1,11 +1,41 @@
 package operator
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+	"k8s.io/apimachinery/pkg/util/clock"
 
 	"github.com/operator-framework/operator-metering/pkg/hive"
 	"github.com/operator-framework/operator-metering/pkg/presto"
-	"github.com/sirupsen/logrus"
+)
+
+const (
+	// healthCheckCacheTTL is how long the result of a dependency check is
+	// reused, so that probes don't query Presto, Hive and Prometheus on
+	// every request.
+	healthCheckCacheTTL = 30 * time.Second
+	// healthCheckTimeout is how long a dependency check can take before
+	// it's considered failed.
+	healthCheckTimeout = 20 * time.Second
+
+	healthCheckInitialized  = "initialized"
+	healthCheckLeader       = "leader"
+	healthCheckPresto       = "presto"
+	healthCheckPrestoWrite  = "presto-write"
+	healthCheckHive         = "hive"
+	healthCheckPrometheus   = "prometheus"
+	healthCheckStatusOK     = "ok"
+	healthCheckExcludeParam = "exclude"
+
+	// healthCheckTableName is the table written to by the presto-write
+	// check.
+	healthCheckTableName = "operator_health_check"
 )
 
 type statusResponse struct {
@@ -13,92 +43,257 @@ type statusResponse struct {
 	Details interface{} `json:"details"`
 }
 
-// healthinessHandler is the readiness check for the metering operator. If this
-// no requests will be sent to this pod, and rolling updates will not proceed
-// until the checks succeed.
-func (op *Reporting) readinessHandler(w http.ResponseWriter, r *http.Request) {
-	logger := newRequestLogger(op.logger, r, op.rand)
-	if !op.isInitialized() {
-		logger.Debugf("not ready: operator is not yet initialized")
-		writeResponseAsJSON(logger, w, http.StatusInternalServerError,
-			statusResponse{
-				Status:  "not ready",
-				Details: "not initialized",
-			})
-		return
+// healthCheck checks that a dependency of the operator is reachable.
+type healthCheck struct {
+	name  string
+	check func(ctx context.Context, logger logrus.FieldLogger) error
+}
+
+// healthCheckResult is the result of running a healthCheck.
+type healthCheckResult struct {
+	Name      string    `json:"name"`
+	Healthy   bool      `json:"healthy"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// healthCheckCache runs healthChecks and caches their results for ttl.
+type healthCheckCache struct {
+	clock   clock.Clock
+	ttl     time.Duration
+	timeout time.Duration
+
+	// ensures only a single instance of each check is running at one time
+	group singleflight.Group
+
+	mu      sync.Mutex
+	results map[string]healthCheckResult
+}
+
+func newHealthCheckCache(clock clock.Clock, ttl, timeout time.Duration) *healthCheckCache {
+	return &healthCheckCache{
+		clock:   clock,
+		ttl:     ttl,
+		timeout: timeout,
+		results: make(map[string]healthCheckResult),
+	}
+}
+
+// run runs the checks in parallel and returns their results in the same
+// order. Results newer than the cache's ttl are returned without running
+// the check again.
+func (c *healthCheckCache) run(logger logrus.FieldLogger, checks []healthCheck) []healthCheckResult {
+	results := make([]healthCheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check healthCheck) {
+			defer wg.Done()
+			results[i] = c.runCheck(logger, check)
+		}(i, check)
+	}
+	wg.Wait()
+	return results
+}
+
+func (c *healthCheckCache) runCheck(logger logrus.FieldLogger, check healthCheck) healthCheckResult {
+	c.mu.Lock()
+	result, ok := c.results[check.name]
+	c.mu.Unlock()
+	if ok && c.clock.Since(result.CheckedAt) < c.ttl {
+		return result
+	}
+
+	v, _, _ := c.group.Do(check.name, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		defer cancel()
+		// not every dependency's client takes a context, so the check is
+		// run in its own goroutine to stop waiting for it after the
+		// timeout.
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- check.check(ctx, logger)
+		}()
+		var err error
+		select {
+		case err = <-errCh:
+		case <-ctx.Done():
+			err = fmt.Errorf("timed out after %s", c.timeout)
+		}
+
+		result := healthCheckResult{
+			Name:      check.name,
+			Healthy:   err == nil,
+			CheckedAt: c.clock.Now(),
+		}
+		if err != nil {
+			result.Error = err.Error()
+			logger.WithError(err).Warnf("health check %s failed", check.name)
+		}
+		c.mu.Lock()
+		c.results[check.name] = result
+		c.mu.Unlock()
+		return result, nil
+	})
+	return v.(healthCheckResult)
+}
+
+// readinessChecks are the dependencies the operator needs to serve its API,
+// import metrics and generate reports.
+func (op *Reporting) readinessChecks() []healthCheck {
+	checks := []healthCheck{{name: healthCheckPresto, check: op.testReadFromPresto}}
+	// nothing is imported and no tables are created in read-only mode, so
+	// Hive and Prometheus are never used.
+	if op.cfg.ReadOnly {
+		return checks
 	}
-	if !op.testReadFromPrestoSingleFlight(logger) {
-		writeResponseAsJSON(logger, w, http.StatusInternalServerError,
-			statusResponse{
-				Status:  "not ready",
-				Details: "cannot read from PrestoDB",
-			})
-		return
+	checks = append(checks, healthCheck{name: healthCheckHive, check: op.testHiveMetastore})
+	if !op.cfg.DisablePromsum && len(op.prometheusClusters) != 0 {
+		checks = append(checks, healthCheck{name: healthCheckPrometheus, check: op.testPrometheus})
 	}
+	return checks
+}
 
-	writeResponseAsJSON(logger, w, http.StatusOK, statusResponse{Status: "ok"})
+// livenessChecks are the checks which restart the operator when they fail.
+// Restarting doesn't help when Hive or Prometheus are unavailable, so only
+// the operator's own use of Presto is checked.
+func (op *Reporting) livenessChecks() []healthCheck {
+	// In read-only mode the operator never writes to Presto, so only reads
+	// are checked.
+	if op.cfg.ReadOnly {
+		return []healthCheck{{name: healthCheckPresto, check: op.testReadFromPresto}}
+	}
+	return []healthCheck{{name: healthCheckPrestoWrite, check: func(_ context.Context, logger logrus.FieldLogger) error {
+		return op.testWriteToPresto(logger)
+	}}}
+}
+
+// readyzHandler is the readiness check for the metering operator. If this
+// fails no requests will be sent to this pod, and rolling updates will not
+// proceed until the checks succeed. The response lists the result of each
+// check, and checks can be skipped with the exclude query parameter.
+func (op *Reporting) readyzHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(op.logger, r, op.rand)
+	initialized := healthCheckResult{
+		Name:      healthCheckInitialized,
+		Healthy:   op.isInitialized(),
+		CheckedAt: op.clock.Now(),
+	}
+	if !initialized.Healthy {
+		initialized.Error = "operator is not yet initialized"
+	}
+	results := []healthCheckResult{initialized}
+	// report runs are only tracked in memory by the replica which started
+	// them, so standbys aren't ready, to keep the API on a single replica.
+	// Read-only replicas don't take part in leader election.
+	if !op.cfg.ReadOnly && !healthCheckExcluded(healthCheckLeader, r) {
+		leader := healthCheckResult{
+			Name:      healthCheckLeader,
+			Healthy:   op.isLeader(),
+			CheckedAt: op.clock.Now(),
+		}
+		if !leader.Healthy {
+			leader.Error = "operator is not the leader"
+		}
+		results = append(results, leader)
+	}
+	results = append(results, op.healthChecks.run(logger, excludeHealthChecks(op.readinessChecks(), r))...)
+	writeHealthCheckResults(logger, w, "not ready", results)
 }
 
-// healthinessHandler is the health check for the metering operator. If this
+// healthzHandler is the health check for the metering operator. If this
 // fails, the process will be restarted.
-func (op *Reporting) healthinessHandler(w http.ResponseWriter, r *http.Request) {
+func (op *Reporting) healthzHandler(w http.ResponseWriter, r *http.Request) {
 	logger := newRequestLogger(op.logger, r, op.rand)
-	if !op.testWriteToPrestoSingleFlight(logger) {
-		writeResponseAsJSON(logger, w, http.StatusInternalServerError,
-			statusResponse{
-				Status:  "not healthy",
-				Details: "cannot write to PrestoDB",
-			})
-		return
-	}
-	writeResponseAsJSON(logger, w, http.StatusOK, statusResponse{Status: "ok"})
-}
-
-func (op *Reporting) testWriteToPrestoSingleFlight(logger logrus.FieldLogger) bool {
-	const key = "presto-write"
-	v, _, _ := op.healthCheckSingleFlight.Do(key, func() (interface{}, error) {
-		defer op.healthCheckSingleFlight.Forget(key)
-		healthy := op.testWriteToPresto(logger)
-		return healthy, nil
-	})
-	healthy := v.(bool)
-	return healthy
+	results := op.healthChecks.run(logger, excludeHealthChecks(op.livenessChecks(), r))
+	writeHealthCheckResults(logger, w, "not healthy", results)
 }
 
-func (op *Reporting) testReadFromPrestoSingleFlight(logger logrus.FieldLogger) bool {
-	const key = "presto-read"
-	v, _, _ := op.healthCheckSingleFlight.Do(key, func() (interface{}, error) {
-		defer op.healthCheckSingleFlight.Forget(key)
-		healthy := op.testReadFromPresto(logger)
-		return healthy, nil
-	})
-	healthy := v.(bool)
-	return healthy
+// excludeHealthChecks removes the checks named by the exclude query
+// parameters of r.
+func excludeHealthChecks(checks []healthCheck, r *http.Request) []healthCheck {
+	if len(r.URL.Query()[healthCheckExcludeParam]) == 0 {
+		return checks
+	}
+	var filtered []healthCheck
+	for _, check := range checks {
+		if !healthCheckExcluded(check.name, r) {
+			filtered = append(filtered, check)
+		}
+	}
+	return filtered
 }
 
-func (op *Reporting) testReadFromPresto(logger logrus.FieldLogger) bool {
+// healthCheckExcluded returns true if the check is named by an exclude
+// query parameter of r.
+func healthCheckExcluded(name string, r *http.Request) bool {
+	for _, excluded := range r.URL.Query()[healthCheckExcludeParam] {
+		if excluded == name {
+			return true
+		}
+	}
+	return false
+}
+
+// writeHealthCheckResults responds with a 200 if every check is healthy,
+// and a 503 with failedStatus otherwise.
+func writeHealthCheckResults(logger logrus.FieldLogger, w http.ResponseWriter, failedStatus string, results []healthCheckResult) {
+	for _, result := range results {
+		if !result.Healthy {
+			logger.Debugf("%s: %s check failed: %s", failedStatus, result.Name, result.Error)
+			writeResponseAsJSON(logger, w, http.StatusServiceUnavailable, statusResponse{Status: failedStatus, Details: results})
+			return
+		}
+	}
+	writeResponseAsJSON(logger, w, http.StatusOK, statusResponse{Status: healthCheckStatusOK, Details: results})
+}
+
+func (op *Reporting) testReadFromPresto(_ context.Context, logger logrus.FieldLogger) error {
 	_, err := presto.ExecuteSelect(op.prestoConn, "SELECT * FROM system.runtime.nodes")
 	if err != nil {
 		logger.WithError(err).Debugf("cannot query Presto system.runtime.nodes table")
-		return false
+		return fmt.Errorf("cannot read from Presto: %v", err)
 	}
-	return true
+	return nil
 }
 
-func (op *Reporting) testWriteToPresto(logger logrus.FieldLogger) bool {
+func (op *Reporting) testWriteToPresto(logger logrus.FieldLogger) error {
 	logger = logger.WithField("component", "testWriteToPresto")
-	const tableName = "operator_health_check"
+	tableName := healthCheckTableName
 	err := op.createTableForStorageNoCR(logger, nil, tableName, []hive.Column{{Name: "check_time", Type: "TIMESTAMP"}})
 	if err != nil {
 		logger.WithError(err).Errorf("cannot create Presto table %s", tableName)
-		return false
+		return fmt.Errorf("cannot create Presto table %s: %v", tableName, err)
 	}
 	// Hive does not support timezones, and now() returns a
 	// TIMESTAMP WITH TIMEZONE so we cast the return of now() to a TIMESTAMP.
 	err = presto.InsertInto(op.prestoQueryer, tableName, "VALUES (cast(now() AS TIMESTAMP))")
 	if err != nil {
 		logger.WithError(err).Errorf("cannot insert into Presto table %s", tableName)
-		return false
+		return fmt.Errorf("cannot insert into Presto table %s: %v", tableName, err)
+	}
+	return nil
+}
+
+// testHiveMetastore lists the databases in the Hive metastore through the
+// Hive server the operator creates tables with.
+func (op *Reporting) testHiveMetastore(_ context.Context, logger logrus.FieldLogger) error {
+	rows, err := op.hiveQueryer.Query("SHOW DATABASES")
+	if err != nil {
+		logger.WithError(err).Debugf("cannot list Hive databases")
+		return fmt.Errorf("cannot list Hive databases: %v", err)
+	}
+	return rows.Close()
+}
+
+// testPrometheus runs an instant query against the local cluster's
+// Prometheus.
+func (op *Reporting) testPrometheus(ctx context.Context, logger logrus.FieldLogger) error {
+	// the local cluster's Prometheus is always the first
+	_, err := op.prometheusClusters[0].promConn.Query(ctx, "vector(1)", op.clock.Now())
+	if err != nil {
+		logger.WithError(err).Debugf("cannot query Prometheus")
+		return fmt.Errorf("cannot query Prometheus: %v", err)
 	}
-	return true
+	return nil
 }
@@ -0,0 +1,175 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestHealthCheckCache(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC))
+	cache := newHealthCheckCache(fakeClock, time.Minute, time.Second)
+
+	var prestoRuns, hiveRuns int
+	hiveErr := errors.New("connection refused")
+	checks := []healthCheck{
+		{name: healthCheckPresto, check: func(context.Context, logrus.FieldLogger) error {
+			prestoRuns++
+			return nil
+		}},
+		{name: healthCheckHive, check: func(context.Context, logrus.FieldLogger) error {
+			hiveRuns++
+			return hiveErr
+		}},
+	}
+
+	results := cache.run(testLogger, checks)
+	require.Len(t, results, 2)
+	assert.Equal(t, healthCheckResult{Name: healthCheckPresto, Healthy: true, CheckedAt: fakeClock.Now()}, results[0])
+	assert.Equal(t, healthCheckResult{Name: healthCheckHive, Error: hiveErr.Error(), CheckedAt: fakeClock.Now()}, results[1])
+
+	fakeClock.Step(30 * time.Second)
+	cached := cache.run(testLogger, checks)
+	assert.Equal(t, results, cached, "results within the ttl should be cached")
+	assert.Equal(t, 1, prestoRuns)
+	assert.Equal(t, 1, hiveRuns)
+
+	fakeClock.Step(30 * time.Second)
+	results = cache.run(testLogger, checks)
+	assert.Equal(t, fakeClock.Now(), results[0].CheckedAt)
+	assert.Equal(t, 2, prestoRuns)
+	assert.Equal(t, 2, hiveRuns)
+}
+
+func TestHealthCheckCacheTimeout(t *testing.T) {
+	cache := newHealthCheckCache(clock.RealClock{}, time.Minute, 10*time.Millisecond)
+	block := make(chan struct{})
+	defer close(block)
+
+	results := cache.run(testLogger, []healthCheck{{name: healthCheckPrometheus, check: func(context.Context, logrus.FieldLogger) error {
+		<-block
+		return nil
+	}}})
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Healthy)
+	assert.Equal(t, "timed out after 10ms", results[0].Error)
+}
+
+func TestHealthCheckHandlers(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC))
+	checks := []healthCheck{
+		{name: healthCheckPresto, check: func(context.Context, logrus.FieldLogger) error { return nil }},
+		{name: healthCheckPrometheus, check: func(context.Context, logrus.FieldLogger) error { return errors.New("cannot query Prometheus") }},
+	}
+
+	tests := map[string]struct {
+		url                string
+		expectedCode       int
+		expectedStatus     string
+		expectedCheckNames []string
+	}{
+		"failing check": {
+			url:                "/readyz",
+			expectedCode:       http.StatusServiceUnavailable,
+			expectedStatus:     "not ready",
+			expectedCheckNames: []string{healthCheckPresto, healthCheckPrometheus},
+		},
+		"failing check excluded": {
+			url:                "/readyz?exclude=prometheus",
+			expectedCode:       http.StatusOK,
+			expectedStatus:     healthCheckStatusOK,
+			expectedCheckNames: []string{healthCheckPresto},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			cache := newHealthCheckCache(fakeClock, time.Minute, time.Second)
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeHealthCheckResults(testLogger, w, "not ready", cache.run(testLogger, excludeHealthChecks(checks, r)))
+			})
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, test.url, nil))
+			assert.Equal(t, test.expectedCode, w.Code)
+
+			var resp struct {
+				Status  string              `json:"status"`
+				Details []healthCheckResult `json:"details"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, test.expectedStatus, resp.Status)
+			var names []string
+			for _, result := range resp.Details {
+				names = append(names, result.Name)
+			}
+			assert.Equal(t, test.expectedCheckNames, names)
+		})
+	}
+}
+
+func TestReadyzHandlerLeader(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC))
+
+	tests := map[string]struct {
+		readOnly           bool
+		leader             bool
+		expectedCode       int
+		expectedCheckNames []string
+	}{
+		"leader": {
+			leader:             true,
+			expectedCode:       http.StatusOK,
+			expectedCheckNames: []string{healthCheckInitialized, healthCheckLeader},
+		},
+		"standby": {
+			leader:             false,
+			expectedCode:       http.StatusServiceUnavailable,
+			expectedCheckNames: []string{healthCheckInitialized, healthCheckLeader},
+		},
+		"read-only": {
+			readOnly:           true,
+			expectedCode:       http.StatusOK,
+			expectedCheckNames: []string{healthCheckInitialized},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			op := &Reporting{
+				cfg:          Config{ReadOnly: test.readOnly},
+				clock:        fakeClock,
+				rand:         rand.New(rand.NewSource(0)),
+				logger:       testLogger,
+				healthChecks: newHealthCheckCache(fakeClock, time.Minute, time.Second),
+				initialized:  true,
+				leader:       test.leader,
+			}
+			w := httptest.NewRecorder()
+			// the dependency checks need connections to Presto and Hive
+			op.readyzHandler(w, httptest.NewRequest(http.MethodGet, "/readyz?exclude=presto&exclude=hive", nil))
+			assert.Equal(t, test.expectedCode, w.Code)
+
+			var resp struct {
+				Details []healthCheckResult `json:"details"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			var names []string
+			for _, result := range resp.Details {
+				names = append(names, result.Name)
+			}
+			assert.Equal(t, test.expectedCheckNames, names)
+		})
+	}
+}
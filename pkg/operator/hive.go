@@ -4,16 +4,26 @@ import (
 	"fmt"
 	"net/url"
 	"path"
+	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/db"
 	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/presto"
 )
 
 func (op *Reporting) createTableForStorage(logger log.FieldLogger, obj runtime.Object, kind, name string, storage *cbTypes.StorageLocationRef, tableName string, columns []hive.Column) error {
+	return op.createPartitionedTableForStorage(logger, obj, kind, name, storage, tableName, columns, nil)
+}
+
+// createPartitionedTableForStorage is like createTableForStorage, but the
+// table is partitioned by the partitions columns.
+func (op *Reporting) createPartitionedTableForStorage(logger log.FieldLogger, obj runtime.Object, kind, name string, storage *cbTypes.StorageLocationRef, tableName string, columns, partitions []hive.Column) error {
 	tableProperties, err := op.getHiveTableProperties(logger, storage, kind)
 	if err != nil {
 		return fmt.Errorf("storage incorrectly configured for %s: %s", kind, name)
@@ -21,6 +31,7 @@ func (op *Reporting) createTableForStorage(logger log.FieldLogger, obj runtime.O
 	tableParams := hive.TableParameters{
 		Name:         tableName,
 		Columns:      columns,
+		Partitions:   partitions,
 		IgnoreExists: true,
 	}
 	return op.createTableWith(logger, obj, kind, name, tableParams, *tableProperties)
@@ -51,6 +62,67 @@ func (op *Reporting) createTableWith(logger log.FieldLogger, obj runtime.Object,
 	return op.createTableAndCR(logger, obj, kind, name, params, newTableProperties)
 }
 
+// addMissingTableColumns adds the columns the existing table doesn't have.
+// Tables created with IgnoreExists keep the columns they were first created
+// with, so when columns are added to a ReportGenerationQuery, such as after
+// an upgrade, the tables of the ScheduledReports using it are missing them.
+// Rows inserted before the columns were added have NULL values for them.
+func addMissingTableColumns(logger log.FieldLogger, prestoQueryer presto.Queryer, hiveQueryer db.Queryer, tableName string, columns []hive.Column) error {
+	rows, err := prestoQueryer.Query(fmt.Sprintf("SHOW COLUMNS FROM %s", tableName))
+	if err != nil {
+		return fmt.Errorf("couldn't get the columns of table %s: %v", tableName, err)
+	}
+	existing := make(map[string]struct{}, len(rows))
+	for _, row := range rows {
+		if name, ok := row["Column"].(string); ok {
+			existing[strings.ToLower(name)] = struct{}{}
+		}
+	}
+	var missing []hive.Column
+	var missingNames []string
+	for _, column := range columns {
+		if _, exists := existing[strings.ToLower(column.Name)]; !exists {
+			missing = append(missing, column)
+			missingNames = append(missingNames, column.Name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	logger.Infof("adding columns %s to table %s", strings.Join(missingNames, ", "), tableName)
+	err = hive.ExecuteAddColumns(hiveQueryer, tableName, missing)
+	if err != nil {
+		return fmt.Errorf("couldn't add columns %s to table %s: %v", strings.Join(missingNames, ", "), tableName, err)
+	}
+	return nil
+}
+
+// recreateTableWith drops the table, and creates it again. Hive deletes the
+// objects of tables in object stores one at a time, and deleted objects may
+// still be listed for a while, so tables in object stores are recreated at a
+// new location, which the dropped table's objects are never listed in.
+func (op *Reporting) recreateTableWith(logger log.FieldLogger, obj runtime.Object, kind, name string, params hive.TableParameters, properties hive.TableProperties) error {
+	logger.Debugf("dropping table %s", params.Name)
+	err := hive.ExecuteDropTable(op.hiveQueryer, params.Name, true)
+	if err != nil {
+		return err
+	}
+	newTableProperties, err := addTableNameToLocation(properties, recreatedTableLocationName(params.Name, properties, op.clock.Now()))
+	if err != nil {
+		return err
+	}
+	return op.createTableAndCR(logger, obj, kind, name, params, newTableProperties)
+}
+
+// recreatedTableLocationName returns the name of the directory within the
+// storage location a table recreated at now is stored in.
+func recreatedTableLocationName(tableName string, properties hive.TableProperties, now time.Time) string {
+	if !hive.IsObjectStoreLocation(properties.Location) {
+		return tableName
+	}
+	return fmt.Sprintf("%s-%d", tableName, now.UnixNano())
+}
+
 func (op *Reporting) createTableAndCR(logger log.FieldLogger, obj runtime.Object, kind, name string, params hive.TableParameters, properties hive.TableProperties) error {
 	err := op.createTable(logger, params, properties)
 	if err != nil {
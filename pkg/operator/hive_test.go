@@ -0,0 +1,104 @@
+package operator
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	mockpresto "github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+func TestRecreatedTableLocationName(t *testing.T) {
+	now := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		location string
+		expected string
+	}{
+		"hdfs": {
+			location: "hdfs://hdfs-namenode-proxy:8020/operator_metering/storage/",
+			expected: "report_namespace_cpu_request",
+		},
+		"default location": {
+			expected: "report_namespace_cpu_request",
+		},
+		"s3a": {
+			location: "s3a://operator-metering/storage/",
+			expected: "report_namespace_cpu_request-1533081600000000000",
+		},
+		"gcs": {
+			location: "gs://operator-metering/storage/",
+			expected: "report_namespace_cpu_request-1533081600000000000",
+		},
+	}
+	for name, test := range tests {
+		props := hive.TableProperties{Location: test.location}
+		assert.Equal(t, test.expected, recreatedTableLocationName("report_namespace_cpu_request", props, now), name)
+	}
+}
+
+// recordingHiveQueryer records the Hive queries it's asked to run.
+type recordingHiveQueryer struct {
+	queries []string
+}
+
+func (q *recordingHiveQueryer) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	q.queries = append(q.queries, query)
+	return nil, nil
+}
+
+func TestAddMissingTableColumns(t *testing.T) {
+	columns := []hive.Column{
+		{Name: "period_start", Type: "timestamp"},
+		{Name: "period_end", Type: "timestamp"},
+		{Name: "cluster_id", Type: "string"},
+		{Name: "namespace", Type: "string"},
+		{Name: "pod_request_cpu_core_seconds", Type: "double"},
+	}
+	tests := map[string]struct {
+		existingColumns []string
+		expectedQueries []string
+	}{
+		"table created before cluster_id was added": {
+			existingColumns: []string{"period_start", "period_end", "namespace", "pod_request_cpu_core_seconds"},
+			expectedQueries: []string{"ALTER TABLE report_table ADD COLUMNS (`cluster_id` string)"},
+		},
+		"table with every column": {
+			existingColumns: []string{"period_start", "period_end", "cluster_id", "namespace", "pod_request_cpu_core_seconds"},
+		},
+		"table with a removed column": {
+			existingColumns: []string{"period_start", "period_end", "cluster_id", "namespace", "pod", "pod_request_cpu_core_seconds"},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			prestoQueryer := mockpresto.NewMockExecQueryer(ctrl)
+			var rows []presto.Row
+			for _, column := range test.existingColumns {
+				rows = append(rows, presto.Row{"Column": column, "Type": "varchar", "Extra": "", "Comment": ""})
+			}
+			prestoQueryer.EXPECT().Query("SHOW COLUMNS FROM report_table").Return(rows, nil)
+			hiveQueryer := &recordingHiveQueryer{}
+
+			err := addMissingTableColumns(logrus.New(), prestoQueryer, hiveQueryer, "report_table", columns)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedQueries, hiveQueryer.queries)
+		})
+	}
+}
+
+func TestFormatInsertColumnsQuery(t *testing.T) {
+	// after cluster_id is added to an existing table, it's the table's last
+	// column, so results are inserted by name.
+	query := presto.FormatInsertColumnsQuery("report_table", []string{"period_start", "cluster_id", "namespace"}, "SELECT 1")
+	assert.Equal(t, `INSERT INTO report_table ("period_start", "cluster_id", "namespace") SELECT 1`, query)
+}
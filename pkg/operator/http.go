@@ -1,6 +1,8 @@
 package operator
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/csv"
 	"encoding/json"
@@ -11,6 +13,7 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"text/tabwriter"
@@ -28,29 +31,82 @@ import (
 	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
 	"github.com/operator-framework/operator-metering/pkg/presto"
 	"github.com/operator-framework/operator-metering/pkg/util/orderedmap"
+	"github.com/operator-framework/operator-metering/pkg/util/parquet"
+	"github.com/operator-framework/operator-metering/pkg/util/xlsx"
 )
 
 var ErrReportIsRunning = errors.New("the report is still running")
 
 const (
-	APIV1ReportsGetEndpoint = "/api/v1/reports/get"
-	APIV2Reports            = "/api/v2/reports"
+	APIV1ReportsGetEndpoint             = "/api/v1/reports/get"
+	APIV1ScheduledReportsGetEndpoint    = "/api/v1/scheduledreports/get"
+	APIV1ReportsStreamEndpoint          = "/api/v1/reports/stream"
+	APIV1ScheduledReportsStreamEndpoint = "/api/v1/scheduledreports/stream"
+	APIV1ReportsRenderEndpoint          = "/api/v1/reports/render"
+	APIV1ScheduledReportsRenderEndpoint = "/api/v1/scheduledreports/render"
+	APIV2Reports                        = "/api/v2/reports"
+
+	// nextCursorHeader is the response header containing the cursor for
+	// the next page of report results.
+	nextCursorHeader = "X-Next-Cursor"
 )
 
 type meteringListers struct {
 	reports                 listers.ReportNamespaceLister
 	scheduledReports        listers.ScheduledReportNamespaceLister
 	reportGenerationQueries listers.ReportGenerationQueryNamespaceLister
+	reportDataSources       listers.ReportDataSourceNamespaceLister
+	reportPrometheusQueries listers.ReportPrometheusQueryNamespaceLister
 	prestoTables            listers.PrestoTableNamespaceLister
+	reportTemplates         listers.ReportTemplateNamespaceLister
+
+	// tenantNamespace is set for the listers of a tenant namespace, whose
+	// Report and ScheduledReport tables are in the tenant's schema.
+	tenantNamespace string
+	// tenantListers returns the listers of a namespace. It's nil unless
+	// tenant namespaces are enabled.
+	tenantListers func(namespace string) meteringListers
 }
 
 type server struct {
 	logger log.FieldLogger
 
-	rand          *rand.Rand
-	queryer       presto.ExecQueryer
-	collectorFunc prometheusImporterFunc
-	listers       meteringListers
+	rand    *rand.Rand
+	queryer presto.ExecQueryer
+	// importerQueryer is used by the endpoints which store and fetch
+	// Prometheus metrics on behalf of the Prometheus importer.
+	importerQueryer presto.ExecQueryer
+	collectorFunc   prometheusImporterFunc
+	// dataStore exports and imports the tables of ReportDataSources.
+	dataStore dataStoreTransferer
+	listers   meteringListers
+	// reportRuns are the ad-hoc report runs started through the API.
+	reportRuns *reportRuns
+	// queryConfig limits the queries run through the ad-hoc query
+	// endpoint.
+	queryConfig QueryConfig
+	// monetaryRounding rounds the monetary values of report results.
+	monetaryRounding MonetaryRoundingConfig
+	// importerTelemetry provides the recommendations returned by the
+	// Prometheus importer recommendations endpoint.
+	importerTelemetry *importerTelemetry
+	// usageAnomalies provides the anomalies returned by the usage
+	// anomalies endpoint. It's nil if anomaly detection is disabled.
+	usageAnomalies *usageAnomalies
+	// faultInjector is configured by the fault injection debug endpoint,
+	// which is only registered when it's set.
+	faultInjector *prestostore.FaultInjector
+	// readOnly disables the endpoints which write to Presto or run
+	// reports.
+	readOnly bool
+	// auth, if set, authenticates and authorizes requests to the API
+	// routes.
+	auth *apiAuth
+	// audit, if set, logs an audit event for each request to the API.
+	audit *auditLogger
+	// queryCache caches the results of report results and ad-hoc query
+	// requests. It's nil if caching is disabled.
+	queryCache *queryCache
 }
 
 type requestLogger struct {
@@ -61,7 +117,28 @@ func (l *requestLogger) Print(v ...interface{}) {
 	l.FieldLogger.Info(v...)
 }
 
-func newRouter(logger log.FieldLogger, queryer presto.ExecQueryer, rand *rand.Rand, collectorFunc prometheusImporterFunc, listers meteringListers) chi.Router {
+// routerConfig is the configuration of the API router. The fields are
+// documented on the server fields they set; the optional ones can be left
+// unset.
+type routerConfig struct {
+	rand               *rand.Rand
+	queryer            presto.ExecQueryer
+	importerQueryer    presto.ExecQueryer
+	collectorFunc      prometheusImporterFunc
+	reportRunQueryFunc reportRunQueryFunc
+	dataStore          dataStoreTransferer
+	listers            meteringListers
+	queryConfig        QueryConfig
+	monetaryRounding   MonetaryRoundingConfig
+	importerTelemetry  *importerTelemetry
+	usageAnomalies     *usageAnomalies
+	faultInjector      *prestostore.FaultInjector
+	readOnly           bool
+	auth               *apiAuth
+	audit              *auditLogger
+}
+
+func newRouter(logger log.FieldLogger, cfg routerConfig) chi.Router {
 	router := chi.NewRouter()
 
 	logger = logger.WithField("component", "api")
@@ -69,28 +146,59 @@ func newRouter(logger log.FieldLogger, queryer presto.ExecQueryer, rand *rand.Ra
 	router.Use(requestLogger)
 
 	srv := &server{
-		logger:        logger,
-		rand:          rand,
-		queryer:       queryer,
-		collectorFunc: collectorFunc,
-		listers:       listers,
+		logger:            logger,
+		rand:              cfg.rand,
+		queryer:           cfg.queryer,
+		importerQueryer:   cfg.importerQueryer,
+		collectorFunc:     cfg.collectorFunc,
+		dataStore:         cfg.dataStore,
+		listers:           cfg.listers,
+		reportRuns:        newReportRuns(cfg.queryer, cfg.reportRunQueryFunc),
+		queryConfig:       cfg.queryConfig,
+		monetaryRounding:  cfg.monetaryRounding,
+		importerTelemetry: cfg.importerTelemetry,
+		usageAnomalies:    cfg.usageAnomalies,
+		faultInjector:     cfg.faultInjector,
+		readOnly:          cfg.readOnly,
+		auth:              cfg.auth,
+		audit:             cfg.audit,
+		queryCache:        newQueryCache(cfg.queryConfig.Cache, time.Now),
 	}
 
-	router.HandleFunc(APIV1ReportsGetEndpoint, srv.getReportHandler)
-	router.HandleFunc("/api/v2/reports/{name}/full", srv.getReportV2FullHandler)
-	router.HandleFunc("/api/v2/reports/{name}/table", srv.getReportV2TableHandler)
+	for _, route := range apiRoutes {
+		if route.enabled != nil && !route.enabled(srv) {
+			continue
+		}
+		router.MethodFunc(route.method, route.path, route.handlerFunc(srv))
+	}
+	router.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		logger := newRequestLogger(srv.logger, r, srv.rand)
+		writeErrorResponse(logger, w, r, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+	})
 	// The following two routes handle returning a 400 when the name parameter is missing, rather than having a 404 returned.
 	router.HandleFunc("/api/v2/reports//full", srv.getReportV2NameMissingHandler)
 	router.HandleFunc("/api/v2/reports//table", srv.getReportV2NameMissingHandler)
-	router.HandleFunc("/api/v1/scheduledreports/get", srv.getScheduledReportHandler)
-	router.HandleFunc("/api/v1/reports/run", srv.runReportHandler)
-	router.HandleFunc("/api/v1/datasources/prometheus/collect", srv.collectPromsumDataHandler)
-	router.HandleFunc("/api/v1/datasources/prometheus/store/{datasourceName}", srv.storePromsumDataHandler)
-	router.HandleFunc("/api/v1/datasources/prometheus/fetch/{datasourceName}", srv.fetchPromsumDataHandler)
+	// Webhooks are called by the API server and aren't part of the API.
+	router.HandleFunc(ConversionWebhookEndpoint, srv.conversionWebhookHandler)
+	router.HandleFunc(DeletionValidationWebhookEndpoint, srv.deletionValidationWebhookHandler)
+	router.HandleFunc(ValidationWebhookEndpoint, srv.validationWebhookHandler)
 
 	return router
 }
 
+// writeHandler wraps handlers which write to Presto or run reports, and
+// rejects their requests when the server is read-only.
+func (srv *server) writeHandler(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if srv.readOnly {
+			logger := newRequestLogger(srv.logger, r, srv.rand)
+			writeErrorResponse(logger, w, r, http.StatusForbidden, "the reporting-operator is running in read-only mode")
+			return
+		}
+		handler(w, r)
+	}
+}
+
 func (srv *server) validateGetReportReq(logger log.FieldLogger, requiredQueryParams []string, w http.ResponseWriter, r *http.Request) bool {
 	if r.Method != "GET" {
 		writeErrorResponse(logger, w, r, http.StatusNotFound, "Not found")
@@ -108,10 +216,10 @@ func (srv *server) validateGetReportReq(logger log.FieldLogger, requiredQueryPar
 	}
 	format := r.Form["format"][0]
 	switch format {
-	case "json", "csv", "tab", "tabular":
+	case "json", "csv", "tab", "tabular", "parquet", "xlsx":
 		return true
 	}
-	writeErrorResponse(logger, w, r, http.StatusBadRequest, "format must be one of: csv, json or tabular")
+	writeErrorResponse(logger, w, r, http.StatusBadRequest, "format must be one of: csv, json, tabular, parquet or xlsx")
 	return false
 }
 
@@ -157,24 +265,48 @@ func (srv *server) getScheduledReportHandler(w http.ResponseWriter, r *http.Requ
 	srv.getScheduledReport(logger, r.Form["name"][0], r.Form["format"][0], w, r)
 }
 
-func (srv *server) runReportHandler(w http.ResponseWriter, r *http.Request) {
+func (srv *server) streamReportHandler(w http.ResponseWriter, r *http.Request) {
 	logger := newRequestLogger(srv.logger, r, srv.rand)
-	if r.Method != "GET" {
-		writeErrorResponse(logger, w, r, http.StatusNotFound, "Not found")
+	if !srv.validateStreamReportReq(logger, w, r) {
 		return
 	}
-	err := r.ParseForm()
-	if err != nil {
-		writeErrorResponse(logger, w, r, http.StatusBadRequest, "couldn't parse URL query params: %v", err)
+	tableName, reportColumns, prestoColumns, ok := srv.getReportTable(logger, r.Form["name"][0], w, r)
+	if !ok {
 		return
 	}
-	vals := r.Form
-	err = checkForFields([]string{"query", "start", "end"}, vals)
-	if err != nil {
-		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+	reportColumns, prestoColumns, whereSQL, ok := srv.selectReportResults(logger, tableName, reportColumns, prestoColumns, w, r)
+	if !ok {
+		return
+	}
+	srv.streamReportResults(logger, r.Form["format"][0], tableName, reportColumns, prestoColumns, whereSQL, w, r)
+}
+
+func (srv *server) streamScheduledReportHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	if !srv.validateStreamReportReq(logger, w, r) {
 		return
 	}
-	srv.runReport(logger, vals["query"][0], vals["start"][0], vals["end"][0], w)
+	tableName, reportColumns, prestoColumns, ok := srv.getScheduledReportTable(logger, r.Form["name"][0], w, r)
+	if !ok {
+		return
+	}
+	reportColumns, prestoColumns, whereSQL, ok := srv.selectReportResults(logger, tableName, reportColumns, prestoColumns, w, r)
+	if !ok {
+		return
+	}
+	srv.streamReportResults(logger, r.Form["format"][0], tableName, reportColumns, prestoColumns, whereSQL, w, r)
+}
+
+func (srv *server) validateStreamReportReq(logger log.FieldLogger, w http.ResponseWriter, r *http.Request) bool {
+	if !srv.validateGetReportReq(logger, []string{"name", "format"}, w, r) {
+		return false
+	}
+	switch r.Form["format"][0] {
+	case "json", "csv":
+		return true
+	}
+	writeErrorResponse(logger, w, r, http.StatusBadRequest, "format must be one of: csv or json")
+	return false
 }
 
 func checkForFields(fields []string, vals url.Values) error {
@@ -191,161 +323,583 @@ func checkForFields(fields []string, vals url.Values) error {
 }
 
 func (srv *server) getScheduledReport(logger log.FieldLogger, name, format string, w http.ResponseWriter, r *http.Request) {
-	// Get the scheduledReport to make sure it's isn't failed
-	report, err := srv.listers.scheduledReports.Get(name)
-	if err != nil {
-		logger.WithError(err).Errorf("error getting scheduledReport: %v", err)
-		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error getting scheduledReport: %v", err)
+	tableName, reportColumns, prestoColumns, ok := srv.getScheduledReportTable(logger, name, w, r)
+	if !ok {
 		return
 	}
-
-	if r.FormValue("ignore_failed") != "true" {
-		if cond := cbutil.GetScheduledReportCondition(report.Status, api.ScheduledReportFailure); cond != nil && cond.Status == v1.ConditionTrue {
-			logger.Errorf("scheduledReport is is failed state, reason: %s, message: %s", cond.Reason, cond.Message)
-			writeErrorResponse(logger, w, r, http.StatusInternalServerError, "scheduledReport is is failed state, reason: %s, message: %s", cond.Reason, cond.Message)
-			return
-		}
+	tableName, ok = srv.reportVersionTable(logger, dependencyKindScheduledReport, name, tableName, w, r)
+	if !ok {
+		return
 	}
-
-	reportQuery, err := srv.listers.reportGenerationQueries.Get(report.Spec.GenerationQueryName)
-	if err != nil {
-		logger.WithError(err).Errorf("error getting report: %v", err)
-		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error getting report: %v", err)
+	reportColumns, prestoColumns, whereSQL, ok := srv.selectReportResults(logger, tableName, reportColumns, prestoColumns, w, r)
+	if !ok {
+		return
+	}
+	results, ok := srv.getReportResults(logger, tableName, srv.reportResultsDataVersion(r, dependencyKindScheduledReport, name), prestoColumns, whereSQL, w, r)
+	if !ok {
 		return
 	}
 
-	// Get the presto table to get actual columns in table
-	prestoTable, err := srv.listers.prestoTables.Get(prestoTableResourceNameFromKind("scheduledreport", report.Name))
-	if err != nil {
-		logger.WithError(err).Errorf("error getting presto table: %v", err)
-		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error getting presto table: %v", err)
+	if len(results) > 0 && len(prestoColumns) != len(results[0]) {
+		logger.Errorf("report results schema doesn't match expected schema, got %d columns, expected %d", len(results[0]), len(prestoColumns))
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "report results schema doesn't match expected schema")
 		return
 	}
 
-	tableColumns := prestoTable.State.Parameters.Columns
-	queryPrestoColumns, err := generatePrestoColumns(reportQuery)
+	results = roundMonetaryResults(srv.monetaryRounding, reportColumns, results)
+	writeResultsResponse(logger, format, reportColumns, results, w, r)
+}
+
+// getScheduledReportTable returns the name of a scheduledReport's table, and
+// its columns, checking the scheduledReport hasn't failed.
+func (srv *server) getScheduledReportTable(logger log.FieldLogger, name string, w http.ResponseWriter, r *http.Request) (string, []api.ReportGenerationQueryColumn, []presto.Column, bool) {
+	listers, err := srv.listersFor(r)
 	if err != nil {
-		logger.WithError(err).Errorf("error converting ReportGenerationQuery columns to presto columns: %v", err)
-		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error converting columns: %v", err)
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return "", nil, nil, false
+	}
+	tableName, reportColumns, prestoColumns, err := getScheduledReportTable(logger, listers, name, r.FormValue("ignore_failed") == "true")
+	if err != nil {
+		writeReportTableError(logger, err, w, r)
+		return "", nil, nil, false
+	}
+	return tableName, reportColumns, prestoColumns, true
+}
+
+func (srv *server) getReport(logger log.FieldLogger, name, format string, useNewFormat bool, full bool, w http.ResponseWriter, r *http.Request) {
+	tableName, reportColumns, prestoColumns, ok := srv.getReportTable(logger, name, w, r)
+	if !ok {
+		return
+	}
+	tableName, ok = srv.reportVersionTable(logger, dependencyKindReport, name, tableName, w, r)
+	if !ok {
+		return
+	}
+	reportColumns, prestoColumns, whereSQL, ok := srv.selectReportResults(logger, tableName, reportColumns, prestoColumns, w, r)
+	if !ok {
+		return
+	}
+	results, ok := srv.getReportResults(logger, tableName, srv.reportResultsDataVersion(r, dependencyKindReport, name), prestoColumns, whereSQL, w, r)
+	if !ok {
 		return
 	}
 
-	prestoColumns, err := hiveColumnsToPrestoColumns(tableColumns)
-	if err != nil {
-		logger.WithError(err).Errorf("error converting PrestoTable hive columns to presto columns: %v", err)
-		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error converting columns: %v", err)
+	if len(results) > 0 && len(prestoColumns) != len(results[0]) {
+		logger.Errorf("report results schema doesn't match expected schema, got %d columns, expected %d", len(results[0]), len(prestoColumns))
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "report results schema doesn't match expected schema")
 		return
 	}
 
-	if !reflect.DeepEqual(queryPrestoColumns, prestoColumns) {
-		logger.Warnf("report columns and table columns don't match, ReportGenerationQuery was likely updated after the report ran")
-		logger.Debugf("mismatched columns, PrestoTable columns: %v, ReportGenerationQuery columns: %v", prestoColumns, queryPrestoColumns)
+	results = roundMonetaryResults(srv.monetaryRounding, reportColumns, results)
+	if useNewFormat {
+		writeResultsResponseV2(logger, full, format, reportColumns, results, w, r)
+	} else {
+		writeResultsResponse(logger, format, reportColumns, results, w, r)
 	}
+}
 
-	tableName := scheduledReportTableName(name)
-	results, err := presto.GetRows(srv.queryer, tableName, prestoColumns)
+// getReportTable returns the name of a report's table, and its columns,
+// checking the report has finished.
+func (srv *server) getReportTable(logger log.FieldLogger, name string, w http.ResponseWriter, r *http.Request) (string, []api.ReportGenerationQueryColumn, []presto.Column, bool) {
+	listers, err := srv.listersFor(r)
 	if err != nil {
-		logger.WithError(err).Errorf("failed to perform presto query")
-		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "failed to perform presto query (see operator logs for more details): %v", err)
-		return
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return "", nil, nil, false
 	}
+	tableName, reportColumns, prestoColumns, err := getReportTable(logger, listers, name)
+	if err != nil {
+		writeReportTableError(logger, err, w, r)
+		return "", nil, nil, false
+	}
+	return tableName, reportColumns, prestoColumns, true
+}
 
-	if len(results) > 0 && len(prestoTable.State.Parameters.Columns) != len(results[0]) {
-		logger.Errorf("report results schema doesn't match expected schema, got %d columns, expected %d", len(results[0]), len(prestoTable.State.Parameters.Columns))
-		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "report results schema doesn't match expected schema")
-		return
+// reportTableError is an error getting the table of a report, and the HTTP
+// status code it's returned with.
+type reportTableError struct {
+	code int
+	err  error
+}
+
+func (e *reportTableError) Error() string {
+	return e.err.Error()
+}
+
+func newReportTableError(code int, format string, args ...interface{}) error {
+	return &reportTableError{code: code, err: fmt.Errorf(format, args...)}
+}
+
+func writeReportTableError(logger log.FieldLogger, err error, w http.ResponseWriter, r *http.Request) {
+	code := http.StatusInternalServerError
+	if tableErr, ok := err.(*reportTableError); ok {
+		code = tableErr.code
 	}
+	logger.WithError(err).Errorf("%v", err)
+	writeErrorResponse(logger, w, r, code, "%v", err)
+}
 
-	writeResultsResponse(logger, format, reportQuery.Spec.Columns, results, w, r)
+// listersFor returns the listers of the namespace in the request's namespace
+// query parameter, or of the metering namespace if it isn't set.
+func (srv *server) listersFor(r *http.Request) (meteringListers, error) {
+	namespace := r.URL.Query().Get("namespace")
+	if namespace == "" {
+		return srv.listers, nil
+	}
+	if srv.listers.tenantListers == nil {
+		return meteringListers{}, fmt.Errorf("the namespace parameter requires tenant namespaces to be enabled")
+	}
+	return srv.listers.tenantListers(namespace), nil
 }
-func (srv *server) getReport(logger log.FieldLogger, name, format string, useNewFormat bool, full bool, w http.ResponseWriter, r *http.Request) {
+
+// getReportTable returns the name of a report's table, and its columns. The
+// report must have finished, otherwise a *reportTableError is returned with
+// http.StatusAccepted.
+func getReportTable(logger log.FieldLogger, listers meteringListers, name string) (string, []api.ReportGenerationQueryColumn, []presto.Column, error) {
 	// Get the current report to make sure it's in a finished state
-	report, err := srv.listers.reports.Get(name)
+	report, err := listers.reports.Get(name)
 	if err != nil {
 		code := http.StatusInternalServerError
 		if k8serrors.IsNotFound(err) {
 			code = http.StatusNotFound
 		}
-
-		logger.WithError(err).Errorf("error getting report: %v", err)
-		writeErrorResponse(logger, w, r, code, "error getting report: %v", err)
-		return
+		return "", nil, nil, newReportTableError(code, "error getting report: %v", err)
 	}
 	switch report.Status.Phase {
 	case api.ReportPhaseError:
-		err := fmt.Errorf(report.Status.Output)
-		logger.WithError(err).Errorf("the report encountered an error")
-		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "the report encountered an error: %v", err)
-		return
+		return "", nil, nil, newReportTableError(http.StatusInternalServerError, "the report encountered an error: %s", report.Status.Output)
 	case api.ReportPhaseFinished:
 		// continue with returning the report if the report is finished
 	case api.ReportPhaseWaiting, api.ReportPhaseStarted:
 		fallthrough
 	default:
-		logger.Errorf(ErrReportIsRunning.Error())
-		writeErrorResponse(logger, w, r, http.StatusAccepted, ErrReportIsRunning.Error())
-		return
+		return "", nil, nil, &reportTableError{code: http.StatusAccepted, err: ErrReportIsRunning}
 	}
 
-	reportQuery, err := srv.listers.reportGenerationQueries.Get(report.Spec.GenerationQueryName)
+	reportColumns, prestoColumns, err := getReportTableColumns(logger, listers, "report", report.Name, report.Spec.GenerationQueryName, report.Spec.GroupByLabels)
 	if err != nil {
-		logger.WithError(err).Errorf("error getting report: %v", err)
-		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error getting report: %v", err)
-		return
+		return "", nil, nil, err
+	}
+	return tenantTableName(listers.tenantNamespace, reportTableName(name)), reportColumns, prestoColumns, nil
+}
+
+// getScheduledReportTable returns the name of a scheduledReport's table, and
+// its columns. Unless ignoreFailed is true, the scheduledReport must not
+// have failed.
+func getScheduledReportTable(logger log.FieldLogger, listers meteringListers, name string, ignoreFailed bool) (string, []api.ReportGenerationQueryColumn, []presto.Column, error) {
+	// Get the scheduledReport to make sure it's isn't failed
+	report, err := listers.scheduledReports.Get(name)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if k8serrors.IsNotFound(err) {
+			code = http.StatusNotFound
+		}
+		return "", nil, nil, newReportTableError(code, "error getting scheduledReport: %v", err)
+	}
+
+	if !ignoreFailed {
+		if cond := cbutil.GetScheduledReportCondition(report.Status, api.ScheduledReportFailure); cond != nil && cond.Status == v1.ConditionTrue {
+			return "", nil, nil, newReportTableError(http.StatusInternalServerError, "scheduledReport is is failed state, reason: %s, message: %s", cond.Reason, cond.Message)
+		}
+	}
+
+	reportColumns, prestoColumns, err := getReportTableColumns(logger, listers, "scheduledreport", report.Name, report.Spec.GenerationQueryName, report.Spec.GroupByLabels)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return tenantTableName(listers.tenantNamespace, scheduledReportTableName(name)), reportColumns, prestoColumns, nil
+}
+
+// getReportTableColumns returns the columns of a report's results, and the
+// columns of its PrestoTable.
+func getReportTableColumns(logger log.FieldLogger, listers meteringListers, kind, name, generationQueryName string, groupByLabelKeys []string) ([]api.ReportGenerationQueryColumn, []presto.Column, error) {
+	reportQuery, err := listers.reportGenerationQueries.Get(generationQueryName)
+	if err != nil {
+		return nil, nil, newReportTableError(http.StatusInternalServerError, "error getting report: %v", err)
 	}
 
 	// Get the presto table to get actual columns in table
-	prestoTable, err := srv.listers.prestoTables.Get(prestoTableResourceNameFromKind("report", report.Name))
+	prestoTable, err := listers.prestoTables.Get(prestoTableResourceNameFromKind(kind, name))
 	if err != nil {
-		logger.WithError(err).Errorf("error getting presto table: %v", err)
-		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error getting presto table: %v", err)
-		return
+		return nil, nil, newReportTableError(http.StatusInternalServerError, "error getting presto table: %v", err)
+	}
+
+	groupByLabels, err := getGroupByLabels(reportQuery, groupByLabelKeys)
+	if err != nil {
+		return nil, nil, newReportTableError(http.StatusInternalServerError, "invalid groupByLabels: %v", err)
 	}
+	reportColumns := getReportColumns(reportQuery, groupByLabels)
 
 	tableColumns := prestoTable.State.Parameters.Columns
-	queryPrestoColumns, err := generatePrestoColumns(reportQuery)
+	queryPrestoColumns, err := generatePrestoColumns(reportColumns)
 	if err != nil {
-		logger.WithError(err).Errorf("error converting ReportGenerationQuery columns to presto columns: %v", err)
-		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error converting columns: %v", err)
-		return
+		return nil, nil, newReportTableError(http.StatusInternalServerError, "error converting columns: %v", err)
 	}
 
 	prestoColumns, err := hiveColumnsToPrestoColumns(tableColumns)
 	if err != nil {
-		logger.WithError(err).Errorf("error converting PrestoTable hive columns to presto columns: %v", err)
-		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error converting columns: %v", err)
-		return
+		return nil, nil, newReportTableError(http.StatusInternalServerError, "error converting columns: %v", err)
 	}
 
 	if !reflect.DeepEqual(queryPrestoColumns, prestoColumns) {
 		logger.Warnf("report columns and table columns don't match, ReportGenerationQuery was likely updated after the report ran")
 		logger.Debugf("mismatched columns, PrestoTable columns: %v, ReportGenerationQuery columns: %v", prestoColumns, queryPrestoColumns)
 	}
+	return reportColumns, prestoColumns, nil
+}
+
+// selectReportResults returns the columns of a report's results to return,
+// and a predicate matching the rows to return, from the columns and filter
+// query parameters. If the columns query parameter is set, only the columns
+// in its comma separated list are returned, in the same order as the
+// report's columns. Each filter query parameter is a filter expression
+// parsed by presto.ParseFilter, and only rows matching every filter are
+// returned. If row-level security is enabled, only the rows of the
+// namespaces the user can access are returned.
+func (srv *server) selectReportResults(logger log.FieldLogger, tableName string, reportColumns []api.ReportGenerationQueryColumn, prestoColumns []presto.Column, w http.ResponseWriter, r *http.Request) ([]api.ReportGenerationQueryColumn, []presto.Column, string, bool) {
+	var columnNames []string
+	if columnsStr := r.FormValue("columns"); columnsStr != "" {
+		for _, name := range strings.Split(columnsStr, ",") {
+			columnNames = append(columnNames, strings.TrimSpace(name))
+		}
+	}
+	selectedReportColumns, selectedPrestoColumns, whereSQL, err := selectReportColumns(reportColumns, prestoColumns, columnNames, r.Form["filter"])
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return nil, nil, "", false
+	}
+	if srv.auth != nil {
+		rowsSQL, err := srv.auth.rowLevelSecurityPredicate(srv.queryer, r, tableName, prestoColumns)
+		if err != nil {
+			writeErrorResponse(logger, w, r, http.StatusInternalServerError, "%v", err)
+			return nil, nil, "", false
+		}
+		if rowsSQL != "" && whereSQL != "" {
+			whereSQL = whereSQL + " AND " + rowsSQL
+		} else if rowsSQL != "" {
+			whereSQL = rowsSQL
+		}
+	}
+	return selectedReportColumns, selectedPrestoColumns, whereSQL, true
+}
+
+// selectReportColumns returns the columns named in columnNames, in the same
+// order as the report's columns, or every column if columnNames is empty,
+// and a predicate matching the rows matching every filter expression.
+func selectReportColumns(reportColumns []api.ReportGenerationQueryColumn, prestoColumns []presto.Column, columnNames, filterExprs []string) ([]api.ReportGenerationQueryColumn, []presto.Column, string, error) {
+	var filters []presto.Filter
+	for _, expr := range filterExprs {
+		filter, err := presto.ParseFilter(expr)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		filters = append(filters, filter)
+	}
+	whereSQL, err := presto.GenerateFiltersSQL(prestoColumns, filters)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	if len(columnNames) == 0 {
+		return reportColumns, prestoColumns, whereSQL, nil
+	}
+	selected := make(map[string]bool)
+	for _, name := range columnNames {
+		selected[name] = true
+	}
+	var (
+		selectedReportColumns []api.ReportGenerationQueryColumn
+		selectedPrestoColumns []presto.Column
+	)
+	for _, col := range prestoColumns {
+		if selected[col.Name] {
+			selectedPrestoColumns = append(selectedPrestoColumns, col)
+			delete(selected, col.Name)
+		}
+	}
+	if len(selected) != 0 {
+		var unknown []string
+		for name := range selected {
+			unknown = append(unknown, name)
+		}
+		sort.Strings(unknown)
+		return nil, nil, "", fmt.Errorf("unknown columns: %s", strings.Join(unknown, ", "))
+	}
+	for _, col := range selectedPrestoColumns {
+		for _, reportCol := range reportColumns {
+			if reportCol.Name == col.Name {
+				selectedReportColumns = append(selectedReportColumns, reportCol)
+				break
+			}
+		}
+	}
+	return selectedReportColumns, selectedPrestoColumns, whereSQL, nil
+}
+
+// getReportResults returns the rows of a report's table matching the
+// whereSQL predicate, which are cached while the report's data is at
+// dataVersion. If the limit query parameter is set, only a page of at most
+// limit rows is returned, starting after the cursor query parameter if it's
+// set, and the cursor for the next page is set in the X-Next-Cursor header
+// if there are more rows.
+func (srv *server) getReportResults(logger log.FieldLogger, tableName, dataVersion string, columns []presto.Column, whereSQL string, w http.ResponseWriter, r *http.Request) ([]presto.Row, bool) {
+	limitStr, cursorStr := r.FormValue("limit"), r.FormValue("cursor")
+	if limitStr == "" {
+		if cursorStr != "" {
+			writeErrorResponse(logger, w, r, http.StatusBadRequest, "limit must be set when cursor is set")
+			return nil, false
+		}
+		results, err := srv.queryCache.get(presto.GenerateGetRowsWhereSQL(tableName, columns, whereSQL), dataVersion, func() ([]presto.Row, error) {
+			return presto.GetRowsWhere(srv.queryer, tableName, columns, whereSQL)
+		})
+		if err != nil {
+			logger.WithError(err).Errorf("failed to perform presto query")
+			writeErrorResponse(logger, w, r, http.StatusInternalServerError, "failed to perform presto query (see operator logs for more details): %v", err)
+			return nil, false
+		}
+		return results, true
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "limit must be a positive integer, got %q", limitStr)
+		return nil, false
+	}
+	if err := presto.CheckPaginationSupported(columns); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "report results can't be paginated: %v", err)
+		return nil, false
+	}
+	var cursor *presto.Cursor
+	if cursorStr != "" {
+		cursor, err = presto.DecodeCursor(cursorStr, columns)
+		if err != nil {
+			writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+			return nil, false
+		}
+	}
 
-	tableName := reportTableName(name)
-	results, err := presto.GetRows(srv.queryer, tableName, prestoColumns)
+	results, next, err := presto.GetRowsPage(srv.queryer, tableName, columns, whereSQL, limit, cursor)
 	if err != nil {
 		logger.WithError(err).Errorf("failed to perform presto query")
 		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "failed to perform presto query (see operator logs for more details): %v", err)
-		return
+		return nil, false
+	}
+	if next != nil {
+		nextCursor, err := presto.EncodeCursor(next)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to encode the next cursor")
+			writeErrorResponse(logger, w, r, http.StatusInternalServerError, "failed to encode the next cursor: %v", err)
+			return nil, false
+		}
+		w.Header().Set(nextCursorHeader, nextCursor)
 	}
+	return results, true
+}
 
-	if len(results) > 0 && len(prestoColumns) != len(results[0]) {
-		logger.Errorf("report results schema doesn't match expected schema, got %d columns, expected %d", len(results[0]), len(prestoColumns))
-		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "report results schema doesn't match expected schema")
+// streamReportResults writes the rows of a report's table matching the
+// whereSQL predicate to the response as they're read from Presto, flushing
+// them every resultsStreamFlushRows rows, so the results are never all held
+// in memory. The response is gzip compressed if the client accepts it.
+func (srv *server) streamReportResults(logger log.FieldLogger, format, tableName string, reportColumns []api.ReportGenerationQueryColumn, prestoColumns []presto.Column, whereSQL string, w http.ResponseWriter, r *http.Request) {
+	csvFormat, err := csvFormatFromRequest(r, ',')
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
 		return
 	}
+	stream := &resultsStreamWriter{
+		w:         w,
+		format:    format,
+		csvFormat: csvFormat,
+		columns:   reportColumns,
+		gzip:      acceptsGzip(r),
+	}
+	defer func() {
+		setAuditRows(r, stream.rows)
+	}()
+	err = presto.StreamRows(r.Context(), srv.queryer, tableName, prestoColumns, whereSQL, func(row presto.Row) error {
+		if len(row) != len(prestoColumns) {
+			return fmt.Errorf("report results schema doesn't match expected schema, got %d columns, expected %d", len(row), len(prestoColumns))
+		}
+		return stream.writeRow(row)
+	})
+	if err == nil {
+		err = stream.close()
+	}
+	if err != nil {
+		logger.WithError(err).Errorf("failed to stream report results")
+		if !stream.started {
+			writeErrorResponse(logger, w, r, http.StatusInternalServerError, "failed to stream report results (see operator logs for more details): %v", err)
+			return
+		}
+		// the status and some of the results have already been sent, so
+		// abort the response to make sure the client doesn't mistake the
+		// partial results for the complete results
+		panic(http.ErrAbortHandler)
+	}
+}
 
-	if useNewFormat {
-		writeResultsResponseV2(logger, full, format, reportQuery.Spec.Columns, results, w, r)
-	} else {
-		writeResultsResponse(logger, format, reportQuery.Spec.Columns, results, w, r)
+// resultsStreamFlushRows is the number of rows written between flushing
+// streamed results to the client.
+const resultsStreamFlushRows = 1000
+
+// resultsStreamWriter writes rows of report results in CSV or as a JSON
+// array. The response headers are only written with the first row, or when
+// the writer is closed, so errors which occur before any results are
+// written can still be returned as error responses.
+type resultsStreamWriter struct {
+	w      http.ResponseWriter
+	format string
+	// csvFormat is how rows are written in the csv format.
+	csvFormat csvFormat
+	columns   []api.ReportGenerationQueryColumn
+	gzip      bool
+
+	started   bool
+	rows      int
+	out       io.Writer
+	gzipOut   *gzip.Writer
+	csvWriter *csv.Writer
+}
+
+func (sw *resultsStreamWriter) start() {
+	sw.started = true
+	switch sw.format {
+	case "json":
+		sw.w.Header().Set("Content-Type", "application/json")
+	case "csv":
+		sw.w.Header().Set("Content-Type", "text/csv")
+	}
+	sw.w.Header().Add("Vary", "Accept-Encoding")
+	sw.out = sw.w
+	if sw.gzip {
+		sw.w.Header().Set("Content-Encoding", "gzip")
+		sw.gzipOut = gzip.NewWriter(sw.w)
+		sw.out = sw.gzipOut
+	}
+	sw.w.WriteHeader(http.StatusOK)
+	if sw.format == "csv" {
+		sw.csvWriter = csv.NewWriter(sw.out)
+		sw.csvWriter.Comma = sw.csvFormat.delimiter
 	}
 }
 
+func (sw *resultsStreamWriter) writeRow(row presto.Row) error {
+	if !sw.started {
+		sw.start()
+	}
+	switch sw.format {
+	case "json":
+		b, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		sep := ","
+		if sw.rows == 0 {
+			sep = "["
+		}
+		if _, err := io.WriteString(sw.out, sep); err != nil {
+			return err
+		}
+		if _, err := sw.out.Write(b); err != nil {
+			return err
+		}
+	case "csv":
+		if sw.rows == 0 {
+			keys := make([]string, len(sw.columns))
+			for i, column := range sw.columns {
+				keys[i] = resultsColumnHeader(column, sw.csvFormat.units)
+			}
+			if err := sw.csvWriter.Write(keys); err != nil {
+				return err
+			}
+		}
+		vals := make([]string, len(sw.columns))
+		for i, column := range sw.columns {
+			val, ok := row[column.Name]
+			if !ok {
+				return fmt.Errorf("report results schema doesn't match expected schema, unexpected key: %q", column.Name)
+			}
+			var err error
+			vals[i], err = sw.csvFormat.value(val)
+			if err != nil {
+				return err
+			}
+		}
+		if err := sw.csvWriter.Write(vals); err != nil {
+			return err
+		}
+	}
+	sw.rows++
+	if sw.rows%resultsStreamFlushRows == 0 {
+		return sw.flush()
+	}
+	return nil
+}
+
+func (sw *resultsStreamWriter) flush() error {
+	if sw.csvWriter != nil {
+		sw.csvWriter.Flush()
+		if err := sw.csvWriter.Error(); err != nil {
+			return err
+		}
+	}
+	if sw.gzipOut != nil {
+		if err := sw.gzipOut.Flush(); err != nil {
+			return err
+		}
+	}
+	if flusher, ok := sw.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// close finishes writing the results.
+func (sw *resultsStreamWriter) close() error {
+	if !sw.started {
+		sw.start()
+	}
+	if sw.format == "json" {
+		end := "]"
+		if sw.rows == 0 {
+			end = "[]"
+		}
+		if _, err := io.WriteString(sw.out, end+"\n"); err != nil {
+			return err
+		}
+	}
+	if err := sw.flush(); err != nil {
+		return err
+	}
+	if sw.gzipOut != nil {
+		return sw.gzipOut.Close()
+	}
+	return nil
+}
+
+// acceptsGzip returns true if the request's Accept-Encoding header includes
+// gzip.
+func acceptsGzip(r *http.Request) bool {
+	for _, header := range r.Header["Accept-Encoding"] {
+		for _, encoding := range strings.Split(header, ",") {
+			if i := strings.Index(encoding, ";"); i != -1 {
+				encoding = encoding[:i]
+			}
+			if strings.TrimSpace(encoding) == "gzip" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func writeResultsResponseAsCSV(logger log.FieldLogger, columns []api.ReportGenerationQueryColumn, results []presto.Row, w http.ResponseWriter, r *http.Request) {
+	format, err := csvFormatFromRequest(r, ',')
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
 	w.Header().Set("Content-Type", "text/csv")
-	err := writeResultsAsCSV(columns, results, w, ',')
+	err = writeResultsAsCSV(columns, results, w, format)
 	if err != nil {
 		writeErrorResponse(logger, w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -353,17 +907,21 @@ func writeResultsResponseAsCSV(logger log.FieldLogger, columns []api.ReportGener
 	w.WriteHeader(http.StatusOK)
 }
 
-func writeResultsAsCSV(columns []api.ReportGenerationQueryColumn, results []presto.Row, w io.Writer, delimiter rune) error {
+// writeResultsAsCSV writes the results to w as CSV, with a header row of
+// the column names, followed by their units if the format includes them.
+func writeResultsAsCSV(columns []api.ReportGenerationQueryColumn, results []presto.Row, w io.Writer, format csvFormat) error {
 	csvWriter := csv.NewWriter(w)
-	csvWriter.Comma = delimiter
+	csvWriter.Comma = format.delimiter
 
 	// Write headers
 	var keys []string
 	if len(results) >= 1 {
-		for _, column := range columns {
+		header := make([]string, len(columns))
+		for i, column := range columns {
 			keys = append(keys, column.Name)
+			header[i] = resultsColumnHeader(column, format.units)
 		}
-		err := csvWriter.Write(keys)
+		err := csvWriter.Write(header)
 		if err != nil {
 			return err
 		}
@@ -377,23 +935,10 @@ func writeResultsAsCSV(columns []api.ReportGenerationQueryColumn, results []pres
 			if !ok {
 				return fmt.Errorf("report results schema doesn't match expected schema, unexpected key: %q", key)
 			}
-			switch v := val.(type) {
-			case string:
-				vals[i] = v
-			case []byte:
-				vals[i] = string(v)
-			case uint, uint8, uint16, uint32, uint64, int, int8, int16, int32, int64:
-				vals[i] = fmt.Sprintf("%d", v)
-			case float32, float64, complex64, complex128:
-				vals[i] = fmt.Sprintf("%f", v)
-			case bool:
-				vals[i] = fmt.Sprintf("%t", v)
-			case time.Time:
-				vals[i] = v.String()
-			case nil:
-				vals[i] = ""
-			default:
-				return fmt.Errorf("error marshalling csv: unknown type %t for value %v", val, val)
+			var err error
+			vals[i], err = format.value(val)
+			if err != nil {
+				return err
 			}
 		}
 		err := csvWriter.Write(vals)
@@ -418,8 +963,13 @@ func writeResultsResponseAsTabular(logger log.FieldLogger, columns []api.ReportG
 			return
 		}
 	}
+	format, err := csvFormatFromRequest(r, '\t')
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
 	tabWriter := tabwriter.NewWriter(w, 0, 8, padding, '\t', 0)
-	err := writeResultsAsCSV(columns, results, tabWriter, '\t')
+	err = writeResultsAsCSV(columns, results, tabWriter, format)
 	if err != nil {
 		writeErrorResponse(logger, w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -433,6 +983,7 @@ func writeResultsResponseAsTabular(logger log.FieldLogger, columns []api.ReportG
 }
 
 func writeResultsResponse(logger log.FieldLogger, format string, columns []api.ReportGenerationQueryColumn, results []presto.Row, w http.ResponseWriter, r *http.Request) {
+	setAuditRows(r, len(results))
 	switch format {
 	case "json":
 		newResults := make([]*orderedmap.OrderedMap, len(results))
@@ -450,7 +1001,204 @@ func writeResultsResponse(logger log.FieldLogger, format string, columns []api.R
 		writeResultsResponseAsCSV(logger, columns, results, w, r)
 	case "tab", "tabular":
 		writeResultsResponseAsTabular(logger, columns, results, w, r)
+	case "parquet":
+		writeResultsResponseAsParquet(logger, columns, results, w, r)
+	case "xlsx":
+		writeResultsResponseAsXLSX(logger, columns, results, w, r)
+	}
+}
+
+// writeResultsResponseAsParquet writes the results as a Parquet file.
+func writeResultsResponseAsParquet(logger log.FieldLogger, columns []api.ReportGenerationQueryColumn, results []presto.Row, w http.ResponseWriter, r *http.Request) {
+	// the file is written to a buffer first, so errors can still be
+	// returned as an error response
+	var buf bytes.Buffer
+	if err := writeResultsAsParquet(columns, results, &buf); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error converting results: %v", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	if _, err := buf.WriteTo(w); err != nil {
+		logger.WithError(err).Errorf("failed to write response")
+	}
+}
+
+// writeResultsAsParquet writes the results to w as a Parquet file. The
+// column types are based on the report's columns, and columns with complex
+// types, such as maps, are written as JSON strings.
+func writeResultsAsParquet(columns []api.ReportGenerationQueryColumn, results []presto.Row, w io.Writer) error {
+	parquetColumns := make([]parquet.Column, len(columns))
+	for i, column := range columns {
+		parquetColumns[i] = parquetColumn(column)
+	}
+
+	writer := parquet.NewWriter(w, parquetColumns)
+	for _, row := range results {
+		values := make([]interface{}, len(columns))
+		for i, column := range parquetColumns {
+			val, ok := row[column.Name]
+			if !ok {
+				return fmt.Errorf("report results schema doesn't match expected schema, unexpected key: %q", column.Name)
+			}
+			var err error
+			values[i], err = parquetValue(column, val)
+			if err != nil {
+				return err
+			}
+		}
+		if err := writer.Write(values); err != nil {
+			return err
+		}
 	}
+	return writer.Close()
+}
+
+func parquetColumn(column api.ReportGenerationQueryColumn) parquet.Column {
+	prestoType := simpleHiveColumnTypeToPrestoColumnType(column.Type)
+	switch prestoType {
+	case "BIGINT":
+		return parquet.Column{Name: column.Name, Type: parquet.Int64}
+	case "DOUBLE":
+		return parquet.Column{Name: column.Name, Type: parquet.Double}
+	case "BOOLEAN":
+		return parquet.Column{Name: column.Name, Type: parquet.Boolean}
+	case "TIMESTAMP":
+		return parquet.Column{Name: column.Name, Type: parquet.Timestamp}
+	}
+	var precision, scale int
+	if _, err := fmt.Sscanf(prestoType, "DECIMAL(%d,%d)", &precision, &scale); err == nil {
+		return parquet.Column{Name: column.Name, Type: parquet.Decimal, Precision: precision, Scale: scale}
+	}
+	return parquet.Column{Name: column.Name, Type: parquet.String}
+}
+
+// parquetValue converts a value returned by Presto into a value of the
+// Parquet column's type.
+func parquetValue(column parquet.Column, val interface{}) (interface{}, error) {
+	if column.Type == parquet.Decimal {
+		return parquetDecimalValue(column, val)
+	}
+	val, err := exportValue(val)
+	if err != nil || val == nil {
+		return val, err
+	}
+	switch column.Type {
+	case parquet.String:
+		if _, ok := val.(string); !ok {
+			return fmt.Sprint(val), nil
+		}
+	case parquet.Double:
+		if v, ok := val.(int64); ok {
+			return float64(v), nil
+		}
+	}
+	return val, nil
+}
+
+// parquetDecimalValue converts a value of a DECIMAL column into its
+// unscaled value with the Parquet column's scale.
+func parquetDecimalValue(column parquet.Column, val interface{}) (interface{}, error) {
+	var d presto.Decimal
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case presto.Decimal:
+		d = v
+	case string, float64:
+		s, ok := v.(string)
+		if !ok {
+			// tables created before the column became a decimal still
+			// contain doubles.
+			s = strconv.FormatFloat(v.(float64), 'f', -1, 64)
+		}
+		var err error
+		if d, err = presto.ParseDecimal(s); err != nil {
+			return nil, fmt.Errorf("column %q: %v", column.Name, err)
+		}
+	default:
+		return nil, fmt.Errorf("column %q: unexpected value type %T", column.Name, val)
+	}
+	d, exact := d.Rescale(column.Scale)
+	if _, isFloat := val.(float64); !exact && !isFloat {
+		return nil, fmt.Errorf("column %q: %s has more than %d digits after the decimal point", column.Name, val, column.Scale)
+	}
+	return d.Unscaled(), nil
+}
+
+// writeResultsResponseAsXLSX writes the results as an Excel workbook.
+func writeResultsResponseAsXLSX(logger log.FieldLogger, columns []api.ReportGenerationQueryColumn, results []presto.Row, w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := writeResultsAsXLSX(columns, results, &buf, r.FormValue("units") == "true"); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error converting results: %v", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	w.WriteHeader(http.StatusOK)
+	if _, err := buf.WriteTo(w); err != nil {
+		logger.WithError(err).Errorf("failed to write response")
+	}
+}
+
+// writeResultsAsXLSX writes the results to w as an Excel workbook, with a
+// header row containing the column names, followed by their units if
+// withUnits is set. Columns with complex types, such as maps, are written as
+// JSON strings.
+func writeResultsAsXLSX(columns []api.ReportGenerationQueryColumn, results []presto.Row, w io.Writer, withUnits bool) error {
+	writer := xlsx.NewWriter(w, "Report")
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = resultsColumnHeader(column, withUnits)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, row := range results {
+		values := make([]interface{}, len(columns))
+		for i, column := range columns {
+			val, ok := row[column.Name]
+			if !ok {
+				return fmt.Errorf("report results schema doesn't match expected schema, unexpected key: %q", column.Name)
+			}
+			var err error
+			values[i], err = exportValue(val)
+			if err != nil {
+				return err
+			}
+		}
+		if err := writer.Write(values); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// exportValue converts a value returned by Presto into a string, int64,
+// float64, bool or time.Time, which the Parquet and Excel writers support.
+// Decimals are converted into json.Numbers, which the Excel writer writes
+// exactly. Other values, such as maps, are converted into JSON strings.
+func exportValue(val interface{}) (interface{}, error) {
+	switch v := val.(type) {
+	case nil, string, int64, float64, bool, time.Time:
+		return v, nil
+	case presto.Decimal:
+		return json.Number(v.String()), nil
+	case []byte:
+		return string(v), nil
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case float32:
+		return float64(v), nil
+	}
+	b, err := json.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
 }
 
 type GetReportResults struct {
@@ -512,17 +1260,13 @@ func writeResultsResponseV2(logger log.FieldLogger, full bool, format string, co
 	}
 
 	if format == "json" {
+		setAuditRows(r, len(results))
 		writeResponseAsJSON(logger, w, http.StatusOK, convertsToGetReportResults(results, filteredColumns))
 		return
 	}
 	writeResultsResponse(logger, format, filteredColumns, results, w, r)
 }
 
-func (srv *server) runReport(logger log.FieldLogger, query, start, end string, w http.ResponseWriter) {
-	w.WriteHeader(http.StatusInternalServerError)
-	w.Write([]byte("method not yet implemented"))
-}
-
 type CollectPromsumDataRequest struct {
 	StartTime time.Time `json:"startTime"`
 	EndTime   time.Time `json:"endTime"`
@@ -559,16 +1303,25 @@ func (srv *server) storePromsumDataHandler(w http.ResponseWriter, r *http.Reques
 	logger := newRequestLogger(srv.logger, r, srv.rand)
 
 	name := chi.URLParam(r, "datasourceName")
+	dataSource, err := srv.listers.reportDataSources.Get(name)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			writeErrorResponse(logger, w, r, http.StatusNotFound, "ReportDataSource %s not found", name)
+			return
+		}
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to get ReportDataSource %s: %v", name, err)
+		return
+	}
 
 	decoder := json.NewDecoder(r.Body)
 	var req StorePromsumDataRequest
-	err := decoder.Decode(&req)
+	err = decoder.Decode(&req)
 	if err != nil {
 		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to decode response as JSON: %v", err)
 		return
 	}
 
-	err = prestostore.StorePrometheusMetrics(context.Background(), srv.queryer, dataSourceTableName(name), []*prestostore.PrometheusMetric(req))
+	err = prestostore.StorePrometheusMetrics(context.Background(), srv.importerQueryer, dataSourceTableName(name), isDatePartitionedDataSource(dataSource), []*prestostore.PrometheusMetric(req))
 	if err != nil {
 		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to store promsum metrics: %v", err)
 		return
@@ -605,7 +1358,7 @@ func (srv *server) fetchPromsumDataHandler(w http.ResponseWriter, r *http.Reques
 			return
 		}
 	}
-	results, err := prestostore.GetPrometheusMetrics(srv.queryer, datasourceTable, startTime, endTime)
+	results, err := prestostore.GetPrometheusMetrics(srv.importerQueryer, datasourceTable, startTime, endTime)
 	if err != nil {
 		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error querying for datasource: %v", err)
 		return
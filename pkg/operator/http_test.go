@@ -1,10 +1,12 @@
 package operator
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
 	"io/ioutil"
+	"math/big"
 	"math/rand"
 	"net/http"
 	"net/http/httptest"
@@ -25,6 +27,7 @@ import (
 	"github.com/operator-framework/operator-metering/pkg/hive"
 	"github.com/operator-framework/operator-metering/pkg/presto"
 	"github.com/operator-framework/operator-metering/pkg/presto/mock"
+	"github.com/operator-framework/operator-metering/pkg/util/parquet"
 )
 
 var (
@@ -294,7 +297,13 @@ func TestAPIV1ReportsGet(t *testing.T) {
 			}
 
 			// setup a test server suitable for making API calls against
-			router := newRouter(testLogger, queryer, testRand, noopPrometheusImporterFunc, listers)
+			router := newRouter(testLogger, routerConfig{
+				rand:            testRand,
+				queryer:         queryer,
+				importerQueryer: queryer,
+				collectorFunc:   noopPrometheusImporterFunc,
+				listers:         listers,
+			})
 			server := httptest.NewServer(router)
 			defer server.Close()
 
@@ -520,7 +529,7 @@ func TestAPIV2ReportsFull(t *testing.T) {
 			report:             newTestReport(testReportName, namespace, testQueryName, reportStart, reportEnd, v1alpha1.ReportStatus{Phase: v1alpha1.ReportPhaseFinished}),
 			apiPath:            apiReportV2URLFull(testReportName) + "?format=doesntexist",
 			expectedStatusCode: http.StatusBadRequest,
-			expectedAPIError:   "format must be one of: csv, json or tabular",
+			expectedAPIError:   "format must be one of: csv, json, tabular, parquet or xlsx",
 		},
 		"mismatched-results-schema-to-table-schema": {
 			reportName: testReportName,
@@ -611,7 +620,13 @@ func TestAPIV2ReportsFull(t *testing.T) {
 			}
 
 			// setup a test server suitable for making API calls against
-			router := newRouter(testLogger, queryer, testRand, noopPrometheusImporterFunc, listers)
+			router := newRouter(testLogger, routerConfig{
+				rand:            testRand,
+				queryer:         queryer,
+				importerQueryer: queryer,
+				collectorFunc:   noopPrometheusImporterFunc,
+				listers:         listers,
+			})
 			server := httptest.NewServer(router)
 			defer server.Close()
 
@@ -820,7 +835,7 @@ func TestAPIV2ReportsTable(t *testing.T) {
 			report:             newTestReport(testReportName, namespace, testQueryName, reportStart, reportEnd, v1alpha1.ReportStatus{Phase: v1alpha1.ReportPhaseFinished}),
 			apiPath:            apiReportV2URLTable(testReportName) + "?format=doesntexist",
 			expectedStatusCode: http.StatusBadRequest,
-			expectedAPIError:   "format must be one of: csv, json or tabular",
+			expectedAPIError:   "format must be one of: csv, json, tabular, parquet or xlsx",
 		},
 		"mismatched-results-schema-to-table-schema": {
 			reportName: testReportName,
@@ -911,7 +926,13 @@ func TestAPIV2ReportsTable(t *testing.T) {
 			}
 
 			// setup a test server suitable for making API calls against
-			router := newRouter(testLogger, queryer, testRand, noopPrometheusImporterFunc, listers)
+			router := newRouter(testLogger, routerConfig{
+				rand:            testRand,
+				queryer:         queryer,
+				importerQueryer: queryer,
+				collectorFunc:   noopPrometheusImporterFunc,
+				listers:         listers,
+			})
 			server := httptest.NewServer(router)
 			defer server.Close()
 
@@ -946,3 +967,246 @@ func TestAPIV2ReportsTable(t *testing.T) {
 		})
 	}
 }
+
+func TestAPIReadOnly(t *testing.T) {
+	tests := map[string]struct {
+		method             string
+		apiPath            string
+		expectedStatusCode int
+	}{
+		"start report run": {
+			method:             "POST",
+			apiPath:            APIV1ReportRunsEndpoint,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		"run ad-hoc query": {
+			method:             "POST",
+			apiPath:            APIV1QueryEndpoint + "?format=json",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		"collect prometheus data": {
+			method:             "POST",
+			apiPath:            "/api/v1/datasources/prometheus/collect",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		"store prometheus data": {
+			method:             "POST",
+			apiPath:            "/api/v1/datasources/prometheus/store/pod-cpu-usage",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		"ingest prometheus data": {
+			method:             "POST",
+			apiPath:            APIV1PrometheusIngestEndpoint + "/pod-cpu-usage",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		"importer recommendations are not disabled": {
+			method:             "GET",
+			apiPath:            APIV1PrometheusImporterRecommendationsEndpoint,
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// the queryer should never be used by disabled endpoints
+			queryer := mockpresto.NewMockExecQueryer(ctrl)
+			router := newRouter(testLogger, routerConfig{
+				rand:            testRand,
+				queryer:         queryer,
+				importerQueryer: queryer,
+				collectorFunc:   noopPrometheusImporterFunc,
+				readOnly:        true,
+			})
+			server := httptest.NewServer(router)
+			defer server.Close()
+
+			req, err := http.NewRequest(tt.method, server.URL+tt.apiPath, nil)
+			require.NoError(t, err)
+			resp, err := server.Client().Do(req)
+			require.NoError(t, err, "expected making http request to not return error")
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode, "Expected http status code to match")
+			if tt.expectedStatusCode == http.StatusForbidden {
+				var errResp errorResponse
+				err = json.NewDecoder(resp.Body).Decode(&errResp)
+				require.NoError(t, err, "expected unmarshal to not error")
+				assert.Contains(t, errResp.Error, "read-only mode")
+			}
+		})
+	}
+}
+
+func TestAPIV1ReportsStream(t *testing.T) {
+	const namespace = "default"
+	const reportName = "test-report"
+	reportStart := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	reportEnd := reportStart.AddDate(0, 1, 0)
+
+	queryColumns := []v1alpha1.ReportGenerationQueryColumn{
+		{Name: "namespace", Type: "string"},
+		{Name: "amount", Type: "double"},
+	}
+	tableColumns := []hive.Column{
+		{Name: "namespace", Type: "string"},
+		{Name: "amount", Type: "double"},
+	}
+	results := []presto.Row{
+		{"namespace": "team-a", "amount": 1.5},
+		{"namespace": "team-b", "amount": nil},
+	}
+
+	tests := map[string]struct {
+		format             string
+		params             url.Values
+		gzip               bool
+		expectedColumns    []presto.Column
+		expectedWhereSQL   string
+		results            []presto.Row
+		queryErr           error
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		"json": {
+			format:             "json",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `[{"amount":1.5,"namespace":"team-a"},{"amount":null,"namespace":"team-b"}]` + "\n",
+		},
+		"gzipped csv": {
+			format:             "csv",
+			gzip:               true,
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "namespace,amount\nteam-a,1.500000\nteam-b,\n",
+		},
+		"filtered columns": {
+			format: "csv",
+			params: url.Values{
+				"columns": []string{"namespace"},
+				"filter":  []string{"namespace=team-a", "amount>1"},
+			},
+			expectedColumns:    []presto.Column{{Name: "namespace", Type: "VARCHAR"}},
+			expectedWhereSQL:   `"namespace" = 'team-a' AND "amount" > DOUBLE '1'`,
+			results:            []presto.Row{{"namespace": "team-a"}},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "namespace\nteam-a\n",
+		},
+		"invalid filter": {
+			format:             "csv",
+			params:             url.Values{"filter": []string{"pod=app-1"}},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"query error": {
+			format:             "csv",
+			queryErr:           errors.New("presto is down"),
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			reportIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+			reportGenerationQueryIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+			prestoTableIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+			reportIndexer.Add(newTestReport(reportName, namespace, "test-query", reportStart, reportEnd, v1alpha1.ReportStatus{Phase: v1alpha1.ReportPhaseFinished}))
+			reportGenerationQueryIndexer.Add(newTestReportGenQuery("test-query", namespace, queryColumns))
+			prestoTableIndexer.Add(newTestPrestoTable(reportName, namespace, tableColumns))
+			listers := meteringListers{
+				reports:                 listers.NewReportLister(reportIndexer).Reports(namespace),
+				reportGenerationQueries: listers.NewReportGenerationQueryLister(reportGenerationQueryIndexer).ReportGenerationQueries(namespace),
+				prestoTables:            listers.NewPrestoTableLister(prestoTableIndexer).PrestoTables(namespace),
+			}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			prestoColumns, err := hiveColumnsToPrestoColumns(tableColumns)
+			require.NoError(t, err)
+			queryer := mockpresto.NewMockExecQueryer(ctrl)
+			if tt.expectedStatusCode != http.StatusBadRequest {
+				expectedColumns, expectedResults := prestoColumns, results
+				if tt.expectedColumns != nil {
+					expectedColumns, expectedResults = tt.expectedColumns, tt.results
+				}
+				queryer.EXPECT().Query(presto.GenerateGetRowsWhereSQL(reportTableName(reportName), expectedColumns, tt.expectedWhereSQL)).Return(expectedResults, tt.queryErr)
+			}
+
+			router := newRouter(testLogger, routerConfig{
+				rand:            testRand,
+				queryer:         queryer,
+				importerQueryer: queryer,
+				collectorFunc:   noopPrometheusImporterFunc,
+				listers:         listers,
+			})
+			server := httptest.NewServer(router)
+			defer server.Close()
+
+			params := url.Values{
+				"format": []string{tt.format},
+				"name":   []string{reportName},
+			}
+			for key, values := range tt.params {
+				params[key] = values
+			}
+			req, err := http.NewRequest("GET", server.URL+APIV1ReportsStreamEndpoint+"?"+params.Encode(), nil)
+			require.NoError(t, err)
+			if tt.gzip {
+				// setting Accept-Encoding disables the client's
+				// transparent decompression
+				req.Header.Set("Accept-Encoding", "gzip")
+			}
+			resp, err := server.Client().Do(req)
+			require.NoError(t, err, "expected making http request to not return error")
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode, "Expected http status code to match")
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+			body := resp.Body
+			if tt.gzip {
+				require.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+				body, err = gzip.NewReader(resp.Body)
+				require.NoError(t, err)
+			}
+			b, err := ioutil.ReadAll(body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedBody, string(b))
+		})
+	}
+}
+
+func TestDecimalExportValues(t *testing.T) {
+	cost := presto.Decimal("12345678901234567890.123456789012")
+
+	csv, err := defaultCSVFormat.value(cost)
+	require.NoError(t, err)
+	assert.Equal(t, "12345678901234567890.123456789012", csv)
+
+	b, err := json.Marshal(map[string]interface{}{"cost": cost})
+	require.NoError(t, err)
+	assert.Equal(t, `{"cost":12345678901234567890.123456789012}`, string(b))
+
+	exported, err := exportValue(cost)
+	require.NoError(t, err)
+	assert.Equal(t, json.Number("12345678901234567890.123456789012"), exported)
+
+	column := parquetColumn(v1alpha1.ReportGenerationQueryColumn{Name: "cost", Type: "decimal(38, 12)"})
+	assert.Equal(t, parquet.Column{Name: "cost", Type: parquet.Decimal, Precision: 38, Scale: 12}, column)
+	unscaled, ok := new(big.Int).SetString("12345678901234567890123456789012", 10)
+	require.True(t, ok)
+	val, err := parquetValue(column, cost)
+	require.NoError(t, err)
+	assert.Equal(t, unscaled, val)
+	val, err = parquetValue(column, presto.Decimal("1.5"))
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(1500000000000), val)
+	_, err = parquetValue(column, presto.Decimal("0.0000000000001"))
+	assert.Error(t, err)
+}
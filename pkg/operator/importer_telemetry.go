@@ -0,0 +1,274 @@
+package operator
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+const (
+	APIV1PrometheusImporterRecommendationsEndpoint = "/api/v1/datasources/prometheus/recommendations"
+
+	// importerMemoryHighWatermark and importerMemoryLowWatermark are the
+	// fractions of the memory limit above and below which changing the
+	// memory limit is recommended.
+	importerMemoryHighWatermark = 0.8
+	importerMemoryLowWatermark  = 0.3
+	// importerMemoryHeadroom is the multiple of the peak heap size
+	// recommended as the memory limit.
+	importerMemoryHeadroom = 1.5
+	// importerMemoryLimitRounding is the multiple recommended memory limits
+	// are rounded up to.
+	importerMemoryLimitRounding = 16 << 20
+	// importerMaxChunkMetrics is the number of metrics returned for a
+	// single chunk above which a smaller chunk size is recommended.
+	importerMaxChunkMetrics = 100000
+)
+
+var (
+	importerPeakHeapBytesGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "metering",
+			Name:      "prometheus_importer_peak_heap_bytes",
+			Help:      "The largest heap size observed during the most recent import of a Prometheus ReportDataSource.",
+		},
+		[]string{"reportdatasource"},
+	)
+	importerMaxChunkMetricsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "metering",
+			Name:      "prometheus_importer_max_chunk_metrics",
+			Help:      "The most metrics returned for a single chunk during the most recent import of a Prometheus ReportDataSource.",
+		},
+		[]string{"reportdatasource"},
+	)
+	importerRecommendedMemoryLimitGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "metering",
+			Name:      "prometheus_importer_recommended_memory_limit_bytes",
+			Help:      "The memory limit recommended for the reporting-operator based on the peak heap size of recent Prometheus imports.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(importerPeakHeapBytesGauge)
+	prometheus.MustRegister(importerMaxChunkMetricsGauge)
+	prometheus.MustRegister(importerRecommendedMemoryLimitGauge)
+}
+
+// importerTelemetry tracks the stats of the most recent import of each
+// Prometheus ReportDataSource, which are used to recommend changes to the
+// reporting-operator's memory limit, and to the chunk and step sizes of
+// ReportDataSources.
+type importerTelemetry struct {
+	memoryLimitBytes int64
+
+	mu    sync.Mutex
+	stats map[string]prestostore.ImportStats
+}
+
+func newImporterTelemetry(memoryLimitBytes int64) *importerTelemetry {
+	return &importerTelemetry{
+		memoryLimitBytes: memoryLimitBytes,
+		stats:            make(map[string]prestostore.ImportStats),
+	}
+}
+
+func (t *importerTelemetry) record(dataSourceName string, stats *prestostore.ImportStats) {
+	if stats == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats[dataSourceName] = *stats
+	importerPeakHeapBytesGauge.WithLabelValues(dataSourceName).Set(float64(stats.PeakHeapBytes))
+	importerMaxChunkMetricsGauge.WithLabelValues(dataSourceName).Set(float64(stats.MaxChunkMetrics))
+	importerRecommendedMemoryLimitGauge.Set(float64(recommendedMemoryLimit(peakHeapBytes(t.stats))))
+}
+
+func (t *importerTelemetry) remove(dataSourceName string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.stats, dataSourceName)
+	importerPeakHeapBytesGauge.DeleteLabelValues(dataSourceName)
+	importerMaxChunkMetricsGauge.DeleteLabelValues(dataSourceName)
+	importerRecommendedMemoryLimitGauge.Set(float64(recommendedMemoryLimit(peakHeapBytes(t.stats))))
+}
+
+// lastImport returns the stats of the most recent import of the
+// ReportDataSource by the local cluster's importer, if it has imported any
+// data since the reporting-operator started.
+func (t *importerTelemetry) lastImport(dataSourceName string) (prestostore.ImportStats, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats, exists := t.stats[dataSourceName]
+	return stats, exists
+}
+
+func (t *importerTelemetry) recommendations() ImporterRecommendationsResponse {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return getImporterRecommendations(t.memoryLimitBytes, t.stats)
+}
+
+type ImporterRecommendationsResponse struct {
+	// MemoryLimitBytes is the reporting-operator's memory limit, if known.
+	MemoryLimitBytes int64 `json:"memoryLimitBytes,omitempty"`
+	// PeakHeapBytes is the largest heap size observed during the most
+	// recent import of any ReportDataSource.
+	PeakHeapBytes uint64 `json:"peakHeapBytes"`
+	// RecommendedMemoryLimitBytes is set when the memory limit should be
+	// changed.
+	RecommendedMemoryLimitBytes int64                              `json:"recommendedMemoryLimitBytes,omitempty"`
+	DataSources                 []ImporterDataSourceRecommendation `json:"dataSources"`
+	Recommendations             []string                           `json:"recommendations"`
+}
+
+type ImporterDataSourceRecommendation struct {
+	Name               string    `json:"name"`
+	LastImportStart    time.Time `json:"lastImportStart"`
+	LastImportDuration string    `json:"lastImportDuration"`
+	ChunkSize          string    `json:"chunkSize"`
+	StepSize           string    `json:"stepSize"`
+	TimeRanges         int       `json:"timeRanges"`
+	Metrics            int       `json:"metrics"`
+	MaxChunkMetrics    int       `json:"maxChunkMetrics"`
+	PeakHeapBytes      uint64    `json:"peakHeapBytes"`
+	// RecommendedChunkSize and RecommendedStepSize are set when the
+	// ReportDataSource's queryConfig should be changed.
+	RecommendedChunkSize string `json:"recommendedChunkSize,omitempty"`
+	RecommendedStepSize  string `json:"recommendedStepSize,omitempty"`
+}
+
+// getImporterRecommendations recommends a memory limit with enough headroom
+// for the peak heap size of the most recent imports, and smaller chunk
+// sizes, or larger step sizes, for ReportDataSources whose chunks contain
+// too many metrics, which is what determines how much memory an import
+// needs. When the heap is close to the memory limit, chunk sizes are
+// reduced enough to halve the metrics in the largest chunks.
+func getImporterRecommendations(memoryLimitBytes int64, stats map[string]prestostore.ImportStats) ImporterRecommendationsResponse {
+	peak := peakHeapBytes(stats)
+	resp := ImporterRecommendationsResponse{
+		MemoryLimitBytes: memoryLimitBytes,
+		PeakHeapBytes:    peak,
+		DataSources:      []ImporterDataSourceRecommendation{},
+		Recommendations:  []string{},
+	}
+	if len(stats) == 0 {
+		return resp
+	}
+
+	recommendedLimit := recommendedMemoryLimit(peak)
+	var memoryPressure bool
+	switch {
+	case memoryLimitBytes <= 0:
+		resp.RecommendedMemoryLimitBytes = recommendedLimit
+		resp.Recommendations = append(resp.Recommendations, fmt.Sprintf("the reporting-operator's memory limit is unknown, set it to at least %s, which is %gx the peak heap size of %s", formatBytes(recommendedLimit), importerMemoryHeadroom, formatBytes(int64(peak))))
+	case float64(peak) >= importerMemoryHighWatermark*float64(memoryLimitBytes):
+		memoryPressure = true
+		resp.RecommendedMemoryLimitBytes = recommendedLimit
+		resp.Recommendations = append(resp.Recommendations, fmt.Sprintf("the peak heap size of %s is %.0f%% of the memory limit of %s, raise the memory limit to %s or reduce the chunk sizes of the largest ReportDataSources", formatBytes(int64(peak)), 100*float64(peak)/float64(memoryLimitBytes), formatBytes(memoryLimitBytes), formatBytes(recommendedLimit)))
+	case float64(peak) <= importerMemoryLowWatermark*float64(memoryLimitBytes) && recommendedLimit < memoryLimitBytes:
+		resp.RecommendedMemoryLimitBytes = recommendedLimit
+		resp.Recommendations = append(resp.Recommendations, fmt.Sprintf("the peak heap size of %s is %.0f%% of the memory limit of %s, the memory limit can be lowered to %s", formatBytes(int64(peak)), 100*float64(peak)/float64(memoryLimitBytes), formatBytes(memoryLimitBytes), formatBytes(recommendedLimit)))
+	}
+
+	var largestChunk int
+	for _, s := range stats {
+		if s.MaxChunkMetrics > largestChunk {
+			largestChunk = s.MaxChunkMetrics
+		}
+	}
+
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s := stats[name]
+		rec := ImporterDataSourceRecommendation{
+			Name:               name,
+			LastImportStart:    s.Start,
+			LastImportDuration: s.Duration.String(),
+			ChunkSize:          s.ChunkSize.String(),
+			StepSize:           s.StepSize.String(),
+			TimeRanges:         s.TimeRanges,
+			Metrics:            s.Metrics,
+			MaxChunkMetrics:    s.MaxChunkMetrics,
+			PeakHeapBytes:      s.PeakHeapBytes,
+		}
+
+		targetMetrics := importerMaxChunkMetrics
+		// under memory pressure, shrink the chunks of the ReportDataSources
+		// with the largest chunks.
+		if memoryPressure && s.MaxChunkMetrics*2 >= largestChunk && s.MaxChunkMetrics/2 < targetMetrics {
+			targetMetrics = s.MaxChunkMetrics / 2
+		}
+		if s.MaxChunkMetrics > targetMetrics && targetMetrics > 0 && s.StepSize > 0 {
+			chunkSize := time.Duration(float64(s.ChunkSize) * float64(targetMetrics) / float64(s.MaxChunkMetrics)).Truncate(s.StepSize)
+			if chunkSize >= s.StepSize {
+				rec.RecommendedChunkSize = chunkSize.String()
+				resp.Recommendations = append(resp.Recommendations, fmt.Sprintf("ReportDataSource %s returned up to %d metrics per chunk, reduce its queryConfig.chunkSize from %s to %s", name, s.MaxChunkMetrics, s.ChunkSize, chunkSize))
+			} else {
+				// the chunk can't get any smaller, so reduce the resolution
+				// instead.
+				factor := (s.MaxChunkMetrics + targetMetrics - 1) / targetMetrics
+				stepSize := s.StepSize * time.Duration(factor)
+				if stepSize > s.ChunkSize {
+					stepSize = s.ChunkSize
+				}
+				if stepSize > s.StepSize {
+					rec.RecommendedStepSize = stepSize.String()
+					resp.Recommendations = append(resp.Recommendations, fmt.Sprintf("ReportDataSource %s returned up to %d metrics per chunk, increase its queryConfig.stepSize from %s to %s", name, s.MaxChunkMetrics, s.StepSize, stepSize))
+				}
+			}
+		}
+		resp.DataSources = append(resp.DataSources, rec)
+	}
+	return resp
+}
+
+func peakHeapBytes(stats map[string]prestostore.ImportStats) uint64 {
+	var peak uint64
+	for _, s := range stats {
+		if s.PeakHeapBytes > peak {
+			peak = s.PeakHeapBytes
+		}
+	}
+	return peak
+}
+
+// recommendedMemoryLimit returns the memory limit with enough headroom for
+// the peak heap size.
+func recommendedMemoryLimit(peak uint64) int64 {
+	limit := int64(float64(peak) * importerMemoryHeadroom)
+	if rem := limit % importerMemoryLimitRounding; rem != 0 {
+		limit += importerMemoryLimitRounding - rem
+	}
+	return limit
+}
+
+func formatBytes(b int64) string {
+	return fmt.Sprintf("%dMi", b>>20)
+}
+
+func (srv *server) getImporterRecommendationsHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	if r.Method != "GET" {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "Not found")
+		return
+	}
+	if srv.importerTelemetry == nil {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "Prometheus importer telemetry is not enabled")
+		return
+	}
+	writeResponseAsJSON(logger, w, http.StatusOK, srv.importerTelemetry.recommendations())
+}
@@ -0,0 +1,113 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+func TestGetImporterRecommendations(t *testing.T) {
+	const mi = 1 << 20
+	newStats := func(maxChunkMetrics int, peakHeapBytes uint64) prestostore.ImportStats {
+		return prestostore.ImportStats{
+			ChunkSize:       5 * time.Minute,
+			StepSize:        time.Minute,
+			TimeRanges:      12,
+			Metrics:         12 * maxChunkMetrics,
+			MaxChunkMetrics: maxChunkMetrics,
+			PeakHeapBytes:   peakHeapBytes,
+		}
+	}
+
+	tests := map[string]struct {
+		memoryLimitBytes int64
+		stats            map[string]prestostore.ImportStats
+
+		expectedMemoryLimit int64
+		expectedChunkSizes  map[string]string
+		expectedStepSizes   map[string]string
+		expectedCount       int
+	}{
+		"no imports": {
+			memoryLimitBytes: 150 * mi,
+		},
+		"within limits": {
+			memoryLimitBytes: 150 * mi,
+			stats: map[string]prestostore.ImportStats{
+				"pod-usage-cpu-cores": newStats(1000, 80*mi),
+			},
+		},
+		"unknown memory limit": {
+			stats: map[string]prestostore.ImportStats{
+				"pod-usage-cpu-cores": newStats(1000, 80*mi),
+			},
+			expectedMemoryLimit: 128 * mi,
+			expectedCount:       1,
+		},
+		"memory limit too low": {
+			memoryLimitBytes: 150 * mi,
+			stats: map[string]prestostore.ImportStats{
+				"pod-usage-cpu-cores":     newStats(40000, 140*mi),
+				"pod-request-cpu-cores":   newStats(30000, 120*mi),
+				"node-capacity-cpu-cores": newStats(1000, 100*mi),
+			},
+			expectedMemoryLimit: 224 * mi,
+			expectedChunkSizes: map[string]string{
+				"pod-usage-cpu-cores":   "2m0s",
+				"pod-request-cpu-cores": "2m0s",
+			},
+			expectedCount: 3,
+		},
+		"memory limit too high": {
+			memoryLimitBytes: 1024 * mi,
+			stats: map[string]prestostore.ImportStats{
+				"pod-usage-cpu-cores": newStats(1000, 100*mi),
+			},
+			expectedMemoryLimit: 160 * mi,
+			expectedCount:       1,
+		},
+		"chunks too large": {
+			memoryLimitBytes: 1024 * mi,
+			stats: map[string]prestostore.ImportStats{
+				"pod-usage-cpu-cores": newStats(250000, 500*mi),
+			},
+			expectedChunkSizes: map[string]string{
+				"pod-usage-cpu-cores": "2m0s",
+			},
+			expectedCount: 1,
+		},
+		"chunks too large at the smallest chunk size": {
+			memoryLimitBytes: 1024 * mi,
+			stats: map[string]prestostore.ImportStats{
+				"pod-usage-cpu-cores": {
+					ChunkSize:       time.Minute,
+					StepSize:        30 * time.Second,
+					MaxChunkMetrics: 250000,
+					PeakHeapBytes:   500 * mi,
+				},
+			},
+			expectedStepSizes: map[string]string{
+				"pod-usage-cpu-cores": "1m0s",
+			},
+			expectedCount: 1,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			resp := getImporterRecommendations(tt.memoryLimitBytes, tt.stats)
+			assert.Equal(t, tt.expectedMemoryLimit, resp.RecommendedMemoryLimitBytes, "unexpected memory limit, recommendations: %v", resp.Recommendations)
+			assert.Len(t, resp.Recommendations, tt.expectedCount, "recommendations: %v", resp.Recommendations)
+			assert.Len(t, resp.DataSources, len(tt.stats))
+			for _, ds := range resp.DataSources {
+				assert.Equal(t, tt.expectedChunkSizes[ds.Name], ds.RecommendedChunkSize, "unexpected chunk size for %s", ds.Name)
+				assert.Equal(t, tt.expectedStepSizes[ds.Name], ds.RecommendedStepSize, "unexpected step size for %s", ds.Name)
+			}
+		})
+	}
+}
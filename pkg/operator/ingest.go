@@ -0,0 +1,152 @@
+package operator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/klauspost/compress/zstd"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+const (
+	// APIV1PrometheusIngestEndpoint accepts batches of PrometheusMetric
+	// rows as JSON lines, optionally compressed with zstd, to store in a
+	// ReportDataSource's table.
+	APIV1PrometheusIngestEndpoint = "/api/v1/datasources/prometheus/ingest"
+
+	// maxIngestBatchSize is the largest decompressed batch accepted by the
+	// ingestion endpoint.
+	maxIngestBatchSize = 256 << 20
+)
+
+type IngestPromsumDataResponse struct {
+	Rows int `json:"rows"`
+}
+
+func (srv *server) ingestPromsumDataHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+
+	if r.Method != http.MethodPost {
+		writeErrorResponse(logger, w, r, http.StatusMethodNotAllowed, "method %s not allowed, use POST", r.Method)
+		return
+	}
+
+	name := chi.URLParam(r, "datasourceName")
+	dataSource, err := srv.listers.reportDataSources.Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			writeErrorResponse(logger, w, r, http.StatusNotFound, "ReportDataSource %s not found", name)
+			return
+		}
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to get ReportDataSource %s: %v", name, err)
+		return
+	}
+	if dataSource.Spec.Promsum == nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "ReportDataSource %s is not a Prometheus metrics ReportDataSource, only Prometheus metrics tables can be ingested into", name)
+		return
+	}
+	if dataSource.TableName == "" {
+		writeErrorResponse(logger, w, r, http.StatusConflict, "the table for ReportDataSource %s has not been created yet", name)
+		return
+	}
+
+	body, err := newIngestBodyReader(r)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusUnsupportedMediaType, "%v", err)
+		return
+	}
+	defer body.Close()
+
+	metrics, err := decodePrometheusMetricsJSONLines(io.LimitReader(body, maxIngestBatchSize+1))
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "invalid batch: %v", err)
+		return
+	}
+
+	logger.Debugf("storing %d ingested metrics into ReportDataSource %s", len(metrics), name)
+	err = prestostore.StorePrometheusMetrics(context.Background(), srv.importerQueryer, dataSource.TableName, isDatePartitionedDataSource(dataSource), metrics)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to store ingested metrics: %v", err)
+		return
+	}
+
+	writeResponseAsJSON(logger, w, http.StatusOK, IngestPromsumDataResponse{Rows: len(metrics)})
+}
+
+// newIngestBodyReader returns a reader of the request body, decompressing it
+// according to the request's Content-Encoding.
+func newIngestBodyReader(r *http.Request) (io.ReadCloser, error) {
+	switch encoding := strings.ToLower(r.Header.Get("Content-Encoding")); encoding {
+	case "", "identity":
+		return r.Body, nil
+	case "zstd":
+		decoder, err := zstd.NewReader(r.Body, zstd.WithDecoderMaxMemory(maxIngestBatchSize))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read zstd body: %v", err)
+		}
+		return zstdReadCloser{decoder}, nil
+	default:
+		return nil, fmt.Errorf("unsupported Content-Encoding %s, must be zstd or identity", encoding)
+	}
+}
+
+// zstdReadCloser adapts zstd.Decoder's Close, which doesn't return an
+// error, to io.ReadCloser.
+type zstdReadCloser struct {
+	*zstd.Decoder
+}
+
+func (z zstdReadCloser) Close() error {
+	z.Decoder.Close()
+	return nil
+}
+
+// decodePrometheusMetricsJSONLines decodes and validates a batch of
+// PrometheusMetrics with one JSON object per line. Blank lines are ignored,
+// and fields which aren't part of the Prometheus metrics table schema are
+// rejected. The whole batch is validated before any of it is stored, so
+// that a bad row doesn't leave a partially stored batch.
+func decodePrometheusMetricsJSONLines(r io.Reader) ([]*prestostore.PrometheusMetric, error) {
+	var metrics []*prestostore.PrometheusMetric
+	reader := bufio.NewReader(r)
+	size := 0
+	for line := 1; ; line++ {
+		data, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return nil, fmt.Errorf("error reading line %d: %v", line, readErr)
+		}
+		size += len(data)
+		if size > maxIngestBatchSize {
+			return nil, fmt.Errorf("batch exceeds the maximum size of %d bytes", maxIngestBatchSize)
+		}
+
+		if data = bytes.TrimSpace(data); len(data) != 0 {
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.DisallowUnknownFields()
+			var metric prestostore.PrometheusMetric
+			if err := decoder.Decode(&metric); err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			if decoder.More() {
+				return nil, fmt.Errorf("line %d: expected one JSON object per line", line)
+			}
+			if err := prestostore.ValidatePrometheusMetric(&metric); err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			metrics = append(metrics, &metric)
+		}
+
+		if readErr == io.EOF {
+			return metrics, nil
+		}
+	}
+}
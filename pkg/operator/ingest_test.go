@@ -0,0 +1,97 @@
+package operator
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+func TestDecodePrometheusMetricsJSONLines(t *testing.T) {
+	timestamp := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		body            string
+		expectedMetrics []*prestostore.PrometheusMetric
+		expectErr       bool
+	}{
+		"multiple rows and blank lines": {
+			body: `{"labels":{"namespace":"web"},"amount":1.5,"stepSize":60000000000,"timestamp":"2018-07-01T00:00:00Z"}
+
+{"labels":{"namespace":"db"},"amount":2,"stepSize":60000000000,"timestamp":"2018-07-01T00:00:00Z"}
+`,
+			expectedMetrics: []*prestostore.PrometheusMetric{
+				{Labels: map[string]string{"namespace": "web"}, Amount: 1.5, StepSize: time.Minute, Timestamp: timestamp},
+				{Labels: map[string]string{"namespace": "db"}, Amount: 2, StepSize: time.Minute, Timestamp: timestamp},
+			},
+		},
+		"no trailing newline": {
+			body: `{"amount":1,"timestamp":"2018-07-01T00:00:00Z"}`,
+			expectedMetrics: []*prestostore.PrometheusMetric{
+				{Amount: 1, Timestamp: timestamp},
+			},
+		},
+		"field not in schema": {
+			body:      `{"amount":1,"timestamp":"2018-07-01T00:00:00Z","node":"node-1"}`,
+			expectErr: true,
+		},
+		"missing timestamp": {
+			body:      `{"amount":1}`,
+			expectErr: true,
+		},
+		"multiple objects on one line": {
+			body:      `{"amount":1,"timestamp":"2018-07-01T00:00:00Z"} {"amount":2,"timestamp":"2018-07-01T00:00:00Z"}`,
+			expectErr: true,
+		},
+		"invalid json": {
+			body:      `{"amount":`,
+			expectErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			metrics, err := decodePrometheusMetricsJSONLines(strings.NewReader(tt.body))
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedMetrics, metrics)
+		})
+	}
+}
+
+func TestNewIngestBodyReader(t *testing.T) {
+	const body = `{"amount":1,"timestamp":"2018-07-01T00:00:00Z"}` + "\n"
+
+	var compressed bytes.Buffer
+	encoder, err := zstd.NewWriter(&compressed)
+	require.NoError(t, err)
+	_, err = encoder.Write([]byte(body))
+	require.NoError(t, err)
+	require.NoError(t, encoder.Close())
+
+	req := httptest.NewRequest("POST", APIV1PrometheusIngestEndpoint+"/pod-cpu", &compressed)
+	req.Header.Set("Content-Encoding", "zstd")
+	reader, err := newIngestBodyReader(req)
+	require.NoError(t, err)
+	defer reader.Close()
+	decompressed, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(decompressed))
+
+	req = httptest.NewRequest("POST", APIV1PrometheusIngestEndpoint+"/pod-cpu", strings.NewReader(body))
+	req.Header.Set("Content-Encoding", "br")
+	_, err = newIngestBodyReader(req)
+	assert.Error(t, err, "unsupported encodings should be rejected")
+}
@@ -0,0 +1,227 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/sirupsen/logrus"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/secrets"
+	"github.com/operator-framework/operator-metering/pkg/util/orderedmap"
+)
+
+const (
+	kafkaClientID = "reporting-operator"
+	// kafkaMaxBatchMessages is the most messages sent to the producer at
+	// once, bounding how many encoded messages are held in memory.
+	kafkaMaxBatchMessages = 1000
+)
+
+// kafkaPublisher publishes imported metrics and the results of reports to
+// Kafka topics.
+type kafkaPublisher struct {
+	logger       logrus.FieldLogger
+	metricsTopic string
+	reportsTopic string
+	// newProducer creates a producer using the current credentials.
+	newProducer func() (sarama.SyncProducer, error)
+
+	mu       sync.RWMutex
+	producer sarama.SyncProducer
+}
+
+func (op *Reporting) newKafkaPublisher(ctx context.Context) (*kafkaPublisher, error) {
+	var ref secrets.Ref
+	if op.cfg.SecretsConfig.KafkaCredentials != "" {
+		var err error
+		ref, err = secrets.ParseRef(op.cfg.SecretsConfig.KafkaCredentials)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Kafka credentials: %v", err)
+		}
+	}
+	p := &kafkaPublisher{
+		logger:       op.logger.WithField("component", "kafkaPublisher"),
+		metricsTopic: op.cfg.KafkaConfig.MetricsTopic,
+		reportsTopic: op.cfg.KafkaConfig.ReportsTopic,
+		newProducer: func() (sarama.SyncProducer, error) {
+			cfg := sarama.NewConfig()
+			cfg.ClientID = kafkaClientID
+			cfg.Version = sarama.V0_10_2_0
+			cfg.Producer.RequiredAcks = sarama.WaitForAll
+			cfg.Producer.Retry.Max = 5
+			// required by the SyncProducer
+			cfg.Producer.Return.Successes = true
+			cfg.Net.TLS.Enable = op.cfg.KafkaConfig.UseTLS
+
+			if ref.Provider != "" {
+				secret, err := op.secretResolver.GetSecret(ctx, ref)
+				if err != nil {
+					return nil, err
+				}
+				cfg.Net.SASL.Enable = true
+				cfg.Net.SASL.User = secret.Data[secrets.UsernameKey]
+				cfg.Net.SASL.Password = secret.Data[secrets.PasswordKey]
+			}
+			return sarama.NewSyncProducer(op.cfg.KafkaConfig.Brokers, cfg)
+		},
+	}
+
+	var err error
+	p.producer, err = p.newProducer()
+	if err != nil {
+		return nil, err
+	}
+	// the producer's credentials can't be changed once it's created, so
+	// it's replaced when they're rotated.
+	if ref.Provider != "" {
+		op.secretResolver.OnChange(ref, func() {
+			go p.reconnect()
+		})
+	}
+	return p, nil
+}
+
+// reconnect replaces the producer with one using the current credentials.
+// If the new producer can't be created, the current producer is kept.
+func (p *kafkaPublisher) reconnect() {
+	producer, err := p.newProducer()
+	if err != nil {
+		p.logger.WithError(err).Error("unable to recreate Kafka producer with rotated credentials, continuing to use the current producer")
+		return
+	}
+	p.mu.Lock()
+	old := p.producer
+	p.producer = producer
+	p.mu.Unlock()
+	if err := old.Close(); err != nil {
+		p.logger.WithError(err).Warn("unable to close previous Kafka producer")
+	}
+	p.logger.Info("recreated Kafka producer with rotated credentials")
+}
+
+func (p *kafkaPublisher) close() error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.producer.Close()
+}
+
+// kafkaMetricMessage is the value of each message published to the metrics
+// topic. The metric is in the format accepted by the storePromsumData API.
+type kafkaMetricMessage struct {
+	ReportDataSource string `json:"reportDataSource"`
+	*prestostore.PrometheusMetric
+}
+
+// publishMetrics publishes each metric to the metrics topic, keyed by the
+// name of the ReportDataSource it was imported for.
+func (p *kafkaPublisher) publishMetrics(dataSourceName string, metrics []*prestostore.PrometheusMetric) error {
+	if p.metricsTopic == "" {
+		return nil
+	}
+	msgs := make([]*sarama.ProducerMessage, 0, len(metrics))
+	for _, metric := range metrics {
+		value, err := json.Marshal(kafkaMetricMessage{
+			ReportDataSource: dataSourceName,
+			PrometheusMetric: metric,
+		})
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, &sarama.ProducerMessage{
+			Topic: p.metricsTopic,
+			Key:   sarama.StringEncoder(dataSourceName),
+			Value: sarama.ByteEncoder(value),
+		})
+	}
+	return p.send(msgs)
+}
+
+// kafkaReportResultMessage is the value of each message published to the
+// reports topic, containing a single row of a report's results.
+type kafkaReportResultMessage struct {
+	Kind        string                 `json:"kind"`
+	Namespace   string                 `json:"namespace"`
+	Name        string                 `json:"name"`
+	PeriodStart time.Time              `json:"periodStart"`
+	PeriodEnd   time.Time              `json:"periodEnd"`
+	Result      *orderedmap.OrderedMap `json:"result"`
+}
+
+// publishReportResults publishes each row of the results to the reports
+// topic, keyed by the namespace and name of the report, so each report's
+// results are published to the same partition.
+func (p *kafkaPublisher) publishReportResults(kind, namespace, name string, periodStart, periodEnd time.Time, results []presto.Row) error {
+	if p.reportsTopic == "" {
+		return nil
+	}
+	key := namespace + "/" + name
+	msgs := make([]*sarama.ProducerMessage, 0, kafkaMaxBatchMessages)
+	for _, row := range results {
+		result, err := orderedmap.NewFromMap(row)
+		if err != nil {
+			return err
+		}
+		value, err := json.Marshal(kafkaReportResultMessage{
+			Kind:        kind,
+			Namespace:   namespace,
+			Name:        name,
+			PeriodStart: periodStart.UTC(),
+			PeriodEnd:   periodEnd.UTC(),
+			Result:      result,
+		})
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, &sarama.ProducerMessage{
+			Topic: p.reportsTopic,
+			Key:   sarama.StringEncoder(key),
+			Value: sarama.ByteEncoder(value),
+		})
+		if len(msgs) == kafkaMaxBatchMessages {
+			if err := p.send(msgs); err != nil {
+				return err
+			}
+			msgs = msgs[:0]
+		}
+	}
+	return p.send(msgs)
+}
+
+func (p *kafkaPublisher) send(msgs []*sarama.ProducerMessage) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	err := p.producer.SendMessages(msgs)
+	if errs, ok := err.(sarama.ProducerErrors); ok && len(errs) != 0 {
+		return fmt.Errorf("failed to publish %d of %d messages: %v", len(errs), len(msgs), errs[0].Err)
+	}
+	return err
+}
+
+// publishReportResultsToKafka publishes the results in the report's table to
+// the reports topic, if it's configured. Publishing is best effort, and
+// failures are logged rather than failing the report, since the results
+// were generated successfully.
+func (op *Reporting) publishReportResultsToKafka(logger logrus.FieldLogger, kind, namespace, name, tableName string, generationQuery *cbTypes.ReportGenerationQuery, groupByLabelKeys []string, periodStart, periodEnd time.Time) {
+	if op.kafkaPublisher == nil || op.kafkaPublisher.reportsTopic == "" {
+		return
+	}
+	_, results, err := op.getDeliveryResults(tableName, generationQuery, groupByLabelKeys)
+	if err == nil {
+		err = op.kafkaPublisher.publishReportResults(kind, namespace, name, periodStart, periodEnd, results)
+	}
+	if err != nil {
+		logger.WithError(err).Errorf("failed to publish report results to Kafka topic %s", op.kafkaPublisher.reportsTopic)
+		return
+	}
+	logger.Infof("published %d report results to Kafka topic %s", len(results), op.kafkaPublisher.reportsTopic)
+}
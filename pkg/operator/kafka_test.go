@@ -0,0 +1,110 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+func TestKafkaPublisherPublishMetrics(t *testing.T) {
+	producer := mocks.NewSyncProducer(t, nil)
+	defer producer.Close()
+	publisher := &kafkaPublisher{producer: producer, metricsTopic: "metering-metrics"}
+
+	timestamp := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	metrics := []*prestostore.PrometheusMetric{
+		{Labels: map[string]string{"namespace": "team-a"}, Amount: 1.5, StepSize: time.Minute, Timestamp: timestamp},
+		{Labels: map[string]string{"namespace": "team-b"}, Amount: 2, StepSize: time.Minute, Timestamp: timestamp},
+	}
+	for _, metric := range metrics {
+		metric := metric
+		producer.ExpectSendMessageWithCheckerFunctionAndSucceed(func(value []byte) error {
+			var msg struct {
+				ReportDataSource string            `json:"reportDataSource"`
+				Labels           map[string]string `json:"labels"`
+				Amount           float64           `json:"amount"`
+				Timestamp        time.Time         `json:"timestamp"`
+			}
+			if err := json.Unmarshal(value, &msg); err != nil {
+				return err
+			}
+			if msg.ReportDataSource != "pod-request-cpu-cores" || msg.Labels["namespace"] != metric.Labels["namespace"] || msg.Amount != metric.Amount || !msg.Timestamp.Equal(timestamp) {
+				return fmt.Errorf("unexpected message %s", value)
+			}
+			return nil
+		})
+	}
+	require.NoError(t, publisher.publishMetrics("pod-request-cpu-cores", metrics))
+}
+
+func TestKafkaPublisherPublishReportResults(t *testing.T) {
+	producer := mocks.NewSyncProducer(t, nil)
+	defer producer.Close()
+	publisher := &kafkaPublisher{producer: producer, reportsTopic: "metering-reports"}
+
+	start := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	results := []presto.Row{
+		{"namespace": "team-a", "total_cost": 75.0},
+		{"namespace": "team-b", "total_cost": 50.0},
+	}
+	producer.ExpectSendMessageWithCheckerFunctionAndSucceed(func(value []byte) error {
+		expected := `{"kind":"ScheduledReport","namespace":"metering","name":"namespace-cost","periodStart":"2018-07-01T00:00:00Z","periodEnd":"2018-08-01T00:00:00Z","result":{"namespace":"team-a","total_cost":75}}`
+		if string(value) != expected {
+			return fmt.Errorf("expected %s, got %s", expected, value)
+		}
+		return nil
+	})
+	producer.ExpectSendMessageAndFail(sarama.ErrNotLeaderForPartition)
+
+	err := publisher.publishReportResults("ScheduledReport", "metering", "namespace-cost", start, end, results)
+	assert.Equal(t, sarama.ErrNotLeaderForPartition, err)
+}
+
+func TestKafkaPublisherTopicsUnset(t *testing.T) {
+	// the mock producer fails the test if any messages are sent
+	producer := mocks.NewSyncProducer(t, nil)
+	defer producer.Close()
+	publisher := &kafkaPublisher{producer: producer}
+
+	assert.NoError(t, publisher.publishMetrics("pod-request-cpu-cores", []*prestostore.PrometheusMetric{{}}))
+	assert.NoError(t, publisher.publishReportResults("Report", "metering", "namespace-cost", time.Now(), time.Now(), []presto.Row{{}}))
+}
+
+func TestKafkaPublisherReconnect(t *testing.T) {
+	old := mocks.NewSyncProducer(t, nil)
+	rotated := mocks.NewSyncProducer(t, nil)
+	defer rotated.Close()
+	newProducerErr := fmt.Errorf("brokers unavailable")
+	publisher := &kafkaPublisher{
+		logger:       testLogger,
+		metricsTopic: "metering-metrics",
+		producer:     old,
+		newProducer: func() (sarama.SyncProducer, error) {
+			return nil, newProducerErr
+		},
+	}
+
+	// the current producer is kept if a new one can't be created
+	publisher.reconnect()
+	assert.Equal(t, old, publisher.producer)
+
+	publisher.newProducer = func() (sarama.SyncProducer, error) {
+		return rotated, nil
+	}
+	publisher.reconnect()
+	assert.Equal(t, rotated, publisher.producer)
+
+	rotated.ExpectSendMessageAndSucceed()
+	err := publisher.publishMetrics("pod-request-cpu-cores", []*prestostore.PrometheusMetric{{Labels: map[string]string{"namespace": "team-a"}, Amount: 1}})
+	assert.NoError(t, err)
+}
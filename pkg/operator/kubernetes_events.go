@@ -0,0 +1,290 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+// kubernetesEventsSeenWindow is how long the events recorded are remembered,
+// so they aren't recorded again while the API server still returns them.
+// Events older than it aren't recorded, so it must be longer than the time
+// events are kept for by the API server.
+const kubernetesEventsSeenWindow = 24 * time.Hour
+
+var (
+	kubernetesEventsHiveColumns = []hive.Column{
+		{Name: "timestamp", Type: "timestamp"},
+		{Name: "kind", Type: "string"},
+		{Name: "namespace", Type: "string"},
+		{Name: "name", Type: "string"},
+		{Name: "uid", Type: "string"},
+		{Name: "reason", Type: "string"},
+		{Name: "message", Type: "string"},
+		{Name: "type", Type: "string"},
+		{Name: "count", Type: "bigint"},
+		{Name: "replicas", Type: "bigint"},
+		{Name: "source", Type: "string"},
+		{Name: "event_uid", Type: "string"},
+		{Name: "cluster_id", Type: "string"},
+	}
+
+	// defaultKubernetesEventsReasons are the reasons of the events recorded
+	// when workloads are scaled, and when pods are created or deleted by
+	// their controllers.
+	defaultKubernetesEventsReasons = []string{"ScalingReplicaSet", "SuccessfulCreate", "SuccessfulDelete", "SuccessfulRescale"}
+
+	// kubernetesEventsReplicasRegexp matches the number of replicas in the
+	// messages of scaling events, such as "Scaled up replica set app-5d8f
+	// to 10" of deployments and "New size: 10; reason: cpu resource
+	// utilization (percentage of request) above target" of horizontal pod
+	// autoscalers.
+	kubernetesEventsReplicasRegexp = regexp.MustCompile(`(?:\bto |New size: )([0-9]+)\b`)
+)
+
+func (op *Reporting) handleKubernetesEventsDataSource(logger logrus.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
+	if interval := op.kubernetesEventsCollectionInterval(dataSource); interval <= 0 {
+		return fmt.Errorf("datasource %q: improperly configured datasource, collectionInterval must be positive, got %s", dataSource.Name, interval)
+	}
+
+	if dataSource.TableName == "" {
+		tableName := dataSourceTableName(dataSource.Name)
+		err := op.createTableForStorage(logger, dataSource, "ReportDataSource", dataSource.Name, dataSource.Spec.KubernetesEvents.Storage, tableName, kubernetesEventsHiveColumns)
+		if err != nil {
+			return err
+		}
+
+		err = op.updateDataSourceTableName(logger, dataSource, tableName)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to update ReportDataSource TableName field %q", tableName)
+			return err
+		}
+	}
+
+	op.kubernetesEventsNewDataSourceQueue <- dataSource
+	return nil
+}
+
+// kubernetesEventsCollectionInterval returns how often the events of a
+// kubernetesEvents ReportDataSource are recorded.
+func (op *Reporting) kubernetesEventsCollectionInterval(dataSource *cbTypes.ReportDataSource) time.Duration {
+	if interval := dataSource.Spec.KubernetesEvents.CollectionInterval; interval != nil {
+		return interval.Duration
+	}
+	return op.cfg.PrometheusQueryConfig.QueryInterval.Duration
+}
+
+func (op *Reporting) runKubernetesEventsCollectorWorker(stopCh <-chan struct{}) {
+	logger := op.logger.WithField("component", "KubernetesEventsCollector")
+	logger.Infof("KubernetesEventsCollector worker started")
+	defer logger.Infof("KubernetesEventsCollector worker shutdown")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	workers := make(map[string]*kubernetesEventsCollectorWorker)
+
+	for {
+		select {
+		case <-stopCh:
+			logger.Infof("got shutdown signal, shutting down KubernetesEventsCollectors")
+			return
+		case dataSourceName := <-op.kubernetesEventsDeletedDataSourceQueue:
+			if worker, exists := workers[dataSourceName]; exists {
+				worker.stop()
+				delete(workers, dataSourceName)
+			}
+		case dataSource := <-op.kubernetesEventsNewDataSourceQueue:
+			reasons := dataSource.Spec.KubernetesEvents.Reasons
+			if len(reasons) == 0 {
+				reasons = defaultKubernetesEventsReasons
+			}
+			worker := &kubernetesEventsCollectorWorker{
+				namespace:      dataSource.Namespace,
+				dataSourceName: dataSource.Name,
+				reasons:        reasons,
+				tableName:      dataSource.TableName,
+				interval:       op.kubernetesEventsCollectionInterval(dataSource),
+				stopCh:         make(chan struct{}),
+				doneCh:         make(chan struct{}),
+			}
+			if existing, exists := workers[dataSource.Name]; exists {
+				if reflect.DeepEqual(existing.reasons, worker.reasons) && existing.tableName == worker.tableName && existing.interval == worker.interval {
+					// config hasn't changed skip the update
+					continue
+				}
+				existing.stop()
+			}
+			workers[dataSource.Name] = worker
+
+			dataSourceLogger := logger.WithFields(logrus.Fields{
+				"reportDataSource": dataSource.Name,
+				"tableName":        worker.tableName,
+			})
+			go worker.start(ctx, dataSourceLogger, op)
+		}
+	}
+}
+
+type kubernetesEventsCollectorWorker struct {
+	namespace      string
+	dataSourceName string
+	reasons        []string
+	tableName      string
+	interval       time.Duration
+	stopCh         chan struct{}
+	doneCh         chan struct{}
+}
+
+// start records new events immediately and then every interval. Each
+// occurrence of an event is only recorded once, so the events recorded
+// within the seen window are loaded from the table first, such as after the
+// reporting-operator restarts.
+func (w *kubernetesEventsCollectorWorker) start(ctx context.Context, logger logrus.FieldLogger, op *Reporting) {
+	ticker := time.NewTicker(w.interval)
+	defer close(w.doneCh)
+	defer ticker.Stop()
+
+	logger.Infof("Recording %v events every %s", w.reasons, w.interval)
+	var seen map[string]time.Time
+	for {
+		now := op.clock.Now().UTC()
+		var err error
+		if seen == nil {
+			seen, err = prestostore.GetKubernetesEventKeys(op.importerPrestoQueryer, w.tableName, now.Add(-kubernetesEventsSeenWindow))
+		}
+		if err == nil {
+			err = op.collectKubernetesEvents(ctx, w.tableName, w.reasons, seen, now)
+		}
+		if err != nil {
+			logger.WithError(err).Errorf("error recording events")
+		}
+		if ctx.Err() == nil {
+			op.updateDataSourceImportCondition(logger, w.namespace, w.dataSourceName, err)
+		}
+
+		select {
+		case <-w.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *kubernetesEventsCollectorWorker) stop() {
+	close(w.stopCh)
+	<-w.doneCh
+}
+
+// collectKubernetesEvents stores the events which haven't been seen yet, and
+// adds them to seen once they're stored.
+func (op *Reporting) collectKubernetesEvents(ctx context.Context, tableName string, reasons []string, seen map[string]time.Time, now time.Time) error {
+	list, err := op.kubeClient.Events(meta.NamespaceAll).List(meta.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list events: %v", err)
+	}
+	cutoff := now.Add(-kubernetesEventsSeenWindow)
+	events := newKubernetesEvents(op.cfg.ClusterID, list.Items, reasons, seen, cutoff)
+	err = prestostore.StoreKubernetesEvents(ctx, op.importerPrestoQueryer, tableName, events)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		seen[event.Key()] = event.Timestamp
+	}
+	for key, timestamp := range seen {
+		if timestamp.Before(cutoff) {
+			delete(seen, key)
+		}
+	}
+	return nil
+}
+
+// newKubernetesEvents converts the events with one of the reasons which
+// occurred at or after cutoff, and haven't been seen yet, into rows for a
+// kubernetesEvents ReportDataSource's table.
+func newKubernetesEvents(clusterID string, events []v1.Event, reasons []string, seen map[string]time.Time, cutoff time.Time) []*prestostore.KubernetesEvent {
+	var newEvents []*prestostore.KubernetesEvent
+	for _, event := range events {
+		if !isKubernetesEventsReason(reasons, event.Reason) {
+			continue
+		}
+		timestamp := kubernetesEventTimestamp(event)
+		if timestamp.Before(cutoff) {
+			continue
+		}
+		newEvent := &prestostore.KubernetesEvent{
+			Timestamp: timestamp,
+			Kind:      event.InvolvedObject.Kind,
+			Namespace: event.InvolvedObject.Namespace,
+			Name:      event.InvolvedObject.Name,
+			UID:       string(event.InvolvedObject.UID),
+			Reason:    event.Reason,
+			Message:   event.Message,
+			Type:      event.Type,
+			Count:     event.Count,
+			Replicas:  kubernetesEventReplicas(event),
+			Source:    event.Source.Component,
+			EventUID:  string(event.UID),
+			ClusterID: clusterID,
+		}
+		if event.ReportingController != "" {
+			newEvent.Source = event.ReportingController
+		}
+		if _, exists := seen[newEvent.Key()]; exists {
+			continue
+		}
+		newEvents = append(newEvents, newEvent)
+	}
+	return newEvents
+}
+
+func isKubernetesEventsReason(reasons []string, reason string) bool {
+	for _, r := range reasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// kubernetesEventTimestamp returns when the event last occurred.
+func kubernetesEventTimestamp(event v1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.UTC()
+	case !event.EventTime.IsZero():
+		return event.EventTime.UTC()
+	default:
+		return event.CreationTimestamp.UTC()
+	}
+}
+
+// kubernetesEventReplicas returns the number of replicas a workload was
+// scaled to by a scaling event, or nil if the event isn't a scaling event.
+func kubernetesEventReplicas(event v1.Event) *int64 {
+	if event.Reason != "ScalingReplicaSet" && event.Reason != "SuccessfulRescale" {
+		return nil
+	}
+	match := kubernetesEventsReplicasRegexp.FindStringSubmatch(event.Message)
+	if match == nil {
+		return nil
+	}
+	replicas, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &replicas
+}
@@ -0,0 +1,126 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+func TestNewKubernetesEvents(t *testing.T) {
+	cutoff := time.Date(2018, time.June, 30, 0, 0, 0, 0, time.UTC)
+	timestamp := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	deployment := v1.ObjectReference{Kind: "Deployment", Namespace: "team-a", Name: "app", UID: "f2b4b4c1"}
+	hpa := v1.ObjectReference{Kind: "HorizontalPodAutoscaler", Namespace: "team-a", Name: "app", UID: "6e1d2a9b"}
+
+	events := []v1.Event{
+		{
+			ObjectMeta:     meta.ObjectMeta{UID: "8a7c5e3a"},
+			InvolvedObject: deployment,
+			Reason:         "ScalingReplicaSet",
+			Message:        "Scaled up replica set app-5d8f to 10",
+			Type:           v1.EventTypeNormal,
+			Count:          2,
+			Source:         v1.EventSource{Component: "deployment-controller"},
+			LastTimestamp:  meta.NewTime(timestamp),
+		},
+		{
+			ObjectMeta:          meta.ObjectMeta{UID: "3b8f6c2d"},
+			InvolvedObject:      hpa,
+			Reason:              "SuccessfulRescale",
+			Message:             "New size: 4; reason: cpu resource utilization (percentage of request) above target",
+			Type:                v1.EventTypeNormal,
+			Count:               1,
+			ReportingController: "horizontal-pod-autoscaler",
+			EventTime:           meta.NewMicroTime(timestamp),
+		},
+		// already recorded
+		{
+			ObjectMeta:     meta.ObjectMeta{UID: "9c4e7a1f"},
+			InvolvedObject: deployment,
+			Reason:         "ScalingReplicaSet",
+			Message:        "Scaled down replica set app-5d8f to 8",
+			Count:          1,
+			LastTimestamp:  meta.NewTime(timestamp),
+		},
+		// not one of the reasons
+		{
+			ObjectMeta:     meta.ObjectMeta{UID: "1f2e3d4c"},
+			InvolvedObject: deployment,
+			Reason:         "FailedCreate",
+			Count:          1,
+			LastTimestamp:  meta.NewTime(timestamp),
+		},
+		// before the cutoff
+		{
+			ObjectMeta:     meta.ObjectMeta{UID: "7d6c5b4a"},
+			InvolvedObject: deployment,
+			Reason:         "SuccessfulCreate",
+			Count:          1,
+			LastTimestamp:  meta.NewTime(cutoff.Add(-time.Minute)),
+		},
+	}
+	seen := map[string]time.Time{
+		prestostore.KubernetesEventKey("9c4e7a1f", 1): timestamp,
+	}
+
+	newEvents := newKubernetesEvents("us-east", events, defaultKubernetesEventsReasons, seen, cutoff)
+	require.Len(t, newEvents, 2)
+
+	replicas := int64(10)
+	assert.Equal(t, &prestostore.KubernetesEvent{
+		Timestamp: timestamp,
+		Kind:      "Deployment",
+		Namespace: "team-a",
+		Name:      "app",
+		UID:       "f2b4b4c1",
+		Reason:    "ScalingReplicaSet",
+		Message:   "Scaled up replica set app-5d8f to 10",
+		Type:      v1.EventTypeNormal,
+		Count:     2,
+		Replicas:  &replicas,
+		Source:    "deployment-controller",
+		EventUID:  "8a7c5e3a",
+		ClusterID: "us-east",
+	}, newEvents[0])
+
+	assert.Equal(t, "HorizontalPodAutoscaler", newEvents[1].Kind)
+	assert.Equal(t, timestamp, newEvents[1].Timestamp)
+	require.NotNil(t, newEvents[1].Replicas)
+	assert.Equal(t, int64(4), *newEvents[1].Replicas)
+	assert.Equal(t, "horizontal-pod-autoscaler", newEvents[1].Source)
+}
+
+func TestKubernetesEventReplicas(t *testing.T) {
+	ten, three := int64(10), int64(3)
+	tests := map[string]struct {
+		event    v1.Event
+		expected *int64
+	}{
+		"scaled up": {
+			event:    v1.Event{Reason: "ScalingReplicaSet", Message: "Scaled up replica set app-5d8f to 10"},
+			expected: &ten,
+		},
+		"rescaled": {
+			event:    v1.Event{Reason: "SuccessfulRescale", Message: "New size: 3; reason: All metrics below target"},
+			expected: &three,
+		},
+		"not a scaling event": {
+			event: v1.Event{Reason: "SuccessfulCreate", Message: "Created pod: app-5d8f-x9k2"},
+		},
+		"unknown message": {
+			event: v1.Event{Reason: "ScalingReplicaSet", Message: "Scaled replica set app-5d8f"},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, kubernetesEventReplicas(test.event))
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+var (
+	kubernetesObjectsHiveColumns = []hive.Column{
+		{Name: "timestamp", Type: "timestamp"},
+		{Name: "timeprecision", Type: "double"},
+		{Name: "kind", Type: "string"},
+		{Name: "namespace", Type: "string"},
+		{Name: "name", Type: "string"},
+		{Name: "uid", Type: "string"},
+		{Name: "labels", Type: "map<string, string>"},
+		{Name: "annotations", Type: "map<string, string>"},
+		{Name: "owner_kind", Type: "string"},
+		{Name: "owner_name", Type: "string"},
+		{Name: "owner_uid", Type: "string"},
+		{Name: "creation_timestamp", Type: "timestamp"},
+		{Name: "cluster_id", Type: "string"},
+	}
+
+	// kubernetesObjectsKinds are the kinds of objects kubernetesObjects
+	// ReportDataSources can snapshot.
+	kubernetesObjectsKinds = []string{"Namespace", "Pod", "ReplicaSet", "Deployment", "StatefulSet", "DaemonSet", "Job", "CronJob"}
+)
+
+func (op *Reporting) handleKubernetesObjectsDataSource(logger logrus.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
+	kind := dataSource.Spec.KubernetesObjects.Kind
+	if !isKubernetesObjectsKind(kind) {
+		return fmt.Errorf("datasource %q: improperly configured datasource, kind %q must be one of %s", dataSource.Name, kind, strings.Join(kubernetesObjectsKinds, ", "))
+	}
+	if interval := op.kubernetesObjectsCollectionInterval(dataSource); interval <= 0 {
+		return fmt.Errorf("datasource %q: improperly configured datasource, collectionInterval must be positive, got %s", dataSource.Name, interval)
+	}
+
+	if dataSource.TableName == "" {
+		tableName := dataSourceTableName(dataSource.Name)
+		err := op.createTableForStorage(logger, dataSource, "ReportDataSource", dataSource.Name, dataSource.Spec.KubernetesObjects.Storage, tableName, kubernetesObjectsHiveColumns)
+		if err != nil {
+			return err
+		}
+
+		err = op.updateDataSourceTableName(logger, dataSource, tableName)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to update ReportDataSource TableName field %q", tableName)
+			return err
+		}
+	}
+
+	op.kubernetesObjectsNewDataSourceQueue <- dataSource
+	return nil
+}
+
+func isKubernetesObjectsKind(kind string) bool {
+	for _, k := range kubernetesObjectsKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// kubernetesObjectsCollectionInterval returns how often the objects of a
+// kubernetesObjects ReportDataSource are snapshotted.
+func (op *Reporting) kubernetesObjectsCollectionInterval(dataSource *cbTypes.ReportDataSource) time.Duration {
+	if interval := dataSource.Spec.KubernetesObjects.CollectionInterval; interval != nil {
+		return interval.Duration
+	}
+	return op.cfg.PrometheusQueryConfig.QueryInterval.Duration
+}
+
+func (op *Reporting) runKubernetesObjectsCollectorWorker(stopCh <-chan struct{}) {
+	logger := op.logger.WithField("component", "KubernetesObjectsCollector")
+	logger.Infof("KubernetesObjectsCollector worker started")
+	defer logger.Infof("KubernetesObjectsCollector worker shutdown")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	workers := make(map[string]*kubernetesObjectsCollectorWorker)
+
+	for {
+		select {
+		case <-stopCh:
+			logger.Infof("got shutdown signal, shutting down KubernetesObjectsCollectors")
+			return
+		case dataSourceName := <-op.kubernetesObjectsDeletedDataSourceQueue:
+			if worker, exists := workers[dataSourceName]; exists {
+				worker.stop()
+				delete(workers, dataSourceName)
+			}
+		case dataSource := <-op.kubernetesObjectsNewDataSourceQueue:
+			worker := &kubernetesObjectsCollectorWorker{
+				namespace:      dataSource.Namespace,
+				dataSourceName: dataSource.Name,
+				kind:           dataSource.Spec.KubernetesObjects.Kind,
+				tableName:      dataSource.TableName,
+				interval:       op.kubernetesObjectsCollectionInterval(dataSource),
+				stopCh:         make(chan struct{}),
+				doneCh:         make(chan struct{}),
+			}
+			if existing, exists := workers[dataSource.Name]; exists {
+				if existing.kind == worker.kind && existing.tableName == worker.tableName && existing.interval == worker.interval {
+					// config hasn't changed skip the update
+					continue
+				}
+				existing.stop()
+			}
+			workers[dataSource.Name] = worker
+
+			dataSourceLogger := logger.WithFields(logrus.Fields{
+				"reportDataSource": dataSource.Name,
+				"tableName":        worker.tableName,
+				"kind":             worker.kind,
+			})
+			go worker.start(ctx, dataSourceLogger, op)
+		}
+	}
+}
+
+type kubernetesObjectsCollectorWorker struct {
+	namespace      string
+	dataSourceName string
+	kind           string
+	tableName      string
+	interval       time.Duration
+	stopCh         chan struct{}
+	doneCh         chan struct{}
+}
+
+// start snapshots the objects immediately and then every interval. The
+// snapshot timestamps are truncated to the interval, so a snapshot which
+// was already stored, such as after the reporting-operator restarts, isn't
+// stored again.
+func (w *kubernetesObjectsCollectorWorker) start(ctx context.Context, logger logrus.FieldLogger, op *Reporting) {
+	ticker := time.NewTicker(w.interval)
+	defer close(w.doneCh)
+	defer ticker.Stop()
+
+	logger.Infof("Collecting %s objects every %s", w.kind, w.interval)
+	var lastTimestamp time.Time
+	if last, err := prestostore.GetLastTimestampForTable(op.importerPrestoQueryer, w.tableName); err != nil {
+		logger.WithError(err).Warnf("unable to get the last collection time")
+	} else if last != nil {
+		lastTimestamp = *last
+	}
+
+	for {
+		timestamp := op.clock.Now().UTC().Truncate(w.interval)
+		if timestamp.After(lastTimestamp) {
+			err := op.collectKubernetesObjects(ctx, w.kind, w.tableName, timestamp, w.interval)
+			if err != nil {
+				logger.WithError(err).Errorf("error collecting %s objects", w.kind)
+			} else {
+				lastTimestamp = timestamp
+			}
+			if ctx.Err() == nil {
+				op.updateDataSourceImportCondition(logger, w.namespace, w.dataSourceName, err)
+			}
+		}
+
+		select {
+		case <-w.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *kubernetesObjectsCollectorWorker) stop() {
+	close(w.stopCh)
+	<-w.doneCh
+}
+
+func (op *Reporting) collectKubernetesObjects(ctx context.Context, kind, tableName string, timestamp time.Time, interval time.Duration) error {
+	objects, err := op.listKubernetesObjects(kind)
+	if err != nil {
+		return fmt.Errorf("unable to list %s objects: %v", kind, err)
+	}
+	return prestostore.StoreKubernetesObjects(ctx, op.importerPrestoQueryer, tableName, kubernetesObjectsSnapshot(op.cfg.ClusterID, kind, objects, timestamp, interval))
+}
+
+// listKubernetesObjects lists the objects of kind in all namespaces.
+func (op *Reporting) listKubernetesObjects(kind string) ([]meta.Object, error) {
+	var objects []meta.Object
+	listOptions := meta.ListOptions{}
+	switch kind {
+	case "Namespace":
+		list, err := op.kubeClient.Namespaces().List(listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "Pod":
+		list, err := op.kubeClient.Pods(meta.NamespaceAll).List(listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "ReplicaSet":
+		list, err := op.appsClient.ReplicaSets(meta.NamespaceAll).List(listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "Deployment":
+		list, err := op.appsClient.Deployments(meta.NamespaceAll).List(listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "StatefulSet":
+		list, err := op.appsClient.StatefulSets(meta.NamespaceAll).List(listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "DaemonSet":
+		list, err := op.appsClient.DaemonSets(meta.NamespaceAll).List(listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "Job":
+		list, err := op.batchClient.Jobs(meta.NamespaceAll).List(listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "CronJob":
+		list, err := op.cronJobClient.CronJobs(meta.NamespaceAll).List(listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported kind %s", kind)
+	}
+	return objects, nil
+}
+
+// kubernetesObjectsSnapshot converts the objects into rows for a
+// kubernetesObjects ReportDataSource's table, recording each object's
+// controller as its owner. The last applied configuration annotation kubectl
+// adds is dropped, since it contains the whole object.
+func kubernetesObjectsSnapshot(clusterID, kind string, objects []meta.Object, timestamp time.Time, stepSize time.Duration) []*prestostore.KubernetesObject {
+	snapshot := make([]*prestostore.KubernetesObject, len(objects))
+	for i, obj := range objects {
+		object := &prestostore.KubernetesObject{
+			Timestamp:         timestamp,
+			StepSize:          stepSize,
+			Kind:              kind,
+			Namespace:         obj.GetNamespace(),
+			Name:              obj.GetName(),
+			UID:               string(obj.GetUID()),
+			Labels:            obj.GetLabels(),
+			Annotations:       make(map[string]string, len(obj.GetAnnotations())),
+			CreationTimestamp: obj.GetCreationTimestamp().UTC(),
+			ClusterID:         clusterID,
+		}
+		for key, value := range obj.GetAnnotations() {
+			if key != v1.LastAppliedConfigAnnotation {
+				object.Annotations[key] = value
+			}
+		}
+		if owner := meta.GetControllerOf(obj); owner != nil {
+			object.OwnerKind = owner.Kind
+			object.OwnerName = owner.Name
+			object.OwnerUID = string(owner.UID)
+		}
+		snapshot[i] = object
+	}
+	return snapshot
+}
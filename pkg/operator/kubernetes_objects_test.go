@@ -0,0 +1,63 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+func TestKubernetesObjectsSnapshot(t *testing.T) {
+	controller := true
+	created := time.Date(2018, time.June, 30, 12, 0, 0, 0, time.UTC)
+	timestamp := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+
+	objects := []meta.Object{
+		&v1.Pod{
+			ObjectMeta: meta.ObjectMeta{
+				Namespace:         "team-a",
+				Name:              "app-1",
+				UID:               "8a7c5e3a",
+				Labels:            map[string]string{"app": "app"},
+				Annotations:       map[string]string{"owner": "team-a", v1.LastAppliedConfigAnnotation: "{}"},
+				CreationTimestamp: meta.NewTime(created),
+				OwnerReferences: []meta.OwnerReference{
+					{Kind: "Node", Name: "node-1", UID: "2c6a1f0e"},
+					{Kind: "ReplicaSet", Name: "app", UID: "f2b4b4c1", Controller: &controller},
+				},
+			},
+		},
+		&v1.Pod{
+			ObjectMeta: meta.ObjectMeta{
+				Namespace:         "team-a",
+				Name:              "standalone",
+				UID:               "5d9e0b7f",
+				CreationTimestamp: meta.NewTime(created),
+			},
+		},
+	}
+
+	snapshot := kubernetesObjectsSnapshot("us-east", "Pod", objects, timestamp, 5*time.Minute)
+	require.Len(t, snapshot, 2)
+	assert.Equal(t, &prestostore.KubernetesObject{
+		Timestamp:         timestamp,
+		StepSize:          5 * time.Minute,
+		Kind:              "Pod",
+		Namespace:         "team-a",
+		Name:              "app-1",
+		UID:               "8a7c5e3a",
+		Labels:            map[string]string{"app": "app"},
+		Annotations:       map[string]string{"owner": "team-a"},
+		OwnerKind:         "ReplicaSet",
+		OwnerName:         "app",
+		OwnerUID:          "f2b4b4c1",
+		CreationTimestamp: created,
+		ClusterID:         "us-east",
+	}, snapshot[0], "the controller should be the owner, and the last applied configuration should be dropped")
+	assert.Equal(t, "", snapshot[1].OwnerKind, "objects without a controller should have no owner")
+}
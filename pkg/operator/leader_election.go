@@ -0,0 +1,106 @@
+package operator
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+const leaderElectionLockName = "reporting-operator-leader-lease"
+
+var (
+	errLeaderLockReleased = errors.New("leader election lock has been released")
+	errNotLeader          = errors.New("this reporting-operator replica is not the leader, only the leader imports metrics")
+
+	leaderGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "metering",
+			Name:      "reporting_operator_leader",
+			Help:      "1 if this reporting-operator replica holds the leader election lock and is running the workers and importers, 0 otherwise.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(leaderGauge)
+}
+
+// configMapLockClient is the part of the ConfigMap client used to release
+// the leader election lock.
+type configMapLockClient interface {
+	Get(name string, options meta.GetOptions) (*v1.ConfigMap, error)
+	Delete(name string, options *meta.DeleteOptions) error
+}
+
+// releasableLock wraps the ConfigMap leader election lock so the leader can
+// give it up when it shuts down. Standby replicas wait for a full lease
+// duration after observing any change to the lock before taking it over, so
+// instead of expiring the lease the lock is deleted, which lets a standby
+// create it on its next retry.
+type releasableLock struct {
+	resourcelock.Interface
+	client configMapLockClient
+	name   string
+
+	mu       sync.Mutex
+	released bool
+}
+
+func newReleasableLock(lock resourcelock.Interface, client configMapLockClient, name string) *releasableLock {
+	return &releasableLock{Interface: lock, client: client, name: name}
+}
+
+func (l *releasableLock) Create(ler resourcelock.LeaderElectionRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.released {
+		return errLeaderLockReleased
+	}
+	return l.Interface.Create(ler)
+}
+
+func (l *releasableLock) Update(ler resourcelock.LeaderElectionRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.released {
+		return errLeaderLockReleased
+	}
+	return l.Interface.Update(ler)
+}
+
+// release deletes the lock if this replica holds it, and returns true if it
+// was deleted. Once released, the lock can't be acquired or renewed again.
+func (l *releasableLock) release() (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.released = true
+
+	cm, err := l.client.Get(l.name, meta.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	var record resourcelock.LeaderElectionRecord
+	if err := json.Unmarshal([]byte(cm.Annotations[resourcelock.LeaderElectionRecordAnnotationKey]), &record); err != nil {
+		return false, err
+	}
+	if record.HolderIdentity != l.Identity() {
+		return false, nil
+	}
+	// the UID precondition ensures a lock created by another replica since
+	// the Get isn't deleted.
+	uid := cm.UID
+	err = l.client.Delete(l.name, &meta.DeleteOptions{Preconditions: &meta.Preconditions{UID: &uid}})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
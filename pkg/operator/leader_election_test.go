@@ -0,0 +1,98 @@
+package operator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+type fakeResourceLock struct {
+	resourcelock.Interface
+	identity string
+	updates  int
+}
+
+func (l *fakeResourceLock) Identity() string { return l.identity }
+
+func (l *fakeResourceLock) Update(resourcelock.LeaderElectionRecord) error {
+	l.updates++
+	return nil
+}
+
+type fakeConfigMapLockClient struct {
+	cm      *v1.ConfigMap
+	deleted bool
+}
+
+func (c *fakeConfigMapLockClient) Get(name string, _ meta.GetOptions) (*v1.ConfigMap, error) {
+	if c.cm == nil || c.deleted {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, name)
+	}
+	return c.cm, nil
+}
+
+func (c *fakeConfigMapLockClient) Delete(name string, options *meta.DeleteOptions) error {
+	if *options.Preconditions.UID != c.cm.UID {
+		return apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, name, nil)
+	}
+	c.deleted = true
+	return nil
+}
+
+func newLockConfigMap(t *testing.T, holder string) *v1.ConfigMap {
+	record, err := json.Marshal(resourcelock.LeaderElectionRecord{HolderIdentity: holder})
+	require.NoError(t, err)
+	return &v1.ConfigMap{
+		ObjectMeta: meta.ObjectMeta{
+			Name:        leaderElectionLockName,
+			UID:         types.UID("1234"),
+			Annotations: map[string]string{resourcelock.LeaderElectionRecordAnnotationKey: string(record)},
+		},
+	}
+}
+
+func TestReleasableLock(t *testing.T) {
+	tests := map[string]struct {
+		cm               *v1.ConfigMap
+		expectedReleased bool
+	}{
+		"held by this replica": {
+			cm:               newLockConfigMap(t, "reporting-operator-1"),
+			expectedReleased: true,
+		},
+		"held by another replica": {
+			cm:               newLockConfigMap(t, "reporting-operator-2"),
+			expectedReleased: false,
+		},
+		"lock doesn't exist": {
+			expectedReleased: false,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			fakeLock := &fakeResourceLock{identity: "reporting-operator-1"}
+			client := &fakeConfigMapLockClient{cm: test.cm}
+			lock := newReleasableLock(fakeLock, client, leaderElectionLockName)
+
+			require.NoError(t, lock.Update(resourcelock.LeaderElectionRecord{}))
+			released, err := lock.release()
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedReleased, released)
+			assert.Equal(t, test.expectedReleased, client.deleted)
+
+			assert.Equal(t, errLeaderLockReleased, lock.Update(resourcelock.LeaderElectionRecord{}), "a released lock shouldn't be renewed")
+			assert.Equal(t, errLeaderLockReleased, lock.Create(resourcelock.LeaderElectionRecord{}), "a released lock shouldn't be recreated")
+			assert.Equal(t, 1, fakeLock.updates)
+		})
+	}
+}
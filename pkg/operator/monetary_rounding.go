@@ -0,0 +1,273 @@
+package operator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	// CurrencyColumnUnit is the unit of ReportGenerationQuery columns
+	// containing monetary values, which are rounded according to the
+	// MonetaryRoundingConfig.
+	CurrencyColumnUnit = "currency"
+
+	DefaultMonetaryDecimalPlaces = 2
+
+	// monetaryRoundingMaxDecimalPlaces is the most decimal places monetary
+	// values can be rounded to, and the scale values are converted to
+	// before they're rounded, so doubles which can't be represented
+	// exactly, such as 2.675, are rounded as the decimal they were meant to
+	// be rather than the binary fraction closest to it.
+	monetaryRoundingMaxDecimalPlaces = 12
+)
+
+// MonetaryRoundingMode is how monetary values are rounded to the configured
+// number of decimal places. Every mode rounds the magnitude of the value, so
+// negative values are rounded the same way as positive ones.
+type MonetaryRoundingMode string
+
+const (
+	// MonetaryRoundingDisabled leaves monetary values unrounded.
+	MonetaryRoundingDisabled MonetaryRoundingMode = ""
+	// MonetaryRoundingHalfUp rounds ties away from zero.
+	MonetaryRoundingHalfUp MonetaryRoundingMode = "halfUp"
+	// MonetaryRoundingHalfDown rounds ties towards zero.
+	MonetaryRoundingHalfDown MonetaryRoundingMode = "halfDown"
+	// MonetaryRoundingHalfEven rounds ties to the nearest even digit, also
+	// known as banker's rounding, so ties don't bias sums up or down.
+	MonetaryRoundingHalfEven MonetaryRoundingMode = "halfEven"
+	// MonetaryRoundingUp rounds away from zero.
+	MonetaryRoundingUp MonetaryRoundingMode = "up"
+	// MonetaryRoundingDown rounds towards zero, truncating the value.
+	MonetaryRoundingDown MonetaryRoundingMode = "down"
+)
+
+// MonetaryRoundingConfig configures rounding the monetary values of reports,
+// so reports which should reconcile don't differ by fractions of a cent
+// caused by floating point arithmetic.
+type MonetaryRoundingConfig struct {
+	// Mode is how values are rounded. If empty, values aren't rounded.
+	Mode MonetaryRoundingMode
+	// DecimalPlaces is how many decimal places values are rounded to.
+	DecimalPlaces int
+}
+
+func (cfg MonetaryRoundingConfig) Valid() error {
+	switch cfg.Mode {
+	case MonetaryRoundingDisabled:
+		return nil
+	case MonetaryRoundingHalfUp, MonetaryRoundingHalfDown, MonetaryRoundingHalfEven, MonetaryRoundingUp, MonetaryRoundingDown:
+	default:
+		return fmt.Errorf("invalid monetary rounding mode %q, must be one of: %s, %s, %s, %s or %s", cfg.Mode, MonetaryRoundingHalfUp, MonetaryRoundingHalfDown, MonetaryRoundingHalfEven, MonetaryRoundingUp, MonetaryRoundingDown)
+	}
+	if cfg.DecimalPlaces < 0 || cfg.DecimalPlaces > monetaryRoundingMaxDecimalPlaces {
+		return fmt.Errorf("the monetary decimal places must be between 0 and %d, got %d", monetaryRoundingMaxDecimalPlaces, cfg.DecimalPlaces)
+	}
+	return nil
+}
+
+func (cfg MonetaryRoundingConfig) enabled() bool {
+	return cfg.Mode != MonetaryRoundingDisabled
+}
+
+// isMonetaryColumn returns true if the column contains monetary values which
+// can be rounded.
+func isMonetaryColumn(column cbTypes.ReportGenerationQueryColumn) bool {
+	return column.Unit == CurrencyColumnUnit && isFractionalColumnType(column.Type)
+}
+
+// isFractionalColumnType returns true if values of the Hive or Presto column
+// type can have a fractional part.
+func isFractionalColumnType(colType string) bool {
+	switch strings.ToLower(colType) {
+	case "double", "real", "float":
+		return true
+	}
+	return isDecimalColumnType(colType)
+}
+
+// isDecimalColumnType returns true if the Hive or Presto column type is a
+// DECIMAL, whose values are exact.
+func isDecimalColumnType(colType string) bool {
+	colType = strings.ToLower(strings.TrimSpace(colType))
+	return strings.HasPrefix(colType, "decimal") || strings.HasPrefix(colType, "numeric")
+}
+
+// reportColumnsQuery wraps query, rounding the values of its monetary
+// columns and casting the values of its decimal columns to their type, so
+// queries computing doubles can be stored in decimal columns. The query is
+// returned unchanged if it has no columns to round or cast.
+func reportColumnsQuery(cfg MonetaryRoundingConfig, query string, columns []cbTypes.ReportGenerationQueryColumn) string {
+	var wrapped bool
+	selects := make([]string, len(columns))
+	for i, column := range columns {
+		name := `"` + column.Name + `"`
+		switch {
+		case cfg.enabled() && isMonetaryColumn(column):
+			selects[i] = fmt.Sprintf("%s AS %s", monetaryRoundingExpression(cfg, name, column.Type), name)
+		case isDecimalColumnType(column.Type):
+			selects[i] = fmt.Sprintf("CAST(%s AS %s) AS %s", name, column.Type, name)
+		default:
+			selects[i] = name
+			continue
+		}
+		wrapped = true
+	}
+	if !wrapped {
+		return query
+	}
+	return fmt.Sprintf("SELECT %s FROM (%s) AS unrounded", strings.Join(selects, ", "), query)
+}
+
+// monetaryRoundingExpression returns a Presto expression rounding the
+// monetary value of expr. The value is converted to a decimal and rounded
+// using decimal arithmetic, so the result is the double closest to the
+// rounded decimal, and matches roundMonetaryValue. Decimal columns are
+// rounded without being converted to a double, so the result is exact.
+func monetaryRoundingExpression(cfg MonetaryRoundingConfig, expr, colType string) string {
+	value := fmt.Sprintf("CAST(%s AS DECIMAL(38, %d))", expr, monetaryRoundingMaxDecimalPlaces)
+	scaled := fmt.Sprintf("(abs(%s) * DECIMAL '1%s')", value, strings.Repeat("0", cfg.DecimalPlaces))
+	var rounded string
+	switch cfg.Mode {
+	case MonetaryRoundingHalfUp:
+		rounded = fmt.Sprintf("floor(%s + 0.5)", scaled)
+	case MonetaryRoundingHalfDown:
+		rounded = fmt.Sprintf("ceil(%s - 0.5)", scaled)
+	case MonetaryRoundingHalfEven:
+		rounded = fmt.Sprintf("CASE WHEN %[1]s - floor(%[1]s) = 0.5 THEN floor(%[1]s) + mod(floor(%[1]s), 2) ELSE floor(%[1]s + 0.5) END", scaled)
+	case MonetaryRoundingUp:
+		rounded = fmt.Sprintf("ceil(%s)", scaled)
+	case MonetaryRoundingDown:
+		rounded = fmt.Sprintf("floor(%s)", scaled)
+	}
+	if isDecimalColumnType(colType) {
+		unit := "1"
+		if cfg.DecimalPlaces > 0 {
+			unit = "0." + strings.Repeat("0", cfg.DecimalPlaces-1) + "1"
+		}
+		return fmt.Sprintf("CAST(sign(%s) * %s * DECIMAL '%s' AS %s)", value, rounded, unit, colType)
+	}
+	result := fmt.Sprintf("CAST(sign(%s) * %s AS double) / 1E%d", value, rounded, cfg.DecimalPlaces)
+	switch strings.ToLower(colType) {
+	case "double":
+		return result
+	case "real", "float":
+		return fmt.Sprintf("CAST(%s AS real)", result)
+	default:
+		return fmt.Sprintf("CAST(%s AS %s)", result, colType)
+	}
+}
+
+// roundMonetaryValue rounds a monetary value the same way the expression
+// returned by monetaryRoundingExpression does. Values which aren't finite
+// are returned unchanged.
+func roundMonetaryValue(cfg MonetaryRoundingConfig, value float64) float64 {
+	if !cfg.enabled() || math.IsNaN(value) || math.IsInf(value, 0) {
+		return value
+	}
+	// the shortest representation of the double is what Presto converts
+	// it to a decimal from.
+	exact, ok := new(big.Rat).SetString(strconv.FormatFloat(math.Abs(value), 'g', -1, 64))
+	if !ok {
+		return value
+	}
+	decimal := new(big.Rat).SetFrac(roundScaled(exact, monetaryRoundingMaxDecimalPlaces, MonetaryRoundingHalfUp), pow10(monetaryRoundingMaxDecimalPlaces))
+	rounded, _ := new(big.Rat).SetFrac(roundScaled(decimal, cfg.DecimalPlaces, cfg.Mode), pow10(cfg.DecimalPlaces)).Float64()
+	if value < 0 {
+		return -rounded
+	}
+	return rounded
+}
+
+// roundMonetaryDecimal rounds a decimal monetary value the same way the
+// expression returned by monetaryRoundingExpression does, keeping its scale.
+func roundMonetaryDecimal(cfg MonetaryRoundingConfig, value presto.Decimal) presto.Decimal {
+	if !cfg.enabled() || value.Scale() <= cfg.DecimalPlaces {
+		return value
+	}
+	exact := value.Rat()
+	negative := exact.Sign() < 0
+	// values with more than monetaryRoundingMaxDecimalPlaces digits are
+	// first converted to DECIMAL(38, 12), like the Presto expression.
+	if value.Scale() > monetaryRoundingMaxDecimalPlaces {
+		exact = new(big.Rat).SetFrac(roundScaled(new(big.Rat).Abs(exact), monetaryRoundingMaxDecimalPlaces, MonetaryRoundingHalfUp), pow10(monetaryRoundingMaxDecimalPlaces))
+	}
+	rounded := roundScaled(new(big.Rat).Abs(exact), cfg.DecimalPlaces, cfg.Mode)
+	if negative {
+		rounded.Neg(rounded)
+	}
+	result, _ := presto.NewDecimal(rounded, cfg.DecimalPlaces).Rescale(value.Scale())
+	return result
+}
+
+// roundScaled returns the non-negative value multiplied by 10^decimalPlaces
+// and rounded to an integer using mode.
+func roundScaled(value *big.Rat, decimalPlaces int, mode MonetaryRoundingMode) *big.Int {
+	num := new(big.Int).Mul(value.Num(), pow10(decimalPlaces))
+	quo, rem := new(big.Int).QuoRem(num, value.Denom(), new(big.Int))
+	if rem.Sign() == 0 {
+		return quo
+	}
+	// half is negative below the midpoint, 0 at it and positive above it.
+	half := new(big.Int).Lsh(rem, 1).Cmp(value.Denom())
+	var roundUp bool
+	switch mode {
+	case MonetaryRoundingHalfUp:
+		roundUp = half >= 0
+	case MonetaryRoundingHalfDown:
+		roundUp = half > 0
+	case MonetaryRoundingHalfEven:
+		roundUp = half > 0 || (half == 0 && quo.Bit(0) == 1)
+	case MonetaryRoundingUp:
+		roundUp = true
+	}
+	if roundUp {
+		quo.Add(quo, big.NewInt(1))
+	}
+	return quo
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// roundMonetaryResults returns results with the values of their monetary
+// columns rounded. The rows are copied rather than modified, since they may
+// be cached.
+func roundMonetaryResults(cfg MonetaryRoundingConfig, columns []cbTypes.ReportGenerationQueryColumn, results []presto.Row) []presto.Row {
+	if !cfg.enabled() {
+		return results
+	}
+	var monetaryColumns []string
+	for _, column := range columns {
+		if isMonetaryColumn(column) {
+			monetaryColumns = append(monetaryColumns, column.Name)
+		}
+	}
+	if len(monetaryColumns) == 0 {
+		return results
+	}
+	rounded := make([]presto.Row, len(results))
+	for i, row := range results {
+		newRow := make(presto.Row, len(row))
+		for name, value := range row {
+			newRow[name] = value
+		}
+		for _, name := range monetaryColumns {
+			switch value := row[name].(type) {
+			case float64:
+				newRow[name] = roundMonetaryValue(cfg, value)
+			case presto.Decimal:
+				newRow[name] = roundMonetaryDecimal(cfg, value)
+			}
+		}
+		rounded[i] = newRow
+	}
+	return rounded
+}
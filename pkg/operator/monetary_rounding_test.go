@@ -0,0 +1,141 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+func TestRoundMonetaryValue(t *testing.T) {
+	tests := map[string]struct {
+		mode          MonetaryRoundingMode
+		decimalPlaces int
+		values        map[float64]float64
+	}{
+		"disabled": {
+			values: map[float64]float64{2.675: 2.675},
+		},
+		"half up": {
+			mode:          MonetaryRoundingHalfUp,
+			decimalPlaces: 2,
+			// 2.675 is stored as 2.67499999..., but is rounded as 2.675.
+			values: map[float64]float64{2.665: 2.67, 2.675: 2.68, 2.674: 2.67, -2.675: -2.68, 0.1 + 0.2: 0.3},
+		},
+		"half down": {
+			mode:          MonetaryRoundingHalfDown,
+			decimalPlaces: 2,
+			values:        map[float64]float64{2.665: 2.66, 2.675: 2.67, 2.6751: 2.68, -2.675: -2.67},
+		},
+		"half even": {
+			mode:          MonetaryRoundingHalfEven,
+			decimalPlaces: 2,
+			values:        map[float64]float64{2.665: 2.66, 2.675: 2.68, 2.6651: 2.67, -2.665: -2.66, -2.675: -2.68, 0.125: 0.12},
+		},
+		"up": {
+			mode:          MonetaryRoundingUp,
+			decimalPlaces: 2,
+			values:        map[float64]float64{2.661: 2.67, 2.66: 2.66, -2.661: -2.67},
+		},
+		"down": {
+			mode:          MonetaryRoundingDown,
+			decimalPlaces: 2,
+			values:        map[float64]float64{2.669: 2.66, 2.66: 2.66, -2.669: -2.66},
+		},
+		"whole units": {
+			mode:   MonetaryRoundingHalfEven,
+			values: map[float64]float64{0.5: 0, 1.5: 2, 2.5: 2, 1234567.5: 1234568},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			cfg := MonetaryRoundingConfig{Mode: test.mode, DecimalPlaces: test.decimalPlaces}
+			for value, expected := range test.values {
+				assert.Equal(t, expected, roundMonetaryValue(cfg, value), "rounding %v", value)
+			}
+		})
+	}
+}
+
+func TestReportColumnsQuery(t *testing.T) {
+	columns := []cbTypes.ReportGenerationQueryColumn{
+		{Name: "namespace", Type: "string", Unit: "kubernetes_namespace"},
+		{Name: "pod_request_cpu_core_seconds", Type: "double", Unit: "cpu_core_seconds"},
+		{Name: "namespace_cost", Type: "double", Unit: CurrencyColumnUnit},
+	}
+	const query = "SELECT namespace, seconds, cost FROM costs"
+
+	assert.Equal(t, query, reportColumnsQuery(MonetaryRoundingConfig{}, query, columns))
+	assert.Equal(t, query, reportColumnsQuery(MonetaryRoundingConfig{Mode: MonetaryRoundingHalfEven, DecimalPlaces: 2}, query, columns[:2]))
+	assert.Equal(t,
+		`SELECT "namespace", "pod_request_cpu_core_seconds", CAST(sign(CAST("namespace_cost" AS DECIMAL(38, 12))) * CASE WHEN (abs(CAST("namespace_cost" AS DECIMAL(38, 12))) * DECIMAL '100') - floor((abs(CAST("namespace_cost" AS DECIMAL(38, 12))) * DECIMAL '100')) = 0.5 THEN floor((abs(CAST("namespace_cost" AS DECIMAL(38, 12))) * DECIMAL '100')) + mod(floor((abs(CAST("namespace_cost" AS DECIMAL(38, 12))) * DECIMAL '100')), 2) ELSE floor((abs(CAST("namespace_cost" AS DECIMAL(38, 12))) * DECIMAL '100') + 0.5) END AS double) / 1E2 AS "namespace_cost" FROM (SELECT namespace, seconds, cost FROM costs) AS unrounded`,
+		reportColumnsQuery(MonetaryRoundingConfig{Mode: MonetaryRoundingHalfEven, DecimalPlaces: 2}, query, columns),
+	)
+	assert.Equal(t,
+		`SELECT "namespace", CAST("total_cost" AS decimal(38,12)) AS "total_cost" FROM (SELECT namespace, cost AS total_cost FROM costs) AS unrounded`,
+		reportColumnsQuery(MonetaryRoundingConfig{}, "SELECT namespace, cost AS total_cost FROM costs", []cbTypes.ReportGenerationQueryColumn{
+			{Name: "namespace", Type: "string"},
+			{Name: "total_cost", Type: "decimal(38,12)", Unit: CurrencyColumnUnit},
+		}),
+	)
+	assert.Equal(t,
+		`CAST(sign(CAST("cost" AS DECIMAL(38, 12))) * floor((abs(CAST("cost" AS DECIMAL(38, 12))) * DECIMAL '1')) * DECIMAL '1' AS decimal(18,4))`,
+		monetaryRoundingExpression(MonetaryRoundingConfig{Mode: MonetaryRoundingDown}, `"cost"`, "decimal(18,4)"),
+	)
+	assert.Equal(t,
+		`CAST(sign(CAST("cost" AS DECIMAL(38, 12))) * ceil((abs(CAST("cost" AS DECIMAL(38, 12))) * DECIMAL '100')) * DECIMAL '0.01' AS decimal(38,12))`,
+		monetaryRoundingExpression(MonetaryRoundingConfig{Mode: MonetaryRoundingUp, DecimalPlaces: 2}, `"cost"`, "decimal(38,12)"),
+	)
+}
+
+func TestRoundMonetaryResults(t *testing.T) {
+	columns := []cbTypes.ReportGenerationQueryColumn{
+		{Name: "namespace", Type: "string", Unit: "kubernetes_namespace"},
+		{Name: "namespace_cost", Type: "double", Unit: CurrencyColumnUnit},
+	}
+	results := []presto.Row{
+		{"namespace": "metering", "namespace_cost": 10.125},
+		{"namespace": "default", "namespace_cost": nil},
+	}
+
+	rounded := roundMonetaryResults(MonetaryRoundingConfig{Mode: MonetaryRoundingHalfEven, DecimalPlaces: 2}, columns, results)
+	assert.Equal(t, []presto.Row{
+		{"namespace": "metering", "namespace_cost": 10.12},
+		{"namespace": "default", "namespace_cost": nil},
+	}, rounded)
+	// the results may be cached, so they mustn't be modified.
+	assert.Equal(t, 10.125, results[0]["namespace_cost"])
+}
+
+func TestRoundMonetaryDecimal(t *testing.T) {
+	tests := map[string]struct {
+		cfg      MonetaryRoundingConfig
+		value    presto.Decimal
+		expected presto.Decimal
+	}{
+		"disabled":       {value: "2.675000000000", expected: "2.675000000000"},
+		"half even":      {cfg: MonetaryRoundingConfig{Mode: MonetaryRoundingHalfEven, DecimalPlaces: 2}, value: "2.665000000000", expected: "2.660000000000"},
+		"half up":        {cfg: MonetaryRoundingConfig{Mode: MonetaryRoundingHalfUp, DecimalPlaces: 2}, value: "-2.665000000000", expected: "-2.670000000000"},
+		"down":           {cfg: MonetaryRoundingConfig{Mode: MonetaryRoundingDown, DecimalPlaces: 2}, value: "-2.669999999999", expected: "-2.660000000000"},
+		"up":             {cfg: MonetaryRoundingConfig{Mode: MonetaryRoundingUp}, value: "1.000000000001", expected: "2.000000000000"},
+		"already scaled": {cfg: MonetaryRoundingConfig{Mode: MonetaryRoundingUp, DecimalPlaces: 2}, value: "1.01", expected: "1.01"},
+		"large scale":    {cfg: MonetaryRoundingConfig{Mode: MonetaryRoundingDown, DecimalPlaces: 2}, value: "0.0099999999999999", expected: "0.0100000000000000"},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, roundMonetaryDecimal(test.cfg, test.value))
+		})
+	}
+}
+
+func TestMonetaryRoundingConfigValid(t *testing.T) {
+	assert.NoError(t, MonetaryRoundingConfig{}.Valid())
+	assert.NoError(t, MonetaryRoundingConfig{Mode: MonetaryRoundingHalfEven, DecimalPlaces: 2}.Valid())
+	assert.Error(t, MonetaryRoundingConfig{Mode: "bankers", DecimalPlaces: 2}.Valid())
+	assert.Error(t, MonetaryRoundingConfig{Mode: MonetaryRoundingHalfUp, DecimalPlaces: -1}.Valid())
+	assert.Error(t, MonetaryRoundingConfig{Mode: MonetaryRoundingHalfUp, DecimalPlaces: 13}.Valid())
+}
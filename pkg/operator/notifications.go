@@ -0,0 +1,490 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/secrets"
+)
+
+// slackWebhookURLKey is the key within a secret containing the URL of a
+// Slack incoming webhook.
+const slackWebhookURLKey = "webhook-url"
+
+// defaultNotificationEvents are the events notifications which don't set
+// events are sent for.
+var defaultNotificationEvents = []cbTypes.ReportNotificationEvent{
+	cbTypes.ReportNotificationEventFailed,
+	cbTypes.ReportNotificationEventCostThresholdExceeded,
+	cbTypes.ReportNotificationEventAssertionFailed,
+}
+
+func validateReportNotifications(notifications []cbTypes.ReportNotification) error {
+	names := make(map[string]bool)
+	for i, notification := range notifications {
+		if notification.Name == "" {
+			return fmt.Errorf("notifications[%d]: name must be set", i)
+		}
+		if names[notification.Name] {
+			return fmt.Errorf("notification %s: name must be unique", notification.Name)
+		}
+		names[notification.Name] = true
+		if err := validateReportNotification(notification); err != nil {
+			return fmt.Errorf("notification %s: %v", notification.Name, err)
+		}
+	}
+	return nil
+}
+
+func validateReportNotification(notification cbTypes.ReportNotification) error {
+	for _, event := range notification.Events {
+		switch event {
+		case cbTypes.ReportNotificationEventSucceeded, cbTypes.ReportNotificationEventFailed, cbTypes.ReportNotificationEventCostThresholdExceeded, cbTypes.ReportNotificationEventAssertionFailed:
+		default:
+			return fmt.Errorf("events must be one of: Succeeded, Failed, CostThresholdExceeded or AssertionFailed, got %q", event)
+		}
+	}
+	if notification.CostThreshold != nil && notification.CostThreshold.Column == "" {
+		return fmt.Errorf("costThreshold.column must be set")
+	}
+	destinations := 0
+	if notification.Email != nil {
+		destinations++
+		if len(notification.Email.To) == 0 {
+			return fmt.Errorf("email.to must be set")
+		}
+	}
+	if notification.Slack != nil {
+		destinations++
+		if _, err := secrets.ParseRef(notification.Slack.WebhookURLSecret); err != nil {
+			return fmt.Errorf("invalid slack.webhookURLSecret: %v", err)
+		}
+	}
+	if notification.Webhook != nil {
+		destinations++
+		if _, err := url.Parse(notification.Webhook.URL); err != nil || notification.Webhook.URL == "" {
+			return fmt.Errorf("webhook.url must be a valid URL")
+		}
+		if notification.Webhook.BearerTokenSecret != "" {
+			if _, err := secrets.ParseRef(notification.Webhook.BearerTokenSecret); err != nil {
+				return fmt.Errorf("invalid webhook.bearerTokenSecret: %v", err)
+			}
+		}
+	}
+	if destinations != 1 {
+		return fmt.Errorf("exactly one of email, slack or webhook must be set")
+	}
+	return nil
+}
+
+// reportRun is a completed run of a Report or ScheduledReport which
+// notifications are sent for.
+type reportRun struct {
+	// kind is Report or ScheduledReport.
+	kind            string
+	namespace       string
+	name            string
+	tableName       string
+	generationQuery *cbTypes.ReportGenerationQuery
+	groupByLabels   []string
+	periodStart     time.Time
+	periodEnd       time.Time
+	// err is the error the run failed with, or nil if it succeeded.
+	err error
+	// failedAssertions are the report's assertions the results of a
+	// successful run failed.
+	failedAssertions []cbTypes.ReportAssertionStatus
+}
+
+// reportNotificationPayload is the body of webhook notifications, and is
+// used to render the other kinds of notification.
+type reportNotificationPayload struct {
+	Kind        string                          `json:"kind"`
+	Namespace   string                          `json:"namespace"`
+	Name        string                          `json:"name"`
+	Event       cbTypes.ReportNotificationEvent `json:"event"`
+	PeriodStart time.Time                       `json:"periodStart"`
+	PeriodEnd   time.Time                       `json:"periodEnd"`
+	Message     string                          `json:"message"`
+	Error       string                          `json:"error,omitempty"`
+	ResultsURL  string                          `json:"resultsURL,omitempty"`
+	// Cost and CostThreshold are set for CostThresholdExceeded events.
+	Cost          *float64 `json:"cost,omitempty"`
+	CostThreshold *float64 `json:"costThreshold,omitempty"`
+	// FailedAssertions is set for AssertionFailed events.
+	FailedAssertions []cbTypes.ReportAssertionStatus `json:"failedAssertions,omitempty"`
+}
+
+// sendReportNotifications sends each of the notifications which are
+// configured for the outcome of the run, returning the status of each
+// notification sent. Failing to send a notification doesn't fail the run.
+func (op *Reporting) sendReportNotifications(ctx context.Context, logger logrus.FieldLogger, run reportRun, notifications []cbTypes.ReportNotification) []cbTypes.ReportNotificationStatus {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	// the results are only retrieved if a notification requires them, and
+	// at most once
+	var (
+		columns    []cbTypes.ReportGenerationQueryColumn
+		results    []presto.Row
+		resultsErr error
+		gotResults bool
+	)
+	getResults := func() ([]cbTypes.ReportGenerationQueryColumn, []presto.Row, error) {
+		if !gotResults {
+			columns, results, resultsErr = op.getDeliveryResults(run.tableName, run.generationQuery, run.groupByLabels)
+			if resultsErr != nil {
+				resultsErr = fmt.Errorf("unable to get report results: %v", resultsErr)
+			}
+			gotResults = true
+		}
+		return columns, results, resultsErr
+	}
+
+	var statuses []cbTypes.ReportNotificationStatus
+	send := func(notification cbTypes.ReportNotification, payload reportNotificationPayload, sendErr error) {
+		if sendErr == nil {
+			sendErr = op.sendReportNotification(ctx, notification, payload, getResults)
+		}
+		status := cbTypes.ReportNotificationStatus{
+			Name:  notification.Name,
+			Event: payload.Event,
+			Time:  metav1.Time{Time: op.clock.Now().UTC()},
+		}
+		if sendErr != nil {
+			status.Error = sendErr.Error()
+			logger.WithError(sendErr).Errorf("failed to send %s notification %s", payload.Event, notification.Name)
+		} else {
+			logger.Infof("sent %s notification %s", payload.Event, notification.Name)
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, notification := range notifications {
+		if run.err != nil {
+			if notificationHasEvent(notification, cbTypes.ReportNotificationEventFailed) {
+				send(notification, op.newReportNotificationPayload(run, cbTypes.ReportNotificationEventFailed), nil)
+			}
+			continue
+		}
+		if notificationHasEvent(notification, cbTypes.ReportNotificationEventSucceeded) {
+			send(notification, op.newReportNotificationPayload(run, cbTypes.ReportNotificationEventSucceeded), nil)
+		}
+		if len(run.failedAssertions) != 0 && notificationHasEvent(notification, cbTypes.ReportNotificationEventAssertionFailed) {
+			send(notification, op.newReportNotificationPayload(run, cbTypes.ReportNotificationEventAssertionFailed), nil)
+		}
+		if notification.CostThreshold != nil && notificationHasEvent(notification, cbTypes.ReportNotificationEventCostThresholdExceeded) {
+			payload := op.newReportNotificationPayload(run, cbTypes.ReportNotificationEventCostThresholdExceeded)
+			_, results, err := getResults()
+			var cost float64
+			if err == nil {
+				cost, err = sumResultsColumn(results, notification.CostThreshold.Column)
+			}
+			if err != nil {
+				send(notification, payload, fmt.Errorf("unable to check cost threshold: %v", err))
+				continue
+			}
+			if cost > notification.CostThreshold.Amount {
+				threshold := notification.CostThreshold.Amount
+				payload.Cost = &cost
+				payload.CostThreshold = &threshold
+				payload.Message = fmt.Sprintf("%s %s/%s exceeded its cost threshold for the period %s to %s: the total %s of %.2f is more than %.2f.", run.kind, run.namespace, run.name, run.periodStart, run.periodEnd, notification.CostThreshold.Column, cost, threshold)
+				send(notification, payload, nil)
+			}
+		}
+	}
+	return statuses
+}
+
+func (op *Reporting) newReportNotificationPayload(run reportRun, event cbTypes.ReportNotificationEvent) reportNotificationPayload {
+	payload := reportNotificationPayload{
+		Kind:        run.kind,
+		Namespace:   run.namespace,
+		Name:        run.name,
+		Event:       event,
+		PeriodStart: run.periodStart,
+		PeriodEnd:   run.periodEnd,
+		ResultsURL:  op.reportResultsURL(run.kind, run.name),
+	}
+	switch event {
+	case cbTypes.ReportNotificationEventFailed:
+		payload.Error = run.err.Error()
+		payload.Message = fmt.Sprintf("%s %s/%s failed for the period %s to %s: %s", run.kind, run.namespace, run.name, run.periodStart, run.periodEnd, run.err)
+		// there are no results to link to
+		payload.ResultsURL = ""
+	case cbTypes.ReportNotificationEventAssertionFailed:
+		payload.FailedAssertions = run.failedAssertions
+		payload.Message = fmt.Sprintf("%s %s/%s finished for the period %s to %s, but its results failed %d assertions: %s.", run.kind, run.namespace, run.name, run.periodStart, run.periodEnd, len(run.failedAssertions), describeReportAssertions(run.failedAssertions))
+	default:
+		payload.Message = fmt.Sprintf("%s %s/%s finished for the period %s to %s.", run.kind, run.namespace, run.name, run.periodStart, run.periodEnd)
+	}
+	return payload
+}
+
+func notificationHasEvent(notification cbTypes.ReportNotification, event cbTypes.ReportNotificationEvent) bool {
+	events := notification.Events
+	if len(events) == 0 {
+		events = defaultNotificationEvents
+	}
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// sumResultsColumn returns the sum of a numeric column of the results.
+func sumResultsColumn(results []presto.Row, column string) (float64, error) {
+	var sum float64
+	for _, row := range results {
+		val, ok := row[column]
+		if !ok {
+			return 0, fmt.Errorf("column %s isn't in the results", column)
+		}
+		switch v := val.(type) {
+		case float64:
+			sum += v
+		case int64:
+			sum += float64(v)
+		case int:
+			sum += float64(v)
+		case nil:
+		default:
+			return 0, fmt.Errorf("column %s isn't numeric, got %T", column, val)
+		}
+	}
+	return sum, nil
+}
+
+// reportResultsURL returns the URL of the API endpoint returning the
+// report's results as CSV, or an empty string if the results URL isn't
+// configured.
+func (op *Reporting) reportResultsURL(kind, name string) string {
+	if op.cfg.NotificationConfig.ResultsURL == "" {
+		return ""
+	}
+	u, err := url.Parse(op.cfg.NotificationConfig.ResultsURL)
+	if err != nil {
+		return ""
+	}
+	endpoint := APIV1ReportsGetEndpoint
+	if kind == "ScheduledReport" {
+		endpoint = APIV1ScheduledReportsGetEndpoint
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + endpoint
+	u.RawQuery = url.Values{"name": {name}, "format": {"csv"}}.Encode()
+	return u.String()
+}
+
+func (op *Reporting) sendReportNotification(ctx context.Context, notification cbTypes.ReportNotification, payload reportNotificationPayload, getResults func() ([]cbTypes.ReportGenerationQueryColumn, []presto.Row, error)) error {
+	text := payload.Message
+	if payload.ResultsURL != "" {
+		text += "\nResults: " + payload.ResultsURL
+	}
+
+	switch {
+	case notification.Email != nil:
+		var attachment []byte
+		if notification.AttachResults && payload.Event != cbTypes.ReportNotificationEventFailed {
+			columns, results, err := getResults()
+			if err != nil {
+				return err
+			}
+			attachment, err = encodeDeliveryResults("csv", columns, results)
+			if err != nil {
+				return err
+			}
+		}
+		subject := fmt.Sprintf("%s %s/%s: %s", payload.Kind, payload.Namespace, payload.Name, payload.Event)
+		msg, err := buildNotificationEmail(op.cfg.NotificationConfig.SMTPFrom, notification.Email.To, subject, text, payload.Name+".csv", attachment, op.clock.Now())
+		if err != nil {
+			return err
+		}
+		return op.sendEmail(ctx, notification.Email.To, msg)
+	case notification.Slack != nil:
+		ref, err := secrets.ParseRef(notification.Slack.WebhookURLSecret)
+		if err != nil {
+			return err
+		}
+		webhookURL, err := op.secretResolver.GetValue(ctx, ref, slackWebhookURLKey)
+		if err != nil {
+			return err
+		}
+		body, err := json.Marshal(map[string]string{"text": text})
+		if err != nil {
+			return err
+		}
+		// the webhook URL is a credential, so it's not included in errors
+		return postNotification(ctx, http.DefaultClient, webhookURL, "Slack webhook", body)
+	case notification.Webhook != nil:
+		client, err := op.webhookNotificationClient(notification.Webhook)
+		if err != nil {
+			return err
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		return postNotification(ctx, client, notification.Webhook.URL, notification.Webhook.URL, body)
+	}
+	return fmt.Errorf("no destination set")
+}
+
+// webhookNotificationClient returns the client used to post to the webhook,
+// which sends the webhook's bearer token if it has one.
+func (op *Reporting) webhookNotificationClient(webhook *cbTypes.WebhookNotification) (*http.Client, error) {
+	if webhook.BearerTokenSecret == "" {
+		return http.DefaultClient, nil
+	}
+	ref, err := secrets.ParseRef(webhook.BearerTokenSecret)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: secrets.NewBearerTokenRoundTripper(op.secretResolver, ref, nil)}, nil
+}
+
+// postNotification posts the JSON body to target, which is described as
+// name in errors.
+func postNotification(ctx context.Context, client *http.Client, target, name string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid URL for %s", name)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("unable to send notification to %s: %v", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("unable to send notification to %s, got status %d: %s", name, resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+// buildNotificationEmail returns an email containing text. If attachment
+// isn't nil, it's attached as a CSV file named attachmentName.
+func buildNotificationEmail(from string, to []string, subject, text, attachmentName string, attachment []byte, date time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if attachment == nil {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+		buf.WriteString(strings.Replace(text, "\n", "\r\n", -1))
+		buf.WriteString("\r\n")
+		return buf.Bytes(), nil
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", writer.Boundary())
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write([]byte(strings.Replace(text, "\n", "\r\n", -1) + "\r\n")); err != nil {
+		return nil, err
+	}
+
+	part, err = writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType("text/csv", map[string]string{"name": attachmentName})},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachmentName})},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	// base64 encoded lines must be at most 76 characters
+	encoded := base64.StdEncoding.EncodeToString(attachment)
+	for len(encoded) > 76 {
+		if _, err := part.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+			return nil, err
+		}
+		encoded = encoded[76:]
+	}
+	if _, err := part.Write([]byte(encoded + "\r\n")); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+// sendEmail sends msg through the configured SMTP server, authenticating
+// with the SMTP credentials if they're set.
+func (op *Reporting) sendEmail(ctx context.Context, to []string, msg []byte) error {
+	cfg := op.cfg.NotificationConfig
+	if cfg.SMTPAddress == "" || cfg.SMTPFrom == "" {
+		return fmt.Errorf("unable to send email, the reporting-operator's SMTP address and from address aren't configured")
+	}
+	var auth smtp.Auth
+	if op.cfg.SecretsConfig.SMTPCredentials != "" {
+		ref, err := secrets.ParseRef(op.cfg.SecretsConfig.SMTPCredentials)
+		if err != nil {
+			return err
+		}
+		secret, err := op.secretResolver.GetSecret(ctx, ref)
+		if err != nil {
+			return err
+		}
+		host, _, err := net.SplitHostPort(cfg.SMTPAddress)
+		if err != nil {
+			return fmt.Errorf("invalid SMTP address %s: %v", cfg.SMTPAddress, err)
+		}
+		auth = smtp.PlainAuth("", secret.Data[secrets.UsernameKey], secret.Data[secrets.PasswordKey], host)
+	}
+	if err := smtp.SendMail(cfg.SMTPAddress, auth, cfg.SMTPFrom, to, msg); err != nil {
+		return fmt.Errorf("unable to send email: %v", err)
+	}
+	return nil
+}
+
+// mergeNotificationStatuses returns the statuses, with the status of each
+// notification replaced by its most recent status in updates.
+func mergeNotificationStatuses(statuses, updates []cbTypes.ReportNotificationStatus) []cbTypes.ReportNotificationStatus {
+	for _, update := range updates {
+		replaced := false
+		for i := range statuses {
+			if statuses[i].Name == update.Name {
+				statuses[i] = update
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			statuses = append(statuses, update)
+		}
+	}
+	return statuses
+}
@@ -0,0 +1,212 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+func TestValidateReportNotifications(t *testing.T) {
+	email := &cbTypes.EmailNotification{To: []string{"finance@example.com"}}
+	tests := map[string]struct {
+		notifications []cbTypes.ReportNotification
+		wantErr       bool
+	}{
+		"none": {},
+		"email, slack and webhook": {
+			notifications: []cbTypes.ReportNotification{
+				{Name: "email", Email: email, CostThreshold: &cbTypes.ReportCostThreshold{Column: "total_cost", Amount: 100}},
+				{Name: "slack", Slack: &cbTypes.SlackNotification{WebhookURLSecret: "kubernetes://slack-webhook"}},
+				{Name: "webhook", Events: []cbTypes.ReportNotificationEvent{cbTypes.ReportNotificationEventSucceeded}, Webhook: &cbTypes.WebhookNotification{URL: "https://example.com/hook"}},
+			},
+		},
+		"missing name": {
+			notifications: []cbTypes.ReportNotification{{Email: email}},
+			wantErr:       true,
+		},
+		"duplicate name": {
+			notifications: []cbTypes.ReportNotification{{Name: "email", Email: email}, {Name: "email", Email: email}},
+			wantErr:       true,
+		},
+		"invalid event": {
+			notifications: []cbTypes.ReportNotification{{Name: "email", Events: []cbTypes.ReportNotificationEvent{"Started"}, Email: email}},
+			wantErr:       true,
+		},
+		"no destination": {
+			notifications: []cbTypes.ReportNotification{{Name: "email"}},
+			wantErr:       true,
+		},
+		"no recipients": {
+			notifications: []cbTypes.ReportNotification{{Name: "email", Email: &cbTypes.EmailNotification{}}},
+			wantErr:       true,
+		},
+		"cost threshold without column": {
+			notifications: []cbTypes.ReportNotification{{Name: "email", Email: email, CostThreshold: &cbTypes.ReportCostThreshold{Amount: 100}}},
+			wantErr:       true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateReportNotifications(tt.notifications)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSendReportNotifications(t *testing.T) {
+	var payloads []reportNotificationPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload reportNotificationPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		payloads = append(payloads, payload)
+	}))
+	defer srv.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	queryer := mockpresto.NewMockExecQueryer(ctrl)
+	now := time.Date(2018, time.August, 1, 6, 0, 0, 0, time.UTC)
+	op := &Reporting{
+		clock:         clock.NewFakeClock(now),
+		prestoQueryer: queryer,
+		cfg:           Config{NotificationConfig: NotificationConfig{ResultsURL: "https://metering.example.com"}},
+	}
+
+	genQuery := &cbTypes.ReportGenerationQuery{
+		Spec: cbTypes.ReportGenerationQuerySpec{
+			Columns: []cbTypes.ReportGenerationQueryColumn{
+				{Name: "namespace", Type: "string"},
+				{Name: "total_cost", Type: "double"},
+			},
+		},
+	}
+	run := reportRun{
+		kind:            "ScheduledReport",
+		namespace:       "metering",
+		name:            "namespace-cost",
+		tableName:       "report_namespace_cost",
+		generationQuery: genQuery,
+		periodStart:     time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC),
+		periodEnd:       time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC),
+	}
+	notifications := []cbTypes.ReportNotification{
+		{
+			Name:          "webhook",
+			Events:        []cbTypes.ReportNotificationEvent{cbTypes.ReportNotificationEventSucceeded, cbTypes.ReportNotificationEventCostThresholdExceeded},
+			CostThreshold: &cbTypes.ReportCostThreshold{Column: "total_cost", Amount: 100},
+			Webhook:       &cbTypes.WebhookNotification{URL: srv.URL},
+		},
+		{
+			Name:    "failures",
+			Webhook: &cbTypes.WebhookNotification{URL: srv.URL},
+		},
+	}
+
+	prestoColumns, err := generatePrestoColumns(genQuery.Spec.Columns)
+	require.NoError(t, err)
+	queryer.EXPECT().Query(presto.GenerateGetRowsSQL(run.tableName, prestoColumns)).Return([]presto.Row{
+		{"namespace": "team-a", "total_cost": 75.0},
+		{"namespace": "team-b", "total_cost": 50.0},
+	}, nil)
+
+	statuses := op.sendReportNotifications(context.Background(), logrus.New(), run, notifications)
+	require.Len(t, payloads, 2, "expected the Succeeded and CostThresholdExceeded events to be sent")
+	assert.Equal(t, cbTypes.ReportNotificationEventSucceeded, payloads[0].Event)
+	assert.Equal(t, "https://metering.example.com/api/v1/scheduledreports/get?format=csv&name=namespace-cost", payloads[0].ResultsURL)
+	assert.Equal(t, cbTypes.ReportNotificationEventCostThresholdExceeded, payloads[1].Event)
+	require.NotNil(t, payloads[1].Cost)
+	assert.Equal(t, 125.0, *payloads[1].Cost)
+	assert.Equal(t, []cbTypes.ReportNotificationStatus{
+		{Name: "webhook", Event: cbTypes.ReportNotificationEventSucceeded, Time: metav1.Time{Time: now}},
+		{Name: "webhook", Event: cbTypes.ReportNotificationEventCostThresholdExceeded, Time: metav1.Time{Time: now}},
+	}, statuses)
+
+	payloads = nil
+	run.err = errors.New("presto is unavailable")
+	statuses = op.sendReportNotifications(context.Background(), logrus.New(), run, notifications)
+	require.Len(t, payloads, 1, "expected only the notification with the default events to be sent")
+	assert.Equal(t, cbTypes.ReportNotificationEventFailed, payloads[0].Event)
+	assert.Equal(t, "presto is unavailable", payloads[0].Error)
+	assert.Empty(t, payloads[0].ResultsURL)
+	assert.Equal(t, []cbTypes.ReportNotificationStatus{
+		{Name: "failures", Event: cbTypes.ReportNotificationEventFailed, Time: metav1.Time{Time: now}},
+	}, statuses)
+}
+
+func TestBuildNotificationEmail(t *testing.T) {
+	date := time.Date(2018, time.August, 1, 6, 0, 0, 0, time.UTC)
+	attachment := bytes.Repeat([]byte("namespace,total_cost\nteam-a,75.000000\n"), 10)
+	msg, err := buildNotificationEmail("metering@example.com", []string{"finance@example.com", "ops@example.com"}, "ScheduledReport metering/namespace-cost: Succeeded", "finished\nResults: https://metering.example.com", "namespace-cost.csv", attachment, date)
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(msg))
+	require.NoError(t, err)
+	assert.Equal(t, "finance@example.com, ops@example.com", parsed.Header.Get("To"))
+	assert.Equal(t, "ScheduledReport metering/namespace-cost: Succeeded", parsed.Header.Get("Subject"))
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	part, err := reader.NextPart()
+	require.NoError(t, err)
+	text, err := ioutil.ReadAll(part)
+	require.NoError(t, err)
+	assert.Equal(t, "finished\r\nResults: https://metering.example.com\r\n", string(text))
+
+	part, err = reader.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "namespace-cost.csv", part.FileName())
+	encoded, err := ioutil.ReadAll(part)
+	require.NoError(t, err)
+	decoded, err := base64.StdEncoding.DecodeString(string(bytes.Replace(encoded, []byte("\r\n"), nil, -1)))
+	require.NoError(t, err)
+	assert.Equal(t, attachment, decoded)
+
+	msg, err = buildNotificationEmail("metering@example.com", []string{"finance@example.com"}, "subject", "failed", "", nil, date)
+	require.NoError(t, err)
+	parsed, err = mail.ReadMessage(bytes.NewReader(msg))
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain; charset=utf-8", parsed.Header.Get("Content-Type"))
+}
+
+func TestMergeNotificationStatuses(t *testing.T) {
+	statuses := []cbTypes.ReportNotificationStatus{
+		{Name: "email", Event: cbTypes.ReportNotificationEventFailed, Error: "no SMTP server"},
+		{Name: "slack", Event: cbTypes.ReportNotificationEventFailed},
+	}
+	merged := mergeNotificationStatuses(statuses, []cbTypes.ReportNotificationStatus{
+		{Name: "email", Event: cbTypes.ReportNotificationEventSucceeded},
+		{Name: "webhook", Event: cbTypes.ReportNotificationEventSucceeded},
+	})
+	assert.Equal(t, []cbTypes.ReportNotificationStatus{
+		{Name: "email", Event: cbTypes.ReportNotificationEventSucceeded},
+		{Name: "slack", Event: cbTypes.ReportNotificationEventFailed},
+		{Name: "webhook", Event: cbTypes.ReportNotificationEventSucceeded},
+	}, merged)
+}
@@ -0,0 +1,190 @@
+package operator
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+)
+
+const (
+	objectStorageFormatCSV     = "csv"
+	objectStorageFormatParquet = "parquet"
+
+	defaultObjectStorageCSVDelimiter = ","
+
+	// objectStorageCSVSerde is the SerDe used to read csv files, which
+	// parses the fields of each line as the Hive types of the columns.
+	objectStorageCSVSerde = "org.apache.hadoop.hive.serde2.lazy.LazySimpleSerDe"
+)
+
+func (op *Reporting) handleObjectStorageDataSource(logger logrus.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
+	objectStorage := dataSource.Spec.ObjectStorage
+	if err := validateObjectStorageDataSource(objectStorage); err != nil {
+		return fmt.Errorf("datasource %q: improperly configured datasource, %v", dataSource.Name, err)
+	}
+	if interval := op.objectStorageRefreshInterval(dataSource); interval <= 0 {
+		return fmt.Errorf("datasource %q: improperly configured datasource, refreshInterval must be positive, got %s", dataSource.Name, interval)
+	}
+
+	if dataSource.TableName == "" {
+		filesTableName := dataSourceFilesTableName(dataSource.Name)
+		logger.Debugf("creating table %s reading the %s files of ReportDataSource %s", filesTableName, objectStorage.Format, dataSource.Name)
+		err := op.createObjectStorageFilesTable(logger, filesTableName, objectStorage)
+		if err != nil {
+			return err
+		}
+
+		tableName := dataSourceTableName(dataSource.Name)
+		err = op.createTableForStorage(logger, dataSource, "ReportDataSource", dataSource.Name, objectStorage.Storage, tableName, generateHiveColumns(objectStorage.Columns))
+		if err != nil {
+			return err
+		}
+
+		err = op.updateDataSourceTableName(logger, dataSource, tableName)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to update ReportDataSource TableName field %q", tableName)
+			return err
+		}
+	}
+
+	op.tableLoaderNewDataSourceQueue <- tableLoad{
+		namespace:      dataSource.Namespace,
+		dataSourceName: dataSource.Name,
+		tableName:      dataSource.TableName,
+		description:    objectStorage.Format + " files",
+		query:          fmt.Sprintf("SELECT %s FROM %s", remoteReportColumnsSQL(objectStorage.Columns), dataSourceFilesTableName(dataSource.Name)),
+		interval:       op.objectStorageRefreshInterval(dataSource),
+	}
+	return nil
+}
+
+func validateObjectStorageDataSource(objectStorage *cbTypes.ObjectStorageDataSource) error {
+	if (objectStorage.S3 == nil) == (objectStorage.GCS == nil) {
+		return fmt.Errorf("exactly one of s3 or gcs must be set")
+	}
+	if objectStorage.S3 != nil && objectStorage.S3.Bucket == "" {
+		return fmt.Errorf("s3.bucket must be set")
+	}
+	if objectStorage.GCS != nil && objectStorage.GCS.Bucket == "" {
+		return fmt.Errorf("gcs.bucket must be set")
+	}
+
+	switch objectStorage.Format {
+	case objectStorageFormatCSV:
+		if csv := objectStorage.CSV; csv != nil {
+			if csv.Delimiter != "" && utf8.RuneCountInString(csv.Delimiter) != 1 {
+				return fmt.Errorf("csv.delimiter must be a single character, got %q", csv.Delimiter)
+			}
+			if csv.SkipHeaderLines < 0 {
+				return fmt.Errorf("csv.skipHeaderLines must not be negative, got %d", csv.SkipHeaderLines)
+			}
+		}
+	case objectStorageFormatParquet:
+		if objectStorage.CSV != nil {
+			return fmt.Errorf("csv can only be set when format is csv")
+		}
+	default:
+		return fmt.Errorf("format must be one of %s or %s, got %q", objectStorageFormatCSV, objectStorageFormatParquet, objectStorage.Format)
+	}
+
+	if len(objectStorage.Columns) == 0 {
+		return fmt.Errorf("columns must be set")
+	}
+	seen := make(map[string]struct{}, len(objectStorage.Columns))
+	for _, column := range objectStorage.Columns {
+		if column.Name == "" {
+			return fmt.Errorf("columns must have a name")
+		}
+		if _, exists := seen[column.Name]; exists {
+			return fmt.Errorf("column %s is defined more than once", column.Name)
+		}
+		seen[column.Name] = struct{}{}
+		if _, err := hiveColumnToPrestoColumn(hive.Column{Name: column.Name, Type: column.Type}); err != nil {
+			return fmt.Errorf("column %s: %v", column.Name, err)
+		}
+		// complex types need collection delimiters, which csv files
+		// exported from spreadsheets don't have.
+		if objectStorage.Format == objectStorageFormatCSV && simpleHiveColumnTypeToPrestoColumnType(column.Type) == "" {
+			return fmt.Errorf("column %s: type %s is not supported in csv files", column.Name, column.Type)
+		}
+	}
+	return nil
+}
+
+// objectStorageFilesTableProperties returns the properties of the external
+// table reading the files of an objectStorage ReportDataSource.
+func objectStorageFilesTableProperties(objectStorage *cbTypes.ObjectStorageDataSource) (hive.TableProperties, error) {
+	var location string
+	var err error
+	if objectStorage.S3 != nil {
+		location, err = hive.S3Location(objectStorage.S3.Bucket, objectStorage.S3.Prefix)
+	} else {
+		location, err = hive.GCSLocation(objectStorage.GCS.Bucket, objectStorage.GCS.Prefix)
+	}
+	if err != nil {
+		return hive.TableProperties{}, err
+	}
+
+	properties := hive.TableProperties{
+		Location: location,
+		External: true,
+	}
+	if objectStorage.Format == objectStorageFormatParquet {
+		properties.FileFormat = "parquet"
+		return properties, nil
+	}
+
+	delimiter := defaultObjectStorageCSVDelimiter
+	var skipHeaderLines int
+	if csv := objectStorage.CSV; csv != nil {
+		if csv.Delimiter != "" {
+			delimiter = csv.Delimiter
+		}
+		skipHeaderLines = csv.SkipHeaderLines
+	}
+	properties.FileFormat = "textfile"
+	properties.SerdeFormat = objectStorageCSVSerde
+	properties.SerdeRowProperties = map[string]string{
+		"serialization.format": delimiter,
+		"field.delim":          delimiter,
+	}
+	if skipHeaderLines > 0 {
+		properties.Properties = map[string]string{
+			"skip.header.line.count": strconv.Itoa(skipHeaderLines),
+		}
+	}
+	return properties, nil
+}
+
+// createObjectStorageFilesTable creates an external Hive table reading the
+// files under the bucket's prefix.
+func (op *Reporting) createObjectStorageFilesTable(logger logrus.FieldLogger, tableName string, objectStorage *cbTypes.ObjectStorageDataSource) error {
+	properties, err := objectStorageFilesTableProperties(objectStorage)
+	if err != nil {
+		return err
+	}
+	params := hive.TableParameters{
+		Name:         tableName,
+		Columns:      generateHiveColumns(objectStorage.Columns),
+		IgnoreExists: true,
+	}
+	// the table reads files managed outside of metering, so there's no
+	// PrestoTable resource for it, and the table's name isn't added to the
+	// location.
+	return op.createTable(logger, params, properties)
+}
+
+// objectStorageRefreshInterval returns how often the files of an
+// objectStorage ReportDataSource are loaded.
+func (op *Reporting) objectStorageRefreshInterval(dataSource *cbTypes.ReportDataSource) time.Duration {
+	if interval := dataSource.Spec.ObjectStorage.RefreshInterval; interval != nil {
+		return interval.Duration
+	}
+	return op.cfg.PrometheusQueryConfig.QueryInterval.Duration
+}
@@ -0,0 +1,177 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+)
+
+func TestValidateObjectStorageDataSource(t *testing.T) {
+	columns := []cbTypes.ReportGenerationQueryColumn{
+		{Name: "instance_type", Type: "string"},
+		{Name: "cpu_core_hour_rate", Type: "decimal(38,12)"},
+		{Name: "effective_from", Type: "timestamp"},
+	}
+
+	tests := map[string]struct {
+		objectStorage *cbTypes.ObjectStorageDataSource
+		expectedErr   bool
+	}{
+		"valid": {
+			objectStorage: &cbTypes.ObjectStorageDataSource{
+				S3:      &cbTypes.S3Bucket{Bucket: "finance", Prefix: "rate-cards"},
+				Format:  "csv",
+				CSV:     &cbTypes.ObjectStorageCSV{SkipHeaderLines: 1},
+				Columns: columns,
+			},
+		},
+		"gcs parquet": {
+			objectStorage: &cbTypes.ObjectStorageDataSource{
+				GCS:    &cbTypes.GCSBucket{Bucket: "finance"},
+				Format: "parquet",
+				Columns: []cbTypes.ReportGenerationQueryColumn{
+					{Name: "instance_type", Type: "string"},
+					{Name: "labels", Type: "map<string, string>"},
+				},
+			},
+		},
+		"s3 and gcs": {
+			objectStorage: &cbTypes.ObjectStorageDataSource{
+				S3:      &cbTypes.S3Bucket{Bucket: "finance"},
+				GCS:     &cbTypes.GCSBucket{Bucket: "finance"},
+				Format:  "csv",
+				Columns: columns,
+			},
+			expectedErr: true,
+		},
+		"no bucket": {
+			objectStorage: &cbTypes.ObjectStorageDataSource{
+				S3:      &cbTypes.S3Bucket{Prefix: "rate-cards"},
+				Format:  "csv",
+				Columns: columns,
+			},
+			expectedErr: true,
+		},
+		"unknown format": {
+			objectStorage: &cbTypes.ObjectStorageDataSource{
+				S3:      &cbTypes.S3Bucket{Bucket: "finance"},
+				Format:  "json",
+				Columns: columns,
+			},
+			expectedErr: true,
+		},
+		"csv options with parquet": {
+			objectStorage: &cbTypes.ObjectStorageDataSource{
+				S3:      &cbTypes.S3Bucket{Bucket: "finance"},
+				Format:  "parquet",
+				CSV:     &cbTypes.ObjectStorageCSV{SkipHeaderLines: 1},
+				Columns: columns,
+			},
+			expectedErr: true,
+		},
+		"multi-character delimiter": {
+			objectStorage: &cbTypes.ObjectStorageDataSource{
+				S3:      &cbTypes.S3Bucket{Bucket: "finance"},
+				Format:  "csv",
+				CSV:     &cbTypes.ObjectStorageCSV{Delimiter: "||"},
+				Columns: columns,
+			},
+			expectedErr: true,
+		},
+		"negative skipHeaderLines": {
+			objectStorage: &cbTypes.ObjectStorageDataSource{
+				S3:      &cbTypes.S3Bucket{Bucket: "finance"},
+				Format:  "csv",
+				CSV:     &cbTypes.ObjectStorageCSV{SkipHeaderLines: -1},
+				Columns: columns,
+			},
+			expectedErr: true,
+		},
+		"no columns": {
+			objectStorage: &cbTypes.ObjectStorageDataSource{
+				S3:     &cbTypes.S3Bucket{Bucket: "finance"},
+				Format: "csv",
+			},
+			expectedErr: true,
+		},
+		"duplicate column": {
+			objectStorage: &cbTypes.ObjectStorageDataSource{
+				S3:     &cbTypes.S3Bucket{Bucket: "finance"},
+				Format: "csv",
+				Columns: []cbTypes.ReportGenerationQueryColumn{
+					{Name: "instance_type", Type: "string"},
+					{Name: "instance_type", Type: "string"},
+				},
+			},
+			expectedErr: true,
+		},
+		"complex column in csv": {
+			objectStorage: &cbTypes.ObjectStorageDataSource{
+				S3:     &cbTypes.S3Bucket{Bucket: "finance"},
+				Format: "csv",
+				Columns: []cbTypes.ReportGenerationQueryColumn{
+					{Name: "instance_type", Type: "string"},
+					{Name: "labels", Type: "map<string, string>"},
+				},
+			},
+			expectedErr: true,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			err := validateObjectStorageDataSource(test.objectStorage)
+			if test.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestObjectStorageFilesTableProperties(t *testing.T) {
+	tests := map[string]struct {
+		objectStorage      *cbTypes.ObjectStorageDataSource
+		expectedProperties hive.TableProperties
+	}{
+		"s3 csv": {
+			objectStorage: &cbTypes.ObjectStorageDataSource{
+				S3:     &cbTypes.S3Bucket{Bucket: "finance", Prefix: "rate-cards"},
+				Format: "csv",
+				CSV:    &cbTypes.ObjectStorageCSV{Delimiter: ";", SkipHeaderLines: 1},
+			},
+			expectedProperties: hive.TableProperties{
+				Location:           "s3a://finance/rate-cards/",
+				FileFormat:         "textfile",
+				SerdeFormat:        objectStorageCSVSerde,
+				SerdeRowProperties: map[string]string{"serialization.format": ";", "field.delim": ";"},
+				External:           true,
+				Properties:         map[string]string{"skip.header.line.count": "1"},
+			},
+		},
+		"gcs parquet": {
+			objectStorage: &cbTypes.ObjectStorageDataSource{
+				GCS:    &cbTypes.GCSBucket{Bucket: "finance", Prefix: "amortization"},
+				Format: "parquet",
+			},
+			expectedProperties: hive.TableProperties{
+				Location:   "gs://finance/amortization/",
+				FileFormat: "parquet",
+				External:   true,
+			},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			properties, err := objectStorageFilesTableProperties(test.objectStorage)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedProperties, properties)
+		})
+	}
+}
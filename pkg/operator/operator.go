@@ -8,22 +8,29 @@ import (
 	"math/rand"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"sync"
 	"syscall"
 	"time"
 
-	_ "github.com/prestodb/presto-go-client/presto"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	prestoclient "github.com/prestodb/presto-go-client/presto"
 	promapi "github.com/prometheus/client_golang/api"
 	prom "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
-	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc"
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes/scheme"
+	appsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	authenticationv1 "k8s.io/client-go/kubernetes/typed/authentication/v1"
+	authorizationv1 "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	batchv1 "k8s.io/client-go/kubernetes/typed/batch/v1"
+	batchv1beta1 "k8s.io/client-go/kubernetes/typed/batch/v1beta1"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
 	"k8s.io/client-go/rest"
@@ -36,24 +43,54 @@ import (
 	"k8s.io/client-go/util/workqueue"
 
 	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/aws"
+	"github.com/operator-framework/operator-metering/pkg/backup"
 	"github.com/operator-framework/operator-metering/pkg/db"
 	cbClientset "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned"
+	cbScheme "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/scheme"
 	cbInformers "github.com/operator-framework/operator-metering/pkg/generated/informers/externalversions"
 	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
 	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/promquery"
+	"github.com/operator-framework/operator-metering/pkg/secrets"
 	_ "github.com/operator-framework/operator-metering/pkg/util/workqueue/prometheus"
 )
 
 const (
-	connBackoff         = time.Second * 15
-	maxConnWaitTime     = time.Minute * 3
-	defaultResyncPeriod = time.Minute
+	connBackoff     = time.Second * 15
+	maxConnWaitTime = time.Minute * 3
 
 	serviceServingCAFile = "/var/run/secrets/kubernetes.io/serviceaccount/service-ca.crt"
 
 	DefaultPrometheusQueryInterval  = time.Minute * 5
 	DefaultPrometheusQueryStepSize  = time.Minute
 	DefaultPrometheusQueryChunkSize = time.Minute * 5
+	// DefaultPrometheusImportJitterFactor is the default fraction of the
+	// query interval each Prometheus import is randomly delayed by.
+	DefaultPrometheusImportJitterFactor = 0.1
+
+	// DefaultResyncPeriod is how often every resource is reconciled again
+	// even if it hasn't changed. Reconciles are driven by watch events, so
+	// this is only a safety net.
+	DefaultResyncPeriod = time.Hour * 10
+
+	// DefaultMaxConcurrentReports is the default number of report runs
+	// which query Presto at the same time.
+	DefaultMaxConcurrentReports = 4
+
+	// DefaultPrestoUser is the user components query Presto as if they
+	// have no user or credentials configured.
+	DefaultPrestoUser = "root"
+
+	// prestoCustomClientKeyPrefix is the prefix of the names the HTTP
+	// clients used to authenticate each component with Presto are
+	// registered with in the Presto driver.
+	prestoCustomClientKeyPrefix = "metering-"
+
+	importerComponent  = "importer"
+	reportingComponent = "reporting"
+	apiComponent       = "api"
 )
 
 type TLSConfig struct {
@@ -84,16 +121,225 @@ type Config struct {
 	PrestoHost     string
 	PromHost       string
 	DisablePromsum bool
+	// PrometheusQueryDryRun runs the query of each Prometheus
+	// ReportDataSource once when it's created or changed, to check that
+	// Prometheus accepts it and that it returns data.
+	PrometheusQueryDryRun bool
 
 	LogDMLQueries bool
 	LogDDLQueries bool
 
 	PrometheusQueryConfig cbTypes.PrometheusQueryConfig
+	// PrometheusImportJitterFactor delays each periodic Prometheus import by
+	// a random duration of up to this fraction of its query interval, so
+	// that imports don't query Prometheus and write to Presto at the same
+	// time.
+	PrometheusImportJitterFactor float64
 
 	LeaderLeaseDuration time.Duration
 
+	// ResyncPeriod is how often the informers re-deliver every resource to
+	// the reconcile queues even if it hasn't changed. If 0, resources are
+	// only reconciled when they or their dependencies change.
+	ResyncPeriod time.Duration
+
+	// MaxConcurrentReports is the number of Report and ScheduledReport runs
+	// which query Presto at the same time. Other runs are queued, and
+	// started fairly across namespaces as runs finish.
+	MaxConcurrentReports int
+
+	// ReadOnly runs the reporting-operator without importing data, running
+	// reports or modifying any resources, only serving the results of
+	// reports from Presto, so that a replica can serve the results stored in
+	// a shared warehouse from another region.
+	ReadOnly bool
+
+	ScheduledReportStaleTolerance time.Duration
+
+	// ReportMetricsMaxSeries is the most series exported for each gauge of
+	// a report with metrics set.
+	ReportMetricsMaxSeries int
+
+	// MemoryLimitBytes is the reporting-operator's memory limit, which is
+	// used to recommend changes to it based on the memory used by the
+	// Prometheus importer. If 0, the memory limit is unknown.
+	MemoryLimitBytes int64
+
+	// EnableFaultInjection enables the debug API for injecting faults into
+	// the Prometheus importer. It should only be enabled for testing.
+	EnableFaultInjection bool
+
+	// DefaultReportRetryPolicy is the retry policy used by Reports and
+	// ScheduledReports which don't set spec.retryPolicy.
+	DefaultReportRetryPolicy ReportRetryPolicy
+
 	APITLSConfig     TLSConfig
 	MetricsTLSConfig TLSConfig
+	// APIClientCAFile, if set, is the path to the CA certificates HTTP API
+	// client certificates are verified with, and requests without a valid
+	// certificate are rejected, except for health checks and webhooks.
+	// Requires TLS to be enabled.
+	APIClientCAFile string
+	// CertificateReloadInterval is how often the HTTP API's TLS
+	// certificate is reloaded from its files, to use rotated certificates.
+	CertificateReloadInterval time.Duration
+
+	GRPCConfig GRPCConfig
+
+	SecretsConfig SecretsConfig
+
+	// AWSWebIdentityConfig, if enabled, retrieves the AWS credentials used
+	// to access S3 by assuming an IAM role with a web identity token, such
+	// as a projected service account token. It can't be used with
+	// SecretsConfig.AWSCredentials.
+	AWSWebIdentityConfig aws.WebIdentityConfig
+
+	NotificationConfig NotificationConfig
+
+	KafkaConfig KafkaConfig
+
+	ComponentIdentities ComponentIdentities
+	// PrestoSessions configures the source and session properties of each
+	// component's Presto queries, to isolate their workloads.
+	PrestoSessions PrestoSessions
+
+	// ClusterID identifies the local cluster in the cluster_id label of the
+	// metrics it imports. If empty, metrics aren't labelled with a cluster.
+	ClusterID string
+	// RemoteClusters are the other clusters metrics are imported from.
+	RemoteClusters []RemoteCluster
+
+	// QueryConfig limits the queries run through the ad-hoc query API.
+	QueryConfig QueryConfig
+
+	APIAuthConfig APIAuthConfig
+
+	// EnableTenantNamespaces watches Reports, ScheduledReports and
+	// ReportGenerationQueries in every namespace, storing the results of
+	// those outside the metering namespace in a schema per namespace, and
+	// rendering their dataSourceTableName as their own namespace's data.
+	// Queries can still read any table by name.
+	EnableTenantNamespaces bool
+
+	// EnableBudgetEnforcement takes the enforcement actions of
+	// ScheduledReports' budgets on the namespaces which exceed them.
+	// Otherwise, budgets only record the namespaces exceeding them.
+	EnableBudgetEnforcement bool
+
+	AuditConfig AuditConfig
+
+	ShardingConfig ShardingConfig
+
+	// AnalyzeConfig configures analyzing tables after significant writes,
+	// to keep the statistics Presto plans queries with up to date.
+	AnalyzeConfig AnalyzeConfig
+
+	// PartitionArchiveInterval is how often the partitions of
+	// ReportDataSources with an archive are moved to their archive
+	// StorageLocation once they're old enough. If 0, partitions aren't
+	// archived.
+	PartitionArchiveInterval time.Duration
+
+	// DataCompletenessWindow is how far back the data of each
+	// ReportDataSource is checked for missing steps. If 0, data
+	// completeness isn't checked.
+	DataCompletenessWindow time.Duration
+
+	// AnomalyDetectionConfig configures flagging sudden spikes and drops in
+	// the usage of each namespace.
+	AnomalyDetectionConfig AnomalyDetectionConfig
+
+	// MonetaryRoundingConfig configures rounding the values of columns with
+	// the currency unit in report results.
+	MonetaryRoundingConfig MonetaryRoundingConfig
+
+	// BackupConfig configures periodically backing up the Metering
+	// resources and the tables in the Hive metastore, so they can be
+	// restored if the metastore is lost.
+	BackupConfig backup.Config
+
+	// RecoverTables enables recovering the tables of the Metering resources
+	// from the files in their StorageLocations when the reporting-operator
+	// becomes the leader, so that a lost Hive metastore can be replaced
+	// with an empty one.
+	RecoverTables bool
+}
+
+// ComponentIdentities configures the identity each component of the
+// reporting-operator uses to access Presto, so that each component can be
+// granted only the permissions it requires:
+// - the Prometheus importer writes to ReportDataSource tables.
+// - the report runner reads ReportDataSource tables, and writes Report and
+//   ScheduledReport tables.
+// - the HTTP API reads Report and ScheduledReport tables.
+type ComponentIdentities struct {
+	Importer  ComponentIdentity
+	Reporting ComponentIdentity
+	API       ComponentIdentity
+}
+
+// ComponentIdentity is the identity a component uses to access Presto.
+type ComponentIdentity struct {
+	// PrestoUser is the user the component queries Presto as. Defaults to
+	// DefaultPrestoUser. Ignored if PrestoCredentials is set.
+	PrestoUser string
+	// PrestoCredentials is a secret reference in the form
+	// <provider>://<path> containing the username and password the
+	// component authenticates with. Defaults to
+	// SecretsConfig.PrestoCredentials.
+	PrestoCredentials string
+}
+
+// SecretsConfig configures the secret providers credentials are retrieved
+// from. Each credential is a secret reference in the form
+// <provider>://<path>, and if empty, the credential isn't used.
+type SecretsConfig struct {
+	CacheTTL    time.Duration
+	VaultConfig secrets.VaultConfig
+	// WatchKubernetesSecrets watches the Kubernetes Secrets in the
+	// reporting-operator's namespace, so that changes to them are used
+	// immediately rather than once they expire from the cache.
+	WatchKubernetesSecrets bool
+
+	PrestoCredentials     string
+	PrometheusBearerToken string
+	AWSCredentials        string
+	// SMTPCredentials contains the username and password keys used to
+	// authenticate with the SMTP server.
+	SMTPCredentials string
+	// KafkaCredentials contains the username and password keys used to
+	// authenticate with the Kafka brokers using SASL/PLAIN.
+	KafkaCredentials string
+}
+
+// NotificationConfig configures how Report and ScheduledReport
+// notifications are sent.
+type NotificationConfig struct {
+	// SMTPAddress is the host:port of the SMTP server emails are sent
+	// through. If empty, email notifications fail to send.
+	SMTPAddress string
+	// SMTPFrom is the address emails are sent from.
+	SMTPFrom string
+	// ResultsURL is the URL of the reporting-operator's HTTP API as
+	// reachable by the recipients of notifications, which is used to link
+	// to the results of reports. If empty, notifications don't contain a
+	// link.
+	ResultsURL string
+}
+
+// KafkaConfig configures publishing imported metrics and the results of
+// reports to Kafka, for feeding them into streaming pipelines. If Brokers is
+// empty, nothing is published.
+type KafkaConfig struct {
+	Brokers []string
+	// MetricsTopic, if set, is the topic the metrics imported from
+	// Prometheus are published to.
+	MetricsTopic string
+	// ReportsTopic, if set, is the topic each row of the results of Reports
+	// and ScheduledReports are published to when they finish.
+	ReportsTopic string
+	// UseTLS enables TLS when connecting to the brokers.
+	UseTLS bool
 }
 
 type Reporting struct {
@@ -103,13 +349,61 @@ type Reporting struct {
 	queues         queues
 	meteringClient cbClientset.Interface
 	kubeClient     corev1.CoreV1Interface
+	// appsClient, batchClient and cronJobClient are used to snapshot
+	// workloads for kubernetesObjects ReportDataSources.
+	appsClient    appsv1.AppsV1Interface
+	batchClient   batchv1.BatchV1Interface
+	cronJobClient batchv1beta1.BatchV1beta1Interface
 
 	prestoConn    *sql.DB
 	prestoQueryer presto.ExecQueryer
-	hiveQueryer   *hiveQueryer
-	promConn      prom.API
+	// importerPrestoConn and apiPrestoConn are the Presto connections used
+	// by the Prometheus importer and HTTP API, which use their own
+	// identities. prestoConn is used by the report runner.
+	importerPrestoConn    *sql.DB
+	importerPrestoQueryer presto.ExecQueryer
+	apiPrestoConn         *sql.DB
+	apiPrestoQueryer      presto.ExecQueryer
+	// prestoClientKeys maps each component to the name of the HTTP client
+	// registered with the Presto driver for its credentials, if it has any.
+	prestoClientKeys map[string]string
+	hiveQueryer   db.Queryer
+	// prometheusClusters are the clusters metrics are imported from, starting
+	// with the local cluster.
+	prometheusClusters []prometheusCluster
+
+	secretResolver *secrets.Resolver
+	// secretInformer is nil unless SecretsConfig.WatchKubernetesSecrets is
+	// set.
+	secretInformer cache.SharedIndexInformer
+	awsCredentials *credentials.Credentials
+	// kafkaPublisher is nil unless KafkaConfig.Brokers is set.
+	kafkaPublisher *kafkaPublisher
 
 	scheduledReportRunner *scheduledReportRunner
+	// reportAmendments holds the late data reports are waiting to be
+	// amended for.
+	reportAmendments *reportAmendmentQueue
+
+	staleScheduledReportsMu sync.Mutex
+	staleScheduledReports   map[scheduledReportWatchdogKey]bool
+
+	// tenantSchemas are the schemas of tenant namespaces which have been
+	// created.
+	tenantSchemasMu sync.Mutex
+	tenantSchemas   map[string]bool
+
+	eventRecorder record.EventRecorder
+
+	importerTelemetry *importerTelemetry
+	// usageAnomalies is nil unless AnomalyDetectionConfig.Interval is set.
+	usageAnomalies *usageAnomalies
+	faultInjector  *prestostore.FaultInjector
+	// apiAuth is nil unless APIAuthConfig.Enabled is set.
+	apiAuth *apiAuth
+	// audit is nil unless AuditConfig.Enabled is set.
+	audit         *auditLogger
+	reportMetrics     *reportMetricsCollector
 
 	clock clock.Clock
 	rand  *rand.Rand
@@ -119,15 +413,30 @@ type Reporting struct {
 	initializedMu sync.Mutex
 	initialized   bool
 
+	leaderMu sync.Mutex
+	leader   bool
+
 	prestoTablePartitionQueue                    chan *cbTypes.ReportDataSource
 	prometheusImporterNewDataSourceQueue         chan *cbTypes.ReportDataSource
 	prometheusImporterDeletedDataSourceQueue     chan string
 	prometheusImporterTriggerFromLastTimestampCh chan struct{}
 	prometheusImporterTriggerForTimeRangeCh      chan prometheusImporterTimeRangeTrigger
-
-	// ensures only at most a single testRead query is running against Presto
-	// at one time
-	healthCheckSingleFlight singleflight.Group
+	kubernetesObjectsNewDataSourceQueue          chan *cbTypes.ReportDataSource
+	kubernetesObjectsDeletedDataSourceQueue      chan string
+	kubernetesEventsNewDataSourceQueue           chan *cbTypes.ReportDataSource
+	kubernetesEventsDeletedDataSourceQueue       chan string
+	remoteReportNewDataSourceQueue               chan *cbTypes.ReportDataSource
+	remoteReportDeletedDataSourceQueue           chan string
+	tableLoaderNewDataSourceQueue                chan tableLoad
+	tableLoaderDeletedDataSourceQueue            chan string
+
+	// caches the results of the dependency checks of the health endpoints
+	healthChecks *healthCheckCache
+
+	// reportScheduler limits the number of report runs querying Presto.
+	reportScheduler *reportScheduler
+	// tableAnalyzer is nil unless AnalyzeConfig.Interval is set.
+	tableAnalyzer *tableAnalyzer
 }
 
 func New(logger log.FieldLogger, cfg Config, clock clock.Clock) (*Reporting, error) {
@@ -138,6 +447,20 @@ func New(logger log.FieldLogger, cfg Config, clock clock.Clock) (*Reporting, err
 		prometheusImporterDeletedDataSourceQueue:     make(chan string),
 		prometheusImporterTriggerFromLastTimestampCh: make(chan struct{}),
 		prometheusImporterTriggerForTimeRangeCh:      make(chan prometheusImporterTimeRangeTrigger),
+		kubernetesObjectsNewDataSourceQueue:          make(chan *cbTypes.ReportDataSource),
+		kubernetesObjectsDeletedDataSourceQueue:      make(chan string),
+		kubernetesEventsNewDataSourceQueue:           make(chan *cbTypes.ReportDataSource),
+		kubernetesEventsDeletedDataSourceQueue:       make(chan string),
+		remoteReportNewDataSourceQueue:               make(chan *cbTypes.ReportDataSource),
+		remoteReportDeletedDataSourceQueue:           make(chan string),
+		tableLoaderNewDataSourceQueue:                make(chan tableLoad),
+		tableLoaderDeletedDataSourceQueue:            make(chan string),
+		staleScheduledReports:                        make(map[scheduledReportWatchdogKey]bool),
+		tenantSchemas:                                make(map[string]bool),
+		importerTelemetry:                            newImporterTelemetry(cfg.MemoryLimitBytes),
+		reportMetrics:                                reportResultMetrics,
+		healthChecks:                                 newHealthCheckCache(clock, healthCheckCacheTTL, healthCheckTimeout),
+		reportScheduler:                              newReportScheduler(cfg.MaxConcurrentReports, clock.Now),
 		logger: logger,
 		clock:  clock,
 	}
@@ -146,11 +469,76 @@ func New(logger log.FieldLogger, cfg Config, clock clock.Clock) (*Reporting, err
 	if err := cfg.APITLSConfig.Valid(); err != nil {
 		return nil, err
 	}
+	if cfg.APIClientCAFile != "" && !cfg.APITLSConfig.UseTLS {
+		return nil, fmt.Errorf("Must enable TLS to verify HTTP API client certificates")
+	}
 	if err := cfg.MetricsTLSConfig.Valid(); err != nil {
 		return nil, err
 	}
+	if err := cfg.GRPCConfig.Valid(); err != nil {
+		return nil, err
+	}
+	if err := cfg.validateClusters(); err != nil {
+		return nil, err
+	}
+	if err := cfg.QueryConfig.Valid(); err != nil {
+		return nil, err
+	}
+	if err := cfg.APIAuthConfig.Valid(); err != nil {
+		return nil, err
+	}
+	if err := cfg.AuditConfig.Valid(); err != nil {
+		return nil, err
+	}
+	if err := cfg.ShardingConfig.Valid(); err != nil {
+		return nil, err
+	}
+	if err := cfg.AnalyzeConfig.Valid(); err != nil {
+		return nil, err
+	}
+	if cfg.PartitionArchiveInterval < 0 {
+		return nil, fmt.Errorf("the partition archive interval must not be negative, got %s", cfg.PartitionArchiveInterval)
+	}
+	if cfg.DataCompletenessWindow < 0 {
+		return nil, fmt.Errorf("the data completeness window must not be negative, got %s", cfg.DataCompletenessWindow)
+	}
+	if err := cfg.AnomalyDetectionConfig.Valid(); err != nil {
+		return nil, err
+	}
+	if err := cfg.MonetaryRoundingConfig.Valid(); err != nil {
+		return nil, err
+	}
+	if err := cfg.PrestoSessions.Valid(); err != nil {
+		return nil, err
+	}
+	if err := cfg.BackupConfig.Valid(); err != nil {
+		return nil, err
+	}
+	if cfg.PrometheusImportJitterFactor < 0 || cfg.PrometheusImportJitterFactor > 1 {
+		return nil, fmt.Errorf("the Prometheus import jitter factor must be between 0 and 1, got %v", cfg.PrometheusImportJitterFactor)
+	}
+	if cfg.MaxConcurrentReports < 1 {
+		return nil, fmt.Errorf("the maximum number of concurrent reports must be at least 1, got %d", cfg.MaxConcurrentReports)
+	}
+	if cfg.ResyncPeriod < 0 {
+		return nil, fmt.Errorf("the resync period must not be negative, got %s", cfg.ResyncPeriod)
+	}
+	if err := cfg.AWSWebIdentityConfig.Valid(); err != nil {
+		return nil, err
+	}
+	if cfg.AWSWebIdentityConfig.Enabled() && cfg.SecretsConfig.AWSCredentials != "" {
+		return nil, fmt.Errorf("AWS credentials can't be retrieved from both a secret and a web identity token")
+	}
 
 	op.rand = rand.New(rand.NewSource(clock.Now().Unix()))
+	op.tableAnalyzer = newTableAnalyzer(logger.WithField("component", "tableAnalyzer"), clock, cfg.AnalyzeConfig)
+	if cfg.AnomalyDetectionConfig.Interval > 0 {
+		op.usageAnomalies = newUsageAnomalies()
+	}
+	if cfg.EnableFaultInjection {
+		logger.Warnf("fault injection is enabled, faults can be injected into the Prometheus importer using the %s endpoint", APIV1DebugFaultsEndpoint)
+		op.faultInjector = prestostore.NewFaultInjector(rand.New(rand.NewSource(clock.Now().UnixNano())))
+	}
 
 	configOverrides := &clientcmd.ConfigOverrides{}
 	var clientConfig clientcmd.ClientConfig
@@ -176,6 +564,40 @@ func New(logger log.FieldLogger, cfg Config, clock clock.Clock) (*Reporting, err
 	if err != nil {
 		return nil, fmt.Errorf("Unable to create Kubernetes client: %v", err)
 	}
+	op.appsClient, err = appsv1.NewForConfig(op.kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to create Kubernetes apps client: %v", err)
+	}
+	op.batchClient, err = batchv1.NewForConfig(op.kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to create Kubernetes batch client: %v", err)
+	}
+	op.cronJobClient, err = batchv1beta1.NewForConfig(op.kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to create Kubernetes batch/v1beta1 client: %v", err)
+	}
+
+	if cfg.APIAuthConfig.Enabled {
+		authenticationClient, err := authenticationv1.NewForConfig(op.kubeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to create Kubernetes authentication client: %v", err)
+		}
+		authorizationClient, err := authorizationv1.NewForConfig(op.kubeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to create Kubernetes authorization client: %v", err)
+		}
+		op.apiAuth = newAPIAuth(authenticationClient.TokenReviews(), authorizationClient.SubjectAccessReviews(), cfg.Namespace, cfg.APIAuthConfig.CacheTTL, clock)
+		op.apiAuth.rowLevelSecurity = cfg.APIAuthConfig.RowLevelSecurity
+	}
+	if cfg.AuditConfig.Enabled {
+		// nothing can be written to Presto in read-only mode, so audit
+		// events are only logged.
+		store := cfg.AuditConfig.StoreInPresto && !cfg.ReadOnly
+		if cfg.AuditConfig.StoreInPresto && cfg.ReadOnly {
+			logger.Warnf("audit events aren't stored in Presto in read-only mode")
+		}
+		op.audit = newAuditLogger(logger, clock, cfg.Namespace, store)
+	}
 
 	logger.Debugf("setting up Metering client...")
 	op.meteringClient, err = cbClientset.NewForConfig(op.kubeConfig)
@@ -183,25 +605,111 @@ func New(logger log.FieldLogger, cfg Config, clock clock.Clock) (*Reporting, err
 		return nil, fmt.Errorf("Unable to create Metering client: %v", err)
 	}
 
+	err = op.setupSecrets()
+	if err != nil {
+		return nil, err
+	}
+
 	op.setupInformers()
 	op.setupQueues()
 
+	op.reportAmendments = newReportAmendmentQueue()
 	op.scheduledReportRunner = newScheduledReportRunner(op)
 
 	logger.Debugf("configuring event listeners...")
 	return op, nil
 }
 
+// setupSecrets configures the secret providers, and the credentials
+// retrieved from them.
+func (op *Reporting) setupSecrets() error {
+	providers := []secrets.Provider{
+		secrets.NewKubernetesProvider(op.kubeClient, op.cfg.Namespace),
+		secrets.NewFileProvider(),
+	}
+	if op.cfg.SecretsConfig.VaultConfig.Address != "" {
+		providers = append(providers, secrets.NewVaultProvider(op.cfg.SecretsConfig.VaultConfig, nil))
+	}
+	op.secretResolver = secrets.NewResolver(op.logger, op.clock, op.cfg.SecretsConfig.CacheTTL, providers...)
+	if op.cfg.SecretsConfig.WatchKubernetesSecrets {
+		op.secretInformer = secrets.NewKubernetesSecretInformer(op.kubeClient, op.cfg.Namespace, op.cfg.ResyncPeriod, op.secretResolver)
+	}
+
+	if op.cfg.SecretsConfig.AWSCredentials != "" {
+		ref, err := secrets.ParseRef(op.cfg.SecretsConfig.AWSCredentials)
+		if err != nil {
+			return fmt.Errorf("invalid AWS credentials: %v", err)
+		}
+		op.awsCredentials = aws.NewSecretCredentials(op.secretResolver, ref)
+	} else if op.cfg.AWSWebIdentityConfig.Enabled() {
+		op.awsCredentials = aws.NewWebIdentityCredentials(op.cfg.AWSWebIdentityConfig)
+		op.logger.Infof("using AWS credentials from assuming role %s with web identity token %s", op.cfg.AWSWebIdentityConfig.RoleARN, op.cfg.AWSWebIdentityConfig.TokenFile)
+	}
+
+	op.prestoClientKeys = make(map[string]string)
+	for component, identity := range op.componentIdentities() {
+		credentials := identity.PrestoCredentials
+		if credentials == "" {
+			credentials = op.cfg.SecretsConfig.PrestoCredentials
+		}
+		if credentials == "" {
+			continue
+		}
+		ref, err := secrets.ParseRef(credentials)
+		if err != nil {
+			return fmt.Errorf("invalid Presto credentials for %s: %v", component, err)
+		}
+		// Presto only sends the password when using https, so the
+		// credentials are always set by the custom client's transport.
+		client := &http.Client{
+			Transport: secrets.NewBasicAuthRoundTripper(op.secretResolver, ref, "X-Presto-User", nil),
+		}
+		key := prestoCustomClientKeyPrefix + component
+		err = prestoclient.RegisterCustomClient(key, client)
+		if err != nil {
+			return err
+		}
+		op.prestoClientKeys[component] = key
+	}
+
+	if op.cfg.SecretsConfig.PrometheusBearerToken != "" {
+		if _, err := secrets.ParseRef(op.cfg.SecretsConfig.PrometheusBearerToken); err != nil {
+			return fmt.Errorf("invalid Prometheus bearer token: %v", err)
+		}
+	}
+	if op.cfg.SecretsConfig.KafkaCredentials != "" {
+		if _, err := secrets.ParseRef(op.cfg.SecretsConfig.KafkaCredentials); err != nil {
+			return fmt.Errorf("invalid Kafka credentials: %v", err)
+		}
+	}
+	return nil
+}
+
+// componentIdentities returns the identity of each component which accesses
+// Presto.
+func (op *Reporting) componentIdentities() map[string]ComponentIdentity {
+	return map[string]ComponentIdentity{
+		importerComponent:  op.cfg.ComponentIdentities.Importer,
+		reportingComponent: op.cfg.ComponentIdentities.Reporting,
+		apiComponent:       op.cfg.ComponentIdentities.API,
+	}
+}
+
 type queues struct {
 	queueList                  []workqueue.RateLimitingInterface
 	reportQueue                workqueue.RateLimitingInterface
 	scheduledReportQueue       workqueue.RateLimitingInterface
 	reportDataSourceQueue      workqueue.RateLimitingInterface
 	reportGenerationQueryQueue workqueue.RateLimitingInterface
+	storageLocationQueue       workqueue.RateLimitingInterface
 }
 
 func (op *Reporting) setupInformers() {
-	op.informers = cbInformers.NewFilteredSharedInformerFactory(op.meteringClient, defaultResyncPeriod, op.cfg.Namespace, nil)
+	namespace := op.cfg.Namespace
+	if op.cfg.EnableTenantNamespaces {
+		namespace = v1.NamespaceAll
+	}
+	op.informers = cbInformers.NewFilteredSharedInformerFactory(op.meteringClient, op.cfg.ResyncPeriod, namespace, nil)
 	inf := op.informers.Metering().V1alpha1()
 	// hacks to ensure these informers are created before we call
 	// op.informers.Start()
@@ -212,6 +720,8 @@ func (op *Reporting) setupInformers() {
 	inf.ReportPrometheusQueries().Informer()
 	inf.Reports().Informer()
 	inf.ScheduledReports().Informer()
+	inf.PricingModels().Informer()
+	inf.ReportTemplates().Informer()
 }
 func (op *Reporting) setupQueues() {
 	reportQueue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "reports")
@@ -221,13 +731,16 @@ func (op *Reporting) setupQueues() {
 			if err == nil {
 				reportQueue.Add(key)
 			}
+			op.enqueueFanOutParent(obj)
 		},
 		UpdateFunc: func(old, current interface{}) {
 			key, err := cache.MetaNamespaceKeyFunc(current)
 			if err == nil {
 				reportQueue.Add(key)
 			}
+			op.enqueueFanOutParent(current)
 		},
+		DeleteFunc: op.handleReportDeleted,
 	})
 
 	scheduledReportQueue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "scheduledreports")
@@ -260,9 +773,21 @@ func (op *Reporting) setupQueues() {
 			if err == nil {
 				reportDataSourceQueue.Add(key)
 			}
+			oldDataSource, currentDataSource := old.(*cbTypes.ReportDataSource), current.(*cbTypes.ReportDataSource)
+			if oldDataSource.TableName == "" && currentDataSource.TableName != "" {
+				op.enqueueUninitializedDependents(currentDataSource.Namespace)
+			}
 		},
 		DeleteFunc: op.handleReportDataSourceDeleted,
 	})
+	op.informers.Metering().V1alpha1().ReportPrometheusQueries().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			op.enqueueDataSourcesForPrometheusQuery(reportDataSourceQueue, obj)
+		},
+		UpdateFunc: func(old, current interface{}) {
+			op.enqueueDataSourcesForPrometheusQuery(reportDataSourceQueue, current)
+		},
+	})
 
 	reportGenerationQueryQueue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "reportgenerationqueries")
 	op.informers.Metering().V1alpha1().ReportGenerationQueries().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -277,19 +802,48 @@ func (op *Reporting) setupQueues() {
 			if err == nil {
 				reportGenerationQueryQueue.Add(key)
 			}
+			oldQuery, currentQuery := old.(*cbTypes.ReportGenerationQuery), current.(*cbTypes.ReportGenerationQuery)
+			if oldQuery.ViewName == "" && currentQuery.ViewName != "" {
+				op.enqueueUninitializedDependents(currentQuery.Namespace)
+			}
 		},
 	})
+
+	storageLocationQueue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "storagelocations")
+	op.informers.Metering().V1alpha1().StorageLocations().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			key, err := cache.MetaNamespaceKeyFunc(obj)
+			if err == nil {
+				storageLocationQueue.Add(key)
+			}
+		},
+		UpdateFunc: func(old, current interface{}) {
+			key, err := cache.MetaNamespaceKeyFunc(current)
+			if err == nil {
+				storageLocationQueue.Add(key)
+			}
+		},
+	})
+	if op.audit != nil {
+		inf := op.informers.Metering().V1alpha1()
+		inf.Reports().Informer().AddEventHandler(op.audit.resourceEventHandler("reports"))
+		inf.ScheduledReports().Informer().AddEventHandler(op.audit.resourceEventHandler("scheduledreports"))
+		inf.ReportDataSources().Informer().AddEventHandler(op.audit.resourceEventHandler("reportdatasources"))
+	}
+
 	op.queues = queues{
 		queueList: []workqueue.RateLimitingInterface{
 			reportQueue,
 			scheduledReportQueue,
 			reportDataSourceQueue,
 			reportGenerationQueryQueue,
+			storageLocationQueue,
 		},
 		reportQueue:                reportQueue,
 		scheduledReportQueue:       scheduledReportQueue,
 		reportDataSourceQueue:      reportDataSourceQueue,
 		reportGenerationQueryQueue: reportGenerationQueryQueue,
+		storageLocationQueue:       storageLocationQueue,
 	}
 
 }
@@ -303,7 +857,7 @@ func (qs queues) ShutdownQueues() {
 func (op *Reporting) Run(stopCh <-chan struct{}) error {
 	var wg sync.WaitGroup
 	// buffered big enough to hold the errs of each server we start.
-	srvErrChan := make(chan error, 3)
+	srvErrChan := make(chan error, 4)
 
 	op.logger.Info("starting Metering operator")
 
@@ -339,6 +893,9 @@ func (op *Reporting) Run(stopCh <-chan struct{}) error {
 	}()
 
 	go op.informers.Start(stopCh)
+	if op.secretInformer != nil {
+		go op.secretInformer.Run(stopCh)
+	}
 
 	op.logger.Infof("setting up DB connections")
 
@@ -348,26 +905,71 @@ func (op *Reporting) Run(stopCh <-chan struct{}) error {
 	var g errgroup.Group
 	g.Go(func() error {
 		var err error
-		op.prestoConn, err = op.newPrestoConn(stopCh)
+		op.prestoConn, err = op.newPrestoConn(stopCh, reportingComponent)
 		if err != nil {
 			return err
 		}
 		prestoDB := db.New(op.prestoConn, op.logger, op.cfg.LogDMLQueries)
-		op.prestoQueryer = presto.NewDB(prestoDB)
+		op.prestoQueryer = presto.NewInstrumentedDB(prestoDB, reportingComponent)
+
+		op.importerPrestoConn, err = op.newPrestoConn(stopCh, importerComponent)
+		if err != nil {
+			return err
+		}
+		importerPrestoDB := db.New(op.importerPrestoConn, op.logger.WithField("component", importerComponent), op.cfg.LogDMLQueries)
+		op.importerPrestoQueryer = presto.NewInstrumentedDB(importerPrestoDB, importerComponent)
+
+		op.apiPrestoConn, err = op.newPrestoConn(stopCh, apiComponent)
+		if err != nil {
+			return err
+		}
+		apiPrestoDB := db.New(op.apiPrestoConn, op.logger.WithField("component", apiComponent), op.cfg.LogDMLQueries)
+		op.apiPrestoQueryer = presto.NewInstrumentedDB(apiPrestoDB, apiComponent)
 		return nil
 	})
-	g.Go(func() error {
-		op.hiveQueryer = newHiveQueryer(op.logger, op.clock, op.cfg.HiveHost, op.cfg.LogDDLQueries, stopCh)
-		_, err := op.hiveQueryer.getHiveConnection()
-		return err
-	})
+	// Hive is only used to create tables, which isn't done in read-only
+	// mode.
+	if !op.cfg.ReadOnly {
+		g.Go(func() error {
+			hq := newHiveQueryer(op.logger, op.clock, op.cfg.HiveHost, op.cfg.LogDDLQueries, stopCh)
+			op.hiveQueryer = hq
+			_, err := hq.getHiveConnection()
+			return err
+		})
+	}
 	err := g.Wait()
 	if err != nil {
 		return err
 	}
 
 	defer op.prestoConn.Close()
-	defer op.hiveQueryer.closeHiveConnection()
+	defer op.importerPrestoConn.Close()
+	defer op.apiPrestoConn.Close()
+	if hq, ok := op.hiveQueryer.(*hiveQueryer); ok {
+		defer hq.closeHiveConnection()
+	}
+
+	// nothing is imported or generated in read-only mode, so there's
+	// nothing to publish.
+	if len(op.cfg.KafkaConfig.Brokers) != 0 && !op.cfg.ReadOnly {
+		op.kafkaPublisher, err = op.newKafkaPublisher(context.Background())
+		if err != nil {
+			return fmt.Errorf("unable to create Kafka producer: %v", err)
+		}
+		defer op.kafkaPublisher.close()
+	}
+
+	if op.audit != nil && op.audit.store {
+		err = op.createTableForStorageNoCR(op.logger, nil, auditTableName, auditHiveColumns)
+		if err != nil {
+			return fmt.Errorf("unable to create audit table %s: %v", auditTableName, err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			op.audit.run(stopCh, op.prestoQueryer, op.cfg.AuditConfig.FlushInterval)
+		}()
+	}
 
 	transportConfig, err := op.kubeConfig.TransportConfig()
 	if err != nil {
@@ -385,13 +987,32 @@ func (op *Reporting) Run(stopCh <-chan struct{}) error {
 		op.logger.Infof("using %s as CA for Prometheus", serviceServingCAFile)
 	}
 
-	op.promConn, err = op.newPrometheusConn(promapi.Config{
+	if op.cfg.SecretsConfig.PrometheusBearerToken != "" {
+		ref, err := secrets.ParseRef(op.cfg.SecretsConfig.PrometheusBearerToken)
+		if err != nil {
+			return err
+		}
+		roundTripper = secrets.NewBearerTokenRoundTripper(op.secretResolver, ref, roundTripper)
+		op.logger.Infof("using bearer token from %s for Prometheus", ref)
+	}
+
+	promClient, err := op.newPrometheusClient(promapi.Config{
 		Address:      op.cfg.PromHost,
 		RoundTripper: roundTripper,
 	})
 	if err != nil {
 		return err
 	}
+	op.prometheusClusters = []prometheusCluster{{
+		id:       op.cfg.ClusterID,
+		promConn: prom.NewAPI(promClient),
+		promAPI:  promquery.NewAPI(promClient),
+	}}
+	remoteClusters, err := op.newRemotePrometheusClusters()
+	if err != nil {
+		return err
+	}
+	op.prometheusClusters = append(op.prometheusClusters, remoteClusters...)
 
 	op.logger.Info("waiting for caches to sync")
 	for t, synced := range op.informers.WaitForCacheSync(stopCh) {
@@ -401,20 +1022,53 @@ func (op *Reporting) Run(stopCh <-chan struct{}) error {
 	}
 
 	op.logger.Infof("starting HTTP server")
-	listers := meteringListers{
-		reports:                 op.informers.Metering().V1alpha1().Reports().Lister().Reports(op.cfg.Namespace),
-		scheduledReports:        op.informers.Metering().V1alpha1().ScheduledReports().Lister().ScheduledReports(op.cfg.Namespace),
-		reportGenerationQueries: op.informers.Metering().V1alpha1().ReportGenerationQueries().Lister().ReportGenerationQueries(op.cfg.Namespace),
-		prestoTables:            op.informers.Metering().V1alpha1().PrestoTables().Lister().PrestoTables(op.cfg.Namespace),
+	listers := op.namespaceListers(op.cfg.Namespace)
+	if op.cfg.EnableTenantNamespaces {
+		listers.tenantListers = op.namespaceListers
 	}
 
-	apiRouter := newRouter(op.logger, op.prestoQueryer, op.rand, op.triggerPrometheusImporterForTimeRange, listers)
-	apiRouter.HandleFunc("/ready", op.readinessHandler)
-	apiRouter.HandleFunc("/healthy", op.healthinessHandler)
-
+	apiRouter := newRouter(op.logger, routerConfig{
+		rand:               op.rand,
+		queryer:            op.apiPrestoQueryer,
+		importerQueryer:    op.importerPrestoQueryer,
+		collectorFunc:      op.triggerPrometheusImporterForTimeRange,
+		reportRunQueryFunc: op.renderReportRunQuery,
+		dataStore:          op,
+		listers:            listers,
+		queryConfig:        op.cfg.QueryConfig,
+		monetaryRounding:   op.cfg.MonetaryRoundingConfig,
+		importerTelemetry:  op.importerTelemetry,
+		usageAnomalies:     op.usageAnomalies,
+		faultInjector:      op.faultInjector,
+		readOnly:           op.cfg.ReadOnly,
+		auth:               op.apiAuth,
+		audit:              op.audit,
+	})
+	apiRouter.HandleFunc("/readyz", op.readyzHandler)
+	apiRouter.HandleFunc("/healthz", op.healthzHandler)
+	// kept for probes configured before /readyz and /healthz were added
+	apiRouter.HandleFunc("/ready", op.readyzHandler)
+	apiRouter.HandleFunc("/healthy", op.healthzHandler)
+	apiRouter.HandleFunc(DefaultingWebhookEndpoint, op.defaultingWebhookHandler)
+
+	var apiHandler http.Handler = apiRouter
+	if op.cfg.APIClientCAFile != "" {
+		apiHandler = requireClientCertificate(op.logger, op.rand, apiRouter, "/readyz", "/healthz", "/ready", "/healthy", ConversionWebhookEndpoint, DeletionValidationWebhookEndpoint, ValidationWebhookEndpoint, DefaultingWebhookEndpoint)
+	}
 	httpServer := &http.Server{
 		Addr:    ":8080",
-		Handler: apiRouter,
+		Handler: apiHandler,
+	}
+	if op.cfg.APITLSConfig.UseTLS {
+		reloader, err := newCertificateReloader(op.logger.WithField("component", "api-tls"), op.cfg.APITLSConfig.TLSCert, op.cfg.APITLSConfig.TLSKey)
+		if err != nil {
+			return err
+		}
+		httpServer.TLSConfig, err = newServerTLSConfig(reloader, op.cfg.APIClientCAFile)
+		if err != nil {
+			return err
+		}
+		go reloader.run(stopCh, op.clock, op.cfg.CertificateReloadInterval)
 	}
 
 	// start the HTTP API server
@@ -424,7 +1078,8 @@ func (op *Reporting) Run(stopCh <-chan struct{}) error {
 		var srvErr error
 		if op.cfg.APITLSConfig.UseTLS {
 			op.logger.Infof("HTTP API server listening with TLS on 127.0.0.1:8080")
-			srvErr = httpServer.ListenAndServeTLS(op.cfg.APITLSConfig.TLSCert, op.cfg.APITLSConfig.TLSKey)
+			// the certificate is served by httpServer.TLSConfig.
+			srvErr = httpServer.ListenAndServeTLS("", "")
 		} else {
 			op.logger.Infof("HTTP API server listening on 127.0.0.1:8080")
 			srvErr = httpServer.ListenAndServe()
@@ -433,65 +1088,51 @@ func (op *Reporting) Run(stopCh <-chan struct{}) error {
 		srvErrChan <- fmt.Errorf("HTTP API server error: %v", srvErr)
 	}()
 
-	// Poll until we can write to presto
-	op.logger.Info("testing ability to write to Presto")
-	err = wait.PollUntil(time.Second*5, func() (bool, error) {
-		if op.testWriteToPresto(op.logger) {
-			return true, nil
+	var grpcAPIServer *grpc.Server
+	if op.cfg.GRPCConfig.Enabled {
+		var opts []grpc.ServerOption
+		if op.cfg.GRPCConfig.TLSConfig.UseTLS {
+			creds, err := newGRPCServerCredentials(op.cfg.GRPCConfig.TLSConfig, op.cfg.GRPCConfig.ClientCAFile)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, grpc.Creds(creds))
 		}
-		return false, nil
-	}, stopCh)
-	if err != nil {
-		return err
-	}
-	op.logger.Info("writes to Presto are succeeding")
-
-	op.logger.Info("basic initialization completed")
-	op.setInitialized()
-
-	eventBroadcaster := record.NewBroadcaster()
-	eventBroadcaster.StartLogging(op.logger.Infof)
-	eventBroadcaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: op.kubeClient.Events(op.cfg.Namespace)})
-	eventRecorder := eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: op.cfg.PodName})
+		grpcListener, err := net.Listen("tcp", ":8083")
+		if err != nil {
+			return fmt.Errorf("unable to listen for the gRPC API: %v", err)
+		}
+		grpcAPIServer = newGRPCServer(op.logger.WithField("component", "grpc"), op.apiPrestoQueryer, op.meteringClient, op.cfg.Namespace, listers, op.apiAuth, op.cfg.ReadOnly, op.cfg.APIAuthConfig.RowLevelSecurity.Enabled, opts...)
 
-	rl, err := resourcelock.New(resourcelock.ConfigMapsResourceLock,
-		op.cfg.Namespace, "reporting-operator-leader-lease", op.kubeClient,
-		resourcelock.ResourceLockConfig{
-			Identity:      op.cfg.Hostname,
-			EventRecorder: eventRecorder,
-		})
-	if err != nil {
-		return fmt.Errorf("error creating lock %v", err)
+		// start the gRPC API server
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if op.cfg.GRPCConfig.TLSConfig.UseTLS {
+				op.logger.Infof("gRPC API server listening with TLS on 127.0.0.1:8083")
+			} else {
+				op.logger.Infof("gRPC API server listening on 127.0.0.1:8083")
+			}
+			srvErr := grpcAPIServer.Serve(grpcListener)
+			op.logger.WithError(srvErr).Info("gRPC API server exited")
+			srvErrChan <- fmt.Errorf("gRPC API server error: %v", srvErr)
+		}()
 	}
 
 	stopWorkersCh := make(chan struct{})
-	lostLeaderCh := make(chan struct{})
-
-	leader, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
-		Lock:          rl,
-		LeaseDuration: op.cfg.LeaderLeaseDuration,
-		RenewDeadline: op.cfg.LeaderLeaseDuration / 2,
-		RetryPeriod:   2 * time.Second,
-		Callbacks: leaderelection.LeaderCallbacks{
-			OnStartedLeading: func(leaderStopCh <-chan struct{}) {
-				op.logger.Infof("became leader")
-				op.logger.Info("starting Metering workers")
-				op.startWorkers(wg, stopWorkersCh)
-				op.logger.Infof("Metering workers started, watching for reports...")
-			},
-			OnStoppedLeading: func() {
-				op.logger.Warn("leader election lost")
-				close(lostLeaderCh)
-			},
-		},
-	})
-	if err != nil {
-		return fmt.Errorf("error creating leader elector: %v", err)
+	var lostLeaderCh <-chan struct{}
+	var leaderLock *releasableLock
+	if op.cfg.ReadOnly {
+		op.logger.Info("running in read-only mode, not starting workers")
+		op.logger.Info("basic initialization completed")
+		op.setInitialized()
+	} else {
+		lostLeaderCh, leaderLock, err = op.startLeaderElection(&wg, stopCh, stopWorkersCh)
+		if err != nil {
+			return err
+		}
 	}
 
-	op.logger.Infof("starting leader election")
-	go leader.Run()
-
 	// wait for an shutdown signal to begin shutdown.
 	// if we lose leadership or an error occurs from one of our server
 	// processes exit immediately.
@@ -536,6 +1177,14 @@ func (op *Reporting) Run(stopCh <-chan struct{}) error {
 		}
 		wg.Done()
 	}()
+	if grpcAPIServer != nil {
+		wg.Add(1)
+		go func() {
+			op.logger.Infof("stopping gRPC API server")
+			grpcAPIServer.GracefulStop()
+			wg.Done()
+		}()
+	}
 
 	// shutdown queues so that they get drained, and workers can begin their
 	// shutdown
@@ -544,10 +1193,153 @@ func (op *Reporting) Run(stopCh <-chan struct{}) error {
 	// wait for our workers to stop
 	wg.Wait()
 	op.logger.Info("Metering workers and collectors stopped")
+
+	// Now that no imports are running, give up leadership so a standby
+	// replica takes over without waiting for the lease to expire. It
+	// resumes importing from the last timestamp stored in each table.
+	if leaderLock != nil {
+		released, err := leaderLock.release()
+		if err != nil {
+			op.logger.WithError(err).Warnf("unable to release leader election lock, standby replicas will take over once the lease expires")
+		} else if released {
+			op.logger.Infof("released leader election lock")
+		}
+	}
 	return nil
 }
 
-func (op *Reporting) startWorkers(wg sync.WaitGroup, stopCh <-chan struct{}) {
+// startLeaderElection waits until Presto can be written to, then starts the
+// workers once the reporting-operator becomes the leader. The returned
+// channel is closed if leadership is lost, and the returned lock should be
+// released once the workers have stopped.
+func (op *Reporting) startLeaderElection(wg *sync.WaitGroup, stopCh <-chan struct{}, stopWorkersCh chan struct{}) (<-chan struct{}, *releasableLock, error) {
+	// Poll until we can write to presto
+	op.logger.Info("testing ability to write to Presto")
+	err := wait.PollUntil(time.Second*5, func() (bool, error) {
+		if op.testWriteToPresto(op.logger) == nil {
+			return true, nil
+		}
+		return false, nil
+	}, stopCh)
+	if err != nil {
+		return nil, nil, err
+	}
+	op.logger.Info("writes to Presto are succeeding")
+
+	op.logger.Info("basic initialization completed")
+	op.setInitialized()
+
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(op.logger.Infof)
+	eventBroadcaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: op.kubeClient.Events(op.cfg.Namespace)})
+	// register the metering types so events can be recorded for them.
+	cbScheme.AddToScheme(scheme.Scheme)
+	eventRecorder := eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: op.cfg.PodName})
+	op.eventRecorder = eventRecorder
+
+	lockName := op.cfg.ShardingConfig.leaderElectionLockName()
+	rl, err := resourcelock.New(resourcelock.ConfigMapsResourceLock,
+		op.cfg.Namespace, lockName, op.kubeClient,
+		resourcelock.ResourceLockConfig{
+			Identity:      op.cfg.Hostname,
+			EventRecorder: eventRecorder,
+		})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating lock %v", err)
+	}
+	lock := newReleasableLock(rl, op.kubeClient.ConfigMaps(op.cfg.Namespace), lockName)
+
+	lostLeaderCh := make(chan struct{})
+
+	// OnStartedLeading adds the workers to wg, which may happen while Run
+	// is waiting on wg during shutdown, so wg holds the leader election
+	// until either the workers are started, or the workers are stopped
+	// before becoming leader, after which no workers are started.
+	var (
+		leadingMu       sync.Mutex
+		leadingReleased bool
+	)
+	releaseLeading := func() {
+		if !leadingReleased {
+			leadingReleased = true
+			wg.Done()
+		}
+	}
+
+	leader, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: op.cfg.LeaderLeaseDuration,
+		RenewDeadline: op.cfg.LeaderLeaseDuration / 2,
+		RetryPeriod:   2 * time.Second,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(leaderStopCh <-chan struct{}) {
+				op.logger.Infof("became leader")
+				op.setLeader(true)
+				leadingMu.Lock()
+				defer leadingMu.Unlock()
+				if leadingReleased {
+					op.logger.Info("workers are stopping, not starting Metering workers")
+					return
+				}
+				op.logger.Info("starting Metering workers")
+				op.startWorkers(wg, stopWorkersCh)
+				op.logger.Infof("Metering workers started, watching for reports...")
+				releaseLeading()
+			},
+			OnStoppedLeading: func() {
+				op.logger.Warn("leader election lost")
+				op.setLeader(false)
+				close(lostLeaderCh)
+			},
+			OnNewLeader: func(identity string) {
+				op.logger.Infof("%s is the leader", identity)
+			},
+		},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating leader elector: %v", err)
+	}
+
+	op.logger.Infof("starting leader election")
+	wg.Add(1)
+	go func() {
+		<-stopWorkersCh
+		leadingMu.Lock()
+		defer leadingMu.Unlock()
+		releaseLeading()
+	}()
+	go leader.Run()
+	return lostLeaderCh, lock, nil
+}
+
+func (op *Reporting) startWorkers(wg *sync.WaitGroup, stopCh <-chan struct{}) {
+	// tables are recovered before any worker runs, so that ReportDataSources
+	// and reports don't find their tables missing.
+	if op.cfg.RecoverTables && op.cfg.ShardingConfig.isPrimary() {
+		if _, err := op.recoverTables(op.logger); err != nil {
+			op.logger.WithError(err).Errorf("unable to recover the tables of the Hive metastore")
+		}
+	}
+
+	op.startDataSourceWorkers(wg, stopCh)
+
+	if op.tableAnalyzer != nil {
+		wg.Add(1)
+		go func() {
+			op.logger.Debugf("starting table analyzer")
+			op.tableAnalyzer.run(stopCh, op.importerPrestoQueryer, op.prestoQueryer)
+			wg.Done()
+			op.logger.Debugf("table analyzer stopped")
+		}()
+	}
+
+	// ReportDataSources are split between shards, but everything else is
+	// handled by the primary shard.
+	if !op.cfg.ShardingConfig.isPrimary() {
+		op.logger.Infof("running as shard %d of %d, only importing the ReportDataSources owned by this shard", op.cfg.ShardingConfig.Index, op.cfg.ShardingConfig.Shards)
+		return
+	}
+
 	wg.Add(1)
 	go func() {
 		op.logger.Infof("starting PrestoTable worker")
@@ -556,17 +1348,41 @@ func (op *Reporting) startWorkers(wg sync.WaitGroup, stopCh <-chan struct{}) {
 		op.logger.Infof("PrestoTable worker stopped")
 	}()
 
-	threadiness := 2
-	for i := 0; i < threadiness; i++ {
-		i := i
+	wg.Add(1)
+	go func() {
+		op.logger.Infof("starting StorageLocation worker")
+		wait.Until(op.runStorageLocationWorker, time.Second, stopCh)
+		wg.Done()
+		op.logger.Infof("StorageLocation worker stopped")
+	}()
 
+	if op.cfg.PartitionArchiveInterval > 0 {
 		wg.Add(1)
 		go func() {
-			op.logger.Infof("starting ReportDataSource worker #%d", i)
-			wait.Until(op.runReportDataSourceWorker, time.Second, stopCh)
+			op.logger.Infof("starting partition archiver")
+			op.runPartitionArchiver(stopCh, op.cfg.PartitionArchiveInterval)
 			wg.Done()
-			op.logger.Infof("ReportDataSource worker #%d stopped", i)
+			op.logger.Infof("partition archiver stopped")
 		}()
+	}
+
+	if op.cfg.BackupConfig.Enabled() {
+		backupCfg := op.cfg.BackupConfig
+		backupCfg.Namespace = op.cfg.Namespace
+		backupCfg.TenantNamespaces = op.cfg.EnableTenantNamespaces
+		backuper := backup.NewBackuper(op.logger.WithField("component", "backuper"), backupCfg, op.clock, op.hiveQueryer, op.prestoQueryer, op.meteringClient.MeteringV1alpha1())
+		wg.Add(1)
+		go func() {
+			op.logger.Infof("starting backuper, backing up to %s every %s", backupCfg.Location, backupCfg.Interval)
+			backuper.Run(stopCh)
+			wg.Done()
+			op.logger.Infof("backuper stopped")
+		}()
+	}
+
+	threadiness := 2
+	for i := 0; i < threadiness; i++ {
+		i := i
 
 		wg.Add(1)
 		go func() {
@@ -579,7 +1395,7 @@ func (op *Reporting) startWorkers(wg sync.WaitGroup, stopCh <-chan struct{}) {
 		wg.Add(1)
 		go func() {
 			op.logger.Infof("starting Report worker #%d", i)
-			wait.Until(op.runReportWorker, time.Second, stopCh)
+			wait.Until(func() { op.runReportWorker(stopCh) }, time.Second, stopCh)
 			wg.Done()
 			op.logger.Infof("Report worker #%d stopped", i)
 		}()
@@ -601,6 +1417,60 @@ func (op *Reporting) startWorkers(wg sync.WaitGroup, stopCh <-chan struct{}) {
 		op.logger.Debugf("ScheduledReportRunner stopped")
 	}()
 
+	wg.Add(1)
+	go func() {
+		op.logger.Debugf("starting ScheduledReport watchdog")
+		op.runScheduledReportWatchdog(stopCh)
+		wg.Done()
+		op.logger.Debugf("ScheduledReport watchdog stopped")
+	}()
+
+}
+
+// startDataSourceWorkers starts the workers which create the tables of
+// ReportDataSources and import their data.
+func (op *Reporting) startDataSourceWorkers(wg *sync.WaitGroup, stopCh <-chan struct{}) {
+	threadiness := 2
+	for i := 0; i < threadiness; i++ {
+		i := i
+
+		wg.Add(1)
+		go func() {
+			op.logger.Infof("starting ReportDataSource worker #%d", i)
+			wait.Until(op.runReportDataSourceWorker, time.Second, stopCh)
+			wg.Done()
+			op.logger.Infof("ReportDataSource worker #%d stopped", i)
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		op.logger.Debugf("starting ReportDataSource validator")
+		op.runReportDataSourceValidator(stopCh)
+		wg.Done()
+		op.logger.Debugf("ReportDataSource validator stopped")
+	}()
+
+	if op.cfg.DataCompletenessWindow > 0 {
+		wg.Add(1)
+		go func() {
+			op.logger.Debugf("starting ReportDataSource completeness checker")
+			op.runReportDataSourceCompletenessChecker(stopCh, op.cfg.DataCompletenessWindow)
+			wg.Done()
+			op.logger.Debugf("ReportDataSource completeness checker stopped")
+		}()
+	}
+
+	if op.cfg.AnomalyDetectionConfig.Interval > 0 {
+		wg.Add(1)
+		go func() {
+			op.logger.Debugf("starting usage anomaly detector")
+			op.runUsageAnomalyDetector(stopCh)
+			wg.Done()
+			op.logger.Debugf("usage anomaly detector stopped")
+		}()
+	}
+
 	wg.Add(1)
 	go func() {
 		op.logger.Debugf("starting PrometheusImport worker")
@@ -608,6 +1478,38 @@ func (op *Reporting) startWorkers(wg sync.WaitGroup, stopCh <-chan struct{}) {
 		wg.Done()
 		op.logger.Debugf("PrometheusImport worker stopped")
 	}()
+
+	wg.Add(1)
+	go func() {
+		op.logger.Debugf("starting KubernetesObjects collector worker")
+		op.runKubernetesObjectsCollectorWorker(stopCh)
+		wg.Done()
+		op.logger.Debugf("KubernetesObjects collector worker stopped")
+	}()
+
+	wg.Add(1)
+	go func() {
+		op.logger.Debugf("starting KubernetesEvents collector worker")
+		op.runKubernetesEventsCollectorWorker(stopCh)
+		wg.Done()
+		op.logger.Debugf("KubernetesEvents collector worker stopped")
+	}()
+
+	wg.Add(1)
+	go func() {
+		op.logger.Debugf("starting RemoteReport loader worker")
+		op.runRemoteReportLoaderWorker(stopCh)
+		wg.Done()
+		op.logger.Debugf("RemoteReport loader worker stopped")
+	}()
+
+	wg.Add(1)
+	go func() {
+		op.logger.Debugf("starting TableLoader worker")
+		op.runTableLoaderWorker(stopCh)
+		wg.Done()
+		op.logger.Debugf("TableLoader worker stopped")
+	}()
 }
 
 func (op *Reporting) setInitialized() {
@@ -623,6 +1525,26 @@ func (op *Reporting) isInitialized() bool {
 	return initialized
 }
 
+func (op *Reporting) setLeader(leader bool) {
+	op.leaderMu.Lock()
+	op.leader = leader
+	op.leaderMu.Unlock()
+	if leader {
+		leaderGauge.Set(1)
+	} else {
+		leaderGauge.Set(0)
+	}
+}
+
+// isLeader returns true if this replica holds the leader election lock, and
+// is running the workers and importers.
+func (op *Reporting) isLeader() bool {
+	op.leaderMu.Lock()
+	leader := op.leader
+	op.leaderMu.Unlock()
+	return leader
+}
+
 // getKeyFromQueueObj tries to convert the object from the queue into a string,
 // and if it isn't, it forgets the key from the queue, and logs an error.
 //
@@ -668,13 +1590,23 @@ func (op *Reporting) getDefaultReportGracePeriod() time.Duration {
 	}
 }
 
-func (op *Reporting) newPrestoConn(stopCh <-chan struct{}) (*sql.DB, error) {
+// newPrestoConn returns a Presto connection using the identity of the
+// component.
+func (op *Reporting) newPrestoConn(stopCh <-chan struct{}, component string) (*sql.DB, error) {
 	// Presto may take longer to start than reporting-operator, so keep
 	// attempting to connect in a loop in case we were just started and presto
 	// is still coming up.
-	connStr := fmt.Sprintf("http://root@%s?catalog=hive&schema=default", op.cfg.PrestoHost)
+	user := op.componentIdentities()[component].PrestoUser
+	if user == "" {
+		user = DefaultPrestoUser
+	}
+	connStr := fmt.Sprintf("http://%s@%s?catalog=hive&schema=default", url.User(user).String(), op.cfg.PrestoHost)
+	if key, ok := op.prestoClientKeys[component]; ok {
+		connStr += "&custom_client=" + key
+	}
+	connStr += "&" + prestoSessionParams(component, op.cfg.PrestoSessions.byComponent()[component]).Encode()
 	startTime := op.clock.Now()
-	op.logger.Debugf("getting Presto connection")
+	op.logger.Debugf("getting Presto connection for %s", component)
 	for {
 		db, err := sql.Open("presto", connStr)
 		if err == nil {
@@ -692,12 +1624,12 @@ func (op *Reporting) newPrestoConn(stopCh <-chan struct{}) (*sql.DB, error) {
 	}
 }
 
-func (op *Reporting) newPrometheusConn(promConfig promapi.Config) (prom.API, error) {
+func (op *Reporting) newPrometheusClient(promConfig promapi.Config) (promapi.Client, error) {
 	client, err := promapi.NewClient(promConfig)
 	if err != nil {
 		return nil, fmt.Errorf("can't connect to prometheus: %v", err)
 	}
-	return prom.NewAPI(client), nil
+	return client, nil
 }
 
 type hiveQueryer struct {
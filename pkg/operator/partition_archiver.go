@@ -0,0 +1,290 @@
+package operator
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	// DefaultPartitionArchiveInterval is how often the partitions of
+	// ReportDataSources with an archive are checked, and moved to their
+	// archive once they're old enough.
+	DefaultPartitionArchiveInterval = time.Hour
+
+	// minPartitionArchiveAfter is the shortest archive after, as the
+	// metrics of a day are only archived once the whole day is older than
+	// it.
+	minPartitionArchiveAfter = 24 * time.Hour
+
+	partitionArchiveResultSuccess = "success"
+	partitionArchiveResultFailure = "failure"
+)
+
+var (
+	promsumDatePartitionColumns = []hive.Column{
+		{Name: prestostore.DatePartitionColumn, Type: "string"},
+	}
+
+	partitionArchiveCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "metering",
+			Name:      "partition_archive_total",
+			Help:      "The number of ReportDataSource table partitions moved to their archive StorageLocation, by whether moving them succeeded.",
+		},
+		[]string{"result"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(partitionArchiveCounter)
+}
+
+// validatePrometheusMetricsArchive returns an error if the archive of a
+// Prometheus ReportDataSource is invalid.
+func validatePrometheusMetricsArchive(archive *cbTypes.PrometheusMetricsArchive) error {
+	if archive == nil {
+		return nil
+	}
+	if archive.After.Duration < minPartitionArchiveAfter {
+		return fmt.Errorf("archive.after must be at least %s, got %s", minPartitionArchiveAfter, archive.After.Duration)
+	}
+	if archive.Storage == nil || (archive.Storage.StorageLocationName == "" && archive.Storage.StorageSpec == nil) {
+		return fmt.Errorf("archive.storage must be set")
+	}
+	return nil
+}
+
+// isDatePartitionedDataSource returns true if the ReportDataSource's table
+// is partitioned by the date of its Prometheus metrics, which it is when
+// the ReportDataSource has an archive.
+func isDatePartitionedDataSource(dataSource *cbTypes.ReportDataSource) bool {
+	return dataSource.Spec.Promsum != nil && dataSource.Spec.Promsum.Archive != nil
+}
+
+// checkDataSourceTablePartitioning returns an error if the ReportDataSource's
+// table was created with different partitions than the metrics imported into
+// it would be stored in, which happens if an archive is added to or removed
+// from the ReportDataSource after its table was created.
+func (op *Reporting) checkDataSourceTablePartitioning(dataSource *cbTypes.ReportDataSource) error {
+	if dataSource.TableName == "" {
+		return nil
+	}
+	prestoTable, err := op.informers.Metering().V1alpha1().PrestoTables().Lister().PrestoTables(dataSource.Namespace).Get(prestoTableResourceNameFromKind("ReportDataSource", dataSource.Name))
+	if err != nil {
+		// tables created before PrestoTables were recorded aren't
+		// partitioned.
+		if apierrors.IsNotFound(err) {
+			if isDatePartitionedDataSource(dataSource) {
+				return fmt.Errorf("table %s isn't partitioned by date, the ReportDataSource must be recreated to add an archive", dataSource.TableName)
+			}
+			return nil
+		}
+		return err
+	}
+	switch partitioned := isDatePartitionedPrestoTable(prestoTable); {
+	case partitioned && !isDatePartitionedDataSource(dataSource):
+		return fmt.Errorf("table %s is partitioned by date, the ReportDataSource must be recreated to remove its archive", dataSource.TableName)
+	case !partitioned && isDatePartitionedDataSource(dataSource):
+		return fmt.Errorf("table %s isn't partitioned by date, the ReportDataSource must be recreated to add an archive", dataSource.TableName)
+	}
+	return nil
+}
+
+// isDatePartitionedPrestoTable returns true if the table is partitioned by
+// the date of its Prometheus metrics.
+func isDatePartitionedPrestoTable(prestoTable *cbTypes.PrestoTable) bool {
+	partitions := prestoTable.State.Parameters.Partitions
+	return len(partitions) == 1 && partitions[0].Name == prestostore.DatePartitionColumn
+}
+
+// datePartitionsToArchive returns the dates of the partitions containing
+// metrics older than cutoff which haven't been archived, sorted.
+func datePartitionsToArchive(dates []string, archived []cbTypes.TablePartition, cutoff time.Time) []string {
+	archivedDates := make(map[string]bool)
+	for _, partition := range archived {
+		archivedDates[partition.PartitionSpec[prestostore.DatePartitionColumn]] = true
+	}
+	// the partition of a date contains metrics newer than cutoff unless the
+	// date is before cutoff's date.
+	cutoffDate := prestostore.DatePartition(cutoff)
+	var toArchive []string
+	for _, date := range dates {
+		if date < cutoffDate && !archivedDates[date] {
+			toArchive = append(toArchive, date)
+		}
+	}
+	sort.Strings(toArchive)
+	return toArchive
+}
+
+// runPartitionArchiver moves the partitions of ReportDataSources with an
+// archive to their archive StorageLocation every interval until stopCh is
+// closed.
+func (op *Reporting) runPartitionArchiver(stopCh <-chan struct{}, interval time.Duration) {
+	logger := op.logger.WithField("component", "partitionArchiver")
+	tick := op.clock.Tick(interval)
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-tick:
+		}
+		dataSources, err := op.informers.Metering().V1alpha1().ReportDataSources().Lister().ReportDataSources(op.cfg.Namespace).List(labels.Everything())
+		if err != nil {
+			logger.WithError(err).Errorf("unable to list ReportDataSources")
+			continue
+		}
+		for _, dataSource := range dataSources {
+			select {
+			case <-stopCh:
+				return
+			default:
+			}
+			if dataSource.Spec.Promsum == nil || dataSource.Spec.Promsum.Archive == nil || dataSource.TableName == "" {
+				continue
+			}
+			dataSourceLogger := logger.WithFields(log.Fields{"reportDataSource": dataSource.Name, "tableName": dataSource.TableName})
+			if err := op.archiveDataSourcePartitions(dataSourceLogger, dataSource); err != nil {
+				dataSourceLogger.WithError(err).Errorf("unable to archive the partitions of ReportDataSource %s", dataSource.Name)
+			}
+		}
+	}
+}
+
+// archiveDataSourcePartitions moves the partitions of the ReportDataSource's
+// table which are older than its archive's after to its archive
+// StorageLocation, one at a time, recording each partition moved in its
+// PrestoTable.
+func (op *Reporting) archiveDataSourcePartitions(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
+	archive := dataSource.Spec.Promsum.Archive
+	if err := validatePrometheusMetricsArchive(archive); err != nil {
+		return err
+	}
+	prestoTable, err := op.informers.Metering().V1alpha1().PrestoTables().Lister().PrestoTables(dataSource.Namespace).Get(prestoTableResourceNameFromKind("ReportDataSource", dataSource.Name))
+	if err != nil {
+		return err
+	}
+	if !isDatePartitionedPrestoTable(prestoTable) {
+		return fmt.Errorf("table %s isn't partitioned by date, the ReportDataSource must be recreated for its partitions to be archived", dataSource.TableName)
+	}
+	archiveProperties, err := op.getHiveTableProperties(logger, archive.Storage, "ReportDataSource archive")
+	if err != nil {
+		return fmt.Errorf("archive storage incorrectly configured: %v", err)
+	}
+
+	tableName := dataSource.TableName
+	rows, err := op.prestoQueryer.Query(fmt.Sprintf(`SELECT %s FROM "%s$partitions"`, prestostore.DatePartitionColumn, tableName))
+	if err != nil {
+		return fmt.Errorf("unable to list the partitions of table %s: %v", tableName, err)
+	}
+	dates := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if date, ok := row[prestostore.DatePartitionColumn].(string); ok {
+			dates = append(dates, date)
+		}
+	}
+
+	toArchive := datePartitionsToArchive(dates, prestoTable.State.Partitions, op.clock.Now().Add(-archive.After.Duration))
+	if len(toArchive) == 0 {
+		return nil
+	}
+	prestoTable = prestoTable.DeepCopy()
+	for _, date := range toArchive {
+		location, err := op.archivePartition(logger, tableName, hive.TableProperties(prestoTable.State.Properties), *archiveProperties, date)
+		if err != nil {
+			partitionArchiveCounter.WithLabelValues(partitionArchiveResultFailure).Inc()
+			return fmt.Errorf("unable to archive partition %s=%s of table %s: %v", prestostore.DatePartitionColumn, date, tableName, err)
+		}
+		partitionArchiveCounter.WithLabelValues(partitionArchiveResultSuccess).Inc()
+		logger.Infof("archived partition %s=%s of table %s to %s", prestostore.DatePartitionColumn, date, tableName, location)
+
+		prestoTable.State.Partitions = append(prestoTable.State.Partitions, cbTypes.TablePartition{
+			Location:      location,
+			PartitionSpec: presto.PartitionSpec{prestostore.DatePartitionColumn: date},
+		})
+		newPrestoTable, err := op.meteringClient.MeteringV1alpha1().PrestoTables(prestoTable.Namespace).Update(prestoTable)
+		if err != nil {
+			return fmt.Errorf("unable to record the archived partitions of table %s in PrestoTable %s: %v", tableName, prestoTable.Name, err)
+		}
+		prestoTable = newPrestoTable
+	}
+	return nil
+}
+
+// archivePartition copies the metrics of the date's partition of the table
+// to a new directory in the archive storage, points the partition at it,
+// and deletes the partition's previous directory, returning the
+// partition's new location.
+//
+// The metrics are copied through an external staging table, which Presto
+// inserts into, and whose directory is kept when it's dropped. Each attempt
+// uses a new directory, so a failed attempt never leaves rows behind which a
+// retry would duplicate.
+func (op *Reporting) archivePartition(logger log.FieldLogger, tableName string, tableProperties, archiveProperties hive.TableProperties, date string) (string, error) {
+	partitionDir := fmt.Sprintf("%s=%s", prestostore.DatePartitionColumn, date)
+	archiveProperties, err := addTableNameToLocation(archiveProperties, path.Join(tableName, fmt.Sprintf("%s-%d", partitionDir, op.clock.Now().UnixNano())))
+	if err != nil {
+		return "", err
+	}
+	archiveProperties.External = true
+	location := archiveProperties.Location
+
+	stagingTableName := fmt.Sprintf("%s_archive_%s", tableName, strings.Replace(date, "-", "", -1))
+	// a staging table left behind by a failed attempt is external, so
+	// dropping it keeps its rows in the failed attempt's directory.
+	if err := hive.ExecuteDropTable(op.hiveQueryer, stagingTableName, true); err != nil {
+		return "", err
+	}
+	stagingParams := hive.TableParameters{Name: stagingTableName, Columns: promsumHiveColumns}
+	if err := hive.ExecuteCreateTable(op.hiveQueryer, stagingParams, archiveProperties); err != nil {
+		return "", fmt.Errorf("couldn't create staging table %s: %v", stagingTableName, err)
+	}
+	logger.Debugf("copying partition %s of table %s to %s", partitionDir, tableName, location)
+	query := fmt.Sprintf(`SELECT amount, "timestamp", timeprecision, labels FROM %s WHERE %s = '%s'`, tableName, prestostore.DatePartitionColumn, date)
+	if err := presto.InsertInto(op.importerPrestoQueryer, stagingTableName, query); err != nil {
+		return "", fmt.Errorf("couldn't copy the partition to staging table %s: %v", stagingTableName, err)
+	}
+	if err := hive.ExecuteDropTable(op.hiveQueryer, stagingTableName, true); err != nil {
+		return "", err
+	}
+
+	stmt := fmt.Sprintf("ALTER TABLE %s PARTITION (`%s`='%s') SET LOCATION '%s'", tableName, prestostore.DatePartitionColumn, date, location)
+	if _, err := op.hiveQueryer.Query(stmt); err != nil {
+		return "", fmt.Errorf("couldn't set the location of the partition: %v", err)
+	}
+
+	// Hive doesn't delete a partition's previous directory when its
+	// location is changed, so it's deleted by dropping a table over it.
+	// Partitions are written by Presto to a directory named after them
+	// within the table's location.
+	if tableProperties.Location == "" {
+		logger.Warnf("table %s has no location, the previous directory of partition %s must be deleted manually", tableName, partitionDir)
+		return location, nil
+	}
+	previousProperties, err := addTableNameToLocation(hive.TableProperties{Location: tableProperties.Location}, partitionDir)
+	if err != nil {
+		return "", err
+	}
+	cleanupParams := hive.TableParameters{Name: stagingTableName, Columns: promsumHiveColumns}
+	err = hive.ExecuteCreateTable(op.hiveQueryer, cleanupParams, previousProperties)
+	if err == nil {
+		err = hive.ExecuteDropTable(op.hiveQueryer, stagingTableName, true)
+	}
+	if err != nil {
+		logger.WithError(err).Warnf("unable to delete the previous directory %s of partition %s of table %s", previousProperties.Location, partitionDir, tableName)
+	}
+	return location, nil
+}
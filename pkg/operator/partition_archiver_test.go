@@ -0,0 +1,55 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+func TestDatePartitionsToArchive(t *testing.T) {
+	archived := []cbTypes.TablePartition{
+		{Location: "s3a://archive/datasource_pod_cpu_request/dt=2018-07-01-1", PartitionSpec: presto.PartitionSpec{"dt": "2018-07-01"}},
+	}
+	dates := []string{"2018-07-04", "2018-07-01", "2018-07-03", "2018-07-02"}
+	// the partition of the cutoff's date still has metrics newer than the
+	// cutoff.
+	cutoff := time.Date(2018, time.July, 3, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"2018-07-02"}, datePartitionsToArchive(dates, archived, cutoff))
+
+	assert.Empty(t, datePartitionsToArchive(dates, archived, time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, []string{"2018-07-02", "2018-07-03", "2018-07-04"}, datePartitionsToArchive(dates, archived, time.Date(2018, time.July, 5, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestValidatePrometheusMetricsArchive(t *testing.T) {
+	storage := &cbTypes.StorageLocationRef{StorageLocationName: "archive"}
+	tests := map[string]struct {
+		archive     *cbTypes.PrometheusMetricsArchive
+		expectedErr bool
+	}{
+		"no archive": {},
+		"valid": {
+			archive: &cbTypes.PrometheusMetricsArchive{After: meta.Duration{Duration: 30 * 24 * time.Hour}, Storage: storage},
+		},
+		"after less than a day": {
+			archive:     &cbTypes.PrometheusMetricsArchive{After: meta.Duration{Duration: time.Hour}, Storage: storage},
+			expectedErr: true,
+		},
+		"no storage": {
+			archive:     &cbTypes.PrometheusMetricsArchive{After: meta.Duration{Duration: 30 * 24 * time.Hour}},
+			expectedErr: true,
+		},
+	}
+	for name, test := range tests {
+		err := validatePrometheusMetricsArchive(test.archive)
+		if test.expectedErr {
+			assert.Error(t, err, name)
+		} else {
+			assert.NoError(t, err, name)
+		}
+	}
+}
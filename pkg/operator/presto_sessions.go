@@ -0,0 +1,95 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// prestoSessionPropertyRE matches the names of Presto system session
+// properties, such as query_max_memory, and catalog session properties,
+// such as hive.orc_bloom_filters_enabled.
+var prestoSessionPropertyRE = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)?$`)
+
+// PrestoSessions configures the Presto session the queries of each
+// component run in, so that Presto can isolate their workloads, such as
+// preventing report queries from starving imports of memory.
+type PrestoSessions struct {
+	Importer  PrestoSession `json:"importer"`
+	Reporting PrestoSession `json:"reporting"`
+	API       PrestoSession `json:"api"`
+}
+
+// PrestoSession is the Presto session of a component's queries.
+type PrestoSession struct {
+	// Source is the source of the component's queries, which Presto's
+	// resource group selectors can match to choose the resource group they
+	// run in. Defaults to metering-<component>, eg: metering-importer.
+	Source string `json:"source,omitempty"`
+	// Properties are the session properties of the component's queries,
+	// such as query_max_memory or query_priority.
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// ParsePrestoSessions parses the JSON PrestoSessions of each component.
+func ParsePrestoSessions(s string) (PrestoSessions, error) {
+	var sessions PrestoSessions
+	if s == "" {
+		return sessions, nil
+	}
+	if err := json.Unmarshal([]byte(s), &sessions); err != nil {
+		return sessions, fmt.Errorf("invalid Presto sessions: %v", err)
+	}
+	return sessions, nil
+}
+
+func (cfg PrestoSessions) Valid() error {
+	for component, session := range cfg.byComponent() {
+		if strings.Contains(session.Source, ",") {
+			return fmt.Errorf("the Presto source of %s must not contain commas, got %q", component, session.Source)
+		}
+		for name, value := range session.Properties {
+			if !prestoSessionPropertyRE.MatchString(name) {
+				return fmt.Errorf("invalid Presto session property name %q for %s", name, component)
+			}
+			// the properties are sent to Presto in a single header as a
+			// comma separated list of name=value pairs.
+			if value == "" || strings.ContainsAny(value, ",=") {
+				return fmt.Errorf("the value of Presto session property %s for %s must be non-empty and must not contain commas or equals signs, got %q", name, component, value)
+			}
+		}
+	}
+	return nil
+}
+
+// byComponent returns the session of each component which accesses Presto.
+func (cfg PrestoSessions) byComponent() map[string]PrestoSession {
+	return map[string]PrestoSession{
+		importerComponent:  cfg.Importer,
+		reportingComponent: cfg.Reporting,
+		apiComponent:       cfg.API,
+	}
+}
+
+// prestoSessionParams returns the query parameters of the Presto driver's
+// connection string which set the session of component's queries.
+func prestoSessionParams(component string, session PrestoSession) url.Values {
+	params := make(url.Values)
+	source := session.Source
+	if source == "" {
+		source = "metering-" + component
+	}
+	params.Set("source", source)
+	if len(session.Properties) != 0 {
+		properties := make([]string, 0, len(session.Properties))
+		for name, value := range session.Properties {
+			properties = append(properties, name+"="+value)
+		}
+		sort.Strings(properties)
+		params.Set("session_properties", strings.Join(properties, ","))
+	}
+	return params
+}
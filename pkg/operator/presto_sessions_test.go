@@ -0,0 +1,72 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePrestoSessions(t *testing.T) {
+	sessions, err := ParsePrestoSessions(`{"importer":{"properties":{"query_priority":"10"}},"reporting":{"source":"metering-reports","properties":{"query_max_memory":"4GB"}}}`)
+	require.NoError(t, err)
+	assert.Equal(t, PrestoSessions{
+		Importer:  PrestoSession{Properties: map[string]string{"query_priority": "10"}},
+		Reporting: PrestoSession{Source: "metering-reports", Properties: map[string]string{"query_max_memory": "4GB"}},
+	}, sessions)
+
+	sessions, err = ParsePrestoSessions("")
+	require.NoError(t, err)
+	assert.Equal(t, PrestoSessions{}, sessions)
+
+	_, err = ParsePrestoSessions("{")
+	assert.Error(t, err)
+}
+
+func TestPrestoSessionsValid(t *testing.T) {
+	tests := map[string]struct {
+		sessions    PrestoSessions
+		expectedErr bool
+	}{
+		"empty": {},
+		"system and catalog properties": {
+			sessions: PrestoSessions{Reporting: PrestoSession{Properties: map[string]string{"query_max_memory": "4GB", "hive.orc_bloom_filters_enabled": "true"}}},
+		},
+		"invalid property name": {
+			sessions:    PrestoSessions{Importer: PrestoSession{Properties: map[string]string{"query max memory": "4GB"}}},
+			expectedErr: true,
+		},
+		"value with a comma": {
+			sessions:    PrestoSessions{API: PrestoSession{Properties: map[string]string{"query_max_memory": "4GB,query_priority=1"}}},
+			expectedErr: true,
+		},
+		"empty value": {
+			sessions:    PrestoSessions{API: PrestoSession{Properties: map[string]string{"query_priority": ""}}},
+			expectedErr: true,
+		},
+		"source with a comma": {
+			sessions:    PrestoSessions{Importer: PrestoSession{Source: "metering,importer"}},
+			expectedErr: true,
+		},
+	}
+	for name, test := range tests {
+		err := test.sessions.Valid()
+		if test.expectedErr {
+			assert.Error(t, err, name)
+		} else {
+			assert.NoError(t, err, name)
+		}
+	}
+}
+
+func TestPrestoSessionParams(t *testing.T) {
+	params := prestoSessionParams(reportingComponent, PrestoSession{})
+	assert.Equal(t, "source=metering-reporting", params.Encode())
+
+	params = prestoSessionParams(reportingComponent, PrestoSession{
+		Source:     "metering-reports",
+		Properties: map[string]string{"query_priority": "1", "query_max_memory": "4GB"},
+	})
+	assert.Equal(t, "metering-reports", params.Get("source"))
+	assert.Equal(t, "query_max_memory=4GB,query_priority=1", params.Get("session_properties"))
+}
@@ -0,0 +1,88 @@
+package prestostore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+// AuditEvent records an access to the reporting API, or a change to a
+// metering resource.
+type AuditEvent struct {
+	Timestamp time.Time
+	// Source is "api" for API requests, and "controller" for changes to
+	// resources observed by the reporting-operator.
+	Source string
+	// User is the authenticated user making the request, and is empty if
+	// the user isn't known.
+	User string
+	// Action is the operation ID of API requests, or "create", "update" or
+	// "delete" for changes to resources.
+	Action string
+	// Endpoint is the API route requested, and is empty for changes to
+	// resources.
+	Endpoint string
+	// Resource is the plural name of the resource accessed or changed, and
+	// is empty for API requests which don't access a resource.
+	Resource   string
+	Namespace  string
+	Name       string
+	StatusCode int
+	// Rows is the number of result rows returned, or -1 if no results were
+	// returned.
+	Rows int64
+}
+
+// StoreAuditEvents handles storing audit events into the specified Presto
+// table.
+func StoreAuditEvents(ctx context.Context, execer presto.Execer, tableName string, events []*AuditEvent) error {
+	values := make([]string, len(events))
+	for i, event := range events {
+		values[i] = generateAuditEventSQLValues(event)
+	}
+	err := insertInBatches(ctx, execer, tableName, values)
+	if err != nil {
+		return fmt.Errorf("failed to store audit events into presto: %v", err)
+	}
+	return nil
+}
+
+// generateAuditEventSQLValues turns an AuditEvent into a SQL literal suited
+// for INSERT statements. Empty strings, a zero status code and negative
+// row counts are stored as NULL.
+//
+// The schema is as follows:
+// column "timestamp" type: "timestamp"
+// column "source" type: "string"
+// column "user" type: "string"
+// column "action" type: "string"
+// column "endpoint" type: "string"
+// column "resource" type: "string"
+// column "namespace" type: "string"
+// column "name" type: "string"
+// column "status_code" type: "int"
+// column "rows" type: "bigint"
+func generateAuditEventSQLValues(event *AuditEvent) string {
+	statusCode := "NULL"
+	if event.StatusCode != 0 {
+		statusCode = fmt.Sprintf("%d", event.StatusCode)
+	}
+	rows := "NULL"
+	if event.Rows >= 0 {
+		rows = fmt.Sprintf("%d", event.Rows)
+	}
+	return fmt.Sprintf("(timestamp '%s','%s',%s,'%s',%s,%s,%s,%s,%s,%s)",
+		presto.Timestamp(event.Timestamp),
+		escapeSQLString(event.Source),
+		sqlNullableString(event.User),
+		escapeSQLString(event.Action),
+		sqlNullableString(event.Endpoint),
+		sqlNullableString(event.Resource),
+		sqlNullableString(event.Namespace),
+		sqlNullableString(event.Name),
+		statusCode,
+		rows,
+	)
+}
@@ -0,0 +1,38 @@
+package prestostore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateAuditEventSQLValues(t *testing.T) {
+	event := &AuditEvent{
+		Timestamp:  time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC),
+		Source:     "api",
+		User:       "alice",
+		Action:     "getReport",
+		Endpoint:   "/api/v1/reports/get",
+		Resource:   "reports",
+		Namespace:  "metering",
+		Name:       "namespace-cost",
+		StatusCode: 200,
+		Rows:       42,
+	}
+	expected := `(timestamp '2018-07-01 00:00:00.000','api','alice','getReport','/api/v1/reports/get','reports','metering','namespace-cost',200,42)`
+	assert.Equal(t, expected, generateAuditEventSQLValues(event))
+
+	// changes to resources have no user, endpoint, status code or rows
+	event = &AuditEvent{
+		Timestamp: time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC),
+		Source:    "controller",
+		Action:    "delete",
+		Resource:  "reportdatasources",
+		Namespace: "metering",
+		Name:      "pod-cpu-o'brien",
+		Rows:      -1,
+	}
+	expected = `(timestamp '2018-07-01 00:00:00.000','controller',NULL,'delete',NULL,'reportdatasources','metering','pod-cpu-o''brien',NULL,NULL)`
+	assert.Equal(t, expected, generateAuditEventSQLValues(event))
+}
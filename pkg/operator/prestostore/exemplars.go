@@ -0,0 +1,85 @@
+package prestostore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/promquery"
+)
+
+// DefaultExemplarTraceIDLabel is the exemplar label containing the trace ID
+// if one isn't configured.
+const DefaultExemplarTraceIDLabel = "trace_id"
+
+// PrometheusExemplar is an exemplar recorded for a Prometheus series, which
+// can be used to tie a metric back to a trace.
+type PrometheusExemplar struct {
+	SeriesLabels map[string]string `json:"seriesLabels"`
+	Labels       map[string]string `json:"labels"`
+	TraceID      string            `json:"traceID"`
+	Value        float64           `json:"value"`
+	Timestamp    time.Time         `json:"timestamp"`
+}
+
+// promExemplarsToPrometheusExemplars converts the results of an exemplars
+// query, extracting the trace ID from the traceIDLabel of each exemplar.
+func promExemplarsToPrometheusExemplars(results []promquery.ExemplarQueryResult, traceIDLabel string) []*PrometheusExemplar {
+	var exemplars []*PrometheusExemplar
+	for _, result := range results {
+		for _, exemplar := range result.Exemplars {
+			exemplars = append(exemplars, &PrometheusExemplar{
+				SeriesLabels: result.SeriesLabels,
+				Labels:       exemplar.Labels,
+				TraceID:      exemplar.Labels[traceIDLabel],
+				Value:        float64(exemplar.Value),
+				Timestamp:    exemplar.Timestamp.Time().UTC(),
+			})
+		}
+	}
+	return exemplars
+}
+
+// StorePrometheusExemplars handles storing Prometheus exemplars into the
+// specified Presto table.
+func StorePrometheusExemplars(ctx context.Context, execer presto.Execer, tableName string, exemplars []*PrometheusExemplar) error {
+	values := make([]string, len(exemplars))
+	for i, exemplar := range exemplars {
+		values[i] = generatePrometheusExemplarSQLValues(exemplar)
+	}
+	err := insertInBatches(ctx, execer, tableName, values)
+	if err != nil {
+		return fmt.Errorf("failed to store exemplars into presto: %v", err)
+	}
+	return nil
+}
+
+// generatePrometheusExemplarSQLValues turns a PrometheusExemplar into a SQL
+// literal suited for INSERT statements.
+//
+// The schema is as follows:
+// column "timestamp" type: "timestamp"
+// column "value" type: "double"
+// column "trace_id" type: "string"
+// column "series_labels" type: "map<string, string>"
+// column "labels" type: "map<string, string>"
+func generatePrometheusExemplarSQLValues(exemplar *PrometheusExemplar) string {
+	return fmt.Sprintf("(timestamp '%s',%f,'%s',%s,%s)",
+		presto.Timestamp(exemplar.Timestamp), exemplar.Value, escapeSQLString(exemplar.TraceID), sqlMap(exemplar.SeriesLabels), sqlMap(exemplar.Labels))
+}
+
+func sqlMap(m map[string]string) string {
+	var keys []string
+	var vals []string
+	for k, v := range m {
+		keys = append(keys, "'"+escapeSQLString(k)+"'")
+		vals = append(vals, "'"+escapeSQLString(v)+"'")
+	}
+	return "map(ARRAY[" + strings.Join(keys, ",") + "],ARRAY[" + strings.Join(vals, ",") + "])"
+}
+
+func escapeSQLString(s string) string {
+	return strings.Replace(s, "'", "''", -1)
+}
@@ -0,0 +1,52 @@
+package prestostore
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-metering/pkg/promquery"
+)
+
+func TestPromExemplarsToPrometheusExemplars(t *testing.T) {
+	// example response data from the Prometheus query_exemplars API
+	data := `[
+		{
+			"seriesLabels": {"__name__": "http_request_duration_seconds_bucket", "pod": "app-1"},
+			"exemplars": [
+				{"labels": {"trace_id": "EpTxMJ40fUus7aGY"}, "value": "6", "timestamp": 1600096945.479},
+				{"labels": {"traceID": "Olp9XHlq763ccsfa"}, "value": "0.5", "timestamp": 1600096955}
+			]
+		}
+	]`
+	var results []promquery.ExemplarQueryResult
+	require.NoError(t, json.Unmarshal([]byte(data), &results))
+
+	exemplars := promExemplarsToPrometheusExemplars(results, DefaultExemplarTraceIDLabel)
+	require.Len(t, exemplars, 2)
+
+	assert.Equal(t, "EpTxMJ40fUus7aGY", exemplars[0].TraceID)
+	assert.Equal(t, 6.0, exemplars[0].Value)
+	assert.Equal(t, time.Unix(1600096945, 479000000).UTC(), exemplars[0].Timestamp)
+	assert.Equal(t, "app-1", exemplars[0].SeriesLabels["pod"])
+
+	// the second exemplar uses a different label for the trace ID
+	assert.Equal(t, "", exemplars[1].TraceID)
+	exemplars = promExemplarsToPrometheusExemplars(results, "traceID")
+	assert.Equal(t, "Olp9XHlq763ccsfa", exemplars[1].TraceID)
+}
+
+func TestGeneratePrometheusExemplarSQLValues(t *testing.T) {
+	exemplar := &PrometheusExemplar{
+		SeriesLabels: map[string]string{"pod": "app-1"},
+		Labels:       map[string]string{"trace_id": "it's"},
+		TraceID:      "it's",
+		Value:        1.5,
+		Timestamp:    time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC),
+	}
+	expected := `(timestamp '2018-07-01 00:00:00.000',1.500000,'it''s',map(ARRAY['pod'],ARRAY['app-1']),map(ARRAY['trace_id'],ARRAY['it''s']))`
+	assert.Equal(t, expected, generatePrometheusExemplarSQLValues(exemplar))
+}
@@ -0,0 +1,169 @@
+package prestostore
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	FaultDropChunk      = "drop_chunk"
+	FaultDelayInsert    = "delay_insert"
+	FaultAmbiguousError = "ambiguous_error"
+)
+
+var injectedFaultsCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "metering",
+		Name:      "prometheus_importer_injected_faults_total",
+		Help:      "The number of faults injected into Prometheus imports by the fault injector.",
+	},
+	[]string{"fault", "table_name"},
+)
+
+func init() {
+	prometheus.MustRegister(injectedFaultsCounter)
+}
+
+// Faults configures the faults a FaultInjector injects when the Prometheus
+// importer stores a chunk of metrics into Presto. Each fault is injected
+// with its probability, between 0 and 1.
+type Faults struct {
+	// Tables limits the faults to imports into these Presto tables. If
+	// empty, faults are injected into imports into every table.
+	Tables []string
+	// DropChunkProbability is the probability the metrics of a chunk are
+	// discarded instead of being stored, as if the insert was lost.
+	DropChunkProbability float64
+	// DelayInsertProbability is the probability that storing a chunk is
+	// delayed by InsertDelay before it's inserted.
+	DelayInsertProbability float64
+	InsertDelay            time.Duration
+	// AmbiguousErrorProbability is the probability that an error is
+	// returned after a chunk was stored successfully, so the importer can't
+	// tell whether the chunk was stored, as happens when the connection to
+	// Presto fails before the insert's result is returned.
+	AmbiguousErrorProbability float64
+}
+
+func (f Faults) Validate() error {
+	for name, p := range map[string]float64{
+		"dropChunkProbability":      f.DropChunkProbability,
+		"delayInsertProbability":    f.DelayInsertProbability,
+		"ambiguousErrorProbability": f.AmbiguousErrorProbability,
+	} {
+		if p < 0 || p > 1 {
+			return fmt.Errorf("%s must be between 0 and 1, got %v", name, p)
+		}
+	}
+	if f.InsertDelay < 0 {
+		return fmt.Errorf("insertDelay cannot be negative")
+	}
+	return nil
+}
+
+// FaultInjector injects faults into the Prometheus importer, to verify
+// imports recover from them correctly. It's shared by every
+// PrometheusImporter, and the faults can be changed while imports are
+// running.
+type FaultInjector struct {
+	mu     sync.Mutex
+	faults Faults
+	rand   *rand.Rand
+}
+
+func NewFaultInjector(rand *rand.Rand) *FaultInjector {
+	return &FaultInjector{rand: rand}
+}
+
+// Faults returns the faults being injected.
+func (injector *FaultInjector) Faults() Faults {
+	injector.mu.Lock()
+	defer injector.mu.Unlock()
+	return injector.faults
+}
+
+// SetFaults replaces the faults being injected. Setting the zero value stops
+// injecting faults.
+func (injector *FaultInjector) SetFaults(faults Faults) {
+	injector.mu.Lock()
+	defer injector.mu.Unlock()
+	injector.faults = faults
+}
+
+// injectedFaults are the faults to inject into storing a single chunk.
+type injectedFaults struct {
+	dropChunk      bool
+	delay          time.Duration
+	ambiguousError bool
+}
+
+// next picks the faults to inject into the next chunk stored into
+// tableName. It's safe to call on a nil FaultInjector, which never injects
+// faults.
+func (injector *FaultInjector) next(tableName string) injectedFaults {
+	var injected injectedFaults
+	if injector == nil {
+		return injected
+	}
+	injector.mu.Lock()
+	defer injector.mu.Unlock()
+
+	faults := injector.faults
+	if len(faults.Tables) != 0 {
+		found := false
+		for _, table := range faults.Tables {
+			if table == tableName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return injected
+		}
+	}
+	if faults.DelayInsertProbability > 0 && injector.rand.Float64() < faults.DelayInsertProbability {
+		injected.delay = faults.InsertDelay
+		injectedFaultsCounter.WithLabelValues(FaultDelayInsert, tableName).Inc()
+	}
+	if faults.DropChunkProbability > 0 && injector.rand.Float64() < faults.DropChunkProbability {
+		injected.dropChunk = true
+		injectedFaultsCounter.WithLabelValues(FaultDropChunk, tableName).Inc()
+		return injected
+	}
+	if faults.AmbiguousErrorProbability > 0 && injector.rand.Float64() < faults.AmbiguousErrorProbability {
+		injected.ambiguousError = true
+		injectedFaultsCounter.WithLabelValues(FaultAmbiguousError, tableName).Inc()
+	}
+	return injected
+}
+
+// storePrometheusMetrics stores the metrics of a chunk into Presto,
+// injecting the faults chosen by the importer's FaultInjector, if it has
+// one.
+func (importer *PrometheusImporter) storePrometheusMetrics(ctx context.Context, metrics []*PrometheusMetric) error {
+	tableName := importer.cfg.PrestoTableName
+	injected := importer.cfg.FaultInjector.next(tableName)
+	if injected.delay > 0 {
+		importer.logger.Warnf("injected fault: delaying insert into table %s by %s", tableName, injected.delay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-importer.clock.After(injected.delay):
+		}
+	}
+	if injected.dropChunk {
+		importer.logger.Warnf("injected fault: dropping %d metrics instead of storing them into table %s", len(metrics), tableName)
+		return nil
+	}
+	err := StorePrometheusMetrics(ctx, importer.prestoQueryer, tableName, importer.cfg.DatePartitioned, metrics)
+	if err == nil && injected.ambiguousError {
+		importer.logger.Warnf("injected fault: returning an error after storing %d metrics into table %s", len(metrics), tableName)
+		return fmt.Errorf("injected fault: connection to Presto lost, the insert into table %s may not have completed", tableName)
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+package prestostore
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+func TestPrometheusImporterFaultInjection(t *testing.T) {
+	const tableName = "datasource_pod_usage_cpu_cores"
+	metrics := []*PrometheusMetric{
+		{
+			Labels:    map[string]string{"pod": "app-1"},
+			Amount:    1,
+			StepSize:  time.Minute,
+			Timestamp: time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	tests := map[string]struct {
+		faults       *Faults
+		expectInsert bool
+		expectedErr  bool
+	}{
+		"no fault injector": {
+			expectInsert: true,
+		},
+		"no faults": {
+			faults:       &Faults{},
+			expectInsert: true,
+		},
+		"drop chunk": {
+			faults: &Faults{DropChunkProbability: 1},
+		},
+		"ambiguous error": {
+			faults:       &Faults{AmbiguousErrorProbability: 1},
+			expectInsert: true,
+			expectedErr:  true,
+		},
+		"delay insert": {
+			faults:       &Faults{DelayInsertProbability: 1, InsertDelay: time.Millisecond},
+			expectInsert: true,
+		},
+		"other table": {
+			faults:       &Faults{Tables: []string{"datasource_node_capacity_cpu_cores"}, DropChunkProbability: 1},
+			expectInsert: true,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queryer := mockpresto.NewMockExecQueryer(ctrl)
+			if tt.expectInsert {
+				queryer.EXPECT().Exec(gomock.Any()).Return(nil)
+			}
+
+			cfg := Config{PrestoTableName: tableName}
+			if tt.faults != nil {
+				cfg.FaultInjector = NewFaultInjector(rand.New(rand.NewSource(0)))
+				cfg.FaultInjector.SetFaults(*tt.faults)
+			}
+			importer := NewPrometheusImporter(logrus.New(), nil, nil, queryer, clock.RealClock{}, cfg)
+
+			err := importer.storePrometheusMetrics(context.Background(), metrics)
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFaultsValidate(t *testing.T) {
+	assert.NoError(t, Faults{DropChunkProbability: 0.5, InsertDelay: time.Second}.Validate())
+	assert.Error(t, Faults{AmbiguousErrorProbability: 1.5}.Validate())
+	assert.Error(t, Faults{DelayInsertProbability: -1}.Validate())
+	assert.Error(t, Faults{InsertDelay: -time.Second}.Validate())
+}
@@ -3,6 +3,7 @@ package prestostore
 import (
 	"context"
 	"fmt"
+	"runtime"
 	"sync"
 	"time"
 
@@ -18,12 +19,17 @@ import (
 const (
 	// cap the maximum importer.cfg.ChunkSize
 	maxChunkDuration = 24 * time.Hour
+
+	// ClusterIDLabel is the label identifying the cluster a metric was
+	// imported from.
+	ClusterIDLabel = "cluster_id"
 )
 
 // PrometheusImporter imports Prometheus metrics into Presto tables
 type PrometheusImporter struct {
 	logger        logrus.FieldLogger
 	promConn      prom.API
+	promAPI       promquery.API
 	prestoQueryer presto.ExecQueryer
 	clock         clock.Clock
 	cfg           Config
@@ -35,6 +41,45 @@ type PrometheusImporter struct {
 	//lastTimestamp is the lastTimestamp stored for this PrometheusImporter
 	lastTimestamp *time.Time
 	metricsCount  int
+	// targetIndex is refreshed at the beginning of each import when
+	// cfg.EnrichTargetMetadata is true
+	targetIndex targetMetadataIndex
+	// maxChunkMetrics and peakHeapBytes track the largest chunk and heap
+	// size of the current import.
+	maxChunkMetrics int
+	peakHeapBytes   uint64
+
+	// statsLock protects lastImportStats, which is read while imports are
+	// running.
+	statsLock       sync.Mutex
+	lastImportStats *ImportStats
+}
+
+// ImportStats summarizes an import by a PrometheusImporter, which is used to
+// recommend chunk sizes, step sizes and memory limits which avoid the
+// reporting-operator running out of memory.
+type ImportStats struct {
+	// TableName is the table the metrics were imported into.
+	TableName  string
+	Start      time.Time
+	Duration   time.Duration
+	ChunkSize  time.Duration
+	StepSize   time.Duration
+	TimeRanges int
+	Metrics    int
+	// MaxChunkMetrics is the most metrics returned for a single chunk,
+	// which are held in memory together while they're stored.
+	MaxChunkMetrics int
+	// PeakHeapBytes is the largest heap size observed after querying a
+	// chunk. The heap is shared by every import running concurrently.
+	PeakHeapBytes uint64
+}
+
+// heapInuseBytes returns the number of bytes in in-use heap spans.
+var heapInuseBytes = func() uint64 {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	return memStats.HeapInuse
 }
 
 type Config struct {
@@ -44,9 +89,46 @@ type Config struct {
 	StepSize              time.Duration
 	MaxTimeRanges         int64
 	MaxQueryRangeDuration time.Duration
+
+	// DatePartitioned is true if the table is partitioned by the date of
+	// its metrics, as the ReportDataSource has an archive.
+	DatePartitioned bool
+
+	// EnrichTargetMetadata enables adding metadata from Prometheus target
+	// discovery to each metric, rewriting the instance label to the name of
+	// the node the target is running on.
+	EnrichTargetMetadata bool
+	// TargetMetadataLabels are additional discovered labels to copy from the
+	// metric's target when EnrichTargetMetadata is true.
+	TargetMetadataLabels []string
+
+	// ExemplarsTableName, if set, is the Presto table to store the
+	// exemplars for the metrics imported into.
+	ExemplarsTableName string
+	// ExemplarTraceIDLabel is the exemplar label containing the trace ID.
+	ExemplarTraceIDLabel string
+
+	// FaultInjector, if set, injects faults when storing metrics, for
+	// testing how imports recover from failures.
+	FaultInjector *FaultInjector
+
+	// PublishMetrics, if set, is called with the metrics of each chunk
+	// after they've been stored, such as to publish them to Kafka.
+	// Publishing is best effort, and errors are logged rather than failing
+	// the import.
+	PublishMetrics func(metrics []*PrometheusMetric) error
+
+	// ClusterID, if set, is added to each metric as the cluster_id label,
+	// so metrics from multiple clusters can be imported into the same table.
+	ClusterID string
+	// Remote is true if the metrics are imported from another cluster's
+	// Prometheus. The last timestamp of a remote cluster only considers the
+	// metrics labelled with its ClusterID, while the local cluster also
+	// considers metrics imported before it had a ClusterID.
+	Remote bool
 }
 
-func NewPrometheusImporter(logger logrus.FieldLogger, promConn prom.API, prestoQueryer presto.ExecQueryer, clock clock.Clock, cfg Config) *PrometheusImporter {
+func NewPrometheusImporter(logger logrus.FieldLogger, promConn prom.API, promAPI promquery.API, prestoQueryer presto.ExecQueryer, clock clock.Clock, cfg Config) *PrometheusImporter {
 	logger = logger.WithFields(logrus.Fields{
 		"component": "PrometheusImporter",
 		"tableName": cfg.PrestoTableName,
@@ -57,15 +139,19 @@ func NewPrometheusImporter(logger logrus.FieldLogger, promConn prom.API, prestoQ
 	return &PrometheusImporter{
 		logger:        logger,
 		promConn:      promConn,
+		promAPI:       promAPI,
 		prestoQueryer: prestoQueryer,
 		clock:         clock,
 		cfg:           cfg,
 	}
 }
 
-func (importer *PrometheusImporter) preProcessingHandler(_ context.Context, timeRanges []prom.Range) error {
-	// reset counter before we begin processing
+func (importer *PrometheusImporter) preProcessingHandler(ctx context.Context, timeRanges []prom.Range) error {
+	// reset counters and target metadata before we begin processing
 	importer.metricsCount = 0
+	importer.maxChunkMetrics = 0
+	importer.peakHeapBytes = 0
+	importer.targetIndex = nil
 
 	if len(timeRanges) == 0 {
 		importer.logger.Infof("no time ranges to query yet for table %s", importer.cfg.PrestoTableName)
@@ -77,6 +163,15 @@ func (importer *PrometheusImporter) preProcessingHandler(_ context.Context, time
 			"rangeEnd":   end,
 		})
 		logger.Debugf("querying for data between %s and %s (chunks: %d)", begin, end, len(timeRanges))
+
+		if importer.cfg.EnrichTargetMetadata {
+			targets, err := importer.promAPI.ActiveTargets(ctx)
+			if err != nil {
+				return fmt.Errorf("unable to get Prometheus targets to enrich metrics for table %s: %v", importer.cfg.PrestoTableName, err)
+			}
+			importer.targetIndex = newTargetMetadataIndex(targets)
+			logger.Debugf("enriching metrics using metadata from %d Prometheus targets", len(importer.targetIndex))
+		}
 	}
 	return nil
 }
@@ -98,6 +193,28 @@ func (importer *PrometheusImporter) postQueryHandler(ctx context.Context, timeRa
 	queryEnd := timeRange.End.UTC()
 
 	metrics := promMatrixToPrometheusMetrics(timeRange, matrix)
+	if len(metrics) > importer.maxChunkMetrics {
+		importer.maxChunkMetrics = len(metrics)
+	}
+	// the matrix and the metrics converted from it are both in memory at
+	// this point, so this is when the heap is largest during an import.
+	if heapBytes := heapInuseBytes(); heapBytes > importer.peakHeapBytes {
+		importer.peakHeapBytes = heapBytes
+	}
+	if importer.targetIndex != nil {
+		enriched := 0
+		for _, metric := range metrics {
+			if importer.targetIndex.enrich(metric.Labels, importer.cfg.TargetMetadataLabels) {
+				enriched++
+			}
+		}
+		importer.logger.Debugf("enriched %d of %d metrics with Prometheus target metadata", enriched, len(metrics))
+	}
+	if importer.cfg.ClusterID != "" {
+		for _, metric := range metrics {
+			metric.Labels[ClusterIDLabel] = importer.cfg.ClusterID
+		}
+	}
 	if len(metrics) != 0 {
 		metricsBegin := metrics[0].Timestamp
 		metricsEnd := metrics[len(metrics)-1].Timestamp
@@ -106,19 +223,71 @@ func (importer *PrometheusImporter) postQueryHandler(ctx context.Context, timeRa
 			"metricsEnd":   metricsEnd,
 		})
 		metricLogger.Debugf("got %d metrics for time range %s to %s, storing them into Presto into table %s", len(metrics), queryBegin, queryEnd, importer.cfg.PrestoTableName)
-		err := StorePrometheusMetrics(ctx, importer.prestoQueryer, importer.cfg.PrestoTableName, metrics)
+		err := importer.storePrometheusMetrics(ctx, metrics)
 		if err != nil {
 			return fmt.Errorf("failed to store Prometheus metrics into table %s for the range %v to %v: %v",
 				importer.cfg.PrestoTableName, queryBegin, queryEnd, err)
 		}
 		metricLogger.Debugf("stored %d metrics for time range into Presto table %s successfully", len(metrics), importer.cfg.PrestoTableName)
+		if importer.cfg.PublishMetrics != nil {
+			if err := importer.cfg.PublishMetrics(metrics); err != nil {
+				metricLogger.WithError(err).Errorf("failed to publish %d metrics for time range %s to %s", len(metrics), queryBegin, queryEnd)
+			}
+		}
 	} else {
 		importer.logger.Debugf("got 0 metrics for time range %s to %s", queryBegin, queryEnd)
 	}
 	importer.metricsCount += len(metrics)
+
+	if importer.cfg.ExemplarsTableName != "" {
+		importer.importExemplars(ctx, timeRange)
+	}
 	return nil
 }
 
+// importExemplars stores the exemplars for the timeRange. Exemplars are best
+// effort, since Prometheus only exposes them when exemplar storage is enabled,
+// so errors are logged rather than failing the import.
+func (importer *PrometheusImporter) importExemplars(ctx context.Context, timeRange prom.Range) {
+	queryBegin := timeRange.Start.UTC()
+	queryEnd := timeRange.End.UTC()
+	logger := importer.logger.WithField("exemplarsTableName", importer.cfg.ExemplarsTableName)
+
+	results, err := importer.promAPI.QueryExemplars(ctx, importer.cfg.PrometheusQuery, queryBegin, queryEnd)
+	if err != nil {
+		logger.WithError(err).Warnf("unable to query exemplars for time range %s to %s", queryBegin, queryEnd)
+		return
+	}
+
+	traceIDLabel := importer.cfg.ExemplarTraceIDLabel
+	if traceIDLabel == "" {
+		traceIDLabel = DefaultExemplarTraceIDLabel
+	}
+	exemplars := promExemplarsToPrometheusExemplars(results, traceIDLabel)
+	if importer.cfg.ClusterID != "" {
+		for _, exemplar := range exemplars {
+			// the series labels are shared by every exemplar of a series,
+			// so they're copied rather than modified.
+			seriesLabels := make(map[string]string, len(exemplar.SeriesLabels)+1)
+			for k, v := range exemplar.SeriesLabels {
+				seriesLabels[k] = v
+			}
+			seriesLabels[ClusterIDLabel] = importer.cfg.ClusterID
+			exemplar.SeriesLabels = seriesLabels
+		}
+	}
+	if len(exemplars) == 0 {
+		logger.Debugf("got 0 exemplars for time range %s to %s", queryBegin, queryEnd)
+		return
+	}
+	err = StorePrometheusExemplars(ctx, importer.prestoQueryer, importer.cfg.ExemplarsTableName, exemplars)
+	if err != nil {
+		logger.WithError(err).Warnf("unable to store exemplars for time range %s to %s", queryBegin, queryEnd)
+		return
+	}
+	logger.Debugf("stored %d exemplars for time range %s to %s into Presto table %s", len(exemplars), queryBegin, queryEnd, importer.cfg.ExemplarsTableName)
+}
+
 func (importer *PrometheusImporter) postProcessingHandler(_ context.Context, timeRanges []prom.Range) error {
 	if len(timeRanges) != 0 {
 		begin := timeRanges[0].Start.UTC()
@@ -162,7 +331,11 @@ func (importer *PrometheusImporter) ImportFromLastTimestamp(ctx context.Context,
 	if importer.lastTimestamp == nil {
 		var err error
 		importer.logger.Debugf("lastTimestamp for table %s: isn't known, querying for timestamp", importer.cfg.PrestoTableName)
-		importer.lastTimestamp, err = getLastTimestampForTable(importer.prestoQueryer, importer.cfg.PrestoTableName)
+		if importer.cfg.ClusterID != "" {
+			importer.lastTimestamp, err = GetLastTimestampForCluster(importer.prestoQueryer, importer.cfg.PrestoTableName, importer.cfg.ClusterID, !importer.cfg.Remote)
+		} else {
+			importer.lastTimestamp, err = GetLastTimestampForTable(importer.prestoQueryer, importer.cfg.PrestoTableName)
+		}
 		if err != nil {
 			importer.logger.WithError(err).Errorf("unable to get last timestamp for table %s", importer.cfg.PrestoTableName)
 			return nil, err
@@ -219,6 +392,7 @@ func (importer *PrometheusImporter) importMetrics(ctx context.Context, startTime
 		endTime = newEndTime
 	}
 
+	importStart := importer.clock.Now()
 	collectHandlers := promquery.ResultHandler{
 		PreProcessingHandler:  importer.preProcessingHandler,
 		PreQueryHandler:       importer.preQueryHandler,
@@ -227,6 +401,9 @@ func (importer *PrometheusImporter) importMetrics(ctx context.Context, startTime
 	}
 
 	timeRanges, err := promquery.QueryRangeChunked(ctx, importer.promConn, importer.cfg.PrometheusQuery, startTime, endTime, importer.cfg.ChunkSize, importer.cfg.StepSize, importer.cfg.MaxTimeRanges, allowIncompleteChunks, collectHandlers)
+	if len(timeRanges) != 0 {
+		importer.recordImportStats(importStart, len(timeRanges))
+	}
 	if err != nil {
 		logger.WithError(err).Error("error collecting metrics")
 		// at this point we cannot be sure what is in Presto and what
@@ -243,6 +420,35 @@ func (importer *PrometheusImporter) importMetrics(ctx context.Context, startTime
 	return timeRanges, nil
 }
 
+func (importer *PrometheusImporter) recordImportStats(start time.Time, timeRanges int) {
+	stats := &ImportStats{
+		TableName:       importer.cfg.PrestoTableName,
+		Start:           start,
+		Duration:        importer.clock.Since(start),
+		ChunkSize:       importer.cfg.ChunkSize,
+		StepSize:        importer.cfg.StepSize,
+		TimeRanges:      timeRanges,
+		Metrics:         importer.metricsCount,
+		MaxChunkMetrics: importer.maxChunkMetrics,
+		PeakHeapBytes:   importer.peakHeapBytes,
+	}
+	importer.statsLock.Lock()
+	importer.lastImportStats = stats
+	importer.statsLock.Unlock()
+}
+
+// LastImportStats returns the stats of the most recent import which queried
+// at least one time range, or nil if there hasn't been one yet.
+func (importer *PrometheusImporter) LastImportStats() *ImportStats {
+	importer.statsLock.Lock()
+	defer importer.statsLock.Unlock()
+	if importer.lastImportStats == nil {
+		return nil
+	}
+	stats := *importer.lastImportStats
+	return &stats
+}
+
 func promMatrixToPrometheusMetrics(timeRange prom.Range, matrix model.Matrix) []*PrometheusMetric {
 	var metrics []*PrometheusMetric
 	// iterate over segments of contiguous billing metrics
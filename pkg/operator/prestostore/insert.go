@@ -0,0 +1,42 @@
+package prestostore
+
+import (
+	"context"
+	"strings"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+// insertInBatches inserts the rows, each a SQL literal suited for INSERT
+// statements, into the table using as few INSERT statements as possible
+// without any of them exceeding prestoQueryCap.
+func insertInBatches(ctx context.Context, execer presto.Execer, tableName string, rows []string) error {
+	insertStatementLength := len(presto.FormatInsertQuery(tableName, ""))
+	queryCap := prestoQueryCap - insertStatementLength
+
+	insert := func(batch []string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return presto.InsertIntoContext(ctx, execer, tableName, "VALUES "+strings.Join(batch, ","))
+	}
+
+	var batch []string
+	batchLength := 0
+	for _, row := range rows {
+		// account for the VALUES keyword and separating commas
+		if len(batch) != 0 && len("VALUES ")+batchLength+len(batch)+len(row) > queryCap {
+			if err := insert(batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+			batchLength = 0
+		}
+		batch = append(batch, row)
+		batchLength += len(row)
+	}
+	if len(batch) != 0 {
+		return insert(batch)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package prestostore
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+func TestInsertInBatches(t *testing.T) {
+	const tableName = "test_table"
+	// two of these rows fit in a single INSERT, but three don't.
+	large := func(c string) string {
+		return "('" + strings.Repeat(c, prestoQueryCap/3) + "')"
+	}
+
+	tests := map[string]struct {
+		rows            []string
+		expectedQueries []string
+	}{
+		"no rows": {},
+		"single batch": {
+			rows:            []string{"(1)", "(2)"},
+			expectedQueries: []string{presto.FormatInsertQuery(tableName, "VALUES (1),(2)")},
+		},
+		"split across batches": {
+			rows: []string{large("a"), large("b"), large("c")},
+			expectedQueries: []string{
+				presto.FormatInsertQuery(tableName, "VALUES "+large("a")+","+large("b")),
+				presto.FormatInsertQuery(tableName, "VALUES "+large("c")),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			execer := mockpresto.NewMockExecQueryer(ctrl)
+			var calls []*gomock.Call
+			for _, query := range test.expectedQueries {
+				calls = append(calls, execer.EXPECT().Exec(query).Return(nil))
+			}
+			gomock.InOrder(calls...)
+
+			err := insertInBatches(context.Background(), execer, tableName, test.rows)
+			assert.NoError(t, err)
+		})
+	}
+}
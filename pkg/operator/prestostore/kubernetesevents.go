@@ -0,0 +1,125 @@
+package prestostore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+// KubernetesEvent is an occurrence of a Kubernetes event. Events which
+// recur have the same EventUID, and are recorded once for each Count.
+type KubernetesEvent struct {
+	Timestamp time.Time
+	// Kind, Namespace, Name and UID identify the object the event is
+	// about, such as the Deployment which was scaled.
+	Kind      string
+	Namespace string
+	Name      string
+	UID       string
+	Reason    string
+	Message   string
+	Type      string
+	Count     int32
+	// Replicas is the number of replicas a workload was scaled to, parsed
+	// from the message of scaling events, or nil for other events.
+	Replicas *int64
+	// Source is the component which reported the event, such as
+	// deployment-controller.
+	Source   string
+	EventUID string
+	// ClusterID identifies the cluster the event is from, and is empty if
+	// the cluster doesn't have an ID.
+	ClusterID string
+}
+
+// Key identifies the occurrence of the event.
+func (event *KubernetesEvent) Key() string {
+	return KubernetesEventKey(event.EventUID, event.Count)
+}
+
+// KubernetesEventKey identifies an occurrence of an event by its UID and
+// count.
+func KubernetesEventKey(eventUID string, count int32) string {
+	return fmt.Sprintf("%s/%d", eventUID, count)
+}
+
+// StoreKubernetesEvents handles storing Kubernetes events into the specified
+// Presto table.
+func StoreKubernetesEvents(ctx context.Context, execer presto.Execer, tableName string, events []*KubernetesEvent) error {
+	values := make([]string, len(events))
+	for i, event := range events {
+		values[i] = generateKubernetesEventSQLValues(event)
+	}
+	err := insertInBatches(ctx, execer, tableName, values)
+	if err != nil {
+		return fmt.Errorf("failed to store Kubernetes events into presto: %v", err)
+	}
+	return nil
+}
+
+// GetKubernetesEventKeys returns the keys of the events stored in the table
+// which occurred at or after since.
+func GetKubernetesEventKeys(queryer presto.Queryer, tableName string, since time.Time) (map[string]time.Time, error) {
+	query := fmt.Sprintf(`SELECT "timestamp", event_uid, "count" FROM %s WHERE "timestamp" >= timestamp '%s'`, tableName, presto.Timestamp(since))
+	results, err := queryer.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting the events stored in table %s: %v", tableName, err)
+	}
+	keys := make(map[string]time.Time, len(results))
+	for _, row := range results {
+		timestamp, ok := row["timestamp"].(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("invalid timestamp %v in table %s", row["timestamp"], tableName)
+		}
+		eventUID, _ := row["event_uid"].(string)
+		count, ok := row["count"].(int64)
+		if !ok {
+			return nil, fmt.Errorf("invalid count %v in table %s", row["count"], tableName)
+		}
+		keys[KubernetesEventKey(eventUID, int32(count))] = timestamp
+	}
+	return keys, nil
+}
+
+// generateKubernetesEventSQLValues turns a KubernetesEvent into a SQL
+// literal suited for INSERT statements. Events which aren't about scaling
+// have a NULL replicas, and events in a cluster without an ID have a NULL
+// cluster_id.
+//
+// The schema is as follows:
+// column "timestamp" type: "timestamp"
+// column "kind" type: "string"
+// column "namespace" type: "string"
+// column "name" type: "string"
+// column "uid" type: "string"
+// column "reason" type: "string"
+// column "message" type: "string"
+// column "type" type: "string"
+// column "count" type: "bigint"
+// column "replicas" type: "bigint"
+// column "source" type: "string"
+// column "event_uid" type: "string"
+// column "cluster_id" type: "string"
+func generateKubernetesEventSQLValues(event *KubernetesEvent) string {
+	replicas := "NULL"
+	if event.Replicas != nil {
+		replicas = fmt.Sprintf("%d", *event.Replicas)
+	}
+	return fmt.Sprintf("(timestamp '%s','%s','%s','%s','%s','%s','%s','%s',%d,%s,'%s','%s',%s)",
+		presto.Timestamp(event.Timestamp),
+		escapeSQLString(event.Kind),
+		escapeSQLString(event.Namespace),
+		escapeSQLString(event.Name),
+		escapeSQLString(event.UID),
+		escapeSQLString(event.Reason),
+		escapeSQLString(event.Message),
+		escapeSQLString(event.Type),
+		event.Count,
+		replicas,
+		escapeSQLString(event.Source),
+		escapeSQLString(event.EventUID),
+		sqlNullableString(event.ClusterID),
+	)
+}
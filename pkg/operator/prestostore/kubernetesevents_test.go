@@ -0,0 +1,37 @@
+package prestostore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateKubernetesEventSQLValues(t *testing.T) {
+	replicas := int64(10)
+	event := &KubernetesEvent{
+		Timestamp: time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC),
+		Kind:      "Deployment",
+		Namespace: "team-a",
+		Name:      "app",
+		UID:       "f2b4b4c1",
+		Reason:    "ScalingReplicaSet",
+		Message:   "Scaled up replica set app-5d8f to 10",
+		Type:      "Normal",
+		Count:     2,
+		Replicas:  &replicas,
+		Source:    "deployment-controller",
+		EventUID:  "8a7c5e3a",
+		ClusterID: "us-east",
+	}
+	expected := `(timestamp '2018-07-01 00:00:00.000','Deployment','team-a','app','f2b4b4c1','ScalingReplicaSet','Scaled up replica set app-5d8f to 10','Normal',2,10,'deployment-controller','8a7c5e3a','us-east')`
+	assert.Equal(t, expected, generateKubernetesEventSQLValues(event))
+	assert.Equal(t, "8a7c5e3a/2", event.Key())
+
+	// events which aren't about scaling have a NULL replicas
+	event.Reason, event.Message = "SuccessfulCreate", "Created pod: app-5d8f-x9k2'q"
+	event.Replicas = nil
+	event.ClusterID = ""
+	expected = `(timestamp '2018-07-01 00:00:00.000','Deployment','team-a','app','f2b4b4c1','SuccessfulCreate','Created pod: app-5d8f-x9k2''q','Normal',2,NULL,'deployment-controller','8a7c5e3a',NULL)`
+	assert.Equal(t, expected, generateKubernetesEventSQLValues(event))
+}
@@ -0,0 +1,89 @@
+package prestostore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+// KubernetesObject is the metadata of a Kubernetes object at the time it
+// was snapshotted.
+type KubernetesObject struct {
+	Timestamp   time.Time
+	StepSize    time.Duration
+	Kind        string
+	Namespace   string
+	Name        string
+	UID         string
+	Labels      map[string]string
+	Annotations map[string]string
+	// OwnerKind, OwnerName and OwnerUID identify the object's controller,
+	// and are empty if it doesn't have one.
+	OwnerKind         string
+	OwnerName         string
+	OwnerUID          string
+	CreationTimestamp time.Time
+	// ClusterID identifies the cluster the object is in, and is empty if
+	// the cluster doesn't have an ID.
+	ClusterID string
+}
+
+// StoreKubernetesObjects handles storing Kubernetes object snapshots into
+// the specified Presto table.
+func StoreKubernetesObjects(ctx context.Context, execer presto.Execer, tableName string, objects []*KubernetesObject) error {
+	values := make([]string, len(objects))
+	for i, object := range objects {
+		values[i] = generateKubernetesObjectSQLValues(object)
+	}
+	err := insertInBatches(ctx, execer, tableName, values)
+	if err != nil {
+		return fmt.Errorf("failed to store Kubernetes objects into presto: %v", err)
+	}
+	return nil
+}
+
+// generateKubernetesObjectSQLValues turns a KubernetesObject into a SQL
+// literal suited for INSERT statements. Objects without an owner have NULL
+// owner columns, and objects in a cluster without an ID have a NULL
+// cluster_id.
+//
+// The schema is as follows:
+// column "timestamp" type: "timestamp"
+// column "timeprecision" type: "double"
+// column "kind" type: "string"
+// column "namespace" type: "string"
+// column "name" type: "string"
+// column "uid" type: "string"
+// column "labels" type: "map<string, string>"
+// column "annotations" type: "map<string, string>"
+// column "owner_kind" type: "string"
+// column "owner_name" type: "string"
+// column "owner_uid" type: "string"
+// column "creation_timestamp" type: "timestamp"
+// column "cluster_id" type: "string"
+func generateKubernetesObjectSQLValues(object *KubernetesObject) string {
+	return fmt.Sprintf("(timestamp '%s',%f,'%s','%s','%s','%s',%s,%s,%s,%s,%s,timestamp '%s',%s)",
+		presto.Timestamp(object.Timestamp),
+		object.StepSize.Seconds(),
+		escapeSQLString(object.Kind),
+		escapeSQLString(object.Namespace),
+		escapeSQLString(object.Name),
+		escapeSQLString(object.UID),
+		sqlMap(object.Labels),
+		sqlMap(object.Annotations),
+		sqlNullableString(object.OwnerKind),
+		sqlNullableString(object.OwnerName),
+		sqlNullableString(object.OwnerUID),
+		presto.Timestamp(object.CreationTimestamp),
+		sqlNullableString(object.ClusterID),
+	)
+}
+
+func sqlNullableString(s string) string {
+	if s == "" {
+		return "NULL"
+	}
+	return "'" + escapeSQLString(s) + "'"
+}
@@ -0,0 +1,35 @@
+package prestostore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateKubernetesObjectSQLValues(t *testing.T) {
+	object := &KubernetesObject{
+		Timestamp:         time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC),
+		StepSize:          5 * time.Minute,
+		Kind:              "Pod",
+		Namespace:         "team-a",
+		Name:              "app-1",
+		UID:               "8a7c5e3a",
+		Labels:            map[string]string{"app": "it's"},
+		Annotations:       map[string]string{"owner": "team-a"},
+		OwnerKind:         "ReplicaSet",
+		OwnerName:         "app",
+		OwnerUID:          "f2b4b4c1",
+		CreationTimestamp: time.Date(2018, time.June, 30, 12, 0, 0, 0, time.UTC),
+		ClusterID:         "us-east",
+	}
+	expected := `(timestamp '2018-07-01 00:00:00.000',300.000000,'Pod','team-a','app-1','8a7c5e3a',map(ARRAY['app'],ARRAY['it''s']),map(ARRAY['owner'],ARRAY['team-a']),'ReplicaSet','app','f2b4b4c1',timestamp '2018-06-30 12:00:00.000','us-east')`
+	assert.Equal(t, expected, generateKubernetesObjectSQLValues(object))
+
+	// objects without an owner have NULL owner columns
+	object.OwnerKind, object.OwnerName, object.OwnerUID = "", "", ""
+	object.ClusterID = ""
+	object.Labels, object.Annotations = nil, nil
+	expected = `(timestamp '2018-07-01 00:00:00.000',300.000000,'Pod','team-a','app-1','8a7c5e3a',map(ARRAY[],ARRAY[]),map(ARRAY[],ARRAY[]),NULL,NULL,NULL,timestamp '2018-06-30 12:00:00.000',NULL)`
+	assert.Equal(t, expected, generateKubernetesObjectSQLValues(object))
+}
@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"strings"
+	"math"
 	"sync"
 	"time"
 
@@ -15,6 +15,12 @@ const (
 	// prestoQueryCap is the maximum payload size a single SQL statement can contain
 	// before Presto will error due to the payload being too large.
 	prestoQueryCap = 1000000
+
+	// DatePartitionColumn is the partition column of Prometheus metrics
+	// tables partitioned by the date of their metrics, which contains the
+	// date of each metric's timestamp in UTC, formatted as DatePartitionFormat.
+	DatePartitionColumn = "dt"
+	DatePartitionFormat = "2006-01-02"
 )
 
 var bufPool = sync.Pool{
@@ -33,8 +39,9 @@ type PrometheusMetric struct {
 }
 
 // StorePrometheusMetrics handles storing Prometheus metrics into the specified
-// Presto table.
-func StorePrometheusMetrics(ctx context.Context, execer presto.Execer, tableName string, metrics []*PrometheusMetric) error {
+// Presto table. If datePartitioned is true, the table is partitioned by
+// DatePartitionColumn, which is set for each metric.
+func StorePrometheusMetrics(ctx context.Context, execer presto.Execer, tableName string, datePartitioned bool, metrics []*PrometheusMetric) error {
 	queryBuf := bufPool.Get().(*bytes.Buffer)
 	queryBuf.Reset()
 	defer bufPool.Put(queryBuf)
@@ -45,7 +52,7 @@ func StorePrometheusMetrics(ctx context.Context, execer presto.Execer, tableName
 	queryCap := prestoQueryCap - insertStatementLength
 
 	for _, metric := range metrics {
-		metricValue := generatePrometheusMetricSQLValues(metric)
+		metricValue := generatePrometheusMetricSQLValues(metric, datePartitioned)
 
 		select {
 		case <-ctx.Done():
@@ -100,20 +107,47 @@ func StorePrometheusMetrics(ctx context.Context, execer presto.Execer, tableName
 // column "timestamp" type: "timestamp"
 // column "timePrecision" type: "double"
 // column "labels" type: "map<string, string>"
-func generatePrometheusMetricSQLValues(metric *PrometheusMetric) string {
-	var keys []string
-	var vals []string
-	for k, v := range metric.Labels {
-		keys = append(keys, "'"+k+"'")
-		vals = append(vals, "'"+v+"'")
-	}
-	keyString := "ARRAY[" + strings.Join(keys, ",") + "]"
-	valString := "ARRAY[" + strings.Join(vals, ",") + "]"
-	return fmt.Sprintf("(%f,timestamp '%s',%f,map(%s,%s))",
-		metric.Amount, presto.Timestamp(metric.Timestamp), metric.StepSize.Seconds(), keyString, valString)
+//
+// followed by the partition column "dt" type: "string" if datePartitioned is
+// true.
+func generatePrometheusMetricSQLValues(metric *PrometheusMetric, datePartitioned bool) string {
+	if datePartitioned {
+		return fmt.Sprintf("(%f,timestamp '%s',%f,%s,'%s')",
+			metric.Amount, presto.Timestamp(metric.Timestamp), metric.StepSize.Seconds(), sqlMap(metric.Labels), DatePartition(metric.Timestamp))
+	}
+	return fmt.Sprintf("(%f,timestamp '%s',%f,%s)",
+		metric.Amount, presto.Timestamp(metric.Timestamp), metric.StepSize.Seconds(), sqlMap(metric.Labels))
+}
+
+// DatePartition returns the value of the DatePartitionColumn of metrics with
+// the timestamp t.
+func DatePartition(t time.Time) string {
+	return t.UTC().Format(DatePartitionFormat)
 }
 
-func getLastTimestampForTable(queryer presto.Queryer, tableName string) (*time.Time, error) {
+// ValidatePrometheusMetric checks the metric can be stored in a Prometheus
+// metrics table, for metrics which come from outside of the reporting-operator.
+func ValidatePrometheusMetric(metric *PrometheusMetric) error {
+	if metric.Timestamp.IsZero() {
+		return fmt.Errorf("timestamp is required")
+	}
+	if math.IsNaN(metric.Amount) || math.IsInf(metric.Amount, 0) {
+		return fmt.Errorf("amount must be a finite number, got %f", metric.Amount)
+	}
+	if metric.StepSize < 0 {
+		return fmt.Errorf("stepSize cannot be negative, got %s", metric.StepSize)
+	}
+	for key := range metric.Labels {
+		if key == "" {
+			return fmt.Errorf("label names cannot be empty")
+		}
+	}
+	return nil
+}
+
+// GetLastTimestampForTable returns the most recent timestamp stored in the
+// Prometheus metrics table, or nil if the table contains no metrics.
+func GetLastTimestampForTable(queryer presto.Queryer, tableName string) (*time.Time, error) {
 	// Get the most recent timestamp in the table for this query
 	getLastTimestampQuery := fmt.Sprintf(`
 				SELECT "timestamp"
@@ -133,6 +167,35 @@ func getLastTimestampForTable(queryer presto.Queryer, tableName string) (*time.T
 	return nil, nil
 }
 
+// GetLastTimestampForCluster returns the most recent timestamp of the metrics
+// imported from the cluster into the table. If includeUnlabelled is true,
+// metrics without a cluster_id label are also considered.
+func GetLastTimestampForCluster(queryer presto.Queryer, tableName, clusterID string, includeUnlabelled bool) (*time.Time, error) {
+	results, err := queryer.Query(lastTimestampForClusterQuery(tableName, clusterID, includeUnlabelled))
+	if err != nil {
+		return nil, fmt.Errorf("error getting last timestamp of cluster %s for table %s, maybe table doesn't exist yet? %v", clusterID, tableName, err)
+	}
+
+	if len(results) != 0 {
+		ts := results[0]["timestamp"].(time.Time)
+		return &ts, nil
+	}
+	return nil, nil
+}
+
+func lastTimestampForClusterQuery(tableName, clusterID string, includeUnlabelled bool) string {
+	whereClause := fmt.Sprintf("element_at(labels, '%s') = '%s'", ClusterIDLabel, escapeSQLString(clusterID))
+	if includeUnlabelled {
+		whereClause += fmt.Sprintf(" OR element_at(labels, '%s') IS NULL", ClusterIDLabel)
+	}
+	return fmt.Sprintf(`
+				SELECT "timestamp"
+				FROM %s
+				WHERE %s
+				ORDER BY "timestamp" DESC
+				LIMIT 1`, tableName, whereClause)
+}
+
 func GetPrometheusMetrics(queryer presto.Queryer, tableName string, start, end time.Time) ([]*PrometheusMetric, error) {
 	whereClause := ""
 	if !start.IsZero() {
@@ -180,3 +243,122 @@ func GetPrometheusMetrics(queryer presto.Queryer, tableName string, start, end t
 	}
 	return results, nil
 }
+
+// PrometheusMetricsStats summarizes the Prometheus metrics stored in a table
+// for a time range.
+type PrometheusMetricsStats struct {
+	// Samples is the number of metrics in the time range.
+	Samples int64
+	// BelowMin and AboveMax are the number of metrics with an amount
+	// outside of the range the stats were requested for.
+	BelowMin int64
+	AboveMax int64
+	// LabelKeys are the distinct label names of the metrics, sorted.
+	LabelKeys []string
+}
+
+// GetPrometheusMetricsStats returns the PrometheusMetricsStats of the
+// metrics in the table between start (inclusive) and end (exclusive),
+// counting the metrics with an amount below minValue or above maxValue if
+// they're set.
+func GetPrometheusMetricsStats(queryer presto.Queryer, tableName string, start, end time.Time, minValue, maxValue *float64) (PrometheusMetricsStats, error) {
+	var stats PrometheusMetricsStats
+	whereClause := fmt.Sprintf(`WHERE "timestamp" >= timestamp '%s' AND "timestamp" < timestamp '%s'`, presto.Timestamp(start), presto.Timestamp(end))
+
+	belowMin, aboveMax := "CAST(0 AS bigint)", "CAST(0 AS bigint)"
+	if minValue != nil {
+		belowMin = fmt.Sprintf("count_if(amount < %f)", *minValue)
+	}
+	if maxValue != nil {
+		aboveMax = fmt.Sprintf("count_if(amount > %f)", *maxValue)
+	}
+	query := fmt.Sprintf(`SELECT count(*) AS samples, %s AS below_min, %s AS above_max FROM %s %s`, belowMin, aboveMax, tableName, whereClause)
+	rows, err := queryer.Query(query)
+	if err != nil {
+		return stats, err
+	}
+	if len(rows) != 1 {
+		return stats, fmt.Errorf("expected 1 row of stats for table %s, got %d", tableName, len(rows))
+	}
+	for column, count := range map[string]*int64{"samples": &stats.Samples, "below_min": &stats.BelowMin, "above_max": &stats.AboveMax} {
+		var ok bool
+		if *count, ok = rows[0][column].(int64); !ok {
+			return stats, fmt.Errorf("invalid %s, valueType: %T, value: %+v", column, rows[0][column], rows[0][column])
+		}
+	}
+
+	query = fmt.Sprintf(`SELECT DISTINCT label_key FROM %s CROSS JOIN UNNEST(map_keys(labels)) AS t (label_key) %s ORDER BY label_key`, tableName, whereClause)
+	rows, err = queryer.Query(query)
+	if err != nil {
+		return stats, err
+	}
+	for _, row := range rows {
+		key, ok := row["label_key"].(string)
+		if !ok {
+			return stats, fmt.Errorf("invalid label_key, valueType: %T", row["label_key"])
+		}
+		stats.LabelKeys = append(stats.LabelKeys, key)
+	}
+	return stats, nil
+}
+
+// GetTimestampStepCount returns the number of steps of length step between
+// start (inclusive) and end (exclusive) which the table has any rows in,
+// counting steps from the unix epoch.
+func GetTimestampStepCount(queryer presto.Queryer, tableName string, start, end time.Time, step time.Duration) (int64, error) {
+	query := fmt.Sprintf(`SELECT count(DISTINCT floor(to_unixtime("timestamp") / %d)) AS steps FROM %s WHERE "timestamp" >= timestamp '%s' AND "timestamp" < timestamp '%s'`, int64(step.Seconds()), tableName, presto.Timestamp(start), presto.Timestamp(end))
+	rows, err := queryer.Query(query)
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) != 1 {
+		return 0, fmt.Errorf("expected 1 row counting steps for table %s, got %d", tableName, len(rows))
+	}
+	steps, ok := rows[0]["steps"].(int64)
+	if !ok {
+		return 0, fmt.Errorf("invalid steps, valueType: %T, value: %+v", rows[0]["steps"], rows[0]["steps"])
+	}
+	return steps, nil
+}
+
+// NamespaceUsage is the usage of a namespace during a bucket of time,
+// which is the sum of the amount of each metric multiplied by its time
+// precision.
+type NamespaceUsage struct {
+	Namespace string
+	Bucket    time.Time
+	Usage     float64
+}
+
+// GetNamespaceUsage returns the usage of each namespace in each bucket of
+// the table between start and end, using the namespace label of each
+// metric. Metrics without a namespace label are ignored.
+func GetNamespaceUsage(queryer presto.Queryer, tableName string, start, end time.Time, bucket time.Duration) ([]NamespaceUsage, error) {
+	bucketSecs := int64(bucket.Seconds())
+	query := fmt.Sprintf(`SELECT element_at(labels, 'namespace') AS namespace, CAST(floor(to_unixtime("timestamp") / %d) AS bigint) AS bucket, sum(amount * timeprecision) AS usage FROM %s WHERE "timestamp" >= timestamp '%s' AND "timestamp" < timestamp '%s' AND element_at(labels, 'namespace') IS NOT NULL GROUP BY 1, 2`, bucketSecs, tableName, presto.Timestamp(start), presto.Timestamp(end))
+	rows, err := queryer.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	usage := make([]NamespaceUsage, len(rows))
+	for i, row := range rows {
+		namespace, ok := row["namespace"].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid namespace, valueType: %T, value: %+v", row["namespace"], row["namespace"])
+		}
+		b, ok := row["bucket"].(int64)
+		if !ok {
+			return nil, fmt.Errorf("invalid bucket, valueType: %T, value: %+v", row["bucket"], row["bucket"])
+		}
+		amount, ok := row["usage"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("invalid usage, valueType: %T, value: %+v", row["usage"], row["usage"])
+		}
+		usage[i] = NamespaceUsage{
+			Namespace: namespace,
+			Bucket:    time.Unix(b*bucketSecs, 0).UTC(),
+			Usage:     amount,
+		}
+	}
+	return usage, nil
+}
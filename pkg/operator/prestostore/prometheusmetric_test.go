@@ -0,0 +1,69 @@
+package prestostore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	prom "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+func TestLastTimestampForClusterQuery(t *testing.T) {
+	query := lastTimestampForClusterQuery("datasource_pod_usage_cpu_cores", "us-east", false)
+	assert.Contains(t, query, "WHERE element_at(labels, 'cluster_id') = 'us-east'\n")
+
+	query = lastTimestampForClusterQuery("datasource_pod_usage_cpu_cores", "it's", true)
+	assert.Contains(t, query, "WHERE element_at(labels, 'cluster_id') = 'it''s' OR element_at(labels, 'cluster_id') IS NULL\n")
+}
+
+func TestPrometheusImporterClusterID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var insert string
+	queryer := mockpresto.NewMockExecQueryer(ctrl)
+	queryer.EXPECT().Exec(gomock.Any()).DoAndReturn(func(query string) error {
+		insert = query
+		return nil
+	})
+
+	cfg := Config{PrestoTableName: "datasource_pod_usage_cpu_cores", ClusterID: "us-east", Remote: true}
+	importer := NewPrometheusImporter(logrus.New(), nil, nil, queryer, clock.RealClock{}, cfg)
+
+	timeRange := prom.Range{
+		Start: time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2018, time.July, 1, 0, 5, 0, 0, time.UTC),
+		Step:  time.Minute,
+	}
+	matrix := model.Matrix{
+		{
+			Metric: model.Metric{"pod": "app-1"},
+			Values: []model.SamplePair{{Timestamp: model.TimeFromUnix(timeRange.Start.Unix()), Value: 1}},
+		},
+	}
+	err := importer.postQueryHandler(context.Background(), timeRange, matrix)
+	require.NoError(t, err)
+	assert.Contains(t, insert, "'cluster_id'")
+	assert.Contains(t, insert, "'us-east'")
+}
+
+func TestGeneratePrometheusMetricSQLValuesDatePartitioned(t *testing.T) {
+	metric := &PrometheusMetric{
+		Labels:    map[string]string{"pod": "app-1"},
+		Amount:    1,
+		StepSize:  time.Minute,
+		Timestamp: time.Date(2018, time.July, 1, 23, 30, 0, 0, time.UTC),
+	}
+	assert.Equal(t, "(1.000000,timestamp '2018-07-01 23:30:00.000',60.000000,map(ARRAY['pod'],ARRAY['app-1']))", generatePrometheusMetricSQLValues(metric, false))
+	assert.Equal(t, "(1.000000,timestamp '2018-07-01 23:30:00.000',60.000000,map(ARRAY['pod'],ARRAY['app-1']),'2018-07-01')", generatePrometheusMetricSQLValues(metric, true))
+	// metrics are partitioned by their date in UTC.
+	assert.Equal(t, "2018-07-02", DatePartition(time.Date(2018, time.July, 1, 23, 30, 0, 0, time.FixedZone("UTC-5", -5*60*60))))
+}
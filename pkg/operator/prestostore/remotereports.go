@@ -0,0 +1,134 @@
+package prestostore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+// RemoteReportRow is a row of a remote report's results, decoded from the
+// JSON returned by the remote reporting-operator's API with numbers decoded
+// as json.Numbers.
+type RemoteReportRow map[string]interface{}
+
+// StoreRemoteReportResults replaces the contents of the specified Presto
+// table with the rows of a remote report's results. The table must have the
+// columns followed by a cluster_id column.
+func StoreRemoteReportResults(ctx context.Context, execer presto.Execer, tableName, clusterID string, columns []presto.Column, rows []RemoteReportRow) error {
+	values := make([]string, len(rows))
+	for i, row := range rows {
+		value, err := generateRemoteReportRowSQLValues(columns, row, clusterID)
+		if err != nil {
+			return fmt.Errorf("invalid row %d: %v", i, err)
+		}
+		values[i] = value
+	}
+
+	err := presto.DeleteFromContext(ctx, execer, tableName)
+	if err != nil {
+		return fmt.Errorf("couldn't empty table %s of previously loaded results: %v", tableName, err)
+	}
+
+	err = insertInBatches(ctx, execer, tableName, values)
+	if err != nil {
+		return fmt.Errorf("failed to store remote report results into presto: %v", err)
+	}
+	return nil
+}
+
+// generateRemoteReportRowSQLValues turns a row of a remote report's results
+// into a SQL literal suited for INSERT statements, with the clusterID as the
+// last value.
+func generateRemoteReportRowSQLValues(columns []presto.Column, row RemoteReportRow, clusterID string) (string, error) {
+	values := make([]string, len(columns)+1)
+	for i, column := range columns {
+		value, ok := row[column.Name]
+		if !ok {
+			return "", fmt.Errorf("missing column %q", column.Name)
+		}
+		var err error
+		values[i], err = remoteReportSQLValue(column, value)
+		if err != nil {
+			return "", fmt.Errorf("column %q: %v", column.Name, err)
+		}
+	}
+	values[len(columns)] = sqlNullableString(clusterID)
+	return "(" + strings.Join(values, ",") + ")", nil
+}
+
+// IsRemoteReportColumnTypeSupported returns true if the results of remote
+// reports can be loaded into columns of the Presto type.
+func IsRemoteReportColumnTypeSupported(columnType string) bool {
+	switch columnType {
+	case "VARCHAR", "BIGINT", "DOUBLE", "BOOLEAN", "TIMESTAMP", "map(VARCHAR,VARCHAR)":
+		return true
+	}
+	return false
+}
+
+// remoteReportSQLValue converts a value decoded from JSON into a SQL literal
+// of the column's type. Timestamps are encoded in JSON as RFC 3339 strings.
+func remoteReportSQLValue(column presto.Column, value interface{}) (string, error) {
+	if value == nil {
+		return "NULL", nil
+	}
+	switch column.Type {
+	case "VARCHAR":
+		switch v := value.(type) {
+		case string:
+			return "'" + escapeSQLString(v) + "'", nil
+		case json.Number:
+			return "'" + v.String() + "'", nil
+		case bool:
+			return "'" + strconv.FormatBool(v) + "'", nil
+		}
+	case "BIGINT":
+		if v, ok := value.(json.Number); ok {
+			n, err := v.Int64()
+			if err != nil {
+				return "", err
+			}
+			return strconv.FormatInt(n, 10), nil
+		}
+	case "DOUBLE":
+		if v, ok := value.(json.Number); ok {
+			f, err := v.Float64()
+			if err != nil {
+				return "", err
+			}
+			return strconv.FormatFloat(f, 'g', -1, 64), nil
+		}
+	case "BOOLEAN":
+		if v, ok := value.(bool); ok {
+			return strconv.FormatBool(v), nil
+		}
+	case "TIMESTAMP":
+		if v, ok := value.(string); ok {
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				return "", err
+			}
+			return "timestamp '" + presto.Timestamp(t.UTC()) + "'", nil
+		}
+	case "map(VARCHAR,VARCHAR)":
+		if v, ok := value.(map[string]interface{}); ok {
+			m := make(map[string]string, len(v))
+			for key, val := range v {
+				s, ok := val.(string)
+				if !ok {
+					return "", fmt.Errorf("map value for key %q is a %T, not a string", key, val)
+				}
+				m[key] = s
+			}
+			return sqlMap(m), nil
+		}
+	default:
+		return "", fmt.Errorf("unsupported column type %s", column.Type)
+	}
+	return "", fmt.Errorf("cannot convert %T to %s", value, column.Type)
+}
@@ -0,0 +1,49 @@
+package prestostore
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+func TestGenerateRemoteReportRowSQLValues(t *testing.T) {
+	columns := []presto.Column{
+		{Name: "period_end", Type: "TIMESTAMP"},
+		{Name: "namespace", Type: "VARCHAR"},
+		{Name: "pods", Type: "BIGINT"},
+		{Name: "pod_request_cpu_core_seconds", Type: "DOUBLE"},
+		{Name: "labels", Type: "map(VARCHAR,VARCHAR)"},
+		{Name: "billable", Type: "BOOLEAN"},
+	}
+	row := RemoteReportRow{
+		"period_end":                   "2018-07-01T00:00:00Z",
+		"namespace":                    "it's",
+		"pods":                         json.Number("3"),
+		"pod_request_cpu_core_seconds": json.Number("7200.5"),
+		"labels":                       map[string]interface{}{"team": "a"},
+		"billable":                     true,
+	}
+
+	values, err := generateRemoteReportRowSQLValues(columns, row, "eu-west")
+	require.NoError(t, err)
+	assert.Equal(t, `(timestamp '2018-07-01 00:00:00.000','it''s',3,7200.5,map(ARRAY['team'],ARRAY['a']),true,'eu-west')`, values)
+
+	// null values are stored as NULL
+	row["namespace"], row["labels"] = nil, nil
+	values, err = generateRemoteReportRowSQLValues(columns, row, "eu-west")
+	require.NoError(t, err)
+	assert.Equal(t, `(timestamp '2018-07-01 00:00:00.000',NULL,3,7200.5,NULL,true,'eu-west')`, values)
+
+	delete(row, "namespace")
+	_, err = generateRemoteReportRowSQLValues(columns, row, "eu-west")
+	assert.Error(t, err, "missing columns should be an error")
+
+	row["namespace"] = "team-a"
+	row["pods"] = "3"
+	_, err = generateRemoteReportRowSQLValues(columns, row, "eu-west")
+	assert.Error(t, err, "values of the wrong type should be an error")
+}
@@ -0,0 +1,85 @@
+package prestostore
+
+import (
+	"strings"
+
+	"github.com/operator-framework/operator-metering/pkg/promquery"
+)
+
+const (
+	jobLabel             = "job"
+	instanceLabel        = "instance"
+	instanceAddressLabel = "instance_address"
+
+	discoveredMetaLabelPrefix = "__meta_"
+)
+
+// nodeNameDiscoveredLabels are the discovered labels which contain the name
+// of the node a target is running on, in order of preference.
+var nodeNameDiscoveredLabels = []string{
+	"__meta_kubernetes_pod_node_name",
+	"__meta_kubernetes_endpoint_node_name",
+	"__meta_kubernetes_node_name",
+}
+
+type targetKey struct {
+	job, instance string
+}
+
+// targetMetadataIndex maps a metric's job and instance labels to the
+// Prometheus target that produced it.
+type targetMetadataIndex map[targetKey]promquery.Target
+
+func newTargetMetadataIndex(targets []promquery.Target) targetMetadataIndex {
+	index := make(targetMetadataIndex, len(targets))
+	for _, target := range targets {
+		key := targetKey{job: target.Labels[jobLabel], instance: target.Labels[instanceLabel]}
+		if key.job == "" || key.instance == "" {
+			continue
+		}
+		index[key] = target
+	}
+	return index
+}
+
+// enrich adds target discovery metadata to the metric labels if the metric
+// came from a known target. The instance label is rewritten to the name of
+// the node the target runs on, with the original value preserved in the
+// instance_address label, and each of the discoveredLabels requested is
+// copied with its __meta_ prefix removed. Labels already present on the
+// metric are never overwritten, except for instance. Returns true if the
+// labels were enriched.
+func (index targetMetadataIndex) enrich(labels map[string]string, discoveredLabels []string) bool {
+	target, ok := index[targetKey{job: labels[jobLabel], instance: labels[instanceLabel]}]
+	if !ok {
+		return false
+	}
+
+	if nodeName := targetNodeName(target); nodeName != "" {
+		if _, exists := labels[instanceAddressLabel]; !exists {
+			labels[instanceAddressLabel] = labels[instanceLabel]
+		}
+		labels[instanceLabel] = nodeName
+	}
+
+	for _, discoveredLabel := range discoveredLabels {
+		value, ok := target.DiscoveredLabels[discoveredLabel]
+		if !ok {
+			continue
+		}
+		name := strings.TrimPrefix(discoveredLabel, discoveredMetaLabelPrefix)
+		if _, exists := labels[name]; !exists {
+			labels[name] = value
+		}
+	}
+	return true
+}
+
+func targetNodeName(target promquery.Target) string {
+	for _, label := range nodeNameDiscoveredLabels {
+		if nodeName := target.DiscoveredLabels[label]; nodeName != "" {
+			return nodeName
+		}
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package prestostore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/operator-framework/operator-metering/pkg/promquery"
+)
+
+func TestTargetMetadataIndexEnrich(t *testing.T) {
+	targets := []promquery.Target{
+		{
+			DiscoveredLabels: map[string]string{
+				"__meta_kubernetes_pod_node_name": "node-1",
+				"__meta_kubernetes_namespace":     "monitoring",
+			},
+			Labels: map[string]string{"job": "node-exporter", "instance": "10.0.0.1:9100"},
+		},
+		{
+			DiscoveredLabels: map[string]string{"__meta_kubernetes_namespace": "default"},
+			Labels:           map[string]string{"job": "app", "instance": "10.0.0.2:8080"},
+		},
+	}
+	index := newTargetMetadataIndex(targets)
+
+	tests := map[string]struct {
+		labels         map[string]string
+		expectEnriched bool
+		expectedLabels map[string]string
+	}{
+		"unknown target": {
+			labels:         map[string]string{"job": "node-exporter", "instance": "10.0.0.3:9100"},
+			expectEnriched: false,
+			expectedLabels: map[string]string{"job": "node-exporter", "instance": "10.0.0.3:9100"},
+		},
+		"instance rewritten to node name": {
+			labels:         map[string]string{"job": "node-exporter", "instance": "10.0.0.1:9100"},
+			expectEnriched: true,
+			expectedLabels: map[string]string{
+				"job":                  "node-exporter",
+				"instance":             "node-1",
+				"instance_address":     "10.0.0.1:9100",
+				"kubernetes_namespace": "monitoring",
+			},
+		},
+		"target without node name": {
+			labels:         map[string]string{"job": "app", "instance": "10.0.0.2:8080"},
+			expectEnriched: true,
+			expectedLabels: map[string]string{
+				"job":                  "app",
+				"instance":             "10.0.0.2:8080",
+				"kubernetes_namespace": "default",
+			},
+		},
+		"existing labels are not overwritten": {
+			labels:         map[string]string{"job": "node-exporter", "instance": "10.0.0.1:9100", "kubernetes_namespace": "foo"},
+			expectEnriched: true,
+			expectedLabels: map[string]string{
+				"job":                  "node-exporter",
+				"instance":             "node-1",
+				"instance_address":     "10.0.0.1:9100",
+				"kubernetes_namespace": "foo",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			enriched := index.enrich(tt.labels, []string{"__meta_kubernetes_namespace"})
+			assert.Equal(t, tt.expectEnriched, enriched)
+			assert.Equal(t, tt.expectedLabels, tt.labels)
+		})
+	}
+}
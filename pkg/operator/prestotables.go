@@ -81,7 +81,7 @@ func (op *Reporting) updateAWSBillingPartitions(logger log.FieldLogger, datasour
 	logger.Infof("updating partitions for presto table %s", prestoTable.Name)
 
 	// Fetch the billing manifests
-	manifestRetriever := aws.NewManifestRetriever(source.Region, source.Bucket, source.Prefix)
+	manifestRetriever := aws.NewManifestRetriever(source.Region, source.Bucket, source.Prefix, op.awsCredentials)
 	manifests, err := manifestRetriever.RetrieveManifests()
 	if err != nil {
 		return err
@@ -170,6 +170,18 @@ func (op *Reporting) updateAWSBillingPartitions(logger log.FieldLogger, datasour
 
 	logger.Infof("finished updating partitions for prestoTable %q", prestoTable.Name)
 
+	// AWS delivers billing data for a period throughout it and revises it
+	// after it ends, so new and replaced partitions are late data for the
+	// reports which already reported on their billing periods.
+	lateData, err := lateAWSBillingData(toAdd)
+	if err != nil {
+		logger.WithError(err).Errorf("unable to parse the billing periods of the partitions added to %q", prestoTable.Name)
+		return nil
+	}
+	for _, data := range lateData {
+		op.amendReportsForLateData(logger, datasource.Namespace, datasource.Name, data.start, data.end)
+	}
+
 	return nil
 }
 
@@ -241,6 +253,16 @@ func getPartitionChanges(currentPartitions, desiredPartitions []cbTypes.TablePar
 }
 
 func (op *Reporting) createPrestoTableCR(obj runtime.Object, apiVersion, kind string, params hive.TableParameters, properties hive.TableProperties, partitions []presto.TablePartition) error {
+	name, err := meta.NewAccessor().Name(obj)
+	if err != nil {
+		return err
+	}
+	return op.createNamedPrestoTableCR(obj, apiVersion, kind, prestoTableResourceNameFromKind(kind, name), params, properties, partitions)
+}
+
+// createNamedPrestoTableCR is like createPrestoTableCR, but names the
+// PrestoTable resourceName, for objects with more than one table.
+func (op *Reporting) createNamedPrestoTableCR(obj runtime.Object, apiVersion, kind, resourceName string, params hive.TableParameters, properties hive.TableProperties, partitions []presto.TablePartition) error {
 	accessor := meta.NewAccessor()
 	name, err := accessor.Name(obj)
 	if err != nil {
@@ -259,7 +281,6 @@ func (op *Reporting) createPrestoTableCR(obj runtime.Object, apiVersion, kind st
 		return err
 	}
 
-	resourceName := prestoTableResourceNameFromKind(kind, name)
 	prestoTableCR := cbTypes.PrestoTable{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "PrestoTable",
@@ -0,0 +1,257 @@
+package operator
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+// pricingModelRatesEnd is the effective_to of the latest rates of a
+// PricingModel, which apply indefinitely.
+var pricingModelRatesEnd = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// getPricingModels returns the PricingModels in namespace, keyed by name.
+func (op *Reporting) getPricingModels(namespace string) (map[string]*cbTypes.PricingModel, error) {
+	pricingModels, err := op.informers.Metering().V1alpha1().PricingModels().Lister().PricingModels(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*cbTypes.PricingModel, len(pricingModels))
+	for _, pricingModel := range pricingModels {
+		byName[pricingModel.Name] = pricingModel
+	}
+	return byName, nil
+}
+
+// effectivePricingModelRate is a PricingModelRates with the time it stops
+// applying.
+type effectivePricingModelRate struct {
+	cbTypes.PricingModelRates
+	EffectiveTo time.Time
+}
+
+// getEffectivePricingModelRates validates the rates of the pricingModel,
+// and returns them ordered by when they apply.
+func getEffectivePricingModelRates(pricingModel *cbTypes.PricingModel) ([]effectivePricingModelRate, error) {
+	if len(pricingModel.Spec.Rates) == 0 {
+		return nil, fmt.Errorf("PricingModel %s has no rates", pricingModel.Name)
+	}
+	rates := make([]effectivePricingModelRate, len(pricingModel.Spec.Rates))
+	for i, rate := range pricingModel.Spec.Rates {
+		if rate.EffectiveFrom.IsZero() {
+			return nil, fmt.Errorf("PricingModel %s rates %d must set effectiveFrom", pricingModel.Name, i)
+		}
+		prices := map[string]float64{
+			"cpuCoreHour":      rate.CPUCoreHour,
+			"memoryGiBHour":    rate.MemoryGiBHour,
+			"storageGiBMonth":  rate.StorageGiBMonth,
+			"gpuHour":          rate.GPUHour,
+			"egressGB":         rate.EgressGB,
+			"loadBalancerHour": rate.LoadBalancerHour,
+		}
+		for storageClass, price := range rate.StorageClassGiBMonth {
+			prices[fmt.Sprintf("storageClassGiBMonth[%s]", storageClass)] = price
+		}
+		for j, node := range rate.Nodes {
+			prices[fmt.Sprintf("nodes[%d].hour", j)] = node.Hour
+		}
+		for name, price := range prices {
+			if price < 0 {
+				return nil, fmt.Errorf("PricingModel %s rates effective from %s: %s cannot be negative", pricingModel.Name, rate.EffectiveFrom.UTC().Format(time.RFC3339), name)
+			}
+		}
+		rates[i] = effectivePricingModelRate{PricingModelRates: rate}
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].EffectiveFrom.Before(&rates[j].EffectiveFrom)
+	})
+	for i := range rates {
+		if i+1 < len(rates) {
+			if rates[i].EffectiveFrom.Equal(&rates[i+1].EffectiveFrom) {
+				return nil, fmt.Errorf("PricingModel %s has multiple rates effective from %s", pricingModel.Name, rates[i].EffectiveFrom.UTC().Format(time.RFC3339))
+			}
+			rates[i].EffectiveTo = rates[i+1].EffectiveFrom.UTC()
+		} else {
+			rates[i].EffectiveTo = pricingModelRatesEnd
+		}
+	}
+	return rates, nil
+}
+
+func (info *templateInfo) getPricingModel(name string) (*cbTypes.PricingModel, error) {
+	if info == nil || info.pricingModels == nil {
+		return nil, fmt.Errorf("PricingModels can only be used by ReportGenerationQueries with view.disabled set")
+	}
+	pricingModel, ok := info.pricingModels[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown PricingModel %s", name)
+	}
+	return pricingModel, nil
+}
+
+// pricingModelRates renders a relation containing the rates of the
+// PricingModel, with the columns effective_from, effective_to, currency,
+// cpu_core_hour, memory_gib_hour, storage_gib_month, gpu_hour, egress_gb and
+// load_balancer_hour. Each row applies to usage with a timestamp in
+// [effective_from, effective_to), so usage must be joined to its rates by
+// timestamp to not use current prices for past usage, eg:
+// JOIN {| pricingModelRates .Report.Inputs.pricingModel |} AS rates
+// ON usage."timestamp" >= rates.effective_from AND usage."timestamp" < rates.effective_to
+func (info *templateInfo) pricingModelRates(name string) (string, error) {
+	pricingModel, err := info.getPricingModel(name)
+	if err != nil {
+		return "", err
+	}
+	rates, err := getEffectivePricingModelRates(pricingModel)
+	if err != nil {
+		return "", err
+	}
+	var rows []string
+	for _, rate := range rates {
+		rows = append(rows, fmt.Sprintf("(timestamp '%s', timestamp '%s', %s, %s, %s, %s, %s, %s, %s)",
+			presto.Timestamp(rate.EffectiveFrom.UTC()),
+			presto.Timestamp(rate.EffectiveTo),
+			prestoString(pricingModel.Spec.Currency),
+			prestoDouble(rate.CPUCoreHour),
+			prestoDouble(rate.MemoryGiBHour),
+			prestoDouble(rate.StorageGiBMonth),
+			prestoDouble(rate.GPUHour),
+			prestoDouble(rate.EgressGB),
+			prestoDouble(rate.LoadBalancerHour),
+		))
+	}
+	return renderValuesRelation(rows, "effective_from, effective_to, currency, cpu_core_hour, memory_gib_hour, storage_gib_month, gpu_hour, egress_gb, load_balancer_hour"), nil
+}
+
+// pricingModelStorageClassRates renders a relation containing the price of
+// storage of each StorageClass in the PricingModel, with the columns
+// effective_from, effective_to, storageclass and storage_gib_month. Like
+// pricingModelRates, usage must be joined to it by timestamp, and
+// StorageClasses without a row use the storage_gib_month of
+// pricingModelRates.
+func (info *templateInfo) pricingModelStorageClassRates(name string) (string, error) {
+	pricingModel, err := info.getPricingModel(name)
+	if err != nil {
+		return "", err
+	}
+	rates, err := getEffectivePricingModelRates(pricingModel)
+	if err != nil {
+		return "", err
+	}
+	var rows []string
+	for _, rate := range rates {
+		storageClasses := make([]string, 0, len(rate.StorageClassGiBMonth))
+		for storageClass := range rate.StorageClassGiBMonth {
+			storageClasses = append(storageClasses, storageClass)
+		}
+		sort.Strings(storageClasses)
+		for _, storageClass := range storageClasses {
+			rows = append(rows, fmt.Sprintf("(timestamp '%s', timestamp '%s', %s, %s)",
+				presto.Timestamp(rate.EffectiveFrom.UTC()),
+				presto.Timestamp(rate.EffectiveTo),
+				prestoString(storageClass),
+				prestoDouble(rate.StorageClassGiBMonth[storageClass]),
+			))
+		}
+	}
+	if len(rows) == 0 {
+		// VALUES must have at least one row, so return an empty relation
+		// with the same columns instead.
+		return "(SELECT CAST(NULL AS timestamp) AS effective_from, CAST(NULL AS timestamp) AS effective_to, CAST(NULL AS varchar) AS storageclass, CAST(NULL AS double) AS storage_gib_month WHERE false)", nil
+	}
+	return renderValuesRelation(rows, "effective_from, effective_to, storageclass, storage_gib_month"), nil
+}
+
+// pricingModelNodeRates renders a relation containing the price of nodes in
+// the PricingModel, with the columns effective_from, effective_to, priority,
+// rate_name, node, metadata and node_hour. Like pricingModelRates, nodes must
+// be joined to it by timestamp. A node matches a row if node is empty or is
+// the node's name, and metadata is a subset of the node's labels and
+// annotations, which are keyed like kube-state-metrics, eg:
+// label_node_kubernetes_io_instance_type. Nodes matching multiple rows use
+// the one with the lowest priority.
+func (info *templateInfo) pricingModelNodeRates(name string) (string, error) {
+	pricingModel, err := info.getPricingModel(name)
+	if err != nil {
+		return "", err
+	}
+	rates, err := getEffectivePricingModelRates(pricingModel)
+	if err != nil {
+		return "", err
+	}
+	var rows []string
+	for _, rate := range rates {
+		for priority, node := range rate.Nodes {
+			metadata := make(map[string]string, len(node.Labels)+len(node.Annotations))
+			for key, value := range node.Labels {
+				metadata[kubeStateMetricsLabelName("label_", key)] = value
+			}
+			for key, value := range node.Annotations {
+				metadata[kubeStateMetricsLabelName("annotation_", key)] = value
+			}
+			rows = append(rows, fmt.Sprintf("(timestamp '%s', timestamp '%s', %d, %s, %s, %s, %s)",
+				presto.Timestamp(rate.EffectiveFrom.UTC()),
+				presto.Timestamp(rate.EffectiveTo),
+				priority,
+				prestoString(node.Name),
+				prestoString(node.Node),
+				prestoStringMap(metadata),
+				prestoDouble(node.Hour),
+			))
+		}
+	}
+	if len(rows) == 0 {
+		return "(SELECT CAST(NULL AS timestamp) AS effective_from, CAST(NULL AS timestamp) AS effective_to, CAST(NULL AS integer) AS priority, CAST(NULL AS varchar) AS rate_name, CAST(NULL AS varchar) AS node, CAST(NULL AS map(varchar, varchar)) AS metadata, CAST(NULL AS double) AS node_hour WHERE false)", nil
+	}
+	return renderValuesRelation(rows, "effective_from, effective_to, priority, rate_name, node, metadata, node_hour"), nil
+}
+
+// kubeStateMetricsLabelName returns the name of the Prometheus label
+// kube-state-metrics uses for a Kubernetes label or annotation.
+func kubeStateMetricsLabelName(prefix, key string) string {
+	return prefix + strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+func renderValuesRelation(rows []string, columns string) string {
+	var buf bytes.Buffer
+	buf.WriteString("(SELECT * FROM (VALUES ")
+	buf.WriteString(strings.Join(rows, ", "))
+	fmt.Fprintf(&buf, ") AS t (%s))", columns)
+	return buf.String()
+}
+
+func prestoString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+func prestoStringMap(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	quotedKeys := make([]string, len(keys))
+	quotedValues := make([]string, len(keys))
+	for i, key := range keys {
+		quotedKeys[i] = prestoString(key)
+		quotedValues[i] = prestoString(m[key])
+	}
+	return fmt.Sprintf("CAST(MAP(ARRAY[%s], ARRAY[%s]) AS map(varchar, varchar))", strings.Join(quotedKeys, ", "), strings.Join(quotedValues, ", "))
+}
+
+func prestoDouble(f float64) string {
+	return fmt.Sprintf("CAST(%s AS double)", strconv.FormatFloat(f, 'f', -1, 64))
+}
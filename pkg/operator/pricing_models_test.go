@@ -0,0 +1,127 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestGetEffectivePricingModelRates(t *testing.T) {
+	jan := meta.NewTime(time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC))
+	feb := meta.NewTime(time.Date(2018, time.February, 1, 0, 0, 0, 0, time.UTC))
+
+	tests := map[string]struct {
+		rates       []cbTypes.PricingModelRates
+		expectedTo  []time.Time
+		expectError bool
+	}{
+		"no rates": {
+			expectError: true,
+		},
+		"missing effectiveFrom": {
+			rates:       []cbTypes.PricingModelRates{{CPUCoreHour: 1}},
+			expectError: true,
+		},
+		"negative rate": {
+			rates:       []cbTypes.PricingModelRates{{EffectiveFrom: jan, GPUHour: -1}},
+			expectError: true,
+		},
+		"negative node rate": {
+			rates:       []cbTypes.PricingModelRates{{EffectiveFrom: jan, Nodes: []cbTypes.PricingModelNodeRate{{Hour: -1}}}},
+			expectError: true,
+		},
+		"negative storage class rate": {
+			rates:       []cbTypes.PricingModelRates{{EffectiveFrom: jan, StorageClassGiBMonth: map[string]float64{"ssd": -1}}},
+			expectError: true,
+		},
+		"duplicate effectiveFrom": {
+			rates:       []cbTypes.PricingModelRates{{EffectiveFrom: jan}, {EffectiveFrom: jan}},
+			expectError: true,
+		},
+		"single rates apply indefinitely": {
+			rates:      []cbTypes.PricingModelRates{{EffectiveFrom: jan}},
+			expectedTo: []time.Time{pricingModelRatesEnd},
+		},
+		"unordered rates": {
+			rates:      []cbTypes.PricingModelRates{{EffectiveFrom: feb, CPUCoreHour: 2}, {EffectiveFrom: jan, CPUCoreHour: 1}},
+			expectedTo: []time.Time{feb.Time, pricingModelRatesEnd},
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			rates, err := getEffectivePricingModelRates(&cbTypes.PricingModel{
+				ObjectMeta: meta.ObjectMeta{Name: "default"},
+				Spec:       cbTypes.PricingModelSpec{Rates: tt.rates},
+			})
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, rates, len(tt.expectedTo))
+			for i, rate := range rates {
+				assert.Equal(t, tt.expectedTo[i], rate.EffectiveTo)
+				if i > 0 {
+					assert.True(t, rates[i-1].EffectiveFrom.Before(&rate.EffectiveFrom))
+				}
+			}
+		})
+	}
+}
+
+func TestRenderPricingModelRates(t *testing.T) {
+	pricingModel := &cbTypes.PricingModel{
+		ObjectMeta: meta.ObjectMeta{Name: "default"},
+		Spec: cbTypes.PricingModelSpec{
+			Currency: "USD",
+			Rates: []cbTypes.PricingModelRates{
+				{
+					EffectiveFrom:        meta.NewTime(time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)),
+					GPUHour:              0.9,
+					StorageClassGiBMonth: map[string]float64{"ssd": 0.17, "hdd": 0.045},
+				},
+			},
+		},
+	}
+	info := &templateInfo{pricingModels: map[string]*cbTypes.PricingModel{"default": pricingModel}}
+
+	rates, err := info.pricingModelRates("default")
+	require.NoError(t, err)
+	assert.Equal(t, "(SELECT * FROM (VALUES (timestamp '2018-01-01 00:00:00.000', timestamp '9999-12-31 00:00:00.000', 'USD', CAST(0 AS double), CAST(0 AS double), CAST(0 AS double), CAST(0.9 AS double), CAST(0 AS double), CAST(0 AS double))) AS t (effective_from, effective_to, currency, cpu_core_hour, memory_gib_hour, storage_gib_month, gpu_hour, egress_gb, load_balancer_hour))", rates)
+
+	storageClassRates, err := info.pricingModelStorageClassRates("default")
+	require.NoError(t, err)
+	assert.Equal(t, "(SELECT * FROM (VALUES (timestamp '2018-01-01 00:00:00.000', timestamp '9999-12-31 00:00:00.000', 'hdd', CAST(0.045 AS double)), (timestamp '2018-01-01 00:00:00.000', timestamp '9999-12-31 00:00:00.000', 'ssd', CAST(0.17 AS double))) AS t (effective_from, effective_to, storageclass, storage_gib_month))", storageClassRates)
+
+	nodeRates, err := info.pricingModelNodeRates("default")
+	require.NoError(t, err)
+	assert.Equal(t, "(SELECT CAST(NULL AS timestamp) AS effective_from, CAST(NULL AS timestamp) AS effective_to, CAST(NULL AS integer) AS priority, CAST(NULL AS varchar) AS rate_name, CAST(NULL AS varchar) AS node, CAST(NULL AS map(varchar, varchar)) AS metadata, CAST(NULL AS double) AS node_hour WHERE false)", nodeRates, "PricingModels without node rates should render an empty relation")
+
+	pricingModel.Spec.Rates[0].Nodes = []cbTypes.PricingModelNodeRate{
+		{Name: "worker-1", Node: "worker-1", Hour: 1.5},
+		{Name: "large", Labels: map[string]string{"node-pool": "large"}, Annotations: map[string]string{"example.com/rack": "a"}, Hour: 0.5},
+		{Hour: 0.1},
+	}
+	nodeRates, err = info.pricingModelNodeRates("default")
+	require.NoError(t, err)
+	assert.Equal(t, "(SELECT * FROM (VALUES "+
+		"(timestamp '2018-01-01 00:00:00.000', timestamp '9999-12-31 00:00:00.000', 0, 'worker-1', 'worker-1', CAST(MAP(ARRAY[], ARRAY[]) AS map(varchar, varchar)), CAST(1.5 AS double)), "+
+		"(timestamp '2018-01-01 00:00:00.000', timestamp '9999-12-31 00:00:00.000', 1, 'large', '', CAST(MAP(ARRAY['annotation_example_com_rack', 'label_node_pool'], ARRAY['a', 'large']) AS map(varchar, varchar)), CAST(0.5 AS double)), "+
+		"(timestamp '2018-01-01 00:00:00.000', timestamp '9999-12-31 00:00:00.000', 2, '', '', CAST(MAP(ARRAY[], ARRAY[]) AS map(varchar, varchar)), CAST(0.1 AS double))"+
+		") AS t (effective_from, effective_to, priority, rate_name, node, metadata, node_hour))", nodeRates)
+
+	_, err = info.pricingModelRates("missing")
+	assert.Error(t, err, "unknown PricingModels should error")
+
+	var nilInfo *templateInfo
+	_, err = nilInfo.pricingModelRates("default")
+	assert.Error(t, err, "PricingModels should only be usable when rendering a report")
+}
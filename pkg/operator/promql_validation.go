@@ -0,0 +1,176 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	prom "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/workqueue"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+)
+
+// prometheusQueryDryRunTimeout is how long the dry run of a ReportDataSource's
+// query may take.
+const prometheusQueryDryRunTimeout = 30 * time.Second
+
+// prometheusQueryDryRunFunc runs the query as an instant query at ts.
+type prometheusQueryDryRunFunc func(ctx context.Context, query string, ts time.Time) (model.Value, error)
+
+// checkPrometheusDataSourceQuery validates the query of a Prometheus
+// ReportDataSource, setting its QueryValid condition, and returns whether
+// the query is valid. ReportDataSources with invalid queries aren't
+// imported until the query is fixed.
+func (op *Reporting) checkPrometheusDataSourceQuery(logger log.FieldLogger, dataSource *cbTypes.ReportDataSource) (bool, error) {
+	queryName := dataSource.Spec.Promsum.Query
+	var status v1.ConditionStatus
+	var reason, msg string
+	promQuery, err := op.informers.Metering().V1alpha1().ReportPrometheusQueries().Lister().ReportPrometheusQueries(dataSource.Namespace).Get(queryName)
+	if apierrors.IsNotFound(err) {
+		status, reason, msg = v1.ConditionFalse, cbutil.InvalidQueryReason, fmt.Sprintf("ReportPrometheusQuery %s does not exist", queryName)
+	} else if err != nil {
+		return false, err
+	} else {
+		var dryRun prometheusQueryDryRunFunc
+		if op.cfg.PrometheusQueryDryRun {
+			// the local cluster's Prometheus is always the first
+			dryRun = op.prometheusClusters[0].promConn.Query
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), prometheusQueryDryRunTimeout)
+		defer cancel()
+		status, reason, msg = checkPrometheusQuery(ctx, logger, dryRun, promQuery.Spec.Query, op.clock.Now())
+	}
+
+	current := cbutil.GetReportDataSourceCondition(dataSource, cbTypes.ReportDataSourceQueryValid)
+	if current != nil && current.Status == status && current.Reason == reason && current.Message == msg {
+		return status == v1.ConditionTrue, nil
+	}
+	switch reason {
+	case cbutil.InvalidQueryReason:
+		logger.Warnf("reportDataSource has an invalid query, not importing metrics: %s", msg)
+		op.eventRecorder.Event(dataSource, v1.EventTypeWarning, reason, msg)
+	case cbutil.EmptyQueryResultReason:
+		logger.Warnf("reportDataSource query returned no data: %s", msg)
+		op.eventRecorder.Event(dataSource, v1.EventTypeWarning, reason, msg)
+	}
+
+	dataSource = dataSource.DeepCopy()
+	cbutil.SetReportDataSourceCondition(dataSource, *cbutil.NewReportDataSourceCondition(cbTypes.ReportDataSourceQueryValid, status, reason, msg))
+	_, err = op.meteringClient.MeteringV1alpha1().ReportDataSources(dataSource.Namespace).Update(dataSource)
+	if err != nil {
+		return false, err
+	}
+	return status == v1.ConditionTrue, nil
+}
+
+// checkPrometheusQuery checks the query for syntax errors, and if dryRun is
+// set, runs it to check that Prometheus accepts it and that it returns data.
+// It returns the status, reason and message of the QueryValid condition.
+// Errors running the dry run other than Prometheus rejecting the query
+// don't make the query invalid.
+func checkPrometheusQuery(ctx context.Context, logger log.FieldLogger, dryRun prometheusQueryDryRunFunc, query string, ts time.Time) (v1.ConditionStatus, string, string) {
+	if err := validatePromQL(query); err != nil {
+		return v1.ConditionFalse, cbutil.InvalidQueryReason, fmt.Sprintf("query is invalid: %v", err)
+	}
+	if dryRun == nil {
+		return v1.ConditionTrue, cbutil.QueryValidatedReason, "query passed static validation"
+	}
+
+	value, err := dryRun(ctx, query, ts)
+	if promErr, ok := err.(*prom.Error); ok && promErr.Type == prom.ErrBadData {
+		return v1.ConditionFalse, cbutil.InvalidQueryReason, fmt.Sprintf("query was rejected by Prometheus: %s", promErr.Msg)
+	} else if err != nil {
+		logger.WithError(err).Warnf("unable to dry run query, only static validation was performed")
+		return v1.ConditionTrue, cbutil.QueryValidatedReason, "query passed static validation"
+	}
+	if isEmptyPrometheusValue(value) {
+		return v1.ConditionTrue, cbutil.EmptyQueryResultReason, "query is valid, but returned no data when dry run, check that the metrics it uses exist"
+	}
+	return v1.ConditionTrue, cbutil.QueryValidatedReason, "query passed static validation and returned data when dry run"
+}
+
+func isEmptyPrometheusValue(value model.Value) bool {
+	switch v := value.(type) {
+	case model.Vector:
+		return len(v) == 0
+	case model.Matrix:
+		return len(v) == 0
+	case nil:
+		return true
+	default:
+		// scalars and strings always have a value
+		return false
+	}
+}
+
+// enqueueDataSourcesForPrometheusQuery adds the ReportDataSources using the
+// ReportPrometheusQuery to the queue, so that their query is validated
+// again when it changes.
+func (op *Reporting) enqueueDataSourcesForPrometheusQuery(queue workqueue.Interface, obj interface{}) {
+	promQuery, ok := obj.(*cbTypes.ReportPrometheusQuery)
+	if !ok {
+		return
+	}
+	dataSources, err := op.informers.Metering().V1alpha1().ReportDataSources().Lister().ReportDataSources(promQuery.Namespace).List(labels.Everything())
+	if err != nil {
+		op.logger.WithError(err).Errorf("unable to list reportDataSources using ReportPrometheusQuery %s", promQuery.Name)
+		return
+	}
+	for _, dataSource := range dataSources {
+		if dataSource.Spec.Promsum != nil && dataSource.Spec.Promsum.Query == promQuery.Name {
+			queue.Add(dataSource.Namespace + "/" + dataSource.Name)
+		}
+	}
+}
+
+// validatePromQL checks the query for mistakes which Prometheus would reject:
+// unbalanced brackets and unterminated strings. It doesn't fully parse the
+// query, so queries which pass may still be rejected by Prometheus.
+func validatePromQL(query string) error {
+	if strings.TrimSpace(query) == "" {
+		return fmt.Errorf("query must not be empty")
+	}
+	closing := map[rune]rune{'(': ')', '[': ']', '{': '}'}
+	var open []rune
+	for i := 0; i < len(query); i++ {
+		c := rune(query[i])
+		switch c {
+		case '"', '\'', '`':
+			end := i + 1
+			for ; end < len(query) && rune(query[end]) != c; end++ {
+				// backticked strings don't have escape sequences
+				if query[end] == '\\' && c != '`' {
+					end++
+				}
+			}
+			if end >= len(query) {
+				return fmt.Errorf("unterminated string at position %d", i)
+			}
+			i = end
+		case '#':
+			// comments run to the end of the line
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case '(', '[', '{':
+			open = append(open, closing[c])
+		case ')', ']', '}':
+			if len(open) == 0 || open[len(open)-1] != c {
+				return fmt.Errorf("unexpected %q at position %d", c, i)
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	if len(open) != 0 {
+		return fmt.Errorf("missing closing %q", open[len(open)-1])
+	}
+	return nil
+}
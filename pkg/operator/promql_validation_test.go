@@ -0,0 +1,103 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	prom "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+)
+
+func TestCheckPrometheusQuery(t *testing.T) {
+	now := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	const query = `sum(kube_pod_container_resource_requests_cpu_cores) by (pod, namespace, node)`
+	dryRunReturning := func(value model.Value, err error) prometheusQueryDryRunFunc {
+		return func(ctx context.Context, q string, ts time.Time) (model.Value, error) {
+			assert.Equal(t, query, q)
+			assert.Equal(t, now, ts)
+			return value, err
+		}
+	}
+
+	tests := map[string]struct {
+		query          string
+		dryRun         prometheusQueryDryRunFunc
+		expectedStatus v1.ConditionStatus
+		expectedReason string
+	}{
+		"syntax error": {
+			query:          `sum(kube_pod_container_resource_requests_cpu_cores`,
+			expectedStatus: v1.ConditionFalse,
+			expectedReason: cbutil.InvalidQueryReason,
+		},
+		"without dry run": {
+			query:          query,
+			expectedStatus: v1.ConditionTrue,
+			expectedReason: cbutil.QueryValidatedReason,
+		},
+		"rejected by Prometheus": {
+			query:          query,
+			dryRun:         dryRunReturning(nil, &prom.Error{Type: prom.ErrBadData, Msg: "parse error"}),
+			expectedStatus: v1.ConditionFalse,
+			expectedReason: cbutil.InvalidQueryReason,
+		},
+		"Prometheus unavailable": {
+			query:          query,
+			dryRun:         dryRunReturning(nil, errors.New("connection refused")),
+			expectedStatus: v1.ConditionTrue,
+			expectedReason: cbutil.QueryValidatedReason,
+		},
+		"empty result": {
+			query:          query,
+			dryRun:         dryRunReturning(model.Vector{}, nil),
+			expectedStatus: v1.ConditionTrue,
+			expectedReason: cbutil.EmptyQueryResultReason,
+		},
+		"returns data": {
+			query:          query,
+			dryRun:         dryRunReturning(model.Vector{{Metric: model.Metric{"pod": "web"}, Value: 0.5}}, nil),
+			expectedStatus: v1.ConditionTrue,
+			expectedReason: cbutil.QueryValidatedReason,
+		},
+	}
+	for name, tt := range tests {
+		status, reason, msg := checkPrometheusQuery(context.Background(), testLogger, tt.dryRun, tt.query, now)
+		assert.Equal(t, tt.expectedStatus, status, name)
+		assert.Equal(t, tt.expectedReason, reason, name)
+		assert.NotEmpty(t, msg, name)
+	}
+}
+
+func TestValidatePromQL(t *testing.T) {
+	tests := map[string]struct {
+		query     string
+		expectErr bool
+	}{
+		"valid":                     {query: `sum(rate(container_cpu_usage_seconds_total{container_name!="POD"}[1m])) by (pod_name)`},
+		"brackets in strings":       {query: `up{job=~"a[)]", instance='b{'}`},
+		"escaped quote":             {query: `up{job="a\"b"}`},
+		"comment":                   {query: "up # (unbalanced"},
+		"empty":                     {query: "  ", expectErr: true},
+		"unbalanced parenthesis":    {query: `sum(up`, expectErr: true},
+		"mismatched brackets":       {query: `rate(up[5m)]`, expectErr: true},
+		"unexpected closing":        {query: `up)`, expectErr: true},
+		"unterminated string":       {query: `up{job="a}`, expectErr: true},
+		"unterminated raw string":   {query: "up{job=`a}", expectErr: true},
+		"backslash in raw string":   {query: "up{job=~`a\\`}"},
+		"unterminated single quote": {query: `up{job='a}`, expectErr: true},
+	}
+	for name, tt := range tests {
+		err := validatePromQL(tt.query)
+		if tt.expectErr {
+			assert.Error(t, err, name)
+		} else {
+			assert.NoError(t, err, name)
+		}
+	}
+}
@@ -2,12 +2,17 @@ package operator
 
 import (
 	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
 	"time"
 
 	prom "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
 	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
 )
 
@@ -48,6 +53,11 @@ type prometheusImporterTimeRangeTrigger struct {
 }
 
 func (op *Reporting) triggerPrometheusImporterForTimeRange(ctx context.Context, start, end time.Time) error {
+	// the importer only runs on the leader, so nothing would receive the
+	// trigger on a standby replica.
+	if !op.isLeader() {
+		return errNotLeader
+	}
 	errCh := make(chan error)
 	select {
 	case op.prometheusImporterTriggerForTimeRangeCh <- prometheusImporterTimeRangeTrigger{start, end, errCh}:
@@ -88,7 +98,7 @@ func (op *Reporting) startPrometheusImporter(ctx context.Context) {
 				dataSourceName := dataSourceName
 				// collect each dataSource concurrently
 				g.Go(func() error {
-					return importPrometheusDataSourceData(ctx, logger, semaphore, dataSourceName, importer, func(ctx context.Context, importer *prestostore.PrometheusImporter) ([]prom.Range, error) {
+					return importPrometheusDataSourceData(ctx, logger, semaphore, dataSourceName, importer, op.importerTelemetry, op.tableAnalyzer, func(ctx context.Context, importer *prestostore.PrometheusImporter) ([]prom.Range, error) {
 						return importer.ImportMetrics(ctx, trigger.start, trigger.end, true)
 					})
 				})
@@ -102,12 +112,16 @@ func (op *Reporting) startPrometheusImporter(ctx context.Context) {
 		case dataSourceName := <-op.prometheusImporterDeletedDataSourceQueue:
 			// if we have a worker for this ReportDataSource then we need to
 			// stop it and remove it from our map
-			if worker, exists := workers[dataSourceName]; exists {
-				worker.stop()
-				delete(workers, dataSourceName)
-			}
-			if _, exists := importers[dataSourceName]; exists {
-				delete(importers, dataSourceName)
+			for _, cluster := range op.prometheusClusters {
+				key := prometheusImporterKey(dataSourceName, cluster)
+				if worker, exists := workers[key]; exists {
+					worker.stop()
+					delete(workers, key)
+				}
+				if _, exists := importers[key]; exists {
+					delete(importers, key)
+				}
+				op.importerTelemetry.remove(key)
 			}
 		case reportDataSource := <-op.prometheusImporterNewDataSourceQueue:
 			if reportDataSource.Spec.Promsum == nil {
@@ -133,22 +147,7 @@ func (op *Reporting) startPrometheusImporter(ctx context.Context) {
 
 			promQuery := reportPromQuery.Spec.Query
 
-			chunkSize := op.cfg.PrometheusQueryConfig.ChunkSize.Duration
-			stepSize := op.cfg.PrometheusQueryConfig.StepSize.Duration
-			queryInterval := op.cfg.PrometheusQueryConfig.QueryInterval.Duration
-
-			queryConf := reportDataSource.Spec.Promsum.QueryConfig
-			if queryConf != nil {
-				if queryConf.ChunkSize != nil {
-					chunkSize = queryConf.ChunkSize.Duration
-				}
-				if queryConf.StepSize != nil {
-					stepSize = queryConf.StepSize.Duration
-				}
-				if queryConf.QueryInterval != nil {
-					queryInterval = queryConf.QueryInterval.Duration
-				}
-			}
+			queryInterval, stepSize, chunkSize := op.prometheusQueryConfig(reportDataSource)
 
 			cfg := prestostore.Config{
 				PrometheusQuery:       promQuery,
@@ -157,79 +156,249 @@ func (op *Reporting) startPrometheusImporter(ctx context.Context) {
 				StepSize:              stepSize,
 				MaxTimeRanges:         defaultMaxPromTimeRanges,
 				MaxQueryRangeDuration: defaultMaxTimeDuration,
+				DatePartitioned:       isDatePartitionedDataSource(reportDataSource),
+				FaultInjector:         op.faultInjector,
 			}
-
-			importer, exists := importers[dataSourceName]
-			if exists {
-				dataSourceLogger.Debugf("ReportDataSource %s already has an importer, updating configuration", dataSourceName)
-				importer.UpdateConfig(cfg)
-			} else {
-				importer = prestostore.NewPrometheusImporter(dataSourceLogger, op.promConn, op.prestoQueryer, op.clock, cfg)
-				importers[dataSourceName] = importer
+			if op.kafkaPublisher != nil && op.kafkaPublisher.metricsTopic != "" {
+				publisher := op.kafkaPublisher
+				cfg.PublishMetrics = func(metrics []*prestostore.PrometheusMetric) error {
+					return publisher.publishMetrics(dataSourceName, metrics)
+				}
+			}
+			if targetMetadata := reportDataSource.Spec.Promsum.TargetMetadata; targetMetadata != nil {
+				cfg.EnrichTargetMetadata = true
+				cfg.TargetMetadataLabels = targetMetadata.Labels
+			}
+			if exemplars := reportDataSource.Spec.Promsum.Exemplars; exemplars != nil {
+				cfg.ExemplarsTableName = dataSourceExemplarsTableName(dataSourceName)
+				cfg.ExemplarTraceIDLabel = exemplars.TraceIDLabel
 			}
 
-			if !op.cfg.DisablePromsum {
-				worker, workerExists := workers[dataSourceName]
-				if workerExists && worker.queryInterval != queryInterval {
-					// queryInterval changed stop the existing worker from
-					// collecting data, and create it with updated config
-					worker.stop()
-				} else if workerExists {
-					// config hasn't changed skip the update
-					continue
+			// each cluster has its own importer, which imports the cluster's
+			// metrics into the ReportDataSource's table labelled with the
+			// cluster's ID.
+			for _, cluster := range op.prometheusClusters {
+				key := prometheusImporterKey(dataSourceName, cluster)
+				clusterLogger := dataSourceLogger
+				clusterCfg := cfg
+				clusterCfg.ClusterID = cluster.id
+				clusterCfg.Remote = cluster.remote
+				if cluster.id != "" {
+					clusterLogger = dataSourceLogger.WithField("clusterID", cluster.id)
 				}
 
-				worker = newPromImportWorker(queryInterval)
-				workers[dataSourceName] = worker
+				importer, exists := importers[key]
+				if exists {
+					clusterLogger.Debugf("ReportDataSource %s already has an importer, updating configuration", dataSourceName)
+					importer.UpdateConfig(clusterCfg)
+				} else {
+					importer = prestostore.NewPrometheusImporter(clusterLogger, cluster.promConn, cluster.promAPI, op.importerPrestoQueryer, op.clock, clusterCfg)
+					importers[key] = importer
+				}
 
-				// launch a go routine that periodically triggers a collection
-				go worker.start(ctx, dataSourceLogger, semaphore, dataSourceName, importer)
+				if !op.cfg.DisablePromsum {
+					worker, workerExists := workers[key]
+					if workerExists && worker.queryInterval != queryInterval {
+						// queryInterval changed stop the existing worker from
+						// collecting data, and create it with updated config
+						worker.stop()
+					} else if workerExists {
+						// config hasn't changed skip the update
+						continue
+					}
+
+					worker = newPromImportWorker(queryInterval, prometheusImportOffset(key, queryInterval), op.cfg.PrometheusImportJitterFactor)
+					workers[key] = worker
+
+					// only the local cluster's imports set the
+					// LastImportSucceeded condition, so that it doesn't
+					// flap between the results of each cluster's import.
+					var onImport func(error)
+					if !cluster.remote {
+						namespace := reportDataSource.Namespace
+						onImport = func(err error) {
+							op.updateDataSourceImportCondition(clusterLogger, namespace, dataSourceName, err)
+						}
+					}
+
+					// launch a go routine that periodically triggers a collection
+					go worker.start(ctx, clusterLogger, semaphore, key, importer, op.importerTelemetry, op.tableAnalyzer, onImport)
+				}
 			}
 		}
 	}
 }
 
+// prometheusQueryConfig returns how often the metrics of a promsum
+// ReportDataSource are imported, and the step and chunk size of the queries
+// importing them.
+func (op *Reporting) prometheusQueryConfig(dataSource *cbTypes.ReportDataSource) (queryInterval, stepSize, chunkSize time.Duration) {
+	return resolvePrometheusQueryConfig(op.cfg.PrometheusQueryConfig, dataSource.Spec.Promsum.QueryConfig)
+}
+
+// resolvePrometheusQueryConfig returns the queryInterval, stepSize and
+// chunkSize set by queryConf, using defaults for those it doesn't set. If
+// queryConf sets a queryInterval shorter than the default chunkSize, the
+// chunkSize and stepSize default to at most the queryInterval instead, so
+// that each import stores a complete chunk.
+func resolvePrometheusQueryConfig(defaults cbTypes.PrometheusQueryConfig, queryConf *cbTypes.PrometheusQueryConfig) (queryInterval, stepSize, chunkSize time.Duration) {
+	if defaults.QueryInterval != nil {
+		queryInterval = defaults.QueryInterval.Duration
+	}
+	if defaults.StepSize != nil {
+		stepSize = defaults.StepSize.Duration
+	}
+	if defaults.ChunkSize != nil {
+		chunkSize = defaults.ChunkSize.Duration
+	}
+	if queryConf == nil {
+		return queryInterval, stepSize, chunkSize
+	}
+
+	if queryConf.QueryInterval != nil {
+		queryInterval = queryConf.QueryInterval.Duration
+	}
+	if queryConf.ChunkSize != nil {
+		chunkSize = queryConf.ChunkSize.Duration
+	} else if queryConf.QueryInterval != nil && queryInterval < chunkSize {
+		chunkSize = queryInterval
+	}
+	if queryConf.StepSize != nil {
+		stepSize = queryConf.StepSize.Duration
+	} else if chunkSize < stepSize {
+		stepSize = chunkSize
+	}
+	return queryInterval, stepSize, chunkSize
+}
+
+// validatePrometheusQueryConfig checks the queryConfig of a promsum
+// ReportDataSource.
+func validatePrometheusQueryConfig(queryConf *cbTypes.PrometheusQueryConfig) error {
+	if queryConf == nil {
+		return nil
+	}
+	for _, d := range []struct {
+		name     string
+		duration *meta.Duration
+	}{
+		{"queryInterval", queryConf.QueryInterval},
+		{"stepSize", queryConf.StepSize},
+		{"chunkSize", queryConf.ChunkSize},
+	} {
+		if d.duration != nil && d.duration.Duration <= 0 {
+			return fmt.Errorf("queryConfig.%s must be positive, got %s", d.name, d.duration.Duration)
+		}
+	}
+	if queryConf.StepSize != nil && queryConf.ChunkSize != nil && queryConf.StepSize.Duration > queryConf.ChunkSize.Duration {
+		return fmt.Errorf("queryConfig.stepSize %s must not be larger than queryConfig.chunkSize %s", queryConf.StepSize.Duration, queryConf.ChunkSize.Duration)
+	}
+	return nil
+}
+
 type prometheusImporterWorker struct {
 	stopCh        chan struct{}
 	doneCh        chan struct{}
 	queryInterval time.Duration
+	// imports run offset after the start of each queryInterval, and are
+	// delayed by a random duration of up to jitterFactor of the
+	// queryInterval, so that workers don't all import at the same time.
+	offset       time.Duration
+	jitterFactor float64
 }
 
-func newPromImportWorker(queryInterval time.Duration) *prometheusImporterWorker {
+func newPromImportWorker(queryInterval, offset time.Duration, jitterFactor float64) *prometheusImporterWorker {
 	return &prometheusImporterWorker{
 		queryInterval: queryInterval,
+		offset:        offset,
+		jitterFactor:  jitterFactor,
 		stopCh:        make(chan struct{}),
 		doneCh:        make(chan struct{}),
 	}
 }
 
-// start begins periodic importing with the configured importer.
-func (w *prometheusImporterWorker) start(ctx context.Context, logger logrus.FieldLogger, semaphore chan struct{}, dataSourceName string, importer *prestostore.PrometheusImporter) {
-	ticker := time.NewTicker(w.queryInterval)
+// start begins periodic importing with the configured importer. If onImport
+// is set, it's called with the result of each import.
+func (w *prometheusImporterWorker) start(ctx context.Context, logger logrus.FieldLogger, semaphore chan struct{}, dataSourceName string, importer *prestostore.PrometheusImporter, telemetry *importerTelemetry, analyzer *tableAnalyzer, onImport func(error)) {
+	now := time.Now()
+	scheduled := firstPrometheusImport(now, w.queryInterval, w.offset)
+	timer := time.NewTimer(w.importDelay(scheduled, now))
 	defer close(w.doneCh)
-	defer ticker.Stop()
+	defer timer.Stop()
 
-	logger.Infof("Importing data for ReportDataSource %s every %s", dataSourceName, w.queryInterval)
+	logger.Infof("Importing data for ReportDataSource %s every %s, starting at %s", dataSourceName, w.queryInterval, scheduled.UTC().Format(time.RFC3339))
 	for {
 		select {
 		case <-w.stopCh:
 			return
-		case _, ok := <-ticker.C:
-			if !ok {
-				return
-			}
-			err := importPrometheusDataSourceData(ctx, logger, semaphore, dataSourceName, importer, func(ctx context.Context, importer *prestostore.PrometheusImporter) ([]prom.Range, error) {
+		case <-timer.C:
+			err := importPrometheusDataSourceData(ctx, logger, semaphore, dataSourceName, importer, telemetry, analyzer, func(ctx context.Context, importer *prestostore.PrometheusImporter) ([]prom.Range, error) {
 				return importer.ImportFromLastTimestamp(ctx, false)
 			})
 			if err != nil {
 				logger.WithError(err).Errorf("error collecting Prometheus DataSource data")
 			}
+			// imports interrupted by shutting down didn't fail
+			if onImport != nil && ctx.Err() == nil {
+				onImport(err)
+			}
+			now = time.Now()
+			scheduled = nextPrometheusImport(scheduled, now, w.queryInterval)
+			timer.Reset(w.importDelay(scheduled, now))
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
+// importDelay returns how long to wait from now until the import scheduled
+// at scheduled, plus the jitter.
+func (w *prometheusImporterWorker) importDelay(scheduled, now time.Time) time.Duration {
+	delay := scheduled.Sub(now)
+	if delay < 0 {
+		delay = 0
+	}
+	if w.jitterFactor > 0 {
+		delay += time.Duration(rand.Float64() * w.jitterFactor * float64(w.queryInterval))
+	}
+	return delay
+}
+
+// firstPrometheusImport returns the first time after now which is offset
+// after the start of a queryInterval. Scheduling imports relative to the
+// clock rather than to when the worker started keeps each importer's
+// schedule the same across restarts and leader changes.
+func firstPrometheusImport(now time.Time, queryInterval, offset time.Duration) time.Time {
+	first := now.Truncate(queryInterval).Add(offset)
+	if first.Before(now) {
+		first = first.Add(queryInterval)
+	}
+	return first
+}
+
+// nextPrometheusImport returns when the import after the one scheduled at
+// previous should run. Like a ticker, imports which were missed because an
+// import took longer than the queryInterval are skipped.
+func nextPrometheusImport(previous, now time.Time, queryInterval time.Duration) time.Time {
+	next := previous.Add(queryInterval)
+	if next.Before(now) {
+		missed := now.Sub(next) / queryInterval
+		next = next.Add((missed + 1) * queryInterval)
+	}
+	return next
+}
+
+// prometheusImportOffset returns how long after the start of each
+// queryInterval the importer with key imports. Hashing the key spreads the
+// importers evenly over the queryInterval.
+func prometheusImportOffset(key string, queryInterval time.Duration) time.Duration {
+	if queryInterval <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return time.Duration(h.Sum64() % uint64(queryInterval))
+}
+
 func (w *prometheusImporterWorker) stop() {
 	close(w.stopCh)
 	<-w.doneCh
@@ -237,7 +406,7 @@ func (w *prometheusImporterWorker) stop() {
 
 type importFunc func(context.Context, *prestostore.PrometheusImporter) ([]prom.Range, error)
 
-func importPrometheusDataSourceData(ctx context.Context, logger logrus.FieldLogger, semaphore chan struct{}, dataSourceName string, prometheusImporter *prestostore.PrometheusImporter, runImport importFunc) error {
+func importPrometheusDataSourceData(ctx context.Context, logger logrus.FieldLogger, semaphore chan struct{}, dataSourceName string, prometheusImporter *prestostore.PrometheusImporter, telemetry *importerTelemetry, analyzer *tableAnalyzer, runImport importFunc) error {
 	// blocks trying to increment the semaphore (sending on the
 	// channel) or until the context is cancelled
 	select {
@@ -253,6 +422,12 @@ func importPrometheusDataSourceData(ctx context.Context, logger logrus.FieldLogg
 	}()
 	dataSourceLogger.Infof("starting import for Prometheus ReportDataSource %s", dataSourceName)
 
-	_, err := runImport(ctx, prometheusImporter)
+	timeRanges, err := runImport(ctx, prometheusImporter)
+	stats := prometheusImporter.LastImportStats()
+	telemetry.record(dataSourceName, stats)
+	// the stats are only updated by imports which queried a time range.
+	if len(timeRanges) != 0 && stats != nil {
+		analyzer.recordImportedRows(stats.TableName, stats.Metrics)
+	}
 	return err
 }
@@ -0,0 +1,135 @@
+package operator
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestPrometheusImportOffset(t *testing.T) {
+	const queryInterval = 5 * time.Minute
+	assert.Equal(t, prometheusImportOffset("pod-usage-cpu-cores", queryInterval), prometheusImportOffset("pod-usage-cpu-cores", queryInterval), "offsets should be deterministic")
+
+	// the offsets of many importers should be spread over the whole
+	// interval.
+	buckets := make([]int, 5)
+	for i := 0; i < 500; i++ {
+		offset := prometheusImportOffset(fmt.Sprintf("datasource-%d", i), queryInterval)
+		assert.True(t, offset >= 0 && offset < queryInterval, "offset %s should be within the query interval", offset)
+		buckets[offset/time.Minute]++
+	}
+	for minute, count := range buckets {
+		assert.InDelta(t, 100, count, 40, "about a fifth of the importers should start in minute %d", minute)
+	}
+}
+
+func TestPrometheusImportSchedule(t *testing.T) {
+	const queryInterval = 5 * time.Minute
+	now := time.Date(2018, time.August, 1, 0, 7, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		offset   time.Duration
+		expected time.Time
+	}{
+		"offset later in this interval": {
+			offset:   3 * time.Minute,
+			expected: time.Date(2018, time.August, 1, 0, 8, 0, 0, time.UTC),
+		},
+		"offset already passed in this interval": {
+			offset:   time.Minute,
+			expected: time.Date(2018, time.August, 1, 0, 11, 0, 0, time.UTC),
+		},
+	}
+	for name, test := range tests {
+		assert.Equal(t, test.expected, firstPrometheusImport(now, queryInterval, test.offset), name)
+	}
+
+	scheduled := time.Date(2018, time.August, 1, 0, 8, 0, 0, time.UTC)
+	assert.Equal(t, scheduled.Add(queryInterval), nextPrometheusImport(scheduled, scheduled.Add(time.Minute), queryInterval))
+	// imports missed while a long import ran are skipped.
+	assert.Equal(t, scheduled.Add(3*queryInterval), nextPrometheusImport(scheduled, scheduled.Add(12*time.Minute), queryInterval))
+}
+
+func TestPrometheusImportDelay(t *testing.T) {
+	const queryInterval = 5 * time.Minute
+	now := time.Date(2018, time.August, 1, 0, 7, 0, 0, time.UTC)
+
+	worker := newPromImportWorker(queryInterval, 0, 0)
+	assert.Equal(t, time.Minute, worker.importDelay(now.Add(time.Minute), now))
+	assert.Equal(t, time.Duration(0), worker.importDelay(now.Add(-time.Minute), now), "an import scheduled in the past should run immediately")
+
+	worker = newPromImportWorker(queryInterval, 0, 0.2)
+	for i := 0; i < 100; i++ {
+		delay := worker.importDelay(now.Add(time.Minute), now)
+		assert.True(t, delay >= time.Minute && delay < 2*time.Minute, "delay %s should be jittered by at most a fifth of the query interval", delay)
+	}
+}
+
+func TestResolvePrometheusQueryConfig(t *testing.T) {
+	defaults := cbTypes.PrometheusQueryConfig{
+		QueryInterval: &meta.Duration{Duration: 5 * time.Minute},
+		StepSize:      &meta.Duration{Duration: time.Minute},
+		ChunkSize:     &meta.Duration{Duration: 5 * time.Minute},
+	}
+	duration := func(d time.Duration) *meta.Duration {
+		return &meta.Duration{Duration: d}
+	}
+
+	tests := map[string]struct {
+		queryConf             *cbTypes.PrometheusQueryConfig
+		expectedQueryInterval time.Duration
+		expectedStepSize      time.Duration
+		expectedChunkSize     time.Duration
+	}{
+		"defaults": {
+			expectedQueryInterval: 5 * time.Minute,
+			expectedStepSize:      time.Minute,
+			expectedChunkSize:     5 * time.Minute,
+		},
+		"longer queryInterval": {
+			queryConf:             &cbTypes.PrometheusQueryConfig{QueryInterval: duration(time.Hour)},
+			expectedQueryInterval: time.Hour,
+			expectedStepSize:      time.Minute,
+			expectedChunkSize:     5 * time.Minute,
+		},
+		"queryInterval shorter than the default chunkSize": {
+			queryConf:             &cbTypes.PrometheusQueryConfig{QueryInterval: duration(time.Minute)},
+			expectedQueryInterval: time.Minute,
+			expectedStepSize:      time.Minute,
+			expectedChunkSize:     time.Minute,
+		},
+		"queryInterval shorter than the default stepSize": {
+			queryConf:             &cbTypes.PrometheusQueryConfig{QueryInterval: duration(30 * time.Second)},
+			expectedQueryInterval: 30 * time.Second,
+			expectedStepSize:      30 * time.Second,
+			expectedChunkSize:     30 * time.Second,
+		},
+		"explicit chunkSize": {
+			queryConf:             &cbTypes.PrometheusQueryConfig{QueryInterval: duration(time.Minute), ChunkSize: duration(10 * time.Minute)},
+			expectedQueryInterval: time.Minute,
+			expectedStepSize:      time.Minute,
+			expectedChunkSize:     10 * time.Minute,
+		},
+	}
+	for name, test := range tests {
+		queryInterval, stepSize, chunkSize := resolvePrometheusQueryConfig(defaults, test.queryConf)
+		assert.Equal(t, test.expectedQueryInterval, queryInterval, name)
+		assert.Equal(t, test.expectedStepSize, stepSize, name)
+		assert.Equal(t, test.expectedChunkSize, chunkSize, name)
+	}
+}
+
+func TestValidatePrometheusQueryConfig(t *testing.T) {
+	duration := func(d time.Duration) *meta.Duration {
+		return &meta.Duration{Duration: d}
+	}
+	assert.NoError(t, validatePrometheusQueryConfig(nil))
+	assert.NoError(t, validatePrometheusQueryConfig(&cbTypes.PrometheusQueryConfig{QueryInterval: duration(time.Minute)}))
+	assert.EqualError(t, validatePrometheusQueryConfig(&cbTypes.PrometheusQueryConfig{QueryInterval: duration(0)}), "queryConfig.queryInterval must be positive, got 0s")
+	assert.EqualError(t, validatePrometheusQueryConfig(&cbTypes.PrometheusQueryConfig{StepSize: duration(2 * time.Minute), ChunkSize: duration(time.Minute)}), "queryConfig.stepSize 2m0s must not be larger than queryConfig.chunkSize 1m0s")
+}
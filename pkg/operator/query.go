@@ -5,7 +5,9 @@ import (
 	"strings"
 
 	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/tools/cache"
 )
@@ -28,7 +30,9 @@ func (op *Reporting) processReportGenerationQuery(logger log.FieldLogger) bool {
 
 	logger = logger.WithFields(newLogIdentifier(op.rand))
 	if key, ok := op.getKeyFromQueueObj(logger, "ReportGenerationQuery", obj, op.queues.reportGenerationQueryQueue); ok {
+		start := op.clock.Now()
 		err := op.syncReportGenerationQuery(logger, key)
+		op.observeReconcile("ReportGenerationQuery", start, err)
 		op.handleErr(logger, err, "ReportGenerationQuery", key, op.queues.reportGenerationQueryQueue)
 	}
 	return true
@@ -65,26 +69,43 @@ func (op *Reporting) syncReportGenerationQuery(logger log.FieldLogger, key strin
 func (op *Reporting) handleReportGenerationQuery(logger log.FieldLogger, generationQuery *cbTypes.ReportGenerationQuery) error {
 	generationQuery = generationQuery.DeepCopy()
 
+	if op.recordGenerationQueryRevision(logger, generationQuery) {
+		newQuery, err := op.meteringClient.MeteringV1alpha1().ReportGenerationQueries(generationQuery.Namespace).Update(generationQuery)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to update ReportGenerationQuery revisions for %q", generationQuery.Name)
+			return err
+		}
+		generationQuery = newQuery
+	}
+
+	if valid, err := op.validateGenerationQuery(logger, generationQuery, true); err != nil {
+		op.updateGenerationQueryCondition(logger, generationQuery, *cbutil.NewReportGenerationQueryCondition(cbTypes.ReportGenerationQueryReady, v1.ConditionFalse, cbutil.InvalidDependenciesReason, err.Error()))
+		return err
+	} else if !valid {
+		logger.Warnf("cannot validate reportGenerationQuery or create its view, it has uninitialized dependencies")
+		_, err = op.updateGenerationQueryCondition(logger, generationQuery, *cbutil.NewReportGenerationQueryCondition(cbTypes.ReportGenerationQueryReady, v1.ConditionFalse, cbutil.DependenciesNotInitializedReason, "waiting for the query's dependencies to be initialized"))
+		return err
+	}
+
+	generationQuery, err := op.checkGenerationQueryValid(logger, generationQuery)
+	if err != nil {
+		return err
+	}
+
 	var viewName string
 	if generationQuery.ViewName == "" {
 		logger.Infof("new reportGenerationQuery discovered")
 		if generationQuery.Spec.View.Disabled {
-			logger.Infof("reportGenerationQuery has spec.view.disabled=true, skipping processing")
-			return nil
+			logger.Infof("reportGenerationQuery has spec.view.disabled=true, skipping view creation")
+			_, err = op.updateGenerationQueryCondition(logger, generationQuery, *cbutil.NewReportGenerationQueryCondition(cbTypes.ReportGenerationQueryReady, v1.ConditionTrue, cbutil.ViewDisabledReason, "query has spec.view.disabled set, no view is created"))
+			return err
 		}
-		viewName = generationQueryViewName(generationQuery.Name)
+		viewName = tenantTableName(op.tenantNamespace(generationQuery.Namespace), generationQueryViewName(generationQuery.Name))
 	} else {
 		logger.Infof("existing reportGenerationQuery discovered, viewName: %s", generationQuery.ViewName)
 		viewName = generationQuery.ViewName
 	}
 
-	if valid, err := op.validateGenerationQuery(logger, generationQuery, true); err != nil {
-		return err
-	} else if !valid {
-		logger.Warnf("cannot create view for reportGenerationQuery, it has uninitialized dependencies")
-		return nil
-	}
-
 	dependentQueries, err := op.getDependentGenerationQueries(generationQuery, true)
 	if err != nil {
 		return err
@@ -92,6 +113,7 @@ func (op *Reporting) handleReportGenerationQuery(logger log.FieldLogger, generat
 	templateInfo := &templateInfo{
 		DynamicDependentQueries: dependentQueries,
 		Report:                  nil,
+		tenantNamespace:         op.tenantNamespace(generationQuery.Namespace),
 	}
 
 	qr := queryRenderer{templateInfo: templateInfo}
@@ -100,9 +122,15 @@ func (op *Reporting) handleReportGenerationQuery(logger log.FieldLogger, generat
 		return err
 	}
 
+	err = op.ensureTenantSchema(logger, generationQuery.Namespace)
+	if err != nil {
+		return err
+	}
+
 	query := fmt.Sprintf("CREATE OR REPLACE VIEW %s AS %s", viewName, renderedQuery)
 	_, err = op.prestoConn.Query(query)
 	if err != nil {
+		op.updateGenerationQueryCondition(logger, generationQuery, *cbutil.NewReportGenerationQueryCondition(cbTypes.ReportGenerationQueryReady, v1.ConditionFalse, cbutil.ViewCreationFailedReason, fmt.Sprintf("unable to create view %s: %v", viewName, err)))
 		return err
 	}
 
@@ -111,11 +139,18 @@ func (op *Reporting) handleReportGenerationQuery(logger log.FieldLogger, generat
 
 func (op *Reporting) updateReportQueryViewName(logger log.FieldLogger, generationQuery *cbTypes.ReportGenerationQuery, viewName string) error {
 	generationQuery.ViewName = viewName
+	var previous v1.ConditionStatus
+	if cond := cbutil.GetReportGenerationQueryCondition(generationQuery, cbTypes.ReportGenerationQueryReady); cond != nil {
+		previous = cond.Status
+	}
+	msg := fmt.Sprintf("view %s has been created", viewName)
+	cbutil.SetReportGenerationQueryCondition(generationQuery, *cbutil.NewReportGenerationQueryCondition(cbTypes.ReportGenerationQueryReady, v1.ConditionTrue, cbutil.ViewCreatedReason, msg))
 	_, err := op.meteringClient.MeteringV1alpha1().ReportGenerationQueries(generationQuery.Namespace).Update(generationQuery)
 	if err != nil {
 		logger.WithError(err).Errorf("failed to update ReportGenerationQuery view name for %q", generationQuery.Name)
 		return err
 	}
+	op.recordConditionTransition(generationQuery, string(cbTypes.ReportGenerationQueryReady), previous, v1.ConditionTrue, cbutil.ViewCreatedReason, msg)
 	return nil
 }
 
@@ -232,7 +267,7 @@ func (op *Reporting) getDependentGenerationQueriesMemoized(generationQuery *cbTy
 		if _, exists := queriesAccumulator[queryName]; exists {
 			continue
 		}
-		genQuery, err := op.informers.Metering().V1alpha1().ReportGenerationQueries().Lister().ReportGenerationQueries(op.cfg.Namespace).Get(queryName)
+		genQuery, err := op.informers.Metering().V1alpha1().ReportGenerationQueries().Lister().ReportGenerationQueries(generationQuery.Namespace).Get(queryName)
 		if err != nil {
 			return err
 		}
@@ -248,7 +283,7 @@ func (op *Reporting) getDependentGenerationQueriesMemoized(generationQuery *cbTy
 func (op *Reporting) getDependentDataSources(generationQuery *cbTypes.ReportGenerationQuery) ([]*cbTypes.ReportDataSource, error) {
 	dataSources := make([]*cbTypes.ReportDataSource, len(generationQuery.Spec.DataSources))
 	for i, dataSourceName := range generationQuery.Spec.DataSources {
-		dataSource, err := op.informers.Metering().V1alpha1().ReportDataSources().Lister().ReportDataSources(op.cfg.Namespace).Get(dataSourceName)
+		dataSource, err := op.getDependentDataSource(generationQuery.Namespace, dataSourceName)
 		if err != nil {
 			return nil, err
 		}
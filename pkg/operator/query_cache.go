@@ -0,0 +1,219 @@
+package operator
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	// DefaultQueryCacheTTL and DefaultQueryCacheMaxRows are the defaults of
+	// the API query results cache.
+	DefaultQueryCacheTTL     = time.Minute
+	DefaultQueryCacheMaxRows = 100000
+
+	queryCacheResultHit  = "hit"
+	queryCacheResultMiss = "miss"
+)
+
+var queryCacheRequestsCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "metering",
+		Name:      "api_query_cache_requests_total",
+		Help:      "The number of report results and ad-hoc query requests to the API, by whether their results were served from the cache.",
+	},
+	[]string{"result"},
+)
+
+func init() {
+	prometheus.MustRegister(queryCacheRequestsCounter)
+}
+
+// QueryCacheConfig configures the cache of the Presto query results served
+// by the API's report results and ad-hoc query endpoints.
+type QueryCacheConfig struct {
+	// TTL is how long results are cached for. If 0, results aren't cached.
+	TTL time.Duration
+	// MaxRows is the most rows cached across all results. The least
+	// recently used results are evicted first, and results with more rows
+	// aren't cached.
+	MaxRows int
+}
+
+func (cfg QueryCacheConfig) Valid() error {
+	if cfg.TTL < 0 {
+		return fmt.Errorf("the query cache TTL must not be negative, got %s", cfg.TTL)
+	}
+	if cfg.TTL > 0 && cfg.MaxRows <= 0 {
+		return fmt.Errorf("the query cache max rows must be positive when the query cache is enabled, got %d", cfg.MaxRows)
+	}
+	return nil
+}
+
+type queryCacheEntry struct {
+	key     string
+	rows    []presto.Row
+	expires time.Time
+}
+
+// queryCache is a bounded LRU cache of query results, keyed by the query and
+// the version of the data it reads, so that dashboards repeatedly fetching
+// the same results don't run the same Presto query each time. Concurrent
+// requests for results which aren't cached share a single query. A nil
+// queryCache caches nothing.
+type queryCache struct {
+	ttl     time.Duration
+	maxRows int
+	now     func() time.Time
+	group   singleflight.Group
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	rows    int
+}
+
+// newQueryCache returns a queryCache configured by cfg, or nil if caching
+// is disabled.
+func newQueryCache(cfg QueryCacheConfig, now func() time.Time) *queryCache {
+	if cfg.TTL == 0 {
+		return nil
+	}
+	return &queryCache{
+		ttl:     cfg.TTL,
+		maxRows: cfg.MaxRows,
+		now:     now,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// queryCacheKey returns the cache key of the results of query when the data
+// it reads is at dataVersion.
+func queryCacheKey(query, dataVersion string) string {
+	sum := sha256.Sum256([]byte(dataVersion + "\x00" + query))
+	return hex.EncodeToString(sum[:])
+}
+
+// get returns the cached results of query at dataVersion, running query
+// with runQuery and caching its results if they aren't cached. Results
+// with an empty dataVersion are never cached. The rows returned are shared
+// with other callers and must not be modified.
+func (c *queryCache) get(query, dataVersion string, runQuery func() ([]presto.Row, error)) ([]presto.Row, error) {
+	if c == nil || dataVersion == "" {
+		return runQuery()
+	}
+	key := queryCacheKey(query, dataVersion)
+	if rows, ok := c.lookup(key); ok {
+		queryCacheRequestsCounter.WithLabelValues(queryCacheResultHit).Inc()
+		return rows, nil
+	}
+	queryCacheRequestsCounter.WithLabelValues(queryCacheResultMiss).Inc()
+	v, err, _ := c.group.Do(key, func() (interface{}, error) {
+		rows, err := runQuery()
+		if err != nil {
+			return nil, err
+		}
+		c.add(key, rows)
+		return rows, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]presto.Row), nil
+}
+
+func (c *queryCache) lookup(key string) ([]presto.Row, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*queryCacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.remove(elem)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return entry.rows, true
+}
+
+func (c *queryCache) add(key string, rows []presto.Row) {
+	if len(rows) > c.maxRows {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	c.entries[key] = c.lru.PushFront(&queryCacheEntry{key: key, rows: rows, expires: c.now().Add(c.ttl)})
+	c.rows += len(rows)
+	for c.rows > c.maxRows {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove removes elem from the cache. It must be called with c.mu held.
+func (c *queryCache) remove(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*queryCacheEntry)
+	delete(c.entries, entry.key)
+	c.rows -= len(entry.rows)
+}
+
+// reportResultsDataVersion returns the version of the results of the Report
+// or ScheduledReport of kind named name: its resourceVersion, which changes
+// whenever it's run again. It returns "" if caching is disabled or the
+// report can't be found, so that its results aren't cached.
+func (srv *server) reportResultsDataVersion(r *http.Request, kind, name string) string {
+	if srv.queryCache == nil {
+		return ""
+	}
+	listers, err := srv.listersFor(r)
+	if err != nil {
+		return ""
+	}
+	var objectMeta meta.Object
+	switch kind {
+	case dependencyKindReport:
+		objectMeta, err = listers.reports.Get(name)
+	case dependencyKindScheduledReport:
+		objectMeta, err = listers.scheduledReports.Get(name)
+	default:
+		return ""
+	}
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", kind, objectMeta.GetNamespace(), name, objectMeta.GetResourceVersion())
+}
+
+// adhocQueryDataVersion returns the version of the data read by an ad-hoc
+// query of the ReportGenerationQuery named name: its resourceVersion, so
+// that changing the query invalidates its cached results. Newly imported
+// data is returned once the cached results expire.
+func (srv *server) adhocQueryDataVersion(r *http.Request, name string) string {
+	if srv.queryCache == nil {
+		return ""
+	}
+	listers, err := srv.listersFor(r)
+	if err != nil {
+		return ""
+	}
+	generationQuery, err := listers.reportGenerationQueries.Get(name)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("ReportGenerationQuery/%s/%s/%s", generationQuery.Namespace, name, generationQuery.ResourceVersion)
+}
@@ -0,0 +1,106 @@
+package operator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+// countingQuery returns a query function returning rows, and a pointer to
+// the number of times it's been run.
+func countingQuery(rows ...presto.Row) (func() ([]presto.Row, error), *int) {
+	var runs int
+	return func() ([]presto.Row, error) {
+		runs++
+		return rows, nil
+	}, &runs
+}
+
+func TestQueryCache(t *testing.T) {
+	now := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	cache := newQueryCache(QueryCacheConfig{TTL: time.Minute, MaxRows: 3}, func() time.Time { return now })
+	row := presto.Row{"namespace": "team-a"}
+	query, runs := countingQuery(row)
+
+	for i := 0; i < 3; i++ {
+		rows, err := cache.get("SELECT 1", "Report/metering/a/1", query)
+		require.NoError(t, err)
+		assert.Equal(t, []presto.Row{row}, rows)
+	}
+	assert.Equal(t, 1, *runs, "repeated queries should be served from the cache")
+
+	_, err := cache.get("SELECT 1", "Report/metering/a/2", query)
+	require.NoError(t, err)
+	assert.Equal(t, 2, *runs, "a new data version should run the query again")
+
+	_, err = cache.get("SELECT 1", "", query)
+	require.NoError(t, err)
+	_, err = cache.get("SELECT 1", "", query)
+	require.NoError(t, err)
+	assert.Equal(t, 4, *runs, "results without a data version shouldn't be cached")
+
+	now = now.Add(time.Minute)
+	_, err = cache.get("SELECT 1", "Report/metering/a/2", query)
+	require.NoError(t, err)
+	assert.Equal(t, 5, *runs, "expired results should be queried again")
+}
+
+func TestQueryCacheEviction(t *testing.T) {
+	cache := newQueryCache(QueryCacheConfig{TTL: time.Minute, MaxRows: 3}, time.Now)
+	twoRows, twoRowsRuns := countingQuery(presto.Row{"a": 1}, presto.Row{"a": 2})
+	oneRow, oneRowRuns := countingQuery(presto.Row{"a": 1})
+	fourRows, fourRowsRuns := countingQuery(presto.Row{"a": 1}, presto.Row{"a": 2}, presto.Row{"a": 3}, presto.Row{"a": 4})
+
+	cache.get("two rows", "v1", twoRows)
+	cache.get("one row", "v1", oneRow)
+	assert.Equal(t, 3, cache.rows)
+
+	// using the two row results makes the one row results the least
+	// recently used.
+	cache.get("two rows", "v1", twoRows)
+	cache.get("another two rows", "v1", twoRows)
+	assert.Equal(t, 2, cache.rows, "the least recently used results should be evicted")
+	cache.get("one row", "v1", oneRow)
+	assert.Equal(t, 2, *oneRowRuns)
+	assert.Equal(t, 2, *twoRowsRuns)
+
+	cache.get("four rows", "v1", fourRows)
+	cache.get("four rows", "v1", fourRows)
+	assert.Equal(t, 2, *fourRowsRuns, "results with more than maxRows rows shouldn't be cached")
+	assert.True(t, cache.rows <= 3)
+}
+
+func TestQueryCacheErrors(t *testing.T) {
+	cache := newQueryCache(QueryCacheConfig{TTL: time.Minute, MaxRows: 10}, time.Now)
+	var runs int
+	failing := func() ([]presto.Row, error) {
+		runs++
+		return nil, errors.New("query failed")
+	}
+	_, err := cache.get("SELECT 1", "v1", failing)
+	assert.EqualError(t, err, "query failed")
+	_, err = cache.get("SELECT 1", "v1", failing)
+	assert.EqualError(t, err, "query failed")
+	assert.Equal(t, 2, runs, "failed queries shouldn't be cached")
+}
+
+func TestQueryCacheDisabled(t *testing.T) {
+	cache := newQueryCache(QueryCacheConfig{}, time.Now)
+	assert.Nil(t, cache)
+	query, runs := countingQuery()
+	cache.get("SELECT 1", "v1", query)
+	cache.get("SELECT 1", "v1", query)
+	assert.Equal(t, 2, *runs)
+}
+
+func TestQueryCacheConfigValid(t *testing.T) {
+	assert.NoError(t, QueryCacheConfig{}.Valid())
+	assert.NoError(t, QueryCacheConfig{TTL: time.Minute, MaxRows: 1}.Valid())
+	assert.Error(t, QueryCacheConfig{TTL: -time.Minute}.Valid())
+	assert.Error(t, QueryCacheConfig{TTL: time.Minute}.Valid())
+}
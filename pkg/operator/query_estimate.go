@@ -0,0 +1,275 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	APIV1QueryEstimateEndpoint = APIV1QueryEndpoint + "/estimate"
+
+	// estimatedScanBytesPerSecond is the rate Presto is assumed to read
+	// data at when estimating how long a query takes. It's a rough figure
+	// for a small Presto cluster reading ORC tables, so estimated durations
+	// are only useful to compare reporting periods, not as a prediction.
+	estimatedScanBytesPerSecond = 32 * 1024 * 1024
+)
+
+var (
+	// tableScanRE matches the TableScan nodes of a distributed query plan,
+	// capturing the table handle, such as hive:default:datasource_pods.
+	tableScanRE = regexp.MustCompile(`TableScan\[([^,\]\s]+)`)
+	// planEstimateRE matches the estimated rows and size of the output of
+	// a node in a query plan, which older Presto versions call its cost.
+	planEstimateRE = regexp.MustCompile(`(?:Estimates|Cost): \{rows: (\S+) \(([^)]+)\)`)
+	dataSizeRE     = regexp.MustCompile(`^([0-9.]+)([kMGTP]?B)$`)
+
+	dataSizeUnits = map[string]float64{
+		"B":  1,
+		"kB": 1 << 10,
+		"MB": 1 << 20,
+		"GB": 1 << 30,
+		"TB": 1 << 40,
+		"PB": 1 << 50,
+	}
+)
+
+// QueryEstimate is the estimated cost of running a ReportGenerationQuery
+// for a reporting period, based on Presto's query plan and the statistics
+// of the tables it reads.
+type QueryEstimate struct {
+	Tables []QueryEstimateTable `json:"tables"`
+	// EstimatedRowsScanned and EstimatedBytesScanned are the total rows and
+	// bytes read from the tables.
+	EstimatedRowsScanned  int64 `json:"estimatedRowsScanned"`
+	EstimatedBytesScanned int64 `json:"estimatedBytesScanned"`
+	// EstimatedDuration is roughly how long the query would take to read
+	// the tables.
+	EstimatedDuration string `json:"estimatedDuration"`
+	// Warnings explain why the estimate may be inaccurate, and whether the
+	// query is likely to exceed the caller's query timeout.
+	Warnings []string `json:"warnings,omitempty"`
+	// Plan is the distributed query plan the estimate is based on.
+	Plan string `json:"plan"`
+}
+
+// QueryEstimateTable is a table read by a query, and how much of it the
+// query is estimated to read.
+type QueryEstimateTable struct {
+	Name string `json:"name"`
+	// RowsScanned and BytesScanned are how much of the table the query
+	// plan expects to read, or the size of the whole table if the plan has
+	// no estimate.
+	RowsScanned  int64 `json:"rowsScanned"`
+	BytesScanned int64 `json:"bytesScanned"`
+	// RowCount and DataSizeBytes are the size of the whole table, from its
+	// statistics, if it has been analyzed.
+	RowCount      *int64 `json:"rowCount,omitempty"`
+	DataSizeBytes *int64 `json:"dataSizeBytes,omitempty"`
+}
+
+// tableScanEstimate is a TableScan node of a query plan, and its estimated
+// output. rows and bytes are negative if the plan has no estimate.
+type tableScanEstimate struct {
+	table string
+	rows  float64
+	bytes float64
+}
+
+// parseTableScanEstimates returns the tables scanned by the distributed
+// query plan, in the order they're first scanned, with the estimated output
+// of their scans summed, as a table can be scanned more than once.
+func parseTableScanEstimates(plan string) []tableScanEstimate {
+	var scans []tableScanEstimate
+	current := -1
+	for _, line := range strings.Split(plan, "\n") {
+		if match := tableScanRE.FindStringSubmatch(line); match != nil {
+			scans = append(scans, tableScanEstimate{table: tableHandleName(match[1]), rows: -1, bytes: -1})
+			current = len(scans) - 1
+			continue
+		}
+		if current == -1 {
+			continue
+		}
+		if match := planEstimateRE.FindStringSubmatch(line); match != nil {
+			if rows, err := strconv.ParseFloat(match[1], 64); err == nil {
+				scans[current].rows = rows
+			}
+			if bytes, ok := parseDataSize(match[2]); ok {
+				scans[current].bytes = bytes
+			}
+			current = -1
+		} else if strings.Contains(line, "- ") {
+			// the next node started before the scan's estimate.
+			current = -1
+		}
+	}
+
+	var tables []tableScanEstimate
+	index := make(map[string]int)
+	for _, scan := range scans {
+		i, seen := index[scan.table]
+		if !seen {
+			index[scan.table] = len(tables)
+			tables = append(tables, scan)
+			continue
+		}
+		tables[i].rows = addEstimates(tables[i].rows, scan.rows)
+		tables[i].bytes = addEstimates(tables[i].bytes, scan.bytes)
+	}
+	return tables
+}
+
+// addEstimates adds two estimates, either of which is negative if it's
+// unknown.
+func addEstimates(a, b float64) float64 {
+	if a < 0 || b < 0 {
+		return -1
+	}
+	return a + b
+}
+
+// tableHandleName returns the qualified name of the table of a TableScan's
+// table handle. Older Presto versions prefix the handle with the connector
+// ID, as in hive:hive:default:datasource_pods.
+func tableHandleName(handle string) string {
+	parts := strings.Split(handle, ":")
+	if len(parts) > 3 {
+		parts = parts[len(parts)-3:]
+	}
+	return strings.Join(parts, ".")
+}
+
+// parseDataSize parses the sizes in Presto's query plans, such as 15.62kB.
+// It returns false if the size is unknown, which Presto shows as ?.
+func parseDataSize(size string) (float64, bool) {
+	match := dataSizeRE.FindStringSubmatch(size)
+	if match == nil {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	return value * dataSizeUnits[match[2]], true
+}
+
+// tableStats returns the row count and data size of a table from its
+// statistics, which are unknown (false) if the table hasn't been analyzed.
+func tableStats(queryer presto.Queryer, table string) (rowCount, dataSize float64, known bool, err error) {
+	rows, err := queryer.Query(fmt.Sprintf("SHOW STATS FOR %s", table))
+	if err != nil {
+		return 0, 0, false, err
+	}
+	rowCountKnown := false
+	for _, row := range rows {
+		if row["column_name"] == nil {
+			// the summary row has the table's row count.
+			rowCount, rowCountKnown = toFloat64(row["row_count"])
+			continue
+		}
+		if size, ok := toFloat64(row["data_size"]); ok {
+			dataSize += size
+		}
+	}
+	return rowCount, dataSize, rowCountKnown, nil
+}
+
+// estimateQuery estimates the cost of running query by explaining it with
+// EXPLAIN (TYPE DISTRIBUTED), which includes the estimated output of each
+// table scan when the tables have statistics, falling back to the size of
+// the whole table when the plan has no estimate for a scan.
+func estimateQuery(logger log.FieldLogger, queryer presto.Queryer, query string) (QueryEstimate, error) {
+	rows, err := queryer.Query(fmt.Sprintf("EXPLAIN (TYPE DISTRIBUTED) %s", query))
+	if err != nil {
+		return QueryEstimate{}, err
+	}
+	var planLines []string
+	for _, row := range rows {
+		for _, value := range row {
+			if s, ok := value.(string); ok {
+				planLines = append(planLines, s)
+			}
+		}
+	}
+
+	estimate := QueryEstimate{
+		Tables: []QueryEstimateTable{},
+		Plan:   strings.Join(planLines, "\n"),
+	}
+	for _, scan := range parseTableScanEstimates(estimate.Plan) {
+		table := QueryEstimateTable{Name: scan.table}
+		rowCount, dataSize, known, err := tableStats(queryer, scan.table)
+		if err != nil {
+			logger.WithError(err).Warnf("unable to get the statistics of table %s", scan.table)
+		} else if known {
+			rowCountInt, dataSizeInt := int64(rowCount), int64(dataSize)
+			table.RowCount = &rowCountInt
+			table.DataSizeBytes = &dataSizeInt
+		}
+
+		switch {
+		case scan.rows >= 0 && scan.bytes >= 0:
+			table.RowsScanned = int64(scan.rows)
+			table.BytesScanned = int64(scan.bytes)
+		case table.RowCount != nil:
+			table.RowsScanned = *table.RowCount
+			table.BytesScanned = *table.DataSizeBytes
+			estimate.Warnings = append(estimate.Warnings, fmt.Sprintf("the query plan has no estimate for table %s, assuming the whole table is read", scan.table))
+		default:
+			estimate.Warnings = append(estimate.Warnings, fmt.Sprintf("table %s has no statistics, run ANALYZE on it to include it in the estimate", scan.table))
+		}
+		estimate.EstimatedRowsScanned += table.RowsScanned
+		estimate.EstimatedBytesScanned += table.BytesScanned
+		estimate.Tables = append(estimate.Tables, table)
+	}
+	estimate.EstimatedDuration = estimatedScanDuration(estimate.EstimatedBytesScanned).String()
+	return estimate, nil
+}
+
+// estimatedScanDuration returns roughly how long Presto takes to read bytes,
+// rounded to the second.
+func estimatedScanDuration(bytes int64) time.Duration {
+	d := time.Duration(float64(bytes) / estimatedScanBytesPerSecond * float64(time.Second))
+	return d.Round(time.Second)
+}
+
+func (srv *server) queryEstimateHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	var req ReportRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to decode request as JSON: %v", err)
+		return
+	}
+	logger = logger.WithField("generationQuery", req.GenerationQuery)
+	query, _, err := srv.reportRuns.renderQuery(logger, req)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	estimate, err := estimateQuery(logger, srv.queryer, query)
+	if err != nil {
+		if isPrestoConnectionError(err) {
+			writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to explain query: %v", err)
+			return
+		}
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "query was rejected by Presto: %v", err)
+		return
+	}
+
+	role := srv.queryConfig.roleFor(r)
+	if duration := estimatedScanDuration(estimate.EstimatedBytesScanned); duration > role.Timeout.Duration {
+		estimate.Warnings = append(estimate.Warnings, fmt.Sprintf("the query is estimated to take %s, longer than the timeout of %s for ad-hoc queries in role %s, narrow the reporting period or create a Report instead", duration, role.Timeout.Duration, role.Name))
+	}
+	writeResponseAsJSON(logger, w, http.StatusOK, estimate)
+}
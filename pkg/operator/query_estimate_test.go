@@ -0,0 +1,119 @@
+package operator
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+const testDistributedPlan = `Fragment 0 [SINGLE]
+    Output layout: [namespace, sum]
+    Output partitioning: SINGLE []
+    - Output[namespace, cost] => [namespace:varchar, sum:double]
+            Estimates: {rows: ? (?), cpu: ?, memory: ?, network: ?}
+        - RemoteSource[1] => [namespace:varchar, sum:double]
+
+Fragment 2 [SOURCE]
+    Output layout: [namespace, pod_request_cpu_core_seconds]
+    Output partitioning: HASH [namespace]
+    - ScanFilterProject[table = hive:default:datasource_pod_cpu_request, grouped = false, filterPredicate = true] => [namespace:varchar]
+        - TableScan[hive:default:datasource_pod_cpu_request, grouped = false] => [namespace:varchar, pod_request_cpu_core_seconds:double]
+                Estimates: {rows: 120000 (3.50MB), cpu: 3.50M, memory: 0.00, network: 0.00}
+    - TableScan[hive:default:datasource_node_capacity_cpu, grouped = false] => [node:varchar]
+            Estimates: {rows: ? (?), cpu: ?, memory: 0.00, network: 0.00}
+    - TableScan[hive:hive:default:datasource_pod_cpu_request, originalConstraint = true] => [namespace:varchar]
+            Cost: {rows: 1000 (1.5kB), cpu: 1.5k, memory: 0.00, network: 0.00}`
+
+func TestParseTableScanEstimates(t *testing.T) {
+	expected := []tableScanEstimate{
+		{table: "hive.default.datasource_pod_cpu_request", rows: 121000, bytes: 3.5*1024*1024 + 1.5*1024},
+		{table: "hive.default.datasource_node_capacity_cpu", rows: -1, bytes: -1},
+	}
+	assert.Equal(t, expected, parseTableScanEstimates(testDistributedPlan))
+}
+
+func TestParseDataSize(t *testing.T) {
+	tests := map[string]struct {
+		size     float64
+		expected bool
+	}{
+		"10B":    {size: 10, expected: true},
+		"15.5kB": {size: 15.5 * 1024, expected: true},
+		"2GB":    {size: 2 * 1024 * 1024 * 1024, expected: true},
+		"?":      {expected: false},
+		"10":     {expected: false},
+	}
+	for input, test := range tests {
+		size, ok := parseDataSize(input)
+		assert.Equal(t, test.expected, ok, input)
+		assert.Equal(t, test.size, size, input)
+	}
+}
+
+func TestQueryEstimateHandler(t *testing.T) {
+	const query = "SELECT namespace, cost FROM datasource_costs"
+	queryFunc := func(logger log.FieldLogger, report *v1alpha1.Report) (string, []v1alpha1.ReportGenerationQueryColumn, error) {
+		return query, []v1alpha1.ReportGenerationQueryColumn{{Name: "namespace", Type: "string"}}, nil
+	}
+	queryConfig := QueryConfig{
+		Roles: []QueryRole{{Name: DefaultQueryRoleName, MaxRows: 10, Timeout: meta.Duration{Duration: time.Minute}}},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	queryer := mockpresto.NewMockExecQueryer(ctrl)
+	queryer.EXPECT().Query("EXPLAIN (TYPE DISTRIBUTED) "+query).Return([]presto.Row{{"Query Plan": testDistributedPlan}}, nil)
+	queryer.EXPECT().Query("SHOW STATS FOR hive.default.datasource_pod_cpu_request").Return([]presto.Row{
+		{"column_name": "namespace", "data_size": 1024.0, "row_count": nil},
+		{"column_name": "pod_request_cpu_core_seconds", "data_size": nil, "row_count": nil},
+		{"column_name": nil, "data_size": nil, "row_count": 500000.0},
+	}, nil)
+	queryer.EXPECT().Query("SHOW STATS FOR hive.default.datasource_node_capacity_cpu").Return([]presto.Row{
+		{"column_name": "node", "data_size": 4.0 * 1024 * 1024 * 1024, "row_count": nil},
+		{"column_name": nil, "data_size": nil, "row_count": 10000000.0},
+	}, nil)
+
+	router := newRouter(testLogger, routerConfig{
+		rand:               testRand,
+		queryer:            queryer,
+		importerQueryer:    queryer,
+		collectorFunc:      noopPrometheusImporterFunc,
+		reportRunQueryFunc: queryFunc,
+		queryConfig:        queryConfig,
+	})
+	body, err := json.Marshal(ReportRunRequest{
+		GenerationQuery: "namespace-cost",
+		ReportingStart:  time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC),
+		ReportingEnd:    time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", APIV1QueryEstimateEndpoint, bytes.NewReader(body)))
+
+	resp := w.Result()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var estimate QueryEstimate
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&estimate))
+
+	require.Len(t, estimate.Tables, 2)
+	assert.Equal(t, int64(121000), estimate.Tables[0].RowsScanned, "the plan's estimates should be used")
+	assert.Equal(t, int64(10000000), estimate.Tables[1].RowsScanned, "the table's statistics should be used when the plan has no estimate")
+	assert.Equal(t, int64(4*1024*1024*1024), estimate.Tables[1].BytesScanned)
+	assert.Equal(t, int64(10121000), estimate.EstimatedRowsScanned)
+	assert.Equal(t, "2m8s", estimate.EstimatedDuration)
+	assert.Len(t, estimate.Warnings, 2, "expected warnings about the missing plan estimate and the query timeout: %v", estimate.Warnings)
+	assert.Equal(t, testDistributedPlan, estimate.Plan)
+}
@@ -0,0 +1,162 @@
+package operator
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func getReportInputs(report runtime.Object) []cbTypes.ReportGenerationQueryInputValue {
+	switch r := report.(type) {
+	case *cbTypes.Report:
+		return r.Spec.Inputs
+	case *cbTypes.ScheduledReport:
+		return r.Spec.Inputs
+	}
+	return nil
+}
+
+// validateReportInputs checks the input values provided by a report are
+// valid for the inputs of its generationQuery, so that invalid reports are
+// rejected before they run.
+func (op *Reporting) validateReportInputs(generationQuery *cbTypes.ReportGenerationQuery, values []cbTypes.ReportGenerationQueryInputValue) error {
+	dependentQueries, err := op.getDependentGenerationQueries(generationQuery, true)
+	if err != nil {
+		return err
+	}
+	definitions, err := getQueryInputDefinitions(generationQuery, dependentQueries)
+	if err != nil {
+		return err
+	}
+	_, err = resolveQueryInputs(definitions, values)
+	return err
+}
+
+// getQueryInputDefinitions returns the inputs declared by the
+// generationQuery and the dynamicQueries rendered into it, which share the
+// same input values. Inputs may be declared by more than one of the queries
+// as long as their types match.
+func getQueryInputDefinitions(generationQuery *cbTypes.ReportGenerationQuery, dynamicQueries []*cbTypes.ReportGenerationQuery) ([]cbTypes.ReportGenerationQueryInputDefinition, error) {
+	var definitions []cbTypes.ReportGenerationQueryInputDefinition
+	declaredBy := make(map[string]int)
+	for _, query := range append([]*cbTypes.ReportGenerationQuery{generationQuery}, dynamicQueries...) {
+		for _, def := range query.Spec.Inputs {
+			if def.Type == "" {
+				def.Type = cbTypes.ReportGenerationQueryInputTypeString
+			}
+			if i, exists := declaredBy[def.Name]; exists {
+				if definitions[i].Type != def.Type {
+					return nil, fmt.Errorf("input %s is declared as %s by ReportGenerationQuery %s, and as %s by another query", def.Name, def.Type, query.Name, definitions[i].Type)
+				}
+				// a query requiring the input makes it required.
+				definitions[i].Required = definitions[i].Required || def.Required
+				continue
+			}
+			declaredBy[def.Name] = len(definitions)
+			definitions = append(definitions, def)
+		}
+	}
+	return definitions, nil
+}
+
+// resolveQueryInputs validates the input values provided by a report
+// against the input definitions of its queries, applying defaults, and
+// returns the values for the query template keyed by input name.
+func resolveQueryInputs(definitions []cbTypes.ReportGenerationQueryInputDefinition, values []cbTypes.ReportGenerationQueryInputValue) (map[string]interface{}, error) {
+	provided := make(map[string]string, len(values))
+	for _, input := range values {
+		if _, exists := provided[input.Name]; exists {
+			return nil, fmt.Errorf("input %s was provided more than once", input.Name)
+		}
+		provided[input.Name] = input.Value
+	}
+
+	inputs := make(map[string]interface{}, len(definitions))
+	for _, def := range definitions {
+		value, ok := provided[def.Name]
+		delete(provided, def.Name)
+		if !ok {
+			if def.Default == nil {
+				if def.Required {
+					return nil, fmt.Errorf("input %s is required", def.Name)
+				}
+				continue
+			}
+			value = *def.Default
+		}
+		parsed, err := parseQueryInput(def, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for input %s: %v", value, def.Name, err)
+		}
+		inputs[def.Name] = parsed
+	}
+
+	if len(provided) != 0 {
+		var unknown []string
+		for _, value := range values {
+			if _, ok := provided[value.Name]; ok {
+				unknown = append(unknown, value.Name)
+			}
+		}
+		return nil, fmt.Errorf("unknown inputs: %s", strings.Join(unknown, ", "))
+	}
+	return inputs, nil
+}
+
+func parseQueryInput(def cbTypes.ReportGenerationQueryInputDefinition, value string) (interface{}, error) {
+	if len(def.AllowedValues) != 0 {
+		allowed := false
+		for _, allowedValue := range def.AllowedValues {
+			if value == allowedValue {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return nil, fmt.Errorf("must be one of: %s", strings.Join(def.AllowedValues, ", "))
+		}
+	}
+
+	switch def.Type {
+	case cbTypes.ReportGenerationQueryInputTypeString, "":
+		// queries written before prestoString existed put string inputs
+		// between quotes themselves, so a quote could end the literal.
+		if strings.Contains(value, "'") {
+			return nil, fmt.Errorf("must not contain a single quote")
+		}
+		return value, nil
+	case cbTypes.ReportGenerationQueryInputTypeInteger:
+		return strconv.ParseInt(value, 10, 64)
+	case cbTypes.ReportGenerationQueryInputTypeNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, err
+		}
+		if math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, fmt.Errorf("must be a finite number")
+		}
+		return number, nil
+	case cbTypes.ReportGenerationQueryInputTypeTime:
+		return time.Parse(time.RFC3339, value)
+	case cbTypes.ReportGenerationQueryInputTypeNamespace:
+		if errs := validation.IsDNS1123Label(value); len(errs) != 0 {
+			return nil, fmt.Errorf("not a valid namespace: %s", strings.Join(errs, ", "))
+		}
+		return value, nil
+	case cbTypes.ReportGenerationQueryInputTypeLabelSelector:
+		if _, err := labels.Parse(value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	default:
+		return nil, fmt.Errorf("unknown input type %s, must be one of: %s, %s, %s, %s, %s or %s", def.Type, cbTypes.ReportGenerationQueryInputTypeString, cbTypes.ReportGenerationQueryInputTypeInteger, cbTypes.ReportGenerationQueryInputTypeNumber, cbTypes.ReportGenerationQueryInputTypeTime, cbTypes.ReportGenerationQueryInputTypeNamespace, cbTypes.ReportGenerationQueryInputTypeLabelSelector)
+	}
+}
@@ -0,0 +1,154 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestResolveQueryInputs(t *testing.T) {
+	defaultLevel := "namespace"
+	definitions := []v1alpha1.ReportGenerationQueryInputDefinition{
+		{Name: "namespace", Type: v1alpha1.ReportGenerationQueryInputTypeNamespace, Required: true},
+		{Name: "selector", Type: v1alpha1.ReportGenerationQueryInputTypeLabelSelector},
+		{Name: "since", Type: v1alpha1.ReportGenerationQueryInputTypeTime},
+		{Name: "limit", Type: v1alpha1.ReportGenerationQueryInputTypeInteger},
+		{Name: "hourlyCost", Type: v1alpha1.ReportGenerationQueryInputTypeNumber},
+		{Name: "aggregationLevel", Default: &defaultLevel, AllowedValues: []string{"pod", "namespace"}},
+		{Name: "team", Type: v1alpha1.ReportGenerationQueryInputTypeString},
+	}
+
+	tests := map[string]struct {
+		values         []v1alpha1.ReportGenerationQueryInputValue
+		expectedInputs map[string]interface{}
+		expectErr      bool
+	}{
+		"all inputs": {
+			values: []v1alpha1.ReportGenerationQueryInputValue{
+				{Name: "namespace", Value: "kube-system"},
+				{Name: "selector", Value: "app=web,tier!=cache"},
+				{Name: "since", Value: "2018-07-01T00:00:00Z"},
+				{Name: "limit", Value: "10"},
+				{Name: "hourlyCost", Value: "2.48"},
+				{Name: "aggregationLevel", Value: "pod"},
+			},
+			expectedInputs: map[string]interface{}{
+				"namespace":        "kube-system",
+				"selector":         "app=web,tier!=cache",
+				"since":            time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC),
+				"limit":            int64(10),
+				"hourlyCost":       2.48,
+				"aggregationLevel": "pod",
+			},
+		},
+		"defaults": {
+			values: []v1alpha1.ReportGenerationQueryInputValue{
+				{Name: "namespace", Value: "kube-system"},
+			},
+			expectedInputs: map[string]interface{}{
+				"namespace":        "kube-system",
+				"aggregationLevel": "namespace",
+			},
+		},
+		"missing required input": {
+			values:    nil,
+			expectErr: true,
+		},
+		"unknown input": {
+			values: []v1alpha1.ReportGenerationQueryInputValue{
+				{Name: "namespace", Value: "kube-system"},
+				{Name: "node", Value: "node-1"},
+			},
+			expectErr: true,
+		},
+		"invalid namespace": {
+			values: []v1alpha1.ReportGenerationQueryInputValue{
+				{Name: "namespace", Value: "Kube_System"},
+			},
+			expectErr: true,
+		},
+		"invalid label selector": {
+			values: []v1alpha1.ReportGenerationQueryInputValue{
+				{Name: "namespace", Value: "kube-system"},
+				{Name: "selector", Value: "app in (web"},
+			},
+			expectErr: true,
+		},
+		"string with a quote": {
+			values: []v1alpha1.ReportGenerationQueryInputValue{
+				{Name: "namespace", Value: "kube-system"},
+				{Name: "team", Value: "x' OR '1'='1"},
+			},
+			expectErr: true,
+		},
+		"invalid number": {
+			values: []v1alpha1.ReportGenerationQueryInputValue{
+				{Name: "namespace", Value: "kube-system"},
+				{Name: "hourlyCost", Value: "NaN"},
+			},
+			expectErr: true,
+		},
+		"value not allowed": {
+			values: []v1alpha1.ReportGenerationQueryInputValue{
+				{Name: "namespace", Value: "kube-system"},
+				{Name: "aggregationLevel", Value: "cluster"},
+			},
+			expectErr: true,
+		},
+		"duplicate input": {
+			values: []v1alpha1.ReportGenerationQueryInputValue{
+				{Name: "namespace", Value: "kube-system"},
+				{Name: "namespace", Value: "default"},
+			},
+			expectErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			inputs, err := resolveQueryInputs(definitions, tt.values)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedInputs, inputs)
+		})
+	}
+}
+
+func TestGetQueryInputDefinitions(t *testing.T) {
+	query := &v1alpha1.ReportGenerationQuery{
+		ObjectMeta: meta.ObjectMeta{Name: "namespace-cpu"},
+		Spec: v1alpha1.ReportGenerationQuerySpec{
+			Inputs: []v1alpha1.ReportGenerationQueryInputDefinition{{Name: "namespace"}},
+		},
+	}
+	dynamicQuery := &v1alpha1.ReportGenerationQuery{
+		ObjectMeta: meta.ObjectMeta{Name: "pod-cpu"},
+		Spec: v1alpha1.ReportGenerationQuerySpec{
+			Inputs: []v1alpha1.ReportGenerationQueryInputDefinition{
+				{Name: "namespace", Type: v1alpha1.ReportGenerationQueryInputTypeString, Required: true},
+				{Name: "limit", Type: v1alpha1.ReportGenerationQueryInputTypeInteger},
+			},
+		},
+	}
+
+	definitions, err := getQueryInputDefinitions(query, []*v1alpha1.ReportGenerationQuery{dynamicQuery})
+	require.NoError(t, err)
+	assert.Equal(t, []v1alpha1.ReportGenerationQueryInputDefinition{
+		{Name: "namespace", Type: v1alpha1.ReportGenerationQueryInputTypeString, Required: true},
+		{Name: "limit", Type: v1alpha1.ReportGenerationQueryInputTypeInteger},
+	}, definitions)
+
+	dynamicQuery.Spec.Inputs[0].Type = v1alpha1.ReportGenerationQueryInputTypeNamespace
+	_, err = getQueryInputDefinitions(query, []*v1alpha1.ReportGenerationQuery{dynamicQuery})
+	assert.Error(t, err, "conflicting input types should be rejected")
+}
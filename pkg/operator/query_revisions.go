@@ -0,0 +1,159 @@
+package operator
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+// maxGenerationQueryRevisions limits how many revisions of a
+// ReportGenerationQuery are kept. The views of the oldest revisions are
+// dropped as they're removed.
+const maxGenerationQueryRevisions = 25
+
+func generationQueryRevisionViewName(queryName string, validFrom time.Time) string {
+	return fmt.Sprintf("%s_%d", generationQueryViewName(queryName), validFrom.Unix())
+}
+
+// addGenerationQueryRevision appends the generationQuery's query and columns
+// as a new revision if they differ from its current revision, marking the
+// current revision as valid until now. The first revision is valid from
+// when the generationQuery was created. Returns true if a revision was
+// added.
+func addGenerationQueryRevision(generationQuery *cbTypes.ReportGenerationQuery, now time.Time) bool {
+	validFrom := generationQuery.CreationTimestamp
+	if n := len(generationQuery.Revisions); n != 0 {
+		current := &generationQuery.Revisions[n-1]
+		if current.Query == generationQuery.Spec.Query && reflect.DeepEqual(current.Columns, generationQuery.Spec.Columns) {
+			return false
+		}
+		validFrom = metav1.Time{Time: now}
+		current.ValidUntil = &validFrom
+	}
+	generationQuery.Revisions = append(generationQuery.Revisions, cbTypes.ReportGenerationQueryRevision{
+		ValidFrom: validFrom,
+		Query:     generationQuery.Spec.Query,
+		Columns:   append([]cbTypes.ReportGenerationQueryColumn(nil), generationQuery.Spec.Columns...),
+	})
+	return true
+}
+
+// getGenerationQueryRevision returns the past revision of the
+// generationQuery which was in effect at t, or nil if the current revision
+// was in effect. Times before the first revision use the first revision.
+func getGenerationQueryRevision(generationQuery *cbTypes.ReportGenerationQuery, t time.Time) *cbTypes.ReportGenerationQueryRevision {
+	for i := range generationQuery.Revisions {
+		revision := &generationQuery.Revisions[i]
+		if revision.ValidUntil == nil {
+			return nil
+		}
+		if t.Before(revision.ValidUntil.Time) {
+			return revision
+		}
+	}
+	return nil
+}
+
+// withGenerationQueryRevision returns a copy of the generationQuery with the
+// query and columns of the revision.
+func withGenerationQueryRevision(generationQuery *cbTypes.ReportGenerationQuery, revision *cbTypes.ReportGenerationQueryRevision) *cbTypes.ReportGenerationQuery {
+	generationQuery = generationQuery.DeepCopy()
+	generationQuery.Spec.Query = revision.Query
+	generationQuery.Spec.Columns = append([]cbTypes.ReportGenerationQueryColumn(nil), revision.Columns...)
+	return generationQuery
+}
+
+// recordGenerationQueryRevision adds a revision to the generationQuery if
+// its query or columns changed. If the replaced revision had a view, a view
+// of the replaced revision's query is kept, so that past revisions of
+// queries reading the view continue to work. Returns true if the revisions
+// changed and need to be saved.
+func (op *Reporting) recordGenerationQueryRevision(logger log.FieldLogger, generationQuery *cbTypes.ReportGenerationQuery) bool {
+	if !addGenerationQueryRevision(generationQuery, op.clock.Now().UTC()) {
+		return false
+	}
+
+	revisions := generationQuery.Revisions
+	if len(revisions) > 1 && generationQuery.ViewName != "" {
+		replaced := &revisions[len(revisions)-2]
+		logger.Infof("reportGenerationQuery changed, keeping view of the revision valid from %s until %s", replaced.ValidFrom.Time, replaced.ValidUntil.Time)
+		viewName := tenantTableName(op.tenantNamespace(generationQuery.Namespace), generationQueryRevisionViewName(generationQuery.Name, replaced.ValidFrom.Time))
+		err := op.createGenerationQueryRevisionView(generationQuery, replaced, viewName)
+		if err != nil {
+			logger.WithError(err).Warnf("unable to create view %s for the replaced revision, past revisions of queries reading this query's view will use its current view", viewName)
+		} else {
+			replaced.ViewName = viewName
+		}
+	}
+
+	for len(revisions) > maxGenerationQueryRevisions {
+		if revisions[0].ViewName != "" {
+			err := presto.DropView(op.prestoQueryer, revisions[0].ViewName, true)
+			if err != nil {
+				logger.WithError(err).Warnf("unable to drop view %s of removed revision", revisions[0].ViewName)
+			}
+		}
+		revisions = revisions[1:]
+	}
+	generationQuery.Revisions = revisions
+	return true
+}
+
+func (op *Reporting) createGenerationQueryRevisionView(generationQuery *cbTypes.ReportGenerationQuery, revision *cbTypes.ReportGenerationQueryRevision, viewName string) error {
+	dependentQueries, err := op.getDependentGenerationQueries(generationQuery, true)
+	if err != nil {
+		return err
+	}
+	qr := queryRenderer{templateInfo: &templateInfo{DynamicDependentQueries: dependentQueries, tenantNamespace: op.tenantNamespace(generationQuery.Namespace)}}
+	renderedQuery, err := qr.Render(revision.Query)
+	if err != nil {
+		return err
+	}
+	return presto.CreateOrReplaceView(op.prestoQueryer, viewName, renderedQuery)
+}
+
+// getGenerationQueriesForPeriod returns the generationQuery and its dynamic
+// dependentQueries using the revisions which were in effect at the end of
+// the reporting period, and the names of the views of past revisions of the
+// ReportGenerationQueries whose views they read.
+// ScheduledReports store results for every period in the same table, so
+// past revisions with different columns to the current revision are not
+// used for ScheduledReports.
+func (op *Reporting) getGenerationQueriesForPeriod(logger log.FieldLogger, reportKind string, generationQuery *cbTypes.ReportGenerationQuery, dependentQueries []*cbTypes.ReportGenerationQuery, reportEnd time.Time) (*cbTypes.ReportGenerationQuery, []*cbTypes.ReportGenerationQuery, map[string]string, error) {
+	if revision := getGenerationQueryRevision(generationQuery, reportEnd); revision != nil {
+		if reportKind == "scheduledreport" && !reflect.DeepEqual(revision.Columns, generationQuery.Spec.Columns) {
+			logger.Warnf("the revision of the generationQuery valid until %s has different columns to the current revision, using the current revision", revision.ValidUntil.Time)
+		} else {
+			logger.Infof("using the revision of the generationQuery valid from %s until %s", revision.ValidFrom.Time, revision.ValidUntil.Time)
+			generationQuery = withGenerationQueryRevision(generationQuery, revision)
+		}
+	}
+
+	queries := make([]*cbTypes.ReportGenerationQuery, len(dependentQueries))
+	for i, query := range dependentQueries {
+		queries[i] = query
+		if revision := getGenerationQueryRevision(query, reportEnd); revision != nil {
+			queries[i] = withGenerationQueryRevision(query, revision)
+		}
+	}
+
+	viewNames := make(map[string]string)
+	for _, query := range append([]*cbTypes.ReportGenerationQuery{generationQuery}, queries...) {
+		viewQueries, err := op.getDependentGenerationQueries(query, false)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for _, viewQuery := range viewQueries {
+			if revision := getGenerationQueryRevision(viewQuery, reportEnd); revision != nil && revision.ViewName != "" {
+				viewNames[viewQuery.Name] = revision.ViewName
+			}
+		}
+	}
+	return generationQuery, queries, viewNames, nil
+}
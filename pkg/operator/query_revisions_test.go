@@ -0,0 +1,79 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestGenerationQueryRevisions(t *testing.T) {
+	created := time.Date(2018, time.June, 1, 0, 0, 0, 0, time.UTC)
+	changed := time.Date(2018, time.August, 1, 12, 0, 0, 0, time.UTC)
+	oldColumns := []v1alpha1.ReportGenerationQueryColumn{{Name: "namespace", Type: "string"}}
+
+	generationQuery := &v1alpha1.ReportGenerationQuery{
+		ObjectMeta: meta.ObjectMeta{
+			Name:              "namespace-cpu",
+			CreationTimestamp: meta.Time{Time: created},
+		},
+		Spec: v1alpha1.ReportGenerationQuerySpec{
+			Query:   "SELECT labels['namespace'] AS namespace",
+			Columns: oldColumns,
+		},
+	}
+
+	require.True(t, addGenerationQueryRevision(generationQuery, created.Add(time.Hour)))
+	require.False(t, addGenerationQueryRevision(generationQuery, created.Add(2*time.Hour)), "unchanged query should not add a revision")
+
+	generationQuery.Spec.Query = "SELECT labels['project'] AS namespace"
+	require.True(t, addGenerationQueryRevision(generationQuery, changed))
+	require.Len(t, generationQuery.Revisions, 2)
+	assert.Equal(t, created, generationQuery.Revisions[0].ValidFrom.Time)
+	assert.Equal(t, changed, generationQuery.Revisions[0].ValidUntil.Time)
+	assert.Equal(t, changed, generationQuery.Revisions[1].ValidFrom.Time)
+	assert.Nil(t, generationQuery.Revisions[1].ValidUntil)
+
+	tests := map[string]struct {
+		reportEnd     time.Time
+		expectedQuery string
+	}{
+		"period before the queries creation uses the first revision": {
+			reportEnd:     created.Add(-24 * time.Hour),
+			expectedQuery: "SELECT labels['namespace'] AS namespace",
+		},
+		"period ending before the change uses the old revision": {
+			reportEnd:     time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC),
+			expectedQuery: "SELECT labels['namespace'] AS namespace",
+		},
+		"period ending after the change uses the current revision": {
+			reportEnd:     time.Date(2018, time.September, 1, 0, 0, 0, 0, time.UTC),
+			expectedQuery: "SELECT labels['project'] AS namespace",
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			query := generationQuery
+			if revision := getGenerationQueryRevision(generationQuery, tt.reportEnd); revision != nil {
+				query = withGenerationQueryRevision(generationQuery, revision)
+			}
+			assert.Equal(t, tt.expectedQuery, query.Spec.Query)
+		})
+	}
+}
+
+func TestRenderRevisionViewNames(t *testing.T) {
+	qr := queryRenderer{templateInfo: &templateInfo{
+		viewNames: map[string]string{"pod-cpu": "view_pod_cpu_1527811200"},
+	}}
+	rendered, err := qr.Render(`SELECT * FROM {| generationQueryViewName "pod-cpu" |} JOIN {| generationQueryViewName "pod-memory" |}`)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM view_pod_cpu_1527811200 JOIN "+generationQueryViewName("pod-memory"), rendered)
+}
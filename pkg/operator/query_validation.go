@@ -0,0 +1,204 @@
+package operator
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"time"
+
+	prestoclient "github.com/prestodb/presto-go-client/presto"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	// placeholderGroupByLabel is the label key queries supporting
+	// groupByLabels are rendered with when they're validated.
+	placeholderGroupByLabel = "metering.openshift.io/placeholder"
+	// placeholderNamespace is the value of namespace inputs when queries
+	// are validated.
+	placeholderNamespace = "default"
+	// placeholderString is the value of string inputs when queries are
+	// validated.
+	placeholderString = "placeholder"
+)
+
+// missingTableRE matches the errors Presto returns for tables and schemas
+// which don't exist, which happen when the query reads the results of
+// reports which haven't run yet, rather than because the query is invalid.
+var missingTableRE = regexp.MustCompile(`(Table|Schema) \S+ does not exist`)
+
+// checkGenerationQueryValid validates the generationQuery's SQL and sets
+// its QueryValid condition, returning the updated generationQuery. Invalid
+// queries are only reported, Reports using them fail when they run.
+func (op *Reporting) checkGenerationQueryValid(logger log.FieldLogger, generationQuery *cbTypes.ReportGenerationQuery) (*cbTypes.ReportGenerationQuery, error) {
+	dependentQueries, err := op.getDependentGenerationQueries(generationQuery, true)
+	if err != nil {
+		return nil, err
+	}
+	pricingModels, err := op.getPricingModels(generationQuery.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	tenantNamespace := op.tenantNamespace(generationQuery.Namespace)
+	status, reason, msg := checkGenerationQuerySQL(logger, op.prestoQueryer, generationQuery, dependentQueries, pricingModels, tenantNamespace, op.clock.Now())
+
+	current := cbutil.GetReportGenerationQueryCondition(generationQuery, cbTypes.ReportGenerationQueryQueryValid)
+	if current != nil && current.Status == status && current.Reason == reason && current.Message == msg {
+		return generationQuery, nil
+	}
+	switch reason {
+	case cbutil.InvalidQueryReason:
+		logger.Warnf("reportGenerationQuery has an invalid query: %s", msg)
+		op.eventRecorder.Event(generationQuery, v1.EventTypeWarning, reason, msg)
+	case cbutil.UnableToValidateQueryReason:
+		logger.Warnf("unable to validate reportGenerationQuery query: %s", msg)
+	}
+
+	cbutil.SetReportGenerationQueryCondition(generationQuery, *cbutil.NewReportGenerationQueryCondition(cbTypes.ReportGenerationQueryQueryValid, status, reason, msg))
+	newQuery, err := op.meteringClient.MeteringV1alpha1().ReportGenerationQueries(generationQuery.Namespace).Update(generationQuery)
+	if err != nil {
+		logger.WithError(err).Errorf("failed to update ReportGenerationQuery conditions for %q", generationQuery.Name)
+		return nil, err
+	}
+	return newQuery, nil
+}
+
+// checkGenerationQuerySQL renders the generationQuery with placeholder
+// report values and has Presto validate the rendered SQL with EXPLAIN
+// (TYPE VALIDATE), which analyzes the query without running it. It returns
+// the status, reason and message of the QueryValid condition.
+func checkGenerationQuerySQL(logger log.FieldLogger, queryer presto.Queryer, generationQuery *cbTypes.ReportGenerationQuery, dependentQueries []*cbTypes.ReportGenerationQuery, pricingModels map[string]*cbTypes.PricingModel, tenantNamespace string, now time.Time) (v1.ConditionStatus, string, string) {
+	if _, err := newQueryTemplate(generationQuery.Spec.Query); err != nil {
+		return v1.ConditionFalse, cbutil.InvalidQueryReason, fmt.Sprintf("query template is invalid: %v", err)
+	}
+	for _, query := range dependentQueries {
+		if _, err := newQueryTemplate(query.Spec.Query); err != nil {
+			return v1.ConditionFalse, cbutil.InvalidQueryReason, fmt.Sprintf("query template of ReportGenerationQuery %s is invalid: %v", query.Name, err)
+		}
+	}
+
+	reportInfo, err := placeholderReportTemplateInfo(generationQuery, dependentQueries, now)
+	if err != nil {
+		return v1.ConditionFalse, cbutil.InvalidQueryReason, err.Error()
+	}
+	reportInfo.tenantNamespace = tenantNamespace
+	qr := queryRenderer{templateInfo: &templateInfo{
+		DynamicDependentQueries: dependentQueries,
+		pricingModels:           pricingModels,
+		tenantNamespace:         tenantNamespace,
+		Report:                  reportInfo,
+	}}
+	renderedQuery, err := qr.Render(generationQuery.Spec.Query)
+	if tenantErr, ok := err.(*tenantQueryError); ok {
+		return v1.ConditionFalse, cbutil.InvalidQueryReason, tenantErr.Error()
+	}
+	if err != nil {
+		// the query may depend on input values, such as the name of a
+		// PricingModel, which the placeholders don't provide.
+		return v1.ConditionUnknown, cbutil.UnableToValidateQueryReason, fmt.Sprintf("unable to render query with placeholder report values: %v", err)
+	}
+
+	_, err = queryer.Query(fmt.Sprintf("EXPLAIN (TYPE VALIDATE) %s", renderedQuery))
+	if err != nil {
+		if isPrestoConnectionError(err) {
+			logger.WithError(err).Warnf("unable to explain query")
+			return v1.ConditionUnknown, cbutil.UnableToValidateQueryReason, fmt.Sprintf("unable to explain query: %v", err)
+		}
+		if missingTableRE.MatchString(err.Error()) {
+			return v1.ConditionUnknown, cbutil.UnableToValidateQueryReason, fmt.Sprintf("query reads tables which don't exist yet: %v", err)
+		}
+		return v1.ConditionFalse, cbutil.InvalidQueryReason, fmt.Sprintf("query was rejected by Presto: %v", err)
+	}
+	return v1.ConditionTrue, cbutil.QueryValidatedReason, "query was explained by Presto"
+}
+
+// placeholderReportTemplateInfo returns the report values queries are
+// rendered with when they're validated: the last hour as the reporting
+// period, and placeholder values for the inputs, groupByLabels and
+// costAllocation the query supports.
+func placeholderReportTemplateInfo(generationQuery *cbTypes.ReportGenerationQuery, dependentQueries []*cbTypes.ReportGenerationQuery, now time.Time) (*reportTemplateInfo, error) {
+	end := now.UTC().Truncate(time.Hour)
+	start := end.Add(-time.Hour)
+
+	definitions, err := getQueryInputDefinitions(generationQuery, dependentQueries)
+	if err != nil {
+		return nil, err
+	}
+	var values []cbTypes.ReportGenerationQueryInputValue
+	for _, def := range definitions {
+		// inputs with defaults use them, so that invalid defaults are
+		// detected.
+		if def.Default != nil {
+			continue
+		}
+		values = append(values, cbTypes.ReportGenerationQueryInputValue{
+			Name:  def.Name,
+			Value: placeholderInputValue(def, start),
+		})
+	}
+	inputs, err := resolveQueryInputs(definitions, values)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inputs: %v", err)
+	}
+
+	var groupByLabels []groupByLabel
+	if generationQuery.Spec.SupportsGroupByLabels {
+		groupByLabels, err = getGroupByLabels(generationQuery, []string{placeholderGroupByLabel})
+		if err != nil {
+			return nil, err
+		}
+	}
+	// chargeback renders the full idle cost allocation expression.
+	var costAllocation *cbTypes.ReportCostAllocation
+	if generationQuery.Spec.SupportsCostAllocation {
+		costAllocation, err = getCostAllocation(generationQuery, &cbTypes.ReportCostAllocation{Mode: cbTypes.ReportCostAllocationModeChargeback})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &reportTemplateInfo{
+		StartPeriod:    start,
+		EndPeriod:      end,
+		Timezone:       "UTC",
+		Inputs:         inputs,
+		GroupByLabels:  groupByLabels,
+		CostAllocation: costAllocation,
+	}, nil
+}
+
+// placeholderInputValue returns a valid value for the input, which is the
+// first of its allowed values if it has any.
+func placeholderInputValue(def cbTypes.ReportGenerationQueryInputDefinition, start time.Time) string {
+	if len(def.AllowedValues) != 0 {
+		return def.AllowedValues[0]
+	}
+	switch def.Type {
+	case cbTypes.ReportGenerationQueryInputTypeInteger, cbTypes.ReportGenerationQueryInputTypeNumber:
+		return "0"
+	case cbTypes.ReportGenerationQueryInputTypeTime:
+		return start.Format(time.RFC3339)
+	case cbTypes.ReportGenerationQueryInputTypeNamespace:
+		return placeholderNamespace
+	case cbTypes.ReportGenerationQueryInputTypeLabelSelector:
+		// an empty selector matches everything
+		return ""
+	default:
+		return placeholderString
+	}
+}
+
+// isPrestoConnectionError returns true if the error is from being unable to
+// reach Presto, rather than Presto rejecting the query.
+func isPrestoConnectionError(err error) bool {
+	switch err.(type) {
+	case *prestoclient.ErrQueryFailed, net.Error:
+		return true
+	}
+	return false
+}
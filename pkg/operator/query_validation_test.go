@@ -0,0 +1,122 @@
+package operator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	prestoclient "github.com/prestodb/presto-go-client/presto"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+	"github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+func TestCheckGenerationQuerySQL(t *testing.T) {
+	now := time.Date(2018, time.August, 1, 12, 30, 0, 0, time.UTC)
+	newQuery := func(query string, inputs ...cbTypes.ReportGenerationQueryInputDefinition) *cbTypes.ReportGenerationQuery {
+		return &cbTypes.ReportGenerationQuery{
+			ObjectMeta: meta.ObjectMeta{Name: "namespace-cpu-request", Namespace: "metering"},
+			Spec: cbTypes.ReportGenerationQuerySpec{
+				Query:  query,
+				Inputs: inputs,
+			},
+		}
+	}
+	const periodQuery = `SELECT * FROM {| dataSourceTableName "pod-request-cpu-cores" |} WHERE "timestamp" >= timestamp '{| .Report.StartPeriod | prestoTimestamp |}' AND "timestamp" < timestamp '{| .Report.EndPeriod | prestoTimestamp |}'`
+	invalidDefault := "abc"
+
+	tests := map[string]struct {
+		generationQuery *cbTypes.ReportGenerationQuery
+		tenantNamespace string
+		expectedSQL     string
+		explainErr      error
+		expectedStatus  v1.ConditionStatus
+		expectedReason  string
+	}{
+		"invalid template": {
+			generationQuery: newQuery(`SELECT {| .Report.StartPeriod `),
+			expectedStatus:  v1.ConditionFalse,
+			expectedReason:  cbutil.InvalidQueryReason,
+		},
+		"valid": {
+			generationQuery: newQuery(periodQuery),
+			expectedSQL:     `EXPLAIN (TYPE VALIDATE) SELECT * FROM datasource_pod_request_cpu_cores WHERE "timestamp" >= timestamp '2018-08-01 11:00:00.000' AND "timestamp" < timestamp '2018-08-01 12:00:00.000'`,
+			expectedStatus:  v1.ConditionTrue,
+			expectedReason:  cbutil.QueryValidatedReason,
+		},
+		"placeholder inputs": {
+			generationQuery: newQuery(`SELECT {| .Report.Inputs.limit |}, '{| .Report.Inputs.namespace |}', '{| .Report.Inputs.aggregation |}'`,
+				cbTypes.ReportGenerationQueryInputDefinition{Name: "limit", Type: cbTypes.ReportGenerationQueryInputTypeInteger, Required: true},
+				cbTypes.ReportGenerationQueryInputDefinition{Name: "namespace", Type: cbTypes.ReportGenerationQueryInputTypeNamespace},
+				cbTypes.ReportGenerationQueryInputDefinition{Name: "aggregation", AllowedValues: []string{"daily", "monthly"}},
+			),
+			expectedSQL:    `EXPLAIN (TYPE VALIDATE) SELECT 0, 'default', 'daily'`,
+			expectedStatus: v1.ConditionTrue,
+			expectedReason: cbutil.QueryValidatedReason,
+		},
+		"invalid input default": {
+			generationQuery: newQuery(`SELECT {| .Report.Inputs.limit |}`,
+				cbTypes.ReportGenerationQueryInputDefinition{Name: "limit", Type: cbTypes.ReportGenerationQueryInputTypeInteger, Default: &invalidDefault},
+			),
+			expectedStatus: v1.ConditionFalse,
+			expectedReason: cbutil.InvalidQueryReason,
+		},
+		"unable to render": {
+			generationQuery: newQuery(`SELECT * FROM {| pricingModelRates "missing" |}`),
+			expectedStatus:  v1.ConditionUnknown,
+			expectedReason:  cbutil.UnableToValidateQueryReason,
+		},
+		"rejected by Presto": {
+			generationQuery: newQuery(periodQuery),
+			expectedSQL:     "*",
+			explainErr:      errors.New(`com.facebook.presto.sql.analyzer.SemanticException: line 1:8: Column 'pod' cannot be resolved`),
+			expectedStatus:  v1.ConditionFalse,
+			expectedReason:  cbutil.InvalidQueryReason,
+		},
+		"missing table": {
+			generationQuery: newQuery(periodQuery),
+			expectedSQL:     "*",
+			explainErr:      errors.New(`com.facebook.presto.sql.analyzer.SemanticException: line 1:15: Table hive.default.report_namespace_cpu_request does not exist`),
+			expectedStatus:  v1.ConditionUnknown,
+			expectedReason:  cbutil.UnableToValidateQueryReason,
+		},
+		"tenant query reading another tenant's report": {
+			generationQuery: newQuery(`SELECT * FROM tenant_team_b.report_payroll`),
+			tenantNamespace: "team-a",
+			expectedStatus:  v1.ConditionFalse,
+			expectedReason:  cbutil.InvalidQueryReason,
+		},
+		"Presto unavailable": {
+			generationQuery: newQuery(periodQuery),
+			expectedSQL:     "*",
+			explainErr:      &prestoclient.ErrQueryFailed{StatusCode: 503, Reason: errors.New("service unavailable")},
+			expectedStatus:  v1.ConditionUnknown,
+			expectedReason:  cbutil.UnableToValidateQueryReason,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			queryer := mockpresto.NewMockExecQueryer(ctrl)
+			if tt.expectedSQL == "*" {
+				queryer.EXPECT().Query(gomock.Any()).Return(nil, tt.explainErr)
+			} else if tt.expectedSQL != "" {
+				queryer.EXPECT().Query(tt.expectedSQL).Return(nil, tt.explainErr)
+			}
+
+			status, reason, msg := checkGenerationQuerySQL(testLogger, queryer, tt.generationQuery, nil, map[string]*cbTypes.PricingModel{}, tt.tenantNamespace, now)
+			assert.Equal(t, tt.expectedStatus, status)
+			assert.Equal(t, tt.expectedReason, reason)
+			assert.NotEmpty(t, msg)
+		})
+	}
+}
@@ -0,0 +1,402 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/secrets"
+)
+
+const (
+	// remoteReportClusterIDColumn is the column added to remote report
+	// results identifying the installation they were loaded from.
+	remoteReportClusterIDColumn = "cluster_id"
+
+	defaultRemoteReportFileFormat = "orc"
+)
+
+func (op *Reporting) handleRemoteReportDataSource(logger logrus.FieldLogger, dataSource *cbTypes.ReportDataSource) error {
+	remote := dataSource.Spec.RemoteReport
+	if err := validateRemoteReportDataSource(remote); err != nil {
+		return fmt.Errorf("datasource %q: improperly configured datasource, %v", dataSource.Name, err)
+	}
+	if interval := op.remoteReportPollInterval(dataSource); interval <= 0 {
+		return fmt.Errorf("datasource %q: improperly configured datasource, pollInterval must be positive, got %s", dataSource.Name, interval)
+	}
+
+	if dataSource.TableName == "" {
+		if remote.S3 != nil {
+			err := op.createRemoteReportS3Table(logger, dataSourceRemoteTableName(dataSource.Name), remote)
+			if err != nil {
+				return err
+			}
+		}
+
+		tableName := dataSourceTableName(dataSource.Name)
+		err := op.createTableForStorage(logger, dataSource, "ReportDataSource", dataSource.Name, remote.Storage, tableName, remoteReportHiveColumns(remote.Columns))
+		if err != nil {
+			return err
+		}
+
+		err = op.updateDataSourceTableName(logger, dataSource, tableName)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to update ReportDataSource TableName field %q", tableName)
+			return err
+		}
+	}
+
+	op.remoteReportNewDataSourceQueue <- dataSource
+	return nil
+}
+
+func validateRemoteReportDataSource(remote *cbTypes.RemoteReportDataSource) error {
+	if remote.ClusterID == "" {
+		return fmt.Errorf("clusterID must be set")
+	}
+	if (remote.ReportName == "") == (remote.ScheduledReportName == "") {
+		return fmt.Errorf("exactly one of reportName or scheduledReportName must be set")
+	}
+	if (remote.API == nil) == (remote.S3 == nil) {
+		return fmt.Errorf("exactly one of api or s3 must be set")
+	}
+	if remote.API != nil {
+		if remote.API.URL == "" {
+			return fmt.Errorf("api.url must be set")
+		}
+		if remote.API.BearerTokenSecret != "" {
+			if _, err := secrets.ParseRef(remote.API.BearerTokenSecret); err != nil {
+				return fmt.Errorf("invalid api.bearerTokenSecret: %v", err)
+			}
+		}
+	}
+	if remote.S3 != nil && remote.S3.Bucket == "" {
+		return fmt.Errorf("s3.bucket must be set")
+	}
+	if len(remote.Columns) == 0 {
+		return fmt.Errorf("columns must be set")
+	}
+
+	timestampColumnFound := remote.TimestampColumn == ""
+	for _, column := range remote.Columns {
+		if column.Name == remoteReportClusterIDColumn {
+			return fmt.Errorf("column %s is reserved for the clusterID", remoteReportClusterIDColumn)
+		}
+		prestoColumn, err := hiveColumnToPrestoColumn(hive.Column{Name: column.Name, Type: column.Type})
+		if err != nil {
+			return fmt.Errorf("column %s: %v", column.Name, err)
+		}
+		if !prestostore.IsRemoteReportColumnTypeSupported(prestoColumn.Type) {
+			return fmt.Errorf("column %s: type %s is not supported", column.Name, column.Type)
+		}
+		if column.Name == remote.TimestampColumn {
+			if prestoColumn.Type != "TIMESTAMP" {
+				return fmt.Errorf("timestampColumn %s must be a timestamp column", column.Name)
+			}
+			timestampColumnFound = true
+		}
+	}
+	if !timestampColumnFound {
+		return fmt.Errorf("timestampColumn %s is not one of the columns", remote.TimestampColumn)
+	}
+	return nil
+}
+
+// remoteReportHiveColumns are the columns of a remoteReport ReportDataSource's
+// table, which are the columns of the remote report and the cluster_id.
+func remoteReportHiveColumns(columns []cbTypes.ReportGenerationQueryColumn) []hive.Column {
+	return append(generateHiveColumns(columns), hive.Column{Name: remoteReportClusterIDColumn, Type: "string"})
+}
+
+// createRemoteReportS3Table creates an external Hive table reading the remote
+// report's results from its table directory in S3.
+func (op *Reporting) createRemoteReportS3Table(logger logrus.FieldLogger, tableName string, remote *cbTypes.RemoteReportDataSource) error {
+	location, err := hive.S3Location(remote.S3.Bucket, remote.S3.Prefix)
+	if err != nil {
+		return err
+	}
+	fileFormat := remote.S3.FileFormat
+	if fileFormat == "" {
+		fileFormat = defaultRemoteReportFileFormat
+	}
+
+	params := hive.TableParameters{
+		Name:         tableName,
+		Columns:      generateHiveColumns(remote.Columns),
+		IgnoreExists: true,
+	}
+	properties := hive.TableProperties{
+		Location:   location,
+		FileFormat: fileFormat,
+		External:   true,
+	}
+	// the table reads the remote installation's files, so there's no
+	// PrestoTable resource for it, and the table's name isn't added to the
+	// location.
+	return op.createTable(logger, params, properties)
+}
+
+// remoteReportPollInterval returns how often the results of a remoteReport
+// ReportDataSource are loaded.
+func (op *Reporting) remoteReportPollInterval(dataSource *cbTypes.ReportDataSource) time.Duration {
+	if interval := dataSource.Spec.RemoteReport.PollInterval; interval != nil {
+		return interval.Duration
+	}
+	return op.cfg.PrometheusQueryConfig.QueryInterval.Duration
+}
+
+func (op *Reporting) runRemoteReportLoaderWorker(stopCh <-chan struct{}) {
+	logger := op.logger.WithField("component", "RemoteReportLoader")
+	logger.Infof("RemoteReportLoader worker started")
+	defer logger.Infof("RemoteReportLoader worker shutdown")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	workers := make(map[string]*remoteReportLoaderWorker)
+
+	for {
+		select {
+		case <-stopCh:
+			logger.Infof("got shutdown signal, shutting down RemoteReportLoaders")
+			return
+		case dataSourceName := <-op.remoteReportDeletedDataSourceQueue:
+			if worker, exists := workers[dataSourceName]; exists {
+				worker.stop()
+				delete(workers, dataSourceName)
+			}
+		case dataSource := <-op.remoteReportNewDataSourceQueue:
+			worker := &remoteReportLoaderWorker{
+				namespace:      dataSource.Namespace,
+				dataSourceName: dataSource.Name,
+				tableName:      dataSource.TableName,
+				remote:         dataSource.Spec.RemoteReport.DeepCopy(),
+				interval:       op.remoteReportPollInterval(dataSource),
+				stopCh:         make(chan struct{}),
+				doneCh:         make(chan struct{}),
+			}
+			if existing, exists := workers[dataSource.Name]; exists {
+				if existing.tableName == worker.tableName && existing.interval == worker.interval && reflect.DeepEqual(existing.remote, worker.remote) {
+					// config hasn't changed skip the update
+					continue
+				}
+				existing.stop()
+			}
+			workers[dataSource.Name] = worker
+
+			dataSourceLogger := logger.WithFields(logrus.Fields{
+				"reportDataSource": dataSource.Name,
+				"tableName":        worker.tableName,
+				"clusterID":        worker.remote.ClusterID,
+			})
+			go worker.start(ctx, dataSourceLogger, op)
+		}
+	}
+}
+
+type remoteReportLoaderWorker struct {
+	namespace      string
+	dataSourceName string
+	tableName      string
+	remote         *cbTypes.RemoteReportDataSource
+	interval       time.Duration
+	stopCh         chan struct{}
+	doneCh         chan struct{}
+}
+
+// start loads the remote report's results immediately and then every
+// interval. Results fetched from the API are only stored if they changed
+// since they were last loaded.
+func (w *remoteReportLoaderWorker) start(ctx context.Context, logger logrus.FieldLogger, op *Reporting) {
+	ticker := time.NewTicker(w.interval)
+	defer close(w.doneCh)
+	defer ticker.Stop()
+
+	logger.Infof("Loading remote report results every %s", w.interval)
+	var lastChecksum [sha256.Size]byte
+	for {
+		var err error
+		if w.remote.S3 != nil {
+			err = op.loadRemoteReportFromS3(ctx, w.tableName, dataSourceRemoteTableName(w.dataSourceName), w.remote)
+		} else {
+			lastChecksum, err = op.loadRemoteReportFromAPI(ctx, logger, w.tableName, w.remote, lastChecksum)
+		}
+		if err != nil {
+			logger.WithError(err).Errorf("error loading remote report results")
+		}
+		if ctx.Err() == nil {
+			op.updateDataSourceImportCondition(logger, w.namespace, w.dataSourceName, err)
+		}
+
+		select {
+		case <-w.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *remoteReportLoaderWorker) stop() {
+	close(w.stopCh)
+	<-w.doneCh
+}
+
+// loadRemoteReportFromS3 replaces the contents of the table with the results
+// read from the remote report's S3 table.
+func (op *Reporting) loadRemoteReportFromS3(ctx context.Context, tableName, remoteTableName string, remote *cbTypes.RemoteReportDataSource) error {
+	err := presto.DeleteFromContext(ctx, op.importerPrestoQueryer, tableName)
+	if err != nil {
+		return fmt.Errorf("couldn't empty table %s of previously loaded results: %v", tableName, err)
+	}
+	query := fmt.Sprintf("SELECT %s, '%s' FROM %s", remoteReportColumnsSQL(remote.Columns), strings.Replace(remote.ClusterID, "'", "''", -1), remoteTableName)
+	return presto.InsertIntoContext(ctx, op.importerPrestoQueryer, tableName, query)
+}
+
+func remoteReportColumnsSQL(columns []cbTypes.ReportGenerationQueryColumn) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = `"` + column.Name + `"`
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// loadRemoteReportFromAPI fetches the results of the remote report from the
+// remote reporting-operator's API, and replaces the contents of the table
+// with them if their checksum differs from lastChecksum. It returns the
+// checksum of the results which are stored in the table.
+func (op *Reporting) loadRemoteReportFromAPI(ctx context.Context, logger logrus.FieldLogger, tableName string, remote *cbTypes.RemoteReportDataSource, lastChecksum [sha256.Size]byte) ([sha256.Size]byte, error) {
+	body, err := op.fetchRemoteReportResults(ctx, remote)
+	if err != nil {
+		return lastChecksum, err
+	}
+	if body == nil {
+		logger.Debugf("remote report hasn't finished yet")
+		return lastChecksum, nil
+	}
+	checksum := sha256.Sum256(body)
+	if checksum == lastChecksum {
+		logger.Debugf("remote report results haven't changed")
+		return lastChecksum, nil
+	}
+
+	rows, err := decodeRemoteReportResults(body)
+	if err != nil {
+		return lastChecksum, err
+	}
+	columns, err := generatePrestoColumns(remote.Columns)
+	if err != nil {
+		return lastChecksum, err
+	}
+	err = prestostore.StoreRemoteReportResults(ctx, op.importerPrestoQueryer, tableName, remote.ClusterID, columns, rows)
+	if err != nil {
+		// the table's contents are unknown, so the results are stored
+		// again next time.
+		return [sha256.Size]byte{}, err
+	}
+	logger.Infof("loaded %d rows of remote report results", len(rows))
+	return checksum, nil
+}
+
+// fetchRemoteReportResults returns the JSON results of the remote report, or
+// nil if the report hasn't finished yet.
+func (op *Reporting) fetchRemoteReportResults(ctx context.Context, remote *cbTypes.RemoteReportDataSource) ([]byte, error) {
+	reqURL, err := remoteReportResultsURL(remote)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	client := http.DefaultClient
+	if remote.API.BearerTokenSecret != "" {
+		ref, err := secrets.ParseRef(remote.API.BearerTokenSecret)
+		if err != nil {
+			return nil, err
+		}
+		client = &http.Client{Transport: secrets.NewBearerTokenRoundTripper(op.secretResolver, ref, nil)}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch remote report results: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read remote report results: %v", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return body, nil
+	case http.StatusAccepted:
+		// the API returns 202 Accepted while the report is running
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unable to fetch remote report results, got status %d: %s", resp.StatusCode, body)
+	}
+}
+
+// remoteReportResultsURL returns the URL of the API endpoint returning the
+// remote report's results as JSON.
+func remoteReportResultsURL(remote *cbTypes.RemoteReportDataSource) (string, error) {
+	u, err := url.Parse(remote.API.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid api.url: %v", err)
+	}
+	name := remote.ReportName
+	endpoint := APIV1ReportsGetEndpoint
+	if remote.ScheduledReportName != "" {
+		name = remote.ScheduledReportName
+		endpoint = APIV1ScheduledReportsGetEndpoint
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + endpoint
+	u.RawQuery = url.Values{"name": {name}, "format": {"json"}}.Encode()
+	return u.String(), nil
+}
+
+// getLastRemoteReportTimestamp returns the most recent value of the timestamp
+// column in a remoteReport ReportDataSource's table.
+func getLastRemoteReportTimestamp(queryer presto.Queryer, tableName, timestampColumn string) (*time.Time, error) {
+	results, err := queryer.Query(fmt.Sprintf(`SELECT max("%s") AS "timestamp" FROM %s`, timestampColumn, tableName))
+	if err != nil {
+		return nil, fmt.Errorf("error getting last %s for table %s: %v", timestampColumn, tableName, err)
+	}
+	if len(results) != 0 {
+		if ts, ok := results[0]["timestamp"].(time.Time); ok {
+			return &ts, nil
+		}
+	}
+	return nil, nil
+}
+
+// decodeRemoteReportResults decodes the rows returned by the report results
+// API, decoding numbers as json.Numbers so that they're stored without losing
+// precision.
+func decodeRemoteReportResults(body []byte) ([]prestostore.RemoteReportRow, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var rows []prestostore.RemoteReportRow
+	if err := decoder.Decode(&rows); err != nil {
+		return nil, fmt.Errorf("unable to decode remote report results: %v", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,217 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestValidateRemoteReportDataSource(t *testing.T) {
+	columns := []cbTypes.ReportGenerationQueryColumn{
+		{Name: "period_end", Type: "timestamp"},
+		{Name: "namespace", Type: "string"},
+		{Name: "pod_request_cpu_core_seconds", Type: "double"},
+	}
+
+	tests := map[string]struct {
+		remote      *cbTypes.RemoteReportDataSource
+		expectedErr bool
+	}{
+		"valid": {
+			remote: &cbTypes.RemoteReportDataSource{
+				ClusterID:       "eu-west",
+				ReportName:      "namespace-cpu-request",
+				Columns:         columns,
+				TimestampColumn: "period_end",
+				API:             &cbTypes.RemoteReportAPI{URL: "https://metering.eu-west.example.com"},
+			},
+		},
+		"s3": {
+			remote: &cbTypes.RemoteReportDataSource{
+				ClusterID:       "eu-west",
+				ReportName:      "namespace-cpu-request",
+				Columns:         columns,
+				TimestampColumn: "period_end",
+				S3:              &cbTypes.RemoteReportS3{S3Bucket: cbTypes.S3Bucket{Bucket: "metering-eu-west", Prefix: "report_namespace_cpu_request"}},
+			},
+		},
+		"no clusterID": {
+			remote: &cbTypes.RemoteReportDataSource{
+				ReportName:      "namespace-cpu-request",
+				Columns:         columns,
+				TimestampColumn: "period_end",
+				API:             &cbTypes.RemoteReportAPI{URL: "https://metering.eu-west.example.com"},
+			},
+			expectedErr: true,
+		},
+		"reportName and scheduledReportName": {
+			remote: &cbTypes.RemoteReportDataSource{
+				ClusterID:           "eu-west",
+				ReportName:          "namespace-cpu-request",
+				ScheduledReportName: "namespace-cpu-request-daily",
+				Columns:             columns,
+				TimestampColumn:     "period_end",
+				API:                 &cbTypes.RemoteReportAPI{URL: "https://metering.eu-west.example.com"},
+			},
+			expectedErr: true,
+		},
+		"api and s3": {
+			remote: &cbTypes.RemoteReportDataSource{
+				ClusterID:       "eu-west",
+				ReportName:      "namespace-cpu-request",
+				Columns:         columns,
+				TimestampColumn: "period_end",
+				API:             &cbTypes.RemoteReportAPI{URL: "https://metering.eu-west.example.com"},
+				S3:              &cbTypes.RemoteReportS3{S3Bucket: cbTypes.S3Bucket{Bucket: "metering-eu-west"}},
+			},
+			expectedErr: true,
+		},
+		"invalid bearerTokenSecret": {
+			remote: &cbTypes.RemoteReportDataSource{
+				ClusterID:       "eu-west",
+				ReportName:      "namespace-cpu-request",
+				Columns:         columns,
+				TimestampColumn: "period_end",
+				API:             &cbTypes.RemoteReportAPI{URL: "https://metering.eu-west.example.com", BearerTokenSecret: "eu-west-metering"},
+			},
+			expectedErr: true,
+		},
+		"cluster_id column": {
+			remote: &cbTypes.RemoteReportDataSource{
+				ClusterID:  "eu-west",
+				ReportName: "namespace-cpu-request",
+				Columns: []cbTypes.ReportGenerationQueryColumn{
+					{Name: "period_end", Type: "timestamp"},
+					{Name: "cluster_id", Type: "string"},
+				},
+				TimestampColumn: "period_end",
+				API:             &cbTypes.RemoteReportAPI{URL: "https://metering.eu-west.example.com"},
+			},
+			expectedErr: true,
+		},
+		"unsupported column type": {
+			remote: &cbTypes.RemoteReportDataSource{
+				ClusterID:  "eu-west",
+				ReportName: "namespace-cpu-request",
+				Columns: []cbTypes.ReportGenerationQueryColumn{
+					{Name: "period_end", Type: "timestamp"},
+					{Name: "nodes", Type: "array<string>"},
+				},
+				TimestampColumn: "period_end",
+				API:             &cbTypes.RemoteReportAPI{URL: "https://metering.eu-west.example.com"},
+			},
+			expectedErr: true,
+		},
+		"unknown timestampColumn": {
+			remote: &cbTypes.RemoteReportDataSource{
+				ClusterID:       "eu-west",
+				ReportName:      "namespace-cpu-request",
+				Columns:         columns,
+				TimestampColumn: "period_start",
+				API:             &cbTypes.RemoteReportAPI{URL: "https://metering.eu-west.example.com"},
+			},
+			expectedErr: true,
+		},
+		"timestampColumn isn't a timestamp": {
+			remote: &cbTypes.RemoteReportDataSource{
+				ClusterID:       "eu-west",
+				ReportName:      "namespace-cpu-request",
+				Columns:         columns,
+				TimestampColumn: "namespace",
+				API:             &cbTypes.RemoteReportAPI{URL: "https://metering.eu-west.example.com"},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			err := validateRemoteReportDataSource(tt.remote)
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRemoteReportResultsURL(t *testing.T) {
+	tests := map[string]struct {
+		remote      *cbTypes.RemoteReportDataSource
+		expectedURL string
+	}{
+		"report": {
+			remote: &cbTypes.RemoteReportDataSource{
+				ReportName: "namespace-cpu-request",
+				API:        &cbTypes.RemoteReportAPI{URL: "https://metering.eu-west.example.com/"},
+			},
+			expectedURL: "https://metering.eu-west.example.com/api/v1/reports/get?format=json&name=namespace-cpu-request",
+		},
+		"scheduled report": {
+			remote: &cbTypes.RemoteReportDataSource{
+				ScheduledReportName: "namespace-cpu-request-daily",
+				API:                 &cbTypes.RemoteReportAPI{URL: "https://metering.eu-west.example.com/"},
+			},
+			expectedURL: "https://metering.eu-west.example.com/api/v1/scheduledreports/get?format=json&name=namespace-cpu-request-daily",
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			u, err := remoteReportResultsURL(tt.remote)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedURL, u)
+		})
+	}
+}
+
+func TestFetchRemoteReportResults(t *testing.T) {
+	status := http.StatusAccepted
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != APIV1ReportsGetEndpoint || r.URL.Query().Get("name") != "namespace-cpu-request" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			fmt.Fprint(w, `[{"period_end": "2018-07-01T00:00:00Z", "namespace": "team-a", "pod_request_cpu_core_seconds": 7200.123456789}]`)
+		}
+	}))
+	defer srv.Close()
+
+	op := &Reporting{}
+	remote := &cbTypes.RemoteReportDataSource{
+		ClusterID:  "eu-west",
+		ReportName: "namespace-cpu-request",
+		API:        &cbTypes.RemoteReportAPI{URL: srv.URL},
+	}
+
+	body, err := op.fetchRemoteReportResults(context.Background(), remote)
+	require.NoError(t, err)
+	assert.Nil(t, body, "reports which haven't finished should have no results")
+
+	status = http.StatusOK
+	body, err = op.fetchRemoteReportResults(context.Background(), remote)
+	require.NoError(t, err)
+	rows, err := decodeRemoteReportResults(body)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, json.Number("7200.123456789"), rows[0]["pod_request_cpu_core_seconds"])
+
+	status = http.StatusInternalServerError
+	_, err = op.fetchRemoteReportResults(context.Background(), remote)
+	assert.Error(t, err)
+}
@@ -0,0 +1,602 @@
+package operator
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	APIV1ReportsAmendmentsEndpoint          = "/api/v1/reports/amendments"
+	APIV1ScheduledReportsAmendmentsEndpoint = "/api/v1/scheduledreports/amendments"
+
+	// The amendments table of a report has its key columns, followed by the
+	// original and amended values of each of its other columns and the
+	// difference between them, and when, for which period and because of
+	// which ReportDataSources each amendment was made, and is partitioned
+	// by the ID of each amendment.
+	reportAmendmentColumn            = "report_amendment"
+	reportAmendmentAmendedAtColumn   = "report_amendment_amended_at"
+	reportAmendmentPeriodStartColumn = "report_amendment_period_start"
+	reportAmendmentPeriodEndColumn   = "report_amendment_period_end"
+	reportAmendmentDataSourcesColumn = "report_amendment_data_sources"
+
+	reportAmendmentOriginalSuffix = "_original"
+	reportAmendmentAmendedSuffix  = "_amended"
+	reportAmendmentDeltaSuffix    = "_delta"
+)
+
+func reportAmendmentsTableName(tableName string) string {
+	return tableName + "_amendments"
+}
+
+func validateReportAmendments(amendments *cbTypes.ReportAmendments) error {
+	if amendments == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for i, column := range amendments.KeyColumns {
+		if column == "" {
+			return fmt.Errorf("amendments.keyColumns[%d] must be set", i)
+		}
+		if seen[column] {
+			return fmt.Errorf("amendments.keyColumns: %s is listed more than once", column)
+		}
+		seen[column] = true
+	}
+	return nil
+}
+
+// validateScheduledReportAmendments checks the amendments of a
+// ScheduledReport, whose table must hold the results of every run for them
+// to be amended.
+func validateScheduledReportAmendments(spec cbTypes.ScheduledReportSpec) error {
+	if spec.Amendments == nil {
+		return nil
+	}
+	if spec.Window != nil || spec.OverwriteExistingData {
+		return fmt.Errorf("ScheduledReport.spec.amendments cannot be used with spec.window or spec.overwriteExistingData")
+	}
+	return validateReportAmendments(spec.Amendments)
+}
+
+// lateData is a time range ReportDataSources imported data for after the
+// reports depending on them had reported on it.
+type lateData struct {
+	dataSources []string
+	start, end  time.Time
+}
+
+// merge returns the time range covering both d and other, imported by the
+// ReportDataSources of both.
+func (d lateData) merge(other lateData) lateData {
+	merged := lateData{start: d.start, end: d.end}
+	if other.start.Before(merged.start) {
+		merged.start = other.start
+	}
+	if other.end.After(merged.end) {
+		merged.end = other.end
+	}
+	seen := make(map[string]bool)
+	for _, name := range append(append([]string(nil), d.dataSources...), other.dataSources...) {
+		if !seen[name] {
+			seen[name] = true
+			merged.dataSources = append(merged.dataSources, name)
+		}
+	}
+	sort.Strings(merged.dataSources)
+	return merged
+}
+
+// reportAmendmentQueue holds the late data each Report and ScheduledReport
+// is waiting to be amended for, until its amended run starts.
+type reportAmendmentQueue struct {
+	mu      sync.Mutex
+	pending map[string]lateData
+}
+
+func newReportAmendmentQueue() *reportAmendmentQueue {
+	return &reportAmendmentQueue{pending: make(map[string]lateData)}
+}
+
+func reportAmendmentKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// add queues an amended run of the report for the late data, merging it
+// with any late data the report is already waiting to be amended for.
+func (q *reportAmendmentQueue) add(key string, data lateData) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if pending, exists := q.pending[key]; exists {
+		data = pending.merge(data)
+	}
+	q.pending[key] = data
+}
+
+// take removes and returns the late data the report is waiting to be
+// amended for, if any.
+func (q *reportAmendmentQueue) take(key string) (lateData, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	data, exists := q.pending[key]
+	delete(q.pending, key)
+	return data, exists
+}
+
+func (q *reportAmendmentQueue) has(key string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	_, exists := q.pending[key]
+	return exists
+}
+
+// amendReportsForLateData is called when a ReportDataSource imports data
+// for a time range which may already have been reported on, and queues
+// amended runs of the Reports and ScheduledReports in namespace with
+// amendments enabled which depend on it and have reported on part of the
+// time range.
+func (op *Reporting) amendReportsForLateData(logger log.FieldLogger, namespace, dataSourceName string, start, end time.Time) {
+	data := lateData{dataSources: []string{dataSourceName}, start: start, end: end}
+	inf := op.informers.Metering().V1alpha1()
+
+	reports, err := inf.Reports().Lister().Reports(namespace).List(labels.Everything())
+	if err != nil {
+		logger.WithError(err).Errorf("unable to list reports to amend for late data from ReportDataSource %s", dataSourceName)
+		return
+	}
+	for _, report := range reports {
+		if report.Spec.Amendments == nil || report.Status.Phase != cbTypes.ReportPhaseFinished {
+			continue
+		}
+		if !report.Spec.ReportingStart.Time.Before(end) || !report.Spec.ReportingEnd.Time.After(start) {
+			continue
+		}
+		if !op.dependsOnDataSource(logger, namespace, report.Spec.GenerationQueryName, dataSourceName) {
+			continue
+		}
+		logger.Infof("queueing an amended run of report %s for late data from ReportDataSource %s between %s and %s", report.Name, dataSourceName, start, end)
+		op.reportAmendments.add(reportAmendmentKey(dependencyKindReport, namespace, report.Name), data)
+		key, err := cache.MetaNamespaceKeyFunc(report)
+		if err == nil {
+			op.queues.reportQueue.Add(key)
+		}
+	}
+
+	scheduledReports, err := inf.ScheduledReports().Lister().ScheduledReports(namespace).List(labels.Everything())
+	if err != nil {
+		logger.WithError(err).Errorf("unable to list scheduledReports to amend for late data from ReportDataSource %s", dataSourceName)
+		return
+	}
+	for _, report := range scheduledReports {
+		if report.Spec.Amendments == nil || report.Status.LastReportTime == nil || !report.Status.LastReportTime.Time.After(start) {
+			continue
+		}
+		if !op.dependsOnDataSource(logger, namespace, report.Spec.GenerationQueryName, dataSourceName) {
+			continue
+		}
+		logger.Infof("queueing an amended run of scheduledReport %s for late data from ReportDataSource %s between %s and %s", report.Name, dataSourceName, start, end)
+		op.reportAmendments.add(reportAmendmentKey(dependencyKindScheduledReport, namespace, report.Name), data)
+		op.scheduledReportRunner.notifyAmendment(report.Name)
+	}
+}
+
+// dependsOnDataSource returns true if the ReportGenerationQuery, or the
+// ReportGenerationQueries it depends on, use the ReportDataSource.
+func (op *Reporting) dependsOnDataSource(logger log.FieldLogger, namespace, generationQueryName, dataSourceName string) bool {
+	generationQuery, err := op.informers.Metering().V1alpha1().ReportGenerationQueries().Lister().ReportGenerationQueries(namespace).Get(generationQueryName)
+	if err != nil {
+		logger.WithError(err).Warnf("unable to get ReportGenerationQuery %s to check if it depends on ReportDataSource %s", generationQueryName, dataSourceName)
+		return false
+	}
+	names, err := op.getDependencyNames(generationQuery, func(query *cbTypes.ReportGenerationQuery) []string {
+		return query.Spec.DataSources
+	}, "dataSourceTableName")
+	if err != nil {
+		logger.WithError(err).Warnf("unable to get the ReportDataSources ReportGenerationQuery %s depends on", generationQueryName)
+		return false
+	}
+	for _, name := range names {
+		if name == dataSourceName {
+			return true
+		}
+	}
+	return false
+}
+
+// amendFinishedReport runs an amended run of a finished report for the late
+// data, and records its outcome in the report's status.
+func (op *Reporting) amendFinishedReport(logger log.FieldLogger, report *cbTypes.Report, data lateData) error {
+	genQuery, err := op.informers.Metering().V1alpha1().ReportGenerationQueries().Lister().ReportGenerationQueries(report.Namespace).Get(report.Spec.GenerationQueryName)
+	if err != nil {
+		// retry the amended run when the report is processed again.
+		op.reportAmendments.add(reportAmendmentKey(dependencyKindReport, report.Namespace, report.Name), data)
+		return err
+	}
+	run := op.newReportRun(report, genQuery, nil)
+	report.Status.LastAmendment = op.runReportAmendment(logger, report, run, report.Spec.Output, report.Spec.Amendments, data, false)
+	newReport, err := op.updateReport(report)
+	if err != nil {
+		logger.WithError(err).Errorf("failed to update report status with its amendment")
+		return err
+	}
+	op.recordReportAmendment(newReport, newReport.Status.LastAmendment)
+	return nil
+}
+
+// recordReportAmendment records an event for the outcome of an amended run
+// of the report.
+func (op *Reporting) recordReportAmendment(obj runtime.Object, status *cbTypes.ReportAmendmentStatus) {
+	dataSources := strings.Join(status.DataSources, ", ")
+	if status.Error != "" {
+		op.eventRecorder.Eventf(obj, v1.EventTypeWarning, cbutil.ReportAmendmentFailedReason, "amending the results for late data from %s failed: %s", dataSources, status.Error)
+		return
+	}
+	op.eventRecorder.Eventf(obj, v1.EventTypeNormal, cbutil.ReportAmendedReason, "late data from %s between %s and %s changed %d rows of the results, see amendment %s", dataSources, status.PeriodStart.Time, status.PeriodEnd.Time, status.ChangedRows, status.ID)
+}
+
+// runReportAmendment compares the results of the report for the periods
+// overlapping the late data with the results its query returns for them
+// now, and writes the rows whose values changed to a new partition of its
+// amendments table, without modifying the results. If byPeriod is true, the
+// table holds the results of every run of a ScheduledReport, and the rows
+// of each run overlapping the late data are amended separately, otherwise
+// the report's reporting period is amended as a whole. An amended run which
+// fails is recorded in the returned status.
+func (op *Reporting) runReportAmendment(logger log.FieldLogger, report runtime.Object, run reportRun, storage *cbTypes.StorageLocationRef, amendments *cbTypes.ReportAmendments, data lateData, byPeriod bool) *cbTypes.ReportAmendmentStatus {
+	logger = logger.WithFields(log.Fields{
+		"lateDataStart":       data.start,
+		"lateDataEnd":         data.end,
+		"lateDataDataSources": data.dataSources,
+	})
+	status := &cbTypes.ReportAmendmentStatus{
+		DataSources: data.dataSources,
+		PeriodStart: metav1.Time{Time: data.start},
+		PeriodEnd:   metav1.Time{Time: data.end},
+	}
+	id, err := newReportRunID()
+	if err == nil {
+		status.ID = id
+		status.ChangedRows, err = op.insertReportAmendment(logger, report, run, storage, amendments, data, id, byPeriod)
+	}
+	status.Time = metav1.Time{Time: op.clock.Now().UTC()}
+	if err != nil {
+		status.Error = err.Error()
+		logger.WithError(err).Errorf("failed to amend the report's results for late data")
+		return status
+	}
+	logger.Infof("amendment %s found late data changed %d rows of the report's results", id, status.ChangedRows)
+	return status
+}
+
+func (op *Reporting) insertReportAmendment(logger log.FieldLogger, report runtime.Object, run reportRun, storage *cbTypes.StorageLocationRef, amendments *cbTypes.ReportAmendments, data lateData, id string, byPeriod bool) (int64, error) {
+	materialization, err := getReportMaterializationPolicy(run.generationQuery)
+	if err != nil {
+		return 0, err
+	}
+	if materialization == cbTypes.ReportMaterializationView {
+		return 0, fmt.Errorf("the results of ReportGenerationQueries materialized as views are always up to date, and can't be amended")
+	}
+	groupByLabels, err := getGroupByLabels(run.generationQuery, run.groupByLabels)
+	if err != nil {
+		return 0, err
+	}
+	reportColumns := getReportColumns(run.generationQuery, groupByLabels)
+	keys, values, err := reportAmendmentColumns(reportColumns, amendments.KeyColumns)
+	if err != nil {
+		return 0, err
+	}
+	keyColumns, err := generatePrestoColumns(keys)
+	if err != nil {
+		return 0, err
+	}
+	valueColumns, err := generatePrestoColumns(values)
+	if err != nil {
+		return 0, err
+	}
+
+	periods := []reportPeriod{{periodStart: run.periodStart, periodEnd: run.periodEnd}}
+	if byPeriod {
+		if !hasTimestampColumns(keyColumns, "period_start", "period_end") {
+			return 0, fmt.Errorf("the results must have period_start and period_end timestamp key columns to be amended")
+		}
+		periods, err = reportAmendmentPeriods(op.prestoQueryer, run.tableName, data)
+		if err != nil {
+			return 0, fmt.Errorf("unable to get the periods of the results overlapping the late data: %v", err)
+		}
+	}
+	if len(periods) == 0 {
+		logger.Infof("no results overlap the late data")
+		return 0, nil
+	}
+
+	tableProperties, err := op.getHiveTableProperties(logger, storage, run.kind)
+	if err != nil {
+		return 0, fmt.Errorf("storage incorrectly configured for %s: %s", run.kind, run.name)
+	}
+	amendmentsTableName := reportAmendmentsTableName(run.tableName)
+	properties, err := addTableNameToLocation(*tableProperties, amendmentsTableName)
+	if err != nil {
+		return 0, err
+	}
+	params := hive.TableParameters{
+		Name:         amendmentsTableName,
+		Columns:      reportAmendmentHiveColumns(keys, values),
+		Partitions:   []hive.Column{{Name: reportAmendmentColumn, Type: "string"}},
+		IgnoreExists: true,
+	}
+	if err := op.createTable(logger, params, properties); err != nil {
+		return 0, err
+	}
+	resourceName := prestoTableResourceNameFromKind(run.kind, run.name) + "-amendments"
+	if err := op.createNamedPrestoTableCR(report, cbTypes.GroupName, run.kind, resourceName, params, properties, nil); err != nil {
+		return 0, fmt.Errorf("couldn't create PrestoTable resource for the amendments of %s %s: %v", run.kind, run.name, err)
+	}
+
+	amendedAt := op.clock.Now().UTC()
+	for _, period := range periods {
+		amendedSQL, _, _, err := op.renderReportQuery(logger, report, run.kind, run.name, period.periodStart, period.periodEnd, run.generationQuery)
+		if err != nil {
+			return 0, err
+		}
+		originalSQL := "SELECT * FROM " + run.tableName
+		if byPeriod {
+			originalSQL += fmt.Sprintf(` WHERE "period_start" >= timestamp '%s' AND "period_end" <= timestamp '%s'`, presto.Timestamp(period.periodStart), presto.Timestamp(period.periodEnd))
+		}
+		logger.Debugf("amending the results for the period %s to %s", period.periodStart, period.periodEnd)
+		query := reportAmendmentQuery(keyColumns, valueColumns, originalSQL, amendedSQL, id, amendedAt, period, data.dataSources)
+		if err := presto.InsertInto(op.prestoQueryer, amendmentsTableName, query); err != nil {
+			return 0, err
+		}
+	}
+
+	rows, err := op.prestoQueryer.Query(fmt.Sprintf("SELECT count(*) AS row_count FROM %s WHERE %s = '%s'", amendmentsTableName, reportAmendmentColumn, id))
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) != 1 {
+		return 0, fmt.Errorf("counting the changed rows returned %d rows, expected 1", len(rows))
+	}
+	count, ok := rows[0]["row_count"].(int64)
+	if !ok {
+		return 0, fmt.Errorf("invalid row_count, valueType: %T, value: %+v", rows[0]["row_count"], rows[0]["row_count"])
+	}
+	return count, nil
+}
+
+// reportAmendmentColumns splits the columns of the results into the key
+// columns identifying each row, and the numeric columns which are compared.
+// Without keyColumns, every column which isn't numeric is a key column.
+func reportAmendmentColumns(columns []cbTypes.ReportGenerationQueryColumn, keyColumns []string) (keys, values []cbTypes.ReportGenerationQueryColumn, err error) {
+	isKey := make(map[string]bool)
+	for _, name := range keyColumns {
+		isKey[name] = true
+	}
+	for _, col := range columns {
+		switch {
+		case isKey[col.Name]:
+			keys = append(keys, col)
+			delete(isKey, col.Name)
+		case len(keyColumns) == 0 && !isNumericColumnType(col.Type):
+			keys = append(keys, col)
+		case !isNumericColumnType(col.Type):
+			return nil, nil, fmt.Errorf("column %s must be numeric or one of amendments.keyColumns, got %s", col.Name, col.Type)
+		default:
+			values = append(values, col)
+		}
+	}
+	for name := range isKey {
+		return nil, nil, fmt.Errorf("amendments.keyColumns: column %s isn't in the results", name)
+	}
+	if len(values) == 0 {
+		return nil, nil, fmt.Errorf("the results have no numeric columns to amend")
+	}
+	return keys, values, nil
+}
+
+// reportAmendmentHiveColumns returns the columns of the amendments table.
+// The values are compared as doubles.
+func reportAmendmentHiveColumns(keys, values []cbTypes.ReportGenerationQueryColumn) []hive.Column {
+	columns := generateHiveColumns(keys)
+	for _, col := range values {
+		columns = append(columns,
+			hive.Column{Name: col.Name + reportAmendmentOriginalSuffix, Type: "double"},
+			hive.Column{Name: col.Name + reportAmendmentAmendedSuffix, Type: "double"},
+			hive.Column{Name: col.Name + reportAmendmentDeltaSuffix, Type: "double"},
+		)
+	}
+	return append(columns,
+		hive.Column{Name: reportAmendmentAmendedAtColumn, Type: "timestamp"},
+		hive.Column{Name: reportAmendmentPeriodStartColumn, Type: "timestamp"},
+		hive.Column{Name: reportAmendmentPeriodEndColumn, Type: "timestamp"},
+		hive.Column{Name: reportAmendmentDataSourcesColumn, Type: "string"},
+	)
+}
+
+// reportAmendmentQuery returns the query comparing the original results of
+// a period, selected by originalSQL, with the results amendedSQL returns for
+// it now. Rows are matched by their key columns, and a row is returned for
+// each whose values changed, including rows which were added or removed,
+// with the original and amended sums of each value and the difference
+// between them.
+func reportAmendmentQuery(keys, values []presto.Column, originalSQL, amendedSQL, id string, amendedAt time.Time, period reportPeriod, dataSources []string) string {
+	var keysSQL string
+	if len(keys) != 0 {
+		keysSQL = presto.GenerateQuotedColumnsListSQL(keys) + ", "
+	}
+	var originalColumns, amendedColumns, sums, changed []string
+	for _, col := range values {
+		name := presto.GenerateQuotedColumnsListSQL([]presto.Column{col})
+		original := presto.GenerateQuotedColumnsListSQL([]presto.Column{{Name: col.Name + reportAmendmentOriginalSuffix}})
+		amended := presto.GenerateQuotedColumnsListSQL([]presto.Column{{Name: col.Name + reportAmendmentAmendedSuffix}})
+		originalColumns = append(originalColumns, fmt.Sprintf("CAST(%s AS double) AS %s, CAST(NULL AS double) AS %s", name, original, amended))
+		amendedColumns = append(amendedColumns, fmt.Sprintf("CAST(NULL AS double), CAST(%s AS double)", name))
+		sums = append(sums, fmt.Sprintf("sum(%[1]s), sum(%[2]s), coalesce(sum(%[2]s), 0) - coalesce(sum(%[1]s), 0)", original, amended))
+		// values summed in a different order may differ slightly, so
+		// only differences larger than that are changes.
+		changed = append(changed, fmt.Sprintf("(sum(%[1]s) IS NULL) <> (sum(%[2]s) IS NULL) OR abs(sum(%[2]s) - sum(%[1]s)) > 1E-9", original, amended))
+	}
+	query := fmt.Sprintf("SELECT %s%s, timestamp '%s', timestamp '%s', timestamp '%s', '%s', '%s' FROM (SELECT %s%s FROM (%s) AS original UNION ALL SELECT %s%s FROM (%s) AS amended) AS results",
+		keysSQL, strings.Join(sums, ", "),
+		presto.Timestamp(amendedAt), presto.Timestamp(period.periodStart), presto.Timestamp(period.periodEnd), strings.Join(dataSources, ","), id,
+		keysSQL, strings.Join(originalColumns, ", "), originalSQL,
+		keysSQL, strings.Join(amendedColumns, ", "), amendedSQL,
+	)
+	if len(keys) != 0 {
+		query += " GROUP BY " + presto.GenerateQuotedColumnsListSQL(keys)
+	}
+	return query + " HAVING " + strings.Join(changed, " OR ")
+}
+
+// reportAmendmentPeriods returns the reporting periods of the runs of a
+// ScheduledReport whose results in tableName overlap the late data.
+func reportAmendmentPeriods(queryer presto.Queryer, tableName string, data lateData) ([]reportPeriod, error) {
+	rows, err := queryer.Query(fmt.Sprintf(`SELECT DISTINCT CAST(to_unixtime("period_start") AS bigint) AS period_start, CAST(to_unixtime("period_end") AS bigint) AS period_end FROM %s WHERE "period_start" < timestamp '%s' AND "period_end" > timestamp '%s' ORDER BY 1, 2`,
+		tableName, presto.Timestamp(data.end), presto.Timestamp(data.start)))
+	if err != nil {
+		return nil, err
+	}
+	periods := make([]reportPeriod, 0, len(rows))
+	for _, row := range rows {
+		var period reportPeriod
+		for column, t := range map[string]*time.Time{"period_start": &period.periodStart, "period_end": &period.periodEnd} {
+			secs, ok := row[column].(int64)
+			if !ok {
+				return nil, fmt.Errorf("invalid %s, valueType: %T, value: %+v", column, row[column], row[column])
+			}
+			*t = time.Unix(secs, 0).UTC()
+		}
+		periods = append(periods, period)
+	}
+	return periods, nil
+}
+
+// lateAWSBillingData returns the billing periods of the partitions an AWS
+// billing ReportDataSource's table gained or had replaced, which are data
+// AWS delivered or revised since they were last imported.
+func lateAWSBillingData(partitions []cbTypes.TablePartition) ([]lateData, error) {
+	var data []lateData
+	for _, p := range partitions {
+		start, err := time.Parse(awsUsagePartitionDateStringLayout, p.PartitionSpec["start"])
+		if err != nil {
+			return nil, err
+		}
+		end, err := time.Parse(awsUsagePartitionDateStringLayout, p.PartitionSpec["end"])
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, lateData{start: start.UTC(), end: end.UTC()})
+	}
+	return data, nil
+}
+
+func (srv *server) getReportAmendmentsHandler(w http.ResponseWriter, r *http.Request) {
+	srv.getReportAmendments(dependencyKindReport, w, r)
+}
+
+func (srv *server) getScheduledReportAmendmentsHandler(w http.ResponseWriter, r *http.Request) {
+	srv.getReportAmendments(dependencyKindScheduledReport, w, r)
+}
+
+// getReportAmendments returns the rows of a report's results changed by its
+// amendments, or by the amendment in the amendment query parameter.
+func (srv *server) getReportAmendments(kind string, w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	if !srv.validateStreamReportReq(logger, w, r) {
+		return
+	}
+	name, format := r.Form["name"][0], r.Form["format"][0]
+	listers, err := srv.listersFor(r)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
+	tableName, reportColumns, prestoColumns, err := getReportAmendmentsTable(listers, kind, name)
+	if err != nil {
+		writeReportTableError(logger, err, w, r)
+		return
+	}
+	if id := r.FormValue("amendment"); id != "" {
+		// amendment IDs are generated the same way as version IDs.
+		if !reportVersionIDRegexp.MatchString(id) {
+			writeErrorResponse(logger, w, r, http.StatusBadRequest, "invalid amendment %q", id)
+			return
+		}
+		tableName = fmt.Sprintf("(SELECT * FROM %s WHERE %s = '%s')", tableName, reportAmendmentColumn, id)
+	}
+	reportColumns, prestoColumns, whereSQL, ok := srv.selectReportResults(logger, tableName, reportColumns, prestoColumns, w, r)
+	if !ok {
+		return
+	}
+	results, ok := srv.getReportResults(logger, tableName, srv.reportResultsDataVersion(r, kind, name), prestoColumns, whereSQL, w, r)
+	if !ok {
+		return
+	}
+	writeResultsResponse(logger, format, reportColumns, results, w, r)
+}
+
+// getReportAmendmentsTable returns the name of the amendments table of the
+// Report or ScheduledReport, and its columns.
+func getReportAmendmentsTable(listers meteringListers, kind, name string) (string, []cbTypes.ReportGenerationQueryColumn, []presto.Column, error) {
+	var (
+		tableName string
+		amendment *cbTypes.ReportAmendmentStatus
+		err       error
+	)
+	switch kind {
+	case dependencyKindReport:
+		var report *cbTypes.Report
+		report, err = listers.reports.Get(name)
+		if err == nil {
+			tableName, amendment = reportTableName(name), report.Status.LastAmendment
+		}
+	case dependencyKindScheduledReport:
+		var report *cbTypes.ScheduledReport
+		report, err = listers.scheduledReports.Get(name)
+		if err == nil {
+			tableName, amendment = scheduledReportTableName(name), report.Status.LastAmendment
+		}
+	default:
+		return "", nil, nil, fmt.Errorf("invalid report kind: %s", kind)
+	}
+	if k8serrors.IsNotFound(err) {
+		return "", nil, nil, newReportTableError(http.StatusNotFound, "%s %s does not exist", kind, name)
+	} else if err != nil {
+		return "", nil, nil, newReportTableError(http.StatusInternalServerError, "error getting %s: %v", kind, err)
+	}
+	if amendment == nil {
+		return "", nil, nil, newReportTableError(http.StatusNotFound, "%s %s has not been amended", kind, name)
+	}
+	prestoTable, err := listers.prestoTables.Get(prestoTableResourceNameFromKind(kind, name) + "-amendments")
+	if k8serrors.IsNotFound(err) {
+		return "", nil, nil, newReportTableError(http.StatusNotFound, "%s %s has no amendments", kind, name)
+	} else if err != nil {
+		return "", nil, nil, newReportTableError(http.StatusInternalServerError, "error getting presto table: %v", err)
+	}
+	tableColumns := append(append([]hive.Column(nil), prestoTable.State.Parameters.Columns...), prestoTable.State.Parameters.Partitions...)
+	reportColumns := make([]cbTypes.ReportGenerationQueryColumn, len(tableColumns))
+	for i, col := range tableColumns {
+		reportColumns[i] = cbTypes.ReportGenerationQueryColumn{Name: col.Name, Type: col.Type}
+	}
+	prestoColumns, err := hiveColumnsToPrestoColumns(tableColumns)
+	if err != nil {
+		return "", nil, nil, newReportTableError(http.StatusInternalServerError, "error converting columns: %v", err)
+	}
+	return reportAmendmentsTableName(tenantTableName(listers.tenantNamespace, tableName)), reportColumns, prestoColumns, nil
+}
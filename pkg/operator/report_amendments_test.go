@@ -0,0 +1,168 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	mockpresto "github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+func TestReportAmendmentColumns(t *testing.T) {
+	columns := []cbTypes.ReportGenerationQueryColumn{
+		{Name: "period_start", Type: "timestamp"},
+		{Name: "period_end", Type: "timestamp"},
+		{Name: "namespace", Type: "varchar"},
+		{Name: "pod_count", Type: "bigint"},
+		{Name: "cost", Type: "double"},
+	}
+
+	tests := map[string]struct {
+		keyColumns []string
+		keys       []string
+		values     []string
+		err        bool
+	}{
+		"non-numeric columns are keys": {
+			keys:   []string{"period_start", "period_end", "namespace"},
+			values: []string{"pod_count", "cost"},
+		},
+		"explicit keys": {
+			keyColumns: []string{"period_start", "period_end", "namespace", "pod_count"},
+			keys:       []string{"period_start", "period_end", "namespace", "pod_count"},
+			values:     []string{"cost"},
+		},
+		"non-numeric value": {
+			keyColumns: []string{"period_start", "period_end"},
+			err:        true,
+		},
+		"unknown key": {
+			keyColumns: []string{"period_start", "period_end", "namespace", "node"},
+			err:        true,
+		},
+		"no values": {
+			keyColumns: []string{"period_start", "period_end", "namespace", "pod_count", "cost"},
+			err:        true,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			keys, values, err := reportAmendmentColumns(columns, test.keyColumns)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var keyNames, valueNames []string
+			for _, col := range keys {
+				keyNames = append(keyNames, col.Name)
+			}
+			for _, col := range values {
+				valueNames = append(valueNames, col.Name)
+			}
+			assert.Equal(t, test.keys, keyNames)
+			assert.Equal(t, test.values, valueNames)
+		})
+	}
+}
+
+func TestReportAmendmentQuery(t *testing.T) {
+	const id = "0123456789abcdef0123456789abcdef"
+	amendedAt := time.Date(2019, time.February, 3, 0, 0, 0, 0, time.UTC)
+	period := reportPeriod{
+		periodStart: time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+		periodEnd:   time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC),
+	}
+	values := []presto.Column{{Name: "cost", Type: "DOUBLE"}}
+
+	assert.Equal(t,
+		`SELECT "namespace", sum("cost_original"), sum("cost_amended"), coalesce(sum("cost_amended"), 0) - coalesce(sum("cost_original"), 0), timestamp '2019-02-03 00:00:00.000', timestamp '2019-01-01 00:00:00.000', timestamp '2019-02-01 00:00:00.000', 'aws-billing,aws-usage', '0123456789abcdef0123456789abcdef' FROM (SELECT "namespace", CAST("cost" AS double) AS "cost_original", CAST(NULL AS double) AS "cost_amended" FROM (SELECT * FROM report_table) AS original UNION ALL SELECT "namespace", CAST(NULL AS double), CAST("cost" AS double) FROM (SELECT namespace, cost FROM aws) AS amended) AS results GROUP BY "namespace" HAVING (sum("cost_original") IS NULL) <> (sum("cost_amended") IS NULL) OR abs(sum("cost_amended") - sum("cost_original")) > 1E-9`,
+		reportAmendmentQuery([]presto.Column{{Name: "namespace", Type: "VARCHAR"}}, values, "SELECT * FROM report_table", "SELECT namespace, cost FROM aws", id, amendedAt, period, []string{"aws-billing", "aws-usage"}),
+	)
+	// without key columns, the results are compared as a whole.
+	assert.Equal(t,
+		`SELECT sum("cost_original"), sum("cost_amended"), coalesce(sum("cost_amended"), 0) - coalesce(sum("cost_original"), 0), timestamp '2019-02-03 00:00:00.000', timestamp '2019-01-01 00:00:00.000', timestamp '2019-02-01 00:00:00.000', 'aws-billing', '0123456789abcdef0123456789abcdef' FROM (SELECT CAST("cost" AS double) AS "cost_original", CAST(NULL AS double) AS "cost_amended" FROM (SELECT * FROM report_table) AS original UNION ALL SELECT CAST(NULL AS double), CAST("cost" AS double) FROM (SELECT sum(cost) AS cost FROM aws) AS amended) AS results HAVING (sum("cost_original") IS NULL) <> (sum("cost_amended") IS NULL) OR abs(sum("cost_amended") - sum("cost_original")) > 1E-9`,
+		reportAmendmentQuery(nil, values, "SELECT * FROM report_table", "SELECT sum(cost) AS cost FROM aws", id, amendedAt, period, []string{"aws-billing"}),
+	)
+}
+
+func TestReportAmendmentPeriods(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	queryer := mockpresto.NewMockExecQueryer(ctrl)
+
+	january := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	february := time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC)
+	march := time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC)
+	queryer.EXPECT().Query(`SELECT DISTINCT CAST(to_unixtime("period_start") AS bigint) AS period_start, CAST(to_unixtime("period_end") AS bigint) AS period_end FROM scheduled_report_invoiced WHERE "period_start" < timestamp '2019-02-15 00:00:00.000' AND "period_end" > timestamp '2019-01-15 00:00:00.000' ORDER BY 1, 2`).Return([]presto.Row{
+		{"period_start": january.Unix(), "period_end": february.Unix()},
+		{"period_start": february.Unix(), "period_end": march.Unix()},
+	}, nil)
+
+	periods, err := reportAmendmentPeriods(queryer, "scheduled_report_invoiced", lateData{start: january.AddDate(0, 0, 14), end: february.AddDate(0, 0, 14)})
+	require.NoError(t, err)
+	assert.Equal(t, []reportPeriod{
+		{periodStart: january, periodEnd: february},
+		{periodStart: february, periodEnd: march},
+	}, periods)
+}
+
+func TestReportAmendmentQueue(t *testing.T) {
+	january := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	february := time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC)
+	march := time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC)
+	key := reportAmendmentKey(dependencyKindReport, "metering", "invoiced")
+
+	queue := newReportAmendmentQueue()
+	queue.add(key, lateData{dataSources: []string{"aws-usage"}, start: february, end: march})
+	queue.add(key, lateData{dataSources: []string{"aws-billing", "aws-usage"}, start: january, end: february})
+	assert.True(t, queue.has(key))
+	assert.False(t, queue.has(reportAmendmentKey(dependencyKindScheduledReport, "metering", "invoiced")))
+
+	data, exists := queue.take(key)
+	require.True(t, exists)
+	assert.Equal(t, lateData{dataSources: []string{"aws-billing", "aws-usage"}, start: january, end: march}, data)
+	_, exists = queue.take(key)
+	assert.False(t, exists)
+}
+
+func TestValidateScheduledReportAmendments(t *testing.T) {
+	tests := map[string]struct {
+		spec cbTypes.ScheduledReportSpec
+		err  bool
+	}{
+		"disabled": {},
+		"enabled": {
+			spec: cbTypes.ScheduledReportSpec{Amendments: &cbTypes.ReportAmendments{KeyColumns: []string{"period_start", "period_end", "namespace"}}},
+		},
+		"duplicate key": {
+			spec: cbTypes.ScheduledReportSpec{Amendments: &cbTypes.ReportAmendments{KeyColumns: []string{"namespace", "namespace"}}},
+			err:  true,
+		},
+		"empty key": {
+			spec: cbTypes.ScheduledReportSpec{Amendments: &cbTypes.ReportAmendments{KeyColumns: []string{""}}},
+			err:  true,
+		},
+		"overwrites existing data": {
+			spec: cbTypes.ScheduledReportSpec{Amendments: &cbTypes.ReportAmendments{}, OverwriteExistingData: true},
+			err:  true,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			err := validateScheduledReportAmendments(test.spec)
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
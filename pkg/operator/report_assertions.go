@@ -0,0 +1,251 @@
+package operator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+func validateReportAssertions(assertions []cbTypes.ReportAssertion) error {
+	names := make(map[string]bool)
+	for i, assertion := range assertions {
+		if assertion.Name == "" {
+			return fmt.Errorf("assertions[%d]: name must be set", i)
+		}
+		if names[assertion.Name] {
+			return fmt.Errorf("assertion %s: name must be unique", assertion.Name)
+		}
+		names[assertion.Name] = true
+		if err := validateReportAssertion(assertion); err != nil {
+			return fmt.Errorf("assertion %s: %v", assertion.Name, err)
+		}
+	}
+	return nil
+}
+
+func validateReportAssertion(assertion cbTypes.ReportAssertion) error {
+	checks := 0
+	if rowCount := assertion.RowCount; rowCount != nil {
+		checks++
+		if rowCount.Min == nil && rowCount.Max == nil {
+			return fmt.Errorf("rowCount.min or rowCount.max must be set")
+		}
+		if rowCount.Min != nil && rowCount.Max != nil && *rowCount.Min > *rowCount.Max {
+			return fmt.Errorf("rowCount.min must not be more than rowCount.max")
+		}
+	}
+	if assertion.NotNull != nil {
+		checks++
+		if assertion.NotNull.Column == "" {
+			return fmt.Errorf("notNull.column must be set")
+		}
+	}
+	if sum := assertion.Sum; sum != nil {
+		checks++
+		if sum.Column == "" {
+			return fmt.Errorf("sum.column must be set")
+		}
+		if (sum.Value == nil) == (sum.Query == "") {
+			return fmt.Errorf("exactly one of sum.value or sum.query must be set")
+		}
+		if sum.Query != "" {
+			if _, err := newQueryTemplate(sum.Query); err != nil {
+				return fmt.Errorf("invalid sum.query: %v", err)
+			}
+		}
+		if sum.TolerancePercent < 0 {
+			return fmt.Errorf("sum.tolerancePercent must not be negative")
+		}
+	}
+	if checks != 1 {
+		return fmt.Errorf("exactly one of rowCount, notNull or sum must be set")
+	}
+	return nil
+}
+
+// checkReportAssertions evaluates each of the assertions against the
+// results of the run, returning the outcome of each. An assertion which
+// can't be evaluated, such as when the results can't be read, fails.
+func (op *Reporting) checkReportAssertions(logger logrus.FieldLogger, run reportRun, assertions []cbTypes.ReportAssertion) []cbTypes.ReportAssertionStatus {
+	if len(assertions) == 0 {
+		return nil
+	}
+	_, results, resultsErr := op.getDeliveryResults(run.tableName, run.generationQuery, run.groupByLabels)
+	if resultsErr != nil {
+		resultsErr = fmt.Errorf("unable to get report results: %v", resultsErr)
+	}
+
+	now := metav1.Time{Time: op.clock.Now().UTC()}
+	statuses := make([]cbTypes.ReportAssertionStatus, len(assertions))
+	for i, assertion := range assertions {
+		statuses[i] = cbTypes.ReportAssertionStatus{Name: assertion.Name, Time: now}
+		err := resultsErr
+		if err == nil {
+			statuses[i].Passed, statuses[i].Message, err = op.checkReportAssertion(run, assertion, results)
+		}
+		if err != nil {
+			statuses[i].Passed = false
+			statuses[i].Message = err.Error()
+		}
+		if !statuses[i].Passed {
+			logger.Warnf("assertion %s failed: %s", assertion.Name, statuses[i].Message)
+		}
+	}
+	return statuses
+}
+
+// checkReportAssertion returns true if the results meet the assertion,
+// along with a message describing what was checked.
+func (op *Reporting) checkReportAssertion(run reportRun, assertion cbTypes.ReportAssertion, results []presto.Row) (bool, string, error) {
+	switch {
+	case assertion.RowCount != nil:
+		rows := int64(len(results))
+		if min := assertion.RowCount.Min; min != nil && rows < *min {
+			return false, fmt.Sprintf("got %d rows, expected at least %d", rows, *min), nil
+		}
+		if max := assertion.RowCount.Max; max != nil && rows > *max {
+			return false, fmt.Sprintf("got %d rows, expected at most %d", rows, *max), nil
+		}
+		return true, fmt.Sprintf("got %d rows", rows), nil
+	case assertion.NotNull != nil:
+		column := assertion.NotNull.Column
+		nulls, err := countResultsColumnNulls(results, column)
+		if err != nil {
+			return false, "", err
+		}
+		if nulls != 0 {
+			return false, fmt.Sprintf("%d of %d rows have a NULL %s", nulls, len(results), column), nil
+		}
+		return true, fmt.Sprintf("no rows have a NULL %s", column), nil
+	case assertion.Sum != nil:
+		sum, err := sumResultsColumn(results, assertion.Sum.Column)
+		if err != nil {
+			return false, "", err
+		}
+		var expected float64
+		if assertion.Sum.Value != nil {
+			expected = *assertion.Sum.Value
+		} else {
+			expected, err = op.queryAssertionExpectedValue(run, assertion.Sum.Query)
+			if err != nil {
+				return false, "", err
+			}
+		}
+		passed, msg := checkSumWithinTolerance(assertion.Sum.Column, sum, expected, assertion.Sum.TolerancePercent)
+		return passed, msg, nil
+	default:
+		return false, "", fmt.Errorf("exactly one of rowCount, notNull or sum must be set")
+	}
+}
+
+// checkSumWithinTolerance returns true if sum is within tolerancePercent
+// percent of expected.
+func checkSumWithinTolerance(column string, sum, expected, tolerancePercent float64) (bool, string) {
+	diff := math.Abs(sum - expected)
+	var diffPercent float64
+	if expected != 0 {
+		diffPercent = diff / math.Abs(expected) * 100
+	} else if diff != 0 {
+		diffPercent = math.Inf(1)
+	}
+	msg := fmt.Sprintf("the sum of %s is %.2f, %.2f%% from the expected %.2f", column, sum, diffPercent, expected)
+	if diffPercent > tolerancePercent {
+		return false, fmt.Sprintf("%s, more than the tolerance of %g%%", msg, tolerancePercent)
+	}
+	return true, msg
+}
+
+// countResultsColumnNulls returns the number of rows of the results with a
+// NULL value in the column.
+func countResultsColumnNulls(results []presto.Row, column string) (int, error) {
+	nulls := 0
+	for _, row := range results {
+		val, ok := row[column]
+		if !ok {
+			return 0, fmt.Errorf("column %s isn't in the results", column)
+		}
+		if val == nil {
+			nulls++
+		}
+	}
+	return nulls, nil
+}
+
+// queryAssertionExpectedValue renders and runs the query of a sum
+// assertion for the period of the run, returning the single value it
+// returns.
+func (op *Reporting) queryAssertionExpectedValue(run reportRun, query string) (float64, error) {
+	tenantNamespace := op.tenantNamespace(run.namespace)
+	qr := queryRenderer{templateInfo: &templateInfo{
+		tenantNamespace: tenantNamespace,
+		Report: &reportTemplateInfo{
+			StartPeriod:     run.periodStart,
+			EndPeriod:       run.periodEnd,
+			tenantNamespace: tenantNamespace,
+		},
+	}}
+	rendered, err := qr.Render(query)
+	if err != nil {
+		return 0, fmt.Errorf("unable to render sum.query: %v", err)
+	}
+	rows, err := op.prestoQueryer.Query(rendered)
+	if err != nil {
+		return 0, fmt.Errorf("unable to run sum.query: %v", err)
+	}
+	if len(rows) != 1 || len(rows[0]) != 1 {
+		return 0, fmt.Errorf("sum.query must return a single value")
+	}
+	for _, val := range rows[0] {
+		switch v := val.(type) {
+		case float64:
+			return v, nil
+		case int64:
+			return float64(v), nil
+		case int:
+			return float64(v), nil
+		default:
+			return 0, fmt.Errorf("sum.query must return a numeric value, got %T", val)
+		}
+	}
+	return 0, nil
+}
+
+// failedReportAssertions returns the statuses of the assertions which
+// failed.
+func failedReportAssertions(statuses []cbTypes.ReportAssertionStatus) []cbTypes.ReportAssertionStatus {
+	var failed []cbTypes.ReportAssertionStatus
+	for _, status := range statuses {
+		if !status.Passed {
+			failed = append(failed, status)
+		}
+	}
+	return failed
+}
+
+// reportAssertionsCondition returns the status, reason and message of the
+// ResultsValid condition of a report whose assertions had the statuses.
+func reportAssertionsCondition(statuses []cbTypes.ReportAssertionStatus) (v1.ConditionStatus, string, string) {
+	failed := failedReportAssertions(statuses)
+	if len(failed) == 0 {
+		return v1.ConditionTrue, cbutil.AssertionsPassedReason, fmt.Sprintf("the results met all %d assertions", len(statuses))
+	}
+	return v1.ConditionFalse, cbutil.AssertionsFailedReason, fmt.Sprintf("the results failed %d of %d assertions: %s", len(failed), len(statuses), describeReportAssertions(failed))
+}
+
+// describeReportAssertions returns the name and message of each of the
+// assertion statuses.
+func describeReportAssertions(statuses []cbTypes.ReportAssertionStatus) string {
+	msgs := make([]string, len(statuses))
+	for i, status := range statuses {
+		msgs[i] = fmt.Sprintf("%s: %s", status.Name, status.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
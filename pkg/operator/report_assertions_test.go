@@ -0,0 +1,207 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	mockpresto "github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+func TestValidateReportAssertions(t *testing.T) {
+	min, max := int64(10), int64(1)
+	value := 100.0
+	tests := map[string]struct {
+		assertions []cbTypes.ReportAssertion
+		expectErr  bool
+	}{
+		"valid": {
+			assertions: []cbTypes.ReportAssertion{
+				{Name: "has-rows", RowCount: &cbTypes.ReportRowCountAssertion{Min: &max}},
+				{Name: "namespace-set", NotNull: &cbTypes.ReportNotNullAssertion{Column: "namespace"}},
+				{Name: "matches-cluster-cost", Sum: &cbTypes.ReportSumAssertion{Column: "total_cost", Query: "SELECT 100", TolerancePercent: 2}},
+			},
+		},
+		"missing name": {
+			assertions: []cbTypes.ReportAssertion{{NotNull: &cbTypes.ReportNotNullAssertion{Column: "namespace"}}},
+			expectErr:  true,
+		},
+		"duplicate name": {
+			assertions: []cbTypes.ReportAssertion{
+				{Name: "a", NotNull: &cbTypes.ReportNotNullAssertion{Column: "namespace"}},
+				{Name: "a", NotNull: &cbTypes.ReportNotNullAssertion{Column: "pod"}},
+			},
+			expectErr: true,
+		},
+		"no check": {
+			assertions: []cbTypes.ReportAssertion{{Name: "a"}},
+			expectErr:  true,
+		},
+		"multiple checks": {
+			assertions: []cbTypes.ReportAssertion{{Name: "a", RowCount: &cbTypes.ReportRowCountAssertion{Min: &max}, NotNull: &cbTypes.ReportNotNullAssertion{Column: "namespace"}}},
+			expectErr:  true,
+		},
+		"min more than max": {
+			assertions: []cbTypes.ReportAssertion{{Name: "a", RowCount: &cbTypes.ReportRowCountAssertion{Min: &min, Max: &max}}},
+			expectErr:  true,
+		},
+		"sum with value and query": {
+			assertions: []cbTypes.ReportAssertion{{Name: "a", Sum: &cbTypes.ReportSumAssertion{Column: "total_cost", Value: &value, Query: "SELECT 100"}}},
+			expectErr:  true,
+		},
+		"sum with invalid query": {
+			assertions: []cbTypes.ReportAssertion{{Name: "a", Sum: &cbTypes.ReportSumAssertion{Column: "total_cost", Query: "SELECT {| .Report"}}},
+			expectErr:  true,
+		},
+		"negative tolerance": {
+			assertions: []cbTypes.ReportAssertion{{Name: "a", Sum: &cbTypes.ReportSumAssertion{Column: "total_cost", Value: &value, TolerancePercent: -1}}},
+			expectErr:  true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateReportAssertions(test.assertions)
+			if test.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckReportAssertions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	queryer := mockpresto.NewMockExecQueryer(ctrl)
+	now := time.Date(2018, time.August, 1, 6, 0, 0, 0, time.UTC)
+	op := &Reporting{
+		clock:         clock.NewFakeClock(now),
+		prestoQueryer: queryer,
+	}
+
+	genQuery := &cbTypes.ReportGenerationQuery{
+		Spec: cbTypes.ReportGenerationQuerySpec{
+			Columns: []cbTypes.ReportGenerationQueryColumn{
+				{Name: "namespace", Type: "string"},
+				{Name: "total_cost", Type: "double"},
+			},
+		},
+	}
+	run := reportRun{
+		kind:            "Report",
+		namespace:       "metering",
+		name:            "namespace-cost",
+		tableName:       "report_namespace_cost",
+		generationQuery: genQuery,
+		periodStart:     time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC),
+		periodEnd:       time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC),
+	}
+	prestoColumns, err := generatePrestoColumns(genQuery.Spec.Columns)
+	require.NoError(t, err)
+	queryer.EXPECT().Query(presto.GenerateGetRowsSQL(run.tableName, prestoColumns)).Return([]presto.Row{
+		{"namespace": "team-a", "total_cost": 75.0},
+		{"namespace": nil, "total_cost": 26.0},
+	}, nil)
+	queryer.EXPECT().Query("SELECT sum(cost) FROM cluster_cost WHERE period_start = timestamp '2018-07-01 00:00:00.000'").Return([]presto.Row{
+		{"_col0": 100.0},
+	}, nil)
+
+	min := int64(1)
+	value := 101.0
+	assertions := []cbTypes.ReportAssertion{
+		{Name: "has-rows", RowCount: &cbTypes.ReportRowCountAssertion{Min: &min}},
+		{Name: "namespace-set", NotNull: &cbTypes.ReportNotNullAssertion{Column: "namespace"}},
+		{Name: "equals-value", Sum: &cbTypes.ReportSumAssertion{Column: "total_cost", Value: &value}},
+		{Name: "near-cluster-cost", Sum: &cbTypes.ReportSumAssertion{
+			Column:           "total_cost",
+			Query:            "SELECT sum(cost) FROM cluster_cost WHERE period_start = timestamp '{| .Report.StartPeriod | prestoTimestamp |}'",
+			TolerancePercent: 2,
+		}},
+		{Name: "missing-column", NotNull: &cbTypes.ReportNotNullAssertion{Column: "pod"}},
+	}
+	statuses := op.checkReportAssertions(logrus.New(), run, assertions)
+	ts := metav1.Time{Time: now}
+	assert.Equal(t, []cbTypes.ReportAssertionStatus{
+		{Name: "has-rows", Passed: true, Message: "got 2 rows", Time: ts},
+		{Name: "namespace-set", Passed: false, Message: "1 of 2 rows have a NULL namespace", Time: ts},
+		{Name: "equals-value", Passed: true, Message: "the sum of total_cost is 101.00, 0.00% from the expected 101.00", Time: ts},
+		{Name: "near-cluster-cost", Passed: true, Message: "the sum of total_cost is 101.00, 1.00% from the expected 100.00", Time: ts},
+		{Name: "missing-column", Passed: false, Message: "column pod isn't in the results", Time: ts},
+	}, statuses)
+
+	status, reason, msg := reportAssertionsCondition(statuses)
+	assert.Equal(t, v1.ConditionFalse, status)
+	assert.Equal(t, cbutil.AssertionsFailedReason, reason)
+	assert.Equal(t, "the results failed 2 of 5 assertions: namespace-set: 1 of 2 rows have a NULL namespace; missing-column: column pod isn't in the results", msg)
+
+	status, reason, _ = reportAssertionsCondition(statuses[:1])
+	assert.Equal(t, v1.ConditionTrue, status)
+	assert.Equal(t, cbutil.AssertionsPassedReason, reason)
+}
+
+func TestCheckSumWithinTolerance(t *testing.T) {
+	passed, _ := checkSumWithinTolerance("total_cost", 98, 100, 2)
+	assert.True(t, passed)
+	passed, msg := checkSumWithinTolerance("total_cost", 97, 100, 2)
+	assert.False(t, passed)
+	assert.Equal(t, "the sum of total_cost is 97.00, 3.00% from the expected 100.00, more than the tolerance of 2%", msg)
+	passed, _ = checkSumWithinTolerance("total_cost", 0, 0, 0)
+	assert.True(t, passed)
+	passed, _ = checkSumWithinTolerance("total_cost", 1, 0, 50)
+	assert.False(t, passed)
+}
+
+func TestSendReportAssertionFailedNotifications(t *testing.T) {
+	var payloads []reportNotificationPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload reportNotificationPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		payloads = append(payloads, payload)
+	}))
+	defer srv.Close()
+
+	now := time.Date(2018, time.August, 1, 6, 0, 0, 0, time.UTC)
+	op := &Reporting{clock: clock.NewFakeClock(now)}
+	failed := []cbTypes.ReportAssertionStatus{
+		{Name: "has-rows", Passed: false, Message: "got 0 rows, expected at least 1", Time: metav1.Time{Time: now}},
+	}
+	run := reportRun{
+		kind:             "Report",
+		namespace:        "metering",
+		name:             "namespace-cost",
+		periodStart:      time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC),
+		periodEnd:        time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC),
+		failedAssertions: failed,
+	}
+	notifications := []cbTypes.ReportNotification{
+		{Name: "defaults", Webhook: &cbTypes.WebhookNotification{URL: srv.URL}},
+		{Name: "succeeded", Events: []cbTypes.ReportNotificationEvent{cbTypes.ReportNotificationEventSucceeded}, Webhook: &cbTypes.WebhookNotification{URL: srv.URL}},
+	}
+
+	statuses := op.sendReportNotifications(context.Background(), logrus.New(), run, notifications)
+	require.Len(t, payloads, 2)
+	assert.Equal(t, cbTypes.ReportNotificationEventAssertionFailed, payloads[0].Event)
+	assert.Equal(t, "Report metering/namespace-cost finished for the period 2018-07-01 00:00:00 +0000 UTC to 2018-08-01 00:00:00 +0000 UTC, but its results failed 1 assertions: has-rows: got 0 rows, expected at least 1.", payloads[0].Message)
+	require.Len(t, payloads[0].FailedAssertions, 1)
+	assert.Equal(t, "has-rows", payloads[0].FailedAssertions[0].Name)
+	assert.Equal(t, cbTypes.ReportNotificationEventSucceeded, payloads[1].Event)
+	assert.Equal(t, []cbTypes.ReportNotificationStatus{
+		{Name: "defaults", Event: cbTypes.ReportNotificationEventAssertionFailed, Time: metav1.Time{Time: now}},
+		{Name: "succeeded", Event: cbTypes.ReportNotificationEventSucceeded, Time: metav1.Time{Time: now}},
+	}, statuses)
+}
@@ -0,0 +1,222 @@
+package operator
+
+import (
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+// reportNode is a Report or ScheduledReport in the graph of reports formed
+// by ReportGenerationQueries reading the results of other reports. It's also
+// used to identify ReportGenerationQueries and ReportDataSources when
+// determining the impact of deleting them.
+type reportNode struct {
+	kind string
+	name string
+}
+
+func (n reportNode) String() string {
+	return n.kind + "/" + n.name
+}
+
+// validateReportDependencies returns an error if the Reports and
+// ScheduledReports the generationQuery depends on, directly or indirectly,
+// depend on the report identified by kind and name, since the reports in
+// such a cycle would wait for each other forever.
+func (op *Reporting) validateReportDependencies(namespace, kind, name string, generationQuery *cbTypes.ReportGenerationQuery) error {
+	start := reportNode{kind: kind, name: name}
+	cycle, err := findReportDependencyCycle(start, func(node reportNode) ([]reportNode, error) {
+		if node == start {
+			return op.getReportNodeDependencies(generationQuery)
+		}
+		return op.getReportNodeDependenciesByName(namespace, node)
+	})
+	if err != nil {
+		return err
+	}
+	if cycle != nil {
+		nodes := make([]string, len(cycle))
+		for i, node := range cycle {
+			nodes[i] = node.String()
+		}
+		return fmt.Errorf("report dependency cycle detected: %s", strings.Join(nodes, " -> "))
+	}
+	return nil
+}
+
+// getReportNodeDependenciesByName returns the reports the report node
+// depends on through its ReportGenerationQuery. Reports or
+// ReportGenerationQueries which don't exist yet have no dependencies.
+func (op *Reporting) getReportNodeDependenciesByName(namespace string, node reportNode) ([]reportNode, error) {
+	var generationQueryName string
+	switch node.kind {
+	case dependencyKindReport:
+		report, err := op.informers.Metering().V1alpha1().Reports().Lister().Reports(namespace).Get(node.name)
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		generationQueryName = report.Spec.GenerationQueryName
+	case dependencyKindScheduledReport:
+		report, err := op.informers.Metering().V1alpha1().ScheduledReports().Lister().ScheduledReports(namespace).Get(node.name)
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		generationQueryName = report.Spec.GenerationQueryName
+	default:
+		return nil, fmt.Errorf("unknown report kind %s", node.kind)
+	}
+
+	generationQuery, err := op.informers.Metering().V1alpha1().ReportGenerationQueries().Lister().ReportGenerationQueries(namespace).Get(generationQueryName)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return op.getReportNodeDependencies(generationQuery)
+}
+
+func (op *Reporting) getReportNodeDependencies(generationQuery *cbTypes.ReportGenerationQuery) ([]reportNode, error) {
+	reports, scheduledReports, err := op.getReportDependencyNames(generationQuery)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []reportNode
+	for _, name := range reports {
+		nodes = append(nodes, reportNode{kind: dependencyKindReport, name: name})
+	}
+	for _, name := range scheduledReports {
+		nodes = append(nodes, reportNode{kind: dependencyKindScheduledReport, name: name})
+	}
+	return nodes, nil
+}
+
+// findReportDependencyCycle performs a depth first search of the report
+// dependency graph starting from start, and returns the first cycle found
+// that includes start, beginning and ending with start, or nil if there is
+// none.
+func findReportDependencyCycle(start reportNode, dependencies func(reportNode) ([]reportNode, error)) ([]reportNode, error) {
+	visited := make(map[reportNode]bool)
+	var path []reportNode
+	var visit func(node reportNode) (bool, error)
+	visit = func(node reportNode) (bool, error) {
+		path = append(path, node)
+		deps, err := dependencies(node)
+		if err != nil {
+			return false, err
+		}
+		for _, dep := range deps {
+			if dep == start {
+				path = append(path, dep)
+				return true, nil
+			}
+			if visited[dep] {
+				continue
+			}
+			visited[dep] = true
+			found, err := visit(dep)
+			if err != nil || found {
+				return found, err
+			}
+		}
+		path = path[:len(path)-1]
+		return false, nil
+	}
+
+	found, err := visit(start)
+	if err != nil || !found {
+		return nil, err
+	}
+	return path, nil
+}
+
+// notifyReportDependents is called when a Report finishes, a
+// ScheduledReport finishes a run or a ReportDataSource imports data, so that
+// the Reports and ScheduledReports waiting on it re-check their dependencies
+// immediately instead of waiting for dataSourceNotReadyRetryInterval.
+func (op *Reporting) notifyReportDependents(namespace, kind, name string) {
+	op.scheduledReportRunner.notifyDependencyUpdated()
+
+	reports, err := op.informers.Metering().V1alpha1().Reports().Lister().Reports(namespace).List(labels.Everything())
+	if err != nil {
+		op.logger.WithError(err).Errorf("unable to list reports to notify dependents of %s %s", kind, name)
+		return
+	}
+	for _, report := range reports {
+		if report.Status.Phase != cbTypes.ReportPhaseWaiting && report.Status.Phase != "" {
+			continue
+		}
+		for _, dep := range report.Status.Dependencies {
+			if dep.Kind == kind && dep.Name == name && !dep.Ready {
+				key, err := cache.MetaNamespaceKeyFunc(report)
+				if err == nil {
+					op.queues.reportQueue.Add(key)
+				}
+				break
+			}
+		}
+	}
+}
+
+// enqueueUninitializedDependents is called when a ReportDataSource has its
+// table created or a ReportGenerationQuery has its view created, and queues
+// the ReportGenerationQueries, Reports and ScheduledReports in namespace
+// which may be waiting for their dependencies to be initialized, so that
+// they don't wait for the next resync.
+func (op *Reporting) enqueueUninitializedDependents(namespace string) {
+	inf := op.informers.Metering().V1alpha1()
+	generationQueries, err := inf.ReportGenerationQueries().Lister().ReportGenerationQueries(namespace).List(labels.Everything())
+	if err != nil {
+		op.logger.WithError(err).Errorf("unable to list reportGenerationQueries waiting for their dependencies to be initialized")
+		return
+	}
+	for _, generationQuery := range generationQueries {
+		if generationQuery.ViewName != "" || generationQuery.Spec.View.Disabled {
+			continue
+		}
+		key, err := cache.MetaNamespaceKeyFunc(generationQuery)
+		if err == nil {
+			op.queues.reportGenerationQueryQueue.Add(key)
+		}
+	}
+
+	reports, err := inf.Reports().Lister().Reports(namespace).List(labels.Everything())
+	if err != nil {
+		op.logger.WithError(err).Errorf("unable to list reports waiting for their dependencies to be initialized")
+		return
+	}
+	for _, report := range reports {
+		if report.Status.Phase != cbTypes.ReportPhaseWaiting && report.Status.Phase != "" {
+			continue
+		}
+		key, err := cache.MetaNamespaceKeyFunc(report)
+		if err == nil {
+			op.queues.reportQueue.Add(key)
+		}
+	}
+
+	scheduledReports, err := inf.ScheduledReports().Lister().ScheduledReports(namespace).List(labels.Everything())
+	if err != nil {
+		op.logger.WithError(err).Errorf("unable to list scheduledReports waiting for their dependencies to be initialized")
+		return
+	}
+	for _, scheduledReport := range scheduledReports {
+		// running jobs wait for their dependencies themselves, only jobs
+		// which stopped because of uninitialized dependencies are restarted.
+		if op.scheduledReportRunner.isRunning(scheduledReport.Name) {
+			continue
+		}
+		key, err := cache.MetaNamespaceKeyFunc(scheduledReport)
+		if err == nil {
+			op.queues.scheduledReportQueue.Add(key)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/fake"
+	cbInformers "github.com/operator-framework/operator-metering/pkg/generated/informers/externalversions"
+)
+
+func TestFindReportDependencyCycle(t *testing.T) {
+	summary := reportNode{kind: dependencyKindScheduledReport, name: "summary"}
+	namespaceA := reportNode{kind: dependencyKindScheduledReport, name: "namespace-a"}
+	namespaceB := reportNode{kind: dependencyKindScheduledReport, name: "namespace-b"}
+	oneOff := reportNode{kind: dependencyKindReport, name: "one-off"}
+
+	tests := map[string]struct {
+		graph       map[reportNode][]reportNode
+		expectCycle []reportNode
+	}{
+		"no dependencies": {
+			graph: map[reportNode][]reportNode{},
+		},
+		"diamond": {
+			graph: map[reportNode][]reportNode{
+				summary:    {namespaceA, namespaceB},
+				namespaceA: {oneOff},
+				namespaceB: {oneOff},
+			},
+		},
+		"self dependency": {
+			graph: map[reportNode][]reportNode{
+				summary: {summary},
+			},
+			expectCycle: []reportNode{summary, summary},
+		},
+		"indirect cycle": {
+			graph: map[reportNode][]reportNode{
+				summary:    {namespaceA, namespaceB},
+				namespaceB: {oneOff},
+				oneOff:     {summary},
+			},
+			expectCycle: []reportNode{summary, namespaceB, oneOff, summary},
+		},
+		"cycle not including start": {
+			graph: map[reportNode][]reportNode{
+				summary:    {namespaceA},
+				namespaceA: {namespaceB},
+				namespaceB: {namespaceA},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			cycle, err := findReportDependencyCycle(summary, func(node reportNode) ([]reportNode, error) {
+				return tt.graph[node], nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectCycle, cycle)
+		})
+	}
+}
+
+func TestGetScheduledReportDependencyStatus(t *testing.T) {
+	reportEnd := time.Date(2018, time.July, 2, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		lastReportTime *time.Time
+		missing        bool
+		expectReady    bool
+	}{
+		"missing": {
+			missing: true,
+		},
+		"never ran": {},
+		"previous period": {
+			lastReportTime: timePtr(reportEnd.Add(-24 * time.Hour)),
+		},
+		"same period": {
+			lastReportTime: timePtr(reportEnd),
+			expectReady:    true,
+		},
+		"later period": {
+			lastReportTime: timePtr(reportEnd.Add(24 * time.Hour)),
+			expectReady:    true,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			var scheduledReport *cbTypes.ScheduledReport
+			if !tt.missing {
+				scheduledReport = &cbTypes.ScheduledReport{}
+				if tt.lastReportTime != nil {
+					scheduledReport.Status.LastReportTime = &meta.Time{Time: *tt.lastReportTime}
+				}
+			}
+			status := getScheduledReportDependencyStatus("upstream", scheduledReport, reportEnd)
+			assert.Equal(t, dependencyKindScheduledReport, status.Kind)
+			assert.Equal(t, "upstream", status.Name)
+			assert.Equal(t, tt.expectReady, status.Ready, status.Message)
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestEnqueueUninitializedDependents(t *testing.T) {
+	op := &Reporting{
+		logger:    testLogger,
+		informers: cbInformers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0),
+		queues: queues{
+			reportQueue:                workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+			scheduledReportQueue:       workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+			reportGenerationQueryQueue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		},
+	}
+	op.scheduledReportRunner = newScheduledReportRunner(op)
+	op.scheduledReportRunner.reports["running"] = &scheduledReportJob{}
+
+	inf := op.informers.Metering().V1alpha1()
+	objectMeta := func(name string) meta.ObjectMeta {
+		return meta.ObjectMeta{Namespace: "metering", Name: name}
+	}
+	for _, query := range []*cbTypes.ReportGenerationQuery{
+		{ObjectMeta: objectMeta("uninitialized")},
+		{ObjectMeta: objectMeta("initialized"), ViewName: "view_initialized"},
+		{ObjectMeta: objectMeta("view-disabled"), Spec: cbTypes.ReportGenerationQuerySpec{View: cbTypes.GenQueryView{Disabled: true}}},
+	} {
+		require.NoError(t, inf.ReportGenerationQueries().Informer().GetIndexer().Add(query))
+	}
+	for _, report := range []*cbTypes.Report{
+		{ObjectMeta: objectMeta("new")},
+		{ObjectMeta: objectMeta("waiting"), Status: cbTypes.ReportStatus{Phase: cbTypes.ReportPhaseWaiting}},
+		{ObjectMeta: objectMeta("finished"), Status: cbTypes.ReportStatus{Phase: cbTypes.ReportPhaseFinished}},
+		{ObjectMeta: meta.ObjectMeta{Namespace: "other", Name: "other-namespace"}},
+	} {
+		require.NoError(t, inf.Reports().Informer().GetIndexer().Add(report))
+	}
+	for _, scheduledReport := range []*cbTypes.ScheduledReport{
+		{ObjectMeta: objectMeta("stopped")},
+		{ObjectMeta: objectMeta("running")},
+	} {
+		require.NoError(t, inf.ScheduledReports().Informer().GetIndexer().Add(scheduledReport))
+	}
+
+	op.enqueueUninitializedDependents("metering")
+
+	queued := func(queue workqueue.RateLimitingInterface) []string {
+		var keys []string
+		for queue.Len() > 0 {
+			key, _ := queue.Get()
+			keys = append(keys, key.(string))
+			queue.Done(key)
+		}
+		return keys
+	}
+	assert.Equal(t, []string{"metering/uninitialized"}, queued(op.queues.reportGenerationQueryQueue))
+	assert.ElementsMatch(t, []string{"metering/new", "metering/waiting"}, queued(op.queues.reportQueue))
+	assert.Equal(t, []string{"metering/stopped"}, queued(op.queues.scheduledReportQueue))
+}
@@ -0,0 +1,176 @@
+package operator
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+const (
+	// fanOutParentLabel is set on the child Reports of a Report with fanOut
+	// set to the name of the parent Report.
+	fanOutParentLabel = "metering.openshift.io/fan-out-parent"
+	// fanOutNamespaceLabel is set on the child Reports of a Report with
+	// fanOut set to the namespace the child Report reports on.
+	fanOutNamespaceLabel = "metering.openshift.io/fan-out-namespace"
+
+	defaultFanOutNamespaceInput = "namespace"
+)
+
+func fanOutReportName(parentName, namespace string) string {
+	return fmt.Sprintf("%s-%s", parentName, namespace)
+}
+
+// handleFanOutReport creates a child Report for each namespace matching the
+// Report's namespaceSelector, and tracks the phases of the child Reports in
+// the Report's status. The Report is finished once all of its child
+// Reports are, and fails if any of them fail.
+func (op *Reporting) handleFanOutReport(logger log.FieldLogger, report *cbTypes.Report) error {
+	switch report.Status.Phase {
+	case cbTypes.ReportPhaseFinished, cbTypes.ReportPhaseError:
+		logger.Infof("ignoring fan out report %s, status: %s", report.Name, report.Status.Phase)
+		return nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(report.Spec.FanOut.NamespaceSelector)
+	if err != nil {
+		op.setReportError(logger, report, err, "report has an invalid fanOut.namespaceSelector")
+		return nil
+	}
+	namespaces, err := op.kubeClient.Namespaces().List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return fmt.Errorf("unable to list namespaces for fan out report %s: %v", report.Name, err)
+	}
+
+	children, err := op.informers.Metering().V1alpha1().Reports().Lister().Reports(report.Namespace).List(labels.SelectorFromSet(labels.Set{fanOutParentLabel: report.Name}))
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for _, child := range children {
+		existing[child.Labels[fanOutNamespaceLabel]] = true
+	}
+	for _, namespace := range namespaces.Items {
+		if existing[namespace.Name] {
+			continue
+		}
+		child := newFanOutReport(report, namespace.Name)
+		logger.Infof("creating report %s for namespace %s", child.Name, namespace.Name)
+		child, err = op.meteringClient.MeteringV1alpha1().Reports(report.Namespace).Create(child)
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("unable to create report for namespace %s: %v", namespace.Name, err)
+		}
+		if err == nil {
+			children = append(children, child)
+		}
+	}
+
+	statuses, phase := getFanOutStatus(children)
+	if phase == report.Status.Phase && reflect.DeepEqual(statuses, report.Status.FanOutReports) {
+		return nil
+	}
+	if phase != report.Status.Phase {
+		logger.Infof("fan out report is now %s", phase)
+	}
+	report.Status.Phase = phase
+	report.Status.FanOutReports = statuses
+	_, err = op.updateReport(report)
+	if err != nil {
+		logger.WithError(err).Errorf("failed to update fan out report status for %q", report.Name)
+	}
+	return err
+}
+
+// newFanOutReport returns the child Report of the parent Report for the
+// namespace, which has the parent's spec with the namespace input set to
+// the namespace.
+func newFanOutReport(parent *cbTypes.Report, namespace string) *cbTypes.Report {
+	spec := parent.Spec.DeepCopy()
+	spec.FanOut = nil
+
+	namespaceInput := parent.Spec.FanOut.NamespaceInput
+	if namespaceInput == "" {
+		namespaceInput = defaultFanOutNamespaceInput
+	}
+	var inputs []cbTypes.ReportGenerationQueryInputValue
+	for _, input := range spec.Inputs {
+		if input.Name != namespaceInput {
+			inputs = append(inputs, input)
+		}
+	}
+	spec.Inputs = append(inputs, cbTypes.ReportGenerationQueryInputValue{Name: namespaceInput, Value: namespace})
+
+	return &cbTypes.Report{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fanOutReportName(parent.Name, namespace),
+			Namespace: parent.Namespace,
+			Labels: map[string]string{
+				fanOutParentLabel:    parent.Name,
+				fanOutNamespaceLabel: namespace,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(parent, cbTypes.SchemeGroupVersion.WithKind("Report")),
+			},
+		},
+		Spec: *spec,
+	}
+}
+
+// getFanOutStatus returns the status of each child Report, sorted by
+// namespace, and the phase of their parent: Started while any child is
+// unfinished, then Error if any child failed, and Finished otherwise.
+func getFanOutStatus(children []*cbTypes.Report) ([]cbTypes.ReportFanOutStatus, cbTypes.ReportPhase) {
+	statuses := make([]cbTypes.ReportFanOutStatus, len(children))
+	var running, failed bool
+	for i, child := range children {
+		childPhase := child.Status.Phase
+		if childPhase == "" {
+			childPhase = cbTypes.ReportPhaseWaiting
+		}
+		statuses[i] = cbTypes.ReportFanOutStatus{
+			Namespace: child.Labels[fanOutNamespaceLabel],
+			Name:      child.Name,
+			Phase:     childPhase,
+		}
+		switch childPhase {
+		case cbTypes.ReportPhaseFinished:
+		case cbTypes.ReportPhaseError:
+			failed = true
+		default:
+			running = true
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Namespace < statuses[j].Namespace
+	})
+
+	switch {
+	case running:
+		return statuses, cbTypes.ReportPhaseStarted
+	case failed:
+		return statuses, cbTypes.ReportPhaseError
+	default:
+		return statuses, cbTypes.ReportPhaseFinished
+	}
+}
+
+// enqueueFanOutParent queues the parent of a fan out child Report, so the
+// parent's status is updated when its children change.
+func (op *Reporting) enqueueFanOutParent(obj interface{}) {
+	report, ok := obj.(*cbTypes.Report)
+	if !ok {
+		return
+	}
+	parent, ok := report.Labels[fanOutParentLabel]
+	if !ok {
+		return
+	}
+	op.queues.reportQueue.Add(report.Namespace + "/" + parent)
+}
@@ -0,0 +1,89 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestNewFanOutReport(t *testing.T) {
+	parent := &v1alpha1.Report{
+		ObjectMeta: meta.ObjectMeta{Name: "namespace-cpu", Namespace: "metering", UID: "parent-uid"},
+		Spec: v1alpha1.ReportSpec{
+			GenerationQueryName: "namespace-cpu-request",
+			Inputs: []v1alpha1.ReportGenerationQueryInputValue{
+				{Name: "aggregationLevel", Value: "day"},
+				{Name: "team", Value: "overridden"},
+			},
+			FanOut: &v1alpha1.ReportFanOut{
+				NamespaceSelector: &meta.LabelSelector{MatchLabels: map[string]string{"chargeback": "true"}},
+				NamespaceInput:    "team",
+			},
+		},
+	}
+
+	child := newFanOutReport(parent, "web")
+	assert.Equal(t, "namespace-cpu-web", child.Name)
+	assert.Equal(t, "metering", child.Namespace)
+	assert.Equal(t, map[string]string{fanOutParentLabel: "namespace-cpu", fanOutNamespaceLabel: "web"}, child.Labels)
+	require.Len(t, child.OwnerReferences, 1)
+	assert.Equal(t, "Report", child.OwnerReferences[0].Kind)
+	assert.Equal(t, parent.UID, child.OwnerReferences[0].UID)
+	assert.Nil(t, child.Spec.FanOut)
+	assert.Equal(t, "namespace-cpu-request", child.Spec.GenerationQueryName)
+	assert.Equal(t, []v1alpha1.ReportGenerationQueryInputValue{
+		{Name: "aggregationLevel", Value: "day"},
+		{Name: "team", Value: "web"},
+	}, child.Spec.Inputs)
+	assert.Len(t, parent.Spec.Inputs, 2, "the parent's inputs should not be modified")
+}
+
+func TestGetFanOutStatus(t *testing.T) {
+	newChild := func(namespace string, phase v1alpha1.ReportPhase) *v1alpha1.Report {
+		return &v1alpha1.Report{
+			ObjectMeta: meta.ObjectMeta{
+				Name:   fanOutReportName("parent", namespace),
+				Labels: map[string]string{fanOutNamespaceLabel: namespace},
+			},
+			Status: v1alpha1.ReportStatus{Phase: phase},
+		}
+	}
+
+	tests := map[string]struct {
+		children      []*v1alpha1.Report
+		expectedPhase v1alpha1.ReportPhase
+	}{
+		"no children": {
+			expectedPhase: v1alpha1.ReportPhaseFinished,
+		},
+		"all finished": {
+			children:      []*v1alpha1.Report{newChild("web", v1alpha1.ReportPhaseFinished), newChild("db", v1alpha1.ReportPhaseFinished)},
+			expectedPhase: v1alpha1.ReportPhaseFinished,
+		},
+		"some still running": {
+			children:      []*v1alpha1.Report{newChild("web", v1alpha1.ReportPhaseError), newChild("db", "")},
+			expectedPhase: v1alpha1.ReportPhaseStarted,
+		},
+		"one failed": {
+			children:      []*v1alpha1.Report{newChild("web", v1alpha1.ReportPhaseError), newChild("db", v1alpha1.ReportPhaseFinished)},
+			expectedPhase: v1alpha1.ReportPhaseError,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			statuses, phase := getFanOutStatus(tt.children)
+			assert.Equal(t, tt.expectedPhase, phase)
+			require.Len(t, statuses, len(tt.children))
+			for i := 1; i < len(statuses); i++ {
+				assert.True(t, statuses[i-1].Namespace < statuses[i].Namespace, "statuses should be sorted by namespace")
+			}
+		})
+	}
+}
@@ -0,0 +1,453 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	APIV1ReportsLineageEndpoint          = "/api/v1/reports/lineage"
+	APIV1ScheduledReportsLineageEndpoint = "/api/v1/scheduledreports/lineage"
+
+	// The lineage table of a report has a row for each source which fed
+	// each of its runs.
+	reportLineageRunColumn         = "report_lineage_run"
+	reportLineageRecordedAtColumn  = "report_lineage_recorded_at"
+	reportLineagePeriodStartColumn = "report_lineage_period_start"
+	reportLineagePeriodEndColumn   = "report_lineage_period_end"
+)
+
+var reportLineageHiveColumns = []hive.Column{
+	{Name: reportLineageRunColumn, Type: "string"},
+	{Name: reportLineageRecordedAtColumn, Type: "timestamp"},
+	{Name: reportLineagePeriodStartColumn, Type: "timestamp"},
+	{Name: reportLineagePeriodEndColumn, Type: "timestamp"},
+	{Name: "source_kind", Type: "string"},
+	{Name: "source_name", Type: "string"},
+	{Name: "source_table", Type: "string"},
+	{Name: "source_type", Type: "string"},
+	{Name: "prometheus_query", Type: "string"},
+	{Name: "prometheus_query_expression", Type: "string"},
+	// partitions is the JSON encoded ReportLineagePartitions.
+	{Name: "partitions", Type: "string"},
+	{Name: "data_start", Type: "timestamp"},
+	{Name: "data_end", Type: "timestamp"},
+	{Name: "data_rows", Type: "bigint"},
+	{Name: "last_import_at", Type: "timestamp"},
+}
+
+// ReportLineagePartition is a partition of a ReportDataSource's table
+// containing data for part of the reporting period of a run, such as the
+// AWS billing report delivered for a billing period.
+type ReportLineagePartition struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Location string `json:"location,omitempty"`
+}
+
+// ReportLineageSource is a ReportDataSource, Report or ScheduledReport
+// which fed a run of a report.
+type ReportLineageSource struct {
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+	Table string `json:"table"`
+	// Type is the kind of data a ReportDataSource imports, such as
+	// promsum or awsBilling.
+	Type string `json:"type,omitempty"`
+	// PrometheusQuery and PrometheusQueryExpression are the
+	// ReportPrometheusQuery a promsum ReportDataSource imports the
+	// metrics of, and its PromQL when the run happened.
+	PrometheusQuery           string                   `json:"prometheusQuery,omitempty"`
+	PrometheusQueryExpression string                   `json:"prometheusQueryExpression,omitempty"`
+	Partitions                []ReportLineagePartition `json:"partitions,omitempty"`
+	// DataStart, DataEnd and DataRows are the time range and number of
+	// rows of the data imported for the reporting period by promsum and
+	// kubernetesObjects ReportDataSources when the run happened.
+	DataStart *time.Time `json:"dataStart,omitempty"`
+	DataEnd   *time.Time `json:"dataEnd,omitempty"`
+	DataRows  *int64     `json:"dataRows,omitempty"`
+	// LastImportAt is when the most recent import of a promsum
+	// ReportDataSource finished, as of the run.
+	LastImportAt *time.Time `json:"lastImportAt,omitempty"`
+}
+
+// ReportLineageRun is the lineage recorded by a run of a report.
+type ReportLineageRun struct {
+	ID          string                `json:"id"`
+	RecordedAt  time.Time             `json:"recordedAt"`
+	PeriodStart time.Time             `json:"periodStart"`
+	PeriodEnd   time.Time             `json:"periodEnd"`
+	Sources     []ReportLineageSource `json:"sources"`
+}
+
+type ReportLineageList struct {
+	Runs []ReportLineageRun `json:"runs"`
+}
+
+func reportLineageTableName(tableName string) string {
+	return tableName + "_lineage"
+}
+
+// writeReportLineage records the sources which fed a successful run of a
+// report with lineage enabled in its lineage table. Lineage which fails to
+// be written doesn't fail the run, and the error is recorded in the
+// returned status.
+func (op *Reporting) writeReportLineage(logger log.FieldLogger, report runtime.Object, run reportRun, storage *cbTypes.StorageLocationRef) *cbTypes.ReportLineageStatus {
+	recordedAt := op.clock.Now().UTC()
+	status := &cbTypes.ReportLineageStatus{RecordedAt: metav1.Time{Time: recordedAt}}
+	id, err := newReportRunID()
+	if err == nil {
+		status.ID = id
+		status.Sources, err = op.insertReportLineage(logger, report, run, storage, id, recordedAt)
+	}
+	if err != nil {
+		status.Error = err.Error()
+		logger.WithError(err).Errorf("failed to write the report's lineage")
+		return status
+	}
+	logger.Infof("wrote lineage %s of the report's %d sources", id, status.Sources)
+	return status
+}
+
+func (op *Reporting) insertReportLineage(logger log.FieldLogger, report runtime.Object, run reportRun, storage *cbTypes.StorageLocationRef, id string, recordedAt time.Time) (int, error) {
+	sources, err := op.getReportLineageSources(run)
+	if err != nil {
+		return 0, fmt.Errorf("unable to get the sources of the report: %v", err)
+	}
+
+	tableProperties, err := op.getHiveTableProperties(logger, storage, run.kind)
+	if err != nil {
+		return 0, fmt.Errorf("storage incorrectly configured for %s: %s", run.kind, run.name)
+	}
+	lineageTableName := reportLineageTableName(run.tableName)
+	properties, err := addTableNameToLocation(*tableProperties, lineageTableName)
+	if err != nil {
+		return 0, err
+	}
+	params := hive.TableParameters{
+		Name:         lineageTableName,
+		Columns:      reportLineageHiveColumns,
+		IgnoreExists: true,
+	}
+	if err := op.createTable(logger, params, properties); err != nil {
+		return 0, err
+	}
+	resourceName := prestoTableResourceNameFromKind(run.kind, run.name) + "-lineage"
+	if err := op.createNamedPrestoTableCR(report, cbTypes.GroupName, run.kind, resourceName, params, properties, nil); err != nil {
+		return 0, fmt.Errorf("couldn't create PrestoTable resource for the lineage of %s %s: %v", run.kind, run.name, err)
+	}
+	if len(sources) == 0 {
+		return 0, nil
+	}
+
+	query, err := reportLineageQuery(id, recordedAt, run.periodStart, run.periodEnd, sources)
+	if err != nil {
+		return 0, err
+	}
+	if err := presto.InsertInto(op.prestoQueryer, lineageTableName, query); err != nil {
+		return 0, err
+	}
+	return len(sources), nil
+}
+
+// getReportLineageSources returns the ReportDataSources, Reports and
+// ScheduledReports the run's ReportGenerationQuery depends on, with the
+// Prometheus queries and importer state of promsum ReportDataSources, and
+// the partitions of AWS billing ReportDataSources overlapping the run's
+// reporting period.
+func (op *Reporting) getReportLineageSources(run reportRun) ([]ReportLineageSource, error) {
+	dataSources, err := op.getReportDataSourceDependencies(run.generationQuery)
+	if err != nil {
+		return nil, err
+	}
+	reports, scheduledReports, err := op.getReportDependencyNames(run.generationQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []ReportLineageSource
+	for _, dataSource := range dataSources {
+		source := ReportLineageSource{
+			Kind:  dependencyKindReportDataSource,
+			Name:  dataSource.Name,
+			Table: dataSource.TableName,
+		}
+		if source.Table == "" {
+			source.Table = dataSourceTableName(dataSource.Name)
+		}
+		switch {
+		case dataSource.Spec.Promsum != nil:
+			source.Type = "promsum"
+			source.PrometheusQuery = dataSource.Spec.Promsum.Query
+			query, err := op.informers.Metering().V1alpha1().ReportPrometheusQueries().Lister().ReportPrometheusQueries(dataSource.Namespace).Get(source.PrometheusQuery)
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return nil, err
+			} else if err == nil {
+				source.PrometheusQueryExpression = query.Spec.Query
+			}
+			if stats, exists := op.importerTelemetry.lastImport(dataSource.Name); exists {
+				importedAt := stats.Start.Add(stats.Duration).UTC()
+				source.LastImportAt = &importedAt
+			}
+		case dataSource.Spec.AWSBilling != nil:
+			source.Type = "awsBilling"
+			prestoTable, err := op.informers.Metering().V1alpha1().PrestoTables().Lister().PrestoTables(op.cfg.Namespace).Get(prestoTableResourceNameFromKind("reportdatasource", dataSource.Name))
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return nil, err
+			} else if err == nil {
+				source.Partitions = reportLineagePartitions(prestoTable.State.Partitions, run.periodStart, run.periodEnd)
+			}
+		case dataSource.Spec.GCPBilling != nil:
+			source.Type = "gcpBilling"
+		case dataSource.Spec.KubernetesObjects != nil:
+			source.Type = "kubernetesObjects"
+		case dataSource.Spec.KubernetesEvents != nil:
+			source.Type = "kubernetesEvents"
+		case dataSource.Spec.RemoteReport != nil:
+			source.Type = "remoteReport"
+		case dataSource.Spec.ObjectStorage != nil:
+			source.Type = "objectStorage"
+		case dataSource.Spec.Database != nil:
+			source.Type = "database"
+		}
+		sources = append(sources, source)
+	}
+	for _, name := range reports {
+		sources = append(sources, ReportLineageSource{
+			Kind:  dependencyKindReport,
+			Name:  name,
+			Table: op.namespacedReportTableName(run.namespace, name),
+		})
+	}
+	for _, name := range scheduledReports {
+		sources = append(sources, ReportLineageSource{
+			Kind:  dependencyKindScheduledReport,
+			Name:  name,
+			Table: op.namespacedScheduledReportTableName(run.namespace, name),
+		})
+	}
+	return sources, nil
+}
+
+// reportLineagePartitions returns the partitions of an AWS billing
+// ReportDataSource's table whose billing period overlaps the reporting
+// period.
+func reportLineagePartitions(partitions []cbTypes.TablePartition, periodStart, periodEnd time.Time) []ReportLineagePartition {
+	var overlapping []ReportLineagePartition
+	for _, p := range partitions {
+		start, err := time.Parse(awsUsagePartitionDateStringLayout, p.PartitionSpec["start"])
+		if err != nil {
+			continue
+		}
+		end, err := time.Parse(awsUsagePartitionDateStringLayout, p.PartitionSpec["end"])
+		if err != nil {
+			continue
+		}
+		if start.Before(periodEnd) && end.After(periodStart) {
+			overlapping = append(overlapping, ReportLineagePartition{
+				Start:    p.PartitionSpec["start"],
+				End:      p.PartitionSpec["end"],
+				Location: p.Location,
+			})
+		}
+	}
+	return overlapping
+}
+
+// reportLineageQuery returns the query selecting a row of the lineage table
+// for each source. The time range and number of rows of the data imported
+// for the reporting period are queried from the tables of promsum and
+// kubernetesObjects ReportDataSources.
+func reportLineageQuery(id string, recordedAt, periodStart, periodEnd time.Time, sources []ReportLineageSource) (string, error) {
+	selects := make([]string, len(sources))
+	for i, source := range sources {
+		partitions := ""
+		if len(source.Partitions) != 0 {
+			b, err := json.Marshal(source.Partitions)
+			if err != nil {
+				return "", err
+			}
+			partitions = string(b)
+		}
+		lastImportAt := "CAST(NULL AS timestamp)"
+		if source.LastImportAt != nil {
+			lastImportAt = fmt.Sprintf("timestamp '%s'", presto.Timestamp(*source.LastImportAt))
+		}
+		values := []string{
+			prestoString(id),
+			fmt.Sprintf("timestamp '%s'", presto.Timestamp(recordedAt)),
+			fmt.Sprintf("timestamp '%s'", presto.Timestamp(periodStart)),
+			fmt.Sprintf("timestamp '%s'", presto.Timestamp(periodEnd)),
+			prestoString(source.Kind),
+			prestoString(source.Name),
+			prestoString(source.Table),
+			prestoString(source.Type),
+			prestoString(source.PrometheusQuery),
+			prestoString(source.PrometheusQueryExpression),
+			prestoString(partitions),
+		}
+		if source.Type == "promsum" || source.Type == "kubernetesObjects" {
+			selects[i] = fmt.Sprintf(`SELECT %s, min("timestamp"), max("timestamp"), count(*), %s FROM %s WHERE "timestamp" >= timestamp '%s' AND "timestamp" < timestamp '%s'`,
+				strings.Join(values, ", "), lastImportAt, source.Table, presto.Timestamp(periodStart), presto.Timestamp(periodEnd))
+		} else {
+			selects[i] = fmt.Sprintf("SELECT %s, CAST(NULL AS timestamp), CAST(NULL AS timestamp), CAST(NULL AS bigint), %s", strings.Join(values, ", "), lastImportAt)
+		}
+	}
+	return strings.Join(selects, " UNION ALL "), nil
+}
+
+// listReportLineage returns the lineage of each run in a report's lineage
+// table, oldest first, or of the run with the ID runID if it isn't empty.
+func listReportLineage(queryer presto.Queryer, lineageTableName, runID string) ([]ReportLineageRun, error) {
+	query := fmt.Sprintf(`SELECT %s AS id, CAST(to_unixtime(%s) AS bigint) AS recorded_at, CAST(to_unixtime(%s) AS bigint) AS period_start, CAST(to_unixtime(%s) AS bigint) AS period_end, source_kind, source_name, source_table, source_type, prometheus_query, prometheus_query_expression, partitions, CAST(to_unixtime(data_start) AS bigint) AS data_start, CAST(to_unixtime(data_end) AS bigint) AS data_end, data_rows, CAST(to_unixtime(last_import_at) AS bigint) AS last_import_at FROM %s`,
+		reportLineageRunColumn, reportLineageRecordedAtColumn, reportLineagePeriodStartColumn, reportLineagePeriodEndColumn, lineageTableName)
+	if runID != "" {
+		query += fmt.Sprintf(" WHERE %s = '%s'", reportLineageRunColumn, runID)
+	}
+	rows, err := queryer.Query(query + " ORDER BY 2, 1, 5, 6")
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]ReportLineageRun, 0)
+	for _, row := range rows {
+		id, ok := row["id"].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid id, valueType: %T, value: %+v", row["id"], row["id"])
+		}
+		if len(runs) == 0 || runs[len(runs)-1].ID != id {
+			run := ReportLineageRun{ID: id}
+			for column, t := range map[string]*time.Time{"recorded_at": &run.RecordedAt, "period_start": &run.PeriodStart, "period_end": &run.PeriodEnd} {
+				secs, ok := row[column].(int64)
+				if !ok {
+					return nil, fmt.Errorf("invalid %s, valueType: %T, value: %+v", column, row[column], row[column])
+				}
+				*t = time.Unix(secs, 0).UTC()
+			}
+			runs = append(runs, run)
+		}
+
+		var source ReportLineageSource
+		for column, s := range map[string]*string{
+			"source_kind":                 &source.Kind,
+			"source_name":                 &source.Name,
+			"source_table":                &source.Table,
+			"source_type":                 &source.Type,
+			"prometheus_query":            &source.PrometheusQuery,
+			"prometheus_query_expression": &source.PrometheusQueryExpression,
+		} {
+			if *s, ok = row[column].(string); !ok {
+				return nil, fmt.Errorf("invalid %s, valueType: %T, value: %+v", column, row[column], row[column])
+			}
+		}
+		if partitions, _ := row["partitions"].(string); partitions != "" {
+			if err := json.Unmarshal([]byte(partitions), &source.Partitions); err != nil {
+				return nil, fmt.Errorf("invalid partitions: %v", err)
+			}
+		}
+		// the data and import columns are null unless they were recorded.
+		for column, t := range map[string]**time.Time{"data_start": &source.DataStart, "data_end": &source.DataEnd, "last_import_at": &source.LastImportAt} {
+			if secs, ok := row[column].(int64); ok {
+				value := time.Unix(secs, 0).UTC()
+				*t = &value
+			}
+		}
+		if dataRows, ok := row["data_rows"].(int64); ok {
+			source.DataRows = &dataRows
+		}
+		runs[len(runs)-1].Sources = append(runs[len(runs)-1].Sources, source)
+	}
+	return runs, nil
+}
+
+// getReportLineageTable returns the name of the lineage table of the Report
+// or ScheduledReport, and its latest lineage, which is nil if it has never
+// written any.
+func getReportLineageTable(listers meteringListers, kind, name string) (string, *cbTypes.ReportLineageStatus, error) {
+	var (
+		tableName string
+		latest    *cbTypes.ReportLineageStatus
+	)
+	switch kind {
+	case dependencyKindReport:
+		report, err := listers.reports.Get(name)
+		if err != nil {
+			return "", nil, err
+		}
+		tableName, latest = reportTableName(name), report.Status.LatestLineage
+	case dependencyKindScheduledReport:
+		report, err := listers.scheduledReports.Get(name)
+		if err != nil {
+			return "", nil, err
+		}
+		tableName, latest = scheduledReportTableName(name), report.Status.LatestLineage
+	default:
+		return "", nil, fmt.Errorf("invalid report kind: %s", kind)
+	}
+	return reportLineageTableName(tenantTableName(listers.tenantNamespace, tableName)), latest, nil
+}
+
+func (srv *server) listReportLineageHandler(w http.ResponseWriter, r *http.Request) {
+	srv.listReportLineage(dependencyKindReport, w, r)
+}
+
+func (srv *server) listScheduledReportLineageHandler(w http.ResponseWriter, r *http.Request) {
+	srv.listReportLineage(dependencyKindScheduledReport, w, r)
+}
+
+func (srv *server) listReportLineage(kind string, w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	if err := r.ParseForm(); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "couldn't parse URL query params: %v", err)
+		return
+	}
+	if err := checkForFields([]string{"name"}, r.Form); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
+	name, runID := r.FormValue("name"), r.FormValue("run")
+	// run IDs are generated the same way as version IDs.
+	if runID != "" && !reportVersionIDRegexp.MatchString(runID) {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "invalid run %q", runID)
+		return
+	}
+	listers, err := srv.listersFor(r)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
+	lineageTableName, latest, err := getReportLineageTable(listers, kind, name)
+	if k8serrors.IsNotFound(err) {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "%s %s does not exist", kind, name)
+		return
+	} else if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error getting %s: %v", kind, err)
+		return
+	}
+	runs := []ReportLineageRun{}
+	if latest != nil {
+		runs, err = listReportLineage(srv.queryer, lineageTableName, runID)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to list the lineage of %s %s", kind, name)
+			writeErrorResponse(logger, w, r, http.StatusInternalServerError, "failed to list the lineage of %s %s: %v", kind, name, err)
+			return
+		}
+	}
+	if runID != "" && len(runs) == 0 {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "%s %s has no lineage for run %s", kind, name, runID)
+		return
+	}
+	writeResponseAsJSON(logger, w, http.StatusOK, ReportLineageList{Runs: runs})
+}
@@ -0,0 +1,187 @@
+package operator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	mockpresto "github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+func TestReportLineageQuery(t *testing.T) {
+	const id = "0123456789abcdef0123456789abcdef"
+	recordedAt := time.Date(2019, time.February, 1, 6, 0, 0, 0, time.UTC)
+	start := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC)
+	importedAt := time.Date(2019, time.February, 1, 5, 0, 0, 0, time.UTC)
+
+	query, err := reportLineageQuery(id, recordedAt, start, end, []ReportLineageSource{
+		{
+			Kind:                      dependencyKindReportDataSource,
+			Name:                      "pod-request-cpu-cores",
+			Table:                     "datasource_pod_request_cpu_cores",
+			Type:                      "promsum",
+			PrometheusQuery:           "pod-request-cpu-cores",
+			PrometheusQueryExpression: `kube_pod_container_resource_requests{resource='cpu'}`,
+			LastImportAt:              &importedAt,
+		},
+		{
+			Kind:       dependencyKindReportDataSource,
+			Name:       "aws-billing",
+			Table:      "datasource_aws_billing",
+			Type:       "awsBilling",
+			Partitions: []ReportLineagePartition{{Start: "20190101", End: "20190201", Location: "s3a://bucket/reports/20190101-20190201/assembly"}},
+		},
+		{
+			Kind:  dependencyKindScheduledReport,
+			Name:  "namespace-cpu-usage-hourly",
+			Table: "scheduled_report_namespace_cpu_usage_hourly",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t,
+		`SELECT '0123456789abcdef0123456789abcdef', timestamp '2019-02-01 06:00:00.000', timestamp '2019-01-01 00:00:00.000', timestamp '2019-02-01 00:00:00.000', 'ReportDataSource', 'pod-request-cpu-cores', 'datasource_pod_request_cpu_cores', 'promsum', 'pod-request-cpu-cores', 'kube_pod_container_resource_requests{resource=''cpu''}', '', min("timestamp"), max("timestamp"), count(*), timestamp '2019-02-01 05:00:00.000' FROM datasource_pod_request_cpu_cores WHERE "timestamp" >= timestamp '2019-01-01 00:00:00.000' AND "timestamp" < timestamp '2019-02-01 00:00:00.000'`+
+			` UNION ALL SELECT '0123456789abcdef0123456789abcdef', timestamp '2019-02-01 06:00:00.000', timestamp '2019-01-01 00:00:00.000', timestamp '2019-02-01 00:00:00.000', 'ReportDataSource', 'aws-billing', 'datasource_aws_billing', 'awsBilling', '', '', '[{"start":"20190101","end":"20190201","location":"s3a://bucket/reports/20190101-20190201/assembly"}]', CAST(NULL AS timestamp), CAST(NULL AS timestamp), CAST(NULL AS bigint), CAST(NULL AS timestamp)`+
+			` UNION ALL SELECT '0123456789abcdef0123456789abcdef', timestamp '2019-02-01 06:00:00.000', timestamp '2019-01-01 00:00:00.000', timestamp '2019-02-01 00:00:00.000', 'ScheduledReport', 'namespace-cpu-usage-hourly', 'scheduled_report_namespace_cpu_usage_hourly', '', '', '', '', CAST(NULL AS timestamp), CAST(NULL AS timestamp), CAST(NULL AS bigint), CAST(NULL AS timestamp)`,
+		query,
+	)
+}
+
+func TestReportLineagePartitions(t *testing.T) {
+	partitions := []cbTypes.TablePartition{
+		{PartitionSpec: presto.PartitionSpec{"start": "20181201", "end": "20190101"}, Location: "s3a://bucket/december"},
+		{PartitionSpec: presto.PartitionSpec{"start": "20190101", "end": "20190201"}, Location: "s3a://bucket/january"},
+		{PartitionSpec: presto.PartitionSpec{"start": "20190201", "end": "20190301"}, Location: "s3a://bucket/february"},
+	}
+	start := time.Date(2019, time.January, 15, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2019, time.February, 15, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []ReportLineagePartition{
+		{Start: "20190101", End: "20190201", Location: "s3a://bucket/january"},
+		{Start: "20190201", End: "20190301", Location: "s3a://bucket/february"},
+	}, reportLineagePartitions(partitions, start, end))
+}
+
+func TestListScheduledReportLineage(t *testing.T) {
+	const namespace = "metering"
+	const id = "0123456789abcdef0123456789abcdef"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	queryer := mockpresto.NewMockExecQueryer(ctrl)
+
+	indexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(&cbTypes.ScheduledReport{
+		ObjectMeta: meta.ObjectMeta{Name: "invoiced", Namespace: namespace},
+		Spec:       cbTypes.ScheduledReportSpec{Lineage: true},
+		Status: cbTypes.ScheduledReportStatus{
+			LatestLineage: &cbTypes.ReportLineageStatus{ID: id, Sources: 2},
+		},
+	})
+	indexer.Add(&cbTypes.ScheduledReport{
+		ObjectMeta: meta.ObjectMeta{Name: "new", Namespace: namespace},
+		Spec:       cbTypes.ScheduledReportSpec{Lineage: true},
+	})
+	reportListers := meteringListers{scheduledReports: listers.NewScheduledReportLister(indexer).ScheduledReports(namespace)}
+	router := newRouter(testLogger, routerConfig{
+		rand:            testRand,
+		queryer:         queryer,
+		importerQueryer: queryer,
+		collectorFunc:   noopPrometheusImporterFunc,
+		listers:         reportListers,
+	})
+
+	january := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	february := time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC)
+	const listQuery = `SELECT report_lineage_run AS id, CAST(to_unixtime(report_lineage_recorded_at) AS bigint) AS recorded_at, CAST(to_unixtime(report_lineage_period_start) AS bigint) AS period_start, CAST(to_unixtime(report_lineage_period_end) AS bigint) AS period_end, source_kind, source_name, source_table, source_type, prometheus_query, prometheus_query_expression, partitions, CAST(to_unixtime(data_start) AS bigint) AS data_start, CAST(to_unixtime(data_end) AS bigint) AS data_end, data_rows, CAST(to_unixtime(last_import_at) AS bigint) AS last_import_at FROM scheduled_report_invoiced_lineage`
+	queryer.EXPECT().Query(listQuery+" WHERE report_lineage_run = '"+id+"' ORDER BY 2, 1, 5, 6").Return([]presto.Row{
+		{
+			"id": id, "recorded_at": february.Unix(), "period_start": january.Unix(), "period_end": february.Unix(),
+			"source_kind": "ReportDataSource", "source_name": "aws-billing", "source_table": "datasource_aws_billing", "source_type": "awsBilling",
+			"prometheus_query": "", "prometheus_query_expression": "", "partitions": `[{"start":"20190101","end":"20190201","location":"s3a://bucket/january"}]`,
+			"data_start": nil, "data_end": nil, "data_rows": nil, "last_import_at": nil,
+		},
+		{
+			"id": id, "recorded_at": february.Unix(), "period_start": january.Unix(), "period_end": february.Unix(),
+			"source_kind": "ReportDataSource", "source_name": "pod-request-cpu-cores", "source_table": "datasource_pod_request_cpu_cores", "source_type": "promsum",
+			"prometheus_query": "pod-request-cpu-cores", "prometheus_query_expression": "kube_pod_container_resource_requests", "partitions": "",
+			"data_start": january.Unix(), "data_end": february.Add(-time.Minute).Unix(), "data_rows": int64(44640), "last_import_at": february.Unix(),
+		},
+	}, nil)
+	queryer.EXPECT().Query(listQuery+" WHERE report_lineage_run = '00000000000000000000000000000001' ORDER BY 2, 1, 5, 6").Return(nil, nil)
+
+	dataEnd := february.Add(-time.Minute)
+	dataRows := int64(44640)
+	tests := map[string]struct {
+		name, run string
+		code      int
+		runs      []ReportLineageRun
+	}{
+		"run": {
+			name: "invoiced",
+			run:  id,
+			code: http.StatusOK,
+			runs: []ReportLineageRun{{
+				ID: id, RecordedAt: february, PeriodStart: january, PeriodEnd: february,
+				Sources: []ReportLineageSource{
+					{
+						Kind: "ReportDataSource", Name: "aws-billing", Table: "datasource_aws_billing", Type: "awsBilling",
+						Partitions: []ReportLineagePartition{{Start: "20190101", End: "20190201", Location: "s3a://bucket/january"}},
+					},
+					{
+						Kind: "ReportDataSource", Name: "pod-request-cpu-cores", Table: "datasource_pod_request_cpu_cores", Type: "promsum",
+						PrometheusQuery: "pod-request-cpu-cores", PrometheusQueryExpression: "kube_pod_container_resource_requests",
+						DataStart: &january, DataEnd: &dataEnd, DataRows: &dataRows, LastImportAt: &february,
+					},
+				},
+			}},
+		},
+		"missing run": {
+			name: "invoiced",
+			run:  "00000000000000000000000000000001",
+			code: http.StatusNotFound,
+		},
+		"invalid run": {
+			name: "invoiced",
+			run:  "1' OR '1'='1",
+			code: http.StatusBadRequest,
+		},
+		"no lineage yet": {
+			name: "new",
+			code: http.StatusOK,
+			runs: []ReportLineageRun{},
+		},
+		"missing": {
+			name: "missing",
+			code: http.StatusNotFound,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", APIV1ScheduledReportsLineageEndpoint, nil)
+			r.Form = map[string][]string{"name": {test.name}}
+			if test.run != "" {
+				r.Form["run"] = []string{test.run}
+			}
+			router.ServeHTTP(w, r)
+			require.Equal(t, test.code, w.Code, w.Body.String())
+			if test.code != http.StatusOK {
+				return
+			}
+			var resp ReportLineageList
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, test.runs, resp.Runs)
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package operator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/sirupsen/logrus"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	defaultReportMetricPrefix      = "metering_report_"
+	reportMetricsDroppedSeriesName = "metering_report_metrics_dropped_series"
+	// reportMetricsPeriodEndColumn is the column used to select the rows of
+	// the most recent period, since a ScheduledReport's table contains the
+	// results of every period.
+	reportMetricsPeriodEndColumn = "period_end"
+)
+
+var (
+	// reportMetricsLabels are the labels identifying the report every
+	// report gauge has.
+	reportMetricsLabels = []string{"report_kind", "report_namespace", "report"}
+
+	reportMetricsDroppedSeriesDesc = prometheus.NewDesc(
+		reportMetricsDroppedSeriesName,
+		"The number of series of a report's gauges which weren't exported because they exceeded its maxSeries.",
+		reportMetricsLabels, nil,
+	)
+
+	reportResultMetrics = newReportMetricsCollector()
+)
+
+func init() {
+	prometheus.MustRegister(reportResultMetrics)
+}
+
+type reportMetricsKey struct {
+	kind, namespace, name string
+}
+
+// reportMetricsExport is the metrics exported for a single report.
+type reportMetricsExport struct {
+	// labelNames are the label names of each of the report's gauges,
+	// keyed by the gauge's name.
+	labelNames map[string][]string
+	metrics    []prometheus.Metric
+}
+
+// reportMetricsCollector exports the gauges of each report with metrics set.
+// The gauges' labels depend on each report's results, so they're collected
+// as const metrics rather than being registered individually.
+type reportMetricsCollector struct {
+	mu      sync.RWMutex
+	reports map[reportMetricsKey]reportMetricsExport
+}
+
+func newReportMetricsCollector() *reportMetricsCollector {
+	return &reportMetricsCollector{
+		reports: make(map[reportMetricsKey]reportMetricsExport),
+	}
+}
+
+func (c *reportMetricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- reportMetricsDroppedSeriesDesc
+}
+
+func (c *reportMetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, export := range c.reports {
+		for _, metric := range export.metrics {
+			ch <- metric
+		}
+	}
+}
+
+// set replaces the metrics exported for the report. Every series of a gauge
+// must have the same labels, so it fails if one of the report's gauges is
+// already exported by another report with different labels.
+func (c *reportMetricsCollector) set(key reportMetricsKey, export reportMetricsExport) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for otherKey, other := range c.reports {
+		if otherKey == key {
+			continue
+		}
+		for name, labelNames := range export.labelNames {
+			otherLabelNames, exists := other.labelNames[name]
+			if exists && strings.Join(labelNames, ",") != strings.Join(otherLabelNames, ",") {
+				return fmt.Errorf("gauge %s is already exported by %s %s/%s with the labels %v", name, otherKey.kind, otherKey.namespace, otherKey.name, otherLabelNames)
+			}
+		}
+	}
+	c.reports[key] = export
+	return nil
+}
+
+func (c *reportMetricsCollector) delete(key reportMetricsKey) {
+	c.mu.Lock()
+	delete(c.reports, key)
+	c.mu.Unlock()
+}
+
+func (c *reportMetricsCollector) has(key reportMetricsKey) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, exists := c.reports[key]
+	return exists
+}
+
+func validateReportMetrics(metrics *cbTypes.ReportMetrics) error {
+	if metrics == nil {
+		return nil
+	}
+	if len(metrics.Gauges) == 0 {
+		return fmt.Errorf("metrics must have at least one gauge")
+	}
+	if metrics.MaxSeries != nil && *metrics.MaxSeries <= 0 {
+		return fmt.Errorf("metrics maxSeries must be greater than 0, got %d", *metrics.MaxSeries)
+	}
+	names := make(map[string]struct{})
+	for _, gauge := range metrics.Gauges {
+		if gauge.Column == "" {
+			return fmt.Errorf("metrics gauges must have a column")
+		}
+		name := reportMetricGaugeName(gauge)
+		if !model.IsValidMetricName(model.LabelValue(name)) {
+			return fmt.Errorf("invalid gauge name %q", name)
+		}
+		if name == reportMetricsDroppedSeriesName {
+			return fmt.Errorf("gauge name %s is reserved", name)
+		}
+		if _, exists := names[name]; exists {
+			return fmt.Errorf("duplicate gauge name %s", name)
+		}
+		names[name] = struct{}{}
+	}
+	labels := make(map[string]struct{})
+	for _, label := range reportMetricsLabels {
+		labels[label] = struct{}{}
+	}
+	for _, label := range metrics.Labels {
+		if label.Column == "" {
+			return fmt.Errorf("metrics labels must have a column")
+		}
+		name := reportMetricLabelName(label)
+		if !model.LabelName(name).IsValid() || strings.HasPrefix(name, model.ReservedLabelPrefix) {
+			return fmt.Errorf("invalid label name %q", name)
+		}
+		if _, exists := labels[name]; exists {
+			return fmt.Errorf("duplicate or reserved label name %s", name)
+		}
+		labels[name] = struct{}{}
+	}
+	return nil
+}
+
+func reportMetricGaugeName(gauge cbTypes.ReportMetricGauge) string {
+	if gauge.Name != "" {
+		return gauge.Name
+	}
+	return defaultReportMetricPrefix + gauge.Column
+}
+
+func reportMetricLabelName(label cbTypes.ReportMetricLabel) string {
+	if label.Label != "" {
+		return label.Label
+	}
+	return label.Column
+}
+
+// reportMetricSeries is a single series of a gauge, the sum of the rows with
+// the same label values.
+type reportMetricSeries struct {
+	labelValues []string
+	value       float64
+}
+
+// newReportMetricsExport builds the gauges of a report from its results. Only
+// the rows of the most recent period are used if the results have a
+// period_end column.
+func newReportMetricsExport(key reportMetricsKey, metrics *cbTypes.ReportMetrics, maxSeries int, columns []cbTypes.ReportGenerationQueryColumn, results []presto.Row) (reportMetricsExport, error) {
+	if metrics.MaxSeries != nil && *metrics.MaxSeries < maxSeries {
+		maxSeries = *metrics.MaxSeries
+	}
+	columnNames := make(map[string]struct{}, len(columns))
+	for _, column := range columns {
+		columnNames[column.Name] = struct{}{}
+	}
+	labelNames := append([]string(nil), reportMetricsLabels...)
+	for _, label := range metrics.Labels {
+		if _, exists := columnNames[label.Column]; !exists {
+			return reportMetricsExport{}, fmt.Errorf("label column %s isn't a column of the results", label.Column)
+		}
+		labelNames = append(labelNames, reportMetricLabelName(label))
+	}
+	if _, exists := columnNames[reportMetricsPeriodEndColumn]; exists {
+		results = latestPeriodResults(results)
+	}
+
+	export := reportMetricsExport{labelNames: make(map[string][]string)}
+	dropped := 0
+	for _, gauge := range metrics.Gauges {
+		if _, exists := columnNames[gauge.Column]; !exists {
+			return reportMetricsExport{}, fmt.Errorf("gauge column %s isn't a column of the results", gauge.Column)
+		}
+		name := reportMetricGaugeName(gauge)
+		desc := prometheus.NewDesc(name, fmt.Sprintf("The sum of the %s column of the most recent results of a report.", gauge.Column), labelNames, nil)
+
+		seriesByLabels := make(map[string]*reportMetricSeries)
+		for _, row := range results {
+			value, ok := toFloat64(row[gauge.Column])
+			if !ok {
+				continue
+			}
+			labelValues := []string{key.kind, key.namespace, key.name}
+			for _, label := range metrics.Labels {
+				labelValues = append(labelValues, reportMetricLabelValue(row[label.Column]))
+			}
+			seriesKey := strings.Join(labelValues, "\xff")
+			if series, exists := seriesByLabels[seriesKey]; exists {
+				series.value += value
+			} else {
+				seriesByLabels[seriesKey] = &reportMetricSeries{labelValues: labelValues, value: value}
+			}
+		}
+		series := make([]*reportMetricSeries, 0, len(seriesByLabels))
+		for _, s := range seriesByLabels {
+			series = append(series, s)
+		}
+		// keep the series with the largest values, which are the ones most
+		// likely to be alerted on.
+		sort.Slice(series, func(i, j int) bool {
+			if series[i].value != series[j].value {
+				return series[i].value > series[j].value
+			}
+			return strings.Join(series[i].labelValues, "\xff") < strings.Join(series[j].labelValues, "\xff")
+		})
+		if len(series) > maxSeries {
+			dropped += len(series) - maxSeries
+			series = series[:maxSeries]
+		}
+		for _, s := range series {
+			metric, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, s.value, s.labelValues...)
+			if err != nil {
+				return reportMetricsExport{}, err
+			}
+			export.metrics = append(export.metrics, metric)
+		}
+		export.labelNames[name] = labelNames
+	}
+	droppedMetric, err := prometheus.NewConstMetric(reportMetricsDroppedSeriesDesc, prometheus.GaugeValue, float64(dropped), key.kind, key.namespace, key.name)
+	if err != nil {
+		return reportMetricsExport{}, err
+	}
+	export.metrics = append(export.metrics, droppedMetric)
+	return export, nil
+}
+
+// latestPeriodResults returns the rows whose period_end is the latest.
+func latestPeriodResults(results []presto.Row) []presto.Row {
+	var latest time.Time
+	for _, row := range results {
+		if periodEnd, ok := row[reportMetricsPeriodEndColumn].(time.Time); ok && periodEnd.After(latest) {
+			latest = periodEnd
+		}
+	}
+	var filtered []presto.Row
+	for _, row := range results {
+		if periodEnd, ok := row[reportMetricsPeriodEndColumn].(time.Time); ok && periodEnd.Equal(latest) {
+			filtered = append(filtered, row)
+		}
+	}
+	return filtered
+}
+
+func reportMetricLabelValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// exportReportMetrics replaces the gauges exported for the report with the
+// results in its table, or stops exporting them if metrics isn't set. Like
+// deliveries, failing to export the results is logged rather than failing
+// the report.
+func (op *Reporting) exportReportMetrics(logger logrus.FieldLogger, kind, namespace, name, tableName string, generationQuery *cbTypes.ReportGenerationQuery, groupByLabelKeys []string, metrics *cbTypes.ReportMetrics) {
+	key := reportMetricsKey{kind: kind, namespace: namespace, name: name}
+	if metrics == nil {
+		op.reportMetrics.delete(key)
+		return
+	}
+	columns, results, err := op.getDeliveryResults(tableName, generationQuery, groupByLabelKeys)
+	if err == nil {
+		var export reportMetricsExport
+		export, err = newReportMetricsExport(key, metrics, op.cfg.ReportMetricsMaxSeries, columns, results)
+		if err == nil {
+			err = op.reportMetrics.set(key, export)
+		}
+	}
+	if err != nil {
+		logger.WithError(err).Errorf("failed to export report metrics")
+		return
+	}
+	logger.Infof("exporting report metrics from %d report results", len(results))
+}
@@ -0,0 +1,149 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+func TestValidateReportMetrics(t *testing.T) {
+	zero := 0
+	tests := map[string]struct {
+		metrics *cbTypes.ReportMetrics
+		wantErr bool
+	}{
+		"unset": {},
+		"gauges and labels": {
+			metrics: &cbTypes.ReportMetrics{
+				Gauges: []cbTypes.ReportMetricGauge{{Column: "total_cost"}, {Column: "cpu_cost", Name: "namespace_cpu_cost"}},
+				Labels: []cbTypes.ReportMetricLabel{{Column: "namespace"}, {Column: "label_team", Label: "team"}},
+			},
+		},
+		"no gauges": {
+			metrics: &cbTypes.ReportMetrics{},
+			wantErr: true,
+		},
+		"invalid gauge name": {
+			metrics: &cbTypes.ReportMetrics{Gauges: []cbTypes.ReportMetricGauge{{Column: "total_cost", Name: "total-cost"}}},
+			wantErr: true,
+		},
+		"duplicate gauge name": {
+			metrics: &cbTypes.ReportMetrics{Gauges: []cbTypes.ReportMetricGauge{{Column: "total_cost"}, {Column: "cost", Name: "metering_report_total_cost"}}},
+			wantErr: true,
+		},
+		"reserved label": {
+			metrics: &cbTypes.ReportMetrics{
+				Gauges: []cbTypes.ReportMetricGauge{{Column: "total_cost"}},
+				Labels: []cbTypes.ReportMetricLabel{{Column: "name", Label: "report"}},
+			},
+			wantErr: true,
+		},
+		"invalid maxSeries": {
+			metrics: &cbTypes.ReportMetrics{Gauges: []cbTypes.ReportMetricGauge{{Column: "total_cost"}}, MaxSeries: &zero},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateReportMetrics(tt.metrics)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// gatherReportMetrics returns the value of each series of each gauge, keyed
+// by the gauge's name and the series' labels.
+func gatherReportMetrics(t *testing.T, collector *reportMetricsCollector) map[string]map[string]float64 {
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(collector))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	gathered := make(map[string]map[string]float64)
+	for _, family := range families {
+		series := make(map[string]float64)
+		for _, metric := range family.Metric {
+			series[labelPairsString(metric.Label)] = metric.Gauge.GetValue()
+		}
+		gathered[family.GetName()] = series
+	}
+	return gathered
+}
+
+func labelPairsString(pairs []*dto.LabelPair) string {
+	var s string
+	for i, pair := range pairs {
+		if i != 0 {
+			s += ","
+		}
+		s += pair.GetName() + "=" + pair.GetValue()
+	}
+	return s
+}
+
+func TestNewReportMetricsExport(t *testing.T) {
+	previousPeriod := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	latestPeriod := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	columns := []cbTypes.ReportGenerationQueryColumn{
+		{Name: "period_end", Type: "timestamp"},
+		{Name: "namespace", Type: "string"},
+		{Name: "pod", Type: "string"},
+		{Name: "total_cost", Type: "double"},
+	}
+	results := []presto.Row{
+		{"period_end": previousPeriod, "namespace": "team-a", "pod": "a-1", "total_cost": 500.0},
+		{"period_end": latestPeriod, "namespace": "team-a", "pod": "a-1", "total_cost": 10.0},
+		{"period_end": latestPeriod, "namespace": "team-a", "pod": "a-2", "total_cost": 15.0},
+		{"period_end": latestPeriod, "namespace": "team-b", "pod": "b-1", "total_cost": 30.0},
+		{"period_end": latestPeriod, "namespace": "team-c", "pod": "c-1", "total_cost": 5.0},
+		{"period_end": latestPeriod, "namespace": "team-d", "pod": "d-1", "total_cost": nil},
+	}
+	maxSeries := 2
+	metrics := &cbTypes.ReportMetrics{
+		Gauges:    []cbTypes.ReportMetricGauge{{Column: "total_cost"}},
+		Labels:    []cbTypes.ReportMetricLabel{{Column: "namespace", Label: "exported_namespace"}},
+		MaxSeries: &maxSeries,
+	}
+
+	key := reportMetricsKey{kind: "ScheduledReport", namespace: "metering", name: "namespace-cost"}
+	export, err := newReportMetricsExport(key, metrics, 1000, columns, results)
+	require.NoError(t, err)
+	collector := newReportMetricsCollector()
+	require.NoError(t, collector.set(key, export))
+	assert.True(t, collector.has(key))
+
+	assert.Equal(t, map[string]map[string]float64{
+		"metering_report_total_cost": {
+			"exported_namespace=team-b,report=namespace-cost,report_kind=ScheduledReport,report_namespace=metering": 30,
+			"exported_namespace=team-a,report=namespace-cost,report_kind=ScheduledReport,report_namespace=metering": 25,
+		},
+		"metering_report_metrics_dropped_series": {
+			"report=namespace-cost,report_kind=ScheduledReport,report_namespace=metering": 1,
+		},
+	}, gatherReportMetrics(t, collector), "expected only the largest series of the latest period to be exported")
+
+	otherKey := reportMetricsKey{kind: "Report", namespace: "metering", name: "pod-cost"}
+	otherExport, err := newReportMetricsExport(otherKey, &cbTypes.ReportMetrics{
+		Gauges: []cbTypes.ReportMetricGauge{{Column: "total_cost"}},
+		Labels: []cbTypes.ReportMetricLabel{{Column: "pod"}},
+	}, 1000, columns, results)
+	require.NoError(t, err)
+	assert.Error(t, collector.set(otherKey, otherExport), "expected an error exporting a gauge with different labels")
+
+	collector.delete(key)
+	assert.False(t, collector.has(key))
+	require.NoError(t, collector.set(otherKey, otherExport))
+
+	_, err = newReportMetricsExport(key, &cbTypes.ReportMetrics{Gauges: []cbTypes.ReportMetricGauge{{Column: "cost"}}}, 1000, columns, results)
+	assert.Error(t, err, "expected an error exporting a column which doesn't exist")
+}
@@ -0,0 +1,407 @@
+package operator
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi"
+	log "github.com/sirupsen/logrus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	APIV1ReportRunsEndpoint = "/api/v1/reportruns"
+
+	// maxActiveReportRuns is how many report runs can be running at once.
+	maxActiveReportRuns = 5
+	// maxReportRunRows is the most rows a report run can return, as the
+	// results are held in memory.
+	maxReportRunRows = 100000
+	// maxReportRunDuration is how long a report run can take before its
+	// query is cancelled.
+	maxReportRunDuration = time.Hour
+	// reportRunTTL is how long a report run and its results are kept after
+	// it finishes.
+	reportRunTTL = time.Hour
+)
+
+// reportRunQueryFunc renders the query of a report run for the Report,
+// returning the query and the columns of its results.
+type reportRunQueryFunc func(logger log.FieldLogger, report *cbTypes.Report) (string, []cbTypes.ReportGenerationQueryColumn, error)
+
+// ReportRunRequest starts an ad-hoc run of a ReportGenerationQuery, which
+// returns its results without creating a Report.
+type ReportRunRequest struct {
+	GenerationQuery string                                    `json:"generationQuery"`
+	ReportingStart  time.Time                                 `json:"reportingStart"`
+	ReportingEnd    time.Time                                 `json:"reportingEnd"`
+	Timezone        string                                    `json:"timezone,omitempty"`
+	Inputs          []cbTypes.ReportGenerationQueryInputValue `json:"inputs,omitempty"`
+	GroupByLabels   []string                                  `json:"groupByLabels,omitempty"`
+}
+
+type ReportRunPhase string
+
+const (
+	ReportRunPhaseRunning   ReportRunPhase = "Running"
+	ReportRunPhaseFinished  ReportRunPhase = "Finished"
+	ReportRunPhaseFailed    ReportRunPhase = "Failed"
+	ReportRunPhaseCancelled ReportRunPhase = "Cancelled"
+)
+
+// ReportRunStatus is the status of a report run.
+type ReportRunStatus struct {
+	ID      string           `json:"id"`
+	Phase   ReportRunPhase   `json:"phase"`
+	Request ReportRunRequest `json:"request"`
+	Started time.Time        `json:"started"`
+	// Finished is set once the run has finished, failed or been cancelled.
+	Finished *time.Time `json:"finished,omitempty"`
+	// Expires is when the run and its results are removed, once it's no
+	// longer running.
+	Expires *time.Time `json:"expires,omitempty"`
+	Error   string     `json:"error,omitempty"`
+	// Rows is how many rows the run returned, once it's finished.
+	Rows int `json:"rows"`
+}
+
+type ReportRunList struct {
+	Runs []ReportRunStatus `json:"runs"`
+}
+
+// trackedReportRun is a report run started through the API.
+type trackedReportRun struct {
+	status  ReportRunStatus
+	columns []cbTypes.ReportGenerationQueryColumn
+	results []presto.Row
+	cancel  context.CancelFunc
+}
+
+// reportRuns tracks the ad-hoc report runs started through the API. Runs
+// are only tracked in memory by the reporting-operator which started them,
+// which is why only the leader is ready, and are forgotten reportRunTTL
+// after they finish.
+type reportRuns struct {
+	queryer   presto.Queryer
+	queryFunc reportRunQueryFunc
+	now       func() time.Time
+
+	mu   sync.Mutex
+	runs map[string]*trackedReportRun
+}
+
+func newReportRuns(queryer presto.Queryer, queryFunc reportRunQueryFunc) *reportRuns {
+	return &reportRuns{
+		queryer:   queryer,
+		queryFunc: queryFunc,
+		now:       time.Now,
+		runs:      make(map[string]*trackedReportRun),
+	}
+}
+
+// renderReportRunQuery renders the query of a report run, the same way it
+// would be rendered for a Report with the same spec.
+func (op *Reporting) renderReportRunQuery(logger log.FieldLogger, report *cbTypes.Report) (string, []cbTypes.ReportGenerationQueryColumn, error) {
+	report.Namespace = op.cfg.Namespace
+	generationQuery, err := op.informers.Metering().V1alpha1().ReportGenerationQueries().Lister().ReportGenerationQueries(report.Namespace).Get(report.Spec.GenerationQueryName)
+	if err != nil {
+		return "", nil, err
+	}
+	query, _, columns, err := op.renderReportQuery(logger, report, "Report", report.Name, report.Spec.ReportingStart.Time, report.Spec.ReportingEnd.Time, generationQuery)
+	return query, columns, err
+}
+
+var errTooManyReportRuns = fmt.Errorf("%d report runs are already running, wait for one to finish", maxActiveReportRuns)
+
+// reportRunRequestError is returned when a report run can't start because
+// the request is invalid.
+type reportRunRequestError struct {
+	err error
+}
+
+func (e reportRunRequestError) Error() string {
+	return e.err.Error()
+}
+
+func newReportRunID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// renderQuery validates the request and renders the query of its
+// ReportGenerationQuery.
+func (runs *reportRuns) renderQuery(logger log.FieldLogger, req ReportRunRequest) (string, []cbTypes.ReportGenerationQueryColumn, error) {
+	if req.GenerationQuery == "" {
+		return "", nil, reportRunRequestError{errors.New("generationQuery must be set")}
+	}
+	if req.ReportingStart.IsZero() || req.ReportingEnd.IsZero() {
+		return "", nil, reportRunRequestError{errors.New("reportingStart and reportingEnd must be set")}
+	}
+	if !req.ReportingStart.Before(req.ReportingEnd) {
+		return "", nil, reportRunRequestError{errors.New("reportingStart must be before reportingEnd")}
+	}
+
+	report := &cbTypes.Report{
+		ObjectMeta: metav1.ObjectMeta{Name: "reportrun"},
+		Spec: cbTypes.ReportSpec{
+			GenerationQueryName: req.GenerationQuery,
+			ReportingStart:      metav1.NewTime(req.ReportingStart),
+			ReportingEnd:        metav1.NewTime(req.ReportingEnd),
+			Timezone:            req.Timezone,
+			Inputs:              req.Inputs,
+			GroupByLabels:       req.GroupByLabels,
+		},
+	}
+	query, columns, err := runs.queryFunc(logger, report)
+	if k8serrors.IsNotFound(err) {
+		return "", nil, reportRunRequestError{fmt.Errorf("ReportGenerationQuery %s does not exist", req.GenerationQuery)}
+	} else if err != nil {
+		return "", nil, reportRunRequestError{err}
+	}
+	return query, columns, nil
+}
+
+// start renders the query of the run and starts running it in the
+// background, returning its status.
+func (runs *reportRuns) start(logger log.FieldLogger, req ReportRunRequest) (ReportRunStatus, error) {
+	query, columns, err := runs.renderQuery(logger, req)
+	if err != nil {
+		return ReportRunStatus{}, err
+	}
+
+	id, err := newReportRunID()
+	if err != nil {
+		return ReportRunStatus{}, err
+	}
+
+	runs.mu.Lock()
+	defer runs.mu.Unlock()
+	runs.expireLocked()
+	active := 0
+	for _, run := range runs.runs {
+		if run.status.Phase == ReportRunPhaseRunning {
+			active++
+		}
+	}
+	if active >= maxActiveReportRuns {
+		return ReportRunStatus{}, errTooManyReportRuns
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), maxReportRunDuration)
+	run := &trackedReportRun{
+		status: ReportRunStatus{
+			ID:      id,
+			Phase:   ReportRunPhaseRunning,
+			Request: req,
+			Started: runs.now().UTC(),
+		},
+		columns: columns,
+		cancel:  cancel,
+	}
+	runs.runs[id] = run
+	go runs.run(ctx, logger.WithField("reportRun", id), run, query)
+	return run.status, nil
+}
+
+var errReportRunTooManyRows = fmt.Errorf("the results exceeded the limit of %d rows, create a Report instead", maxReportRunRows)
+
+func (runs *reportRuns) run(ctx context.Context, logger log.FieldLogger, run *trackedReportRun, query string) {
+	defer run.cancel()
+	logger.Infof("starting report run of ReportGenerationQuery %s", run.status.Request.GenerationQuery)
+
+	var results []presto.Row
+	err := presto.StreamQuery(ctx, runs.queryer, query, func(row presto.Row) error {
+		if len(results) >= maxReportRunRows {
+			return errReportRunTooManyRows
+		}
+		results = append(results, row)
+		return nil
+	})
+
+	runs.mu.Lock()
+	defer runs.mu.Unlock()
+	if run.status.Phase != ReportRunPhaseRunning {
+		// the run was cancelled
+		return
+	}
+	finished := runs.now().UTC()
+	expires := finished.Add(reportRunTTL)
+	run.status.Finished = &finished
+	run.status.Expires = &expires
+	switch {
+	case err != nil && ctx.Err() == context.DeadlineExceeded:
+		run.status.Phase = ReportRunPhaseFailed
+		run.status.Error = fmt.Sprintf("the run exceeded the limit of %s", maxReportRunDuration)
+	case err != nil:
+		run.status.Phase = ReportRunPhaseFailed
+		run.status.Error = err.Error()
+	default:
+		run.status.Phase = ReportRunPhaseFinished
+		run.status.Rows = len(results)
+		run.results = results
+	}
+	if err != nil {
+		logger.WithError(err).Warnf("report run failed")
+		return
+	}
+	logger.Infof("report run finished with %d rows", len(results))
+}
+
+// expireLocked forgets runs which finished more than reportRunTTL ago. The
+// caller must hold runs.mu.
+func (runs *reportRuns) expireLocked() {
+	now := runs.now()
+	for id, run := range runs.runs {
+		if run.status.Expires != nil && !now.Before(*run.status.Expires) {
+			delete(runs.runs, id)
+		}
+	}
+}
+
+func (runs *reportRuns) list() []ReportRunStatus {
+	runs.mu.Lock()
+	defer runs.mu.Unlock()
+	runs.expireLocked()
+	statuses := make([]ReportRunStatus, 0, len(runs.runs))
+	for _, run := range runs.runs {
+		statuses = append(statuses, run.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Started.Before(statuses[j].Started)
+	})
+	return statuses
+}
+
+// get returns the status of the run, and its columns and results if it's
+// finished.
+func (runs *reportRuns) get(id string) (ReportRunStatus, []cbTypes.ReportGenerationQueryColumn, []presto.Row, bool) {
+	runs.mu.Lock()
+	defer runs.mu.Unlock()
+	runs.expireLocked()
+	run, exists := runs.runs[id]
+	if !exists {
+		return ReportRunStatus{}, nil, nil, false
+	}
+	return run.status, run.columns, run.results, true
+}
+
+// delete cancels the run if it's running, and forgets it.
+func (runs *reportRuns) delete(id string) (ReportRunStatus, bool) {
+	runs.mu.Lock()
+	defer runs.mu.Unlock()
+	run, exists := runs.runs[id]
+	if !exists {
+		return ReportRunStatus{}, false
+	}
+	if run.status.Phase == ReportRunPhaseRunning {
+		finished := runs.now().UTC()
+		run.status.Phase = ReportRunPhaseCancelled
+		run.status.Finished = &finished
+		run.cancel()
+	}
+	delete(runs.runs, id)
+	return run.status, true
+}
+
+func (srv *server) createReportRunHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	var req ReportRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "unable to decode request as JSON: %v", err)
+		return
+	}
+	status, err := srv.reportRuns.start(logger, req)
+	switch err.(type) {
+	case nil:
+	case reportRunRequestError:
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	default:
+		if err == errTooManyReportRuns {
+			writeErrorResponse(logger, w, r, http.StatusTooManyRequests, "%v", err)
+			return
+		}
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "unable to start report run: %v", err)
+		return
+	}
+	w.Header().Set("Location", APIV1ReportRunsEndpoint+"/"+status.ID)
+	writeResponseAsJSON(logger, w, http.StatusAccepted, status)
+}
+
+func (srv *server) listReportRunsHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	writeResponseAsJSON(logger, w, http.StatusOK, ReportRunList{Runs: srv.reportRuns.list()})
+}
+
+func (srv *server) getReportRunHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	id := chi.URLParam(r, "id")
+	status, _, _, exists := srv.reportRuns.get(id)
+	if !exists {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "report run %s does not exist", id)
+		return
+	}
+	writeResponseAsJSON(logger, w, http.StatusOK, status)
+}
+
+func (srv *server) deleteReportRunHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	id := chi.URLParam(r, "id")
+	status, exists := srv.reportRuns.delete(id)
+	if !exists {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "report run %s does not exist", id)
+		return
+	}
+	writeResponseAsJSON(logger, w, http.StatusOK, status)
+}
+
+func (srv *server) getReportRunResultsHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	if err := r.ParseForm(); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "couldn't parse URL query params: %v", err)
+		return
+	}
+	if err := checkForFields([]string{"format"}, r.Form); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
+	format := r.Form["format"][0]
+	switch format {
+	case "json", "csv", "tab", "tabular", "parquet", "xlsx":
+	default:
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "format must be one of: csv, json, tabular, parquet or xlsx")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	status, columns, results, exists := srv.reportRuns.get(id)
+	if !exists {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "report run %s does not exist", id)
+		return
+	}
+	switch status.Phase {
+	case ReportRunPhaseRunning:
+		writeErrorResponse(logger, w, r, http.StatusAccepted, "report run %s is still running", id)
+		return
+	case ReportRunPhaseFailed:
+		writeErrorResponse(logger, w, r, http.StatusConflict, "report run %s failed: %s", id, status.Error)
+		return
+	}
+	results = roundMonetaryResults(srv.monetaryRounding, columns, results)
+	writeResultsResponse(logger, format, columns, results, w, r)
+}
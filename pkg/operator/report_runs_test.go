@@ -0,0 +1,131 @@
+package operator
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+func TestReportRuns(t *testing.T) {
+	const query = "SELECT namespace, cost FROM datasource_costs"
+	start := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	columns := []v1alpha1.ReportGenerationQueryColumn{
+		{Name: "namespace", Type: "string"},
+		{Name: "cost", Type: "double"},
+	}
+	queryFunc := func(logger log.FieldLogger, report *v1alpha1.Report) (string, []v1alpha1.ReportGenerationQueryColumn, error) {
+		if report.Spec.GenerationQueryName != "namespace-cost" {
+			return "", nil, k8serrors.NewNotFound(schema.GroupResource{Resource: "reportgenerationqueries"}, report.Spec.GenerationQueryName)
+		}
+		assert.Equal(t, start, report.Spec.ReportingStart.Time)
+		assert.Equal(t, end, report.Spec.ReportingEnd.Time)
+		return query, columns, nil
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	queryer := mockpresto.NewMockExecQueryer(ctrl)
+	queryer.EXPECT().Query(query).Return([]presto.Row{
+		{"namespace": "team-a", "cost": 75.0},
+		{"namespace": "team-b", "cost": 50.5},
+	}, nil)
+
+	router := newRouter(testLogger, routerConfig{
+		rand:               testRand,
+		queryer:            queryer,
+		importerQueryer:    queryer,
+		collectorFunc:      noopPrometheusImporterFunc,
+		reportRunQueryFunc: queryFunc,
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	startRun := func(req ReportRunRequest) *http.Response {
+		body, err := json.Marshal(req)
+		require.NoError(t, err)
+		resp, err := server.Client().Post(server.URL+APIV1ReportRunsEndpoint, "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := startRun(ReportRunRequest{GenerationQuery: "missing", ReportingStart: start, ReportingEnd: end})
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "expected runs of missing queries to be rejected")
+	resp = startRun(ReportRunRequest{GenerationQuery: "namespace-cost", ReportingStart: end, ReportingEnd: start})
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "expected runs ending before they start to be rejected")
+
+	resp = startRun(ReportRunRequest{GenerationQuery: "namespace-cost", ReportingStart: start, ReportingEnd: end})
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	var status ReportRunStatus
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	resp.Body.Close()
+	require.NotEmpty(t, status.ID)
+	runURL := server.URL + APIV1ReportRunsEndpoint + "/" + status.ID
+	assert.Equal(t, APIV1ReportRunsEndpoint+"/"+status.ID, resp.Header.Get("Location"))
+
+	deadline := time.Now().Add(10 * time.Second)
+	for status.Phase == ReportRunPhaseRunning {
+		require.True(t, time.Now().Before(deadline), "timed out waiting for the run to finish")
+		time.Sleep(10 * time.Millisecond)
+		resp, err := server.Client().Get(runURL)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+		resp.Body.Close()
+	}
+	assert.Equal(t, ReportRunPhaseFinished, status.Phase)
+	assert.Equal(t, 2, status.Rows)
+	require.NotNil(t, status.Expires)
+
+	resp, err := server.Client().Get(runURL + "/results?format=csv")
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "namespace,cost\nteam-a,75.000000\nteam-b,50.500000\n", string(body))
+
+	req, err := http.NewRequest("DELETE", runURL, nil)
+	require.NoError(t, err)
+	resp, err = server.Client().Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, err = server.Client().Get(runURL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "expected deleted runs to be forgotten")
+}
+
+func TestReportRunsExpire(t *testing.T) {
+	now := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	runs := newReportRuns(nil, nil)
+	runs.now = func() time.Time { return now }
+	expires := now.Add(time.Minute)
+	runs.runs["finished"] = &trackedReportRun{status: ReportRunStatus{ID: "finished", Phase: ReportRunPhaseFinished, Expires: &expires}}
+	runs.runs["running"] = &trackedReportRun{status: ReportRunStatus{ID: "running", Phase: ReportRunPhaseRunning}}
+
+	assert.Len(t, runs.list(), 2)
+	now = now.Add(reportRunTTL)
+	_, _, _, exists := runs.get("finished")
+	assert.False(t, exists, "expected finished runs to be removed once they expire")
+	_, _, _, exists = runs.get("running")
+	assert.True(t, exists, "expected running runs not to expire")
+}
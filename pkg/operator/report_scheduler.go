@@ -0,0 +1,239 @@
+package operator
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	reportRunsQueuedGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "metering",
+			Name:      "report_runs_queued",
+			Help:      "The number of report runs waiting for one of the concurrent report query slots.",
+		},
+	)
+	reportRunsRunningGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "metering",
+			Name:      "report_runs_running",
+			Help:      "The number of report runs holding one of the concurrent report query slots.",
+		},
+	)
+	reportRunQueueWaitHistogram = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "metering",
+			Name:      "report_run_queue_wait_seconds",
+			Help:      "How long report runs waited for one of the concurrent report query slots.",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 4, 9),
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(reportRunsQueuedGauge)
+	prometheus.MustRegister(reportRunsRunningGauge)
+	prometheus.MustRegister(reportRunQueueWaitHistogram)
+}
+
+// reportRunRequest is a run of a report waiting to query Presto.
+type reportRunRequest struct {
+	namespace string
+	// size is the length of the reporting period of the run, smaller runs
+	// are started first.
+	size time.Duration
+	// deadline is when the run is cancelled by its activeDeadlineSeconds,
+	// zero if it has none. Runs with the earliest deadline are started
+	// first.
+	deadline time.Time
+
+	seq      uint64
+	enqueued time.Time
+	ready    chan struct{}
+}
+
+// before returns true if r should be started before other, when both are
+// queued in the same namespace.
+func (r *reportRunRequest) before(other *reportRunRequest) bool {
+	if !r.deadline.Equal(other.deadline) {
+		if r.deadline.IsZero() || other.deadline.IsZero() {
+			return !r.deadline.IsZero()
+		}
+		return r.deadline.Before(other.deadline)
+	}
+	if r.size != other.size {
+		return r.size < other.size
+	}
+	return r.seq < other.seq
+}
+
+// reportScheduler limits how many report runs query Presto at the same time.
+// Runs waiting for a slot are queued per namespace, and each free slot goes
+// to the namespace with the fewest running reports, taking turns between
+// namespaces with the same number, so one namespace creating many reports
+// can't starve the others. Within a namespace, runs with the earliest
+// deadline, and then the smallest reporting period, are started first.
+type reportScheduler struct {
+	maxConcurrent int
+	now           func() time.Time
+
+	mu            sync.Mutex
+	seq           uint64
+	pending       map[string][]*reportRunRequest
+	running       map[string]int
+	totalRunning  int
+	lastNamespace string
+}
+
+func newReportScheduler(maxConcurrent int, now func() time.Time) *reportScheduler {
+	return &reportScheduler{
+		maxConcurrent: maxConcurrent,
+		now:           now,
+		pending:       make(map[string][]*reportRunRequest),
+		running:       make(map[string]int),
+	}
+}
+
+// acquire waits until the run of a report in namespace, with a reporting
+// period of size, may query Presto, and returns a function to call when the
+// run is finished. It returns an error if ctx is done first, which also
+// sets the deadline of the run.
+func (s *reportScheduler) acquire(ctx context.Context, namespace string, size time.Duration) (func(), error) {
+	var deadline time.Time
+	if d, ok := ctx.Deadline(); ok {
+		deadline = d
+	}
+	req := s.enqueue(namespace, size, deadline)
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			s.release(namespace)
+		})
+	}
+
+	select {
+	case <-req.ready:
+		reportRunQueueWaitHistogram.Observe(s.now().Sub(req.enqueued).Seconds())
+		return release, nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		removed := s.remove(req)
+		s.mu.Unlock()
+		if !removed {
+			// the run was started while ctx was being cancelled.
+			release()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// enqueue queues a run, starting it immediately if there's a free slot, and
+// returns it. req.ready is closed once the run is started.
+func (s *reportScheduler) enqueue(namespace string, size time.Duration, deadline time.Time) *reportRunRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	req := &reportRunRequest{
+		namespace: namespace,
+		size:      size,
+		deadline:  deadline,
+		seq:       s.seq,
+		enqueued:  s.now(),
+		ready:     make(chan struct{}),
+	}
+	s.pending[namespace] = append(s.pending[namespace], req)
+	reportRunsQueuedGauge.Inc()
+	s.dispatch()
+	return req
+}
+
+// release frees the slot of a finished run in namespace, and starts the next
+// queued run.
+func (s *reportScheduler) release(namespace string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running[namespace]--
+	if s.running[namespace] == 0 {
+		delete(s.running, namespace)
+	}
+	s.totalRunning--
+	reportRunsRunningGauge.Dec()
+	s.dispatch()
+}
+
+// dispatch starts queued runs while there are free slots. It must be called
+// with s.mu held.
+func (s *reportScheduler) dispatch() {
+	for s.totalRunning < s.maxConcurrent && len(s.pending) != 0 {
+		namespace := s.nextNamespace()
+		queue := s.pending[namespace]
+		next := 0
+		for i, req := range queue {
+			if req.before(queue[next]) {
+				next = i
+			}
+		}
+		req := queue[next]
+		s.removeAt(namespace, next)
+
+		s.running[namespace]++
+		s.totalRunning++
+		s.lastNamespace = namespace
+		reportRunsRunningGauge.Inc()
+		close(req.ready)
+	}
+}
+
+// nextNamespace returns the namespace with queued runs and the fewest
+// running reports. Ties go to the first such namespace after the one which
+// was given the last slot, in alphabetical order. It must be called with
+// s.mu held and at least one run queued.
+func (s *reportScheduler) nextNamespace() string {
+	namespaces := make([]string, 0, len(s.pending))
+	for namespace := range s.pending {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	// rotate the namespaces so that those after lastNamespace come first.
+	start := sort.SearchStrings(namespaces, s.lastNamespace)
+	if start < len(namespaces) && namespaces[start] == s.lastNamespace {
+		start++
+	}
+	namespaces = append(namespaces[start:], namespaces[:start]...)
+
+	best := namespaces[0]
+	for _, namespace := range namespaces[1:] {
+		if s.running[namespace] < s.running[best] {
+			best = namespace
+		}
+	}
+	return best
+}
+
+// remove removes req from the queue, and returns false if it isn't queued
+// because it has been started. It must be called with s.mu held.
+func (s *reportScheduler) remove(req *reportRunRequest) bool {
+	for i, queued := range s.pending[req.namespace] {
+		if queued == req {
+			s.removeAt(req.namespace, i)
+			return true
+		}
+	}
+	return false
+}
+
+func (s *reportScheduler) removeAt(namespace string, i int) {
+	queue := s.pending[namespace]
+	queue = append(queue[:i], queue[i+1:]...)
+	if len(queue) == 0 {
+		delete(s.pending, namespace)
+	} else {
+		s.pending[namespace] = queue
+	}
+	reportRunsQueuedGauge.Dec()
+}
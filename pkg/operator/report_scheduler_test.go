@@ -0,0 +1,127 @@
+package operator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportRunRequestBefore(t *testing.T) {
+	now := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		a, b     reportRunRequest
+		expected bool
+	}{
+		"earlier deadline": {
+			a:        reportRunRequest{deadline: now, size: time.Hour * 24, seq: 2},
+			b:        reportRunRequest{deadline: now.Add(time.Hour), size: time.Hour, seq: 1},
+			expected: true,
+		},
+		"deadline before no deadline": {
+			a:        reportRunRequest{deadline: now, seq: 2},
+			b:        reportRunRequest{seq: 1},
+			expected: true,
+		},
+		"no deadline after deadline": {
+			a:        reportRunRequest{seq: 1},
+			b:        reportRunRequest{deadline: now, seq: 2},
+			expected: false,
+		},
+		"smaller reporting period": {
+			a:        reportRunRequest{size: time.Hour, seq: 2},
+			b:        reportRunRequest{size: time.Hour * 24, seq: 1},
+			expected: true,
+		},
+		"queued first": {
+			a:        reportRunRequest{size: time.Hour, seq: 1},
+			b:        reportRunRequest{size: time.Hour, seq: 2},
+			expected: true,
+		},
+	}
+	for name, test := range tests {
+		assert.Equal(t, test.expected, test.a.before(&test.b), name)
+	}
+}
+
+func started(req *reportRunRequest) bool {
+	select {
+	case <-req.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestReportSchedulerConcurrency(t *testing.T) {
+	s := newReportScheduler(2, time.Now)
+	first := s.enqueue("metering", time.Hour, time.Time{})
+	second := s.enqueue("metering", time.Hour, time.Time{})
+	third := s.enqueue("metering", time.Hour, time.Time{})
+	assert.True(t, started(first))
+	assert.True(t, started(second))
+	assert.False(t, started(third), "only 2 runs should be started at once")
+
+	s.release("metering")
+	assert.True(t, started(third), "the queued run should start once a run finishes")
+}
+
+func TestReportSchedulerPriority(t *testing.T) {
+	s := newReportScheduler(1, time.Now)
+	now := time.Now()
+	running := s.enqueue("metering", time.Hour, time.Time{})
+	require.True(t, started(running))
+
+	monthly := s.enqueue("metering", time.Hour*24*30, time.Time{})
+	daily := s.enqueue("metering", time.Hour*24, time.Time{})
+	deadline := s.enqueue("metering", time.Hour*24*30, now.Add(time.Hour))
+
+	for _, next := range []*reportRunRequest{deadline, daily, monthly} {
+		s.release("metering")
+		assert.True(t, started(next))
+	}
+}
+
+func TestReportSchedulerFairness(t *testing.T) {
+	s := newReportScheduler(2, time.Now)
+	var busy []*reportRunRequest
+	for i := 0; i < 4; i++ {
+		busy = append(busy, s.enqueue("busy", time.Hour, time.Time{}))
+	}
+	quiet := s.enqueue("quiet", time.Hour, time.Time{})
+	other := s.enqueue("other", time.Hour, time.Time{})
+	require.True(t, started(busy[0]))
+	require.True(t, started(busy[1]))
+
+	// the namespaces with no running reports are served before the busy
+	// namespace's queued runs, even though they were queued later.
+	s.release("busy")
+	assert.True(t, started(other))
+	assert.False(t, started(quiet))
+	s.release("busy")
+	assert.True(t, started(quiet))
+	assert.False(t, started(busy[2]))
+
+	s.release("other")
+	assert.True(t, started(busy[2]))
+}
+
+func TestReportSchedulerAcquireCancelled(t *testing.T) {
+	s := newReportScheduler(1, time.Now)
+	release, err := s.acquire(context.Background(), "metering", time.Hour)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = s.acquire(ctx, "metering", time.Hour)
+	assert.Equal(t, context.Canceled, err)
+	assert.Empty(t, s.pending, "a cancelled run should be removed from the queue")
+
+	release()
+	release()
+	assert.Equal(t, 0, s.totalRunning, "releasing a run twice should only free its slot once")
+	_, err = s.acquire(context.Background(), "metering", time.Hour)
+	assert.NoError(t, err)
+}
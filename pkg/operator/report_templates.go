@@ -0,0 +1,508 @@
+package operator
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"math"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	log "github.com/sirupsen/logrus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	// pdfLandscapeColumns is the number of columns in the table of results
+	// above which PDF documents are landscape.
+	pdfLandscapeColumns = 6
+	pdfFont             = "Helvetica"
+	pdfLineHeight       = 5.0
+	pdfRowHeight        = 6.0
+)
+
+// reportTemplateData is the data ReportTemplates are rendered with.
+type reportTemplateData struct {
+	// Kind is Report or ScheduledReport.
+	Kind      string
+	Namespace string
+	Name      string
+	// PeriodStart and PeriodEnd are the period the results cover. They're
+	// zero if unknown.
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	// Columns are the names of the columns of the results.
+	Columns []string
+	Results []presto.Row
+	// Totals are the sums of each numeric column, keyed by name.
+	Totals map[string]float64
+}
+
+func newReportTemplateData(kind, namespace, name string, periodStart, periodEnd time.Time, columns []cbTypes.ReportGenerationQueryColumn, results []presto.Row) reportTemplateData {
+	data := reportTemplateData{
+		Kind:        kind,
+		Namespace:   namespace,
+		Name:        name,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		Results:     results,
+		Totals:      make(map[string]float64),
+	}
+	for _, column := range columns {
+		data.Columns = append(data.Columns, column.Name)
+		total, numeric := 0.0, false
+		for _, row := range results {
+			if value, ok := toFloat64(row[column.Name]); ok {
+				total += value
+				numeric = true
+			}
+		}
+		if numeric {
+			data.Totals[column.Name] = total
+		}
+	}
+	return data
+}
+
+// toFloat64 returns the value of numeric values returned by Presto.
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// reportTemplateText is the title, header and footer of a ReportTemplate
+// rendered with the data.
+type reportTemplateText struct {
+	Title  string
+	Header string
+	Footer string
+}
+
+func renderReportTemplateText(reportTemplate *cbTypes.ReportTemplate, data reportTemplateData) (reportTemplateText, error) {
+	var text reportTemplateText
+	var err error
+	if text.Title, err = renderTextTemplate("title", reportTemplate.Spec.Title, data); err != nil {
+		return text, err
+	}
+	if text.Header, err = renderTextTemplate("header", reportTemplate.Spec.Header, data); err != nil {
+		return text, err
+	}
+	if text.Footer, err = renderTextTemplate("footer", reportTemplate.Spec.Footer, data); err != nil {
+		return text, err
+	}
+	return text, nil
+}
+
+func renderTextTemplate(name, text string, data reportTemplateData) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %v", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("unable to render %s template: %v", name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// reportTemplateTable is the table of results shown in documents.
+type reportTemplateTable struct {
+	Headings []string
+	// Numeric is true for each column containing numbers, which are right
+	// aligned.
+	Numeric []bool
+	Rows    [][]string
+	// Totals is the last row of the table, which is nil unless a column has
+	// Total set.
+	Totals []string
+}
+
+// newReportTemplateTable formats the data as the table of results, with the
+// template's columns, or every column if it doesn't specify any.
+func newReportTemplateTable(reportTemplate *cbTypes.ReportTemplate, data reportTemplateData) reportTemplateTable {
+	columns := reportTemplate.Spec.Columns
+	if len(columns) == 0 {
+		for _, name := range data.Columns {
+			columns = append(columns, cbTypes.ReportTemplateColumn{Name: name})
+		}
+	}
+
+	table := reportTemplateTable{
+		Headings: make([]string, len(columns)),
+		Numeric:  make([]bool, len(columns)),
+		Rows:     make([][]string, len(data.Results)),
+	}
+	for i, column := range columns {
+		table.Headings[i] = column.Title
+		if table.Headings[i] == "" {
+			table.Headings[i] = column.Name
+		}
+		_, table.Numeric[i] = data.Totals[column.Name]
+		if column.Total {
+			if table.Totals == nil {
+				table.Totals = make([]string, len(columns))
+			}
+			table.Totals[i] = formatReportTemplateValue(column, data.Totals[column.Name])
+		}
+	}
+	for i, row := range data.Results {
+		table.Rows[i] = make([]string, len(columns))
+		for j, column := range columns {
+			table.Rows[i][j] = formatReportTemplateValue(column, row[column.Name])
+		}
+	}
+	return table
+}
+
+func formatReportTemplateValue(column cbTypes.ReportTemplateColumn, value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	if column.Format != "" {
+		return fmt.Sprintf(column.Format, value)
+	}
+	if t, ok := value.(time.Time); ok {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprint(value)
+}
+
+// defaultReportHTMLTemplate renders ReportTemplates which don't set html.
+var defaultReportHTMLTemplate = htmltemplate.Must(htmltemplate.New("default").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Text.Title }}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; font-size: 10pt; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #999; padding: 2px 6px; }
+th { background: #eee; }
+td.numeric { text-align: right; }
+tr.totals td { font-weight: bold; }
+p { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+{{- if .Text.Title }}
+<h1>{{ .Text.Title }}</h1>
+{{- end }}
+{{- if .Text.Header }}
+<p>{{ .Text.Header }}</p>
+{{- end }}
+<table>
+<tr>{{ range .Table.Headings }}<th>{{ . }}</th>{{ end }}</tr>
+{{- range .Table.Rows }}
+<tr>{{ range $i, $value := . }}<td{{ if index $.Table.Numeric $i }} class="numeric"{{ end }}>{{ $value }}</td>{{ end }}</tr>
+{{- end }}
+{{- if .Table.Totals }}
+<tr class="totals">{{ range $i, $value := .Table.Totals }}<td{{ if index $.Table.Numeric $i }} class="numeric"{{ end }}>{{ $value }}</td>{{ end }}</tr>
+{{- end }}
+</table>
+{{- if .Text.Footer }}
+<p>{{ .Text.Footer }}</p>
+{{- end }}
+</body>
+</html>
+`))
+
+// renderReportHTML renders the data as an HTML document using the
+// template's html, or the default document if it isn't set.
+func renderReportHTML(reportTemplate *cbTypes.ReportTemplate, data reportTemplateData) ([]byte, error) {
+	var buf bytes.Buffer
+	if reportTemplate.Spec.HTML != "" {
+		tmpl, err := htmltemplate.New("html").Option("missingkey=zero").Parse(reportTemplate.Spec.HTML)
+		if err != nil {
+			return nil, fmt.Errorf("invalid html template: %v", err)
+		}
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("unable to render html template: %v", err)
+		}
+		return buf.Bytes(), nil
+	}
+
+	text, err := renderReportTemplateText(reportTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+	err = defaultReportHTMLTemplate.Execute(&buf, struct {
+		Text  reportTemplateText
+		Table reportTemplateTable
+	}{text, newReportTemplateTable(reportTemplate, data)})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// renderReportPDF renders the data as a PDF document containing the title,
+// header, table of results and footer. The table's headings are repeated on
+// each page.
+func renderReportPDF(reportTemplate *cbTypes.ReportTemplate, data reportTemplateData, creationDate time.Time) ([]byte, error) {
+	text, err := renderReportTemplateText(reportTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+	table := newReportTemplateTable(reportTemplate, data)
+
+	orientation := "P"
+	if len(table.Headings) > pdfLandscapeColumns {
+		orientation = "L"
+	}
+	pdf := gofpdf.New(orientation, "mm", "A4", "")
+	pdf.SetCreationDate(creationDate)
+	pdf.SetCreator("metering", false)
+	pdf.SetTitle(text.Title, true)
+	// the core fonts only support cp1252
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	width := pageWidth - left - right
+
+	columnWidth := 0.0
+	if len(table.Headings) != 0 {
+		columnWidth = width / float64(len(table.Headings))
+	}
+	align := func(i int) string {
+		if table.Numeric[i] {
+			return "R"
+		}
+		return "L"
+	}
+	writeHeadings := func() {
+		pdf.SetFont(pdfFont, "B", 9)
+		pdf.SetFillColor(238, 238, 238)
+		for i, heading := range table.Headings {
+			pdf.CellFormat(columnWidth, pdfRowHeight, tr(truncatePDFCell(pdf, heading, columnWidth)), "1", 0, align(i), true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont(pdfFont, "", 9)
+	}
+	writeRow := func(row []string) {
+		for i, value := range row {
+			pdf.CellFormat(columnWidth, pdfRowHeight, tr(truncatePDFCell(pdf, value, columnWidth)), "1", 0, align(i), false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	inTable := false
+	pdf.SetHeaderFunc(func() {
+		if inTable {
+			writeHeadings()
+		}
+	})
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont(pdfFont, "", 8)
+		pdf.CellFormat(0, 10, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	if text.Title != "" {
+		pdf.SetFont(pdfFont, "B", 16)
+		pdf.MultiCell(0, 8, tr(text.Title), "", "L", false)
+		pdf.Ln(pdfLineHeight)
+	}
+	if text.Header != "" {
+		pdf.SetFont(pdfFont, "", 10)
+		pdf.MultiCell(0, pdfLineHeight, tr(text.Header), "", "L", false)
+		pdf.Ln(pdfLineHeight)
+	}
+
+	if len(table.Headings) != 0 {
+		writeHeadings()
+		inTable = true
+		for _, row := range table.Rows {
+			writeRow(row)
+		}
+		if table.Totals != nil {
+			pdf.SetFont(pdfFont, "B", 9)
+			writeRow(table.Totals)
+		}
+		inTable = false
+	}
+
+	if text.Footer != "" {
+		pdf.Ln(pdfLineHeight)
+		pdf.SetFont(pdfFont, "", 10)
+		pdf.MultiCell(0, pdfLineHeight, tr(text.Footer), "", "L", false)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// truncatePDFCell shortens text which is wider than a cell, so it doesn't
+// overflow into the next cell.
+func truncatePDFCell(pdf *gofpdf.Fpdf, text string, width float64) string {
+	// leave room for the cell's padding
+	width -= 2 * pdf.GetCellMargin()
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	n := int(math.Floor(float64(len(runes)) * width / pdf.GetStringWidth(text)))
+	for ; n > 0; n-- {
+		truncated := string(runes[:n]) + "..."
+		if pdf.GetStringWidth(truncated) <= width {
+			return truncated
+		}
+	}
+	return ""
+}
+
+// renderReportTemplate renders the data as an html or pdf document using the
+// named ReportTemplate.
+func (op *Reporting) renderReportTemplate(namespace, templateName, format string, data reportTemplateData) ([]byte, error) {
+	reportTemplate, err := op.informers.Metering().V1alpha1().ReportTemplates().Lister().ReportTemplates(namespace).Get(templateName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get ReportTemplate %s: %v", templateName, err)
+	}
+	switch format {
+	case "html":
+		return renderReportHTML(reportTemplate, data)
+	case "pdf":
+		return renderReportPDF(reportTemplate, data, op.clock.Now())
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+func (srv *server) renderReportHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	if !srv.validateRenderReportReq(logger, w, r) {
+		return
+	}
+	name := r.Form["name"][0]
+	tableName, reportColumns, prestoColumns, ok := srv.getReportTable(logger, name, w, r)
+	if !ok {
+		return
+	}
+	listers, err := srv.listersFor(r)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
+	report, err := listers.reports.Get(name)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error getting report: %v", err)
+		return
+	}
+	srv.renderReport(logger, "Report", report.Namespace, report.Name, report.Spec.ReportingStart.Time, report.Spec.ReportingEnd.Time, tableName, reportColumns, prestoColumns, w, r)
+}
+
+func (srv *server) renderScheduledReportHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	if !srv.validateRenderReportReq(logger, w, r) {
+		return
+	}
+	name := r.Form["name"][0]
+	tableName, reportColumns, prestoColumns, ok := srv.getScheduledReportTable(logger, name, w, r)
+	if !ok {
+		return
+	}
+	listers, err := srv.listersFor(r)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
+	report, err := listers.scheduledReports.Get(name)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error getting scheduledReport: %v", err)
+		return
+	}
+	// the table contains the results of every run, so only the end of the
+	// most recent run is known.
+	var periodEnd time.Time
+	if report.Status.LastReportTime != nil {
+		periodEnd = report.Status.LastReportTime.Time
+	}
+	srv.renderReport(logger, "ScheduledReport", report.Namespace, report.Name, time.Time{}, periodEnd, tableName, reportColumns, prestoColumns, w, r)
+}
+
+func (srv *server) validateRenderReportReq(logger log.FieldLogger, w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != "GET" {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "Not found")
+		return false
+	}
+	err := r.ParseForm()
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "couldn't parse URL query params: %v", err)
+		return false
+	}
+	err = checkForFields([]string{"name", "template", "format"}, r.Form)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return false
+	}
+	switch r.Form["format"][0] {
+	case "html", "pdf":
+		return true
+	}
+	writeErrorResponse(logger, w, r, http.StatusBadRequest, "format must be one of: html or pdf")
+	return false
+}
+
+// renderReport renders the results in the report's table, selected by the
+// request's filter and columns query parameters, using the ReportTemplate
+// named by the template query parameter.
+func (srv *server) renderReport(logger log.FieldLogger, kind, namespace, name string, periodStart, periodEnd time.Time, tableName string, reportColumns []cbTypes.ReportGenerationQueryColumn, prestoColumns []presto.Column, w http.ResponseWriter, r *http.Request) {
+	templateName := r.Form["template"][0]
+	reportTemplate, err := srv.listers.reportTemplates.Get(templateName)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if k8serrors.IsNotFound(err) {
+			code = http.StatusNotFound
+		}
+		writeErrorResponse(logger, w, r, code, "error getting ReportTemplate: %v", err)
+		return
+	}
+
+	reportColumns, prestoColumns, whereSQL, ok := srv.selectReportResults(logger, tableName, reportColumns, prestoColumns, w, r)
+	if !ok {
+		return
+	}
+	results, ok := srv.getReportResults(logger, tableName, srv.reportResultsDataVersion(r, kind, name), prestoColumns, whereSQL, w, r)
+	if !ok {
+		return
+	}
+
+	data := newReportTemplateData(kind, namespace, name, periodStart, periodEnd, reportColumns, results)
+	var body []byte
+	format := r.Form["format"][0]
+	if format == "pdf" {
+		body, err = renderReportPDF(reportTemplate, data, time.Now())
+	} else {
+		body, err = renderReportHTML(reportTemplate, data)
+	}
+	if err != nil {
+		logger.WithError(err).Errorf("failed to render report with ReportTemplate %s", templateName)
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "failed to render report: %v", err)
+		return
+	}
+	w.Header().Set("Content-Type", deliveryFormats[format].contentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		logger.WithError(err).Warnf("failed to write response")
+	}
+}
@@ -0,0 +1,195 @@
+package operator
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+var (
+	testTemplatePeriodStart = time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	testTemplatePeriodEnd   = time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	testTemplateColumns     = []v1alpha1.ReportGenerationQueryColumn{
+		{Name: "namespace", Type: "string"},
+		{Name: "cost", Type: "double"},
+	}
+	testTemplateResults = []presto.Row{
+		{"namespace": "team-a", "cost": 75.0},
+		{"namespace": "<team-b>", "cost": 50.5},
+	}
+)
+
+func newTestReportTemplate(name, namespace string, spec v1alpha1.ReportTemplateSpec) *v1alpha1.ReportTemplate {
+	return &v1alpha1.ReportTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       spec,
+	}
+}
+
+func TestNewReportTemplateData(t *testing.T) {
+	data := newReportTemplateData("Report", "metering", "namespace-cost", testTemplatePeriodStart, testTemplatePeriodEnd, testTemplateColumns, testTemplateResults)
+	assert.Equal(t, []string{"namespace", "cost"}, data.Columns)
+	assert.Equal(t, map[string]float64{"cost": 125.5}, data.Totals, "only numeric columns should have totals")
+}
+
+func TestRenderReportHTML(t *testing.T) {
+	data := newReportTemplateData("Report", "metering", "namespace-cost", testTemplatePeriodStart, testTemplatePeriodEnd, testTemplateColumns, testTemplateResults)
+
+	reportTemplate := newTestReportTemplate("invoice", "metering", v1alpha1.ReportTemplateSpec{
+		Title:  `Invoice for {{ .PeriodStart.Format "January 2006" }}`,
+		Footer: `Total due: {{ printf "%.2f" (index .Totals "cost") }}`,
+		Columns: []v1alpha1.ReportTemplateColumn{
+			{Name: "namespace", Title: "Namespace"},
+			{Name: "cost", Title: "Cost (USD)", Format: "%.2f", Total: true},
+		},
+	})
+	body, err := renderReportHTML(reportTemplate, data)
+	require.NoError(t, err)
+	html := string(body)
+	assert.Contains(t, html, "<h1>Invoice for July 2018</h1>")
+	assert.Contains(t, html, "<th>Namespace</th><th>Cost (USD)</th>")
+	assert.Contains(t, html, `<tr><td>&lt;team-b&gt;</td><td class="numeric">50.50</td></tr>`, "values should be escaped")
+	assert.Contains(t, html, `<tr class="totals"><td></td><td class="numeric">125.50</td></tr>`)
+	assert.Contains(t, html, "<p>Total due: 125.50</p>")
+
+	reportTemplate.Spec.HTML = `<ul>{{ range .Results }}<li>{{ .namespace }}</li>{{ end }}</ul>`
+	body, err = renderReportHTML(reportTemplate, data)
+	require.NoError(t, err)
+	assert.Equal(t, "<ul><li>team-a</li><li>&lt;team-b&gt;</li></ul>", string(body))
+
+	reportTemplate.Spec.HTML = `{{ .Missing }}`
+	_, err = renderReportHTML(reportTemplate, data)
+	assert.Error(t, err, "expected an error referencing a field which doesn't exist")
+}
+
+func TestRenderReportPDF(t *testing.T) {
+	data := newReportTemplateData("ScheduledReport", "metering", "namespace-cost", testTemplatePeriodStart, testTemplatePeriodEnd, testTemplateColumns, testTemplateResults)
+	reportTemplate := newTestReportTemplate("invoice", "metering", v1alpha1.ReportTemplateSpec{
+		Title:  "Invoice for {{ .Name }}",
+		Header: "Période: {{ .PeriodStart.Format \"2006-01-02\" }} to {{ .PeriodEnd.Format \"2006-01-02\" }}",
+	})
+
+	body, err := renderReportPDF(reportTemplate, data, testTemplatePeriodEnd)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(body, []byte("%PDF-")), "expected a PDF document")
+
+	reportTemplate.Spec.Title = "{{ .Name"
+	_, err = renderReportPDF(reportTemplate, data, testTemplatePeriodEnd)
+	assert.Error(t, err)
+}
+
+func TestAPIV1ReportsRender(t *testing.T) {
+	const namespace = "default"
+	const reportName = "test-report"
+	tableColumns := []hive.Column{
+		{Name: "namespace", Type: "string"},
+		{Name: "cost", Type: "double"},
+	}
+
+	tests := map[string]struct {
+		params              url.Values
+		expectQuery         bool
+		expectedStatusCode  int
+		expectedContentType string
+	}{
+		"html": {
+			params:              url.Values{"format": {"html"}, "template": {"invoice"}},
+			expectQuery:         true,
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/html; charset=utf-8",
+		},
+		"pdf": {
+			params:              url.Values{"format": {"pdf"}, "template": {"invoice"}},
+			expectQuery:         true,
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "application/pdf",
+		},
+		"unsupported format": {
+			params:             url.Values{"format": {"csv"}, "template": {"invoice"}},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"missing template": {
+			params:             url.Values{"format": {"html"}},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"template not found": {
+			params:             url.Values{"format": {"html"}, "template": {"statement"}},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			reportIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+			reportGenerationQueryIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+			prestoTableIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+			reportTemplateIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+			reportIndexer.Add(newTestReport(reportName, namespace, "test-query", testTemplatePeriodStart, testTemplatePeriodEnd, v1alpha1.ReportStatus{Phase: v1alpha1.ReportPhaseFinished}))
+			reportGenerationQueryIndexer.Add(newTestReportGenQuery("test-query", namespace, testTemplateColumns))
+			prestoTableIndexer.Add(newTestPrestoTable(reportName, namespace, tableColumns))
+			reportTemplateIndexer.Add(newTestReportTemplate("invoice", namespace, v1alpha1.ReportTemplateSpec{Title: "Invoice for {{ .Name }}"}))
+			listers := meteringListers{
+				reports:                 listers.NewReportLister(reportIndexer).Reports(namespace),
+				reportGenerationQueries: listers.NewReportGenerationQueryLister(reportGenerationQueryIndexer).ReportGenerationQueries(namespace),
+				prestoTables:            listers.NewPrestoTableLister(prestoTableIndexer).PrestoTables(namespace),
+				reportTemplates:         listers.NewReportTemplateLister(reportTemplateIndexer).ReportTemplates(namespace),
+			}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			queryer := mockpresto.NewMockExecQueryer(ctrl)
+			if tt.expectQuery {
+				prestoColumns, err := hiveColumnsToPrestoColumns(tableColumns)
+				require.NoError(t, err)
+				queryer.EXPECT().Query(presto.GenerateGetRowsWhereSQL(reportTableName(reportName), prestoColumns, "")).Return(testTemplateResults, nil)
+			}
+
+			router := newRouter(testLogger, routerConfig{
+				rand:            testRand,
+				queryer:         queryer,
+				importerQueryer: queryer,
+				collectorFunc:   noopPrometheusImporterFunc,
+				listers:         listers,
+			})
+			server := httptest.NewServer(router)
+			defer server.Close()
+
+			params := url.Values{"name": {reportName}}
+			for key, values := range tt.params {
+				params[key] = values
+			}
+			resp, err := server.Client().Get(server.URL + APIV1ReportsRenderEndpoint + "?" + params.Encode())
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.expectedStatusCode, resp.StatusCode, "unexpected response: %s", body)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tt.expectedContentType, resp.Header.Get("Content-Type"))
+			if tt.params.Get("format") == "html" {
+				assert.Contains(t, string(body), "<h1>Invoice for test-report</h1>")
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package operator
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	APIV1ReportsVersionsEndpoint          = "/api/v1/reports/versions"
+	APIV1ScheduledReportsVersionsEndpoint = "/api/v1/scheduledreports/versions"
+
+	// The versions table of a report has the columns of its results,
+	// followed by when and for which period each version was written, and
+	// is partitioned by the ID of each version.
+	reportVersionColumn            = "report_version"
+	reportVersionGeneratedAtColumn = "report_version_generated_at"
+	reportVersionPeriodStartColumn = "report_version_period_start"
+	reportVersionPeriodEndColumn   = "report_version_period_end"
+)
+
+// reportVersionIDRegexp matches the IDs of versions, which are generated by
+// newReportRunID.
+var reportVersionIDRegexp = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// ReportVersion is an immutable snapshot of the results of a run of a
+// versioned report.
+type ReportVersion struct {
+	ID          string    `json:"id"`
+	GeneratedAt time.Time `json:"generatedAt"`
+	PeriodStart time.Time `json:"periodStart"`
+	PeriodEnd   time.Time `json:"periodEnd"`
+	Rows        int64     `json:"rows"`
+}
+
+type ReportVersionList struct {
+	Versions []ReportVersion `json:"versions"`
+}
+
+func reportVersionsTableName(tableName string) string {
+	return tableName + "_versions"
+}
+
+// writeReportVersion writes the results of a successful run of a versioned
+// report from its table to a new partition of its versions table, which is
+// never modified once written. If periodRowsOnly is true and the results
+// have period_start and period_end columns, only the rows of the run's
+// reporting period are written, as the table also holds the results of
+// earlier runs. A version which fails to be written doesn't fail the run,
+// and the error is recorded in the returned status.
+func (op *Reporting) writeReportVersion(logger log.FieldLogger, report runtime.Object, run reportRun, storage *cbTypes.StorageLocationRef, periodRowsOnly bool) *cbTypes.ReportVersionStatus {
+	generatedAt := op.clock.Now().UTC()
+	status := &cbTypes.ReportVersionStatus{GeneratedAt: metav1.Time{Time: generatedAt}}
+	id, err := newReportRunID()
+	if err == nil {
+		status.ID = id
+		err = op.insertReportVersion(logger, report, run, storage, id, generatedAt, periodRowsOnly)
+	}
+	if err != nil {
+		status.Error = err.Error()
+		logger.WithError(err).Errorf("failed to write a version of the report's results")
+		return status
+	}
+	logger.Infof("wrote version %s of the report's results", id)
+	return status
+}
+
+func (op *Reporting) insertReportVersion(logger log.FieldLogger, report runtime.Object, run reportRun, storage *cbTypes.StorageLocationRef, id string, generatedAt time.Time, periodRowsOnly bool) error {
+	groupByLabels, err := getGroupByLabels(run.generationQuery, run.groupByLabels)
+	if err != nil {
+		return err
+	}
+	reportColumns := getReportColumns(run.generationQuery, groupByLabels)
+	columns, err := generatePrestoColumns(reportColumns)
+	if err != nil {
+		return err
+	}
+
+	tableProperties, err := op.getHiveTableProperties(logger, storage, run.kind)
+	if err != nil {
+		return fmt.Errorf("storage incorrectly configured for %s: %s", run.kind, run.name)
+	}
+	versionsTableName := reportVersionsTableName(run.tableName)
+	properties, err := addTableNameToLocation(*tableProperties, versionsTableName)
+	if err != nil {
+		return err
+	}
+	params := hive.TableParameters{
+		Name: versionsTableName,
+		Columns: append(generateHiveColumns(reportColumns),
+			hive.Column{Name: reportVersionGeneratedAtColumn, Type: "timestamp"},
+			hive.Column{Name: reportVersionPeriodStartColumn, Type: "timestamp"},
+			hive.Column{Name: reportVersionPeriodEndColumn, Type: "timestamp"},
+		),
+		Partitions:   []hive.Column{{Name: reportVersionColumn, Type: "string"}},
+		IgnoreExists: true,
+	}
+	if err := op.createTable(logger, params, properties); err != nil {
+		return err
+	}
+	resourceName := prestoTableResourceNameFromKind(run.kind, run.name) + "-versions"
+	if err := op.createNamedPrestoTableCR(report, cbTypes.GroupName, run.kind, resourceName, params, properties, nil); err != nil {
+		return fmt.Errorf("couldn't create PrestoTable resource for the versions of %s %s: %v", run.kind, run.name, err)
+	}
+
+	query := reportVersionQuery(run.tableName, columns, id, generatedAt, run.periodStart, run.periodEnd, periodRowsOnly)
+	return presto.InsertInto(op.prestoQueryer, versionsTableName, query)
+}
+
+// reportVersionQuery returns the query selecting the rows of a version from
+// the report's table.
+func reportVersionQuery(tableName string, columns []presto.Column, id string, generatedAt, periodStart, periodEnd time.Time, periodRowsOnly bool) string {
+	query := fmt.Sprintf("SELECT %s, timestamp '%s', timestamp '%s', timestamp '%s', '%s' FROM %s",
+		presto.GenerateQuotedColumnsListSQL(columns),
+		presto.Timestamp(generatedAt), presto.Timestamp(periodStart), presto.Timestamp(periodEnd),
+		id, tableName,
+	)
+	if periodRowsOnly && hasTimestampColumns(columns, "period_start", "period_end") {
+		query += fmt.Sprintf(` WHERE "period_start" >= timestamp '%s' AND "period_end" <= timestamp '%s'`, presto.Timestamp(periodStart), presto.Timestamp(periodEnd))
+	}
+	return query
+}
+
+func hasTimestampColumns(columns []presto.Column, names ...string) bool {
+	found := 0
+	for _, col := range columns {
+		for _, name := range names {
+			if col.Name == name && strings.EqualFold(col.Type, "timestamp") {
+				found++
+			}
+		}
+	}
+	return found == len(names)
+}
+
+// listReportVersions returns the versions in a report's versions table,
+// oldest first. Versions without any rows aren't listed.
+func listReportVersions(queryer presto.Queryer, versionsTableName string) ([]ReportVersion, error) {
+	rows, err := queryer.Query(fmt.Sprintf(`SELECT %s AS id, CAST(to_unixtime(%s) AS bigint) AS generated_at, CAST(to_unixtime(%s) AS bigint) AS period_start, CAST(to_unixtime(%s) AS bigint) AS period_end, count(*) AS row_count FROM %s GROUP BY 1, 2, 3, 4 ORDER BY 2, 1`,
+		reportVersionColumn, reportVersionGeneratedAtColumn, reportVersionPeriodStartColumn, reportVersionPeriodEndColumn, versionsTableName))
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]ReportVersion, 0, len(rows))
+	for _, row := range rows {
+		id, ok := row["id"].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid id, valueType: %T, value: %+v", row["id"], row["id"])
+		}
+		version := ReportVersion{ID: id}
+		for column, t := range map[string]*time.Time{"generated_at": &version.GeneratedAt, "period_start": &version.PeriodStart, "period_end": &version.PeriodEnd} {
+			secs, ok := row[column].(int64)
+			if !ok {
+				return nil, fmt.Errorf("invalid %s, valueType: %T, value: %+v", column, row[column], row[column])
+			}
+			*t = time.Unix(secs, 0).UTC()
+		}
+		if version.Rows, ok = row["row_count"].(int64); !ok {
+			return nil, fmt.Errorf("invalid row_count, valueType: %T, value: %+v", row["row_count"], row["row_count"])
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// getReportVersionsTable returns the name of the versions table of the
+// Report or ScheduledReport, and its latest version, which is nil if it
+// has never written a version.
+func getReportVersionsTable(listers meteringListers, kind, name string) (string, *cbTypes.ReportVersionStatus, error) {
+	var (
+		tableName string
+		latest    *cbTypes.ReportVersionStatus
+	)
+	switch kind {
+	case dependencyKindReport:
+		report, err := listers.reports.Get(name)
+		if err != nil {
+			return "", nil, err
+		}
+		tableName, latest = reportTableName(name), report.Status.LatestVersion
+	case dependencyKindScheduledReport:
+		report, err := listers.scheduledReports.Get(name)
+		if err != nil {
+			return "", nil, err
+		}
+		tableName, latest = scheduledReportTableName(name), report.Status.LatestVersion
+	default:
+		return "", nil, fmt.Errorf("invalid report kind: %s", kind)
+	}
+	return reportVersionsTableName(tenantTableName(listers.tenantNamespace, tableName)), latest, nil
+}
+
+func (srv *server) listReportVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	srv.listReportVersions(dependencyKindReport, w, r)
+}
+
+func (srv *server) listScheduledReportVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	srv.listReportVersions(dependencyKindScheduledReport, w, r)
+}
+
+func (srv *server) listReportVersions(kind string, w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	if err := r.ParseForm(); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "couldn't parse URL query params: %v", err)
+		return
+	}
+	if err := checkForFields([]string{"name"}, r.Form); err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
+	name := r.FormValue("name")
+	listers, err := srv.listersFor(r)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return
+	}
+	versionsTableName, latest, err := getReportVersionsTable(listers, kind, name)
+	if k8serrors.IsNotFound(err) {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "%s %s does not exist", kind, name)
+		return
+	} else if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error getting %s: %v", kind, err)
+		return
+	}
+	if latest == nil {
+		writeResponseAsJSON(logger, w, http.StatusOK, ReportVersionList{Versions: []ReportVersion{}})
+		return
+	}
+	versions, err := listReportVersions(srv.queryer, versionsTableName)
+	if err != nil {
+		logger.WithError(err).Errorf("failed to list the versions of %s %s", kind, name)
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "failed to list the versions of %s %s: %v", kind, name, err)
+		return
+	}
+	writeResponseAsJSON(logger, w, http.StatusOK, ReportVersionList{Versions: versions})
+}
+
+// reportVersionTable returns the table the results of the version in the
+// version query parameter are read from, which is a subquery of the
+// version's rows in the report's versions table, or tableName if the
+// parameter isn't set.
+func (srv *server) reportVersionTable(logger log.FieldLogger, kind, name, tableName string, w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := r.FormValue("version")
+	if id == "" {
+		return tableName, true
+	}
+	if !reportVersionIDRegexp.MatchString(id) {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "invalid version %q", id)
+		return "", false
+	}
+	listers, err := srv.listersFor(r)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusBadRequest, "%v", err)
+		return "", false
+	}
+	versionsTableName, latest, err := getReportVersionsTable(listers, kind, name)
+	if err != nil {
+		writeErrorResponse(logger, w, r, http.StatusInternalServerError, "error getting %s: %v", kind, err)
+		return "", false
+	}
+	if latest == nil {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "%s %s has no versions", kind, name)
+		return "", false
+	}
+	versionSQL := fmt.Sprintf("(SELECT * FROM %s WHERE %s = '%s')", versionsTableName, reportVersionColumn, id)
+	// the latest version may have no rows, but every other version has
+	// rows.
+	if id != latest.ID {
+		rows, err := srv.queryer.Query(fmt.Sprintf("SELECT count(*) AS row_count FROM %s", versionSQL))
+		if err != nil {
+			logger.WithError(err).Errorf("failed to perform presto query")
+			writeErrorResponse(logger, w, r, http.StatusInternalServerError, "failed to perform presto query (see operator logs for more details): %v", err)
+			return "", false
+		}
+		if len(rows) != 1 || rows[0]["row_count"] == int64(0) {
+			writeErrorResponse(logger, w, r, http.StatusNotFound, "version %s of %s %s does not exist", id, kind, name)
+			return "", false
+		}
+	}
+	return versionSQL, true
+}
@@ -0,0 +1,168 @@
+package operator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	mockpresto "github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+func TestReportVersionQuery(t *testing.T) {
+	const id = "0123456789abcdef0123456789abcdef"
+	generatedAt := time.Date(2019, time.February, 1, 6, 0, 0, 0, time.UTC)
+	start := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC)
+	columns := []presto.Column{
+		{Name: "period_start", Type: "TIMESTAMP"},
+		{Name: "period_end", Type: "TIMESTAMP"},
+		{Name: "total_cost", Type: "DOUBLE"},
+	}
+
+	assert.Equal(t,
+		`SELECT "period_start","period_end","total_cost", timestamp '2019-02-01 06:00:00.000', timestamp '2019-01-01 00:00:00.000', timestamp '2019-02-01 00:00:00.000', '0123456789abcdef0123456789abcdef' FROM report_table`,
+		reportVersionQuery("report_table", columns, id, generatedAt, start, end, false),
+	)
+	assert.Equal(t,
+		`SELECT "period_start","period_end","total_cost", timestamp '2019-02-01 06:00:00.000', timestamp '2019-01-01 00:00:00.000', timestamp '2019-02-01 00:00:00.000', '0123456789abcdef0123456789abcdef' FROM report_table WHERE "period_start" >= timestamp '2019-01-01 00:00:00.000' AND "period_end" <= timestamp '2019-02-01 00:00:00.000'`,
+		reportVersionQuery("report_table", columns, id, generatedAt, start, end, true),
+	)
+	// without the period columns, every row is written.
+	assert.Equal(t,
+		`SELECT "total_cost", timestamp '2019-02-01 06:00:00.000', timestamp '2019-01-01 00:00:00.000', timestamp '2019-02-01 00:00:00.000', '0123456789abcdef0123456789abcdef' FROM report_table`,
+		reportVersionQuery("report_table", columns[2:], id, generatedAt, start, end, true),
+	)
+}
+
+func TestListScheduledReportVersions(t *testing.T) {
+	const namespace = "metering"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	queryer := mockpresto.NewMockExecQueryer(ctrl)
+
+	indexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(&cbTypes.ScheduledReport{
+		ObjectMeta: meta.ObjectMeta{Name: "invoiced", Namespace: namespace},
+		Spec:       cbTypes.ScheduledReportSpec{Versioned: true},
+		Status: cbTypes.ScheduledReportStatus{
+			LatestVersion: &cbTypes.ReportVersionStatus{ID: "0123456789abcdef0123456789abcdef"},
+		},
+	})
+	indexer.Add(&cbTypes.ScheduledReport{
+		ObjectMeta: meta.ObjectMeta{Name: "new", Namespace: namespace},
+		Spec:       cbTypes.ScheduledReportSpec{Versioned: true},
+	})
+	reportListers := meteringListers{scheduledReports: listers.NewScheduledReportLister(indexer).ScheduledReports(namespace)}
+	router := newRouter(testLogger, routerConfig{
+		rand:            testRand,
+		queryer:         queryer,
+		importerQueryer: queryer,
+		collectorFunc:   noopPrometheusImporterFunc,
+		listers:         reportListers,
+	})
+
+	january := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	february := time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC)
+	queryer.EXPECT().Query(`SELECT report_version AS id, CAST(to_unixtime(report_version_generated_at) AS bigint) AS generated_at, CAST(to_unixtime(report_version_period_start) AS bigint) AS period_start, CAST(to_unixtime(report_version_period_end) AS bigint) AS period_end, count(*) AS row_count FROM scheduled_report_invoiced_versions GROUP BY 1, 2, 3, 4 ORDER BY 2, 1`).Return([]presto.Row{
+		{"id": "0123456789abcdef0123456789abcdef", "generated_at": february.Add(time.Hour).Unix(), "period_start": january.Unix(), "period_end": february.Unix(), "row_count": int64(3)},
+	}, nil)
+
+	tests := map[string]struct {
+		name     string
+		code     int
+		versions []ReportVersion
+	}{
+		"versioned": {
+			name: "invoiced",
+			code: http.StatusOK,
+			versions: []ReportVersion{
+				{ID: "0123456789abcdef0123456789abcdef", GeneratedAt: february.Add(time.Hour), PeriodStart: january, PeriodEnd: february, Rows: 3},
+			},
+		},
+		"no versions yet": {
+			name:     "new",
+			code:     http.StatusOK,
+			versions: []ReportVersion{},
+		},
+		"missing": {
+			name: "missing",
+			code: http.StatusNotFound,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", APIV1ScheduledReportsVersionsEndpoint+"?name="+test.name, nil))
+			require.Equal(t, test.code, w.Code, w.Body.String())
+			if test.code != http.StatusOK {
+				return
+			}
+			var resp ReportVersionList
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, test.versions, resp.Versions)
+		})
+	}
+}
+
+func TestReportVersionTable(t *testing.T) {
+	const namespace = "metering"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	queryer := mockpresto.NewMockExecQueryer(ctrl)
+
+	const latest, previous, missing = "0123456789abcdef0123456789abcdef", "00000000000000000000000000000001", "00000000000000000000000000000002"
+	indexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(&cbTypes.Report{
+		ObjectMeta: meta.ObjectMeta{Name: "invoiced", Namespace: namespace},
+		Status:     cbTypes.ReportStatus{LatestVersion: &cbTypes.ReportVersionStatus{ID: latest}},
+	})
+	indexer.Add(&cbTypes.Report{ObjectMeta: meta.ObjectMeta{Name: "unversioned", Namespace: namespace}})
+	srv := &server{
+		logger:  testLogger,
+		queryer: queryer,
+		listers: meteringListers{reports: listers.NewReportLister(indexer).Reports(namespace)},
+	}
+	queryer.EXPECT().Query("SELECT count(*) AS row_count FROM (SELECT * FROM report_invoiced_versions WHERE report_version = '"+previous+"')").Return([]presto.Row{{"row_count": int64(3)}}, nil)
+	queryer.EXPECT().Query("SELECT count(*) AS row_count FROM (SELECT * FROM report_invoiced_versions WHERE report_version = '"+missing+"')").Return([]presto.Row{{"row_count": int64(0)}}, nil)
+
+	tests := map[string]struct {
+		name, version string
+		code          int
+		table         string
+	}{
+		"current results": {name: "invoiced", code: http.StatusOK, table: "report_invoiced"},
+		"latest version":  {name: "invoiced", version: latest, code: http.StatusOK, table: "(SELECT * FROM report_invoiced_versions WHERE report_version = '" + latest + "')"},
+		"earlier version": {name: "invoiced", version: previous, code: http.StatusOK, table: "(SELECT * FROM report_invoiced_versions WHERE report_version = '" + previous + "')"},
+		"missing version": {name: "invoiced", version: missing, code: http.StatusNotFound},
+		"invalid version": {name: "invoiced", version: "1' OR '1'='1", code: http.StatusBadRequest},
+		"unversioned":     {name: "unversioned", version: latest, code: http.StatusNotFound},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", APIV1ReportsGetEndpoint, nil)
+			r.Form = map[string][]string{"version": {test.version}}
+			table, ok := srv.reportVersionTable(testLogger, dependencyKindReport, test.name, reportTableName(test.name), w, r)
+			if test.code != http.StatusOK {
+				assert.False(t, ok)
+				assert.Equal(t, test.code, w.Code)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, test.table, table)
+		})
+	}
+}
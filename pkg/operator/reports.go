@@ -1,6 +1,7 @@
 package operator
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -10,21 +11,22 @@ import (
 	"k8s.io/client-go/tools/cache"
 
 	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
 )
 
 var (
 	defaultGracePeriod = metav1.Duration{Duration: time.Minute * 5}
 )
 
-func (op *Reporting) runReportWorker() {
+func (op *Reporting) runReportWorker(stopCh <-chan struct{}) {
 	logger := op.logger.WithField("component", "reportWorker")
 	logger.Infof("Report worker started")
-	for op.processReport(logger) {
+	for op.processReport(logger, stopCh) {
 
 	}
 }
 
-func (op *Reporting) processReport(logger log.FieldLogger) bool {
+func (op *Reporting) processReport(logger log.FieldLogger, stopCh <-chan struct{}) bool {
 	obj, quit := op.queues.reportQueue.Get()
 	if quit {
 		logger.Infof("queue is shutting down, exiting Report worker")
@@ -34,13 +36,15 @@ func (op *Reporting) processReport(logger log.FieldLogger) bool {
 
 	logger = logger.WithFields(newLogIdentifier(op.rand))
 	if key, ok := op.getKeyFromQueueObj(logger, "report", obj, op.queues.reportQueue); ok {
-		err := op.syncReport(logger, key)
+		start := op.clock.Now()
+		err := op.syncReport(logger, key, stopCh)
+		op.observeReconcile("Report", start, err)
 		op.handleErr(logger, err, "report", obj, op.queues.reportQueue)
 	}
 	return true
 }
 
-func (op *Reporting) syncReport(logger log.FieldLogger, key string) error {
+func (op *Reporting) syncReport(logger log.FieldLogger, key string, stopCh <-chan struct{}) error {
 	namespace, name, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
 		logger.WithError(err).Errorf("invalid resource key :%s", key)
@@ -52,13 +56,14 @@ func (op *Reporting) syncReport(logger log.FieldLogger, key string) error {
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			logger.Infof("Report %s does not exist anymore", key)
+			op.reportMetrics.delete(reportMetricsKey{kind: "Report", namespace: namespace, name: name})
 			return nil
 		}
 		return err
 	}
 
 	logger.Infof("syncing report %s", report.GetName())
-	err = op.handleReport(logger, report)
+	err = op.handleReport(logger, report, stopCh)
 	if err != nil {
 		logger.WithError(err).Errorf("error syncing report %s", report.GetName())
 		return err
@@ -67,9 +72,35 @@ func (op *Reporting) syncReport(logger log.FieldLogger, key string) error {
 	return nil
 }
 
-func (op *Reporting) handleReport(logger log.FieldLogger, report *cbTypes.Report) error {
+func (op *Reporting) handleReportDeleted(obj interface{}) {
+	report, ok := obj.(*cbTypes.Report)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			op.logger.Errorf("Couldn't get object from tombstone %#v", obj)
+			return
+		}
+		report, ok = tombstone.Obj.(*cbTypes.Report)
+		if !ok {
+			op.logger.Errorf("Tombstone contained object that is not a Report %#v", obj)
+			return
+		}
+	}
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(report)
+	if err != nil {
+		op.logger.WithField("report", report.Name).WithError(err).Errorf("couldn't get key for object: %#v", report)
+		return
+	}
+	op.queues.reportQueue.Add(key)
+}
+
+func (op *Reporting) handleReport(logger log.FieldLogger, report *cbTypes.Report, stopCh <-chan struct{}) error {
 	report = report.DeepCopy()
 
+	if report.Spec.FanOut != nil {
+		return op.handleFanOutReport(logger, report)
+	}
+
 	switch report.Status.Phase {
 	case cbTypes.ReportPhaseStarted:
 		// If it's started, query the API to get the most up to date resource,
@@ -100,15 +131,48 @@ func (op *Reporting) handleReport(logger log.FieldLogger, report *cbTypes.Report
 			return nil
 		}
 
-		err = fmt.Errorf("unable to determine if report generation succeeded")
-		op.setReportError(logger, report, err, "found already started report, report generation likely failed while processing")
-		return nil
+		// checkpointed reports can be resumed from their last checkpoint
+		if newReport.Spec.CheckpointInterval == nil {
+			err = fmt.Errorf("found already started report, report generation likely failed while processing")
+			now := op.clock.Now().UTC()
+			op.handleReportRunFailure(logger, newReport.DeepCopy(), now, now, "", err)
+			return nil
+		}
+		logger.Infof("found already started report with checkpointing enabled, resuming report")
+		report = newReport.DeepCopy()
 	case cbTypes.ReportPhaseFinished, cbTypes.ReportPhaseError:
 		logger.Infof("ignoring report %s, status: %s", report.Name, report.Status.Phase)
+		if cbutil.GetReportCondition(report.Status, cbTypes.ReportReady) == nil {
+			// set the conditions of reports which completed before they
+			// were added.
+			newReport, err := op.updateReport(report)
+			if err != nil {
+				logger.WithError(err).Errorf("failed to update report conditions for %q", report.Name)
+				return err
+			}
+			report = newReport
+		}
+		if report.Status.Phase == cbTypes.ReportPhaseFinished {
+			op.exportFinishedReportMetrics(logger, report)
+			if data, exists := op.reportAmendments.take(reportAmendmentKey(dependencyKindReport, report.Namespace, report.Name)); exists && report.Spec.Amendments != nil {
+				return op.amendFinishedReport(logger, report, data)
+			}
+		}
 		return nil
 	default:
-
-		logger.Infof("new report discovered")
+		if report.Status.NextRetryTime != nil {
+			if waitTime := report.Status.NextRetryTime.Sub(op.clock.Now()); waitTime > 0 {
+				logger.Infof("report %s failed its last attempt, retrying at %s (%s)", report.Name, report.Status.NextRetryTime.Time, waitTime)
+				key, err := cache.MetaNamespaceKeyFunc(report)
+				if err == nil {
+					op.queues.reportQueue.AddAfter(key, waitTime)
+				}
+				return nil
+			}
+			logger.Infof("retrying report after %d failed attempts", len(report.Status.Attempts))
+		} else {
+			logger.Infof("new report discovered")
+		}
 	}
 
 	logger = logger.WithFields(log.Fields{
@@ -135,6 +199,10 @@ func (op *Reporting) handleReport(logger log.FieldLogger, report *cbTypes.Report
 		logger.Infof("report configured to run immediately with %s until periodEnd+gracePeriod: %s", waitTime, nextRunTime)
 	} else if reportGracePeriodUnmet {
 		logger.Infof("report %s not past grace period yet, ignoring until %s (%s)", report.Name, nextRunTime, waitTime)
+		key, err := cache.MetaNamespaceKeyFunc(report)
+		if err == nil {
+			op.queues.reportQueue.AddAfter(key, waitTime)
+		}
 		return nil
 	}
 
@@ -153,53 +221,274 @@ func (op *Reporting) handleReport(logger log.FieldLogger, report *cbTypes.Report
 		return nil
 	}
 
+	if err := op.validateReportDependencies(report.Namespace, dependencyKindReport, report.Name, genQuery); err != nil {
+		op.setReportError(logger, report, err, "report is invalid")
+		return nil
+	}
+
+	if err := op.validateReportInputs(genQuery, report.Spec.Inputs); err != nil {
+		op.setReportError(logger, report, err, "report has invalid inputs")
+		return nil
+	}
+
+	if _, err := getGroupByLabels(genQuery, report.Spec.GroupByLabels); err != nil {
+		op.setReportError(logger, report, err, "report has invalid groupByLabels")
+		return nil
+	}
+
+	if _, err := getCostAllocation(genQuery, report.Spec.CostAllocation); err != nil {
+		op.setReportError(logger, report, err, "report has invalid costAllocation")
+		return nil
+	}
+
+	if err := validateReportDeliveries(report.Spec.Deliveries); err != nil {
+		op.setReportError(logger, report, err, "report has invalid deliveries")
+		return nil
+	}
+
+	if err := validateReportNotifications(report.Spec.Notifications); err != nil {
+		op.setReportError(logger, report, err, "report has invalid notifications")
+		return nil
+	}
+
+	if err := validateReportMetrics(report.Spec.Metrics); err != nil {
+		op.setReportError(logger, report, err, "report has invalid metrics")
+		return nil
+	}
+
+	if err := validateReportAssertions(report.Spec.Assertions); err != nil {
+		op.setReportError(logger, report, err, "report has invalid assertions")
+		return nil
+	}
+
+	if err := validateReportAmendments(report.Spec.Amendments); err != nil {
+		op.setReportError(logger, report, err, "report has invalid amendments")
+		return nil
+	}
+
+	// RunImmediately reports run with whatever data is available, so only
+	// check if the dependencies have data for the period otherwise.
+	if !report.Spec.RunImmediately {
+		dependencies, ready, err := op.getDependenciesStatus(logger, genQuery, report.Spec.ReportingEnd.Time)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				op.setReportError(logger, report, err, "report is invalid")
+				return nil
+			}
+			return err
+		}
+		report.Status.Dependencies = dependencies
+		if !ready {
+			logger.Warnf("cannot start report, its dependencies do not have data for the reporting period yet, checking again in %s", dataSourceNotReadyRetryInterval)
+			newReport, err := op.updateReport(report)
+			if err != nil {
+				logger.WithError(err).Errorf("failed to update report dependency status for %q", report.Name)
+				return err
+			}
+			report = newReport
+			key, err := cache.MetaNamespaceKeyFunc(report)
+			if err == nil {
+				op.queues.reportQueue.AddAfter(key, dataSourceNotReadyRetryInterval)
+			}
+			return nil
+		}
+	}
+
 	logger.Debug("updating report status to started")
 	// update status
+	attemptStart := op.clock.Now().UTC()
 	report.Status.Phase = cbTypes.ReportPhaseStarted
-	newReport, err := op.meteringClient.MeteringV1alpha1().Reports(report.Namespace).Update(report)
+	report.Status.NextRetryTime = nil
+	newReport, err := op.updateReport(report)
 	if err != nil {
 		logger.WithError(err).Errorf("failed to update report status to started for %q", report.Name)
 		return err
 	}
 
 	report = newReport
-	tableName := reportTableName(report.Name)
-
-	err = op.generateReport(
-		logger,
-		report,
-		"report",
-		report.Name,
-		tableName,
-		report.Spec.ReportingStart.Time,
-		report.Spec.ReportingEnd.Time,
-		report.Spec.Output,
-		genQuery,
-		true,
-		false,
-	)
+	tableName := op.namespacedReportTableName(report.Namespace, report.Name)
+
+	ctx, cancel := newReportRunContext(stopCh, report.Spec.ActiveDeadlineSeconds)
+	defer cancel()
+
+	if report.Spec.CheckpointInterval != nil {
+		var checkpoint *time.Time
+		if report.Status.Checkpoint != nil {
+			checkpoint = &report.Status.Checkpoint.Time
+		}
+		reportStart, resuming := getCheckpointResumeTime(checkpoint, report.Spec.ReportingStart.Time, report.Spec.ReportingEnd.Time)
+		if resuming {
+			logger.Infof("resuming report from checkpoint %s", reportStart)
+		}
+		err = op.generateReportCheckpointed(
+			ctx,
+			logger,
+			report,
+			"report",
+			report.Name,
+			tableName,
+			reportStart,
+			report.Spec.ReportingEnd.Time,
+			report.Spec.CheckpointInterval.Duration,
+			report.Spec.Output,
+			genQuery,
+			!resuming,
+			false,
+			func(checkpoint time.Time) error {
+				report.Status.Checkpoint = &metav1.Time{Time: checkpoint}
+				newReport, err := op.updateReport(report)
+				if err != nil {
+					return err
+				}
+				report = newReport
+				return nil
+			},
+		)
+	} else {
+		err = op.generateReport(
+			ctx,
+			logger,
+			report,
+			"report",
+			report.Name,
+			tableName,
+			report.Spec.ReportingStart.Time,
+			report.Spec.ReportingEnd.Time,
+			report.Spec.Output,
+			genQuery,
+			true,
+			false,
+		)
+	}
+	if err != nil && stopRequested(stopCh) {
+		// the run was cancelled because the operator is stopping, so the
+		// report is left Started to be retried, or resumed from its
+		// checkpoint, once it restarts.
+		logger.WithError(err).Warnf("report run stopped before it finished")
+		return nil
+	}
+	op.observeReportRun("Report", attemptStart, err, reportRunTimedOut(ctx))
 	if err != nil {
-		op.setReportError(logger, report, err, "report execution failed")
+		var reason string
+		if reportRunTimedOut(ctx) {
+			reason = cbTypes.ReportDeadlineExceededReason
+			err = fmt.Errorf("report run timed out after exceeding activeDeadlineSeconds of %d: %v", *report.Spec.ActiveDeadlineSeconds, err)
+		}
+		if retrying := op.handleReportRunFailure(logger, report, attemptStart, op.clock.Now().UTC(), reason, err); retrying {
+			return nil
+		}
 		return err
 	}
 
+	run := op.newReportRun(report, genQuery, nil)
+	if report.Spec.Versioned {
+		report.Status.LatestVersion = op.writeReportVersion(logger, report, run, report.Spec.Output, false)
+	}
+	if report.Spec.Lineage {
+		report.Status.LatestLineage = op.writeReportLineage(logger, report, run, report.Spec.Output)
+	}
+	report.Status.Assertions = op.checkReportAssertions(logger, run, report.Spec.Assertions)
+	run.failedAssertions = failedReportAssertions(report.Status.Assertions)
+	report.Status.Deliveries = op.deliverReportResults(context.Background(), logger, "Report", report.Namespace, report.Name, tableName, genQuery, report.Spec.GroupByLabels, report.Spec.Deliveries, report.Spec.ReportingStart.Time, report.Spec.ReportingEnd.Time)
+	notifications := op.sendReportNotifications(context.Background(), logger, run, report.Spec.Notifications)
+	report.Status.Notifications = mergeNotificationStatuses(report.Status.Notifications, notifications)
+	op.publishReportResultsToKafka(logger, "Report", report.Namespace, report.Name, tableName, genQuery, report.Spec.GroupByLabels, report.Spec.ReportingStart.Time, report.Spec.ReportingEnd.Time)
+	op.exportReportMetrics(logger, "Report", report.Namespace, report.Name, tableName, genQuery, report.Spec.GroupByLabels, report.Spec.Metrics)
+
 	// update status
 	report.Status.Phase = cbTypes.ReportPhaseFinished
-	_, err = op.meteringClient.MeteringV1alpha1().Reports(report.Namespace).Update(report)
+	_, err = op.updateReport(report)
 	if err != nil {
 		logger.WithError(err).Warnf("failed to update report status to finished for %q", report.Name)
 	} else {
 		logger.Infof("finished report %q", report.Name)
+		op.notifyReportDependents(report.Namespace, dependencyKindReport, report.Name)
 	}
 	return nil
 }
 
+// handleReportRunFailure records the failed attempt to run the report, and
+// either schedules it to be retried according to its retry policy, or marks
+// it as failed if it has no attempts left. Runs which timed out are not
+// retried, since they're likely to time out again. Returns true if the
+// report will be retried.
+func (op *Reporting) handleReportRunFailure(logger log.FieldLogger, report *cbTypes.Report, attemptStart, attemptEnd time.Time, reason string, err error) bool {
+	report.Status.Attempts = append(report.Status.Attempts, cbTypes.ReportAttempt{
+		StartTime: metav1.Time{Time: attemptStart},
+		EndTime:   metav1.Time{Time: attemptEnd},
+		Error:     err.Error(),
+		Reason:    reason,
+	})
+	retryPolicy := op.getReportRetryPolicy(report.Spec.RetryPolicy)
+	retryTime, retry := retryPolicy.nextRetryTime(report.Status.Attempts)
+	if reason == cbTypes.ReportDeadlineExceededReason {
+		retry = false
+	}
+	if !retry {
+		report.Status.NextRetryTime = nil
+		op.setReportError(logger, report, err, fmt.Sprintf("report execution failed after %d attempts", len(report.Status.Attempts)))
+		return false
+	}
+
+	logger.WithError(err).Warnf("report execution failed on attempt %d of %d, retrying at %s", len(report.Status.Attempts), retryPolicy.MaxAttempts, retryTime)
+	report.Status.Phase = cbTypes.ReportPhaseWaiting
+	report.Status.Output = fmt.Sprintf("attempt %d of %d failed, retrying at %s: %s", len(report.Status.Attempts), retryPolicy.MaxAttempts, retryTime, err)
+	report.Status.NextRetryTime = &metav1.Time{Time: retryTime}
+	report, err = op.updateReport(report)
+	if err != nil {
+		logger.WithError(err).Errorf("unable to update report status with failed attempt")
+		return false
+	}
+	key, err := cache.MetaNamespaceKeyFunc(report)
+	if err == nil {
+		op.queues.reportQueue.AddAfter(key, retryTime.Sub(attemptEnd))
+	}
+	return true
+}
+
 func (op *Reporting) setReportError(logger log.FieldLogger, report *cbTypes.Report, err error, errMsg string) {
 	logger.WithField("report", report.Name).WithError(err).Errorf(errMsg)
 	report.Status.Phase = cbTypes.ReportPhaseError
 	report.Status.Output = err.Error()
-	_, err = op.meteringClient.MeteringV1alpha1().Reports(report.Namespace).Update(report)
+	notifications := op.sendReportNotifications(context.Background(), logger, op.newReportRun(report, nil, err), report.Spec.Notifications)
+	report.Status.Notifications = mergeNotificationStatuses(report.Status.Notifications, notifications)
+	_, err = op.updateReport(report)
 	if err != nil {
 		logger.WithError(err).Errorf("unable to update report status to error")
 	}
 }
+
+// exportFinishedReportMetrics exports the results of a finished report if
+// they aren't already, such as after the reporting-operator restarts.
+func (op *Reporting) exportFinishedReportMetrics(logger log.FieldLogger, report *cbTypes.Report) {
+	key := reportMetricsKey{kind: "Report", namespace: report.Namespace, name: report.Name}
+	if report.Spec.Metrics == nil {
+		op.reportMetrics.delete(key)
+		return
+	}
+	if op.reportMetrics.has(key) {
+		return
+	}
+	genQuery, err := op.informers.Metering().V1alpha1().ReportGenerationQueries().Lister().ReportGenerationQueries(report.Namespace).Get(report.Spec.GenerationQueryName)
+	if err != nil {
+		logger.WithError(err).Errorf("failed to get report generation query to export report metrics")
+		return
+	}
+	op.exportReportMetrics(logger, "Report", report.Namespace, report.Name, op.namespacedReportTableName(report.Namespace, report.Name), genQuery, report.Spec.GroupByLabels, report.Spec.Metrics)
+}
+
+// newReportRun returns the run of the report which notifications are sent
+// for, where err is the error the run failed with.
+func (op *Reporting) newReportRun(report *cbTypes.Report, genQuery *cbTypes.ReportGenerationQuery, err error) reportRun {
+	return reportRun{
+		kind:            "Report",
+		namespace:       report.Namespace,
+		name:            report.Name,
+		tableName:       op.namespacedReportTableName(report.Namespace, report.Name),
+		generationQuery: genQuery,
+		groupByLabels:   report.Spec.GroupByLabels,
+		periodStart:     report.Spec.ReportingStart.Time,
+		periodEnd:       report.Spec.ReportingEnd.Time,
+		err:             err,
+	}
+}
@@ -0,0 +1,91 @@
+package operator
+
+import (
+	"time"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+// ReportRetryPolicy controls how Report and ScheduledReport runs which fail
+// are retried.
+type ReportRetryPolicy struct {
+	// MaxAttempts is the maximum number of times a run is attempted,
+	// including the first attempt.
+	MaxAttempts int
+	// Backoff is how long to wait before the first retry, and doubles after
+	// each subsequent failed attempt.
+	Backoff time.Duration
+	// MaxBackoff is the longest to wait between attempts.
+	MaxBackoff time.Duration
+	// Deadline, if non-zero, is how long after the first attempt started a
+	// run may be retried.
+	Deadline time.Duration
+}
+
+// getReportRetryPolicy returns the default retry policy, overridden by any
+// fields set in the report's retryPolicy.
+func (op *Reporting) getReportRetryPolicy(policy *cbTypes.ReportRetryPolicy) ReportRetryPolicy {
+	retryPolicy := op.cfg.DefaultReportRetryPolicy
+	if policy != nil {
+		if policy.MaxAttempts != 0 {
+			retryPolicy.MaxAttempts = policy.MaxAttempts
+		}
+		if policy.Backoff != nil {
+			retryPolicy.Backoff = policy.Backoff.Duration
+		}
+		if policy.MaxBackoff != nil {
+			retryPolicy.MaxBackoff = policy.MaxBackoff.Duration
+		}
+		if policy.Deadline != nil {
+			retryPolicy.Deadline = policy.Deadline.Duration
+		}
+	}
+	if retryPolicy.MaxAttempts < 1 {
+		retryPolicy.MaxAttempts = 1
+	}
+	return retryPolicy
+}
+
+// nextRetryTime returns when a run which failed each of the attempts should
+// be retried, and false if it has no attempts left or retrying would exceed
+// the deadline.
+func (p ReportRetryPolicy) nextRetryTime(attempts []cbTypes.ReportAttempt) (time.Time, bool) {
+	if len(attempts) == 0 || len(attempts) >= p.MaxAttempts {
+		return time.Time{}, false
+	}
+
+	backoff := p.Backoff
+	for i := 1; i < len(attempts); i++ {
+		backoff *= 2
+		if p.MaxBackoff != 0 && backoff > p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff != 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+
+	retryTime := attempts[len(attempts)-1].EndTime.Add(backoff)
+	if p.Deadline != 0 && retryTime.After(attempts[0].StartTime.Add(p.Deadline)) {
+		return time.Time{}, false
+	}
+	return retryTime, true
+}
+
+// scheduledRetryTime returns when a ScheduledReport run which failed each of
+// the attempts should be attempted again, and true if it has no attempts
+// left. Runs with no attempts left, including runs which timed out, are
+// attempted again with a new set of attempts the next time the schedule is
+// due, so that ScheduledReports recover from outages outlasting the policy.
+func (p ReportRetryPolicy) scheduledRetryTime(schedule reportSchedule, attempts []cbTypes.ReportAttempt) (time.Time, bool) {
+	if len(attempts) == 0 {
+		return time.Time{}, false
+	}
+	last := attempts[len(attempts)-1]
+	if last.Reason != cbTypes.ReportDeadlineExceededReason {
+		if retryTime, retry := p.nextRetryTime(attempts); retry {
+			return retryTime, false
+		}
+	}
+	return schedule.Next(last.EndTime.Time), true
+}
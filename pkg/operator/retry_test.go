@@ -0,0 +1,143 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+func TestReportRetryPolicyNextRetryTime(t *testing.T) {
+	start := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	// newAttempts returns n attempts, each taking a minute, starting a minute
+	// after the previous attempt ended.
+	newAttempts := func(n int) []cbTypes.ReportAttempt {
+		attempts := make([]cbTypes.ReportAttempt, n)
+		for i := range attempts {
+			attemptStart := start.Add(time.Duration(2*i) * time.Minute)
+			attempts[i] = cbTypes.ReportAttempt{
+				StartTime: meta.Time{Time: attemptStart},
+				EndTime:   meta.Time{Time: attemptStart.Add(time.Minute)},
+				Error:     "presto is unavailable",
+			}
+		}
+		return attempts
+	}
+	policy := ReportRetryPolicy{
+		MaxAttempts: 5,
+		Backoff:     time.Minute,
+		MaxBackoff:  5 * time.Minute,
+	}
+
+	tests := map[string]struct {
+		policy            ReportRetryPolicy
+		attempts          []cbTypes.ReportAttempt
+		expectRetry       bool
+		expectedRetryTime time.Time
+	}{
+		"no attempts": {
+			policy:      policy,
+			expectRetry: false,
+		},
+		"first failure": {
+			policy:            policy,
+			attempts:          newAttempts(1),
+			expectRetry:       true,
+			expectedRetryTime: start.Add(2 * time.Minute),
+		},
+		"backoff doubles": {
+			policy:            policy,
+			attempts:          newAttempts(3),
+			expectRetry:       true,
+			expectedRetryTime: start.Add(5*time.Minute + 4*time.Minute),
+		},
+		"backoff capped at maxBackoff": {
+			policy:            policy,
+			attempts:          newAttempts(4),
+			expectRetry:       true,
+			expectedRetryTime: start.Add(7*time.Minute + 5*time.Minute),
+		},
+		"no attempts left": {
+			policy:      policy,
+			attempts:    newAttempts(5),
+			expectRetry: false,
+		},
+		"retries disabled": {
+			policy:      ReportRetryPolicy{MaxAttempts: 1, Backoff: time.Minute},
+			attempts:    newAttempts(1),
+			expectRetry: false,
+		},
+		"retry would exceed deadline": {
+			policy:      ReportRetryPolicy{MaxAttempts: 5, Backoff: time.Minute, Deadline: 4 * time.Minute},
+			attempts:    newAttempts(2),
+			expectRetry: false,
+		},
+		"retry within deadline": {
+			policy:            ReportRetryPolicy{MaxAttempts: 5, Backoff: time.Minute, Deadline: 10 * time.Minute},
+			attempts:          newAttempts(2),
+			expectRetry:       true,
+			expectedRetryTime: start.Add(3*time.Minute + 2*time.Minute),
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			retryTime, retry := tt.policy.nextRetryTime(tt.attempts)
+			assert.Equal(t, tt.expectRetry, retry)
+			assert.Equal(t, tt.expectedRetryTime, retryTime)
+		})
+	}
+}
+
+func TestReportRetryPolicyScheduledRetryTime(t *testing.T) {
+	start := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	schedule, err := getSchedule(cbTypes.ScheduledReportSchedule{Period: cbTypes.ScheduledReportPeriodDaily}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := ReportRetryPolicy{MaxAttempts: 2, Backoff: time.Minute}
+	failed := cbTypes.ReportAttempt{
+		StartTime: meta.Time{Time: start},
+		EndTime:   meta.Time{Time: start.Add(time.Minute)},
+		Error:     "presto is unavailable",
+	}
+	timedOut := failed
+	timedOut.Reason = cbTypes.ReportDeadlineExceededReason
+
+	tests := map[string]struct {
+		attempts          []cbTypes.ReportAttempt
+		expectedRetryTime time.Time
+		expectExhausted   bool
+	}{
+		"no attempts": {},
+		"attempts left": {
+			attempts:          []cbTypes.ReportAttempt{failed},
+			expectedRetryTime: start.Add(2 * time.Minute),
+		},
+		"no attempts left": {
+			attempts:          []cbTypes.ReportAttempt{failed, failed},
+			expectedRetryTime: start.AddDate(0, 0, 1),
+			expectExhausted:   true,
+		},
+		"timed out": {
+			attempts:          []cbTypes.ReportAttempt{timedOut},
+			expectedRetryTime: start.AddDate(0, 0, 1),
+			expectExhausted:   true,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			retryTime, exhausted := policy.scheduledRetryTime(schedule, tt.attempts)
+			assert.Equal(t, tt.expectExhausted, exhausted)
+			assert.Equal(t, tt.expectedRetryTime, retryTime)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	DefaultRowLevelSecurityNamespaceColumn = "namespace"
+	DefaultRowLevelSecurityVerb            = "list"
+	DefaultRowLevelSecurityResource        = "pods"
+
+	// allNamespaces in the namespaces of a NamespaceMapping grants access to
+	// the rows of every namespace.
+	allNamespaces = "*"
+)
+
+// RowLevelSecurityConfig restricts the rows of report results returned by
+// the HTTP API to those of the namespaces the authenticated user can
+// access, so that reports covering every namespace can be shared with users
+// who can only access some of them.
+type RowLevelSecurityConfig struct {
+	Enabled bool
+	// NamespaceColumn is the column of report results containing the
+	// namespace of each row. Results without the column aren't filtered.
+	NamespaceColumn string
+	// Verb and Resource are the access to the core API group resource a
+	// user must have in a namespace to see its rows, which is checked using
+	// SubjectAccessReviews. If Verb is empty, only Mappings are used.
+	Verb     string
+	Resource string
+	// Mappings grant users and groups access to the rows of namespaces,
+	// in addition to those they can access.
+	Mappings []NamespaceMapping
+}
+
+// NamespaceMapping grants users and groups access to the rows of the
+// namespaces.
+type NamespaceMapping struct {
+	Users  []string `json:"users,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+	// Namespaces are the namespaces whose rows the users and groups can
+	// see, or "*" for every namespace.
+	Namespaces []string `json:"namespaces"`
+}
+
+// ParseNamespaceMappings parses a JSON list of NamespaceMappings.
+func ParseNamespaceMappings(s string) ([]NamespaceMapping, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var mappings []NamespaceMapping
+	if err := json.Unmarshal([]byte(s), &mappings); err != nil {
+		return nil, fmt.Errorf("invalid row-level security namespace mappings: %v", err)
+	}
+	return mappings, nil
+}
+
+func (cfg RowLevelSecurityConfig) Valid() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.NamespaceColumn == "" {
+		return fmt.Errorf("the row-level security namespace column must be set")
+	}
+	if cfg.Verb != "" && cfg.Resource == "" {
+		return fmt.Errorf("the row-level security resource must be set when the verb is set")
+	}
+	for i, mapping := range cfg.Mappings {
+		if len(mapping.Users) == 0 && len(mapping.Groups) == 0 {
+			return fmt.Errorf("row-level security namespace mapping %d must have users or groups", i)
+		}
+		if len(mapping.Namespaces) == 0 {
+			return fmt.Errorf("row-level security namespace mapping %d must have namespaces", i)
+		}
+	}
+	return nil
+}
+
+// mappedNamespaces returns the namespaces the Mappings grant the user
+// access to.
+func (cfg RowLevelSecurityConfig) mappedNamespaces(user authenticationv1.UserInfo) map[string]bool {
+	namespaces := make(map[string]bool)
+	for _, mapping := range cfg.Mappings {
+		if !mappingIncludesUser(mapping, user) {
+			continue
+		}
+		for _, namespace := range mapping.Namespaces {
+			namespaces[namespace] = true
+		}
+	}
+	return namespaces
+}
+
+func mappingIncludesUser(mapping NamespaceMapping, user authenticationv1.UserInfo) bool {
+	for _, name := range mapping.Users {
+		if name == user.Username {
+			return true
+		}
+	}
+	for _, group := range mapping.Groups {
+		for _, userGroup := range user.Groups {
+			if group == userGroup {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// restricts returns true if the user can only see the rows of some
+// namespaces.
+func (cfg RowLevelSecurityConfig) restricts(user authenticationv1.UserInfo) bool {
+	return cfg.Enabled && !cfg.mappedNamespaces(user)[allNamespaces]
+}
+
+// unrestrictedUserHandler wraps the handlers of routes returning rows which
+// can't be restricted to namespaces, such as the results of ad-hoc queries,
+// rejecting requests from users row-level security restricts.
+func (auth *apiAuth) unrestrictedUserHandler(srv *server, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, _ := authenticatedUser(r.Context())
+		if auth.rowLevelSecurity.restricts(user) {
+			logger := newRequestLogger(srv.logger, r, srv.rand)
+			writeErrorResponse(logger, w, r, http.StatusForbidden, "row-level security restricts user %s to the rows of some namespaces, so it cannot run queries or get their results", user.Username)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// rowLevelSecurityPredicate returns a predicate matching the rows of the
+// table in the namespaces the request's user can access, or an empty string
+// if every row can be returned. Users are checked for access to each
+// namespace in the table which isn't granted by the Mappings.
+func (auth *apiAuth) rowLevelSecurityPredicate(queryer presto.Queryer, r *http.Request, tableName string, columns []presto.Column) (string, error) {
+	cfg := auth.rowLevelSecurity
+	if !cfg.Enabled {
+		return "", nil
+	}
+	var namespaceColumn *presto.Column
+	for i := range columns {
+		if columns[i].Name == cfg.NamespaceColumn {
+			namespaceColumn = &columns[i]
+			break
+		}
+	}
+	if namespaceColumn == nil {
+		return "", nil
+	}
+	user, ok := authenticatedUser(r.Context())
+	if !ok {
+		return "", fmt.Errorf("row-level security requires the request to be authenticated")
+	}
+
+	allowed := cfg.mappedNamespaces(user)
+	if allowed[allNamespaces] {
+		return "", nil
+	}
+	if cfg.Verb != "" {
+		rows, err := queryer.Query(fmt.Sprintf("SELECT DISTINCT %s FROM %s", presto.GenerateQuotedColumnsListSQL([]presto.Column{*namespaceColumn}), tableName))
+		if err != nil {
+			return "", fmt.Errorf("unable to get the namespaces of the results: %v", err)
+		}
+		tokenHash := hashToken(bearerToken(r))
+		for _, row := range rows {
+			namespace, _ := row[namespaceColumn.Name].(string)
+			if namespace == "" || allowed[namespace] {
+				continue
+			}
+			spec := userAccessReviewSpec(user)
+			spec.ResourceAttributes = &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      cfg.Verb,
+				Resource:  cfg.Resource,
+			}
+			canAccess, _, err := auth.authorize(spec, tokenHash)
+			if err != nil {
+				return "", fmt.Errorf("unable to authorize access to namespace %s: %v", namespace, err)
+			}
+			if canAccess {
+				allowed[namespace] = true
+			}
+		}
+	}
+
+	namespaces := make([]string, 0, len(allowed))
+	for namespace := range allowed {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return presto.GenerateInSQL(*namespaceColumn, namespaces)
+}
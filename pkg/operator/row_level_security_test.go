@@ -0,0 +1,211 @@
+package operator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+func TestRowLevelSecurity(t *testing.T) {
+	const (
+		namespace  = "metering"
+		reportName = "namespace-cost"
+		tableName  = "report_namespace_cost"
+	)
+	start := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	columns := []v1alpha1.ReportGenerationQueryColumn{{Name: "namespace", Type: "string"}, {Name: "cost", Type: "double"}}
+	tableColumns := []hive.Column{{Name: "namespace", Type: "string"}, {Name: "cost", Type: "double"}}
+
+	reportIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	reportGenerationQueryIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	prestoTableIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	reportIndexer.Add(newTestReport(reportName, namespace, "test-query", start, end, v1alpha1.ReportStatus{Phase: v1alpha1.ReportPhaseFinished}))
+	reportGenerationQueryIndexer.Add(newTestReportGenQuery("test-query", namespace, columns))
+	prestoTableIndexer.Add(newTestPrestoTable(reportName, namespace, tableColumns))
+	meteringListers := meteringListers{
+		reports:                 listers.NewReportLister(reportIndexer).Reports(namespace),
+		reportGenerationQueries: listers.NewReportGenerationQueryLister(reportGenerationQueryIndexer).ReportGenerationQueries(namespace),
+		prestoTables:            listers.NewPrestoTableLister(prestoTableIndexer).PrestoTables(namespace),
+	}
+	prestoColumns := []presto.Column{{Name: "namespace", Type: "VARCHAR"}, {Name: "cost", Type: "DOUBLE"}}
+	const distinctSQL = `SELECT DISTINCT "namespace" FROM ` + tableName
+
+	tests := map[string]struct {
+		token          string
+		filter         string
+		expectDistinct bool
+		expectedWhere  string
+	}{
+		"user who can access some namespaces": {
+			token:          "bob-token",
+			expectDistinct: true,
+			expectedWhere:  `"namespace" IN ('team-a')`,
+		},
+		"user who can access no namespaces": {
+			token:          "dave-token",
+			expectDistinct: true,
+			expectedWhere:  "false",
+		},
+		"group mapped to a namespace": {
+			token:          "carol-token",
+			filter:         "cost>10",
+			expectDistinct: true,
+			expectedWhere:  `"cost" > DOUBLE '10' AND "namespace" IN ('team-a', 'team-b')`,
+		},
+		"user mapped to every namespace": {
+			token: "alice-token",
+		},
+	}
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			queryer := mockpresto.NewMockExecQueryer(ctrl)
+			if tt.expectDistinct {
+				queryer.EXPECT().Query(distinctSQL).Return([]presto.Row{{"namespace": "team-a"}, {"namespace": "team-b"}, {"namespace": "team-c"}}, nil)
+			}
+			queryer.EXPECT().Query(presto.GenerateGetRowsWhereSQL(tableName, prestoColumns, tt.expectedWhere)).Return(nil, nil)
+
+			tokenReviews := &fakeTokenReviews{users: map[string]authenticationv1.UserInfo{
+				"alice-token": {Username: "alice"},
+				"bob-token":   {Username: "bob"},
+				"carol-token": {Username: "carol", Groups: []string{"finance"}},
+				"dave-token":  {Username: "dave"},
+			}}
+			reportAccess := "get reports.metering.openshift.io/" + reportName + " in namespace metering"
+			accessReviews := &fakeAccessReviews{allowed: map[string][]string{
+				"alice": {reportAccess},
+				"bob":   {reportAccess, "list pods in namespace team-a"},
+				"carol": {reportAccess, "list pods in namespace team-a"},
+				"dave":  {reportAccess},
+			}}
+			auth := newAPIAuth(tokenReviews, accessReviews, namespace, time.Minute, clock.NewFakeClock(time.Now()))
+			auth.rowLevelSecurity = RowLevelSecurityConfig{
+				Enabled:         true,
+				NamespaceColumn: DefaultRowLevelSecurityNamespaceColumn,
+				Verb:            DefaultRowLevelSecurityVerb,
+				Resource:        DefaultRowLevelSecurityResource,
+				Mappings: []NamespaceMapping{
+					{Users: []string{"alice"}, Namespaces: []string{allNamespaces}},
+					{Groups: []string{"finance"}, Namespaces: []string{"team-b"}},
+				},
+			}
+			router := newRouter(testLogger, routerConfig{
+				rand:            testRand,
+				queryer:         queryer,
+				importerQueryer: queryer,
+				collectorFunc:   noopPrometheusImporterFunc,
+				listers:         meteringListers,
+				auth:            auth,
+			})
+
+			path := APIV1ReportsGetEndpoint + "?format=json&name=" + reportName
+			if tt.filter != "" {
+				path += "&filter=" + tt.filter
+			}
+			req := httptest.NewRequest("GET", path, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code, "unexpected status code, body: %s", w.Body.String())
+		})
+	}
+}
+
+func TestRowLevelSecurityUnfilteredResults(t *testing.T) {
+	tests := map[string]struct {
+		token              string
+		expectedStatusCode int
+	}{
+		"restricted user": {
+			token:              "bob-token",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		"user mapped to every namespace": {
+			token:              "alice-token",
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			tokenReviews := &fakeTokenReviews{users: map[string]authenticationv1.UserInfo{
+				"alice-token": {Username: "alice"},
+				"bob-token":   {Username: "bob"},
+			}}
+			runAccess := "get reports.metering.openshift.io in namespace metering"
+			accessReviews := &fakeAccessReviews{allowed: map[string][]string{
+				"alice": {runAccess},
+				"bob":   {runAccess},
+			}}
+			auth := newAPIAuth(tokenReviews, accessReviews, "metering", time.Minute, clock.NewFakeClock(time.Now()))
+			auth.rowLevelSecurity = RowLevelSecurityConfig{
+				Enabled:         true,
+				NamespaceColumn: DefaultRowLevelSecurityNamespaceColumn,
+				Mappings:        []NamespaceMapping{{Users: []string{"alice"}, Namespaces: []string{allNamespaces}}},
+			}
+			router := newRouter(testLogger, routerConfig{
+				rand:          testRand,
+				collectorFunc: noopPrometheusImporterFunc,
+				auth:          auth,
+			})
+
+			req := httptest.NewRequest("GET", APIV1ReportRunsEndpoint+"/unknown/results?format=json", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code, "unexpected status code, body: %s", w.Body.String())
+		})
+	}
+}
+
+func TestRowLevelSecurityConfigValid(t *testing.T) {
+	tests := map[string]struct {
+		cfg         APIAuthConfig
+		expectedErr bool
+	}{
+		"valid": {
+			cfg: APIAuthConfig{Enabled: true, RowLevelSecurity: RowLevelSecurityConfig{Enabled: true, NamespaceColumn: "namespace", Verb: "list", Resource: "pods"}},
+		},
+		"mappings only": {
+			cfg: APIAuthConfig{Enabled: true, RowLevelSecurity: RowLevelSecurityConfig{Enabled: true, NamespaceColumn: "namespace", Mappings: []NamespaceMapping{{Users: []string{"alice"}, Namespaces: []string{"team-a"}}}}},
+		},
+		"API auth disabled": {
+			cfg:         APIAuthConfig{RowLevelSecurity: RowLevelSecurityConfig{Enabled: true, NamespaceColumn: "namespace", Verb: "list", Resource: "pods"}},
+			expectedErr: true,
+		},
+		"mapping without users or groups": {
+			cfg:         APIAuthConfig{Enabled: true, RowLevelSecurity: RowLevelSecurityConfig{Enabled: true, NamespaceColumn: "namespace", Mappings: []NamespaceMapping{{Namespaces: []string{"team-a"}}}}},
+			expectedErr: true,
+		},
+		"verb without resource": {
+			cfg:         APIAuthConfig{Enabled: true, RowLevelSecurity: RowLevelSecurityConfig{Enabled: true, NamespaceColumn: "namespace", Verb: "list"}},
+			expectedErr: true,
+		},
+	}
+	for name, tt := range tests {
+		err := tt.cfg.Valid()
+		if tt.expectedErr {
+			assert.Error(t, err, name)
+		} else {
+			assert.NoError(t, err, name)
+		}
+	}
+}
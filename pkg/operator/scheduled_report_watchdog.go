@@ -0,0 +1,190 @@
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+)
+
+const scheduledReportWatchdogInterval = time.Minute
+
+var (
+	scheduledReportStaleGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "metering",
+			Name:      "scheduled_report_stale",
+			Help:      "Set to 1 if a ScheduledReport's last successful run is older than its schedule period plus tolerance, and 0 otherwise.",
+		},
+		[]string{"namespace", "scheduledreport"},
+	)
+	scheduledReportLastReportTimeGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "metering",
+			Name:      "scheduled_report_last_report_time_seconds",
+			Help:      "The end of the most recent reporting period a ScheduledReport successfully ran for, as a unix timestamp.",
+		},
+		[]string{"namespace", "scheduledreport"},
+	)
+)
+
+type scheduledReportWatchdogKey struct {
+	namespace, name string
+}
+
+func init() {
+	prometheus.MustRegister(scheduledReportStaleGauge)
+	prometheus.MustRegister(scheduledReportLastReportTimeGauge)
+}
+
+// runScheduledReportWatchdog periodically checks every ScheduledReport in the
+// watched namespaces, including tenant namespaces if they're enabled, to
+// determine if it has missed running for one of its reporting periods,
+// exporting a metric and recording an event when ScheduledReports become
+// stale. The watchdog doesn't update ScheduledReports, as their jobs update
+// them concurrently; instead, jobs set the Stale condition along with the
+// rest of their status, using setScheduledReportStaleCondition.
+func (op *Reporting) runScheduledReportWatchdog(stopCh <-chan struct{}) {
+	logger := op.logger.WithField("component", "scheduledReportWatchdog")
+	logger.Infof("ScheduledReport watchdog started")
+
+	for {
+		select {
+		case <-stopCh:
+			logger.Infof("ScheduledReport watchdog exiting")
+			return
+		case <-op.clock.Tick(scheduledReportWatchdogInterval):
+			reports, err := op.informers.Metering().V1alpha1().ScheduledReports().Lister().List(labels.Everything())
+			if err != nil {
+				logger.WithError(err).Errorf("unable to list scheduledReports")
+				continue
+			}
+			seen := make(map[scheduledReportWatchdogKey]struct{})
+			for _, report := range reports {
+				seen[scheduledReportWatchdogKey{namespace: report.Namespace, name: report.Name}] = struct{}{}
+				err := op.checkScheduledReportStale(logger.WithFields(log.Fields{"namespace": report.Namespace, "scheduledReport": report.Name}), report)
+				if err != nil {
+					logger.WithError(err).Errorf("unable to check if scheduledReport %s/%s is stale", report.Namespace, report.Name)
+				}
+			}
+			op.scheduledReportWatchdogForgetDeleted(seen)
+		}
+	}
+}
+
+// scheduledReportWatchdogForgetDeleted removes the metrics for
+// ScheduledReports which no longer exist.
+func (op *Reporting) scheduledReportWatchdogForgetDeleted(existing map[scheduledReportWatchdogKey]struct{}) {
+	op.staleScheduledReportsMu.Lock()
+	defer op.staleScheduledReportsMu.Unlock()
+	for key := range op.staleScheduledReports {
+		if _, exists := existing[key]; !exists {
+			scheduledReportStaleGauge.DeleteLabelValues(key.namespace, key.name)
+			scheduledReportLastReportTimeGauge.DeleteLabelValues(key.namespace, key.name)
+			delete(op.staleScheduledReports, key)
+		}
+	}
+}
+
+func (op *Reporting) checkScheduledReportStale(logger log.FieldLogger, report *cbTypes.ScheduledReport) error {
+	stale, deadline, err := op.getScheduledReportStaleness(report)
+	if err != nil {
+		return err
+	}
+
+	key := scheduledReportWatchdogKey{namespace: report.Namespace, name: report.Name}
+	op.staleScheduledReportsMu.Lock()
+	wasStale, checked := op.staleScheduledReports[key]
+	op.staleScheduledReports[key] = stale
+	op.staleScheduledReportsMu.Unlock()
+
+	if report.Status.LastReportTime != nil {
+		scheduledReportLastReportTimeGauge.WithLabelValues(report.Namespace, report.Name).Set(float64(report.Status.LastReportTime.Unix()))
+	}
+
+	if stale {
+		scheduledReportStaleGauge.WithLabelValues(report.Namespace, report.Name).Set(1)
+		if !wasStale {
+			msg := scheduledReportStaleMessage(report, deadline)
+			logger.Warnf("scheduledReport is stale: %s", msg)
+			op.eventRecorder.Event(report, v1.EventTypeWarning, cbutil.LastReportTooOldReason, msg)
+		}
+	} else {
+		scheduledReportStaleGauge.WithLabelValues(report.Namespace, report.Name).Set(0)
+		if checked && wasStale {
+			logger.Infof("scheduledReport is no longer stale")
+		}
+	}
+	return nil
+}
+
+// setScheduledReportStaleCondition sets the Stale condition of the
+// ScheduledReport if it's stale, and removes it otherwise. It's called by the
+// ScheduledReport's job before it updates the ScheduledReport, so that the
+// condition is updated without conflicting with the job's own updates.
+func (op *Reporting) setScheduledReportStaleCondition(logger log.FieldLogger, report *cbTypes.ScheduledReport) {
+	stale, deadline, err := op.getScheduledReportStaleness(report)
+	if err != nil {
+		logger.WithError(err).Warnf("unable to check if scheduledReport %s is stale", report.Name)
+		return
+	}
+	if !stale {
+		cbutil.RemoveScheduledReportCondition(&report.Status, cbTypes.ScheduledReportStale)
+		return
+	}
+	if cond := cbutil.GetScheduledReportCondition(report.Status, cbTypes.ScheduledReportStale); cond != nil && cond.Status == v1.ConditionTrue {
+		return
+	}
+	cond := cbutil.NewScheduledReportCondition(cbTypes.ScheduledReportStale, v1.ConditionTrue, cbutil.LastReportTooOldReason, scheduledReportStaleMessage(report, deadline))
+	cbutil.SetScheduledReportCondition(&report.Status, *cond)
+}
+
+// getScheduledReportStaleness returns whether the ScheduledReport is stale
+// now, and the time by which its next report should have run.
+func (op *Reporting) getScheduledReportStaleness(report *cbTypes.ScheduledReport) (bool, time.Time, error) {
+	schedule, err := getSchedule(report.Spec.Schedule, report.Spec.Timezone)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+
+	var gracePeriod time.Duration
+	if report.Spec.GracePeriod != nil {
+		gracePeriod = report.Spec.GracePeriod.Duration
+	} else {
+		gracePeriod = op.getDefaultReportGracePeriod()
+	}
+
+	stale, deadline := isScheduledReportStale(report, schedule, gracePeriod, op.cfg.ScheduledReportStaleTolerance, op.clock.Now().UTC())
+	return stale, deadline, nil
+}
+
+func scheduledReportStaleMessage(report *cbTypes.ScheduledReport, deadline time.Time) string {
+	return fmt.Sprintf("the next reporting period should have finished by %s, but the last report time is %s", deadline, formatLastReportTime(report))
+}
+
+// isScheduledReportStale returns true if the ScheduledReport should have run
+// for the period following its lastReportTime, plus the gracePeriod and
+// tolerance, by now. If the ScheduledReport hasn't run yet, the period
+// following its creation is used. Also returns the time by which the next
+// report should have run.
+func isScheduledReportStale(report *cbTypes.ScheduledReport, schedule reportSchedule, gracePeriod, tolerance time.Duration, now time.Time) (bool, time.Time) {
+	lastScheduled := report.CreationTimestamp.Time
+	if report.Status.LastReportTime != nil {
+		lastScheduled = report.Status.LastReportTime.Time
+	}
+	deadline := schedule.Next(lastScheduled).Add(gracePeriod).Add(tolerance).UTC()
+	return now.After(deadline), deadline
+}
+
+func formatLastReportTime(report *cbTypes.ScheduledReport) string {
+	if report.Status.LastReportTime == nil {
+		return "unset"
+	}
+	return report.Status.LastReportTime.UTC().String()
+}
@@ -0,0 +1,144 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+)
+
+func TestIsScheduledReportStale(t *testing.T) {
+	created := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	lastReportTime := time.Date(2018, time.July, 2, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		lastReportTime *time.Time
+		now            time.Time
+		expectStale    bool
+	}{
+		"never ran, within first period": {
+			now:         created.Add(12 * time.Hour),
+			expectStale: false,
+		},
+		"never ran, past first period and tolerance": {
+			now:         created.Add(26 * time.Hour),
+			expectStale: true,
+		},
+		"ran recently": {
+			lastReportTime: &lastReportTime,
+			now:            lastReportTime.Add(24*time.Hour + 30*time.Minute),
+			expectStale:    false,
+		},
+		"missed a period": {
+			lastReportTime: &lastReportTime,
+			now:            lastReportTime.Add(48 * time.Hour),
+			expectStale:    true,
+		},
+	}
+
+	schedule, err := getSchedule(v1alpha1.ScheduledReportSchedule{Period: v1alpha1.ScheduledReportPeriodDaily}, "")
+	require.NoError(t, err)
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			report := &v1alpha1.ScheduledReport{
+				ObjectMeta: meta.ObjectMeta{
+					Name:              "test-report",
+					CreationTimestamp: meta.Time{Time: created},
+				},
+			}
+			if tt.lastReportTime != nil {
+				report.Status.LastReportTime = &meta.Time{Time: *tt.lastReportTime}
+			}
+			stale, _ := isScheduledReportStale(report, schedule, 5*time.Minute, time.Hour, tt.now)
+			assert.Equal(t, tt.expectStale, stale)
+		})
+	}
+}
+
+func TestSetScheduledReportStaleCondition(t *testing.T) {
+	lastReportTime := time.Date(2018, time.July, 2, 0, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(lastReportTime.Add(48 * time.Hour))
+	op := &Reporting{
+		clock: fakeClock,
+		cfg:   Config{ScheduledReportStaleTolerance: time.Hour},
+	}
+	report := &v1alpha1.ScheduledReport{
+		ObjectMeta: meta.ObjectMeta{Name: "test-report"},
+		Spec: v1alpha1.ScheduledReportSpec{
+			Schedule:    v1alpha1.ScheduledReportSchedule{Period: v1alpha1.ScheduledReportPeriodDaily},
+			GracePeriod: &meta.Duration{Duration: 5 * time.Minute},
+		},
+		Status: v1alpha1.ScheduledReportStatus{
+			LastReportTime: &meta.Time{Time: lastReportTime},
+		},
+	}
+
+	op.setScheduledReportStaleCondition(testLogger, report)
+	cond := cbutil.GetScheduledReportCondition(report.Status, v1alpha1.ScheduledReportStale)
+	require.NotNil(t, cond, "a report which missed a period is stale")
+	assert.Equal(t, v1.ConditionTrue, cond.Status)
+	assert.Equal(t, cbutil.LastReportTooOldReason, cond.Reason)
+
+	// the job sets the Stale condition with the new last report time after
+	// it catches up.
+	report.Status.LastReportTime = &meta.Time{Time: lastReportTime.Add(48 * time.Hour)}
+	op.setScheduledReportStaleCondition(testLogger, report)
+	assert.Nil(t, cbutil.GetScheduledReportCondition(report.Status, v1alpha1.ScheduledReportStale))
+}
+
+func TestCheckScheduledReportStaleNamespaces(t *testing.T) {
+	lastReportTime := time.Date(2018, time.July, 2, 0, 0, 0, 0, time.UTC)
+	op := &Reporting{
+		clock:                 clock.NewFakeClock(lastReportTime.Add(48 * time.Hour)),
+		cfg:                   Config{ScheduledReportStaleTolerance: time.Hour},
+		eventRecorder:         record.NewFakeRecorder(10),
+		staleScheduledReports: make(map[scheduledReportWatchdogKey]bool),
+	}
+	newReport := func(namespace string, lastReportTime time.Time) *v1alpha1.ScheduledReport {
+		return &v1alpha1.ScheduledReport{
+			ObjectMeta: meta.ObjectMeta{Namespace: namespace, Name: "test-report"},
+			Spec: v1alpha1.ScheduledReportSpec{
+				Schedule:    v1alpha1.ScheduledReportSchedule{Period: v1alpha1.ScheduledReportPeriodDaily},
+				GracePeriod: &meta.Duration{Duration: 5 * time.Minute},
+			},
+			Status: v1alpha1.ScheduledReportStatus{
+				LastReportTime: &meta.Time{Time: lastReportTime},
+			},
+		}
+	}
+	staleValue := func(namespace string) float64 {
+		var m dto.Metric
+		require.NoError(t, scheduledReportStaleGauge.WithLabelValues(namespace, "test-report").(prometheus.Metric).Write(&m))
+		return m.Gauge.GetValue()
+	}
+
+	// same-named ScheduledReports in different namespaces are tracked
+	// separately.
+	require.NoError(t, op.checkScheduledReportStale(testLogger, newReport("metering", lastReportTime)))
+	require.NoError(t, op.checkScheduledReportStale(testLogger, newReport("tenant-a", lastReportTime.Add(48*time.Hour))))
+	assert.Equal(t, float64(1), staleValue("metering"))
+	assert.Equal(t, float64(0), staleValue("tenant-a"))
+	assert.Equal(t, map[scheduledReportWatchdogKey]bool{
+		{namespace: "metering", name: "test-report"}: true,
+		{namespace: "tenant-a", name: "test-report"}: false,
+	}, op.staleScheduledReports)
+
+	op.scheduledReportWatchdogForgetDeleted(map[scheduledReportWatchdogKey]struct{}{
+		{namespace: "tenant-a", name: "test-report"}: {},
+	})
+	assert.Equal(t, map[scheduledReportWatchdogKey]bool{
+		{namespace: "tenant-a", name: "test-report"}: false,
+	}, op.staleScheduledReports)
+}
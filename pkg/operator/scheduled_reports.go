@@ -1,6 +1,7 @@
 package operator
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -16,8 +17,13 @@ import (
 	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
 	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
 	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/presto"
 )
 
+// scheduledReportJobRestartDelay is how long to wait before restarting a
+// scheduledReport job which exited without being stopped.
+const scheduledReportJobRestartDelay = time.Minute
+
 func (op *Reporting) runScheduledReportWorker() {
 	logger := op.logger.WithField("component", "scheduledReportWorker")
 	logger.Infof("ScheduledReport worker started")
@@ -36,7 +42,9 @@ func (op *Reporting) processScheduledReport(logger log.FieldLogger) bool {
 
 	logger = logger.WithFields(newLogIdentifier(op.rand))
 	if key, ok := op.getKeyFromQueueObj(logger, "ScheduledReport", obj, op.queues.scheduledReportQueue); ok {
+		start := op.clock.Now()
 		err := op.syncScheduledReport(logger, key)
+		op.observeReconcile("ScheduledReport", start, err)
 		op.handleErr(logger, err, "ScheduledReport", obj, op.queues.scheduledReportQueue)
 	}
 	return true
@@ -58,6 +66,7 @@ func (op *Reporting) syncScheduledReport(logger log.FieldLogger, key string) err
 				job.stop(true)
 				logger.Infof("stopped running jobs for ScheduledReport")
 			}
+			op.reportMetrics.delete(reportMetricsKey{kind: "ScheduledReport", namespace: namespace, name: name})
 			return nil
 		}
 		return err
@@ -99,13 +108,25 @@ type reportSchedule interface {
 	// Return the next activation time, later than the given time.
 	// Next is invoked initially, and then each time the job runs..
 	Next(time.Time) time.Time
+	// Location returns the timezone the schedule is evaluated in.
+	Location() *time.Location
 }
 
-func getSchedule(reportSched cbTypes.ScheduledReportSchedule) (reportSchedule, error) {
+// getSchedule returns the schedule for reportSched, evaluated in timezone,
+// so that periods begin at the hour, day, week or month boundaries of that
+// timezone. A cron schedule's own timezone takes precedence over timezone.
+func getSchedule(reportSched cbTypes.ScheduledReportSchedule, timezone string) (reportSchedule, error) {
 	var cronSpec string
 	switch reportSched.Period {
 	case cbTypes.ScheduledReportPeriodCron:
-		return cron.ParseStandard(reportSched.Cron.Expression)
+		if reportSched.Cron == nil {
+			return nil, fmt.Errorf("ScheduledReport.spec.schedule.cron must be set when period is %s", cbTypes.ScheduledReportPeriodCron)
+		}
+		cronTimezone := reportSched.Cron.Timezone
+		if cronTimezone == "" {
+			cronTimezone = timezone
+		}
+		return parseCronSchedule(reportSched.Cron.Expression, cronTimezone)
 	case cbTypes.ScheduledReportPeriodHourly:
 		sched := reportSched.Hourly
 		if sched == nil {
@@ -181,15 +202,34 @@ func getSchedule(reportSched cbTypes.ScheduledReportSchedule) (reportSchedule, e
 	default:
 		return nil, fmt.Errorf("invalid ScheduledReport.spec.schedule.period: %s", reportSched.Period)
 	}
-	return cron.Parse(cronSpec)
+	loc, err := loadTimezone(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ScheduledReport.spec.timezone: %v", err)
+	}
+	schedule, err := cron.Parse(cronSpec)
+	if err != nil {
+		return nil, err
+	}
+	return &cronSchedule{schedule: schedule, loc: loc}, nil
 }
 
 func (op *Reporting) handleScheduledReport(logger log.FieldLogger, scheduledReport *cbTypes.ScheduledReport) error {
 	scheduledReport = scheduledReport.DeepCopy()
-	reportSchedule, err := getSchedule(scheduledReport.Spec.Schedule)
+	reportSchedule, err := getSchedule(scheduledReport.Spec.Schedule, scheduledReport.Spec.Timezone)
 	if err != nil {
 		return err
 	}
+	if err := validateScheduledReportWindow(scheduledReport.Spec); err != nil {
+		return err
+	}
+	genQuery, err := op.informers.Metering().V1alpha1().ReportGenerationQueries().Lister().ReportGenerationQueries(scheduledReport.Namespace).Get(scheduledReport.Spec.GenerationQueryName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	} else if err == nil {
+		if err := op.validateReportDependencies(scheduledReport.Namespace, dependencyKindScheduledReport, scheduledReport.Name, genQuery); err != nil {
+			return err
+		}
+	}
 	job := newScheduledReportJob(op, scheduledReport, reportSchedule)
 	op.scheduledReportRunner.AddJob(job)
 
@@ -203,15 +243,25 @@ type scheduledReportJob struct {
 	once     sync.Once
 	stopCh   chan struct{}
 	doneCh   chan struct{}
+	// dependencyUpdatedCh is notified when another report finishes or a
+	// ReportDataSource imports data, so that a job waiting on its
+	// dependencies can check them again.
+	dependencyUpdatedCh chan struct{}
+	// amendmentCh is notified when late data for a period the
+	// scheduledReport has already reported on is queued, so the job can
+	// run an amended run while it waits for its next run.
+	amendmentCh chan struct{}
 }
 
 func newScheduledReportJob(operator *Reporting, report *cbTypes.ScheduledReport, schedule reportSchedule) *scheduledReportJob {
 	return &scheduledReportJob{
-		operator: operator,
-		report:   report,
-		schedule: schedule,
-		stopCh:   make(chan struct{}),
-		doneCh:   make(chan struct{}),
+		operator:            operator,
+		report:              report,
+		schedule:            schedule,
+		stopCh:              make(chan struct{}),
+		doneCh:              make(chan struct{}),
+		dependencyUpdatedCh: make(chan struct{}, 1),
+		amendmentCh:         make(chan struct{}, 1),
 	}
 }
 
@@ -224,13 +274,31 @@ func (job *scheduledReportJob) stop(dropTable bool) {
 		logger.Info("waiting for scheduledReport job to finish")
 		<-job.doneCh
 		if dropTable {
-			tableName := scheduledReportTableName(job.report.Name)
+			tableName := job.operator.namespacedScheduledReportTableName(job.report.Namespace, job.report.Name)
 			logger.Infof("deleting scheduledReport table %s", tableName)
-			err := hive.ExecuteDropTable(job.operator.hiveQueryer, tableName, true)
+			var err error
+			genQuery, getErr := job.operator.informers.Metering().V1alpha1().ReportGenerationQueries().Lister().ReportGenerationQueries(job.report.Namespace).Get(job.report.Spec.GenerationQueryName)
+			if getErr == nil && genQuery.Spec.Materialization == cbTypes.ReportMaterializationView {
+				err = presto.DropView(job.operator.prestoQueryer, tableName, true)
+			} else {
+				err = hive.ExecuteDropTable(job.operator.hiveQueryer, tableName, true)
+			}
 			if err != nil {
 				job.operator.logger.WithError(err).Error("unable to drop table")
 			}
 			job.operator.logger.Infof("successfully deleted table %s", tableName)
+			err = hive.ExecuteDropTable(job.operator.hiveQueryer, reportVersionsTableName(tableName), true)
+			if err != nil {
+				job.operator.logger.WithError(err).Error("unable to drop versions table")
+			}
+			err = hive.ExecuteDropTable(job.operator.hiveQueryer, reportAmendmentsTableName(tableName), true)
+			if err != nil {
+				job.operator.logger.WithError(err).Error("unable to drop amendments table")
+			}
+			err = hive.ExecuteDropTable(job.operator.hiveQueryer, reportLineageTableName(tableName), true)
+			if err != nil {
+				job.operator.logger.WithError(err).Error("unable to drop lineage table")
+			}
 		}
 	})
 }
@@ -240,6 +308,22 @@ type reportPeriod struct {
 	periodStart time.Time
 }
 
+// newReportRun returns the run of the scheduledReport for the period which
+// notifications are sent for, where err is the error the run failed with.
+func (job *scheduledReportJob) newReportRun(genQuery *cbTypes.ReportGenerationQuery, tableName string, period reportPeriod, err error) reportRun {
+	return reportRun{
+		kind:            "ScheduledReport",
+		namespace:       job.report.Namespace,
+		name:            job.report.Name,
+		tableName:       tableName,
+		generationQuery: genQuery,
+		groupByLabels:   job.report.Spec.GroupByLabels,
+		periodStart:     period.periodStart,
+		periodEnd:       period.periodEnd,
+		err:             err,
+	}
+}
+
 // start runs a scheduledReportJob according to it's configured schedule. It
 // returns nothing because it should never stop unless Metering is shutting
 // down or the scheduledReport for this job has been deleted.
@@ -281,13 +365,72 @@ func (job *scheduledReportJob) start(logger log.FieldLogger) {
 			return
 		}
 
-		tableName := scheduledReportTableName(job.report.Name)
-		columns := generateHiveColumns(genQuery)
-		err = job.operator.createTableForStorage(logger, job.report, "scheduledreport", job.report.Name, job.report.Spec.Output, tableName, columns)
+		if err := job.operator.validateReportInputs(genQuery, job.report.Spec.Inputs); err != nil {
+			logger.WithError(err).Errorf("invalid inputs for scheduled report %s", job.report.Name)
+			return
+		}
+
+		groupByLabels, err := getGroupByLabels(genQuery, job.report.Spec.GroupByLabels)
+		if err != nil {
+			logger.WithError(err).Errorf("invalid groupByLabels for scheduled report %s", job.report.Name)
+			return
+		}
+
+		if _, err := getCostAllocation(genQuery, job.report.Spec.CostAllocation); err != nil {
+			logger.WithError(err).Errorf("invalid costAllocation for scheduled report %s", job.report.Name)
+			return
+		}
+
+		if err := validateReportDeliveries(job.report.Spec.Deliveries); err != nil {
+			logger.WithError(err).Errorf("invalid deliveries for scheduled report %s", job.report.Name)
+			return
+		}
+
+		if err := validateReportNotifications(job.report.Spec.Notifications); err != nil {
+			logger.WithError(err).Errorf("invalid notifications for scheduled report %s", job.report.Name)
+			return
+		}
+
+		if err := validateReportMetrics(job.report.Spec.Metrics); err != nil {
+			logger.WithError(err).Errorf("invalid metrics for scheduled report %s", job.report.Name)
+			return
+		}
+
+		if err := validateReportAssertions(job.report.Spec.Assertions); err != nil {
+			logger.WithError(err).Errorf("invalid assertions for scheduled report %s", job.report.Name)
+			return
+		}
+
+		if err := validateScheduledReportBudget(job.report.Spec.Budget); err != nil {
+			logger.WithError(err).Errorf("invalid budget for scheduled report %s", job.report.Name)
+			return
+		}
+
+		if err := validateScheduledReportAmendments(job.report.Spec); err != nil {
+			logger.WithError(err).Errorf("invalid amendments for scheduled report %s", job.report.Name)
+			return
+		}
+
+		tableName := job.operator.namespacedScheduledReportTableName(job.report.Namespace, job.report.Name)
+		materialization, err := getReportMaterializationPolicy(genQuery)
 		if err != nil {
-			logger.WithError(err).Error("error creating report table for scheduledReport")
+			logger.WithError(err).Errorf("invalid report generation query for scheduled report %s", job.report.Name)
 			return
 		}
+		// views are created when the report runs, so there's no table to
+		// create ahead of time.
+		if materialization != cbTypes.ReportMaterializationView {
+			if err := job.operator.ensureTenantSchema(logger, job.report.Namespace); err != nil {
+				logger.WithError(err).Errorf("unable to create the schema for scheduled report %s", job.report.Name)
+				return
+			}
+			columns := generateHiveColumns(getReportColumns(genQuery, groupByLabels))
+			err = job.operator.createTableForStorage(logger, job.report, "scheduledreport", job.report.Name, job.report.Spec.Output, tableName, columns)
+			if err != nil {
+				logger.WithError(err).Error("error creating report table for scheduledReport")
+				return
+			}
+		}
 
 		now := job.operator.clock.Now().UTC()
 		var lastScheduled time.Time
@@ -300,13 +443,23 @@ func (job *scheduledReportJob) start(logger log.FieldLogger) {
 			lastScheduled = now
 		}
 
+		// export the results of the last run if they aren't already, such
+		// as after the reporting-operator restarts.
+		metricsKey := reportMetricsKey{kind: "ScheduledReport", namespace: job.report.Namespace, name: job.report.Name}
+		if job.report.Spec.Metrics == nil || (lastReportTime != nil && !job.operator.reportMetrics.has(metricsKey)) {
+			job.operator.exportReportMetrics(logger, "ScheduledReport", job.report.Namespace, job.report.Name, tableName, genQuery, job.report.Spec.GroupByLabels, job.report.Spec.Metrics)
+		}
+
 		reportPeriod := getNextReportPeriod(job.schedule, job.report.Spec.Schedule.Period, lastScheduled)
+		queryStart, deleteExistingData := getReportQueryStart(job.report.Spec.Window, job.schedule, reportPeriod, lastReportTime == nil)
+		deleteExistingData = deleteExistingData || job.report.Spec.OverwriteExistingData
 
 		loggerWithFields := logger.WithFields(log.Fields{
 			"periodStart":       reportPeriod.periodStart,
 			"periodEnd":         reportPeriod.periodEnd,
+			"queryStart":        queryStart,
 			"period":            job.report.Spec.Schedule.Period,
-			"overwriteExisting": job.report.Spec.OverwriteExistingData,
+			"overwriteExisting": deleteExistingData,
 		})
 
 		var gracePeriod time.Duration
@@ -319,6 +472,13 @@ func (job *scheduledReportJob) start(logger log.FieldLogger) {
 
 		var waitTime time.Duration
 		nextRunTime := reportPeriod.periodEnd.Add(gracePeriod)
+		// a previous attempt of this run failed, wait until it should be
+		// retried
+		retryPolicy := job.operator.getReportRetryPolicy(job.report.Spec.RetryPolicy)
+		retryTime, attemptsExhausted := retryPolicy.scheduledRetryTime(job.schedule, report.Status.Attempts)
+		if retryTime.After(nextRunTime) {
+			nextRunTime = retryTime
+		}
 		reportGracePeriodUnmet := nextRunTime.After(now)
 		if reportGracePeriodUnmet {
 			waitTime = nextRunTime.Sub(now)
@@ -329,6 +489,7 @@ func (job *scheduledReportJob) start(logger log.FieldLogger) {
 
 		runningCondition = cbutil.NewScheduledReportCondition(cbTypes.ScheduledReportRunning, v1.ConditionTrue, cbutil.ReportPeriodWaitingReason, waitMsg)
 		cbutil.SetScheduledReportCondition(&report.Status, *runningCondition)
+		job.operator.setScheduledReportStaleCondition(loggerWithFields, report)
 
 		report, err = job.operator.meteringClient.MeteringV1alpha1().ScheduledReports(job.report.Namespace).Update(report)
 		if err != nil {
@@ -340,7 +501,49 @@ func (job *scheduledReportJob) start(logger log.FieldLogger) {
 		case <-job.stopCh:
 			loggerWithFields.Info("got stop signal, stopping scheduledReport job")
 			return
+		case <-job.amendmentCh:
+			data, exists := job.operator.reportAmendments.take(reportAmendmentKey(dependencyKindScheduledReport, job.report.Namespace, job.report.Name))
+			if !exists || job.report.Spec.Amendments == nil {
+				continue
+			}
+			run := job.newReportRun(genQuery, tableName, reportPeriod, nil)
+			report.Status.LastAmendment = job.operator.runReportAmendment(loggerWithFields, job.report, run, job.report.Spec.Output, job.report.Spec.Amendments, data, true)
+			newReport, err := job.operator.meteringClient.MeteringV1alpha1().ScheduledReports(job.report.Namespace).Update(report)
+			if err != nil {
+				loggerWithFields.WithError(err).Errorf("unable to update scheduledReport status with its amendment")
+				return
+			}
+			job.operator.recordReportAmendment(newReport, newReport.Status.LastAmendment)
+			continue
 		case <-job.operator.clock.After(waitTime):
+			_, ready, err := job.operator.getDependenciesStatus(loggerWithFields, genQuery, reportPeriod.periodEnd)
+			if err != nil {
+				loggerWithFields.WithError(err).Errorf("unable to check dependencies")
+				return
+			}
+			if !ready {
+				notReadyMsg := fmt.Sprintf("dependencies do not have data for the reporting period [%s to %s] yet, checking again in %s", reportPeriod.periodStart, reportPeriod.periodEnd, dataSourceNotReadyRetryInterval)
+				loggerWithFields.Warn(notReadyMsg)
+				runningCondition := cbutil.NewScheduledReportCondition(cbTypes.ScheduledReportRunning, v1.ConditionTrue, cbutil.DataSourcesNotReadyReason, notReadyMsg)
+				cbutil.SetScheduledReportCondition(&report.Status, *runningCondition)
+				job.operator.setScheduledReportStaleCondition(loggerWithFields, report)
+				_, err = job.operator.meteringClient.MeteringV1alpha1().ScheduledReports(job.report.Namespace).Update(report)
+				if err != nil {
+					loggerWithFields.WithError(err).Errorf("unable to update scheduledReport status")
+					return
+				}
+				select {
+				case <-job.stopCh:
+					loggerWithFields.Info("got stop signal, stopping scheduledReport job")
+					return
+				case <-job.operator.clock.After(dataSourceNotReadyRetryInterval):
+					continue
+				case <-job.dependencyUpdatedCh:
+					loggerWithFields.Debugf("a report this scheduledReport may depend on has run, checking dependencies again")
+					continue
+				}
+			}
+
 			runningMsg := fmt.Sprintf("reached end of last reporting period [%s to %s]", reportPeriod.periodStart, reportPeriod.periodEnd)
 			runningCondition := cbutil.NewScheduledReportCondition(cbTypes.ScheduledReportRunning, v1.ConditionTrue, cbutil.ScheduledReason, runningMsg)
 			cbutil.SetScheduledReportCondition(&report.Status, *runningCondition)
@@ -351,44 +554,166 @@ func (job *scheduledReportJob) start(logger log.FieldLogger) {
 				return
 			}
 
-			err = job.operator.generateReport(
-				loggerWithFields,
-				job.report,
-				"scheduledreport",
-				job.report.Name,
-				tableName,
-				reportPeriod.periodStart,
-				reportPeriod.periodEnd,
-				job.report.Spec.Output,
-				genQuery,
-				false,
-				job.report.Spec.OverwriteExistingData,
-			)
+			// the run starts over with a new set of attempts
+			if attemptsExhausted {
+				report.Status.Attempts = nil
+			}
+			attemptStart := job.operator.clock.Now().UTC()
+			ctx, cancel := newReportRunContext(job.stopCh, job.report.Spec.ActiveDeadlineSeconds)
+			if job.report.Spec.CheckpointInterval != nil {
+				var checkpoint *time.Time
+				if report.Status.Checkpoint != nil {
+					checkpoint = &report.Status.Checkpoint.Time
+				}
+				resumeStart, resuming := getCheckpointResumeTime(checkpoint, queryStart, reportPeriod.periodEnd)
+				if resuming {
+					loggerWithFields.Infof("resuming scheduledReport run from checkpoint %s", resumeStart)
+					queryStart = resumeStart
+					deleteExistingData = false
+				}
+				err = job.operator.generateReportCheckpointed(
+					ctx,
+					loggerWithFields,
+					job.report,
+					"scheduledreport",
+					job.report.Name,
+					tableName,
+					queryStart,
+					reportPeriod.periodEnd,
+					job.report.Spec.CheckpointInterval.Duration,
+					job.report.Spec.Output,
+					genQuery,
+					false,
+					deleteExistingData,
+					func(checkpoint time.Time) error {
+						report.Status.Checkpoint = &metav1.Time{Time: checkpoint}
+						newReport, err := job.operator.meteringClient.MeteringV1alpha1().ScheduledReports(job.report.Namespace).Update(report)
+						if err != nil {
+							return err
+						}
+						report = newReport
+						return nil
+					},
+				)
+			} else {
+				err = job.operator.generateReport(
+					ctx,
+					loggerWithFields,
+					job.report,
+					"scheduledreport",
+					job.report.Name,
+					tableName,
+					queryStart,
+					reportPeriod.periodEnd,
+					job.report.Spec.Output,
+					genQuery,
+					false,
+					deleteExistingData,
+				)
+			}
+
+			timedOut := reportRunTimedOut(ctx)
+			cancel()
+			if err != nil && stopRequested(job.stopCh) {
+				// the run was cancelled because the job is stopping, so it
+				// isn't counted as an attempt and is run again, or resumed
+				// from its checkpoint, once the job restarts.
+				loggerWithFields.WithError(err).Warnf("scheduledReport run stopped before it finished")
+				return
+			}
+			job.operator.observeReportRun("ScheduledReport", attemptStart, err, timedOut)
 
 			if err != nil {
+				reason := cbutil.GenerateReportErrorReason
+				var attemptReason string
+				if timedOut {
+					reason = cbutil.DeadlineExceededReason
+					attemptReason = cbTypes.ReportDeadlineExceededReason
+					err = fmt.Errorf("run timed out after exceeding activeDeadlineSeconds of %d: %v", *job.report.Spec.ActiveDeadlineSeconds, err)
+				}
+				report.Status.Attempts = append(report.Status.Attempts, cbTypes.ReportAttempt{
+					StartTime: metav1.Time{Time: attemptStart},
+					EndTime:   metav1.Time{Time: job.operator.clock.Now().UTC()},
+					Error:     err.Error(),
+					Reason:    attemptReason,
+				})
+				retryTime, retry := retryPolicy.nextRetryTime(report.Status.Attempts)
+				// runs which timed out are likely to time out again
+				retry = retry && !timedOut
+
 				// update the status to Failed with message containing the
 				// error
 				errMsg := fmt.Sprintf("error occurred while generating report: %s", err)
-				failureCondition := cbutil.NewScheduledReportCondition(cbTypes.ScheduledReportFailure, v1.ConditionTrue, cbutil.GenerateReportErrorReason, errMsg)
-				cbutil.RemoveScheduledReportCondition(&report.Status, cbTypes.ScheduledReportRunning)
+				if retry {
+					errMsg = fmt.Sprintf("attempt %d of %d failed, retrying at %s: %s", len(report.Status.Attempts), retryPolicy.MaxAttempts, retryTime, errMsg)
+				} else {
+					nextRetryTime, _ := retryPolicy.scheduledRetryTime(job.schedule, report.Status.Attempts)
+					errMsg = fmt.Sprintf("%d attempts failed, retrying when the schedule is next due at %s: %s", len(report.Status.Attempts), nextRetryTime, errMsg)
+					cbutil.RemoveScheduledReportCondition(&report.Status, cbTypes.ScheduledReportRunning)
+					notifications := job.operator.sendReportNotifications(context.Background(), loggerWithFields, job.newReportRun(genQuery, tableName, reportPeriod, err), job.report.Spec.Notifications)
+					report.Status.Notifications = mergeNotificationStatuses(report.Status.Notifications, notifications)
+				}
+				failureCondition := cbutil.NewScheduledReportCondition(cbTypes.ScheduledReportFailure, v1.ConditionTrue, reason, errMsg)
 				cbutil.SetScheduledReportCondition(&report.Status, *failureCondition)
+				job.operator.setScheduledReportStaleCondition(loggerWithFields, report)
 
 				_, updateErr := job.operator.meteringClient.MeteringV1alpha1().ScheduledReports(job.report.Namespace).Update(report)
 				if updateErr != nil {
 					loggerWithFields.WithError(updateErr).Errorf("unable to update scheduledReport status")
+					return
 				}
-				loggerWithFields.WithError(err).Errorf("error occurred while generating report")
-				return
+				if retry {
+					loggerWithFields.WithError(err).Warnf("error occurred while generating report on attempt %d of %d, retrying at %s", len(report.Status.Attempts), retryPolicy.MaxAttempts, retryTime)
+					continue
+				}
+				loggerWithFields.WithError(err).Errorf("error occurred while generating report after %d attempts, retrying when the schedule is next due", len(report.Status.Attempts))
+				continue
 			}
 
+			run := job.newReportRun(genQuery, tableName, reportPeriod, nil)
+			if job.report.Spec.Versioned {
+				// without a window or overwriteExistingData, the table
+				// holds the results of every run.
+				periodRowsOnly := job.report.Spec.Window == nil && !job.report.Spec.OverwriteExistingData
+				report.Status.LatestVersion = job.operator.writeReportVersion(loggerWithFields, job.report, run, job.report.Spec.Output, periodRowsOnly)
+			}
+			if job.report.Spec.Lineage {
+				report.Status.LatestLineage = job.operator.writeReportLineage(loggerWithFields, job.report, run, job.report.Spec.Output)
+			}
+			report.Status.Assertions = job.operator.checkReportAssertions(loggerWithFields, run, job.report.Spec.Assertions)
+			run.failedAssertions = failedReportAssertions(report.Status.Assertions)
+			var previousResultsValid v1.ConditionStatus
+			if cond := cbutil.GetScheduledReportCondition(report.Status, cbTypes.ScheduledReportResultsValid); cond != nil {
+				previousResultsValid = cond.Status
+			}
+			if len(report.Status.Assertions) != 0 {
+				status, reason, msg := reportAssertionsCondition(report.Status.Assertions)
+				cbutil.SetScheduledReportCondition(&report.Status, *cbutil.NewScheduledReportCondition(cbTypes.ScheduledReportResultsValid, status, reason, msg))
+			} else {
+				cbutil.RemoveScheduledReportCondition(&report.Status, cbTypes.ScheduledReportResultsValid)
+			}
+			report.Status.Deliveries = job.operator.deliverReportResults(context.Background(), loggerWithFields, "ScheduledReport", job.report.Namespace, job.report.Name, tableName, genQuery, job.report.Spec.GroupByLabels, job.report.Spec.Deliveries, reportPeriod.periodStart, reportPeriod.periodEnd)
+			notifications := job.operator.sendReportNotifications(context.Background(), loggerWithFields, run, job.report.Spec.Notifications)
+			report.Status.Notifications = mergeNotificationStatuses(report.Status.Notifications, notifications)
+			report.Status.Budget = job.operator.checkScheduledReportBudget(context.Background(), loggerWithFields, run, job.report.Spec.Budget, report.Status.Budget)
+			job.operator.publishReportResultsToKafka(loggerWithFields, "ScheduledReport", job.report.Namespace, job.report.Name, tableName, genQuery, job.report.Spec.GroupByLabels, reportPeriod.periodStart, reportPeriod.periodEnd)
+			job.operator.exportReportMetrics(loggerWithFields, "ScheduledReport", job.report.Namespace, job.report.Name, tableName, genQuery, job.report.Spec.GroupByLabels, job.report.Spec.Metrics)
+
 			// We generated a report successfully, remove the failure condition
 			cbutil.RemoveScheduledReportCondition(&report.Status, cbTypes.ScheduledReportFailure)
 			report.Status.LastReportTime = &metav1.Time{Time: reportPeriod.periodEnd}
-			_, err = job.operator.meteringClient.MeteringV1alpha1().ScheduledReports(job.report.Namespace).Update(report)
+			report.Status.Checkpoint = nil
+			report.Status.Attempts = nil
+			job.operator.setScheduledReportStaleCondition(loggerWithFields, report)
+			newReport, err := job.operator.meteringClient.MeteringV1alpha1().ScheduledReports(job.report.Namespace).Update(report)
 			if err != nil {
 				loggerWithFields.WithError(err).Errorf("unable to update scheduledReport status")
 				return
 			}
+			if cond := cbutil.GetScheduledReportCondition(newReport.Status, cbTypes.ScheduledReportResultsValid); cond != nil {
+				job.operator.recordConditionTransition(newReport, string(cond.Type), previousResultsValid, cond.Status, cond.Reason, cond.Message)
+			}
+			job.operator.notifyReportDependents(job.report.Namespace, dependencyKindScheduledReport, job.report.Name)
 		}
 	}
 }
@@ -439,6 +764,44 @@ func (runner *scheduledReportRunner) RemoveJob(name string) (*scheduledReportJob
 	return job, exists
 }
 
+// isRunning returns true if the job of the ScheduledReport named name is
+// running.
+func (runner *scheduledReportRunner) isRunning(name string) bool {
+	runner.reportsMu.Lock()
+	defer runner.reportsMu.Unlock()
+	_, exists := runner.reports[name]
+	return exists
+}
+
+// notifyDependencyUpdated notifies every running job that one of the
+// reports or ReportDataSources it may depend on has been updated, without
+// blocking if a job has a pending notification already.
+func (runner *scheduledReportRunner) notifyDependencyUpdated() {
+	runner.reportsMu.Lock()
+	defer runner.reportsMu.Unlock()
+	for _, job := range runner.reports {
+		select {
+		case job.dependencyUpdatedCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// notifyAmendment notifies the job of the ScheduledReport named name, if
+// it's running, that late data it should be amended for has been queued.
+func (runner *scheduledReportRunner) notifyAmendment(name string) {
+	runner.reportsMu.Lock()
+	defer runner.reportsMu.Unlock()
+	job, exists := runner.reports[name]
+	if !exists {
+		return
+	}
+	select {
+	case job.amendmentCh <- struct{}{}:
+	default:
+	}
+}
+
 func (runner *scheduledReportRunner) handleJob(stop <-chan struct{}, job *scheduledReportJob) {
 	logger := runner.operator.logger.WithField("scheduledReport", job.report.Name)
 	runner.reportsMu.Lock()
@@ -451,6 +814,10 @@ func (runner *scheduledReportRunner) handleJob(stop <-chan struct{}, job *schedu
 
 	runner.reports[job.report.Name] = job
 	runner.reportsMu.Unlock()
+	// late data may have been queued while the job wasn't running.
+	if runner.operator.reportAmendments.has(reportAmendmentKey(dependencyKindScheduledReport, job.report.Namespace, job.report.Name)) {
+		job.amendmentCh <- struct{}{}
+	}
 
 	logger.Info("starting scheduledReport job")
 	defer runner.RemoveJob(job.report.Name)
@@ -468,6 +835,19 @@ func (runner *scheduledReportRunner) handleJob(stop <-chan struct{}, job *schedu
 	wg.Wait()
 	logger.Info("scheduledReport job stopped")
 
+	select {
+	case <-job.stopCh:
+	default:
+		// the job exited on its own, usually because its dependencies
+		// aren't initialized or it failed to update the ScheduledReport,
+		// so restart it later rather than waiting for the next resync.
+		key, err := cache.MetaNamespaceKeyFunc(job.report)
+		if err == nil {
+			logger.Infof("restarting scheduledReport job in %s", scheduledReportJobRestartDelay)
+			runner.operator.queues.scheduledReportQueue.AddAfter(key, scheduledReportJobRestartDelay)
+		}
+	}
+
 }
 
 func getNextReportPeriod(schedule reportSchedule, period cbTypes.ScheduledReportPeriod, lastScheduled time.Time) reportPeriod {
@@ -479,6 +859,67 @@ func getNextReportPeriod(schedule reportSchedule, period cbTypes.ScheduledReport
 	}
 }
 
+// getReportQueryStart returns the start of the time range a ScheduledReport
+// should query for the reporting period, and whether or not the existing data
+// in the report table must be deleted first. Without a window only the
+// reporting period is queried. With a window, the entire window up until the
+// end of the period is recomputed, unless the window is incremental, in which
+// case only the reporting period is appended. firstRun indicates the
+// ScheduledReport has never run, in which case incremental windows are
+// backfilled from the start of the window, as they are for the first period
+// of each new window, when the table is emptied. Periods belong to the
+// window they begin in, and windows begin at day, week or month boundaries
+// in the schedule's location.
+func getReportQueryStart(window *cbTypes.ScheduledReportWindow, schedule reportSchedule, period reportPeriod, firstRun bool) (time.Time, bool) {
+	if window == nil {
+		return period.periodStart, false
+	}
+	windowStart := getWindowStart(window.Period, period.periodStart, schedule.Location())
+	if !window.Incremental || firstRun {
+		return windowStart, true
+	}
+	// The table contains the previous period, which ended at the last
+	// report time. It began in an earlier window unless the schedule has a
+	// period beginning within this window before this period, which isn't
+	// the case when the window's first period begins at or after
+	// periodStart, including when periods aren't aligned to the window.
+	firstPeriodStart := schedule.Next(windowStart.Add(-time.Nanosecond)).Truncate(time.Millisecond).UTC()
+	if !firstPeriodStart.Before(period.periodStart) {
+		return windowStart, true
+	}
+	return period.periodStart, false
+}
+
+// getWindowStart returns the beginning of the day, week or month in loc
+// containing t, in UTC.
+func getWindowStart(windowPeriod cbTypes.ScheduledReportPeriod, t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	switch windowPeriod {
+	case cbTypes.ScheduledReportPeriodWeekly:
+		return day.AddDate(0, 0, -int(day.Weekday())).UTC()
+	case cbTypes.ScheduledReportPeriodMonthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc).UTC()
+	default:
+		return day.UTC()
+	}
+}
+
+func validateScheduledReportWindow(spec cbTypes.ScheduledReportSpec) error {
+	if spec.Window == nil {
+		return nil
+	}
+	if spec.OverwriteExistingData {
+		return fmt.Errorf("ScheduledReport.spec.overwriteExistingData cannot be used with spec.window")
+	}
+	switch spec.Window.Period {
+	case cbTypes.ScheduledReportPeriodDaily, cbTypes.ScheduledReportPeriodWeekly, cbTypes.ScheduledReportPeriodMonthly:
+		return nil
+	default:
+		return fmt.Errorf("invalid ScheduledReport.spec.window.period: %s, must be one of: %s, %s or %s", spec.Window.Period, cbTypes.ScheduledReportPeriodDaily, cbTypes.ScheduledReportPeriodWeekly, cbTypes.ScheduledReportPeriodMonthly)
+	}
+}
+
 func convertDayOfWeek(dow string) (int, error) {
 	switch strings.ToLower(dow) {
 	case "sun", "sunday":
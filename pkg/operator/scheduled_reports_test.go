@@ -13,6 +13,7 @@ func TestGetNextReportPeriod(t *testing.T) {
 	baseTime := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
 	tests := map[string]struct {
 		period              v1alpha1.ScheduledReportPeriod
+		timezone            string
 		expectError         bool
 		expectReportPeriods []reportPeriod
 	}{
@@ -42,6 +43,30 @@ func TestGetNextReportPeriod(t *testing.T) {
 				},
 			},
 		},
+		"daily in America/New_York": {
+			period:   v1alpha1.ScheduledReportPeriodDaily,
+			timezone: "America/New_York",
+			expectReportPeriods: []reportPeriod{
+				{
+					periodStart: baseTime,
+					periodEnd:   time.Date(2018, time.July, 1, 4, 0, 0, 0, time.UTC),
+				},
+				{
+					periodStart: time.Date(2018, time.July, 1, 4, 0, 0, 0, time.UTC),
+					periodEnd:   time.Date(2018, time.July, 2, 4, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		"monthly in Asia/Tokyo": {
+			period:   v1alpha1.ScheduledReportPeriodMonthly,
+			timezone: "Asia/Tokyo",
+			expectReportPeriods: []reportPeriod{
+				{
+					periodStart: baseTime,
+					periodEnd:   time.Date(2018, time.July, 31, 15, 0, 0, 0, time.UTC),
+				},
+			},
+		},
 		"weekly": {
 			period: v1alpha1.ScheduledReportPeriodWeekly,
 			expectReportPeriods: []reportPeriod{
@@ -78,7 +103,7 @@ func TestGetNextReportPeriod(t *testing.T) {
 				Monthly: &v1alpha1.ScheduledReportScheduleMonthly{},
 			}
 
-			schedule, err := getSchedule(apiSched)
+			schedule, err := getSchedule(apiSched, test.timezone)
 			require.NoError(t, err)
 
 			lastScheduled := baseTime
@@ -92,3 +117,112 @@ func TestGetNextReportPeriod(t *testing.T) {
 		})
 	}
 }
+
+func TestGetReportQueryStart(t *testing.T) {
+	period := reportPeriod{
+		periodStart: time.Date(2018, time.July, 11, 5, 0, 0, 0, time.UTC),
+		periodEnd:   time.Date(2018, time.July, 11, 6, 0, 0, 0, time.UTC),
+	}
+	newWindowPeriod := reportPeriod{
+		periodStart: time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC),
+		periodEnd:   time.Date(2018, time.August, 1, 1, 0, 0, 0, time.UTC),
+	}
+	hourly := v1alpha1.ScheduledReportSchedule{Period: v1alpha1.ScheduledReportPeriodHourly}
+	// daily periods beginning at 06:00 aren't aligned to the window.
+	dailyAt6 := v1alpha1.ScheduledReportSchedule{
+		Period: v1alpha1.ScheduledReportPeriodDaily,
+		Daily:  &v1alpha1.ScheduledReportScheduleDaily{Hour: 6},
+	}
+	tests := map[string]struct {
+		window               *v1alpha1.ScheduledReportWindow
+		schedule             v1alpha1.ScheduledReportSchedule
+		period               reportPeriod
+		firstRun             bool
+		timezone             string
+		expectedStart        time.Time
+		expectDeleteExisting bool
+	}{
+		"no window": {
+			period:        period,
+			expectedStart: period.periodStart,
+		},
+		"monthly window": {
+			window:               &v1alpha1.ScheduledReportWindow{Period: v1alpha1.ScheduledReportPeriodMonthly},
+			period:               period,
+			expectedStart:        time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC),
+			expectDeleteExisting: true,
+		},
+		"weekly window": {
+			window:               &v1alpha1.ScheduledReportWindow{Period: v1alpha1.ScheduledReportPeriodWeekly},
+			period:               period,
+			expectedStart:        time.Date(2018, time.July, 8, 0, 0, 0, 0, time.UTC),
+			expectDeleteExisting: true,
+		},
+		"daily window": {
+			window:               &v1alpha1.ScheduledReportWindow{Period: v1alpha1.ScheduledReportPeriodDaily},
+			period:               period,
+			expectedStart:        time.Date(2018, time.July, 11, 0, 0, 0, 0, time.UTC),
+			expectDeleteExisting: true,
+		},
+		"daily window in America/New_York": {
+			window:               &v1alpha1.ScheduledReportWindow{Period: v1alpha1.ScheduledReportPeriodDaily},
+			period:               period,
+			timezone:             "America/New_York",
+			expectedStart:        time.Date(2018, time.July, 11, 4, 0, 0, 0, time.UTC),
+			expectDeleteExisting: true,
+		},
+		"incremental monthly window": {
+			window:        &v1alpha1.ScheduledReportWindow{Period: v1alpha1.ScheduledReportPeriodMonthly, Incremental: true},
+			period:        period,
+			expectedStart: period.periodStart,
+		},
+		"incremental monthly window first run backfills": {
+			window:               &v1alpha1.ScheduledReportWindow{Period: v1alpha1.ScheduledReportPeriodMonthly, Incremental: true},
+			period:               period,
+			firstRun:             true,
+			expectedStart:        time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC),
+			expectDeleteExisting: true,
+		},
+		"incremental monthly window new window": {
+			window:               &v1alpha1.ScheduledReportWindow{Period: v1alpha1.ScheduledReportPeriodMonthly, Incremental: true},
+			period:               newWindowPeriod,
+			expectedStart:        newWindowPeriod.periodStart,
+			expectDeleteExisting: true,
+		},
+		"incremental monthly window first period of new window not aligned to the window": {
+			window:   &v1alpha1.ScheduledReportWindow{Period: v1alpha1.ScheduledReportPeriodMonthly, Incremental: true},
+			schedule: dailyAt6,
+			period: reportPeriod{
+				periodStart: time.Date(2018, time.August, 1, 6, 0, 0, 0, time.UTC),
+				periodEnd:   time.Date(2018, time.August, 2, 6, 0, 0, 0, time.UTC),
+			},
+			expectedStart:        time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC),
+			expectDeleteExisting: true,
+		},
+		"incremental monthly window second period of new window not aligned to the window": {
+			window:   &v1alpha1.ScheduledReportWindow{Period: v1alpha1.ScheduledReportPeriodMonthly, Incremental: true},
+			schedule: dailyAt6,
+			period: reportPeriod{
+				periodStart: time.Date(2018, time.August, 2, 6, 0, 0, 0, time.UTC),
+				periodEnd:   time.Date(2018, time.August, 3, 6, 0, 0, 0, time.UTC),
+			},
+			expectedStart: time.Date(2018, time.August, 2, 6, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			apiSched := tt.schedule
+			if apiSched.Period == "" {
+				apiSched = hourly
+			}
+			schedule, err := getSchedule(apiSched, tt.timezone)
+			require.NoError(t, err)
+			start, deleteExisting := getReportQueryStart(tt.window, schedule, tt.period, tt.firstRun)
+			assert.Equal(t, tt.expectedStart, start)
+			assert.Equal(t, tt.expectDeleteExisting, deleteExisting)
+		})
+	}
+}
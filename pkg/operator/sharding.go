@@ -0,0 +1,79 @@
+package operator
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+type ShardingConfig struct {
+	// Shards is the number of shards ReportDataSources are split between.
+	// Each shard is a separate set of reporting-operator replicas with its
+	// own leader, which imports only the ReportDataSources it owns.
+	Shards int
+	// Index is the shard this reporting-operator belongs to. Only shard 0
+	// runs reports and handles the other resources.
+	Index int
+}
+
+func (cfg ShardingConfig) Valid() error {
+	if cfg.Shards < 1 {
+		return fmt.Errorf("the number of shards must be at least 1, got %d", cfg.Shards)
+	}
+	if cfg.Index < 0 || cfg.Index >= cfg.Shards {
+		return fmt.Errorf("the shard index must be between 0 and %d, got %d", cfg.Shards-1, cfg.Index)
+	}
+	return nil
+}
+
+// enabled returns true if ReportDataSources are split between more than one
+// shard.
+func (cfg ShardingConfig) enabled() bool {
+	return cfg.Shards > 1
+}
+
+// isPrimary returns true if this shard runs reports and handles resources
+// other than ReportDataSources.
+func (cfg ShardingConfig) isPrimary() bool {
+	return cfg.Index == 0
+}
+
+// owns returns true if the ReportDataSource named dataSourceName is
+// imported by this shard.
+func (cfg ShardingConfig) owns(dataSourceName string) bool {
+	if !cfg.enabled() {
+		return true
+	}
+	return dataSourceShard(dataSourceName, cfg.Shards) == cfg.Index
+}
+
+// leaderElectionLockName returns the name of the lock the replicas of this
+// shard elect their leader with. Shard 0 uses the same lock as an unsharded
+// reporting-operator, so enabling sharding doesn't elect a second leader.
+func (cfg ShardingConfig) leaderElectionLockName() string {
+	if cfg.Index == 0 {
+		return leaderElectionLockName
+	}
+	return fmt.Sprintf("%s-shard-%d", leaderElectionLockName, cfg.Index)
+}
+
+// dataSourceShard returns the shard which owns the ReportDataSource named
+// dataSourceName. It uses jump consistent hashing, so when the number of
+// shards grows from n to n+1 only 1/(n+1) of the ReportDataSources move,
+// all of them to the new shard.
+func dataSourceShard(dataSourceName string, shards int) int {
+	h := fnv.New64a()
+	h.Write([]byte(dataSourceName))
+	return jumpHash(h.Sum64(), shards)
+}
+
+// jumpHash is the jump consistent hash function from "A Fast, Minimal
+// Memory, Consistent Hash Algorithm" by Lamping and Veach.
+func jumpHash(key uint64, buckets int) int {
+	var b, j int64 = -1, 0
+	for j < int64(buckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
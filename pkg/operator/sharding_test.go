@@ -0,0 +1,74 @@
+package operator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardingConfigValid(t *testing.T) {
+	tests := map[string]struct {
+		cfg       ShardingConfig
+		expectErr bool
+	}{
+		"unsharded":          {cfg: ShardingConfig{Shards: 1}},
+		"last shard":         {cfg: ShardingConfig{Shards: 3, Index: 2}},
+		"no shards":          {cfg: ShardingConfig{Shards: 0}, expectErr: true},
+		"index out of range": {cfg: ShardingConfig{Shards: 3, Index: 3}, expectErr: true},
+		"negative index":     {cfg: ShardingConfig{Shards: 3, Index: -1}, expectErr: true},
+	}
+	for name, test := range tests {
+		err := test.cfg.Valid()
+		if test.expectErr {
+			assert.Error(t, err, name)
+		} else {
+			assert.NoError(t, err, name)
+		}
+	}
+}
+
+func TestShardingConfigOwns(t *testing.T) {
+	var names []string
+	for i := 0; i < 1000; i++ {
+		names = append(names, fmt.Sprintf("datasource-%d", i))
+	}
+
+	const shards = 4
+	owned := make([]int, shards)
+	for _, name := range names {
+		assert.True(t, ShardingConfig{Shards: 1}.owns(name), "an unsharded reporting-operator should own every ReportDataSource")
+
+		var owners int
+		for index := 0; index < shards; index++ {
+			if (ShardingConfig{Shards: shards, Index: index}).owns(name) {
+				owners++
+				owned[index]++
+			}
+		}
+		assert.Equal(t, 1, owners, "%s should be owned by exactly one shard", name)
+	}
+	for index, count := range owned {
+		assert.InDelta(t, len(names)/shards, count, float64(len(names))/10, "shard %d should own about a quarter of the ReportDataSources", index)
+	}
+}
+
+func TestDataSourceShardConsistent(t *testing.T) {
+	// when a shard is added, ReportDataSources only move to the new shard.
+	var moved int
+	for i := 0; i < 1000; i++ {
+		name := fmt.Sprintf("datasource-%d", i)
+		before, after := dataSourceShard(name, 3), dataSourceShard(name, 4)
+		if before != after {
+			assert.Equal(t, 3, after, "%s moved from shard %d to an existing shard", name, before)
+			moved++
+		}
+	}
+	assert.InDelta(t, 250, moved, 100)
+}
+
+func TestShardingConfigLeaderElectionLockName(t *testing.T) {
+	assert.Equal(t, "reporting-operator-leader-lease", ShardingConfig{Shards: 1}.leaderElectionLockName())
+	assert.Equal(t, "reporting-operator-leader-lease", ShardingConfig{Shards: 3}.leaderElectionLockName())
+	assert.Equal(t, "reporting-operator-leader-lease-shard-2", ShardingConfig{Shards: 3, Index: 2}.leaderElectionLockName())
+}
@@ -72,6 +72,12 @@ func (op *Reporting) getHiveTableProperties(logger log.FieldLogger, storage *cbT
 	}
 	if storageSpec.Hive != nil {
 		props := hive.TableProperties(storageSpec.Hive.TableProperties)
+		if storageSpec.Hive.Encryption != nil {
+			props, err = applyStorageEncryption(props, *storageSpec.Hive.Encryption)
+			if err != nil {
+				return nil, err
+			}
+		}
 		return &props, nil
 	} else {
 		return nil, fmt.Errorf("incorrect storage configuration, must configure spec.hive")
@@ -0,0 +1,77 @@
+package operator
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+)
+
+const (
+	// s3KMSKeyIDTableProperty records the AWS KMS key the objects of a
+	// table in S3 are encrypted with.
+	s3KMSKeyIDTableProperty = "metering.s3.sse-kms-key-id"
+	// hdfsEncryptionZoneTableProperty records the HDFS encryption zone a
+	// table is created in.
+	hdfsEncryptionZoneTableProperty = "metering.hdfs.encryption-zone"
+)
+
+// applyStorageEncryption returns the table properties for tables created in
+// storage encrypted as configured by enc.
+//
+// Presto and Hive encrypt the objects they write to S3 with the KMS key
+// configured for their S3 filesystem, so tables in S3 are only tagged with
+// their key, which must match it. Tables in HDFS are encrypted by being
+// located in an encryption zone, so their location must be within it.
+func applyStorageEncryption(props hive.TableProperties, enc cbTypes.HiveStorageEncryption) (hive.TableProperties, error) {
+	if enc.S3KMSKeyID != "" && enc.HDFSEncryptionZone != "" {
+		return props, fmt.Errorf("invalid storage encryption, only one of s3KMSKeyID and hdfsEncryptionZone can be set")
+	}
+	if enc.S3KMSKeyID == "" && enc.HDFSEncryptionZone == "" {
+		return props, nil
+	}
+
+	// the properties map is shared with the StorageLocation in the lister
+	// cache, so it's copied before being modified.
+	properties := make(map[string]string, len(props.Properties)+1)
+	for k, v := range props.Properties {
+		properties[k] = v
+	}
+	props.Properties = properties
+
+	u, err := url.Parse(props.Location)
+	if err != nil {
+		return props, fmt.Errorf("invalid storage location %q: %v", props.Location, err)
+	}
+
+	switch {
+	case enc.S3KMSKeyID != "":
+		switch u.Scheme {
+		case "s3a", "s3", "s3n":
+		default:
+			return props, fmt.Errorf("invalid storage encryption, s3KMSKeyID requires an S3 location, got %q", props.Location)
+		}
+		props.Properties[s3KMSKeyIDTableProperty] = enc.S3KMSKeyID
+	case enc.HDFSEncryptionZone != "":
+		zone := path.Clean(enc.HDFSEncryptionZone)
+		if !path.IsAbs(zone) {
+			return props, fmt.Errorf("invalid storage encryption, hdfsEncryptionZone must be an absolute path, got %q", enc.HDFSEncryptionZone)
+		}
+		if props.Location == "" {
+			props.Location = "hdfs://" + zone
+		} else {
+			if u.Scheme != "" && u.Scheme != "hdfs" {
+				return props, fmt.Errorf("invalid storage encryption, hdfsEncryptionZone requires an HDFS location, got %q", props.Location)
+			}
+			locationPath := path.Clean(u.Path)
+			if locationPath != zone && !strings.HasPrefix(locationPath, strings.TrimSuffix(zone, "/")+"/") {
+				return props, fmt.Errorf("invalid storage encryption, location %q isn't within the HDFS encryption zone %q", props.Location, zone)
+			}
+		}
+		props.Properties[hdfsEncryptionZoneTableProperty] = zone
+	}
+	return props, nil
+}
@@ -0,0 +1,83 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+)
+
+func TestApplyStorageEncryption(t *testing.T) {
+	const kmsKeyARN = "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+
+	tests := map[string]struct {
+		props         hive.TableProperties
+		encryption    cbTypes.HiveStorageEncryption
+		expectErr     bool
+		expectedProps hive.TableProperties
+	}{
+		"no encryption": {
+			props:         hive.TableProperties{Location: "s3a://metering/data"},
+			expectedProps: hive.TableProperties{Location: "s3a://metering/data"},
+		},
+		"s3 kms key": {
+			props:      hive.TableProperties{Location: "s3a://metering/data", Properties: map[string]string{"owner": "billing"}},
+			encryption: cbTypes.HiveStorageEncryption{S3KMSKeyID: kmsKeyARN},
+			expectedProps: hive.TableProperties{
+				Location:   "s3a://metering/data",
+				Properties: map[string]string{"owner": "billing", s3KMSKeyIDTableProperty: kmsKeyARN},
+			},
+		},
+		"s3 kms key without an s3 location": {
+			props:      hive.TableProperties{Location: "hdfs:///metering"},
+			encryption: cbTypes.HiveStorageEncryption{S3KMSKeyID: kmsKeyARN},
+			expectErr:  true,
+		},
+		"hdfs encryption zone as the location": {
+			encryption: cbTypes.HiveStorageEncryption{HDFSEncryptionZone: "/metering/encrypted/"},
+			expectedProps: hive.TableProperties{
+				Location:   "hdfs:///metering/encrypted",
+				Properties: map[string]string{hdfsEncryptionZoneTableProperty: "/metering/encrypted"},
+			},
+		},
+		"hdfs location within the encryption zone": {
+			props:      hive.TableProperties{Location: "hdfs://namenode:9820/metering/encrypted/reports"},
+			encryption: cbTypes.HiveStorageEncryption{HDFSEncryptionZone: "/metering/encrypted"},
+			expectedProps: hive.TableProperties{
+				Location:   "hdfs://namenode:9820/metering/encrypted/reports",
+				Properties: map[string]string{hdfsEncryptionZoneTableProperty: "/metering/encrypted"},
+			},
+		},
+		"hdfs location outside the encryption zone": {
+			props:      hive.TableProperties{Location: "hdfs:///metering/encrypted-not"},
+			encryption: cbTypes.HiveStorageEncryption{HDFSEncryptionZone: "/metering/encrypted"},
+			expectErr:  true,
+		},
+		"relative hdfs encryption zone": {
+			encryption: cbTypes.HiveStorageEncryption{HDFSEncryptionZone: "metering"},
+			expectErr:  true,
+		},
+		"s3 kms key and hdfs encryption zone": {
+			props:      hive.TableProperties{Location: "s3a://metering/data"},
+			encryption: cbTypes.HiveStorageEncryption{S3KMSKeyID: kmsKeyARN, HDFSEncryptionZone: "/metering"},
+			expectErr:  true,
+		},
+	}
+	for name, tt := range tests {
+		original := tt.props.Properties["owner"]
+		props, err := applyStorageEncryption(tt.props, tt.encryption)
+		if tt.expectErr {
+			assert.Error(t, err, name)
+			continue
+		}
+		require.NoError(t, err, name)
+		assert.Equal(t, tt.expectedProps, props, name)
+		// the StorageLocation's properties must not be modified.
+		assert.Equal(t, original, tt.props.Properties["owner"], name)
+		_, tagged := tt.props.Properties[s3KMSKeyIDTableProperty]
+		assert.False(t, tagged, name)
+	}
+}
@@ -0,0 +1,142 @@
+package operator
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/cache"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	cbutil "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1/util"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+)
+
+// azureDataLakeHostSuffix is the suffix of the hostnames of Azure Data Lake
+// Storage Gen2 storage accounts.
+const azureDataLakeHostSuffix = ".dfs.core.windows.net"
+
+func (op *Reporting) runStorageLocationWorker() {
+	logger := op.logger.WithField("component", "storageLocationWorker")
+	logger.Infof("StorageLocation worker started")
+	for op.processStorageLocation(logger) {
+
+	}
+}
+
+func (op *Reporting) processStorageLocation(logger log.FieldLogger) bool {
+	obj, quit := op.queues.storageLocationQueue.Get()
+	if quit {
+		logger.Infof("queue is shutting down, exiting StorageLocation worker")
+		return false
+	}
+	defer op.queues.storageLocationQueue.Done(obj)
+
+	logger = logger.WithFields(newLogIdentifier(op.rand))
+	if key, ok := op.getKeyFromQueueObj(logger, "StorageLocation", obj, op.queues.storageLocationQueue); ok {
+		start := op.clock.Now()
+		err := op.syncStorageLocation(logger, key)
+		op.observeReconcile("StorageLocation", start, err)
+		op.handleErr(logger, err, "StorageLocation", key, op.queues.storageLocationQueue)
+	}
+	return true
+}
+
+func (op *Reporting) syncStorageLocation(logger log.FieldLogger, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		logger.WithError(err).Errorf("invalid resource key :%s", key)
+		return nil
+	}
+
+	logger = logger.WithField("storageLocation", name)
+
+	storageLocation, err := op.informers.Metering().V1alpha1().StorageLocations().Lister().StorageLocations(namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Infof("StorageLocation %s does not exist anymore", key)
+			return nil
+		}
+		return err
+	}
+
+	logger.Infof("syncing storageLocation %s", storageLocation.GetName())
+	return op.handleStorageLocation(logger, storageLocation)
+}
+
+// handleStorageLocation validates the storageLocation and sets its Ready
+// condition.
+func (op *Reporting) handleStorageLocation(logger log.FieldLogger, storageLocation *cbTypes.StorageLocation) error {
+	status, reason, msg := v1.ConditionTrue, cbutil.StorageLocationValidatedReason, "storage location is valid"
+	if err := validateStorageLocationSpec(storageLocation.Spec); err != nil {
+		status, reason, msg = v1.ConditionFalse, cbutil.InvalidStorageLocationReason, err.Error()
+	}
+
+	var previous v1.ConditionStatus
+	current := cbutil.GetStorageLocationCondition(storageLocation.Status, cbTypes.StorageLocationReady)
+	if current != nil {
+		if current.Status == status && current.Reason == reason && current.Message == msg {
+			return nil
+		}
+		previous = current.Status
+	}
+	if status != v1.ConditionTrue {
+		logger.Warnf("storageLocation is invalid: %s", msg)
+	}
+
+	storageLocation = storageLocation.DeepCopy()
+	cbutil.SetStorageLocationCondition(&storageLocation.Status, *cbutil.NewStorageLocationCondition(cbTypes.StorageLocationReady, status, reason, msg))
+	_, err := op.meteringClient.MeteringV1alpha1().StorageLocations(storageLocation.Namespace).Update(storageLocation)
+	if err != nil {
+		logger.WithError(err).Errorf("failed to update StorageLocation conditions for %q", storageLocation.Name)
+		return err
+	}
+	op.recordConditionTransition(storageLocation, string(cbTypes.StorageLocationReady), previous, status, reason, msg)
+	return nil
+}
+
+// validateStorageLocationSpec returns an error if tables can't be created
+// in the storage location described by spec.
+func validateStorageLocationSpec(spec cbTypes.StorageLocationSpec) error {
+	if spec.Hive == nil {
+		return fmt.Errorf("spec.hive must be set")
+	}
+	props := hive.TableProperties(spec.Hive.TableProperties)
+	u, err := url.Parse(props.Location)
+	if err != nil {
+		return fmt.Errorf("invalid location %q: %v", props.Location, err)
+	}
+	if err := validateStorageLocationURL(u); err != nil {
+		return fmt.Errorf("invalid location %q: %v", props.Location, err)
+	}
+	if spec.Hive.Encryption != nil {
+		if _, err := applyStorageEncryption(props, *spec.Hive.Encryption); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateStorageLocationURL returns an error if u isn't in a filesystem
+// Presto and Hive can be configured to store tables in.
+func validateStorageLocationURL(u *url.URL) error {
+	switch u.Scheme {
+	case "":
+		// tables are created in Hive's default filesystem.
+	case "hdfs":
+	case "s3a", "s3", "s3n", "gs":
+		if u.Host == "" {
+			return fmt.Errorf("%s:// locations must include the bucket", u.Scheme)
+		}
+	case "abfs", "abfss":
+		if u.User == nil || u.User.Username() == "" || !strings.HasSuffix(u.Hostname(), azureDataLakeHostSuffix) {
+			return fmt.Errorf("%s:// locations must be in the form %s://<container>@<storage-account>%s/<path>", u.Scheme, u.Scheme, azureDataLakeHostSuffix)
+		}
+	default:
+		return fmt.Errorf("unsupported filesystem %q, must be one of hdfs, s3a, gs, abfs or abfss", u.Scheme)
+	}
+	return nil
+}
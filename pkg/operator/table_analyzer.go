@@ -0,0 +1,222 @@
+package operator
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	// DefaultAnalyzeInterval and DefaultAnalyzeMinRows are the defaults of
+	// the automatic collection of table statistics.
+	DefaultAnalyzeInterval = time.Minute * 15
+	DefaultAnalyzeMinRows  = 100000
+
+	tableAnalyzeResultSuccess = "success"
+	tableAnalyzeResultFailure = "failure"
+)
+
+var (
+	tableAnalyzeCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "metering",
+			Name:      "table_analyze_total",
+			Help:      "The number of tables analyzed to update their statistics, by whether the ANALYZE succeeded.",
+		},
+		[]string{"result"},
+	)
+	tableAnalyzeDurationHistogram = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "metering",
+			Name:      "table_analyze_duration_seconds",
+			Help:      "How long analyzing a table to update its statistics took.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(tableAnalyzeCounter)
+	prometheus.MustRegister(tableAnalyzeDurationHistogram)
+}
+
+// AnalyzeConfig configures collecting the statistics of ReportDataSource,
+// Report and ScheduledReport tables after they're written to, which Presto
+// uses to plan the joins of report queries.
+type AnalyzeConfig struct {
+	// Interval is how often the tables written to since they were last
+	// analyzed are checked, and analyzed if the writes were significant.
+	// If 0, tables aren't analyzed.
+	Interval time.Duration
+	// MinRows is how many rows must be imported into a ReportDataSource
+	// table before it's analyzed again. Report tables are analyzed after
+	// every run, as the rows they write aren't counted.
+	MinRows int
+}
+
+func (cfg AnalyzeConfig) Valid() error {
+	if cfg.Interval < 0 {
+		return fmt.Errorf("the analyze interval must not be negative, got %s", cfg.Interval)
+	}
+	if cfg.Interval > 0 && cfg.MinRows <= 0 {
+		return fmt.Errorf("the analyze min rows must be positive when analyzing tables is enabled, got %d", cfg.MinRows)
+	}
+	return nil
+}
+
+// analyzeTable is a table written to since it was last analyzed.
+type analyzeTable struct {
+	// imported is set for tables written to by the Prometheus importer,
+	// which are analyzed by the importer, as the component which writes to
+	// a table is allowed to analyze it. Other tables are analyzed by the
+	// report runner.
+	imported bool
+	// rows is how many rows have been written since the table was last
+	// analyzed.
+	rows int
+	// due is set once the writes are significant enough to analyze the
+	// table.
+	due bool
+}
+
+// tableAnalyzer tracks the writes to tables, and periodically runs ANALYZE
+// on the tables which have had significant writes since they were last
+// analyzed, so that Presto's statistics don't go stale as tables grow. A nil
+// tableAnalyzer analyzes nothing.
+type tableAnalyzer struct {
+	logger   log.FieldLogger
+	clock    clock.Clock
+	interval time.Duration
+	minRows  int
+
+	mu     sync.Mutex
+	tables map[string]*analyzeTable
+}
+
+// newTableAnalyzer returns a tableAnalyzer configured by cfg, or nil if
+// analyzing tables is disabled.
+func newTableAnalyzer(logger log.FieldLogger, clock clock.Clock, cfg AnalyzeConfig) *tableAnalyzer {
+	if cfg.Interval == 0 {
+		return nil
+	}
+	return &tableAnalyzer{
+		logger:   logger,
+		clock:    clock,
+		interval: cfg.Interval,
+		minRows:  cfg.MinRows,
+		tables:   make(map[string]*analyzeTable),
+	}
+}
+
+// recordImportedRows records that the Prometheus importer wrote rows to
+// table. The table is analyzed once minRows have been written since it was
+// last analyzed.
+func (a *tableAnalyzer) recordImportedRows(table string, rows int) {
+	if a == nil || rows <= 0 {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	t := a.table(table)
+	t.imported = true
+	t.rows += rows
+	if t.rows >= a.minRows {
+		t.due = true
+	}
+}
+
+// recordReportInsert records that a report run inserted its results into
+// table. The number of rows isn't known, so every run is significant enough
+// to analyze the table.
+func (a *tableAnalyzer) recordReportInsert(table string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.table(table).due = true
+}
+
+// table returns the writes to table since it was last analyzed. It must be
+// called with a.mu held.
+func (a *tableAnalyzer) table(table string) *analyzeTable {
+	t, ok := a.tables[table]
+	if !ok {
+		t = &analyzeTable{}
+		a.tables[table] = t
+	}
+	return t
+}
+
+// dueTables returns the tables which are due to be analyzed, by name, and
+// forgets their writes.
+func (a *tableAnalyzer) dueTables() map[string]*analyzeTable {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	due := make(map[string]*analyzeTable)
+	for name, t := range a.tables {
+		if t.due {
+			due[name] = t
+			delete(a.tables, name)
+		}
+	}
+	return due
+}
+
+// run analyzes the tables which are due every interval until stopCh is
+// closed, using importerExecer for the tables written to by the Prometheus
+// importer, and reportingExecer for the others.
+func (a *tableAnalyzer) run(stopCh <-chan struct{}, importerExecer, reportingExecer presto.Execer) {
+	tick := a.clock.Tick(a.interval)
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-tick:
+			a.analyzeDueTables(stopCh, importerExecer, reportingExecer)
+		}
+	}
+}
+
+// analyzeDueTables analyzes the tables which are due, one at a time, so that
+// collecting statistics doesn't compete with reports for Presto. Tables which
+// fail to be analyzed are analyzed again after their next significant
+// writes.
+func (a *tableAnalyzer) analyzeDueTables(stopCh <-chan struct{}, importerExecer, reportingExecer presto.Execer) {
+	due := a.dueTables()
+	names := make([]string, 0, len(due))
+	for name := range due {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		select {
+		case <-stopCh:
+			return
+		default:
+		}
+		logger := a.logger.WithField("tableName", name)
+		logger.Debugf("analyzing table %s", name)
+		start := a.clock.Now()
+		execer := reportingExecer
+		if due[name].imported {
+			execer = importerExecer
+		}
+		err := execer.Exec(fmt.Sprintf("ANALYZE %s", name))
+		tableAnalyzeDurationHistogram.Observe(a.clock.Since(start).Seconds())
+		if err != nil {
+			tableAnalyzeCounter.WithLabelValues(tableAnalyzeResultFailure).Inc()
+			logger.WithError(err).Warnf("unable to analyze table %s", name)
+			continue
+		}
+		tableAnalyzeCounter.WithLabelValues(tableAnalyzeResultSuccess).Inc()
+		logger.Infof("analyzed table %s", name)
+	}
+}
@@ -0,0 +1,57 @@
+package operator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+func TestTableAnalyzer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	importerExecer := mockpresto.NewMockExecQueryer(ctrl)
+	reportingExecer := mockpresto.NewMockExecQueryer(ctrl)
+	stopCh := make(chan struct{})
+
+	fakeClock := clock.NewFakeClock(time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC))
+	analyzer := newTableAnalyzer(testLogger, fakeClock, AnalyzeConfig{Interval: time.Minute, MinRows: 100})
+
+	analyzer.recordImportedRows("datasource_pod_cpu_request", 60)
+	analyzer.recordImportedRows("datasource_node_capacity_cpu", 10)
+	analyzer.recordReportInsert("report_namespace_cpu_request")
+	// only the report table is due, as too few rows have been imported into
+	// the ReportDataSource tables.
+	reportingExecer.EXPECT().Exec("ANALYZE report_namespace_cpu_request").Return(nil)
+	analyzer.analyzeDueTables(stopCh, importerExecer, reportingExecer)
+
+	analyzer.recordImportedRows("datasource_pod_cpu_request", 40)
+	importerExecer.EXPECT().Exec("ANALYZE datasource_pod_cpu_request").Return(errors.New("Presto is unavailable"))
+	analyzer.analyzeDueTables(stopCh, importerExecer, reportingExecer)
+
+	// the writes to tables are forgotten once they're analyzed, even if
+	// the ANALYZE failed.
+	analyzer.recordImportedRows("datasource_pod_cpu_request", 99)
+	analyzer.analyzeDueTables(stopCh, importerExecer, reportingExecer)
+	assert.Equal(t, 99, analyzer.tables["datasource_pod_cpu_request"].rows)
+	assert.Equal(t, 10, analyzer.tables["datasource_node_capacity_cpu"].rows)
+}
+
+func TestTableAnalyzerDisabled(t *testing.T) {
+	analyzer := newTableAnalyzer(testLogger, clock.RealClock{}, AnalyzeConfig{})
+	assert.Nil(t, analyzer)
+	analyzer.recordImportedRows("datasource_pod_cpu_request", 1000)
+	analyzer.recordReportInsert("report_namespace_cpu_request")
+}
+
+func TestAnalyzeConfigValid(t *testing.T) {
+	assert.NoError(t, AnalyzeConfig{}.Valid())
+	assert.NoError(t, AnalyzeConfig{Interval: time.Minute, MinRows: 1}.Valid())
+	assert.Error(t, AnalyzeConfig{Interval: -time.Minute}.Valid())
+	assert.Error(t, AnalyzeConfig{Interval: time.Minute}.Valid())
+}
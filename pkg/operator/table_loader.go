@@ -0,0 +1,115 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+// tableLoad is a ReportDataSource whose table is periodically replaced with
+// the results of a query, such as the rows of files in object storage or of
+// a table in an external database.
+type tableLoad struct {
+	namespace      string
+	dataSourceName string
+	tableName      string
+	// description describes what's loaded in logs, eg: csv files.
+	description string
+	query       string
+	interval    time.Duration
+}
+
+func (op *Reporting) runTableLoaderWorker(stopCh <-chan struct{}) {
+	logger := op.logger.WithField("component", "TableLoader")
+	logger.Infof("TableLoader worker started")
+	defer logger.Infof("TableLoader worker shutdown")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	workers := make(map[string]*tableLoaderWorker)
+
+	for {
+		select {
+		case <-stopCh:
+			logger.Infof("got shutdown signal, shutting down TableLoaders")
+			return
+		case dataSourceName := <-op.tableLoaderDeletedDataSourceQueue:
+			if worker, exists := workers[dataSourceName]; exists {
+				worker.stop()
+				delete(workers, dataSourceName)
+			}
+		case load := <-op.tableLoaderNewDataSourceQueue:
+			worker := &tableLoaderWorker{
+				load:   load,
+				stopCh: make(chan struct{}),
+				doneCh: make(chan struct{}),
+			}
+			if existing, exists := workers[load.dataSourceName]; exists {
+				if existing.load == load {
+					// config hasn't changed skip the update
+					continue
+				}
+				existing.stop()
+			}
+			workers[load.dataSourceName] = worker
+
+			dataSourceLogger := logger.WithFields(logrus.Fields{
+				"reportDataSource": load.dataSourceName,
+				"tableName":        load.tableName,
+			})
+			go worker.start(ctx, dataSourceLogger, op)
+		}
+	}
+}
+
+type tableLoaderWorker struct {
+	load   tableLoad
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// start loads the table immediately and then every interval.
+func (w *tableLoaderWorker) start(ctx context.Context, logger logrus.FieldLogger, op *Reporting) {
+	ticker := time.NewTicker(w.load.interval)
+	defer close(w.doneCh)
+	defer ticker.Stop()
+
+	logger.Infof("Loading %s every %s", w.load.description, w.load.interval)
+	for {
+		err := op.loadTable(ctx, w.load)
+		if err != nil {
+			logger.WithError(err).Errorf("error loading %s", w.load.description)
+		}
+		if ctx.Err() == nil {
+			op.updateDataSourceImportCondition(logger, w.load.namespace, w.load.dataSourceName, err)
+		}
+
+		select {
+		case <-w.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *tableLoaderWorker) stop() {
+	close(w.stopCh)
+	<-w.doneCh
+}
+
+// loadTable replaces the contents of the table with the results of the
+// query, so rows which were changed or removed since they were last loaded
+// are reflected in reports.
+func (op *Reporting) loadTable(ctx context.Context, load tableLoad) error {
+	err := presto.DeleteFromContext(ctx, op.importerPrestoQueryer, load.tableName)
+	if err != nil {
+		return fmt.Errorf("couldn't empty table %s of previously loaded %s: %v", load.tableName, load.description, err)
+	}
+	return presto.InsertIntoContext(ctx, op.importerPrestoQueryer, load.tableName, load.query)
+}
@@ -0,0 +1,304 @@
+package operator
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/backup"
+)
+
+// recreatedTableDirRE matches the directories of tables recreated in an
+// object store, which are suffixed with the time they were recreated at.
+var recreatedTableDirRE = regexp.MustCompile(`^(.+)-[0-9]+$`)
+
+// tableRecovery is the outcome of recovering the tables of a Hive metastore.
+type tableRecovery struct {
+	// Recreated are the tables registered in the metastore again, and
+	// Existing the tables which were already registered.
+	Recreated []string
+	Existing  []string
+	// Empty are the recreated tables whose location is in a
+	// StorageLocation, but wasn't found in it, so they have no data.
+	Empty []string
+	// Views are the views of Reports and ScheduledReports, which can't be
+	// recovered from files, and are recreated by running the report again.
+	Views []string
+	// ResetDataSources are the ReportDataSources whose table and
+	// PrestoTable were both missing, whose table name was cleared so that
+	// their tables are created again over their directories.
+	ResetDataSources []string
+	// Orphaned are the directories in StorageLocations which don't belong to
+	// any table, such as the directories of deleted resources' tables.
+	Orphaned []string
+}
+
+// recoverTables registers the tables of the Metering resources in the Hive
+// metastore again, for when the metastore is lost or replaced with an
+// empty one. The directories in each StorageLocation are listed, and
+// matched to the PrestoTables recording the schema, location and
+// partitions of each table, which are recreated over the data in their
+// directories. ReportDataSources whose PrestoTable is also missing are
+// reset so that the reporting-operator creates their tables again, in the
+// same directories.
+func (op *Reporting) recoverTables(logger log.FieldLogger) (*tableRecovery, error) {
+	logger = logger.WithField("component", "tableRecovery")
+	logger.Infof("recovering the tables of the Hive metastore")
+	recovery := &tableRecovery{}
+
+	storageLocations, err := op.informers.Metering().V1alpha1().StorageLocations().Lister().StorageLocations(op.cfg.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("unable to list StorageLocations: %v", err)
+	}
+	var storageDirs []string
+	dirs := make(map[string]bool)
+	for _, storageLocation := range storageLocations {
+		if storageLocation.Spec.Hive == nil || storageLocation.Spec.Hive.TableProperties.Location == "" {
+			continue
+		}
+		location := strings.TrimSuffix(storageLocation.Spec.Hive.TableProperties.Location, "/")
+		listing, err := op.listStorageDirectories(location)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list the directories of StorageLocation %s: %v", storageLocation.Name, err)
+		}
+		storageDirs = append(storageDirs, location)
+		for _, dir := range listing {
+			dirs[dir] = false
+		}
+	}
+
+	prestoTables, err := op.informers.Metering().V1alpha1().PrestoTables().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("unable to list PrestoTables: %v", err)
+	}
+	sort.Slice(prestoTables, func(i, j int) bool {
+		return prestoTables[i].State.Parameters.Name < prestoTables[j].State.Parameters.Name
+	})
+	existing, err := op.listHiveTables(prestoTables)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, prestoTable := range prestoTables {
+		tableName := prestoTable.State.Parameters.Name
+		if tableName == "" {
+			continue
+		}
+		location := strings.TrimSuffix(prestoTable.State.Properties.Location, "/")
+		if _, ok := dirs[location]; ok {
+			dirs[location] = true
+		}
+		if existing[strings.ToLower(tableName)] {
+			recovery.Existing = append(recovery.Existing, tableName)
+			continue
+		}
+		if op.isReportViewPrestoTable(prestoTable) {
+			logger.Warnf("view %s can't be recovered, run its report again to recreate it", tableName)
+			recovery.Views = append(recovery.Views, tableName)
+			continue
+		}
+		logger.Infof("recreating table %s at %s", tableName, location)
+		if err := backup.RestoreTable(op.hiveQueryer, prestoTable); err != nil {
+			return nil, err
+		}
+		recovery.Recreated = append(recovery.Recreated, tableName)
+		if _, found := dirs[location]; !found && isInStorageDirs(location, storageDirs) {
+			logger.Warnf("the location %s of table %s wasn't found, the recreated table is empty", location, tableName)
+			recovery.Empty = append(recovery.Empty, tableName)
+		}
+	}
+
+	hasPrestoTable := make(map[string]bool)
+	for _, prestoTable := range prestoTables {
+		hasPrestoTable[prestoTable.Namespace+"/"+prestoTable.Name] = true
+	}
+	dataSources, err := op.informers.Metering().V1alpha1().ReportDataSources().Lister().ReportDataSources(op.cfg.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("unable to list ReportDataSources: %v", err)
+	}
+	for _, dataSource := range dataSources {
+		if dataSource.TableName == "" || existing[strings.ToLower(dataSource.TableName)] || hasPrestoTable[dataSource.Namespace+"/"+prestoTableResourceNameFromKind("ReportDataSource", dataSource.Name)] {
+			continue
+		}
+		for dir := range dirs {
+			if tableNameFromDirectory(dir) == dataSource.TableName {
+				dirs[dir] = true
+			}
+		}
+		logger.Infof("resetting ReportDataSource %s, its table %s and PrestoTable are missing", dataSource.Name, dataSource.TableName)
+		reset := dataSource.DeepCopy()
+		reset.TableName = ""
+		if _, err := op.meteringClient.MeteringV1alpha1().ReportDataSources(reset.Namespace).Update(reset); err != nil {
+			return nil, fmt.Errorf("unable to reset ReportDataSource %s: %v", dataSource.Name, err)
+		}
+		recovery.ResetDataSources = append(recovery.ResetDataSources, dataSource.Name)
+	}
+
+	// the tables created without a PrestoTable are created again in their
+	// directories the next time they're used.
+	unmanagedTables := map[string]bool{auditTableName: true, healthCheckTableName: true}
+	for _, dataSource := range dataSources {
+		if dataSource.Spec.Promsum != nil && dataSource.Spec.Promsum.Exemplars != nil {
+			unmanagedTables[dataSourceExemplarsTableName(dataSource.Name)] = true
+		}
+	}
+	for dir := range dirs {
+		if unmanagedTables[tableNameFromDirectory(dir)] {
+			dirs[dir] = true
+		}
+	}
+
+	for dir, claimed := range dirs {
+		if !claimed {
+			recovery.Orphaned = append(recovery.Orphaned, dir)
+		}
+	}
+	sort.Strings(recovery.Orphaned)
+	for _, dir := range recovery.Orphaned {
+		logger.Warnf("directory %s doesn't belong to any table", dir)
+	}
+
+	logger.Infof("recovered the tables of the Hive metastore: %d recreated, %d already existed, %d empty, %d views, %d ReportDataSources reset, %d orphaned directories", len(recovery.Recreated), len(recovery.Existing), len(recovery.Empty), len(recovery.Views), len(recovery.ResetDataSources), len(recovery.Orphaned))
+	return recovery, nil
+}
+
+// listStorageDirectories returns the directories within location, which
+// are the directories of the tables stored in it, using Hive's dfs
+// command so that every filesystem Hive can store tables in is supported.
+func (op *Reporting) listStorageDirectories(location string) ([]string, error) {
+	rows, err := op.hiveQueryer.Query(fmt.Sprintf("dfs -ls %s", location))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return parseDirectoryListing(lines), nil
+}
+
+// parseDirectoryListing returns the paths of the directories in the output
+// of hadoop fs -ls, eg:
+//
+//	Found 1 items
+//	drwxr-xr-x   - hadoop hadoop          0 2019-01-02 03:04 s3a://bucket/metering/datasource_pod_request_cpu_cores
+func parseDirectoryListing(lines []string) []string {
+	var dirs []string
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 8 || !strings.HasPrefix(fields[0], "d") {
+			continue
+		}
+		dirs = append(dirs, strings.TrimSuffix(fields[len(fields)-1], "/"))
+	}
+	return dirs
+}
+
+// tableNameFromDirectory returns the name of the table stored in dir,
+// which is the directory's name, less the suffix of tables recreated in an
+// object store.
+func tableNameFromDirectory(dir string) string {
+	name := path.Base(dir)
+	if match := recreatedTableDirRE.FindStringSubmatch(name); match != nil {
+		return match[1]
+	}
+	return name
+}
+
+func isInStorageDirs(location string, storageDirs []string) bool {
+	for _, dir := range storageDirs {
+		if strings.HasPrefix(location, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// listHiveTables returns the lowercased names of the tables in the Hive
+// metastore, in the default schema and in the schemas of the PrestoTables,
+// qualified with their schema outside the default schema.
+func (op *Reporting) listHiveTables(prestoTables []*cbTypes.PrestoTable) (map[string]bool, error) {
+	schemas := map[string]bool{"default": true}
+	for _, prestoTable := range prestoTables {
+		if i := strings.Index(prestoTable.State.Parameters.Name, "."); i > 0 {
+			schemas[prestoTable.State.Parameters.Name[:i]] = true
+		}
+	}
+	tables := make(map[string]bool)
+	for schema := range schemas {
+		rows, err := op.hiveQueryer.Query(fmt.Sprintf("SHOW TABLES IN %s", schema))
+		if err != nil {
+			if schema == "default" {
+				return nil, fmt.Errorf("unable to list the tables in the Hive metastore: %v", err)
+			}
+			// the schemas of tenant namespaces are missing from an empty
+			// metastore, and are created when their tables are recreated.
+			continue
+		}
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if schema != "default" {
+				name = schema + "." + name
+			}
+			tables[strings.ToLower(name)] = true
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+// isReportViewPrestoTable returns true if the PrestoTable is the view of a
+// Report or ScheduledReport whose ReportGenerationQuery uses the view
+// materialization.
+func (op *Reporting) isReportViewPrestoTable(prestoTable *cbTypes.PrestoTable) bool {
+	inf := op.informers.Metering().V1alpha1()
+	for _, ref := range prestoTable.OwnerReferences {
+		var generationQueryName string
+		switch strings.ToLower(ref.Kind) {
+		case "report":
+			report, err := inf.Reports().Lister().Reports(prestoTable.Namespace).Get(ref.Name)
+			if err != nil {
+				continue
+			}
+			generationQueryName = report.Spec.GenerationQueryName
+		case "scheduledreport":
+			report, err := inf.ScheduledReports().Lister().ScheduledReports(prestoTable.Namespace).Get(ref.Name)
+			if err != nil {
+				continue
+			}
+			generationQueryName = report.Spec.GenerationQueryName
+		default:
+			continue
+		}
+		generationQuery, err := inf.ReportGenerationQueries().Lister().ReportGenerationQueries(prestoTable.Namespace).Get(generationQueryName)
+		if err != nil {
+			continue
+		}
+		if generationQuery.Spec.Materialization == cbTypes.ReportMaterializationView {
+			return true
+		}
+	}
+	return false
+}
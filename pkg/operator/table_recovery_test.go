@@ -0,0 +1,53 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDirectoryListing(t *testing.T) {
+	lines := []string{
+		"Found 3 items",
+		"drwxr-xr-x   - hadoop hadoop          0 2019-01-02 03:04 s3a://bucket/metering/datasource_pod_request_cpu_cores",
+		"-rw-r--r--   1 hadoop hadoop       1024 2019-01-02 03:04 s3a://bucket/metering/README",
+		"drwxr-xr-x   - hadoop hadoop          0 2019-01-02 03:04 s3a://bucket/metering/report_namespace_cpu-1546398245000000000/",
+		"",
+	}
+	assert.Equal(t, []string{
+		"s3a://bucket/metering/datasource_pod_request_cpu_cores",
+		"s3a://bucket/metering/report_namespace_cpu-1546398245000000000",
+	}, parseDirectoryListing(lines))
+}
+
+func TestTableNameFromDirectory(t *testing.T) {
+	tests := map[string]struct {
+		dir  string
+		want string
+	}{
+		"table directory": {
+			dir:  "s3a://bucket/metering/datasource_pod_request_cpu_cores",
+			want: "datasource_pod_request_cpu_cores",
+		},
+		"recreated table directory": {
+			dir:  "s3a://bucket/metering/report_namespace_cpu-1546398245000000000",
+			want: "report_namespace_cpu",
+		},
+		"hyphenated table directory": {
+			dir:  "hdfs://hdfs-namenode-0:9820/metering/report_namespace-cpu",
+			want: "report_namespace-cpu",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, tableNameFromDirectory(test.dir))
+		})
+	}
+}
+
+func TestIsInStorageDirs(t *testing.T) {
+	storageDirs := []string{"s3a://bucket/metering"}
+	assert.True(t, isInStorageDirs("s3a://bucket/metering/datasource_pod_request_cpu_cores", storageDirs))
+	assert.False(t, isInStorageDirs("s3a://bucket/metering-archive/datasource_pod_request_cpu_cores", storageDirs))
+	assert.False(t, isInStorageDirs("hdfs://hdfs-namenode-0:9820/metering/datasource_pod_request_cpu_cores", storageDirs))
+}
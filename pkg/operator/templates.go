@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"text/template"
+	"text/template/parse"
 	"time"
 
 	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
@@ -13,20 +14,79 @@ import (
 type templateInfo struct {
 	Report                  *reportTemplateInfo
 	DynamicDependentQueries []*cbTypes.ReportGenerationQuery
+	// viewNames overrides the views generationQueryViewName returns for
+	// ReportGenerationQueries, allowing past revisions of their views to be
+	// used when re-running reports for old periods.
+	viewNames map[string]string
+	// pricingModels are the PricingModels the pricingModelRates,
+	// pricingModelStorageClassRates and pricingModelNodeRates template
+	// functions render, keyed by name. They're only set when rendering queries for reports.
+	pricingModels map[string]*cbTypes.PricingModel
+	// tenantNamespace is set when rendering queries in a tenant namespace,
+	// so that the table name template functions return the tables of the
+	// tenant's schema, and ReportDataSources filtered to its namespace.
+	tenantNamespace string
+}
+
+func (info *templateInfo) generationQueryViewName(queryName string) string {
+	if viewName, ok := info.viewNames[queryName]; ok {
+		return viewName
+	}
+	return tenantTableName(info.tenantNamespace, generationQueryViewName(queryName))
+}
+
+func (info *templateInfo) dataSourceTableName(dataSourceName string) string {
+	return tenantDataSourceTableName(info.tenantNamespace, dataSourceName)
+}
+
+func (info *templateInfo) reportTableName(reportName string) string {
+	return tenantTableName(info.tenantNamespace, reportTableName(reportName))
+}
+
+func (info *templateInfo) scheduledReportTableName(reportName string) string {
+	return tenantTableName(info.tenantNamespace, scheduledReportTableName(reportName))
 }
 
 type reportTemplateInfo struct {
 	StartPeriod time.Time
 	EndPeriod   time.Time
+	// Timezone is the IANA timezone name of the report, defaulting to UTC,
+	// which can be used with the inTimezone template function or Presto's
+	// AT TIME ZONE operator to compute local day and month boundaries.
+	Timezone string
+	// tenantNamespace is the templateInfo's tenantNamespace, which
+	// GroupByLabelsTable filters the labels it reads to.
+	tenantNamespace string
+	// Inputs are the values of the ReportGenerationQuery's inputs, keyed by
+	// name, which are strings, or int64 and time.Time values for integer
+	// and time inputs.
+	Inputs map[string]interface{}
+	// GroupByLabels are the labels the report groups its results by, which
+	// queries supporting groupByLabels render using GroupByLabelsTable,
+	// GroupByLabelColumns and GroupByLabelsClause.
+	GroupByLabels []groupByLabel
+	// CostAllocation is how the report allocates idle cost, which queries
+	// supporting costAllocation render using AllocatedIdleCost.
+	CostAllocation *cbTypes.ReportCostAllocation
 }
 
 func newQueryTemplate(queryTemplate string) (*template.Template, error) {
 	var templateFuncMap = template.FuncMap{
 		"prestoTimestamp":             presto.Timestamp,
+		"prestoString":                prestoString,
 		"dataSourceTableName":         dataSourceTableName,
+		"reportTableName":             reportTableName,
+		"scheduledReportTableName":    scheduledReportTableName,
 		"generationQueryViewName":     generationQueryViewName,
 		"billingPeriodTimestamp":      billingPeriodTimestamp,
 		"renderReportGenerationQuery": renderReportGenerationQuery,
+		"inTimezone":                  inTimezone,
+		"convertUnit":                 convertUnit,
+		// the PricingModel template functions are replaced by the
+		// queryRenderer using the templateInfo's PricingModels.
+		"pricingModelRates":             (*templateInfo)(nil).pricingModelRates,
+		"pricingModelStorageClassRates": (*templateInfo)(nil).pricingModelStorageClassRates,
+		"pricingModelNodeRates":         (*templateInfo)(nil).pricingModelNodeRates,
 	}
 
 	tmpl, err := template.New("report-generation-query").Delims("{|", "|}").Funcs(templateFuncMap).Parse(queryTemplate)
@@ -36,15 +96,58 @@ func newQueryTemplate(queryTemplate string) (*template.Template, error) {
 	return tmpl, nil
 }
 
+// inTimezone converts t to the local time in timezone, so that the
+// timestamps of a report can be rendered as local time, eg:
+// {| .Report.StartPeriod | inTimezone .Report.Timezone | prestoTimestamp |}
+func inTimezone(timezone string, t time.Time) (time.Time, error) {
+	loc, err := loadTimezone(timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(loc), nil
+}
+
 type queryRenderer struct {
 	templateInfo *templateInfo
 }
 
+// Render renders the query template. Queries rendered in a tenant
+// namespace are rejected with a tenantQueryError if they read tables
+// outside the tenant's schema.
 func (qr queryRenderer) Render(query string) (string, error) {
+	rendered, err := qr.render(query)
+	if err != nil {
+		return "", err
+	}
+	if qr.templateInfo != nil && qr.templateInfo.tenantNamespace != "" {
+		if err := checkTenantQueryTables(rendered, qr.templateInfo.tenantNamespace); err != nil {
+			return "", err
+		}
+	}
+	return rendered, nil
+}
+
+func (qr queryRenderer) render(query string) (string, error) {
 	tmpl, err := newQueryTemplate(query)
 	if err != nil {
 		return "", err
 	}
+	if qr.templateInfo != nil {
+		funcs := template.FuncMap{
+			"pricingModelRates":             qr.templateInfo.pricingModelRates,
+			"pricingModelStorageClassRates": qr.templateInfo.pricingModelStorageClassRates,
+			"pricingModelNodeRates":         qr.templateInfo.pricingModelNodeRates,
+		}
+		if len(qr.templateInfo.viewNames) != 0 || qr.templateInfo.tenantNamespace != "" {
+			funcs["generationQueryViewName"] = qr.templateInfo.generationQueryViewName
+		}
+		if qr.templateInfo.tenantNamespace != "" {
+			funcs["dataSourceTableName"] = qr.templateInfo.dataSourceTableName
+			funcs["reportTableName"] = qr.templateInfo.reportTableName
+			funcs["scheduledReportTableName"] = qr.templateInfo.scheduledReportTableName
+		}
+		tmpl = tmpl.Funcs(funcs)
+	}
 	return qr.renderTemplate(tmpl)
 }
 
@@ -68,10 +171,89 @@ func renderReportGenerationQuery(generationQueryName string, templateInfo *templ
 	if query == "" {
 		return "", fmt.Errorf("unknown generationQuery %s", generationQueryName)
 	}
+	// the query is checked as part of the query it's rendered in.
 	qr := queryRenderer{templateInfo: templateInfo}
-	renderedQuery, err := qr.Render(query)
+	renderedQuery, err := qr.render(query)
 	if err != nil {
 		return "", fmt.Errorf("unable to render query %s, err: %v", generationQueryName, err)
 	}
 	return renderedQuery, nil
 }
+
+// getTemplateDataSourceReferences parses the query template and returns the
+// names of the ReportDataSources it references using the dataSourceTableName
+// template function. Only references using string literals can be detected.
+func getTemplateDataSourceReferences(query string) ([]string, error) {
+	return getTemplateReferences(query, "dataSourceTableName")
+}
+
+// getTemplateReferences parses the query template and returns the string
+// literal arguments of each call to the funcName template function.
+func getTemplateReferences(query, funcName string) ([]string, error) {
+	tmpl, err := newQueryTemplate(query)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	seen := make(map[string]struct{})
+	walkTemplateFuncCalls(tmpl.Tree.Root, funcName, func(arg string) {
+		if _, exists := seen[arg]; !exists {
+			seen[arg] = struct{}{}
+			names = append(names, arg)
+		}
+	})
+	return names, nil
+}
+
+// walkTemplateFuncCalls walks the template parse tree calling fn with the
+// string literal argument of each call to the funcName template function,
+// either in the form {| funcName "arg" |} or {| "arg" | funcName |}.
+func walkTemplateFuncCalls(node parse.Node, funcName string, fn func(arg string)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkTemplateFuncCalls(child, funcName, fn)
+		}
+	case *parse.ActionNode:
+		walkTemplateFuncCalls(n.Pipe, funcName, fn)
+	case *parse.IfNode:
+		walkTemplateFuncCalls(&n.BranchNode, funcName, fn)
+	case *parse.RangeNode:
+		walkTemplateFuncCalls(&n.BranchNode, funcName, fn)
+	case *parse.WithNode:
+		walkTemplateFuncCalls(&n.BranchNode, funcName, fn)
+	case *parse.BranchNode:
+		walkTemplateFuncCalls(n.Pipe, funcName, fn)
+		walkTemplateFuncCalls(n.List, funcName, fn)
+		walkTemplateFuncCalls(n.ElseList, funcName, fn)
+	case *parse.TemplateNode:
+		walkTemplateFuncCalls(n.Pipe, funcName, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for i, cmd := range n.Cmds {
+			if len(cmd.Args) == 0 {
+				continue
+			}
+			if ident, ok := cmd.Args[0].(*parse.IdentifierNode); ok && ident.Ident == funcName {
+				if len(cmd.Args) == 2 {
+					if str, ok := cmd.Args[1].(*parse.StringNode); ok {
+						fn(str.Text)
+					}
+				} else if len(cmd.Args) == 1 && i > 0 && len(n.Cmds[i-1].Args) == 1 {
+					// the argument was piped in from the previous command
+					if str, ok := n.Cmds[i-1].Args[0].(*parse.StringNode); ok {
+						fn(str.Text)
+					}
+				}
+			}
+			for _, arg := range cmd.Args {
+				walkTemplateFuncCalls(arg, funcName, fn)
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTemplateDataSourceReferences(t *testing.T) {
+	tests := map[string]struct {
+		query    string
+		expected []string
+	}{
+		"no references": {
+			query:    `SELECT * FROM foo`,
+			expected: nil,
+		},
+		"function call": {
+			query:    `SELECT * FROM {| dataSourceTableName "pod-request-memory-bytes" |}`,
+			expected: []string{"pod-request-memory-bytes"},
+		},
+		"piped argument": {
+			query:    `SELECT * FROM {| "pod-request-memory-bytes" | dataSourceTableName |}`,
+			expected: []string{"pod-request-memory-bytes"},
+		},
+		"join with duplicates": {
+			query: `SELECT * FROM {| dataSourceTableName "node-capacity-cpu-cores" |} a
+			JOIN {| dataSourceTableName "pod-usage-cpu-cores" |} b ON a.timestamp = b.timestamp
+			JOIN {| dataSourceTableName "node-capacity-cpu-cores" |} c ON a.timestamp = c.timestamp`,
+			expected: []string{"node-capacity-cpu-cores", "pod-usage-cpu-cores"},
+		},
+		"inside if and range": {
+			query:    `{| if .Report |}{| dataSourceTableName "a" |}{| else |}{| dataSourceTableName "b" |}{| end |}{| range .DynamicDependentQueries |}{| dataSourceTableName "c" |}{| end |}`,
+			expected: []string{"a", "b", "c"},
+		},
+		"ignores other functions": {
+			query:    `SELECT * FROM {| generationQueryViewName "pod-memory-request-raw" |}`,
+			expected: nil,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			refs, err := getTemplateDataSourceReferences(tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, refs)
+		})
+	}
+}
+
+func TestRenderPrestoString(t *testing.T) {
+	qr := queryRenderer{templateInfo: &templateInfo{
+		Report: &reportTemplateInfo{Inputs: map[string]interface{}{"team": "o'brien"}},
+	}}
+	query, err := qr.Render(`SELECT * FROM t WHERE team = {| .Report.Inputs.team | prestoString |}`)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM t WHERE team = 'o''brien'`, query)
+}
+
+func TestRenderInTimezone(t *testing.T) {
+	start := time.Date(2018, time.July, 1, 4, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		timezone    string
+		expected    string
+		expectError bool
+	}{
+		"UTC": {
+			timezone: "UTC",
+			expected: "timestamp '2018-07-01 04:00:00.000'",
+		},
+		"America/New_York": {
+			timezone: "America/New_York",
+			expected: "timestamp '2018-07-01 00:00:00.000'",
+		},
+		"invalid timezone": {
+			timezone:    "Not/AZone",
+			expectError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			qr := queryRenderer{templateInfo: &templateInfo{
+				Report: &reportTemplateInfo{StartPeriod: start, Timezone: tt.timezone},
+			}}
+			query, err := qr.Render(`timestamp '{| .Report.StartPeriod | inTimezone .Report.Timezone | prestoTimestamp |}'`)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, query)
+		})
+	}
+}
@@ -0,0 +1,455 @@
+package operator
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+// tenantSchema is the Hive database the tables and views of Reports,
+// ScheduledReports and ReportGenerationQueries in a tenant namespace are
+// created in. Queries rendered in a tenant namespace can only read the
+// tables of its schema, which is checked by checkTenantQueryTables.
+func tenantSchema(namespace string) string {
+	return fmt.Sprintf("tenant_%s", resourceNameReplacer.Replace(namespace))
+}
+
+// tenantTableName qualifies the table with the schema of the tenant
+// namespace, or returns it unchanged if tenantNamespace is empty.
+func tenantTableName(tenantNamespace, table string) string {
+	if tenantNamespace == "" {
+		return table
+	}
+	return tenantSchema(tenantNamespace) + "." + table
+}
+
+// tenantDataSourceTableName returns the table queries in the tenant
+// namespace read the ReportDataSource from. ReportDataSources are shared
+// from the metering namespace, so for tenant namespaces this is a subquery
+// of the rows labelled with the tenant's namespace.
+func tenantDataSourceTableName(tenantNamespace, dataSourceName string) string {
+	if tenantNamespace == "" {
+		return dataSourceTableName(dataSourceName)
+	}
+	return fmt.Sprintf("(SELECT * FROM %s WHERE element_at(labels, 'namespace') = '%s')", dataSourceTableName(dataSourceName), tenantNamespace)
+}
+
+// tenantNamespace returns the namespace if it's a tenant namespace, or an
+// empty string for the metering namespace, or if tenant namespaces aren't
+// enabled.
+func (op *Reporting) tenantNamespace(namespace string) string {
+	if !op.cfg.EnableTenantNamespaces || namespace == op.cfg.Namespace {
+		return ""
+	}
+	return namespace
+}
+
+func (op *Reporting) namespacedReportTableName(namespace, reportName string) string {
+	return tenantTableName(op.tenantNamespace(namespace), reportTableName(reportName))
+}
+
+func (op *Reporting) namespacedScheduledReportTableName(namespace, reportName string) string {
+	return tenantTableName(op.tenantNamespace(namespace), scheduledReportTableName(reportName))
+}
+
+// namespaceListers returns the listers the API uses for the namespace.
+// ReportTemplates are always shared from the metering namespace.
+func (op *Reporting) namespaceListers(namespace string) meteringListers {
+	inf := op.informers.Metering().V1alpha1()
+	return meteringListers{
+		reports:                 inf.Reports().Lister().Reports(namespace),
+		scheduledReports:        inf.ScheduledReports().Lister().ScheduledReports(namespace),
+		reportGenerationQueries: inf.ReportGenerationQueries().Lister().ReportGenerationQueries(namespace),
+		reportDataSources:       inf.ReportDataSources().Lister().ReportDataSources(op.cfg.Namespace),
+		reportPrometheusQueries: inf.ReportPrometheusQueries().Lister().ReportPrometheusQueries(op.cfg.Namespace),
+		prestoTables:            inf.PrestoTables().Lister().PrestoTables(namespace),
+		reportTemplates:         inf.ReportTemplates().Lister().ReportTemplates(op.cfg.Namespace),
+		tenantNamespace:         op.tenantNamespace(namespace),
+	}
+}
+
+// ensureTenantSchema creates the schema of the namespace if it's a tenant
+// namespace and the schema hasn't been created yet.
+func (op *Reporting) ensureTenantSchema(logger log.FieldLogger, namespace string) error {
+	tenantNamespace := op.tenantNamespace(namespace)
+	if tenantNamespace == "" {
+		return nil
+	}
+	op.tenantSchemasMu.Lock()
+	defer op.tenantSchemasMu.Unlock()
+	schema := tenantSchema(tenantNamespace)
+	if op.tenantSchemas[schema] {
+		return nil
+	}
+	logger.Infof("creating schema %s for tenant namespace %s", schema, tenantNamespace)
+	_, err := op.hiveQueryer.Query(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", schema))
+	if err != nil {
+		return fmt.Errorf("couldn't create schema %s for namespace %s: %v", schema, tenantNamespace, err)
+	}
+	op.tenantSchemas[schema] = true
+	return nil
+}
+
+// getDependentDataSource returns the ReportDataSource a
+// ReportGenerationQuery in the namespace depends on. Tenant namespaces
+// can't have their own ReportDataSources, and instead use the Prometheus
+// ReportDataSources in the metering namespace, which are filtered to the
+// tenant's namespace when rendering queries.
+func (op *Reporting) getDependentDataSource(namespace, name string) (*cbTypes.ReportDataSource, error) {
+	lister := op.informers.Metering().V1alpha1().ReportDataSources().Lister()
+	tenantNamespace := op.tenantNamespace(namespace)
+	if tenantNamespace == "" {
+		return lister.ReportDataSources(namespace).Get(name)
+	}
+	dataSource, err := lister.ReportDataSources(op.cfg.Namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if dataSource.Spec.Promsum == nil {
+		return nil, fmt.Errorf("ReportDataSource %s can't be used in namespace %s, only Prometheus ReportDataSources are shared with tenant namespaces", name, tenantNamespace)
+	}
+	return dataSource, nil
+}
+
+// tenantQueryError is returned when a query rendered in a tenant namespace
+// references a table it isn't allowed to read.
+type tenantQueryError struct {
+	msg string
+}
+
+func (e *tenantQueryError) Error() string {
+	return e.msg
+}
+
+func newTenantQueryError(format string, args ...interface{}) error {
+	return &tenantQueryError{msg: fmt.Sprintf(format, args...)}
+}
+
+// checkTenantQueryTables returns a tenantQueryError if the rendered query
+// reads a table other than the tables of the tenant's schema, the common
+// table expressions it defines, and the ReportDataSource subqueries
+// dataSourceTableName renders for the tenant, which are filtered to its
+// namespace. Every query runs as the same Presto user, so this is what
+// stops a tenant's queries reading the tables of the metering namespace or
+// other tenants by naming them directly.
+func checkTenantQueryTables(query, tenantNamespace string) error {
+	tokens, err := tokenizeSQL(query)
+	if err != nil {
+		return newTenantQueryError("unable to check the tables the query reads: %v", err)
+	}
+	schema := tenantSchema(tenantNamespace)
+	// scopes holds a scope for each level of parentheses the query is in.
+	scopes := []*sqlScope{{}}
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		scope := scopes[len(scopes)-1]
+
+		if n := matchTenantDataSourceSubquery(tokens[i:], tenantNamespace); n != 0 {
+			scope.expectTable = false
+			if scope.with == sqlWithAs {
+				scope.defineCTE()
+			}
+			i += n - 1
+			continue
+		}
+
+		if scope.expectTable {
+			scope.expectTable = false
+			switch {
+			case tok.is(sqlPunctuation, "("):
+				// either a subquery or a parenthesized relation, which
+				// is checked like a FROM clause.
+				scopes = append(scopes, &sqlScope{inFrom: true, expectTable: true})
+				continue
+			case tok.is(sqlWord, "lateral"):
+				scope.expectTable = true
+				continue
+			case tok.is(sqlWord, "unnest"):
+				continue
+			case tok.is(sqlWord, "select"), tok.is(sqlWord, "with"), tok.is(sqlWord, "values"), tok.is(sqlWord, "table"):
+				// the parenthesized relation is a subquery.
+				scope.inFrom = false
+			case tok.kind == sqlWord || tok.kind == sqlQuotedIdentifier:
+				name := []string{tok.text}
+				for i+2 < len(tokens) && tokens[i+1].is(sqlPunctuation, ".") && (tokens[i+2].kind == sqlWord || tokens[i+2].kind == sqlQuotedIdentifier) {
+					name = append(name, tokens[i+2].text)
+					i += 2
+				}
+				if err := checkTenantTableName(name, schema, scopes); err != nil {
+					return err
+				}
+				continue
+			default:
+				return newTenantQueryError("unable to check the tables the query reads: unexpected %q after FROM", tok.text)
+			}
+		}
+
+		// the names of common table expressions aren't keywords.
+		if scope.with != sqlWithNone && tok.kind != sqlPunctuation {
+			switch scope.with {
+			case sqlWithName:
+				if !tok.is(sqlWord, "recursive") {
+					scope.cteName = tok.text
+					scope.with = sqlWithColumns
+				}
+				continue
+			case sqlWithColumns:
+				if tok.is(sqlWord, "as") {
+					scope.with = sqlWithAs
+					continue
+				}
+			}
+			scope.with = sqlWithNone
+		}
+
+		switch tok.kind {
+		case sqlPunctuation:
+			switch tok.text {
+			case "(":
+				child := &sqlScope{}
+				if i > 0 && tokens[i-1].kind == sqlWord {
+					child.function = tokens[i-1].text
+				}
+				if scope.with == sqlWithAs {
+					scope.with = sqlWithBody
+					child.cteBody = true
+				} else if scope.with != sqlWithColumns {
+					scope.with = sqlWithNone
+				}
+				scopes = append(scopes, child)
+			case ")":
+				if len(scopes) == 1 {
+					return newTenantQueryError("unable to check the tables the query reads: unbalanced parentheses")
+				}
+				scopes = scopes[:len(scopes)-1]
+				parent := scopes[len(scopes)-1]
+				if scope.cteBody {
+					parent.defineCTE()
+				}
+			case ",":
+				if scope.with == sqlWithNext {
+					scope.with = sqlWithName
+					continue
+				}
+				scope.with = sqlWithNone
+				if scope.inFrom {
+					scope.expectTable = true
+				}
+			default:
+				if scope.with != sqlWithColumns {
+					scope.with = sqlWithNone
+				}
+			}
+		case sqlWord:
+			switch tok.text {
+			case "from":
+				// FROM is also part of EXTRACT(field FROM value) and IS
+				// DISTINCT FROM.
+				if scope.function == "extract" || scope.function == "substring" || scope.function == "trim" || i > 0 && tokens[i-1].is(sqlWord, "distinct") {
+					continue
+				}
+				scope.inFrom = true
+				scope.expectTable = true
+			case "join", "table":
+				scope.expectTable = true
+			case "with":
+				scope.with = sqlWithName
+			case "select", "where", "group", "having", "order", "limit", "offset", "fetch", "window", "union", "intersect", "except":
+				scope.inFrom = false
+			}
+		}
+	}
+	if len(scopes) != 1 {
+		return newTenantQueryError("unable to check the tables the query reads: unbalanced parentheses")
+	}
+	if scopes[0].expectTable {
+		return newTenantQueryError("unable to check the tables the query reads: expected a table at the end of the query")
+	}
+	return nil
+}
+
+// checkTenantTableName returns a tenantQueryError unless the table name is
+// a common table expression in scope, or a table in the tenant's schema.
+func checkTenantTableName(name []string, schema string, scopes []*sqlScope) error {
+	qualified := strings.Join(name, ".")
+	switch len(name) {
+	case 1:
+		for _, scope := range scopes {
+			if scope.ctes[name[0]] {
+				return nil
+			}
+		}
+	case 2:
+		if name[0] == schema {
+			return nil
+		}
+	case 3:
+		if name[0] == "hive" && name[1] == schema {
+			return nil
+		}
+	}
+	return newTenantQueryError("queries in tenant namespaces can only read the tables of schema %s and the ReportDataSources rendered by dataSourceTableName, but the query reads %s", schema, qualified)
+}
+
+// matchTenantDataSourceSubquery returns the number of tokens of the
+// subquery tenantDataSourceTableName renders for the tenant namespace at
+// the start of tokens, or 0 if tokens don't start with one.
+func matchTenantDataSourceSubquery(tokens []sqlToken, tenantNamespace string) int {
+	expected := []sqlToken{
+		{sqlPunctuation, "("}, {sqlWord, "select"}, {sqlPunctuation, "*"}, {sqlWord, "from"}, {sqlWord, ""},
+		{sqlWord, "where"}, {sqlWord, "element_at"}, {sqlPunctuation, "("}, {sqlWord, "labels"}, {sqlPunctuation, ","},
+		{sqlString, "namespace"}, {sqlPunctuation, ")"}, {sqlPunctuation, "="}, {sqlString, tenantNamespace}, {sqlPunctuation, ")"},
+	}
+	if len(tokens) < len(expected) {
+		return 0
+	}
+	for i, tok := range expected {
+		if tok.text == "" {
+			// the unqualified table of a ReportDataSource in the metering
+			// namespace.
+			if tokens[i].kind != sqlWord || !strings.HasPrefix(tokens[i].text, "datasource_") {
+				return 0
+			}
+			continue
+		}
+		if tokens[i] != tok {
+			return 0
+		}
+	}
+	return len(expected)
+}
+
+// sqlScope is the state of checkTenantQueryTables within a level of
+// parentheses of a query.
+type sqlScope struct {
+	// inFrom is set in FROM clauses, where commas separate tables.
+	inFrom bool
+	// expectTable is set when the next token is a table, subquery or
+	// relation.
+	expectTable bool
+	// ctes are the common table expressions defined in the scope.
+	ctes    map[string]bool
+	with    sqlWithState
+	cteName string
+	// cteBody is set if the scope is the body of a common table
+	// expression, which is defined once it ends.
+	cteBody bool
+	// function is the word before the opening parenthesis of the scope.
+	function string
+}
+
+// defineCTE defines the common table expression whose body just ended.
+// Common table expressions can only be referenced after their definition.
+func (scope *sqlScope) defineCTE() {
+	if scope.ctes == nil {
+		scope.ctes = make(map[string]bool)
+	}
+	scope.ctes[scope.cteName] = true
+	scope.with = sqlWithNext
+}
+
+// sqlWithState is the position in the definitions of common table
+// expressions in a WITH clause.
+type sqlWithState int
+
+const (
+	sqlWithNone sqlWithState = iota
+	sqlWithName
+	sqlWithColumns
+	sqlWithAs
+	sqlWithBody
+	sqlWithNext
+)
+
+type sqlTokenKind int
+
+const (
+	sqlWord sqlTokenKind = iota
+	sqlQuotedIdentifier
+	sqlString
+	sqlPunctuation
+)
+
+// sqlToken is a token of a query. The text of words and quoted identifiers
+// is lowercased, as identifiers aren't case sensitive, and the text of
+// quoted identifiers and strings is unquoted.
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+}
+
+func (tok sqlToken) is(kind sqlTokenKind, text string) bool {
+	return tok.kind == kind && tok.text == text
+}
+
+// tokenizeSQL splits a query into words, quoted identifiers, strings and
+// punctuation, dropping whitespace and comments the same way Presto does.
+func tokenizeSQL(query string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case strings.HasPrefix(query[i:], "--"):
+			// comments end at either line ending.
+			end := strings.IndexAny(query[i:], "\r\n")
+			if end == -1 {
+				end = len(query) - i
+			}
+			i += end
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 4
+		case c == '\'' || c == '"':
+			var text strings.Builder
+			j := i + 1
+			for {
+				if j >= len(query) {
+					return nil, fmt.Errorf("unterminated %c quote", c)
+				}
+				if query[j] == c {
+					// quotes are escaped by doubling them.
+					if j+1 < len(query) && query[j+1] == c {
+						text.WriteByte(c)
+						j += 2
+						continue
+					}
+					break
+				}
+				text.WriteByte(query[j])
+				j++
+			}
+			if c == '"' {
+				tokens = append(tokens, sqlToken{sqlQuotedIdentifier, strings.ToLower(text.String())})
+			} else {
+				tokens = append(tokens, sqlToken{sqlString, text.String()})
+			}
+			i = j + 1
+		case isSQLWordByte(c):
+			j := i
+			for j < len(query) && isSQLWordByte(query[j]) {
+				j++
+			}
+			tokens = append(tokens, sqlToken{sqlWord, strings.ToLower(query[i:j])})
+			i = j
+		default:
+			tokens = append(tokens, sqlToken{sqlPunctuation, string(c)})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// isSQLWordByte returns whether c can be part of an unquoted identifier,
+// keyword or number. Bytes of non-ASCII characters are included, so they're
+// never mistaken for punctuation.
+func isSQLWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '@' || c == ':' || c >= 0x80
+}
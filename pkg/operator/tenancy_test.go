@@ -0,0 +1,207 @@
+package operator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	listers "github.com/operator-framework/operator-metering/pkg/generated/listers/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+func TestRenderTenantQuery(t *testing.T) {
+	const query = `SELECT * FROM {| dataSourceTableName "pod-cpu" |} JOIN {| reportTableName "node-cost" |} JOIN {| scheduledReportTableName "daily" |} JOIN {| generationQueryViewName "pod-usage" |}`
+	tests := map[string]struct {
+		info     *templateInfo
+		expected string
+	}{
+		"metering namespace": {
+			info:     &templateInfo{},
+			expected: `SELECT * FROM datasource_pod_cpu JOIN report_node_cost JOIN scheduled_report_daily JOIN view_pod_usage`,
+		},
+		"tenant namespace": {
+			info:     &templateInfo{tenantNamespace: "team-a"},
+			expected: `SELECT * FROM (SELECT * FROM datasource_pod_cpu WHERE element_at(labels, 'namespace') = 'team-a') JOIN tenant_team_a.report_node_cost JOIN tenant_team_a.scheduled_report_daily JOIN tenant_team_a.view_pod_usage`,
+		},
+		"tenant namespace with past revisions": {
+			info:     &templateInfo{tenantNamespace: "team-a", viewNames: map[string]string{"pod-usage": "tenant_team_a.view_pod_usage_1530403200"}},
+			expected: `SELECT * FROM (SELECT * FROM datasource_pod_cpu WHERE element_at(labels, 'namespace') = 'team-a') JOIN tenant_team_a.report_node_cost JOIN tenant_team_a.scheduled_report_daily JOIN tenant_team_a.view_pod_usage_1530403200`,
+		},
+	}
+	for name, tt := range tests {
+		rendered, err := queryRenderer{templateInfo: tt.info}.Render(query)
+		require.NoError(t, err, name)
+		assert.Equal(t, tt.expected, rendered, name)
+	}
+}
+
+func TestCheckTenantQueryTables(t *testing.T) {
+	const dataSource = `(SELECT * FROM datasource_pod_cpu WHERE element_at(labels, 'namespace') = 'team-a')`
+	tests := map[string]struct {
+		query       string
+		expectError bool
+	}{
+		"tenant tables": {
+			query: `SELECT * FROM tenant_team_a.report_node_cost a JOIN hive.tenant_team_a.view_pod_usage b ON a.pod = b.pod, "TENANT_TEAM_A"."scheduled_report_daily" c`,
+		},
+		"data source": {
+			query: `SELECT * FROM ` + dataSource + ` AS pod_cpu CROSS JOIN UNNEST(pod_cpu.labels) AS t (k, v)`,
+		},
+		"data source as common table expression": {
+			query: `WITH pod_cpu AS ` + dataSource + `, usage (pod) AS (SELECT pod FROM pod_cpu) SELECT * FROM usage, pod_cpu WHERE pod_cpu.pod IN (SELECT pod FROM usage)`,
+		},
+		"subqueries and values": {
+			query: `SELECT extract(day FROM "timestamp"), x IS DISTINCT FROM y FROM (SELECT * FROM (VALUES (1, 'FROM default.secrets')) AS v (x, y)) -- FROM default.secrets
+			/* FROM default.secrets */ JOIN LATERAL (SELECT * FROM tenant_team_a.report_payroll) ON true`,
+		},
+		"another tenant's report": {
+			query:       `SELECT * FROM tenant_team_b.report_payroll`,
+			expectError: true,
+		},
+		"another tenant's report in a subquery": {
+			query:       `SELECT * FROM tenant_team_a.report_payroll WHERE pod IN (SELECT pod FROM hive.tenant_team_b.report_payroll)`,
+			expectError: true,
+		},
+		"unfiltered data source": {
+			query:       `SELECT * FROM datasource_pod_cpu`,
+			expectError: true,
+		},
+		"data source filtered to another tenant": {
+			query:       `SELECT * FROM (SELECT * FROM datasource_pod_cpu WHERE element_at(labels, 'namespace') = 'team-b')`,
+			expectError: true,
+		},
+		"metering namespace report": {
+			query:       `SELECT * FROM tenant_team_a.report_payroll, "default".report_payroll`,
+			expectError: true,
+		},
+		"parenthesized table": {
+			query:       `SELECT * FROM ((tenant_team_a.report_payroll) JOIN (datasource_pod_cpu) ON true)`,
+			expectError: true,
+		},
+		"table query": {
+			query:       `SELECT * FROM (TABLE system.runtime.queries)`,
+			expectError: true,
+		},
+		"common table expression referencing itself": {
+			query:       `WITH datasource_pod_cpu AS (SELECT * FROM datasource_pod_cpu) SELECT * FROM datasource_pod_cpu`,
+			expectError: true,
+		},
+		"common table expression out of scope": {
+			query:       `SELECT * FROM (WITH datasource_pod_cpu AS (SELECT 1) SELECT * FROM datasource_pod_cpu) a, datasource_pod_cpu`,
+			expectError: true,
+		},
+		"table after a comment ending with a carriage return": {
+			query:       "SELECT * FROM tenant_team_a.report_payroll -- comment\r, datasource_pod_cpu",
+			expectError: true,
+		},
+		"unterminated string": {
+			query:       `SELECT * FROM tenant_team_a.report_payroll WHERE pod = 'a`,
+			expectError: true,
+		},
+	}
+	for name, tt := range tests {
+		err := checkTenantQueryTables(tt.query, "team-a")
+		if tt.expectError {
+			assert.IsType(t, &tenantQueryError{}, err, name)
+		} else {
+			assert.NoError(t, err, name)
+		}
+	}
+}
+
+func TestRenderTenantQueryCrossTenantReference(t *testing.T) {
+	qr := queryRenderer{templateInfo: &templateInfo{tenantNamespace: "team-a"}}
+	_, err := qr.Render(`SELECT * FROM {| reportTableName "payroll" |} UNION ALL SELECT * FROM tenant_team_b.report_payroll`)
+	assert.EqualError(t, err, "queries in tenant namespaces can only read the tables of schema tenant_team_a and the ReportDataSources rendered by dataSourceTableName, but the query reads tenant_team_b.report_payroll")
+}
+
+func TestGetReportTenantNamespace(t *testing.T) {
+	const (
+		meteringNamespace = "metering"
+		tenantNamespace   = "team-a"
+		reportName        = "cpu-usage"
+	)
+	start := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	columns := []v1alpha1.ReportGenerationQueryColumn{{Name: "pod", Type: "string"}}
+	tableColumns := []hive.Column{{Name: "pod", Type: "string"}}
+
+	reportIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	reportGenerationQueryIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	prestoTableIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	reportIndexer.Add(newTestReport(reportName, tenantNamespace, "test-query", start, end, v1alpha1.ReportStatus{Phase: v1alpha1.ReportPhaseFinished}))
+	reportGenerationQueryIndexer.Add(newTestReportGenQuery("test-query", tenantNamespace, columns))
+	prestoTableIndexer.Add(newTestPrestoTable(reportName, tenantNamespace, tableColumns))
+	namespaceListers := func(namespace string) meteringListers {
+		l := meteringListers{
+			reports:                 listers.NewReportLister(reportIndexer).Reports(namespace),
+			reportGenerationQueries: listers.NewReportGenerationQueryLister(reportGenerationQueryIndexer).ReportGenerationQueries(namespace),
+			prestoTables:            listers.NewPrestoTableLister(prestoTableIndexer).PrestoTables(namespace),
+		}
+		if namespace != meteringNamespace {
+			l.tenantNamespace = namespace
+		}
+		return l
+	}
+	prestoColumns, err := hiveColumnsToPrestoColumns(tableColumns)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		path               string
+		enableTenancy      bool
+		expectedSQL        string
+		expectedStatusCode int
+	}{
+		"tenant report": {
+			path:               APIV1ReportsGetEndpoint + "?format=csv&name=" + reportName + "&namespace=" + tenantNamespace,
+			enableTenancy:      true,
+			expectedSQL:        presto.GenerateGetRowsWhereSQL("tenant_team_a.report_cpu_usage", prestoColumns, ""),
+			expectedStatusCode: http.StatusOK,
+		},
+		"report in another namespace": {
+			path:               APIV1ReportsGetEndpoint + "?format=csv&name=" + reportName,
+			enableTenancy:      true,
+			expectedStatusCode: http.StatusNotFound,
+		},
+		"tenancy disabled": {
+			path:               APIV1ReportsGetEndpoint + "?format=csv&name=" + reportName + "&namespace=" + tenantNamespace,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			queryer := mockpresto.NewMockExecQueryer(ctrl)
+			if tt.expectedSQL != "" {
+				queryer.EXPECT().Query(tt.expectedSQL).Return([]presto.Row{{"pod": "app-1"}}, nil)
+			}
+			l := namespaceListers(meteringNamespace)
+			if tt.enableTenancy {
+				l.tenantListers = namespaceListers
+			}
+			router := newRouter(testLogger, routerConfig{
+				rand:            testRand,
+				queryer:         queryer,
+				importerQueryer: queryer,
+				collectorFunc:   noopPrometheusImporterFunc,
+				listers:         l,
+			})
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatusCode, w.Code, "unexpected status code, body: %s", w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package operator
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+const DefaultCertificateReloadInterval = time.Minute
+
+// certificateReloader serves a TLS certificate loaded from files, and
+// reloads it when the files change, so that certificates rotated by
+// cert-manager or the service CA are used without restarting.
+type certificateReloader struct {
+	logger   log.FieldLogger
+	certFile string
+	keyFile  string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newCertificateReloader(logger log.FieldLogger, certFile, keyFile string) (*certificateReloader, error) {
+	r := &certificateReloader{
+		logger:   logger,
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the current certificate, and is used as the
+// tls.Config's GetCertificate.
+func (r *certificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// reload loads the certificate if the files have changed, and returns
+// whether it was loaded. If the files can't be loaded, the current
+// certificate is kept.
+func (r *certificateReloader) reload() (bool, error) {
+	certPEM, err := ioutil.ReadFile(r.certFile)
+	if err != nil {
+		return false, fmt.Errorf("unable to read TLS certificate: %v", err)
+	}
+	keyPEM, err := ioutil.ReadFile(r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("unable to read TLS private key: %v", err)
+	}
+	r.mu.RLock()
+	unchanged := bytes.Equal(certPEM, r.certPEM) && bytes.Equal(keyPEM, r.keyPEM)
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+	// the certificate and key are written separately when they're
+	// rotated, so they may not match until both have been written.
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return false, fmt.Errorf("unable to load TLS certificate: %v", err)
+	}
+	r.mu.Lock()
+	r.cert = &cert
+	r.certPEM = certPEM
+	r.keyPEM = keyPEM
+	r.mu.Unlock()
+	return true, nil
+}
+
+// run reloads the certificate every interval until stopCh is closed.
+func (r *certificateReloader) run(stopCh <-chan struct{}, clock clock.Clock, interval time.Duration) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-clock.Tick(interval):
+			reloaded, err := r.reload()
+			if err != nil {
+				r.logger.WithError(err).Warnf("unable to reload TLS certificate %s, continuing to use the current certificate", r.certFile)
+			} else if reloaded {
+				r.logger.Infof("reloaded TLS certificate %s", r.certFile)
+			}
+		}
+	}
+}
+
+// newServerTLSConfig returns the TLS configuration serving the reloader's
+// certificate. If clientCAFile is set, client certificates are verified
+// with the CA certificates in it. Clients without a certificate are still
+// accepted, and requireClientCertificate rejects their requests, so that
+// health checks and webhooks, whose callers don't have client
+// certificates, can be served by the same listener.
+func newServerTLSConfig(reloader *certificateReloader, clientCAFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		GetCertificate: reloader.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+	if clientCAFile != "" {
+		clientCAs, err := loadCertPool(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsConfig, nil
+}
+
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	caPEM, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read client CA: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in client CA %s", caFile)
+	}
+	return pool, nil
+}
+
+// requireClientCertificate rejects requests which weren't made with a
+// verified client certificate, except to the exempt paths.
+func requireClientCertificate(logger log.FieldLogger, rand *rand.Rand, handler http.Handler, exemptPaths ...string) http.Handler {
+	exempt := make(map[string]bool)
+	for _, path := range exemptPaths {
+		exempt[path] = true
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !exempt[r.URL.Path] && !hasVerifiedClientCertificate(r) {
+			writeErrorResponse(newRequestLogger(logger, r, rand), w, r, http.StatusUnauthorized, "a valid client certificate is required")
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func hasVerifiedClientCertificate(r *http.Request) bool {
+	return r.TLS != nil && len(r.TLS.VerifiedChains) != 0
+}
+
+// clientCertificateUser returns the common name of the request's verified
+// client certificate, or an empty string if it doesn't have one.
+func clientCertificateUser(r *http.Request) string {
+	if !hasVerifiedClientCertificate(r) {
+		return ""
+	}
+	return r.TLS.VerifiedChains[0][0].Subject.CommonName
+}
@@ -0,0 +1,190 @@
+package operator
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCertificate is a certificate and key, signed by its CA, or
+// self-signed if it doesn't have one.
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCertificate(t *testing.T, commonName string, serial int64, ca *testCertificate) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  ca == nil,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	parent, signer := template, key
+	if ca != nil {
+		parent, signer = ca.cert, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return &testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func (c *testCertificate) tlsCertificate(t *testing.T) tls.Certificate {
+	cert, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	require.NoError(t, err)
+	return cert
+}
+
+func writeTestCertificate(t *testing.T, dir string, c *testCertificate) (string, string) {
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	require.NoError(t, ioutil.WriteFile(certFile, c.certPEM, 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile, c.keyPEM, 0600))
+	return certFile, keyFile
+}
+
+func TestCertificateReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metering-tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	first := newTestCertificate(t, "reporting-operator", 1, nil)
+	certFile, keyFile := writeTestCertificate(t, dir, first)
+	reloader, err := newCertificateReloader(testLogger, certFile, keyFile)
+	require.NoError(t, err)
+
+	reloaded, err := reloader.reload()
+	require.NoError(t, err)
+	assert.False(t, reloaded, "unchanged files shouldn't be reloaded")
+
+	// a certificate which doesn't match the key keeps the current
+	// certificate in use.
+	second := newTestCertificate(t, "reporting-operator", 2, nil)
+	require.NoError(t, ioutil.WriteFile(certFile, second.certPEM, 0600))
+	_, err = reloader.reload()
+	assert.Error(t, err)
+	cert, err := reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, first.cert.Raw, cert.Certificate[0])
+
+	require.NoError(t, ioutil.WriteFile(keyFile, second.keyPEM, 0600))
+	reloaded, err = reloader.reload()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	cert, err = reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, second.cert.Raw, cert.Certificate[0])
+}
+
+func TestRequireClientCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metering-tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca := newTestCertificate(t, "metering-ca", 1, nil)
+	serverCert := newTestCertificate(t, "reporting-operator", 2, ca)
+	clientCert := newTestCertificate(t, "billing-exporter", 3, ca)
+	untrustedCert := newTestCertificate(t, "untrusted", 4, nil)
+
+	certFile, keyFile := writeTestCertificate(t, dir, serverCert)
+	caFile := filepath.Join(dir, "ca.crt")
+	require.NoError(t, ioutil.WriteFile(caFile, ca.certPEM, 0600))
+	reloader, err := newCertificateReloader(testLogger, certFile, keyFile)
+	require.NoError(t, err)
+	tlsConfig, err := newServerTLSConfig(reloader, caFile)
+	require.NoError(t, err)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(clientCertificateUser(r)))
+	})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &http.Server{
+		Handler:   requireClientCertificate(testLogger, testRand, handler, "/healthy"),
+		TLSConfig: tlsConfig,
+		ErrorLog:  log.New(ioutil.Discard, "", 0),
+	}
+	go srv.ServeTLS(listener, "", "")
+	defer srv.Close()
+	url := "https://" + listener.Addr().String()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(ca.cert)
+
+	tests := map[string]struct {
+		path               string
+		clientCert         *testCertificate
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		"trusted client certificate": {
+			path:               APIV1ReportsGetEndpoint,
+			clientCert:         clientCert,
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "billing-exporter",
+		},
+		"no client certificate": {
+			path:               APIV1ReportsGetEndpoint,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		"health check without a client certificate": {
+			path:               "/healthy",
+			expectedStatusCode: http.StatusOK,
+		},
+		"untrusted client certificate": {
+			path:               APIV1ReportsGetEndpoint,
+			clientCert:         untrustedCert,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+	for name, tt := range tests {
+		clientTLSConfig := &tls.Config{RootCAs: rootCAs}
+		if tt.clientCert != nil {
+			clientTLSConfig.Certificates = []tls.Certificate{tt.clientCert.tlsCertificate(t)}
+		}
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLSConfig}}
+		resp, err := client.Get(url + tt.path)
+		require.NoError(t, err, name)
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err, name)
+		assert.Equal(t, tt.expectedStatusCode, resp.StatusCode, name)
+		if tt.expectedBody != "" {
+			assert.Equal(t, tt.expectedBody, string(body), name)
+		}
+	}
+}
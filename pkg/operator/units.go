@@ -0,0 +1,112 @@
+package operator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	api "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+// columnUnit is a unit of the values of ReportGenerationQuery columns which
+// can be converted to the other units of the same quantity.
+type columnUnit struct {
+	// quantity is what the unit measures. Only units of the same quantity
+	// can be converted to each other.
+	quantity string
+	// scale is how many of the quantity's smallest unit the unit is.
+	scale int64
+}
+
+const (
+	kib = 1 << 10
+	mib = 1 << 20
+	gib = 1 << 30
+)
+
+// columnUnits are the units which the convertUnit template function can
+// convert between, keyed by the name used in the unit field of columns.
+var columnUnits = map[string]columnUnit{
+	"seconds": {quantity: "time", scale: 1},
+	"minutes": {quantity: "time", scale: 60},
+	"hours":   {quantity: "time", scale: 3600},
+	"days":    {quantity: "time", scale: 86400},
+
+	"millicores": {quantity: "cpu", scale: 1},
+	"cpu_cores":  {quantity: "cpu", scale: 1000},
+
+	"cpu_core_seconds": {quantity: "cpu_time", scale: 1},
+	"cpu_core_hours":   {quantity: "cpu_time", scale: 3600},
+
+	"bytes": {quantity: "memory", scale: 1},
+	"kib":   {quantity: "memory", scale: kib},
+	"mib":   {quantity: "memory", scale: mib},
+	"gib":   {quantity: "memory", scale: gib},
+	"kb":    {quantity: "memory", scale: 1e3},
+	"mb":    {quantity: "memory", scale: 1e6},
+	"gb":    {quantity: "memory", scale: 1e9},
+
+	"byte_seconds": {quantity: "memory_time", scale: 1},
+	"byte_hours":   {quantity: "memory_time", scale: 3600},
+	"gib_seconds":  {quantity: "memory_time", scale: gib},
+	"gib_hours":    {quantity: "memory_time", scale: gib * 3600},
+
+	"bytes_per_hour":   {quantity: "throughput", scale: 1},
+	"bytes_per_second": {quantity: "throughput", scale: 3600},
+
+	"gpus": {quantity: "gpu", scale: 1},
+
+	"gpu_seconds": {quantity: "gpu_time", scale: 1},
+	"gpu_hours":   {quantity: "gpu_time", scale: 3600},
+}
+
+// convertUnit returns a Presto expression converting the value of expr from
+// one unit to another, eg:
+// {| convertUnit "cpu_core_seconds" "cpu_core_hours" "sum(pod_request_cpu_core_seconds)" |}
+// renders (sum(pod_request_cpu_core_seconds) / 3600E0). Values converted to
+// a smaller unit are multiplied by an integer, keeping their type, while
+// values converted to a larger unit are divided as doubles, so integers
+// aren't truncated.
+func convertUnit(from, to, expr string) (string, error) {
+	fromUnit, ok := columnUnits[from]
+	if !ok {
+		return "", fmt.Errorf("cannot convert from unknown unit %q, must be one of: %s", from, knownColumnUnits())
+	}
+	toUnit, ok := columnUnits[to]
+	if !ok {
+		return "", fmt.Errorf("cannot convert to unknown unit %q, must be one of: %s", to, knownColumnUnits())
+	}
+	if fromUnit.quantity != toUnit.quantity {
+		return "", fmt.Errorf("cannot convert %s to %s, they're units of different quantities", from, to)
+	}
+	switch {
+	case fromUnit.scale == toUnit.scale:
+		return expr, nil
+	case fromUnit.scale%toUnit.scale == 0:
+		return fmt.Sprintf("(%s * %d)", expr, fromUnit.scale/toUnit.scale), nil
+	case toUnit.scale%fromUnit.scale == 0:
+		return fmt.Sprintf("(%s / %dE0)", expr, toUnit.scale/fromUnit.scale), nil
+	default:
+		return fmt.Sprintf("(%s * %dE0 / %d)", expr, fromUnit.scale, toUnit.scale), nil
+	}
+}
+
+func knownColumnUnits() string {
+	units := make([]string, 0, len(columnUnits))
+	for unit := range columnUnits {
+		units = append(units, unit)
+	}
+	sort.Strings(units)
+	return strings.Join(units, ", ")
+}
+
+// resultsColumnHeader returns the header of a column in CSV, tabular and
+// Excel results, which is the column's name, followed by its unit in
+// parentheses if withUnits is set and the column has one, eg:
+// pod_request_cpu_core_seconds (cpu_core_seconds).
+func resultsColumnHeader(column api.ReportGenerationQueryColumn, withUnits bool) string {
+	if !withUnits || column.Unit == "" {
+		return column.Name
+	}
+	return fmt.Sprintf("%s (%s)", column.Name, column.Unit)
+}
@@ -0,0 +1,65 @@
+package operator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+func TestConvertUnit(t *testing.T) {
+	tests := map[string]struct {
+		from, to    string
+		expected    string
+		expectedErr bool
+	}{
+		"same unit":          {from: "cpu_core_seconds", to: "cpu_core_seconds", expected: "x"},
+		"to a larger unit":   {from: "cpu_core_seconds", to: "cpu_core_hours", expected: "(x / 3600E0)"},
+		"to a smaller unit":  {from: "gib_hours", to: "byte_seconds", expected: "(x * 3865470566400)"},
+		"cores":              {from: "cpu_cores", to: "millicores", expected: "(x * 1000)"},
+		"not a multiple":     {from: "gib", to: "gb", expected: "(x * 1073741824E0 / 1000000000)"},
+		"rate":               {from: "bytes_per_second", to: "bytes_per_hour", expected: "(x * 3600)"},
+		"different quantity": {from: "cpu_core_seconds", to: "byte_seconds", expectedErr: true},
+		"unknown from":       {from: "core_seconds", to: "cpu_core_hours", expectedErr: true},
+		"unknown to":         {from: "cpu_core_seconds", to: "core_hours", expectedErr: true},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			expr, err := convertUnit(test.from, test.to, "x")
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, expr)
+		})
+	}
+}
+
+func TestRenderConvertUnit(t *testing.T) {
+	qr := queryRenderer{templateInfo: &templateInfo{}}
+	rendered, err := qr.Render(`SELECT {| convertUnit "cpu_core_seconds" "cpu_core_hours" "sum(pod_request_cpu_core_seconds)" |} AS pod_request_cpu_core_hours, {| "pod_request_memory_byte_seconds" | convertUnit "byte_seconds" "gib_hours" |} AS pod_request_memory_gib_hours FROM pods`)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT (sum(pod_request_cpu_core_seconds) / 3600E0) AS pod_request_cpu_core_hours, (pod_request_memory_byte_seconds / 3865470566400E0) AS pod_request_memory_gib_hours FROM pods`, rendered)
+}
+
+func TestWriteResultsAsCSVWithUnits(t *testing.T) {
+	columns := []cbTypes.ReportGenerationQueryColumn{
+		{Name: "namespace", Type: "string"},
+		{Name: "pod_request_cpu_core_seconds", Type: "double", Unit: "cpu_core_seconds"},
+	}
+	results := []presto.Row{{"namespace": "metering", "pod_request_cpu_core_seconds": 7200.0}}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeResultsAsCSV(columns, results, &buf, defaultCSVFormat))
+	assert.Equal(t, "namespace,pod_request_cpu_core_seconds\nmetering,7200.000000\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, writeResultsAsCSV(columns, results, &buf, csvFormat{delimiter: ',', units: true, decimalSeparator: "."}))
+	assert.Equal(t, "namespace,pod_request_cpu_core_seconds (cpu_core_seconds)\nmetering,7200.000000\n", buf.String())
+}
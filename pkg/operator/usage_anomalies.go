@@ -0,0 +1,350 @@
+package operator
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/labels"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+const (
+	APIV1UsageAnomaliesEndpoint = "/api/v1/anomalies"
+
+	DefaultAnomalyDetectionWindow           = 7 * 24 * time.Hour
+	DefaultAnomalyDetectionThreshold        = 3.0
+	DefaultAnomalyDetectionMinChangePercent = 50.0
+
+	// anomalyDetectionMinBuckets is how many buckets of history a namespace
+	// must have before its usage is scored, so new namespaces aren't
+	// flagged while they ramp up.
+	anomalyDetectionMinBuckets = 6
+	// anomalyMinStdDevRatio is the smallest standard deviation used to
+	// score a namespace, as a fraction of its mean usage, so that any
+	// change to perfectly steady usage isn't infinitely anomalous.
+	anomalyMinStdDevRatio = 0.01
+
+	UsageAnomalySpike = "Spike"
+	UsageAnomalyDrop  = "Drop"
+)
+
+var (
+	usageAnomalyZScoreGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "metering",
+			Name:      "usage_anomaly_zscore",
+			Help:      "The number of standard deviations the usage of a namespace in the latest bucket is from its mean over the anomaly detection window.",
+		},
+		[]string{"reportdatasource", "namespace"},
+	)
+	usageAnomaliesGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "metering",
+			Name:      "usage_anomalies",
+			Help:      "The number of namespaces whose usage in the latest bucket was flagged as a spike or drop.",
+		},
+		[]string{"reportdatasource"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(usageAnomalyZScoreGauge)
+	prometheus.MustRegister(usageAnomaliesGauge)
+}
+
+// AnomalyDetectionConfig configures periodically flagging sudden spikes and
+// drops in the usage of each namespace recorded by Prometheus
+// ReportDataSources.
+type AnomalyDetectionConfig struct {
+	// Interval is the size of the buckets usage is summed into, and how
+	// often the latest bucket is compared against the buckets before it. If
+	// 0, anomalies aren't detected.
+	Interval time.Duration
+	// Window is how far back the buckets the latest bucket is compared
+	// against go.
+	Window time.Duration
+	// Threshold is how many standard deviations from its mean the usage of
+	// a namespace must be to be flagged.
+	Threshold float64
+	// MinChangePercent is how far from its mean, as a percentage of the
+	// mean, the usage of a namespace must be to be flagged, so small
+	// changes to steady usage aren't.
+	MinChangePercent float64
+}
+
+func (cfg AnomalyDetectionConfig) Valid() error {
+	if cfg.Interval < 0 {
+		return fmt.Errorf("the anomaly detection interval must not be negative, got %s", cfg.Interval)
+	}
+	if cfg.Interval == 0 {
+		return nil
+	}
+	if cfg.Interval < time.Minute {
+		return fmt.Errorf("the anomaly detection interval must be at least 1m, got %s", cfg.Interval)
+	}
+	if cfg.Window < anomalyDetectionMinBuckets*cfg.Interval {
+		return fmt.Errorf("the anomaly detection window must be at least %d times the interval of %s, got %s", anomalyDetectionMinBuckets, cfg.Interval, cfg.Window)
+	}
+	if cfg.Threshold <= 0 {
+		return fmt.Errorf("the anomaly detection threshold must be positive, got %v", cfg.Threshold)
+	}
+	if cfg.MinChangePercent < 0 {
+		return fmt.Errorf("the anomaly detection min change percent must not be negative, got %v", cfg.MinChangePercent)
+	}
+	return nil
+}
+
+// UsageAnomaly is a namespace whose usage in the latest bucket was
+// flagged as a spike or drop.
+type UsageAnomaly struct {
+	ReportDataSource string `json:"reportDataSource"`
+	Namespace        string `json:"namespace"`
+	// Direction is Spike if the usage is above the mean, and Drop if it's
+	// below.
+	Direction   string    `json:"direction"`
+	PeriodStart time.Time `json:"periodStart"`
+	PeriodEnd   time.Time `json:"periodEnd"`
+	// Usage is the usage of the namespace during the period, and Mean and
+	// StdDev are of its usage in the buckets before it.
+	Usage         float64 `json:"usage"`
+	Mean          float64 `json:"mean"`
+	StdDev        float64 `json:"stdDev"`
+	ZScore        float64 `json:"zScore"`
+	ChangePercent float64 `json:"changePercent"`
+}
+
+type UsageAnomaliesResponse struct {
+	Anomalies []UsageAnomaly `json:"anomalies"`
+}
+
+// usageAnomalies holds the anomalies found in the most recent check of
+// each ReportDataSource, which are returned by the anomalies endpoint.
+type usageAnomalies struct {
+	mu sync.Mutex
+	// anomalies are keyed by ReportDataSource.
+	anomalies map[string][]UsageAnomaly
+	// scored are the namespaces of each ReportDataSource with a z-score
+	// gauge.
+	scored map[string][]string
+}
+
+func newUsageAnomalies() *usageAnomalies {
+	return &usageAnomalies{
+		anomalies: make(map[string][]UsageAnomaly),
+		scored:    make(map[string][]string),
+	}
+}
+
+func (a *usageAnomalies) record(dataSourceName string, scores map[string]float64, anomalies []UsageAnomaly) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, namespace := range a.scored[dataSourceName] {
+		if _, exists := scores[namespace]; !exists {
+			usageAnomalyZScoreGauge.DeleteLabelValues(dataSourceName, namespace)
+		}
+	}
+	namespaces := make([]string, 0, len(scores))
+	for namespace, score := range scores {
+		usageAnomalyZScoreGauge.WithLabelValues(dataSourceName, namespace).Set(score)
+		namespaces = append(namespaces, namespace)
+	}
+	usageAnomaliesGauge.WithLabelValues(dataSourceName).Set(float64(len(anomalies)))
+	a.scored[dataSourceName] = namespaces
+	a.anomalies[dataSourceName] = anomalies
+}
+
+func (a *usageAnomalies) remove(dataSourceName string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, namespace := range a.scored[dataSourceName] {
+		usageAnomalyZScoreGauge.DeleteLabelValues(dataSourceName, namespace)
+	}
+	usageAnomaliesGauge.DeleteLabelValues(dataSourceName)
+	delete(a.scored, dataSourceName)
+	delete(a.anomalies, dataSourceName)
+}
+
+// list returns the anomalies of every ReportDataSource, sorted by
+// ReportDataSource and namespace.
+func (a *usageAnomalies) list() UsageAnomaliesResponse {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	resp := UsageAnomaliesResponse{Anomalies: []UsageAnomaly{}}
+	for _, anomalies := range a.anomalies {
+		resp.Anomalies = append(resp.Anomalies, anomalies...)
+	}
+	sort.Slice(resp.Anomalies, func(i, j int) bool {
+		if resp.Anomalies[i].ReportDataSource != resp.Anomalies[j].ReportDataSource {
+			return resp.Anomalies[i].ReportDataSource < resp.Anomalies[j].ReportDataSource
+		}
+		return resp.Anomalies[i].Namespace < resp.Anomalies[j].Namespace
+	})
+	return resp
+}
+
+// runUsageAnomalyDetector periodically compares the usage of each
+// namespace in the latest bucket of each Prometheus ReportDataSource with
+// its usage in the buckets before it, flagging namespaces whose usage
+// spiked or dropped. Like the data completeness checker, the latest bucket
+// ends once its data should have been imported, and each bucket is checked
+// once.
+func (op *Reporting) runUsageAnomalyDetector(stopCh <-chan struct{}) {
+	logger := op.logger.WithField("component", "usageAnomalyDetector")
+	logger.Infof("usage anomaly detector started")
+
+	cfg := op.cfg.AnomalyDetectionConfig
+	checkedUntil := make(map[string]time.Time)
+	for {
+		select {
+		case <-stopCh:
+			logger.Infof("usage anomaly detector exiting")
+			return
+		case <-op.clock.Tick(dataSourceValidationInterval):
+			dataSources, err := op.informers.Metering().V1alpha1().ReportDataSources().Lister().ReportDataSources(op.cfg.Namespace).List(labels.Everything())
+			if err != nil {
+				logger.WithError(err).Errorf("unable to list reportDataSources")
+				continue
+			}
+
+			now := op.clock.Now().UTC()
+			seen := make(map[string]struct{})
+			for _, dataSource := range dataSources {
+				if dataSource.Spec.Promsum == nil || dataSource.TableName == "" || !op.cfg.ShardingConfig.owns(dataSource.Name) {
+					continue
+				}
+				seen[dataSource.Name] = struct{}{}
+				queryInterval, _, _ := op.prometheusQueryConfig(dataSource)
+				end := now.Add(-queryInterval - dataSourceValidationDelay).Truncate(cfg.Interval)
+				if checkedUntil[dataSource.Name].Equal(end) {
+					continue
+				}
+				err := op.checkUsageAnomalies(dataSource, end.Add(-cfg.Window), end)
+				if err != nil {
+					logger.WithError(err).Errorf("unable to check the usage of reportDataSource %s for anomalies", dataSource.Name)
+					continue
+				}
+				checkedUntil[dataSource.Name] = end
+			}
+			for name := range checkedUntil {
+				if _, exists := seen[name]; !exists {
+					delete(checkedUntil, name)
+					op.usageAnomalies.remove(name)
+				}
+			}
+		}
+	}
+}
+
+func (op *Reporting) checkUsageAnomalies(dataSource *cbTypes.ReportDataSource, start, end time.Time) error {
+	cfg := op.cfg.AnomalyDetectionConfig
+	usage, err := prestostore.GetNamespaceUsage(op.prestoQueryer, dataSource.TableName, start, end, cfg.Interval)
+	if err != nil {
+		return err
+	}
+	scores, anomalies := detectUsageAnomalies(dataSource.Name, usage, start, end, cfg)
+	for _, anomaly := range anomalies {
+		op.logger.WithField("reportDataSource", dataSource.Name).Warnf("usage of namespace %s between %s and %s was %g, %.0f%% from its mean of %g (z-score %.2f)", anomaly.Namespace, anomaly.PeriodStart, anomaly.PeriodEnd, anomaly.Usage, anomaly.ChangePercent, anomaly.Mean, anomaly.ZScore)
+	}
+	op.usageAnomalies.record(dataSource.Name, scores, anomalies)
+	return nil
+}
+
+// detectUsageAnomalies scores the usage of each namespace in the latest
+// bucket before end against its usage in the buckets from start, returning
+// the z-score of each namespace with enough history, and the namespaces
+// whose z-score and change from the mean both exceed the thresholds. A
+// namespace's history starts at its first bucket with usage, and buckets
+// after that without usage count as no usage.
+func detectUsageAnomalies(dataSourceName string, usage []prestostore.NamespaceUsage, start, end time.Time, cfg AnomalyDetectionConfig) (map[string]float64, []UsageAnomaly) {
+	latestStart := end.Add(-cfg.Interval)
+	buckets := make(map[string]map[time.Time]float64)
+	for _, u := range usage {
+		if u.Bucket.Before(start) || !u.Bucket.Before(end) {
+			continue
+		}
+		if buckets[u.Namespace] == nil {
+			buckets[u.Namespace] = make(map[time.Time]float64)
+		}
+		buckets[u.Namespace][u.Bucket] += u.Usage
+	}
+
+	scores := make(map[string]float64)
+	var anomalies []UsageAnomaly
+	for namespace, nsBuckets := range buckets {
+		first := latestStart
+		for bucket := range nsBuckets {
+			if bucket.Before(first) {
+				first = bucket
+			}
+		}
+		var history []float64
+		for bucket := first; bucket.Before(latestStart); bucket = bucket.Add(cfg.Interval) {
+			history = append(history, nsBuckets[bucket])
+		}
+		if len(history) < anomalyDetectionMinBuckets {
+			continue
+		}
+
+		mean, stdDev := meanStdDev(history)
+		stdDev = math.Max(stdDev, anomalyMinStdDevRatio*mean)
+		if mean <= 0 || stdDev <= 0 {
+			continue
+		}
+		latest := nsBuckets[latestStart]
+		zScore := (latest - mean) / stdDev
+		scores[namespace] = zScore
+
+		changePercent := math.Abs(latest-mean) / mean * 100
+		if math.Abs(zScore) < cfg.Threshold || changePercent < cfg.MinChangePercent {
+			continue
+		}
+		direction := UsageAnomalySpike
+		if latest < mean {
+			direction = UsageAnomalyDrop
+		}
+		anomalies = append(anomalies, UsageAnomaly{
+			ReportDataSource: dataSourceName,
+			Namespace:        namespace,
+			Direction:        direction,
+			PeriodStart:      latestStart,
+			PeriodEnd:        end,
+			Usage:            latest,
+			Mean:             mean,
+			StdDev:           stdDev,
+			ZScore:           zScore,
+			ChangePercent:    changePercent,
+		})
+	}
+	sort.Slice(anomalies, func(i, j int) bool { return anomalies[i].Namespace < anomalies[j].Namespace })
+	return scores, anomalies
+}
+
+// meanStdDev returns the mean and population standard deviation of values.
+func meanStdDev(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+func (srv *server) getUsageAnomaliesHandler(w http.ResponseWriter, r *http.Request) {
+	logger := newRequestLogger(srv.logger, r, srv.rand)
+	if srv.usageAnomalies == nil {
+		writeErrorResponse(logger, w, r, http.StatusNotFound, "anomaly detection is not enabled")
+		return
+	}
+	writeResponseAsJSON(logger, w, http.StatusOK, srv.usageAnomalies.list())
+}
@@ -0,0 +1,109 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-metering/pkg/operator/prestostore"
+)
+
+func TestDetectUsageAnomalies(t *testing.T) {
+	cfg := AnomalyDetectionConfig{
+		Interval:         time.Hour,
+		Window:           12 * time.Hour,
+		Threshold:        3,
+		MinChangePercent: 50,
+	}
+	end := time.Date(2019, time.January, 2, 12, 0, 0, 0, time.UTC)
+	start := end.Add(-cfg.Window)
+	latest := end.Add(-cfg.Interval)
+
+	// hourly returns the usage of a namespace in each bucket from first,
+	// ending with the latest bucket.
+	hourly := func(namespace string, first time.Time, usage ...float64) []prestostore.NamespaceUsage {
+		var rows []prestostore.NamespaceUsage
+		for i, u := range usage {
+			rows = append(rows, prestostore.NamespaceUsage{Namespace: namespace, Bucket: first.Add(time.Duration(i) * time.Hour), Usage: u})
+		}
+		return rows
+	}
+
+	var usage []prestostore.NamespaceUsage
+	// spike has steady usage which spikes in the latest bucket.
+	usage = append(usage, hourly("spike", start, 100, 110, 90, 100, 105, 95, 100, 110, 90, 100, 100, 400)...)
+	// drop has no usage in the latest bucket.
+	usage = append(usage, hourly("drop", start, 100, 110, 90, 100, 105, 95, 100, 110, 90, 100, 100)...)
+	// noisy varies too much for its latest bucket to be an anomaly.
+	usage = append(usage, hourly("noisy", start, 10, 300, 20, 250, 15, 400, 30, 200, 10, 350, 20, 380)...)
+	// steady has perfectly steady usage, which changes by less than the
+	// minimum change.
+	usage = append(usage, hourly("steady", start, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 120)...)
+	// new namespaces aren't scored until they have enough history.
+	usage = append(usage, hourly("new", latest.Add(-3*time.Hour), 10, 10, 10, 1000)...)
+	// rows outside the window are ignored.
+	usage = append(usage, prestostore.NamespaceUsage{Namespace: "spike", Bucket: start.Add(-time.Hour), Usage: 1000000})
+
+	scores, anomalies := detectUsageAnomalies("pod-usage-cpu-cores", usage, start, end, cfg)
+	assert.Len(t, scores, 4)
+	assert.NotContains(t, scores, "new")
+	assert.InDelta(t, 20, scores["steady"], 0.001)
+	assert.True(t, scores["noisy"] < cfg.Threshold)
+
+	require.Len(t, anomalies, 2)
+	assert.Equal(t, "drop", anomalies[0].Namespace)
+	assert.Equal(t, UsageAnomalyDrop, anomalies[0].Direction)
+	assert.Equal(t, 0.0, anomalies[0].Usage)
+	assert.Equal(t, 100.0, anomalies[0].ChangePercent)
+	assert.Equal(t, "spike", anomalies[1].Namespace)
+	assert.Equal(t, UsageAnomalySpike, anomalies[1].Direction)
+	assert.Equal(t, "pod-usage-cpu-cores", anomalies[1].ReportDataSource)
+	assert.Equal(t, latest, anomalies[1].PeriodStart)
+	assert.Equal(t, end, anomalies[1].PeriodEnd)
+	assert.Equal(t, 400.0, anomalies[1].Usage)
+	assert.Equal(t, 100.0, anomalies[1].Mean)
+	assert.InDelta(t, 300, anomalies[1].ChangePercent, 0.001)
+	assert.True(t, anomalies[1].ZScore > cfg.Threshold)
+}
+
+func TestAnomalyDetectionConfigValid(t *testing.T) {
+	valid := AnomalyDetectionConfig{
+		Interval:         time.Hour,
+		Window:           DefaultAnomalyDetectionWindow,
+		Threshold:        DefaultAnomalyDetectionThreshold,
+		MinChangePercent: DefaultAnomalyDetectionMinChangePercent,
+	}
+	assert.NoError(t, valid.Valid())
+	assert.NoError(t, AnomalyDetectionConfig{}.Valid())
+
+	invalid := valid
+	invalid.Window = 5 * time.Hour
+	assert.Error(t, invalid.Valid())
+
+	invalid = valid
+	invalid.Threshold = 0
+	assert.Error(t, invalid.Valid())
+
+	invalid = valid
+	invalid.Interval = time.Second
+	assert.Error(t, invalid.Valid())
+}
+
+func TestUsageAnomaliesList(t *testing.T) {
+	anomalies := newUsageAnomalies()
+	assert.Equal(t, []UsageAnomaly{}, anomalies.list().Anomalies)
+
+	anomalies.record("b", map[string]float64{"team-a": 4, "team-b": 1}, []UsageAnomaly{{ReportDataSource: "b", Namespace: "team-a"}})
+	anomalies.record("a", map[string]float64{"team-c": -5}, []UsageAnomaly{{ReportDataSource: "a", Namespace: "team-c"}})
+	assert.Equal(t, []UsageAnomaly{
+		{ReportDataSource: "a", Namespace: "team-c"},
+		{ReportDataSource: "b", Namespace: "team-a"},
+	}, anomalies.list().Anomalies)
+
+	anomalies.record("b", map[string]float64{"team-a": 0}, nil)
+	assert.Equal(t, []string{"team-a"}, anomalies.scored["b"])
+	anomalies.remove("a")
+	assert.Equal(t, []UsageAnomaly{}, anomalies.list().Anomalies)
+}
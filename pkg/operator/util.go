@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math/rand"
 	"net/http"
+	"regexp"
 	"strings"
 	"time"
 
@@ -18,10 +19,30 @@ const logIdentifierLength = 10
 
 var resourceNameReplacer = strings.NewReplacer("-", "_", ".", "_")
 
+// hiveDecimalTypeRegexp matches Hive DECIMAL types, whose precision defaults
+// to 10 and scale to 0.
+var hiveDecimalTypeRegexp = regexp.MustCompile(`^(?:DECIMAL|NUMERIC)(?:\(\s*([0-9]+)\s*(?:,\s*([0-9]+)\s*)?\))?$`)
+
 func dataSourceTableName(dataSourceName string) string {
 	return fmt.Sprintf("datasource_%s", resourceNameReplacer.Replace(dataSourceName))
 }
 
+func dataSourceExemplarsTableName(dataSourceName string) string {
+	return fmt.Sprintf("datasource_%s_exemplars", resourceNameReplacer.Replace(dataSourceName))
+}
+
+// dataSourceRemoteTableName is the name of the external table reading the
+// results of a remoteReport ReportDataSource from S3.
+func dataSourceRemoteTableName(dataSourceName string) string {
+	return fmt.Sprintf("datasource_%s_remote", resourceNameReplacer.Replace(dataSourceName))
+}
+
+// dataSourceFilesTableName is the name of the external table reading the
+// files of an objectStorage ReportDataSource.
+func dataSourceFilesTableName(dataSourceName string) string {
+	return fmt.Sprintf("datasource_%s_files", resourceNameReplacer.Replace(dataSourceName))
+}
+
 func reportTableName(reportName string) string {
 	return fmt.Sprintf("report_%s", resourceNameReplacer.Replace(reportName))
 }
@@ -42,20 +63,33 @@ func billingPeriodTimestamp(date time.Time) string {
 	return date.Format(awsUsagePartitionDateStringLayout)
 }
 
+// loadTimezone returns the location for the IANA timezone name, or UTC if
+// timezone is empty.
+func loadTimezone(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q: %v", timezone, err)
+	}
+	return loc, nil
+}
+
 func truncateToMinute(t time.Time) time.Time {
 	return t.Truncate(time.Minute)
 }
 
-func generateHiveColumns(genQuery *cbTypes.ReportGenerationQuery) []hive.Column {
+func generateHiveColumns(reportColumns []cbTypes.ReportGenerationQueryColumn) []hive.Column {
 	var columns []hive.Column
-	for _, col := range genQuery.Spec.Columns {
+	for _, col := range reportColumns {
 		columns = append(columns, hive.Column{Name: col.Name, Type: col.Type})
 	}
 	return columns
 }
 
-func generatePrestoColumns(genQuery *cbTypes.ReportGenerationQuery) ([]presto.Column, error) {
-	return hiveColumnsToPrestoColumns(generateHiveColumns(genQuery))
+func generatePrestoColumns(reportColumns []cbTypes.ReportGenerationQueryColumn) ([]presto.Column, error) {
+	return hiveColumnsToPrestoColumns(generateHiveColumns(reportColumns))
 }
 
 func hiveColumnsToPrestoColumns(columns []hive.Column) ([]presto.Column, error) {
@@ -82,10 +116,20 @@ func simpleHiveColumnTypeToPrestoColumnType(colType string) string {
 		return "TIMESTAMP"
 	case "BOOLEAN":
 		return "BOOLEAN"
-	case "DECIMAL", "NUMERIC", "CHAR":
+	case "CHAR":
 		// explicitly not visible to Presto tables according to Presto docs
 		return ""
 	}
+	if match := hiveDecimalTypeRegexp.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(colType))); match != nil {
+		precision, scale := match[1], match[2]
+		if precision == "" {
+			precision = "10"
+		}
+		if scale == "" {
+			scale = "0"
+		}
+		return fmt.Sprintf("DECIMAL(%s,%s)", precision, scale)
+	}
 	return ""
 }
 
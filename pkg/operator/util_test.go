@@ -41,6 +41,30 @@ func TestHiveColumnToPrestoColumn(t *testing.T) {
 			compareAssertion: assert.Equal,
 			errAssertion:     assert.NoError,
 		},
+		"decimal(38, 12) to DECIMAL(38,12)": {
+			hiveColumn: hive.Column{
+				Name: "foo",
+				Type: "decimal(38, 12)",
+			},
+			expectedPrestoColumn: presto.Column{
+				Name: "foo",
+				Type: "DECIMAL(38,12)",
+			},
+			compareAssertion: assert.Equal,
+			errAssertion:     assert.NoError,
+		},
+		"DECIMAL to DECIMAL(10,0)": {
+			hiveColumn: hive.Column{
+				Name: "foo",
+				Type: "DECIMAL",
+			},
+			expectedPrestoColumn: presto.Column{
+				Name: "foo",
+				Type: "DECIMAL(10,0)",
+			},
+			compareAssertion: assert.Equal,
+			errAssertion:     assert.NoError,
+		},
 		"MAP<STRING,STRING> to map(VARCHAR,VARCHAR)": {
 			hiveColumn: hive.Column{
 				Name: "foo",
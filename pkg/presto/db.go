@@ -1,6 +1,9 @@
 package presto
 
 import (
+	"context"
+	"time"
+
 	"github.com/operator-framework/operator-metering/pkg/db"
 )
 
@@ -8,6 +11,13 @@ type Queryer interface {
 	Query(query string) ([]Row, error)
 }
 
+// StreamQueryer is a Queryer which can also stream the rows of a query's
+// results, rather than returning them all at once.
+type StreamQueryer interface {
+	Queryer
+	QueryStream(ctx context.Context, query string, fn func(Row) error) error
+}
+
 type Execer interface {
 	Exec(query string) error
 }
@@ -17,18 +27,65 @@ type ExecQueryer interface {
 	Execer
 }
 
+// ContextExecer is an Execer which can also execute queries that are
+// cancelled when a context is done.
+type ContextExecer interface {
+	Execer
+	ExecContext(ctx context.Context, query string) error
+}
+
 type DB struct {
 	queryer db.Queryer
+	// component is the component queries are recorded in metrics as run
+	// by, if set.
+	component string
 }
 
 func NewDB(queryer db.Queryer) *DB {
-	return &DB{queryer}
+	return &DB{queryer: queryer}
+}
+
+// NewInstrumentedDB returns a DB which records the duration and errors of
+// its queries, and the rows they write, in metrics labelled with component.
+func NewInstrumentedDB(queryer db.Queryer, component string) *DB {
+	return &DB{queryer: queryer, component: component}
 }
 
 func (db *DB) Query(query string) ([]Row, error) {
-	return ExecuteSelect(db.queryer, query)
+	start := time.Now()
+	rows, err := ExecuteSelect(db.queryer, query)
+	db.observe(queryTypeSelect, start, err)
+	return rows, err
+}
+
+// QueryStream calls fn with each row of the query's results as they're
+// received from Presto. The query is cancelled if ctx is done, or fn returns
+// an error, before it finishes.
+func (db *DB) QueryStream(ctx context.Context, query string, fn func(Row) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	start := time.Now()
+	err := StreamSelectContext(ctx, db.queryer, query, fn)
+	db.observe(queryTypeSelect, start, err)
+	return err
 }
 
 func (db *DB) Exec(query string) error {
-	return ExecuteQuery(db.queryer, query)
+	return db.ExecContext(context.Background(), query)
+}
+
+func (db *DB) ExecContext(ctx context.Context, query string) error {
+	start := time.Now()
+	written, err := executeQueryContext(ctx, db.queryer, query)
+	db.observe(queryTypeExec, start, err)
+	if db.component != "" && err == nil && isInsertQuery(query) {
+		rowsWrittenCounter.WithLabelValues(db.component).Add(float64(written))
+	}
+	return err
+}
+
+func (db *DB) observe(queryType string, start time.Time, err error) {
+	if db.component != "" {
+		observeQuery(db.component, queryType, start, err)
+	}
 }
@@ -0,0 +1,137 @@
+package presto
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+var decimalRegexp = regexp.MustCompile(`^([+-]?)([0-9]*)(?:\.([0-9]*))?(?:[eE]([+-]?[0-9]+))?$`)
+
+// Decimal is the exact value of a DECIMAL column, such as "12.340000000000".
+// Presto returns DECIMAL values as strings, which are converted to Decimals
+// rather than float64s, so they're serialized as numbers without being
+// rounded.
+type Decimal string
+
+// ParseDecimal parses a decimal number, which may use an exponent, into a
+// Decimal with the same scale, which never uses one.
+func ParseDecimal(s string) (Decimal, error) {
+	match := decimalRegexp.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil || match[2]+match[3] == "" {
+		return "", fmt.Errorf("invalid decimal %q", s)
+	}
+	unscaled, ok := new(big.Int).SetString(match[2]+match[3], 10)
+	if !ok {
+		return "", fmt.Errorf("invalid decimal %q", s)
+	}
+	if match[1] == "-" {
+		unscaled.Neg(unscaled)
+	}
+	scale := len(match[3])
+	if match[4] != "" {
+		var exponent int
+		if _, err := fmt.Sscan(match[4], &exponent); err != nil || exponent > 1000 || exponent < -1000 {
+			return "", fmt.Errorf("invalid decimal %q", s)
+		}
+		scale -= exponent
+	}
+	if scale < 0 {
+		unscaled.Mul(unscaled, pow10(-scale))
+		scale = 0
+	}
+	return NewDecimal(unscaled, scale), nil
+}
+
+// NewDecimal returns the Decimal unscaled * 10^-scale.
+func NewDecimal(unscaled *big.Int, scale int) Decimal {
+	digits := new(big.Int).Abs(unscaled).String()
+	if scale > 0 {
+		if len(digits) <= scale {
+			digits = strings.Repeat("0", scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+	}
+	if unscaled.Sign() < 0 {
+		digits = "-" + digits
+	}
+	return Decimal(digits)
+}
+
+func (d Decimal) String() string {
+	return string(d)
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int {
+	if i := strings.IndexByte(string(d), '.'); i != -1 {
+		return len(d) - i - 1
+	}
+	return 0
+}
+
+// Unscaled returns the value multiplied by 10^Scale.
+func (d Decimal) Unscaled() *big.Int {
+	unscaled, _ := new(big.Int).SetString(strings.Replace(string(d), ".", "", 1), 10)
+	return unscaled
+}
+
+// Rat returns the exact value.
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.Unscaled(), pow10(d.Scale()))
+}
+
+// Float64 returns the float64 nearest to the value.
+func (d Decimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// Rescale returns the value with scale digits after the decimal point,
+// and whether it was rescaled without losing any digits.
+func (d Decimal) Rescale(scale int) (Decimal, bool) {
+	current := d.Scale()
+	unscaled := d.Unscaled()
+	if scale >= current {
+		return NewDecimal(unscaled.Mul(unscaled, pow10(scale-current)), scale), true
+	}
+	quo, rem := new(big.Int).QuoRem(unscaled, pow10(current-scale), new(big.Int))
+	return NewDecimal(quo, scale), rem.Sign() == 0
+}
+
+// MarshalJSON marshals the Decimal as a JSON number.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d), nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// isDecimalColumnType returns true if the Presto column type is a DECIMAL.
+func isDecimalColumnType(colType string) bool {
+	return strings.HasPrefix(strings.ToUpper(colType), "DECIMAL")
+}
+
+// convertDecimals converts the values of the DECIMAL columns of the rows,
+// which Presto returns as strings, into Decimals.
+func convertDecimals(columns []Column, rows []Row) error {
+	for _, col := range columns {
+		if !isDecimalColumnType(col.Type) {
+			continue
+		}
+		for _, row := range rows {
+			s, ok := row[col.Name].(string)
+			if !ok {
+				continue
+			}
+			d, err := ParseDecimal(s)
+			if err != nil {
+				return fmt.Errorf("column %q: %v", col.Name, err)
+			}
+			row[col.Name] = d
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package presto_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	mockpresto "github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := map[string]struct {
+		value       string
+		expected    presto.Decimal
+		expectedErr bool
+	}{
+		"integer":           {value: "12", expected: "12"},
+		"keeps scale":       {value: "12.340000000000", expected: "12.340000000000"},
+		"negative":          {value: "-0.5", expected: "-0.5"},
+		"positive sign":     {value: "+.5", expected: "0.5"},
+		"exponent":          {value: "1.234E-9", expected: "0.000000001234"},
+		"positive exponent": {value: "1.5e3", expected: "1500"},
+		"empty":             {value: "", expectedErr: true},
+		"sign only":         {value: "-", expectedErr: true},
+		"not a number":      {value: "NaN", expectedErr: true},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			d, err := presto.ParseDecimal(test.value)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, d)
+		})
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	d := presto.Decimal("-1234567890123456789.012345678901")
+	assert.Equal(t, 12, d.Scale())
+	unscaled, _ := new(big.Int).SetString("-1234567890123456789012345678901", 10)
+	assert.Equal(t, unscaled, d.Unscaled())
+	assert.Equal(t, d, presto.NewDecimal(unscaled, 12))
+	assert.Equal(t, presto.Decimal("0.05"), presto.NewDecimal(big.NewInt(5), 2))
+
+	rescaled, exact := presto.Decimal("1.5").Rescale(3)
+	assert.True(t, exact)
+	assert.Equal(t, presto.Decimal("1.500"), rescaled)
+	rescaled, exact = presto.Decimal("1.25").Rescale(1)
+	assert.False(t, exact)
+	assert.Equal(t, presto.Decimal("1.2"), rescaled)
+
+	b, err := json.Marshal([]interface{}{d})
+	require.NoError(t, err)
+	assert.Equal(t, `[-1234567890123456789.012345678901]`, string(b))
+}
+
+func TestGetRowsDecimals(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	columns := []presto.Column{
+		{Name: "namespace", Type: "VARCHAR"},
+		{Name: "cost", Type: "DECIMAL(38,12)"},
+	}
+	queryer := mockpresto.NewMockQueryer(ctrl)
+	queryer.EXPECT().Query(gomock.Any()).Return([]presto.Row{
+		{"namespace": "a", "cost": "0.100000000000"},
+		{"namespace": "b", "cost": nil},
+	}, nil)
+	rows, err := presto.GetRows(queryer, "report_table", columns)
+	require.NoError(t, err)
+	assert.Equal(t, []presto.Row{
+		{"namespace": "a", "cost": presto.Decimal("0.100000000000")},
+		{"namespace": "b", "cost": nil},
+	}, rows)
+
+	query, err := presto.GenerateGetRowsPageSQL("report_table", columns[1:], "", 100, &presto.Cursor{Values: []*string{stringPtr("0.100000000000")}})
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT "cost" FROM report_table WHERE (("cost" > DECIMAL '0.100000000000' OR "cost" IS NULL)) OR ("cost" = DECIMAL '0.100000000000') ORDER BY "cost" ASC LIMIT 101`, query)
+}
@@ -0,0 +1,87 @@
+package presto
+
+import (
+	"fmt"
+	"strings"
+)
+
+// filterOperators are the comparison operators filters support. Operators
+// which are a prefix of another operator must come after it.
+var filterOperators = []string{">=", "<=", "!=", "=", ">", "<"}
+
+// Filter compares a column to a value, such as namespace=team-a.
+type Filter struct {
+	Column   string
+	Operator string
+	Value    string
+}
+
+// ParseFilter parses a filter expression in the form <column><op><value>,
+// where op is one of =, !=, >, >=, < or <=.
+func ParseFilter(s string) (Filter, error) {
+	i := 0
+	for i < len(s) && isColumnNameChar(s[i]) {
+		i++
+	}
+	if i == 0 {
+		return Filter{}, fmt.Errorf("invalid filter %q: must start with a column name", s)
+	}
+	for _, op := range filterOperators {
+		if strings.HasPrefix(s[i:], op) {
+			return Filter{Column: s[:i], Operator: op, Value: s[i+len(op):]}, nil
+		}
+	}
+	return Filter{}, fmt.Errorf("invalid filter %q: operator must be one of %s", s, strings.Join(filterOperators, ", "))
+}
+
+// GenerateFiltersSQL returns a predicate matching the rows which match every
+// filter, or an empty string if there are no filters. Each filter's column
+// must be one of the columns, and its value must be valid for the column's
+// type.
+func GenerateFiltersSQL(columns []Column, filters []Filter) (string, error) {
+	columnsByName := make(map[string]Column, len(columns))
+	for _, col := range columns {
+		columnsByName[col.Name] = col
+	}
+	var predicates []string
+	for _, filter := range filters {
+		col, ok := columnsByName[filter.Column]
+		if !ok {
+			return "", fmt.Errorf("invalid filter: unknown column %q", filter.Column)
+		}
+		if !isPaginationColumnType(col.Type) {
+			return "", fmt.Errorf("invalid filter: column %q has type %s, which can't be filtered", col.Name, col.Type)
+		}
+		value, err := literalSQL(col, &filter.Value)
+		if err != nil {
+			return "", fmt.Errorf("invalid filter: column %q: %v", col.Name, err)
+		}
+		op := filter.Operator
+		if op == "!=" {
+			op = "<>"
+		}
+		predicates = append(predicates, fmt.Sprintf("%s %s %s", quoteColumn(col), op, value))
+	}
+	return strings.Join(predicates, " AND "), nil
+}
+
+// GenerateInSQL returns a predicate matching the rows whose value of the
+// column is one of the values. If there are no values, no rows match.
+func GenerateInSQL(col Column, values []string) (string, error) {
+	if len(values) == 0 {
+		return "false", nil
+	}
+	literals := make([]string, len(values))
+	for i := range values {
+		value, err := literalSQL(col, &values[i])
+		if err != nil {
+			return "", fmt.Errorf("column %q: %v", col.Name, err)
+		}
+		literals[i] = value
+	}
+	return fmt.Sprintf("%s IN (%s)", quoteColumn(col), strings.Join(literals, ", ")), nil
+}
+
+func isColumnNameChar(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
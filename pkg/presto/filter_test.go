@@ -0,0 +1,101 @@
+package presto_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := map[string]struct {
+		expr     string
+		expected presto.Filter
+		wantErr  bool
+	}{
+		"equal": {
+			expr:     "namespace=team-a",
+			expected: presto.Filter{Column: "namespace", Operator: "=", Value: "team-a"},
+		},
+		"greater than or equal": {
+			expr:     "period_start>=2018-07-01T00:00:00Z",
+			expected: presto.Filter{Column: "period_start", Operator: ">=", Value: "2018-07-01T00:00:00Z"},
+		},
+		"value containing an operator": {
+			expr:     "label!=a=b",
+			expected: presto.Filter{Column: "label", Operator: "!=", Value: "a=b"},
+		},
+		"empty value": {
+			expr:     "namespace=",
+			expected: presto.Filter{Column: "namespace", Operator: "=", Value: ""},
+		},
+		"no column": {
+			expr:    "=team-a",
+			wantErr: true,
+		},
+		"no operator": {
+			expr:    "namespace",
+			wantErr: true,
+		},
+		"invalid column name": {
+			expr:    `"namespace"=team-a`,
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			filter, err := presto.ParseFilter(tt.expr)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, filter)
+		})
+	}
+}
+
+func TestGenerateFiltersSQL(t *testing.T) {
+	columns := []presto.Column{
+		{Name: "period_start", Type: "TIMESTAMP"},
+		{Name: "namespace", Type: "VARCHAR"},
+		{Name: "amount", Type: "DOUBLE"},
+		{Name: "labels", Type: "map(VARCHAR,VARCHAR)"},
+	}
+
+	whereSQL, err := presto.GenerateFiltersSQL(columns, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "", whereSQL)
+
+	whereSQL, err = presto.GenerateFiltersSQL(columns, []presto.Filter{
+		{Column: "namespace", Operator: "!=", Value: "it's"},
+		{Column: "period_start", Operator: ">=", Value: "2018-07-01T00:00:00Z"},
+		{Column: "amount", Operator: "<", Value: "10"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `"namespace" <> 'it''s' AND "period_start" >= timestamp '2018-07-01 00:00:00.000' AND "amount" < DOUBLE '10'`, whereSQL)
+
+	_, err = presto.GenerateFiltersSQL(columns, []presto.Filter{{Column: "pod", Operator: "=", Value: "a"}})
+	assert.Error(t, err, "unknown columns can't be filtered")
+	_, err = presto.GenerateFiltersSQL(columns, []presto.Filter{{Column: "amount", Operator: "=", Value: "1; DROP TABLE report_table"}})
+	assert.Error(t, err, "values must be valid for the column type")
+	_, err = presto.GenerateFiltersSQL(columns, []presto.Filter{{Column: "labels", Operator: "=", Value: "a"}})
+	assert.Error(t, err, "map columns can't be filtered")
+}
+
+func TestGenerateInSQL(t *testing.T) {
+	col := presto.Column{Name: "namespace", Type: "VARCHAR"}
+
+	inSQL, err := presto.GenerateInSQL(col, []string{"team-a", "it's"})
+	require.NoError(t, err)
+	assert.Equal(t, `"namespace" IN ('team-a', 'it''s')`, inSQL)
+
+	inSQL, err = presto.GenerateInSQL(col, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "false", inSQL, "expected no rows to match no values")
+
+	_, err = presto.GenerateInSQL(presto.Column{Name: "amount", Type: "DOUBLE"}, []string{"a"})
+	assert.Error(t, err, "values must be valid for the column type")
+}
@@ -0,0 +1,63 @@
+package presto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	queryTypeSelect = "select"
+	queryTypeExec   = "exec"
+)
+
+var (
+	queryDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "metering",
+			Name:      "presto_query_duration_seconds",
+			Help:      "How long Presto queries take, including reading their results, by the component which ran them and whether they're selects or statements such as inserts.",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 4, 9),
+		},
+		[]string{"component", "type"},
+	)
+	queryErrorsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "metering",
+			Name:      "presto_query_errors_total",
+			Help:      "The number of Presto queries which failed, by the component which ran them and whether they're selects or statements such as inserts.",
+		},
+		[]string{"component", "type"},
+	)
+	rowsWrittenCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "metering",
+			Name:      "presto_rows_written_total",
+			Help:      "The number of rows inserted into tables by Presto queries, by the component which ran them.",
+		},
+		[]string{"component"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(queryDurationHistogram)
+	prometheus.MustRegister(queryErrorsCounter)
+	prometheus.MustRegister(rowsWrittenCounter)
+}
+
+// observeQuery records the duration and result of a query run by component
+// which started at start.
+func observeQuery(component, queryType string, start time.Time, err error) {
+	queryDurationHistogram.WithLabelValues(component, queryType).Observe(time.Since(start).Seconds())
+	if err != nil {
+		queryErrorsCounter.WithLabelValues(component, queryType).Inc()
+	}
+}
+
+// isInsertQuery returns true if the query is an INSERT, which returns the
+// number of rows written.
+func isInsertQuery(query string) bool {
+	fields := strings.Fields(query)
+	return len(fields) != 0 && strings.EqualFold(fields[0], "INSERT")
+}
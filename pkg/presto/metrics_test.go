@@ -0,0 +1,20 @@
+package presto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsInsertQuery(t *testing.T) {
+	tests := map[string]bool{
+		"INSERT INTO report_a SELECT * FROM datasource_b": true,
+		"\n  insert into report_a VALUES (1)":             true,
+		"DELETE FROM report_a":                            false,
+		"SELECT 'INSERT'":                                 false,
+		"":                                                false,
+	}
+	for query, expected := range tests {
+		assert.Equal(t, expected, isInsertQuery(query), query)
+	}
+}
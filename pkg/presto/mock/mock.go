@@ -5,6 +5,7 @@
 package mockpresto
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	presto "github.com/operator-framework/operator-metering/pkg/presto"
 	reflect "reflect"
@@ -46,6 +47,54 @@ func (mr *MockQueryerMockRecorder) Query(query interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockQueryer)(nil).Query), query)
 }
 
+// MockStreamQueryer is a mock of StreamQueryer interface
+type MockStreamQueryer struct {
+	ctrl     *gomock.Controller
+	recorder *MockStreamQueryerMockRecorder
+}
+
+// MockStreamQueryerMockRecorder is the mock recorder for MockStreamQueryer
+type MockStreamQueryerMockRecorder struct {
+	mock *MockStreamQueryer
+}
+
+// NewMockStreamQueryer creates a new mock instance
+func NewMockStreamQueryer(ctrl *gomock.Controller) *MockStreamQueryer {
+	mock := &MockStreamQueryer{ctrl: ctrl}
+	mock.recorder = &MockStreamQueryerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockStreamQueryer) EXPECT() *MockStreamQueryerMockRecorder {
+	return m.recorder
+}
+
+// Query mocks base method
+func (m *MockStreamQueryer) Query(query string) ([]presto.Row, error) {
+	ret := m.ctrl.Call(m, "Query", query)
+	ret0, _ := ret[0].([]presto.Row)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query
+func (mr *MockStreamQueryerMockRecorder) Query(query interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockStreamQueryer)(nil).Query), query)
+}
+
+// QueryStream mocks base method
+func (m *MockStreamQueryer) QueryStream(ctx context.Context, query string, fn func(presto.Row) error) error {
+	ret := m.ctrl.Call(m, "QueryStream", ctx, query, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// QueryStream indicates an expected call of QueryStream
+func (mr *MockStreamQueryerMockRecorder) QueryStream(ctx, query, fn interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryStream", reflect.TypeOf((*MockStreamQueryer)(nil).QueryStream), ctx, query, fn)
+}
+
 // MockExecer is a mock of Execer interface
 type MockExecer struct {
 	ctrl     *gomock.Controller
@@ -128,3 +177,50 @@ func (m *MockExecQueryer) Exec(query string) error {
 func (mr *MockExecQueryerMockRecorder) Exec(query interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exec", reflect.TypeOf((*MockExecQueryer)(nil).Exec), query)
 }
+
+// MockContextExecer is a mock of ContextExecer interface
+type MockContextExecer struct {
+	ctrl     *gomock.Controller
+	recorder *MockContextExecerMockRecorder
+}
+
+// MockContextExecerMockRecorder is the mock recorder for MockContextExecer
+type MockContextExecerMockRecorder struct {
+	mock *MockContextExecer
+}
+
+// NewMockContextExecer creates a new mock instance
+func NewMockContextExecer(ctrl *gomock.Controller) *MockContextExecer {
+	mock := &MockContextExecer{ctrl: ctrl}
+	mock.recorder = &MockContextExecerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockContextExecer) EXPECT() *MockContextExecerMockRecorder {
+	return m.recorder
+}
+
+// Exec mocks base method
+func (m *MockContextExecer) Exec(query string) error {
+	ret := m.ctrl.Call(m, "Exec", query)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Exec indicates an expected call of Exec
+func (mr *MockContextExecerMockRecorder) Exec(query interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exec", reflect.TypeOf((*MockContextExecer)(nil).Exec), query)
+}
+
+// ExecContext mocks base method
+func (m *MockContextExecer) ExecContext(ctx context.Context, query string) error {
+	ret := m.ctrl.Call(m, "ExecContext", ctx, query)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExecContext indicates an expected call of ExecContext
+func (mr *MockContextExecerMockRecorder) ExecContext(ctx, query interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecContext", reflect.TypeOf((*MockContextExecer)(nil).ExecContext), ctx, query)
+}
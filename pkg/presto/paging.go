@@ -0,0 +1,280 @@
+package presto
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cursor is the position in a table's rows, ordered by every column, after
+// which the next page of rows starts.
+type Cursor struct {
+	// Values are the values of the last row of the previous page, or nil
+	// for NULL.
+	Values []*string `json:"values"`
+	// Skip is the number of rows equal to the last row of the previous page
+	// which were already returned, since rows aren't necessarily unique.
+	Skip int `json:"skip,omitempty"`
+}
+
+// EncodeCursor encodes the cursor as an opaque, URL safe string.
+func EncodeCursor(cursor *Cursor) (string, error) {
+	b, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecodeCursor decodes a cursor encoded by EncodeCursor, checking it has a
+// valid value for each of the columns.
+func DecodeCursor(s string, columns []Column) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %v", err)
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(b, &cursor); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %v", err)
+	}
+	if len(cursor.Values) != len(columns) {
+		return nil, fmt.Errorf("invalid cursor: got %d values, expected %d", len(cursor.Values), len(columns))
+	}
+	if cursor.Skip < 0 {
+		return nil, fmt.Errorf("invalid cursor: skip must not be negative")
+	}
+	for i, col := range columns {
+		if _, err := literalSQL(col, cursor.Values[i]); err != nil {
+			return nil, fmt.Errorf("invalid cursor: column %q: %v", col.Name, err)
+		}
+	}
+	return &cursor, nil
+}
+
+// CheckPaginationSupported returns an error if the rows of a table with the
+// columns can't be paginated. Only tables with columns of simple types can
+// be, since the cursor is compared to each column.
+func CheckPaginationSupported(columns []Column) error {
+	for _, col := range columns {
+		if !isPaginationColumnType(col.Type) {
+			return fmt.Errorf("column %q has type %s, which doesn't support pagination", col.Name, col.Type)
+		}
+	}
+	return nil
+}
+
+// GetRowsPage returns at most limit rows of the table matching the whereSQL
+// predicate, or any row if it's empty, in the same order as GetRows,
+// starting after the cursor, or from the first row if cursor is nil. If
+// there are more rows, it also returns the cursor for the next page.
+func GetRowsPage(queryer Queryer, tableName string, columns []Column, whereSQL string, limit int, cursor *Cursor) ([]Row, *Cursor, error) {
+	query, err := GenerateGetRowsPageSQL(tableName, columns, whereSQL, limit, cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := queryer.Query(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := convertDecimals(columns, rows); err != nil {
+		return nil, nil, err
+	}
+
+	skip := 0
+	if cursor != nil {
+		skip = cursor.Skip
+	}
+	if len(rows) <= skip {
+		return nil, nil, nil
+	}
+	rows = rows[skip:]
+	if len(rows) <= limit {
+		return rows, nil, nil
+	}
+	rows = rows[:limit]
+
+	last, err := cursorValues(columns, rows[len(rows)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	next := &Cursor{Values: last}
+	for i := len(rows) - 1; i >= 0; i-- {
+		values, err := cursorValues(columns, rows[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		if !cursorValuesEqual(values, last) {
+			break
+		}
+		next.Skip++
+	}
+	// if every row of the page is equal to the cursor's row, the rows
+	// skipped for this page must be skipped for the next one too
+	if next.Skip == len(rows) && cursor != nil && cursorValuesEqual(cursor.Values, last) {
+		next.Skip += skip
+	}
+	return rows, next, nil
+}
+
+// GenerateGetRowsPageSQL returns the query used by GetRowsPage. The query
+// selects the rows greater than or equal to the cursor's row, using the
+// same ordering as GenerateGetRowsSQL, where NULLs are ordered last, and
+// fetches an extra row to find out if there's another page.
+func GenerateGetRowsPageSQL(tableName string, columns []Column, whereSQL string, limit int, cursor *Cursor) (string, error) {
+	if limit <= 0 {
+		return "", fmt.Errorf("limit must be positive, got %d", limit)
+	}
+	if err := CheckPaginationSupported(columns); err != nil {
+		return "", err
+	}
+	fetch := limit + 1
+	if cursor != nil {
+		cursorWhereSQL, err := generateCursorWhereSQL(columns, cursor)
+		if err != nil {
+			return "", err
+		}
+		if whereSQL == "" {
+			whereSQL = cursorWhereSQL
+		} else {
+			whereSQL = fmt.Sprintf("(%s) AND (%s)", whereSQL, cursorWhereSQL)
+		}
+		fetch += cursor.Skip
+	}
+	return fmt.Sprintf("%s LIMIT %d", GenerateGetRowsWhereSQL(tableName, columns, whereSQL), fetch), nil
+}
+
+// generateCursorWhereSQL returns a predicate matching the rows which are
+// ordered after or equal to the cursor's row:
+// (a > x) OR (a = x AND b > y) OR ... OR (a = x AND b = y AND ...).
+func generateCursorWhereSQL(columns []Column, cursor *Cursor) (string, error) {
+	if len(cursor.Values) != len(columns) {
+		return "", fmt.Errorf("cursor has %d values, expected %d", len(cursor.Values), len(columns))
+	}
+	var (
+		equal []string
+		terms []string
+	)
+	for i, col := range columns {
+		value, err := literalSQL(col, cursor.Values[i])
+		if err != nil {
+			return "", fmt.Errorf("column %q: %v", col.Name, err)
+		}
+		name := quoteColumn(col)
+		if cursor.Values[i] == nil {
+			// NULLs are ordered last, so nothing is greater than a NULL
+			equal = append(equal, fmt.Sprintf("%s IS NULL", name))
+			continue
+		}
+		greater := fmt.Sprintf("(%s > %s OR %s IS NULL)", name, value, name)
+		terms = append(terms, "("+strings.Join(append(equal[:len(equal):len(equal)], greater), " AND ")+")")
+		equal = append(equal, fmt.Sprintf("%s = %s", name, value))
+	}
+	terms = append(terms, "("+strings.Join(equal, " AND ")+")")
+	return strings.Join(terms, " OR "), nil
+}
+
+func isPaginationColumnType(colType string) bool {
+	switch strings.ToUpper(colType) {
+	case "VARCHAR", "BIGINT", "DOUBLE", "BOOLEAN", "TIMESTAMP":
+		return true
+	}
+	return isDecimalColumnType(colType)
+}
+
+// cursorValues converts a row's values, as returned by a Queryer, into the
+// values of a cursor.
+func cursorValues(columns []Column, row Row) ([]*string, error) {
+	values := make([]*string, len(columns))
+	for i, col := range columns {
+		var s string
+		switch v := row[col.Name].(type) {
+		case nil:
+			continue
+		case string:
+			s = v
+		case Decimal:
+			s = v.String()
+		case int64:
+			s = strconv.FormatInt(v, 10)
+		case float64:
+			s = strconv.FormatFloat(v, 'g', -1, 64)
+		case bool:
+			s = strconv.FormatBool(v)
+		case time.Time:
+			s = v.UTC().Format(time.RFC3339Nano)
+		default:
+			return nil, fmt.Errorf("column %q: unsupported value type %T", col.Name, v)
+		}
+		values[i] = &s
+	}
+	return values, nil
+}
+
+func cursorValuesEqual(a, b []*string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if (a[i] == nil) != (b[i] == nil) || (a[i] != nil && *a[i] != *b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// literalSQL converts a value of a cursor or filter into a SQL literal of
+// the column's type, validating it, since they're provided by clients.
+func literalSQL(col Column, value *string) (string, error) {
+	if value == nil {
+		return "NULL", nil
+	}
+	s := *value
+	switch strings.ToUpper(col.Type) {
+	case "VARCHAR":
+		return "'" + strings.Replace(s, "'", "''", -1) + "'", nil
+	case "BIGINT":
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(n, 10), nil
+	case "DOUBLE":
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case math.IsNaN(f):
+			return "nan()", nil
+		case math.IsInf(f, 1):
+			return "infinity()", nil
+		case math.IsInf(f, -1):
+			return "-infinity()", nil
+		}
+		return "DOUBLE '" + strconv.FormatFloat(f, 'g', -1, 64) + "'", nil
+	case "BOOLEAN":
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatBool(b), nil
+	case "TIMESTAMP":
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return "", err
+		}
+		return "timestamp '" + Timestamp(t.UTC()) + "'", nil
+	}
+	if isDecimalColumnType(col.Type) {
+		d, err := ParseDecimal(s)
+		if err != nil {
+			return "", err
+		}
+		return "DECIMAL '" + d.String() + "'", nil
+	}
+	return "", fmt.Errorf("unsupported column type %s", col.Type)
+}
@@ -0,0 +1,101 @@
+package presto_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	mockpresto "github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestGenerateGetRowsPageSQL(t *testing.T) {
+	columns := []presto.Column{
+		{Name: "period_start", Type: "TIMESTAMP"},
+		{Name: "namespace", Type: "VARCHAR"},
+		{Name: "amount", Type: "DOUBLE"},
+	}
+
+	query, err := presto.GenerateGetRowsPageSQL("report_table", columns, "", 100, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT "period_start","namespace","amount" FROM report_table ORDER BY "period_start", "namespace", "amount" ASC LIMIT 101`, query)
+
+	cursor := &presto.Cursor{
+		Values: []*string{stringPtr("2018-07-01T00:00:00Z"), nil, stringPtr("1.5")},
+		Skip:   2,
+	}
+	query, err = presto.GenerateGetRowsPageSQL("report_table", columns, "", 100, cursor)
+	require.NoError(t, err)
+	expected := `SELECT "period_start","namespace","amount" FROM report_table WHERE ` +
+		`(("period_start" > timestamp '2018-07-01 00:00:00.000' OR "period_start" IS NULL)) OR ` +
+		`("period_start" = timestamp '2018-07-01 00:00:00.000' AND "namespace" IS NULL AND ("amount" > DOUBLE '1.5' OR "amount" IS NULL)) OR ` +
+		`("period_start" = timestamp '2018-07-01 00:00:00.000' AND "namespace" IS NULL AND "amount" = DOUBLE '1.5') ` +
+		`ORDER BY "period_start", "namespace", "amount" ASC LIMIT 103`
+	assert.Equal(t, expected, query)
+
+	_, err = presto.GenerateGetRowsPageSQL("report_table", []presto.Column{{Name: "labels", Type: "map(VARCHAR,VARCHAR)"}}, "", 100, nil)
+	assert.Error(t, err, "map columns can't be paginated")
+}
+
+func TestGetRowsPage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	columns := []presto.Column{
+		{Name: "namespace", Type: "VARCHAR"},
+		{Name: "amount", Type: "BIGINT"},
+	}
+	a := presto.Row{"namespace": "a", "amount": int64(1)}
+	b := presto.Row{"namespace": "b", "amount": nil}
+
+	queryer := mockpresto.NewMockQueryer(ctrl)
+	queryer.EXPECT().Query(gomock.Any()).Return([]presto.Row{a, a}, nil)
+	rows, next, err := presto.GetRowsPage(queryer, "report_table", columns, "", 1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []presto.Row{a}, rows)
+	assert.Equal(t, &presto.Cursor{Values: []*string{stringPtr("a"), stringPtr("1")}, Skip: 1}, next)
+
+	// the rows equal to the cursor's row which were already returned are
+	// skipped, including ones returned before the previous page
+	queryer.EXPECT().Query(gomock.Any()).Return([]presto.Row{a, a, a}, nil)
+	rows, next, err = presto.GetRowsPage(queryer, "report_table", columns, "", 1, next)
+	require.NoError(t, err)
+	assert.Equal(t, []presto.Row{a}, rows)
+	assert.Equal(t, &presto.Cursor{Values: []*string{stringPtr("a"), stringPtr("1")}, Skip: 2}, next)
+
+	queryer.EXPECT().Query(gomock.Any()).Return([]presto.Row{a, a, a, b}, nil)
+	rows, next, err = presto.GetRowsPage(queryer, "report_table", columns, "", 2, next)
+	require.NoError(t, err)
+	assert.Equal(t, []presto.Row{a, b}, rows)
+	assert.Nil(t, next, "expected no more pages")
+}
+
+func TestDecodeCursor(t *testing.T) {
+	columns := []presto.Column{
+		{Name: "timestamp", Type: "TIMESTAMP"},
+		{Name: "amount", Type: "BIGINT"},
+	}
+	cursor := &presto.Cursor{Values: []*string{stringPtr(time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339Nano)), nil}, Skip: 3}
+	encoded, err := presto.EncodeCursor(cursor)
+	require.NoError(t, err)
+	decoded, err := presto.DecodeCursor(encoded, columns)
+	require.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+
+	// cursors with values which aren't valid for the column type are
+	// rejected
+	invalid, err := presto.EncodeCursor(&presto.Cursor{Values: []*string{nil, stringPtr("1; DROP TABLE report_table")}})
+	require.NoError(t, err)
+	_, err = presto.DecodeCursor(invalid, columns)
+	assert.Error(t, err)
+
+	_, err = presto.DecodeCursor("not a cursor", columns)
+	assert.Error(t, err)
+}
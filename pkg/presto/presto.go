@@ -1,6 +1,8 @@
 package presto
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 
 	_ "github.com/prestodb/presto-go-client/presto"
@@ -23,37 +25,87 @@ type TablePartition struct {
 }
 
 func ExecuteQuery(queryer db.Queryer, query string) error {
-	rows, err := queryer.Query(query)
+	return ExecuteQueryContext(context.Background(), queryer, query)
+}
+
+// ExecuteQueryContext is like ExecuteQuery, but if the queryer is a
+// db.ContextQueryer, the query is cancelled in Presto if ctx is done before
+// the query finishes.
+func ExecuteQueryContext(ctx context.Context, queryer db.Queryer, query string) error {
+	_, err := executeQueryContext(ctx, queryer, query)
+	return err
+}
+
+// executeQueryContext executes the query, returning the number of rows it
+// reports writing, which Presto returns for INSERT and DELETE queries.
+func executeQueryContext(ctx context.Context, queryer db.Queryer, query string) (int64, error) {
+	var rows *sql.Rows
+	var err error
+	if contextQueryer, ok := queryer.(db.ContextQueryer); ok {
+		rows, err = contextQueryer.QueryContext(ctx, query)
+	} else {
+		rows, err = queryer.Query(query)
+	}
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer rows.Close()
 	// Must call rows.Next() in order for errors to be populated correctly
 	// because Query() only submits the query, and doesn't handle
 	// success/failure. Next() is the method which inspects the submitted
 	// queries status and causes errors to get stored in the sql.Rows object.
+	var written int64
 	for rows.Next() {
+		cols, err := rows.Columns()
+		if err == nil && len(cols) == 1 && cols[0] == "rows" {
+			var n int64
+			if err := rows.Scan(&n); err == nil {
+				written += n
+			}
+		}
 	}
 	if err := rows.Err(); err != nil {
-		return fmt.Errorf("presto SQL error: %v", err)
+		return 0, fmt.Errorf("presto SQL error: %v", err)
 	}
-	return nil
+	return written, nil
 }
 
 // ExecuteSelectQuery performs the query on the table target. It's expected
 // target has the correct schema.
 func ExecuteSelect(queryer db.Queryer, query string) ([]Row, error) {
-	rows, err := queryer.Query(query)
+	var results []Row
+	err := StreamSelectContext(context.Background(), queryer, query, func(row Row) error {
+		results = append(results, row)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return results, nil
+}
+
+// StreamSelectContext performs the query and calls fn with each row of the
+// results as they're received, without buffering them. If fn returns an
+// error, no more rows are read and the error is returned. If the queryer is
+// a db.ContextQueryer, the query is cancelled if ctx is done before it
+// finishes.
+func StreamSelectContext(ctx context.Context, queryer db.Queryer, query string, fn func(Row) error) error {
+	var rows *sql.Rows
+	var err error
+	if contextQueryer, ok := queryer.(db.ContextQueryer); ok {
+		rows, err = contextQueryer.QueryContext(ctx, query)
+	} else {
+		rows, err = queryer.Query(query)
+	}
+	if err != nil {
+		return err
+	}
 	defer rows.Close()
 	cols, err := rows.Columns()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var results []Row
 	for rows.Next() {
 		// Create a slice of interface{}'s to represent each column,
 		// and a second slice to contain pointers to each item in the columns slice.
@@ -65,7 +117,7 @@ func ExecuteSelect(queryer db.Queryer, query string) ([]Row, error) {
 
 		// Scan the result into the column pointers...
 		if err := rows.Scan(columnPointers...); err != nil {
-			return nil, err
+			return err
 		}
 
 		// Create our map, and retrieve the value for each column from the pointers slice,
@@ -75,11 +127,9 @@ func ExecuteSelect(queryer db.Queryer, query string) ([]Row, error) {
 			val := columnPointers[i].(*interface{})
 			m[colName] = *val
 		}
-		results = append(results, Row(m))
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+		if err := fn(Row(m)); err != nil {
+			return err
+		}
 	}
-
-	return results, nil
+	return rows.Err()
 }
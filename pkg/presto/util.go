@@ -1,6 +1,7 @@
 package presto
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -15,18 +16,135 @@ func DeleteFrom(execer Execer, tableName string) error {
 	return execer.Exec(fmt.Sprintf("DELETE FROM %s", tableName))
 }
 
+// DeleteFromContext is like DeleteFrom, but the query is cancelled if ctx is
+// done before it finishes and execer is a ContextExecer.
+func DeleteFromContext(ctx context.Context, execer Execer, tableName string) error {
+	return execContext(ctx, execer, fmt.Sprintf("DELETE FROM %s", tableName))
+}
+
 func InsertInto(execer Execer, tableName, query string) error {
 	return execer.Exec(FormatInsertQuery(tableName, query))
 }
 
+// InsertIntoContext is like InsertInto, but the query is cancelled if ctx is
+// done before it finishes and execer is a ContextExecer.
+func InsertIntoContext(ctx context.Context, execer Execer, tableName, query string) error {
+	return execContext(ctx, execer, FormatInsertQuery(tableName, query))
+}
+
+// InsertIntoColumnsContext is like InsertIntoContext, but inserts the
+// query's columns into the named columns of the table.
+func InsertIntoColumnsContext(ctx context.Context, execer Execer, tableName string, columns []string, query string) error {
+	return execContext(ctx, execer, FormatInsertColumnsQuery(tableName, columns, query))
+}
+
+func execContext(ctx context.Context, execer Execer, query string) error {
+	if contextExecer, ok := execer.(ContextExecer); ok {
+		return contextExecer.ExecContext(ctx, query)
+	}
+	return execer.Exec(query)
+}
+
+const (
+	// TableTypeTable is the type GetTableType returns for tables.
+	TableTypeTable = "BASE TABLE"
+	// TableTypeView is the type GetTableType returns for views.
+	TableTypeView = "VIEW"
+)
+
+// GetTableType returns whether tableName is a table, TableTypeTable, or a
+// view, TableTypeView, or an empty string if it doesn't exist. Names which
+// aren't qualified with a schema are looked up in the default schema.
+func GetTableType(queryer Queryer, tableName string) (string, error) {
+	schema, name := "default", tableName
+	if i := strings.LastIndex(tableName, "."); i != -1 {
+		schema, name = tableName[:i], tableName[i+1:]
+	}
+	rows, err := queryer.Query(fmt.Sprintf("SELECT table_type FROM information_schema.tables WHERE table_schema = '%s' AND table_name = '%s'", strings.ToLower(schema), strings.ToLower(name)))
+	if err != nil {
+		return "", err
+	}
+	if len(rows) == 0 {
+		return "", nil
+	}
+	tableType, _ := rows[0]["table_type"].(string)
+	return tableType, nil
+}
+
+func CreateOrReplaceView(execer Execer, viewName, query string) error {
+	return execer.Exec(fmt.Sprintf("CREATE OR REPLACE VIEW %s AS %s", viewName, query))
+}
+
+func DropView(execer Execer, viewName string, ignoreNotExists bool) error {
+	ifExists := ""
+	if ignoreNotExists {
+		ifExists = "IF EXISTS"
+	}
+	return execer.Exec(fmt.Sprintf("DROP VIEW %s %s", ifExists, viewName))
+}
+
+// GetRows returns every row of the table. The values of DECIMAL columns
+// are returned as Decimals.
 func GetRows(queryer Queryer, tableName string, columns []Column) ([]Row, error) {
-	return queryer.Query(GenerateGetRowsSQL(tableName, columns))
+	return GetRowsWhere(queryer, tableName, columns, "")
+}
+
+// GetRowsWhere is like GetRows, but only returns the rows matching the
+// whereSQL predicate, or every row if whereSQL is empty.
+func GetRowsWhere(queryer Queryer, tableName string, columns []Column, whereSQL string) ([]Row, error) {
+	rows, err := queryer.Query(GenerateGetRowsWhereSQL(tableName, columns, whereSQL))
+	if err != nil {
+		return nil, err
+	}
+	if err := convertDecimals(columns, rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// StreamRows calls fn with each row of the table matching the whereSQL
+// predicate, or every row if it's empty, in the same order as GetRows. If
+// queryer is a StreamQueryer the rows are streamed from Presto rather than
+// all being fetched first.
+func StreamRows(ctx context.Context, queryer Queryer, tableName string, columns []Column, whereSQL string, fn func(Row) error) error {
+	return StreamQuery(ctx, queryer, GenerateGetRowsWhereSQL(tableName, columns, whereSQL), func(row Row) error {
+		if err := convertDecimals(columns, []Row{row}); err != nil {
+			return err
+		}
+		return fn(row)
+	})
+}
+
+// StreamQuery calls fn with each row of the results of query. If queryer is
+// a StreamQueryer the rows are streamed from Presto rather than all being
+// fetched first.
+func StreamQuery(ctx context.Context, queryer Queryer, query string, fn func(Row) error) error {
+	if streamQueryer, ok := queryer.(StreamQueryer); ok {
+		return streamQueryer.QueryStream(ctx, query, fn)
+	}
+	rows, err := queryer.Query(query)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func GenerateGetRowsSQL(tableName string, columns []Column) string {
+	return GenerateGetRowsWhereSQL(tableName, columns, "")
+}
+
+func GenerateGetRowsWhereSQL(tableName string, columns []Column, whereSQL string) string {
 	columnsSQL := GenerateQuotedColumnsListSQL(columns)
 	orderBySQL := GenerateOrderBySQL(columns)
-	return fmt.Sprintf("SELECT %s FROM %s ORDER BY %s", columnsSQL, tableName, orderBySQL)
+	if whereSQL == "" {
+		return fmt.Sprintf("SELECT %s FROM %s ORDER BY %s", columnsSQL, tableName, orderBySQL)
+	}
+	return fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY %s", columnsSQL, tableName, whereSQL, orderBySQL)
 }
 
 func GenerateQuotedColumnsListSQL(columns []Column) string {
@@ -60,6 +178,17 @@ func FormatInsertQuery(target, query string) string {
 	return fmt.Sprintf("INSERT INTO %s %s", target, query)
 }
 
+// FormatInsertColumnsQuery is like FormatInsertQuery, but the query's
+// columns are inserted into the named columns of target, rather than by
+// position, and target's other columns are NULL.
+func FormatInsertColumnsQuery(target string, columns []string, query string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quoteColumn(Column{Name: column})
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) %s", target, strings.Join(quoted, ", "), query)
+}
+
 func Timestamp(date time.Time) string {
 	return date.Format(TimestampFormat)
 }
@@ -0,0 +1,73 @@
+package presto_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	mockpresto "github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+func TestInsertIntoContext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	query := presto.FormatInsertQuery("report_table", "SELECT 1")
+
+	// execers which support contexts are passed the context, so the query
+	// can be cancelled
+	contextExecer := mockpresto.NewMockContextExecer(ctrl)
+	contextExecer.EXPECT().ExecContext(ctx, query).Return(nil)
+	assert.NoError(t, presto.InsertIntoContext(ctx, contextExecer, "report_table", "SELECT 1"))
+
+	// other execers fall back to Exec
+	execer := mockpresto.NewMockExecQueryer(ctrl)
+	execer.EXPECT().Exec(query).Return(nil)
+	assert.NoError(t, presto.InsertIntoContext(ctx, execer, "report_table", "SELECT 1"))
+}
+
+func TestGetTableType(t *testing.T) {
+	tests := map[string]struct {
+		tableName     string
+		expectedQuery string
+		rows          []presto.Row
+		expected      string
+	}{
+		"table in the default schema": {
+			tableName:     "report_cpu",
+			expectedQuery: "SELECT table_type FROM information_schema.tables WHERE table_schema = 'default' AND table_name = 'report_cpu'",
+			rows:          []presto.Row{{"table_type": "BASE TABLE"}},
+			expected:      presto.TableTypeTable,
+		},
+		"view in a tenant schema": {
+			tableName:     "tenant_team_a.report_cpu",
+			expectedQuery: "SELECT table_type FROM information_schema.tables WHERE table_schema = 'tenant_team_a' AND table_name = 'report_cpu'",
+			rows:          []presto.Row{{"table_type": "VIEW"}},
+			expected:      presto.TableTypeView,
+		},
+		"doesn't exist": {
+			tableName:     "report_cpu",
+			expectedQuery: "SELECT table_type FROM information_schema.tables WHERE table_schema = 'default' AND table_name = 'report_cpu'",
+			expected:      "",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			queryer := mockpresto.NewMockExecQueryer(ctrl)
+			queryer.EXPECT().Query(test.expectedQuery).Return(test.rows, nil)
+
+			tableType, err := presto.GetTableType(queryer, test.tableName)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, tableType)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package promquery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	promapi "github.com/prometheus/client_golang/api"
+)
+
+// API provides access to the parts of the Prometheus HTTP API which aren't
+// exposed by the vendored Prometheus API client.
+type API interface {
+	TargetsAPI
+	ExemplarsAPI
+}
+
+// NewAPI returns an API for the client.
+func NewAPI(client promapi.Client) API {
+	return &httpAPI{client: client}
+}
+
+type httpAPI struct {
+	client promapi.Client
+}
+
+type apiResponse struct {
+	Status    string          `json:"status"`
+	Data      json.RawMessage `json:"data"`
+	ErrorType string          `json:"errorType"`
+	Error     string          `json:"error"`
+}
+
+// do performs the request and decodes the data field of the response into
+// data.
+func (api *httpAPI) do(ctx context.Context, req *http.Request, data interface{}) error {
+	resp, body, err := api.client.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	var result apiResponse
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		if resp.StatusCode/100 != 2 {
+			return fmt.Errorf("bad response code %d from %s", resp.StatusCode, req.URL.Path)
+		}
+		return fmt.Errorf("unable to decode response from %s: %v", req.URL.Path, err)
+	}
+	if result.Status != "success" {
+		return fmt.Errorf("error response from %s: %s: %s", req.URL.Path, result.ErrorType, result.Error)
+	}
+	err = json.Unmarshal(result.Data, data)
+	if err != nil {
+		return fmt.Errorf("unable to decode response from %s: %v", req.URL.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package promquery
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+const epQueryExemplars = "/api/v1/query_exemplars"
+
+// ExemplarQueryResult contains the exemplars for a single series.
+type ExemplarQueryResult struct {
+	SeriesLabels map[string]string `json:"seriesLabels"`
+	Exemplars    []Exemplar        `json:"exemplars"`
+}
+
+// Exemplar is a sample recorded alongside a series, usually containing a
+// trace ID in its labels.
+type Exemplar struct {
+	Labels    map[string]string `json:"labels"`
+	Value     model.SampleValue `json:"value"`
+	Timestamp model.Time        `json:"timestamp"`
+}
+
+// ExemplarsAPI provides access to the Prometheus exemplars API, which is only
+// available in Prometheus 2.26 and newer when exemplar storage is enabled.
+type ExemplarsAPI interface {
+	// QueryExemplars returns the exemplars for the series selected by
+	// query between start and end.
+	QueryExemplars(ctx context.Context, query string, start, end time.Time) ([]ExemplarQueryResult, error)
+}
+
+func (api *httpAPI) QueryExemplars(ctx context.Context, query string, start, end time.Time) ([]ExemplarQueryResult, error) {
+	u := api.client.URL(epQueryExemplars, nil)
+	q := u.Query()
+	q.Set("query", query)
+	q.Set("start", start.Format(time.RFC3339Nano))
+	q.Set("end", end.Format(time.RFC3339Nano))
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []ExemplarQueryResult
+	err = api.do(ctx, req, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
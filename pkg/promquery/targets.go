@@ -0,0 +1,44 @@
+package promquery
+
+import (
+	"context"
+	"net/http"
+)
+
+const epTargets = "/api/v1/targets"
+
+// Target is an active scrape target returned by the Prometheus targets API.
+type Target struct {
+	// DiscoveredLabels are the unmodified labels retrieved during service
+	// discovery before relabelling has occurred, including the __meta_*
+	// labels.
+	DiscoveredLabels map[string]string `json:"discoveredLabels"`
+	// Labels are the labels after relabelling has occurred, such as job
+	// and instance.
+	Labels    map[string]string `json:"labels"`
+	ScrapeURL string            `json:"scrapeUrl"`
+	Health    string            `json:"health"`
+}
+
+// TargetsAPI provides access to the Prometheus targets API.
+type TargetsAPI interface {
+	// ActiveTargets returns the targets Prometheus is currently scraping.
+	ActiveTargets(ctx context.Context) ([]Target, error)
+}
+
+func (api *httpAPI) ActiveTargets(ctx context.Context) ([]Target, error) {
+	u := api.client.URL(epTargets, nil)
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		ActiveTargets []Target `json:"activeTargets"`
+	}
+	err = api.do(ctx, req, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data.ActiveTargets, nil
+}
@@ -0,0 +1,1932 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: reporting.proto
+
+package reportingpb
+
+/*
+Package metering.reporting.v1 is the gRPC API of the reporting-operator,
+for managing Reports and reading their results, and checking the status
+of ReportDataSources.
+*/
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import timestamp "github.com/golang/protobuf/ptypes/timestamp"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type ReportKind int32
+
+const (
+	ReportKind_REPORT           ReportKind = 0
+	ReportKind_SCHEDULED_REPORT ReportKind = 1
+)
+
+var ReportKind_name = map[int32]string{
+	0: "REPORT",
+	1: "SCHEDULED_REPORT",
+}
+var ReportKind_value = map[string]int32{
+	"REPORT":           0,
+	"SCHEDULED_REPORT": 1,
+}
+
+func (x ReportKind) String() string {
+	return proto.EnumName(ReportKind_name, int32(x))
+}
+func (ReportKind) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{0}
+}
+
+type InputValue struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Value                string   `protobuf:"bytes,2,opt,name=value" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *InputValue) Reset()         { *m = InputValue{} }
+func (m *InputValue) String() string { return proto.CompactTextString(m) }
+func (*InputValue) ProtoMessage()    {}
+func (*InputValue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{0}
+}
+func (m *InputValue) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InputValue.Unmarshal(m, b)
+}
+func (m *InputValue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InputValue.Marshal(b, m, deterministic)
+}
+func (dst *InputValue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InputValue.Merge(dst, src)
+}
+func (m *InputValue) XXX_Size() int {
+	return xxx_messageInfo_InputValue.Size(m)
+}
+func (m *InputValue) XXX_DiscardUnknown() {
+	xxx_messageInfo_InputValue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InputValue proto.InternalMessageInfo
+
+func (m *InputValue) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *InputValue) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+type Report struct {
+	Name         string               `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	CreationTime *timestamp.Timestamp `protobuf:"bytes,2,opt,name=creation_time,json=creationTime" json:"creation_time,omitempty"`
+	// generation_query is the name of the ReportGenerationQuery the Report
+	// runs.
+	GenerationQuery      string               `protobuf:"bytes,3,opt,name=generation_query,json=generationQuery" json:"generation_query,omitempty"`
+	ReportingStart       *timestamp.Timestamp `protobuf:"bytes,4,opt,name=reporting_start,json=reportingStart" json:"reporting_start,omitempty"`
+	ReportingEnd         *timestamp.Timestamp `protobuf:"bytes,5,opt,name=reporting_end,json=reportingEnd" json:"reporting_end,omitempty"`
+	RunImmediately       bool                 `protobuf:"varint,6,opt,name=run_immediately,json=runImmediately" json:"run_immediately,omitempty"`
+	Inputs               []*InputValue        `protobuf:"bytes,7,rep,name=inputs" json:"inputs,omitempty"`
+	GroupByLabels        []string             `protobuf:"bytes,8,rep,name=group_by_labels,json=groupByLabels" json:"group_by_labels,omitempty"`
+	Status               *ReportStatus        `protobuf:"bytes,9,opt,name=status" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Report) Reset()         { *m = Report{} }
+func (m *Report) String() string { return proto.CompactTextString(m) }
+func (*Report) ProtoMessage()    {}
+func (*Report) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{1}
+}
+func (m *Report) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Report.Unmarshal(m, b)
+}
+func (m *Report) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Report.Marshal(b, m, deterministic)
+}
+func (dst *Report) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Report.Merge(dst, src)
+}
+func (m *Report) XXX_Size() int {
+	return xxx_messageInfo_Report.Size(m)
+}
+func (m *Report) XXX_DiscardUnknown() {
+	xxx_messageInfo_Report.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Report proto.InternalMessageInfo
+
+func (m *Report) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Report) GetCreationTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreationTime
+	}
+	return nil
+}
+
+func (m *Report) GetGenerationQuery() string {
+	if m != nil {
+		return m.GenerationQuery
+	}
+	return ""
+}
+
+func (m *Report) GetReportingStart() *timestamp.Timestamp {
+	if m != nil {
+		return m.ReportingStart
+	}
+	return nil
+}
+
+func (m *Report) GetReportingEnd() *timestamp.Timestamp {
+	if m != nil {
+		return m.ReportingEnd
+	}
+	return nil
+}
+
+func (m *Report) GetRunImmediately() bool {
+	if m != nil {
+		return m.RunImmediately
+	}
+	return false
+}
+
+func (m *Report) GetInputs() []*InputValue {
+	if m != nil {
+		return m.Inputs
+	}
+	return nil
+}
+
+func (m *Report) GetGroupByLabels() []string {
+	if m != nil {
+		return m.GroupByLabels
+	}
+	return nil
+}
+
+func (m *Report) GetStatus() *ReportStatus {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type ReportStatus struct {
+	// phase is one of Waiting, Started, Finished or Error.
+	Phase string `protobuf:"bytes,1,opt,name=phase" json:"phase,omitempty"`
+	// output is the error the Report failed with.
+	Output               string               `protobuf:"bytes,2,opt,name=output" json:"output,omitempty"`
+	NextRetryTime        *timestamp.Timestamp `protobuf:"bytes,3,opt,name=next_retry_time,json=nextRetryTime" json:"next_retry_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *ReportStatus) Reset()         { *m = ReportStatus{} }
+func (m *ReportStatus) String() string { return proto.CompactTextString(m) }
+func (*ReportStatus) ProtoMessage()    {}
+func (*ReportStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{2}
+}
+func (m *ReportStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReportStatus.Unmarshal(m, b)
+}
+func (m *ReportStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReportStatus.Marshal(b, m, deterministic)
+}
+func (dst *ReportStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReportStatus.Merge(dst, src)
+}
+func (m *ReportStatus) XXX_Size() int {
+	return xxx_messageInfo_ReportStatus.Size(m)
+}
+func (m *ReportStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReportStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReportStatus proto.InternalMessageInfo
+
+func (m *ReportStatus) GetPhase() string {
+	if m != nil {
+		return m.Phase
+	}
+	return ""
+}
+
+func (m *ReportStatus) GetOutput() string {
+	if m != nil {
+		return m.Output
+	}
+	return ""
+}
+
+func (m *ReportStatus) GetNextRetryTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.NextRetryTime
+	}
+	return nil
+}
+
+type ListReportsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListReportsRequest) Reset()         { *m = ListReportsRequest{} }
+func (m *ListReportsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListReportsRequest) ProtoMessage()    {}
+func (*ListReportsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{3}
+}
+func (m *ListReportsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListReportsRequest.Unmarshal(m, b)
+}
+func (m *ListReportsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListReportsRequest.Marshal(b, m, deterministic)
+}
+func (dst *ListReportsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListReportsRequest.Merge(dst, src)
+}
+func (m *ListReportsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListReportsRequest.Size(m)
+}
+func (m *ListReportsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListReportsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListReportsRequest proto.InternalMessageInfo
+
+type ListReportsResponse struct {
+	Reports              []*Report `protobuf:"bytes,1,rep,name=reports" json:"reports,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *ListReportsResponse) Reset()         { *m = ListReportsResponse{} }
+func (m *ListReportsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListReportsResponse) ProtoMessage()    {}
+func (*ListReportsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{4}
+}
+func (m *ListReportsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListReportsResponse.Unmarshal(m, b)
+}
+func (m *ListReportsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListReportsResponse.Marshal(b, m, deterministic)
+}
+func (dst *ListReportsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListReportsResponse.Merge(dst, src)
+}
+func (m *ListReportsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListReportsResponse.Size(m)
+}
+func (m *ListReportsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListReportsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListReportsResponse proto.InternalMessageInfo
+
+func (m *ListReportsResponse) GetReports() []*Report {
+	if m != nil {
+		return m.Reports
+	}
+	return nil
+}
+
+type GetReportRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetReportRequest) Reset()         { *m = GetReportRequest{} }
+func (m *GetReportRequest) String() string { return proto.CompactTextString(m) }
+func (*GetReportRequest) ProtoMessage()    {}
+func (*GetReportRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{5}
+}
+func (m *GetReportRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetReportRequest.Unmarshal(m, b)
+}
+func (m *GetReportRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetReportRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetReportRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetReportRequest.Merge(dst, src)
+}
+func (m *GetReportRequest) XXX_Size() int {
+	return xxx_messageInfo_GetReportRequest.Size(m)
+}
+func (m *GetReportRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetReportRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetReportRequest proto.InternalMessageInfo
+
+func (m *GetReportRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type CreateReportRequest struct {
+	// report is the Report to create. Its creation_time and status are
+	// ignored.
+	Report               *Report  `protobuf:"bytes,1,opt,name=report" json:"report,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateReportRequest) Reset()         { *m = CreateReportRequest{} }
+func (m *CreateReportRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateReportRequest) ProtoMessage()    {}
+func (*CreateReportRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{6}
+}
+func (m *CreateReportRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateReportRequest.Unmarshal(m, b)
+}
+func (m *CreateReportRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateReportRequest.Marshal(b, m, deterministic)
+}
+func (dst *CreateReportRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateReportRequest.Merge(dst, src)
+}
+func (m *CreateReportRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateReportRequest.Size(m)
+}
+func (m *CreateReportRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateReportRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateReportRequest proto.InternalMessageInfo
+
+func (m *CreateReportRequest) GetReport() *Report {
+	if m != nil {
+		return m.Report
+	}
+	return nil
+}
+
+type DeleteReportRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteReportRequest) Reset()         { *m = DeleteReportRequest{} }
+func (m *DeleteReportRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteReportRequest) ProtoMessage()    {}
+func (*DeleteReportRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{7}
+}
+func (m *DeleteReportRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteReportRequest.Unmarshal(m, b)
+}
+func (m *DeleteReportRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteReportRequest.Marshal(b, m, deterministic)
+}
+func (dst *DeleteReportRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteReportRequest.Merge(dst, src)
+}
+func (m *DeleteReportRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteReportRequest.Size(m)
+}
+func (m *DeleteReportRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteReportRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteReportRequest proto.InternalMessageInfo
+
+func (m *DeleteReportRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type DeleteReportResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteReportResponse) Reset()         { *m = DeleteReportResponse{} }
+func (m *DeleteReportResponse) String() string { return proto.CompactTextString(m) }
+func (*DeleteReportResponse) ProtoMessage()    {}
+func (*DeleteReportResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{8}
+}
+func (m *DeleteReportResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteReportResponse.Unmarshal(m, b)
+}
+func (m *DeleteReportResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteReportResponse.Marshal(b, m, deterministic)
+}
+func (dst *DeleteReportResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteReportResponse.Merge(dst, src)
+}
+func (m *DeleteReportResponse) XXX_Size() int {
+	return xxx_messageInfo_DeleteReportResponse.Size(m)
+}
+func (m *DeleteReportResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteReportResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteReportResponse proto.InternalMessageInfo
+
+type ScheduledReport struct {
+	Name            string               `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	CreationTime    *timestamp.Timestamp `protobuf:"bytes,2,opt,name=creation_time,json=creationTime" json:"creation_time,omitempty"`
+	GenerationQuery string               `protobuf:"bytes,3,opt,name=generation_query,json=generationQuery" json:"generation_query,omitempty"`
+	// period is one of hourly, daily, weekly, monthly or cron.
+	Period               string                 `protobuf:"bytes,4,opt,name=period" json:"period,omitempty"`
+	Inputs               []*InputValue          `protobuf:"bytes,5,rep,name=inputs" json:"inputs,omitempty"`
+	GroupByLabels        []string               `protobuf:"bytes,6,rep,name=group_by_labels,json=groupByLabels" json:"group_by_labels,omitempty"`
+	Status               *ScheduledReportStatus `protobuf:"bytes,7,opt,name=status" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
+}
+
+func (m *ScheduledReport) Reset()         { *m = ScheduledReport{} }
+func (m *ScheduledReport) String() string { return proto.CompactTextString(m) }
+func (*ScheduledReport) ProtoMessage()    {}
+func (*ScheduledReport) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{9}
+}
+func (m *ScheduledReport) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ScheduledReport.Unmarshal(m, b)
+}
+func (m *ScheduledReport) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ScheduledReport.Marshal(b, m, deterministic)
+}
+func (dst *ScheduledReport) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ScheduledReport.Merge(dst, src)
+}
+func (m *ScheduledReport) XXX_Size() int {
+	return xxx_messageInfo_ScheduledReport.Size(m)
+}
+func (m *ScheduledReport) XXX_DiscardUnknown() {
+	xxx_messageInfo_ScheduledReport.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ScheduledReport proto.InternalMessageInfo
+
+func (m *ScheduledReport) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ScheduledReport) GetCreationTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreationTime
+	}
+	return nil
+}
+
+func (m *ScheduledReport) GetGenerationQuery() string {
+	if m != nil {
+		return m.GenerationQuery
+	}
+	return ""
+}
+
+func (m *ScheduledReport) GetPeriod() string {
+	if m != nil {
+		return m.Period
+	}
+	return ""
+}
+
+func (m *ScheduledReport) GetInputs() []*InputValue {
+	if m != nil {
+		return m.Inputs
+	}
+	return nil
+}
+
+func (m *ScheduledReport) GetGroupByLabels() []string {
+	if m != nil {
+		return m.GroupByLabels
+	}
+	return nil
+}
+
+func (m *ScheduledReport) GetStatus() *ScheduledReportStatus {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type ScheduledReportStatus struct {
+	// last_report_time is the end of the most recent period the
+	// ScheduledReport ran for.
+	LastReportTime       *timestamp.Timestamp `protobuf:"bytes,1,opt,name=last_report_time,json=lastReportTime" json:"last_report_time,omitempty"`
+	Conditions           []*Condition         `protobuf:"bytes,2,rep,name=conditions" json:"conditions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *ScheduledReportStatus) Reset()         { *m = ScheduledReportStatus{} }
+func (m *ScheduledReportStatus) String() string { return proto.CompactTextString(m) }
+func (*ScheduledReportStatus) ProtoMessage()    {}
+func (*ScheduledReportStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{10}
+}
+func (m *ScheduledReportStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ScheduledReportStatus.Unmarshal(m, b)
+}
+func (m *ScheduledReportStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ScheduledReportStatus.Marshal(b, m, deterministic)
+}
+func (dst *ScheduledReportStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ScheduledReportStatus.Merge(dst, src)
+}
+func (m *ScheduledReportStatus) XXX_Size() int {
+	return xxx_messageInfo_ScheduledReportStatus.Size(m)
+}
+func (m *ScheduledReportStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_ScheduledReportStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ScheduledReportStatus proto.InternalMessageInfo
+
+func (m *ScheduledReportStatus) GetLastReportTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.LastReportTime
+	}
+	return nil
+}
+
+func (m *ScheduledReportStatus) GetConditions() []*Condition {
+	if m != nil {
+		return m.Conditions
+	}
+	return nil
+}
+
+type Condition struct {
+	Type                 string               `protobuf:"bytes,1,opt,name=type" json:"type,omitempty"`
+	Status               string               `protobuf:"bytes,2,opt,name=status" json:"status,omitempty"`
+	Reason               string               `protobuf:"bytes,3,opt,name=reason" json:"reason,omitempty"`
+	Message              string               `protobuf:"bytes,4,opt,name=message" json:"message,omitempty"`
+	LastTransitionTime   *timestamp.Timestamp `protobuf:"bytes,5,opt,name=last_transition_time,json=lastTransitionTime" json:"last_transition_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Condition) Reset()         { *m = Condition{} }
+func (m *Condition) String() string { return proto.CompactTextString(m) }
+func (*Condition) ProtoMessage()    {}
+func (*Condition) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{11}
+}
+func (m *Condition) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Condition.Unmarshal(m, b)
+}
+func (m *Condition) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Condition.Marshal(b, m, deterministic)
+}
+func (dst *Condition) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Condition.Merge(dst, src)
+}
+func (m *Condition) XXX_Size() int {
+	return xxx_messageInfo_Condition.Size(m)
+}
+func (m *Condition) XXX_DiscardUnknown() {
+	xxx_messageInfo_Condition.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Condition proto.InternalMessageInfo
+
+func (m *Condition) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Condition) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *Condition) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *Condition) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *Condition) GetLastTransitionTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.LastTransitionTime
+	}
+	return nil
+}
+
+type ListScheduledReportsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListScheduledReportsRequest) Reset()         { *m = ListScheduledReportsRequest{} }
+func (m *ListScheduledReportsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListScheduledReportsRequest) ProtoMessage()    {}
+func (*ListScheduledReportsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{12}
+}
+func (m *ListScheduledReportsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListScheduledReportsRequest.Unmarshal(m, b)
+}
+func (m *ListScheduledReportsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListScheduledReportsRequest.Marshal(b, m, deterministic)
+}
+func (dst *ListScheduledReportsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListScheduledReportsRequest.Merge(dst, src)
+}
+func (m *ListScheduledReportsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListScheduledReportsRequest.Size(m)
+}
+func (m *ListScheduledReportsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListScheduledReportsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListScheduledReportsRequest proto.InternalMessageInfo
+
+type ListScheduledReportsResponse struct {
+	ScheduledReports     []*ScheduledReport `protobuf:"bytes,1,rep,name=scheduled_reports,json=scheduledReports" json:"scheduled_reports,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *ListScheduledReportsResponse) Reset()         { *m = ListScheduledReportsResponse{} }
+func (m *ListScheduledReportsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListScheduledReportsResponse) ProtoMessage()    {}
+func (*ListScheduledReportsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{13}
+}
+func (m *ListScheduledReportsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListScheduledReportsResponse.Unmarshal(m, b)
+}
+func (m *ListScheduledReportsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListScheduledReportsResponse.Marshal(b, m, deterministic)
+}
+func (dst *ListScheduledReportsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListScheduledReportsResponse.Merge(dst, src)
+}
+func (m *ListScheduledReportsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListScheduledReportsResponse.Size(m)
+}
+func (m *ListScheduledReportsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListScheduledReportsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListScheduledReportsResponse proto.InternalMessageInfo
+
+func (m *ListScheduledReportsResponse) GetScheduledReports() []*ScheduledReport {
+	if m != nil {
+		return m.ScheduledReports
+	}
+	return nil
+}
+
+type GetScheduledReportRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetScheduledReportRequest) Reset()         { *m = GetScheduledReportRequest{} }
+func (m *GetScheduledReportRequest) String() string { return proto.CompactTextString(m) }
+func (*GetScheduledReportRequest) ProtoMessage()    {}
+func (*GetScheduledReportRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{14}
+}
+func (m *GetScheduledReportRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetScheduledReportRequest.Unmarshal(m, b)
+}
+func (m *GetScheduledReportRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetScheduledReportRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetScheduledReportRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetScheduledReportRequest.Merge(dst, src)
+}
+func (m *GetScheduledReportRequest) XXX_Size() int {
+	return xxx_messageInfo_GetScheduledReportRequest.Size(m)
+}
+func (m *GetScheduledReportRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetScheduledReportRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetScheduledReportRequest proto.InternalMessageInfo
+
+func (m *GetScheduledReportRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type StreamReportResultsRequest struct {
+	Kind ReportKind `protobuf:"varint,1,opt,name=kind,enum=metering.reporting.v1.ReportKind" json:"kind,omitempty"`
+	Name string     `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	// columns are the columns returned, defaulting to every column.
+	Columns []string `protobuf:"bytes,3,rep,name=columns" json:"columns,omitempty"`
+	// filters are expressions in the form <column><operator><value>. Only
+	// rows matching every filter are returned.
+	Filters []string `protobuf:"bytes,4,rep,name=filters" json:"filters,omitempty"`
+	// batch_size is the most rows sent in each batch, defaulting to 1000.
+	BatchSize int32 `protobuf:"varint,5,opt,name=batch_size,json=batchSize" json:"batch_size,omitempty"`
+	// ignore_failed returns the results of a ScheduledReport even if its most
+	// recent run failed.
+	IgnoreFailed         bool     `protobuf:"varint,6,opt,name=ignore_failed,json=ignoreFailed" json:"ignore_failed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamReportResultsRequest) Reset()         { *m = StreamReportResultsRequest{} }
+func (m *StreamReportResultsRequest) String() string { return proto.CompactTextString(m) }
+func (*StreamReportResultsRequest) ProtoMessage()    {}
+func (*StreamReportResultsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{15}
+}
+func (m *StreamReportResultsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamReportResultsRequest.Unmarshal(m, b)
+}
+func (m *StreamReportResultsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamReportResultsRequest.Marshal(b, m, deterministic)
+}
+func (dst *StreamReportResultsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamReportResultsRequest.Merge(dst, src)
+}
+func (m *StreamReportResultsRequest) XXX_Size() int {
+	return xxx_messageInfo_StreamReportResultsRequest.Size(m)
+}
+func (m *StreamReportResultsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamReportResultsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamReportResultsRequest proto.InternalMessageInfo
+
+func (m *StreamReportResultsRequest) GetKind() ReportKind {
+	if m != nil {
+		return m.Kind
+	}
+	return ReportKind_REPORT
+}
+
+func (m *StreamReportResultsRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *StreamReportResultsRequest) GetColumns() []string {
+	if m != nil {
+		return m.Columns
+	}
+	return nil
+}
+
+func (m *StreamReportResultsRequest) GetFilters() []string {
+	if m != nil {
+		return m.Filters
+	}
+	return nil
+}
+
+func (m *StreamReportResultsRequest) GetBatchSize() int32 {
+	if m != nil {
+		return m.BatchSize
+	}
+	return 0
+}
+
+func (m *StreamReportResultsRequest) GetIgnoreFailed() bool {
+	if m != nil {
+		return m.IgnoreFailed
+	}
+	return false
+}
+
+type Column struct {
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	// type is the Hive type of the column, such as string or double.
+	Type                 string   `protobuf:"bytes,2,opt,name=type" json:"type,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Column) Reset()         { *m = Column{} }
+func (m *Column) String() string { return proto.CompactTextString(m) }
+func (*Column) ProtoMessage()    {}
+func (*Column) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{16}
+}
+func (m *Column) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Column.Unmarshal(m, b)
+}
+func (m *Column) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Column.Marshal(b, m, deterministic)
+}
+func (dst *Column) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Column.Merge(dst, src)
+}
+func (m *Column) XXX_Size() int {
+	return xxx_messageInfo_Column.Size(m)
+}
+func (m *Column) XXX_DiscardUnknown() {
+	xxx_messageInfo_Column.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Column proto.InternalMessageInfo
+
+func (m *Column) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Column) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+type ReportResultsBatch struct {
+	// columns are the columns of the rows, which are only set in the first
+	// batch.
+	Columns              []*Column `protobuf:"bytes,1,rep,name=columns" json:"columns,omitempty"`
+	Rows                 []*Row    `protobuf:"bytes,2,rep,name=rows" json:"rows,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *ReportResultsBatch) Reset()         { *m = ReportResultsBatch{} }
+func (m *ReportResultsBatch) String() string { return proto.CompactTextString(m) }
+func (*ReportResultsBatch) ProtoMessage()    {}
+func (*ReportResultsBatch) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{17}
+}
+func (m *ReportResultsBatch) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReportResultsBatch.Unmarshal(m, b)
+}
+func (m *ReportResultsBatch) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReportResultsBatch.Marshal(b, m, deterministic)
+}
+func (dst *ReportResultsBatch) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReportResultsBatch.Merge(dst, src)
+}
+func (m *ReportResultsBatch) XXX_Size() int {
+	return xxx_messageInfo_ReportResultsBatch.Size(m)
+}
+func (m *ReportResultsBatch) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReportResultsBatch.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReportResultsBatch proto.InternalMessageInfo
+
+func (m *ReportResultsBatch) GetColumns() []*Column {
+	if m != nil {
+		return m.Columns
+	}
+	return nil
+}
+
+func (m *ReportResultsBatch) GetRows() []*Row {
+	if m != nil {
+		return m.Rows
+	}
+	return nil
+}
+
+type Row struct {
+	// values are the values of each of the columns, in order.
+	Values               []*Value `protobuf:"bytes,1,rep,name=values" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Row) Reset()         { *m = Row{} }
+func (m *Row) String() string { return proto.CompactTextString(m) }
+func (*Row) ProtoMessage()    {}
+func (*Row) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{18}
+}
+func (m *Row) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Row.Unmarshal(m, b)
+}
+func (m *Row) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Row.Marshal(b, m, deterministic)
+}
+func (dst *Row) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Row.Merge(dst, src)
+}
+func (m *Row) XXX_Size() int {
+	return xxx_messageInfo_Row.Size(m)
+}
+func (m *Row) XXX_DiscardUnknown() {
+	xxx_messageInfo_Row.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Row proto.InternalMessageInfo
+
+func (m *Row) GetValues() []*Value {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+// Value is a value of a row. No value is set for nulls.
+type Value struct {
+	// Types that are valid to be assigned to Value:
+	//	*Value_StringValue
+	//	*Value_IntValue
+	//	*Value_DoubleValue
+	//	*Value_BoolValue
+	//	*Value_TimestampValue
+	//	*Value_JsonValue
+	Value                isValue_Value `protobuf_oneof:"value"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *Value) Reset()         { *m = Value{} }
+func (m *Value) String() string { return proto.CompactTextString(m) }
+func (*Value) ProtoMessage()    {}
+func (*Value) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{19}
+}
+func (m *Value) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Value.Unmarshal(m, b)
+}
+func (m *Value) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Value.Marshal(b, m, deterministic)
+}
+func (dst *Value) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Value.Merge(dst, src)
+}
+func (m *Value) XXX_Size() int {
+	return xxx_messageInfo_Value.Size(m)
+}
+func (m *Value) XXX_DiscardUnknown() {
+	xxx_messageInfo_Value.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Value proto.InternalMessageInfo
+
+type isValue_Value interface {
+	isValue_Value()
+}
+
+type Value_StringValue struct {
+	StringValue string `protobuf:"bytes,1,opt,name=string_value,json=stringValue,oneof"`
+}
+type Value_IntValue struct {
+	IntValue int64 `protobuf:"varint,2,opt,name=int_value,json=intValue,oneof"`
+}
+type Value_DoubleValue struct {
+	DoubleValue float64 `protobuf:"fixed64,3,opt,name=double_value,json=doubleValue,oneof"`
+}
+type Value_BoolValue struct {
+	BoolValue bool `protobuf:"varint,4,opt,name=bool_value,json=boolValue,oneof"`
+}
+type Value_TimestampValue struct {
+	TimestampValue *timestamp.Timestamp `protobuf:"bytes,5,opt,name=timestamp_value,json=timestampValue,oneof"`
+}
+type Value_JsonValue struct {
+	JsonValue string `protobuf:"bytes,6,opt,name=json_value,json=jsonValue,oneof"`
+}
+
+func (*Value_StringValue) isValue_Value()    {}
+func (*Value_IntValue) isValue_Value()       {}
+func (*Value_DoubleValue) isValue_Value()    {}
+func (*Value_BoolValue) isValue_Value()      {}
+func (*Value_TimestampValue) isValue_Value() {}
+func (*Value_JsonValue) isValue_Value()      {}
+
+func (m *Value) GetValue() isValue_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *Value) GetStringValue() string {
+	if x, ok := m.GetValue().(*Value_StringValue); ok {
+		return x.StringValue
+	}
+	return ""
+}
+
+func (m *Value) GetIntValue() int64 {
+	if x, ok := m.GetValue().(*Value_IntValue); ok {
+		return x.IntValue
+	}
+	return 0
+}
+
+func (m *Value) GetDoubleValue() float64 {
+	if x, ok := m.GetValue().(*Value_DoubleValue); ok {
+		return x.DoubleValue
+	}
+	return 0
+}
+
+func (m *Value) GetBoolValue() bool {
+	if x, ok := m.GetValue().(*Value_BoolValue); ok {
+		return x.BoolValue
+	}
+	return false
+}
+
+func (m *Value) GetTimestampValue() *timestamp.Timestamp {
+	if x, ok := m.GetValue().(*Value_TimestampValue); ok {
+		return x.TimestampValue
+	}
+	return nil
+}
+
+func (m *Value) GetJsonValue() string {
+	if x, ok := m.GetValue().(*Value_JsonValue); ok {
+		return x.JsonValue
+	}
+	return ""
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*Value) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _Value_OneofMarshaler, _Value_OneofUnmarshaler, _Value_OneofSizer, []interface{}{
+		(*Value_StringValue)(nil),
+		(*Value_IntValue)(nil),
+		(*Value_DoubleValue)(nil),
+		(*Value_BoolValue)(nil),
+		(*Value_TimestampValue)(nil),
+		(*Value_JsonValue)(nil),
+	}
+}
+
+func _Value_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*Value)
+	// value
+	switch x := m.Value.(type) {
+	case *Value_StringValue:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		b.EncodeStringBytes(x.StringValue)
+	case *Value_IntValue:
+		b.EncodeVarint(2<<3 | proto.WireVarint)
+		b.EncodeVarint(uint64(x.IntValue))
+	case *Value_DoubleValue:
+		b.EncodeVarint(3<<3 | proto.WireFixed64)
+		b.EncodeFixed64(math.Float64bits(x.DoubleValue))
+	case *Value_BoolValue:
+		t := uint64(0)
+		if x.BoolValue {
+			t = 1
+		}
+		b.EncodeVarint(4<<3 | proto.WireVarint)
+		b.EncodeVarint(t)
+	case *Value_TimestampValue:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.TimestampValue); err != nil {
+			return err
+		}
+	case *Value_JsonValue:
+		b.EncodeVarint(6<<3 | proto.WireBytes)
+		b.EncodeStringBytes(x.JsonValue)
+	case nil:
+	default:
+		return fmt.Errorf("Value.Value has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _Value_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*Value)
+	switch tag {
+	case 1: // value.string_value
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeStringBytes()
+		m.Value = &Value_StringValue{x}
+		return true, err
+	case 2: // value.int_value
+		if wire != proto.WireVarint {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeVarint()
+		m.Value = &Value_IntValue{int64(x)}
+		return true, err
+	case 3: // value.double_value
+		if wire != proto.WireFixed64 {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeFixed64()
+		m.Value = &Value_DoubleValue{math.Float64frombits(x)}
+		return true, err
+	case 4: // value.bool_value
+		if wire != proto.WireVarint {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeVarint()
+		m.Value = &Value_BoolValue{x != 0}
+		return true, err
+	case 5: // value.timestamp_value
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(timestamp.Timestamp)
+		err := b.DecodeMessage(msg)
+		m.Value = &Value_TimestampValue{msg}
+		return true, err
+	case 6: // value.json_value
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeStringBytes()
+		m.Value = &Value_JsonValue{x}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _Value_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*Value)
+	// value
+	switch x := m.Value.(type) {
+	case *Value_StringValue:
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(len(x.StringValue)))
+		n += len(x.StringValue)
+	case *Value_IntValue:
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(x.IntValue))
+	case *Value_DoubleValue:
+		n += 1 // tag and wire
+		n += 8
+	case *Value_BoolValue:
+		n += 1 // tag and wire
+		n += 1
+	case *Value_TimestampValue:
+		s := proto.Size(x.TimestampValue)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Value_JsonValue:
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(len(x.JsonValue)))
+		n += len(x.JsonValue)
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+type ReportDataSource struct {
+	Name         string               `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	CreationTime *timestamp.Timestamp `protobuf:"bytes,2,opt,name=creation_time,json=creationTime" json:"creation_time,omitempty"`
+	// type is the field of the ReportDataSource's spec which is set, one of
+	// promsum, awsBilling, gcpBilling, kubernetesObjects, kubernetesEvents,
+	// remoteReport, objectStorage or database.
+	Type string `protobuf:"bytes,3,opt,name=type" json:"type,omitempty"`
+	// table_name is the name of the table the data is stored in.
+	TableName string `protobuf:"bytes,4,opt,name=table_name,json=tableName" json:"table_name,omitempty"`
+	// conditions contains the Degraded condition, which is set when the
+	// imported data violates the ReportDataSource's validation rules.
+	Conditions           []*Condition `protobuf:"bytes,5,rep,name=conditions" json:"conditions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *ReportDataSource) Reset()         { *m = ReportDataSource{} }
+func (m *ReportDataSource) String() string { return proto.CompactTextString(m) }
+func (*ReportDataSource) ProtoMessage()    {}
+func (*ReportDataSource) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{20}
+}
+func (m *ReportDataSource) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReportDataSource.Unmarshal(m, b)
+}
+func (m *ReportDataSource) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReportDataSource.Marshal(b, m, deterministic)
+}
+func (dst *ReportDataSource) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReportDataSource.Merge(dst, src)
+}
+func (m *ReportDataSource) XXX_Size() int {
+	return xxx_messageInfo_ReportDataSource.Size(m)
+}
+func (m *ReportDataSource) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReportDataSource.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReportDataSource proto.InternalMessageInfo
+
+func (m *ReportDataSource) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ReportDataSource) GetCreationTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreationTime
+	}
+	return nil
+}
+
+func (m *ReportDataSource) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *ReportDataSource) GetTableName() string {
+	if m != nil {
+		return m.TableName
+	}
+	return ""
+}
+
+func (m *ReportDataSource) GetConditions() []*Condition {
+	if m != nil {
+		return m.Conditions
+	}
+	return nil
+}
+
+type ListReportDataSourcesRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListReportDataSourcesRequest) Reset()         { *m = ListReportDataSourcesRequest{} }
+func (m *ListReportDataSourcesRequest) String() string { return proto.CompactTextString(m) }
+func (*ListReportDataSourcesRequest) ProtoMessage()    {}
+func (*ListReportDataSourcesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{21}
+}
+func (m *ListReportDataSourcesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListReportDataSourcesRequest.Unmarshal(m, b)
+}
+func (m *ListReportDataSourcesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListReportDataSourcesRequest.Marshal(b, m, deterministic)
+}
+func (dst *ListReportDataSourcesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListReportDataSourcesRequest.Merge(dst, src)
+}
+func (m *ListReportDataSourcesRequest) XXX_Size() int {
+	return xxx_messageInfo_ListReportDataSourcesRequest.Size(m)
+}
+func (m *ListReportDataSourcesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListReportDataSourcesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListReportDataSourcesRequest proto.InternalMessageInfo
+
+type ListReportDataSourcesResponse struct {
+	ReportDataSources    []*ReportDataSource `protobuf:"bytes,1,rep,name=report_data_sources,json=reportDataSources" json:"report_data_sources,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *ListReportDataSourcesResponse) Reset()         { *m = ListReportDataSourcesResponse{} }
+func (m *ListReportDataSourcesResponse) String() string { return proto.CompactTextString(m) }
+func (*ListReportDataSourcesResponse) ProtoMessage()    {}
+func (*ListReportDataSourcesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{22}
+}
+func (m *ListReportDataSourcesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListReportDataSourcesResponse.Unmarshal(m, b)
+}
+func (m *ListReportDataSourcesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListReportDataSourcesResponse.Marshal(b, m, deterministic)
+}
+func (dst *ListReportDataSourcesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListReportDataSourcesResponse.Merge(dst, src)
+}
+func (m *ListReportDataSourcesResponse) XXX_Size() int {
+	return xxx_messageInfo_ListReportDataSourcesResponse.Size(m)
+}
+func (m *ListReportDataSourcesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListReportDataSourcesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListReportDataSourcesResponse proto.InternalMessageInfo
+
+func (m *ListReportDataSourcesResponse) GetReportDataSources() []*ReportDataSource {
+	if m != nil {
+		return m.ReportDataSources
+	}
+	return nil
+}
+
+type GetReportDataSourceRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetReportDataSourceRequest) Reset()         { *m = GetReportDataSourceRequest{} }
+func (m *GetReportDataSourceRequest) String() string { return proto.CompactTextString(m) }
+func (*GetReportDataSourceRequest) ProtoMessage()    {}
+func (*GetReportDataSourceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_reporting_fc7ad5eb2cc698df, []int{23}
+}
+func (m *GetReportDataSourceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetReportDataSourceRequest.Unmarshal(m, b)
+}
+func (m *GetReportDataSourceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetReportDataSourceRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetReportDataSourceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetReportDataSourceRequest.Merge(dst, src)
+}
+func (m *GetReportDataSourceRequest) XXX_Size() int {
+	return xxx_messageInfo_GetReportDataSourceRequest.Size(m)
+}
+func (m *GetReportDataSourceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetReportDataSourceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetReportDataSourceRequest proto.InternalMessageInfo
+
+func (m *GetReportDataSourceRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*InputValue)(nil), "metering.reporting.v1.InputValue")
+	proto.RegisterType((*Report)(nil), "metering.reporting.v1.Report")
+	proto.RegisterType((*ReportStatus)(nil), "metering.reporting.v1.ReportStatus")
+	proto.RegisterType((*ListReportsRequest)(nil), "metering.reporting.v1.ListReportsRequest")
+	proto.RegisterType((*ListReportsResponse)(nil), "metering.reporting.v1.ListReportsResponse")
+	proto.RegisterType((*GetReportRequest)(nil), "metering.reporting.v1.GetReportRequest")
+	proto.RegisterType((*CreateReportRequest)(nil), "metering.reporting.v1.CreateReportRequest")
+	proto.RegisterType((*DeleteReportRequest)(nil), "metering.reporting.v1.DeleteReportRequest")
+	proto.RegisterType((*DeleteReportResponse)(nil), "metering.reporting.v1.DeleteReportResponse")
+	proto.RegisterType((*ScheduledReport)(nil), "metering.reporting.v1.ScheduledReport")
+	proto.RegisterType((*ScheduledReportStatus)(nil), "metering.reporting.v1.ScheduledReportStatus")
+	proto.RegisterType((*Condition)(nil), "metering.reporting.v1.Condition")
+	proto.RegisterType((*ListScheduledReportsRequest)(nil), "metering.reporting.v1.ListScheduledReportsRequest")
+	proto.RegisterType((*ListScheduledReportsResponse)(nil), "metering.reporting.v1.ListScheduledReportsResponse")
+	proto.RegisterType((*GetScheduledReportRequest)(nil), "metering.reporting.v1.GetScheduledReportRequest")
+	proto.RegisterType((*StreamReportResultsRequest)(nil), "metering.reporting.v1.StreamReportResultsRequest")
+	proto.RegisterType((*Column)(nil), "metering.reporting.v1.Column")
+	proto.RegisterType((*ReportResultsBatch)(nil), "metering.reporting.v1.ReportResultsBatch")
+	proto.RegisterType((*Row)(nil), "metering.reporting.v1.Row")
+	proto.RegisterType((*Value)(nil), "metering.reporting.v1.Value")
+	proto.RegisterType((*ReportDataSource)(nil), "metering.reporting.v1.ReportDataSource")
+	proto.RegisterType((*ListReportDataSourcesRequest)(nil), "metering.reporting.v1.ListReportDataSourcesRequest")
+	proto.RegisterType((*ListReportDataSourcesResponse)(nil), "metering.reporting.v1.ListReportDataSourcesResponse")
+	proto.RegisterType((*GetReportDataSourceRequest)(nil), "metering.reporting.v1.GetReportDataSourceRequest")
+	proto.RegisterEnum("metering.reporting.v1.ReportKind", ReportKind_name, ReportKind_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Reporting service
+
+type ReportingClient interface {
+	// ListReports returns every Report.
+	ListReports(ctx context.Context, in *ListReportsRequest, opts ...grpc.CallOption) (*ListReportsResponse, error)
+	// GetReport returns a Report and its status.
+	GetReport(ctx context.Context, in *GetReportRequest, opts ...grpc.CallOption) (*Report, error)
+	// CreateReport creates a Report, which runs once it's created.
+	CreateReport(ctx context.Context, in *CreateReportRequest, opts ...grpc.CallOption) (*Report, error)
+	// DeleteReport deletes a Report and its results.
+	DeleteReport(ctx context.Context, in *DeleteReportRequest, opts ...grpc.CallOption) (*DeleteReportResponse, error)
+	// ListScheduledReports returns every ScheduledReport.
+	ListScheduledReports(ctx context.Context, in *ListScheduledReportsRequest, opts ...grpc.CallOption) (*ListScheduledReportsResponse, error)
+	// GetScheduledReport returns a ScheduledReport and its status.
+	GetScheduledReport(ctx context.Context, in *GetScheduledReportRequest, opts ...grpc.CallOption) (*ScheduledReport, error)
+	// StreamReportResults streams the results of a finished Report, or of a
+	// ScheduledReport, in batches of rows as they're read from Presto.
+	StreamReportResults(ctx context.Context, in *StreamReportResultsRequest, opts ...grpc.CallOption) (Reporting_StreamReportResultsClient, error)
+	// ListReportDataSources returns every ReportDataSource.
+	ListReportDataSources(ctx context.Context, in *ListReportDataSourcesRequest, opts ...grpc.CallOption) (*ListReportDataSourcesResponse, error)
+	// GetReportDataSource returns a ReportDataSource and its status.
+	GetReportDataSource(ctx context.Context, in *GetReportDataSourceRequest, opts ...grpc.CallOption) (*ReportDataSource, error)
+}
+
+type reportingClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewReportingClient(cc *grpc.ClientConn) ReportingClient {
+	return &reportingClient{cc}
+}
+
+func (c *reportingClient) ListReports(ctx context.Context, in *ListReportsRequest, opts ...grpc.CallOption) (*ListReportsResponse, error) {
+	out := new(ListReportsResponse)
+	err := grpc.Invoke(ctx, "/metering.reporting.v1.Reporting/ListReports", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reportingClient) GetReport(ctx context.Context, in *GetReportRequest, opts ...grpc.CallOption) (*Report, error) {
+	out := new(Report)
+	err := grpc.Invoke(ctx, "/metering.reporting.v1.Reporting/GetReport", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reportingClient) CreateReport(ctx context.Context, in *CreateReportRequest, opts ...grpc.CallOption) (*Report, error) {
+	out := new(Report)
+	err := grpc.Invoke(ctx, "/metering.reporting.v1.Reporting/CreateReport", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reportingClient) DeleteReport(ctx context.Context, in *DeleteReportRequest, opts ...grpc.CallOption) (*DeleteReportResponse, error) {
+	out := new(DeleteReportResponse)
+	err := grpc.Invoke(ctx, "/metering.reporting.v1.Reporting/DeleteReport", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reportingClient) ListScheduledReports(ctx context.Context, in *ListScheduledReportsRequest, opts ...grpc.CallOption) (*ListScheduledReportsResponse, error) {
+	out := new(ListScheduledReportsResponse)
+	err := grpc.Invoke(ctx, "/metering.reporting.v1.Reporting/ListScheduledReports", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reportingClient) GetScheduledReport(ctx context.Context, in *GetScheduledReportRequest, opts ...grpc.CallOption) (*ScheduledReport, error) {
+	out := new(ScheduledReport)
+	err := grpc.Invoke(ctx, "/metering.reporting.v1.Reporting/GetScheduledReport", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reportingClient) StreamReportResults(ctx context.Context, in *StreamReportResultsRequest, opts ...grpc.CallOption) (Reporting_StreamReportResultsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Reporting_serviceDesc.Streams[0], c.cc, "/metering.reporting.v1.Reporting/StreamReportResults", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &reportingStreamReportResultsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Reporting_StreamReportResultsClient interface {
+	Recv() (*ReportResultsBatch, error)
+	grpc.ClientStream
+}
+
+type reportingStreamReportResultsClient struct {
+	grpc.ClientStream
+}
+
+func (x *reportingStreamReportResultsClient) Recv() (*ReportResultsBatch, error) {
+	m := new(ReportResultsBatch)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *reportingClient) ListReportDataSources(ctx context.Context, in *ListReportDataSourcesRequest, opts ...grpc.CallOption) (*ListReportDataSourcesResponse, error) {
+	out := new(ListReportDataSourcesResponse)
+	err := grpc.Invoke(ctx, "/metering.reporting.v1.Reporting/ListReportDataSources", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reportingClient) GetReportDataSource(ctx context.Context, in *GetReportDataSourceRequest, opts ...grpc.CallOption) (*ReportDataSource, error) {
+	out := new(ReportDataSource)
+	err := grpc.Invoke(ctx, "/metering.reporting.v1.Reporting/GetReportDataSource", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Reporting service
+
+type ReportingServer interface {
+	// ListReports returns every Report.
+	ListReports(context.Context, *ListReportsRequest) (*ListReportsResponse, error)
+	// GetReport returns a Report and its status.
+	GetReport(context.Context, *GetReportRequest) (*Report, error)
+	// CreateReport creates a Report, which runs once it's created.
+	CreateReport(context.Context, *CreateReportRequest) (*Report, error)
+	// DeleteReport deletes a Report and its results.
+	DeleteReport(context.Context, *DeleteReportRequest) (*DeleteReportResponse, error)
+	// ListScheduledReports returns every ScheduledReport.
+	ListScheduledReports(context.Context, *ListScheduledReportsRequest) (*ListScheduledReportsResponse, error)
+	// GetScheduledReport returns a ScheduledReport and its status.
+	GetScheduledReport(context.Context, *GetScheduledReportRequest) (*ScheduledReport, error)
+	// StreamReportResults streams the results of a finished Report, or of a
+	// ScheduledReport, in batches of rows as they're read from Presto.
+	StreamReportResults(*StreamReportResultsRequest, Reporting_StreamReportResultsServer) error
+	// ListReportDataSources returns every ReportDataSource.
+	ListReportDataSources(context.Context, *ListReportDataSourcesRequest) (*ListReportDataSourcesResponse, error)
+	// GetReportDataSource returns a ReportDataSource and its status.
+	GetReportDataSource(context.Context, *GetReportDataSourceRequest) (*ReportDataSource, error)
+}
+
+func RegisterReportingServer(s *grpc.Server, srv ReportingServer) {
+	s.RegisterService(&_Reporting_serviceDesc, srv)
+}
+
+func _Reporting_ListReports_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReportsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportingServer).ListReports(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/metering.reporting.v1.Reporting/ListReports",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportingServer).ListReports(ctx, req.(*ListReportsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Reporting_GetReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportingServer).GetReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/metering.reporting.v1.Reporting/GetReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportingServer).GetReport(ctx, req.(*GetReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Reporting_CreateReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportingServer).CreateReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/metering.reporting.v1.Reporting/CreateReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportingServer).CreateReport(ctx, req.(*CreateReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Reporting_DeleteReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportingServer).DeleteReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/metering.reporting.v1.Reporting/DeleteReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportingServer).DeleteReport(ctx, req.(*DeleteReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Reporting_ListScheduledReports_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListScheduledReportsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportingServer).ListScheduledReports(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/metering.reporting.v1.Reporting/ListScheduledReports",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportingServer).ListScheduledReports(ctx, req.(*ListScheduledReportsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Reporting_GetScheduledReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetScheduledReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportingServer).GetScheduledReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/metering.reporting.v1.Reporting/GetScheduledReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportingServer).GetScheduledReport(ctx, req.(*GetScheduledReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Reporting_StreamReportResults_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamReportResultsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ReportingServer).StreamReportResults(m, &reportingStreamReportResultsServer{stream})
+}
+
+type Reporting_StreamReportResultsServer interface {
+	Send(*ReportResultsBatch) error
+	grpc.ServerStream
+}
+
+type reportingStreamReportResultsServer struct {
+	grpc.ServerStream
+}
+
+func (x *reportingStreamReportResultsServer) Send(m *ReportResultsBatch) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Reporting_ListReportDataSources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReportDataSourcesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportingServer).ListReportDataSources(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/metering.reporting.v1.Reporting/ListReportDataSources",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportingServer).ListReportDataSources(ctx, req.(*ListReportDataSourcesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Reporting_GetReportDataSource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReportDataSourceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportingServer).GetReportDataSource(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/metering.reporting.v1.Reporting/GetReportDataSource",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportingServer).GetReportDataSource(ctx, req.(*GetReportDataSourceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Reporting_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "metering.reporting.v1.Reporting",
+	HandlerType: (*ReportingServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListReports",
+			Handler:    _Reporting_ListReports_Handler,
+		},
+		{
+			MethodName: "GetReport",
+			Handler:    _Reporting_GetReport_Handler,
+		},
+		{
+			MethodName: "CreateReport",
+			Handler:    _Reporting_CreateReport_Handler,
+		},
+		{
+			MethodName: "DeleteReport",
+			Handler:    _Reporting_DeleteReport_Handler,
+		},
+		{
+			MethodName: "ListScheduledReports",
+			Handler:    _Reporting_ListScheduledReports_Handler,
+		},
+		{
+			MethodName: "GetScheduledReport",
+			Handler:    _Reporting_GetScheduledReport_Handler,
+		},
+		{
+			MethodName: "ListReportDataSources",
+			Handler:    _Reporting_ListReportDataSources_Handler,
+		},
+		{
+			MethodName: "GetReportDataSource",
+			Handler:    _Reporting_GetReportDataSource_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamReportResults",
+			Handler:       _Reporting_StreamReportResults_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "reporting.proto",
+}
+
+func init() { proto.RegisterFile("reporting.proto", fileDescriptor_reporting_fc7ad5eb2cc698df) }
+
+var fileDescriptor_reporting_fc7ad5eb2cc698df = []byte{
+	// 1266 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x56, 0x6f, 0x6f, 0xdb, 0x44,
+	0x18, 0xaf, 0x9b, 0xc6, 0xa9, 0x9f, 0xa6, 0x4d, 0x76, 0xc9, 0x26, 0x63, 0x16, 0x56, 0x3c, 0x69,
+	0x6b, 0x07, 0xca, 0xba, 0x6e, 0x03, 0xa1, 0xbd, 0x00, 0xb5, 0x29, 0xeb, 0x44, 0x35, 0xe0, 0x32,
+	0x40, 0xf0, 0xc6, 0x72, 0xe2, 0x5b, 0x66, 0x70, 0xec, 0xcc, 0x77, 0x5e, 0x97, 0x49, 0x08, 0xb4,
+	0x2f, 0xc2, 0xc7, 0xe0, 0x7b, 0xf0, 0x86, 0x37, 0x48, 0xbc, 0xe7, 0x53, 0xa0, 0xfb, 0x63, 0x3b,
+	0x4d, 0xed, 0xa4, 0x43, 0x20, 0xf1, 0xee, 0xee, 0xb9, 0xdf, 0xf3, 0xff, 0xcf, 0x3d, 0xd0, 0x88,
+	0xc9, 0x24, 0x8a, 0x99, 0x1f, 0x8e, 0xba, 0x93, 0x38, 0x62, 0x11, 0xba, 0x3c, 0x26, 0x8c, 0xc4,
+	0xfc, 0x9e, 0xbf, 0xbc, 0xb8, 0x63, 0x5d, 0x1b, 0x45, 0xd1, 0x28, 0x20, 0xb7, 0x05, 0x68, 0x90,
+	0x3c, 0xbd, 0xcd, 0xfc, 0x31, 0xa1, 0xcc, 0x1d, 0x4f, 0x24, 0x9f, 0xfd, 0x01, 0xc0, 0xa3, 0x70,
+	0x92, 0xb0, 0xaf, 0xdd, 0x20, 0x21, 0x08, 0xc1, 0x5a, 0xe8, 0x8e, 0x89, 0xa9, 0x6d, 0x6b, 0x3b,
+	0x06, 0x16, 0x67, 0xd4, 0x86, 0xea, 0x0b, 0xfe, 0x68, 0xae, 0x0a, 0xa2, 0xbc, 0xd8, 0xbf, 0x57,
+	0x40, 0xc7, 0x42, 0x53, 0x21, 0xd3, 0xc7, 0xb0, 0x39, 0x8c, 0x89, 0xcb, 0xfc, 0x28, 0x74, 0xb8,
+	0x4a, 0xc1, 0xbc, 0xb1, 0x6f, 0x75, 0xa5, 0x3d, 0xdd, 0xd4, 0x9e, 0xee, 0x93, 0xd4, 0x1e, 0x5c,
+	0x4f, 0x19, 0x38, 0x09, 0xed, 0x42, 0x73, 0x44, 0x42, 0x12, 0x4b, 0x11, 0xcf, 0x13, 0x12, 0x4f,
+	0xcd, 0x8a, 0x50, 0xd0, 0xc8, 0xe9, 0x5f, 0x72, 0x32, 0x3a, 0x9c, 0x89, 0x86, 0x43, 0x99, 0x1b,
+	0x33, 0x73, 0x6d, 0xa9, 0xb6, 0xad, 0x8c, 0xa5, 0xcf, 0x39, 0xb8, 0xc1, 0xb9, 0x10, 0x12, 0x7a,
+	0x66, 0x75, 0xb9, 0xc1, 0x19, 0xc3, 0x51, 0xe8, 0xa1, 0x9b, 0xd0, 0x88, 0x93, 0xd0, 0xf1, 0xc7,
+	0x63, 0xe2, 0xf9, 0x2e, 0x23, 0xc1, 0xd4, 0xd4, 0xb7, 0xb5, 0x9d, 0x75, 0xbc, 0x15, 0x27, 0xe1,
+	0xa3, 0x9c, 0x8a, 0x3e, 0x02, 0xdd, 0xe7, 0x11, 0xa7, 0x66, 0x6d, 0xbb, 0xb2, 0xb3, 0xb1, 0xff,
+	0x6e, 0xb7, 0x30, 0x75, 0xdd, 0x3c, 0x2d, 0x58, 0x31, 0xa0, 0x1b, 0xd0, 0x18, 0xc5, 0x51, 0x32,
+	0x71, 0x06, 0x53, 0x27, 0x70, 0x07, 0x24, 0xa0, 0xe6, 0xfa, 0x76, 0x65, 0xc7, 0xc0, 0x9b, 0x82,
+	0x7c, 0x30, 0x3d, 0x11, 0x44, 0xf4, 0x00, 0x74, 0xca, 0x5c, 0x96, 0x50, 0xd3, 0x10, 0x5e, 0x5c,
+	0x2f, 0x51, 0x21, 0x13, 0xd8, 0x17, 0x50, 0xac, 0x58, 0xec, 0x9f, 0x35, 0xa8, 0xcf, 0x3e, 0xf0,
+	0x02, 0x98, 0x3c, 0x73, 0x69, 0x9a, 0x60, 0x79, 0x41, 0x57, 0x40, 0x8f, 0x12, 0x36, 0x49, 0x98,
+	0xaa, 0x0b, 0x75, 0x43, 0x07, 0xd0, 0x08, 0xc9, 0x4b, 0xe6, 0xc4, 0x84, 0xc5, 0x53, 0x99, 0xfb,
+	0xca, 0xd2, 0x50, 0x6e, 0x72, 0x16, 0xcc, 0x39, 0x38, 0xcd, 0x6e, 0x03, 0x3a, 0xf1, 0x29, 0x93,
+	0x56, 0x50, 0x4c, 0x9e, 0x27, 0x84, 0x32, 0xfb, 0x31, 0xb4, 0xce, 0x50, 0xe9, 0x24, 0x0a, 0x29,
+	0x41, 0x1f, 0x42, 0x4d, 0x3a, 0x45, 0x4d, 0x4d, 0x04, 0xb4, 0xb3, 0xd0, 0x5b, 0x9c, 0xa2, 0xed,
+	0x1b, 0xd0, 0x7c, 0x48, 0x94, 0x38, 0xa5, 0xa3, 0xa8, 0x96, 0xed, 0x13, 0x68, 0x1d, 0xf2, 0xd2,
+	0x24, 0x67, 0xa1, 0xf7, 0x41, 0x97, 0x92, 0x04, 0x78, 0xa9, 0x5a, 0x05, 0xb6, 0x77, 0xa1, 0xd5,
+	0x23, 0x01, 0x61, 0x64, 0xb9, 0xe2, 0x2b, 0xd0, 0x3e, 0x0b, 0x95, 0x1e, 0xdb, 0xbf, 0xad, 0x42,
+	0xa3, 0x3f, 0x7c, 0x46, 0xbc, 0x24, 0x20, 0xde, 0xff, 0xa4, 0x09, 0xaf, 0x80, 0x3e, 0x21, 0xb1,
+	0x1f, 0x79, 0xa2, 0xf7, 0x0c, 0xac, 0x6e, 0x33, 0xd5, 0x5e, 0xfd, 0x17, 0xaa, 0x5d, 0x2f, 0xaa,
+	0xf6, 0x5e, 0x56, 0xed, 0x35, 0xe1, 0xdf, 0xfb, 0x25, 0x2a, 0xe6, 0x42, 0x36, 0x57, 0xf6, 0xbf,
+	0x68, 0x70, 0xb9, 0x10, 0x81, 0x7a, 0xd0, 0x0c, 0x5c, 0xca, 0x2b, 0x9a, 0x13, 0x65, 0x24, 0xb5,
+	0xe5, 0x03, 0x86, 0xf3, 0x48, 0x39, 0x22, 0x96, 0x9f, 0x00, 0x0c, 0xa3, 0xd0, 0xf3, 0x79, 0xc8,
+	0xa8, 0xb9, 0x2a, 0x82, 0xb1, 0x5d, 0x62, 0xe9, 0x61, 0x0a, 0xc4, 0x33, 0x3c, 0xf6, 0xaf, 0x1a,
+	0x18, 0xd9, 0x0b, 0x4f, 0x38, 0x9b, 0x4e, 0xb2, 0x84, 0xf3, 0x33, 0x4f, 0x82, 0x8a, 0x84, 0xea,
+	0x49, 0x79, 0xe3, 0xf4, 0x98, 0xb8, 0x34, 0x0a, 0x55, 0xf6, 0xd4, 0x0d, 0x99, 0x50, 0x1b, 0x13,
+	0x4a, 0xdd, 0x11, 0x51, 0x59, 0x4b, 0xaf, 0xe8, 0x04, 0xda, 0xc2, 0x67, 0x16, 0xbb, 0x21, 0xf5,
+	0xf3, 0x0a, 0x5a, 0x3e, 0x15, 0x11, 0xe7, 0x7b, 0x92, 0xb1, 0x89, 0x7e, 0xee, 0xc0, 0xdb, 0xbc,
+	0x73, 0xe7, 0xc2, 0x9b, 0x35, 0x36, 0x85, 0xab, 0xc5, 0xcf, 0xaa, 0xc3, 0xfb, 0x70, 0x89, 0xa6,
+	0x6f, 0xce, 0xd9, 0x5e, 0xbf, 0x71, 0xb1, 0x5c, 0xe3, 0x26, 0x9d, 0x13, 0x6e, 0xdf, 0x86, 0xb7,
+	0x1e, 0x92, 0x79, 0x9d, 0x8b, 0xba, 0xf1, 0x4f, 0x0d, 0xac, 0x3e, 0x8b, 0x89, 0x3b, 0xce, 0xda,
+	0x31, 0x09, 0x32, 0x27, 0xd0, 0x7d, 0x58, 0xfb, 0xc1, 0x0f, 0x3d, 0xc1, 0xb2, 0x55, 0x5a, 0xe6,
+	0x92, 0xf5, 0x33, 0x3f, 0xf4, 0xb0, 0x80, 0x67, 0x9a, 0x56, 0x67, 0xfa, 0xd6, 0x84, 0xda, 0x30,
+	0x0a, 0x92, 0x71, 0x48, 0xcd, 0x8a, 0x28, 0xf8, 0xf4, 0xca, 0x5f, 0x9e, 0xfa, 0x01, 0x23, 0x31,
+	0x35, 0xd7, 0xe4, 0x8b, 0xba, 0xa2, 0x0e, 0xc0, 0xc0, 0x65, 0xc3, 0x67, 0x0e, 0xf5, 0x5f, 0xc9,
+	0x34, 0x55, 0xb1, 0x21, 0x28, 0x7d, 0xff, 0x15, 0x41, 0xd7, 0x61, 0xd3, 0x1f, 0x85, 0x51, 0x4c,
+	0x9c, 0xa7, 0xae, 0x1f, 0x10, 0x4f, 0xfd, 0x4d, 0x75, 0x49, 0xfc, 0x54, 0xd0, 0xec, 0x3d, 0xd0,
+	0x0f, 0x85, 0xa2, 0xc2, 0x69, 0x92, 0x16, 0xdc, 0x6a, 0x5e, 0x70, 0xf6, 0x8f, 0x80, 0xce, 0x04,
+	0xe3, 0x80, 0x2b, 0xe4, 0x13, 0x39, 0xb5, 0x7f, 0xf1, 0x44, 0x96, 0xda, 0x72, 0xf7, 0xba, 0xb0,
+	0x16, 0x47, 0xa7, 0x69, 0x77, 0x58, 0x65, 0x31, 0x8c, 0x4e, 0xb1, 0xc0, 0xd9, 0x0f, 0xa0, 0x82,
+	0xa3, 0x53, 0x74, 0x0f, 0x74, 0xb1, 0x94, 0xa4, 0xea, 0xae, 0x96, 0x30, 0xaa, 0xf1, 0x22, 0xb1,
+	0xf6, 0xeb, 0x55, 0xa8, 0x0a, 0x0a, 0xba, 0x0e, 0x75, 0xca, 0x38, 0xdc, 0x11, 0x4f, 0xd2, 0xeb,
+	0xe3, 0x15, 0xbc, 0x21, 0xa9, 0x12, 0xd4, 0x01, 0xc3, 0x0f, 0x99, 0x93, 0xaf, 0x42, 0x95, 0xe3,
+	0x15, 0xbc, 0xee, 0x87, 0x2c, 0x93, 0xe1, 0x45, 0xc9, 0x20, 0x20, 0x0a, 0xc1, 0x1b, 0x4d, 0xe3,
+	0x32, 0x24, 0x55, 0x82, 0xae, 0x01, 0x0c, 0xa2, 0x28, 0x50, 0x10, 0xde, 0x72, 0xeb, 0xc7, 0x2b,
+	0xd8, 0xe0, 0x34, 0x09, 0x38, 0x82, 0x46, 0xb6, 0xa0, 0x29, 0xd4, 0xd2, 0x8e, 0x3b, 0x5e, 0xc1,
+	0x5b, 0x19, 0x53, 0xa6, 0xe7, 0x7b, 0x1a, 0x85, 0x4a, 0x82, 0xae, 0xdc, 0x31, 0x38, 0x4d, 0x00,
+	0x0e, 0x6a, 0x6a, 0xa7, 0xb3, 0xff, 0xd0, 0xa0, 0x29, 0x33, 0xd8, 0x73, 0x99, 0xdb, 0x8f, 0x92,
+	0x78, 0x48, 0xfe, 0x9b, 0xbf, 0x24, 0x2d, 0x9f, 0xca, 0xcc, 0xbc, 0xea, 0x00, 0x30, 0x97, 0xc7,
+	0x4c, 0xa8, 0x93, 0x23, 0xc8, 0x10, 0x94, 0xc7, 0xee, 0xb9, 0x91, 0x59, 0xfd, 0x07, 0x23, 0xf3,
+	0x1d, 0x39, 0x59, 0xe6, 0x3d, 0xcc, 0x26, 0xcf, 0x4b, 0xe8, 0x94, 0xbc, 0xab, 0xd1, 0xf3, 0x0d,
+	0xb4, 0xd4, 0xd8, 0xf7, 0x5c, 0xe6, 0x3a, 0x54, 0x3e, 0xab, 0x3a, 0xbb, 0xb9, 0xb0, 0xc9, 0x73,
+	0x71, 0xf8, 0x52, 0x3c, 0xaf, 0xc0, 0xde, 0x03, 0x2b, 0x5b, 0x3e, 0x66, 0x90, 0xe5, 0xf3, 0xe7,
+	0x56, 0x17, 0x20, 0x9f, 0x1e, 0x08, 0x40, 0xc7, 0x47, 0x5f, 0x7c, 0x8e, 0x9f, 0x34, 0x57, 0x50,
+	0x1b, 0x9a, 0xfd, 0xc3, 0xe3, 0xa3, 0xde, 0x57, 0x27, 0x47, 0x3d, 0x47, 0x51, 0xb5, 0xfd, 0xbf,
+	0x6a, 0x60, 0xe0, 0xd4, 0x2c, 0xe4, 0xc1, 0x46, 0xee, 0x29, 0x45, 0xbb, 0x25, 0xa6, 0x9f, 0x5f,
+	0xbb, 0xac, 0x5b, 0x17, 0x81, 0x66, 0x93, 0xda, 0xc8, 0xbc, 0x42, 0x65, 0xe1, 0x99, 0x5f, 0xba,
+	0xac, 0xc5, 0x9b, 0x13, 0xfa, 0x16, 0xea, 0xb3, 0xfb, 0x17, 0x2a, 0x33, 0xa8, 0x60, 0x49, 0x5b,
+	0x26, 0x7a, 0x04, 0xf5, 0xd9, 0x0d, 0xab, 0x54, 0x74, 0xc1, 0xc6, 0x66, 0xbd, 0x77, 0x21, 0xac,
+	0x0a, 0xcc, 0x4f, 0xd0, 0x2e, 0xfa, 0xe2, 0xd0, 0xfe, 0x82, 0xe0, 0x96, 0x7c, 0x97, 0xd6, 0xdd,
+	0x37, 0xe2, 0x51, 0x06, 0x84, 0x80, 0xce, 0x7f, 0x77, 0x68, 0xaf, 0x3c, 0x45, 0xc5, 0x3f, 0xa3,
+	0x75, 0xc1, 0x0f, 0x17, 0x25, 0xd0, 0x2a, 0xf8, 0x2c, 0xd1, 0x9d, 0x32, 0xf6, 0xd2, 0x8f, 0xd5,
+	0xda, 0x5d, 0xbc, 0x57, 0xcf, 0x7c, 0x3c, 0x7b, 0x1a, 0x7a, 0xad, 0xc1, 0xe5, 0xc2, 0x8e, 0x46,
+	0x77, 0x97, 0x96, 0xf1, 0xf9, 0xf9, 0x60, 0xdd, 0x7b, 0x33, 0x26, 0x15, 0xeb, 0xe7, 0xd0, 0x2a,
+	0xe8, 0xed, 0x52, 0xdf, 0xcb, 0xe7, 0x80, 0x75, 0xd1, 0x09, 0x73, 0xb0, 0xf9, 0xdd, 0x46, 0x06,
+	0x98, 0x0c, 0x06, 0xba, 0x18, 0xc7, 0x77, 0xff, 0x1e, 0x00, 0xd4, 0xfc, 0x59, 0xfd, 0x27, 0x10,
+	0x00, 0x00,
+}
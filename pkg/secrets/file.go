@@ -0,0 +1,52 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// FileProviderName is the name of the file provider.
+const FileProviderName = "file"
+
+type fileProvider struct{}
+
+// NewFileProvider returns a Provider which reads secrets from a directory,
+// where each file in the directory is a key, and the file's contents are the
+// value. This is the layout used by Kubernetes Secret volumes, the Secrets
+// Store CSI driver and the Vault agent injector, making it suitable for
+// credentials synced from cloud secret managers. The path of a Ref is the
+// absolute path of the directory, eg: file:///etc/metering/presto.
+func NewFileProvider() Provider {
+	return fileProvider{}
+}
+
+func (fileProvider) Name() string {
+	return FileProviderName
+}
+
+func (fileProvider) GetSecret(_ context.Context, path string) (*Secret, error) {
+	if !filepath.IsAbs(path) {
+		path = "/" + path
+	}
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string]string)
+	for _, file := range files {
+		// Kubernetes secret volumes contain hidden directories and
+		// symlinks used for atomic updates, which we skip
+		if strings.HasPrefix(file.Name(), ".") || file.IsDir() {
+			continue
+		}
+		contents, err := ioutil.ReadFile(filepath.Join(path, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read secret file %s: %v", file.Name(), err)
+		}
+		data[file.Name()] = strings.TrimSpace(string(contents))
+	}
+	return &Secret{Data: data}, nil
+}
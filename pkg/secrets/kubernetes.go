@@ -0,0 +1,93 @@
+package secrets
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// KubernetesProviderName is the name of the Kubernetes Secret provider.
+const KubernetesProviderName = "kubernetes"
+
+type kubernetesProvider struct {
+	secretsGetter corev1.SecretsGetter
+	namespace     string
+}
+
+// NewKubernetesProvider returns a Provider which reads Kubernetes Secrets in
+// namespace. The path of a Ref is the name of the Secret.
+func NewKubernetesProvider(secretsGetter corev1.SecretsGetter, namespace string) Provider {
+	return &kubernetesProvider{secretsGetter: secretsGetter, namespace: namespace}
+}
+
+func (p *kubernetesProvider) Name() string {
+	return KubernetesProviderName
+}
+
+func (p *kubernetesProvider) GetSecret(_ context.Context, path string) (*Secret, error) {
+	secret, err := p.secretsGetter.Secrets(p.namespace).Get(path, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return kubernetesSecret(secret), nil
+}
+
+func kubernetesSecret(secret *v1.Secret) *Secret {
+	data := make(map[string]string, len(secret.Data))
+	for k, v := range secret.Data {
+		data[k] = string(v)
+	}
+	return &Secret{Data: data}
+}
+
+// NewKubernetesSecretInformer returns an informer watching the Secrets in
+// namespace, which updates the resolver's cached Kubernetes Secrets as soon
+// as they change, rather than once their cache TTL expires.
+func NewKubernetesSecretInformer(secretsGetter corev1.SecretsGetter, namespace string, resyncPeriod time.Duration, resolver *Resolver) cache.SharedIndexInformer {
+	informer := cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return secretsGetter.Secrets(namespace).List(options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return secretsGetter.Secrets(namespace).Watch(options)
+			},
+		},
+		&v1.Secret{},
+		resyncPeriod,
+		cache.Indexers{},
+	)
+	informer.AddEventHandler(KubernetesSecretEventHandler(resolver))
+	return informer
+}
+
+// KubernetesSecretEventHandler returns an event handler updating the
+// resolver's cached Kubernetes Secrets when they're created, updated or
+// deleted.
+func KubernetesSecretEventHandler(resolver *Resolver) cache.ResourceEventHandlerFuncs {
+	update := func(obj interface{}) {
+		if secret, ok := obj.(*v1.Secret); ok {
+			resolver.Update(Ref{Provider: KubernetesProviderName, Path: secret.Name}, kubernetesSecret(secret))
+		}
+	}
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: update,
+		UpdateFunc: func(_, current interface{}) {
+			update(current)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if secret, ok := obj.(*v1.Secret); ok {
+				resolver.Invalidate(Ref{Provider: KubernetesProviderName, Path: secret.Name})
+			}
+		},
+	}
+}
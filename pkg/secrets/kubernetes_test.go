@@ -0,0 +1,53 @@
+package secrets
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+)
+
+// fakeKubernetesProvider is a Provider named like the Kubernetes provider,
+// returning the data of a Secret.
+type fakeKubernetesProvider struct {
+	secret *v1.Secret
+}
+
+func (p *fakeKubernetesProvider) Name() string {
+	return KubernetesProviderName
+}
+
+func (p *fakeKubernetesProvider) GetSecret(ctx context.Context, path string) (*Secret, error) {
+	return kubernetesSecret(p.secret), nil
+}
+
+func TestKubernetesSecretEventHandler(t *testing.T) {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "presto-credentials", Namespace: "metering"},
+		Data:       map[string][]byte{UsernameKey: []byte("metering"), PasswordKey: []byte("first")},
+	}
+	ref := Ref{Provider: KubernetesProviderName, Path: "presto-credentials"}
+	fakeClock := clock.NewFakeClock(time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC))
+	resolver := NewResolver(logrus.New(), fakeClock, time.Hour, &fakeKubernetesProvider{secret: secret})
+	handler := KubernetesSecretEventHandler(resolver)
+
+	_, err := resolver.GetSecret(context.Background(), ref)
+	require.NoError(t, err)
+
+	rotated := secret.DeepCopy()
+	rotated.Data[PasswordKey] = []byte("second")
+	handler.OnUpdate(secret, rotated)
+	password, err := resolver.GetValue(context.Background(), ref, PasswordKey)
+	require.NoError(t, err)
+	assert.Equal(t, "second", password)
+
+	handler.OnDelete(cache.DeletedFinalStateUnknown{Key: "metering/presto-credentials", Obj: rotated})
+	assert.True(t, resolver.Expired(ref))
+}
@@ -0,0 +1,180 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// DefaultCacheTTL is how long secrets are cached for if their provider
+// doesn't specify a TTL.
+const DefaultCacheTTL = 5 * time.Minute
+
+// Resolver retrieves secrets from the Provider named by each Ref, caching
+// them until their TTL expires so that rotated credentials are picked up
+// the next time they're used. Secrets which are watched, such as Kubernetes
+// Secrets, are updated in the cache as soon as they change.
+type Resolver struct {
+	logger    logrus.FieldLogger
+	clock     clock.Clock
+	cacheTTL  time.Duration
+	providers map[string]Provider
+
+	cacheMu sync.Mutex
+	cache   map[Ref]cachedSecret
+
+	handlersMu sync.Mutex
+	handlers   map[Ref][]func()
+}
+
+type cachedSecret struct {
+	secret    *Secret
+	expiresAt time.Time
+}
+
+// NewResolver returns a Resolver for the providers given. If cacheTTL is
+// zero, DefaultCacheTTL is used.
+func NewResolver(logger logrus.FieldLogger, clock clock.Clock, cacheTTL time.Duration, providers ...Provider) *Resolver {
+	if cacheTTL == 0 {
+		cacheTTL = DefaultCacheTTL
+	}
+	r := &Resolver{
+		logger:    logger.WithField("component", "secretResolver"),
+		clock:     clock,
+		cacheTTL:  cacheTTL,
+		providers: make(map[string]Provider),
+		cache:     make(map[Ref]cachedSecret),
+		handlers:  make(map[Ref][]func()),
+	}
+	for _, provider := range providers {
+		r.providers[provider.Name()] = provider
+	}
+	return r
+}
+
+// GetSecret returns the secret for ref, from the cache if it hasn't expired.
+// If the secret has expired and cannot be refreshed, the expired secret is
+// returned so that a temporarily unavailable provider doesn't cause
+// failures, as long as the provider has returned the secret before.
+func (r *Resolver) GetSecret(ctx context.Context, ref Ref) (*Secret, error) {
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+
+	cached, exists := r.cache[ref]
+	now := r.clock.Now()
+	if exists && now.Before(cached.expiresAt) {
+		return cached.secret, nil
+	}
+
+	provider, ok := r.providers[ref.Provider]
+	if !ok {
+		return nil, fmt.Errorf("unknown secret provider %q for secret %s", ref.Provider, ref)
+	}
+	secret, err := provider.GetSecret(ctx, ref.Path)
+	if err != nil {
+		if exists {
+			r.logger.WithError(err).Warnf("unable to refresh secret %s, using previously retrieved value", ref)
+			return cached.secret, nil
+		}
+		return nil, fmt.Errorf("unable to get secret %s: %v", ref, err)
+	}
+
+	ttl := r.cacheTTL
+	if secret.TTL > 0 && secret.TTL < ttl {
+		ttl = secret.TTL
+	}
+	r.cache[ref] = cachedSecret{secret: secret, expiresAt: now.Add(ttl)}
+	return secret, nil
+}
+
+// GetValue returns the value of key within the secret for ref.
+func (r *Resolver) GetValue(ctx context.Context, ref Ref, key string) (string, error) {
+	secret, err := r.GetSecret(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	value, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("secret %s does not contain key %q", ref, key)
+	}
+	return value, nil
+}
+
+// Expired returns true if the cached secret for ref has expired or was never
+// retrieved.
+func (r *Resolver) Expired(ref Ref) bool {
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+	cached, exists := r.cache[ref]
+	return !exists || !r.clock.Now().Before(cached.expiresAt)
+}
+
+// Stale returns true if secret is no longer the cached secret for ref,
+// because it expired or was changed, and should be retrieved again.
+func (r *Resolver) Stale(ref Ref, secret *Secret) bool {
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+	cached, exists := r.cache[ref]
+	return !exists || !r.clock.Now().Before(cached.expiresAt) || cached.secret != secret
+}
+
+// OnChange registers fn to be called when the secret for ref is changed or
+// deleted, so that clients whose credentials can't be updated in place can
+// be rebuilt. fn is called synchronously by the watch observing the change,
+// so it must not block.
+func (r *Resolver) OnChange(ref Ref, fn func()) {
+	r.handlersMu.Lock()
+	defer r.handlersMu.Unlock()
+	r.handlers[ref] = append(r.handlers[ref], fn)
+}
+
+// Update replaces the cached secret for ref with secret, which a watch has
+// observed, calling the OnChange handlers for ref if its data changed.
+// Secrets which haven't been retrieved and have no handlers aren't cached.
+func (r *Resolver) Update(ref Ref, secret *Secret) {
+	r.cacheMu.Lock()
+	cached, exists := r.cache[ref]
+	changed := exists && !reflect.DeepEqual(cached.secret.Data, secret.Data)
+	if exists || r.hasHandlers(ref) {
+		r.cache[ref] = cachedSecret{secret: secret, expiresAt: r.clock.Now().Add(r.cacheTTL)}
+	}
+	r.cacheMu.Unlock()
+	if changed {
+		r.logger.Infof("secret %s changed", ref)
+		r.notify(ref)
+	}
+}
+
+// Invalidate removes the cached secret for ref, so that it's retrieved
+// again the next time it's used, calling the OnChange handlers for ref if
+// it was cached.
+func (r *Resolver) Invalidate(ref Ref) {
+	r.cacheMu.Lock()
+	_, exists := r.cache[ref]
+	delete(r.cache, ref)
+	r.cacheMu.Unlock()
+	if exists {
+		r.logger.Infof("secret %s was removed", ref)
+		r.notify(ref)
+	}
+}
+
+func (r *Resolver) hasHandlers(ref Ref) bool {
+	r.handlersMu.Lock()
+	defer r.handlersMu.Unlock()
+	return len(r.handlers[ref]) != 0
+}
+
+func (r *Resolver) notify(ref Ref) {
+	r.handlersMu.Lock()
+	handlers := append([]func(){}, r.handlers[ref]...)
+	r.handlersMu.Unlock()
+	for _, fn := range handlers {
+		fn()
+	}
+}
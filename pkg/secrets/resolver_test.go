@@ -0,0 +1,159 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+type fakeProvider struct {
+	secret *Secret
+	err    error
+	calls  int
+}
+
+func (p *fakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *fakeProvider) GetSecret(ctx context.Context, path string) (*Secret, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.secret, nil
+}
+
+func TestParseRef(t *testing.T) {
+	tests := map[string]struct {
+		ref         string
+		expectRef   Ref
+		expectError bool
+	}{
+		"kubernetes": {
+			ref:       "kubernetes://presto-credentials",
+			expectRef: Ref{Provider: "kubernetes", Path: "presto-credentials"},
+		},
+		"absolute file path": {
+			ref:       "file:///var/run/secrets/presto",
+			expectRef: Ref{Provider: "file", Path: "/var/run/secrets/presto"},
+		},
+		"missing provider": {
+			ref:         "presto-credentials",
+			expectError: true,
+		},
+		"missing path": {
+			ref:         "vault://",
+			expectError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ref, err := ParseRef(tt.ref)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectRef, ref)
+			assert.Equal(t, tt.ref, ref.String())
+		})
+	}
+}
+
+func TestResolverGetSecret(t *testing.T) {
+	ref := Ref{Provider: "fake", Path: "creds"}
+	provider := &fakeProvider{secret: &Secret{Data: map[string]string{TokenKey: "first"}}}
+	fakeClock := clock.NewFakeClock(time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC))
+	resolver := NewResolver(logrus.New(), fakeClock, time.Minute, provider)
+
+	assert.True(t, resolver.Expired(ref), "secret should be expired before it is retrieved")
+
+	token, err := resolver.GetValue(context.Background(), ref, TokenKey)
+	require.NoError(t, err)
+	assert.Equal(t, "first", token)
+	assert.False(t, resolver.Expired(ref))
+
+	// the secret is rotated, but the cached value is used until it expires
+	provider.secret = &Secret{Data: map[string]string{TokenKey: "second"}}
+	token, err = resolver.GetValue(context.Background(), ref, TokenKey)
+	require.NoError(t, err)
+	assert.Equal(t, "first", token)
+	assert.Equal(t, 1, provider.calls)
+
+	fakeClock.Step(time.Minute)
+	assert.True(t, resolver.Expired(ref))
+	token, err = resolver.GetValue(context.Background(), ref, TokenKey)
+	require.NoError(t, err)
+	assert.Equal(t, "second", token)
+	assert.Equal(t, 2, provider.calls)
+
+	// refresh failures continue to use the last retrieved value
+	provider.err = errors.New("provider unavailable")
+	fakeClock.Step(time.Minute)
+	token, err = resolver.GetValue(context.Background(), ref, TokenKey)
+	require.NoError(t, err)
+	assert.Equal(t, "second", token)
+
+	_, err = resolver.GetValue(context.Background(), ref, PasswordKey)
+	assert.Error(t, err, "missing keys should return an error")
+
+	_, err = resolver.GetSecret(context.Background(), Ref{Provider: "unknown", Path: "creds"})
+	assert.Error(t, err, "unknown providers should return an error")
+}
+
+func TestResolverSecretTTL(t *testing.T) {
+	ref := Ref{Provider: "fake", Path: "creds"}
+	provider := &fakeProvider{secret: &Secret{Data: map[string]string{TokenKey: "token"}, TTL: 30 * time.Second}}
+	fakeClock := clock.NewFakeClock(time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC))
+	resolver := NewResolver(logrus.New(), fakeClock, time.Minute, provider)
+
+	_, err := resolver.GetSecret(context.Background(), ref)
+	require.NoError(t, err)
+	fakeClock.Step(30 * time.Second)
+	assert.True(t, resolver.Expired(ref), "secret should expire after its TTL when shorter than the cache TTL")
+}
+
+func TestResolverWatchedSecrets(t *testing.T) {
+	ref := Ref{Provider: "fake", Path: "creds"}
+	provider := &fakeProvider{secret: &Secret{Data: map[string]string{TokenKey: "first"}}}
+	fakeClock := clock.NewFakeClock(time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC))
+	resolver := NewResolver(logrus.New(), fakeClock, time.Minute, provider)
+	changes := 0
+	resolver.OnChange(ref, func() { changes++ })
+
+	secret, err := resolver.GetSecret(context.Background(), ref)
+	require.NoError(t, err)
+
+	// watches observing the same data don't replace the cached secret
+	resolver.Update(ref, &Secret{Data: map[string]string{TokenKey: "first"}})
+	assert.Equal(t, 0, changes)
+
+	// rotated secrets are used immediately, without waiting for the cached
+	// secret to expire
+	resolver.Update(ref, &Secret{Data: map[string]string{TokenKey: "second"}})
+	assert.Equal(t, 1, changes)
+	assert.True(t, resolver.Stale(ref, secret))
+	token, err := resolver.GetValue(context.Background(), ref, TokenKey)
+	require.NoError(t, err)
+	assert.Equal(t, "second", token)
+	assert.Equal(t, 1, provider.calls)
+
+	// secrets which were never retrieved aren't cached
+	other := Ref{Provider: "fake", Path: "other"}
+	resolver.Update(other, &Secret{Data: map[string]string{TokenKey: "other"}})
+	assert.True(t, resolver.Expired(other))
+
+	resolver.Invalidate(ref)
+	assert.Equal(t, 2, changes)
+	assert.True(t, resolver.Expired(ref))
+}
@@ -0,0 +1,50 @@
+// Package secrets provides access to credentials stored in external secret
+// providers, such as Kubernetes Secrets, files mounted by a secrets store CSI
+// driver, or Vault, with caching so that rotated credentials are picked up
+// without restarting.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Secret is a set of key/value pairs returned by a Provider.
+type Secret struct {
+	Data map[string]string
+	// TTL, if non-zero, is how long the provider indicates the secret is
+	// valid for before it should be fetched again, such as a Vault lease
+	// duration.
+	TTL time.Duration
+}
+
+// Provider retrieves secrets from a secret store.
+type Provider interface {
+	// Name returns the name of the provider, which is used as the scheme of
+	// a Ref.
+	Name() string
+	// GetSecret returns the secret at path.
+	GetSecret(ctx context.Context, path string) (*Secret, error)
+}
+
+// Ref refers to a secret stored in a Provider, and is written as
+// "<provider>://<path>", for example "vault://secret/data/metering/presto".
+type Ref struct {
+	Provider string
+	Path     string
+}
+
+func (ref Ref) String() string {
+	return ref.Provider + "://" + ref.Path
+}
+
+// ParseRef parses a secret reference in the form "<provider>://<path>".
+func ParseRef(ref string) (Ref, error) {
+	parts := strings.SplitN(ref, "://", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return Ref{}, fmt.Errorf("invalid secret reference %q, must be in the form <provider>://<path>", ref)
+	}
+	return Ref{Provider: parts[0], Path: parts[1]}, nil
+}
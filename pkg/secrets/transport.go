@@ -0,0 +1,82 @@
+package secrets
+
+import (
+	"net/http"
+)
+
+// Keys within secrets used for credentials.
+const (
+	UsernameKey = "username"
+	PasswordKey = "password"
+	TokenKey    = "token"
+)
+
+// NewBasicAuthRoundTripper returns a RoundTripper which sets basic
+// authentication on every request using the username and password keys of
+// the secret, so that rotated credentials are used once the Resolver's cache
+// expires. If userHeader is set, the username is also set in that header.
+func NewBasicAuthRoundTripper(resolver *Resolver, ref Ref, userHeader string, rt http.RoundTripper) http.RoundTripper {
+	return &basicAuthRoundTripper{resolver: resolver, ref: ref, userHeader: userHeader, rt: defaultRoundTripper(rt)}
+}
+
+type basicAuthRoundTripper struct {
+	resolver   *Resolver
+	ref        Ref
+	userHeader string
+	rt         http.RoundTripper
+}
+
+func (rt *basicAuthRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	secret, err := rt.resolver.GetSecret(req.Context(), rt.ref)
+	if err != nil {
+		return nil, err
+	}
+	req = cloneRequest(req)
+	username := secret.Data[UsernameKey]
+	req.SetBasicAuth(username, secret.Data[PasswordKey])
+	if rt.userHeader != "" && username != "" {
+		req.Header.Set(rt.userHeader, username)
+	}
+	return rt.rt.RoundTrip(req)
+}
+
+// NewBearerTokenRoundTripper returns a RoundTripper which sets the
+// Authorization header on every request using the token key of the secret.
+func NewBearerTokenRoundTripper(resolver *Resolver, ref Ref, rt http.RoundTripper) http.RoundTripper {
+	return &bearerTokenRoundTripper{resolver: resolver, ref: ref, rt: defaultRoundTripper(rt)}
+}
+
+type bearerTokenRoundTripper struct {
+	resolver *Resolver
+	ref      Ref
+	rt       http.RoundTripper
+}
+
+func (rt *bearerTokenRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := rt.resolver.GetValue(req.Context(), rt.ref, TokenKey)
+	if err != nil {
+		return nil, err
+	}
+	req = cloneRequest(req)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return rt.rt.RoundTrip(req)
+}
+
+func defaultRoundTripper(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		return http.DefaultTransport
+	}
+	return rt
+}
+
+// cloneRequest returns a shallow copy of req with a copy of its headers, as
+// RoundTrippers must not modify the request they are given.
+func cloneRequest(req *http.Request) *http.Request {
+	clone := new(http.Request)
+	*clone = *req
+	clone.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		clone.Header[k] = append([]string(nil), v...)
+	}
+	return clone
+}
@@ -0,0 +1,101 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultProviderName is the name of the Vault provider.
+const VaultProviderName = "vault"
+
+// VaultConfig configures the Vault provider.
+type VaultConfig struct {
+	// Address is the URL of the Vault server.
+	Address string
+	// TokenFile is the path of a file containing the Vault token, which is
+	// re-read on every request so that it can be rotated, for example by
+	// the Vault agent.
+	TokenFile string
+}
+
+type vaultProvider struct {
+	cfg        VaultConfig
+	httpClient *http.Client
+}
+
+// NewVaultProvider returns a Provider which reads secrets from the Vault KV
+// secrets engine, supporting both version 1 and 2. The path of a Ref is the
+// API path of the secret, without the v1/ prefix, eg:
+// vault://secret/data/metering/presto.
+func NewVaultProvider(cfg VaultConfig, httpClient *http.Client) Provider {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &vaultProvider{cfg: cfg, httpClient: httpClient}
+}
+
+func (p *vaultProvider) Name() string {
+	return VaultProviderName
+}
+
+type vaultSecretResponse struct {
+	LeaseDuration int                    `json:"lease_duration"`
+	Data          map[string]interface{} `json:"data"`
+	Errors        []string               `json:"errors"`
+}
+
+func (p *vaultProvider) GetSecret(ctx context.Context, path string) (*Secret, error) {
+	token, err := ioutil.ReadFile(p.cfg.TokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read Vault token: %v", err)
+	}
+
+	url := strings.TrimSuffix(p.cfg.Address, "/") + "/v1/" + strings.TrimPrefix(path, "/")
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("X-Vault-Token", strings.TrimSpace(string(token)))
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result vaultSecretResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("unable to decode Vault response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Vault returned status code %d: %s", resp.StatusCode, strings.Join(result.Errors, ", "))
+	}
+
+	// the KV version 2 engine nests the secret's data within data.data
+	data := result.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = nested
+		}
+	}
+
+	secret := &Secret{
+		Data: make(map[string]string, len(data)),
+		TTL:  time.Duration(result.LeaseDuration) * time.Second,
+	}
+	for k, v := range data {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("Vault secret key %q is not a string", k)
+		}
+		secret.Data[k] = s
+	}
+	return secret, nil
+}
@@ -0,0 +1,74 @@
+package secrets
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultProviderGetSecret(t *testing.T) {
+	tests := map[string]struct {
+		path         string
+		response     string
+		expectSecret *Secret
+	}{
+		"kv version 1": {
+			path:     "secret/metering/presto",
+			response: `{"lease_duration": 3600, "data": {"username": "metering", "password": "hunter2"}}`,
+			expectSecret: &Secret{
+				Data: map[string]string{UsernameKey: "metering", PasswordKey: "hunter2"},
+				TTL:  time.Hour,
+			},
+		},
+		"kv version 2": {
+			path:     "secret/data/metering/presto",
+			response: `{"lease_duration": 0, "data": {"data": {"username": "metering", "password": "hunter2"}, "metadata": {"version": 2}}}`,
+			expectSecret: &Secret{
+				Data: map[string]string{UsernameKey: "metering", PasswordKey: "hunter2"},
+			},
+		},
+	}
+
+	dir, err := ioutil.TempDir("", "vault-provider-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("vault-token\n"), 0600))
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("X-Vault-Token") != "vault-token" {
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte(`{"errors": ["permission denied"]}`))
+					return
+				}
+				if r.URL.Path != "/v1/"+tt.path {
+					w.WriteHeader(http.StatusNotFound)
+					w.Write([]byte(`{"errors": []}`))
+					return
+				}
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			provider := NewVaultProvider(VaultConfig{Address: server.URL, TokenFile: tokenFile}, nil)
+			secret, err := provider.GetSecret(context.Background(), tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectSecret, secret)
+
+			_, err = provider.GetSecret(context.Background(), "secret/missing")
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package supportbundle
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+)
+
+const redacted = "REDACTED"
+
+// anonymizer replaces the names of ReportDataSources and tables created by
+// users with pseudonyms, which are consistent within a bundle so the
+// ReportDataSources, import ledgers and tables can still be correlated. The
+// pseudonyms are salted with a random salt which isn't included in the
+// bundle, so they can't be reversed by hashing likely names. A nil
+// anonymizer leaves everything unchanged.
+type anonymizer struct {
+	salt []byte
+	// knownDataSources and knownTables are the ReportDataSources installed
+	// by Metering and their tables, whose names are the same in every
+	// installation and are kept.
+	knownDataSources map[string]bool
+	knownTables      map[string]bool
+}
+
+func newAnonymizer() (*anonymizer, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return newAnonymizerWithSalt(salt), nil
+}
+
+func newAnonymizerWithSalt(salt []byte) *anonymizer {
+	return &anonymizer{
+		salt:             salt,
+		knownDataSources: make(map[string]bool),
+		knownTables:      make(map[string]bool),
+	}
+}
+
+func (a *anonymizer) addKnownDataSources(dataSources []*cbTypes.ReportDataSource) {
+	if a == nil {
+		return
+	}
+	for _, ds := range dataSources {
+		if ds.Labels[defaultResourceLabel] != "true" {
+			continue
+		}
+		a.knownDataSources[ds.Name] = true
+		if ds.TableName != "" {
+			a.knownTables[ds.TableName] = true
+		}
+	}
+}
+
+func (a *anonymizer) pseudonym(prefix, name string) string {
+	hash := sha256.New()
+	hash.Write(a.salt)
+	hash.Write([]byte(name))
+	return prefix + hex.EncodeToString(hash.Sum(nil))[:12]
+}
+
+func (a *anonymizer) dataSourceName(name string) string {
+	if a == nil || name == "" || a.knownDataSources[name] {
+		return name
+	}
+	return a.pseudonym("datasource-", name)
+}
+
+// dataSourceQuery anonymizes the name of the ReportPrometheusQuery used by
+// a ReportDataSource, unless the ReportDataSource was installed by
+// Metering.
+func (a *anonymizer) dataSourceQuery(dataSourceName, query string) string {
+	if a == nil || query == "" || a.knownDataSources[dataSourceName] {
+		return query
+	}
+	return a.pseudonym("query-", query)
+}
+
+func (a *anonymizer) tableName(name string) string {
+	if a == nil || name == "" {
+		return name
+	}
+	for known := range a.knownTables {
+		// tables derived from a ReportDataSource's table, such as its
+		// exemplars table, are also known.
+		if name == known || strings.HasPrefix(name, known+"_") {
+			return name
+		}
+	}
+	return a.pseudonym("table_", name)
+}
+
+// configValue redacts the reporting-operator configuration values which
+// identify hosts in the installation's environment.
+func (a *anonymizer) configValue(key, value string) string {
+	if a == nil || value == "" {
+		return value
+	}
+	for _, suffix := range []string{"-url", "-host", "-address"} {
+		if strings.HasSuffix(key, suffix) {
+			return redacted
+		}
+	}
+	return value
+}
+
+func (a *anonymizer) redact(value string) string {
+	if a == nil || value == "" {
+		return value
+	}
+	return redacted
+}
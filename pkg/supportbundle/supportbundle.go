@@ -0,0 +1,319 @@
+// Package supportbundle exports the configuration and state of a Metering
+// installation into an archive which can be shared to debug it, without
+// including the metrics or report results it contains.
+package supportbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	meteringv1alpha1 "github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/typed/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+)
+
+const (
+	DefaultImportHistory = 24 * time.Hour
+
+	reportingOperatorConfigName = "reporting-operator-config"
+	// defaultResourceLabel is the label set on the resources installed by
+	// the reporting-operator chart, whose names aren't anonymized.
+	defaultResourceLabel = "operator-metering"
+)
+
+type Config struct {
+	// Namespace is the namespace Metering is installed in.
+	Namespace string
+	// ImportHistory is how far back the import ledger of each Prometheus
+	// ReportDataSource goes.
+	ImportHistory time.Duration
+	// Anonymize replaces the names of resources and tables not installed by
+	// Metering with pseudonyms, and redacts hosts, URLs and S3 and GCS locations.
+	Anonymize bool
+}
+
+// Metadata describes how a support bundle was generated.
+type Metadata struct {
+	GeneratedAt   time.Time `json:"generatedAt"`
+	Namespace     string    `json:"namespace"`
+	ImportHistory string    `json:"importHistory"`
+	Anonymized    bool      `json:"anonymized"`
+}
+
+type DataSourceStatus struct {
+	Name       string                              `json:"name"`
+	TableName  string                              `json:"tableName"`
+	Spec       cbTypes.ReportDataSourceSpec        `json:"spec"`
+	Conditions []cbTypes.ReportDataSourceCondition `json:"conditions,omitempty"`
+}
+
+// ImportLedger is the number of metrics imported into the table of a
+// Prometheus ReportDataSource for each hour of the import history.
+type ImportLedger struct {
+	DataSource    string             `json:"dataSource"`
+	TableName     string             `json:"tableName"`
+	LastTimestamp *time.Time         `json:"lastTimestamp"`
+	Hours         []ImportLedgerHour `json:"hours"`
+	Error         string             `json:"error,omitempty"`
+}
+
+type ImportLedgerHour struct {
+	Hour    time.Time `json:"hour"`
+	Samples int64     `json:"samples"`
+}
+
+type TableSchema struct {
+	Name       string        `json:"name"`
+	Columns    []hive.Column `json:"columns"`
+	Partitions int           `json:"partitions"`
+	RowCount   *int64        `json:"rowCount"`
+	Error      string        `json:"error,omitempty"`
+}
+
+type bundleFile struct {
+	name    string
+	content interface{}
+}
+
+// Generator collects the contents of a support bundle.
+type Generator struct {
+	logger         log.FieldLogger
+	cfg            Config
+	clock          clock.Clock
+	configMaps     corev1.ConfigMapsGetter
+	meteringClient meteringv1alpha1.MeteringV1alpha1Interface
+	queryer        presto.Queryer
+	anonymizer     *anonymizer
+}
+
+func NewGenerator(logger log.FieldLogger, cfg Config, clock clock.Clock, configMaps corev1.ConfigMapsGetter, meteringClient meteringv1alpha1.MeteringV1alpha1Interface, queryer presto.Queryer) (*Generator, error) {
+	if cfg.ImportHistory <= 0 {
+		cfg.ImportHistory = DefaultImportHistory
+	}
+	g := &Generator{
+		logger:         logger,
+		cfg:            cfg,
+		clock:          clock,
+		configMaps:     configMaps,
+		meteringClient: meteringClient,
+		queryer:        queryer,
+	}
+	if cfg.Anonymize {
+		var err error
+		g.anonymizer, err = newAnonymizer()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// Write collects the support bundle and writes it to w as a gzipped tar
+// archive. Failing to collect part of the bundle doesn't fail the bundle,
+// the errors are written to errors.json instead, since a partial bundle is
+// still useful when debugging a broken installation.
+func (g *Generator) Write(w io.Writer) error {
+	var collectErrs []string
+	addErr := func(err error) {
+		g.logger.WithError(err).Warnf("unable to collect part of the support bundle")
+		collectErrs = append(collectErrs, err.Error())
+	}
+
+	files := []bundleFile{
+		{"metadata.json", Metadata{
+			GeneratedAt:   g.clock.Now().UTC(),
+			Namespace:     g.cfg.Namespace,
+			ImportHistory: g.cfg.ImportHistory.String(),
+			Anonymized:    g.cfg.Anonymize,
+		}},
+	}
+
+	config, err := g.collectConfig()
+	if err != nil {
+		addErr(err)
+	}
+	files = append(files, bundleFile{"config.json", config})
+
+	dataSources, err := g.meteringClient.ReportDataSources(g.cfg.Namespace).List(meta.ListOptions{})
+	if err != nil {
+		addErr(fmt.Errorf("unable to list ReportDataSources: %v", err))
+		dataSources = &cbTypes.ReportDataSourceList{}
+	}
+	sort.Slice(dataSources.Items, func(i, j int) bool { return dataSources.Items[i].Name < dataSources.Items[j].Name })
+	g.anonymizer.addKnownDataSources(dataSources.Items)
+
+	tables, err := g.meteringClient.PrestoTables(g.cfg.Namespace).List(meta.ListOptions{})
+	if err != nil {
+		addErr(fmt.Errorf("unable to list PrestoTables: %v", err))
+		tables = &cbTypes.PrestoTableList{}
+	}
+
+	if collectErrs == nil {
+		collectErrs = []string{}
+	}
+	files = append(files,
+		bundleFile{"datasources.json", g.collectDataSourceStatuses(dataSources.Items)},
+		bundleFile{"imports.json", g.collectImportLedgers(dataSources.Items)},
+		bundleFile{"tables.json", g.collectTableSchemas(tables.Items)},
+		bundleFile{"errors.json", collectErrs},
+	)
+
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+	modTime := g.clock.Now()
+	for _, file := range files {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.content); err != nil {
+			return fmt.Errorf("unable to encode %s: %v", file.name, err)
+		}
+		err := tw.WriteHeader(&tar.Header{
+			Name:    file.name,
+			Mode:    0644,
+			Size:    int64(buf.Len()),
+			ModTime: modTime,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := tw.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gzw.Close()
+}
+
+// collectConfig returns the reporting-operator's configuration. The
+// configuration only references secrets, so it contains no credentials, but
+// hosts and URLs are redacted when anonymizing.
+func (g *Generator) collectConfig() (map[string]string, error) {
+	configMap, err := g.configMaps.ConfigMaps(g.cfg.Namespace).Get(reportingOperatorConfigName, meta.GetOptions{})
+	if err != nil {
+		return map[string]string{}, fmt.Errorf("unable to get ConfigMap %s: %v", reportingOperatorConfigName, err)
+	}
+	config := make(map[string]string, len(configMap.Data))
+	for key, value := range configMap.Data {
+		config[key] = g.anonymizer.configValue(key, value)
+	}
+	return config, nil
+}
+
+func (g *Generator) collectDataSourceStatuses(dataSources []*cbTypes.ReportDataSource) []DataSourceStatus {
+	statuses := make([]DataSourceStatus, 0, len(dataSources))
+	for _, ds := range dataSources {
+		status := DataSourceStatus{
+			Name:       g.anonymizer.dataSourceName(ds.Name),
+			TableName:  g.anonymizer.tableName(ds.TableName),
+			Conditions: ds.Conditions,
+		}
+		spec := ds.Spec.DeepCopy()
+		if spec.Promsum != nil {
+			spec.Promsum.Query = g.anonymizer.dataSourceQuery(ds.Name, spec.Promsum.Query)
+		}
+		if spec.AWSBilling != nil && spec.AWSBilling.Source != nil {
+			spec.AWSBilling.Source.Bucket = g.anonymizer.redact(spec.AWSBilling.Source.Bucket)
+			spec.AWSBilling.Source.Prefix = g.anonymizer.redact(spec.AWSBilling.Source.Prefix)
+		}
+		if spec.GCPBilling != nil && spec.GCPBilling.Source != nil {
+			spec.GCPBilling.Source.Bucket = g.anonymizer.redact(spec.GCPBilling.Source.Bucket)
+			spec.GCPBilling.Source.Prefix = g.anonymizer.redact(spec.GCPBilling.Source.Prefix)
+		}
+		status.Spec = *spec
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// collectImportLedgers counts the metrics imported into the table of each
+// Prometheus ReportDataSource for each hour of the import history. Missing
+// or sparse hours show where imports failed or fell behind.
+func (g *Generator) collectImportLedgers(dataSources []*cbTypes.ReportDataSource) []ImportLedger {
+	start := g.clock.Now().UTC().Add(-g.cfg.ImportHistory).Truncate(time.Hour)
+	ledgers := []ImportLedger{}
+	for _, ds := range dataSources {
+		if ds.Spec.Promsum == nil || ds.TableName == "" {
+			continue
+		}
+		ledger := ImportLedger{
+			DataSource: g.anonymizer.dataSourceName(ds.Name),
+			TableName:  g.anonymizer.tableName(ds.TableName),
+			Hours:      []ImportLedgerHour{},
+		}
+		query := fmt.Sprintf(`SELECT date_trunc('hour', "timestamp") AS hour, count(*) AS samples, max("timestamp") AS last_timestamp FROM %s WHERE "timestamp" >= timestamp '%s' GROUP BY 1 ORDER BY 1`, ds.TableName, presto.Timestamp(start))
+		rows, err := g.queryer.Query(query)
+		if err != nil {
+			ledger.Error = fmt.Sprintf("unable to query table: %s", anonymizeError(err, ds.TableName, ledger.TableName))
+			ledgers = append(ledgers, ledger)
+			continue
+		}
+		for _, row := range rows {
+			hour, ok := row["hour"].(time.Time)
+			if !ok {
+				ledger.Error = fmt.Sprintf("invalid hour, valueType: %T", row["hour"])
+				break
+			}
+			samples, ok := row["samples"].(int64)
+			if !ok {
+				ledger.Error = fmt.Sprintf("invalid samples, valueType: %T", row["samples"])
+				break
+			}
+			if last, ok := row["last_timestamp"].(time.Time); ok && (ledger.LastTimestamp == nil || last.After(*ledger.LastTimestamp)) {
+				ledger.LastTimestamp = &last
+			}
+			ledger.Hours = append(ledger.Hours, ImportLedgerHour{Hour: hour.UTC(), Samples: samples})
+		}
+		ledgers = append(ledgers, ledger)
+	}
+	return ledgers
+}
+
+func (g *Generator) collectTableSchemas(tables []*cbTypes.PrestoTable) []TableSchema {
+	schemas := make([]TableSchema, 0, len(tables))
+	for _, table := range tables {
+		params := table.State.Parameters
+		schema := TableSchema{
+			Name:       g.anonymizer.tableName(params.Name),
+			Columns:    params.Columns,
+			Partitions: len(table.State.Partitions),
+		}
+		if schema.Columns == nil {
+			schema.Columns = []hive.Column{}
+		}
+		rows, err := g.queryer.Query(fmt.Sprintf("SELECT count(*) AS row_count FROM %s", params.Name))
+		if err != nil {
+			schema.Error = fmt.Sprintf("unable to count rows: %s", anonymizeError(err, params.Name, schema.Name))
+		} else if len(rows) != 1 {
+			schema.Error = fmt.Sprintf("expected 1 row counting rows, got %d", len(rows))
+		} else if count, ok := rows[0]["row_count"].(int64); ok {
+			schema.RowCount = &count
+		} else {
+			schema.Error = fmt.Sprintf("invalid row_count, valueType: %T", rows[0]["row_count"])
+		}
+		schemas = append(schemas, schema)
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Name < schemas[j].Name })
+	return schemas
+}
+
+// anonymizeError replaces the name of the table in an error from Presto with
+// its pseudonym.
+func anonymizeError(err error, tableName, anonymizedTableName string) string {
+	return strings.Replace(err.Error(), tableName, anonymizedTableName, -1)
+}
@@ -0,0 +1,242 @@
+package supportbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	cbTypes "github.com/operator-framework/operator-metering/pkg/apis/metering/v1alpha1"
+	"github.com/operator-framework/operator-metering/pkg/generated/clientset/versioned/fake"
+	"github.com/operator-framework/operator-metering/pkg/hive"
+	"github.com/operator-framework/operator-metering/pkg/presto"
+	"github.com/operator-framework/operator-metering/pkg/presto/mock"
+)
+
+// fakeConfigMaps returns the ConfigMap it contains from Get, the rest of
+// ConfigMapInterface is unused.
+type fakeConfigMaps struct {
+	corev1.ConfigMapInterface
+	configMap *v1.ConfigMap
+}
+
+func (f fakeConfigMaps) ConfigMaps(namespace string) corev1.ConfigMapInterface {
+	return f
+}
+
+func (f fakeConfigMaps) Get(name string, options meta.GetOptions) (*v1.ConfigMap, error) {
+	if f.configMap == nil || f.configMap.Name != name {
+		return nil, fmt.Errorf("configmap %s not found", name)
+	}
+	return f.configMap, nil
+}
+
+func readBundle(t *testing.T, r io.Reader) map[string][]byte {
+	gzr, err := gzip.NewReader(r)
+	require.NoError(t, err)
+	tr := tar.NewReader(gzr)
+	files := make(map[string][]byte)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		files[header.Name], err = ioutil.ReadAll(tr)
+		require.NoError(t, err)
+	}
+	return files
+}
+
+func TestGeneratorWrite(t *testing.T) {
+	const namespace = "metering"
+	now := time.Date(2018, time.June, 1, 12, 30, 0, 0, time.UTC)
+	hour := time.Date(2018, time.June, 1, 11, 0, 0, 0, time.UTC)
+
+	defaultDS := &cbTypes.ReportDataSource{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "pod-usage-cpu-cores",
+			Namespace: namespace,
+			Labels:    map[string]string{defaultResourceLabel: "true"},
+		},
+		Spec: cbTypes.ReportDataSourceSpec{
+			Promsum: &cbTypes.PrometheusMetricsDataSource{Query: "pod-usage-cpu-cores"},
+		},
+		TableName: "datasource_pod_usage_cpu_cores",
+	}
+	customDS := &cbTypes.ReportDataSource{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "acme-widgets",
+			Namespace: namespace,
+		},
+		Spec: cbTypes.ReportDataSourceSpec{
+			Promsum: &cbTypes.PrometheusMetricsDataSource{Query: "acme-widgets-query"},
+		},
+		TableName: "datasource_acme_widgets",
+	}
+	billingDS := &cbTypes.ReportDataSource{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "aws-billing",
+			Namespace: namespace,
+			Labels:    map[string]string{defaultResourceLabel: "true"},
+		},
+		Spec: cbTypes.ReportDataSourceSpec{
+			AWSBilling: &cbTypes.AWSBillingDataSource{
+				Source: &cbTypes.S3Bucket{Region: "us-east-1", Bucket: "acme-billing", Prefix: "reports"},
+			},
+		},
+		TableName: "datasource_aws_billing",
+	}
+	reportTable := &cbTypes.PrestoTable{
+		ObjectMeta: meta.ObjectMeta{Name: "report-acme-chargeback", Namespace: namespace},
+		State: cbTypes.PrestoTableState{
+			Parameters: cbTypes.TableParameters{
+				Name:    "report_acme_chargeback",
+				Columns: []hive.Column{{Name: "namespace", Type: "string"}},
+			},
+		},
+	}
+	configMap := &v1.ConfigMap{
+		ObjectMeta: meta.ObjectMeta{Name: reportingOperatorConfigName, Namespace: namespace},
+		Data: map[string]string{
+			"prometheus-url":  "http://prometheus.acme.internal:9090",
+			"presto-host":     "presto:8080",
+			"disable-promsum": "false",
+		},
+	}
+
+	tests := map[string]struct {
+		anonymize bool
+
+		expectedDataSourceNames []string
+		expectedTableNames      []string
+		expectedConfig          map[string]string
+		expectedBucket          string
+	}{
+		"not anonymized": {
+			expectedDataSourceNames: []string{"acme-widgets", "aws-billing", "pod-usage-cpu-cores"},
+			expectedTableNames:      []string{"report_acme_chargeback"},
+			expectedConfig:          configMap.Data,
+			expectedBucket:          "acme-billing",
+		},
+		"anonymized": {
+			anonymize:               true,
+			expectedDataSourceNames: []string{"datasource-", "aws-billing", "pod-usage-cpu-cores"},
+			expectedTableNames:      []string{"table_"},
+			expectedConfig: map[string]string{
+				"prometheus-url":  redacted,
+				"presto-host":     redacted,
+				"disable-promsum": "false",
+			},
+			expectedBucket: redacted,
+		},
+	}
+
+	for name, tt := range tests {
+		name := name
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			queryer := mockpresto.NewMockQueryer(ctrl)
+			queryer.EXPECT().Query(gomock.Any()).DoAndReturn(func(query string) ([]presto.Row, error) {
+				switch {
+				case strings.Contains(query, "datasource_pod_usage_cpu_cores"):
+					return []presto.Row{{"hour": hour, "samples": int64(60), "last_timestamp": hour.Add(59 * time.Minute)}}, nil
+				case strings.Contains(query, "datasource_acme_widgets"):
+					return nil, fmt.Errorf("Table hive.default.datasource_acme_widgets does not exist")
+				case strings.Contains(query, "report_acme_chargeback"):
+					return []presto.Row{{"row_count": int64(42)}}, nil
+				}
+				return nil, fmt.Errorf("unexpected query: %s", query)
+			}).AnyTimes()
+
+			meteringClient := fake.NewSimpleClientset(defaultDS, customDS, billingDS, reportTable)
+			generator, err := NewGenerator(log.New(), Config{Namespace: namespace, Anonymize: tt.anonymize}, clock.NewFakeClock(now), fakeConfigMaps{configMap: configMap}, meteringClient.MeteringV1alpha1(), queryer)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			require.NoError(t, generator.Write(&buf))
+			files := readBundle(t, &buf)
+			for _, file := range []string{"metadata.json", "config.json", "datasources.json", "imports.json", "tables.json", "errors.json"} {
+				assert.Contains(t, files, file)
+			}
+			if tt.anonymize {
+				for file, content := range files {
+					assert.NotContains(t, string(content), "acme", "%s should be anonymized", file)
+				}
+			}
+
+			var config map[string]string
+			require.NoError(t, json.Unmarshal(files["config.json"], &config))
+			assert.Equal(t, tt.expectedConfig, config)
+
+			var statuses []DataSourceStatus
+			require.NoError(t, json.Unmarshal(files["datasources.json"], &statuses))
+			require.Len(t, statuses, len(tt.expectedDataSourceNames))
+			for i, status := range statuses {
+				assert.True(t, strings.HasPrefix(status.Name, tt.expectedDataSourceNames[i]), "expected %s to start with %s", status.Name, tt.expectedDataSourceNames[i])
+				if status.Spec.AWSBilling != nil {
+					assert.Equal(t, tt.expectedBucket, status.Spec.AWSBilling.Source.Bucket)
+					assert.Equal(t, "us-east-1", status.Spec.AWSBilling.Source.Region)
+				}
+			}
+
+			var ledgers []ImportLedger
+			require.NoError(t, json.Unmarshal(files["imports.json"], &ledgers))
+			require.Len(t, ledgers, 2)
+			assert.NotEmpty(t, ledgers[0].Error)
+			assert.Empty(t, ledgers[0].Hours)
+			assert.Equal(t, "pod-usage-cpu-cores", ledgers[1].DataSource)
+			assert.Equal(t, "datasource_pod_usage_cpu_cores", ledgers[1].TableName)
+			assert.Equal(t, []ImportLedgerHour{{Hour: hour, Samples: 60}}, ledgers[1].Hours)
+			require.NotNil(t, ledgers[1].LastTimestamp)
+			assert.Equal(t, hour.Add(59*time.Minute), *ledgers[1].LastTimestamp)
+
+			var schemas []TableSchema
+			require.NoError(t, json.Unmarshal(files["tables.json"], &schemas))
+			require.Len(t, schemas, len(tt.expectedTableNames))
+			for i, schema := range schemas {
+				assert.True(t, strings.HasPrefix(schema.Name, tt.expectedTableNames[i]), "expected %s to start with %s", schema.Name, tt.expectedTableNames[i])
+				assert.Equal(t, reportTable.State.Parameters.Columns, schema.Columns)
+				require.NotNil(t, schema.RowCount)
+				assert.Equal(t, int64(42), *schema.RowCount)
+			}
+		})
+	}
+}
+
+func TestAnonymizerTableName(t *testing.T) {
+	a := newAnonymizerWithSalt([]byte("salt"))
+	a.addKnownDataSources([]*cbTypes.ReportDataSource{
+		{
+			ObjectMeta: meta.ObjectMeta{Name: "pod-usage-cpu-cores", Labels: map[string]string{defaultResourceLabel: "true"}},
+			TableName:  "datasource_pod_usage_cpu_cores",
+		},
+	})
+
+	assert.Equal(t, "datasource_pod_usage_cpu_cores", a.tableName("datasource_pod_usage_cpu_cores"))
+	assert.Equal(t, "datasource_pod_usage_cpu_cores_exemplars", a.tableName("datasource_pod_usage_cpu_cores_exemplars"))
+	anonymized := a.tableName("report_acme")
+	assert.NotContains(t, anonymized, "acme")
+	assert.Equal(t, anonymized, a.tableName("report_acme"), "pseudonyms should be consistent")
+	assert.NotEqual(t, anonymized, newAnonymizerWithSalt([]byte("other")).tableName("report_acme"), "pseudonyms should depend on the salt")
+
+	var nilAnonymizer *anonymizer
+	assert.Equal(t, "report_acme", nilAnonymizer.tableName("report_acme"))
+}
@@ -1,6 +1,7 @@
 package orderedmap
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"sort"
@@ -31,13 +32,16 @@ func New() *OrderedMap {
 	return &o
 }
 
+// NewFromMap returns an OrderedMap of m, ordered by its keys. Numbers are
+// stored as json.Numbers, so they're marshalled exactly as they were in m,
+// rather than being rounded to float64s.
 func NewFromMap(m map[string]interface{}) (*OrderedMap, error) {
 	om := New()
 	b, err := json.Marshal(m)
 	if err != nil {
 		return nil, err
 	}
-	err = om.UnmarshalJSON(b)
+	err = om.unmarshalJSON(b, true)
 	if err != nil {
 		return nil, err
 	}
@@ -79,8 +83,16 @@ func (o *OrderedMap) Keys() []string {
 }
 
 func (o *OrderedMap) UnmarshalJSON(b []byte) error {
+	return o.unmarshalJSON(b, false)
+}
+
+func (o *OrderedMap) unmarshalJSON(b []byte, useNumber bool) error {
 	m := map[string]interface{}{}
-	if err := json.Unmarshal(b, &m); err != nil {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	if useNumber {
+		decoder.UseNumber()
+	}
+	if err := decoder.Decode(&m); err != nil {
 		return err
 	}
 	s := string(b)
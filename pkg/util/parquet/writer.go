@@ -0,0 +1,475 @@
+// Package parquet implements a minimal writer for Apache Parquet files.
+//
+// Every column is optional, and is written uncompressed using the PLAIN
+// encoding, in a single data page per row group. This is enough for
+// exporting query results to be read by other tools, but the files are
+// larger than ones written by writers which support dictionary encoding
+// and compression.
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"time"
+
+	"git.apache.org/thrift.git/lib/go/thrift"
+)
+
+// DefaultRowGroupSize is the number of rows buffered before they are
+// written to the file as a row group.
+const DefaultRowGroupSize = 65536
+
+var magic = []byte("PAR1")
+
+// ColumnType is the type of a column's values.
+type ColumnType int
+
+const (
+	// String columns contain string values, stored as UTF-8 byte arrays.
+	String ColumnType = iota
+	// Int64 columns contain int64 values.
+	Int64
+	// Double columns contain float64 values.
+	Double
+	// Boolean columns contain bool values.
+	Boolean
+	// Timestamp columns contain time.Time values, stored as milliseconds
+	// since the Unix epoch.
+	Timestamp
+	// Decimal columns contain *big.Int values, which are the unscaled
+	// values of decimals with the column's Scale, stored as big-endian
+	// two's complement byte arrays.
+	Decimal
+)
+
+// Column describes a column of a Parquet file.
+type Column struct {
+	Name string
+	Type ColumnType
+	// Precision and Scale are the maximum number of digits, and the number
+	// of digits after the decimal point, of a Decimal column's values.
+	Precision int
+	Scale     int
+}
+
+// Parquet physical types, converted types, encodings and page types, as
+// defined by the Parquet format's Thrift definitions.
+const (
+	typeBoolean   = 0
+	typeInt64     = 2
+	typeDouble    = 5
+	typeByteArray = 6
+
+	convertedTypeUTF8            = 0
+	convertedTypeDecimal         = 5
+	convertedTypeTimestampMillis = 9
+
+	repetitionOptional = 1
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	codecUncompressed = 0
+
+	pageTypeData = 0
+)
+
+type columnChunk struct {
+	offset    int64
+	size      int64
+	numValues int64
+}
+
+type rowGroup struct {
+	chunks  []columnChunk
+	numRows int64
+	size    int64
+}
+
+// Writer writes rows to a Parquet file. Rows are buffered in memory until
+// RowGroupSize rows have been written, and then written as a row group.
+type Writer struct {
+	// RowGroupSize is the maximum number of rows in each row group.
+	RowGroupSize int
+
+	w         io.Writer
+	columns   []Column
+	offset    int64
+	started   bool
+	rows      [][]interface{}
+	rowGroups []rowGroup
+	numRows   int64
+}
+
+// NewWriter returns a Writer which writes a Parquet file with the columns
+// to w. Close must be called to finish writing the file.
+func NewWriter(w io.Writer, columns []Column) *Writer {
+	return &Writer{
+		RowGroupSize: DefaultRowGroupSize,
+		w:            w,
+		columns:      columns,
+	}
+}
+
+// Write writes a row. The row must have a value for each column, of the
+// column's type, or nil for NULL.
+func (w *Writer) Write(row []interface{}) error {
+	if len(row) != len(w.columns) {
+		return fmt.Errorf("got %d values, expected %d", len(row), len(w.columns))
+	}
+	for i, col := range w.columns {
+		if err := checkValue(col, row[i]); err != nil {
+			return err
+		}
+	}
+	if err := w.start(); err != nil {
+		return err
+	}
+	w.rows = append(w.rows, row)
+	if len(w.rows) >= w.RowGroupSize {
+		return w.flushRowGroup()
+	}
+	return nil
+}
+
+// Close writes any buffered rows and the file's footer. It doesn't close
+// the underlying writer.
+func (w *Writer) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if err := w.flushRowGroup(); err != nil {
+		return err
+	}
+	footer, err := w.fileMetadata()
+	if err != nil {
+		return err
+	}
+	if err := w.write(footer); err != nil {
+		return err
+	}
+	length := make([]byte, 4)
+	binary.LittleEndian.PutUint32(length, uint32(len(footer)))
+	if err := w.write(length); err != nil {
+		return err
+	}
+	return w.write(magic)
+}
+
+func (w *Writer) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	return w.write(magic)
+}
+
+func (w *Writer) write(b []byte) error {
+	n, err := w.w.Write(b)
+	w.offset += int64(n)
+	return err
+}
+
+func (w *Writer) flushRowGroup() error {
+	if len(w.rows) == 0 {
+		return nil
+	}
+	group := rowGroup{numRows: int64(len(w.rows))}
+	for i, col := range w.columns {
+		page, err := w.dataPage(i, col)
+		if err != nil {
+			return err
+		}
+		chunk := columnChunk{
+			offset:    w.offset,
+			size:      int64(len(page)),
+			numValues: int64(len(w.rows)),
+		}
+		if err := w.write(page); err != nil {
+			return err
+		}
+		group.chunks = append(group.chunks, chunk)
+		group.size += chunk.size
+	}
+	w.rowGroups = append(w.rowGroups, group)
+	w.numRows += group.numRows
+	w.rows = w.rows[:0]
+	return nil
+}
+
+// dataPage encodes the values of a column in the buffered rows as a data
+// page, including its header.
+func (w *Writer) dataPage(column int, col Column) ([]byte, error) {
+	defined := make([]bool, len(w.rows))
+	var values bytes.Buffer
+	var bools []bool
+	for i, row := range w.rows {
+		value := row[column]
+		if value == nil {
+			continue
+		}
+		defined[i] = true
+		switch v := value.(type) {
+		case string:
+			binary.Write(&values, binary.LittleEndian, uint32(len(v)))
+			values.WriteString(v)
+		case int64:
+			binary.Write(&values, binary.LittleEndian, v)
+		case float64:
+			binary.Write(&values, binary.LittleEndian, math.Float64bits(v))
+		case bool:
+			bools = append(bools, v)
+		case time.Time:
+			binary.Write(&values, binary.LittleEndian, v.UnixNano()/int64(time.Millisecond))
+		case *big.Int:
+			b := twosComplement(v)
+			binary.Write(&values, binary.LittleEndian, uint32(len(b)))
+			values.Write(b)
+		}
+	}
+	if col.Type == Boolean {
+		values.Write(packBits(bools))
+	}
+
+	var data bytes.Buffer
+	levels := encodeDefinitionLevels(defined)
+	binary.Write(&data, binary.LittleEndian, uint32(len(levels)))
+	data.Write(levels)
+	data.Write(values.Bytes())
+
+	enc := newThriftEncoder()
+	enc.structBegin()
+	enc.i32(1, pageTypeData)
+	enc.i32(2, int32(data.Len()))
+	enc.i32(3, int32(data.Len()))
+	enc.structField(5, func() {
+		enc.i32(1, int32(len(w.rows)))
+		enc.i32(2, encodingPlain)
+		enc.i32(3, encodingRLE)
+		enc.i32(4, encodingRLE)
+	})
+	enc.structEnd()
+	header, err := enc.bytes()
+	if err != nil {
+		return nil, err
+	}
+	return append(header, data.Bytes()...), nil
+}
+
+func (w *Writer) fileMetadata() ([]byte, error) {
+	enc := newThriftEncoder()
+	enc.structBegin()
+	enc.i32(1, 1)
+	enc.structList(2, len(w.columns)+1, func(i int) {
+		if i == 0 {
+			enc.str(4, "schema")
+			enc.i32(5, int32(len(w.columns)))
+			return
+		}
+		col := w.columns[i-1]
+		physicalType, convertedType := parquetTypes(col.Type)
+		enc.i32(1, physicalType)
+		enc.i32(3, repetitionOptional)
+		enc.str(4, col.Name)
+		if convertedType >= 0 {
+			enc.i32(6, convertedType)
+		}
+		if col.Type == Decimal {
+			enc.i32(7, int32(col.Scale))
+			enc.i32(8, int32(col.Precision))
+		}
+	})
+	enc.i64(3, w.numRows)
+	enc.structList(4, len(w.rowGroups), func(i int) {
+		group := w.rowGroups[i]
+		enc.structList(1, len(group.chunks), func(j int) {
+			chunk := group.chunks[j]
+			col := w.columns[j]
+			physicalType, _ := parquetTypes(col.Type)
+			enc.i64(2, chunk.offset)
+			enc.structField(3, func() {
+				enc.i32(1, physicalType)
+				enc.i32List(2, []int32{encodingPlain, encodingRLE})
+				enc.strList(3, []string{col.Name})
+				enc.i32(4, codecUncompressed)
+				enc.i64(5, chunk.numValues)
+				enc.i64(6, chunk.size)
+				enc.i64(7, chunk.size)
+				enc.i64(9, chunk.offset)
+			})
+		})
+		enc.i64(2, group.size)
+		enc.i64(3, group.numRows)
+	})
+	enc.str(6, "operator-metering")
+	enc.structEnd()
+	return enc.bytes()
+}
+
+// parquetTypes returns the physical type of a column type, and its
+// converted type, or -1 if it has none.
+func parquetTypes(colType ColumnType) (int32, int32) {
+	switch colType {
+	case Int64:
+		return typeInt64, -1
+	case Double:
+		return typeDouble, -1
+	case Boolean:
+		return typeBoolean, -1
+	case Timestamp:
+		return typeInt64, convertedTypeTimestampMillis
+	case Decimal:
+		return typeByteArray, convertedTypeDecimal
+	default:
+		return typeByteArray, convertedTypeUTF8
+	}
+}
+
+func checkValue(col Column, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	var ok bool
+	switch col.Type {
+	case String:
+		_, ok = value.(string)
+	case Int64:
+		_, ok = value.(int64)
+	case Double:
+		_, ok = value.(float64)
+	case Boolean:
+		_, ok = value.(bool)
+	case Timestamp:
+		_, ok = value.(time.Time)
+	case Decimal:
+		_, ok = value.(*big.Int)
+	}
+	if !ok {
+		return fmt.Errorf("column %q: unexpected value type %T", col.Name, value)
+	}
+	return nil
+}
+
+// twosComplement returns the big-endian two's complement representation of
+// n, with enough bytes for its sign.
+func twosComplement(n *big.Int) []byte {
+	size := n.BitLen()/8 + 1
+	v := new(big.Int).Set(n)
+	if v.Sign() < 0 {
+		v.Add(v, new(big.Int).Lsh(big.NewInt(1), uint(size*8)))
+	}
+	b := v.Bytes()
+	return append(make([]byte, size-len(b)), b...)
+}
+
+// encodeDefinitionLevels encodes the definition levels of an optional
+// column's values using the RLE/bit-packing hybrid encoding, with a single
+// bit-packed run.
+func encodeDefinitionLevels(defined []bool) []byte {
+	groups := (len(defined) + 7) / 8
+	header := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(header, uint64(groups)<<1|1)
+	return append(header[:n], packBits(defined)...)
+}
+
+// packBits packs the values into bytes, starting with the least
+// significant bit.
+func packBits(values []bool) []byte {
+	packed := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if v {
+			packed[i/8] |= 1 << uint(i%8)
+		}
+	}
+	return packed
+}
+
+// thriftEncoder encodes the Parquet format's Thrift structures using the
+// compact protocol. The first error encountered is returned by bytes.
+type thriftEncoder struct {
+	buf   *thrift.TMemoryBuffer
+	proto *thrift.TCompactProtocol
+	err   error
+}
+
+func newThriftEncoder() *thriftEncoder {
+	buf := thrift.NewTMemoryBuffer()
+	return &thriftEncoder{buf: buf, proto: thrift.NewTCompactProtocol(buf)}
+}
+
+func (e *thriftEncoder) check(err error) {
+	if e.err == nil && err != nil {
+		e.err = err
+	}
+}
+
+func (e *thriftEncoder) structBegin() {
+	e.check(e.proto.WriteStructBegin(""))
+}
+
+func (e *thriftEncoder) structEnd() {
+	e.check(e.proto.WriteFieldStop())
+	e.check(e.proto.WriteStructEnd())
+}
+
+func (e *thriftEncoder) i32(id int16, v int32) {
+	e.check(e.proto.WriteFieldBegin("", thrift.I32, id))
+	e.check(e.proto.WriteI32(v))
+}
+
+func (e *thriftEncoder) i64(id int16, v int64) {
+	e.check(e.proto.WriteFieldBegin("", thrift.I64, id))
+	e.check(e.proto.WriteI64(v))
+}
+
+func (e *thriftEncoder) str(id int16, v string) {
+	e.check(e.proto.WriteFieldBegin("", thrift.STRING, id))
+	e.check(e.proto.WriteString(v))
+}
+
+func (e *thriftEncoder) i32List(id int16, values []int32) {
+	e.check(e.proto.WriteFieldBegin("", thrift.LIST, id))
+	e.check(e.proto.WriteListBegin(thrift.I32, len(values)))
+	for _, v := range values {
+		e.check(e.proto.WriteI32(v))
+	}
+}
+
+func (e *thriftEncoder) strList(id int16, values []string) {
+	e.check(e.proto.WriteFieldBegin("", thrift.LIST, id))
+	e.check(e.proto.WriteListBegin(thrift.STRING, len(values)))
+	for _, v := range values {
+		e.check(e.proto.WriteString(v))
+	}
+}
+
+// structField writes a struct field, whose fields are written by fn.
+func (e *thriftEncoder) structField(id int16, fn func()) {
+	e.check(e.proto.WriteFieldBegin("", thrift.STRUCT, id))
+	e.structBegin()
+	fn()
+	e.structEnd()
+}
+
+// structList writes a list of n structs, where fn writes the fields of the
+// i'th struct.
+func (e *thriftEncoder) structList(id int16, n int, fn func(i int)) {
+	e.check(e.proto.WriteFieldBegin("", thrift.LIST, id))
+	e.check(e.proto.WriteListBegin(thrift.STRUCT, n))
+	for i := 0; i < n; i++ {
+		e.structBegin()
+		fn(i)
+		e.structEnd()
+	}
+}
+
+func (e *thriftEncoder) bytes() ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.buf.Bytes(), nil
+}
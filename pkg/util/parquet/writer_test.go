@@ -0,0 +1,166 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"testing"
+	"time"
+
+	"git.apache.org/thrift.git/lib/go/thrift"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readThriftStruct decodes a compact protocol encoded struct into a map of
+// field IDs to values, where structs are decoded as maps and lists as
+// slices.
+func readThriftStruct(t *testing.T, proto thrift.TProtocol) map[int16]interface{} {
+	_, err := proto.ReadStructBegin()
+	require.NoError(t, err)
+	fields := make(map[int16]interface{})
+	for {
+		_, fieldType, id, err := proto.ReadFieldBegin()
+		require.NoError(t, err)
+		if fieldType == thrift.STOP {
+			break
+		}
+		fields[id] = readThriftValue(t, proto, fieldType)
+		require.NoError(t, proto.ReadFieldEnd())
+	}
+	require.NoError(t, proto.ReadStructEnd())
+	return fields
+}
+
+func readThriftValue(t *testing.T, proto thrift.TProtocol, fieldType thrift.TType) interface{} {
+	switch fieldType {
+	case thrift.I32:
+		v, err := proto.ReadI32()
+		require.NoError(t, err)
+		return v
+	case thrift.I64:
+		v, err := proto.ReadI64()
+		require.NoError(t, err)
+		return v
+	case thrift.STRING:
+		v, err := proto.ReadString()
+		require.NoError(t, err)
+		return v
+	case thrift.STRUCT:
+		return readThriftStruct(t, proto)
+	case thrift.LIST:
+		elemType, size, err := proto.ReadListBegin()
+		require.NoError(t, err)
+		values := make([]interface{}, size)
+		for i := range values {
+			values[i] = readThriftValue(t, proto, elemType)
+		}
+		require.NoError(t, proto.ReadListEnd())
+		return values
+	}
+	t.Fatalf("unexpected thrift type %v", fieldType)
+	return nil
+}
+
+func TestWriter(t *testing.T) {
+	columns := []Column{
+		{Name: "namespace", Type: String},
+		{Name: "pods", Type: Int64},
+		{Name: "amount", Type: Double},
+		{Name: "billable", Type: Boolean},
+		{Name: "period_start", Type: Timestamp},
+	}
+	periodStart := time.Date(2018, time.July, 1, 0, 0, 0, 0, time.UTC)
+	rows := [][]interface{}{
+		{"team-a", int64(3), 1.5, true, periodStart},
+		{"team-b", nil, 2.5, false, nil},
+		{nil, int64(1), nil, true, periodStart},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, columns)
+	w.RowGroupSize = 2
+	for _, row := range rows {
+		require.NoError(t, w.Write(row))
+	}
+	assert.Error(t, w.Write([]interface{}{"team-c"}), "rows must have a value for each column")
+	assert.Error(t, w.Write([]interface{}{"team-c", "3", nil, nil, nil}), "values must have the column's type")
+	require.NoError(t, w.Close())
+
+	file := buf.Bytes()
+	require.True(t, len(file) > 12)
+	assert.Equal(t, magic, file[:4])
+	assert.Equal(t, magic, file[len(file)-4:])
+	footerLength := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footer := file[len(file)-8-footerLength : len(file)-8]
+
+	metadata := readThriftStruct(t, thrift.NewTCompactProtocol(thrift.NewStreamTransportR(bytes.NewReader(footer))))
+	assert.Equal(t, int64(3), metadata[3], "expected num_rows to be the number of rows written")
+	schema := metadata[2].([]interface{})
+	require.Len(t, schema, len(columns)+1)
+	assert.Equal(t, int32(len(columns)), schema[0].(map[int16]interface{})[5])
+	for i, col := range columns {
+		element := schema[i+1].(map[int16]interface{})
+		assert.Equal(t, col.Name, element[4])
+		assert.Equal(t, int32(repetitionOptional), element[3])
+	}
+	assert.Equal(t, int32(convertedTypeTimestampMillis), schema[5].(map[int16]interface{})[6])
+
+	rowGroups := metadata[4].([]interface{})
+	require.Len(t, rowGroups, 2, "expected a row group for every 2 rows")
+	assert.Equal(t, int64(2), rowGroups[0].(map[int16]interface{})[3])
+	assert.Equal(t, int64(1), rowGroups[1].(map[int16]interface{})[3])
+
+	// decode the pods column of the first row group
+	chunk := rowGroups[0].(map[int16]interface{})[1].([]interface{})[1].(map[int16]interface{})
+	offset := chunk[2].(int64)
+	pageBuf := thrift.NewTMemoryBuffer()
+	pageBuf.Write(file[offset:])
+	header := readThriftStruct(t, thrift.NewTCompactProtocol(pageBuf))
+	dataPageHeader := header[5].(map[int16]interface{})
+	assert.Equal(t, int32(2), dataPageHeader[1], "expected num_values to be the number of rows")
+	page := pageBuf.Bytes()[:header[3].(int32)]
+	levelsLength := binary.LittleEndian.Uint32(page)
+	// one bit-packed group, where only the first value is defined
+	assert.Equal(t, []byte{1<<1 | 1, 0x01}, page[4:4+levelsLength])
+	assert.Equal(t, int64(3), int64(binary.LittleEndian.Uint64(page[4+levelsLength:])))
+}
+
+func TestWriterEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, []Column{{Name: "amount", Type: Double}})
+	require.NoError(t, w.Close())
+	file := buf.Bytes()
+	assert.Equal(t, magic, file[:4])
+	assert.Equal(t, magic, file[len(file)-4:])
+}
+
+func TestWriterDecimal(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, []Column{{Name: "cost", Type: Decimal, Precision: 38, Scale: 12}})
+	require.NoError(t, w.Write([]interface{}{big.NewInt(1234)}))
+	assert.Error(t, w.Write([]interface{}{1.234}), "values must have the column's type")
+	require.NoError(t, w.Close())
+
+	file := buf.Bytes()
+	footerLength := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footer := file[len(file)-8-footerLength : len(file)-8]
+	metadata := readThriftStruct(t, thrift.NewTCompactProtocol(thrift.NewStreamTransportR(bytes.NewReader(footer))))
+	element := metadata[2].([]interface{})[1].(map[int16]interface{})
+	assert.Equal(t, int32(typeByteArray), element[1])
+	assert.Equal(t, int32(convertedTypeDecimal), element[6])
+	assert.Equal(t, int32(12), element[7], "expected the scale")
+	assert.Equal(t, int32(38), element[8], "expected the precision")
+}
+
+func TestTwosComplement(t *testing.T) {
+	assert.Equal(t, []byte{0x00}, twosComplement(big.NewInt(0)))
+	assert.Equal(t, []byte{0x04, 0xd2}, twosComplement(big.NewInt(1234)))
+	assert.Equal(t, []byte{0x00, 0x80}, twosComplement(big.NewInt(128)))
+	assert.Equal(t, []byte{0xff, 0x80}, twosComplement(big.NewInt(-128)))
+	assert.Equal(t, []byte{0xfb, 0x2e}, twosComplement(big.NewInt(-1234)))
+}
+
+func TestPackBits(t *testing.T) {
+	assert.Equal(t, []byte{0x05, 0x01}, packBits([]bool{true, false, true, false, false, false, false, false, true}))
+}
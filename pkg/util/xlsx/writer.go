@@ -0,0 +1,214 @@
+// Package xlsx implements a minimal streaming writer for Excel workbooks in
+// the Office Open XML (.xlsx) format, containing a single worksheet.
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxRows is the maximum number of rows in an Excel worksheet.
+const MaxRows = 1048576
+
+const (
+	contentTypesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`
+
+	relsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+	workbookRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`
+
+	// stylesXML contains the default cell style, and a style for dates,
+	// which is style 1.
+	stylesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts><fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs><cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles></styleSheet>`
+
+	worksheetStartXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	worksheetEndXML = `</sheetData></worksheet>`
+
+	dateStyle = 1
+)
+
+// excelEpoch is the time Excel's date serial numbers count days from.
+var excelEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// Writer writes rows to the worksheet of an Excel workbook as they're
+// written, without buffering them.
+type Writer struct {
+	sheetName string
+	zip       *zip.Writer
+	sheet     *bufio.Writer
+	started   bool
+	rows      int
+}
+
+// NewWriter returns a Writer which writes a workbook to w, with a single
+// worksheet named sheetName. Close must be called to finish writing the
+// workbook.
+func NewWriter(w io.Writer, sheetName string) *Writer {
+	return &Writer{
+		sheetName: sheetName,
+		zip:       zip.NewWriter(w),
+	}
+}
+
+func (w *Writer) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	var workbook bytes.Buffer
+	workbook.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="`)
+	if err := xml.EscapeText(&workbook, []byte(w.sheetName)); err != nil {
+		return err
+	}
+	workbook.WriteString(`" sheetId="1" r:id="rId1"/></sheets></workbook>`)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypesXML},
+		{"_rels/.rels", relsXML},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXML},
+		{"xl/styles.xml", stylesXML},
+	}
+	for _, file := range files {
+		f, err := w.zip.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, file.content); err != nil {
+			return err
+		}
+	}
+	sheet, err := w.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	w.sheet = bufio.NewWriter(sheet)
+	_, err = w.sheet.WriteString(worksheetStartXML)
+	return err
+}
+
+// Write writes a row. Values may be strings, integers, floats,
+// json.Numbers, which are written exactly as they're formatted, such as
+// decimals with more digits than a float64 has, bools, time.Times, which are
+// written as dates, or nil for an empty cell.
+func (w *Writer) Write(row []interface{}) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if w.rows >= MaxRows {
+		return fmt.Errorf("worksheets can't have more than %d rows", MaxRows)
+	}
+	for i, value := range row {
+		switch value.(type) {
+		case nil, string, int64, int, float64, bool, time.Time:
+		case json.Number:
+			if _, err := strconv.ParseFloat(string(value.(json.Number)), 64); err != nil {
+				return fmt.Errorf("column %d: invalid number %q", i, value)
+			}
+		default:
+			return fmt.Errorf("column %d: unsupported value type %T", i, value)
+		}
+	}
+	w.rows++
+	fmt.Fprintf(w.sheet, `<row r="%d">`, w.rows)
+	for i, value := range row {
+		if value == nil {
+			continue
+		}
+		ref := cellReference(i, w.rows)
+		switch v := value.(type) {
+		case string:
+			fmt.Fprintf(w.sheet, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			if err := xml.EscapeText(w.sheet, []byte(validXMLString(v))); err != nil {
+				return err
+			}
+			w.sheet.WriteString(`</t></is></c>`)
+		case int64:
+			fmt.Fprintf(w.sheet, `<c r="%s"><v>%d</v></c>`, ref, v)
+		case int:
+			fmt.Fprintf(w.sheet, `<c r="%s"><v>%d</v></c>`, ref, v)
+		case float64:
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				// Excel can't represent these as numbers
+				fmt.Fprintf(w.sheet, `<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, ref, strconv.FormatFloat(v, 'g', -1, 64))
+				continue
+			}
+			fmt.Fprintf(w.sheet, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(v, 'g', -1, 64))
+		case json.Number:
+			fmt.Fprintf(w.sheet, `<c r="%s"><v>%s</v></c>`, ref, v)
+		case bool:
+			b := 0
+			if v {
+				b = 1
+			}
+			fmt.Fprintf(w.sheet, `<c r="%s" t="b"><v>%d</v></c>`, ref, b)
+		case time.Time:
+			fmt.Fprintf(w.sheet, `<c r="%s" s="%d"><v>%s</v></c>`, ref, dateStyle, strconv.FormatFloat(dateSerial(v), 'f', -1, 64))
+		}
+	}
+	_, err := w.sheet.WriteString(`</row>`)
+	return err
+}
+
+// Close finishes writing the workbook. It doesn't close the underlying
+// writer.
+func (w *Writer) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if _, err := w.sheet.WriteString(worksheetEndXML); err != nil {
+		return err
+	}
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.zip.Close()
+}
+
+// cellReference returns the A1 style reference of a cell, where column
+// starts at 0 and row starts at 1.
+func cellReference(column, row int) string {
+	var name []byte
+	for column >= 0 {
+		name = append([]byte{byte('A' + column%26)}, name...)
+		column = column/26 - 1
+	}
+	return string(name) + strconv.Itoa(row)
+}
+
+// dateSerial converts t into the number of days since Excel's epoch. Excel
+// dates have no time zone, so the time in UTC is used.
+func dateSerial(t time.Time) float64 {
+	const secondsPerDay = 24 * 60 * 60
+	return float64(t.Unix()-excelEpoch.Unix())/secondsPerDay + float64(t.Nanosecond())/(secondsPerDay*1e9)
+}
+
+// validXMLString removes the characters which aren't allowed in XML
+// documents from s. Invalid UTF-8 is replaced with the Unicode replacement
+// character.
+func validXMLString(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' || (r >= 0x20 && r <= 0xD7FF) || (r >= 0xE000 && r <= 0xFFFD) || (r >= 0x10000 && r <= 0x10FFFF) {
+			return r
+		}
+		return -1
+	}, s)
+}
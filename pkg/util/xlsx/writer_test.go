@@ -0,0 +1,54 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, "namespace-cpu-request")
+	require.NoError(t, w.Write([]interface{}{"namespace", "amount", "billable", "period_start"}))
+	require.NoError(t, w.Write([]interface{}{"team-a & b\x00", 1.5, true, time.Date(2018, time.July, 1, 12, 0, 0, 0, time.UTC)}))
+	require.NoError(t, w.Write([]interface{}{nil, int64(3), nil, nil}))
+	require.NoError(t, w.Write([]interface{}{nil, json.Number("1234567890.123456789012"), nil, nil}))
+	assert.Error(t, w.Write([]interface{}{map[string]string{}}), "unsupported values should be rejected")
+	assert.Error(t, w.Write([]interface{}{json.Number("1</v>")}), "invalid numbers should be rejected")
+	require.NoError(t, w.Close())
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	files := make(map[string]string)
+	for _, f := range r.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		assert.Contains(t, files, name)
+	}
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="namespace-cpu-request" sheetId="1" r:id="rId1"/>`)
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<row r="1"><c r="A1" t="inlineStr"><is><t xml:space="preserve">namespace</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="A2" t="inlineStr"><is><t xml:space="preserve">team-a &amp; b</t></is></c><c r="B2"><v>1.5</v></c><c r="C2" t="b"><v>1</v></c><c r="D2" s="1"><v>43282.5</v></c>`)
+	assert.Contains(t, sheet, `<row r="3"><c r="B3"><v>3</v></c></row>`)
+	assert.Contains(t, sheet, `<row r="4"><c r="B4"><v>1234567890.123456789012</v></c></row></sheetData>`)
+}
+
+func TestCellReference(t *testing.T) {
+	assert.Equal(t, "A1", cellReference(0, 1))
+	assert.Equal(t, "Z2", cellReference(25, 2))
+	assert.Equal(t, "AA3", cellReference(26, 3))
+	assert.Equal(t, "BA4", cellReference(52, 4))
+}
@@ -22,6 +22,8 @@ var (
 	reportTestTimeout         = 5 * time.Minute
 	reportTestOutputDirectory string
 	runAWSBillingTests        bool
+	runGCPBillingTests        bool
+	runKubernetesObjectsTests bool
 )
 
 func init() {
@@ -43,6 +45,8 @@ func init() {
 	}
 
 	runAWSBillingTests = os.Getenv("ENABLE_AWS_BILLING_TESTS") == "true"
+	runGCPBillingTests = os.Getenv("ENABLE_GCP_BILLING_TESTS") == "true"
+	runKubernetesObjectsTests = os.Getenv("ENABLE_KUBERNETES_OBJECTS_TESTS") == "true"
 }
 
 func TestReportsProduceData(t *testing.T) {
@@ -63,6 +67,11 @@ func TestReportsProduceData(t *testing.T) {
 			queryName: "namespace-cpu-usage",
 			timeout:   reportTestTimeout,
 		},
+		{
+			name:      "namespace-cpu-cost",
+			queryName: "namespace-cpu-cost",
+			timeout:   reportTestTimeout,
+		},
 		{
 			name:      "namespace-memory-request",
 			queryName: "namespace-memory-request",
@@ -73,6 +82,16 @@ func TestReportsProduceData(t *testing.T) {
 			queryName: "namespace-memory-usage",
 			timeout:   reportTestTimeout + time.Minute,
 		},
+		{
+			name:      "namespace-memory-cost",
+			queryName: "namespace-memory-cost",
+			timeout:   reportTestTimeout + time.Minute,
+		},
+		{
+			name:      "namespace-cost-forecast",
+			queryName: "namespace-cost-forecast",
+			timeout:   reportTestTimeout + time.Minute,
+		},
 		{
 			name:      "pod-cpu-request",
 			queryName: "pod-cpu-request",
@@ -98,6 +117,66 @@ func TestReportsProduceData(t *testing.T) {
 			queryName: "pod-memory-request-vs-node-memory-allocatable",
 			timeout:   reportTestTimeout + time.Minute,
 		},
+		{
+			name:      "namespace-cpu-efficiency",
+			queryName: "namespace-cpu-efficiency",
+			timeout:   reportTestTimeout,
+		},
+		{
+			name:      "namespace-memory-efficiency",
+			queryName: "namespace-memory-efficiency",
+			timeout:   reportTestTimeout,
+		},
+		{
+			name:      "workload-cpu-efficiency",
+			queryName: "workload-cpu-efficiency",
+			timeout:   reportTestTimeout,
+		},
+		{
+			name:      "workload-memory-efficiency",
+			queryName: "workload-memory-efficiency",
+			timeout:   reportTestTimeout,
+		},
+		{
+			name:      "pod-network-usage",
+			queryName: "pod-network-usage",
+			timeout:   reportTestTimeout,
+		},
+		{
+			name:      "namespace-network-usage",
+			queryName: "namespace-network-usage",
+			timeout:   reportTestTimeout,
+		},
+		{
+			name:      "namespace-network-cost",
+			queryName: "namespace-network-cost",
+			timeout:   reportTestTimeout,
+		},
+		{
+			name:      "persistentvolumeclaim-usage",
+			queryName: "persistentvolumeclaim-usage",
+			timeout:   reportTestTimeout,
+		},
+		{
+			name:      "namespace-persistentvolumeclaim-usage",
+			queryName: "namespace-persistentvolumeclaim-usage",
+			timeout:   reportTestTimeout,
+		},
+		{
+			name:      "namespace-persistentvolumeclaim-cost",
+			queryName: "namespace-persistentvolumeclaim-cost",
+			timeout:   reportTestTimeout,
+		},
+		{
+			name:      "service-loadbalancer-usage",
+			queryName: "service-loadbalancer-usage",
+			timeout:   reportTestTimeout,
+		},
+		{
+			name:      "namespace-loadbalancer-cost",
+			queryName: "namespace-loadbalancer-cost",
+			timeout:   reportTestTimeout,
+		},
 		{
 			name:      "node-cpu-utilization",
 			queryName: "node-cpu-utilization",
@@ -108,6 +187,16 @@ func TestReportsProduceData(t *testing.T) {
 			queryName: "node-memory-utilization",
 			timeout:   reportTestTimeout,
 		},
+		{
+			name:      "node-cost",
+			queryName: "node-cost",
+			timeout:   reportTestTimeout,
+		},
+		{
+			name:      "namespace-node-cost",
+			queryName: "namespace-node-cost",
+			timeout:   reportTestTimeout,
+		},
 		{
 			name:      "pod-cpu-request-aws",
 			queryName: "pod-cpu-request-aws",
@@ -126,6 +215,30 @@ func TestReportsProduceData(t *testing.T) {
 			timeout:   reportTestTimeout,
 			skip:      !runAWSBillingTests,
 		},
+		{
+			name:      "gcp-gce-cluster-cost",
+			queryName: "gcp-gce-cluster-cost",
+			timeout:   reportTestTimeout,
+			skip:      !runGCPBillingTests,
+		},
+		{
+			name:      "namespace-cpu-cost-gcp",
+			queryName: "namespace-cpu-cost-gcp",
+			timeout:   reportTestTimeout,
+			skip:      !runGCPBillingTests,
+		},
+		{
+			name:      "workload-cpu-request",
+			queryName: "workload-cpu-request",
+			timeout:   reportTestTimeout,
+			skip:      !runKubernetesObjectsTests,
+		},
+		{
+			name:      "workload-memory-request",
+			queryName: "workload-memory-request",
+			timeout:   reportTestTimeout,
+			skip:      !runKubernetesObjectsTests,
+		},
 	}
 
 	reportStart, reportEnd := testFramework.CollectMetricsOnce(t)